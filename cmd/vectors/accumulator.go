@@ -0,0 +1,211 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+
+	"github.com/etclab/kryptology/pkg/accumulator"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+var accumulatorModule = module{
+	name: "accumulator",
+	description: "Dynamic universal accumulator on BLS12-381 from pkg/accumulator with the accumulator in G1. " +
+		"The secret key is Hash(seed) and each element is the scalar Hash(message) of the curves package. Keys, " +
+		"accumulators, witnesses, parameters and proofs use the curve-tagged BARE encoding of the package. Membership " +
+		"proofs are randomized and are only to be verified: the challenge is Hash of the challenge bytes of the " +
+		"committed proof, and finalizing the proof must yield the challenge.",
+	generate: generateAccumulator,
+	verify:   verifyAccumulator,
+}
+
+type accumulatorVector struct {
+	Seed        Hex                `json:"seed"`
+	Entropy     Hex                `json:"params_entropy"`
+	SecretKey   Hex                `json:"secret_key"`
+	PublicKey   Hex                `json:"public_key"`
+	Params      Hex                `json:"params"`
+	Messages    []Hex              `json:"messages"`
+	Accumulator Hex                `json:"accumulator"`
+	Members     []accumulatorProof `json:"members"`
+}
+
+type accumulatorProof struct {
+	Message   int `json:"message"`
+	Witness   Hex `json:"witness"`
+	Challenge Hex `json:"challenge"`
+	Proof     Hex `json:"proof"`
+}
+
+func accumulatorCurve() *curves.PairingCurve {
+	return curves.BLS12381(&curves.PointBls12381G1{})
+}
+
+// accumulatorState holds the deterministic values of a vector
+type accumulatorState struct {
+	sk       *accumulator.SecretKey
+	pk       *accumulator.PublicKey
+	params   *accumulator.ProofParams
+	elements []accumulator.Element
+	acc      *accumulator.Accumulator
+}
+
+func newAccumulatorState(seed, entropy []byte, msgs []Hex) (*accumulatorState, error) {
+	curve := accumulatorCurve()
+	sk, err := new(accumulator.SecretKey).New(curve, seed)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := sk.GetPublicKey(curve)
+	if err != nil {
+		return nil, err
+	}
+	params, err := new(accumulator.ProofParams).New(curve, pk, entropy)
+	if err != nil {
+		return nil, err
+	}
+	elements := make([]accumulator.Element, len(msgs))
+	for i, msg := range msgs {
+		elements[i] = curve.Scalar.Hash(msg)
+	}
+	acc, err := new(accumulator.Accumulator).WithElements(curve, sk, elements)
+	if err != nil {
+		return nil, err
+	}
+	return &accumulatorState{sk, pk, params, elements, acc}, nil
+}
+
+// encodings returns the encodings of the secret key, public key, parameters and accumulator
+func (s *accumulatorState) encodings() ([][]byte, error) {
+	values := []encoding.BinaryMarshaler{s.sk, s.pk, s.params, s.acc}
+	out := make([][]byte, len(values))
+	for i, v := range values {
+		data, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = data
+	}
+	return out, nil
+}
+
+func generateAccumulator() (interface{}, error) {
+	curve := accumulatorCurve()
+	v := accumulatorVector{
+		Seed:     seed("accumulator secret key", 32),
+		Entropy:  seed("accumulator params entropy", 32),
+		Messages: messages,
+	}
+	s, err := newAccumulatorState(v.Seed, v.Entropy, v.Messages)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := s.encodings()
+	if err != nil {
+		return nil, err
+	}
+	v.SecretKey, v.PublicKey, v.Params, v.Accumulator = enc[0], enc[1], enc[2], enc[3]
+
+	for i, element := range s.elements {
+		wit, err := new(accumulator.MembershipWitness).New(element, s.acc, s.sk)
+		if err != nil {
+			return nil, err
+		}
+		witBytes, err := wit.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		mpc, err := new(accumulator.MembershipProofCommitting).New(wit, s.acc, s.params, s.pk)
+		if err != nil {
+			return nil, err
+		}
+		challenge := curve.Scalar.Hash(mpc.GetChallengeBytes())
+		proofBytes, err := mpc.GenProof(challenge).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		v.Members = append(v.Members, accumulatorProof{
+			Message:   i,
+			Witness:   witBytes,
+			Challenge: challenge.Bytes(),
+			Proof:     proofBytes,
+		})
+	}
+	return []accumulatorVector{v}, nil
+}
+
+func verifyAccumulator(raw json.RawMessage) error {
+	var vectors []accumulatorVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		return err
+	}
+	for i, v := range vectors {
+		if err := verifyAccumulatorVector(v); err != nil {
+			return fmt.Errorf("vector %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func verifyAccumulatorVector(v accumulatorVector) error {
+	curve := accumulatorCurve()
+	s, err := newAccumulatorState(v.Seed, v.Entropy, v.Messages)
+	if err != nil {
+		return err
+	}
+	enc, err := s.encodings()
+	if err != nil {
+		return err
+	}
+	for i, name := range []string{"secret key", "public key", "params", "accumulator"} {
+		expected := [][]byte{v.SecretKey, v.PublicKey, v.Params, v.Accumulator}[i]
+		if err = expectEqual(name, expected, enc[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range v.Members {
+		if m.Message < 0 || m.Message >= len(s.elements) {
+			return fmt.Errorf("member %d out of range", m.Message)
+		}
+		wit, err := new(accumulator.MembershipWitness).New(s.elements[m.Message], s.acc, s.sk)
+		if err != nil {
+			return err
+		}
+		witBytes, err := wit.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if err = expectEqual(fmt.Sprintf("witness %d", m.Message), m.Witness, witBytes); err != nil {
+			return err
+		}
+		if err = wit.Verify(s.pk, s.acc); err != nil {
+			return err
+		}
+
+		challenge, err := curve.Scalar.SetBytes(m.Challenge)
+		if err != nil {
+			return err
+		}
+		proof := new(accumulator.MembershipProof)
+		if err = proof.UnmarshalBinary(m.Proof); err != nil {
+			return err
+		}
+		final, err := proof.Finalize(s.acc, s.params, s.pk, challenge)
+		if err != nil {
+			return err
+		}
+		ok := final.GetChallenge(curve).Cmp(challenge) == 0
+		if err = expectValid(fmt.Sprintf("proof %d", m.Message), ok, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gtank/merlin"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/bbs"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+var bbsModule = module{
+	name: "bbs",
+	description: "BBS+ signatures on BLS12-381 from pkg/signatures/bbs with public keys in G2. Each message is mapped " +
+		"to the scalar Hash(message) of the curves package and the message generators are derived from the public key. " +
+		"Signatures are deterministic. Proofs of knowledge are randomized and are only to be verified: the challenge " +
+		"is extracted from a merlin transcript with the label, after the proof commitments and the nonce.",
+	generate: generateBbs,
+	verify:   verifyBbs,
+}
+
+type bbsVector struct {
+	SecretKey      Hex            `json:"secret_key"`
+	PublicKey      Hex            `json:"public_key"`
+	Messages       []Hex          `json:"messages"`
+	MessageScalars []Hex          `json:"message_scalars"`
+	Signature      Hex            `json:"signature"`
+	Proofs         []bbsPokVector `json:"proofs"`
+}
+
+type bbsPokVector struct {
+	Label     string `json:"transcript_label"`
+	Revealed  []int  `json:"revealed"`
+	Nonce     Hex    `json:"nonce"`
+	Challenge Hex    `json:"challenge"`
+	Proof     Hex    `json:"proof"`
+}
+
+// bbsRevealed are the sets of messages revealed by the proofs of knowledge
+var bbsRevealed = [][]int{{}, {0, 2}, {0, 1, 2, 3}}
+
+func bbsCurve() *curves.PairingCurve {
+	return curves.BLS12381(&curves.PointBls12381G2{})
+}
+
+// bbsSetup returns the public key, generators and message scalars of the secret key and messages
+func bbsSetup(skBytes []byte, msgs []Hex) (*bbs.SecretKey, *bbs.PublicKey, *bbs.MessageGenerators, []curves.Scalar, error) {
+	curve := bbsCurve()
+	sk := new(bbs.SecretKey).Init(curve)
+	if err := sk.UnmarshalBinary(skBytes); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	pk := sk.PublicKey()
+	generators, err := new(bbs.MessageGenerators).Init(pk, len(msgs))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	scalars := make([]curves.Scalar, len(msgs))
+	for i, msg := range msgs {
+		scalars[i] = curve.Scalar.Hash(msg)
+	}
+	return sk, pk, generators, scalars, nil
+}
+
+func generateBbs() (interface{}, error) {
+	curve := bbsCurve()
+	skBytes := curve.Scalar.Hash(seed("bbs secret key", 32)).Bytes()
+	sk, pk, generators, scalars, err := bbsSetup(skBytes, messages)
+	if err != nil {
+		return nil, err
+	}
+	pkBytes, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sk.Sign(generators, scalars)
+	if err != nil {
+		return nil, err
+	}
+	sigBytes, err := sig.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	v := bbsVector{
+		SecretKey: skBytes,
+		PublicKey: pkBytes,
+		Messages:  messages,
+		Signature: sigBytes,
+	}
+	for _, s := range scalars {
+		v.MessageScalars = append(v.MessageScalars, s.Bytes())
+	}
+
+	for _, revealed := range bbsRevealed {
+		label := fmt.Sprintf("bbs proof revealing %v", revealed)
+		isRevealed := make(map[int]bool)
+		for _, i := range revealed {
+			isRevealed[i] = true
+		}
+		proofMsgs := make([]common.ProofMessage, len(scalars))
+		for i, s := range scalars {
+			if isRevealed[i] {
+				proofMsgs[i] = &common.RevealedMessage{Message: s}
+			} else {
+				proofMsgs[i] = &common.ProofSpecificMessage{Message: s}
+			}
+		}
+		reader := seededReader(label)
+		pok, err := bbs.NewPokSignature(sig, generators, proofMsgs, reader)
+		if err != nil {
+			return nil, err
+		}
+		nonce := curve.Scalar.Random(reader)
+		transcript := merlin.NewTranscript(label)
+		pok.GetChallengeContribution(transcript)
+		transcript.AppendMessage([]byte("nonce"), nonce.Bytes())
+		challenge, err := curve.Scalar.SetBytesWide(transcript.ExtractBytes([]byte("signature proof of knowledge"), 64))
+		if err != nil {
+			return nil, err
+		}
+		proof, err := pok.GenerateProof(challenge)
+		if err != nil {
+			return nil, err
+		}
+		proofBytes, err := proof.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		v.Proofs = append(v.Proofs, bbsPokVector{
+			Label:     label,
+			Revealed:  append([]int{}, revealed...),
+			Nonce:     nonce.Bytes(),
+			Challenge: challenge.Bytes(),
+			Proof:     proofBytes,
+		})
+	}
+	return []bbsVector{v}, nil
+}
+
+func verifyBbs(raw json.RawMessage) error {
+	var vectors []bbsVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		return err
+	}
+	for i, v := range vectors {
+		if err := verifyBbsVector(v); err != nil {
+			return fmt.Errorf("vector %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func verifyBbsVector(v bbsVector) error {
+	curve := bbsCurve()
+	sk, pk, generators, scalars, err := bbsSetup(v.SecretKey, v.Messages)
+	if err != nil {
+		return err
+	}
+	pkBytes, err := pk.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("public key", v.PublicKey, pkBytes); err != nil {
+		return err
+	}
+	if len(v.MessageScalars) != len(scalars) {
+		return fmt.Errorf("expected %d message scalars, got %d", len(scalars), len(v.MessageScalars))
+	}
+	for i, s := range scalars {
+		if err = expectEqual(fmt.Sprintf("message scalar %d", i), v.MessageScalars[i], s.Bytes()); err != nil {
+			return err
+		}
+	}
+	sig, err := sk.Sign(generators, scalars)
+	if err != nil {
+		return err
+	}
+	sigBytes, err := sig.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("signature", v.Signature, sigBytes); err != nil {
+		return err
+	}
+	sig = new(bbs.Signature).Init(curve)
+	if err = sig.UnmarshalBinary(v.Signature); err != nil {
+		return err
+	}
+	if err = pk.Verify(sig, generators, scalars); err != nil {
+		return err
+	}
+
+	for j, p := range v.Proofs {
+		revealedMsgs := make(map[int]curves.Scalar)
+		for _, i := range p.Revealed {
+			if i < 0 || i >= len(scalars) {
+				return fmt.Errorf("proof %d: revealed message %d out of range", j, i)
+			}
+			revealedMsgs[i] = scalars[i]
+		}
+		nonce, err := curve.Scalar.SetBytes(p.Nonce)
+		if err != nil {
+			return err
+		}
+		challenge, err := curve.Scalar.SetBytes(p.Challenge)
+		if err != nil {
+			return err
+		}
+		proof := new(bbs.PokSignatureProof).Init(curve)
+		if err = proof.UnmarshalBinary(p.Proof); err != nil {
+			return err
+		}
+		ok := proof.Verify(revealedMsgs, pk, generators, nonce, challenge, merlin.NewTranscript(p.Label))
+		if err = expectValid(fmt.Sprintf("proof %d", j), ok, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/signatures/bls/bls_sig"
+)
+
+var blsModule = module{
+	name: "bls",
+	description: "BLS signatures on BLS12-381 from pkg/signatures/bls/bls_sig. The usual variant has public keys in G1 " +
+		"and signatures in G2, the tiny variant has public keys in G2 and signatures in G1. Keys are derived from the " +
+		"ikm with KeyGen of draft-irtf-cfrg-bls-signature and points use the compressed ZCash encoding. " +
+		"The aug scheme signs the public key followed by the message.",
+	generate: generateBls,
+	verify:   verifyBls,
+}
+
+type blsVector struct {
+	Variant   string `json:"variant"`
+	Scheme    string `json:"scheme"`
+	Dst       string `json:"dst"`
+	PopDst    string `json:"pop_dst,omitempty"`
+	Ikm       Hex    `json:"ikm"`
+	SecretKey Hex    `json:"secret_key"`
+	PublicKey Hex    `json:"public_key"`
+	Message   Hex    `json:"message"`
+	Signature Hex    `json:"signature"`
+	Pop       Hex    `json:"pop,omitempty"`
+}
+
+// ietfBlsSuite is the layout of the test vectors of draft-irtf-cfrg-bls-signature, the vectors of one
+// ciphersuite named by its ID
+type ietfBlsSuite struct {
+	Ciphersuite string          `json:"ciphersuite"`
+	Vectors     []ietfBlsVector `json:"vectors"`
+}
+
+type ietfBlsVector struct {
+	Ikm       Hex `json:"ikm"`
+	SecretKey Hex `json:"sk"`
+	PublicKey Hex `json:"pk"`
+	Message   Hex `json:"msg"`
+	Signature Hex `json:"sig"`
+	Pop       Hex `json:"pop,omitempty"`
+}
+
+// blsScheme adapts the signature schemes of both variants to byte strings
+type blsScheme struct {
+	variant, scheme string
+	dst, popDst     string
+	keygen          func(ikm []byte) (sk, pk []byte, err error)
+	sign            func(sk, msg []byte) ([]byte, error)
+	verify          func(pk, msg, sig []byte) (bool, error)
+	popProve        func(sk []byte) ([]byte, error)
+	popVerify       func(pk, pop []byte) (bool, error)
+}
+
+func blsSchemes() []blsScheme {
+	basic, aug, pop := bls_sig.NewSigBasic(), bls_sig.NewSigAug(), bls_sig.NewSigPop()
+	basicVt, augVt, popVt := bls_sig.NewSigBasicVt(), bls_sig.NewSigAugVt(), bls_sig.NewSigPopVt()
+	return []blsScheme{
+		{
+			variant: "usual", scheme: "basic", dst: "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_",
+			keygen: usualKeygen(basic.KeygenWithSeed), sign: usualSign(basic.Sign), verify: usualVerify(basic.Verify),
+		},
+		{
+			variant: "usual", scheme: "aug", dst: "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_AUG_",
+			keygen: usualKeygen(aug.KeygenWithSeed), sign: usualSign(aug.Sign), verify: usualVerify(aug.Verify),
+		},
+		{
+			variant: "usual", scheme: "pop", dst: "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_",
+			popDst: "BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_",
+			keygen: usualKeygen(pop.KeygenWithSeed), sign: usualSign(pop.Sign), verify: usualVerify(pop.Verify),
+			popProve: func(skBytes []byte) ([]byte, error) {
+				sk := new(bls_sig.SecretKey)
+				if err := sk.UnmarshalBinary(skBytes); err != nil {
+					return nil, err
+				}
+				proof, err := pop.PopProve(sk)
+				if err != nil {
+					return nil, err
+				}
+				return proof.MarshalBinary()
+			},
+			popVerify: func(pkBytes, popBytes []byte) (bool, error) {
+				pk, proof := new(bls_sig.PublicKey), new(bls_sig.ProofOfPossession)
+				if err := pk.UnmarshalBinary(pkBytes); err != nil {
+					return false, err
+				}
+				if err := proof.UnmarshalBinary(popBytes); err != nil {
+					return false, err
+				}
+				return pop.PopVerify(pk, proof)
+			},
+		},
+		{
+			variant: "tiny", scheme: "basic", dst: "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_",
+			keygen: tinyKeygen(basicVt.KeygenWithSeed), sign: tinySign(basicVt.Sign), verify: tinyVerify(basicVt.Verify),
+		},
+		{
+			variant: "tiny", scheme: "aug", dst: "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_AUG_",
+			keygen: tinyKeygen(augVt.KeygenWithSeed), sign: tinySign(augVt.Sign), verify: tinyVerify(augVt.Verify),
+		},
+		{
+			variant: "tiny", scheme: "pop", dst: "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_",
+			popDst: "BLS_POP_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_",
+			keygen: tinyKeygen(popVt.KeygenWithSeed), sign: tinySign(popVt.Sign), verify: tinyVerify(popVt.Verify),
+			popProve: func(skBytes []byte) ([]byte, error) {
+				sk := new(bls_sig.SecretKey)
+				if err := sk.UnmarshalBinary(skBytes); err != nil {
+					return nil, err
+				}
+				proof, err := popVt.PopProve(sk)
+				if err != nil {
+					return nil, err
+				}
+				return proof.MarshalBinary()
+			},
+			popVerify: func(pkBytes, popBytes []byte) (bool, error) {
+				pk, proof := new(bls_sig.PublicKeyVt), new(bls_sig.ProofOfPossessionVt)
+				if err := pk.UnmarshalBinary(pkBytes); err != nil {
+					return false, err
+				}
+				if err := proof.UnmarshalBinary(popBytes); err != nil {
+					return false, err
+				}
+				return popVt.PopVerify(pk, proof)
+			},
+		},
+	}
+}
+
+func usualKeygen(keygen func([]byte) (*bls_sig.PublicKey, *bls_sig.SecretKey, error)) func([]byte) ([]byte, []byte, error) {
+	return func(ikm []byte) ([]byte, []byte, error) {
+		pk, sk, err := keygen(ikm)
+		if err != nil {
+			return nil, nil, err
+		}
+		skBytes, err := sk.MarshalBinary()
+		if err != nil {
+			return nil, nil, err
+		}
+		pkBytes, err := pk.MarshalBinary()
+		return skBytes, pkBytes, err
+	}
+}
+
+func usualSign(sign func(*bls_sig.SecretKey, []byte) (*bls_sig.Signature, error)) func([]byte, []byte) ([]byte, error) {
+	return func(skBytes, msg []byte) ([]byte, error) {
+		sk := new(bls_sig.SecretKey)
+		if err := sk.UnmarshalBinary(skBytes); err != nil {
+			return nil, err
+		}
+		sig, err := sign(sk, msg)
+		if err != nil {
+			return nil, err
+		}
+		return sig.MarshalBinary()
+	}
+}
+
+func usualVerify(verify func(*bls_sig.PublicKey, []byte, *bls_sig.Signature) (bool, error)) func([]byte, []byte, []byte) (bool, error) {
+	return func(pkBytes, msg, sigBytes []byte) (bool, error) {
+		pk, sig := new(bls_sig.PublicKey), new(bls_sig.Signature)
+		if err := pk.UnmarshalBinary(pkBytes); err != nil {
+			return false, err
+		}
+		if err := sig.UnmarshalBinary(sigBytes); err != nil {
+			return false, err
+		}
+		return verify(pk, msg, sig)
+	}
+}
+
+func tinyKeygen(keygen func([]byte) (*bls_sig.PublicKeyVt, *bls_sig.SecretKey, error)) func([]byte) ([]byte, []byte, error) {
+	return func(ikm []byte) ([]byte, []byte, error) {
+		pk, sk, err := keygen(ikm)
+		if err != nil {
+			return nil, nil, err
+		}
+		skBytes, err := sk.MarshalBinary()
+		if err != nil {
+			return nil, nil, err
+		}
+		pkBytes, err := pk.MarshalBinary()
+		return skBytes, pkBytes, err
+	}
+}
+
+func tinySign(sign func(*bls_sig.SecretKey, []byte) (*bls_sig.SignatureVt, error)) func([]byte, []byte) ([]byte, error) {
+	return func(skBytes, msg []byte) ([]byte, error) {
+		sk := new(bls_sig.SecretKey)
+		if err := sk.UnmarshalBinary(skBytes); err != nil {
+			return nil, err
+		}
+		sig, err := sign(sk, msg)
+		if err != nil {
+			return nil, err
+		}
+		return sig.MarshalBinary()
+	}
+}
+
+func tinyVerify(verify func(*bls_sig.PublicKeyVt, []byte, *bls_sig.SignatureVt) (bool, error)) func([]byte, []byte, []byte) (bool, error) {
+	return func(pkBytes, msg, sigBytes []byte) (bool, error) {
+		pk, sig := new(bls_sig.PublicKeyVt), new(bls_sig.SignatureVt)
+		if err := pk.UnmarshalBinary(pkBytes); err != nil {
+			return false, err
+		}
+		if err := sig.UnmarshalBinary(sigBytes); err != nil {
+			return false, err
+		}
+		return verify(pk, msg, sig)
+	}
+}
+
+func generateBls() (interface{}, error) {
+	var vectors []blsVector
+	for _, s := range blsSchemes() {
+		ikm := seed(fmt.Sprintf("bls %s %s", s.variant, s.scheme), 32)
+		sk, pk, err := s.keygen(ikm)
+		if err != nil {
+			return nil, err
+		}
+		var pop []byte
+		if s.popProve != nil {
+			if pop, err = s.popProve(sk); err != nil {
+				return nil, err
+			}
+		}
+		for _, msg := range messages {
+			sig, err := s.sign(sk, msg)
+			if err != nil {
+				return nil, err
+			}
+			vectors = append(vectors, blsVector{
+				Variant:   s.variant,
+				Scheme:    s.scheme,
+				Dst:       s.dst,
+				PopDst:    s.popDst,
+				Ikm:       ikm,
+				SecretKey: sk,
+				PublicKey: pk,
+				Message:   msg,
+				Signature: sig,
+				Pop:       pop,
+			})
+		}
+	}
+	return vectors, nil
+}
+
+func verifyBls(raw json.RawMessage) error {
+	var vectors []blsVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		return err
+	}
+	schemes := make(map[string]blsScheme)
+	for _, s := range blsSchemes() {
+		schemes[s.variant+" "+s.scheme] = s
+	}
+	for i, v := range vectors {
+		s, ok := schemes[v.Variant+" "+v.Scheme]
+		if !ok {
+			return fmt.Errorf("vector %d: unknown scheme %s %s", i, v.Variant, v.Scheme)
+		}
+		if err := verifyBlsVector(s, v); err != nil {
+			return fmt.Errorf("vector %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func verifyBlsVector(s blsScheme, v blsVector) error {
+	sk, pk, err := s.keygen(v.Ikm)
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("secret key", v.SecretKey, sk); err != nil {
+		return err
+	}
+	if err = expectEqual("public key", v.PublicKey, pk); err != nil {
+		return err
+	}
+	sig, err := s.sign(sk, v.Message)
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("signature", v.Signature, sig); err != nil {
+		return err
+	}
+	ok, err := s.verify(v.PublicKey, v.Message, v.Signature)
+	if err = expectValid("signature", ok, err); err != nil {
+		return err
+	}
+	if s.popProve == nil {
+		return nil
+	}
+	pop, err := s.popProve(sk)
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("proof of possession", v.Pop, pop); err != nil {
+		return err
+	}
+	ok, err = s.popVerify(v.PublicKey, v.Pop)
+	return expectValid("proof of possession", ok, err)
+}
+
+// ietfBls groups the vectors by ciphersuite, in the order of blsSchemes
+func ietfBls(vectors []blsVector) []*ietfBlsSuite {
+	var suites []*ietfBlsSuite
+	byDst := make(map[string]*ietfBlsSuite)
+	for _, v := range vectors {
+		suite, ok := byDst[v.Dst]
+		if !ok {
+			suite = &ietfBlsSuite{Ciphersuite: v.Dst}
+			byDst[v.Dst] = suite
+			suites = append(suites, suite)
+		}
+		suite.Vectors = append(suite.Vectors, ietfBlsVector{
+			Ikm:       v.Ikm,
+			SecretKey: v.SecretKey,
+			PublicKey: v.PublicKey,
+			Message:   v.Message,
+			Signature: v.Signature,
+			Pop:       v.Pop,
+		})
+	}
+	return suites
+}
+
+// PrintIetfBls writes the BLS vectors to `w` in the layout of the test vectors of draft-irtf-cfrg-bls-signature
+func PrintIetfBls(w io.Writer) error {
+	vectors, err := generateBls()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ietfBls(vectors.([]blsVector)), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,129 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/gtank/merlin"
+
+	"github.com/etclab/kryptology/pkg/bulletproof"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+var bulletproofModule = module{
+	name: "bulletproof",
+	description: "Bulletproof range proofs from pkg/bulletproof that the value committed in commitment = g*v + h*gamma " +
+		"is less than 2^n. The g, h and u generators are derived from the generator domain with DeriveGenerators " +
+		"of the curves package, in that order. Proofs are randomized and are only to be verified with a merlin " +
+		"transcript with the label.",
+	generate: generateBulletproof,
+	verify:   verifyBulletproof,
+}
+
+type bulletproofVector struct {
+	Curve           string `json:"curve"`
+	N               int    `json:"n"`
+	RangeDomain     string `json:"range_domain"`
+	IppDomain       string `json:"ipp_domain"`
+	GeneratorDomain string `json:"generator_domain"`
+	Label           string `json:"transcript_label"`
+	Commitment      Hex    `json:"commitment"`
+	Proof           Hex    `json:"proof"`
+}
+
+// bulletproofCases are the curves and bit lengths of the range proofs
+var bulletproofCases = []struct {
+	curve *curves.Curve
+	n     int
+}{
+	{curves.ED25519(), 8},
+	{curves.ED25519(), 32},
+	{curves.ED25519(), 64},
+}
+
+func generateBulletproof() (interface{}, error) {
+	var vectors []bulletproofVector
+	for _, c := range bulletproofCases {
+		curve := c.curve
+		label := fmt.Sprintf("bulletproof %s %d", curve.Name, c.n)
+		v := bulletproofVector{
+			Curve:           curve.Name,
+			N:               c.n,
+			RangeDomain:     "kryptology range domain",
+			IppDomain:       "kryptology ipp domain",
+			GeneratorDomain: "kryptology generator domain",
+			Label:           label,
+		}
+		prover, err := bulletproof.NewRangeProver(c.n, []byte(v.RangeDomain), []byte(v.IppDomain), *curve)
+		if err != nil {
+			return nil, err
+		}
+		generators, err := bulletproof.NewRangeProofGenerators([]byte(v.GeneratorDomain), *curve)
+		if err != nil {
+			return nil, err
+		}
+		points, err := curves.DeriveGenerators(curve, []byte(v.GeneratorDomain), 3)
+		if err != nil {
+			return nil, err
+		}
+		// The value is below 2^n since it is formed of n/8 bytes
+		val, err := curve.Scalar.SetBigInt(new(big.Int).SetBytes(seed(label+" value", c.n/8)))
+		if err != nil {
+			return nil, err
+		}
+		gamma := curve.Scalar.Hash(seed(label+" blinding", 32))
+		proof, err := prover.Prove(val, gamma, c.n, *generators, merlin.NewTranscript(label))
+		if err != nil {
+			return nil, err
+		}
+		v.Commitment = points[0].Mul(val).Add(points[1].Mul(gamma)).ToAffineCompressed()
+		v.Proof = proof.MarshalBinary()
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+func verifyBulletproof(raw json.RawMessage) error {
+	var vectors []bulletproofVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		return err
+	}
+	for i, v := range vectors {
+		if err := verifyBulletproofVector(v); err != nil {
+			return fmt.Errorf("vector %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func verifyBulletproofVector(v bulletproofVector) error {
+	curve := curves.GetCurveByName(v.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve %s", v.Curve)
+	}
+	verifier, err := bulletproof.NewRangeVerifier(v.N, []byte(v.RangeDomain), []byte(v.IppDomain), *curve)
+	if err != nil {
+		return err
+	}
+	generators, err := bulletproof.NewRangeProofGenerators([]byte(v.GeneratorDomain), *curve)
+	if err != nil {
+		return err
+	}
+	capV, err := curve.Point.FromAffineCompressed(v.Commitment)
+	if err != nil {
+		return err
+	}
+	proof := bulletproof.NewRangeProof(curve)
+	if err = proof.UnmarshalBinary(v.Proof); err != nil {
+		return err
+	}
+	ok, err := verifier.Verify(proof, capV, *generators, v.N, merlin.NewTranscript(v.Label))
+	return expectValid("range proof", ok, err)
+}
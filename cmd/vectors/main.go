@@ -0,0 +1,166 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// vectors implements a command that emits JSON known-answer vectors for the signature, accumulator,
+// bulletproof and oblivious transfer modules from the Go implementations, so implementations in other languages can
+// check their compatibility mechanically. The vectors are written to a directory named after the
+// release, which is read from the first section of the changelog unless given with -version.
+// With -check, the command verifies the vectors of the release against the Go implementations
+// instead of writing them, and with -ietf it prints the BLS vectors grouped by ciphersuite.
+//
+// It is run with go generate and writes the vectors to test/vectors.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//go:generate go run . -out ../../test/vectors -changelog ../../CHANGELOG.md
+
+// Generator is the name recorded in every vector file
+const Generator = "github.com/etclab/kryptology/cmd/vectors"
+
+// File is the content of a vector file. The byte strings of the vectors are hex encoded
+type File struct {
+	Module      string          `json:"module"`
+	Version     string          `json:"version"`
+	Generator   string          `json:"generator"`
+	Description string          `json:"description"`
+	Vectors     json.RawMessage `json:"vectors"`
+}
+
+// module generates and verifies the vectors of one module
+type module struct {
+	name        string
+	description string
+	generate    func() (interface{}, error)
+	verify      func(vectors json.RawMessage) error
+}
+
+var modules = []module{
+	blsModule,
+	bbsModule,
+	schnorrModule,
+	accumulatorModule,
+	bulletproofModule,
+	otModule,
+}
+
+func main() {
+	out := flag.String("out", ".", "The directory under which the directory of the release is created.")
+	version := flag.String("version", "", "The release of the vectors. Defaults to the first section of the changelog.")
+	changelog := flag.String("changelog", "CHANGELOG.md", "The path to the changelog.")
+	check := flag.Bool("check", false, "Verify the vectors of the release instead of writing them.")
+	ietf := flag.Bool("ietf", false, "Print the BLS vectors grouped by ciphersuite ID instead of writing the vectors.")
+	flag.Parse()
+
+	if *ietf {
+		if err := PrintIetfBls(os.Stdout); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if *version == "" {
+		v, err := releaseFromChangelog(*changelog)
+		if err != nil {
+			panic(err)
+		}
+		*version = v
+	}
+	dir := filepath.Join(*out, *version)
+	var err error
+	if *check {
+		err = Check(dir)
+	} else {
+		err = Write(dir, *version)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// releaseFromChangelog returns the name of the first section of the changelog in lowercase,
+// which is "unreleased" between releases and the version, e.g. v1.8.0, at a release
+func releaseFromChangelog(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "opening changelog")
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "## ") {
+			return strings.ToLower(strings.TrimSpace(line[3:])), nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return "", errors.Wrap(err, "reading changelog")
+	}
+	return "", fmt.Errorf("no release found in %s", path)
+}
+
+// Write generates the vectors of every module into `dir`
+func Write(dir, version string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrap(err, "creating vector directory")
+	}
+	for _, m := range modules {
+		vectors, err := m.generate()
+		if err != nil {
+			return errors.Wrapf(err, "generating %s vectors", m.name)
+		}
+		raw, err := json.Marshal(vectors)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(File{
+			Module:      m.name,
+			Version:     version,
+			Generator:   Generator,
+			Description: m.description,
+			Vectors:     raw,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, m.name+".json"), append(data, '\n'), 0o644); err != nil {
+			return errors.Wrapf(err, "writing %s vectors", m.name)
+		}
+	}
+	return nil
+}
+
+// Check verifies the vectors of every module in `dir`
+func Check(dir string) error {
+	for _, m := range modules {
+		data, err := ioutil.ReadFile(filepath.Join(dir, m.name+".json"))
+		if err != nil {
+			return errors.Wrapf(err, "reading %s vectors", m.name)
+		}
+		var f File
+		if err = json.Unmarshal(data, &f); err != nil {
+			return errors.Wrapf(err, "decoding %s vectors", m.name)
+		}
+		if f.Module != m.name {
+			return fmt.Errorf("%s vectors are for module %s", m.name, f.Module)
+		}
+		if err = m.verify(f.Vectors); err != nil {
+			return errors.Wrapf(err, "%s vectors", m.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCheck(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Write(dir, "test"))
+	require.NoError(t, Check(dir))
+
+	// A changed signature is detected
+	path := filepath.Join(dir, "bls.json")
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var f File
+	require.NoError(t, json.Unmarshal(data, &f))
+	var vectors []blsVector
+	require.NoError(t, json.Unmarshal(f.Vectors, &vectors))
+	vectors[0].Signature[len(vectors[0].Signature)-1] ^= 1
+	f.Vectors, err = json.Marshal(vectors)
+	require.NoError(t, err)
+	data, err = json.Marshal(f)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, data, 0o644))
+	require.Error(t, Check(dir))
+}
+
+func TestIetfBls(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, PrintIetfBls(&buf))
+	var suites []ietfBlsSuite
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &suites))
+	schemes := blsSchemes()
+	require.Len(t, suites, len(schemes))
+	for i, suite := range suites {
+		s := schemes[i]
+		require.Equal(t, s.dst, suite.Ciphersuite)
+		require.Len(t, suite.Vectors, len(messages))
+		for j, v := range suite.Vectors {
+			require.NoError(t, verifyBlsVector(s, blsVector{
+				Ikm:       v.Ikm,
+				SecretKey: v.SecretKey,
+				PublicKey: v.PublicKey,
+				Message:   v.Message,
+				Signature: v.Signature,
+				Pop:       v.Pop,
+			}))
+			require.Equal(t, messages[j], v.Message)
+		}
+	}
+}
+
+func TestPublishedVectors(t *testing.T) {
+	version, err := releaseFromChangelog("../../CHANGELOG.md")
+	require.NoError(t, err)
+	require.NoError(t, Check(filepath.Join("../../test/vectors", version)))
+}
+
+func TestOtVectors(t *testing.T) {
+	generated, err := generateOt()
+	require.NoError(t, err)
+	raw, err := json.Marshal(generated)
+	require.NoError(t, err)
+	require.NoError(t, verifyOt(raw))
+
+	// The runs are reproducible from the seeds, so any changed message is detected
+	vectors := generated.(otVectors)
+	vectors.Simplest[0].Challenges[3][0] ^= 1
+	raw, err = json.Marshal(vectors)
+	require.NoError(t, err)
+	require.Error(t, verifyOt(raw))
+	vectors.Simplest[0].Challenges[3][0] ^= 1
+	vectors.Kos[0].Tau[100][1][5] ^= 1
+	raw, err = json.Marshal(vectors)
+	require.NoError(t, err)
+	require.Error(t, verifyOt(raw))
+
+	vectors.Format++
+	raw, err = json.Marshal(vectors)
+	require.NoError(t, err)
+	require.Error(t, verifyOt(raw))
+}
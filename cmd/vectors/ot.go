@@ -0,0 +1,430 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+)
+
+// otFormat is the version of the layout of the OT vectors. It changes whenever a field is added, removed or
+// reinterpreted, or the randomness of a party is drawn in a different order.
+const otFormat = 1
+
+var otModule = module{
+	name: "ot",
+	description: "Oblivious transfer. simplest are runs of the verified simplest OT of pkg/ot/base/simplest and kos " +
+		"are runs of the KOS cOT extension of pkg/ot/extension/kos, on top of the base OT outputs of the vector. " +
+		"All the randomness of a party is read in order from SHAKE256 of its seed: the simplest sender draws its " +
+		"secret key b and then the nonce of its Schnorr proof, the simplest receiver draws its packed choice bits and " +
+		"then the secret key a of every OT, and the KOS receiver draws its random coins gamma^ext. Scalars are drawn " +
+		"as in curves.Scalar.Random, i.e. 64 bytes hashed with the hash to field of the curve, and are in the " +
+		"encoding of the curves package; points are compressed. These secrets are recorded next to the seeds, and " +
+		"every message and output of the run can be reproduced exactly from the inputs of a vector. Bits are packed " +
+		"least significant bit first. format is the version of this layout.",
+	generate: generateOt,
+	verify:   verifyOt,
+}
+
+type otVectors struct {
+	Format   int                `json:"format"`
+	Simplest []simplestOtVector `json:"simplest"`
+	Kos      []kosVector        `json:"kos"`
+}
+
+type simplestOtVector struct {
+	Curve        string `json:"curve"`
+	BatchSize    int    `json:"batch_size"`
+	SessionId    Hex    `json:"session_id"`
+	SenderSeed   Hex    `json:"sender_seed"`
+	ReceiverSeed Hex    `json:"receiver_seed"`
+
+	// secrets drawn from the seeds
+	SenderSecretKey    Hex   `json:"sender_secret_key"`
+	ProofNonce         Hex   `json:"proof_nonce"`
+	ChoiceBits         Hex   `json:"choice_bits"`
+	ReceiverSecretKeys []Hex `json:"receiver_secret_keys"`
+
+	// messages, in the order they are sent
+	PublicKey          Hex      `json:"public_key"`
+	ProofC             Hex      `json:"proof_c"`
+	ProofS             Hex      `json:"proof_s"`
+	MaskedChoices      []Hex    `json:"masked_choices"`
+	Challenges         []Hex    `json:"challenges"`
+	ChallengeResponses []Hex    `json:"challenge_responses"`
+	ChallengeOpenings  [][2]Hex `json:"challenge_openings"`
+
+	// outputs
+	SenderKeys   [][2]Hex `json:"sender_keys"`
+	ReceiverKeys []Hex    `json:"receiver_keys"`
+	Transcript   Hex      `json:"transcript"`
+}
+
+type kosVector struct {
+	Curve     string `json:"curve"`
+	SessionId Hex    `json:"session_id"`
+
+	// the base OT, in which the KOS receiver is the sender
+	BaseSenderKeys   [][2]Hex `json:"base_sender_keys"`
+	BaseChoiceBits   Hex      `json:"base_choice_bits"`
+	BaseReceiverKeys []Hex    `json:"base_receiver_keys"`
+	BaseTranscript   Hex      `json:"base_transcript"`
+
+	Choice       Hex      `json:"choice"`
+	Input        [][2]Hex `json:"input"`
+	ReceiverSeed Hex      `json:"receiver_seed"`
+	GammaExt     Hex      `json:"gamma_ext"`
+
+	// messages, in the order they are sent
+	U      []Hex    `json:"u"`
+	WPrime Hex      `json:"w_prime"`
+	VPrime Hex      `json:"v_prime"`
+	Tau    [][2]Hex `json:"tau"`
+
+	// outputs
+	SenderShares   [][2]Hex `json:"sender_shares"`
+	ReceiverShares [][2]Hex `json:"receiver_shares"`
+}
+
+// otCurves are the curves of the OT vectors. KOS only has vectors on the first, as they are large.
+var otCurves = []*curves.Curve{curves.K256(), curves.P256()}
+
+// otBatchSizes are the batch sizes of the simplest OT vectors
+var otBatchSizes = []int{8, 16}
+
+// tape is the randomness of a party, read from SHAKE256 of its seed
+func tape(seed []byte) io.Reader {
+	h := sha3.NewShake256()
+	_, _ = h.Write(seed)
+	return h
+}
+
+func generateOt() (interface{}, error) {
+	vectors := otVectors{Format: otFormat}
+	for _, curve := range otCurves {
+		for _, batchSize := range otBatchSizes {
+			label := fmt.Sprintf("simplest ot %s %d", curve.Name, batchSize)
+			v, err := runSimplestOt(curve, batchSize, seed(label+" session", simplest.DigestSize),
+				seed(label+" sender", 32), seed(label+" receiver", 32))
+			if err != nil {
+				return nil, err
+			}
+			vectors.Simplest = append(vectors.Simplest, *v)
+		}
+	}
+
+	curve := otCurves[0]
+	label := "kos " + curve.Name
+	base := &kosVector{
+		Curve:          curve.Name,
+		SessionId:      seed(label+" session", simplest.DigestSize),
+		BaseChoiceBits: seed(label+" base choice bits", kos.KappaBytes),
+		BaseTranscript: seed(label+" base transcript", simplest.DigestSize),
+		Choice:         seed(label+" choice", kos.COtBlockSizeBytes),
+		ReceiverSeed:   seed(label+" receiver", 32),
+	}
+	keys := seededReader(label + " base keys")
+	for i := 0; i < kos.Kappa; i++ {
+		pair := [2]Hex{make(Hex, simplest.DigestSize), make(Hex, simplest.DigestSize)}
+		for k := range pair {
+			if _, err := io.ReadFull(keys, pair[k]); err != nil {
+				return nil, err
+			}
+		}
+		base.BaseSenderKeys = append(base.BaseSenderKeys, pair)
+		base.BaseReceiverKeys = append(base.BaseReceiverKeys, pair[simplest.ExtractBitFromByteVector(base.BaseChoiceBits, i)])
+	}
+	inputs := seededReader(label + " input")
+	for j := 0; j < kos.L; j++ {
+		base.Input = append(base.Input, [2]Hex{curve.Scalar.Random(inputs).Bytes(), curve.Scalar.Random(inputs).Bytes()})
+	}
+	v, err := runKos(base)
+	if err != nil {
+		return nil, err
+	}
+	vectors.Kos = append(vectors.Kos, *v)
+	return vectors, nil
+}
+
+// runSimplestOt runs the simplest OT on the inputs of a vector, and returns the complete vector
+func runSimplestOt(curve *curves.Curve, batchSize int, sessionId, senderSeed, receiverSeed Hex) (*simplestOtVector, error) {
+	v := &simplestOtVector{
+		Curve:        curve.Name,
+		BatchSize:    batchSize,
+		SessionId:    sessionId,
+		SenderSeed:   senderSeed,
+		ReceiverSeed: receiverSeed,
+	}
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	if len(sessionId) != len(uniqueSessionId) {
+		return nil, fmt.Errorf("session id must be %d bytes", len(uniqueSessionId))
+	}
+	copy(uniqueSessionId[:], sessionId)
+
+	// replay the tapes to record the secrets the parties draw from them
+	senderTape := tape(senderSeed)
+	v.SenderSecretKey = curve.Scalar.Random(senderTape).Bytes()
+	v.ProofNonce = curve.Scalar.Random(senderTape).Bytes()
+	receiverTape := tape(receiverSeed)
+	v.ChoiceBits = make(Hex, batchSize>>3)
+	if _, err := io.ReadFull(receiverTape, v.ChoiceBits); err != nil {
+		return nil, err
+	}
+	for i := 0; i < batchSize; i++ {
+		v.ReceiverSecretKeys = append(v.ReceiverSecretKeys, curve.Scalar.Random(receiverTape).Bytes())
+	}
+
+	sender, err := simplest.NewSenderWithReader(curve, batchSize, uniqueSessionId, tape(senderSeed))
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := simplest.NewReceiverWithReader(curve, batchSize, uniqueSessionId, tape(receiverSeed))
+	if err != nil {
+		return nil, err
+	}
+	proof, err := sender.Round1ComputeAndZkpToPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	v.PublicKey = proof.Statement.ToAffineCompressed()
+	v.ProofC = proof.C.Bytes()
+	v.ProofS = proof.S.Bytes()
+	maskedChoices, err := receiver.Round2VerifySchnorrAndPadTransfer(proof)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range maskedChoices {
+		v.MaskedChoices = append(v.MaskedChoices, Hex(m))
+	}
+	challenges, err := sender.Round3PadTransfer(maskedChoices)
+	if err != nil {
+		return nil, err
+	}
+	for i := range challenges {
+		v.Challenges = append(v.Challenges, challenges[i][:])
+	}
+	responses, err := receiver.Round4RespondToChallenge(challenges)
+	if err != nil {
+		return nil, err
+	}
+	for i := range responses {
+		v.ChallengeResponses = append(v.ChallengeResponses, responses[i][:])
+	}
+	openings, err := sender.Round5Verify(responses)
+	if err != nil {
+		return nil, err
+	}
+	for i := range openings {
+		v.ChallengeOpenings = append(v.ChallengeOpenings, [2]Hex{openings[i][0][:], openings[i][1][:]})
+	}
+	if err = receiver.Round6Verify(openings); err != nil {
+		return nil, err
+	}
+
+	if err = expectEqual("choice bits", v.ChoiceBits, receiver.Output.PackedRandomChoiceBits); err != nil {
+		return nil, err
+	}
+	for i := range sender.Output.OneTimePadEncryptionKeys {
+		keys := &sender.Output.OneTimePadEncryptionKeys[i]
+		v.SenderKeys = append(v.SenderKeys, [2]Hex{keys[0][:], keys[1][:]})
+		v.ReceiverKeys = append(v.ReceiverKeys, receiver.Output.OneTimePadDecryptionKey[i][:])
+	}
+	v.Transcript = receiver.Output.Transcript[:]
+	return v, nil
+}
+
+// runKos runs the KOS cOT extension on the inputs of a vector, and returns the complete vector
+func runKos(in *kosVector) (*kosVector, error) {
+	curve := curves.GetCurveByName(in.Curve)
+	if curve == nil {
+		return nil, fmt.Errorf("unknown curve %s", in.Curve)
+	}
+	if len(in.SessionId) != simplest.DigestSize || len(in.BaseTranscript) != simplest.DigestSize ||
+		len(in.BaseChoiceBits) != kos.KappaBytes || len(in.Choice) != kos.COtBlockSizeBytes ||
+		len(in.BaseSenderKeys) != kos.Kappa || len(in.BaseReceiverKeys) != kos.Kappa || len(in.Input) != kos.L {
+		return nil, fmt.Errorf("malformed inputs")
+	}
+	v := &kosVector{
+		Curve:            in.Curve,
+		SessionId:        in.SessionId,
+		BaseSenderKeys:   in.BaseSenderKeys,
+		BaseChoiceBits:   in.BaseChoiceBits,
+		BaseReceiverKeys: in.BaseReceiverKeys,
+		BaseTranscript:   in.BaseTranscript,
+		Choice:           in.Choice,
+		Input:            in.Input,
+		ReceiverSeed:     in.ReceiverSeed,
+		GammaExt:         make(Hex, (kos.Kappa+80)>>3), // kappa + s bits
+	}
+	if _, err := io.ReadFull(tape(in.ReceiverSeed), v.GammaExt); err != nil {
+		return nil, err
+	}
+
+	baseSender := &simplest.SenderOutput{OneTimePadEncryptionKeys: make([]simplest.OneTimePadEncryptionKeys, kos.Kappa)}
+	baseReceiver := &simplest.ReceiverOutput{
+		PackedRandomChoiceBits:  in.BaseChoiceBits,
+		RandomChoiceBits:        make([]int, kos.Kappa),
+		OneTimePadDecryptionKey: make([]simplest.OneTimePadDecryptionKey, kos.Kappa),
+	}
+	copy(baseSender.Transcript[:], in.BaseTranscript)
+	copy(baseReceiver.Transcript[:], in.BaseTranscript)
+	for i := 0; i < kos.Kappa; i++ {
+		copy(baseSender.OneTimePadEncryptionKeys[i][0][:], in.BaseSenderKeys[i][0])
+		copy(baseSender.OneTimePadEncryptionKeys[i][1][:], in.BaseSenderKeys[i][1])
+		baseReceiver.RandomChoiceBits[i] = int(simplest.ExtractBitFromByteVector(in.BaseChoiceBits, i))
+		copy(baseReceiver.OneTimePadDecryptionKey[i][:], in.BaseReceiverKeys[i])
+	}
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	copy(uniqueSessionId[:], in.SessionId)
+	choice := [kos.COtBlockSizeBytes]byte{}
+	copy(choice[:], in.Choice)
+	input := [kos.L][kos.OtWidth]curves.Scalar{}
+	for j := range input {
+		for k := range input[j] {
+			var err error
+			if input[j][k], err = curve.Scalar.SetBytes(in.Input[j][k]); err != nil {
+				return nil, fmt.Errorf("input %d: %v", j, err)
+			}
+		}
+	}
+
+	receiver := kos.NewCOtReceiverWithReader(baseSender, curve, tape(in.ReceiverSeed))
+	sender := kos.NewCOtSender(baseReceiver, curve)
+	round1, err := receiver.Round1Initialize(uniqueSessionId, choice)
+	if err != nil {
+		return nil, err
+	}
+	for i := range round1.U {
+		v.U = append(v.U, round1.U[i][:])
+	}
+	v.WPrime = round1.WPrime[:]
+	v.VPrime = round1.VPrime[:]
+	round2, err := sender.Round2Transfer(uniqueSessionId, input, round1)
+	if err != nil {
+		return nil, err
+	}
+	if err = receiver.Round3Transfer(round2); err != nil {
+		return nil, err
+	}
+	for j := 0; j < kos.L; j++ {
+		v.Tau = append(v.Tau, [2]Hex{round2.Tau[j][0].Bytes(), round2.Tau[j][1].Bytes()})
+		v.SenderShares = append(v.SenderShares, [2]Hex{
+			sender.OutputAdditiveShares[j][0].Bytes(), sender.OutputAdditiveShares[j][1].Bytes(),
+		})
+		v.ReceiverShares = append(v.ReceiverShares, [2]Hex{
+			receiver.OutputAdditiveShares[j][0].Bytes(), receiver.OutputAdditiveShares[j][1].Bytes(),
+		})
+	}
+	return v, nil
+}
+
+func verifyOt(raw json.RawMessage) error {
+	var vectors otVectors
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		return err
+	}
+	if vectors.Format != otFormat {
+		return fmt.Errorf("unsupported format %d", vectors.Format)
+	}
+	for i, v := range vectors.Simplest {
+		if err := verifySimplestOtVector(v); err != nil {
+			return fmt.Errorf("simplest vector %d: %v", i, err)
+		}
+	}
+	for i, v := range vectors.Kos {
+		if err := verifyKosVector(v); err != nil {
+			return fmt.Errorf("kos vector %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func verifySimplestOtVector(v simplestOtVector) error {
+	curve := curves.GetCurveByName(v.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve %s", v.Curve)
+	}
+	actual, err := runSimplestOt(curve, v.BatchSize, v.SessionId, v.SenderSeed, v.ReceiverSeed)
+	if err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name             string
+		expected, actual []Hex
+	}{
+		{"sender secret key", []Hex{v.SenderSecretKey}, []Hex{actual.SenderSecretKey}},
+		{"proof nonce", []Hex{v.ProofNonce}, []Hex{actual.ProofNonce}},
+		{"choice bits", []Hex{v.ChoiceBits}, []Hex{actual.ChoiceBits}},
+		{"receiver secret key", v.ReceiverSecretKeys, actual.ReceiverSecretKeys},
+		{"public key", []Hex{v.PublicKey}, []Hex{actual.PublicKey}},
+		{"proof", []Hex{v.ProofC, v.ProofS}, []Hex{actual.ProofC, actual.ProofS}},
+		{"masked choice", v.MaskedChoices, actual.MaskedChoices},
+		{"challenge", v.Challenges, actual.Challenges},
+		{"challenge response", v.ChallengeResponses, actual.ChallengeResponses},
+		{"challenge opening", flattenPairs(v.ChallengeOpenings), flattenPairs(actual.ChallengeOpenings)},
+		{"sender key", flattenPairs(v.SenderKeys), flattenPairs(actual.SenderKeys)},
+		{"receiver key", v.ReceiverKeys, actual.ReceiverKeys},
+		{"transcript", []Hex{v.Transcript}, []Hex{actual.Transcript}},
+	} {
+		if err = expectEqualList(f.name, f.expected, f.actual); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyKosVector(v kosVector) error {
+	actual, err := runKos(&v)
+	if err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name             string
+		expected, actual []Hex
+	}{
+		{"gamma ext", []Hex{v.GammaExt}, []Hex{actual.GammaExt}},
+		{"u", v.U, actual.U},
+		{"w prime", []Hex{v.WPrime}, []Hex{actual.WPrime}},
+		{"v prime", []Hex{v.VPrime}, []Hex{actual.VPrime}},
+		{"tau", flattenPairs(v.Tau), flattenPairs(actual.Tau)},
+		{"sender share", flattenPairs(v.SenderShares), flattenPairs(actual.SenderShares)},
+		{"receiver share", flattenPairs(v.ReceiverShares), flattenPairs(actual.ReceiverShares)},
+	} {
+		if err = expectEqualList(f.name, f.expected, f.actual); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expectEqualList returns an error if the generated values differ from the vector
+func expectEqualList(name string, expected, actual []Hex) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("%s count mismatch: expected %d, got %d", name, len(expected), len(actual))
+	}
+	for i := range expected {
+		if err := expectEqual(fmt.Sprintf("%s %d", name, i), expected[i], actual[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flattenPairs(pairs [][2]Hex) []Hex {
+	result := make([]Hex, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		result = append(result, pair[:]...)
+	}
+	return result
+}
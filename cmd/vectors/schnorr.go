@@ -0,0 +1,213 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/schnorr/mina"
+	"github.com/etclab/kryptology/pkg/signatures/schnorr/nem"
+	"github.com/etclab/kryptology/pkg/zkp/schnorr"
+)
+
+var schnorrModule = module{
+	name: "schnorr",
+	description: "Schnorr signatures and proofs. mina are Mina signatures of messages on Pallas from " +
+		"pkg/signatures/schnorr/mina for the main network. nem are Ed25519 signatures with Keccak-512 from " +
+		"pkg/signatures/schnorr/nem. zkp are the randomized proofs of knowledge of a discrete logarithm with respect " +
+		"to the generator from pkg/zkp/schnorr, which are only to be verified; scalars are in the encoding of the " +
+		"curves package and the statement is a compressed point.",
+	generate: generateSchnorr,
+	verify:   verifySchnorr,
+}
+
+type schnorrVectors struct {
+	Mina []minaVector `json:"mina"`
+	Nem  []nemVector  `json:"nem"`
+	Zkp  []zkpVector  `json:"zkp"`
+}
+
+type minaVector struct {
+	SecretKey Hex    `json:"secret_key"`
+	PublicKey Hex    `json:"public_key"`
+	Message   string `json:"message"`
+	Signature Hex    `json:"signature"`
+}
+
+type nemVector struct {
+	Seed      Hex `json:"seed"`
+	PublicKey Hex `json:"public_key"`
+	Message   Hex `json:"message"`
+	Signature Hex `json:"signature"`
+}
+
+type zkpVector struct {
+	Curve     string `json:"curve"`
+	SessionId Hex    `json:"session_id"`
+	Statement Hex    `json:"statement"`
+	C         Hex    `json:"c"`
+	S         Hex    `json:"s"`
+}
+
+// zkpCurves are the curves of the proofs of knowledge
+var zkpCurves = []*curves.Curve{curves.K256(), curves.P256()}
+
+func generateSchnorr() (interface{}, error) {
+	var vectors schnorrVectors
+
+	pk, sk, err := mina.NewKeysFromReader(seededReader("mina secret key"))
+	if err != nil {
+		return nil, err
+	}
+	skBytes, err := sk.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pkBytes, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range messages {
+		sig, err := sk.SignMessage(string(msg))
+		if err != nil {
+			return nil, err
+		}
+		sigBytes, err := sig.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		vectors.Mina = append(vectors.Mina, minaVector{skBytes, pkBytes, string(msg), sigBytes})
+	}
+
+	nemSeed := seed("nem seed", nem.SeedSize)
+	priv, err := nem.NewKeyFromSeed(nemSeed)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range messages {
+		sig, err := nem.Sign(priv, msg)
+		if err != nil {
+			return nil, err
+		}
+		vectors.Nem = append(vectors.Nem, nemVector{nemSeed, Hex(priv.Public().(nem.PublicKey)), msg, sig})
+	}
+
+	for _, curve := range zkpCurves {
+		label := "zkp schnorr " + curve.Name
+		sessionId := seed(label+" session", 32)
+		x := curve.Scalar.Hash(seed(label+" witness", 32))
+		proof, err := schnorr.NewProver(curve, nil, sessionId).Prove(x)
+		if err != nil {
+			return nil, err
+		}
+		vectors.Zkp = append(vectors.Zkp, zkpVector{
+			Curve:     curve.Name,
+			SessionId: sessionId,
+			Statement: proof.Statement.ToAffineCompressed(),
+			C:         proof.C.Bytes(),
+			S:         proof.S.Bytes(),
+		})
+	}
+	return vectors, nil
+}
+
+func verifySchnorr(raw json.RawMessage) error {
+	var vectors schnorrVectors
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		return err
+	}
+	for i, v := range vectors.Mina {
+		if err := verifyMinaVector(v); err != nil {
+			return fmt.Errorf("mina vector %d: %v", i, err)
+		}
+	}
+	for i, v := range vectors.Nem {
+		if err := verifyNemVector(v); err != nil {
+			return fmt.Errorf("nem vector %d: %v", i, err)
+		}
+	}
+	for i, v := range vectors.Zkp {
+		if err := verifyZkpVector(v); err != nil {
+			return fmt.Errorf("zkp vector %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func verifyMinaVector(v minaVector) error {
+	sk := new(mina.SecretKey)
+	if err := sk.UnmarshalBinary(v.SecretKey); err != nil {
+		return err
+	}
+	pkBytes, err := sk.GetPublicKey().MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("public key", v.PublicKey, pkBytes); err != nil {
+		return err
+	}
+	sig, err := sk.SignMessage(v.Message)
+	if err != nil {
+		return err
+	}
+	sigBytes, err := sig.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("signature", v.Signature, sigBytes); err != nil {
+		return err
+	}
+	pk, sig := new(mina.PublicKey), new(mina.Signature)
+	if err = pk.UnmarshalBinary(v.PublicKey); err != nil {
+		return err
+	}
+	if err = sig.UnmarshalBinary(v.Signature); err != nil {
+		return err
+	}
+	return pk.VerifyMessage(sig, v.Message)
+}
+
+func verifyNemVector(v nemVector) error {
+	priv, err := nem.NewKeyFromSeed(v.Seed)
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("public key", v.PublicKey, priv.Public().(nem.PublicKey)); err != nil {
+		return err
+	}
+	sig, err := nem.Sign(priv, v.Message)
+	if err != nil {
+		return err
+	}
+	if err = expectEqual("signature", v.Signature, sig); err != nil {
+		return err
+	}
+	ok, err := nem.Verify(nem.PublicKey(v.PublicKey), v.Message, v.Signature)
+	return expectValid("signature", ok, err)
+}
+
+func verifyZkpVector(v zkpVector) error {
+	curve := curves.GetCurveByName(v.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve %s", v.Curve)
+	}
+	statement, err := curve.Point.FromAffineCompressed(v.Statement)
+	if err != nil {
+		return err
+	}
+	c, err := curve.Scalar.SetBytes(v.C)
+	if err != nil {
+		return err
+	}
+	s, err := curve.Scalar.SetBytes(v.S)
+	if err != nil {
+		return err
+	}
+	return schnorr.Verify(&schnorr.Proof{C: c, S: s, Statement: statement}, curve, nil, v.SessionId)
+}
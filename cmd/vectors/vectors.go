@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Hex is a byte string that is hex encoded in the vector files
+type Hex []byte
+
+// MarshalText encodes the bytes in lowercase hex
+func (h Hex) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(h)), nil
+}
+
+// UnmarshalText decodes hex encoded bytes
+func (h *Hex) UnmarshalText(text []byte) error {
+	out, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*h = out
+	return nil
+}
+
+// messages are signed by every scheme
+var messages = []Hex{
+	{0x00},
+	Hex("abc"),
+	Hex("kryptology test vector message"),
+	bytes.Repeat([]byte{0x5a}, 200),
+}
+
+// seededReader returns a deterministic stream of bytes for the label, so the inputs of the
+// vectors and the randomness of the randomized provers are reproducible
+func seededReader(label string) io.Reader {
+	h := sha3.NewShake256()
+	_, _ = h.Write([]byte("kryptology test vectors " + label))
+	return h
+}
+
+// seed returns n bytes of the deterministic stream of the label
+func seed(label string, n int) Hex {
+	out := make([]byte, n)
+	_, _ = io.ReadFull(seededReader(label), out)
+	return out
+}
+
+// expectEqual returns an error if the generated value differs from the vector
+func expectEqual(name string, expected, actual []byte) error {
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf("%s mismatch: expected %x, got %x", name, expected, actual)
+	}
+	return nil
+}
+
+// expectValid returns an error unless the verification succeeded
+func expectValid(name string, ok bool, err error) error {
+	if err != nil {
+		return fmt.Errorf("%s verification failed: %v", name, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s is invalid", name)
+	}
+	return nil
+}
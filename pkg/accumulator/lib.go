@@ -53,12 +53,14 @@ func (p polynomialPoint) evaluate(x curves.Scalar) (curves.Point, error) {
 		}
 	}
 
-	pp := x
-	res := p[0]
+	powers := make([]curves.Scalar, len(p))
+	powers[0] = x.One()
 	for i := 1; i < len(p); i++ {
-		r := p[i].Mul(pp)
-		res = res.Add(r)
-		pp = pp.Mul(x)
+		powers[i] = powers[i-1].Mul(x)
+	}
+	res := p[0].SumOfProducts(p, powers)
+	if res == nil {
+		return nil, fmt.Errorf("invalid coefficients or input")
 	}
 	return res, nil
 }
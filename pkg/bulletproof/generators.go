@@ -55,3 +55,23 @@ func getGeneratorPoints(lenVector int, domain []byte, curve curves.Curve) (*ippG
 
 	return &out, nil
 }
+
+// NewRangeProofGenerators derives the g, h and u generators of a range proof from `domain`
+// with curves.DeriveGenerators, so integrators can audit that nobody knows their discrete logs
+func NewRangeProofGenerators(domain []byte, curve curves.Curve) (*RangeProofGenerators, error) {
+	points, err := curves.DeriveGenerators(&curve, domain, 3)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRangeProofGenerators")
+	}
+	return &RangeProofGenerators{g: points[0], h: points[1], u: points[2]}, nil
+}
+
+// Commit returns the commitment g * v + h * gamma to v, which is the capV of range proofs
+func (g RangeProofGenerators) Commit(v, gamma curves.Scalar) curves.Point {
+	return getcapV(v, gamma, g.g, g.h)
+}
+
+// Verify checks that the generators were derived from `domain` by NewRangeProofGenerators
+func (g RangeProofGenerators) Verify(domain []byte, curve curves.Curve) error {
+	return curves.VerifyGenerators(&curve, domain, []curves.Point{g.g, g.h, g.u})
+}
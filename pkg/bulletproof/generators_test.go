@@ -30,6 +30,21 @@ func TestGeneratorsUniquePerDomain(t *testing.T) {
 	require.True(t, areDisjoint(gs1Concatenated, gs2Concatenated))
 }
 
+func TestRangeProofGeneratorsDerivation(t *testing.T) {
+	curve := curves.ED25519()
+	gens, err := NewRangeProofGenerators([]byte("test"), *curve)
+	require.NoError(t, err)
+	require.True(t, noDuplicates(generators{gens.g, gens.h, gens.u}))
+	require.NoError(t, gens.Verify([]byte("test"), *curve))
+	require.Error(t, gens.Verify([]byte("test2"), *curve))
+
+	// Swapped or replaced generators are detected
+	swapped := RangeProofGenerators{g: gens.h, h: gens.g, u: gens.u}
+	require.Error(t, swapped.Verify([]byte("test"), *curve))
+	replaced := RangeProofGenerators{g: gens.g, h: curve.Point.Generator(), u: gens.u}
+	require.Error(t, replaced.Verify([]byte("test"), *curve))
+}
+
 func noDuplicates(gs generators) bool {
 	seen := map[[32]byte]bool{}
 	for _, G := range gs {
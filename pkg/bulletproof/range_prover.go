@@ -396,7 +396,8 @@ func getcapV(v, gamma curves.Scalar, g, h curves.Point) curves.Point {
 func getaL(v curves.Scalar, n int, curve curves.Curve) ([]curves.Scalar, error) {
 	var err error
 
-	vBytes := v.Bytes()
+	// Scalar.Bytes is big-endian on some curves, so the bits are read from the integer value
+	vInt := v.BigInt()
 	zero := curve.Scalar.Zero()
 	one := curve.Scalar.One()
 	aL := make([]curves.Scalar, n)
@@ -404,7 +405,7 @@ func getaL(v curves.Scalar, n int, curve curves.Curve) ([]curves.Scalar, error)
 		aL[j] = zero
 	}
 	for i := 0; i < n; i++ {
-		ithBit := vBytes[i>>3] >> (i & 0x07) & 0x01
+		ithBit := vInt.Bit(i)
 		aL[i], err = cmoveScalar(zero, one, int(ithBit), curve)
 		if err != nil {
 			return nil, errors.Wrap(err, "getaL")
@@ -85,3 +85,24 @@ func TestRangeVerifyNonRandom(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, verified)
 }
+
+func TestRangeVerifyBigEndianCurves(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.BLS12381G1(), curves.K256()} {
+		n := 64
+		prover, err := NewRangeProver(n, []byte("rangeDomain"), []byte("ippDomain"), *curve)
+		require.NoError(t, err)
+		v := curve.Scalar.New(19900515)
+		gamma := curve.Scalar.Random(crand.Reader)
+		proofGenerators, err := NewRangeProofGenerators([]byte("generators"), *curve)
+		require.NoError(t, err)
+		proof, err := prover.Prove(v, gamma, n, *proofGenerators, merlin.NewTranscript("test"))
+		require.NoError(t, err)
+
+		verifier, err := NewRangeVerifier(n, []byte("rangeDomain"), []byte("ippDomain"), *curve)
+		require.NoError(t, err)
+		capV := proofGenerators.Commit(v, gamma)
+		verified, err := verifier.Verify(proof, capV, *proofGenerators, n, merlin.NewTranscript("test"))
+		require.NoError(t, err, curve.Name)
+		require.True(t, verified, curve.Name)
+	}
+}
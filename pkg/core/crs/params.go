@@ -0,0 +1,459 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package crs manages common public parameters such as Pedersen generators,
+// structured reference strings or accumulator parameters. Parameters carry a
+// content digest, so deployments can check every party uses the same values,
+// and provenance metadata describing how they were produced.
+package crs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+const (
+	// MethodHashToCurve denotes points derived by hashing the seed, scheme, entry name and index to the curve.
+	// Parameters produced this way can be regenerated with VerifyProvenance
+	MethodHashToCurve = "hash-to-curve"
+
+	// MethodImported denotes parameters produced outside this package, for example by a setup ceremony
+	MethodImported = "imported"
+
+	// encodingVersion is the first byte of the binary encoding
+	encodingVersion = 1
+
+	// digestDomain separates parameter digests from other uses of SHA-256
+	digestDomain = "kryptology crs params v1"
+)
+
+const (
+	kindPoints byte = iota + 1
+	kindBytes
+)
+
+var (
+	// ErrDigestMismatch is returned when parameters do not match any of the known-good digests
+	ErrDigestMismatch = fmt.Errorf("parameter digest does not match")
+	// ErrUnknownEntry is returned when a named entry does not exist or has a different type
+	ErrUnknownEntry = fmt.Errorf("unknown parameter entry")
+)
+
+// Provenance describes how parameters were produced
+type Provenance struct {
+	// Method is how the parameters were produced, for example MethodHashToCurve
+	Method string
+	// Seed is the public input to the generation method
+	Seed []byte
+	// Source identifies who produced the parameters, for example a ceremony transcript url
+	Source string
+	// Attributes contains any other information worth recording
+	Attributes map[string]string
+}
+
+// entry is a named list of points or a byte string
+type entry struct {
+	name   string
+	kind   byte
+	points []curves.Point
+	value  []byte
+}
+
+// Params is a named set of public parameters for `Scheme` over `Curve`.
+// The digest covers the scheme, curve and entries but not the provenance
+// so the same parameters have the same digest regardless of who produced them
+type Params struct {
+	Scheme     string
+	Curve      *curves.Curve
+	Provenance Provenance
+	entries    []*entry
+}
+
+// New creates empty parameters for `scheme` over `curve`
+func New(scheme string, curve *curves.Curve) (*Params, error) {
+	if scheme == "" {
+		return nil, fmt.Errorf("scheme cannot be empty")
+	}
+	if curve == nil || curves.GetCurveByName(curve.Name) == nil {
+		return nil, fmt.Errorf("unsupported curve")
+	}
+	return &Params{
+		Scheme:     scheme,
+		Curve:      curve,
+		Provenance: Provenance{Method: MethodImported},
+	}, nil
+}
+
+// HashToCurve creates parameters with one entry per name in `names`, each holding `count` points
+// obtained by hashing `seed`, `scheme`, the entry name and the point index to `curve`.
+// Nobody knows the discrete logarithms between the resulting points
+func HashToCurve(scheme string, curve *curves.Curve, seed []byte, count int, names ...string) (*Params, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("count must be positive")
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("at least one entry name is required")
+	}
+	p, err := New(scheme, curve)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err = p.AddPoints(name, hashPoints(scheme, curve, seed, name, count)...); err != nil {
+			return nil, err
+		}
+	}
+	p.Provenance = Provenance{
+		Method: MethodHashToCurve,
+		Seed:   append([]byte{}, seed...),
+	}
+	return p, nil
+}
+
+// hashPoints derives `count` points for entry `name`
+func hashPoints(scheme string, curve *curves.Curve, seed []byte, name string, count int) []curves.Point {
+	prefix := appendBytes(nil, []byte(digestDomain))
+	prefix = appendBytes(prefix, []byte(scheme))
+	prefix = appendBytes(prefix, []byte(curve.Name))
+	prefix = appendBytes(prefix, seed)
+	prefix = appendBytes(prefix, []byte(name))
+	points := make([]curves.Point, count)
+	for i := range points {
+		points[i] = curve.Point.Hash(appendUint32(append([]byte{}, prefix...), uint32(i)))
+	}
+	return points
+}
+
+// AddPoints adds a named list of points
+func (p *Params) AddPoints(name string, points ...curves.Point) error {
+	if err := p.checkName(name); err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return fmt.Errorf("at least one point is required")
+	}
+	pts := make([]curves.Point, len(points))
+	for i, pt := range points {
+		if pt == nil || pt.CurveName() != p.Curve.Name {
+			return fmt.Errorf("point %d of %s is not on %s", i, name, p.Curve.Name)
+		}
+		if pt.IsIdentity() {
+			return fmt.Errorf("point %d of %s is the identity", i, name)
+		}
+		pts[i] = pt
+	}
+	p.entries = append(p.entries, &entry{name: name, kind: kindPoints, points: pts})
+	return nil
+}
+
+// AddBytes adds a named byte string such as an encoded modulus
+func (p *Params) AddBytes(name string, value []byte) error {
+	if err := p.checkName(name); err != nil {
+		return err
+	}
+	p.entries = append(p.entries, &entry{name: name, kind: kindBytes, value: append([]byte{}, value...)})
+	return nil
+}
+
+func (p *Params) checkName(name string) error {
+	if name == "" {
+		return fmt.Errorf("entry name cannot be empty")
+	}
+	if p.find(name) != nil {
+		return fmt.Errorf("entry %s already exists", name)
+	}
+	return nil
+}
+
+func (p Params) find(name string) *entry {
+	for _, e := range p.entries {
+		if e.name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Names returns the entry names in the order they were added
+func (p Params) Names() []string {
+	names := make([]string, len(p.entries))
+	for i, e := range p.entries {
+		names[i] = e.name
+	}
+	return names
+}
+
+// Points returns a copy of the named list of points
+func (p Params) Points(name string) ([]curves.Point, error) {
+	e := p.find(name)
+	if e == nil || e.kind != kindPoints {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEntry, name)
+	}
+	return append([]curves.Point{}, e.points...), nil
+}
+
+// Bytes returns a copy of the named byte string
+func (p Params) Bytes(name string) ([]byte, error) {
+	e := p.find(name)
+	if e == nil || e.kind != kindBytes {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEntry, name)
+	}
+	return append([]byte{}, e.value...), nil
+}
+
+// Digest returns the SHA-256 digest of the scheme, curve and entries
+func (p Params) Digest() []byte {
+	h := sha256.New()
+	_, _ = h.Write(appendBytes(nil, []byte(digestDomain)))
+	_, _ = h.Write(p.encodeContent(nil))
+	return h.Sum(nil)
+}
+
+// DigestHex returns the hex encoded digest for use in configuration files
+func (p Params) DigestHex() string {
+	return hex.EncodeToString(p.Digest())
+}
+
+// VerifyDigest succeeds if the parameters match one of the `known` digests
+func (p Params) VerifyDigest(known ...[]byte) error {
+	d := p.Digest()
+	for _, k := range known {
+		if subtle.ConstantTimeCompare(d, k) == 1 {
+			return nil
+		}
+	}
+	return ErrDigestMismatch
+}
+
+// VerifyDigestHex is VerifyDigest for hex encoded digests
+func (p Params) VerifyDigestHex(known ...string) error {
+	digests := make([][]byte, 0, len(known))
+	for _, k := range known {
+		d, err := hex.DecodeString(k)
+		if err != nil {
+			return err
+		}
+		digests = append(digests, d)
+	}
+	return p.VerifyDigest(digests...)
+}
+
+// VerifyProvenance regenerates parameters produced with MethodHashToCurve from
+// their seed and checks they are identical. Other methods cannot be checked
+func (p Params) VerifyProvenance() error {
+	if p.Provenance.Method != MethodHashToCurve {
+		return fmt.Errorf("provenance method %q cannot be verified", p.Provenance.Method)
+	}
+	if len(p.entries) == 0 {
+		return fmt.Errorf("parameters are empty")
+	}
+	for _, e := range p.entries {
+		if e.kind != kindPoints {
+			return fmt.Errorf("entry %s is not derived from the seed", e.name)
+		}
+		expected := hashPoints(p.Scheme, p.Curve, p.Provenance.Seed, e.name, len(e.points))
+		for i, pt := range e.points {
+			if !pt.Equal(expected[i]) {
+				return fmt.Errorf("point %d of %s does not match the seed", i, e.name)
+			}
+		}
+	}
+	return nil
+}
+
+// encodeContent appends scheme || curve || count || (name || kind || data)*
+// where point entries are encoded as count || compressed point*
+func (p Params) encodeContent(out []byte) []byte {
+	out = appendBytes(out, []byte(p.Scheme))
+	out = appendBytes(out, []byte(p.Curve.Name))
+	out = appendUint32(out, uint32(len(p.entries)))
+	for _, e := range p.entries {
+		out = appendBytes(out, []byte(e.name))
+		out = append(out, e.kind)
+		switch e.kind {
+		case kindPoints:
+			out = appendUint32(out, uint32(len(e.points)))
+			for _, pt := range e.points {
+				out = appendBytes(out, pt.ToAffineCompressed())
+			}
+		case kindBytes:
+			out = appendBytes(out, e.value)
+		}
+	}
+	return out
+}
+
+// MarshalBinary encodes the parameters followed by their provenance
+func (p Params) MarshalBinary() ([]byte, error) {
+	if p.Curve == nil {
+		return nil, fmt.Errorf("parameters are not initialized")
+	}
+	out := p.encodeContent([]byte{encodingVersion})
+	out = appendBytes(out, []byte(p.Provenance.Method))
+	out = appendBytes(out, p.Provenance.Seed)
+	out = appendBytes(out, []byte(p.Provenance.Source))
+	keys := make([]string, 0, len(p.Provenance.Attributes))
+	for k := range p.Provenance.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out = appendUint32(out, uint32(len(keys)))
+	for _, k := range keys {
+		out = appendBytes(out, []byte(k))
+		out = appendBytes(out, []byte(p.Provenance.Attributes[k]))
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes the output of MarshalBinary
+func (p *Params) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	version, err := r.ReadByte()
+	if err != nil || version != encodingVersion {
+		return fmt.Errorf("unsupported encoding")
+	}
+	scheme, err := readBytes(r)
+	if err != nil {
+		return err
+	}
+	curveName, err := readBytes(r)
+	if err != nil {
+		return err
+	}
+	curve := curves.GetCurveByName(string(curveName))
+	if curve == nil || curve.Name != string(curveName) {
+		return fmt.Errorf("unsupported curve %s", curveName)
+	}
+	out, err := New(string(scheme), curve)
+	if err != nil {
+		return err
+	}
+	count, err := readUint32(r)
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < count; i++ {
+		name, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		kind, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case kindPoints:
+			n, err := readUint32(r)
+			if err != nil {
+				return err
+			}
+			if int64(n) > int64(r.Len()) {
+				return fmt.Errorf("invalid byte sequence")
+			}
+			points := make([]curves.Point, n)
+			for j := range points {
+				b, err := readBytes(r)
+				if err != nil {
+					return err
+				}
+				if points[j], err = curve.Point.FromAffineCompressed(b); err != nil {
+					return err
+				}
+			}
+			err = out.AddPoints(string(name), points...)
+		case kindBytes:
+			var b []byte
+			if b, err = readBytes(r); err == nil {
+				err = out.AddBytes(string(name), b)
+			}
+		default:
+			err = fmt.Errorf("unknown entry kind %d", kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	method, err := readBytes(r)
+	if err != nil {
+		return err
+	}
+	seed, err := readBytes(r)
+	if err != nil {
+		return err
+	}
+	source, err := readBytes(r)
+	if err != nil {
+		return err
+	}
+	out.Provenance = Provenance{Method: string(method), Seed: seed, Source: string(source)}
+	if count, err = readUint32(r); err != nil {
+		return err
+	}
+	if count > 0 {
+		out.Provenance.Attributes = make(map[string]string, count)
+	}
+	prev := ""
+	for i := uint32(0); i < count; i++ {
+		k, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		v, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		if i > 0 && string(k) <= prev {
+			return fmt.Errorf("attributes are not in canonical order")
+		}
+		prev = string(k)
+		out.Provenance.Attributes[prev] = string(v)
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	*p = *out
+	return nil
+}
+
+func appendUint32(out []byte, v uint32) []byte {
+	var t [4]byte
+	binary.BigEndian.PutUint32(t[:], v)
+	return append(out, t[:]...)
+}
+
+func appendBytes(out, data []byte) []byte {
+	out = appendUint32(out, uint32(len(data)))
+	return append(out, data...)
+}
+
+func readUint32(r *bytes.Reader) (uint32, error) {
+	var t [4]byte
+	if n, _ := r.Read(t[:]); n != len(t) {
+		return 0, fmt.Errorf("invalid byte sequence")
+	}
+	return binary.BigEndian.Uint32(t[:]), nil
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	l, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	if int64(l) > int64(r.Len()) {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	b := make([]byte, l)
+	_, _ = r.Read(b)
+	return b, nil
+}
@@ -0,0 +1,125 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package crs
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestHashToCurveParams(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256(), curves.ED25519(), curves.BLS12381G1(), curves.PALLAS(), curves.VESTA()} {
+		p, err := HashToCurve("pedersen", curve, []byte("seed"), 4, "g", "h")
+		require.NoError(t, err)
+		require.Equal(t, []string{"g", "h"}, p.Names())
+		require.NoError(t, p.VerifyProvenance())
+
+		g, err := p.Points("g")
+		require.NoError(t, err)
+		require.Len(t, g, 4)
+		h, err := p.Points("h")
+		require.NoError(t, err)
+		require.False(t, g[0].Equal(h[0]))
+		require.False(t, g[0].Equal(g[1]))
+
+		// Generation is deterministic
+		q, err := HashToCurve("pedersen", curve, []byte("seed"), 4, "g", "h")
+		require.NoError(t, err)
+		require.Equal(t, p.Digest(), q.Digest())
+		require.NoError(t, q.VerifyDigestHex(p.DigestHex()))
+
+		// Any change in the inputs changes the digest
+		for _, other := range []func() (*Params, error){
+			func() (*Params, error) { return HashToCurve("pedersen", curve, []byte("seed2"), 4, "g", "h") },
+			func() (*Params, error) { return HashToCurve("pedersen2", curve, []byte("seed"), 4, "g", "h") },
+			func() (*Params, error) { return HashToCurve("pedersen", curve, []byte("seed"), 3, "g", "h") },
+			func() (*Params, error) { return HashToCurve("pedersen", curve, []byte("seed"), 4, "h", "g") },
+		} {
+			o, err := other()
+			require.NoError(t, err)
+			require.ErrorIs(t, o.VerifyDigest(p.Digest()), ErrDigestMismatch)
+		}
+	}
+}
+
+func TestParamsDigestIgnoresProvenance(t *testing.T) {
+	curve := curves.K256()
+	p, err := HashToCurve("pedersen", curve, []byte("seed"), 2, "g")
+	require.NoError(t, err)
+	points, err := p.Points("g")
+	require.NoError(t, err)
+
+	imported, err := New("pedersen", curve)
+	require.NoError(t, err)
+	require.NoError(t, imported.AddPoints("g", points...))
+	imported.Provenance.Source = "ceremony"
+	require.NoError(t, imported.VerifyDigest(p.Digest()))
+	require.Error(t, imported.VerifyProvenance())
+
+	// Provenance that does not match the points is detected
+	p.Provenance.Seed = []byte("other")
+	require.Error(t, p.VerifyProvenance())
+}
+
+func TestParamsMarshalBinary(t *testing.T) {
+	curve := curves.BLS12381G1()
+	p, err := HashToCurve("accumulator", curve, []byte("seed"), 3, "g")
+	require.NoError(t, err)
+	require.NoError(t, p.AddBytes("modulus", []byte{1, 2, 3}))
+	p.Provenance.Source = "https://example.com/ceremony"
+	p.Provenance.Attributes = map[string]string{"participants": "5", "date": "2026-10-17"}
+
+	data, err := p.MarshalBinary()
+	require.NoError(t, err)
+	q := new(Params)
+	require.NoError(t, q.UnmarshalBinary(data))
+	require.Equal(t, p.Digest(), q.Digest())
+	require.Equal(t, p.Scheme, q.Scheme)
+	require.Equal(t, p.Curve.Name, q.Curve.Name)
+	require.Equal(t, p.Provenance, q.Provenance)
+	modulus, err := q.Bytes("modulus")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, modulus)
+	_, err = q.Bytes("g")
+	require.ErrorIs(t, err, ErrUnknownEntry)
+	_, err = q.Points("modulus")
+	require.ErrorIs(t, err, ErrUnknownEntry)
+
+	again, err := q.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+
+	for i := range data {
+		require.Error(t, new(Params).UnmarshalBinary(data[:i]))
+	}
+	require.Error(t, new(Params).UnmarshalBinary(append(data, 0)))
+}
+
+func TestParamsInvalid(t *testing.T) {
+	_, err := New("", curves.K256())
+	require.Error(t, err)
+	_, err = New("pedersen", nil)
+	require.Error(t, err)
+	_, err = HashToCurve("pedersen", curves.K256(), nil, 0, "g")
+	require.Error(t, err)
+	_, err = HashToCurve("pedersen", curves.K256(), nil, 1)
+	require.Error(t, err)
+
+	p, err := New("pedersen", curves.K256())
+	require.NoError(t, err)
+	require.Error(t, p.AddPoints("g"))
+	require.Error(t, p.AddPoints("g", curves.P256().Point.Random(crand.Reader)))
+	require.Error(t, p.AddPoints("g", curves.K256().Point.Identity()))
+	require.Error(t, p.AddBytes("", nil))
+	require.NoError(t, p.AddBytes("n", nil))
+	require.Error(t, p.AddBytes("n", nil))
+	require.Error(t, p.VerifyDigestHex("zz"))
+}
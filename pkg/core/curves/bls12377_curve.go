@@ -22,6 +22,12 @@ import (
 	"github.com/etclab/kryptology/pkg/core"
 )
 
+func init() {
+	mustRegisterCurve(BLS12377G1Name, nil, BLS12377G1)
+	mustRegisterCurve(BLS12377G2Name, nil, BLS12377G2)
+	mustRegisterCurve(BLS12377Name, nil, BLS12377G1)
+}
+
 // See 'r' = https://eprint.iacr.org/2018/962.pdf Figure 16
 var bls12377modulus = bhex("12ab655e9a2ca55660b44d1e5c37b00159aa76fed00000010a11800000000001")
 var g1Inf = bls12377.G1Affine{X: [6]uint64{}, Y: [6]uint64{}}
@@ -338,6 +344,7 @@ func (s *ScalarBls12377) UnmarshalJSON(input []byte) error {
 		return fmt.Errorf("invalid type")
 	}
 	s.value = S.value
+	s.point = S.point
 	return nil
 }
 
@@ -386,6 +393,18 @@ func (p *PointBls12377G1) IsOnCurve() bool {
 	return p.value.IsOnCurve()
 }
 
+// IsInPrimeSubgroup reports whether the point is in the prime order subgroup
+func (p *PointBls12377G1) IsInPrimeSubgroup() bool {
+	return p.value.IsOnCurve() && p.value.IsInSubGroup()
+}
+
+// ClearCofactor maps the point into the prime order subgroup
+func (p *PointBls12377G1) ClearCofactor() Point {
+	value := &bls12377.G1Affine{}
+	value.ClearCofactor(p.value)
+	return &PointBls12377G1{value}
+}
+
 func (p *PointBls12377G1) Double() Point {
 	t := &bls12377.G1Jac{}
 	t.FromAffine(p.value)
@@ -536,7 +555,7 @@ func (p *PointBls12377G1) Pairing(rhs PairingPoint) Scalar {
 	}
 	value := bls12377.GT{}
 	if p.value.IsInfinity() || pt.value.IsInfinity() {
-		return &ScalarBls12377Gt{&value}
+		return &ScalarBls12377Gt{value.SetOne()}
 	}
 	value, err := bls12377.Pair([]bls12377.G1Affine{*p.value}, []bls12377.G2Affine{*pt.value})
 	if err != nil {
@@ -550,6 +569,10 @@ func (p *PointBls12377G1) MultiPairing(points ...PairingPoint) Scalar {
 	return multiPairingBls12377(points...)
 }
 
+func (p *PointBls12377G1) MillerLoop(points ...PairingPoint) Scalar {
+	return millerLoopBls12377(points...)
+}
+
 func (p *PointBls12377G1) X() *big.Int {
 	b := p.value.RawBytes()
 	return new(big.Int).SetBytes(b[:48])
@@ -659,6 +682,18 @@ func (p *PointBls12377G2) IsOnCurve() bool {
 	return p.value.IsOnCurve()
 }
 
+// IsInPrimeSubgroup reports whether the point is in the prime order subgroup
+func (p *PointBls12377G2) IsInPrimeSubgroup() bool {
+	return p.value.IsOnCurve() && p.value.IsInSubGroup()
+}
+
+// ClearCofactor maps the point into the prime order subgroup
+func (p *PointBls12377G2) ClearCofactor() Point {
+	value := &bls12377.G2Affine{}
+	value.ClearCofactor(p.value)
+	return &PointBls12377G2{value}
+}
+
 func (p *PointBls12377G2) Double() Point {
 	t := &bls12377.G2Jac{}
 	t.FromAffine(p.value)
@@ -809,7 +844,7 @@ func (p *PointBls12377G2) Pairing(rhs PairingPoint) Scalar {
 	}
 	value := bls12377.GT{}
 	if p.value.IsInfinity() || pt.value.IsInfinity() {
-		return &ScalarBls12377Gt{&value}
+		return &ScalarBls12377Gt{value.SetOne()}
 	}
 	value, err := bls12377.Pair([]bls12377.G1Affine{*pt.value}, []bls12377.G2Affine{*p.value})
 	if err != nil {
@@ -823,6 +858,10 @@ func (p *PointBls12377G2) MultiPairing(points ...PairingPoint) Scalar {
 	return multiPairingBls12377(points...)
 }
 
+func (p *PointBls12377G2) MillerLoop(points ...PairingPoint) Scalar {
+	return millerLoopBls12377(points...)
+}
+
 func (p *PointBls12377G2) X() *big.Int {
 	b := p.value.RawBytes()
 	return new(big.Int).SetBytes(b[:96])
@@ -889,9 +928,38 @@ func (p *PointBls12377G2) UnmarshalJSON(input []byte) error {
 }
 
 func multiPairingBls12377(points ...PairingPoint) Scalar {
-	if len(points)%2 != 0 {
+	g1Arr, g2Arr := pairingArgsBls12377(points...)
+	if g1Arr == nil {
+		return nil
+	}
+
+	value, err := bls12377.Pair(g1Arr, g2Arr)
+	if err != nil {
 		return nil
 	}
+
+	return &ScalarBls12377Gt{&value}
+}
+
+func millerLoopBls12377(points ...PairingPoint) Scalar {
+	g1Arr, g2Arr := pairingArgsBls12377(points...)
+	if g1Arr == nil {
+		return nil
+	}
+
+	value, err := bls12377.MillerLoop(g1Arr, g2Arr)
+	if err != nil {
+		return nil
+	}
+
+	return &ScalarBls12377Gt{&value}
+}
+
+// pairingArgsBls12377 returns the G1 and G2 points of the pairs, or nil if the points are not such pairs
+func pairingArgsBls12377(points ...PairingPoint) ([]bls12377.G1Affine, []bls12377.G2Affine) {
+	if len(points)%2 != 0 {
+		return nil, nil
+	}
 	g1Arr := make([]bls12377.G1Affine, 0, len(points)/2)
 	g2Arr := make([]bls12377.G2Affine, 0, len(points)/2)
 	valid := true
@@ -910,14 +978,14 @@ func multiPairingBls12377(points ...PairingPoint) Scalar {
 		}
 	}
 	if !valid {
-		return nil
-	}
-
-	value, err := bls12377.Pair(g1Arr, g2Arr)
-	if err != nil {
-		return nil
+		return nil, nil
 	}
+	return g1Arr, g2Arr
+}
 
+// FinalExponentiation maps the result of MillerLoop, or a product of such results, to the pairing value
+func (s *ScalarBls12377Gt) FinalExponentiation() Scalar {
+	value := bls12377.FinalExponentiation(s.value)
 	return &ScalarBls12377Gt{&value}
 }
 
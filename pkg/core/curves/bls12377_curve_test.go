@@ -515,3 +515,31 @@ func TestPointBls12377G1SumOfProducts(t *testing.T) {
 	require.NotNil(t, rhs)
 	require.True(t, lhs.Equal(rhs))
 }
+
+func TestBls12377Pairing(t *testing.T) {
+	for _, name := range []string{BLS12377Name, BLS12377G1Name, BLS12377G2Name} {
+		require.NotNil(t, GetPairingCurveByName(name))
+	}
+	curve := GetPairingCurveByName(BLS12377Name)
+	a := curve.Scalar.Random(crand.Reader)
+	b := curve.Scalar.Random(crand.Reader)
+	g1 := curve.NewG1GeneratorPoint()
+	g2 := curve.NewG2GeneratorPoint()
+
+	e := g1.Pairing(g2)
+	require.NotNil(t, e)
+	require.False(t, e.IsOne())
+	lhs := curve.ScalarG1BaseMult(a).Pairing(curve.ScalarG2BaseMult(b))
+	require.Equal(t, 0, lhs.Cmp(curve.ScalarG1BaseMult(a.Mul(b)).Pairing(g2)))
+	require.Equal(t, 0, lhs.Cmp(g2.Pairing(curve.ScalarG1BaseMult(a.Mul(b)))))
+	require.Equal(t, 0, curve.ScalarG1BaseMult(curve.Scalar.New(2)).Pairing(g2).Cmp(e.Mul(e)))
+
+	// Pairings with the identity are the identity of GT
+	require.True(t, curve.NewG1IdentityPoint().Pairing(g2).IsOne())
+	require.True(t, curve.NewG2IdentityPoint().Pairing(g1).IsOne())
+
+	res := g1.MultiPairing(curve.ScalarG1BaseMult(a), g2, g1.Neg().(PairingPoint), curve.ScalarG2BaseMult(a))
+	require.NotNil(t, res)
+	require.True(t, res.IsOne())
+	require.True(t, curve.GT.IsOne())
+}
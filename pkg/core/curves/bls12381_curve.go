@@ -18,6 +18,13 @@ import (
 	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
 )
 
+func init() {
+	mustRegisterCurve(BLS12381G1Name, nil, BLS12381G1)
+	mustRegisterCurve(BLS12381G2Name, nil, BLS12381G2)
+	// Misspelled alias kept for existing encodings
+	mustRegisterCurve(BLS12831Name, nil, BLS12381G1)
+}
+
 var bls12381modulus = bhex("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
 
 type ScalarBls12381 struct {
@@ -338,6 +345,7 @@ func (s *ScalarBls12381) UnmarshalJSON(input []byte) error {
 		return fmt.Errorf("invalid type")
 	}
 	s.Value = S.Value
+	s.point = S.point
 	return nil
 }
 
@@ -379,6 +387,21 @@ func (p *PointBls12381G1) IsOnCurve() bool {
 	return p.Value.IsOnCurve() == 1
 }
 
+// ClearCofactor multiplies the point by the effective cofactor of RFC 9380
+func (p *PointBls12381G1) ClearCofactor() Point {
+	return &PointBls12381G1{new(bls12381.G1).ClearCofactor(p.Value)}
+}
+
+// IsTorsionFree reports whether the point is in the prime order subgroup
+func (p *PointBls12381G1) IsTorsionFree() bool {
+	return p.Value.IsOnCurve()&p.Value.InCorrectSubgroup() == 1
+}
+
+// IsInPrimeSubgroup reports whether the point is in the prime order subgroup
+func (p *PointBls12381G1) IsInPrimeSubgroup() bool {
+	return p.IsTorsionFree()
+}
+
 func (p *PointBls12381G1) Double() Point {
 	return &PointBls12381G1{new(bls12381.G1).Double(p.Value)}
 }
@@ -459,6 +482,9 @@ func (p *PointBls12381G1) ToAffineUncompressed() []byte {
 
 func (p *PointBls12381G1) FromAffineCompressed(bytes []byte) (Point, error) {
 	var b [bls12381.FieldBytes]byte
+	if len(bytes) != len(b) {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
 	copy(b[:], bytes)
 	value, err := new(bls12381.G1).FromCompressed(&b)
 	if err != nil {
@@ -469,6 +495,9 @@ func (p *PointBls12381G1) FromAffineCompressed(bytes []byte) (Point, error) {
 
 func (p *PointBls12381G1) FromAffineUncompressed(bytes []byte) (Point, error) {
 	var b [96]byte
+	if len(bytes) != len(b) {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
 	copy(b[:], bytes)
 	value, err := new(bls12381.G1).FromUncompressed(&b)
 	if err != nil {
@@ -482,27 +511,60 @@ func (p *PointBls12381G1) CurveName() string {
 }
 
 func (p *PointBls12381G1) SumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := bls12381G1Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value, err := new(bls12381.G1).SumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointBls12381G1{value}
+}
+
+// VarTimeMul multiplies the point by `rhs` in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (p *PointBls12381G1) VarTimeMul(rhs Scalar) Point {
+	r, ok := rhs.(*ScalarBls12381)
+	if !ok {
+		return nil
+	}
+	return &PointBls12381G1{new(bls12381.G1).VarTimeMul(p.Value, r.Value)}
+}
+
+// VarTimeSumOfProducts computes the multi-scalar multiplication of `points` and `scalars`
+// in variable time. It must only be used with public scalars, such as when verifying proofs
+func (p *PointBls12381G1) VarTimeSumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := bls12381G1Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value, err := new(bls12381.G1).VarTimeSumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointBls12381G1{value}
+}
+
+// bls12381G1Batch converts `points` and `scalars` to their native values
+func bls12381G1Batch(points []Point, scalars []Scalar) ([]*bls12381.G1, []*native.Field, bool) {
 	nPoints := make([]*bls12381.G1, len(points))
 	nScalars := make([]*native.Field, len(scalars))
 	for i, pt := range points {
 		pp, ok := pt.(*PointBls12381G1)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nPoints[i] = pp.Value
 	}
 	for i, sc := range scalars {
 		s, ok := sc.(*ScalarBls12381)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nScalars[i] = s.Value
 	}
-	value, err := new(bls12381.G1).SumOfProducts(nPoints, nScalars)
-	if err != nil {
-		return nil
-	}
-	return &PointBls12381G1{value}
+	return nPoints, nScalars, true
 }
 
 func (p *PointBls12381G1) OtherGroup() PairingPoint {
@@ -526,6 +588,10 @@ func (p *PointBls12381G1) MultiPairing(points ...PairingPoint) Scalar {
 	return multiPairing(points...)
 }
 
+func (p *PointBls12381G1) MillerLoop(points ...PairingPoint) Scalar {
+	return millerLoop(points...)
+}
+
 func (p *PointBls12381G1) X() *big.Int {
 	return p.Value.GetX().BigInt()
 }
@@ -627,6 +693,21 @@ func (p *PointBls12381G2) IsOnCurve() bool {
 	return p.Value.IsOnCurve() == 1
 }
 
+// ClearCofactor multiplies the point by the effective cofactor of RFC 9380
+func (p *PointBls12381G2) ClearCofactor() Point {
+	return &PointBls12381G2{new(bls12381.G2).ClearCofactor(p.Value)}
+}
+
+// IsTorsionFree reports whether the point is in the prime order subgroup
+func (p *PointBls12381G2) IsTorsionFree() bool {
+	return p.Value.IsOnCurve()&p.Value.InCorrectSubgroup() == 1
+}
+
+// IsInPrimeSubgroup reports whether the point is in the prime order subgroup
+func (p *PointBls12381G2) IsInPrimeSubgroup() bool {
+	return p.IsTorsionFree()
+}
+
 func (p *PointBls12381G2) Double() Point {
 	return &PointBls12381G2{new(bls12381.G2).Double(p.Value)}
 }
@@ -707,6 +788,9 @@ func (p *PointBls12381G2) ToAffineUncompressed() []byte {
 
 func (p *PointBls12381G2) FromAffineCompressed(bytes []byte) (Point, error) {
 	var b [bls12381.WideFieldBytes]byte
+	if len(bytes) != len(b) {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
 	copy(b[:], bytes)
 	value, err := new(bls12381.G2).FromCompressed(&b)
 	if err != nil {
@@ -717,6 +801,9 @@ func (p *PointBls12381G2) FromAffineCompressed(bytes []byte) (Point, error) {
 
 func (p *PointBls12381G2) FromAffineUncompressed(bytes []byte) (Point, error) {
 	var b [bls12381.DoubleWideFieldBytes]byte
+	if len(bytes) != len(b) {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
 	copy(b[:], bytes)
 	value, err := new(bls12381.G2).FromUncompressed(&b)
 	if err != nil {
@@ -730,27 +817,60 @@ func (p *PointBls12381G2) CurveName() string {
 }
 
 func (p *PointBls12381G2) SumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := bls12381G2Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value, err := new(bls12381.G2).SumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointBls12381G2{value}
+}
+
+// VarTimeMul multiplies the point by `rhs` in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (p *PointBls12381G2) VarTimeMul(rhs Scalar) Point {
+	r, ok := rhs.(*ScalarBls12381)
+	if !ok {
+		return nil
+	}
+	return &PointBls12381G2{new(bls12381.G2).VarTimeMul(p.Value, r.Value)}
+}
+
+// VarTimeSumOfProducts computes the multi-scalar multiplication of `points` and `scalars`
+// in variable time. It must only be used with public scalars, such as when verifying proofs
+func (p *PointBls12381G2) VarTimeSumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := bls12381G2Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value, err := new(bls12381.G2).VarTimeSumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointBls12381G2{value}
+}
+
+// bls12381G2Batch converts `points` and `scalars` to their native values
+func bls12381G2Batch(points []Point, scalars []Scalar) ([]*bls12381.G2, []*native.Field, bool) {
 	nPoints := make([]*bls12381.G2, len(points))
 	nScalars := make([]*native.Field, len(scalars))
 	for i, pt := range points {
 		pp, ok := pt.(*PointBls12381G2)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nPoints[i] = pp.Value
 	}
 	for i, sc := range scalars {
 		s, ok := sc.(*ScalarBls12381)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nScalars[i] = s.Value
 	}
-	value, err := new(bls12381.G2).SumOfProducts(nPoints, nScalars)
-	if err != nil {
-		return nil
-	}
-	return &PointBls12381G2{value}
+	return nPoints, nScalars, true
 }
 
 func (p *PointBls12381G2) OtherGroup() PairingPoint {
@@ -774,6 +894,10 @@ func (p *PointBls12381G2) MultiPairing(points ...PairingPoint) Scalar {
 	return multiPairing(points...)
 }
 
+func (p *PointBls12381G2) MillerLoop(points ...PairingPoint) Scalar {
+	return millerLoop(points...)
+}
+
 func (p *PointBls12381G2) X() *big.Int {
 	x := p.Value.ToUncompressed()
 	return new(big.Int).SetBytes(x[:bls12381.WideFieldBytes])
@@ -840,6 +964,25 @@ func (p *PointBls12381G2) UnmarshalJSON(input []byte) error {
 }
 
 func multiPairing(points ...PairingPoint) Scalar {
+	eng := pairingEngine(points...)
+	if eng == nil {
+		return nil
+	}
+	value := eng.Result()
+	return &ScalarBls12381Gt{value}
+}
+
+func millerLoop(points ...PairingPoint) Scalar {
+	eng := pairingEngine(points...)
+	if eng == nil {
+		return nil
+	}
+	value := eng.MillerLoop()
+	return &ScalarBls12381Gt{value}
+}
+
+// pairingEngine returns an engine with the pairs of G1 and G2 points, or nil if the points are not such pairs
+func pairingEngine(points ...PairingPoint) *bls12381.Engine {
 	if len(points)%2 != 0 {
 		return nil
 	}
@@ -857,8 +1000,12 @@ func multiPairing(points ...PairingPoint) Scalar {
 	if !valid {
 		return nil
 	}
+	return eng
+}
 
-	value := eng.Result()
+// FinalExponentiation maps the result of MillerLoop, or a product of such results, to the pairing value
+func (s *ScalarBls12381Gt) FinalExponentiation() Scalar {
+	value := new(bls12381.Gt).FinalExponentiation(s.Value)
 	return &ScalarBls12381Gt{value}
 }
 
@@ -0,0 +1,214 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bn254Decimal(s string) *big.Int {
+	v, _ := new(big.Int).SetString(s, 10)
+	return v
+}
+
+func TestScalarBn254Arithmetic(t *testing.T) {
+	bn254G1 := BN254G1()
+	require.True(t, bn254G1.Scalar.Zero().IsZero())
+	require.True(t, bn254G1.Scalar.One().IsOne())
+	require.True(t, bn254G1.Scalar.One().IsOdd())
+	three := bn254G1.Scalar.New(3)
+	require.Equal(t, 0, three.Square().Cmp(bn254G1.Scalar.New(9)))
+	require.Equal(t, 0, three.Cube().Cmp(bn254G1.Scalar.New(27)))
+	require.Equal(t, 0, three.Double().Cmp(bn254G1.Scalar.New(6)))
+	require.Equal(t, 0, three.Neg().Add(three).Cmp(bn254G1.Scalar.Zero()))
+	require.Equal(t, 0, bn254G1.Scalar.New(-1).Add(bn254G1.Scalar.One()).Cmp(bn254G1.Scalar.Zero()))
+	require.Equal(t, 0, bn254G1.Scalar.New(54).Div(bn254G1.Scalar.New(9)).Cmp(bn254G1.Scalar.New(6)))
+	inv, err := three.Invert()
+	require.NoError(t, err)
+	require.True(t, inv.Mul(three).IsOne())
+	sqrt, err := bn254G1.Scalar.New(9).Sqrt()
+	require.NoError(t, err)
+	require.Equal(t, 0, sqrt.Square().Cmp(bn254G1.Scalar.New(9)))
+
+	// The order is the group order of EIP-197
+	order := bn254Decimal("21888242871839275222246405745257275088548364400416034343698204186575808495617")
+	require.Equal(t, order, bn254G1.Scalar.(*ScalarBn254).Order())
+	_, err = bn254G1.Scalar.SetBigInt(order)
+	require.Error(t, err)
+	require.True(t, bn254G1.Point.Generator().Mul(bn254G1.Scalar.New(-1)).Equal(bn254G1.Point.Generator().Neg()))
+}
+
+func TestScalarBn254Serialize(t *testing.T) {
+	bn254G1 := BN254G1()
+	for i := 0; i < 10; i++ {
+		sc := bn254G1.Scalar.Random(crand.Reader)
+		sequence := sc.Bytes()
+		require.Equal(t, 32, len(sequence))
+		ret, err := bn254G1.Scalar.SetBytes(sequence)
+		require.NoError(t, err)
+		require.Equal(t, 0, ret.Cmp(sc))
+
+		data, err := sc.(*ScalarBn254).MarshalBinary()
+		require.NoError(t, err)
+		sc2 := new(ScalarBn254)
+		require.NoError(t, sc2.UnmarshalBinary(data))
+		require.Equal(t, 0, sc2.Cmp(sc))
+	}
+	require.Equal(t, 0, bn254G1.Scalar.Hash([]byte("bn254")).Cmp(bn254G1.Scalar.Hash([]byte("bn254"))))
+	_, ok := bn254G1.Scalar.Point().(*PointBn254G1)
+	require.True(t, ok)
+	_, ok = BN254G2().Scalar.Point().(*PointBn254G2)
+	require.True(t, ok)
+}
+
+func TestPointBn254G1Ethereum(t *testing.T) {
+	bn254G1 := BN254G1()
+	g := bn254G1.Point.Generator().(*PointBn254G1)
+	// The EIP-196 generator is (1, 2)
+	require.Equal(t, big.NewInt(1), g.X())
+	require.Equal(t, big.NewInt(2), g.Y())
+	expected := make([]byte, 64)
+	expected[31] = 1
+	expected[63] = 2
+	require.Equal(t, expected, g.ToAffineUncompressed())
+	// The identity is encoded as all zeros like the precompiles expect
+	require.Equal(t, make([]byte, 64), bn254G1.Point.Identity().ToAffineUncompressed())
+
+	pt, err := bn254G1.Point.Set(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	require.True(t, pt.Equal(g))
+	_, err = bn254G1.Point.Set(big.NewInt(1), big.NewInt(3))
+	require.Error(t, err)
+	pt, err = bn254G1.Point.Set(big.NewInt(0), big.NewInt(0))
+	require.NoError(t, err)
+	require.True(t, pt.IsIdentity())
+}
+
+func TestPointBn254G2Ethereum(t *testing.T) {
+	g := BN254G2().Point.Generator().(*PointBn254G2)
+	// EIP-197 encodes the imaginary part of each coordinate first
+	x := new(big.Int).Lsh(bn254Decimal("11559732032986387107991004021392285783925812861821192530917403151452391805634"), 256)
+	x.Add(x, bn254Decimal("10857046999023057135944570762232829481370756359578518086990519993285655852781"))
+	y := new(big.Int).Lsh(bn254Decimal("4082367875863433681332203403145435568316851327593401208105741076214120093531"), 256)
+	y.Add(y, bn254Decimal("8495653923123431417604973247489272438418190587263600148770280649306958101930"))
+	require.Equal(t, x, g.X())
+	require.Equal(t, y, g.Y())
+	pt, err := g.Set(x, y)
+	require.NoError(t, err)
+	require.True(t, pt.Equal(g))
+	require.Equal(t, make([]byte, 128), BN254G2().Point.Identity().ToAffineUncompressed())
+}
+
+func TestPointBn254Arithmetic(t *testing.T) {
+	for _, curve := range []*Curve{BN254G1(), BN254G2()} {
+		g := curve.Point.Generator()
+		require.True(t, g.IsOnCurve())
+		require.False(t, g.IsIdentity())
+		require.True(t, curve.Point.Identity().IsIdentity())
+		require.True(t, g.Double().Equal(g.Add(g)))
+		require.True(t, g.Double().Equal(g.Mul(curve.Scalar.New(2))))
+		require.True(t, g.Add(g.Neg()).IsIdentity())
+		require.True(t, g.Mul(curve.Scalar.New(3)).Sub(g).Equal(g.Double()))
+		require.True(t, g.Mul(curve.Scalar.New(-1)).Equal(g.Neg()))
+		require.NotEqual(t, g.IsNegative(), g.Neg().IsNegative())
+
+		h := curve.Point.Hash([]byte("bn254"))
+		require.NotNil(t, h)
+		require.True(t, h.IsOnCurve())
+		require.True(t, h.Equal(curve.Point.Hash([]byte("bn254"))))
+		require.False(t, h.Equal(curve.Point.Hash([]byte("bn255"))))
+
+		require.Nil(t, g.Add(nil))
+		require.Nil(t, g.Sub(nil))
+		require.Nil(t, g.Mul(nil))
+		require.Nil(t, g.Mul(BLS12377G1().Scalar.One()))
+		require.False(t, g.Equal(nil))
+	}
+}
+
+func TestPointBn254Serialize(t *testing.T) {
+	for _, curve := range []*Curve{BN254G1(), BN254G2()} {
+		for i := 0; i < 5; i++ {
+			pt := curve.Point.Random(crand.Reader)
+			ppt, err := curve.Point.FromAffineCompressed(pt.ToAffineCompressed())
+			require.NoError(t, err)
+			require.True(t, ppt.Equal(pt))
+			ppt, err = curve.Point.FromAffineUncompressed(pt.ToAffineUncompressed())
+			require.NoError(t, err)
+			require.True(t, ppt.Equal(pt))
+
+			data, err := pointMarshalBinary(pt)
+			require.NoError(t, err)
+			pt2, err := pointUnmarshalBinary(data)
+			require.NoError(t, err)
+			require.True(t, pt2.Equal(pt))
+		}
+		ppt, err := curve.Point.FromAffineCompressed(curve.Point.Identity().ToAffineCompressed())
+		require.NoError(t, err)
+		require.True(t, ppt.IsIdentity())
+		_, err = curve.Point.FromAffineCompressed([]byte{0x80})
+		require.Error(t, err)
+	}
+	require.Equal(t, BN254G1(), GetCurveByName(BN254G1Name))
+	require.Equal(t, BN254G2(), GetCurveByName(BN254G2Name))
+	require.Equal(t, BN254G1(), GetCurveByName(BN254Name))
+}
+
+func TestPointBn254SumOfProducts(t *testing.T) {
+	lhs := new(PointBn254G1).Generator().Mul(new(ScalarBn254).New(50))
+	points := make([]Point, 5)
+	for i := range points {
+		points[i] = new(PointBn254G1).Generator()
+	}
+	scalars := []Scalar{
+		new(ScalarBn254).New(8),
+		new(ScalarBn254).New(9),
+		new(ScalarBn254).New(10),
+		new(ScalarBn254).New(11),
+		new(ScalarBn254).New(12),
+	}
+	rhs := lhs.SumOfProducts(points, scalars)
+	require.NotNil(t, rhs)
+	require.True(t, lhs.Equal(rhs))
+}
+
+func TestBn254Pairing(t *testing.T) {
+	curve := GetPairingCurveByName(BN254Name)
+	require.NotNil(t, curve)
+	a := curve.Scalar.Random(crand.Reader)
+	b := curve.Scalar.Random(crand.Reader)
+	g1 := curve.NewG1GeneratorPoint()
+	g2 := curve.NewG2GeneratorPoint()
+
+	e := g1.Pairing(g2)
+	require.NotNil(t, e)
+	require.False(t, e.IsOne())
+	// e(aP, bQ) = e(abP, Q) = e(P, abQ)
+	lhs := curve.ScalarG1BaseMult(a).Pairing(curve.ScalarG2BaseMult(b))
+	require.Equal(t, 0, lhs.Cmp(curve.ScalarG1BaseMult(a.Mul(b)).Pairing(g2)))
+	require.Equal(t, 0, lhs.Cmp(curve.ScalarG2BaseMult(a.Mul(b)).Pairing(g1)))
+	// e(2P, Q) = e(P, Q) * e(P, Q)
+	require.Equal(t, 0, curve.ScalarG1BaseMult(curve.Scalar.New(2)).Pairing(g2).Cmp(e.Mul(e)))
+	require.True(t, curve.NewG1IdentityPoint().Pairing(g2).IsOne())
+
+	// The pairing check of the EIP-197 precompile: e(aP, Q) * e(-P, aQ) = 1
+	res := g1.MultiPairing(curve.ScalarG1BaseMult(a), g2, g1.Neg().(PairingPoint), curve.ScalarG2BaseMult(a))
+	require.NotNil(t, res)
+	require.True(t, res.IsOne())
+	res = g1.MultiPairing(curve.ScalarG1BaseMult(a), g2, g1.Neg().(PairingPoint), curve.ScalarG2BaseMult(b))
+	require.False(t, res.IsOne())
+	require.Nil(t, g1.MultiPairing(g1))
+	require.Nil(t, g1.Pairing(BLS12377G2().Point.Generator().(PairingPoint)))
+
+	gt, err := curve.GT.SetBytes(e.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 0, gt.Cmp(e))
+}
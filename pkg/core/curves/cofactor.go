@@ -0,0 +1,149 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"fmt"
+)
+
+// CofactorPoint is implemented by the points of curves whose group order has a
+// cofactor, such as ED25519, ED448 and the BLS12-381 groups
+type CofactorPoint interface {
+	Point
+	// IsTorsionFree reports whether the point is in the prime order subgroup,
+	// it is the same as IsInPrimeSubgroup
+	IsTorsionFree() bool
+}
+
+var (
+	_ CofactorPoint = (*PointEd25519)(nil)
+	_ CofactorPoint = (*PointEd448)(nil)
+	_ CofactorPoint = (*PointBls12381G1)(nil)
+	_ CofactorPoint = (*PointBls12381G2)(nil)
+
+	_ encodable = (*SubgroupPoint)(nil)
+)
+
+// IsTorsionFree reports whether `p` is in the prime order subgroup of its curve.
+// Points of prime order curves only need to be on the curve, curves with a
+// cofactor pay an extra check
+func IsTorsionFree(p Point) bool {
+	if p == nil {
+		return false
+	}
+	return p.IsInPrimeSubgroup()
+}
+
+// SubgroupPoint is a point known to be in the prime order subgroup of its curve.
+// It can only be created from a torsion free point, by clearing the cofactor or by
+// decoding, which rejects points outside the subgroup, so protocols can accept a
+// SubgroupPoint instead of checking their inputs for small torsion components
+type SubgroupPoint struct {
+	value Point
+}
+
+// NewSubgroupPoint returns `p` as a SubgroupPoint if it is in the prime order subgroup
+func NewSubgroupPoint(p Point) (*SubgroupPoint, error) {
+	if !IsTorsionFree(p) {
+		return nil, ErrNotInSubgroup
+	}
+	return &SubgroupPoint{value: p}, nil
+}
+
+// ClearCofactor maps `p` into the prime order subgroup. Points of prime order curves are unchanged
+func ClearCofactor(p Point) *SubgroupPoint {
+	if p == nil {
+		return nil
+	}
+	return &SubgroupPoint{value: p.ClearCofactor()}
+}
+
+// SubgroupPointFromAffineCompressed decodes a compressed point of `curve` and rejects
+// points outside the prime order subgroup
+func SubgroupPointFromAffineCompressed(curve *Curve, bytes []byte) (*SubgroupPoint, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	p, err := curve.Point.FromAffineCompressed(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return NewSubgroupPoint(p)
+}
+
+// SubgroupPointFromAffineUncompressed decodes an uncompressed point of `curve` and rejects
+// points outside the prime order subgroup
+func SubgroupPointFromAffineUncompressed(curve *Curve, bytes []byte) (*SubgroupPoint, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	p, err := curve.Point.FromAffineUncompressed(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return NewSubgroupPoint(p)
+}
+
+// Point returns the underlying point
+func (p *SubgroupPoint) Point() Point {
+	return p.value
+}
+
+// CurveName returns the name of the curve of the point
+func (p *SubgroupPoint) CurveName() string {
+	return p.value.CurveName()
+}
+
+// MarshalBinary encodes the point like the underlying point
+func (p *SubgroupPoint) MarshalBinary() ([]byte, error) {
+	return pointMarshalBinary(p.value)
+}
+
+// UnmarshalBinary decodes a curve-tagged point and rejects points outside the subgroup
+func (p *SubgroupPoint) UnmarshalBinary(input []byte) error {
+	value, err := pointUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	return p.set(value)
+}
+
+// MarshalText encodes the point like the underlying point
+func (p *SubgroupPoint) MarshalText() ([]byte, error) {
+	return pointMarshalText(p.value)
+}
+
+// UnmarshalText decodes a curve-tagged point and rejects points outside the subgroup
+func (p *SubgroupPoint) UnmarshalText(input []byte) error {
+	value, err := pointUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	return p.set(value)
+}
+
+// MarshalJSON encodes the point like the underlying point
+func (p *SubgroupPoint) MarshalJSON() ([]byte, error) {
+	return pointMarshalJson(p.value)
+}
+
+// UnmarshalJSON decodes a curve-tagged point and rejects points outside the subgroup
+func (p *SubgroupPoint) UnmarshalJSON(input []byte) error {
+	value, err := pointUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	return p.set(value)
+}
+
+func (p *SubgroupPoint) set(value Point) error {
+	if !IsTorsionFree(value) {
+		return ErrNotInSubgroup
+	}
+	p.value = value
+	return nil
+}
@@ -0,0 +1,134 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/stretchr/testify/require"
+)
+
+// ed25519LowOrder decodes a point of small order, which FromAffineCompressed rejects
+func ed25519LowOrder(t *testing.T, input []byte) *PointEd25519 {
+	value, err := edwards25519.NewIdentityPoint().SetBytes(input)
+	require.NoError(t, err)
+	return &PointEd25519{value}
+}
+
+// ed25519Torsion returns an ed25519 point with a small torsion component
+func ed25519Torsion(t *testing.T) Point {
+	// y = 0 is a point of order 4
+	lowOrder := ed25519LowOrder(t, make([]byte, 32))
+	return ED25519().Point.Random(crand.Reader).Add(lowOrder)
+}
+
+func TestCofactorPoints(t *testing.T) {
+	for _, curve := range []*Curve{ED25519(), BLS12381G1(), BLS12381G2()} {
+		p, ok := curve.Point.Random(crand.Reader).(CofactorPoint)
+		require.True(t, ok, curve.Name)
+		require.True(t, p.IsTorsionFree(), curve.Name)
+		require.True(t, p.ClearCofactor().(CofactorPoint).IsTorsionFree(), curve.Name)
+		require.True(t, curve.NewIdentityPoint().(CofactorPoint).IsTorsionFree(), curve.Name)
+	}
+	// ed25519 clears the cofactor by multiplying by 8
+	g := ED25519().NewGeneratorPoint()
+	require.True(t, g.(CofactorPoint).ClearCofactor().Equal(g.Mul(ED25519().Scalar.New(8))))
+
+	for _, curve := range []*Curve{K256(), P256(), PALLAS(), BLS12377G1(), BN254G2()} {
+		_, ok := curve.Point.(CofactorPoint)
+		require.False(t, ok, curve.Name)
+		require.True(t, IsTorsionFree(curve.Point.Random(crand.Reader)), curve.Name)
+	}
+}
+
+func TestIsInPrimeSubgroup(t *testing.T) {
+	for _, curve := range []*Curve{
+		K256(), P256(), P384(), ED25519(), ED448(), PALLAS(), RISTRETTO255(),
+		BLS12381G1(), BLS12381G2(), BLS12377G1(), BLS12377G2(), BN254G1(), BN254G2(),
+	} {
+		p := curve.Point.Random(crand.Reader)
+		require.True(t, p.IsInPrimeSubgroup(), curve.Name)
+		require.True(t, p.ClearCofactor().IsInPrimeSubgroup(), curve.Name)
+		require.True(t, curve.NewIdentityPoint().IsInPrimeSubgroup(), curve.Name)
+	}
+	// Prime order curves leave the point unchanged
+	for _, curve := range []*Curve{K256(), P256(), P384(), PALLAS(), RISTRETTO255(), BN254G1()} {
+		p := curve.Point.Random(crand.Reader)
+		require.True(t, p.ClearCofactor().Equal(p), curve.Name)
+	}
+
+	p := ed25519Torsion(t)
+	require.False(t, p.IsInPrimeSubgroup())
+	require.True(t, p.ClearCofactor().IsInPrimeSubgroup())
+	_, err := ED25519().Point.FromAffineCompressed(p.ToAffineCompressed())
+	require.ErrorIs(t, err, ErrNotInSubgroup)
+	_, err = ED25519().Point.FromAffineUncompressed(p.ToAffineUncompressed())
+	require.ErrorIs(t, err, ErrNotInSubgroup)
+	data, err := pointMarshalBinary(p)
+	require.NoError(t, err)
+	require.ErrorIs(t, new(PointEd25519).UnmarshalBinary(data), ErrNotInSubgroup)
+}
+
+func TestSubgroupPoint(t *testing.T) {
+	p := ed25519Torsion(t)
+	require.False(t, IsTorsionFree(p))
+	require.False(t, p.(CofactorPoint).IsTorsionFree())
+	_, err := NewSubgroupPoint(p)
+	require.ErrorIs(t, err, ErrNotInSubgroup)
+
+	cleared := ClearCofactor(p)
+	require.True(t, IsTorsionFree(cleared.Point()))
+	require.Equal(t, ED25519Name, cleared.CurveName())
+
+	// Decoding enforces the subgroup check
+	_, err = SubgroupPointFromAffineCompressed(ED25519(), p.ToAffineCompressed())
+	require.ErrorIs(t, err, ErrNotInSubgroup)
+	data, err := pointMarshalBinary(p)
+	require.NoError(t, err)
+	require.ErrorIs(t, new(SubgroupPoint).UnmarshalBinary(data), ErrNotInSubgroup)
+	data, err = pointMarshalJson(p)
+	require.NoError(t, err)
+	require.ErrorIs(t, new(SubgroupPoint).UnmarshalJSON(data), ErrNotInSubgroup)
+
+	for _, curve := range []*Curve{K256(), ED25519(), BLS12381G1(), BLS12381G2()} {
+		p := curve.Point.Random(crand.Reader)
+		sp, err := NewSubgroupPoint(p)
+		require.NoError(t, err)
+		require.True(t, sp.Point().Equal(p))
+		require.True(t, ClearCofactor(curve.Point.Generator()).Point().IsOnCurve())
+
+		decoded, err := SubgroupPointFromAffineCompressed(curve, p.ToAffineCompressed())
+		require.NoError(t, err)
+		require.True(t, decoded.Point().Equal(p))
+		decoded, err = SubgroupPointFromAffineUncompressed(curve, p.ToAffineUncompressed())
+		require.NoError(t, err)
+		require.True(t, decoded.Point().Equal(p))
+
+		type wrapper struct {
+			P *SubgroupPoint
+		}
+		data, err := json.Marshal(wrapper{sp})
+		require.NoError(t, err)
+		var w wrapper
+		require.NoError(t, json.Unmarshal(data, &w))
+		require.True(t, w.P.Point().Equal(p))
+
+		bin, err := sp.MarshalBinary()
+		require.NoError(t, err)
+		var out SubgroupPoint
+		require.NoError(t, out.UnmarshalBinary(bin))
+		require.True(t, out.Point().Equal(p))
+
+		text, err := sp.MarshalText()
+		require.NoError(t, err)
+		require.NoError(t, out.UnmarshalText(text))
+		require.True(t, out.Point().Equal(p))
+	}
+}
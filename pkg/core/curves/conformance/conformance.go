@@ -0,0 +1,447 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package conformance is a test suite for implementations of the curves.Scalar and curves.Point
+// interfaces. An out-of-tree backend, e.g. one that offloads arithmetic to hardware, runs it from
+// its own tests before it is plugged into the protocols:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, &curves.Curve{Scalar: new(MyScalar), Point: new(MyPoint), Name: curves.K256Name})
+//	}
+//
+// The suite checks the field and group laws, the serialization round trips and the rejection of
+// malformed encodings, hashing and the edge cases around zero and the identity. When the name of
+// the curve is one of the built-in curves, every result is also compared with the built-in
+// implementation, so a backend must agree with it bit for bit, including on Point.Hash.
+//
+// Inputs are derived from a fixed seed, so a failure reproduces on every run.
+package conformance
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// iterations is the number of random inputs each law is checked with
+const iterations = 16
+
+// Run runs every check of the suite against `curve` as subtests of t
+func Run(t *testing.T, curve *curves.Curve) {
+	require.NotNil(t, curve)
+	require.NotNil(t, curve.Scalar)
+	require.NotNil(t, curve.Point)
+	t.Run("Scalars", func(t *testing.T) { RunScalars(t, curve) })
+	t.Run("Points", func(t *testing.T) { RunPoints(t, curve) })
+	t.Run("Encoding", func(t *testing.T) { RunEncoding(t, curve) })
+	t.Run("Hash", func(t *testing.T) { RunHash(t, curve) })
+	if reference := curves.GetCurveByName(curve.Name); reference != nil {
+		t.Run("Reference", func(t *testing.T) { RunReference(t, curve, reference) })
+	}
+}
+
+// RunScalars checks the arithmetic of the scalar field
+func RunScalars(t *testing.T, curve *curves.Curve) {
+	reader := newReader("scalars")
+	zero := curve.Scalar.Zero()
+	one := curve.Scalar.One()
+
+	require.True(t, zero.IsZero())
+	require.False(t, zero.IsOne())
+	require.True(t, one.IsOne())
+	require.False(t, one.IsZero())
+	require.True(t, one.IsOdd())
+	require.False(t, one.IsEven())
+	require.True(t, curve.Scalar.New(1).IsOne())
+	require.Equal(t, 0, curve.Scalar.New(3).Cmp(one.Add(one).Add(one)))
+	require.Equal(t, 0, curve.Scalar.New(-1).Cmp(one.Neg()))
+
+	// The order is prime and -1 is its largest element
+	order := new(big.Int).Add(one.Neg().BigInt(), big.NewInt(1))
+	require.True(t, order.ProbablyPrime(20), "scalar field order %s is not prime", order)
+	reduced, err := curve.Scalar.SetBigInt(order)
+	require.NoError(t, err)
+	require.True(t, reduced.IsZero(), "the order does not reduce to zero")
+
+	_, err = zero.Invert()
+	require.Error(t, err, "zero has no inverse")
+
+	for i := 0; i < iterations; i++ {
+		a := curve.Scalar.Random(reader)
+		b := curve.Scalar.Random(reader)
+		c := curve.Scalar.Random(reader)
+		require.False(t, a.IsZero())
+		require.NotEqual(t, a.IsOdd(), a.IsEven())
+		require.Equal(t, 0, a.Cmp(a.Clone()))
+
+		// The inputs are not modified by any operation
+		aBytes, bBytes := a.Bytes(), b.Bytes()
+
+		require.Equal(t, 0, a.Add(b).Cmp(b.Add(a)), "addition is not commutative")
+		require.Equal(t, 0, a.Add(b).Add(c).Cmp(a.Add(b.Add(c))), "addition is not associative")
+		require.Equal(t, 0, a.Mul(b).Cmp(b.Mul(a)), "multiplication is not commutative")
+		require.Equal(t, 0, a.Mul(b).Mul(c).Cmp(a.Mul(b.Mul(c))), "multiplication is not associative")
+		require.Equal(t, 0, a.Mul(b.Add(c)).Cmp(a.Mul(b).Add(a.Mul(c))), "multiplication does not distribute")
+		require.Equal(t, 0, a.Add(zero).Cmp(a))
+		require.Equal(t, 0, a.Mul(one).Cmp(a))
+		require.True(t, a.Mul(zero).IsZero())
+		require.True(t, a.Add(a.Neg()).IsZero())
+		require.Equal(t, 0, a.Sub(b).Cmp(a.Add(b.Neg())))
+		require.Equal(t, 0, a.Neg().Neg().Cmp(a))
+		require.Equal(t, 0, a.Double().Cmp(a.Add(a)))
+		require.Equal(t, 0, a.Square().Cmp(a.Mul(a)))
+		require.Equal(t, 0, a.Cube().Cmp(a.Mul(a).Mul(a)))
+		require.Equal(t, 0, a.MulAdd(b, c).Cmp(a.Mul(b).Add(c)))
+
+		inv, err := a.Invert()
+		require.NoError(t, err)
+		require.True(t, a.Mul(inv).IsOne())
+		require.Equal(t, 0, a.Div(b).Mul(b).Cmp(a))
+
+		square := a.Square()
+		root, err := square.Sqrt()
+		require.NoError(t, err)
+		require.Equal(t, 0, root.Square().Cmp(square), "square root")
+
+		x := new(big.Int).Mod(new(big.Int).SetBytes(randomBytes(reader, len(a.Bytes())+8)), order)
+		s, err := curve.Scalar.SetBigInt(x)
+		require.NoError(t, err)
+		require.Equal(t, 0, x.Cmp(s.BigInt()), "big integer round trip")
+		sum := new(big.Int).Add(a.BigInt(), b.BigInt())
+		require.Equal(t, 0, sum.Mod(sum, order).Cmp(a.Add(b).BigInt()), "addition of big integers")
+		product := new(big.Int).Mul(a.BigInt(), b.BigInt())
+		require.Equal(t, 0, product.Mod(product, order).Cmp(a.Mul(b).BigInt()), "multiplication of big integers")
+
+		require.Equal(t, aBytes, a.Bytes(), "an operation modified its receiver")
+		require.Equal(t, bBytes, b.Bytes(), "an operation modified its argument")
+	}
+
+	// Random is a deterministic function of the reader
+	a := curve.Scalar.Random(newReader("random"))
+	b := curve.Scalar.Random(newReader("random"))
+	require.Equal(t, 0, a.Cmp(b))
+}
+
+// RunPoints checks the group law and scalar multiplication
+func RunPoints(t *testing.T, curve *curves.Curve) {
+	reader := newReader("points")
+	g := curve.Point.Generator()
+	identity := curve.Point.Identity()
+	name := g.CurveName()
+	require.NotEmpty(t, name)
+
+	require.True(t, identity.IsIdentity())
+	require.False(t, g.IsIdentity())
+	require.True(t, g.IsOnCurve())
+	require.True(t, identity.Double().IsIdentity())
+	require.True(t, identity.Neg().IsIdentity())
+	require.True(t, identity.Add(identity).IsIdentity())
+	require.True(t, identity.Mul(curve.Scalar.Random(reader)).IsIdentity())
+	require.True(t, g.Mul(curve.Scalar.Zero()).IsIdentity())
+	require.True(t, g.Mul(curve.Scalar.One()).Equal(g))
+	require.True(t, g.Scalar().IsZero())
+
+	// The generator has the order of the scalar field
+	minusOne := curve.Scalar.One().Neg()
+	require.True(t, g.Mul(minusOne).Equal(g.Neg()))
+	require.True(t, g.Mul(minusOne).Add(g).IsIdentity())
+
+	// Scalar multiplication by small scalars matches repeated addition
+	acc := identity
+	for k := int64(0); k < 20; k++ {
+		require.True(t, g.Mul(curve.Scalar.New(int(k))).Equal(acc), "%d * G", k)
+		acc = acc.Add(g)
+	}
+
+	for i := 0; i < iterations; i++ {
+		a := curve.Scalar.Random(reader)
+		b := curve.Scalar.Random(reader)
+		p := curve.Point.Random(reader)
+		q := curve.Point.Random(reader)
+		r := g.Mul(a)
+		require.True(t, p.IsOnCurve())
+		require.False(t, p.IsIdentity())
+		require.Equal(t, name, p.Add(q).CurveName())
+		pBytes, qBytes := p.ToAffineCompressed(), q.ToAffineCompressed()
+
+		require.True(t, p.Add(q).Equal(q.Add(p)), "addition is not commutative")
+		require.True(t, p.Add(q).Add(r).Equal(p.Add(q.Add(r))), "addition is not associative")
+		require.True(t, p.Add(identity).Equal(p))
+		require.True(t, identity.Add(p).Equal(p))
+		require.True(t, p.Add(p.Neg()).IsIdentity())
+		require.True(t, p.Sub(p).IsIdentity())
+		require.True(t, p.Sub(q).Equal(p.Add(q.Neg())))
+		require.True(t, p.Double().Equal(p.Add(p)), "doubling")
+		require.True(t, p.Neg().Neg().Equal(p))
+		require.False(t, p.Equal(q))
+
+		require.True(t, g.Mul(a.Add(b)).Equal(g.Mul(a).Add(g.Mul(b))), "(a + b) * G")
+		require.True(t, p.Mul(a.Mul(b)).Equal(p.Mul(a).Mul(b)), "(a * b) * P")
+		require.True(t, p.Add(q).Mul(a).Equal(p.Mul(a).Add(q.Mul(a))), "a * (P + Q)")
+		require.True(t, p.Mul(a.Neg()).Equal(p.Mul(a).Neg()), "-a * P")
+		require.True(t, p.Mul(curve.Scalar.New(2)).Equal(p.Double()))
+
+		require.Equal(t, pBytes, p.ToAffineCompressed(), "an operation modified its receiver")
+		require.Equal(t, qBytes, q.ToAffineCompressed(), "an operation modified its argument")
+	}
+
+	// SumOfProducts matches the naive sum, including for identities and zero scalars
+	for _, n := range []int{1, 2, 3, 8, 33} {
+		points := make([]curves.Point, n)
+		scalars := make([]curves.Scalar, n)
+		expected := identity
+		for i := range points {
+			points[i] = curve.Point.Random(reader)
+			scalars[i] = curve.Scalar.Random(reader)
+			switch i % 7 {
+			case 3:
+				points[i] = identity
+			case 5:
+				scalars[i] = curve.Scalar.Zero()
+			}
+			expected = expected.Add(points[i].Mul(scalars[i]))
+		}
+		actual := curve.Point.SumOfProducts(points, scalars)
+		require.NotNil(t, actual, "sum of %d products", n)
+		require.True(t, expected.Equal(actual), "sum of %d products", n)
+	}
+	require.Nil(t, curve.Point.SumOfProducts([]curves.Point{g}, nil), "mismatched lengths")
+
+	// Random is a deterministic function of the reader
+	p := curve.Point.Random(newReader("random"))
+	q := curve.Point.Random(newReader("random"))
+	require.True(t, p.Equal(q))
+}
+
+// RunEncoding checks the round trips of the point and scalar encodings and that malformed
+// encodings are rejected without a panic
+func RunEncoding(t *testing.T, curve *curves.Curve) {
+	reader := newReader("encoding")
+	g := curve.Point.Generator()
+	compressedLen := len(g.ToAffineCompressed())
+	uncompressedLen := len(g.ToAffineUncompressed())
+	scalarLen := len(curve.Scalar.One().Bytes())
+	require.NotZero(t, compressedLen)
+	require.NotZero(t, uncompressedLen)
+	require.NotZero(t, scalarLen)
+
+	for i := 0; i < iterations; i++ {
+		p := curve.Point.Random(reader)
+		compressed := p.ToAffineCompressed()
+		require.Len(t, compressed, compressedLen)
+		q, err := curve.Point.FromAffineCompressed(compressed)
+		require.NoError(t, err)
+		require.True(t, p.Equal(q), "compressed round trip")
+		require.NotEqual(t, compressed, p.Neg().ToAffineCompressed(), "P and -P have the same encoding")
+
+		uncompressed := p.ToAffineUncompressed()
+		require.Len(t, uncompressed, uncompressedLen)
+		q, err = curve.Point.FromAffineUncompressed(uncompressed)
+		require.NoError(t, err)
+		require.True(t, p.Equal(q), "uncompressed round trip")
+
+		s := curve.Scalar.Random(reader)
+		b := s.Bytes()
+		require.Len(t, b, scalarLen)
+		s2, err := curve.Scalar.SetBytes(b)
+		require.NoError(t, err)
+		require.Equal(t, 0, s.Cmp(s2), "scalar round trip")
+
+		wide, err := curve.Scalar.SetBytesWide(randomBytes(reader, 2*scalarLen))
+		require.NoError(t, err)
+		require.False(t, wide.IsZero())
+	}
+
+	// Points and scalars support the standard encoding packages with encodings tagged with the curve name
+	p := curve.Point.Random(reader)
+	for _, value := range []interface{}{p, curve.Scalar.Random(reader)} {
+		require.Implements(t, (*encoding.BinaryMarshaler)(nil), value)
+		require.Implements(t, (*encoding.BinaryUnmarshaler)(nil), value)
+		require.Implements(t, (*encoding.TextMarshaler)(nil), value)
+		require.Implements(t, (*encoding.TextUnmarshaler)(nil), value)
+		require.Implements(t, (*json.Marshaler)(nil), value)
+		require.Implements(t, (*json.Unmarshaler)(nil), value)
+		data, err := value.(encoding.BinaryMarshaler).MarshalBinary()
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(data, []byte(p.CurveName()+":")), "binary encoding is not tagged")
+		text, err := value.(encoding.TextMarshaler).MarshalText()
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(text, []byte(p.CurveName()+":")), "text encoding is not tagged")
+	}
+
+	// Equal points have equal encodings whatever their internal representation
+	sum := g.Add(g).Add(g)
+	tripled := g.Mul(curve.Scalar.New(3))
+	require.Equal(t, sum.ToAffineCompressed(), tripled.ToAffineCompressed())
+	require.Equal(t, sum.ToAffineUncompressed(), tripled.ToAffineUncompressed())
+
+	// The identity either round trips or is rejected. Encodings without a dedicated flag, like
+	// SEC 1 compressed points, may collide with a point on the curve
+	identity := curve.Point.Identity()
+	if q, err := curve.Point.FromAffineCompressed(identity.ToAffineCompressed()); err == nil {
+		require.True(t, q.IsIdentity() || q.IsOnCurve(), "compressed identity")
+	}
+	if q, err := curve.Point.FromAffineUncompressed(identity.ToAffineUncompressed()); err == nil {
+		require.True(t, q.IsIdentity(), "uncompressed identity")
+	}
+
+	// Wrong lengths
+	for _, n := range []int{0, 1, compressedLen - 1, compressedLen + 1} {
+		input := randomBytes(reader, n)
+		require.NotPanics(t, func() {
+			_, err := curve.Point.FromAffineCompressed(input)
+			require.Error(t, err, "compressed point of %d bytes", n)
+		})
+	}
+	for _, n := range []int{0, 1, uncompressedLen - 1, uncompressedLen + 1} {
+		input := randomBytes(reader, n)
+		require.NotPanics(t, func() {
+			_, err := curve.Point.FromAffineUncompressed(input)
+			require.Error(t, err, "uncompressed point of %d bytes", n)
+		})
+	}
+	for _, n := range []int{0, 1, scalarLen - 1, scalarLen + 1} {
+		input := randomBytes(reader, n)
+		require.NotPanics(t, func() {
+			_, err := curve.Scalar.SetBytes(input)
+			require.Error(t, err, "scalar of %d bytes", n)
+		})
+	}
+	require.NotPanics(t, func() {
+		_, err := curve.Scalar.SetBytesWide(randomBytes(reader, 2*scalarLen-1))
+		require.Error(t, err, "wide scalar of %d bytes", 2*scalarLen-1)
+	})
+
+	// Points off the curve. Random inputs of the right length may or may not decode, but
+	// whatever decodes must be on the curve and re-encode to the same bytes
+	for i := 0; i < 64; i++ {
+		input := randomBytes(reader, uncompressedLen)
+		require.NotPanics(t, func() {
+			if p, err := curve.Point.FromAffineUncompressed(input); err == nil {
+				require.True(t, p.IsOnCurve(), "decoded a point off the curve")
+			}
+		})
+		input = randomBytes(reader, compressedLen)
+		require.NotPanics(t, func() {
+			if p, err := curve.Point.FromAffineCompressed(input); err == nil {
+				require.True(t, p.IsOnCurve(), "decoded a point off the curve")
+				require.Equal(t, input, p.ToAffineCompressed(), "non-canonical compressed encoding")
+			}
+		})
+	}
+	// A point with one coordinate changed is not on the curve
+	for i := 0; i < iterations; i++ {
+		uncompressed := curve.Point.Random(reader).ToAffineUncompressed()
+		uncompressed[len(uncompressed)-1] ^= 1
+		_, err := curve.Point.FromAffineUncompressed(uncompressed)
+		require.Error(t, err, "decoded a point off the curve")
+	}
+}
+
+// RunHash checks that hashing to the curve is deterministic and lands in the group
+func RunHash(t *testing.T, curve *curves.Curve) {
+	var previous curves.Point
+	for _, msg := range []string{"", "abc", "abcdef0123456789", string(make([]byte, 1000))} {
+		p := curve.Point.Hash([]byte(msg))
+		require.NotNil(t, p)
+		require.True(t, p.IsOnCurve(), "hash of %q", msg)
+		require.False(t, p.IsIdentity(), "hash of %q", msg)
+		require.True(t, p.Equal(curve.Point.Hash([]byte(msg))), "hash of %q is not deterministic", msg)
+		// The output is in the prime order subgroup
+		require.True(t, p.Mul(curve.Scalar.One().Neg()).Equal(p.Neg()), "hash of %q is not in the group", msg)
+		if previous != nil {
+			require.False(t, p.Equal(previous))
+		}
+		previous = p
+	}
+
+	s := curve.Scalar.Hash([]byte("abc"))
+	require.NotNil(t, s)
+	require.Equal(t, 0, s.Cmp(curve.Scalar.Hash([]byte("abc"))), "scalar hash is not deterministic")
+	require.NotEqual(t, 0, s.Cmp(curve.Scalar.Hash([]byte("abd"))))
+}
+
+// RunReference compares every operation of `curve` with `reference`, the curve it implements.
+// Scalars and points are exchanged through their byte encodings
+func RunReference(t *testing.T, curve, reference *curves.Curve) {
+	reader := newReader("reference")
+	require.Equal(t, reference.Point.Generator().ToAffineUncompressed(), curve.Point.Generator().ToAffineUncompressed(), "generator")
+	require.Equal(t, reference.Point.Generator().CurveName(), curve.Point.Generator().CurveName(), "curve name")
+	require.Equal(t, 0, reference.Scalar.One().Neg().BigInt().Cmp(curve.Scalar.One().Neg().BigInt()), "order")
+
+	toReference := func(p curves.Point) curves.Point {
+		q, err := reference.Point.FromAffineUncompressed(p.ToAffineUncompressed())
+		require.NoError(t, err)
+		return q
+	}
+	scalarToReference := func(s curves.Scalar) curves.Scalar {
+		r, err := reference.Scalar.SetBytes(s.Bytes())
+		require.NoError(t, err)
+		return r
+	}
+	for i := 0; i < iterations; i++ {
+		a := curve.Scalar.Random(reader)
+		b := curve.Scalar.Random(reader)
+		ra, rb := scalarToReference(a), scalarToReference(b)
+		require.Equal(t, ra.Mul(rb).Bytes(), a.Mul(b).Bytes(), "scalar multiplication")
+		require.Equal(t, ra.Add(rb).Bytes(), a.Add(b).Bytes(), "scalar addition")
+		inv, err := a.Invert()
+		require.NoError(t, err)
+		rinv, err := ra.Invert()
+		require.NoError(t, err)
+		require.Equal(t, rinv.Bytes(), inv.Bytes(), "inversion")
+
+		p := curve.Point.Random(reader)
+		q := curve.Point.Random(reader)
+		rp, rq := toReference(p), toReference(q)
+		require.Equal(t, rp.ToAffineCompressed(), p.ToAffineCompressed(), "compressed encoding")
+		require.Equal(t, rp.Add(rq).ToAffineUncompressed(), p.Add(q).ToAffineUncompressed(), "point addition")
+		require.Equal(t, rp.Mul(ra).ToAffineUncompressed(), p.Mul(a).ToAffineUncompressed(), "scalar multiplication")
+		require.Equal(t, reference.Point.Generator().Mul(ra).ToAffineUncompressed(), curve.Point.Generator().Mul(a).ToAffineUncompressed(), "base multiplication")
+
+		// The curve-tagged encodings are interchangeable
+		for _, pair := range [][2]interface{}{{rp, p}, {ra, a}} {
+			expected, err := pair[0].(encoding.BinaryMarshaler).MarshalBinary()
+			require.NoError(t, err)
+			actual, err := pair[1].(encoding.BinaryMarshaler).MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, expected, actual, "binary encoding")
+			expected, err = json.Marshal(pair[0])
+			require.NoError(t, err)
+			actual, err = json.Marshal(pair[1])
+			require.NoError(t, err)
+			require.Equal(t, expected, actual, "json encoding")
+		}
+	}
+	for _, msg := range []string{"", "abc", "abcdef0123456789"} {
+		require.Equal(t, reference.Point.Hash([]byte(msg)).ToAffineUncompressed(), curve.Point.Hash([]byte(msg)).ToAffineUncompressed(), "hash of %q", msg)
+		require.Equal(t, reference.Scalar.Hash([]byte(msg)).Bytes(), curve.Scalar.Hash([]byte(msg)).Bytes(), "scalar hash of %q", msg)
+	}
+}
+
+// newReader returns a deterministic stream of bytes for `label`
+func newReader(label string) io.Reader {
+	xof := sha3.NewShake256()
+	_, _ = xof.Write([]byte(fmt.Sprintf("kryptology curve conformance %s", label)))
+	return xof
+}
+
+func randomBytes(reader io.Reader, n int) []byte {
+	out := make([]byte, n)
+	if _, err := io.ReadFull(reader, out); err != nil {
+		panic(err)
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package conformance
+
+import (
+	"testing"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestBuiltinCurves(t *testing.T) {
+	for _, curve := range []*curves.Curve{
+		curves.K256(),
+		curves.P256(),
+		curves.P384(),
+		curves.ED25519(),
+		curves.ED448(),
+		curves.PALLAS(),
+		curves.VESTA(),
+		curves.BLS12381G1(),
+		curves.BLS12381G2(),
+	} {
+		curve := curve
+		t.Run(curve.Name, func(t *testing.T) { Run(t, curve) })
+	}
+}
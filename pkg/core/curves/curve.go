@@ -7,7 +7,9 @@
 package curves
 
 import (
+	"bytes"
 	"crypto/elliptic"
+	"encoding"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -16,6 +18,7 @@ import (
 	"math/big"
 	"sync"
 
+	"github.com/etclab/kryptology/pkg/core/curves/native"
 	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
 )
 
@@ -38,27 +41,56 @@ var (
 	p256Initonce sync.Once
 	p256         Curve
 
+	p384Initonce sync.Once
+	p384         Curve
+
 	ed25519Initonce sync.Once
 	ed25519         Curve
 
+	ed448Initonce sync.Once
+	ed448         Curve
+
 	pallasInitonce sync.Once
 	pallas         Curve
+
+	vestaInitonce sync.Once
+	vesta         Curve
+
+	ristretto255Initonce sync.Once
+	ristretto255         Curve
+
+	bn254g1Initonce sync.Once
+	bn254g1         Curve
+
+	bn254g2Initonce sync.Once
+	bn254g2         Curve
 )
 
 const (
-	K256Name       = "secp256k1"
-	BLS12381G1Name = "BLS12381G1"
-	BLS12381G2Name = "BLS12381G2"
-	BLS12831Name   = "BLS12831"
-	P256Name       = "P-256"
-	ED25519Name    = "ed25519"
-	PallasName     = "pallas"
-	BLS12377G1Name = "BLS12377G1"
-	BLS12377G2Name = "BLS12377G2"
-	BLS12377Name   = "BLS12377"
+	K256Name         = "secp256k1"
+	BLS12381G1Name   = "BLS12381G1"
+	BLS12381G2Name   = "BLS12381G2"
+	BLS12831Name     = "BLS12831"
+	P256Name         = "P-256"
+	P384Name         = "P-384"
+	ED25519Name      = "ed25519"
+	ED448Name        = "ed448"
+	PallasName       = "pallas"
+	VestaName        = "vesta"
+	BLS12377G1Name   = "BLS12377G1"
+	BLS12377G2Name   = "BLS12377G2"
+	BLS12377Name     = "BLS12377"
+	Ristretto255Name = "ristretto255"
+	BN254G1Name      = "BN254G1"
+	BN254G2Name      = "BN254G2"
+	BN254Name        = "BN254"
+	BLS12381GtName   = "BLS12381Gt"
+	BLS12377GtName   = "BLS12377Gt"
+	BN254GtName      = "BN254Gt"
 )
 
-const scalarBytes = 32
+// WideScalarBytes is the length of the input of Scalar.SetBytesWide that every curve accepts
+const WideScalarBytes = 64
 
 // Scalar represents an element of the scalar field \mathbb{F}_q
 // of the elliptic curve construction.
@@ -121,7 +153,8 @@ type Scalar interface {
 	Bytes() []byte
 	// SetBytes creates a scalar from the canonical representation expecting the exact number of bytes needed to represent the scalar
 	SetBytes(bytes []byte) (Scalar, error)
-	// SetBytesWide creates a scalar expecting double the exact number of bytes needed to represent the scalar which is reduced by the modulus
+	// SetBytesWide creates a scalar expecting double the exact number of bytes needed to represent the scalar which is reduced by the modulus.
+	// Every curve accepts WideScalarBytes, so a 64-byte hash output can be mapped to a scalar with negligible bias
 	SetBytesWide(bytes []byte) (Scalar, error)
 	// Clone returns a cloned Scalar of this value
 	Clone() Scalar
@@ -132,81 +165,159 @@ type PairingScalar interface {
 	SetPoint(p Point) PairingScalar
 }
 
-func unmarshalScalar(input []byte) (*Curve, []byte, error) {
-	sep := byte(':')
-	i := 0
-	for ; i < len(input); i++ {
-		if input[i] == sep {
-			break
-		}
+// encodable is satisfied by every Point and Scalar of this package, so that structs
+// holding them can use the standard encoding packages. The encodings are canonical and
+// tagged with the curve name. The interfaces are not part of Point and Scalar, since gob
+// would then encode fields of those types with the marshalers instead of as interfaces
+type encodable interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+	json.Marshaler
+	json.Unmarshaler
+}
+
+var (
+	_ encodable = (*ScalarK256)(nil)
+	_ encodable = (*ScalarP256)(nil)
+	_ encodable = (*ScalarP384)(nil)
+	_ encodable = (*ScalarEd25519)(nil)
+	_ encodable = (*ScalarEd448)(nil)
+	_ encodable = (*ScalarPallas)(nil)
+	_ encodable = (*ScalarVesta)(nil)
+	_ encodable = (*ScalarRistretto255)(nil)
+	_ encodable = (*ScalarBls12381)(nil)
+	_ encodable = (*ScalarBls12381Gt)(nil)
+	_ encodable = (*ScalarBls12377)(nil)
+	_ encodable = (*ScalarBls12377Gt)(nil)
+	_ encodable = (*ScalarBn254)(nil)
+	_ encodable = (*ScalarBn254Gt)(nil)
+	_ encodable = (*PointK256)(nil)
+	_ encodable = (*PointP256)(nil)
+	_ encodable = (*PointP384)(nil)
+	_ encodable = (*PointEd25519)(nil)
+	_ encodable = (*PointEd448)(nil)
+	_ encodable = (*PointPallas)(nil)
+	_ encodable = (*PointVesta)(nil)
+	_ encodable = (*PointRistretto255)(nil)
+	_ encodable = (*PointBls12381G1)(nil)
+	_ encodable = (*PointBls12381G2)(nil)
+	_ encodable = (*PointBls12377G1)(nil)
+	_ encodable = (*PointBls12377G2)(nil)
+	_ encodable = (*PointBn254G1)(nil)
+	_ encodable = (*PointBn254G2)(nil)
+)
+
+// splitTag separates the curve name from the value of a curve-tagged encoding
+func splitTag(input []byte) (string, []byte, error) {
+	i := bytes.IndexByte(input, ':')
+	if i <= 0 {
+		return "", nil, fmt.Errorf("invalid byte sequence")
+	}
+	return string(input[:i]), input[i+1:], nil
+}
+
+// scalarTag returns the name that tags the encodings of `scalar`.
+// Elements of a target group are tagged with the name of the group
+// since they do not share the scalar field of the curve
+func scalarTag(scalar Scalar) string {
+	switch scalar.(type) {
+	case *ScalarBls12381Gt:
+		return BLS12381GtName
+	case *ScalarBls12377Gt:
+		return BLS12377GtName
+	case *ScalarBn254Gt:
+		return BN254GtName
+	}
+	return scalar.Point().CurveName()
+}
+
+// scalarByTag returns a scalar of the type tagged with `name`
+func scalarByTag(name string) (Scalar, error) {
+	switch name {
+	case BLS12381GtName:
+		return new(ScalarBls12381Gt).One(), nil
+	case BLS12377GtName:
+		return new(ScalarBls12377Gt).One(), nil
+	case BN254GtName:
+		return new(ScalarBn254Gt).One(), nil
 	}
-	name := string(input[:i])
 	curve := GetCurveByName(name)
 	if curve == nil {
-		return nil, nil, fmt.Errorf("unrecognized curve")
+		return nil, fmt.Errorf("unrecognized curve")
+	}
+	return curve.Scalar, nil
+}
+
+// scalarSetCanonicalBytes decodes the value of a curve-tagged encoding,
+// which must be the canonical representation of the scalar
+func scalarSetCanonicalBytes(name string, data []byte) (Scalar, error) {
+	scalar, err := scalarByTag(name)
+	if err != nil {
+		return nil, err
 	}
-	return curve, input[i+1:], nil
+	if len(data) != len(scalar.One().Bytes()) {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	sc, err := scalar.SetBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sc.Bytes(), data) {
+		return nil, fmt.Errorf("non-canonical scalar")
+	}
+	return sc, nil
 }
 
 func scalarMarshalBinary(scalar Scalar) ([]byte, error) {
-	// All scalars are 32 bytes long
-	// The last 32 bytes are the actual value
-	// The first remaining bytes are the curve name
-	// separated by a colon
-	name := []byte(scalar.Point().CurveName())
-	output := make([]byte, len(name)+1+scalarBytes)
+	// The curve name is followed by a colon
+	// and the canonical bytes of the scalar
+	name := []byte(scalarTag(scalar))
+	value := scalar.Bytes()
+	output := make([]byte, len(name)+1+len(value))
 	copy(output[:len(name)], name)
 	output[len(name)] = byte(':')
-	copy(output[len(name)+1:], scalar.Bytes())
+	copy(output[len(name)+1:], value)
 	return output, nil
 }
 
 func scalarUnmarshalBinary(input []byte) (Scalar, error) {
-	// All scalars are 32 bytes long
-	// The first 32 bytes are the actual value
-	// The remaining bytes are the curve name
-	if len(input) < scalarBytes+1+len(P256Name) {
-		return nil, fmt.Errorf("invalid byte sequence")
-	}
-	sc, data, err := unmarshalScalar(input)
+	name, data, err := splitTag(input)
 	if err != nil {
 		return nil, err
 	}
-	return sc.Scalar.SetBytes(data)
+	return scalarSetCanonicalBytes(name, data)
 }
 
 func scalarMarshalText(scalar Scalar) ([]byte, error) {
-	// All scalars are 32 bytes long
 	// For text encoding we put the curve name first for readability
 	// separated by a colon, then the hex encoding of the scalar
 	// which avoids the base64 weakness with strict mode or not
-	name := []byte(scalar.Point().CurveName())
-	output := make([]byte, len(name)+1+scalarBytes*2)
+	name := []byte(scalarTag(scalar))
+	value := scalar.Bytes()
+	output := make([]byte, len(name)+1+len(value)*2)
 	copy(output[:len(name)], name)
 	output[len(name)] = byte(':')
-	_ = hex.Encode(output[len(name)+1:], scalar.Bytes())
+	_ = hex.Encode(output[len(name)+1:], value)
 	return output, nil
 }
 
 func scalarUnmarshalText(input []byte) (Scalar, error) {
-	if len(input) < scalarBytes*2+len(P256Name)+1 {
-		return nil, fmt.Errorf("invalid byte sequence")
-	}
-	curve, data, err := unmarshalScalar(input)
+	name, data, err := splitTag(input)
 	if err != nil {
 		return nil, err
 	}
-	var t [scalarBytes]byte
-	_, err = hex.Decode(t[:], data)
-	if err != nil {
+	t := make([]byte, hex.DecodedLen(len(data)))
+	if _, err = hex.Decode(t, data); err != nil {
 		return nil, err
 	}
-	return curve.Scalar.SetBytes(t[:])
+	return scalarSetCanonicalBytes(name, t)
 }
 
 func scalarMarshalJson(scalar Scalar) ([]byte, error) {
 	m := make(map[string]string, 2)
-	m["type"] = scalar.Point().CurveName()
+	m["type"] = scalarTag(scalar)
 	m["value"] = hex.EncodeToString(scalar.Bytes())
 	return json.Marshal(m)
 }
@@ -218,19 +329,11 @@ func scalarUnmarshalJson(input []byte) (Scalar, error) {
 	if err != nil {
 		return nil, err
 	}
-	curve := GetCurveByName(m["type"])
-	if curve == nil {
-		return nil, fmt.Errorf("invalid type")
-	}
 	s, err := hex.DecodeString(m["value"])
 	if err != nil {
 		return nil, err
 	}
-	S, err := curve.Scalar.SetBytes(s)
-	if err != nil {
-		return nil, err
-	}
-	return S, nil
+	return scalarSetCanonicalBytes(m["type"], s)
 }
 
 // Point represents an elliptic curve point
@@ -242,6 +345,12 @@ type Point interface {
 	IsIdentity() bool
 	IsNegative() bool
 	IsOnCurve() bool
+	// IsInPrimeSubgroup reports whether the point is in the subgroup of prime order q.
+	// It is equivalent to IsOnCurve for curves of prime order
+	IsInPrimeSubgroup() bool
+	// ClearCofactor maps the point into the prime order subgroup with the cofactor clearing
+	// method of its curve. Points of prime order curves are returned unchanged
+	ClearCofactor() Point
 	Double() Point
 	Scalar() Scalar
 	Neg() Point
@@ -265,6 +374,70 @@ type PairingPoint interface {
 	MultiPairing(...PairingPoint) Scalar
 }
 
+// VarTimePoint is a Point with scalar multiplications that run in variable time. They are faster
+// than Mul and SumOfProducts but leak the scalars through timing, so they must only be used with
+// public scalars, such as when verifying signatures and proofs
+type VarTimePoint interface {
+	Point
+	VarTimeMul(rhs Scalar) Point
+	VarTimeSumOfProducts(points []Point, scalars []Scalar) Point
+}
+
+// VarTimeMul returns `p` multiplied by the public scalar `s` in variable time if the curve supports it,
+// and with Mul otherwise
+func VarTimeMul(p Point, s Scalar) Point {
+	if vp, ok := p.(VarTimePoint); ok {
+		return vp.VarTimeMul(s)
+	}
+	return p.Mul(s)
+}
+
+// VarTimeSumOfProducts returns the multi-scalar multiplication of `points` and the public `scalars`
+// in variable time if the curve supports it, and with SumOfProducts otherwise
+func VarTimeSumOfProducts(points []Point, scalars []Scalar) Point {
+	if len(points) == 0 {
+		return nil
+	}
+	if vp, ok := points[0].(VarTimePoint); ok {
+		return vp.VarTimeSumOfProducts(points, scalars)
+	}
+	return points[0].SumOfProducts(points, scalars)
+}
+
+// MillerLoopPoint is a PairingPoint that computes the Miller loops of pairings separately
+// from their final exponentiation. MillerLoop takes pairs of G1 and G2 points like
+// MultiPairing and returns a FinalExponentiator. Results of several calls can be
+// multiplied so that the pairings of several equations share one final exponentiation
+type MillerLoopPoint interface {
+	PairingPoint
+	MillerLoop(...PairingPoint) Scalar
+}
+
+// FinalExponentiator is the result of a Miller loop, which FinalExponentiation maps to the target group
+type FinalExponentiator interface {
+	FinalExponentiation() Scalar
+}
+
+// pointSetCanonicalBytes decodes the value of a curve-tagged encoding,
+// which must be the canonical compressed representation of the point
+func pointSetCanonicalBytes(name string, data []byte) (Point, error) {
+	curve := GetCurveByName(name)
+	if curve == nil {
+		return nil, fmt.Errorf("unrecognized curve")
+	}
+	if len(data) != len(curve.Point.Generator().ToAffineCompressed()) {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	p, err := curve.Point.FromAffineCompressed(data)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(p.ToAffineCompressed(), data) {
+		return nil, fmt.Errorf("non-canonical point")
+	}
+	return p, nil
+}
+
 func pointMarshalBinary(point Point) ([]byte, error) {
 	// Always stores points in compressed form
 	// The first bytes are the curve name
@@ -280,22 +453,11 @@ func pointMarshalBinary(point Point) ([]byte, error) {
 }
 
 func pointUnmarshalBinary(input []byte) (Point, error) {
-	if len(input) < scalarBytes+1+len(P256Name) {
-		return nil, fmt.Errorf("invalid byte sequence")
-	}
-	sep := byte(':')
-	i := 0
-	for ; i < len(input); i++ {
-		if input[i] == sep {
-			break
-		}
-	}
-	name := string(input[:i])
-	curve := GetCurveByName(name)
-	if curve == nil {
-		return nil, fmt.Errorf("unrecognized curve")
+	name, data, err := splitTag(input)
+	if err != nil {
+		return nil, err
 	}
-	return curve.Point.FromAffineCompressed(input[i+1:])
+	return pointSetCanonicalBytes(name, data)
 }
 
 func pointMarshalText(point Point) ([]byte, error) {
@@ -313,27 +475,15 @@ func pointMarshalText(point Point) ([]byte, error) {
 }
 
 func pointUnmarshalText(input []byte) (Point, error) {
-	if len(input) < scalarBytes*2+1+len(P256Name) {
-		return nil, fmt.Errorf("invalid byte sequence")
-	}
-	sep := byte(':')
-	i := 0
-	for ; i < len(input); i++ {
-		if input[i] == sep {
-			break
-		}
-	}
-	name := string(input[:i])
-	curve := GetCurveByName(name)
-	if curve == nil {
-		return nil, fmt.Errorf("unrecognized curve")
-	}
-	buffer := make([]byte, (len(input)-i)/2)
-	_, err := hex.Decode(buffer, input[i+1:])
+	name, data, err := splitTag(input)
 	if err != nil {
 		return nil, err
 	}
-	return curve.Point.FromAffineCompressed(buffer)
+	buffer := make([]byte, hex.DecodedLen(len(data)))
+	if _, err = hex.Decode(buffer, data); err != nil {
+		return nil, err
+	}
+	return pointSetCanonicalBytes(name, buffer)
 }
 
 func pointMarshalJson(point Point) ([]byte, error) {
@@ -350,19 +500,11 @@ func pointUnmarshalJson(input []byte) (Point, error) {
 	if err != nil {
 		return nil, err
 	}
-	curve := GetCurveByName(m["type"])
-	if curve == nil {
-		return nil, fmt.Errorf("invalid type")
-	}
 	p, err := hex.DecodeString(m["value"])
 	if err != nil {
 		return nil, err
 	}
-	P, err := curve.Point.FromAffineCompressed(p)
-	if err != nil {
-		return nil, err
-	}
-	return P, nil
+	return pointSetCanonicalBytes(m["type"], p)
 }
 
 // Curve represents a named elliptic curve with a scalar field and point group
@@ -402,16 +544,30 @@ func (c Curve) ToEllipticCurve() (elliptic.Curve, error) {
 		return nil, err
 	case P256Name:
 		return NistP256Curve(), nil
+	case P384Name:
+		return elliptic.P384(), nil
 	case ED25519Name:
 		return nil, err
+	case ED448Name:
+		return nil, err
 	case PallasName:
 		return nil, err
+	case VestaName:
+		return nil, err
 	case BLS12377G1Name:
 		return nil, err
 	case BLS12377G2Name:
 		return nil, err
 	case BLS12377Name:
 		return nil, err
+	case Ristretto255Name:
+		return nil, err
+	case BN254G1Name:
+		return nil, err
+	case BN254G2Name:
+		return nil, err
+	case BN254Name:
+		return nil, err
 	default:
 		return nil, err
 	}
@@ -427,6 +583,30 @@ type PairingCurve struct {
 	Name    string
 }
 
+// MillerLoop returns the product of the Miller loops of the pairs of G1 and G2 points
+// without the final exponentiation, or an error if the curve does not support it
+func (c PairingCurve) MillerLoop(points ...PairingPoint) (Scalar, error) {
+	p, ok := c.PointG1.(MillerLoopPoint)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support Miller loops", c.Name)
+	}
+	f := p.MillerLoop(points...)
+	if f == nil {
+		return nil, fmt.Errorf("invalid pairs of points")
+	}
+	return f, nil
+}
+
+// FinalExponentiation maps the result of MillerLoop, or a product of such results, to
+// the target group, where it equals the product of the pairings
+func (c PairingCurve) FinalExponentiation(f Scalar) (Scalar, error) {
+	e, ok := f.(FinalExponentiator)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support final exponentiation", c.Name)
+	}
+	return e.FinalExponentiation(), nil
+}
+
 func (c PairingCurve) ScalarG1BaseMult(sc Scalar) PairingPoint {
 	return c.PointG1.Generator().Mul(sc).(PairingPoint)
 }
@@ -455,34 +635,6 @@ func (c PairingCurve) NewScalar() PairingScalar {
 	return c.Scalar.Zero().(PairingScalar)
 }
 
-// GetCurveByName returns the correct `Curve` given the name
-func GetCurveByName(name string) *Curve {
-	switch name {
-	case K256Name:
-		return K256()
-	case BLS12381G1Name:
-		return BLS12381G1()
-	case BLS12381G2Name:
-		return BLS12381G2()
-	case BLS12831Name:
-		return BLS12381G1()
-	case P256Name:
-		return P256()
-	case ED25519Name:
-		return ED25519()
-	case PallasName:
-		return PALLAS()
-	case BLS12377G1Name:
-		return BLS12377G1()
-	case BLS12377G2Name:
-		return BLS12377G2()
-	case BLS12377Name:
-		return BLS12377G1()
-	default:
-		return nil
-	}
-}
-
 func GetPairingCurveByName(name string) *PairingCurve {
 	switch name {
 	case BLS12381G1Name:
@@ -491,6 +643,18 @@ func GetPairingCurveByName(name string) *PairingCurve {
 		return BLS12381(BLS12381G2().NewIdentityPoint())
 	case BLS12831Name:
 		return BLS12381(BLS12381G1().NewIdentityPoint())
+	case BLS12377G1Name:
+		return BLS12377(BLS12377G1().NewIdentityPoint())
+	case BLS12377G2Name:
+		return BLS12377(BLS12377G2().NewIdentityPoint())
+	case BLS12377Name:
+		return BLS12377(BLS12377G1().NewIdentityPoint())
+	case BN254G1Name:
+		return BN254(BN254G1().NewIdentityPoint())
+	case BN254G2Name:
+		return BN254(BN254G2().NewIdentityPoint())
+	case BN254Name:
+		return BN254(BN254G1().NewIdentityPoint())
 	default:
 		return nil
 	}
@@ -583,6 +747,68 @@ func bls12377g2Init() {
 	}
 }
 
+// BLS12377 returns the BLS12-377 pairing curve
+func BLS12377(preferredPoint Point) *PairingCurve {
+	return &PairingCurve{
+		Scalar: &ScalarBls12377{
+			value: new(big.Int),
+			point: preferredPoint,
+		},
+		PointG1: new(PointBls12377G1).Identity().(PairingPoint),
+		PointG2: new(PointBls12377G2).Identity().(PairingPoint),
+		GT:      new(ScalarBls12377Gt).One(),
+		Name:    BLS12377Name,
+	}
+}
+
+// BN254G1 returns the BN254 curve with points in G1
+func BN254G1() *Curve {
+	bn254g1Initonce.Do(bn254g1Init)
+	return &bn254g1
+}
+
+func bn254g1Init() {
+	bn254g1 = Curve{
+		Scalar: &ScalarBn254{
+			value: new(big.Int),
+			point: new(PointBn254G1),
+		},
+		Point: new(PointBn254G1).Identity(),
+		Name:  BN254G1Name,
+	}
+}
+
+// BN254G2 returns the BN254 curve with points in G2
+func BN254G2() *Curve {
+	bn254g2Initonce.Do(bn254g2Init)
+	return &bn254g2
+}
+
+func bn254g2Init() {
+	bn254g2 = Curve{
+		Scalar: &ScalarBn254{
+			value: new(big.Int),
+			point: new(PointBn254G2),
+		},
+		Point: new(PointBn254G2).Identity(),
+		Name:  BN254G2Name,
+	}
+}
+
+// BN254 returns the BN254 pairing curve, also known as alt_bn128
+func BN254(preferredPoint Point) *PairingCurve {
+	return &PairingCurve{
+		Scalar: &ScalarBn254{
+			value: new(big.Int),
+			point: preferredPoint,
+		},
+		PointG1: new(PointBn254G1).Identity().(PairingPoint),
+		PointG2: new(PointBn254G2).Identity().(PairingPoint),
+		GT:      new(ScalarBn254Gt).One(),
+		Name:    BN254Name,
+	}
+}
+
 // K256 returns the secp256k1 curve
 func K256() *Curve {
 	k256Initonce.Do(k256Init)
@@ -610,6 +836,20 @@ func p256Init() {
 	}
 }
 
+// P384 returns NIST P-384, also known as secp384r1
+func P384() *Curve {
+	p384Initonce.Do(p384Init)
+	return &p384
+}
+
+func p384Init() {
+	p384 = Curve{
+		Scalar: new(ScalarP384).Zero(),
+		Point:  new(PointP384).Identity(),
+		Name:   P384Name,
+	}
+}
+
 func ED25519() *Curve {
 	ed25519Initonce.Do(ed25519Init)
 	return &ed25519
@@ -623,6 +863,34 @@ func ed25519Init() {
 	}
 }
 
+// ED448 returns edwards448, the curve of Ed448 in RFC 8032
+func ED448() *Curve {
+	ed448Initonce.Do(ed448Init)
+	return &ed448
+}
+
+func ed448Init() {
+	ed448 = Curve{
+		Scalar: new(ScalarEd448).Zero(),
+		Point:  new(PointEd448).Identity(),
+		Name:   ED448Name,
+	}
+}
+
+// RISTRETTO255 returns the ristretto255 prime order group
+func RISTRETTO255() *Curve {
+	ristretto255Initonce.Do(ristretto255Init)
+	return &ristretto255
+}
+
+func ristretto255Init() {
+	ristretto255 = Curve{
+		Scalar: new(ScalarRistretto255).Zero(),
+		Point:  new(PointRistretto255).Identity(),
+		Name:   Ristretto255Name,
+	}
+}
+
 func PALLAS() *Curve {
 	pallasInitonce.Do(pallasInit)
 	return &pallas
@@ -636,6 +904,20 @@ func pallasInit() {
 	}
 }
 
+// VESTA returns the Vesta curve, the cycle companion of PALLAS
+func VESTA() *Curve {
+	vestaInitonce.Do(vestaInit)
+	return &vesta
+}
+
+func vestaInit() {
+	vesta = Curve{
+		Scalar: new(ScalarVesta).Zero(),
+		Point:  new(PointVesta).Identity(),
+		Name:   VestaName,
+	}
+}
+
 // https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-11#appendix-G.2.1
 func osswu3mod4(u *big.Int, p *sswuParams) (x, y *big.Int) {
 	params := p.Params
@@ -778,7 +1060,7 @@ type sswuParams struct {
 // The algorithm works as follows:
 //
 // Let `n` be a number of point-scalar pairs.
-// Let `w` be a window of bits (6..8, chosen based on `n`, see cost factor).
+// Let `w` be a window of bits (chosen based on `n`, see cost factor).
 //
 // 1. Prepare `2^(w-1) - 1` buckets with indices `[1..2^(w-1))` initialized with identity points.
 //    Bucket 0 is not needed as it would contain points multiplied by 0.
@@ -811,19 +1093,25 @@ type sswuParams struct {
 // However, if `w` is too big and `n` is not too big, then `(2^w/2)*A` could dominate.
 // Therefore, the optimal choice of `w` grows slowly as `n` grows.
 //
-// For constant time we use a fixed window of 6
+// The window is chosen by native.PippengerWindowSize, it depends only on `n` and not on the scalars
 //
 // This algorithm is adapted from section 4 of <https://eprint.iacr.org/2012/549.pdf>.
 // and https://cacr.uwaterloo.ca/techreports/2010/cacr2010-26.pdf
 func sumOfProductsPippenger(points []Point, scalars []*big.Int) Point {
+	return sumOfProductsPippengerBits(points, scalars, 256)
+}
+
+// sumOfProductsPippengerBits is sumOfProductsPippenger for scalars of up to `bits` bits,
+// e.g. 384 for P-384 or 446 for Ed448
+func sumOfProductsPippengerBits(points []Point, scalars []*big.Int, bits int) Point {
 	if len(points) != len(scalars) {
 		return nil
 	}
 
-	const w = 6
+	w := native.PippengerWindowSize(len(points))
 
-	bucketSize := (1 << w) - 1
-	windows := make([]Point, 255/w+1)
+	bucketSize := (1 << uint(w)) - 1
+	windows := make([]Point, (bits-1)/w+1)
 	for i := range windows {
 		windows[i] = points[0].Identity()
 	}
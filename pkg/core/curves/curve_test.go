@@ -0,0 +1,291 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSumOfProductsMatchesNaive(t *testing.T) {
+	curves := []*Curve{K256(), P256(), P384(), ED25519(), ED448(), PALLAS(), VESTA(), BLS12381G1(), BLS12381G2(), BLS12377G1(), BN254G1()}
+	// sizes cross several window widths
+	for _, curve := range curves {
+		for _, n := range []int{1, 3, 17, 70} {
+			points := make([]Point, n)
+			scalars := make([]Scalar, n)
+			expected := curve.NewIdentityPoint()
+			for i := range points {
+				points[i] = curve.Point.Random(crand.Reader)
+				scalars[i] = curve.Scalar.Random(crand.Reader)
+				expected = expected.Add(points[i].Mul(scalars[i]))
+			}
+			actual := curve.Point.SumOfProducts(points, scalars)
+			require.NotNil(t, actual, curve.Name)
+			require.True(t, expected.Equal(actual), "%s with %d points", curve.Name, n)
+		}
+	}
+}
+
+var encodingCurves = []*Curve{
+	K256(), P256(), P384(), ED25519(), PALLAS(), VESTA(), RISTRETTO255(),
+	BLS12381G1(), BLS12381G2(), BLS12377G1(), BLS12377G2(), BN254G1(), BN254G2(),
+}
+
+// roundTrip decodes `value` with the Marshal* methods into a new value of the same concrete type
+func roundTrip(t *testing.T, value interface{}) {
+	fresh := func() interface{} { return reflect.New(reflect.TypeOf(value).Elem()).Interface() }
+
+	data, err := value.(encoding.BinaryMarshaler).MarshalBinary()
+	require.NoError(t, err)
+	out := fresh()
+	require.NoError(t, out.(encoding.BinaryUnmarshaler).UnmarshalBinary(data))
+	again, err := out.(encoding.BinaryMarshaler).MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+
+	text, err := value.(encoding.TextMarshaler).MarshalText()
+	require.NoError(t, err)
+	out = fresh()
+	require.NoError(t, out.(encoding.TextUnmarshaler).UnmarshalText(text))
+	againText, err := out.(encoding.TextMarshaler).MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, text, againText)
+
+	js, err := json.Marshal(value)
+	require.NoError(t, err)
+	out = fresh()
+	require.NoError(t, json.Unmarshal(js, out))
+	againJs, err := json.Marshal(out)
+	require.NoError(t, err)
+	require.Equal(t, js, againJs)
+}
+
+func TestPointAndScalarEncodings(t *testing.T) {
+	for _, curve := range encodingCurves {
+		roundTrip(t, curve.Point.Random(crand.Reader))
+		roundTrip(t, curve.Point.Identity())
+		roundTrip(t, curve.Scalar.Random(crand.Reader))
+		roundTrip(t, curve.Scalar.Zero())
+	}
+	for _, c := range []*PairingCurve{BLS12381(BLS12381G1().Point), BLS12377(BLS12377G1().Point), BN254(BN254G1().Point)} {
+		r := c.Scalar.Random(crand.Reader)
+		gt := c.PointG1.Generator().Mul(r).(PairingPoint).Pairing(c.PointG2.Generator().(PairingPoint))
+		roundTrip(t, gt)
+	}
+}
+
+func TestEncodingsInStructs(t *testing.T) {
+	type keyShare struct {
+		Id     uint32
+		Secret *ScalarK256
+		Public *PointK256
+	}
+	curve := K256()
+	s := curve.Scalar.Random(crand.Reader)
+	in := keyShare{Id: 3, Secret: s.(*ScalarK256), Public: curve.ScalarBaseMult(s).(*PointK256)}
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	var out keyShare
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.Equal(t, 0, in.Secret.Cmp(out.Secret))
+	require.True(t, in.Public.Equal(out.Public))
+
+	// Interface fields marshal through the concrete type
+	withInterfaces := struct {
+		Secret Scalar
+		Public Point
+	}{s, curve.ScalarBaseMult(s)}
+	data, err = json.Marshal(withInterfaces)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.Equal(t, 0, in.Secret.Cmp(out.Secret))
+	require.True(t, in.Public.Equal(out.Public))
+}
+
+func TestEncodingsRejectMalformedInput(t *testing.T) {
+	curve := P256()
+	p := curve.Point.Random(crand.Reader).(*PointP256)
+	data, err := p.MarshalBinary()
+	require.NoError(t, err)
+	s := curve.Scalar.Random(crand.Reader).(*ScalarP256)
+	sdata, err := s.MarshalBinary()
+	require.NoError(t, err)
+
+	malformed := func(data []byte) [][]byte {
+		return [][]byte{
+			nil,
+			[]byte(":"),
+			data[:len(data)-1],
+			append(append([]byte{}, data...), 0),
+			data[len(P256Name):],
+			append([]byte("unknown"), data[len(P256Name):]...),
+		}
+	}
+	for _, input := range malformed(data) {
+		require.NotPanics(t, func() {
+			require.Error(t, new(PointP256).UnmarshalBinary(input))
+		})
+	}
+	for _, input := range malformed(sdata) {
+		require.NotPanics(t, func() {
+			require.Error(t, new(ScalarP256).UnmarshalBinary(input))
+		})
+	}
+	// Encodings of another curve
+	require.Error(t, new(PointK256).UnmarshalBinary(data))
+	require.Error(t, new(ScalarK256).UnmarshalBinary(sdata))
+
+	// Non-canonical values
+	order := new(big.Int).Add(curve.Scalar.One().Neg().BigInt(), big.NewInt(1))
+	nonCanonical := append([]byte(P256Name+":"), make([]byte, 32)...)
+	order.FillBytes(nonCanonical[len(P256Name)+1:])
+	require.Error(t, new(ScalarP256).UnmarshalBinary(nonCanonical))
+	text, err := p.MarshalText()
+	require.NoError(t, err)
+	require.Error(t, new(PointP256).UnmarshalText(append(text, '0')))
+	require.Error(t, new(PointP256).UnmarshalText(text[:len(text)-2]))
+	require.Error(t, new(PointP256).UnmarshalJSON([]byte(`{"type":"P-256","value":"zz"}`)))
+}
+
+func TestPairingCurveMillerLoop(t *testing.T) {
+	pairingCurves := []*PairingCurve{
+		BLS12381(BLS12381G1().NewGeneratorPoint()),
+		BLS12377(BLS12377G1().NewGeneratorPoint()),
+		BN254(BN254G1().NewGeneratorPoint()),
+	}
+	for _, curve := range pairingCurves {
+		a := curve.Scalar.Random(crand.Reader)
+		b := curve.Scalar.Random(crand.Reader)
+		g1 := curve.NewG1GeneratorPoint()
+		g2 := curve.NewG2GeneratorPoint()
+		aG1 := curve.ScalarG1BaseMult(a)
+		bG2 := curve.ScalarG2BaseMult(b)
+
+		// Two equations checked with a single final exponentiation
+		f1, err := curve.MillerLoop(aG1, g2, g1.Neg().(PairingPoint), curve.ScalarG2BaseMult(a))
+		require.NoError(t, err, curve.Name)
+		f2, err := curve.MillerLoop(g1.Neg().(PairingPoint), bG2, curve.ScalarG1BaseMult(b), g2)
+		require.NoError(t, err, curve.Name)
+		res, err := curve.FinalExponentiation(f1.Mul(f2))
+		require.NoError(t, err, curve.Name)
+		require.True(t, res.IsOne(), curve.Name)
+
+		// The final exponentiation of a Miller loop is the product of the pairings
+		f, err := curve.MillerLoop(aG1, g2, g1, bG2)
+		require.NoError(t, err, curve.Name)
+		res, err = curve.FinalExponentiation(f)
+		require.NoError(t, err, curve.Name)
+		require.Equal(t, 0, res.Cmp(g1.MultiPairing(aG1, g2, g1, bG2)), curve.Name)
+		require.Equal(t, 0, res.Cmp(aG1.Pairing(g2).Mul(g1.Pairing(bG2))), curve.Name)
+
+		_, err = curve.MillerLoop(g1)
+		require.Error(t, err, curve.Name)
+		_, err = curve.MillerLoop(g2, g1)
+		require.Error(t, err, curve.Name)
+		_, err = curve.FinalExponentiation(a)
+		require.Error(t, err, curve.Name)
+	}
+}
+
+func TestScalarSetBytesWide(t *testing.T) {
+	curves := []*Curve{K256(), P256(), P384(), ED25519(), ED448(), RISTRETTO255(), PALLAS(), VESTA(), BLS12381G1(), BLS12377G1(), BN254G1()}
+	for _, curve := range curves {
+		order := new(big.Int).Add(curve.Scalar.New(-1).BigInt(), big.NewInt(1))
+
+		zero, err := curve.Scalar.SetBytesWide(make([]byte, WideScalarBytes))
+		require.NoError(t, err, curve.Name)
+		require.True(t, zero.IsZero(), curve.Name)
+
+		for i := 0; i < 10; i++ {
+			wide := make([]byte, WideScalarBytes)
+			_, _ = crand.Read(wide)
+			s, err := curve.Scalar.SetBytesWide(wide)
+			require.NoError(t, err, curve.Name)
+			// The input is reduced as a big-endian or a little-endian integer depending on the curve
+			be := new(big.Int).Mod(new(big.Int).SetBytes(wide), order)
+			le := new(big.Int).Mod(new(big.Int).SetBytes(reverseBytes(wide)), order)
+			require.True(t, s.BigInt().Cmp(be) == 0 || s.BigInt().Cmp(le) == 0, curve.Name)
+		}
+
+		_, err = curve.Scalar.SetBytesWide(make([]byte, 20))
+		require.Error(t, err, curve.Name)
+	}
+}
+
+func reverseBytes(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i, b := range in {
+		out[len(in)-1-i] = b
+	}
+	return out
+}
+
+func TestVarTimeMatchesConstantTime(t *testing.T) {
+	curves := []*Curve{K256(), P256(), P384(), ED25519(), ED448(), RISTRETTO255(), PALLAS(), VESTA(), BLS12381G1(), BLS12381G2(), BLS12377G1(), BN254G1()}
+	for _, curve := range curves {
+		for _, n := range []int{1, 3, 40} {
+			points := make([]Point, n)
+			scalars := make([]Scalar, n)
+			for i := range points {
+				points[i] = curve.Point.Random(crand.Reader)
+				scalars[i] = curve.Scalar.Random(crand.Reader)
+			}
+			scalars[0] = curve.Scalar.Zero()
+			if n > 1 {
+				scalars[1] = curve.Scalar.One()
+				scalars[2] = curve.Scalar.One().Neg()
+			}
+			for i := range points {
+				require.True(t, points[i].Mul(scalars[i]).Equal(VarTimeMul(points[i], scalars[i])), curve.Name)
+			}
+			expected := points[0].SumOfProducts(points, scalars)
+			require.True(t, expected.Equal(VarTimeSumOfProducts(points, scalars)), curve.Name)
+		}
+	}
+	_, ok := K256().Point.(VarTimePoint)
+	require.True(t, ok)
+	require.Nil(t, VarTimeSumOfProducts(nil, nil))
+}
+
+func BenchmarkVarTimeMul(b *testing.B) {
+	curve := K256()
+	p := curve.Point.Random(crand.Reader)
+	s := curve.Scalar.Random(crand.Reader)
+	b.Run("constant time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Mul(s)
+		}
+	})
+	b.Run("variable time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			VarTimeMul(p, s)
+		}
+	})
+}
+
+func BenchmarkVarTimeSumOfProducts(b *testing.B) {
+	curve := K256()
+	points := []Point{curve.Point.Generator(), curve.Point.Random(crand.Reader)}
+	scalars := []Scalar{curve.Scalar.Random(crand.Reader), curve.Scalar.Random(crand.Reader)}
+	b.Run("constant time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			points[0].SumOfProducts(points, scalars)
+		}
+	})
+	b.Run("variable time", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			VarTimeSumOfProducts(points, scalars)
+		}
+	})
+}
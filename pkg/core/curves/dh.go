@@ -0,0 +1,77 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"fmt"
+
+	"filippo.io/edwards25519"
+)
+
+var (
+	// ErrIdentityPoint is returned when a Diffie-Hellman input or result is the identity
+	ErrIdentityPoint = fmt.Errorf("identity point")
+	// ErrLowOrderPoint is returned when a Diffie-Hellman input lies in the small torsion subgroup
+	ErrLowOrderPoint = fmt.Errorf("low order point")
+	// ErrNotInSubgroup is returned when a Diffie-Hellman input is not in the prime order subgroup
+	ErrNotInSubgroup = fmt.Errorf("point not in the prime order subgroup")
+)
+
+// ValidateDHPoint checks that a point received from a peer is safe to use in a
+// Diffie-Hellman style computation: it must not be the identity and must lie in
+// the prime order subgroup, so the result depends on both parties' secrets.
+func ValidateDHPoint(p Point) error {
+	if p == nil {
+		return fmt.Errorf("invalid point")
+	}
+	if p.IsIdentity() {
+		return ErrIdentityPoint
+	}
+	if pt, ok := p.(*PointEd25519); ok {
+		if edwards25519.NewIdentityPoint().MultByCofactor(pt.value).Equal(edwards25519.NewIdentityPoint()) == 1 {
+			return ErrLowOrderPoint
+		}
+	}
+	if !IsTorsionFree(p) {
+		return ErrNotInSubgroup
+	}
+	return nil
+}
+
+// DH computes s * p after validating the peer's point `p` and rejects
+// non-contributory results, such as when the secret `s` is zero
+func DH(s Scalar, p Point) (Point, error) {
+	if s == nil {
+		return nil, fmt.Errorf("invalid scalar")
+	}
+	if err := ValidateDHPoint(p); err != nil {
+		return nil, err
+	}
+	res := p.Mul(s)
+	if res == nil {
+		return nil, fmt.Errorf("scalar and point are from different curves")
+	}
+	if res.IsIdentity() {
+		return nil, ErrIdentityPoint
+	}
+	return res, nil
+}
+
+// ValidateDHEcPoint is ValidateDHPoint for points on the prime order
+// Weierstrass curves supported by EcPoint
+func ValidateDHEcPoint(p *EcPoint) error {
+	if p == nil || p.Curve == nil || p.X == nil || p.Y == nil {
+		return fmt.Errorf("invalid point")
+	}
+	if p.IsIdentity() {
+		return ErrIdentityPoint
+	}
+	if !p.IsOnCurve() {
+		return ErrNotInSubgroup
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDHPoint(t *testing.T) {
+	for _, curve := range []*Curve{K256(), P256(), ED25519(), PALLAS(), BLS12381G1(), BLS12381G2()} {
+		require.NoError(t, ValidateDHPoint(curve.Point.Random(crand.Reader)), curve.Name)
+		require.ErrorIs(t, ValidateDHPoint(curve.NewIdentityPoint()), ErrIdentityPoint, curve.Name)
+	}
+	require.Error(t, ValidateDHPoint(nil))
+}
+
+func TestValidateDHPointEd25519Torsion(t *testing.T) {
+	curve := ED25519()
+	// y = 0 is a point of order 4, which decoding rejects
+	_, err := curve.Point.FromAffineCompressed(make([]byte, 32))
+	require.ErrorIs(t, err, ErrNotInSubgroup)
+	lowOrder := ed25519LowOrder(t, make([]byte, 32))
+	require.ErrorIs(t, ValidateDHPoint(lowOrder), ErrLowOrderPoint)
+
+	mixed := curve.Point.Random(crand.Reader).Add(lowOrder)
+	require.ErrorIs(t, ValidateDHPoint(mixed), ErrNotInSubgroup)
+
+	// A low order point makes the result independent of most of the secret
+	s := curve.Scalar.New(4)
+	_, err = DH(s, lowOrder)
+	require.ErrorIs(t, err, ErrLowOrderPoint)
+}
+
+func TestDH(t *testing.T) {
+	curve := K256()
+	a := curve.Scalar.Random(crand.Reader)
+	b := curve.Scalar.Random(crand.Reader)
+	pubA := curve.ScalarBaseMult(a)
+	pubB := curve.ScalarBaseMult(b)
+
+	sharedA, err := DH(a, pubB)
+	require.NoError(t, err)
+	sharedB, err := DH(b, pubA)
+	require.NoError(t, err)
+	require.True(t, sharedA.Equal(sharedB))
+
+	_, err = DH(curve.Scalar.Zero(), pubB)
+	require.ErrorIs(t, err, ErrIdentityPoint)
+	_, err = DH(a, curve.NewIdentityPoint())
+	require.ErrorIs(t, err, ErrIdentityPoint)
+	_, err = DH(nil, pubB)
+	require.Error(t, err)
+	_, err = DH(P256().Scalar.New(2), pubB)
+	require.Error(t, err)
+}
+
+func TestValidateDHEcPoint(t *testing.T) {
+	curve := btcec.S256()
+	k, err := NewScalarBaseMult(curve, big.NewInt(7))
+	require.NoError(t, err)
+	require.NoError(t, ValidateDHEcPoint(k))
+
+	identity := &EcPoint{Curve: curve, X: big.NewInt(0), Y: big.NewInt(0)}
+	require.ErrorIs(t, ValidateDHEcPoint(identity), ErrIdentityPoint)
+
+	offCurve := &EcPoint{Curve: curve, X: k.X, Y: new(big.Int).Add(k.Y, big.NewInt(1))}
+	require.ErrorIs(t, ValidateDHEcPoint(offCurve), ErrNotInSubgroup)
+	require.Error(t, ValidateDHEcPoint(nil))
+}
+
+func TestX25519LowOrderError(t *testing.T) {
+	scalar := make([]byte, X25519ScalarSize)
+	scalar[0] = 1
+	_, err := X25519(scalar, make([]byte, X25519PointSize))
+	require.ErrorIs(t, err, ErrLowOrderPoint)
+}
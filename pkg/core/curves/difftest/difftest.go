@@ -0,0 +1,527 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package difftest cross-checks the native curve arithmetic against independent
+// implementations, so that the field and group operations can be fuzzed continuously
+// for divergences:
+//
+//   - secp256k1 against github.com/btcsuite/btcd/btcec
+//   - P-256 against crypto/elliptic
+//   - ed25519 against filippo.io/edwards25519
+//   - base and scalar field arithmetic against math/big
+//
+// Each Check function derives two scalars and a candidate point encoding from its input
+// and returns an error describing the first operation on which the implementations
+// disagree. The Fuzz functions wrap them as go-fuzz entry points, e.g.
+//
+//	go-fuzz-build -func FuzzK256 github.com/etclab/kryptology/pkg/core/curves/difftest
+//
+// and the package tests carry the same targets for go test -fuzz on Go 1.18 and newer:
+//
+//	go test -run XXX -fuzz FuzzK256 github.com/etclab/kryptology/pkg/core/curves/difftest
+package difftest
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+	k256fp "github.com/etclab/kryptology/pkg/core/curves/native/k256/fp"
+	p256fp "github.com/etclab/kryptology/pkg/core/curves/native/p256/fp"
+)
+
+// ed25519L is the order of the ed25519 prime order subgroup
+var ed25519L, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+
+// input holds the values derived from the fuzzer's bytes
+type input struct {
+	// a and b are 32-byte big-endian values, unreduced
+	a, b []byte
+	// enc is a candidate point encoding of the curve's compressed length
+	enc []byte
+}
+
+// newInput splits data into two 32-byte values and a point encoding of encLen bytes,
+// zero padding short inputs
+func newInput(data []byte, encLen int) *input {
+	buf := make([]byte, 64+encLen)
+	copy(buf, data)
+	return &input{a: buf[:32], b: buf[32:64], enc: buf[64:]}
+}
+
+// CheckK256 compares curves.K256 and its native base field with btcec and math/big
+func CheckK256(data []byte) error {
+	in := newInput(data, 33)
+	ref := btcec.S256()
+	if err := checkField(k256fp.K256FpNew, ref.P, in); err != nil {
+		return fmt.Errorf("k256 base field: %v", err)
+	}
+	curve := curves.K256()
+	if err := checkScalars(curve, ref.N, in, true); err != nil {
+		return fmt.Errorf("k256 scalar: %v", err)
+	}
+	decompress := func(enc []byte) (*big.Int, *big.Int, bool) {
+		// secp256k1 has no point with x = 0, so curves.K256 uses it to encode the identity
+		if (enc[0] == 2 || enc[0] == 3) && new(big.Int).SetBytes(enc[1:]).Sign() == 0 {
+			return new(big.Int), new(big.Int), true
+		}
+		pk, err := btcec.ParsePubKey(enc, ref)
+		if err != nil {
+			return nil, nil, false
+		}
+		return pk.X, pk.Y, true
+	}
+	if err := checkWeierstrass(curve, ref, decompress, in); err != nil {
+		return fmt.Errorf("k256 point: %v", err)
+	}
+	return nil
+}
+
+// CheckP256 compares curves.P256 and its native base field with crypto/elliptic and math/big
+func CheckP256(data []byte) error {
+	in := newInput(data, 33)
+	ref := elliptic.P256()
+	params := ref.Params()
+	if err := checkField(p256fp.P256FpNew, params.P, in); err != nil {
+		return fmt.Errorf("p256 base field: %v", err)
+	}
+	curve := curves.P256()
+	if err := checkScalars(curve, params.N, in, true); err != nil {
+		return fmt.Errorf("p256 scalar: %v", err)
+	}
+	decompress := func(enc []byte) (*big.Int, *big.Int, bool) {
+		x, y := elliptic.UnmarshalCompressed(ref, enc)
+		return x, y, x != nil
+	}
+	if err := checkWeierstrass(curve, ref, decompress, in); err != nil {
+		return fmt.Errorf("p256 point: %v", err)
+	}
+	return nil
+}
+
+// CheckEd25519 compares curves.ED25519 with filippo.io/edwards25519 and math/big
+func CheckEd25519(data []byte) error {
+	in := newInput(data, 32)
+	curve := curves.ED25519()
+	if err := checkScalars(curve, ed25519L, in, false); err != nil {
+		return fmt.Errorf("ed25519 scalar: %v", err)
+	}
+	if err := checkEdwards(curve, in); err != nil {
+		return fmt.Errorf("ed25519 point: %v", err)
+	}
+	return nil
+}
+
+// FuzzK256 is a go-fuzz entry point that panics when CheckK256 fails
+func FuzzK256(data []byte) int {
+	return fuzz(CheckK256, data)
+}
+
+// FuzzP256 is a go-fuzz entry point that panics when CheckP256 fails
+func FuzzP256(data []byte) int {
+	return fuzz(CheckP256, data)
+}
+
+// FuzzEd25519 is a go-fuzz entry point that panics when CheckEd25519 fails
+func FuzzEd25519(data []byte) int {
+	return fuzz(CheckEd25519, data)
+}
+
+func fuzz(check func([]byte) error, data []byte) int {
+	if err := check(data); err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// Seeds returns inputs that exercise the edges of every check: zero, one, the moduli
+// minus one and all ones, combined with the generator encodings
+func Seeds() [][]byte {
+	edges := [][]byte{
+		make([]byte, 32),
+		new(big.Int).SetInt64(1).FillBytes(make([]byte, 32)),
+		bytes.Repeat([]byte{0xff}, 32),
+		new(big.Int).Sub(btcec.S256().P, big.NewInt(1)).FillBytes(make([]byte, 32)),
+		new(big.Int).Sub(btcec.S256().N, big.NewInt(1)).FillBytes(make([]byte, 32)),
+		new(big.Int).Sub(elliptic.P256().Params().P, big.NewInt(1)).FillBytes(make([]byte, 32)),
+		new(big.Int).Sub(elliptic.P256().Params().N, big.NewInt(1)).FillBytes(make([]byte, 32)),
+		new(big.Int).Sub(ed25519L, big.NewInt(1)).FillBytes(make([]byte, 32)),
+	}
+	encodings := [][]byte{
+		curves.K256().Point.Generator().ToAffineCompressed(),
+		curves.P256().Point.Generator().ToAffineCompressed(),
+		curves.ED25519().Point.Generator().ToAffineCompressed(),
+	}
+	var out [][]byte
+	for _, a := range edges {
+		for _, b := range edges {
+			for _, enc := range encodings {
+				seed := append(append(append([]byte{}, a...), b...), enc...)
+				out = append(out, seed)
+			}
+		}
+	}
+	return out
+}
+
+// checkField compares the native field created by newField with arithmetic modulo p
+func checkField(newField func() *native.Field, p *big.Int, in *input) error {
+	a := new(big.Int).SetBytes(in.a)
+	b := new(big.Int).SetBytes(in.b)
+
+	// SetBytes takes little-endian input and must reject values that are not reduced
+	var le [native.FieldBytes]byte
+	copy(le[:], reverse(in.a))
+	fa, err := newField().SetBytes(&le)
+	if (err == nil) != (a.Cmp(p) < 0) {
+		return fmt.Errorf("SetBytes accepted %x: %v", in.a, err == nil)
+	}
+	if err == nil && fa.BigInt().Cmp(a) != 0 {
+		return fmt.Errorf("SetBytes(%x)", in.a)
+	}
+
+	a.Mod(a, p)
+	b.Mod(b, p)
+	fa = newField().SetBigInt(a)
+	fb := newField().SetBigInt(b)
+	if fa.BigInt().Cmp(a) != 0 {
+		return fmt.Errorf("SetBigInt(%x)", a)
+	}
+	if out := fa.Bytes(); !bytes.Equal(reverse(out[:]), a.FillBytes(make([]byte, native.FieldBytes))) {
+		return fmt.Errorf("Bytes(%x)", a)
+	}
+
+	expect := func(op string, got *native.Field, want *big.Int) error {
+		want.Mod(want, p)
+		if got.BigInt().Cmp(want) != 0 {
+			return fmt.Errorf("%s(%x, %x) = %x, want %x", op, a, b, got.BigInt(), want)
+		}
+		return nil
+	}
+	if err := expect("Add", newField().Add(fa, fb), new(big.Int).Add(a, b)); err != nil {
+		return err
+	}
+	if err := expect("Sub", newField().Sub(fa, fb), new(big.Int).Sub(a, b)); err != nil {
+		return err
+	}
+	if err := expect("Mul", newField().Mul(fa, fb), new(big.Int).Mul(a, b)); err != nil {
+		return err
+	}
+	if err := expect("Square", newField().Square(fa), new(big.Int).Mul(a, a)); err != nil {
+		return err
+	}
+	if err := expect("Double", newField().Double(fa), new(big.Int).Lsh(a, 1)); err != nil {
+		return err
+	}
+	if err := expect("Neg", newField().Neg(fa), new(big.Int).Neg(a)); err != nil {
+		return err
+	}
+	if (fa.IsZero() == 1) != (a.Sign() == 0) {
+		return fmt.Errorf("IsZero(%x)", a)
+	}
+	// Cmp orders the Montgomery representations, so only equality is comparable
+	if (fa.Cmp(fb) == 0) != (a.Cmp(b) == 0) || (fa.Equal(fb) == 1) != (a.Cmp(b) == 0) {
+		return fmt.Errorf("Cmp(%x, %x)", a, b)
+	}
+
+	inv, ok := newField().Invert(fa)
+	if ok != (a.Sign() != 0) {
+		return fmt.Errorf("Invert(%x) succeeded: %v", a, ok)
+	}
+	if ok {
+		if err := expect("Invert", inv, new(big.Int).ModInverse(a, p)); err != nil {
+			return err
+		}
+	}
+
+	root, ok := newField().Sqrt(fa)
+	if ok != (big.Jacobi(a, p) >= 0) {
+		return fmt.Errorf("Sqrt(%x) succeeded: %v", a, ok)
+	}
+	if ok {
+		if err := expect("Sqrt", newField().Square(root), new(big.Int).Set(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkScalars compares curve.Scalar with arithmetic modulo n. The canonical encoding is
+// big-endian when bigEndian is set and little-endian otherwise
+func checkScalars(curve *curves.Curve, n *big.Int, in *input, bigEndian bool) error {
+	a := new(big.Int).SetBytes(in.a)
+	b := new(big.Int).SetBytes(in.b)
+
+	enc := in.a
+	if !bigEndian {
+		enc = reverse(in.a)
+	}
+	sa, err := curve.Scalar.SetBytes(enc)
+	if (err == nil) != (a.Cmp(n) < 0) {
+		return fmt.Errorf("SetBytes accepted %x: %v", enc, err == nil)
+	}
+	if err == nil && !bytes.Equal(sa.Bytes(), enc) {
+		return fmt.Errorf("Bytes(SetBytes(%x)) = %x", enc, sa.Bytes())
+	}
+
+	a.Mod(a, n)
+	b.Mod(b, n)
+	sa, err = curve.Scalar.SetBigInt(a)
+	if err != nil {
+		return fmt.Errorf("SetBigInt(%x): %v", a, err)
+	}
+	sb, err := curve.Scalar.SetBigInt(b)
+	if err != nil {
+		return fmt.Errorf("SetBigInt(%x): %v", b, err)
+	}
+
+	expect := func(op string, got curves.Scalar, want *big.Int) error {
+		want.Mod(want, n)
+		if got.BigInt().Cmp(want) != 0 {
+			return fmt.Errorf("%s(%x, %x) = %x, want %x", op, a, b, got.BigInt(), want)
+		}
+		return nil
+	}
+	if err := expect("Add", sa.Add(sb), new(big.Int).Add(a, b)); err != nil {
+		return err
+	}
+	if err := expect("Sub", sa.Sub(sb), new(big.Int).Sub(a, b)); err != nil {
+		return err
+	}
+	if err := expect("Mul", sa.Mul(sb), new(big.Int).Mul(a, b)); err != nil {
+		return err
+	}
+	if err := expect("MulAdd", sa.MulAdd(sb, sa), new(big.Int).Add(new(big.Int).Mul(a, b), a)); err != nil {
+		return err
+	}
+	if err := expect("Square", sa.Square(), new(big.Int).Mul(a, a)); err != nil {
+		return err
+	}
+	if err := expect("Neg", sa.Neg(), new(big.Int).Neg(a)); err != nil {
+		return err
+	}
+	if sa.IsZero() != (a.Sign() == 0) || (sa.Cmp(sb) == 0) != (a.Cmp(b) == 0) {
+		return fmt.Errorf("Cmp(%x, %x)", a, b)
+	}
+
+	inv, err := sa.Invert()
+	if (err == nil) != (a.Sign() != 0) {
+		return fmt.Errorf("Invert(%x) succeeded: %v", a, err == nil)
+	}
+	if err == nil {
+		if err := expect("Invert", inv, new(big.Int).ModInverse(a, n)); err != nil {
+			return err
+		}
+	}
+
+	root, err := sa.Sqrt()
+	if (err == nil) != (big.Jacobi(a, n) >= 0) {
+		return fmt.Errorf("Sqrt(%x) succeeded: %v", a, err == nil)
+	}
+	if err == nil {
+		if err := expect("Sqrt", root.Square(), new(big.Int).Set(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWeierstrass compares the group operations of a short Weierstrass curve with ref.
+// decompress parses a SEC 1 compressed point with the reference implementation
+func checkWeierstrass(
+	curve *curves.Curve,
+	ref elliptic.Curve,
+	decompress func([]byte) (*big.Int, *big.Int, bool),
+	in *input,
+) error {
+	params := ref.Params()
+	byteLen := (params.BitSize + 7) / 8
+	a := new(big.Int).Mod(new(big.Int).SetBytes(in.a), params.N)
+	b := new(big.Int).Mod(new(big.Int).SetBytes(in.b), params.N)
+	sa, _ := curve.Scalar.SetBigInt(a)
+	sb, _ := curve.Scalar.SetBigInt(b)
+
+	// equal reports whether p is the affine point (x, y), where (0, 0) is the identity
+	equal := func(p curves.Point, x, y *big.Int) bool {
+		if x.Sign() == 0 && y.Sign() == 0 {
+			return p.IsIdentity()
+		}
+		if p.IsIdentity() {
+			return false
+		}
+		want := append([]byte{4}, x.FillBytes(make([]byte, byteLen))...)
+		want = append(want, y.FillBytes(make([]byte, byteLen))...)
+		return bytes.Equal(p.ToAffineUncompressed(), want)
+	}
+
+	pa := curve.ScalarBaseMult(sa)
+	ax, ay := ref.ScalarBaseMult(a.FillBytes(make([]byte, byteLen)))
+	if !equal(pa, ax, ay) {
+		return fmt.Errorf("ScalarBaseMult(%x)", a)
+	}
+	pb := pa.Mul(sb)
+	bx, by := ref.ScalarMult(ax, ay, b.FillBytes(make([]byte, byteLen)))
+	if !equal(pb, bx, by) {
+		return fmt.Errorf("Mul(%x * G, %x)", a, b)
+	}
+	x, y := ref.Add(ax, ay, bx, by)
+	if !equal(pa.Add(pb), x, y) {
+		return fmt.Errorf("Add(%x * G, %x * %x * G)", a, b, a)
+	}
+	x, y = ref.Add(ax, ay, bx, negY(bx, by, params.P))
+	if !equal(pa.Sub(pb), x, y) {
+		return fmt.Errorf("Sub(%x * G, %x * %x * G)", a, b, a)
+	}
+	x, y = ref.Double(ax, ay)
+	if !equal(pa.Double(), x, y) {
+		return fmt.Errorf("Double(%x * G)", a)
+	}
+	x, y = ref.Add(ax, ay, ax, ay)
+	if !equal(pa.Add(pa), x, y) {
+		return fmt.Errorf("Add(%x * G, %x * G)", a, a)
+	}
+	x, y = ref.Add(ax, ay, ax, negY(ax, ay, params.P))
+	if !equal(pa.Sub(pa), x, y) {
+		return fmt.Errorf("Sub(%x * G, %x * G)", a, a)
+	}
+
+	// b * A + a * G
+	x, y = ref.Add(bx, by, ax, ay)
+	points := []curves.Point{pa, curve.Point.Generator()}
+	scalars := []curves.Scalar{sb, sa}
+	if !equal(curve.Point.SumOfProducts(points, scalars), x, y) {
+		return fmt.Errorf("SumOfProducts(%x * G, G; %x, %x)", a, b, a)
+	}
+	if !equal(curves.VarTimeSumOfProducts(points, scalars), x, y) {
+		return fmt.Errorf("VarTimeSumOfProducts(%x * G, G; %x, %x)", a, b, a)
+	}
+	if !equal(curves.VarTimeMul(pa, sb), bx, by) {
+		return fmt.Errorf("VarTimeMul(%x * G, %x)", a, b)
+	}
+
+	if !pa.IsIdentity() {
+		want := append([]byte{byte(2 + ay.Bit(0))}, ax.FillBytes(make([]byte, byteLen))...)
+		if !bytes.Equal(pa.ToAffineCompressed(), want) {
+			return fmt.Errorf("ToAffineCompressed(%x * G)", a)
+		}
+	}
+
+	// Decode the candidate encoding as is and with a valid prefix so that most inputs get
+	// as far as the square root
+	candidates := [][]byte{in.enc, append([]byte{2 | in.enc[0]&1}, in.enc[1:]...)}
+	for _, enc := range candidates {
+		p, err := curve.Point.FromAffineCompressed(enc)
+		x, y, ok := decompress(enc)
+		if (err == nil) != ok {
+			return fmt.Errorf("FromAffineCompressed accepted %x: %v", enc, err == nil)
+		}
+		if ok && !equal(p, x, y) {
+			return fmt.Errorf("FromAffineCompressed(%x)", enc)
+		}
+	}
+	return nil
+}
+
+// checkEdwards compares the ed25519 group operations with filippo.io/edwards25519
+func checkEdwards(curve *curves.Curve, in *input) error {
+	a := new(big.Int).Mod(new(big.Int).SetBytes(in.a), ed25519L)
+	b := new(big.Int).Mod(new(big.Int).SetBytes(in.b), ed25519L)
+	sa, _ := curve.Scalar.SetBigInt(a)
+	sb, _ := curve.Scalar.SetBigInt(b)
+	ra, _ := edwards25519.NewScalar().SetCanonicalBytes(reverse(a.FillBytes(make([]byte, 32))))
+	rb, _ := edwards25519.NewScalar().SetCanonicalBytes(reverse(b.FillBytes(make([]byte, 32))))
+
+	equal := func(p curves.Point, q *edwards25519.Point) bool {
+		return bytes.Equal(p.ToAffineCompressed(), q.Bytes())
+	}
+
+	pa := curve.ScalarBaseMult(sa)
+	qa := edwards25519.NewIdentityPoint().ScalarBaseMult(ra)
+	if !equal(pa, qa) {
+		return fmt.Errorf("ScalarBaseMult(%x)", a)
+	}
+	pb := pa.Mul(sb)
+	qb := edwards25519.NewIdentityPoint().ScalarMult(rb, qa)
+	if !equal(pb, qb) {
+		return fmt.Errorf("Mul(%x * G, %x)", a, b)
+	}
+	if !equal(pa.Add(pb), edwards25519.NewIdentityPoint().Add(qa, qb)) {
+		return fmt.Errorf("Add(%x * G, %x * %x * G)", a, b, a)
+	}
+	if !equal(pa.Sub(pb), edwards25519.NewIdentityPoint().Subtract(qa, qb)) {
+		return fmt.Errorf("Sub(%x * G, %x * %x * G)", a, b, a)
+	}
+	if !equal(pa.Double(), edwards25519.NewIdentityPoint().Add(qa, qa)) {
+		return fmt.Errorf("Double(%x * G)", a)
+	}
+	if !equal(pa.Neg(), edwards25519.NewIdentityPoint().Negate(qa)) {
+		return fmt.Errorf("Neg(%x * G)", a)
+	}
+	if pa.IsIdentity() != (qa.Equal(edwards25519.NewIdentityPoint()) == 1) {
+		return fmt.Errorf("IsIdentity(%x * G)", a)
+	}
+
+	points := []curves.Point{pa, curve.Point.Generator()}
+	scalars := []curves.Scalar{sb, sa}
+	want := edwards25519.NewIdentityPoint().MultiScalarMult(
+		[]*edwards25519.Scalar{rb, ra},
+		[]*edwards25519.Point{qa, edwards25519.NewGeneratorPoint()},
+	)
+	if !equal(curve.Point.SumOfProducts(points, scalars), want) {
+		return fmt.Errorf("SumOfProducts(%x * G, G; %x, %x)", a, b, a)
+	}
+	if !equal(curves.VarTimeSumOfProducts(points, scalars), want) {
+		return fmt.Errorf("VarTimeSumOfProducts(%x * G, G; %x, %x)", a, b, a)
+	}
+	if !equal(curves.VarTimeMul(pa, sb), qb) {
+		return fmt.Errorf("VarTimeMul(%x * G, %x)", a, b)
+	}
+
+	// The reference decodes any point on the curve while FromAffineCompressed also rejects
+	// points with a torsion component
+	p, err := curve.Point.FromAffineCompressed(in.enc)
+	q, refErr := edwards25519.NewIdentityPoint().SetBytes(in.enc)
+	ok := refErr == nil && torsionFree(q)
+	if (err == nil) != ok {
+		return fmt.Errorf("FromAffineCompressed accepted %x: %v", in.enc, err == nil)
+	}
+	if ok && !equal(p, q) {
+		return fmt.Errorf("FromAffineCompressed(%x)", in.enc)
+	}
+	return nil
+}
+
+// torsionFree reports whether q is in the prime order subgroup, i.e. whether
+// 8^-1 * (8 * q) == q with 8^-1 taken modulo the subgroup order
+func torsionFree(q *edwards25519.Point) bool {
+	eighth := new(big.Int).ModInverse(big.NewInt(8), ed25519L)
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(reverse(eighth.FillBytes(make([]byte, 32))))
+	r := edwards25519.NewIdentityPoint().MultByCofactor(q)
+	r.ScalarMult(s, r)
+	return r.Equal(q) == 1
+}
+
+// negY returns the y coordinate of -(x, y), which is zero for the identity
+func negY(x, y, p *big.Int) *big.Int {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(p, y)
+}
+
+func reverse(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i, b := range in {
+		out[len(in)-1-i] = b
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package difftest
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var checks = map[string]func([]byte) error{
+	"k256":    CheckK256,
+	"p256":    CheckP256,
+	"ed25519": CheckEd25519,
+}
+
+func TestSeeds(t *testing.T) {
+	for name, check := range checks {
+		for _, seed := range Seeds() {
+			require.NoError(t, check(seed), name)
+		}
+		// Short inputs are zero padded
+		require.NoError(t, check(nil), name)
+		require.NoError(t, check([]byte{1}), name)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	iterations := 200
+	if testing.Short() {
+		iterations = 20
+	}
+	for name, check := range checks {
+		for i := 0; i < iterations; i++ {
+			var data [97]byte
+			_, _ = crand.Read(data[:])
+			require.NoError(t, check(data[:]), name)
+		}
+	}
+}
+
+func TestFuzzPanics(t *testing.T) {
+	require.Equal(t, 1, fuzz(func([]byte) error { return nil }, nil))
+	require.Panics(t, func() {
+		fuzz(func([]byte) error { return fmt.Errorf("mismatch") }, nil)
+	})
+}
@@ -0,0 +1,39 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+//go:build go1.18
+// +build go1.18
+
+package difftest_test
+
+import (
+	"testing"
+
+	"github.com/etclab/kryptology/pkg/core/curves/difftest"
+)
+
+func FuzzK256(f *testing.F) {
+	fuzz(f, difftest.CheckK256)
+}
+
+func FuzzP256(f *testing.F) {
+	fuzz(f, difftest.CheckP256)
+}
+
+func FuzzEd25519(f *testing.F) {
+	fuzz(f, difftest.CheckEd25519)
+}
+
+func fuzz(f *testing.F, check func([]byte) error) {
+	for _, seed := range difftest.Seeds() {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := check(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
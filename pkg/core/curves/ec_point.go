@@ -81,21 +81,30 @@ func (a *EcPoint) UnmarshalJSON(bytes []byte) error {
 }
 
 func (a *EcPoint) MarshalBinary() ([]byte, error) {
-	result := [65]byte{}
 	if code, ok := curveNameToId[a.Curve.Params().Name]; ok {
+		fieldSize := internal.CalcFieldSize(a.Curve)
+		result := make([]byte, 1+2*fieldSize)
 		result[0] = code
-		a.X.FillBytes(result[1:33])
-		a.Y.FillBytes(result[33:65])
-		return result[:], nil
+		a.X.FillBytes(result[1 : 1+fieldSize])
+		a.Y.FillBytes(result[1+fieldSize:])
+		return result, nil
 	}
 	return nil, fmt.Errorf("unknown curve serialized")
 }
 
 func (a *EcPoint) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("invalid byte sequence")
+	}
 	if mapper, ok := curveIdToName[data[0]]; ok {
-		a.Curve = mapper()
-		a.X = new(big.Int).SetBytes(data[1:33])
-		a.Y = new(big.Int).SetBytes(data[33:65])
+		curve := mapper()
+		fieldSize := internal.CalcFieldSize(curve)
+		if len(data) != 1+2*fieldSize {
+			return fmt.Errorf("invalid byte sequence")
+		}
+		a.Curve = curve
+		a.X = new(big.Int).SetBytes(data[1 : 1+fieldSize])
+		a.Y = new(big.Int).SetBytes(data[1+fieldSize:])
 		return nil
 	}
 	return fmt.Errorf("unknown curve deserialized")
@@ -368,3 +368,21 @@ func TestEquals(t *testing.T) {
 		})
 	}
 }
+
+func TestEcPointMarshalBinaryCurveSizes(t *testing.T) {
+	for _, curve := range []elliptic.Curve{btcec.S256(), elliptic.P256(), elliptic.P384()} {
+		k, err := core.Rand(curve.Params().N)
+		require.NoError(t, err)
+		p, err := NewScalarBaseMult(curve, k)
+		require.NoError(t, err)
+		data, err := p.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, 1+2*tt.CalcFieldSize(curve), len(data))
+
+		q := new(EcPoint)
+		require.NoError(t, q.UnmarshalBinary(data))
+		require.True(t, p.Equals(q))
+		require.Error(t, q.UnmarshalBinary(data[:len(data)-1]))
+	}
+	require.Error(t, new(EcPoint).UnmarshalBinary(nil))
+}
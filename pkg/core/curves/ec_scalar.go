@@ -7,7 +7,6 @@
 package curves
 
 import (
-	"crypto/elliptic"
 	crand "crypto/rand"
 	"crypto/sha512"
 	"fmt"
@@ -21,6 +20,7 @@ import (
 	"github.com/etclab/kryptology/internal"
 	"github.com/etclab/kryptology/pkg/core"
 	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
+	"github.com/etclab/kryptology/pkg/core/curves/native/p256/fq"
 )
 
 type EcScalar interface {
@@ -113,32 +113,36 @@ func NewP256Scalar() *P256Scalar {
 }
 
 func (k P256Scalar) Add(x, y *big.Int) *big.Int {
-	v := new(big.Int).Add(x, y)
-	v.Mod(v, elliptic.P256().Params().N)
-	return v
+	a := fq.P256FqNew().SetBigInt(x)
+	b := fq.P256FqNew().SetBigInt(y)
+	return a.Add(a, b).BigInt()
 }
 
 func (k P256Scalar) Sub(x, y *big.Int) *big.Int {
-	v := new(big.Int).Sub(x, y)
-	v.Mod(v, elliptic.P256().Params().N)
-	return v
+	a := fq.P256FqNew().SetBigInt(x)
+	b := fq.P256FqNew().SetBigInt(y)
+	return a.Sub(a, b).BigInt()
 }
 
 func (k P256Scalar) Neg(x *big.Int) *big.Int {
-	v := new(big.Int).Sub(elliptic.P256().Params().N, x)
-	v.Mod(v, elliptic.P256().Params().N)
-	return v
+	a := fq.P256FqNew().SetBigInt(x)
+	return a.Neg(a).BigInt()
 }
 
 func (k P256Scalar) Mul(x, y *big.Int) *big.Int {
-	v := new(big.Int).Mul(x, y)
-	v.Mod(v, elliptic.P256().Params().N)
-	return v
+	a := fq.P256FqNew().SetBigInt(x)
+	b := fq.P256FqNew().SetBigInt(y)
+	return a.Mul(a, b).BigInt()
 }
 
 func (k P256Scalar) Div(x, y *big.Int) *big.Int {
-	t := new(big.Int).ModInverse(y, elliptic.P256().Params().N)
-	return k.Mul(x, t)
+	c := fq.P256FqNew()
+	a := fq.P256FqNew().SetBigInt(x)
+	b := fq.P256FqNew().SetBigInt(y)
+	_, wasInverted := c.Invert(b)
+	c.Mul(a, c)
+	tt := map[bool]int{false: 0, true: 1}
+	return a.CMove(a, c, tt[wasInverted]).BigInt()
 }
 
 func (k P256Scalar) Hash(input []byte) *big.Int {
@@ -146,21 +150,16 @@ func (k P256Scalar) Hash(input []byte) *big.Int {
 }
 
 func (k P256Scalar) Random() (*big.Int, error) {
-	b := make([]byte, 48)
-	n, err := crand.Read(b)
-	if err != nil {
-		return nil, err
-	}
-	if n != 48 {
-		return nil, fmt.Errorf("insufficient bytes read")
+	a := P256().NewScalar().Random(crand.Reader)
+	if a == nil {
+		return nil, fmt.Errorf("invalid random value")
 	}
-	v := new(big.Int).SetBytes(b)
-	v.Mod(v, elliptic.P256().Params().N)
-	return v, nil
+	return a.BigInt(), nil
 }
 
 func (k P256Scalar) IsValid(x *big.Int) bool {
-	return core.In(x, elliptic.P256().Params().N) == nil
+	a := fq.P256FqNew().SetBigInt(x)
+	return a.BigInt().Cmp(x) == 0
 }
 
 func (k P256Scalar) Bytes(x *big.Int) []byte {
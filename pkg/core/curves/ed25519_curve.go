@@ -22,6 +22,10 @@ import (
 	"github.com/etclab/kryptology/internal"
 )
 
+func init() {
+	mustRegisterCurve(ED25519Name, OidEd25519, ED25519)
+}
+
 type ScalarEd25519 struct {
 	value *edwards25519.Scalar
 }
@@ -133,6 +137,9 @@ func (s *ScalarEd25519) Double() Scalar {
 }
 
 func (s *ScalarEd25519) Invert() (Scalar, error) {
+	if s.IsZero() {
+		return nil, fmt.Errorf("inverse doesn't exist")
+	}
 	return &ScalarEd25519{
 		value: edwards25519.NewScalar().Invert(s.value),
 	}, nil
@@ -140,8 +147,10 @@ func (s *ScalarEd25519) Invert() (Scalar, error) {
 
 func (s *ScalarEd25519) Sqrt() (Scalar, error) {
 	bi25519, _ := new(big.Int).SetString("1000000000000000000000000000000014DEF9DEA2F79CD65812631A5CF5D3ED", 16)
-	x := s.BigInt()
-	x.ModSqrt(x, bi25519)
+	x := new(big.Int).ModSqrt(s.BigInt(), bi25519)
+	if x == nil {
+		return nil, fmt.Errorf("not a square")
+	}
 	return s.SetBigInt(x)
 }
 
@@ -403,6 +412,24 @@ func (p *PointEd25519) IsOnCurve() bool {
 	return err == nil
 }
 
+// ClearCofactor multiplies the point by the cofactor 8
+func (p *PointEd25519) ClearCofactor() Point {
+	return &PointEd25519{value: edwards25519.NewIdentityPoint().MultByCofactor(p.value)}
+}
+
+// IsTorsionFree reports whether the point is in the subgroup of order l
+func (p *PointEd25519) IsTorsionFree() bool {
+	// l is represented by 0, so l * P is computed as (l - 1) * P + P
+	lMinusOne := new(ScalarEd25519).New(-1).(*ScalarEd25519)
+	q := edwards25519.NewIdentityPoint().ScalarMult(lMinusOne.value, p.value)
+	return q.Add(q, p.value).Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// IsInPrimeSubgroup reports whether the point is in the prime order subgroup
+func (p *PointEd25519) IsInPrimeSubgroup() bool {
+	return p.IsTorsionFree()
+}
+
 func (p *PointEd25519) Double() Point {
 	return &PointEd25519{value: edwards25519.NewIdentityPoint().Add(p.value, p.value)}
 }
@@ -573,12 +600,14 @@ func (p *PointEd25519) ToAffineUncompressed() []byte {
 	return out[:]
 }
 
+// FromAffineCompressed decodes the 32-byte encoding of RFC 8032 and rejects
+// points outside the prime order subgroup
 func (p *PointEd25519) FromAffineCompressed(inBytes []byte) (Point, error) {
 	pt, err := edwards25519.NewIdentityPoint().SetBytes(inBytes)
 	if err != nil {
 		return nil, err
 	}
-	return &PointEd25519{value: pt}, nil
+	return newSubgroupPointEd25519(pt)
 }
 
 func (p *PointEd25519) FromAffineUncompressed(inBytes []byte) (Point, error) {
@@ -602,7 +631,16 @@ func (p *PointEd25519) FromAffineUncompressed(inBytes []byte) (Point, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &PointEd25519{value}, nil
+	return newSubgroupPointEd25519(value)
+}
+
+// newSubgroupPointEd25519 wraps a decoded point if it is in the prime order subgroup
+func newSubgroupPointEd25519(value *edwards25519.Point) (*PointEd25519, error) {
+	pt := &PointEd25519{value}
+	if !pt.IsTorsionFree() {
+		return nil, ErrNotInSubgroup
+	}
+	return pt, nil
 }
 
 func (p *PointEd25519) CurveName() string {
@@ -610,24 +648,58 @@ func (p *PointEd25519) CurveName() string {
 }
 
 func (p *PointEd25519) SumOfProducts(points []Point, scalars []Scalar) Point {
+	nScalars, nPoints, ok := ed25519Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	pt := edwards25519.NewIdentityPoint().MultiScalarMult(nScalars, nPoints)
+	return &PointEd25519{value: pt}
+}
+
+// VarTimeMul multiplies the point by `rhs` in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (p *PointEd25519) VarTimeMul(rhs Scalar) Point {
+	r, ok := rhs.(*ScalarEd25519)
+	if !ok {
+		return nil
+	}
+	value := edwards25519.NewIdentityPoint().VarTimeDoubleScalarBaseMult(r.value, p.value, edwards25519.NewScalar())
+	return &PointEd25519{value}
+}
+
+// VarTimeSumOfProducts computes the multi-scalar multiplication of `points` and `scalars`
+// in variable time. It must only be used with public scalars, such as when verifying proofs
+func (p *PointEd25519) VarTimeSumOfProducts(points []Point, scalars []Scalar) Point {
+	nScalars, nPoints, ok := ed25519Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	pt := edwards25519.NewIdentityPoint().VarTimeMultiScalarMult(nScalars, nPoints)
+	return &PointEd25519{value: pt}
+}
+
+// ed25519Batch converts `points` and `scalars` to their edwards25519 values
+func ed25519Batch(points []Point, scalars []Scalar) ([]*edwards25519.Scalar, []*edwards25519.Point, bool) {
+	if len(points) != len(scalars) {
+		return nil, nil, false
+	}
 	nScalars := make([]*edwards25519.Scalar, len(scalars))
 	nPoints := make([]*edwards25519.Point, len(points))
 	for i, sc := range scalars {
 		s, err := edwards25519.NewScalar().SetCanonicalBytes(sc.Bytes())
 		if err != nil {
-			return nil
+			return nil, nil, false
 		}
 		nScalars[i] = s
 	}
 	for i, pt := range points {
 		pp, ok := pt.(*PointEd25519)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nPoints[i] = pp.value
 	}
-	pt := edwards25519.NewIdentityPoint().MultiScalarMult(nScalars, nPoints)
-	return &PointEd25519{value: pt}
+	return nScalars, nPoints, true
 }
 
 func (p *PointEd25519) VarTimeDoubleScalarBaseMult(a Scalar, A Point, b Scalar) Point {
@@ -0,0 +1,563 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+	ed448n "github.com/etclab/kryptology/pkg/core/curves/native/ed448"
+)
+
+func init() {
+	mustRegisterCurve(ED448Name, OidEd448, ED448)
+}
+
+// ed448ScalarHashBytes is L of hash_to_field for the scalar field, ceil((446 + 224) / 8)
+const ed448ScalarHashBytes = 84
+
+type ScalarEd448 struct {
+	value *ed448n.Scalar
+}
+
+type PointEd448 struct {
+	value *ed448n.Point
+}
+
+func (s *ScalarEd448) Random(reader io.Reader) Scalar {
+	if reader == nil {
+		return nil
+	}
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return s.Hash(seed[:])
+}
+
+func (s *ScalarEd448) Hash(bytes []byte) Scalar {
+	xof := native.ExpandMsgXof(native.EllipticPointHasherShake256Rfc9380(), bytes, []byte(ed448n.SuiteId), ed448ScalarHashBytes)
+	var t [ed448n.WideScalarBytes]byte
+	copy(t[:ed448ScalarHashBytes], internal.ReverseScalarBytes(xof))
+	value, err := new(ed448n.Scalar).SetBytesWide(t[:])
+	if err != nil {
+		return nil
+	}
+	return &ScalarEd448{value}
+}
+
+func (s *ScalarEd448) Zero() Scalar {
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).Zero(),
+	}
+}
+
+func (s *ScalarEd448) One() Scalar {
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).One(),
+	}
+}
+
+func (s *ScalarEd448) IsZero() bool {
+	return s.value.IsZero() == 1
+}
+
+func (s *ScalarEd448) IsOne() bool {
+	return s.value.Equal(new(ed448n.Scalar).One()) == 1
+}
+
+func (s *ScalarEd448) IsOdd() bool {
+	return s.value.Bytes()[0]&1 == 1
+}
+
+func (s *ScalarEd448) IsEven() bool {
+	return s.value.Bytes()[0]&1 == 0
+}
+
+func (s *ScalarEd448) New(value int) Scalar {
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).SetBigInt(big.NewInt(int64(value))),
+	}
+}
+
+func (s *ScalarEd448) Cmp(rhs Scalar) int {
+	r, ok := rhs.(*ScalarEd448)
+	if ok {
+		return s.value.BigInt().Cmp(r.value.BigInt())
+	} else {
+		return -2
+	}
+}
+
+func (s *ScalarEd448) Square() Scalar {
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).Mul(s.value, s.value),
+	}
+}
+
+func (s *ScalarEd448) Double() Scalar {
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).Add(s.value, s.value),
+	}
+}
+
+func (s *ScalarEd448) Invert() (Scalar, error) {
+	value, wasInverted := new(ed448n.Scalar).Invert(s.value)
+	if !wasInverted {
+		return nil, fmt.Errorf("inverse doesn't exist")
+	}
+	return &ScalarEd448{
+		value,
+	}, nil
+}
+
+func (s *ScalarEd448) Sqrt() (Scalar, error) {
+	value, wasSquare := new(ed448n.Scalar).Sqrt(s.value)
+	if !wasSquare {
+		return nil, fmt.Errorf("not a square")
+	}
+	return &ScalarEd448{
+		value,
+	}, nil
+}
+
+func (s *ScalarEd448) Cube() Scalar {
+	value := new(ed448n.Scalar).Mul(s.value, s.value)
+	value.Mul(value, s.value)
+	return &ScalarEd448{
+		value,
+	}
+}
+
+func (s *ScalarEd448) Add(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarEd448)
+	if ok {
+		return &ScalarEd448{
+			value: new(ed448n.Scalar).Add(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarEd448) Sub(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarEd448)
+	if ok {
+		return &ScalarEd448{
+			value: new(ed448n.Scalar).Sub(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarEd448) Mul(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarEd448)
+	if ok {
+		return &ScalarEd448{
+			value: new(ed448n.Scalar).Mul(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarEd448) MulAdd(y, z Scalar) Scalar {
+	return s.Mul(y).Add(z)
+}
+
+func (s *ScalarEd448) Div(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarEd448)
+	if ok {
+		v, wasInverted := new(ed448n.Scalar).Invert(r.value)
+		if !wasInverted {
+			return nil
+		}
+		v.Mul(v, s.value)
+		return &ScalarEd448{value: v}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarEd448) Neg() Scalar {
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).Neg(s.value),
+	}
+}
+
+func (s *ScalarEd448) SetBigInt(v *big.Int) (Scalar, error) {
+	if v == nil {
+		return nil, fmt.Errorf("'v' cannot be nil")
+	}
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).SetBigInt(v),
+	}, nil
+}
+
+func (s *ScalarEd448) BigInt() *big.Int {
+	return s.value.BigInt()
+}
+
+// Bytes returns the 57-byte little-endian encoding of RFC 8032
+func (s *ScalarEd448) Bytes() []byte {
+	t := s.value.Bytes()
+	return t[:]
+}
+
+// SetBytes expects the canonical 57-byte little-endian encoding of RFC 8032
+func (s *ScalarEd448) SetBytes(bytes []byte) (Scalar, error) {
+	if len(bytes) != ed448n.ScalarBytes {
+		return nil, fmt.Errorf("invalid length")
+	}
+	value, err := new(ed448n.Scalar).SetBytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarEd448{
+		value,
+	}, nil
+}
+
+// SetBytesWide reduces 114 little-endian bytes, such as the SHAKE256 outputs of Ed448, modulo the group order.
+// It also accepts the 64 bytes taken by the other curves, whose reduction has a bias of 2^-66
+func (s *ScalarEd448) SetBytesWide(bytes []byte) (Scalar, error) {
+	if len(bytes) != ed448n.WideScalarBytes && len(bytes) != WideScalarBytes {
+		return nil, fmt.Errorf("invalid length")
+	}
+	var t [ed448n.WideScalarBytes]byte
+	copy(t[:], bytes)
+	value, err := new(ed448n.Scalar).SetBytesWide(t[:])
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarEd448{
+		value,
+	}, nil
+}
+
+func (s *ScalarEd448) Point() Point {
+	return new(PointEd448).Identity()
+}
+
+func (s *ScalarEd448) Clone() Scalar {
+	return &ScalarEd448{
+		value: new(ed448n.Scalar).Set(s.value),
+	}
+}
+
+func (s *ScalarEd448) MarshalBinary() ([]byte, error) {
+	return scalarMarshalBinary(s)
+}
+
+func (s *ScalarEd448) UnmarshalBinary(input []byte) error {
+	sc, err := scalarUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarEd448)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarEd448) MarshalText() ([]byte, error) {
+	return scalarMarshalText(s)
+}
+
+func (s *ScalarEd448) UnmarshalText(input []byte) error {
+	sc, err := scalarUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarEd448)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarEd448) MarshalJSON() ([]byte, error) {
+	return scalarMarshalJson(s)
+}
+
+func (s *ScalarEd448) UnmarshalJSON(input []byte) error {
+	sc, err := scalarUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	S, ok := sc.(*ScalarEd448)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	s.value = S.value
+	return nil
+}
+
+func (p *PointEd448) Random(reader io.Reader) Point {
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return p.Hash(seed[:])
+}
+
+// Hash maps `bytes` to the prime order subgroup with edwards448_XOF:SHAKE256_ELL2_RO_
+// using the suite identifier as the domain separation tag
+func (p *PointEd448) Hash(bytes []byte) Point {
+	value := new(ed448n.Point).Hash(native.EllipticPointHasherShake256Rfc9380(), bytes, []byte(ed448n.SuiteId))
+	return &PointEd448{value}
+}
+
+func (p *PointEd448) Identity() Point {
+	return &PointEd448{
+		value: new(ed448n.Point).Identity(),
+	}
+}
+
+func (p *PointEd448) Generator() Point {
+	return &PointEd448{
+		value: new(ed448n.Point).Generator(),
+	}
+}
+
+func (p *PointEd448) IsIdentity() bool {
+	return p.value.IsIdentity() == 1
+}
+
+// IsNegative returns the sign bit of the compressed encoding, which is set when x is odd
+func (p *PointEd448) IsNegative() bool {
+	return p.value.ToCompressed()[ed448n.PointBytes-1]>>7 == 1
+}
+
+func (p *PointEd448) IsOnCurve() bool {
+	return p.value.IsOnCurve() == 1
+}
+
+// ClearCofactor multiplies the point by the cofactor 4
+func (p *PointEd448) ClearCofactor() Point {
+	return &PointEd448{value: new(ed448n.Point).MulByCofactor(p.value)}
+}
+
+// IsTorsionFree reports whether the point is in the subgroup of order l
+func (p *PointEd448) IsTorsionFree() bool {
+	return p.value.IsTorsionFree() == 1
+}
+
+// IsInPrimeSubgroup reports whether the point is in the prime order subgroup
+func (p *PointEd448) IsInPrimeSubgroup() bool {
+	return p.IsTorsionFree()
+}
+
+func (p *PointEd448) Double() Point {
+	return &PointEd448{value: new(ed448n.Point).Double(p.value)}
+}
+
+func (p *PointEd448) Scalar() Scalar {
+	return new(ScalarEd448).Zero()
+}
+
+func (p *PointEd448) Neg() Point {
+	return &PointEd448{value: new(ed448n.Point).Neg(p.value)}
+}
+
+func (p *PointEd448) Add(rhs Point) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*PointEd448)
+	if ok {
+		return &PointEd448{value: new(ed448n.Point).Add(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointEd448) Sub(rhs Point) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*PointEd448)
+	if ok {
+		return &PointEd448{value: new(ed448n.Point).Sub(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointEd448) Mul(rhs Scalar) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*ScalarEd448)
+	if ok {
+		return &PointEd448{value: new(ed448n.Point).Mul(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointEd448) Equal(rhs Point) bool {
+	r, ok := rhs.(*PointEd448)
+	if ok {
+		return p.value.Equal(r.value) == 1
+	} else {
+		return false
+	}
+}
+
+func (p *PointEd448) Set(x, y *big.Int) (Point, error) {
+	value, err := new(ed448n.Point).SetBigInt(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return &PointEd448{value}, nil
+}
+
+// ToAffineCompressed returns the 57-byte encoding of RFC 8032
+func (p *PointEd448) ToAffineCompressed() []byte {
+	out := p.value.ToCompressed()
+	return out[:]
+}
+
+// ToAffineUncompressed returns x || y, each as 56 little-endian bytes
+func (p *PointEd448) ToAffineUncompressed() []byte {
+	out := p.value.ToUncompressed()
+	return out[:]
+}
+
+// FromAffineCompressed decodes the 57-byte encoding of RFC 8032 and rejects
+// points outside the prime order subgroup
+func (p *PointEd448) FromAffineCompressed(bytes []byte) (Point, error) {
+	var input [ed448n.PointBytes]byte
+	if len(bytes) != ed448n.PointBytes {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	copy(input[:], bytes)
+	value, err := new(ed448n.Point).FromCompressed(&input)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsTorsionFree() == 0 {
+		return nil, ErrNotInSubgroup
+	}
+	return &PointEd448{value}, nil
+}
+
+func (p *PointEd448) FromAffineUncompressed(bytes []byte) (Point, error) {
+	var input [ed448n.UncompressedBytes]byte
+	if len(bytes) != ed448n.UncompressedBytes {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	copy(input[:], bytes)
+	value, err := new(ed448n.Point).FromUncompressed(&input)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsTorsionFree() == 0 {
+		return nil, ErrNotInSubgroup
+	}
+	return &PointEd448{value}, nil
+}
+
+func (p *PointEd448) CurveName() string {
+	return ED448Name
+}
+
+func (p *PointEd448) SumOfProducts(points []Point, scalars []Scalar) Point {
+	if len(points) != len(scalars) {
+		return nil
+	}
+	if len(points) == 0 {
+		return p.Identity()
+	}
+	nScalars := make([]*big.Int, len(scalars))
+	for i, sc := range scalars {
+		if _, ok := points[i].(*PointEd448); !ok {
+			return nil
+		}
+		s, ok := sc.(*ScalarEd448)
+		if !ok {
+			return nil
+		}
+		nScalars[i] = s.BigInt()
+	}
+	return sumOfProductsPippengerBits(points, nScalars, 446)
+}
+
+func (p *PointEd448) MarshalBinary() ([]byte, error) {
+	return pointMarshalBinary(p)
+}
+
+func (p *PointEd448) UnmarshalBinary(input []byte) error {
+	pt, err := pointUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointEd448)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointEd448) MarshalText() ([]byte, error) {
+	return pointMarshalText(p)
+}
+
+func (p *PointEd448) UnmarshalText(input []byte) error {
+	pt, err := pointUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointEd448)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointEd448) MarshalJSON() ([]byte, error) {
+	return pointMarshalJson(p)
+}
+
+func (p *PointEd448) UnmarshalJSON(input []byte) error {
+	pt, err := pointUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	P, ok := pt.(*PointEd448)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	p.value = P.value
+	return nil
+}
+
+// GetEd448Point returns the underlying point of the native implementation
+func (p *PointEd448) GetEd448Point() *ed448n.Point {
+	return new(ed448n.Point).Set(p.value)
+}
+
+// SetEd448Point sets the underlying point of the native implementation
+func (p *PointEd448) SetEd448Point(pt *ed448n.Point) *PointEd448 {
+	return &PointEd448{value: new(ed448n.Point).Set(pt)}
+}
+
+// GetEd448Scalar returns the underlying scalar of the native implementation
+func (s *ScalarEd448) GetEd448Scalar() *ed448n.Scalar {
+	return new(ed448n.Scalar).Set(s.value)
+}
+
+// SetEd448Scalar sets the underlying scalar of the native implementation
+func (s *ScalarEd448) SetEd448Scalar(sc *ed448n.Scalar) *ScalarEd448 {
+	return &ScalarEd448{value: new(ed448n.Scalar).Set(sc)}
+}
@@ -0,0 +1,150 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	ed448n "github.com/etclab/kryptology/pkg/core/curves/native/ed448"
+)
+
+func TestScalarEd448(t *testing.T) {
+	ed448 := ED448()
+	require.True(t, ed448.Scalar.Zero().IsZero())
+	require.True(t, ed448.Scalar.One().IsOne())
+	require.True(t, ed448.Scalar.One().IsOdd())
+	require.Len(t, ed448.Scalar.One().Bytes(), 57)
+
+	// l - 1 is the largest canonical scalar
+	order, _ := new(big.Int).SetString("3fffffffffffffffffffffffffffffffffffffffffffffffffffffff7cca23e9c44edb49aed63690216cc2728dc58f552378c292ab5844f3", 16)
+	require.Equal(t, new(big.Int).Sub(order, big.NewInt(1)), ed448.Scalar.New(-1).BigInt())
+
+	nonCanonical := internal.ReverseScalarBytes(order.FillBytes(make([]byte, 57)))
+	_, err := ed448.Scalar.SetBytes(nonCanonical)
+	require.Error(t, err)
+	// The most significant byte is always zero
+	high := make([]byte, 57)
+	high[56] = 1
+	_, err = ed448.Scalar.SetBytes(high)
+	require.Error(t, err)
+
+	s := ed448.Scalar.Random(crand.Reader)
+	inv, err := s.Invert()
+	require.NoError(t, err)
+	require.True(t, s.Mul(inv).IsOne())
+	_, err = ed448.Scalar.Zero().Invert()
+	require.Error(t, err)
+	require.Nil(t, s.Add(K256().Scalar.One()))
+	require.Equal(t, -2, s.Cmp(K256().Scalar.One()))
+}
+
+func TestPointEd448Rfc8032(t *testing.T) {
+	// The "1 octet" vector of RFC 8032 section 7.4. The secret scalar is derived
+	// from the SHAKE256 hash of the private key by the signature scheme, here it
+	// is checked that the curve maps it to the expected public key
+	public, _ := hex.DecodeString("43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480")
+	p, err := ED448().Point.FromAffineCompressed(public)
+	require.NoError(t, err)
+	require.True(t, p.IsOnCurve())
+	require.True(t, p.(CofactorPoint).IsTorsionFree())
+	require.Equal(t, public, p.ToAffineCompressed())
+	require.Equal(t, public[56]>>7 == 1, p.IsNegative())
+
+	g := ED448().Point.Generator()
+	x, y := new(big.Int), new(big.Int)
+	x.SetString("224580040295924300187604334099896036246789641632564134246125461686950415467406032909029192869357953282578032075146446173674602635247710", 10)
+	y.SetString("298819210078481492676017930443930673437544040154080242095928241372331506189835876003536878655418784733982303233503462500531545062832660", 10)
+	q, err := ED448().Point.Set(x, y)
+	require.NoError(t, err)
+	require.True(t, q.Equal(g))
+	_, err = ED448().Point.Set(x, x)
+	require.Error(t, err)
+}
+
+func TestPointEd448(t *testing.T) {
+	ed448 := ED448()
+	g := ed448.Point.Generator()
+	require.True(t, g.Mul(ed448.Scalar.New(-1)).Add(g).IsIdentity())
+	require.True(t, g.Double().Equal(g.Add(g)))
+	require.True(t, g.Sub(g).IsIdentity())
+	require.Nil(t, g.Add(ED25519().Point.Generator()))
+
+	a := ed448.Scalar.Random(crand.Reader)
+	b := ed448.Scalar.Random(crand.Reader)
+	p := ed448.Point.Random(crand.Reader)
+	require.True(t, p.(CofactorPoint).IsTorsionFree())
+	expected := g.Mul(a).Add(p.Mul(b))
+	require.True(t, expected.Equal(g.SumOfProducts([]Point{g, p}, []Scalar{a, b})))
+
+	// (0, -1) has order two and is cleared by the cofactor
+	twoTorsion := make([]byte, 57)
+	for i := 0; i < 56; i++ {
+		twoTorsion[i] = 0xff
+	}
+	twoTorsion[0] = 0xfe
+	twoTorsion[28] = 0xfe
+	_, err := ed448.Point.FromAffineCompressed(twoTorsion)
+	require.ErrorIs(t, err, ErrNotInSubgroup)
+	var input [ed448n.PointBytes]byte
+	copy(input[:], twoTorsion)
+	value, err := new(ed448n.Point).FromCompressed(&input)
+	require.NoError(t, err)
+	torsion := &PointEd448{value}
+	require.True(t, torsion.Double().IsIdentity())
+	mixed := p.Add(torsion)
+	require.False(t, IsTorsionFree(mixed))
+	require.True(t, ClearCofactor(mixed).Point().Equal(p.Mul(ed448.Scalar.New(4))))
+}
+
+func TestPointEd448Encoding(t *testing.T) {
+	p := ED448().Point.Random(crand.Reader)
+	s := ED448().Scalar.Random(crand.Reader)
+	data, err := json.Marshal(struct {
+		P *PointEd448
+		S *ScalarEd448
+	}{p.(*PointEd448), s.(*ScalarEd448)})
+	require.NoError(t, err)
+	var out struct {
+		P *PointEd448
+		S *ScalarEd448
+	}
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.True(t, out.P.Equal(p))
+	require.Equal(t, 0, out.S.Cmp(s))
+
+	bin, err := p.(*PointEd448).MarshalBinary()
+	require.NoError(t, err)
+	q, err := pointUnmarshalBinary(bin)
+	require.NoError(t, err)
+	require.True(t, q.Equal(p))
+}
+
+func TestHashToCurveEd448(t *testing.T) {
+	suite, err := HashToCurveSuite(ED448())
+	require.NoError(t, err)
+	require.Equal(t, "edwards448_XOF:SHAKE256_ELL2_RO_", suite)
+	dst := []byte("QUUX-V01-CS02-with-" + suite)
+	p, err := HashToCurve(ED448(), []byte("abc"), dst)
+	require.NoError(t, err)
+	require.True(t, p.IsOnCurve())
+	require.True(t, IsTorsionFree(p))
+	q, err := HashToCurve(ED448(), []byte("abc"), dst)
+	require.NoError(t, err)
+	require.True(t, p.Equal(q))
+	q, err = HashToCurve(ED448(), []byte("abc"), []byte("other"))
+	require.NoError(t, err)
+	require.False(t, p.Equal(q))
+	_, err = HashToCurve(ED448(), []byte("abc"), nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,97 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"sync"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+// fixedBaseWindow is the number of scalar bits handled by each row of a FixedBaseTable
+const fixedBaseWindow = 4
+
+// generatorTables caches the FixedBaseTable of each curve's generator by curve name
+var generatorTables sync.Map
+
+// FixedBaseTable holds precomputed multiples of a fixed point, such as a curve
+// generator or a Pedersen blinding generator, so multiplying it by a scalar
+// costs one addition per 4 bits of the scalar and no doublings.
+// The table is built on the first call to Mul and is safe for concurrent use.
+// It takes 16 points of memory per 4 bits of the scalar, 1024 points for 256-bit scalars.
+//
+// Mul selects the table entries by the scalar and is not constant time,
+// so the table must only be used with public scalars, e.g. to verify proofs
+type FixedBaseTable struct {
+	base  Point
+	once  sync.Once
+	table [][1 << fixedBaseWindow]Point
+}
+
+// NewFixedBaseTable returns a table for multiplying `base` by scalars.
+// The precomputation is deferred until the first multiplication
+func NewFixedBaseTable(base Point) *FixedBaseTable {
+	if base == nil {
+		return nil
+	}
+	return &FixedBaseTable{base: base}
+}
+
+// GeneratorTable returns the shared FixedBaseTable for the curve's generator
+func (c Curve) GeneratorTable() *FixedBaseTable {
+	if t, ok := generatorTables.Load(c.Name); ok {
+		return t.(*FixedBaseTable)
+	}
+	t, _ := generatorTables.LoadOrStore(c.Name, NewFixedBaseTable(c.NewGeneratorPoint()))
+	return t.(*FixedBaseTable)
+}
+
+// Base returns the point this table multiplies
+func (t *FixedBaseTable) Base() Point {
+	return t.base
+}
+
+// Mul computes s * base in variable time, `s` must not be secret.
+// Returns nil if `s` is from a different curve
+func (t *FixedBaseTable) Mul(s Scalar) Point {
+	if s == nil || s.Point().CurveName() != t.base.CurveName() {
+		return nil
+	}
+	t.once.Do(t.precompute)
+
+	// The scalar as little-endian bytes, two windows per byte
+	bytes := make([]byte, len(t.table)*fixedBaseWindow/8)
+	be := s.BigInt().Bytes()
+	if len(be) > len(bytes) {
+		return nil
+	}
+	for i, b := range be {
+		bytes[len(be)-1-i] = b
+	}
+
+	result := t.base.Identity()
+	for i := range t.table {
+		result = result.Add(t.table[i][native.ScalarWindow(bytes, i*fixedBaseWindow, fixedBaseWindow)])
+	}
+	return result
+}
+
+// precompute fills table[i][d] = d * 16^i * base
+func (t *FixedBaseTable) precompute() {
+	// One row per window of the curve's scalars
+	t.table = make([][1 << fixedBaseWindow]Point, len(t.base.Scalar().Bytes())*8/fixedBaseWindow)
+	row := t.base
+	for i := range t.table {
+		t.table[i][0] = row.Identity()
+		t.table[i][1] = row
+		for d := 2; d < len(t.table[i]); d++ {
+			t.table[i][d] = t.table[i][d-1].Add(row)
+		}
+		// The next row starts at 16^(i+1) * base
+		row = t.table[i][len(t.table[i])-1].Add(row)
+	}
+}
@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedBaseTableMul(t *testing.T) {
+	curves := []*Curve{K256(), P256(), ED25519(), PALLAS(), BLS12381G1(), BLS12381G2(), BLS12377G1(), BN254G1(), RISTRETTO255(), P384(), ED448()}
+	for _, curve := range curves {
+		h := curve.Point.Random(crand.Reader)
+		table := NewFixedBaseTable(h)
+		require.True(t, table.Base().Equal(h))
+		for _, s := range []Scalar{curve.Scalar.Zero(), curve.Scalar.One(), curve.Scalar.New(-1), curve.Scalar.Random(crand.Reader)} {
+			require.True(t, h.Mul(s).Equal(table.Mul(s)), curve.Name)
+		}
+
+		gen := curve.GeneratorTable()
+		require.True(t, gen == curve.GeneratorTable())
+		s := curve.Scalar.Random(crand.Reader)
+		require.True(t, curve.ScalarBaseMult(s).Equal(gen.Mul(s)), curve.Name)
+	}
+}
+
+func TestFixedBaseTableInvalid(t *testing.T) {
+	require.Nil(t, NewFixedBaseTable(nil))
+	table := K256().GeneratorTable()
+	require.Nil(t, table.Mul(nil))
+	require.Nil(t, table.Mul(P256().Scalar.One()))
+}
+
+func TestFixedBaseTableConcurrent(t *testing.T) {
+	curve := K256()
+	table := NewFixedBaseTable(curve.Point.Random(crand.Reader))
+	scalars := make([]Scalar, 8)
+	results := make([]Point, len(scalars))
+	var wg sync.WaitGroup
+	for i := range scalars {
+		scalars[i] = curve.Scalar.Random(crand.Reader)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = table.Mul(scalars[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, s := range scalars {
+		require.True(t, table.Base().Mul(s).Equal(results[i]))
+	}
+}
+
+func BenchmarkFixedBaseTable(b *testing.B) {
+	curve := K256()
+	table := curve.GeneratorTable()
+	s := curve.Scalar.Random(crand.Reader)
+	table.Mul(s)
+	b.Run("table", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			table.Mul(s)
+		}
+	})
+	b.Run("ScalarBaseMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.ScalarBaseMult(s)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GeneratorDomain prefixes every message hashed by DeriveGenerators.
+// Together with the curve's hash-to-curve suite used by Point.Hash,
+// e.g. BLS12381G1_XMD:SHA-256_SSWU_RO_, it fully determines the derived generators
+const GeneratorDomain = "kryptology-generators-v1"
+
+// maxGenerators bounds the generator index, which is encoded in 4 bytes
+const maxGenerators = 1<<32 - 1
+
+// DeriveGenerators derives `count` nothing-up-my-sleeve generators for `label`.
+// Generator i is Point.Hash(GeneratorDomain || I2OSP(len(label), 2) || label || I2OSP(i, 4)),
+// so nobody knows the discrete log relation between any two of them, and anyone
+// can recompute them with VerifyGenerators
+func DeriveGenerators(curve *Curve, label []byte, count int) ([]Point, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	if len(label) > 0xffff {
+		return nil, fmt.Errorf("label must be at most %d bytes", 0xffff)
+	}
+	if count < 1 || uint64(count) > maxGenerators {
+		return nil, fmt.Errorf("invalid generator count %d", count)
+	}
+	msg := make([]byte, 0, len(GeneratorDomain)+2+len(label)+4)
+	msg = append(msg, GeneratorDomain...)
+	msg = append(msg, byte(len(label)>>8), byte(len(label)))
+	msg = append(msg, label...)
+	prefix := len(msg)
+	msg = append(msg, 0, 0, 0, 0)
+
+	generators := make([]Point, count)
+	for i := range generators {
+		binary.BigEndian.PutUint32(msg[prefix:], uint32(i))
+		generators[i] = curve.Point.Hash(msg)
+		// A hash to curve output is the identity or outside
+		// the prime order subgroup with negligible probability
+		if err := ValidateDHPoint(generators[i]); err != nil {
+			return nil, fmt.Errorf("generator %d: %v", i, err)
+		}
+	}
+	return generators, nil
+}
+
+// VerifyGenerators checks that `generators` are exactly the first
+// len(generators) generators derived by DeriveGenerators for `label`
+func VerifyGenerators(curve *Curve, label []byte, generators []Point) error {
+	expected, err := DeriveGenerators(curve, label, len(generators))
+	if err != nil {
+		return err
+	}
+	for i, g := range generators {
+		if g == nil || !g.Equal(expected[i]) {
+			return fmt.Errorf("generator %d was not derived from the label", i)
+		}
+	}
+	return nil
+}
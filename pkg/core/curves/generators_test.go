@@ -0,0 +1,61 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveGenerators(t *testing.T) {
+	for _, curve := range []*Curve{K256(), P256(), ED25519(), PALLAS(), BLS12381G1(), BN254G1()} {
+		gens, err := DeriveGenerators(curve, []byte("pedersen"), 4)
+		require.NoError(t, err, curve.Name)
+		require.Len(t, gens, 4)
+		for i := range gens {
+			require.False(t, gens[i].IsIdentity())
+			require.False(t, gens[i].Equal(curve.Point.Generator()))
+			for j := 0; j < i; j++ {
+				require.False(t, gens[i].Equal(gens[j]))
+			}
+		}
+		require.NoError(t, VerifyGenerators(curve, []byte("pedersen"), gens))
+		// A prefix of the generators verifies, the derivation does not depend on the count
+		require.NoError(t, VerifyGenerators(curve, []byte("pedersen"), gens[:2]))
+
+		other, err := DeriveGenerators(curve, []byte("bulletproof"), 4)
+		require.NoError(t, err)
+		require.False(t, other[0].Equal(gens[0]))
+		require.Error(t, VerifyGenerators(curve, []byte("bulletproof"), gens))
+		require.Error(t, VerifyGenerators(curve, []byte("pedersen"), []Point{gens[1], gens[0]}))
+	}
+}
+
+func TestDeriveGeneratorsDeterministic(t *testing.T) {
+	curve := K256()
+	gens1, err := DeriveGenerators(curve, []byte("label"), 2)
+	require.NoError(t, err)
+	gens2, err := DeriveGenerators(curve, []byte("label"), 2)
+	require.NoError(t, err)
+	require.True(t, gens1[0].Equal(gens2[0]))
+	require.True(t, gens1[1].Equal(gens2[1]))
+	// The label length is encoded, so labels cannot run into the index
+	gens3, err := DeriveGenerators(curve, []byte("labe"), 2)
+	require.NoError(t, err)
+	require.False(t, gens1[0].Equal(gens3[0]))
+}
+
+func TestDeriveGeneratorsInvalid(t *testing.T) {
+	_, err := DeriveGenerators(nil, []byte("label"), 1)
+	require.Error(t, err)
+	_, err = DeriveGenerators(K256(), []byte("label"), 0)
+	require.Error(t, err)
+	_, err = DeriveGenerators(K256(), make([]byte, 0x10000), 1)
+	require.Error(t, err)
+	require.Error(t, VerifyGenerators(K256(), []byte("label"), nil))
+}
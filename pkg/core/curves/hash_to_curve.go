@@ -0,0 +1,244 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
+	ed448n "github.com/etclab/kryptology/pkg/core/curves/native/ed448"
+	secp256k1 "github.com/etclab/kryptology/pkg/core/curves/native/k256"
+	p256n "github.com/etclab/kryptology/pkg/core/curves/native/p256"
+)
+
+const (
+	// ed25519SuiteId is the RFC 9380 suite used by HashToCurve for ED25519
+	ed25519SuiteId = "edwards25519_XMD:SHA-512_ELL2_RO_"
+	// pallasSuiteId is the suite used by HashToCurve for PALLAS. It follows the
+	// structure of RFC 9380, which does not define a suite for the Pasta curves
+	pallasSuiteId = "pallas_XMD:BLAKE2b_SSWU_RO_"
+	// vestaSuiteId is the suite used by HashToCurve for VESTA
+	vestaSuiteId = "vesta_XMD:BLAKE2b_SSWU_RO_"
+)
+
+var (
+	// elligatorJ is the Montgomery coefficient A of curve25519
+	elligatorJ = new(field.Element).Mult32(new(field.Element).One(), 486662)
+	// edwardsMapConstant is sqrt(-486664) with sgn0 equal to 0, used by the rational map to edwards25519
+	edwardsMapConstant, _ = new(field.Element).SqrtRatio(
+		new(field.Element).Negate(new(field.Element).Mult32(new(field.Element).One(), 486664)),
+		new(field.Element).One(),
+	)
+	ed25519Modulus, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+)
+
+// HashToCurveSuite returns the RFC 9380 suite identifier used by HashToCurve for `curve`,
+// e.g. P256_XMD:SHA-256_SSWU_RO_
+func HashToCurveSuite(curve *Curve) (string, error) {
+	if curve == nil {
+		return "", fmt.Errorf("invalid curve")
+	}
+	switch curve.Name {
+	case K256Name:
+		return secp256k1.K256PointNew().SuiteId(native.EllipticPointHasherSha256()), nil
+	case P256Name:
+		return p256n.P256PointNew().SuiteId(native.EllipticPointHasherSha256()), nil
+	case ED25519Name:
+		return ed25519SuiteId, nil
+	case ED448Name:
+		return ed448n.SuiteId, nil
+	case BLS12381G1Name:
+		return "BLS12381G1_XMD:SHA-256_SSWU_RO_", nil
+	case BLS12381G2Name:
+		return "BLS12381G2_XMD:SHA-256_SSWU_RO_", nil
+	case BN254G1Name:
+		return "BN254G1_XMD:SHA-256_SVDW_RO_", nil
+	case BN254G2Name:
+		return "BN254G2_XMD:SHA-256_SVDW_RO_", nil
+	case PallasName:
+		return pallasSuiteId, nil
+	case VestaName:
+		return vestaSuiteId, nil
+	}
+	return "", fmt.Errorf("curve %s does not support hash to curve with a domain separation tag", curve.Name)
+}
+
+// HashToCurve hashes `msg` to a point of `curve` with the RFC 9380 hash_to_curve of the suite
+// returned by HashToCurveSuite and the application's domain separation tag `dst`, so the output
+// matches other implementations of the suite. Point.Hash is unchanged and, for ED25519, is not
+// compatible with RFC 9380
+func HashToCurve(curve *Curve, msg, dst []byte) (Point, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	switch curve.Name {
+	case ED25519Name:
+		if len(dst) == 0 {
+			return nil, fmt.Errorf("empty domain separation tag")
+		}
+		return hashToEd25519(msg, dst)
+	case PallasName:
+		if len(dst) == 0 {
+			return nil, fmt.Errorf("empty domain separation tag")
+		}
+		return &PointPallas{new(Ep).hashWithDst(msg, dst)}, nil
+	case VestaName:
+		if len(dst) == 0 {
+			return nil, fmt.Errorf("empty domain separation tag")
+		}
+		return &PointVesta{new(Eq).hashWithDst(msg, dst)}, nil
+	case BN254G1Name:
+		if len(dst) == 0 {
+			return nil, fmt.Errorf("empty domain separation tag")
+		}
+		value, err := bn254.HashToCurveG1Svdw(msg, bn254Dst(dst))
+		if err != nil {
+			return nil, err
+		}
+		return &PointBn254G1{&value}, nil
+	case BN254G2Name:
+		if len(dst) == 0 {
+			return nil, fmt.Errorf("empty domain separation tag")
+		}
+		value, err := bn254.HashToCurveG2Svdw(msg, bn254Dst(dst))
+		if err != nil {
+			return nil, err
+		}
+		return &PointBn254G2{&value}, nil
+	case ED448Name:
+		return HashToCurveWithHasher(curve, native.EllipticPointHasherShake256Rfc9380(), msg, dst)
+	}
+	return HashToCurveWithHasher(curve, native.EllipticPointHasherSha256(), msg, dst)
+}
+
+// HashToCurveWithHasher is HashToCurve with a choice of the expand_message function, e.g.
+// expand_message_xof with native.EllipticPointHasherShake256Rfc9380. It supports K256, P256, ED448 and BLS12-381
+func HashToCurveWithHasher(curve *Curve, hasher *native.EllipticPointHasher, msg, dst []byte) (Point, error) {
+	if curve == nil || hasher == nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	if len(dst) == 0 {
+		return nil, fmt.Errorf("empty domain separation tag")
+	}
+	switch curve.Name {
+	case K256Name:
+		value, err := secp256k1.K256PointNew().HashWithDst(msg, dst, hasher)
+		if err != nil {
+			return nil, err
+		}
+		return &PointK256{value}, nil
+	case P256Name:
+		value, err := p256n.P256PointNew().HashWithDst(msg, dst, hasher)
+		if err != nil {
+			return nil, err
+		}
+		return &PointP256{value}, nil
+	case ED448Name:
+		return &PointEd448{new(ed448n.Point).Hash(hasher, msg, dst)}, nil
+	case BLS12381G1Name:
+		return &PointBls12381G1{Value: new(bls12381.G1).Hash(hasher, msg, dst)}, nil
+	case BLS12381G2Name:
+		return &PointBls12381G2{Value: new(bls12381.G2).Hash(hasher, msg, dst)}, nil
+	}
+	return nil, fmt.Errorf("curve %s does not support hash to curve with a choice of hasher", curve.Name)
+}
+
+// bn254Dst hashes tags longer than 255 bytes as in RFC 9380 section 5.3.3, which gnark-crypto rejects
+func bn254Dst(dst []byte) []byte {
+	if len(dst) <= 255 {
+		return dst
+	}
+	h := sha256.New()
+	_, _ = h.Write(native.OversizeDstSalt)
+	_, _ = h.Write(dst)
+	return h.Sum(nil)
+}
+
+// hashToEd25519 implements edwards25519_XMD:SHA-512_ELL2_RO_ of RFC 9380 section 8.5
+func hashToEd25519(msg, dst []byte) (Point, error) {
+	// hash_to_field with L = 48 and count = 2
+	u := native.ExpandMsgXmd(native.EllipticPointHasherSha512(), msg, dst, 96)
+	q0, err := mapToEd25519(ed25519FieldElement(u[:48]))
+	if err != nil {
+		return nil, err
+	}
+	q1, err := mapToEd25519(ed25519FieldElement(u[48:]))
+	if err != nil {
+		return nil, err
+	}
+	r := edwards25519.NewIdentityPoint().Add(q0, q1)
+	return &PointEd25519{value: r.MultByCofactor(r)}, nil
+}
+
+// ed25519FieldElement reduces the big-endian bytes modulo 2^255 - 19
+func ed25519FieldElement(be []byte) *field.Element {
+	v := new(big.Int).SetBytes(be)
+	v.Mod(v, ed25519Modulus)
+	var buf [32]byte
+	v.FillBytes(buf[:])
+	e, _ := new(field.Element).SetBytes(internal.ReverseScalarBytes(buf[:]))
+	return e
+}
+
+// mapToEd25519 applies the Elligator 2 map to curve25519 of RFC 9380 section 6.7.1
+// and the rational map to edwards25519 of appendix D
+func mapToEd25519(u *field.Element) (*edwards25519.Point, error) {
+	one := new(field.Element).One()
+	negJ := new(field.Element).Negate(elligatorJ)
+
+	// x1 = -J / (1 + 2 * u^2), or -J if the denominator is zero
+	tv := new(field.Element).Square(u)
+	tv.Add(tv, tv)
+	tv.Add(tv, one)
+	x1 := new(field.Element).Multiply(negJ, new(field.Element).Invert(tv))
+	x1.Select(negJ, x1, x1.Equal(new(field.Element).Zero()))
+	// gx1 = x1^3 + J * x1^2 + x1
+	gx1 := montgomeryRhs(x1)
+	// x2 = -x1 - J
+	x2 := new(field.Element).Subtract(negJ, x1)
+	gx2 := montgomeryRhs(x2)
+
+	// If gx1 is square then (x1, y1) with sgn0(y1) = 1, otherwise (x2, y2) with sgn0(y2) = 0.
+	// SqrtRatio returns the root with sgn0 = 0
+	y1, isSquare := new(field.Element).SqrtRatio(gx1, one)
+	y1.Negate(y1)
+	y2, _ := new(field.Element).SqrtRatio(gx2, one)
+	s := new(field.Element).Select(x1, x2, isSquare)
+	t := new(field.Element).Select(y1, y2, isSquare)
+
+	// x = sqrt(-486664) * s / t, y = (s - 1) / (s + 1), or the identity when t * (s + 1) is zero
+	sPlusOne := new(field.Element).Add(s, one)
+	den := new(field.Element).Multiply(t, sPlusOne)
+	exceptional := den.Equal(new(field.Element).Zero())
+	inv := new(field.Element).Invert(den)
+	x := new(field.Element).Multiply(edwardsMapConstant, s)
+	x.Multiply(x, sPlusOne)
+	x.Multiply(x, inv)
+	y := new(field.Element).Subtract(s, one)
+	y.Multiply(y, t)
+	y.Multiply(y, inv)
+	x.Select(new(field.Element).Zero(), x, exceptional)
+	y.Select(one, y, exceptional)
+
+	return edwards25519.NewIdentityPoint().SetExtendedCoordinates(x, y, one, new(field.Element).Multiply(x, y))
+}
+
+// montgomeryRhs computes x^3 + J * x^2 + x for curve25519
+func montgomeryRhs(x *field.Element) *field.Element {
+	out := new(field.Element).Add(x, elligatorJ)
+	out.Multiply(out, x)
+	out.Add(out, new(field.Element).One())
+	return out.Multiply(out, x)
+}
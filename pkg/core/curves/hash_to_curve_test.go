@@ -0,0 +1,161 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+	secp256k1 "github.com/etclab/kryptology/pkg/core/curves/native/k256"
+	p256n "github.com/etclab/kryptology/pkg/core/curves/native/p256"
+)
+
+type hashToCurveVector struct {
+	msg, x, y string
+}
+
+// Vectors from RFC 9380 appendix J
+var hashToCurveVectors = map[string][]hashToCurveVector{
+	"P256_XMD:SHA-256_SSWU_RO_": {
+		{"", "2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4", "8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415"},
+		{"abc", "0bb8b87485551aa43ed54f009230450b492fead5f1cc91658775dac4a3388a0f", "5c41b3d0731a27a7b14bc0bf0ccded2d8751f83493404c84a88e71ffd424212e"},
+		{"abcdef0123456789", "65038ac8f2b1def042a5df0b33b1f4eca6bff7cb0f9c6c1526811864e544ed80", "cad44d40a656e7aff4002a8de287abc8ae0482b5ae825822bb870d6df9b56ca3"},
+	},
+	"secp256k1_XMD:SHA-256_SSWU_RO_": {
+		{"", "c1cae290e291aee617ebaef1be6d73861479c48b841eaba9b7b5852ddfeb1346", "64fa678e07ae116126f08b022a94af6de15985c996c3a91b64c406a960e51067"},
+		{"abc", "3377e01eab42db296b512293120c6cee72b6ecf9f9205760bd9ff11fb3cb2c4b", "7f95890f33efebd1044d382a01b1bee0900fb6116f94688d487c6c7b9c8371f6"},
+		{"abcdef0123456789", "bac54083f293f1fe08e4a70137260aa90783a5cb84d3f35848b324d0674b0e3a", "4436476085d4c3c4508b60fcf4389c40176adce756b398bdee27bca19758d828"},
+	},
+	"edwards25519_XMD:SHA-512_ELL2_RO_": {
+		{"", "3c3da6925a3c3c268448dcabb47ccde5439559d9599646a8260e47b1e4822fc6", "09a6c8561a0b22bef63124c588ce4c62ea83a3c899763af26d795302e115dc21"},
+		{"abc", "608040b42285cc0d72cbb3985c6b04c935370c7361f4b7fbdb1ae7f8c1a8ecad", "1a8395b88338f22e435bbd301183e7f20a5f9de643f11882fb237f88268a5531"},
+		{"abcdef0123456789", "6d7fabf47a2dc03fe7d47f7dddd21082c5fb8f86743cd020f3fb147d57161472", "53060a3d140e7fbcda641ed3cf42c88a75411e648a1add71217f70ea8ec561a6"},
+	},
+	"BLS12381G1_XMD:SHA-256_SSWU_RO_": {
+		{"", "052926add2207b76ca4fa57a8734416c8dc95e24501772c814278700eed6d1e4e8cf62d9c09db0fac349612b759e79a1", "08ba738453bfed09cb546dbb0783dbb3a5f1f566ed67bb6be0e8c67e2e81a4cc68ee29813bb7994998f3eae0c9c6a265"},
+		{"abc", "03567bc5ef9c690c2ab2ecdf6a96ef1c139cc0b2f284dca0a9a7943388a49a3aee664ba5379a7655d3c68900be2f6903", "0b9c15f3fe6e5cf4211f346271d7b01c8f3b28be689c8429c85b67af215533311f0b8dfaaa154fa6b88176c229f2885d"},
+	},
+}
+
+func TestHashToCurveVectors(t *testing.T) {
+	for _, curve := range []*Curve{P256(), K256(), ED25519(), BLS12381G1()} {
+		suite, err := HashToCurveSuite(curve)
+		require.NoError(t, err)
+		vectors, ok := hashToCurveVectors[suite]
+		require.True(t, ok, suite)
+		dst := []byte("QUUX-V01-CS02-with-" + suite)
+		for _, v := range vectors {
+			p, err := HashToCurve(curve, []byte(v.msg), dst)
+			require.NoError(t, err)
+			x, err := hex.DecodeString(v.x)
+			require.NoError(t, err)
+			y, err := hex.DecodeString(v.y)
+			require.NoError(t, err)
+
+			var expected []byte
+			if curve.Name == ED25519Name {
+				// Little-endian y with the sign of x in the top bit
+				expected = internal.ReverseScalarBytes(y)
+				expected[31] |= x[len(x)-1] & 1 << 7
+				require.Equal(t, expected, p.ToAffineCompressed(), "%s %q", suite, v.msg)
+				continue
+			}
+			if curve.Name == BLS12381G1Name {
+				expected = append(x, y...)
+			} else {
+				expected = append(append([]byte{4}, x...), y...)
+			}
+			require.Equal(t, expected, p.ToAffineUncompressed(), "%s %q", suite, v.msg)
+		}
+	}
+}
+
+func TestHashToCurveDst(t *testing.T) {
+	for _, curve := range []*Curve{P256(), K256(), ED25519(), BLS12381G1(), BLS12381G2(), PALLAS(), VESTA(), BN254G1(), BN254G2()} {
+		suite, err := HashToCurveSuite(curve)
+		require.NoError(t, err)
+		a, err := HashToCurve(curve, []byte("msg"), []byte("app-a"))
+		require.NoError(t, err)
+		b, err := HashToCurve(curve, []byte("msg"), []byte("app-b"))
+		require.NoError(t, err)
+		require.True(t, a.IsOnCurve())
+		require.False(t, a.IsIdentity())
+		require.False(t, a.Equal(b), suite)
+
+		// Oversize tags are hashed first
+		long, err := HashToCurve(curve, []byte("msg"), []byte(strings.Repeat("x", 300)))
+		require.NoError(t, err)
+		require.True(t, long.IsOnCurve())
+
+		_, err = HashToCurve(curve, []byte("msg"), nil)
+		require.Error(t, err)
+	}
+	// The suite DST reproduces Point.Hash, except for ed25519 which predates RFC 9380
+	for _, curve := range []*Curve{P256(), K256(), BLS12381G1(), BLS12381G2(), PALLAS(), VESTA(), BN254G1(), BN254G2()} {
+		suite, err := HashToCurveSuite(curve)
+		require.NoError(t, err)
+		p, err := HashToCurve(curve, []byte("msg"), []byte(suite))
+		require.NoError(t, err)
+		require.True(t, p.Equal(curve.Point.Hash([]byte("msg"))), suite)
+	}
+
+	_, err := HashToCurve(BLS12377G1(), []byte("msg"), []byte("app"))
+	require.Error(t, err)
+	_, err = HashToCurveWithHasher(ED25519(), native.EllipticPointHasherSha256(), []byte("msg"), []byte("app"))
+	require.Error(t, err)
+}
+
+func TestHashToCurveXof(t *testing.T) {
+	for _, curve := range []*Curve{P256(), K256(), BLS12381G1()} {
+		a, err := HashToCurveWithHasher(curve, native.EllipticPointHasherShake256Rfc9380(), []byte("msg"), []byte("app"))
+		require.NoError(t, err)
+		b, err := HashToCurveWithHasher(curve, native.EllipticPointHasherShake128(), []byte("msg"), []byte("app"))
+		require.NoError(t, err)
+		require.True(t, a.IsOnCurve())
+		require.False(t, a.Equal(b))
+	}
+}
+
+func TestHashShake256Compatibility(t *testing.T) {
+	// The points of EllipticPointHasherShake256 are those of earlier releases,
+	// which hash with the suite identifier of SHAKE-128
+	for _, tc := range []struct {
+		point func() *native.EllipticPoint
+		suite string
+		x, y  string
+	}{
+		{
+			p256n.P256PointNew, "P256_XOF:SHAKE-128_SSWU_RO_",
+			"be924c7961a118de69378e62c4eac4710aae4cc29bfcb9dae91999640ff5246c",
+			"77efa01ccd794b62c2a8f92a41bf6190e0d8de49d017c8d1bee649ee637d87a7",
+		},
+		{
+			secp256k1.K256PointNew, "secp256k1_XOF:SHAKE-128_SSWU_RO_",
+			"c1d0497364c1b57c5e61e86b70c37c66d53fb3e8e817dcf973081b309a33073b",
+			"a09ffcecde575369b54825afa1e8ebf59eea73c0a0e36db94ae3d7e48a307de6",
+		},
+	} {
+		require.Equal(t, tc.suite, tc.point().SuiteId(native.EllipticPointHasherShake256()))
+		p, err := tc.point().Hash([]byte("abc"), native.EllipticPointHasherShake256())
+		require.NoError(t, err)
+		x, y := p.GetX().Bytes(), p.GetY().Bytes()
+		require.Equal(t, tc.x, hex.EncodeToString(x[:]), tc.suite)
+		require.Equal(t, tc.y, hex.EncodeToString(y[:]), tc.suite)
+
+		// The RFC 9380 hasher is named SHAKE-256
+		suite := strings.Replace(tc.suite, "SHAKE-128", "SHAKE-256", 1)
+		require.Equal(t, suite, tc.point().SuiteId(native.EllipticPointHasherShake256Rfc9380()))
+		q, err := tc.point().Hash([]byte("abc"), native.EllipticPointHasherShake256Rfc9380())
+		require.NoError(t, err)
+		require.Equal(t, 0, p.Equal(q))
+	}
+}
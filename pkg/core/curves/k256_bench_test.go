@@ -360,6 +360,14 @@ func (p *BenchPoint) IsOnCurve() bool {
 	return btcec.S256().IsOnCurve(p.x, p.y)
 }
 
+func (p *BenchPoint) IsInPrimeSubgroup() bool {
+	return p.IsOnCurve()
+}
+
+func (p *BenchPoint) ClearCofactor() Point {
+	return &BenchPoint{x: new(big.Int).Set(p.x), y: new(big.Int).Set(p.y)}
+}
+
 func (p *BenchPoint) Double() Point {
 	x, y := btcec.S256().Double(p.x, p.y)
 	return &BenchPoint{
@@ -22,6 +22,10 @@ import (
 	"github.com/etclab/kryptology/pkg/core/curves/native/k256/fq"
 )
 
+func init() {
+	mustRegisterCurve(K256Name, OidSecp256k1, K256)
+}
+
 var oldK256Initonce sync.Once
 var oldK256 Koblitz256
 
@@ -412,14 +416,25 @@ func (p *PointK256) IsIdentity() bool {
 	return p.value.IsIdentity()
 }
 
+// IsNegative returns true if the affine Y coordinate is odd
 func (p *PointK256) IsNegative() bool {
-	return p.value.GetY().Value[0]&1 == 1
+	return p.value.GetY().Bytes()[0]&1 == 1
 }
 
 func (p *PointK256) IsOnCurve() bool {
 	return p.value.IsOnCurve()
 }
 
+// IsInPrimeSubgroup reports whether the point is on the curve, whose group has prime order
+func (p *PointK256) IsInPrimeSubgroup() bool {
+	return p.IsIdentity() || p.IsOnCurve()
+}
+
+// ClearCofactor returns a copy of the point, as the cofactor is 1
+func (p *PointK256) ClearCofactor() Point {
+	return &PointK256{secp256k1.K256PointNew().Set(p.value)}
+}
+
 func (p *PointK256) Double() Point {
 	value := secp256k1.K256PointNew().Double(p.value)
 	return &PointK256{value}
@@ -535,8 +550,11 @@ func (p *PointK256) FromAffineCompressed(bytes []byte) (Point, error) {
 	rhs := fp.K256FpNew()
 	p.value.Arithmetic.RhsEq(rhs, x)
 	// test that rhs is quadratic residue
-	// if not, then this Point is at infinity
+	// if not, x must be zero which encodes the point at infinity
 	y, wasQr := fp.K256FpNew().Sqrt(rhs)
+	if !wasQr && x.IsZero() == 0 {
+		return nil, fmt.Errorf("invalid x coordinate")
+	}
 	if wasQr {
 		// fix the sign
 		sigY := int(y.Bytes()[0] & 1)
@@ -570,9 +588,16 @@ func (p *PointK256) FromAffineUncompressed(bytes []byte) (Point, error) {
 		return nil, err
 	}
 	value := secp256k1.K256PointNew()
+	// All zero coordinates encode the identity
+	if x.IsZero()&y.IsZero() == 1 {
+		return &PointK256{value.Identity()}, nil
+	}
 	value.X = x
 	value.Y = y
 	value.Z.SetOne()
+	if !value.IsOnCurve() {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
 	return &PointK256{value}, nil
 }
 
@@ -581,28 +606,63 @@ func (p *PointK256) CurveName() string {
 }
 
 func (p *PointK256) SumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := k256Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value := secp256k1.K256PointNew()
+	_, err := value.SumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointK256{value}
+}
+
+// VarTimeMul multiplies the point by `rhs` in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (p *PointK256) VarTimeMul(rhs Scalar) Point {
+	r, ok := rhs.(*ScalarK256)
+	if !ok {
+		return nil
+	}
+	value := secp256k1.K256PointNew().VarTimeMul(p.value, r.value)
+	return &PointK256{value}
+}
+
+// VarTimeSumOfProducts computes the multi-scalar multiplication of `points` and `scalars`
+// in variable time. It must only be used with public scalars, such as when verifying proofs
+func (p *PointK256) VarTimeSumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := k256Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value := secp256k1.K256PointNew()
+	_, err := value.VarTimeSumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointK256{value}
+}
+
+// k256Batch converts `points` and `scalars` to their native values
+func k256Batch(points []Point, scalars []Scalar) ([]*native.EllipticPoint, []*native.Field, bool) {
 	nPoints := make([]*native.EllipticPoint, len(points))
 	nScalars := make([]*native.Field, len(scalars))
 	for i, pt := range points {
 		ptv, ok := pt.(*PointK256)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nPoints[i] = ptv.value
 	}
 	for i, sc := range scalars {
 		s, ok := sc.(*ScalarK256)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nScalars[i] = s.value
 	}
-	value := secp256k1.K256PointNew()
-	_, err := value.SumOfProducts(nPoints, nScalars)
-	if err != nil {
-		return nil
-	}
-	return &PointK256{value}
+	return nPoints, nScalars, true
 }
 
 func (p *PointK256) X() *native.Field {
@@ -315,6 +315,15 @@ func TestPointK256Neg(t *testing.T) {
 	require.True(t, k256.Point.Identity().Neg().Equal(k256.Point.Identity()))
 }
 
+func TestPointK256IsNegative(t *testing.T) {
+	k256 := K256()
+	for i := 0; i < 25; i++ {
+		p := k256.Point.Random(crand.Reader)
+		require.Equal(t, p.ToAffineCompressed()[0] == 3, p.IsNegative())
+		require.NotEqual(t, p.IsNegative(), p.Neg().IsNegative())
+	}
+}
+
 func TestPointK256Add(t *testing.T) {
 	curve := K256()
 	pt := curve.Point.Generator().(*PointK256)
@@ -0,0 +1,45 @@
+package native
+
+// BatchNormalize converts every point to affine coordinates in place, like ToAffine,
+// but with Montgomery's trick so that all the points share a single field inversion
+// instead of one each. It assumes the homogeneous projective coordinates, x = X/Z and
+// y = Y/Z, of the k256 and p256 arithmetic. The identity becomes (0, 0, 0) as with
+// ToAffine, and which points are the identity does not affect the running time.
+// All points must be on the same curve
+func BatchNormalize(points []*EllipticPoint) {
+	if len(points) == 0 {
+		return
+	}
+	// prefix[i] is the product of the Z coordinates of points 0..i, with one in place of zero
+	zs := make([]*Field, len(points))
+	prefix := make([]*Field, len(points))
+	identity := make([]int, len(points))
+	one := new(Field).Set(points[0].Z).SetOne()
+	for i, p := range points {
+		identity[i] = p.Z.IsZero()
+		zs[i] = new(Field).Set(p.Z).CMove(p.Z, one, identity[i])
+		prefix[i] = new(Field).Set(zs[i])
+		if i > 0 {
+			prefix[i].Mul(prefix[i-1], zs[i])
+		}
+	}
+
+	// The product is never zero, so the inversion always succeeds
+	inv, _ := new(Field).Set(one).Invert(prefix[len(points)-1])
+	zInv := new(Field).Set(one)
+	zero := new(Field).Set(one).SetZero()
+	for i := len(points) - 1; i >= 0; i-- {
+		// inv is the inverse of prefix[i], so inv * prefix[i-1] is 1/Z_i
+		if i > 0 {
+			zInv.Mul(inv, prefix[i-1])
+		} else {
+			zInv.Set(inv)
+		}
+		inv.Mul(inv, zs[i])
+
+		p := points[i]
+		p.X.Mul(p.X, zInv).CMove(p.X, zero, identity[i])
+		p.Y.Mul(p.Y, zInv).CMove(p.Y, zero, identity[i])
+		p.Z.CMove(one, zero, identity[i])
+	}
+}
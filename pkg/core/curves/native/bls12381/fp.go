@@ -8,6 +8,7 @@ import (
 
 	"github.com/etclab/kryptology/internal"
 	"github.com/etclab/kryptology/pkg/core/curves/native"
+	"github.com/etclab/kryptology/pkg/core/curves/native/internal/mont"
 )
 
 // fp field element mod p
@@ -251,6 +252,10 @@ func (f *fp) Neg(a *fp) *fp {
 
 // Square performs modular square
 func (f *fp) Square(a *fp) *fp {
+	if mont.HasAsm {
+		mont.Mul6((*[Limbs]uint64)(f), (*[Limbs]uint64)(a), (*[Limbs]uint64)(a), (*[Limbs]uint64)(&modulus), inv)
+		return f
+	}
 	var r [2 * Limbs]uint64
 	var carry uint64
 
@@ -309,6 +314,10 @@ func (f *fp) Double(a *fp) *fp {
 
 // Mul performs modular multiplication
 func (f *fp) Mul(arg1, arg2 *fp) *fp {
+	if mont.HasAsm {
+		mont.Mul6((*[Limbs]uint64)(f), (*[Limbs]uint64)(arg1), (*[Limbs]uint64)(arg2), (*[Limbs]uint64)(&modulus), inv)
+		return f
+	}
 	// Schoolbook multiplication
 	var r [2 * Limbs]uint64
 	var carry uint64
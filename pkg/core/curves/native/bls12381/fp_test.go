@@ -295,3 +295,12 @@ func TestFpLexicographicallyLargest(t *testing.T) {
 		0x040ab3263eff0206,
 	}).LexicographicallyLargest())
 }
+
+func BenchmarkFpMul(b *testing.B) {
+	var x fp
+	x.Random(crand.Reader)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Mul(&x, &x)
+	}
+}
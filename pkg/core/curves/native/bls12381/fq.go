@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/etclab/kryptology/pkg/core/curves/native"
+	"github.com/etclab/kryptology/pkg/core/curves/native/internal/mont"
 )
 
 type Fq [native.FieldLimbs]uint64
@@ -89,6 +90,10 @@ func (f bls12381FqArithmetic) Neg(out, arg *[native.FieldLimbs]uint64) {
 
 // Square performs modular square
 func (f bls12381FqArithmetic) Square(out, arg *[native.FieldLimbs]uint64) {
+	if mont.HasAsm {
+		mont.Mul4(out, arg, arg, &fqModulus, qInv)
+		return
+	}
 	var r [2 * native.FieldLimbs]uint64
 	var carry uint64
 
@@ -123,6 +128,10 @@ func (f bls12381FqArithmetic) Square(out, arg *[native.FieldLimbs]uint64) {
 
 // Mul performs modular multiplication
 func (f bls12381FqArithmetic) Mul(out, arg1, arg2 *[native.FieldLimbs]uint64) {
+	if mont.HasAsm {
+		mont.Mul4(out, arg1, arg2, &fqModulus, qInv)
+		return
+	}
 	// Schoolbook multiplication
 	var r [2 * native.FieldLimbs]uint64
 	var carry uint64
@@ -834,6 +834,9 @@ func (g1 *G1) FromCompressed(input *[FieldBytes]byte) (*G1, error) {
 	}
 
 	if infinityFlag == 1 {
+		if !isCanonicalInfinity(input[:]) {
+			return nil, errors.New("non-canonical encoding of the identity")
+		}
 		return g1.Identity(), nil
 	}
 
@@ -887,6 +890,9 @@ func (g1 *G1) FromUncompressed(input *[WideFieldBytes]byte) (*G1, error) {
 	infinityFlag := int((input[0] >> 6) & 1)
 
 	if infinityFlag == 1 {
+		if !isCanonicalInfinity(input[:]) {
+			return nil, errors.New("non-canonical encoding of the identity")
+		}
 		return g1.Identity(), nil
 	}
 
@@ -931,6 +937,49 @@ func (g1 *G1) ToAffine(a *G1) *G1 {
 	return g1
 }
 
+// BatchNormalizeG1 converts every point to affine coordinates in place, like ToAffine,
+// but with Montgomery's trick so that all the points share a single field inversion
+// instead of one each. Which points are the identity does not affect the running time
+func BatchNormalizeG1(points []*G1) {
+	if len(points) == 0 {
+		return
+	}
+	// prefix[i] is the product of the z coordinates of points 0..i, with one in place of zero
+	zs := make([]fp, len(points))
+	prefix := make([]fp, len(points))
+	identity := make([]int, len(points))
+	var one, zero fp
+	one.SetOne()
+	for i, p := range points {
+		identity[i] = p.z.IsZero()
+		zs[i].CMove(&p.z, &one, identity[i])
+		prefix[i] = zs[i]
+		if i > 0 {
+			prefix[i].Mul(&prefix[i-1], &zs[i])
+		}
+	}
+
+	// The product is never zero, so the inversion always succeeds
+	var inv, zInv fp
+	inv.Invert(&prefix[len(points)-1])
+	for i := len(points) - 1; i >= 0; i-- {
+		// inv is the inverse of prefix[i], so inv * prefix[i-1] is 1/z_i
+		if i > 0 {
+			zInv.Mul(&inv, &prefix[i-1])
+		} else {
+			zInv = inv
+		}
+		inv.Mul(&inv, &zs[i])
+
+		p := points[i]
+		p.x.Mul(&p.x, &zInv)
+		p.x.CMove(&p.x, &zero, identity[i])
+		p.y.Mul(&p.y, &zInv)
+		p.y.CMove(&p.y, &zero, identity[i])
+		p.z.CMove(&one, &zero, identity[i])
+	}
+}
+
 // GetX returns the affine X coordinate
 func (g1 *G1) GetX() *fp {
 	var t G1
@@ -974,18 +1023,24 @@ func (g1 *G1) CMove(arg1, arg2 *G1, choice int) *G1 {
 
 // SumOfProducts computes the multi-exponentiation for the specified
 // points and scalars and stores the result in `g1`.
+// It uses Pippenger's bucket method with a window chosen by native.PippengerWindowSize.
 // Returns an error if the lengths of the arguments is not equal.
 func (g1 *G1) SumOfProducts(points []*G1, scalars []*native.Field) (*G1, error) {
-	const Upper = 256
-	const W = 4
-	const Windows = Upper / W // careful--use ceiling division in case this doesn't divide evenly
+	return g1.sumOfProducts(points, scalars, false)
+}
+
+// sumOfProducts is Pippenger's bucket method, which skips the additions
+// that do not change the result when `varTime` is true
+func (g1 *G1) sumOfProducts(points []*G1, scalars []*native.Field, varTime bool) (*G1, error) {
 	var sum G1
 	if len(points) != len(scalars) {
 		return nil, fmt.Errorf("length mismatch")
 	}
 
-	bucketSize := 1 << W
-	windows := make([]G1, Windows)
+	w := native.PippengerWindowSize(len(points))
+	windowCount := (native.FieldBytes*8 + w - 1) / w
+	bucketSize := 1 << uint(w)
+	windows := make([]G1, windowCount)
 	bytes := make([][32]byte, len(scalars))
 	buckets := make([]G1, bucketSize)
 
@@ -1003,11 +1058,12 @@ func (g1 *G1) SumOfProducts(points []*G1, scalars []*native.Field) (*G1, error)
 		}
 
 		for i := 0; i < len(scalars); i++ {
-			// j*W to get the nibble
-			// >> 3 to convert to byte, / 8
-			// (W * j & W) gets the nibble, mod W
-			// 1 << W - 1 to get the offset
-			index := bytes[i][j*W>>3] >> (W * j & W) & (1<<W - 1) // little-endian
+			// bucket 0 is never used but is still added to so
+			// every point costs the same regardless of its scalar
+			index := native.ScalarWindow(bytes[i][:], j*w, w) // little-endian
+			if varTime && index == 0 {
+				continue
+			}
 			buckets[index].Add(&buckets[index], points[i])
 		}
 
@@ -1021,7 +1077,7 @@ func (g1 *G1) SumOfProducts(points []*G1, scalars []*native.Field) (*G1, error)
 
 	g1.Identity()
 	for i := len(windows) - 1; i >= 0; i-- {
-		for j := 0; j < W; j++ {
+		for j := 0; j < w; j++ {
 			g1.Double(g1)
 		}
 
@@ -1122,3 +1178,14 @@ func computeKFp(xxs []fp, k []fp) fp {
 	}
 	return xx
 }
+
+// isCanonicalInfinity checks that an encoding with the infinity flag set has
+// the sort flag and all other bits cleared, as required by the ZCash serialization
+func isCanonicalInfinity(input []byte) bool {
+	var acc byte
+	acc |= input[0] & 0x3F
+	for _, b := range input[1:] {
+		acc |= b
+	}
+	return acc == 0
+}
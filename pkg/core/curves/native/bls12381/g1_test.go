@@ -410,3 +410,23 @@ func TestSumOfProducts(t *testing.T) {
 	_, _ = rhs.SumOfProducts([]*G1{u, h0}, []*native.Field{c, sHat})
 	require.Equal(t, 1, uTilde.Equal(rhs))
 }
+
+func TestG1BatchNormalize(t *testing.T) {
+	points := make([]*G1, 10)
+	expected := make([]*G1, len(points))
+	for i := range points {
+		p, err := new(G1).Random(crand.Reader)
+		require.NoError(t, err)
+		points[i] = new(G1).Double(p)
+		if i%4 == 1 {
+			points[i].Identity()
+		}
+		expected[i] = new(G1).ToAffine(points[i])
+	}
+	BatchNormalizeG1(points)
+	for i, p := range points {
+		require.Equal(t, expected[i].x, p.x, "point %d", i)
+		require.Equal(t, expected[i].y, p.y, "point %d", i)
+		require.Equal(t, expected[i].z, p.z, "point %d", i)
+	}
+}
@@ -809,6 +809,9 @@ func (g2 *G2) FromCompressed(input *[WideFieldBytes]byte) (*G2, error) {
 	}
 
 	if infinityFlag == 1 {
+		if !isCanonicalInfinity(input[:]) {
+			return nil, errors.New("non-canonical encoding of the identity")
+		}
 		return g2.Identity(), nil
 	}
 
@@ -869,6 +872,9 @@ func (g2 *G2) FromUncompressed(input *[DoubleWideFieldBytes]byte) (*G2, error) {
 	infinityFlag := int((input[0] >> 6) & 1)
 
 	if infinityFlag == 1 {
+		if !isCanonicalInfinity(input[:]) {
+			return nil, errors.New("non-canonical encoding of the identity")
+		}
 		return g2.Identity(), nil
 	}
 
@@ -927,6 +933,49 @@ func (g2 *G2) ToAffine(a *G2) *G2 {
 	return g2
 }
 
+// BatchNormalizeG2 converts every point to affine coordinates in place, like ToAffine,
+// but with Montgomery's trick so that all the points share a single field inversion
+// instead of one each. Which points are the identity does not affect the running time
+func BatchNormalizeG2(points []*G2) {
+	if len(points) == 0 {
+		return
+	}
+	// prefix[i] is the product of the z coordinates of points 0..i, with one in place of zero
+	zs := make([]fp2, len(points))
+	prefix := make([]fp2, len(points))
+	identity := make([]int, len(points))
+	var one, zero fp2
+	one.SetOne()
+	for i, p := range points {
+		identity[i] = p.z.IsZero()
+		zs[i].CMove(&p.z, &one, identity[i])
+		prefix[i] = zs[i]
+		if i > 0 {
+			prefix[i].Mul(&prefix[i-1], &zs[i])
+		}
+	}
+
+	// The product is never zero, so the inversion always succeeds
+	var inv, zInv fp2
+	inv.Invert(&prefix[len(points)-1])
+	for i := len(points) - 1; i >= 0; i-- {
+		// inv is the inverse of prefix[i], so inv * prefix[i-1] is 1/z_i
+		if i > 0 {
+			zInv.Mul(&inv, &prefix[i-1])
+		} else {
+			zInv = inv
+		}
+		inv.Mul(&inv, &zs[i])
+
+		p := points[i]
+		p.x.Mul(&p.x, &zInv)
+		p.x.CMove(&p.x, &zero, identity[i])
+		p.y.Mul(&p.y, &zInv)
+		p.y.CMove(&p.y, &zero, identity[i])
+		p.z.CMove(&one, &zero, identity[i])
+	}
+}
+
 // GetX returns the affine X coordinate
 func (g2 *G2) GetX() *fp2 {
 	var t G2
@@ -970,18 +1019,24 @@ func (g2 *G2) CMove(arg1, arg2 *G2, choice int) *G2 {
 
 // SumOfProducts computes the multi-exponentiation for the specified
 // points and scalars and stores the result in `g2`.
+// It uses Pippenger's bucket method with a window chosen by native.PippengerWindowSize.
 // Returns an error if the lengths of the arguments is not equal.
 func (g2 *G2) SumOfProducts(points []*G2, scalars []*native.Field) (*G2, error) {
-	const Upper = 256
-	const W = 4
-	const Windows = Upper / W // careful--use ceiling division in case this doesn't divide evenly
+	return g2.sumOfProducts(points, scalars, false)
+}
+
+// sumOfProducts is Pippenger's bucket method, which skips the additions
+// that do not change the result when `varTime` is true
+func (g2 *G2) sumOfProducts(points []*G2, scalars []*native.Field, varTime bool) (*G2, error) {
 	var sum G2
 	if len(points) != len(scalars) {
 		return nil, fmt.Errorf("length mismatch")
 	}
 
-	bucketSize := 1 << W
-	windows := make([]G2, Windows)
+	w := native.PippengerWindowSize(len(points))
+	windowCount := (native.FieldBytes*8 + w - 1) / w
+	bucketSize := 1 << uint(w)
+	windows := make([]G2, windowCount)
 	bytes := make([][32]byte, len(scalars))
 	buckets := make([]G2, bucketSize)
 	for i := 0; i < len(windows); i++ {
@@ -998,11 +1053,12 @@ func (g2 *G2) SumOfProducts(points []*G2, scalars []*native.Field) (*G2, error)
 		}
 
 		for i := 0; i < len(scalars); i++ {
-			// j*W to get the nibble
-			// >> 3 to convert to byte, / 8
-			// (W * j & W) gets the nibble, mod W
-			// 1 << W - 1 to get the offset
-			index := bytes[i][j*W>>3] >> (W * j & W) & (1<<W - 1) // little-endian
+			// bucket 0 is never used but is still added to so
+			// every point costs the same regardless of its scalar
+			index := native.ScalarWindow(bytes[i][:], j*w, w) // little-endian
+			if varTime && index == 0 {
+				continue
+			}
 			buckets[index].Add(&buckets[index], points[i])
 		}
 
@@ -1016,7 +1072,7 @@ func (g2 *G2) SumOfProducts(points []*G2, scalars []*native.Field) (*G2, error)
 
 	g2.Identity()
 	for i := len(windows) - 1; i >= 0; i-- {
-		for j := 0; j < W; j++ {
+		for j := 0; j < w; j++ {
 			g2.Double(g2)
 		}
 
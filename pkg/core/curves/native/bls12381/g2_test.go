@@ -552,3 +552,23 @@ func TestG2SumOfProducts(t *testing.T) {
 	_, _ = rhs.SumOfProducts([]*G2{u, h0}, []*native.Field{c, sHat})
 	require.Equal(t, 1, uTilde.Equal(rhs))
 }
+
+func TestG2BatchNormalize(t *testing.T) {
+	points := make([]*G2, 10)
+	expected := make([]*G2, len(points))
+	for i := range points {
+		p, err := new(G2).Random(crand.Reader)
+		require.NoError(t, err)
+		points[i] = new(G2).Double(p)
+		if i%4 == 1 {
+			points[i].Identity()
+		}
+		expected[i] = new(G2).ToAffine(points[i])
+	}
+	BatchNormalizeG2(points)
+	for i, p := range points {
+		require.Equal(t, expected[i].x, p.x, "point %d", i)
+		require.Equal(t, expected[i].y, p.y, "point %d", i)
+		require.Equal(t, expected[i].z, p.z, "point %d", i)
+	}
+}
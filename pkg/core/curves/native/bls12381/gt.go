@@ -317,9 +317,11 @@ func (gt *Gt) Neg(a *Gt) *Gt {
 func (gt *Gt) Mul(a *Gt, s *native.Field) *Gt {
 	var f, p fp12
 	f.Set((*fp12)(a))
+	p.SetOne()
 	bytes := s.Bytes()
 
 	precomputed := [16]fp12{}
+	precomputed[0].SetOne()
 	precomputed[1].Set(&f)
 	for i := 2; i < 16; i += 2 {
 		precomputed[i].Square(&precomputed[i>>1])
@@ -65,13 +65,24 @@ func (e *Engine) Result() *Gt {
 	return e.pairing()
 }
 
-func (e *Engine) pairing() *Gt {
+// MillerLoop returns the product of the Miller loops of the pairs without the final
+// exponentiation. The results of several engines can be multiplied together and
+// finished with a single Gt.FinalExponentiation
+func (e *Engine) MillerLoop() *Gt {
 	f := new(Gt).SetOne()
 	if len(e.pairs) == 0 {
 		return f
 	}
 	coeffs := e.computeCoeffs()
 	e.millerLoop((*fp12)(f), coeffs)
+	return f
+}
+
+func (e *Engine) pairing() *Gt {
+	if len(e.pairs) == 0 {
+		return new(Gt).SetOne()
+	}
+	f := e.MillerLoop()
 	return f.FinalExponentiation(f)
 }
 
@@ -62,3 +62,43 @@ func TestMultiPairing(t *testing.T) {
 	actual := e2.Result()
 	require.Equal(t, 1, expected.Equal(actual))
 }
+
+func TestGtMul(t *testing.T) {
+	var bytes [64]byte
+	_, _ = crand.Read(bytes[:])
+	sc := Bls12381FqNew()
+	sc.SetBytesWide(&bytes)
+
+	e := new(Engine)
+	e.AddPair(new(G1).Generator(), new(G2).Generator())
+	z := e.Result()
+	e.Reset()
+	e.AddPair(new(G1).Mul(new(G1).Generator(), sc), new(G2).Generator())
+	expected := e.Result()
+
+	require.Equal(t, 1, new(Gt).Mul(z, sc).Equal(expected))
+	require.Equal(t, 1, new(Gt).Mul(z, Bls12381FqNew().SetOne()).Equal(z))
+	require.Equal(t, 1, new(Gt).Mul(z, Bls12381FqNew().SetZero()).IsOne())
+}
+
+func TestMillerLoop(t *testing.T) {
+	var bytes [64]byte
+	_, _ = crand.Read(bytes[:])
+	s := Bls12381FqNew().SetBytesWide(&bytes)
+	g := new(G1).Generator()
+	h := new(G2).Generator()
+	sg := new(G1).Mul(g, s)
+	sh := new(G2).Mul(h, s)
+
+	e1 := new(Engine)
+	e1.AddPair(sg, h)
+	e2 := new(Engine)
+	e2.AddPairInvG1(g, sh)
+	f := new(Gt).Add(e1.MillerLoop(), e2.MillerLoop())
+	require.Equal(t, 1, f.FinalExponentiation(f).IsOne())
+
+	e1.AddPair(g, sh)
+	f = e1.MillerLoop()
+	require.Equal(t, 1, f.FinalExponentiation(f).Equal(e1.Result()))
+	require.Equal(t, 1, new(Engine).MillerLoop().IsOne())
+}
@@ -0,0 +1,135 @@
+package bls12381
+
+import (
+	"fmt"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+// VarTimeMul multiplies the point by the scalar in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (g1 *G1) VarTimeMul(a *G1, s *native.Field) *G1 {
+	bytes := s.Bytes()
+	digits := native.Wnaf(bytes[:], native.VarTimeWindow)
+	table := oddMultiplesG1(a)
+	g1.Identity()
+	for i := len(digits) - 1; i >= 0; i-- {
+		g1.Double(g1)
+		g1.addDigit(table, digits[i])
+	}
+	return g1
+}
+
+// VarTimeSumOfProducts computes the multi-exponentiation for the specified points and
+// scalars in variable time and stores the result in `g1`. It must only be used with public scalars.
+// Returns an error if the lengths of the arguments is not equal.
+func (g1 *G1) VarTimeSumOfProducts(points []*G1, scalars []*native.Field) (*G1, error) {
+	if !native.UseStraus(len(points)) {
+		return g1.sumOfProducts(points, scalars, true)
+	}
+	if len(points) != len(scalars) {
+		return nil, fmt.Errorf("length mismatch")
+	}
+	digits := make([][]int8, len(points))
+	tables := make([][]G1, len(points))
+	for i, point := range points {
+		bytes := scalars[i].Bytes()
+		digits[i] = native.Wnaf(bytes[:], native.VarTimeWindow)
+		tables[i] = oddMultiplesG1(point)
+	}
+	g1.Identity()
+	for j := native.MaxLength(digits) - 1; j >= 0; j-- {
+		g1.Double(g1)
+		for i := range points {
+			if j < len(digits[i]) {
+				g1.addDigit(tables[i], digits[i][j])
+			}
+		}
+	}
+	return g1, nil
+}
+
+// oddMultiplesG1 returns a, 3*a, ..., (2^(native.VarTimeWindow-1)-1)*a
+func oddMultiplesG1(a *G1) []G1 {
+	var double G1
+	table := make([]G1, 1<<(native.VarTimeWindow-2))
+	double.Double(a)
+	table[0].Set(a)
+	for i := 1; i < len(table); i++ {
+		table[i].Add(&table[i-1], &double)
+	}
+	return table
+}
+
+// addDigit adds the multiple of a wNAF digit from the table of odd multiples
+func (g1 *G1) addDigit(table []G1, digit int8) {
+	if digit > 0 {
+		g1.Add(g1, &table[digit/2])
+	} else if digit < 0 {
+		g1.Sub(g1, &table[-digit/2])
+	}
+}
+
+// VarTimeMul multiplies the point by the scalar in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (g2 *G2) VarTimeMul(a *G2, s *native.Field) *G2 {
+	bytes := s.Bytes()
+	digits := native.Wnaf(bytes[:], native.VarTimeWindow)
+	table := oddMultiplesG2(a)
+	g2.Identity()
+	for i := len(digits) - 1; i >= 0; i-- {
+		g2.Double(g2)
+		g2.addDigit(table, digits[i])
+	}
+	return g2
+}
+
+// VarTimeSumOfProducts computes the multi-exponentiation for the specified points and
+// scalars in variable time and stores the result in `g2`. It must only be used with public scalars.
+// Returns an error if the lengths of the arguments is not equal.
+func (g2 *G2) VarTimeSumOfProducts(points []*G2, scalars []*native.Field) (*G2, error) {
+	if !native.UseStraus(len(points)) {
+		return g2.sumOfProducts(points, scalars, true)
+	}
+	if len(points) != len(scalars) {
+		return nil, fmt.Errorf("length mismatch")
+	}
+	digits := make([][]int8, len(points))
+	tables := make([][]G2, len(points))
+	for i, point := range points {
+		bytes := scalars[i].Bytes()
+		digits[i] = native.Wnaf(bytes[:], native.VarTimeWindow)
+		tables[i] = oddMultiplesG2(point)
+	}
+	g2.Identity()
+	for j := native.MaxLength(digits) - 1; j >= 0; j-- {
+		g2.Double(g2)
+		for i := range points {
+			if j < len(digits[i]) {
+				g2.addDigit(tables[i], digits[i][j])
+			}
+		}
+	}
+	return g2, nil
+}
+
+// oddMultiplesG2 returns a, 3*a, ..., (2^(native.VarTimeWindow-1)-1)*a
+func oddMultiplesG2(a *G2) []G2 {
+	var double G2
+	table := make([]G2, 1<<(native.VarTimeWindow-2))
+	double.Double(a)
+	table[0].Set(a)
+	for i := 1; i < len(table); i++ {
+		table[i].Add(&table[i-1], &double)
+	}
+	return table
+}
+
+// addDigit adds the multiple of a wNAF digit from the table of odd multiples
+func (g2 *G2) addDigit(table []G2, digit int8) {
+	if digit > 0 {
+		g2.Add(g2, &table[digit/2])
+	} else if digit < 0 {
+		g2.Sub(g2, &table[-digit/2])
+	}
+}
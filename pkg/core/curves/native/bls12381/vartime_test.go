@@ -0,0 +1,61 @@
+package bls12381
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+func randomScalars(n int) []*native.Field {
+	scalars := make([]*native.Field, n)
+	for i := range scalars {
+		var bytes [64]byte
+		_, _ = crand.Read(bytes[:])
+		scalars[i] = Bls12381FqNew().SetBytesWide(&bytes)
+	}
+	scalars[0] = Bls12381FqNew().SetZero()
+	if n > 1 {
+		scalars[1] = Bls12381FqNew().SetOne()
+	}
+	if n > 2 {
+		scalars[2] = Bls12381FqNew().Neg(Bls12381FqNew().SetOne())
+	}
+	return scalars
+}
+
+func TestG1VarTime(t *testing.T) {
+	for _, n := range []int{1, 3, 10, 40} {
+		scalars := randomScalars(n)
+		points := make([]*G1, n)
+		for i := range points {
+			points[i] = new(G1).Mul(new(G1).Generator(), randomScalars(4)[3])
+			require.Equal(t, 1, new(G1).VarTimeMul(points[i], scalars[i]).Equal(new(G1).Mul(points[i], scalars[i])))
+		}
+		expected, err := new(G1).SumOfProducts(points, scalars)
+		require.NoError(t, err)
+		actual, err := new(G1).VarTimeSumOfProducts(points, scalars)
+		require.NoError(t, err)
+		require.Equal(t, 1, expected.Equal(actual))
+	}
+	_, err := new(G1).VarTimeSumOfProducts([]*G1{new(G1).Generator()}, nil)
+	require.Error(t, err)
+}
+
+func TestG2VarTime(t *testing.T) {
+	for _, n := range []int{1, 3, 40} {
+		scalars := randomScalars(n)
+		points := make([]*G2, n)
+		for i := range points {
+			points[i] = new(G2).Mul(new(G2).Generator(), randomScalars(4)[3])
+			require.Equal(t, 1, new(G2).VarTimeMul(points[i], scalars[i]).Equal(new(G2).Mul(points[i], scalars[i])))
+		}
+		expected, err := new(G2).SumOfProducts(points, scalars)
+		require.NoError(t, err)
+		actual, err := new(G2).VarTimeSumOfProducts(points, scalars)
+		require.NoError(t, err)
+		require.Equal(t, 1, expected.Equal(actual))
+	}
+}
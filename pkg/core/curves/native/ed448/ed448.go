@@ -0,0 +1,495 @@
+// Package ed448 implements the arithmetic of edwards448, the Edwards form of
+// Goldilocks used by Ed448 in RFC 8032, x^2 + y^2 = 1 + d * x^2 * y^2 with d = -39081
+// over the field of order p = 2^448 - 2^224 - 1. The group has cofactor 4 and the prime
+// order subgroup has order l = 2^446 - 13818066809895115352007386748515426880336692474882178609894547503885
+package ed448
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+const (
+	// FieldBytes is the length of an encoded field element
+	FieldBytes = 56
+	// PointBytes is the length of an encoded point
+	PointBytes = 57
+	// ScalarBytes is the length of an encoded scalar, which matches the point encoding
+	ScalarBytes = 57
+	// WideScalarBytes is the length of the input of SetBytesWide
+	WideScalarBytes = 114
+	// UncompressedBytes is the length of the affine coordinates x and y
+	UncompressedBytes = 2 * FieldBytes
+)
+
+var (
+	pModulus, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
+	lModulus, _ = new(big.Int).SetString("3fffffffffffffffffffffffffffffffffffffffffffffffffffffff7cca23e9c44edb49aed63690216cc2728dc58f552378c292ab5844f3", 16)
+
+	fp = newField(pModulus)
+	fq = newField(lModulus)
+
+	// curveD is d = -39081
+	curveD = fpInt(-39081)
+	// montgomeryA is the coefficient A = 156326 of curve448
+	montgomeryA = fpInt(156326)
+	// sqrtExp is (p + 1) / 4
+	sqrtExp = fromBig(new(big.Int).Rsh(new(big.Int).Add(pModulus, big.NewInt(1)), 2))
+	// scalarSqrtExp is (l + 1) / 4, since l = 3 mod 4 as well
+	scalarSqrtExp = fromBig(new(big.Int).Rsh(new(big.Int).Add(lModulus, big.NewInt(1)), 2))
+	// order is l, the order of the prime order subgroup
+	order = fromBig(lModulus)
+
+	generator = func() *Point {
+		x, _ := new(big.Int).SetString("224580040295924300187604334099896036246789641632564134246125461686950415467406032909029192869357953282578032075146446173674602635247710", 10)
+		y, _ := new(big.Int).SetString("298819210078481492676017930443930673437544040154080242095928241372331506189835876003536878655418784733982303233503462500531545062832660", 10)
+		g, err := new(Point).SetBigInt(x, y)
+		if err != nil {
+			panic(err)
+		}
+		return g
+	}()
+)
+
+// fpInt returns a small integer as a field element
+func fpInt(v int64) element {
+	var out element
+	fp.toMont(&out, &element{uint64(abs(v))})
+	if v < 0 {
+		fp.neg(&out, &out)
+	}
+	return out
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Scalar is an integer modulo l
+type Scalar struct {
+	value element
+}
+
+// Zero sets s = 0
+func (s *Scalar) Zero() *Scalar {
+	s.value = element{}
+	return s
+}
+
+// One sets s = 1
+func (s *Scalar) One() *Scalar {
+	s.value = fq.r
+	return s
+}
+
+// SetUint64 sets s = v
+func (s *Scalar) SetUint64(v uint64) *Scalar {
+	fq.toMont(&s.value, &element{v})
+	return s
+}
+
+// Set sets s = a
+func (s *Scalar) Set(a *Scalar) *Scalar {
+	s.value = a.value
+	return s
+}
+
+// Random sets s to a uniformly random scalar read from `reader`
+func (s *Scalar) Random(reader io.Reader) (*Scalar, error) {
+	var buf [WideScalarBytes]byte
+	if _, err := io.ReadFull(reader, buf[:]); err != nil {
+		return nil, err
+	}
+	return s.SetBytesWide(buf[:])
+}
+
+// SetBytes sets s to the canonical 57-byte little-endian encoding `input`
+func (s *Scalar) SetBytes(input []byte) (*Scalar, error) {
+	if len(input) != ScalarBytes || input[ScalarBytes-1] != 0 {
+		return nil, fmt.Errorf("invalid scalar encoding")
+	}
+	var value element
+	if !fq.setBytes(&value, input[:ScalarBytes-1]) {
+		return nil, fmt.Errorf("scalar is not canonical")
+	}
+	s.value = value
+	return s, nil
+}
+
+// SetBytesWide sets s to the 114-byte little-endian integer `input` reduced modulo l,
+// as done with the SHAKE256 outputs in RFC 8032
+func (s *Scalar) SetBytesWide(input []byte) (*Scalar, error) {
+	if len(input) != WideScalarBytes {
+		return nil, fmt.Errorf("invalid length")
+	}
+	fq.setBytesWide(&s.value, input)
+	return s, nil
+}
+
+// Bytes returns the canonical 57-byte little-endian encoding
+func (s *Scalar) Bytes() [ScalarBytes]byte {
+	var out [ScalarBytes]byte
+	b := fq.bytes(&s.value)
+	copy(out[:], b[:])
+	return out
+}
+
+// SetBigInt sets s = v mod l
+func (s *Scalar) SetBigInt(v *big.Int) *Scalar {
+	n := fromBig(new(big.Int).Mod(v, lModulus))
+	fq.toMont(&s.value, &n)
+	return s
+}
+
+// BigInt returns the scalar as an integer
+func (s *Scalar) BigInt() *big.Int {
+	var n element
+	fq.fromMont(&n, &s.value)
+	return toBig(&n)
+}
+
+// Add sets s = a + b
+func (s *Scalar) Add(a, b *Scalar) *Scalar {
+	fq.add(&s.value, &a.value, &b.value)
+	return s
+}
+
+// Sub sets s = a - b
+func (s *Scalar) Sub(a, b *Scalar) *Scalar {
+	fq.sub(&s.value, &a.value, &b.value)
+	return s
+}
+
+// Mul sets s = a * b
+func (s *Scalar) Mul(a, b *Scalar) *Scalar {
+	fq.mul(&s.value, &a.value, &b.value)
+	return s
+}
+
+// Neg sets s = -a
+func (s *Scalar) Neg(a *Scalar) *Scalar {
+	fq.neg(&s.value, &a.value)
+	return s
+}
+
+// Invert sets s = a^-1 and reports whether a was invertible
+func (s *Scalar) Invert(a *Scalar) (*Scalar, bool) {
+	var v element
+	fq.invert(&v, &a.value)
+	s.value = v
+	return s, fq.isZero(&a.value) == 0
+}
+
+// Sqrt sets s to a square root of a and reports whether a is a square
+func (s *Scalar) Sqrt(a *Scalar) (*Scalar, bool) {
+	var r, check element
+	fq.exp(&r, &a.value, &scalarSqrtExp)
+	fq.square(&check, &r)
+	s.value = r
+	return s, fq.equal(&check, &a.value) == 1
+}
+
+// IsZero returns 1 if s = 0 and 0 otherwise
+func (s *Scalar) IsZero() int {
+	return fq.isZero(&s.value)
+}
+
+// Equal returns 1 if s = a and 0 otherwise
+func (s *Scalar) Equal(a *Scalar) int {
+	return fq.equal(&s.value, &a.value)
+}
+
+// Point is a point of edwards448 in projective coordinates
+type Point struct {
+	x, y, z element
+}
+
+// Identity sets p to the neutral element (0, 1)
+func (p *Point) Identity() *Point {
+	p.x = element{}
+	p.y = fp.r
+	p.z = fp.r
+	return p
+}
+
+// Generator sets p to the base point of RFC 8032
+func (p *Point) Generator() *Point {
+	*p = *generator
+	return p
+}
+
+// Set sets p = a
+func (p *Point) Set(a *Point) *Point {
+	*p = *a
+	return p
+}
+
+// Random sets p to a random point of the prime order subgroup
+func (p *Point) Random(reader io.Reader) (*Point, error) {
+	var seed [64]byte
+	if _, err := io.ReadFull(reader, seed[:]); err != nil {
+		return nil, err
+	}
+	return p.Hash(native.EllipticPointHasherShake256Rfc9380(), seed[:], []byte(SuiteId)), nil
+}
+
+// IsIdentity returns 1 if p is the neutral element and 0 otherwise
+func (p *Point) IsIdentity() int {
+	return fp.isZero(&p.x) & fp.equal(&p.y, &p.z)
+}
+
+// IsOnCurve returns 1 if p satisfies the curve equation and 0 otherwise
+func (p *Point) IsOnCurve() int {
+	// (X^2 + Y^2) * Z^2 = Z^4 + d * X^2 * Y^2
+	var xx, yy, zz, lhs, rhs, t element
+	fp.square(&xx, &p.x)
+	fp.square(&yy, &p.y)
+	fp.square(&zz, &p.z)
+	fp.add(&lhs, &xx, &yy)
+	fp.mul(&lhs, &lhs, &zz)
+	fp.square(&rhs, &zz)
+	fp.mul(&t, &xx, &yy)
+	fp.mul(&t, &t, &curveD)
+	fp.add(&rhs, &rhs, &t)
+	return fp.equal(&lhs, &rhs) & (1 - fp.isZero(&p.z))
+}
+
+// Add sets p = a + b with the complete formulas of RFC 8032 section 5.2.4
+func (p *Point) Add(a, b *Point) *Point {
+	var aa, bb, c, d, e, f, g, h, t element
+	fp.mul(&aa, &a.z, &b.z)
+	fp.square(&bb, &aa)
+	fp.mul(&c, &a.x, &b.x)
+	fp.mul(&d, &a.y, &b.y)
+	fp.mul(&e, &c, &d)
+	fp.mul(&e, &e, &curveD)
+	fp.sub(&f, &bb, &e)
+	fp.add(&g, &bb, &e)
+	fp.add(&h, &a.x, &a.y)
+	fp.add(&t, &b.x, &b.y)
+	fp.mul(&h, &h, &t)
+
+	var x, y, z element
+	// X3 = A * F * (H - C - D)
+	fp.sub(&t, &h, &c)
+	fp.sub(&t, &t, &d)
+	fp.mul(&x, &aa, &f)
+	fp.mul(&x, &x, &t)
+	// Y3 = A * G * (D - C)
+	fp.sub(&t, &d, &c)
+	fp.mul(&y, &aa, &g)
+	fp.mul(&y, &y, &t)
+	// Z3 = F * G
+	fp.mul(&z, &f, &g)
+	p.x, p.y, p.z = x, y, z
+	return p
+}
+
+// Sub sets p = a - b
+func (p *Point) Sub(a, b *Point) *Point {
+	var nb Point
+	return p.Add(a, nb.Neg(b))
+}
+
+// Double sets p = 2 * a
+func (p *Point) Double(a *Point) *Point {
+	var b, c, d, e, h, j, t element
+	fp.add(&b, &a.x, &a.y)
+	fp.square(&b, &b)
+	fp.square(&c, &a.x)
+	fp.square(&d, &a.y)
+	fp.add(&e, &c, &d)
+	fp.square(&h, &a.z)
+	fp.add(&t, &h, &h)
+	fp.sub(&j, &e, &t)
+
+	var x, y, z element
+	// X3 = (B - E) * J
+	fp.sub(&t, &b, &e)
+	fp.mul(&x, &t, &j)
+	// Y3 = E * (C - D)
+	fp.sub(&t, &c, &d)
+	fp.mul(&y, &e, &t)
+	// Z3 = E * J
+	fp.mul(&z, &e, &j)
+	p.x, p.y, p.z = x, y, z
+	return p
+}
+
+// Neg sets p = -a
+func (p *Point) Neg(a *Point) *Point {
+	fp.neg(&p.x, &a.x)
+	p.y = a.y
+	p.z = a.z
+	return p
+}
+
+// Mul sets p = s * a in constant time
+func (p *Point) Mul(a *Point, s *Scalar) *Point {
+	var n element
+	fq.fromMont(&n, &s.value)
+	return p.mulBits(a, &n)
+}
+
+// mulBits sets p = n * a for the integer n in constant time
+func (p *Point) mulBits(a *Point, n *element) *Point {
+	var r, t Point
+	r.Identity()
+	base := *a
+	for i := limbs*64 - 1; i >= 0; i-- {
+		r.Double(&r)
+		t.Add(&r, &base)
+		r.cmove(&r, &t, int((n[i/64]>>(i%64))&1))
+	}
+	*p = r
+	return p
+}
+
+// cmove sets p = b when choice is 1 and p = a when it is 0
+func (p *Point) cmove(a, b *Point, choice int) *Point {
+	cmove(&p.x, &a.x, &b.x, choice)
+	cmove(&p.y, &a.y, &b.y, choice)
+	cmove(&p.z, &a.z, &b.z, choice)
+	return p
+}
+
+// MulByCofactor sets p = 4 * a
+func (p *Point) MulByCofactor(a *Point) *Point {
+	return p.Double(p.Double(a))
+}
+
+// IsTorsionFree returns 1 if p is in the prime order subgroup and 0 otherwise
+func (p *Point) IsTorsionFree() int {
+	var t Point
+	return t.mulBits(p, &order).IsIdentity()
+}
+
+// Equal returns 1 if p = a and 0 otherwise
+func (p *Point) Equal(a *Point) int {
+	var l, r element
+	fp.mul(&l, &p.x, &a.z)
+	fp.mul(&r, &a.x, &p.z)
+	eq := fp.equal(&l, &r)
+	fp.mul(&l, &p.y, &a.z)
+	fp.mul(&r, &a.y, &p.z)
+	return eq & fp.equal(&l, &r)
+}
+
+// affine returns the affine coordinates of p
+func (p *Point) affine() (x, y element) {
+	var inv element
+	fp.invert(&inv, &p.z)
+	fp.mul(&x, &p.x, &inv)
+	fp.mul(&y, &p.y, &inv)
+	return x, y
+}
+
+// ToCompressed returns the RFC 8032 encoding of p: y in little-endian and the sign of x in the top bit
+func (p *Point) ToCompressed() [PointBytes]byte {
+	x, y := p.affine()
+	var out [PointBytes]byte
+	yb := fp.bytes(&y)
+	copy(out[:], yb[:])
+	out[PointBytes-1] = byte(fp.isOdd(&x) << 7)
+	return out
+}
+
+// FromCompressed sets p to the point with the RFC 8032 encoding `input`
+func (p *Point) FromCompressed(input *[PointBytes]byte) (*Point, error) {
+	if input[PointBytes-1]&0x7f != 0 {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	sign := int(input[PointBytes-1] >> 7)
+	var y element
+	if !fp.setBytes(&y, input[:FieldBytes]) {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	// x^2 = (y^2 - 1) / (d * y^2 - 1)
+	var u, v, yy element
+	one := fp.r
+	fp.square(&yy, &y)
+	fp.sub(&u, &yy, &one)
+	fp.mul(&v, &yy, &curveD)
+	fp.sub(&v, &v, &one)
+	x, ok := sqrtRatio(&u, &v)
+	if !ok {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+	if fp.isZero(&x) == 1 && sign == 1 {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	var nx element
+	fp.neg(&nx, &x)
+	cmove(&x, &x, &nx, fp.isOdd(&x)^sign)
+	p.x = x
+	p.y = y
+	p.z = one
+	return p, nil
+}
+
+// ToUncompressed returns the little-endian affine coordinates x || y
+func (p *Point) ToUncompressed() [UncompressedBytes]byte {
+	x, y := p.affine()
+	var out [UncompressedBytes]byte
+	xb := fp.bytes(&x)
+	yb := fp.bytes(&y)
+	copy(out[:FieldBytes], xb[:])
+	copy(out[FieldBytes:], yb[:])
+	return out
+}
+
+// FromUncompressed sets p to the point with the canonical little-endian affine coordinates x || y
+func (p *Point) FromUncompressed(input *[UncompressedBytes]byte) (*Point, error) {
+	var q Point
+	if !fp.setBytes(&q.x, input[:FieldBytes]) || !fp.setBytes(&q.y, input[FieldBytes:]) {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	q.z = fp.r
+	if q.IsOnCurve() == 0 {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+	*p = q
+	return p, nil
+}
+
+// sqrtRatio returns a square root of u / v and whether it exists
+func sqrtRatio(u, v *element) (element, bool) {
+	var w, r, check element
+	fp.invert(&w, v)
+	fp.mul(&w, &w, u)
+	fp.exp(&r, &w, &sqrtExp)
+	fp.square(&check, &r)
+	return r, fp.equal(&check, &w) == 1
+}
+
+// BigInt returns the affine coordinates of p
+func (p *Point) BigInt() (x, y *big.Int) {
+	ax, ay := p.affine()
+	var nx, ny element
+	fp.fromMont(&nx, &ax)
+	fp.fromMont(&ny, &ay)
+	return toBig(&nx), toBig(&ny)
+}
+
+// SetBigInt sets p to the point with affine coordinates (x, y)
+func (p *Point) SetBigInt(x, y *big.Int) (*Point, error) {
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(pModulus) >= 0 || y.Cmp(pModulus) >= 0 {
+		return nil, fmt.Errorf("invalid coordinates")
+	}
+	var q Point
+	xn, yn := fromBig(x), fromBig(y)
+	fp.toMont(&q.x, &xn)
+	fp.toMont(&q.y, &yn)
+	q.z = fp.r
+	if q.IsOnCurve() == 0 {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+	*p = q
+	return p, nil
+}
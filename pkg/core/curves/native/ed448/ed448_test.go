@@ -0,0 +1,198 @@
+package ed448
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+func randomElement(t *testing.T, f *field, modulus *big.Int) (element, *big.Int) {
+	v, err := crand.Int(crand.Reader, modulus)
+	require.NoError(t, err)
+	var e element
+	n := fromBig(v)
+	f.toMont(&e, &n)
+	return e, v
+}
+
+func elementBig(f *field, e *element) *big.Int {
+	var n element
+	f.fromMont(&n, e)
+	return toBig(&n)
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	for _, tc := range []struct {
+		f       *field
+		modulus *big.Int
+	}{{fp, pModulus}, {fq, lModulus}} {
+		for i := 0; i < 100; i++ {
+			a, ab := randomElement(t, tc.f, tc.modulus)
+			b, bb := randomElement(t, tc.f, tc.modulus)
+			var r element
+			tc.f.add(&r, &a, &b)
+			require.Equal(t, new(big.Int).Mod(new(big.Int).Add(ab, bb), tc.modulus), elementBig(tc.f, &r))
+			tc.f.sub(&r, &a, &b)
+			require.Equal(t, new(big.Int).Mod(new(big.Int).Sub(ab, bb), tc.modulus), elementBig(tc.f, &r))
+			tc.f.mul(&r, &a, &b)
+			require.Equal(t, new(big.Int).Mod(new(big.Int).Mul(ab, bb), tc.modulus), elementBig(tc.f, &r))
+			tc.f.neg(&r, &a)
+			require.Equal(t, new(big.Int).Mod(new(big.Int).Neg(ab), tc.modulus), elementBig(tc.f, &r))
+			tc.f.invert(&r, &a)
+			require.Equal(t, new(big.Int).ModInverse(ab, tc.modulus), elementBig(tc.f, &r))
+		}
+		// Extreme values
+		max := fromBig(new(big.Int).Sub(tc.modulus, big.NewInt(1)))
+		var m, r element
+		tc.f.toMont(&m, &max)
+		tc.f.mul(&r, &m, &m)
+		require.Equal(t, big.NewInt(1), elementBig(tc.f, &r))
+		tc.f.add(&r, &m, &m)
+		require.Equal(t, new(big.Int).Sub(tc.modulus, big.NewInt(2)), elementBig(tc.f, &r))
+
+		wide := make([]byte, WideScalarBytes)
+		_, _ = crand.Read(wide)
+		tc.f.setBytesWide(&r, wide)
+		expected := new(big.Int).SetBytes(reverse(wide))
+		require.Equal(t, expected.Mod(expected, tc.modulus), elementBig(tc.f, &r))
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	g := new(Point).Generator()
+	require.Equal(t, 1, g.IsOnCurve())
+	require.Equal(t, 1, g.IsTorsionFree())
+	require.Equal(t, 0, g.IsIdentity())
+
+	require.Equal(t, 1, new(Point).Mul(g, new(Scalar).Zero()).IsIdentity())
+	// (l - 1) * G = -G
+	minusOne := new(Scalar).Neg(new(Scalar).One())
+	require.Equal(t, 1, new(Point).Mul(g, minusOne).Equal(new(Point).Neg(g)))
+}
+
+func TestGroupLaws(t *testing.T) {
+	g := new(Point).Generator()
+	a, err := new(Scalar).Random(crand.Reader)
+	require.NoError(t, err)
+	b, err := new(Scalar).Random(crand.Reader)
+	require.NoError(t, err)
+	pa := new(Point).Mul(g, a)
+	pb := new(Point).Mul(g, b)
+	sum := new(Point).Add(pa, pb)
+	require.Equal(t, 1, sum.Equal(new(Point).Mul(g, new(Scalar).Add(a, b))))
+	require.Equal(t, 1, new(Point).Double(pa).Equal(new(Point).Add(pa, pa)))
+	require.Equal(t, 1, new(Point).Sub(sum, pb).Equal(pa))
+	require.Equal(t, 1, new(Point).Add(pa, new(Point).Identity()).Equal(pa))
+	require.Equal(t, 1, new(Point).Mul(pa, b).Equal(new(Point).Mul(g, new(Scalar).Mul(a, b))))
+	inv, ok := new(Scalar).Invert(a)
+	require.True(t, ok)
+	require.Equal(t, 1, new(Scalar).Mul(a, inv).Equal(new(Scalar).One()))
+	_, ok = new(Scalar).Invert(new(Scalar).Zero())
+	require.False(t, ok)
+}
+
+func TestEncoding(t *testing.T) {
+	g := new(Point).Generator()
+	for i := 0; i < 20; i++ {
+		s, err := new(Scalar).Random(crand.Reader)
+		require.NoError(t, err)
+		p := new(Point).Mul(g, s)
+		enc := p.ToCompressed()
+		q, err := new(Point).FromCompressed(&enc)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.Equal(p))
+
+		unc := p.ToUncompressed()
+		q, err = new(Point).FromUncompressed(&unc)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.Equal(p))
+		unc[0] ^= 1
+		_, err = new(Point).FromUncompressed(&unc)
+		require.Error(t, err)
+
+		sq := new(Scalar).Mul(s, s)
+		root, ok := new(Scalar).Sqrt(sq)
+		require.True(t, ok)
+		require.Equal(t, 1, new(Scalar).Mul(root, root).Equal(sq))
+
+		sb := s.Bytes()
+		s2, err := new(Scalar).SetBytes(sb[:])
+		require.NoError(t, err)
+		require.Equal(t, 1, s2.Equal(s))
+		require.Equal(t, 0, s.BigInt().Cmp(new(Scalar).SetBigInt(s.BigInt()).BigInt()))
+	}
+	identity := new(Point).Identity().ToCompressed()
+	require.Equal(t, byte(1), identity[0])
+
+	// Non-canonical y, bad top byte, and non-canonical scalars are rejected
+	var bad [PointBytes]byte
+	for i := range bad[:FieldBytes] {
+		bad[i] = 0xff
+	}
+	_, err := new(Point).FromCompressed(&bad)
+	require.Error(t, err)
+	enc := g.ToCompressed()
+	enc[PointBytes-1] |= 1
+	_, err = new(Point).FromCompressed(&enc)
+	require.Error(t, err)
+	lb := make([]byte, ScalarBytes)
+	copy(lb, reverse(lModulus.Bytes()))
+	_, err = new(Scalar).SetBytes(lb)
+	require.Error(t, err)
+}
+
+func TestTorsion(t *testing.T) {
+	// (0, -1) has order 2
+	var twoTorsion Point
+	twoTorsion.Identity()
+	fp.neg(&twoTorsion.y, &twoTorsion.y)
+	require.Equal(t, 1, twoTorsion.IsOnCurve())
+	require.Equal(t, 0, twoTorsion.IsTorsionFree())
+	require.Equal(t, 1, new(Point).Double(&twoTorsion).IsIdentity())
+
+	mixed := new(Point).Add(new(Point).Generator(), &twoTorsion)
+	require.Equal(t, 0, mixed.IsTorsionFree())
+	require.Equal(t, 1, new(Point).MulByCofactor(mixed).IsTorsionFree())
+}
+
+func TestHash(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-" + SuiteId)
+	a := new(Point).Hash(native.EllipticPointHasherShake256Rfc9380(), []byte("abc"), dst)
+	b := new(Point).Hash(native.EllipticPointHasherShake256Rfc9380(), []byte("abc"), dst)
+	c := new(Point).Hash(native.EllipticPointHasherShake256Rfc9380(), []byte("abd"), dst)
+	require.Equal(t, 1, a.IsOnCurve())
+	require.Equal(t, 1, a.IsTorsionFree())
+	require.Equal(t, 1, a.Equal(b))
+	require.Equal(t, 0, a.Equal(c))
+
+	// Every output of the map lies on the curve
+	for i := 0; i < 20; i++ {
+		u, _ := randomElement(t, fp, pModulus)
+		require.Equal(t, 1, new(Point).mapToCurve(&u).IsOnCurve())
+	}
+	var zero element
+	require.Equal(t, 1, new(Point).mapToCurve(&zero).IsOnCurve())
+}
+
+// The public key of the "Blank" test vector of RFC 8032 section 7.4
+func TestRfc8032PublicKey(t *testing.T) {
+	secret, _ := hex.DecodeString("6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b")
+	public, _ := hex.DecodeString("5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180")
+	h := make([]byte, WideScalarBytes)
+	sha3.ShakeSum256(h, secret)
+	h[0] &= 0xfc
+	h[55] |= 0x80
+	h[56] = 0
+	wide := make([]byte, WideScalarBytes)
+	copy(wide, h[:ScalarBytes])
+	s, err := new(Scalar).SetBytesWide(wide)
+	require.NoError(t, err)
+	pk := new(Point).Mul(new(Point).Generator(), s).ToCompressed()
+	require.Equal(t, public, pk[:])
+}
@@ -0,0 +1,280 @@
+package ed448
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// limbs is the number of 64-bit words in a field element
+const limbs = 7
+
+// element is an integer modulo the modulus of a field in Montgomery form with R = 2^448,
+// reduced to [0, modulus)
+type element [limbs]uint64
+
+// field holds the constants of the Montgomery arithmetic modulo an odd 446 to 448 bit modulus
+type field struct {
+	modulus element
+	// mPrime is -modulus^-1 mod 2^64
+	mPrime uint64
+	// r is R mod modulus, the Montgomery form of 1
+	r element
+	// r2, r3 and r4 are R^2, R^3 and R^4 mod modulus, used to convert into Montgomery form
+	r2, r3, r4 element
+	// bitLen is the bit length of the modulus
+	bitLen int
+	// minusTwo is modulus - 2, the exponent of inversion
+	minusTwo element
+}
+
+func newField(modulus *big.Int) *field {
+	f := &field{bitLen: modulus.BitLen()}
+	f.modulus = fromBig(modulus)
+	// Newton iteration for the inverse of the lowest word modulo 2^64
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - f.modulus[0]*inv
+	}
+	f.mPrime = -inv
+	r := new(big.Int).Lsh(big.NewInt(1), 64*limbs)
+	f.r = fromBig(new(big.Int).Mod(r, modulus))
+	r2 := new(big.Int).Mul(r, r)
+	f.r2 = fromBig(r2.Mod(r2, modulus))
+	r3 := new(big.Int).Mul(r2, r)
+	f.r3 = fromBig(r3.Mod(r3, modulus))
+	r4 := new(big.Int).Mul(r3, r)
+	f.r4 = fromBig(r4.Mod(r4, modulus))
+	f.minusTwo = fromBig(new(big.Int).Sub(modulus, big.NewInt(2)))
+	return f
+}
+
+// fromBig returns the little-endian words of a non-negative integer below 2^448
+func fromBig(v *big.Int) element {
+	var buf [limbs * 8]byte
+	v.FillBytes(buf[:])
+	var out element
+	for i := 0; i < limbs; i++ {
+		out[i] = binary.BigEndian.Uint64(buf[len(buf)-8*(i+1):])
+	}
+	return out
+}
+
+// toBig returns the integer with the little-endian words of `a`
+func toBig(a *element) *big.Int {
+	var buf [limbs * 8]byte
+	for i := 0; i < limbs; i++ {
+		binary.BigEndian.PutUint64(buf[len(buf)-8*(i+1):], a[i])
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// mul sets out = a * b / R mod modulus with the CIOS method
+func (f *field) mul(out, a, b *element) {
+	var t [limbs + 2]uint64
+	for i := 0; i < limbs; i++ {
+		var c uint64
+		for j := 0; j < limbs; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			var carry uint64
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j] = lo
+			c = hi
+		}
+		var carry uint64
+		t[limbs], carry = bits.Add64(t[limbs], c, 0)
+		t[limbs+1] = carry
+
+		m := t[0] * f.mPrime
+		hi, lo := bits.Mul64(m, f.modulus[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < limbs; j++ {
+			hi, lo = bits.Mul64(m, f.modulus[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1] = lo
+			c = hi
+		}
+		t[limbs-1], carry = bits.Add64(t[limbs], c, 0)
+		t[limbs] = t[limbs+1] + carry
+	}
+	var r element
+	copy(r[:], t[:limbs])
+	f.reduceOnce(out, &r, t[limbs])
+}
+
+// reduceOnce sets out = a + carry * 2^448 - modulus if that is non-negative and out = a otherwise.
+// The inputs must be less than twice the modulus
+func (f *field) reduceOnce(out, a *element, carry uint64) {
+	var d element
+	var borrow uint64
+	for i := 0; i < limbs; i++ {
+		d[i], borrow = bits.Sub64(a[i], f.modulus[i], borrow)
+	}
+	// Keep a when the subtraction borrowed without a carry to absorb it
+	_, keep := bits.Sub64(carry, 0, borrow)
+	mask := -keep
+	for i := 0; i < limbs; i++ {
+		out[i] = (a[i] & mask) | (d[i] &^ mask)
+	}
+}
+
+func (f *field) add(out, a, b *element) {
+	var s element
+	var carry uint64
+	for i := 0; i < limbs; i++ {
+		s[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	f.reduceOnce(out, &s, carry)
+}
+
+func (f *field) sub(out, a, b *element) {
+	var d element
+	var borrow uint64
+	for i := 0; i < limbs; i++ {
+		d[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	mask := -borrow
+	var carry uint64
+	for i := 0; i < limbs; i++ {
+		d[i], carry = bits.Add64(d[i], f.modulus[i]&mask, carry)
+	}
+	*out = d
+}
+
+func (f *field) neg(out, a *element) {
+	var zero element
+	f.sub(out, &zero, a)
+}
+
+func (f *field) square(out, a *element) {
+	f.mul(out, a, a)
+}
+
+// exp sets out = a^e for a public exponent `e`
+func (f *field) exp(out, a, e *element) {
+	res := f.r
+	base := *a
+	for i := limbs*64 - 1; i >= 0; i-- {
+		f.square(&res, &res)
+		if (e[i/64]>>(i%64))&1 == 1 {
+			f.mul(&res, &res, &base)
+		}
+	}
+	*out = res
+}
+
+// invert sets out = a^-1, or zero when a is zero
+func (f *field) invert(out, a *element) {
+	f.exp(out, a, &f.minusTwo)
+}
+
+// toMont converts an integer below 2^448 into Montgomery form
+func (f *field) toMont(out, a *element) {
+	var r element
+	f.reduceOnce(&r, a, 0)
+	// Integers up to 2^448 can be four times a 446-bit modulus
+	for i := 0; i < 3; i++ {
+		f.reduceOnce(&r, &r, 0)
+	}
+	f.mul(out, &r, &f.r2)
+}
+
+// fromMont converts out of Montgomery form
+func (f *field) fromMont(out, a *element) {
+	one := element{1}
+	f.mul(out, a, &one)
+}
+
+// setBytes sets out to the little-endian integer `input` of at most 56 bytes, which
+// must be canonical, and reports whether it was
+func (f *field) setBytes(out *element, input []byte) bool {
+	var buf [limbs * 8]byte
+	copy(buf[:], input)
+	var a element
+	for i := 0; i < limbs; i++ {
+		a[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	var borrow uint64
+	for i := 0; i < limbs; i++ {
+		_, borrow = bits.Sub64(a[i], f.modulus[i], borrow)
+	}
+	f.toMont(out, &a)
+	return borrow == 1
+}
+
+// setBytesWide sets out to the little-endian integer `input` of at most 168 bytes reduced modulo the modulus
+func (f *field) setBytesWide(out *element, input []byte) {
+	var buf [3 * limbs * 8]byte
+	copy(buf[:], input)
+	var chunks [3]element
+	for c := 0; c < 3; c++ {
+		for i := 0; i < limbs; i++ {
+			chunks[c][i] = binary.LittleEndian.Uint64(buf[c*limbs*8+8*i:])
+		}
+	}
+	// c0 + c1 * R + c2 * R^2 in Montgomery form is c0 * R + c1 * R^2 + c2 * R^3
+	var a, b element
+	f.toMont(&a, &chunks[0])
+	var r element
+	f.reduceOnce(&r, &chunks[1], 0)
+	for i := 0; i < 3; i++ {
+		f.reduceOnce(&r, &r, 0)
+	}
+	f.mul(&b, &r, &f.r3)
+	f.add(&a, &a, &b)
+	f.reduceOnce(&r, &chunks[2], 0)
+	for i := 0; i < 3; i++ {
+		f.reduceOnce(&r, &r, 0)
+	}
+	f.mul(&b, &r, &f.r4)
+	f.add(out, &a, &b)
+}
+
+// bytes returns the canonical 56-byte little-endian encoding
+func (f *field) bytes(a *element) [limbs * 8]byte {
+	var n element
+	f.fromMont(&n, a)
+	var out [limbs * 8]byte
+	for i := 0; i < limbs; i++ {
+		binary.LittleEndian.PutUint64(out[8*i:], n[i])
+	}
+	return out
+}
+
+func (f *field) isZero(a *element) int {
+	var acc uint64
+	for i := 0; i < limbs; i++ {
+		acc |= a[i]
+	}
+	return int(1 - ((acc | -acc) >> 63))
+}
+
+func (f *field) equal(a, b *element) int {
+	var acc uint64
+	for i := 0; i < limbs; i++ {
+		acc |= a[i] ^ b[i]
+	}
+	return int(1 - ((acc | -acc) >> 63))
+}
+
+// isOdd reports whether the canonical integer is odd, i.e. sgn0 of RFC 9380
+func (f *field) isOdd(a *element) int {
+	var n element
+	f.fromMont(&n, a)
+	return int(n[0] & 1)
+}
+
+// cmove sets out = b when choice is 1 and out = a when it is 0
+func cmove(out, a, b *element, choice int) {
+	mask := -uint64(choice)
+	for i := 0; i < limbs; i++ {
+		out[i] = a[i] ^ ((a[i] ^ b[i]) & mask)
+	}
+}
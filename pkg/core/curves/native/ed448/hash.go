@@ -0,0 +1,132 @@
+package ed448
+
+import (
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+// SuiteId is the RFC 9380 suite implemented by Hash
+const SuiteId = "edwards448_XOF:SHAKE256_ELL2_RO_"
+
+// hashFieldBytes is L = ceil((ceil(log2(p)) + k) / 8) for k = 224
+const hashFieldBytes = 84
+
+// Hash sets p to the hash of `msg` with the domain separation tag `dst` following
+// edwards448_XOF:SHAKE256_ELL2_RO_ of RFC 9380. `hasher` chooses the expand_message function
+func (p *Point) Hash(hasher *native.EllipticPointHasher, msg, dst []byte) *Point {
+	var u []byte
+	switch hasher.Type() {
+	case native.XMD:
+		u = native.ExpandMsgXmd(hasher, msg, dst, 2*hashFieldBytes)
+	case native.XOF:
+		u = native.ExpandMsgXof(hasher, msg, dst, 2*hashFieldBytes)
+	}
+	var u0, u1 element
+	fp.setBytesWide(&u0, reverse(u[:hashFieldBytes]))
+	fp.setBytesWide(&u1, reverse(u[hashFieldBytes:]))
+	var q0, q1 Point
+	q0.mapToCurve(&u0)
+	q1.mapToCurve(&u1)
+	p.Add(&q0, &q1)
+	return p.MulByCofactor(p)
+}
+
+// reverse returns the bytes in the opposite order
+func reverse(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i, b := range in {
+		out[len(in)-1-i] = b
+	}
+	return out
+}
+
+// mapToCurve applies the Elligator 2 map to curve448 of RFC 9380 section 6.7.1 with Z = -1
+// followed by the 4-isogeny to edwards448 of RFC 7748 section 4.2
+func (p *Point) mapToCurve(u *element) *Point {
+	one := fp.r
+	var negA, tv, x1, x2, gx1, gx2 element
+	fp.neg(&negA, &montgomeryA)
+
+	// x1 = -A / (1 - u^2), or -A if the denominator is zero
+	fp.square(&tv, u)
+	fp.sub(&tv, &one, &tv)
+	fp.invert(&x1, &tv)
+	fp.mul(&x1, &x1, &negA)
+	cmove(&x1, &x1, &negA, fp.isZero(&x1))
+	montgomeryRhs(&gx1, &x1)
+	// x2 = -x1 - A
+	fp.sub(&x2, &negA, &x1)
+	montgomeryRhs(&gx2, &x2)
+
+	// (x1, y1) with sgn0(y1) = 1 when gx1 is square, otherwise (x2, y2) with sgn0(y2) = 0
+	var y1, y2, ny, check element
+	fp.exp(&y1, &gx1, &sqrtExp)
+	fp.square(&check, &y1)
+	isSquare := fp.equal(&check, &gx1)
+	fp.neg(&ny, &y1)
+	cmove(&y1, &y1, &ny, 1-fp.isOdd(&y1))
+	fp.exp(&y2, &gx2, &sqrtExp)
+	fp.neg(&ny, &y2)
+	cmove(&y2, &y2, &ny, fp.isOdd(&y2))
+	var s, t element
+	cmove(&s, &x2, &x1, isSquare)
+	cmove(&t, &y2, &y1, isSquare)
+
+	// x = 4 * t * (s^2 - 1) / (s^4 - 2 * s^2 + 4 * t^2 + 1)
+	// y = -(s^5 - 2 * s^3 - 4 * s * t^2 + s) / (s^5 - 2 * s^2 * t^2 - 2 * s^3 - 2 * t^2 + s)
+	var ss, tt, s3, s4, s5, a, b, xn, xd, yn, yd element
+	fp.square(&ss, &s)
+	fp.square(&tt, &t)
+	fp.mul(&s3, &ss, &s)
+	fp.square(&s4, &ss)
+	fp.mul(&s5, &s4, &s)
+
+	fp.sub(&xn, &ss, &one)
+	fp.mul(&xn, &xn, &t)
+	fp.add(&xn, &xn, &xn)
+	fp.add(&xn, &xn, &xn)
+
+	fp.add(&a, &ss, &ss)
+	fp.sub(&xd, &s4, &a)
+	fp.add(&b, &tt, &tt)
+	fp.add(&b, &b, &b)
+	fp.add(&xd, &xd, &b)
+	fp.add(&xd, &xd, &one)
+
+	fp.add(&a, &s3, &s3)
+	fp.sub(&yn, &s5, &a)
+	fp.mul(&b, &s, &tt)
+	fp.add(&b, &b, &b)
+	fp.add(&b, &b, &b)
+	fp.sub(&yn, &yn, &b)
+	fp.add(&yn, &yn, &s)
+	fp.neg(&yn, &yn)
+
+	fp.mul(&b, &ss, &tt)
+	fp.add(&b, &b, &b)
+	fp.sub(&yd, &s5, &b)
+	fp.add(&a, &s3, &s3)
+	fp.sub(&yd, &yd, &a)
+	fp.add(&b, &tt, &tt)
+	fp.sub(&yd, &yd, &b)
+	fp.add(&yd, &yd, &s)
+
+	// The map sends the points where a denominator vanishes to the identity
+	var den element
+	fp.mul(&den, &xd, &yd)
+	exceptional := fp.isZero(&den)
+	fp.mul(&p.x, &xn, &yd)
+	fp.mul(&p.y, &yn, &xd)
+	p.z = den
+	var id Point
+	id.Identity()
+	return p.cmove(p, &id, exceptional)
+}
+
+// montgomeryRhs sets out = x^3 + A * x^2 + x
+func montgomeryRhs(out, x *element) {
+	var t element
+	fp.add(&t, x, &montgomeryA)
+	fp.mul(&t, &t, x)
+	fp.add(&t, &t, &fp.r)
+	fp.mul(out, &t, x)
+}
@@ -2,8 +2,6 @@ package native
 
 import (
 	"hash"
-
-	"golang.org/x/crypto/sha3"
 )
 
 // OversizeDstSalt is the salt used to hash a dst over MaxDstLen
@@ -25,15 +23,20 @@ func getDomainXmd(h hash.Hash, domain []byte) []byte {
 	return out
 }
 
-func getDomainXof(h sha3.ShakeHash, domain []byte) []byte {
+// getDomainXof reduces an oversize domain to ceil(2 * k / 8) bytes,
+// where k is the security level of the xof, see RFC 9380 section 5.3.3
+func getDomainXof(h *EllipticPointHasher, domain []byte) []byte {
 	var out []byte
 	if len(domain) > MaxDstLen {
-		h.Reset()
-		_, _ = h.Write(OversizeDstSalt)
-		_, _ = h.Write(domain)
-		var tv [64]byte
-		_, _ = h.Read(tv[:])
-		out = tv[:]
+		h.xof.Reset()
+		_, _ = h.xof.Write(OversizeDstSalt)
+		_, _ = h.xof.Write(domain)
+		tv := make([]byte, 64)
+		if h.name == SHAKE128 {
+			tv = tv[:32]
+		}
+		_, _ = h.xof.Read(tv)
+		out = tv
 	} else {
 		out = domain
 	}
@@ -42,7 +45,7 @@ func getDomainXof(h sha3.ShakeHash, domain []byte) []byte {
 
 // ExpandMsgXmd expands the msg with the domain to output a byte array
 // with outLen in size using a fixed size hash.
-// See https://datatracker.ietf.org/doc/html/draft-irtf-cfrg-hash-to-curve-13#section-5.4.1
+// See https://www.rfc-editor.org/rfc/rfc9380#section-5.3.1
 func ExpandMsgXmd(h *EllipticPointHasher, msg, domain []byte, outLen int) []byte {
 	domain = getDomainXmd(h.xmd, domain)
 	domainLen := byte(len(domain))
@@ -92,9 +95,9 @@ func ExpandMsgXmd(h *EllipticPointHasher, msg, domain []byte, outLen int) []byte
 
 // ExpandMsgXof expands the msg with the domain to output a byte array
 // with outLen in size using a xof hash
-// See https://datatracker.ietf.org/doc/html/draft-irtf-cfrg-hash-to-curve-13#section-5.4.2
+// See https://www.rfc-editor.org/rfc/rfc9380#section-5.3.2
 func ExpandMsgXof(h *EllipticPointHasher, msg, domain []byte, outLen int) []byte {
-	domain = getDomainXof(h.xof, domain)
+	domain = getDomainXof(h, domain)
 	domainLen := byte(len(domain))
 	h.xof.Reset()
 	_, _ = h.xof.Write(msg)
@@ -0,0 +1,92 @@
+// Package mont implements the Montgomery multiplication of the native field
+// packages with the coarsely integrated operand scanning method (CIOS) of
+// Koç, Acar and Kaliski, "Analyzing and Comparing Montgomery Multiplication Algorithms".
+//
+// On amd64 the multiplication is written in assembly. The pure Go version is used on
+// the other architectures and when building with the purego tag. There is deliberately
+// no arm64 assembly: math/bits.Mul64 and Add64 are compiler intrinsics there, so the
+// pure Go loops compile to MUL, UMULH, ADDS and ADC with mulAdd inlined, which is the
+// code an assembly version would contain. HasAsm reports which version is used, so
+// that callers can keep their own pure Go code in the latter case.
+package mont
+
+import "math/bits"
+
+// mul4Generic is the pure Go version of Mul4
+func mul4Generic(out, a, b, modulus *[4]uint64, inv uint64) {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		var c uint64
+		for j := 0; j < 4; j++ {
+			t[j], c = mulAdd(t[j], a[j], b[i], c)
+		}
+		t[4], c = bits.Add64(t[4], c, 0)
+		t[5] = c
+
+		m := t[0] * inv
+		_, c = mulAdd(t[0], m, modulus[0], 0)
+		for j := 1; j < 4; j++ {
+			t[j-1], c = mulAdd(t[j], m, modulus[j], c)
+		}
+		t[3], c = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + c
+	}
+	reduce4(out, &t, modulus)
+}
+
+// mul6Generic is the pure Go version of Mul6
+func mul6Generic(out, a, b, modulus *[6]uint64, inv uint64) {
+	var t [8]uint64
+	for i := 0; i < 6; i++ {
+		var c uint64
+		for j := 0; j < 6; j++ {
+			t[j], c = mulAdd(t[j], a[j], b[i], c)
+		}
+		t[6], c = bits.Add64(t[6], c, 0)
+		t[7] = c
+
+		m := t[0] * inv
+		_, c = mulAdd(t[0], m, modulus[0], 0)
+		for j := 1; j < 6; j++ {
+			t[j-1], c = mulAdd(t[j], m, modulus[j], c)
+		}
+		t[5], c = bits.Add64(t[6], c, 0)
+		t[6] = t[7] + c
+	}
+	var d [6]uint64
+	var borrow uint64
+	for j := 0; j < 6; j++ {
+		d[j], borrow = bits.Sub64(t[j], modulus[j], borrow)
+	}
+	_, borrow = bits.Sub64(t[6], 0, borrow)
+	// Keep t when it is less than the modulus
+	mask := -borrow
+	for j := 0; j < 6; j++ {
+		out[j] = d[j] ^ ((d[j] ^ t[j]) & mask)
+	}
+}
+
+// reduce4 sets out to t - modulus if that is not negative and to t otherwise
+func reduce4(out *[4]uint64, t *[6]uint64, modulus *[4]uint64) {
+	var d [4]uint64
+	var borrow uint64
+	for j := 0; j < 4; j++ {
+		d[j], borrow = bits.Sub64(t[j], modulus[j], borrow)
+	}
+	_, borrow = bits.Sub64(t[4], 0, borrow)
+	mask := -borrow
+	for j := 0; j < 4; j++ {
+		out[j] = d[j] ^ ((d[j] ^ t[j]) & mask)
+	}
+}
+
+// mulAdd returns the low and high words of t + x * y + c
+func mulAdd(t, x, y, c uint64) (uint64, uint64) {
+	hi, lo := bits.Mul64(x, y)
+	var carry uint64
+	lo, carry = bits.Add64(lo, t, 0)
+	hi += carry
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	return lo, hi
+}
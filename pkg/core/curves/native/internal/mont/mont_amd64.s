@@ -0,0 +1,271 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+#include "textflag.h"
+
+// The accumulator t lives in a ring of registers. Each reduction step frees its lowest
+// word, which becomes the highest word of the next step, so the macros below are
+// called with the registers rotated by one.
+//
+// DI = a, SI = b, CX = modulus, BX = b[i] or m, R14 = carry word, AX and DX for MULQ
+
+// MULADD4 sets (T5, T4, T3, T2, T1, T0) += a * b[off/8], T5 is zero on entry
+#define MULADD4(off, T0, T1, T2, T3, T4, T5) \
+	MOVQ off(SI), BX; \
+	MOVQ 0(DI), AX; \
+	MULQ BX; \
+	ADDQ AX, T0; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 8(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T1; \
+	ADCQ $0, DX; \
+	ADDQ AX, T1; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 16(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T2; \
+	ADCQ $0, DX; \
+	ADDQ AX, T2; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 24(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T3; \
+	ADCQ $0, DX; \
+	ADDQ AX, T3; \
+	ADCQ $0, DX; \
+	ADDQ DX, T4; \
+	ADCQ $0, T5
+
+// REDUCE4 adds m * modulus to (T5, ..., T0) so that T0 becomes zero and the
+// result is (T5, ..., T1)
+#define REDUCE4(T0, T1, T2, T3, T4, T5) \
+	MOVQ T0, BX; \
+	IMULQ inv+32(FP), BX; \
+	MOVQ 0(CX), AX; \
+	MULQ BX; \
+	ADDQ AX, T0; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 8(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T1; \
+	ADCQ $0, DX; \
+	ADDQ AX, T1; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 16(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T2; \
+	ADCQ $0, DX; \
+	ADDQ AX, T2; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 24(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T3; \
+	ADCQ $0, DX; \
+	ADDQ AX, T3; \
+	ADCQ $0, DX; \
+	ADDQ DX, T4; \
+	ADCQ $0, T5; \
+	XORQ T0, T0
+
+// func Mul4(out, a, b, modulus *[4]uint64, inv uint64)
+TEXT ·Mul4(SB), NOSPLIT, $0-40
+	MOVQ a+8(FP), DI
+	MOVQ b+16(FP), SI
+	MOVQ modulus+24(FP), CX
+	XORQ R8, R8
+	XORQ R9, R9
+	XORQ R10, R10
+	XORQ R11, R11
+	XORQ R12, R12
+	XORQ R13, R13
+
+	MULADD4(0, R8, R9, R10, R11, R12, R13)
+	REDUCE4(R8, R9, R10, R11, R12, R13)
+	MULADD4(8, R9, R10, R11, R12, R13, R8)
+	REDUCE4(R9, R10, R11, R12, R13, R8)
+	MULADD4(16, R10, R11, R12, R13, R8, R9)
+	REDUCE4(R10, R11, R12, R13, R8, R9)
+	MULADD4(24, R11, R12, R13, R8, R9, R10)
+	REDUCE4(R11, R12, R13, R8, R9, R10)
+
+	// t = (R10, R9, R8, R13, R12) < 2 * modulus, subtract the modulus unless it borrows
+	MOVQ R12, AX
+	MOVQ R13, BX
+	MOVQ R8, DX
+	MOVQ R9, R14
+	SUBQ 0(CX), AX
+	SBBQ 8(CX), BX
+	SBBQ 16(CX), DX
+	SBBQ 24(CX), R14
+	SBBQ $0, R10
+	CMOVQCC AX, R12
+	CMOVQCC BX, R13
+	CMOVQCC DX, R8
+	CMOVQCC R14, R9
+
+	MOVQ out+0(FP), DI
+	MOVQ R12, 0(DI)
+	MOVQ R13, 8(DI)
+	MOVQ R8, 16(DI)
+	MOVQ R9, 24(DI)
+	RET
+
+// The 6-limb version needs eight accumulator registers, so it reloads the pointer to b
+// into BX on every step and uses SI as the eighth.
+
+// MULADD6 sets (T7, ..., T0) += a * b[off/8], T7 is zero on entry
+#define MULADD6(off, T0, T1, T2, T3, T4, T5, T6, T7) \
+	MOVQ b+16(FP), BX; \
+	MOVQ off(BX), BX; \
+	MOVQ 0(DI), AX; \
+	MULQ BX; \
+	ADDQ AX, T0; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 8(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T1; \
+	ADCQ $0, DX; \
+	ADDQ AX, T1; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 16(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T2; \
+	ADCQ $0, DX; \
+	ADDQ AX, T2; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 24(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T3; \
+	ADCQ $0, DX; \
+	ADDQ AX, T3; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 32(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T4; \
+	ADCQ $0, DX; \
+	ADDQ AX, T4; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 40(DI), AX; \
+	MULQ BX; \
+	ADDQ R14, T5; \
+	ADCQ $0, DX; \
+	ADDQ AX, T5; \
+	ADCQ $0, DX; \
+	ADDQ DX, T6; \
+	ADCQ $0, T7
+
+// REDUCE6 adds m * modulus to (T7, ..., T0) so that T0 becomes zero and the
+// result is (T7, ..., T1)
+#define REDUCE6(T0, T1, T2, T3, T4, T5, T6, T7) \
+	MOVQ T0, BX; \
+	IMULQ inv+32(FP), BX; \
+	MOVQ 0(CX), AX; \
+	MULQ BX; \
+	ADDQ AX, T0; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 8(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T1; \
+	ADCQ $0, DX; \
+	ADDQ AX, T1; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 16(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T2; \
+	ADCQ $0, DX; \
+	ADDQ AX, T2; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 24(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T3; \
+	ADCQ $0, DX; \
+	ADDQ AX, T3; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 32(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T4; \
+	ADCQ $0, DX; \
+	ADDQ AX, T4; \
+	ADCQ $0, DX; \
+	MOVQ DX, R14; \
+	MOVQ 40(CX), AX; \
+	MULQ BX; \
+	ADDQ R14, T5; \
+	ADCQ $0, DX; \
+	ADDQ AX, T5; \
+	ADCQ $0, DX; \
+	ADDQ DX, T6; \
+	ADCQ $0, T7; \
+	XORQ T0, T0
+
+// func Mul6(out, a, b, modulus *[6]uint64, inv uint64)
+TEXT ·Mul6(SB), NOSPLIT, $0-40
+	MOVQ a+8(FP), DI
+	MOVQ modulus+24(FP), CX
+	XORQ R8, R8
+	XORQ R9, R9
+	XORQ R10, R10
+	XORQ R11, R11
+	XORQ R12, R12
+	XORQ R13, R13
+	XORQ R15, R15
+	XORQ SI, SI
+
+	MULADD6(0, R8, R9, R10, R11, R12, R13, R15, SI)
+	REDUCE6(R8, R9, R10, R11, R12, R13, R15, SI)
+	MULADD6(8, R9, R10, R11, R12, R13, R15, SI, R8)
+	REDUCE6(R9, R10, R11, R12, R13, R15, SI, R8)
+	MULADD6(16, R10, R11, R12, R13, R15, SI, R8, R9)
+	REDUCE6(R10, R11, R12, R13, R15, SI, R8, R9)
+	MULADD6(24, R11, R12, R13, R15, SI, R8, R9, R10)
+	REDUCE6(R11, R12, R13, R15, SI, R8, R9, R10)
+	MULADD6(32, R12, R13, R15, SI, R8, R9, R10, R11)
+	REDUCE6(R12, R13, R15, SI, R8, R9, R10, R11)
+	MULADD6(40, R13, R15, SI, R8, R9, R10, R11, R12)
+	REDUCE6(R13, R15, SI, R8, R9, R10, R11, R12)
+
+	// t = (R12, R11, R10, R9, R8, SI, R15) < 2 * modulus, subtract the modulus unless it borrows
+	MOVQ R15, AX
+	MOVQ SI, BX
+	MOVQ R8, DX
+	MOVQ R9, R14
+	MOVQ R10, DI
+	MOVQ R11, R13
+	SUBQ 0(CX), AX
+	SBBQ 8(CX), BX
+	SBBQ 16(CX), DX
+	SBBQ 24(CX), R14
+	SBBQ 32(CX), DI
+	SBBQ 40(CX), R13
+	SBBQ $0, R12
+	CMOVQCC AX, R15
+	CMOVQCC BX, SI
+	CMOVQCC DX, R8
+	CMOVQCC R14, R9
+	CMOVQCC DI, R10
+	CMOVQCC R13, R11
+
+	MOVQ out+0(FP), DI
+	MOVQ R15, 0(DI)
+	MOVQ SI, 8(DI)
+	MOVQ R8, 16(DI)
+	MOVQ R9, 24(DI)
+	MOVQ R10, 32(DI)
+	MOVQ R11, 40(DI)
+	RET
@@ -0,0 +1,19 @@
+//go:build !amd64 || purego
+// +build !amd64 purego
+
+package mont
+
+// HasAsm is true when Mul4 and Mul6 are written in assembly
+const HasAsm = false
+
+// Mul4 sets out = a * b / 2^256 mod modulus for a < 2^256, b < modulus and
+// inv = -modulus^-1 mod 2^64. The output may alias the inputs
+func Mul4(out, a, b, modulus *[4]uint64, inv uint64) {
+	mul4Generic(out, a, b, modulus, inv)
+}
+
+// Mul6 sets out = a * b / 2^384 mod modulus for a < 2^384, b < modulus and
+// inv = -modulus^-1 mod 2^64. The output may alias the inputs
+func Mul6(out, a, b, modulus *[6]uint64, inv uint64) {
+	mul6Generic(out, a, b, modulus, inv)
+}
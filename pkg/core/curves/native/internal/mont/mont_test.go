@@ -0,0 +1,180 @@
+package mont
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// secp256k1 base field, 2^256 - 2^32 - 977
+	k256P = [4]uint64{0xfffffffefffffc2f, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+	// secp256k1 group order
+	k256N = [4]uint64{0xbfd25e8cd0364141, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+	// BLS12-381 base field
+	bls12381P = [6]uint64{
+		0xb9feffffffffaaab, 0x1eabfffeb153ffff, 0x6730d2a0f6b0f624,
+		0x64774b84f38512bf, 0x4b1ba7b6434bacd7, 0x1a0111ea397fe69a,
+	}
+)
+
+func limbsToBig(limbs []uint64) *big.Int {
+	out := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		out.Lsh(out, 64)
+		out.Or(out, new(big.Int).SetUint64(limbs[i]))
+	}
+	return out
+}
+
+func bigToLimbs(limbs []uint64, v *big.Int) {
+	w := new(big.Int).Set(v)
+	mask := new(big.Int).SetUint64(^uint64(0))
+	for i := range limbs {
+		limbs[i] = new(big.Int).And(w, mask).Uint64()
+		w.Rsh(w, 64)
+	}
+}
+
+// negInv returns -m^-1 mod 2^64
+func negInv(m *big.Int) uint64 {
+	r := new(big.Int).Lsh(big.NewInt(1), 64)
+	inv := new(big.Int).ModInverse(m, r)
+	return new(big.Int).Sub(r, inv).Uint64()
+}
+
+// expected returns a * b / 2^bits mod m
+func expected(a, b, m *big.Int, bits uint) *big.Int {
+	rInv := new(big.Int).ModInverse(new(big.Int).Lsh(big.NewInt(1), bits), m)
+	out := new(big.Int).Mul(a, b)
+	out.Mul(out, rInv)
+	return out.Mod(out, m)
+}
+
+// unreduced are values of a below 2^bits but not necessarily below the modulus
+func unreduced(bits uint) []*big.Int {
+	max := new(big.Int).Lsh(big.NewInt(1), bits)
+	out := []*big.Int{new(big.Int).Sub(max, big.NewInt(1))}
+	for i := 0; i < 10; i++ {
+		v, _ := crand.Int(crand.Reader, max)
+		out = append(out, v)
+	}
+	return out
+}
+
+// edgeValues are inputs that stress the carries
+func edgeValues(m *big.Int) []*big.Int {
+	return []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(m, big.NewInt(1)),
+		new(big.Int).Sub(m, big.NewInt(2)),
+		new(big.Int).Rsh(m, 1),
+	}
+}
+
+func TestMul4(t *testing.T) {
+	for _, modulus := range []*[4]uint64{&k256P, &k256N} {
+		m := limbsToBig(modulus[:])
+		inv := negInv(m)
+		values := edgeValues(m)
+		for i := 0; i < 50; i++ {
+			v, _ := crand.Int(crand.Reader, m)
+			values = append(values, v)
+		}
+		for _, x := range values {
+			for _, y := range values {
+				var a, b, out, generic [4]uint64
+				bigToLimbs(a[:], x)
+				bigToLimbs(b[:], y)
+				Mul4(&out, &a, &b, modulus, inv)
+				require.Equal(t, 0, expected(x, y, m, 256).Cmp(limbsToBig(out[:])))
+				mul4Generic(&generic, &a, &b, modulus, inv)
+				require.Equal(t, out, generic)
+
+				// Aliased output
+				Mul4(&a, &a, &b, modulus, inv)
+				require.Equal(t, out, a)
+			}
+		}
+		for _, x := range unreduced(256) {
+			for _, y := range values {
+				var a, b, out, generic [4]uint64
+				bigToLimbs(a[:], x)
+				bigToLimbs(b[:], y)
+				Mul4(&out, &a, &b, modulus, inv)
+				require.Equal(t, 0, expected(x, y, m, 256).Cmp(limbsToBig(out[:])))
+				mul4Generic(&generic, &a, &b, modulus, inv)
+				require.Equal(t, out, generic)
+			}
+		}
+	}
+}
+
+func TestMul6(t *testing.T) {
+	m := limbsToBig(bls12381P[:])
+	inv := negInv(m)
+	require.Equal(t, uint64(0x89f3fffcfffcfffd), inv)
+	values := edgeValues(m)
+	for i := 0; i < 50; i++ {
+		v, _ := crand.Int(crand.Reader, m)
+		values = append(values, v)
+	}
+	for _, x := range values {
+		for _, y := range values {
+			var a, b, out, generic [6]uint64
+			bigToLimbs(a[:], x)
+			bigToLimbs(b[:], y)
+			Mul6(&out, &a, &b, &bls12381P, inv)
+			require.Equal(t, 0, expected(x, y, m, 384).Cmp(limbsToBig(out[:])))
+			mul6Generic(&generic, &a, &b, &bls12381P, inv)
+			require.Equal(t, out, generic)
+
+			Mul6(&b, &a, &b, &bls12381P, inv)
+			require.Equal(t, out, b)
+		}
+	}
+	for _, x := range unreduced(384) {
+		for _, y := range values {
+			var a, b, out, generic [6]uint64
+			bigToLimbs(a[:], x)
+			bigToLimbs(b[:], y)
+			Mul6(&out, &a, &b, &bls12381P, inv)
+			require.Equal(t, 0, expected(x, y, m, 384).Cmp(limbsToBig(out[:])))
+			mul6Generic(&generic, &a, &b, &bls12381P, inv)
+			require.Equal(t, out, generic)
+		}
+	}
+}
+
+func BenchmarkMul4(b *testing.B) {
+	inv := negInv(limbsToBig(k256P[:]))
+	x := [4]uint64{1, 2, 3, 4}
+	b.Run("asm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Mul4(&x, &x, &x, &k256P, inv)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mul4Generic(&x, &x, &x, &k256P, inv)
+		}
+	})
+}
+
+func BenchmarkMul6(b *testing.B) {
+	x := [6]uint64{1, 2, 3, 4, 5, 6}
+	b.Run("asm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Mul6(&x, &x, &x, &bls12381P, 0x89f3fffcfffcfffd)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mul6Generic(&x, &x, &x, &bls12381P, 0x89f3fffcfffcfffd)
+		}
+	})
+}
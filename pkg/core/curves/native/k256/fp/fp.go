@@ -11,11 +11,17 @@ import (
 	"sync"
 
 	"github.com/etclab/kryptology/pkg/core/curves/native"
+	"github.com/etclab/kryptology/pkg/core/curves/native/internal/mont"
 )
 
 var k256FpInitonce sync.Once
 var k256FpParams native.FieldParams
 
+// modulus and inv = -modulus^-1 mod 2^64 for mont.Mul4
+var modulus = [native.FieldLimbs]uint64{0xfffffffefffffc2f, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+
+const inv = 0xd838091dd2253531
+
 func K256FpNew() *native.Field {
 	return &native.Field{
 		Value:      [native.FieldLimbs]uint64{},
@@ -62,11 +68,19 @@ func (f k256FpArithmetic) Neg(out, arg *[native.FieldLimbs]uint64) {
 
 // Square performs modular square
 func (f k256FpArithmetic) Square(out, arg *[native.FieldLimbs]uint64) {
+	if mont.HasAsm {
+		mont.Mul4(out, arg, arg, &modulus, inv)
+		return
+	}
 	Square((*MontgomeryDomainFieldElement)(out), (*MontgomeryDomainFieldElement)(arg))
 }
 
 // Mul performs modular multiplication
 func (f k256FpArithmetic) Mul(out, arg1, arg2 *[native.FieldLimbs]uint64) {
+	if mont.HasAsm {
+		mont.Mul4(out, arg1, arg2, &modulus, inv)
+		return
+	}
 	Mul((*MontgomeryDomainFieldElement)(out), (*MontgomeryDomainFieldElement)(arg1), (*MontgomeryDomainFieldElement)(arg2))
 }
 
@@ -326,3 +326,11 @@ func TestFpSetBytesWideBigInt(t *testing.T) {
 		require.Equal(t, 0, e.Cmp(a.BigInt()))
 	}
 }
+
+func BenchmarkFpMul(b *testing.B) {
+	x := K256FpNew().SetUint64(rand.Uint64())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Mul(x, x)
+	}
+}
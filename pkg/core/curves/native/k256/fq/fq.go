@@ -11,11 +11,17 @@ import (
 	"sync"
 
 	"github.com/etclab/kryptology/pkg/core/curves/native"
+	"github.com/etclab/kryptology/pkg/core/curves/native/internal/mont"
 )
 
 var k256FqInitonce sync.Once
 var k256FqParams native.FieldParams
 
+// modulus and inv = -modulus^-1 mod 2^64 for mont.Mul4
+var modulus = [native.FieldLimbs]uint64{0xbfd25e8cd0364141, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+
+const inv = 0x4b0dff665588b13f
+
 func K256FqNew() *native.Field {
 	return &native.Field{
 		Value:      [native.FieldLimbs]uint64{},
@@ -62,11 +68,19 @@ func (f k256FqArithmetic) Neg(out, arg *[native.FieldLimbs]uint64) {
 
 // Square performs modular square
 func (f k256FqArithmetic) Square(out, arg *[native.FieldLimbs]uint64) {
+	if mont.HasAsm {
+		mont.Mul4(out, arg, arg, &modulus, inv)
+		return
+	}
 	Square((*MontgomeryDomainFieldElement)(out), (*MontgomeryDomainFieldElement)(arg))
 }
 
 // Mul performs modular multiplication
 func (f k256FqArithmetic) Mul(out, arg1, arg2 *[native.FieldLimbs]uint64) {
+	if mont.HasAsm {
+		mont.Mul4(out, arg1, arg2, &modulus, inv)
+		return
+	}
 	Mul((*MontgomeryDomainFieldElement)(out), (*MontgomeryDomainFieldElement)(arg1), (*MontgomeryDomainFieldElement)(arg2))
 }
 
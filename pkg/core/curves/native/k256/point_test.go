@@ -1,6 +1,7 @@
 package k256_test
 
 import (
+	crand "crypto/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -17,3 +18,25 @@ func TestK256PointArithmetic_Hash(t *testing.T) {
 	require.True(t, !sc.IsIdentity())
 	require.True(t, sc.IsOnCurve())
 }
+
+func TestK256PointBatchNormalize(t *testing.T) {
+	points := make([]*native.EllipticPoint, 10)
+	expected := make([]*native.EllipticPoint, len(points))
+	for i := range points {
+		p, err := k256.K256PointNew().Random(crand.Reader)
+		require.NoError(t, err)
+		// Move away from Z = 1
+		points[i] = k256.K256PointNew().Add(p, p)
+		if i%4 == 1 {
+			points[i] = k256.K256PointNew().Identity()
+		}
+		expected[i] = k256.K256PointNew().ToAffine(points[i])
+	}
+	native.BatchNormalize(points)
+	for i, p := range points {
+		require.Equal(t, expected[i].X.Value, p.X.Value, "point %d", i)
+		require.Equal(t, expected[i].Y.Value, p.Y.Value, "point %d", i)
+		require.Equal(t, expected[i].Z.Value, p.Z.Value, "point %d", i)
+	}
+	native.BatchNormalize(nil)
+}
@@ -1,6 +1,7 @@
 package p256_test
 
 import (
+	crand "crypto/rand"
 	"fmt"
 	"testing"
 
@@ -36,3 +37,25 @@ func TestP256PointArithmetic_Hash(t *testing.T) {
 	require.True(t, !sc.IsIdentity())
 	require.True(t, sc.IsOnCurve())
 }
+
+func TestP256PointBatchNormalize(t *testing.T) {
+	points := make([]*native.EllipticPoint, 10)
+	expected := make([]*native.EllipticPoint, len(points))
+	for i := range points {
+		p, err := p256.P256PointNew().Random(crand.Reader)
+		require.NoError(t, err)
+		// Move away from Z = 1
+		points[i] = p256.P256PointNew().Add(p, p)
+		if i%4 == 1 {
+			points[i] = p256.P256PointNew().Identity()
+		}
+		expected[i] = p256.P256PointNew().ToAffine(points[i])
+	}
+	native.BatchNormalize(points)
+	for i, p := range points {
+		require.Equal(t, expected[i].X.Value, p.X.Value, "point %d", i)
+		require.Equal(t, expected[i].Y.Value, p.Y.Value, "point %d", i)
+		require.Equal(t, expected[i].Z.Value, p.Z.Value, "point %d", i)
+	}
+	native.BatchNormalize(nil)
+}
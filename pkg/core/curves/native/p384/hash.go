@@ -0,0 +1,96 @@
+package p384
+
+import (
+	"math/big"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+// SuiteId is the RFC 9380 suite implemented by Hash
+const SuiteId = "P384_XMD:SHA-384_SSWU_RO_"
+
+// hashFieldBytes is L = ceil((ceil(log2(p)) + k) / 8) for k = 192
+const hashFieldBytes = 72
+
+var (
+	// sswuZ is Z = -12 of RFC 9380 section 8.3
+	sswuZ = func() element {
+		var z element
+		twelve := fpBig(big.NewInt(12))
+		fp.neg(&z, &twelve)
+		return z
+	}()
+	// sswuMinusBOverA is -B / A = B / 3
+	sswuMinusBOverA = func() element {
+		var out element
+		three := fpBig(big.NewInt(3))
+		fp.invert(&out, &three)
+		fp.mul(&out, &out, &curveB)
+		return out
+	}()
+	// sswuBOverZA is B / (Z * A) = B / 36, the value of x1 when the denominator vanishes
+	sswuBOverZA = func() element {
+		var out element
+		d := fpBig(big.NewInt(36))
+		fp.invert(&out, &d)
+		fp.mul(&out, &out, &curveB)
+		return out
+	}()
+)
+
+// Hash sets p to the hash of `msg` with the domain separation tag `dst` following
+// P384_XMD:SHA-384_SSWU_RO_ of RFC 9380. `hasher` chooses the expand_message function
+func (p *Point) Hash(hasher *native.EllipticPointHasher, msg, dst []byte) *Point {
+	var u []byte
+	switch hasher.Type() {
+	case native.XMD:
+		u = native.ExpandMsgXmd(hasher, msg, dst, 2*hashFieldBytes)
+	case native.XOF:
+		u = native.ExpandMsgXof(hasher, msg, dst, 2*hashFieldBytes)
+	}
+	var u0, u1 element
+	fp.setBytesWide(&u0, reverse(u[:hashFieldBytes]))
+	fp.setBytesWide(&u1, reverse(u[hashFieldBytes:]))
+	var q0, q1 Point
+	q0.mapToCurve(&u0)
+	q1.mapToCurve(&u1)
+	// The cofactor is one
+	return p.Add(&q0, &q1)
+}
+
+// mapToCurve applies the simplified SWU map of RFC 9380 section 6.6.2
+func (p *Point) mapToCurve(u *element) *Point {
+	var uu, zuu, tv1, t, x1, x2, gx1, gx2 element
+	fp.square(&uu, u)
+	fp.mul(&zuu, &sswuZ, &uu)
+
+	// tv1 = 1 / (Z^2 * u^4 + Z * u^2), or zero when the denominator is zero
+	fp.square(&t, &zuu)
+	fp.add(&t, &t, &zuu)
+	fp.invert(&tv1, &t)
+
+	// x1 = (-B / A) * (1 + tv1), or B / (Z * A) when tv1 is zero
+	fp.add(&x1, &tv1, &fp.r)
+	fp.mul(&x1, &x1, &sswuMinusBOverA)
+	cmove(&x1, &x1, &sswuBOverZA, fp.isZero(&tv1))
+	curveRhs(&gx1, &x1)
+
+	// x2 = Z * u^2 * x1
+	fp.mul(&x2, &zuu, &x1)
+	curveRhs(&gx2, &x2)
+
+	y1, isSquare := sqrt(&gx1)
+	y2, _ := sqrt(&gx2)
+	var x, y element
+	cmove(&x, &x2, &x1, isSquare)
+	cmove(&y, &y2, &y1, isSquare)
+
+	// sgn0(y) = sgn0(u)
+	var ny element
+	fp.neg(&ny, &y)
+	cmove(&y, &y, &ny, fp.isOdd(&y)^fp.isOdd(u))
+	p.x = x
+	p.y = y
+	p.z = fp.r
+	return p
+}
@@ -0,0 +1,583 @@
+// Package p384 implements the arithmetic of NIST P-384 from FIPS 186-4, the short Weierstrass curve
+// y^2 = x^3 - 3x + b over the field of order p = 2^384 - 2^128 - 2^96 + 2^32 - 1. The group has
+// prime order n and cofactor 1. Field elements and scalars are encoded as 48-byte big-endian
+// integers and points with the SEC 1 encodings.
+package p384
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+const (
+	// FieldBytes is the length of an encoded field element
+	FieldBytes = 48
+	// ScalarBytes is the length of an encoded scalar
+	ScalarBytes = 48
+	// WideScalarBytes is the length of the input of SetBytesWide
+	WideScalarBytes = 96
+	// CompressedBytes is the length of a compressed SEC 1 point
+	CompressedBytes = 1 + FieldBytes
+	// UncompressedBytes is the length of an uncompressed SEC 1 point
+	UncompressedBytes = 1 + 2*FieldBytes
+)
+
+var (
+	pModulus, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff", 16)
+	nModulus, _ = new(big.Int).SetString("ffffffffffffffffffffffffffffffffffffffffffffffffc7634d81f4372ddf581a0db248b0a77aecec196accc52973", 16)
+	bCoeff, _   = new(big.Int).SetString("b3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef", 16)
+
+	fp = newField(pModulus)
+	fq = newField(nModulus)
+
+	// curveB is the coefficient b of the curve equation
+	curveB = fpBig(bCoeff)
+	// sqrtExp is (p + 1) / 4, since p = 3 mod 4
+	sqrtExp = fromBig(new(big.Int).Rsh(new(big.Int).Add(pModulus, big.NewInt(1)), 2))
+	// scalarSqrtExp is (n + 1) / 4, since n = 3 mod 4 as well
+	scalarSqrtExp = fromBig(new(big.Int).Rsh(new(big.Int).Add(nModulus, big.NewInt(1)), 2))
+
+	generator = func() *Point {
+		x, _ := new(big.Int).SetString("aa87ca22be8b05378eb1c71ef320ad746e1d3b628ba79b9859f741e082542a385502f25dbf55296c3a545e3872760ab7", 16)
+		y, _ := new(big.Int).SetString("3617de4a96262c6f5d9e98bf9292dc29f8f41dbd289a147ce9da3113b5f0b8c00a60b1ce1d7e819d7a431d7c90ea0e5f", 16)
+		g, err := new(Point).SetBigInt(x, y)
+		if err != nil {
+			panic(err)
+		}
+		return g
+	}()
+)
+
+// fpBig returns a non-negative integer below p as a field element
+func fpBig(v *big.Int) element {
+	var out element
+	n := fromBig(v)
+	fp.toMont(&out, &n)
+	return out
+}
+
+// Order returns n, the order of the group
+func Order() *big.Int {
+	return new(big.Int).Set(nModulus)
+}
+
+// reverse returns the bytes in the opposite order
+func reverse(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i, b := range in {
+		out[len(in)-1-i] = b
+	}
+	return out
+}
+
+// Scalar is an integer modulo n
+type Scalar struct {
+	value element
+}
+
+// Zero sets s = 0
+func (s *Scalar) Zero() *Scalar {
+	s.value = element{}
+	return s
+}
+
+// One sets s = 1
+func (s *Scalar) One() *Scalar {
+	s.value = fq.r
+	return s
+}
+
+// SetUint64 sets s = v
+func (s *Scalar) SetUint64(v uint64) *Scalar {
+	fq.toMont(&s.value, &element{v})
+	return s
+}
+
+// Set sets s = a
+func (s *Scalar) Set(a *Scalar) *Scalar {
+	s.value = a.value
+	return s
+}
+
+// Random sets s to a uniformly random scalar read from `reader`
+func (s *Scalar) Random(reader io.Reader) (*Scalar, error) {
+	var buf [WideScalarBytes]byte
+	if _, err := io.ReadFull(reader, buf[:]); err != nil {
+		return nil, err
+	}
+	return s.SetBytesWide(buf[:])
+}
+
+// SetBytes sets s to the canonical 48-byte big-endian encoding `input`
+func (s *Scalar) SetBytes(input []byte) (*Scalar, error) {
+	if len(input) != ScalarBytes {
+		return nil, fmt.Errorf("invalid scalar encoding")
+	}
+	var value element
+	if !fq.setBytes(&value, reverse(input)) {
+		return nil, fmt.Errorf("scalar is not canonical")
+	}
+	s.value = value
+	return s, nil
+}
+
+// SetBytesWide sets s to the big-endian integer `input` of at most 144 bytes reduced modulo n
+func (s *Scalar) SetBytesWide(input []byte) (*Scalar, error) {
+	if len(input) > 3*limbs*8 {
+		return nil, fmt.Errorf("invalid length")
+	}
+	fq.setBytesWide(&s.value, reverse(input))
+	return s, nil
+}
+
+// Bytes returns the canonical 48-byte big-endian encoding
+func (s *Scalar) Bytes() [ScalarBytes]byte {
+	var out [ScalarBytes]byte
+	b := fq.bytes(&s.value)
+	copy(out[:], reverse(b[:]))
+	return out
+}
+
+// SetBigInt sets s = v mod n
+func (s *Scalar) SetBigInt(v *big.Int) *Scalar {
+	n := fromBig(new(big.Int).Mod(v, nModulus))
+	fq.toMont(&s.value, &n)
+	return s
+}
+
+// BigInt returns the scalar as an integer
+func (s *Scalar) BigInt() *big.Int {
+	var n element
+	fq.fromMont(&n, &s.value)
+	return toBig(&n)
+}
+
+// Add sets s = a + b
+func (s *Scalar) Add(a, b *Scalar) *Scalar {
+	fq.add(&s.value, &a.value, &b.value)
+	return s
+}
+
+// Sub sets s = a - b
+func (s *Scalar) Sub(a, b *Scalar) *Scalar {
+	fq.sub(&s.value, &a.value, &b.value)
+	return s
+}
+
+// Mul sets s = a * b
+func (s *Scalar) Mul(a, b *Scalar) *Scalar {
+	fq.mul(&s.value, &a.value, &b.value)
+	return s
+}
+
+// Neg sets s = -a
+func (s *Scalar) Neg(a *Scalar) *Scalar {
+	fq.neg(&s.value, &a.value)
+	return s
+}
+
+// Invert sets s = a^-1 and reports whether a was invertible
+func (s *Scalar) Invert(a *Scalar) (*Scalar, bool) {
+	var v element
+	fq.invert(&v, &a.value)
+	s.value = v
+	return s, fq.isZero(&a.value) == 0
+}
+
+// Sqrt sets s to a square root of a and reports whether a is a square
+func (s *Scalar) Sqrt(a *Scalar) (*Scalar, bool) {
+	var r, check element
+	fq.exp(&r, &a.value, &scalarSqrtExp)
+	fq.square(&check, &r)
+	s.value = r
+	return s, fq.equal(&check, &a.value) == 1
+}
+
+// IsZero returns 1 if s = 0 and 0 otherwise
+func (s *Scalar) IsZero() int {
+	return fq.isZero(&s.value)
+}
+
+// IsOdd returns 1 if the integer s is odd and 0 otherwise
+func (s *Scalar) IsOdd() int {
+	return fq.isOdd(&s.value)
+}
+
+// Equal returns 1 if s = a and 0 otherwise
+func (s *Scalar) Equal(a *Scalar) int {
+	return fq.equal(&s.value, &a.value)
+}
+
+// Point is a point of P-384 in projective coordinates, the identity has z = 0
+type Point struct {
+	x, y, z element
+}
+
+// Identity sets p to the point at infinity
+func (p *Point) Identity() *Point {
+	p.x = element{}
+	p.y = fp.r
+	p.z = element{}
+	return p
+}
+
+// Generator sets p to the base point of FIPS 186-4
+func (p *Point) Generator() *Point {
+	*p = *generator
+	return p
+}
+
+// Set sets p = a
+func (p *Point) Set(a *Point) *Point {
+	*p = *a
+	return p
+}
+
+// Random sets p to a random point
+func (p *Point) Random(reader io.Reader) (*Point, error) {
+	var seed [64]byte
+	if _, err := io.ReadFull(reader, seed[:]); err != nil {
+		return nil, err
+	}
+	return p.Hash(native.EllipticPointHasherSha384(), seed[:], []byte(SuiteId)), nil
+}
+
+// IsIdentity returns 1 if p is the point at infinity and 0 otherwise
+func (p *Point) IsIdentity() int {
+	return fp.isZero(&p.z)
+}
+
+// IsOnCurve returns 1 if p satisfies the curve equation and 0 otherwise.
+// The point at infinity is on the curve
+func (p *Point) IsOnCurve() int {
+	// Y^2 * Z = X^3 - 3 * X * Z^2 + b * Z^3
+	var lhs, rhs, zz, t element
+	fp.square(&lhs, &p.y)
+	fp.mul(&lhs, &lhs, &p.z)
+	fp.square(&zz, &p.z)
+	fp.square(&rhs, &p.x)
+	fp.mul(&rhs, &rhs, &p.x)
+	fp.mul(&t, &p.x, &zz)
+	fp.sub(&rhs, &rhs, &t)
+	fp.sub(&rhs, &rhs, &t)
+	fp.sub(&rhs, &rhs, &t)
+	fp.mul(&t, &zz, &p.z)
+	fp.mul(&t, &t, &curveB)
+	fp.add(&rhs, &rhs, &t)
+	return fp.equal(&lhs, &rhs) | p.IsIdentity()
+}
+
+// Add sets p = a + b with the complete formulas for a = -3 of Renes-Costello-Batina 2015
+// (https://eprint.iacr.org/2015/1060 Algorithm 4)
+func (p *Point) Add(a, b *Point) *Point {
+	var t0, t1, t2, t3, t4, x3, y3, z3 element
+	fp.mul(&t0, &a.x, &b.x)
+	fp.mul(&t1, &a.y, &b.y)
+	fp.mul(&t2, &a.z, &b.z)
+	fp.add(&t3, &a.x, &a.y)
+	fp.add(&t4, &b.x, &b.y)
+	fp.mul(&t3, &t3, &t4)
+	fp.add(&t4, &t0, &t1)
+	fp.sub(&t3, &t3, &t4)
+	fp.add(&t4, &a.y, &a.z)
+	fp.add(&x3, &b.y, &b.z)
+	fp.mul(&t4, &t4, &x3)
+	fp.add(&x3, &t1, &t2)
+	fp.sub(&t4, &t4, &x3)
+	fp.add(&x3, &a.x, &a.z)
+	fp.add(&y3, &b.x, &b.z)
+	fp.mul(&x3, &x3, &y3)
+	fp.add(&y3, &t0, &t2)
+	fp.sub(&y3, &x3, &y3)
+	fp.mul(&z3, &curveB, &t2)
+	fp.sub(&x3, &y3, &z3)
+	fp.add(&z3, &x3, &x3)
+	fp.add(&x3, &x3, &z3)
+	fp.sub(&z3, &t1, &x3)
+	fp.add(&x3, &t1, &x3)
+	fp.mul(&y3, &curveB, &y3)
+	fp.add(&t1, &t2, &t2)
+	fp.add(&t2, &t1, &t2)
+	fp.sub(&y3, &y3, &t2)
+	fp.sub(&y3, &y3, &t0)
+	fp.add(&t1, &y3, &y3)
+	fp.add(&y3, &t1, &y3)
+	fp.add(&t1, &t0, &t0)
+	fp.add(&t0, &t1, &t0)
+	fp.sub(&t0, &t0, &t2)
+	fp.mul(&t1, &t4, &y3)
+	fp.mul(&t2, &t0, &y3)
+	fp.mul(&y3, &x3, &z3)
+	fp.add(&y3, &y3, &t2)
+	fp.mul(&x3, &t3, &x3)
+	fp.sub(&x3, &x3, &t1)
+	fp.mul(&z3, &t4, &z3)
+	fp.mul(&t1, &t3, &t0)
+	fp.add(&z3, &z3, &t1)
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// Sub sets p = a - b
+func (p *Point) Sub(a, b *Point) *Point {
+	var nb Point
+	return p.Add(a, nb.Neg(b))
+}
+
+// Double sets p = 2 * a with the formulas for a = -3 of Renes-Costello-Batina 2015
+// (https://eprint.iacr.org/2015/1060 Algorithm 6)
+func (p *Point) Double(a *Point) *Point {
+	var t0, t1, t2, t3, x3, y3, z3 element
+	fp.square(&t0, &a.x)
+	fp.square(&t1, &a.y)
+	fp.square(&t2, &a.z)
+	fp.mul(&t3, &a.x, &a.y)
+	fp.add(&t3, &t3, &t3)
+	fp.mul(&z3, &a.x, &a.z)
+	fp.add(&z3, &z3, &z3)
+	fp.mul(&y3, &curveB, &t2)
+	fp.sub(&y3, &y3, &z3)
+	fp.add(&x3, &y3, &y3)
+	fp.add(&y3, &x3, &y3)
+	fp.sub(&x3, &t1, &y3)
+	fp.add(&y3, &t1, &y3)
+	fp.mul(&y3, &x3, &y3)
+	fp.mul(&x3, &x3, &t3)
+	fp.add(&t3, &t2, &t2)
+	fp.add(&t2, &t2, &t3)
+	fp.mul(&z3, &curveB, &z3)
+	fp.sub(&z3, &z3, &t2)
+	fp.sub(&z3, &z3, &t0)
+	fp.add(&t3, &z3, &z3)
+	fp.add(&z3, &z3, &t3)
+	fp.add(&t3, &t0, &t0)
+	fp.add(&t0, &t3, &t0)
+	fp.sub(&t0, &t0, &t2)
+	fp.mul(&t0, &t0, &z3)
+	fp.add(&y3, &y3, &t0)
+	fp.mul(&t0, &a.y, &a.z)
+	fp.add(&t0, &t0, &t0)
+	fp.mul(&z3, &t0, &z3)
+	fp.sub(&x3, &x3, &z3)
+	fp.mul(&z3, &t0, &t1)
+	fp.add(&z3, &z3, &z3)
+	fp.add(&z3, &z3, &z3)
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// Neg sets p = -a
+func (p *Point) Neg(a *Point) *Point {
+	p.x = a.x
+	fp.neg(&p.y, &a.y)
+	p.z = a.z
+	return p
+}
+
+// Mul sets p = s * a in constant time with a fixed window of 4 bits
+func (p *Point) Mul(a *Point, s *Scalar) *Point {
+	var table [16]Point
+	table[0].Identity()
+	table[1] = *a
+	for i := 2; i < 16; i += 2 {
+		table[i].Double(&table[i/2])
+		table[i+1].Add(&table[i], a)
+	}
+	var n element
+	fq.fromMont(&n, &s.value)
+	var r, t Point
+	r.Identity()
+	for i := limbs*64 - 4; i >= 0; i -= 4 {
+		r.Double(&r)
+		r.Double(&r)
+		r.Double(&r)
+		r.Double(&r)
+		window := int((n[i/64] >> uint(i%64)) & 0xf)
+		t.Identity()
+		for j := 1; j < 16; j++ {
+			t.cmove(&t, &table[j], ctEqual(j, window))
+		}
+		r.Add(&r, &t)
+	}
+	*p = r
+	return p
+}
+
+// ctEqual returns 1 if a = b and 0 otherwise without branching on the values
+func ctEqual(a, b int) int {
+	d := uint64(a ^ b)
+	return int(1 - ((d | -d) >> 63))
+}
+
+// cmove sets p = b when choice is 1 and p = a when it is 0
+func (p *Point) cmove(a, b *Point, choice int) *Point {
+	cmove(&p.x, &a.x, &b.x, choice)
+	cmove(&p.y, &a.y, &b.y, choice)
+	cmove(&p.z, &a.z, &b.z, choice)
+	return p
+}
+
+// Equal returns 1 if p = a and 0 otherwise
+func (p *Point) Equal(a *Point) int {
+	var l, r element
+	fp.mul(&l, &p.x, &a.z)
+	fp.mul(&r, &a.x, &p.z)
+	eq := fp.equal(&l, &r)
+	fp.mul(&l, &p.y, &a.z)
+	fp.mul(&r, &a.y, &p.z)
+	eq &= fp.equal(&l, &r)
+	// Both are the identity or neither is
+	both := p.IsIdentity() & a.IsIdentity()
+	neither := (1 - p.IsIdentity()) & (1 - a.IsIdentity())
+	return both | (neither & eq)
+}
+
+// affine returns the affine coordinates of p, which are zero for the identity
+func (p *Point) affine() (x, y element) {
+	var inv element
+	fp.invert(&inv, &p.z)
+	fp.mul(&x, &p.x, &inv)
+	fp.mul(&y, &p.y, &inv)
+	return x, y
+}
+
+// feBytes returns the big-endian encoding of a field element
+func feBytes(a *element) []byte {
+	b := fp.bytes(a)
+	return reverse(b[:])
+}
+
+// feSetBytes decodes a canonical big-endian field element
+func feSetBytes(out *element, input []byte) bool {
+	return fp.setBytes(out, reverse(input))
+}
+
+// ToCompressed returns the compressed SEC 1 encoding of p. The identity is encoded as zeros
+func (p *Point) ToCompressed() [CompressedBytes]byte {
+	var out [CompressedBytes]byte
+	if p.IsIdentity() == 1 {
+		return out
+	}
+	x, y := p.affine()
+	out[0] = byte(2 | fp.isOdd(&y))
+	copy(out[1:], feBytes(&x))
+	return out
+}
+
+// FromCompressed sets p to the point with the compressed SEC 1 encoding `input`
+func (p *Point) FromCompressed(input *[CompressedBytes]byte) (*Point, error) {
+	if input[0] == 0 {
+		for _, b := range input[1:] {
+			if b != 0 {
+				return nil, fmt.Errorf("invalid point encoding")
+			}
+		}
+		return p.Identity(), nil
+	}
+	if input[0] != 2 && input[0] != 3 {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	sign := int(input[0] & 1)
+	var x element
+	if !feSetBytes(&x, input[1:]) {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	var rhs element
+	curveRhs(&rhs, &x)
+	y, ok := sqrt(&rhs)
+	if ok == 0 {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+	var ny element
+	fp.neg(&ny, &y)
+	cmove(&y, &y, &ny, fp.isOdd(&y)^sign)
+	p.x = x
+	p.y = y
+	p.z = fp.r
+	return p, nil
+}
+
+// ToUncompressed returns the uncompressed SEC 1 encoding of p. The identity is encoded as zeros
+func (p *Point) ToUncompressed() [UncompressedBytes]byte {
+	var out [UncompressedBytes]byte
+	if p.IsIdentity() == 1 {
+		return out
+	}
+	x, y := p.affine()
+	out[0] = 4
+	copy(out[1:1+FieldBytes], feBytes(&x))
+	copy(out[1+FieldBytes:], feBytes(&y))
+	return out
+}
+
+// FromUncompressed sets p to the point with the uncompressed SEC 1 encoding `input`
+func (p *Point) FromUncompressed(input *[UncompressedBytes]byte) (*Point, error) {
+	if input[0] == 0 {
+		for _, b := range input[1:] {
+			if b != 0 {
+				return nil, fmt.Errorf("invalid point encoding")
+			}
+		}
+		return p.Identity(), nil
+	}
+	if input[0] != 4 {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	var q Point
+	if !feSetBytes(&q.x, input[1:1+FieldBytes]) || !feSetBytes(&q.y, input[1+FieldBytes:]) {
+		return nil, fmt.Errorf("invalid point encoding")
+	}
+	q.z = fp.r
+	if q.IsOnCurve() == 0 {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+	*p = q
+	return p, nil
+}
+
+// curveRhs sets out = x^3 - 3x + b
+func curveRhs(out, x *element) {
+	var t, x3 element
+	fp.square(&x3, x)
+	fp.mul(&x3, &x3, x)
+	fp.add(&t, x, x)
+	fp.add(&t, &t, x)
+	fp.sub(&x3, &x3, &t)
+	fp.add(out, &x3, &curveB)
+}
+
+// sqrt returns a candidate square root of a, and 1 if it is one or 0 if a is not a square
+func sqrt(a *element) (element, int) {
+	var r, check element
+	fp.exp(&r, a, &sqrtExp)
+	fp.square(&check, &r)
+	return r, fp.equal(&check, a)
+}
+
+// BigInt returns the affine coordinates of p, which are zero for the identity
+func (p *Point) BigInt() (x, y *big.Int) {
+	ax, ay := p.affine()
+	var nx, ny element
+	fp.fromMont(&nx, &ax)
+	fp.fromMont(&ny, &ay)
+	return toBig(&nx), toBig(&ny)
+}
+
+// SetBigInt sets p to the point with affine coordinates (x, y)
+func (p *Point) SetBigInt(x, y *big.Int) (*Point, error) {
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(pModulus) >= 0 || y.Cmp(pModulus) >= 0 {
+		return nil, fmt.Errorf("invalid coordinates")
+	}
+	var q Point
+	q.x = fpBig(x)
+	q.y = fpBig(y)
+	q.z = fp.r
+	if q.IsOnCurve() == 0 {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+	*p = q
+	return p, nil
+}
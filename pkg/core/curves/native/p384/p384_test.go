@@ -0,0 +1,160 @@
+package p384
+
+import (
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+func randomElement(t *testing.T, f *field, modulus *big.Int) (element, *big.Int) {
+	v, err := crand.Int(crand.Reader, modulus)
+	require.NoError(t, err)
+	var e element
+	n := fromBig(v)
+	f.toMont(&e, &n)
+	return e, v
+}
+
+func elementBig(f *field, e *element) *big.Int {
+	var n element
+	f.fromMont(&n, e)
+	return toBig(&n)
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	for _, tc := range []struct {
+		f       *field
+		modulus *big.Int
+	}{{fp, pModulus}, {fq, nModulus}} {
+		for i := 0; i < 100; i++ {
+			a, ab := randomElement(t, tc.f, tc.modulus)
+			b, bb := randomElement(t, tc.f, tc.modulus)
+			var r element
+			tc.f.add(&r, &a, &b)
+			require.Equal(t, new(big.Int).Mod(new(big.Int).Add(ab, bb), tc.modulus), elementBig(tc.f, &r))
+			tc.f.sub(&r, &a, &b)
+			require.Equal(t, new(big.Int).Mod(new(big.Int).Sub(ab, bb), tc.modulus), elementBig(tc.f, &r))
+			tc.f.mul(&r, &a, &b)
+			require.Equal(t, new(big.Int).Mod(new(big.Int).Mul(ab, bb), tc.modulus), elementBig(tc.f, &r))
+			tc.f.invert(&r, &a)
+			require.Equal(t, new(big.Int).ModInverse(ab, tc.modulus), elementBig(tc.f, &r))
+		}
+	}
+}
+
+func TestScalarBytes(t *testing.T) {
+	s, err := new(Scalar).Random(crand.Reader)
+	require.NoError(t, err)
+	b := s.Bytes()
+	require.Equal(t, s.BigInt().FillBytes(make([]byte, ScalarBytes)), b[:])
+	s2, err := new(Scalar).SetBytes(b[:])
+	require.NoError(t, err)
+	require.Equal(t, 1, s.Equal(s2))
+
+	_, err = new(Scalar).SetBytes(nModulus.Bytes())
+	require.Error(t, err)
+	_, err = new(Scalar).SetBytes(b[1:])
+	require.Error(t, err)
+
+	wide := make([]byte, WideScalarBytes)
+	_, _ = crand.Read(wide)
+	s, err = new(Scalar).SetBytesWide(wide)
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Mod(new(big.Int).SetBytes(wide), nModulus), s.BigInt())
+
+	sq := new(Scalar).Mul(s, s)
+	r, ok := new(Scalar).Sqrt(sq)
+	require.True(t, ok)
+	require.Equal(t, 1, new(Scalar).Mul(r, r).Equal(sq))
+}
+
+func TestPointMatchesStdlib(t *testing.T) {
+	curve := elliptic.P384()
+	for i := 0; i < 10; i++ {
+		s, err := new(Scalar).Random(crand.Reader)
+		require.NoError(t, err)
+		b := s.Bytes()
+		p := new(Point).Mul(new(Point).Generator(), s)
+		ex, ey := curve.ScalarBaseMult(b[:])
+		x, y := p.BigInt()
+		require.Equal(t, ex, x)
+		require.Equal(t, ey, y)
+
+		// Addition and doubling
+		q := new(Point).Add(p, new(Point).Generator())
+		ex, ey = curve.Add(ex, ey, curve.Params().Gx, curve.Params().Gy)
+		x, y = q.BigInt()
+		require.Equal(t, ex, x)
+		require.Equal(t, ey, y)
+		require.Equal(t, 1, new(Point).Double(p).Equal(new(Point).Add(p, p)))
+
+		// SEC 1 encodings
+		u := q.ToUncompressed()
+		require.Equal(t, elliptic.Marshal(curve, ex, ey), u[:])
+		c := q.ToCompressed()
+		require.Equal(t, elliptic.MarshalCompressed(curve, ex, ey), c[:])
+		q2, err := new(Point).FromCompressed(&c)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.Equal(q2))
+		q2, err = new(Point).FromUncompressed(&u)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.Equal(q2))
+	}
+}
+
+func TestPointIdentity(t *testing.T) {
+	g := new(Point).Generator()
+	id := new(Point).Identity()
+	require.Equal(t, 1, id.IsOnCurve())
+	require.Equal(t, 1, new(Point).Add(g, id).Equal(g))
+	require.Equal(t, 1, new(Point).Sub(g, g).IsIdentity())
+	require.Equal(t, 1, new(Point).Double(id).IsIdentity())
+	n := new(Scalar).SetBigInt(new(big.Int).Sub(nModulus, big.NewInt(1)))
+	require.Equal(t, 1, new(Point).Mul(g, n).Equal(new(Point).Neg(g)))
+	require.Equal(t, 1, new(Point).Mul(g, new(Scalar).Zero()).IsIdentity())
+
+	c := id.ToCompressed()
+	p, err := new(Point).FromCompressed(&c)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.IsIdentity())
+	u := id.ToUncompressed()
+	p, err = new(Point).FromUncompressed(&u)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.IsIdentity())
+
+	u = g.ToUncompressed()
+	u[UncompressedBytes-1] ^= 1
+	_, err = new(Point).FromUncompressed(&u)
+	require.Error(t, err)
+}
+
+// TestHash checks the vectors of RFC 9380 appendix J.3.1
+func TestHash(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-P384_XMD:SHA-384_SSWU_RO_")
+	for _, tc := range []struct {
+		msg, x, y string
+	}{
+		{
+			"",
+			"eb9fe1b4f4e14e7140803c1d99d0a93cd823d2b024040f9c067a8eca1f5a2eeac9ad604973527a356f3fa3aeff0e4d83",
+			"0c21708cff382b7f4643c07b105c2eaec2cead93a917d825601e63c8f21f6abd9abc22c93c2bed6f235954b25048bb1a",
+		},
+		{
+			"abc",
+			"e02fc1a5f44a7519419dd314e29863f30df55a514da2d655775a81d413003c4d4e7fd59af0826dfaad4200ac6f60abe1",
+			"01f638d04d98677d65bef99aef1a12a70a4cbb9270ec55248c04530d8bc1f8f90f8a6a859a7c1f1ddccedf8f96d675f6",
+		},
+	} {
+		p := new(Point).Hash(native.EllipticPointHasherSha384(), []byte(tc.msg), dst)
+		require.Equal(t, 1, p.IsOnCurve())
+		x, y := p.BigInt()
+		require.Equal(t, tc.x, hex.EncodeToString(x.FillBytes(make([]byte, FieldBytes))))
+		require.Equal(t, tc.y, hex.EncodeToString(y.FillBytes(make([]byte, FieldBytes))))
+	}
+}
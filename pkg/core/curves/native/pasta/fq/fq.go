@@ -73,6 +73,13 @@ func (fq *Fq) IsZero() bool {
 	return t == 0
 }
 
+// IsOdd returns true if fq is odd
+func (fq *Fq) IsOdd() bool {
+	tv := new(fiat_pasta_fq_non_montgomery_domain_field_element)
+	fiat_pasta_fq_from_montgomery(tv, (*fiat_pasta_fq_montgomery_domain_field_element)(fq))
+	return tv[0]&0x01 == 0x01
+}
+
 // IsOne returns true if fp == r
 func (fq *Fq) IsOne() bool {
 	return fq.Equal(r)
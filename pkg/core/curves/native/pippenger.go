@@ -0,0 +1,35 @@
+package native
+
+// maxPippengerWindow bounds the number of buckets, and therefore memory, to 2^16
+const maxPippengerWindow = 16
+
+// PippengerWindowSize returns the window width in bits that minimises the
+// cost of a bucket multi-scalar multiplication of `n` points with 256-bit scalars.
+// Each of the 256/w windows costs about n additions to fill the buckets
+// and 2 * 2^w additions to combine them, so the best width grows slowly with n.
+// The width only depends on the number of points, never on the scalars
+func PippengerWindowSize(n int) int {
+	best, bestCost := 1, -1
+	for w := 1; w <= maxPippengerWindow; w++ {
+		windows := (FieldBytes*8 + w - 1) / w
+		cost := windows * (n + 2<<uint(w))
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = w, cost
+		}
+	}
+	return best
+}
+
+// ScalarWindow returns the `width` bits of the little-endian scalar `s`
+// starting at bit `offset`. Bits beyond the end of `s` are zero
+func ScalarWindow(s []byte, offset, width int) int {
+	window := 0
+	for i := 0; i < width; i++ {
+		bit := offset + i
+		if bit>>3 >= len(s) {
+			break
+		}
+		window |= int(s[bit>>3]>>uint(bit&7)&1) << uint(i)
+	}
+	return window
+}
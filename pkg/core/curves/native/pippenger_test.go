@@ -0,0 +1,30 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPippengerWindowSize(t *testing.T) {
+	prev := 0
+	for _, n := range []int{0, 1, 2, 8, 64, 256, 1024, 1 << 14, 1 << 20} {
+		w := PippengerWindowSize(n)
+		require.GreaterOrEqual(t, w, prev)
+		require.LessOrEqual(t, w, maxPippengerWindow)
+		prev = w
+	}
+	require.Greater(t, PippengerWindowSize(1<<20), PippengerWindowSize(8))
+}
+
+func TestScalarWindow(t *testing.T) {
+	s := []byte{0xa5, 0x3c, 0xff}
+	require.Equal(t, 0x5, ScalarWindow(s, 0, 4))
+	require.Equal(t, 0xa, ScalarWindow(s, 4, 4))
+	require.Equal(t, 0xca5, ScalarWindow(s, 0, 12))
+	require.Equal(t, 0xca, ScalarWindow(s, 4, 8))
+	require.Equal(t, 0x1, ScalarWindow(s, 7, 1))
+	// bits past the end are zero
+	require.Equal(t, 0xff, ScalarWindow(s, 16, 12))
+	require.Equal(t, 0, ScalarWindow(s, 24, 6))
+}
@@ -37,6 +37,7 @@ const (
 	BLAKE2B
 	SHAKE128
 	SHAKE256
+	SHA384
 )
 
 // EllipticPoint represents a Weierstrauss elliptic curve point
@@ -98,6 +99,15 @@ func EllipticPointHasherSha256() *EllipticPointHasher {
 	}
 }
 
+// EllipticPointHasherSha384 creates a point hasher that uses Sha384
+func EllipticPointHasherSha384() *EllipticPointHasher {
+	return &EllipticPointHasher{
+		name:     SHA384,
+		hashType: XMD,
+		xmd:      sha512.New384(),
+	}
+}
+
 // EllipticPointHasherSha512 creates a point hasher that uses Sha512
 func EllipticPointHasherSha512() *EllipticPointHasher {
 	return &EllipticPointHasher{
@@ -153,7 +163,10 @@ func EllipticPointHasherShake128() *EllipticPointHasher {
 	}
 }
 
-// EllipticPointHasherShake256 creates a point hasher that uses Shake256
+// EllipticPointHasherShake256 creates a point hasher that uses Shake256.
+// Its name, and so the suite identifier used by EllipticPoint.Hash, is SHAKE-128
+// as in earlier releases, which keeps the points it hashes to unchanged.
+// Use EllipticPointHasherShake256Rfc9380 for the SHAKE-256 suites of RFC 9380
 func EllipticPointHasherShake256() *EllipticPointHasher {
 	return &EllipticPointHasher{
 		name:     SHAKE128,
@@ -162,6 +175,16 @@ func EllipticPointHasherShake256() *EllipticPointHasher {
 	}
 }
 
+// EllipticPointHasherShake256Rfc9380 creates a point hasher that uses Shake256
+// and is named SHAKE-256, e.g. for the suite P256_XOF:SHAKE-256_SSWU_RO_
+func EllipticPointHasherShake256Rfc9380() *EllipticPointHasher {
+	return &EllipticPointHasher{
+		name:     SHAKE256,
+		hashType: XOF,
+		xof:      sha3.NewShake256(),
+	}
+}
+
 // EllipticPointArithmetic are the methods that specific curves
 // need to implement for higher abstractions to wrap the point
 type EllipticPointArithmetic interface {
@@ -208,6 +231,8 @@ func (n EllipticPointHashName) String() string {
 		return "SHAKE-128"
 	case SHAKE256:
 		return "SHAKE-256"
+	case SHA384:
+		return "SHA-384"
 	}
 	return "unknown"
 }
@@ -231,9 +256,21 @@ func (p *EllipticPoint) Random(reader io.Reader) (*EllipticPoint, error) {
 	return p, nil
 }
 
-// Hash uses the hasher to map bytes to a valid point
+// Hash uses the hasher to map bytes to a valid point, using the
+// RFC 9380 suite identifier returned by SuiteId as the domain separation tag
 func (p *EllipticPoint) Hash(bytes []byte, hasher *EllipticPointHasher) (*EllipticPoint, error) {
-	dst := []byte(fmt.Sprintf("%s_%s:%s_SSWU_RO_", p.Params.Name, hasher.hashType, hasher.name))
+	return p.HashWithDst(bytes, []byte(p.SuiteId(hasher)), hasher)
+}
+
+// HashWithDst maps bytes to a valid point with the RFC 9380 hash_to_curve
+// of the suite returned by SuiteId and the application's domain separation tag `dst`
+func (p *EllipticPoint) HashWithDst(bytes, dst []byte, hasher *EllipticPointHasher) (*EllipticPoint, error) {
+	if hasher == nil {
+		return nil, fmt.Errorf("invalid hasher")
+	}
+	if len(dst) == 0 {
+		return nil, fmt.Errorf("empty domain separation tag")
+	}
 	err := p.Arithmetic.Hash(p, hasher, bytes, dst)
 	if err != nil {
 		return nil, errors.Wrap(err, "hash failed")
@@ -241,6 +278,12 @@ func (p *EllipticPoint) Hash(bytes []byte, hasher *EllipticPointHasher) (*Ellipt
 	return p, nil
 }
 
+// SuiteId returns the RFC 9380 suite identifier for hashing to this curve
+// with `hasher`, e.g. P256_XMD:SHA-256_SSWU_RO_
+func (p *EllipticPoint) SuiteId(hasher *EllipticPointHasher) string {
+	return fmt.Sprintf("%s_%s:%s_SSWU_RO_", p.Params.Name, hasher.hashType, hasher.name)
+}
+
 // Identity returns the identity point
 func (p *EllipticPoint) Identity() *EllipticPoint {
 	p.X.SetZero()
@@ -407,17 +450,23 @@ func (p *EllipticPoint) ToAffine(clone *EllipticPoint) *EllipticPoint {
 
 // SumOfProducts computes the multi-exponentiation for the specified
 // points and scalars and stores the result in `p`.
+// It uses Pippenger's bucket method with a window chosen by PippengerWindowSize.
 // Returns an error if the lengths of the arguments is not equal.
 func (p *EllipticPoint) SumOfProducts(points []*EllipticPoint, scalars []*Field) (*EllipticPoint, error) {
-	const Upper = 256
-	const W = 4
-	const Windows = Upper / W // careful--use ceiling division in case this doesn't divide evenly
+	return p.sumOfProducts(points, scalars, false)
+}
+
+// sumOfProducts is Pippenger's bucket method, which skips the additions
+// that do not change the result when `varTime` is true
+func (p *EllipticPoint) sumOfProducts(points []*EllipticPoint, scalars []*Field, varTime bool) (*EllipticPoint, error) {
 	if len(points) != len(scalars) {
 		return nil, fmt.Errorf("length mismatch")
 	}
 
-	bucketSize := 1 << W
-	windows := make([]*EllipticPoint, Windows)
+	w := PippengerWindowSize(len(points))
+	windowCount := (FieldBytes*8 + w - 1) / w
+	bucketSize := 1 << uint(w)
+	windows := make([]*EllipticPoint, windowCount)
 	bytes := make([][32]byte, len(scalars))
 	buckets := make([]*EllipticPoint, bucketSize)
 
@@ -440,11 +489,12 @@ func (p *EllipticPoint) SumOfProducts(points []*EllipticPoint, scalars []*Field)
 		}
 
 		for i := 0; i < len(scalars); i++ {
-			// j*W to get the nibble
-			// >> 3 to convert to byte, / 8
-			// (W * j & W) gets the nibble, mod W
-			// 1 << W - 1 to get the offset
-			index := bytes[i][j*W>>3] >> (W * j & W) & (1<<W - 1) // little-endian
+			// bucket 0 is never used but is still added to so
+			// every point costs the same regardless of its scalar
+			index := ScalarWindow(bytes[i][:], j*w, w) // little-endian
+			if varTime && index == 0 {
+				continue
+			}
 			buckets[index].Add(buckets[index], points[i])
 		}
 
@@ -458,7 +508,7 @@ func (p *EllipticPoint) SumOfProducts(points []*EllipticPoint, scalars []*Field)
 
 	p.Identity()
 	for i := len(windows) - 1; i >= 0; i-- {
-		for j := 0; j < W; j++ {
+		for j := 0; j < w; j++ {
 			p.Double(p)
 		}
 
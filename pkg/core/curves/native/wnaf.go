@@ -0,0 +1,127 @@
+package native
+
+import "fmt"
+
+// VarTimeWindow is the wNAF width used for variable-time scalar multiplication
+const VarTimeWindow = 5
+
+// maxStrausPoints is the number of points up to which variable-time multi-scalar multiplication
+// interleaves the wNAF of every scalar, above it Pippenger's bucket method is faster
+const maxStrausPoints = 32
+
+// Wnaf returns the width-`w` non-adjacent form of the little-endian scalar `s`, least
+// significant digit first. Non-zero digits are odd and lie in (-2^(w-1), 2^(w-1)), and any
+// w consecutive digits contain at most one of them. The output ends with the most significant
+// non-zero digit, so it is empty for zero and has at most one digit more than `s` has bits.
+//
+// The computation depends on the value of the scalar, so it must only be used with public scalars
+func Wnaf(s []byte, w int) []int8 {
+	width := 1 << uint(w)
+	digits := make([]int8, len(s)*8+1)
+	carry := 0
+	for pos := 0; pos < len(digits); {
+		window := carry + ScalarWindow(s, pos, w)
+		if window&1 == 0 {
+			pos++
+			continue
+		}
+		if window < width/2 {
+			carry = 0
+			digits[pos] = int8(window)
+		} else {
+			carry = 1
+			digits[pos] = int8(window - width)
+		}
+		pos += w
+	}
+	top := len(digits)
+	for top > 0 && digits[top-1] == 0 {
+		top--
+	}
+	return digits[:top]
+}
+
+// MaxLength returns the length of the longest wNAF
+func MaxLength(digits [][]int8) int {
+	n := 0
+	for _, d := range digits {
+		if len(d) > n {
+			n = len(d)
+		}
+	}
+	return n
+}
+
+// UseStraus returns true if a variable-time multi-scalar multiplication of `n` points
+// should interleave wNAFs rather than use buckets
+func UseStraus(n int) bool {
+	return n <= maxStrausPoints
+}
+
+// VarTimeMul multiplies the point by the scalar in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (p *EllipticPoint) VarTimeMul(point *EllipticPoint, scalar *Field) *EllipticPoint {
+	bytes := scalar.Bytes()
+	digits := Wnaf(bytes[:], VarTimeWindow)
+	table := p.oddMultiples(point)
+	p.Identity()
+	for i := len(digits) - 1; i >= 0; i-- {
+		p.Arithmetic.Double(p, p)
+		p.addDigit(table, digits[i])
+	}
+	return p
+}
+
+// VarTimeSumOfProducts computes the multi-exponentiation for the specified points and
+// scalars in variable time and stores the result in `p`. It must only be used with public scalars.
+// Returns an error if the lengths of the arguments is not equal.
+func (p *EllipticPoint) VarTimeSumOfProducts(points []*EllipticPoint, scalars []*Field) (*EllipticPoint, error) {
+	if !UseStraus(len(points)) {
+		return p.sumOfProducts(points, scalars, true)
+	}
+	if len(points) != len(scalars) {
+		return nil, fmt.Errorf("length mismatch")
+	}
+	digits := make([][]int8, len(points))
+	tables := make([][]*EllipticPoint, len(points))
+	for i, point := range points {
+		bytes := scalars[i].Bytes()
+		digits[i] = Wnaf(bytes[:], VarTimeWindow)
+		tables[i] = p.oddMultiples(point)
+	}
+	p.Identity()
+	for j := MaxLength(digits) - 1; j >= 0; j-- {
+		p.Arithmetic.Double(p, p)
+		for i := range points {
+			if j < len(digits[i]) {
+				p.addDigit(tables[i], digits[i][j])
+			}
+		}
+	}
+	return p, nil
+}
+
+// oddMultiples returns point, 3*point, ..., (2^(VarTimeWindow-1)-1)*point followed by their negations
+func (p *EllipticPoint) oddMultiples(point *EllipticPoint) []*EllipticPoint {
+	n := 1 << (VarTimeWindow - 2)
+	table := make([]*EllipticPoint, 2*n)
+	double := new(EllipticPoint).Set(point).Double(point)
+	table[0] = new(EllipticPoint).Set(point)
+	for i := 1; i < n; i++ {
+		table[i] = new(EllipticPoint).Set(point).Add(table[i-1], double)
+	}
+	for i := 0; i < n; i++ {
+		table[n+i] = new(EllipticPoint).Neg(table[i])
+	}
+	return table
+}
+
+// addDigit adds the multiple of a wNAF digit from the table of odd multiples.
+// It calls the point arithmetic directly as the digits are public
+func (p *EllipticPoint) addDigit(table []*EllipticPoint, digit int8) {
+	if digit > 0 {
+		p.Arithmetic.Add(p, p, table[digit/2])
+	} else if digit < 0 {
+		p.Arithmetic.Add(p, p, table[len(table)/2-int(digit/2)])
+	}
+}
@@ -0,0 +1,45 @@
+package native
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWnaf(t *testing.T) {
+	inputs := [][]byte{make([]byte, 32), {1}, {0xff, 0xff, 0xff, 0xff}}
+	for i := 0; i < 20; i++ {
+		s := make([]byte, 32)
+		_, _ = crand.Read(s)
+		inputs = append(inputs, s)
+	}
+	for _, s := range inputs {
+		for w := 2; w <= 6; w++ {
+			digits := Wnaf(s, w)
+			require.LessOrEqual(t, len(digits), len(s)*8+1)
+			if len(digits) > 0 {
+				require.NotZero(t, digits[len(digits)-1])
+			}
+			value := new(big.Int)
+			last := len(digits) + w
+			for i := len(digits) - 1; i >= 0; i-- {
+				value.Lsh(value, 1)
+				value.Add(value, big.NewInt(int64(digits[i])))
+				if digits[i] != 0 {
+					require.Equal(t, int8(1), digits[i]&1)
+					require.Less(t, int(digits[i]), 1<<uint(w-1))
+					require.Greater(t, int(digits[i]), -(1 << uint(w-1)))
+					require.GreaterOrEqual(t, last-i, w)
+					last = i
+				}
+			}
+			le := make([]byte, len(s))
+			for i := range s {
+				le[len(s)-1-i] = s[i]
+			}
+			require.Equal(t, 0, value.Cmp(new(big.Int).SetBytes(le)))
+		}
+	}
+}
@@ -499,6 +499,14 @@ func (p *BenchPointP256) IsOnCurve() bool {
 	return elliptic.P256().IsOnCurve(p.x, p.y)
 }
 
+func (p *BenchPointP256) IsInPrimeSubgroup() bool {
+	return p.IsOnCurve()
+}
+
+func (p *BenchPointP256) ClearCofactor() Point {
+	return &BenchPointP256{x: new(big.Int).Set(p.x), y: new(big.Int).Set(p.y)}
+}
+
 func (p *BenchPointP256) Double() Point {
 	curve := elliptic.P256()
 	x, y := curve.Double(p.x, p.y)
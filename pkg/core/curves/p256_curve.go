@@ -20,6 +20,10 @@ import (
 	"github.com/etclab/kryptology/pkg/core/curves/native/p256/fq"
 )
 
+func init() {
+	mustRegisterCurve(P256Name, OidP256, P256)
+}
+
 var oldP256InitOnce sync.Once
 var oldP256 NistP256
 
@@ -156,12 +160,14 @@ func (s *ScalarP256) IsEven() bool {
 
 func (s *ScalarP256) New(value int) Scalar {
 	t := fq.P256FqNew()
-	v := big.NewInt(int64(value))
 	if value < 0 {
-		v.Mod(v, t.Params.BiModulus)
+		t.SetUint64(uint64(-int64(value)))
+		t.Neg(t)
+	} else {
+		t.SetUint64(uint64(value))
 	}
 	return &ScalarP256{
-		value: t.SetBigInt(v),
+		value: t,
 	}
 }
 
@@ -410,14 +416,25 @@ func (p *PointP256) IsIdentity() bool {
 	return p.value.IsIdentity()
 }
 
+// IsNegative returns true if the affine Y coordinate is odd
 func (p *PointP256) IsNegative() bool {
-	return p.value.GetY().Value[0]&1 == 1
+	return p.value.GetY().Bytes()[0]&1 == 1
 }
 
 func (p *PointP256) IsOnCurve() bool {
 	return p.value.IsOnCurve()
 }
 
+// IsInPrimeSubgroup reports whether the point is on the curve, whose group has prime order
+func (p *PointP256) IsInPrimeSubgroup() bool {
+	return p.IsIdentity() || p.IsOnCurve()
+}
+
+// ClearCofactor returns a copy of the point, as the cofactor is 1
+func (p *PointP256) ClearCofactor() Point {
+	return &PointP256{p256n.P256PointNew().Set(p.value)}
+}
+
 func (p *PointP256) Double() Point {
 	value := p256n.P256PointNew().Double(p.value)
 	return &PointP256{value}
@@ -533,8 +550,11 @@ func (p *PointP256) FromAffineCompressed(bytes []byte) (Point, error) {
 	rhs := fp.P256FpNew()
 	p.value.Arithmetic.RhsEq(rhs, x)
 	// test that rhs is quadratic residue
-	// if not, then this Point is at infinity
+	// if not, x must be zero which encodes the point at infinity
 	y, wasQr := fp.P256FpNew().Sqrt(rhs)
+	if !wasQr && x.IsZero() == 0 {
+		return nil, fmt.Errorf("invalid x coordinate")
+	}
 	if wasQr {
 		// fix the sign
 		sigY := int(y.Bytes()[0] & 1)
@@ -568,9 +588,16 @@ func (p *PointP256) FromAffineUncompressed(bytes []byte) (Point, error) {
 		return nil, err
 	}
 	value := p256n.P256PointNew()
+	// All zero coordinates encode the identity
+	if x.IsZero()&y.IsZero() == 1 {
+		return &PointP256{value.Identity()}, nil
+	}
 	value.X = x
 	value.Y = y
 	value.Z.SetOne()
+	if !value.IsOnCurve() {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
 	return &PointP256{value}, nil
 }
 
@@ -579,28 +606,63 @@ func (p *PointP256) CurveName() string {
 }
 
 func (p *PointP256) SumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := p256Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value := p256n.P256PointNew()
+	_, err := value.SumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointP256{value}
+}
+
+// VarTimeMul multiplies the point by `rhs` in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (p *PointP256) VarTimeMul(rhs Scalar) Point {
+	r, ok := rhs.(*ScalarP256)
+	if !ok {
+		return nil
+	}
+	value := p256n.P256PointNew().VarTimeMul(p.value, r.value)
+	return &PointP256{value}
+}
+
+// VarTimeSumOfProducts computes the multi-scalar multiplication of `points` and `scalars`
+// in variable time. It must only be used with public scalars, such as when verifying proofs
+func (p *PointP256) VarTimeSumOfProducts(points []Point, scalars []Scalar) Point {
+	nPoints, nScalars, ok := p256Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	value := p256n.P256PointNew()
+	_, err := value.VarTimeSumOfProducts(nPoints, nScalars)
+	if err != nil {
+		return nil
+	}
+	return &PointP256{value}
+}
+
+// p256Batch converts `points` and `scalars` to their native values
+func p256Batch(points []Point, scalars []Scalar) ([]*native.EllipticPoint, []*native.Field, bool) {
 	nPoints := make([]*native.EllipticPoint, len(points))
 	nScalars := make([]*native.Field, len(scalars))
 	for i, pt := range points {
 		ptv, ok := pt.(*PointP256)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nPoints[i] = ptv.value
 	}
 	for i, sc := range scalars {
 		s, ok := sc.(*ScalarP256)
 		if !ok {
-			return nil
+			return nil, nil, false
 		}
 		nScalars[i] = s.value
 	}
-	value := p256n.P256PointNew()
-	_, err := value.SumOfProducts(nPoints, nScalars)
-	if err != nil {
-		return nil
-	}
-	return &PointP256{value}
+	return nPoints, nScalars, true
 }
 
 func (p *PointP256) X() *native.Field {
@@ -65,6 +65,9 @@ func TestScalarP256New(t *testing.T) {
 	require.True(t, neg1.IsEven())
 	neg2 := p256.Scalar.New(-2)
 	require.True(t, neg2.IsOdd())
+	n := elliptic.P256().Params().N
+	require.Equal(t, new(big.Int).Sub(n, big.NewInt(333333)), p256.Scalar.New(-333333).BigInt())
+	require.Equal(t, big.NewInt(1<<40), p256.Scalar.New(1<<40).BigInt())
 }
 
 func TestScalarP256Square(t *testing.T) {
@@ -363,3 +366,24 @@ func TestPointP256SumOfProducts(t *testing.T) {
 	require.NotNil(t, rhs)
 	require.True(t, lhs.Equal(rhs))
 }
+
+func TestP256ScalarNative(t *testing.T) {
+	n := elliptic.P256().Params().N
+	k := NewP256Scalar()
+	x, err := k.Random()
+	require.NoError(t, err)
+	y, err := k.Random()
+	require.NoError(t, err)
+	require.True(t, k.IsValid(x))
+	require.False(t, k.IsValid(n))
+	require.False(t, k.IsValid(big.NewInt(-1)))
+
+	mod := func(v *big.Int) *big.Int { return v.Mod(v, n) }
+	require.Equal(t, mod(new(big.Int).Add(x, y)), k.Add(x, y))
+	require.Equal(t, mod(new(big.Int).Sub(x, y)), k.Sub(x, y))
+	require.Equal(t, mod(new(big.Int).Neg(x)), k.Neg(x))
+	require.Equal(t, mod(new(big.Int).Mul(x, y)), k.Mul(x, y))
+	require.Equal(t, x, k.Mul(k.Div(x, y), y))
+	// Unreduced inputs are reduced
+	require.Equal(t, k.Add(x, y), k.Add(new(big.Int).Add(x, n), y))
+}
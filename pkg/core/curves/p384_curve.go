@@ -0,0 +1,548 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+	p384n "github.com/etclab/kryptology/pkg/core/curves/native/p384"
+)
+
+func init() {
+	mustRegisterCurve(P384Name, OidP384, P384)
+}
+
+// p384ScalarHashBytes is L of hash_to_field for the scalar field, ceil((384 + 192) / 8)
+const p384ScalarHashBytes = 72
+
+type ScalarP384 struct {
+	value *p384n.Scalar
+}
+
+type PointP384 struct {
+	value *p384n.Point
+}
+
+func (s *ScalarP384) Random(reader io.Reader) Scalar {
+	if reader == nil {
+		return nil
+	}
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return s.Hash(seed[:])
+}
+
+func (s *ScalarP384) Hash(bytes []byte) Scalar {
+	xmd := native.ExpandMsgXmd(native.EllipticPointHasherSha384(), bytes, []byte(p384n.SuiteId), p384ScalarHashBytes)
+	value, err := new(p384n.Scalar).SetBytesWide(xmd)
+	if err != nil {
+		return nil
+	}
+	return &ScalarP384{value}
+}
+
+func (s *ScalarP384) Zero() Scalar {
+	return &ScalarP384{
+		value: new(p384n.Scalar).Zero(),
+	}
+}
+
+func (s *ScalarP384) One() Scalar {
+	return &ScalarP384{
+		value: new(p384n.Scalar).One(),
+	}
+}
+
+func (s *ScalarP384) IsZero() bool {
+	return s.value.IsZero() == 1
+}
+
+func (s *ScalarP384) IsOne() bool {
+	return s.value.Equal(new(p384n.Scalar).One()) == 1
+}
+
+func (s *ScalarP384) IsOdd() bool {
+	return s.value.IsOdd() == 1
+}
+
+func (s *ScalarP384) IsEven() bool {
+	return s.value.IsOdd() == 0
+}
+
+func (s *ScalarP384) New(value int) Scalar {
+	return &ScalarP384{
+		value: new(p384n.Scalar).SetBigInt(big.NewInt(int64(value))),
+	}
+}
+
+func (s *ScalarP384) Cmp(rhs Scalar) int {
+	r, ok := rhs.(*ScalarP384)
+	if ok {
+		return s.value.BigInt().Cmp(r.value.BigInt())
+	} else {
+		return -2
+	}
+}
+
+func (s *ScalarP384) Square() Scalar {
+	return &ScalarP384{
+		value: new(p384n.Scalar).Mul(s.value, s.value),
+	}
+}
+
+func (s *ScalarP384) Double() Scalar {
+	return &ScalarP384{
+		value: new(p384n.Scalar).Add(s.value, s.value),
+	}
+}
+
+func (s *ScalarP384) Invert() (Scalar, error) {
+	value, wasInverted := new(p384n.Scalar).Invert(s.value)
+	if !wasInverted {
+		return nil, fmt.Errorf("inverse doesn't exist")
+	}
+	return &ScalarP384{
+		value,
+	}, nil
+}
+
+func (s *ScalarP384) Sqrt() (Scalar, error) {
+	value, wasSquare := new(p384n.Scalar).Sqrt(s.value)
+	if !wasSquare {
+		return nil, fmt.Errorf("not a square")
+	}
+	return &ScalarP384{
+		value,
+	}, nil
+}
+
+func (s *ScalarP384) Cube() Scalar {
+	value := new(p384n.Scalar).Mul(s.value, s.value)
+	value.Mul(value, s.value)
+	return &ScalarP384{
+		value,
+	}
+}
+
+func (s *ScalarP384) Add(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarP384)
+	if ok {
+		return &ScalarP384{
+			value: new(p384n.Scalar).Add(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarP384) Sub(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarP384)
+	if ok {
+		return &ScalarP384{
+			value: new(p384n.Scalar).Sub(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarP384) Mul(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarP384)
+	if ok {
+		return &ScalarP384{
+			value: new(p384n.Scalar).Mul(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarP384) MulAdd(y, z Scalar) Scalar {
+	return s.Mul(y).Add(z)
+}
+
+func (s *ScalarP384) Div(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarP384)
+	if ok {
+		v, wasInverted := new(p384n.Scalar).Invert(r.value)
+		if !wasInverted {
+			return nil
+		}
+		v.Mul(v, s.value)
+		return &ScalarP384{value: v}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarP384) Neg() Scalar {
+	return &ScalarP384{
+		value: new(p384n.Scalar).Neg(s.value),
+	}
+}
+
+func (s *ScalarP384) SetBigInt(v *big.Int) (Scalar, error) {
+	if v == nil {
+		return nil, fmt.Errorf("'v' cannot be nil")
+	}
+	return &ScalarP384{
+		value: new(p384n.Scalar).SetBigInt(v),
+	}, nil
+}
+
+func (s *ScalarP384) BigInt() *big.Int {
+	return s.value.BigInt()
+}
+
+// Bytes returns the 48-byte big-endian encoding of SEC 1
+func (s *ScalarP384) Bytes() []byte {
+	t := s.value.Bytes()
+	return t[:]
+}
+
+// SetBytes expects the canonical 48-byte big-endian encoding of SEC 1
+func (s *ScalarP384) SetBytes(bytes []byte) (Scalar, error) {
+	if len(bytes) != p384n.ScalarBytes {
+		return nil, fmt.Errorf("invalid length")
+	}
+	value, err := new(p384n.Scalar).SetBytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarP384{
+		value,
+	}, nil
+}
+
+// SetBytesWide reduces 96 big-endian bytes modulo the group order. It also accepts
+// the 64 bytes taken by the other curves, whose reduction has a bias of 2^-128
+func (s *ScalarP384) SetBytesWide(bytes []byte) (Scalar, error) {
+	if len(bytes) != p384n.WideScalarBytes && len(bytes) != WideScalarBytes {
+		return nil, fmt.Errorf("invalid length")
+	}
+	value, err := new(p384n.Scalar).SetBytesWide(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarP384{
+		value,
+	}, nil
+}
+
+func (s *ScalarP384) Point() Point {
+	return new(PointP384).Identity()
+}
+
+func (s *ScalarP384) Clone() Scalar {
+	return &ScalarP384{
+		value: new(p384n.Scalar).Set(s.value),
+	}
+}
+
+func (s *ScalarP384) MarshalBinary() ([]byte, error) {
+	return scalarMarshalBinary(s)
+}
+
+func (s *ScalarP384) UnmarshalBinary(input []byte) error {
+	sc, err := scalarUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarP384)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarP384) MarshalText() ([]byte, error) {
+	return scalarMarshalText(s)
+}
+
+func (s *ScalarP384) UnmarshalText(input []byte) error {
+	sc, err := scalarUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarP384)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarP384) MarshalJSON() ([]byte, error) {
+	return scalarMarshalJson(s)
+}
+
+func (s *ScalarP384) UnmarshalJSON(input []byte) error {
+	sc, err := scalarUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	S, ok := sc.(*ScalarP384)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	s.value = S.value
+	return nil
+}
+
+func (p *PointP384) Random(reader io.Reader) Point {
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return p.Hash(seed[:])
+}
+
+// Hash maps `bytes` to the curve with P384_XMD:SHA-384_SSWU_RO_ using the suite
+// identifier as the domain separation tag
+func (p *PointP384) Hash(bytes []byte) Point {
+	value := new(p384n.Point).Hash(native.EllipticPointHasherSha384(), bytes, []byte(p384n.SuiteId))
+	return &PointP384{value}
+}
+
+func (p *PointP384) Identity() Point {
+	return &PointP384{
+		value: new(p384n.Point).Identity(),
+	}
+}
+
+func (p *PointP384) Generator() Point {
+	return &PointP384{
+		value: new(p384n.Point).Generator(),
+	}
+}
+
+func (p *PointP384) IsIdentity() bool {
+	return p.value.IsIdentity() == 1
+}
+
+// IsNegative returns true if the affine y coordinate is odd
+func (p *PointP384) IsNegative() bool {
+	return p.value.ToCompressed()[0] == 3
+}
+
+func (p *PointP384) IsOnCurve() bool {
+	return p.value.IsOnCurve() == 1
+}
+
+// IsInPrimeSubgroup reports whether the point is on the curve, whose group has prime order
+func (p *PointP384) IsInPrimeSubgroup() bool {
+	return p.IsIdentity() || p.IsOnCurve()
+}
+
+// ClearCofactor returns a copy of the point, as the cofactor is 1
+func (p *PointP384) ClearCofactor() Point {
+	return &PointP384{value: new(p384n.Point).Set(p.value)}
+}
+
+func (p *PointP384) Double() Point {
+	return &PointP384{value: new(p384n.Point).Double(p.value)}
+}
+
+func (p *PointP384) Scalar() Scalar {
+	return new(ScalarP384).Zero()
+}
+
+func (p *PointP384) Neg() Point {
+	return &PointP384{value: new(p384n.Point).Neg(p.value)}
+}
+
+func (p *PointP384) Add(rhs Point) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*PointP384)
+	if ok {
+		return &PointP384{value: new(p384n.Point).Add(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointP384) Sub(rhs Point) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*PointP384)
+	if ok {
+		return &PointP384{value: new(p384n.Point).Sub(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointP384) Mul(rhs Scalar) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*ScalarP384)
+	if ok {
+		return &PointP384{value: new(p384n.Point).Mul(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointP384) Equal(rhs Point) bool {
+	r, ok := rhs.(*PointP384)
+	if ok {
+		return p.value.Equal(r.value) == 1
+	} else {
+		return false
+	}
+}
+
+func (p *PointP384) Set(x, y *big.Int) (Point, error) {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return p.Identity(), nil
+	}
+	value, err := new(p384n.Point).SetBigInt(x, y)
+	if err != nil {
+		return nil, err
+	}
+	return &PointP384{value}, nil
+}
+
+// ToAffineCompressed returns the 49-byte compressed encoding of SEC 1
+func (p *PointP384) ToAffineCompressed() []byte {
+	out := p.value.ToCompressed()
+	return out[:]
+}
+
+// ToAffineUncompressed returns the 97-byte uncompressed encoding of SEC 1
+func (p *PointP384) ToAffineUncompressed() []byte {
+	out := p.value.ToUncompressed()
+	return out[:]
+}
+
+func (p *PointP384) FromAffineCompressed(bytes []byte) (Point, error) {
+	var input [p384n.CompressedBytes]byte
+	if len(bytes) != p384n.CompressedBytes {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	copy(input[:], bytes)
+	value, err := new(p384n.Point).FromCompressed(&input)
+	if err != nil {
+		return nil, err
+	}
+	return &PointP384{value}, nil
+}
+
+func (p *PointP384) FromAffineUncompressed(bytes []byte) (Point, error) {
+	var input [p384n.UncompressedBytes]byte
+	if len(bytes) != p384n.UncompressedBytes {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	copy(input[:], bytes)
+	value, err := new(p384n.Point).FromUncompressed(&input)
+	if err != nil {
+		return nil, err
+	}
+	return &PointP384{value}, nil
+}
+
+func (p *PointP384) CurveName() string {
+	return P384Name
+}
+
+func (p *PointP384) SumOfProducts(points []Point, scalars []Scalar) Point {
+	if len(points) != len(scalars) {
+		return nil
+	}
+	if len(points) == 0 {
+		return p.Identity()
+	}
+	nScalars := make([]*big.Int, len(scalars))
+	for i, sc := range scalars {
+		if _, ok := points[i].(*PointP384); !ok {
+			return nil
+		}
+		s, ok := sc.(*ScalarP384)
+		if !ok {
+			return nil
+		}
+		nScalars[i] = s.BigInt()
+	}
+	return sumOfProductsPippengerBits(points, nScalars, 384)
+}
+
+func (p *PointP384) MarshalBinary() ([]byte, error) {
+	return pointMarshalBinary(p)
+}
+
+func (p *PointP384) UnmarshalBinary(input []byte) error {
+	pt, err := pointUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointP384)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointP384) MarshalText() ([]byte, error) {
+	return pointMarshalText(p)
+}
+
+func (p *PointP384) UnmarshalText(input []byte) error {
+	pt, err := pointUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointP384)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointP384) MarshalJSON() ([]byte, error) {
+	return pointMarshalJson(p)
+}
+
+func (p *PointP384) UnmarshalJSON(input []byte) error {
+	pt, err := pointUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	P, ok := pt.(*PointP384)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	p.value = P.value
+	return nil
+}
+
+// GetP384Point returns the underlying point of the native implementation
+func (p *PointP384) GetP384Point() *p384n.Point {
+	return new(p384n.Point).Set(p.value)
+}
+
+// SetP384Point sets the underlying point of the native implementation
+func (p *PointP384) SetP384Point(pt *p384n.Point) *PointP384 {
+	return &PointP384{value: new(p384n.Point).Set(pt)}
+}
+
+// GetP384Scalar returns the underlying scalar of the native implementation
+func (s *ScalarP384) GetP384Scalar() *p384n.Scalar {
+	return new(p384n.Scalar).Set(s.value)
+}
+
+// SetP384Scalar sets the underlying scalar of the native implementation
+func (s *ScalarP384) SetP384Scalar(sc *p384n.Scalar) *ScalarP384 {
+	return &ScalarP384{value: new(p384n.Scalar).Set(sc)}
+}
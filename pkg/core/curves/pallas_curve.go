@@ -18,10 +18,15 @@ import (
 
 	"golang.org/x/crypto/blake2b"
 
+	"github.com/etclab/kryptology/pkg/core/curves/native"
 	"github.com/etclab/kryptology/pkg/core/curves/native/pasta/fp"
 	"github.com/etclab/kryptology/pkg/core/curves/native/pasta/fq"
 )
 
+func init() {
+	mustRegisterCurve(PallasName, nil, PALLAS)
+}
+
 var b = new(fp.Fp).SetUint64(5)
 var three = &fp.Fp{0x6b0ee5d0fffffff5, 0x86f76d2b99b14bd0, 0xfffffffffffffffe, 0x3fffffffffffffff}
 var eight = &fp.Fp{0x7387134cffffffe1, 0xd973797adfadd5a8, 0xfffffffffffffffb, 0x3fffffffffffffff}
@@ -522,6 +527,16 @@ func (p *PointPallas) IsOnCurve() bool {
 	return p.value.IsOnCurve()
 }
 
+// IsInPrimeSubgroup reports whether the point is on the curve, whose group has prime order
+func (p *PointPallas) IsInPrimeSubgroup() bool {
+	return p.IsIdentity() || p.IsOnCurve()
+}
+
+// ClearCofactor returns a copy of the point, as the cofactor is 1
+func (p *PointPallas) ClearCofactor() Point {
+	return &PointPallas{new(Ep).Set(p.value)}
+}
+
 func (p *PointPallas) Double() Point {
 	return &PointPallas{new(Ep).Double(p.value)}
 }
@@ -624,6 +639,9 @@ func (p *PointPallas) SumOfProducts(points []Point, scalars []Scalar) Point {
 		eps[i] = ps.value
 	}
 	value := p.value.SumOfProducts(eps, scalars)
+	if value == nil {
+		return nil
+	}
 	return &PointPallas{value}
 }
 
@@ -703,11 +721,22 @@ func (p *Ep) Random(reader io.Reader) *Ep {
 }
 
 func (p *Ep) Hash(bytes []byte) *Ep {
+	return p.hashWithDst(bytes, []byte(pallasSuiteId))
+}
+
+// hashWithDst maps bytes to the curve with the domain separation tag `dst`
+func (p *Ep) hashWithDst(bytes, dst []byte) *Ep {
 	if bytes == nil {
 		bytes = []byte{}
 	}
 	h, _ := blake2b.New(64, []byte{})
-	u, _ := expandMsgXmd(h, bytes, []byte("pallas_XMD:BLAKE2b_SSWU_RO_"), 128)
+	if len(dst) > native.MaxDstLen {
+		_, _ = h.Write(native.OversizeDstSalt)
+		_, _ = h.Write(dst)
+		dst = h.Sum(nil)
+		h.Reset()
+	}
+	u, _ := expandMsgXmd(h, bytes, dst, 128)
 	var buf [64]byte
 	copy(buf[:], u[:64])
 	u0 := new(fp.Fp).SetBytesWide(&buf)
@@ -914,8 +943,12 @@ func (p *Ep) FromAffineCompressed(bytes []byte) (*Ep, error) {
 		return nil, fmt.Errorf("invalid byte sequence")
 	}
 
-	var input [32]byte
+	var input, inf [32]byte
 	copy(input[:], bytes)
+	// ZCash encoding of infinity
+	if input == inf {
+		return p.Identity(), nil
+	}
 	sign := (input[31] >> 7) & 1
 	input[31] &= 0x7F
 
@@ -1025,9 +1058,9 @@ func sumOfProductsPippengerPallas(points []*Ep, scalars []*big.Int) *Ep {
 		return nil
 	}
 
-	const w = 6
+	w := native.PippengerWindowSize(len(points))
 
-	bucketSize := (1 << w) - 1
+	bucketSize := (1 << uint(w)) - 1
 	windows := make([]*Ep, 255/w+1)
 	for i := range windows {
 		windows[i] = new(Ep).Identity()
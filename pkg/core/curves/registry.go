@@ -0,0 +1,124 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Object identifiers of the curves that have one
+var (
+	// OidSecp256k1 is secp256k1 from SEC 2
+	OidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	// OidP256 is prime256v1 from RFC 5480
+	OidP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	// OidP384 is secp384r1 from RFC 5480
+	OidP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	// OidEd25519 is id-Ed25519 from RFC 8410
+	OidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+	// OidEd448 is id-Ed448 from RFC 8410
+	OidEd448 = asn1.ObjectIdentifier{1, 3, 101, 113}
+)
+
+type registration struct {
+	name        string
+	oid         asn1.ObjectIdentifier
+	constructor func() *Curve
+}
+
+var registry = struct {
+	sync.RWMutex
+	byName map[string]*registration
+	byOid  map[string]*registration
+}{
+	byName: make(map[string]*registration),
+	byOid:  make(map[string]*registration),
+}
+
+// RegisterCurve makes a curve available to GetCurveByName and GetCurveByOid,
+// and therefore to the decoding of curve-tagged points and scalars. `oid` may be nil
+// for curves without an object identifier. Names and object identifiers can only be
+// registered once. Curves usually register themselves in an init function
+func RegisterCurve(name string, oid asn1.ObjectIdentifier, constructor func() *Curve) error {
+	if name == "" || constructor == nil {
+		return fmt.Errorf("invalid curve registration")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.byName[name]; ok {
+		return fmt.Errorf("curve %s is already registered", name)
+	}
+	r := &registration{name: name, constructor: constructor}
+	if len(oid) > 0 {
+		key := oid.String()
+		if other, ok := registry.byOid[key]; ok {
+			return fmt.Errorf("object identifier %s is already registered to %s", key, other.name)
+		}
+		r.oid = append(asn1.ObjectIdentifier{}, oid...)
+		registry.byOid[key] = r
+	}
+	registry.byName[name] = r
+	return nil
+}
+
+// mustRegisterCurve registers a built-in curve
+func mustRegisterCurve(name string, oid asn1.ObjectIdentifier, constructor func() *Curve) {
+	if err := RegisterCurve(name, oid, constructor); err != nil {
+		panic(err)
+	}
+}
+
+// GetCurveByName returns the correct `Curve` given the name
+func GetCurveByName(name string) *Curve {
+	registry.RLock()
+	r, ok := registry.byName[name]
+	registry.RUnlock()
+	if !ok {
+		return nil
+	}
+	return r.constructor()
+}
+
+// GetCurveByOid returns the curve registered with the object identifier `oid`
+func GetCurveByOid(oid asn1.ObjectIdentifier) *Curve {
+	registry.RLock()
+	r, ok := registry.byOid[oid.String()]
+	registry.RUnlock()
+	if !ok {
+		return nil
+	}
+	return r.constructor()
+}
+
+// CurveOid returns the object identifier registered for the curve named `name`
+func CurveOid(name string) (asn1.ObjectIdentifier, error) {
+	registry.RLock()
+	r, ok := registry.byName[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unrecognized curve %s", name)
+	}
+	if r.oid == nil {
+		return nil, fmt.Errorf("curve %s has no object identifier", name)
+	}
+	return append(asn1.ObjectIdentifier{}, r.oid...), nil
+}
+
+// RegisteredCurves returns the sorted names of all registered curves, including aliases
+func RegisteredCurves() []string {
+	registry.RLock()
+	names := make([]string, 0, len(registry.byName))
+	for name := range registry.byName {
+		names = append(names, name)
+	}
+	registry.RUnlock()
+	sort.Strings(names)
+	return names
+}
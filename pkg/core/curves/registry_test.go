@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryBuiltinCurves(t *testing.T) {
+	names := RegisteredCurves()
+	for _, curve := range []*Curve{
+		K256(), P256(), ED25519(), ED448(), PALLAS(), RISTRETTO255(),
+		BLS12381G1(), BLS12381G2(), BLS12377G1(), BLS12377G2(), BN254G1(), BN254G2(),
+	} {
+		require.Contains(t, names, curve.Name)
+		require.Equal(t, curve, GetCurveByName(curve.Name))
+	}
+	require.Equal(t, BLS12381G1Name, GetCurveByName(BLS12831Name).Name)
+	require.Equal(t, BN254G1Name, GetCurveByName(BN254Name).Name)
+	require.Nil(t, GetCurveByName("unknown"))
+
+	for name, oid := range map[string]asn1.ObjectIdentifier{
+		K256Name:    OidSecp256k1,
+		P256Name:    OidP256,
+		ED25519Name: OidEd25519,
+		ED448Name:   OidEd448,
+	} {
+		actual, err := CurveOid(name)
+		require.NoError(t, err)
+		require.True(t, oid.Equal(actual))
+		require.Equal(t, name, GetCurveByOid(oid).Name)
+
+		// Object identifiers survive DER, e.g. in the algorithm parameters of a key
+		der, err := asn1.Marshal(oid)
+		require.NoError(t, err)
+		var decoded asn1.ObjectIdentifier
+		_, err = asn1.Unmarshal(der, &decoded)
+		require.NoError(t, err)
+		require.Equal(t, name, GetCurveByOid(decoded).Name)
+	}
+	_, err := CurveOid(PallasName)
+	require.Error(t, err)
+	_, err = CurveOid("unknown")
+	require.Error(t, err)
+	require.Nil(t, GetCurveByOid(asn1.ObjectIdentifier{1, 2, 3}))
+}
+
+func TestRegisterCurve(t *testing.T) {
+	oid := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}
+	require.NoError(t, RegisterCurve("registry-test", oid, K256))
+	require.Equal(t, K256Name, GetCurveByOid(oid).Name)
+	require.NotNil(t, GetCurveByName("registry-test"))
+	require.Contains(t, RegisteredCurves(), "registry-test")
+
+	// Names and object identifiers are unique
+	require.Error(t, RegisterCurve("registry-test", nil, P256))
+	require.Error(t, RegisterCurve(K256Name, nil, K256))
+	require.Error(t, RegisterCurve("registry-test-2", oid, P256))
+	require.Error(t, RegisterCurve("registry-test-3", OidP256, P256))
+	require.Error(t, RegisterCurve("", nil, P256))
+	require.Error(t, RegisterCurve("registry-test-4", nil, nil))
+	require.Nil(t, GetCurveByName("registry-test-2"))
+
+	// The registered identifier cannot be changed through the returned value
+	actual, err := CurveOid("registry-test")
+	require.NoError(t, err)
+	actual[0] = 2
+	require.Equal(t, K256Name, GetCurveByOid(oid).Name)
+}
@@ -0,0 +1,679 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+func init() {
+	mustRegisterCurve(Ristretto255Name, nil, RISTRETTO255)
+}
+
+// Ristretto255 is the prime order group of RFC 9496 built on top of edwards25519.
+// Points are equivalence classes of edwards25519 points with a unique 32-byte encoding
+// so protocols using it need no cofactor handling. Scalars are the same as ed25519 scalars.
+
+type ScalarRistretto255 struct {
+	value *edwards25519.Scalar
+}
+
+type PointRistretto255 struct {
+	value *edwards25519.Point
+}
+
+// ristretto255HashDst is the suite identifier of RFC 9380 for hashing to ristretto255
+var ristretto255HashDst = []byte("ristretto255_XMD:SHA-512_R255MAP_RO_")
+
+var (
+	r255D              = r255Constant("a3785913ca4deb75abd841414d0a700098e879777940c78c73fe6f2bee6c0352")
+	r255SqrtM1         = r255Constant("b0a00e4a271beec478e42fad0618432fa7d7fb3d99004d2b0bdfc14f8024832b")
+	r255SqrtADMinusOne = r255Constant("1b2e7b49a0f6977ebd54781b0c8e9daffdd1f531c9fc3c0fac48832bbf316937")
+	r255InvSqrtAMinusD = r255Constant("ea405d80aafdc899be72415a17162f9d40d801fe917bc216a2fcafcf05896c78")
+	r255OneMinusDSq    = r255Constant("76c15f94c1097ce20f355ecd38a1812ce4df70beddab9499d7e0b3b2a8729002")
+	r255DMinusOneSq    = r255Constant("204ded44aa5aad3199191eb02c4a9ed2eb4e9b522fd3dc4c41226cf67ab36859")
+	r255One            = new(field.Element).One()
+)
+
+func r255Constant(h string) *field.Element {
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		panic(err)
+	}
+	fe, err := new(field.Element).SetBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return fe
+}
+
+func (s *ScalarRistretto255) Random(reader io.Reader) Scalar {
+	if reader == nil {
+		return nil
+	}
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return s.Hash(seed[:])
+}
+
+func (s *ScalarRistretto255) Hash(bytes []byte) Scalar {
+	h := sha512.Sum512(bytes)
+	value, err := edwards25519.NewScalar().SetUniformBytes(h[:])
+	if err != nil {
+		return nil
+	}
+	return &ScalarRistretto255{value}
+}
+
+func (s *ScalarRistretto255) Zero() Scalar {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar(),
+	}
+}
+
+func (s *ScalarRistretto255) One() Scalar {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar().Set(scOne),
+	}
+}
+
+func (s *ScalarRistretto255) IsZero() bool {
+	return s.value.Equal(edwards25519.NewScalar()) == 1
+}
+
+func (s *ScalarRistretto255) IsOne() bool {
+	return s.value.Equal(scOne) == 1
+}
+
+func (s *ScalarRistretto255) IsOdd() bool {
+	return s.value.Bytes()[0]&1 == 1
+}
+
+func (s *ScalarRistretto255) IsEven() bool {
+	return s.value.Bytes()[0]&1 == 0
+}
+
+func (s *ScalarRistretto255) New(input int) Scalar {
+	var data [64]byte
+	i := int64(input)
+	if input < 0 {
+		i = -i
+	}
+	for j := 0; j < 8; j++ {
+		data[j] = byte(i >> (8 * j))
+	}
+	value, err := edwards25519.NewScalar().SetUniformBytes(data[:])
+	if err != nil {
+		return nil
+	}
+	if input < 0 {
+		value.Negate(value)
+	}
+	return &ScalarRistretto255{value}
+}
+
+func (s *ScalarRistretto255) Cmp(rhs Scalar) int {
+	r, ok := rhs.(*ScalarRistretto255)
+	if !ok {
+		return -2
+	}
+	return s.BigInt().Cmp(r.BigInt())
+}
+
+func (s *ScalarRistretto255) Square() Scalar {
+	return &ScalarRistretto255{edwards25519.NewScalar().Multiply(s.value, s.value)}
+}
+
+func (s *ScalarRistretto255) Double() Scalar {
+	return &ScalarRistretto255{edwards25519.NewScalar().Add(s.value, s.value)}
+}
+
+func (s *ScalarRistretto255) Invert() (Scalar, error) {
+	if s.IsZero() {
+		return nil, fmt.Errorf("cannot invert zero")
+	}
+	return &ScalarRistretto255{edwards25519.NewScalar().Invert(s.value)}, nil
+}
+
+func (s *ScalarRistretto255) Sqrt() (Scalar, error) {
+	r, err := (&ScalarEd25519{s.value}).Sqrt()
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarRistretto255{r.(*ScalarEd25519).value}, nil
+}
+
+func (s *ScalarRistretto255) Cube() Scalar {
+	value := edwards25519.NewScalar().Multiply(s.value, s.value)
+	value.Multiply(value, s.value)
+	return &ScalarRistretto255{value}
+}
+
+func (s *ScalarRistretto255) Add(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	return &ScalarRistretto255{edwards25519.NewScalar().Add(s.value, r.value)}
+}
+
+func (s *ScalarRistretto255) Sub(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	return &ScalarRistretto255{edwards25519.NewScalar().Subtract(s.value, r.value)}
+}
+
+func (s *ScalarRistretto255) Mul(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	return &ScalarRistretto255{edwards25519.NewScalar().Multiply(s.value, r.value)}
+}
+
+func (s *ScalarRistretto255) MulAdd(y, z Scalar) Scalar {
+	yy, ok := y.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	zz, ok := z.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	return &ScalarRistretto255{edwards25519.NewScalar().MultiplyAdd(s.value, yy.value, zz.value)}
+}
+
+func (s *ScalarRistretto255) Div(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	value := edwards25519.NewScalar().Invert(r.value)
+	value.Multiply(value, s.value)
+	return &ScalarRistretto255{value}
+}
+
+func (s *ScalarRistretto255) Neg() Scalar {
+	return &ScalarRistretto255{edwards25519.NewScalar().Negate(s.value)}
+}
+
+func (s *ScalarRistretto255) SetBigInt(x *big.Int) (Scalar, error) {
+	r, err := new(ScalarEd25519).SetBigInt(x)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarRistretto255{r.(*ScalarEd25519).value}, nil
+}
+
+func (s *ScalarRistretto255) BigInt() *big.Int {
+	return (&ScalarEd25519{s.value}).BigInt()
+}
+
+func (s *ScalarRistretto255) Bytes() []byte {
+	return s.value.Bytes()
+}
+
+// SetBytes takes a 32-byte little-endian canonical encoding of a scalar
+func (s *ScalarRistretto255) SetBytes(input []byte) (Scalar, error) {
+	if len(input) != 32 {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	value, err := edwards25519.NewScalar().SetCanonicalBytes(input)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarRistretto255{value}, nil
+}
+
+// SetBytesWide reduces a 64-byte little-endian value modulo the group order
+func (s *ScalarRistretto255) SetBytesWide(bytes []byte) (Scalar, error) {
+	value, err := edwards25519.NewScalar().SetUniformBytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarRistretto255{value}, nil
+}
+
+func (s *ScalarRistretto255) Point() Point {
+	return new(PointRistretto255).Identity()
+}
+
+func (s *ScalarRistretto255) Clone() Scalar {
+	return &ScalarRistretto255{edwards25519.NewScalar().Set(s.value)}
+}
+
+func (s *ScalarRistretto255) MarshalBinary() ([]byte, error) {
+	return scalarMarshalBinary(s)
+}
+
+func (s *ScalarRistretto255) UnmarshalBinary(input []byte) error {
+	sc, err := scalarUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarRistretto255) MarshalText() ([]byte, error) {
+	return scalarMarshalText(s)
+}
+
+func (s *ScalarRistretto255) UnmarshalText(input []byte) error {
+	sc, err := scalarUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarRistretto255) MarshalJSON() ([]byte, error) {
+	return scalarMarshalJson(s)
+}
+
+func (s *ScalarRistretto255) UnmarshalJSON(input []byte) error {
+	sc, err := scalarUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (p *PointRistretto255) Random(reader io.Reader) Point {
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return p.FromUniformBytes(seed[:])
+}
+
+// Hash maps `bytes` to the group with hash_to_ristretto255 from RFC 9380 using the
+// ristretto255_XMD:SHA-512_R255MAP_RO_ suite
+func (p *PointRistretto255) Hash(bytes []byte) Point {
+	return p.HashWithDomain(bytes, ristretto255HashDst)
+}
+
+// HashWithDomain maps `bytes` to the group with hash_to_ristretto255 from RFC 9380
+// using `dst` as the domain separation tag
+func (p *PointRistretto255) HashWithDomain(bytes, dst []byte) Point {
+	uniform, err := expandMsgXmd(sha512.New(), bytes, dst, 64)
+	if err != nil {
+		return nil
+	}
+	return p.FromUniformBytes(uniform)
+}
+
+// FromUniformBytes maps 64 uniformly random bytes to the group with the
+// one-way map of RFC 9496 section 4.3.4. It returns nil for other lengths
+func (p *PointRistretto255) FromUniformBytes(bytes []byte) Point {
+	if len(bytes) != 64 {
+		return nil
+	}
+	// SetBytes ignores the most significant bit as required by the map
+	r0, _ := new(field.Element).SetBytes(bytes[:32])
+	r1, _ := new(field.Element).SetBytes(bytes[32:])
+	value := edwards25519.NewIdentityPoint().Add(r255Elligator(r0), r255Elligator(r1))
+	return &PointRistretto255{value}
+}
+
+func (p *PointRistretto255) Identity() Point {
+	return &PointRistretto255{edwards25519.NewIdentityPoint()}
+}
+
+func (p *PointRistretto255) Generator() Point {
+	return &PointRistretto255{edwards25519.NewGeneratorPoint()}
+}
+
+func (p *PointRistretto255) IsIdentity() bool {
+	return p.Equal(p.Identity())
+}
+
+func (p *PointRistretto255) IsNegative() bool {
+	// Group elements have no sign
+	return false
+}
+
+func (p *PointRistretto255) IsOnCurve() bool {
+	// Every value of the type is a valid group element
+	return p.value != nil
+}
+
+// IsInPrimeSubgroup returns true for every element, ristretto255 is a prime order group
+func (p *PointRistretto255) IsInPrimeSubgroup() bool {
+	return p.IsOnCurve()
+}
+
+// ClearCofactor returns a copy of the element, ristretto255 has no cofactor
+func (p *PointRistretto255) ClearCofactor() Point {
+	return &PointRistretto255{edwards25519.NewIdentityPoint().Set(p.value)}
+}
+
+func (p *PointRistretto255) Double() Point {
+	return &PointRistretto255{edwards25519.NewIdentityPoint().Add(p.value, p.value)}
+}
+
+func (p *PointRistretto255) Scalar() Scalar {
+	return new(ScalarRistretto255).Zero()
+}
+
+func (p *PointRistretto255) Neg() Point {
+	return &PointRistretto255{edwards25519.NewIdentityPoint().Negate(p.value)}
+}
+
+func (p *PointRistretto255) Add(rhs Point) Point {
+	r, ok := rhs.(*PointRistretto255)
+	if !ok {
+		return nil
+	}
+	return &PointRistretto255{edwards25519.NewIdentityPoint().Add(p.value, r.value)}
+}
+
+func (p *PointRistretto255) Sub(rhs Point) Point {
+	r, ok := rhs.(*PointRistretto255)
+	if !ok {
+		return nil
+	}
+	return &PointRistretto255{edwards25519.NewIdentityPoint().Subtract(p.value, r.value)}
+}
+
+func (p *PointRistretto255) Mul(rhs Scalar) Point {
+	r, ok := rhs.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	return &PointRistretto255{edwards25519.NewIdentityPoint().ScalarMult(r.value, p.value)}
+}
+
+// Equal compares the equivalence classes of both points as in RFC 9496 section 4.3.3
+func (p *PointRistretto255) Equal(rhs Point) bool {
+	r, ok := rhs.(*PointRistretto255)
+	if !ok {
+		return false
+	}
+	x1, y1, _, _ := p.value.ExtendedCoordinates()
+	x2, y2, _, _ := r.value.ExtendedCoordinates()
+	var a, b field.Element
+	eq := a.Multiply(x1, y2).Equal(b.Multiply(y1, x2))
+	eq |= a.Multiply(y1, y2).Equal(b.Multiply(x1, x2))
+	return eq == 1
+}
+
+// Set is not supported, the affine coordinates of a representative do not identify a group element
+func (p *PointRistretto255) Set(x, y *big.Int) (Point, error) {
+	return nil, fmt.Errorf("ristretto255 points cannot be set from coordinates")
+}
+
+// ToAffineCompressed returns the canonical 32-byte encoding of RFC 9496 section 4.3.2
+func (p *PointRistretto255) ToAffineCompressed() []byte {
+	x0, y0, z0, t0 := p.value.ExtendedCoordinates()
+	var u1, u2, tmp field.Element
+	u1.Multiply(u1.Add(z0, y0), tmp.Subtract(z0, y0))
+	u2.Multiply(x0, y0)
+
+	var invSqrt field.Element
+	tmp.Multiply(&u1, tmp.Square(&u2))
+	invSqrt.SqrtRatio(r255One, &tmp)
+
+	var den1, den2, zInv field.Element
+	den1.Multiply(&invSqrt, &u1)
+	den2.Multiply(&invSqrt, &u2)
+	zInv.Multiply(zInv.Multiply(&den1, &den2), t0)
+
+	var ix0, iy0, enchanted field.Element
+	ix0.Multiply(x0, r255SqrtM1)
+	iy0.Multiply(y0, r255SqrtM1)
+	enchanted.Multiply(&den1, r255InvSqrtAMinusD)
+
+	rotate := tmp.Multiply(t0, &zInv).IsNegative()
+	var x, y, denInv field.Element
+	x.Select(&iy0, x0, rotate)
+	y.Select(&ix0, y0, rotate)
+	denInv.Select(&enchanted, &den2, rotate)
+
+	var yNeg field.Element
+	y.Select(yNeg.Negate(&y), &y, tmp.Multiply(&x, &zInv).IsNegative())
+
+	var s field.Element
+	s.Absolute(s.Multiply(&denInv, tmp.Subtract(z0, &y)))
+	return s.Bytes()
+}
+
+// ToAffineUncompressed returns the same encoding as ToAffineCompressed, the group has no other canonical form
+func (p *PointRistretto255) ToAffineUncompressed() []byte {
+	return p.ToAffineCompressed()
+}
+
+// FromAffineCompressed decodes a canonical 32-byte encoding as in RFC 9496 section 4.3.1.
+// Non-canonical encodings are rejected
+func (p *PointRistretto255) FromAffineCompressed(inBytes []byte) (Point, error) {
+	if len(inBytes) != 32 {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	s, _ := new(field.Element).SetBytes(inBytes)
+	// Reject values that are not reduced, including those with the top bit set, and negative values
+	if subtle.ConstantTimeCompare(s.Bytes(), inBytes) != 1 || s.IsNegative() == 1 {
+		return nil, fmt.Errorf("invalid ristretto255 encoding")
+	}
+
+	var ss, u1, u2, u2Sqr, v, tmp field.Element
+	ss.Square(s)
+	u1.Subtract(r255One, &ss)
+	u2.Add(r255One, &ss)
+	u2Sqr.Square(&u2)
+	// v = -(D * u1^2) - u2^2
+	v.Subtract(v.Negate(v.Multiply(r255D, tmp.Square(&u1))), &u2Sqr)
+
+	var invSqrt field.Element
+	_, wasSquare := invSqrt.SqrtRatio(r255One, tmp.Multiply(&v, &u2Sqr))
+
+	var denX, denY, x, y, t field.Element
+	denX.Multiply(&invSqrt, &u2)
+	denY.Multiply(denY.Multiply(&invSqrt, &denX), &v)
+	x.Absolute(x.Multiply(x.Add(s, s), &denX))
+	y.Multiply(&u1, &denY)
+	t.Multiply(&x, &y)
+
+	if wasSquare == 0 || t.IsNegative() == 1 || y.Equal(new(field.Element).Zero()) == 1 {
+		return nil, fmt.Errorf("invalid ristretto255 encoding")
+	}
+	value, err := edwards25519.NewIdentityPoint().SetExtendedCoordinates(&x, &y, new(field.Element).One(), &t)
+	if err != nil {
+		return nil, err
+	}
+	return &PointRistretto255{value}, nil
+}
+
+// FromAffineUncompressed decodes the output of ToAffineUncompressed
+func (p *PointRistretto255) FromAffineUncompressed(inBytes []byte) (Point, error) {
+	return p.FromAffineCompressed(inBytes)
+}
+
+func (p *PointRistretto255) CurveName() string {
+	return Ristretto255Name
+}
+
+// SumOfProducts computes the multi-scalar multiplication of `points` and `scalars` in constant time
+func (p *PointRistretto255) SumOfProducts(points []Point, scalars []Scalar) Point {
+	nScalars, nPoints, ok := r255Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	return &PointRistretto255{edwards25519.NewIdentityPoint().MultiScalarMult(nScalars, nPoints)}
+}
+
+// VarTimeMul multiplies the point by `rhs` in variable time.
+// It must only be used with public scalars, such as when verifying signatures
+func (p *PointRistretto255) VarTimeMul(rhs Scalar) Point {
+	r, ok := rhs.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	value := edwards25519.NewIdentityPoint().VarTimeDoubleScalarBaseMult(r.value, p.value, edwards25519.NewScalar())
+	return &PointRistretto255{value}
+}
+
+// VarTimeSumOfProducts computes the multi-scalar multiplication of `points` and `scalars`
+// in variable time. It must only be used with public values, such as when verifying proofs
+func (p *PointRistretto255) VarTimeSumOfProducts(points []Point, scalars []Scalar) Point {
+	nScalars, nPoints, ok := r255Batch(points, scalars)
+	if !ok {
+		return nil
+	}
+	return &PointRistretto255{edwards25519.NewIdentityPoint().VarTimeMultiScalarMult(nScalars, nPoints)}
+}
+
+func (p *PointRistretto255) VarTimeDoubleScalarBaseMult(a Scalar, A Point, b Scalar) Point {
+	AA, ok := A.(*PointRistretto255)
+	if !ok {
+		return nil
+	}
+	aa, ok := a.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	bb, ok := b.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	return &PointRistretto255{edwards25519.NewIdentityPoint().VarTimeDoubleScalarBaseMult(aa.value, AA.value, bb.value)}
+}
+
+func (p *PointRistretto255) MarshalBinary() ([]byte, error) {
+	return pointMarshalBinary(p)
+}
+
+func (p *PointRistretto255) UnmarshalBinary(input []byte) error {
+	pt, err := pointUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointRistretto255) MarshalText() ([]byte, error) {
+	return pointMarshalText(p)
+}
+
+func (p *PointRistretto255) UnmarshalText(input []byte) error {
+	pt, err := pointUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointRistretto255) MarshalJSON() ([]byte, error) {
+	return pointMarshalJson(p)
+}
+
+func (p *PointRistretto255) UnmarshalJSON(input []byte) error {
+	pt, err := pointUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	P, ok := pt.(*PointRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	p.value = P.value
+	return nil
+}
+
+// r255Elligator is the MAP function of RFC 9496 section 4.3.4
+func r255Elligator(t *field.Element) *edwards25519.Point {
+	var r, u, v, tmp, minusOne field.Element
+	minusOne.Negate(r255One)
+	r.Multiply(r255SqrtM1, tmp.Square(t))
+	u.Multiply(u.Add(&r, r255One), r255OneMinusDSq)
+	// v = (-1 - r*D) * (r + D)
+	v.Multiply(v.Subtract(&minusOne, v.Multiply(&r, r255D)), tmp.Add(&r, r255D))
+
+	var s, sPrime, c field.Element
+	_, wasSquare := s.SqrtRatio(&u, &v)
+	sPrime.Negate(sPrime.Absolute(sPrime.Multiply(&s, t)))
+	s.Select(&s, &sPrime, wasSquare)
+	c.Select(&minusOne, &r, wasSquare)
+
+	// N = c * (r - 1) * D_MINUS_ONE_SQ - v
+	var n field.Element
+	n.Multiply(n.Multiply(&c, tmp.Subtract(&r, r255One)), r255DMinusOneSq)
+	n.Subtract(&n, &v)
+
+	var w0, w1, w2, w3 field.Element
+	w0.Multiply(w0.Add(&s, &s), &v)
+	w1.Multiply(&n, r255SqrtADMinusOne)
+	tmp.Square(&s)
+	w2.Subtract(r255One, &tmp)
+	w3.Add(r255One, &tmp)
+
+	var x, y, z, tt field.Element
+	x.Multiply(&w0, &w3)
+	y.Multiply(&w2, &w1)
+	z.Multiply(&w1, &w3)
+	tt.Multiply(&w0, &w2)
+	value, err := edwards25519.NewIdentityPoint().SetExtendedCoordinates(&x, &y, &z, &tt)
+	if err != nil {
+		// The map always outputs a point on the curve
+		panic(err)
+	}
+	return value
+}
+
+func r255Batch(points []Point, scalars []Scalar) ([]*edwards25519.Scalar, []*edwards25519.Point, bool) {
+	if len(points) != len(scalars) {
+		return nil, nil, false
+	}
+	nScalars := make([]*edwards25519.Scalar, len(scalars))
+	nPoints := make([]*edwards25519.Point, len(points))
+	for i, sc := range scalars {
+		s, ok := sc.(*ScalarRistretto255)
+		if !ok {
+			return nil, nil, false
+		}
+		nScalars[i] = s.value
+	}
+	for i, pt := range points {
+		pp, ok := pt.(*PointRistretto255)
+		if !ok {
+			return nil, nil, false
+		}
+		nPoints[i] = pp.value
+	}
+	return nScalars, nPoints, true
+}
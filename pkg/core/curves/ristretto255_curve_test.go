@@ -0,0 +1,206 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/require"
+)
+
+// Encodings of multiples of the generator from RFC 9496 appendix A.1
+var ristretto255GeneratorMultiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	"e882b131016b52c1d3337080187cf768423efccbb517bb495ab812c4160ff44e",
+	"f64746d3c92b13050ed8d80236a7f0007c3b3f962f5ba793d19a601ebb1df403",
+	"44f53520926ec81fbd5a387845beb7df85a96a24ece18738bdcfa6a7822a176d",
+}
+
+func TestPointRistretto255GeneratorMultiples(t *testing.T) {
+	r255 := RISTRETTO255()
+	p := r255.Point.Identity()
+	for i, h := range ristretto255GeneratorMultiples {
+		require.Equal(t, h, hex.EncodeToString(p.ToAffineCompressed()), "multiple %d", i)
+		q, err := r255.Point.FromAffineCompressed(p.ToAffineCompressed())
+		require.NoError(t, err)
+		require.True(t, q.Equal(p))
+		require.True(t, r255.ScalarBaseMult(r255.Scalar.New(i)).Equal(p))
+		p = p.Add(r255.Point.Generator())
+	}
+}
+
+func TestPointRistretto255InvalidEncodings(t *testing.T) {
+	r255 := RISTRETTO255()
+	for _, h := range []string{
+		// Non-canonical field elements
+		"00ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		// Negative field elements
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		// Too short
+		"00",
+	} {
+		b, _ := hex.DecodeString(h)
+		_, err := r255.Point.FromAffineCompressed(b)
+		require.Error(t, err, h)
+	}
+	// Roughly half of the random field elements do not decode
+	failures := 0
+	for i := 0; i < 64; i++ {
+		var b [32]byte
+		_, _ = crand.Read(b[:])
+		b[31] &= 0x7f
+		b[0] &= 0xfe
+		if _, err := r255.Point.FromAffineCompressed(b[:]); err != nil {
+			failures++
+		}
+	}
+	require.Greater(t, failures, 0)
+}
+
+func TestPointRistretto255MatchesReference(t *testing.T) {
+	r255 := RISTRETTO255()
+	for i := 0; i < 16; i++ {
+		var data [64]byte
+		_, _ = crand.Read(data[:])
+
+		// The one-way map over a SHA-512 digest is DeriveDalek
+		var expected ristretto.Point
+		expected.DeriveDalek(data[:])
+		h := sha512.Sum512(data[:])
+		p, ok := r255.Point.(*PointRistretto255).FromUniformBytes(h[:]).(*PointRistretto255)
+		require.True(t, ok)
+		require.Equal(t, expected.Bytes(), p.ToAffineCompressed())
+
+		// Scalar multiplication and decoding agree
+		var s ristretto.Scalar
+		s.Derive(data[:])
+		expected.ScalarMult(&expected, &s)
+		sc, err := r255.Scalar.SetBytes(s.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, expected.Bytes(), p.Mul(sc).ToAffineCompressed())
+		q, err := r255.Point.FromAffineCompressed(expected.Bytes())
+		require.NoError(t, err)
+		require.True(t, q.Equal(p.Mul(sc)))
+	}
+}
+
+func TestPointRistretto255Equal(t *testing.T) {
+	r255 := RISTRETTO255()
+	p := r255.Point.Random(crand.Reader).(*PointRistretto255)
+	// Adding a 4-torsion point gives a different representative of the same element
+	torsion := ed25519LowOrder(t, append([]byte{0xec}, append(bytes.Repeat([]byte{0xff}, 30), 0x7f)...))
+	q := &PointRistretto255{p.value}
+	q.value = q.value.Add(q.value, torsion.value)
+	require.True(t, p.Equal(q))
+	require.Equal(t, p.ToAffineCompressed(), q.ToAffineCompressed())
+	require.False(t, p.Equal(p.Double()))
+	require.False(t, p.Equal(ED25519().Point.Generator()))
+}
+
+func TestPointRistretto255Hash(t *testing.T) {
+	r255 := RISTRETTO255()
+	p := r255.Point.Hash([]byte("ristretto255"))
+	require.NotNil(t, p)
+	require.False(t, p.IsIdentity())
+	require.True(t, p.Equal(r255.Point.Hash([]byte("ristretto255"))))
+	require.False(t, p.Equal(r255.Point.Hash([]byte("ristretto256"))))
+	other := r255.Point.(*PointRistretto255).HashWithDomain([]byte("ristretto255"), []byte("other domain"))
+	require.False(t, p.Equal(other))
+}
+
+func TestPointRistretto255Arithmetic(t *testing.T) {
+	r255 := RISTRETTO255()
+	g := r255.Point.Generator()
+	a := r255.Scalar.Random(crand.Reader)
+	b := r255.Scalar.Random(crand.Reader)
+	require.True(t, g.Mul(a).Add(g.Mul(b)).Equal(g.Mul(a.Add(b))))
+	require.True(t, g.Mul(a).Sub(g.Mul(b)).Equal(g.Mul(a.Sub(b))))
+	require.True(t, g.Mul(a).Neg().Equal(g.Mul(a.Neg())))
+	require.True(t, g.Double().Equal(g.Mul(r255.Scalar.New(2))))
+	require.True(t, g.Mul(a).Add(g.Mul(a).Neg()).IsIdentity())
+	require.Nil(t, g.Add(ED25519().Point.Generator()))
+	require.Nil(t, g.Mul(ED25519().Scalar.One()))
+
+	inv, err := a.Invert()
+	require.NoError(t, err)
+	require.True(t, a.Mul(inv).IsOne())
+	require.Equal(t, 0, a.Div(b).Mul(b).Cmp(a))
+	require.Equal(t, 0, r255.Scalar.New(-3).Add(r255.Scalar.New(3)).Cmp(r255.Scalar.Zero()))
+	require.Equal(t, -2, a.Cmp(ED25519().Scalar.One()))
+	_, err = r255.Scalar.Zero().Invert()
+	require.Error(t, err)
+}
+
+func TestPointRistretto255SumOfProducts(t *testing.T) {
+	r255 := RISTRETTO255()
+	points := make([]Point, 5)
+	scalars := make([]Scalar, 5)
+	expected := r255.Point.Identity()
+	for i := range points {
+		points[i] = r255.Point.Random(crand.Reader)
+		scalars[i] = r255.Scalar.Random(crand.Reader)
+		expected = expected.Add(points[i].Mul(scalars[i]))
+	}
+	p := r255.Point.(*PointRistretto255)
+	require.True(t, expected.Equal(p.SumOfProducts(points, scalars)))
+	require.True(t, expected.Equal(p.VarTimeSumOfProducts(points, scalars)))
+	require.Nil(t, p.SumOfProducts(points, scalars[1:]))
+
+	a := r255.Scalar.Random(crand.Reader)
+	b := r255.Scalar.Random(crand.Reader)
+	expected = points[0].Mul(a).Add(r255.Point.Generator().Mul(b))
+	require.True(t, expected.Equal(p.VarTimeDoubleScalarBaseMult(a, points[0], b)))
+}
+
+func TestRistretto255Serialize(t *testing.T) {
+	r255 := RISTRETTO255()
+	require.Equal(t, r255, GetCurveByName(Ristretto255Name))
+
+	sc := r255.Scalar.Random(crand.Reader)
+	data, err := sc.(*ScalarRistretto255).MarshalBinary()
+	require.NoError(t, err)
+	sc2 := new(ScalarRistretto255)
+	require.NoError(t, sc2.UnmarshalBinary(data))
+	require.Equal(t, 0, sc.Cmp(sc2))
+	data, err = sc.(*ScalarRistretto255).MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, sc2.UnmarshalJSON(data))
+	require.Equal(t, 0, sc.Cmp(sc2))
+
+	p := r255.Point.Random(crand.Reader)
+	data, err = p.(*PointRistretto255).MarshalBinary()
+	require.NoError(t, err)
+	p2 := new(PointRistretto255)
+	require.NoError(t, p2.UnmarshalBinary(data))
+	require.True(t, p.Equal(p2))
+	data, err = p.(*PointRistretto255).MarshalText()
+	require.NoError(t, err)
+	require.NoError(t, p2.UnmarshalText(data))
+	require.True(t, p.Equal(p2))
+	data, err = p.(*PointRistretto255).MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, p2.UnmarshalJSON(data))
+	require.True(t, p.Equal(p2))
+
+	q, err := r255.Point.FromAffineUncompressed(p.ToAffineUncompressed())
+	require.NoError(t, err)
+	require.True(t, p.Equal(q))
+	_, err = r255.Point.Set(nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,41 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+// limbBits is the width of the limbs passed to Scalar.New, small enough
+// to be handled identically by every implementation
+const limbBits = 16
+
+// ScalarFromUint64 returns `v` as an element of the same field as `s`.
+// The value is reduced modulo the field order, which is larger than 2^64 for
+// every supported curve so the result always equals `v`. Unlike Scalar.New it
+// accepts the full uint64 range, never fails and runs the same sequence of
+// field operations for every input
+func ScalarFromUint64(s Scalar, v uint64) Scalar {
+	base := s.New(1 << limbBits)
+	mask := uint64(1<<limbBits - 1)
+	r := s.New(int(v >> (64 - limbBits)))
+	for shift := 64 - 2*limbBits; shift >= 0; shift -= limbBits {
+		r = r.Mul(base).Add(s.New(int(v >> uint(shift) & mask)))
+	}
+	return r
+}
+
+// ScalarFromIndex returns the share identifier or index `id` as an element of the same field as `s`
+func ScalarFromIndex(s Scalar, id uint32) Scalar {
+	return ScalarFromUint64(s, uint64(id))
+}
+
+// ScalarFromUint64 returns `v` as a scalar of this curve, see ScalarFromUint64
+func (c Curve) ScalarFromUint64(v uint64) Scalar {
+	return ScalarFromUint64(c.Scalar, v)
+}
+
+// ScalarFromIndex returns the share identifier or index `id` as a scalar of this curve
+func (c Curve) ScalarFromIndex(id uint32) Scalar {
+	return ScalarFromIndex(c.Scalar, id)
+}
@@ -0,0 +1,32 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScalarFromUint64(t *testing.T) {
+	values := []uint64{0, 1, 2, 0xffff, 0x10000, 0xffffffff, 0x100000000, 1 << 63, math.MaxUint64 - 1, math.MaxUint64}
+	for _, curve := range []*Curve{K256(), P256(), ED25519(), BLS12381G1(), BLS12377G1(), PALLAS()} {
+		for _, v := range values {
+			expected, err := curve.Scalar.SetBigInt(new(big.Int).SetUint64(v))
+			require.NoError(t, err)
+			actual := curve.ScalarFromUint64(v)
+			require.NotNil(t, actual)
+			require.Equal(t, 0, expected.Cmp(actual), "%s %d", curve.Name, v)
+			require.Equal(t, 0, new(big.Int).SetUint64(v).Cmp(actual.BigInt()), "%s %d", curve.Name, v)
+		}
+		require.Equal(t, 0, curve.ScalarFromIndex(math.MaxUint32).Cmp(curve.ScalarFromUint64(math.MaxUint32)))
+		require.True(t, curve.ScalarFromIndex(1).IsOne())
+		require.True(t, ScalarFromIndex(curve.Scalar, 0).IsZero())
+	}
+}
@@ -0,0 +1,1026 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+	"github.com/etclab/kryptology/pkg/core/curves/native/pasta/fp"
+	"github.com/etclab/kryptology/pkg/core/curves/native/pasta/fq"
+)
+
+func init() {
+	mustRegisterCurve(VestaName, nil, VESTA)
+}
+
+// Vesta is y^2 = x^3 + 5 over the scalar field of Pallas, and its scalar field is the base field of Pallas
+var vestaB = new(fq.Fq).SetUint64(5)
+var vestaThree = new(fq.Fq).SetUint64(3)
+var vestaEight = new(fq.Fq).SetUint64(8)
+
+// vestaIsomapper are the coefficients of the 3-isogeny from iso-Vesta to Vesta, in the order used by isoMapVesta
+var vestaIsomapper = [13]*fq.Fq{
+	new(fq.Fq).SetRaw(&[4]uint64{0x43cd42c800000001, 0x0205dd51cfa0961a, 0x8e38e38e38e38e39, 0x38e38e38e38e38e3}),
+	new(fq.Fq).SetRaw(&[4]uint64{0x8b95c6aaf703bcc5, 0x216b8861ec72bd5d, 0xacecf10f5f7c09a2, 0x1d935247b4473d17}),
+	new(fq.Fq).SetRaw(&[4]uint64{0xaeac67bbeb586a3d, 0xd59d03d23b39cb11, 0xed7ee4a9cdf78f8f, 0x18760c7f7a9ad20d}),
+	new(fq.Fq).SetRaw(&[4]uint64{0xfb539a6f0000002b, 0xe1c521a795ac8356, 0x1c71c71c71c71c71, 0x31c71c71c71c71c7}),
+	new(fq.Fq).SetRaw(&[4]uint64{0xb7284f7eaf21a2e9, 0xa3ad678129b604d3, 0x1454798a5b5c56b2, 0x0a2de485568125d5}),
+	new(fq.Fq).SetRaw(&[4]uint64{0xf169c187d2533465, 0x30cd6d53df49d235, 0x0c621de8b91c242a, 0x14735171ee542778}),
+	new(fq.Fq).SetRaw(&[4]uint64{0x6bef1642aaaaaaab, 0x5601f4709a8adcb3, 0xda12f684bda12f68, 0x12f684bda12f684b}),
+	new(fq.Fq).SetRaw(&[4]uint64{0x8bee58e5fb81de63, 0x21d910aefb03b31d, 0xd6767887afbe04d1, 0x2ec9a923da239e8b}),
+	new(fq.Fq).SetRaw(&[4]uint64{0x4986913ab4443034, 0x97a3ca5c24e9ea63, 0x66d1466e9de10e64, 0x19b0d87e16e25788}),
+	new(fq.Fq).SetRaw(&[4]uint64{0x8f64842c55555533, 0x8bc32d36fb21a6a3, 0x425ed097b425ed09, 0x1ed097b425ed097b}),
+	new(fq.Fq).SetRaw(&[4]uint64{0x58dfecce86b2745e, 0x06a767bfc35b5bac, 0x9e7eb64f890a820c, 0x2f44d6c801c1b8bf}),
+	new(fq.Fq).SetRaw(&[4]uint64{0xd43d449776f99d2f, 0x926847fb9ddd76a1, 0x252659ba2b546c7e, 0x3d59f455cafc7668}),
+	new(fq.Fq).SetRaw(&[4]uint64{0x8c46eb20fffffde5, 0x224698fc0994a8dd, 0x0000000000000000, 0x4000000000000000}),
+}
+var vestaIsoa = new(fq.Fq).SetRaw(&[4]uint64{0xc515ad7242eaa6b1, 0x9673928c7d01b212, 0x81639c4d96f78773, 0x267f9b2ee592271a})
+var vestaIsob = new(fq.Fq).SetRaw(&[4]uint64{1265, 0, 0, 0})
+var vestaZ = new(fq.Fq).SetRaw(&[4]uint64{0x8c46eb20fffffff4, 0x224698fc0994a8dd, 0x0000000000000000, 0x4000000000000000})
+
+type ScalarVesta struct {
+	value *fp.Fp
+}
+
+func (s *ScalarVesta) Random(reader io.Reader) Scalar {
+	if reader == nil {
+		return nil
+	}
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return s.Hash(seed[:])
+}
+
+func (s *ScalarVesta) Hash(bytes []byte) Scalar {
+	h, _ := blake2b.New(64, []byte{})
+	xmd, err := expandMsgXmd(h, bytes, []byte("vesta_XMD:BLAKE2b_SSWU_RO_"), 64)
+	if err != nil {
+		return nil
+	}
+	var t [64]byte
+	copy(t[:], xmd)
+	return &ScalarVesta{
+		value: new(fp.Fp).SetBytesWide(&t),
+	}
+}
+
+func (s *ScalarVesta) Zero() Scalar {
+	return &ScalarVesta{
+		value: new(fp.Fp).SetZero(),
+	}
+}
+
+func (s *ScalarVesta) One() Scalar {
+	return &ScalarVesta{
+		value: new(fp.Fp).SetOne(),
+	}
+}
+
+func (s *ScalarVesta) IsZero() bool {
+	return s.value.IsZero()
+}
+
+func (s *ScalarVesta) IsOne() bool {
+	return s.value.IsOne()
+}
+
+func (s *ScalarVesta) IsOdd() bool {
+	return (s.value[0] & 1) == 1
+}
+
+func (s *ScalarVesta) IsEven() bool {
+	return (s.value[0] & 1) == 0
+}
+
+func (s *ScalarVesta) New(value int) Scalar {
+	v := big.NewInt(int64(value))
+	return &ScalarVesta{
+		value: new(fp.Fp).SetBigInt(v),
+	}
+}
+
+func (s *ScalarVesta) Cmp(rhs Scalar) int {
+	r, ok := rhs.(*ScalarVesta)
+	if ok {
+		return s.value.Cmp(r.value)
+	} else {
+		return -2
+	}
+}
+
+func (s *ScalarVesta) Square() Scalar {
+	return &ScalarVesta{
+		value: new(fp.Fp).Square(s.value),
+	}
+}
+
+func (s *ScalarVesta) Double() Scalar {
+	return &ScalarVesta{
+		value: new(fp.Fp).Double(s.value),
+	}
+}
+
+func (s *ScalarVesta) Invert() (Scalar, error) {
+	value, wasInverted := new(fp.Fp).Invert(s.value)
+	if !wasInverted {
+		return nil, fmt.Errorf("inverse doesn't exist")
+	}
+	return &ScalarVesta{
+		value,
+	}, nil
+}
+
+func (s *ScalarVesta) Sqrt() (Scalar, error) {
+	value, wasSquare := new(fp.Fp).Sqrt(s.value)
+	if !wasSquare {
+		return nil, fmt.Errorf("not a square")
+	}
+	return &ScalarVesta{
+		value,
+	}, nil
+}
+
+func (s *ScalarVesta) Cube() Scalar {
+	value := new(fp.Fp).Mul(s.value, s.value)
+	value.Mul(value, s.value)
+	return &ScalarVesta{
+		value,
+	}
+}
+
+func (s *ScalarVesta) Add(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarVesta)
+	if ok {
+		return &ScalarVesta{
+			value: new(fp.Fp).Add(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarVesta) Sub(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarVesta)
+	if ok {
+		return &ScalarVesta{
+			value: new(fp.Fp).Sub(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarVesta) Mul(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarVesta)
+	if ok {
+		return &ScalarVesta{
+			value: new(fp.Fp).Mul(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarVesta) MulAdd(y, z Scalar) Scalar {
+	return s.Mul(y).Add(z)
+}
+
+func (s *ScalarVesta) Div(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarVesta)
+	if ok {
+		v, wasInverted := new(fp.Fp).Invert(r.value)
+		if !wasInverted {
+			return nil
+		}
+		v.Mul(v, s.value)
+		return &ScalarVesta{value: v}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarVesta) Neg() Scalar {
+	return &ScalarVesta{
+		value: new(fp.Fp).Neg(s.value),
+	}
+}
+
+func (s *ScalarVesta) SetBigInt(v *big.Int) (Scalar, error) {
+	return &ScalarVesta{
+		value: new(fp.Fp).SetBigInt(v),
+	}, nil
+}
+
+func (s *ScalarVesta) BigInt() *big.Int {
+	return s.value.BigInt()
+}
+
+func (s *ScalarVesta) Bytes() []byte {
+	t := s.value.Bytes()
+	return t[:]
+}
+
+func (s *ScalarVesta) SetBytes(bytes []byte) (Scalar, error) {
+	if len(bytes) != 32 {
+		return nil, fmt.Errorf("invalid length")
+	}
+	var seq [32]byte
+	copy(seq[:], bytes)
+	value, err := new(fp.Fp).SetBytes(&seq)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarVesta{
+		value,
+	}, nil
+}
+
+func (s *ScalarVesta) SetBytesWide(bytes []byte) (Scalar, error) {
+	if len(bytes) != 64 {
+		return nil, fmt.Errorf("invalid length")
+	}
+	var seq [64]byte
+	copy(seq[:], bytes)
+	return &ScalarVesta{
+		value: new(fp.Fp).SetBytesWide(&seq),
+	}, nil
+}
+
+func (s *ScalarVesta) Point() Point {
+	return new(PointVesta).Identity()
+}
+
+func (s *ScalarVesta) Clone() Scalar {
+	return &ScalarVesta{
+		value: new(fp.Fp).Set(s.value),
+	}
+}
+
+func (s *ScalarVesta) GetFp() *fp.Fp {
+	return new(fp.Fp).Set(s.value)
+}
+
+func (s *ScalarVesta) SetFp(fp *fp.Fp) *ScalarVesta {
+	s.value = fp
+	return s
+}
+
+func (s *ScalarVesta) MarshalBinary() ([]byte, error) {
+	return scalarMarshalBinary(s)
+}
+
+func (s *ScalarVesta) UnmarshalBinary(input []byte) error {
+	sc, err := scalarUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarVesta)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarVesta) MarshalText() ([]byte, error) {
+	return scalarMarshalText(s)
+}
+
+func (s *ScalarVesta) UnmarshalText(input []byte) error {
+	sc, err := scalarUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarVesta)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarVesta) MarshalJSON() ([]byte, error) {
+	return scalarMarshalJson(s)
+}
+
+func (s *ScalarVesta) UnmarshalJSON(input []byte) error {
+	sc, err := scalarUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	S, ok := sc.(*ScalarVesta)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	s.value = S.value
+	return nil
+}
+
+type PointVesta struct {
+	value *Eq
+}
+
+func (p *PointVesta) Random(reader io.Reader) Point {
+	return &PointVesta{new(Eq).Random(reader)}
+}
+
+func (p *PointVesta) Hash(bytes []byte) Point {
+	return &PointVesta{new(Eq).Hash(bytes)}
+}
+
+func (p *PointVesta) Identity() Point {
+	return &PointVesta{new(Eq).Identity()}
+}
+
+func (p *PointVesta) Generator() Point {
+	return &PointVesta{new(Eq).Generator()}
+}
+
+func (p *PointVesta) IsIdentity() bool {
+	return p.value.IsIdentity()
+}
+
+func (p *PointVesta) IsNegative() bool {
+	return p.value.Y().IsOdd()
+}
+
+func (p *PointVesta) IsOnCurve() bool {
+	return p.value.IsOnCurve()
+}
+
+// IsInPrimeSubgroup reports whether the point is on the curve, whose group has prime order
+func (p *PointVesta) IsInPrimeSubgroup() bool {
+	return p.IsIdentity() || p.IsOnCurve()
+}
+
+// ClearCofactor returns a copy of the point, as the cofactor is 1
+func (p *PointVesta) ClearCofactor() Point {
+	return &PointVesta{new(Eq).Set(p.value)}
+}
+
+func (p *PointVesta) Double() Point {
+	return &PointVesta{new(Eq).Double(p.value)}
+}
+
+func (p *PointVesta) Scalar() Scalar {
+	return &ScalarVesta{new(fp.Fp).SetZero()}
+}
+
+func (p *PointVesta) Neg() Point {
+	return &PointVesta{new(Eq).Neg(p.value)}
+}
+
+func (p *PointVesta) Add(rhs Point) Point {
+	r, ok := rhs.(*PointVesta)
+	if !ok {
+		return nil
+	}
+	return &PointVesta{new(Eq).Add(p.value, r.value)}
+}
+
+func (p *PointVesta) Sub(rhs Point) Point {
+	r, ok := rhs.(*PointVesta)
+	if !ok {
+		return nil
+	}
+	return &PointVesta{new(Eq).Sub(p.value, r.value)}
+}
+
+func (p *PointVesta) Mul(rhs Scalar) Point {
+	s, ok := rhs.(*ScalarVesta)
+	if !ok {
+		return nil
+	}
+	return &PointVesta{new(Eq).Mul(p.value, s.value)}
+}
+
+func (p *PointVesta) Equal(rhs Point) bool {
+	r, ok := rhs.(*PointVesta)
+	if !ok {
+		return false
+	}
+	return p.value.Equal(r.value)
+}
+
+func (p *PointVesta) Set(x, y *big.Int) (Point, error) {
+	xx := subtle.ConstantTimeCompare(x.Bytes(), []byte{})
+	yy := subtle.ConstantTimeCompare(y.Bytes(), []byte{})
+	xElem := new(fq.Fq).SetBigInt(x)
+	var data [32]byte
+	if yy == 1 {
+		if xx == 1 {
+			return &PointVesta{new(Eq).Identity()}, nil
+		}
+		data = xElem.Bytes()
+		return p.FromAffineCompressed(data[:])
+	}
+	yElem := new(fq.Fq).SetBigInt(y)
+	value := &Eq{xElem, yElem, new(fq.Fq).SetOne()}
+	if !value.IsOnCurve() {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+	return &PointVesta{value}, nil
+}
+
+func (p *PointVesta) ToAffineCompressed() []byte {
+	return p.value.ToAffineCompressed()
+}
+
+func (p *PointVesta) ToAffineUncompressed() []byte {
+	return p.value.ToAffineUncompressed()
+}
+
+func (p *PointVesta) FromAffineCompressed(bytes []byte) (Point, error) {
+	value, err := new(Eq).FromAffineCompressed(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &PointVesta{value}, nil
+}
+
+func (p *PointVesta) FromAffineUncompressed(bytes []byte) (Point, error) {
+	value, err := new(Eq).FromAffineUncompressed(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &PointVesta{value}, nil
+}
+
+func (p *PointVesta) CurveName() string {
+	return VestaName
+}
+
+func (p *PointVesta) SumOfProducts(points []Point, scalars []Scalar) Point {
+	eps := make([]*Eq, len(points))
+	for i, pt := range points {
+		ps, ok := pt.(*PointVesta)
+		if !ok {
+			return nil
+		}
+		eps[i] = ps.value
+	}
+	value := p.value.SumOfProducts(eps, scalars)
+	if value == nil {
+		return nil
+	}
+	return &PointVesta{value}
+}
+
+func (p *PointVesta) MarshalBinary() ([]byte, error) {
+	return pointMarshalBinary(p)
+}
+
+func (p *PointVesta) UnmarshalBinary(input []byte) error {
+	pt, err := pointUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointVesta)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointVesta) MarshalText() ([]byte, error) {
+	return pointMarshalText(p)
+}
+
+func (p *PointVesta) UnmarshalText(input []byte) error {
+	pt, err := pointUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointVesta)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointVesta) MarshalJSON() ([]byte, error) {
+	return pointMarshalJson(p)
+}
+
+func (p *PointVesta) UnmarshalJSON(input []byte) error {
+	pt, err := pointUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	P, ok := pt.(*PointVesta)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	p.value = P.value
+	return nil
+}
+
+func (p *PointVesta) X() *fq.Fq {
+	return p.value.X()
+}
+
+func (p *PointVesta) Y() *fq.Fq {
+	return p.value.Y()
+}
+
+func (p *PointVesta) GetEq() *Eq {
+	return new(Eq).Set(p.value)
+}
+
+type Eq struct {
+	x *fq.Fq
+	y *fq.Fq
+	z *fq.Fq
+}
+
+func (p *Eq) Random(reader io.Reader) *Eq {
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return p.Hash(seed[:])
+}
+
+func (p *Eq) Hash(bytes []byte) *Eq {
+	return p.hashWithDst(bytes, []byte(vestaSuiteId))
+}
+
+// hashWithDst maps bytes to the curve with the domain separation tag `dst`
+func (p *Eq) hashWithDst(bytes, dst []byte) *Eq {
+	if bytes == nil {
+		bytes = []byte{}
+	}
+	h, _ := blake2b.New(64, []byte{})
+	if len(dst) > native.MaxDstLen {
+		_, _ = h.Write(native.OversizeDstSalt)
+		_, _ = h.Write(dst)
+		dst = h.Sum(nil)
+		h.Reset()
+	}
+	u, _ := expandMsgXmd(h, bytes, dst, 128)
+	// The field elements are big-endian as in RFC 9380 and pasta_curves, unlike Pallas which predates them
+	var buf [64]byte
+	copy(buf[:], internal.ReverseScalarBytes(u[:64]))
+	u0 := new(fq.Fq).SetBytesWide(&buf)
+	copy(buf[:], internal.ReverseScalarBytes(u[64:]))
+	u1 := new(fq.Fq).SetBytesWide(&buf)
+
+	q0 := mapSswuVesta(u0)
+	q1 := mapSswuVesta(u1)
+	r1 := isoMapVesta(q0)
+	r2 := isoMapVesta(q1)
+	return p.Identity().Add(r1, r2)
+}
+
+func (p *Eq) Identity() *Eq {
+	p.x = new(fq.Fq).SetZero()
+	p.y = new(fq.Fq).SetZero()
+	p.z = new(fq.Fq).SetZero()
+	return p
+}
+
+func (p *Eq) Generator() *Eq {
+	// (-1, 2)
+	p.x = new(fq.Fq).Neg(new(fq.Fq).SetOne())
+	p.y = new(fq.Fq).SetUint64(2)
+	p.z = new(fq.Fq).SetOne()
+	return p
+}
+
+func (p *Eq) IsIdentity() bool {
+	return p.z.IsZero()
+}
+
+func (p *Eq) Double(other *Eq) *Eq {
+	if other.IsIdentity() {
+		p.Set(other)
+		return p
+	}
+	r := new(Eq)
+	// essentially paraphrased https://github.com/MinaProtocol/c-reference-signer/blob/master/crypto.c#L306-L337
+	a := new(fq.Fq).Square(other.x)
+	b := new(fq.Fq).Square(other.y)
+	c := new(fq.Fq).Square(b)
+	r.x = new(fq.Fq).Add(other.x, b)
+	r.y = new(fq.Fq).Square(r.x)
+	r.z = new(fq.Fq).Sub(r.y, a)
+	r.x.Sub(r.z, c)
+	d := new(fq.Fq).Double(r.x)
+	e := new(fq.Fq).Mul(vestaThree, a)
+	f := new(fq.Fq).Square(e)
+	r.y.Double(d)
+	r.x.Sub(f, r.y)
+	r.y.Sub(d, r.x)
+	f.Mul(vestaEight, c)
+	r.z.Mul(e, r.y)
+	r.y.Sub(r.z, f)
+	f.Mul(other.y, other.z)
+	r.z.Double(f)
+	p.Set(r)
+	return p
+}
+
+func (p *Eq) Neg(other *Eq) *Eq {
+	p.x = new(fq.Fq).Set(other.x)
+	p.y = new(fq.Fq).Neg(other.y)
+	p.z = new(fq.Fq).Set(other.z)
+	return p
+}
+
+func (p *Eq) Add(lhs *Eq, rhs *Eq) *Eq {
+	if lhs.IsIdentity() {
+		return p.Set(rhs)
+	}
+	if rhs.IsIdentity() {
+		return p.Set(lhs)
+	}
+	z1z1 := new(fq.Fq).Square(lhs.z)
+	z2z2 := new(fq.Fq).Square(rhs.z)
+	u1 := new(fq.Fq).Mul(lhs.x, z2z2)
+	u2 := new(fq.Fq).Mul(rhs.x, z1z1)
+	s1 := new(fq.Fq).Mul(lhs.y, z2z2)
+	s1.Mul(s1, rhs.z)
+	s2 := new(fq.Fq).Mul(rhs.y, z1z1)
+	s2.Mul(s2, lhs.z)
+
+	if u1.Equal(u2) {
+		if s1.Equal(s2) {
+			return p.Double(lhs)
+		} else {
+			return p.Identity()
+		}
+	} else {
+		h := new(fq.Fq).Sub(u2, u1)
+		i := new(fq.Fq).Double(h)
+		i.Square(i)
+		j := new(fq.Fq).Mul(i, h)
+		r := new(fq.Fq).Sub(s2, s1)
+		r.Double(r)
+		v := new(fq.Fq).Mul(u1, i)
+		x3 := new(fq.Fq).Square(r)
+		x3.Sub(x3, j)
+		x3.Sub(x3, new(fq.Fq).Double(v))
+		s1.Mul(s1, j)
+		s1.Double(s1)
+		y3 := new(fq.Fq).Mul(r, new(fq.Fq).Sub(v, x3))
+		y3.Sub(y3, s1)
+		z3 := new(fq.Fq).Add(lhs.z, rhs.z)
+		z3.Square(z3)
+		z3.Sub(z3, z1z1)
+		z3.Sub(z3, z2z2)
+		z3.Mul(z3, h)
+		p.x = new(fq.Fq).Set(x3)
+		p.y = new(fq.Fq).Set(y3)
+		p.z = new(fq.Fq).Set(z3)
+
+		return p
+	}
+}
+
+func (p *Eq) Sub(lhs, rhs *Eq) *Eq {
+	return p.Add(lhs, new(Eq).Neg(rhs))
+}
+
+func (p *Eq) Mul(point *Eq, scalar *fp.Fp) *Eq {
+	bytes := scalar.Bytes()
+	precomputed := [16]*Eq{}
+	precomputed[0] = new(Eq).Identity()
+	precomputed[1] = new(Eq).Set(point)
+	for i := 2; i < 16; i += 2 {
+		precomputed[i] = new(Eq).Double(precomputed[i>>1])
+		precomputed[i+1] = new(Eq).Add(precomputed[i], point)
+	}
+	p.Identity()
+	for i := 0; i < 256; i += 4 {
+		// Brouwer / windowing method. window size of 4.
+		for j := 0; j < 4; j++ {
+			p.Double(p)
+		}
+		window := bytes[32-1-i>>3] >> (4 - i&0x04) & 0x0F
+		p.Add(p, precomputed[window])
+	}
+	return p
+}
+
+func (p *Eq) Equal(other *Eq) bool {
+	// warning: requires converting both to affine
+	// could save slightly by modifying one so that its z-value equals the other
+	// this would save one inversion and a handful of multiplications
+	// but this is more subtle and error-prone, so going to just convert both to affine.
+	lhs := new(Eq).Set(p)
+	rhs := new(Eq).Set(other)
+	lhs.toAffine()
+	rhs.toAffine()
+	return lhs.x.Equal(rhs.x) && lhs.y.Equal(rhs.y)
+}
+
+func (p *Eq) Set(other *Eq) *Eq {
+	// check is identity or on curve
+	p.x = new(fq.Fq).Set(other.x)
+	p.y = new(fq.Fq).Set(other.y)
+	p.z = new(fq.Fq).Set(other.z)
+	return p
+}
+
+func (p *Eq) toAffine() *Eq {
+	// mutates `p` in-place to convert it to "affine" form.
+	if p.IsIdentity() {
+		// warning: control flow / not constant-time
+		p.x.SetZero()
+		p.y.SetZero()
+		p.z.SetOne()
+		return p
+	}
+	zInv3, _ := new(fq.Fq).Invert(p.z) // z is necessarily nonzero
+	zInv2 := new(fq.Fq).Square(zInv3)
+	zInv3.Mul(zInv3, zInv2)
+	p.x.Mul(p.x, zInv2)
+	p.y.Mul(p.y, zInv3)
+	p.z.SetOne()
+	return p
+}
+
+func (p *Eq) ToAffineCompressed() []byte {
+	// Use ZCash encoding where infinity is all zeros
+	// and the top bit represents the sign of y and the
+	// remainder represent the x-coordinate
+	var inf [32]byte
+	p1 := new(Eq).Set(p)
+	p1.toAffine()
+	x := p1.x.Bytes()
+	x[31] |= (p1.y.Bytes()[0] & 1) << 7
+	subtle.ConstantTimeCopy(bool2int[p1.IsIdentity()], x[:], inf[:])
+	return x[:]
+}
+
+func (p *Eq) ToAffineUncompressed() []byte {
+	p1 := new(Eq).Set(p)
+	p1.toAffine()
+	x := p1.x.Bytes()
+	y := p1.y.Bytes()
+	return append(x[:], y[:]...)
+}
+
+func (p *Eq) FromAffineCompressed(bytes []byte) (*Eq, error) {
+	if len(bytes) != 32 {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+
+	var input, inf [32]byte
+	copy(input[:], bytes)
+	// ZCash encoding of infinity
+	if input == inf {
+		return p.Identity(), nil
+	}
+	sign := (input[31] >> 7) & 1
+	input[31] &= 0x7F
+
+	x := new(fq.Fq)
+	if _, err := x.SetBytes(&input); err != nil {
+		return nil, err
+	}
+	rhs := rhsVesta(x)
+	if _, square := rhs.Sqrt(rhs); !square {
+		return nil, fmt.Errorf("rhs of given x-coordinate is not a square")
+	}
+	if rhs.Bytes()[0]&1 != sign {
+		rhs.Neg(rhs)
+	}
+	p.x = x
+	p.y = rhs
+	p.z = new(fq.Fq).SetOne()
+	if !p.IsOnCurve() {
+		return nil, fmt.Errorf("invalid point")
+	}
+	return p, nil
+}
+
+func (p *Eq) FromAffineUncompressed(bytes []byte) (*Eq, error) {
+	if len(bytes) != 64 {
+		return nil, fmt.Errorf("invalid length")
+	}
+	p.z = new(fq.Fq).SetOne()
+	p.x = new(fq.Fq)
+	p.y = new(fq.Fq)
+	var x, y [32]byte
+	copy(x[:], bytes[:32])
+	copy(y[:], bytes[32:])
+	if _, err := p.x.SetBytes(&x); err != nil {
+		return nil, err
+	}
+	if _, err := p.y.SetBytes(&y); err != nil {
+		return nil, err
+	}
+	if !p.IsOnCurve() {
+		return nil, fmt.Errorf("invalid point")
+	}
+	return p, nil
+}
+
+// rhs of the curve equation
+func rhsVesta(x *fq.Fq) *fq.Fq {
+	x2 := new(fq.Fq).Square(x)
+	x3 := new(fq.Fq).Mul(x, x2)
+	return new(fq.Fq).Add(x3, vestaB)
+}
+
+func (p Eq) CurveName() string {
+	return "vesta"
+}
+
+func (p Eq) SumOfProducts(points []*Eq, scalars []Scalar) *Eq {
+	nScalars := make([]*big.Int, len(scalars))
+	for i, s := range scalars {
+		sc, ok := s.(*ScalarVesta)
+		if !ok {
+			return nil
+		}
+		nScalars[i] = sc.value.BigInt()
+	}
+	return sumOfProductsPippengerVesta(points, nScalars)
+}
+
+func (p *Eq) X() *fq.Fq {
+	t := new(Eq).Set(p)
+	t.toAffine()
+	return new(fq.Fq).Set(t.x)
+}
+
+func (p *Eq) Y() *fq.Fq {
+	t := new(Eq).Set(p)
+	t.toAffine()
+	return new(fq.Fq).Set(t.y)
+}
+
+func (p *Eq) IsOnCurve() bool {
+	// y^2 = x^3 + axz^4 + bz^6
+	// a = 0
+	// b = 5
+	z2 := new(fq.Fq).Square(p.z)
+	z4 := new(fq.Fq).Square(z2)
+	z6 := new(fq.Fq).Mul(z2, z4)
+	x2 := new(fq.Fq).Square(p.x)
+	x3 := new(fq.Fq).Mul(x2, p.x)
+
+	lhs := new(fq.Fq).Square(p.y)
+	rhs := new(fq.Fq).SetUint64(5)
+	rhs.Mul(rhs, z6)
+	rhs.Add(rhs, x3)
+	return p.z.IsZero() || lhs.Equal(rhs)
+}
+
+func (p *Eq) CMove(lhs, rhs *Eq, condition int) *Eq {
+	p.x = new(fq.Fq).CMove(lhs.x, rhs.x, condition)
+	p.y = new(fq.Fq).CMove(lhs.y, rhs.y, condition)
+	p.z = new(fq.Fq).CMove(lhs.z, rhs.z, condition)
+	return p
+}
+
+func sumOfProductsPippengerVesta(points []*Eq, scalars []*big.Int) *Eq {
+	if len(points) != len(scalars) {
+		return nil
+	}
+
+	w := native.PippengerWindowSize(len(points))
+
+	bucketSize := (1 << uint(w)) - 1
+	windows := make([]*Eq, 255/w+1)
+	for i := range windows {
+		windows[i] = new(Eq).Identity()
+	}
+	bucket := make([]*Eq, bucketSize)
+
+	for j := 0; j < len(windows); j++ {
+		for i := 0; i < bucketSize; i++ {
+			bucket[i] = new(Eq).Identity()
+		}
+
+		for i := 0; i < len(scalars); i++ {
+			index := bucketSize & int(new(big.Int).Rsh(scalars[i], uint(w*j)).Int64())
+			if index != 0 {
+				bucket[index-1].Add(bucket[index-1], points[i])
+			}
+		}
+
+		acc, sum := new(Eq).Identity(), new(Eq).Identity()
+
+		for i := bucketSize - 1; i >= 0; i-- {
+			sum.Add(sum, bucket[i])
+			acc.Add(acc, sum)
+		}
+		windows[j] = acc
+	}
+
+	acc := new(Eq).Identity()
+	for i := len(windows) - 1; i >= 0; i-- {
+		for j := 0; j < w; j++ {
+			acc.Double(acc)
+		}
+		acc.Add(acc, windows[i])
+	}
+	return acc
+}
+
+// Implements a degree 3 isogeny map.
+// The input and output are in Jacobian coordinates, using the method
+// in "Avoiding inversions" [WB2019, section 4.3].
+func isoMapVesta(p *Eq) *Eq {
+	var z [4]*fq.Fq
+	z[0] = new(fq.Fq).Square(p.z)    //z^2
+	z[1] = new(fq.Fq).Mul(z[0], p.z) // z^3
+	z[2] = new(fq.Fq).Square(z[0])   // z^4
+	z[3] = new(fq.Fq).Square(z[1])   // z^6
+
+	// ((iso[0] * x + iso[1] * z^2) * x + iso[2] * z^4) * x + iso[3] * z^6
+	numX := new(fq.Fq).Set(vestaIsomapper[0])
+	numX.Mul(numX, p.x)
+	numX.Add(numX, new(fq.Fq).Mul(vestaIsomapper[1], z[0]))
+	numX.Mul(numX, p.x)
+	numX.Add(numX, new(fq.Fq).Mul(vestaIsomapper[2], z[2]))
+	numX.Mul(numX, p.x)
+	numX.Add(numX, new(fq.Fq).Mul(vestaIsomapper[3], z[3]))
+
+	// (z^2 * x + iso[4] * z^4) * x + iso[5] * z^6
+	divX := new(fq.Fq).Set(z[0])
+	divX.Mul(divX, p.x)
+	divX.Add(divX, new(fq.Fq).Mul(vestaIsomapper[4], z[2]))
+	divX.Mul(divX, p.x)
+	divX.Add(divX, new(fq.Fq).Mul(vestaIsomapper[5], z[3]))
+
+	// (((iso[6] * x + iso[7] * z2) * x + iso[8] * z4) * x + iso[9] * z6) * y
+	numY := new(fq.Fq).Set(vestaIsomapper[6])
+	numY.Mul(numY, p.x)
+	numY.Add(numY, new(fq.Fq).Mul(vestaIsomapper[7], z[0]))
+	numY.Mul(numY, p.x)
+	numY.Add(numY, new(fq.Fq).Mul(vestaIsomapper[8], z[2]))
+	numY.Mul(numY, p.x)
+	numY.Add(numY, new(fq.Fq).Mul(vestaIsomapper[9], z[3]))
+	numY.Mul(numY, p.y)
+
+	// (((x + iso[10] * z2) * x + iso[11] * z4) * x + iso[12] * z6) * z3
+	divY := new(fq.Fq).Set(p.x)
+	divY.Add(divY, new(fq.Fq).Mul(vestaIsomapper[10], z[0]))
+	divY.Mul(divY, p.x)
+	divY.Add(divY, new(fq.Fq).Mul(vestaIsomapper[11], z[2]))
+	divY.Mul(divY, p.x)
+	divY.Add(divY, new(fq.Fq).Mul(vestaIsomapper[12], z[3]))
+	divY.Mul(divY, z[1])
+
+	z0 := new(fq.Fq).Mul(divX, divY)
+	x := new(fq.Fq).Mul(numX, divY)
+	x.Mul(x, z0)
+	y := new(fq.Fq).Mul(numY, divX)
+	y.Mul(y, new(fq.Fq).Square(z0))
+
+	return &Eq{
+		x, y, z0,
+	}
+}
+
+func mapSswuVesta(u *fq.Fq) *Eq {
+	//c1 := new(fq.Fq).Neg(vestaIsoa)
+	//c1.Invert(c1)
+	//c1.Mul(vestaIsob, c1)
+	c1 := new(fq.Fq).SetRaw(&[4]uint64{0x6dab74e8ef9dc7d3, 0xbb4a015f2450502c, 0x5385df3f6207bb22, 0x23447efd3c451b98})
+	//c2 := new(fq.Fq).Neg(vestaZ)
+	//c2.Invert(c2)
+	c2 := new(fq.Fq).SetRaw(&[4]uint64{0x6a0441ecec4ec4ed, 0x778de7fd8fbdf1c3, 0x7627627627627627, 0x2762762762762762})
+
+	u2 := new(fq.Fq).Square(u)
+	tv1 := new(fq.Fq).Mul(vestaZ, u2)
+	tv2 := new(fq.Fq).Square(tv1)
+	x1 := new(fq.Fq).Add(tv1, tv2)
+	x1.Invert(x1)
+	e1 := bool2int[x1.IsZero()]
+	x1.Add(x1, new(fq.Fq).SetOne())
+	x1.CMove(x1, c2, e1)
+	x1.Mul(x1, c1)
+	gx1 := new(fq.Fq).Square(x1)
+	gx1.Add(gx1, vestaIsoa)
+	gx1.Mul(gx1, x1)
+	gx1.Add(gx1, vestaIsob)
+	x2 := new(fq.Fq).Mul(tv1, x1)
+	tv2.Mul(tv1, tv2)
+	gx2 := new(fq.Fq).Mul(gx1, tv2)
+	gx1Sqrt, e2 := new(fq.Fq).Sqrt(gx1)
+	x := new(fq.Fq).CMove(x2, x1, bool2int[e2])
+	gx2Sqrt, _ := new(fq.Fq).Sqrt(gx2)
+	y := new(fq.Fq).CMove(gx2Sqrt, gx1Sqrt, bool2int[e2])
+	e3 := u.IsOdd() == y.IsOdd()
+	y.CMove(new(fq.Fq).Neg(y), y, bool2int[e3])
+
+	return &Eq{
+		x: x, y: y, z: new(fq.Fq).SetOne(),
+	}
+}
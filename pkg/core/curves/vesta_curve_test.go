@@ -0,0 +1,145 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves/native/pasta/fp"
+	"github.com/etclab/kryptology/pkg/core/curves/native/pasta/fq"
+)
+
+func TestPointVestaAddDoubleMul(t *testing.T) {
+	g := new(Eq).Generator()
+	require.True(t, g.IsOnCurve())
+	id := new(Eq).Identity()
+	require.Equal(t, g.Add(g, id), g)
+
+	g2 := new(Eq).Add(g, g)
+	require.True(t, new(Eq).Double(g).Equal(g2))
+	g3 := new(Eq).Add(g, g2)
+	require.True(t, g3.Equal(new(Eq).Mul(g, new(fp.Fp).SetUint64(3))))
+
+	g4 := new(Eq).Add(g3, g)
+	require.True(t, g4.Equal(new(Eq).Double(g2)))
+	require.True(t, g4.Equal(new(Eq).Mul(g, new(fp.Fp).SetUint64(4))))
+
+	// The group order is the Pallas base field modulus
+	minusOne := new(fp.Fp).Neg(new(fp.Fp).SetOne())
+	require.True(t, new(Eq).Add(new(Eq).Mul(g, minusOne), g).IsIdentity())
+}
+
+func TestPointVestaHash(t *testing.T) {
+	h0 := new(Eq).Hash(nil)
+	require.True(t, h0.IsOnCurve())
+	h1 := new(Eq).Hash([]byte{})
+	require.True(t, h1.IsOnCurve())
+	require.True(t, h0.Equal(h1))
+	for i := 0; i < 25; i++ {
+		var msg [32]byte
+		_, _ = crand.Read(msg[:])
+		h := new(Eq).Hash(msg[:])
+		require.True(t, h.IsOnCurve())
+		require.False(t, h.IsIdentity())
+	}
+}
+
+func TestPointVestaHashVectors(t *testing.T) {
+	// Eq::hash_to_curve("z.cash:test") of pasta_curves, whose tag is the domain prefix followed by
+	// "-vesta_XMD:BLAKE2b_SSWU_RO_". The points were computed with an independent implementation of
+	// its hash_to_field, simplified SWU and an isogeny derived with Velu's formulas
+	dst := []byte("z.cash:test-" + vestaSuiteId)
+	for _, v := range []struct {
+		msg, x, y string
+	}{
+		{"", "0125c4dd7127efebc7dfaeafdedf0dae160543c2f5d7b0529cbe15a7a05749c2", "296c814d449475efe14c9482465c963cdce2b45465f06068d6d6597904df4817"},
+		{"abc", "119937fec645cbe784db7b81aa7886abcd5da638d9348228ecb5c2526a47863d", "311ef415188f700f3b63c7fc2ee321320d0f5fa251fe9ce945a801ee527f4d9c"},
+		{"hello", "2e983e009cf3b86bc95f91b3411bd6cbd0a87f8c3c3dae80f3f2637084849204", "310fb8f3316d069a1fb9374bdbc0fb1391c864a5208b2a812341db7f50b2e106"},
+		{"abcdef0123456789", "03d33685a31e146ef33f8667f17e8f238ff545b260d665d871aabd7b5674ce51", "2d0a926bd3b2bdaa6846637f236d8c79bbe479dd747da5bc707c339e42a8078d"},
+	} {
+		p, err := HashToCurve(VESTA(), []byte(v.msg), dst)
+		require.NoError(t, err)
+		x, err := hex.DecodeString(v.x)
+		require.NoError(t, err)
+		y, err := hex.DecodeString(v.y)
+		require.NoError(t, err)
+		// Coordinates are little-endian in the uncompressed encoding
+		expected := append(internal.ReverseScalarBytes(x), internal.ReverseScalarBytes(y)...)
+		require.Equal(t, expected, p.ToAffineUncompressed(), "%q", v.msg)
+	}
+}
+
+func TestPointVestaMapToIsoCurve(t *testing.T) {
+	// mapSswuVesta lands on iso-Vesta, y^2 = x^3 + A'x + 1265
+	for i := 0; i < 25; i++ {
+		var wide [64]byte
+		_, _ = crand.Read(wide[:])
+		p := mapSswuVesta(new(fq.Fq).SetBytesWide(&wide))
+		lhs := new(fq.Fq).Square(p.y)
+		rhs := new(fq.Fq).Square(p.x)
+		rhs.Add(rhs, vestaIsoa)
+		rhs.Mul(rhs, p.x)
+		rhs.Add(rhs, vestaIsob)
+		require.True(t, lhs.Equal(rhs))
+		require.True(t, isoMapVesta(p).IsOnCurve())
+	}
+}
+
+func TestPointVestaSerialize(t *testing.T) {
+	g := new(Eq).Generator()
+	for i := 0; i < 25; i++ {
+		s := new(ScalarVesta).Random(crand.Reader).(*ScalarVesta)
+		pt := new(Eq).Mul(g, s.value)
+		cmprs := pt.ToAffineCompressed()
+		require.Equal(t, len(cmprs), 32)
+		retC, err := new(Eq).FromAffineCompressed(cmprs)
+		require.NoError(t, err)
+		require.True(t, pt.Equal(retC))
+
+		un := pt.ToAffineUncompressed()
+		require.Equal(t, len(un), 64)
+		retU, err := new(Eq).FromAffineUncompressed(un)
+		require.NoError(t, err)
+		require.True(t, pt.Equal(retU))
+	}
+	retI, err := new(Eq).FromAffineCompressed(new(Eq).Identity().ToAffineCompressed())
+	require.NoError(t, err)
+	require.True(t, retI.IsIdentity())
+}
+
+func TestPointVestaSumOfProducts(t *testing.T) {
+	lhs := new(Eq).Generator()
+	lhs.Mul(lhs, new(fp.Fp).SetUint64(50))
+	points := make([]*Eq, 5)
+	for i := range points {
+		points[i] = new(Eq).Generator()
+	}
+	scalars := []Scalar{
+		new(ScalarVesta).New(8),
+		new(ScalarVesta).New(9),
+		new(ScalarVesta).New(10),
+		new(ScalarVesta).New(11),
+		new(ScalarVesta).New(12),
+	}
+	rhs := lhs.SumOfProducts(points, scalars)
+	require.NotNil(t, rhs)
+	require.True(t, lhs.Equal(rhs))
+}
+
+func TestPastaCycle(t *testing.T) {
+	// The scalar field of each curve is the base field of the other
+	order := new(big.Int).Add(VESTA().Scalar.New(-1).BigInt(), big.NewInt(1))
+	require.Equal(t, 0, Pallas().P.Cmp(order))
+	order = new(big.Int).Add(PALLAS().Scalar.New(-1).BigInt(), big.NewInt(1))
+	require.Equal(t, 0, fq.BiModulus.Cmp(order))
+}
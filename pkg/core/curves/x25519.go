@@ -0,0 +1,186 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+const (
+	// X25519ScalarSize is the size of an X25519 secret scalar in bytes
+	X25519ScalarSize = 32
+	// X25519PointSize is the size of an encoded Montgomery u-coordinate in bytes
+	X25519PointSize = 32
+)
+
+// X25519Basepoint returns the encoding of the canonical Curve25519 base point u = 9
+func X25519Basepoint() []byte {
+	basepoint := make([]byte, X25519PointSize)
+	basepoint[0] = 9
+	return basepoint
+}
+
+// X25519 computes the Diffie-Hellman function of RFC 7748 section 5,
+// multiplying the u-coordinate `point` by the clamped `scalar` with a
+// constant time Montgomery ladder.
+// As recommended in RFC 7748 section 6.1 an error is returned when the
+// result is all zeros, which happens when `point` has low order
+func X25519(scalar, point []byte) ([]byte, error) {
+	if len(scalar) != X25519ScalarSize {
+		return nil, fmt.Errorf("invalid scalar length, expected %d, got %d", X25519ScalarSize, len(scalar))
+	}
+	if len(point) != X25519PointSize {
+		return nil, fmt.Errorf("invalid point length, expected %d, got %d", X25519PointSize, len(point))
+	}
+	// SetBytes masks the most significant bit and accepts
+	// non-canonical values as required by RFC 7748
+	x1, err := new(field.Element).SetBytes(point)
+	if err != nil {
+		return nil, err
+	}
+	out := montgomeryLadder(clampX25519(scalar), x1).Bytes()
+	if isAllZero(out) {
+		return nil, ErrLowOrderPoint
+	}
+	return out, nil
+}
+
+// X25519Base computes X25519(scalar, 9) using the faster fixed-base
+// multiplication on the birationally equivalent Edwards curve
+func X25519Base(scalar []byte) ([]byte, error) {
+	if len(scalar) != X25519ScalarSize {
+		return nil, fmt.Errorf("invalid scalar length, expected %d, got %d", X25519ScalarSize, len(scalar))
+	}
+	s, err := edwards25519.NewScalar().SetBytesWithClamping(scalar)
+	if err != nil {
+		return nil, err
+	}
+	p := &PointEd25519{edwards25519.NewIdentityPoint().ScalarBaseMult(s)}
+	return p.ToMontgomery(), nil
+}
+
+// NewX25519Key generates a random X25519 secret scalar and its public u-coordinate
+func NewX25519Key(reader io.Reader) (secret, public []byte, err error) {
+	if reader == nil {
+		return nil, nil, fmt.Errorf("invalid reader")
+	}
+	secret = make([]byte, X25519ScalarSize)
+	if _, err = io.ReadFull(reader, secret); err != nil {
+		return nil, nil, err
+	}
+	public, err = X25519Base(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secret, public, nil
+}
+
+// ToMontgomery returns the RFC 7748 encoding of the u-coordinate of the
+// Curve25519 point birationally equivalent to this point, u = (1 + y) / (1 - y).
+// The identity maps to u = 0
+func (p *PointEd25519) ToMontgomery() []byte {
+	_, y, z, _ := p.value.ExtendedCoordinates()
+	// u = (Z + Y) / (Z - Y), the inverse of zero is zero
+	num := new(field.Element).Add(z, y)
+	den := new(field.Element).Subtract(z, y)
+	den.Invert(den)
+	return num.Multiply(num, den).Bytes()
+}
+
+// FromMontgomery returns the Edwards point birationally equivalent to the Curve25519 point with the RFC 7748
+// u-coordinate `u`, y = (u - 1) / (u + 1). Of the two points with this u-coordinate, the one with a
+// non-negative x is returned, as both have the same multiples up to sign. An error is returned for u = -1 and
+// for u-coordinates of points on the twist of Curve25519, which have no Edwards equivalent
+func (p *PointEd25519) FromMontgomery(u []byte) (*PointEd25519, error) {
+	if len(u) != X25519PointSize {
+		return nil, fmt.Errorf("invalid point length, expected %d, got %d", X25519PointSize, len(u))
+	}
+	x, err := new(field.Element).SetBytes(u)
+	if err != nil {
+		return nil, err
+	}
+	one := new(field.Element).One()
+	den := new(field.Element).Add(x, one)
+	if den.Equal(new(field.Element).Zero()) == 1 {
+		return nil, fmt.Errorf("invalid u-coordinate")
+	}
+	num := new(field.Element).Subtract(x, one)
+	y := num.Multiply(num, den.Invert(den))
+	// The sign bit of x is clear
+	value, err := edwards25519.NewIdentityPoint().SetBytes(y.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("u-coordinate is not on the curve")
+	}
+	return &PointEd25519{value}, nil
+}
+
+// clampX25519 applies the scalar decoding of RFC 7748 section 5
+func clampX25519(scalar []byte) [32]byte {
+	var k [32]byte
+	copy(k[:], scalar)
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+	return k
+}
+
+// montgomeryLadder computes the u-coordinate of k * u as described in RFC 7748 section 5
+func montgomeryLadder(k [32]byte, x1 *field.Element) *field.Element {
+	x2 := new(field.Element).One()
+	z2 := new(field.Element).Zero()
+	x3 := new(field.Element).Set(x1)
+	z3 := new(field.Element).One()
+
+	tmp0 := new(field.Element)
+	tmp1 := new(field.Element)
+	swap := 0
+	for i := 254; i >= 0; i-- {
+		kt := int(k[i/8]>>uint(i&7)) & 1
+		swap ^= kt
+		x2.Swap(x3, swap)
+		z2.Swap(z3, swap)
+		swap = kt
+
+		// Montgomery differential addition and doubling with a24 = 121666
+		tmp0.Subtract(x3, z3)
+		tmp1.Subtract(x2, z2)
+		x2.Add(x2, z2)
+		z2.Add(x3, z3)
+		z3.Multiply(tmp0, x2)
+		z2.Multiply(z2, tmp1)
+		tmp0.Square(tmp1)
+		tmp1.Square(x2)
+		x3.Add(z3, z2)
+		z2.Subtract(z3, z2)
+		x2.Multiply(tmp1, tmp0)
+		tmp1.Subtract(tmp1, tmp0)
+		z2.Square(z2)
+		z3.Mult32(tmp1, 121666)
+		x3.Square(x3)
+		tmp0.Add(tmp0, z3)
+		z3.Multiply(x1, z2)
+		z2.Multiply(tmp1, tmp0)
+	}
+	x2.Swap(x3, swap)
+	z2.Swap(z3, swap)
+
+	z2.Invert(z2)
+	return x2.Multiply(x2, z2)
+}
+
+func isAllZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return subtle.ConstantTimeByteEq(acc, 0) == 1
+}
@@ -0,0 +1,164 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestX25519Vectors(t *testing.T) {
+	// RFC 7748 section 5.2
+	tests := []struct {
+		scalar, point, expected string
+	}{
+		{
+			"a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4",
+			"e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c",
+			"c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552",
+		},
+		{
+			"4b66e9d4d1b4673c5ad22691957d6af5c11b6421e0ea01d42ca4169e7918ba0d",
+			"e5210f12786811d3f4b7959d0538ae2c31dbe7106fc03c3efc4cd549c715a493",
+			"95cbde9476e8907d7aade45cb4b873f88b595a68799fa152e6f8f7647aac7957",
+		},
+	}
+	for _, test := range tests {
+		scalar, _ := hex.DecodeString(test.scalar)
+		point, _ := hex.DecodeString(test.point)
+		out, err := X25519(scalar, point)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, hex.EncodeToString(out))
+	}
+}
+
+func TestX25519Iterated(t *testing.T) {
+	// RFC 7748 section 5.2, after 1 and 1000 iterations
+	k := X25519Basepoint()
+	u := X25519Basepoint()
+	iterations := 1000
+	if testing.Short() {
+		iterations = 1
+	}
+	for i := 1; i <= iterations; i++ {
+		out, err := X25519(k, u)
+		require.NoError(t, err)
+		u, k = k, out
+		switch i {
+		case 1:
+			require.Equal(t, "422c8e7a6227d7bca1350b3e2bb7279f7897b87bb6854b783c60e80311ae3079", hex.EncodeToString(k))
+		case 1000:
+			require.Equal(t, "684cf59ba83309552800ef566f2f4d3c1c3887c49360e3875f2eb94d99532c51", hex.EncodeToString(k))
+		}
+	}
+}
+
+func TestX25519KeyAgreement(t *testing.T) {
+	// RFC 7748 section 6.1
+	aliceSecret, _ := hex.DecodeString("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	bobSecret, _ := hex.DecodeString("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	alicePublic, err := X25519Base(aliceSecret)
+	require.NoError(t, err)
+	require.Equal(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a", hex.EncodeToString(alicePublic))
+	bobPublic, err := X25519Base(bobSecret)
+	require.NoError(t, err)
+	require.Equal(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f", hex.EncodeToString(bobPublic))
+
+	k1, err := X25519(aliceSecret, bobPublic)
+	require.NoError(t, err)
+	k2, err := X25519(bobSecret, alicePublic)
+	require.NoError(t, err)
+	require.Equal(t, k1, k2)
+	require.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(k1))
+}
+
+func TestX25519MatchesReference(t *testing.T) {
+	for i := 0; i < 32; i++ {
+		secret, public, err := NewX25519Key(crand.Reader)
+		require.NoError(t, err)
+		expected, err := curve25519.X25519(secret, curve25519.Basepoint)
+		require.NoError(t, err)
+		require.Equal(t, expected, public)
+
+		ladder, err := X25519(secret, X25519Basepoint())
+		require.NoError(t, err)
+		require.Equal(t, expected, ladder)
+
+		peer := make([]byte, X25519PointSize)
+		_, _ = crand.Read(peer)
+		expected, err = curve25519.X25519(secret, peer)
+		require.NoError(t, err)
+		shared, err := X25519(secret, peer)
+		require.NoError(t, err)
+		require.Equal(t, expected, shared)
+	}
+}
+
+func TestX25519LowOrder(t *testing.T) {
+	secret, _, err := NewX25519Key(crand.Reader)
+	require.NoError(t, err)
+	for _, h := range []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b800",
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	} {
+		point, _ := hex.DecodeString(h)
+		_, err = X25519(secret, point)
+		require.Error(t, err, h)
+	}
+	_, err = X25519(secret[:31], X25519Basepoint())
+	require.Error(t, err)
+	_, err = X25519(secret, X25519Basepoint()[:31])
+	require.Error(t, err)
+	_, _, err = NewX25519Key(nil)
+	require.Error(t, err)
+}
+
+func TestPointEd25519ToMontgomery(t *testing.T) {
+	ed := ED25519()
+	require.Equal(t, X25519Basepoint(), ed.Point.Generator().(*PointEd25519).ToMontgomery())
+	require.Equal(t, make([]byte, X25519PointSize), ed.Point.Identity().(*PointEd25519).ToMontgomery())
+
+	// X25519 of an Edwards public key equals the Montgomery form of the Edwards product
+	secret, _, err := NewX25519Key(crand.Reader)
+	require.NoError(t, err)
+	s, err := new(ScalarEd25519).SetBytesClamping(secret)
+	require.NoError(t, err)
+	p := ed.Point.Random(crand.Reader).(*PointEd25519)
+	shared, err := X25519(secret, p.ToMontgomery())
+	require.NoError(t, err)
+	require.Equal(t, p.Mul(s).(*PointEd25519).ToMontgomery(), shared)
+}
+
+func TestPointEd25519FromMontgomery(t *testing.T) {
+	ed := ED25519()
+	for i := 0; i < 8; i++ {
+		p := ed.Point.Random(crand.Reader).(*PointEd25519)
+		q, err := new(PointEd25519).FromMontgomery(p.ToMontgomery())
+		require.NoError(t, err)
+		require.True(t, q.Equal(p) || q.Equal(p.Neg()))
+	}
+	g, err := new(PointEd25519).FromMontgomery(X25519Basepoint())
+	require.NoError(t, err)
+	require.True(t, g.Equal(ed.Point.Generator()) || g.Equal(ed.Point.Generator().Neg()))
+
+	// u = -1 and points on the twist are rejected
+	minusOne, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	_, err = new(PointEd25519).FromMontgomery(minusOne)
+	require.Error(t, err)
+	twist := make([]byte, X25519PointSize)
+	twist[0] = 2
+	_, err = new(PointEd25519).FromMontgomery(twist)
+	require.Error(t, err)
+	_, err = new(PointEd25519).FromMontgomery(twist[:31])
+	require.Error(t, err)
+}
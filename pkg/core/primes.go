@@ -7,10 +7,13 @@
 package core
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
+	"runtime"
 )
 
 // GenerateSafePrime creates a prime number `p`
@@ -40,3 +43,125 @@ func GenerateSafePrime(bits uint) (*big.Int, error) {
 
 	return p, nil
 }
+
+// GenerateSafePrimes searches for `count` distinct `bits` sized safe primes with a pool of `workers` goroutines,
+// or runtime.NumCPU() of them if `workers` is not positive. The search stops as soon as the context is done.
+func GenerateSafePrimes(ctx context.Context, bits uint, count, workers int) ([]*big.Int, error) {
+	if bits < 3 {
+		return nil, fmt.Errorf("safe prime size must be at least 3-bits")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("count must be positive")
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	// Stop the remaining workers once enough primes are found
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each running worker sends exactly one value, so the buffers never block
+	primes := make(chan *big.Int, workers)
+	errors := make(chan error, workers)
+	search := func() {
+		p, err := searchSafePrime(ctx, rand.Reader, bits)
+		if err != nil {
+			errors <- err
+			return
+		}
+		primes <- p
+	}
+	for i := 0; i < workers; i++ {
+		go search()
+	}
+
+	result := make([]*big.Int, 0, count)
+	for len(result) < count {
+		select {
+		case p := <-primes:
+			if !containsInt(result, p) {
+				result = append(result, p)
+			}
+			if len(result) < count {
+				// Replace the worker that finished
+				go search()
+			}
+		case err := <-errors:
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GenerateSafePrimeFromReader creates a `bits` sized safe prime from the randomness of `reader`.
+// Unlike GenerateSafePrime, the result only depends on the bytes read, so a deterministic reader such as
+// an XOF of a seed generates the same prime every time. This is meant for tests and test vectors:
+// the prime is only secret if the reader is.
+func GenerateSafePrimeFromReader(reader io.Reader, bits uint) (*big.Int, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("reader cannot be nil")
+	}
+	if bits < 3 {
+		return nil, fmt.Errorf("safe prime size must be at least 3-bits")
+	}
+	return searchSafePrime(context.Background(), reader, bits)
+}
+
+// IsSafePrime checks whether p is a safe prime: both p and (p-1)/2 are prime.
+func IsSafePrime(p *big.Int) bool {
+	if p == nil || p.Cmp(big.NewInt(5)) < 0 {
+		return false
+	}
+	checks := int(math.Max(float64(p.BitLen())/16, 8))
+	return p.ProbablyPrime(checks) && new(big.Int).Rsh(p, 1).ProbablyPrime(checks)
+}
+
+// searchSafePrime draws candidates q of `bits`-1 bits from `reader` until 2q+1 is a safe prime,
+// checking the context between candidates.
+func searchSafePrime(ctx context.Context, reader io.Reader, bits uint) (*big.Int, error) {
+	qBits := bits - 1
+	buf := make([]byte, (qBits+7)/8)
+	// Number of unused top bits of the first byte
+	excess := uint(len(buf))*8 - qBits
+	checks := int(math.Max(float64(bits)/16, 8))
+	q := new(big.Int)
+	p := new(big.Int)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		buf[0] &= byte(0xff >> excess)
+		// Set the top two bits like rand.Prime so that the product of two such primes has 2·bits bits,
+		// and the lowest bit so that q is odd
+		buf[0] |= byte(0xc0 >> excess)
+		if excess == 7 {
+			buf[1] |= 0x80
+		}
+		buf[len(buf)-1] |= 1
+		q.SetBytes(buf)
+
+		// ProbablyPrime(0) only runs the Baillie-PSW test, which is enough to discard candidates cheaply
+		if !q.ProbablyPrime(0) {
+			continue
+		}
+		p.Lsh(q, 1)
+		p.Add(p, One)
+		if p.ProbablyPrime(0) && q.ProbablyPrime(checks) && p.ProbablyPrime(checks) {
+			return new(big.Int).Set(p), nil
+		}
+	}
+}
+
+// containsInt checks whether x is in values.
+func containsInt(values []*big.Int, x *big.Int) bool {
+	for _, v := range values {
+		if v.Cmp(x) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func seededReader(seed string) io.Reader {
+	h := sha3.NewShake256()
+	_, _ = h.Write([]byte(seed))
+	return h
+}
+
+func TestGenerateSafePrimes(t *testing.T) {
+	p, err := GenerateSafePrimes(context.Background(), 3, 1, 2)
+	require.NoError(t, err)
+	require.Len(t, p, 1)
+	require.Equal(t, int64(7), p[0].Int64())
+
+	for _, bits := range []uint{10, 17, 128} {
+		primes, err := GenerateSafePrimes(context.Background(), bits, 2, 4)
+		require.NoError(t, err)
+		require.Len(t, primes, 2)
+		require.NotEqual(t, 0, primes[0].Cmp(primes[1]))
+		for _, p := range primes {
+			require.True(t, IsSafePrime(p))
+			require.Equal(t, int(bits), p.BitLen())
+		}
+	}
+}
+
+func TestGenerateSafePrimesErrors(t *testing.T) {
+	_, err := GenerateSafePrimes(context.Background(), 2, 1, 1)
+	require.Error(t, err)
+	_, err = GenerateSafePrimes(context.Background(), 64, 0, 1)
+	require.Error(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GenerateSafePrimes(ctx, 1024, 1, 0)
+	require.ErrorIs(t, err, context.Canceled)
+	// the search never ends as there is only one 3-bit safe prime, so the deadline stops it
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = GenerateSafePrimes(ctx, 3, 2, 2)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestGenerateSafePrimeFromReader(t *testing.T) {
+	p1, err := GenerateSafePrimeFromReader(seededReader("seed"), 256)
+	require.NoError(t, err)
+	p2, err := GenerateSafePrimeFromReader(seededReader("seed"), 256)
+	require.NoError(t, err)
+	p3, err := GenerateSafePrimeFromReader(seededReader("other seed"), 256)
+	require.NoError(t, err)
+	require.Equal(t, p1, p2)
+	require.NotEqual(t, p1, p3)
+	for _, p := range []*big.Int{p1, p3} {
+		require.True(t, IsSafePrime(p))
+		require.Equal(t, 256, p.BitLen())
+	}
+
+	_, err = GenerateSafePrimeFromReader(bytes.NewReader([]byte{1, 2, 3}), 256)
+	require.Error(t, err)
+	_, err = GenerateSafePrimeFromReader(nil, 256)
+	require.Error(t, err)
+}
+
+func TestIsSafePrime(t *testing.T) {
+	for _, p := range []int64{5, 7, 11, 23, 47, 59, 83, 107} {
+		require.True(t, IsSafePrime(big.NewInt(p)), p)
+	}
+	for _, p := range []int64{-7, 0, 1, 2, 3, 13, 15, 29, 31} {
+		require.False(t, IsSafePrime(big.NewInt(p)), p)
+	}
+	require.False(t, IsSafePrime(nil))
+}
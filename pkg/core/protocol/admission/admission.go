@@ -0,0 +1,506 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package admission protects services that run expensive protocols, such as
+// DKG or threshold signing, against computational denial of service by
+// unauthenticated peers.
+//
+// Before a session is started the service hands the peer a Challenge: a client
+// puzzle authenticated with a key only the service knows, so no state is kept
+// for unsolved challenges. The peer proves it spent work by finding a counter
+// such that SHA-256 of the challenge and the counter has Difficulty leading zero
+// bits, which takes about 2^Difficulty hashes to find and a single hash to check.
+//
+// Redeeming a solution yields a Ticket that allows a limited number of sessions
+// of one kind for one peer within a time window. Each challenge can only be
+// redeemed once. The service calls Start with the ticket and a constructor for
+// the session, the constructor is only invoked once the ticket has been admitted,
+// so rejected peers never cause the service to generate keys, primes or proofs.
+package admission
+
+import (
+	"bytes"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/bits"
+	"sync"
+	"time"
+
+	"git.sr.ht/~sircmpwn/go-bare"
+
+	"github.com/etclab/kryptology/pkg/core/protocol"
+)
+
+const (
+	// DefaultDifficulty is the number of leading zero bits required when no difficulty is set for a kind
+	DefaultDifficulty = 20
+	// MaxDifficulty is the largest difficulty a gate will issue
+	MaxDifficulty = 40
+	// DefaultChallengeTTL is how long a challenge can be solved and redeemed
+	DefaultChallengeTTL = time.Minute
+	// DefaultTicketTTL is how long a ticket admits sessions
+	DefaultTicketTTL = 10 * time.Minute
+	// DefaultSessionsPerTicket is the number of sessions a ticket admits
+	DefaultSessionsPerTicket = 1
+
+	nonceSize      = 16
+	challengeLabel = "kryptology admission challenge v1"
+	puzzleLabel    = "kryptology admission puzzle v1"
+	ticketLabel    = "kryptology admission ticket v1"
+)
+
+var (
+	// ErrInvalidTag is returned when a challenge or ticket was not issued by this gate
+	ErrInvalidTag = fmt.Errorf("invalid authentication tag")
+	// ErrExpired is returned when a challenge or ticket is used after its expiry
+	ErrExpired = fmt.Errorf("expired")
+	// ErrInsufficientWork is returned when a solution does not meet the difficulty
+	ErrInsufficientWork = fmt.Errorf("insufficient work")
+	// ErrReplayed is returned when a challenge is redeemed twice
+	ErrReplayed = fmt.Errorf("challenge already redeemed")
+	// ErrTicketExhausted is returned when a ticket has admitted all its sessions
+	ErrTicketExhausted = fmt.Errorf("ticket exhausted")
+	// ErrMismatch is returned when a challenge or ticket is presented by another peer or for another session kind
+	ErrMismatch = fmt.Errorf("peer or session kind mismatch")
+	// ErrNoSolution is returned by Solve when no solution was found within the allowed attempts
+	ErrNoSolution = fmt.Errorf("no solution found")
+)
+
+// Challenge is a client puzzle issued to a peer for a kind of session
+type Challenge struct {
+	Peer       string
+	Kind       string
+	Nonce      []byte
+	Difficulty uint8
+	Expiry     int64
+	Tag        []byte
+}
+
+// Solution is a solved challenge
+type Solution struct {
+	Challenge *Challenge
+	Counter   uint64
+}
+
+// Ticket admits a number of sessions of one kind for one peer until its expiry
+type Ticket struct {
+	Peer     string
+	Kind     string
+	Id       []byte
+	Sessions uint32
+	Expiry   int64
+	Tag      []byte
+}
+
+// Gate issues challenges and tickets and admits sessions.
+// The exported fields must be set before the gate is used
+type Gate struct {
+	// ChallengeTTL is the lifetime of challenges, DefaultChallengeTTL is used when zero
+	ChallengeTTL time.Duration
+	// TicketTTL is the lifetime of tickets, DefaultTicketTTL is used when zero
+	TicketTTL time.Duration
+	// SessionsPerTicket is the number of sessions a ticket admits, DefaultSessionsPerTicket is used when zero
+	SessionsPerTicket uint32
+	// Now returns the current time, time.Now is used when nil
+	Now func() time.Time
+
+	key          []byte
+	mu           sync.Mutex
+	difficulties map[string]uint8
+	redeemed     map[string]int64
+	admitted     map[string]*admittedTicket
+}
+
+type admittedTicket struct {
+	used   uint32
+	expiry int64
+}
+
+// NewGate creates a gate that authenticates its challenges and tickets with `key`,
+// which must be at least 32 bytes. Gates sharing a key accept each other's challenges
+// and tickets but keep track of redemptions separately. A random key is used when nil
+func NewGate(key []byte) (*Gate, error) {
+	if key == nil {
+		key = make([]byte, 32)
+		if _, err := crand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+	if len(key) < 32 {
+		return nil, fmt.Errorf("key must be at least 32 bytes")
+	}
+	return &Gate{
+		key:          append([]byte{}, key...),
+		difficulties: make(map[string]uint8),
+		redeemed:     make(map[string]int64),
+		admitted:     make(map[string]*admittedTicket),
+	}, nil
+}
+
+// SetDifficulty sets the number of leading zero bits required for sessions of `kind`.
+// Expensive sessions such as DKG should require more work than cheap ones
+func (g *Gate) SetDifficulty(kind string, difficulty uint8) error {
+	if difficulty > MaxDifficulty {
+		return fmt.Errorf("difficulty must be at most %d", MaxDifficulty)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.difficulties[kind] = difficulty
+	return nil
+}
+
+// NewChallenge issues a challenge to `peer` for a session of `kind`
+func (g *Gate) NewChallenge(peer, kind string) (*Challenge, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := crand.Read(nonce); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	difficulty, ok := g.difficulties[kind]
+	g.mu.Unlock()
+	if !ok {
+		difficulty = DefaultDifficulty
+	}
+	ch := &Challenge{
+		Peer:       peer,
+		Kind:       kind,
+		Nonce:      nonce,
+		Difficulty: difficulty,
+		Expiry:     g.now().Add(g.challengeTTL()).Unix(),
+	}
+	ch.Tag = g.mac(challengeLabel, ch.fields())
+	return ch, nil
+}
+
+// Redeem checks a solution presented by `peer` and returns a ticket
+func (g *Gate) Redeem(peer string, sol *Solution) (*Ticket, error) {
+	if sol == nil || sol.Challenge == nil {
+		return nil, fmt.Errorf("invalid solution")
+	}
+	ch := sol.Challenge
+	if !hmac.Equal(ch.Tag, g.mac(challengeLabel, ch.fields())) {
+		return nil, ErrInvalidTag
+	}
+	if ch.Peer != peer {
+		return nil, ErrMismatch
+	}
+	now := g.now().Unix()
+	if now > ch.Expiry {
+		return nil, ErrExpired
+	}
+	if !sol.valid() {
+		return nil, ErrInsufficientWork
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prune(now)
+	nonce := hex.EncodeToString(ch.Nonce)
+	if _, ok := g.redeemed[nonce]; ok {
+		return nil, ErrReplayed
+	}
+	g.redeemed[nonce] = ch.Expiry
+
+	id := make([]byte, nonceSize)
+	if _, err := crand.Read(id); err != nil {
+		return nil, err
+	}
+	t := &Ticket{
+		Peer:     ch.Peer,
+		Kind:     ch.Kind,
+		Id:       id,
+		Sessions: g.sessionsPerTicket(),
+		Expiry:   g.now().Add(g.ticketTTL()).Unix(),
+	}
+	t.Tag = g.mac(ticketLabel, t.fields())
+	return t, nil
+}
+
+// Admit consumes one session of `ticket` for `peer` to start a session of `kind`
+func (g *Gate) Admit(peer, kind string, ticket *Ticket) error {
+	if ticket == nil {
+		return fmt.Errorf("invalid ticket")
+	}
+	if !hmac.Equal(ticket.Tag, g.mac(ticketLabel, ticket.fields())) {
+		return ErrInvalidTag
+	}
+	if ticket.Peer != peer || ticket.Kind != kind {
+		return ErrMismatch
+	}
+	now := g.now().Unix()
+	if now > ticket.Expiry {
+		return ErrExpired
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prune(now)
+	id := hex.EncodeToString(ticket.Id)
+	a, ok := g.admitted[id]
+	if !ok {
+		a = &admittedTicket{expiry: ticket.Expiry}
+		g.admitted[id] = a
+	}
+	if a.used >= ticket.Sessions {
+		return ErrTicketExhausted
+	}
+	a.used++
+	return nil
+}
+
+// Start admits a session of `kind` for `peer` and only then calls `newSession` to create it
+func (g *Gate) Start(peer, kind string, ticket *Ticket, newSession func() (protocol.Iterator, error)) (protocol.Iterator, error) {
+	if newSession == nil {
+		return nil, fmt.Errorf("invalid session constructor")
+	}
+	if err := g.Admit(peer, kind, ticket); err != nil {
+		return nil, err
+	}
+	return newSession()
+}
+
+// Solve searches for a solution to `ch`, trying at most `maxAttempts` counters
+func Solve(ch *Challenge, maxAttempts uint64) (*Solution, error) {
+	if ch == nil {
+		return nil, fmt.Errorf("invalid challenge")
+	}
+	if ch.Difficulty > MaxDifficulty {
+		return nil, fmt.Errorf("difficulty must be at most %d", MaxDifficulty)
+	}
+	prefix := ch.puzzlePrefix()
+	for counter := uint64(0); counter < maxAttempts; counter++ {
+		if leadingZeros(puzzleHash(prefix, counter)) >= int(ch.Difficulty) {
+			return &Solution{Challenge: ch, Counter: counter}, nil
+		}
+	}
+	return nil, ErrNoSolution
+}
+
+func (sol *Solution) valid() bool {
+	return sol.Challenge.Difficulty <= MaxDifficulty &&
+		leadingZeros(puzzleHash(sol.Challenge.puzzlePrefix(), sol.Counter)) >= int(sol.Challenge.Difficulty)
+}
+
+func (g *Gate) mac(label string, fields [][]byte) []byte {
+	h := hmac.New(sha256.New, g.key)
+	_, _ = h.Write([]byte(label))
+	writeFields(h, fields)
+	return h.Sum(nil)
+}
+
+// prune forgets redeemed challenges and admitted tickets that have expired,
+// they are rejected by their expiry from then on
+func (g *Gate) prune(now int64) {
+	for k, expiry := range g.redeemed {
+		if now > expiry {
+			delete(g.redeemed, k)
+		}
+	}
+	for k, a := range g.admitted {
+		if now > a.expiry {
+			delete(g.admitted, k)
+		}
+	}
+}
+
+func (g *Gate) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+func (g *Gate) challengeTTL() time.Duration {
+	if g.ChallengeTTL > 0 {
+		return g.ChallengeTTL
+	}
+	return DefaultChallengeTTL
+}
+
+func (g *Gate) ticketTTL() time.Duration {
+	if g.TicketTTL > 0 {
+		return g.TicketTTL
+	}
+	return DefaultTicketTTL
+}
+
+func (g *Gate) sessionsPerTicket() uint32 {
+	if g.SessionsPerTicket > 0 {
+		return g.SessionsPerTicket
+	}
+	return DefaultSessionsPerTicket
+}
+
+func (ch *Challenge) fields() [][]byte {
+	return [][]byte{
+		[]byte(ch.Peer),
+		[]byte(ch.Kind),
+		ch.Nonce,
+		{ch.Difficulty},
+		uint64Bytes(uint64(ch.Expiry)),
+	}
+}
+
+func (ch *Challenge) puzzlePrefix() []byte {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString(puzzleLabel)
+	writeFields(&buf, ch.fields())
+	writeFields(&buf, [][]byte{ch.Tag})
+	return buf.Bytes()
+}
+
+func (t *Ticket) fields() [][]byte {
+	return [][]byte{
+		[]byte(t.Peer),
+		[]byte(t.Kind),
+		t.Id,
+		uint64Bytes(uint64(t.Sessions)),
+		uint64Bytes(uint64(t.Expiry)),
+	}
+}
+
+// writeFields writes length prefixed fields so distinct field lists never collide
+func writeFields(w io.Writer, fields [][]byte) {
+	for _, f := range fields {
+		_, _ = w.Write(uint64Bytes(uint64(len(f))))
+		_, _ = w.Write(f)
+	}
+}
+
+func uint64Bytes(v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return b[:]
+}
+
+func puzzleHash(prefix []byte, counter uint64) [32]byte {
+	h := sha256.New()
+	_, _ = h.Write(prefix)
+	_, _ = h.Write(uint64Bytes(counter))
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+func leadingZeros(h [32]byte) int {
+	n := 0
+	for _, b := range h {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+type challengeMarshal struct {
+	Peer       string `bare:"peer"`
+	Kind       string `bare:"kind"`
+	Nonce      []byte `bare:"nonce"`
+	Difficulty uint8  `bare:"difficulty"`
+	Expiry     int64  `bare:"expiry"`
+	Tag        []byte `bare:"tag"`
+}
+
+type solutionMarshal struct {
+	Challenge []byte `bare:"challenge"`
+	Counter   uint64 `bare:"counter"`
+}
+
+type ticketMarshal struct {
+	Peer     string `bare:"peer"`
+	Kind     string `bare:"kind"`
+	Id       []byte `bare:"id"`
+	Sessions uint32 `bare:"sessions"`
+	Expiry   int64  `bare:"expiry"`
+	Tag      []byte `bare:"tag"`
+}
+
+// MarshalBinary serializes a challenge to bytes
+func (ch Challenge) MarshalBinary() ([]byte, error) {
+	return bare.Marshal(&challengeMarshal{
+		Peer:       ch.Peer,
+		Kind:       ch.Kind,
+		Nonce:      ch.Nonce,
+		Difficulty: ch.Difficulty,
+		Expiry:     ch.Expiry,
+		Tag:        ch.Tag,
+	})
+}
+
+// UnmarshalBinary deserializes a challenge from bytes
+func (ch *Challenge) UnmarshalBinary(data []byte) error {
+	tv := new(challengeMarshal)
+	if err := bare.Unmarshal(data, tv); err != nil {
+		return err
+	}
+	ch.Peer = tv.Peer
+	ch.Kind = tv.Kind
+	ch.Nonce = tv.Nonce
+	ch.Difficulty = tv.Difficulty
+	ch.Expiry = tv.Expiry
+	ch.Tag = tv.Tag
+	return nil
+}
+
+// MarshalBinary serializes a solution to bytes
+func (sol Solution) MarshalBinary() ([]byte, error) {
+	if sol.Challenge == nil {
+		return nil, fmt.Errorf("invalid solution")
+	}
+	ch, err := sol.Challenge.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return bare.Marshal(&solutionMarshal{Challenge: ch, Counter: sol.Counter})
+}
+
+// UnmarshalBinary deserializes a solution from bytes
+func (sol *Solution) UnmarshalBinary(data []byte) error {
+	tv := new(solutionMarshal)
+	if err := bare.Unmarshal(data, tv); err != nil {
+		return err
+	}
+	ch := new(Challenge)
+	if err := ch.UnmarshalBinary(tv.Challenge); err != nil {
+		return err
+	}
+	sol.Challenge = ch
+	sol.Counter = tv.Counter
+	return nil
+}
+
+// MarshalBinary serializes a ticket to bytes
+func (t Ticket) MarshalBinary() ([]byte, error) {
+	return bare.Marshal(&ticketMarshal{
+		Peer:     t.Peer,
+		Kind:     t.Kind,
+		Id:       t.Id,
+		Sessions: t.Sessions,
+		Expiry:   t.Expiry,
+		Tag:      t.Tag,
+	})
+}
+
+// UnmarshalBinary deserializes a ticket from bytes
+func (t *Ticket) UnmarshalBinary(data []byte) error {
+	tv := new(ticketMarshal)
+	if err := bare.Unmarshal(data, tv); err != nil {
+		return err
+	}
+	t.Peer = tv.Peer
+	t.Kind = tv.Kind
+	t.Id = tv.Id
+	t.Sessions = tv.Sessions
+	t.Expiry = tv.Expiry
+	t.Tag = tv.Tag
+	return nil
+}
@@ -0,0 +1,201 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package admission
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/protocol"
+)
+
+const (
+	testPeer = "peer-1"
+	testKind = protocol.Dkls18Dkg
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestGate(t *testing.T) (*Gate, *testClock) {
+	gate, err := NewGate(nil)
+	require.NoError(t, err)
+	clock := &testClock{time.Unix(1600000000, 0)}
+	gate.Now = clock.Now
+	require.NoError(t, gate.SetDifficulty(testKind, 8))
+	return gate, clock
+}
+
+func solve(t *testing.T, gate *Gate, peer, kind string) *Solution {
+	ch, err := gate.NewChallenge(peer, kind)
+	require.NoError(t, err)
+	sol, err := Solve(ch, 1<<20)
+	require.NoError(t, err)
+	return sol
+}
+
+type noopIterator struct{}
+
+func (noopIterator) Next(*protocol.Message) (*protocol.Message, error) {
+	return nil, protocol.ErrProtocolFinished
+}
+
+func (noopIterator) Result(uint) (*protocol.Message, error) {
+	return nil, nil
+}
+
+func TestAdmissionHappyPath(t *testing.T) {
+	gate, _ := newTestGate(t)
+	gate.SessionsPerTicket = 2
+	sol := solve(t, gate, testPeer, testKind)
+	require.Equal(t, uint8(8), sol.Challenge.Difficulty)
+	ticket, err := gate.Redeem(testPeer, sol)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), ticket.Sessions)
+
+	calls := 0
+	newSession := func() (protocol.Iterator, error) {
+		calls++
+		return noopIterator{}, nil
+	}
+	for i := 0; i < 2; i++ {
+		it, err := gate.Start(testPeer, testKind, ticket, newSession)
+		require.NoError(t, err)
+		require.NotNil(t, it)
+	}
+	_, err = gate.Start(testPeer, testKind, ticket, newSession)
+	require.Equal(t, ErrTicketExhausted, err)
+	require.Equal(t, 2, calls)
+}
+
+func TestAdmissionRejections(t *testing.T) {
+	gate, clock := newTestGate(t)
+
+	// Replay
+	sol := solve(t, gate, testPeer, testKind)
+	_, err := gate.Redeem(testPeer, sol)
+	require.NoError(t, err)
+	_, err = gate.Redeem(testPeer, sol)
+	require.Equal(t, ErrReplayed, err)
+
+	// Another peer cannot use the challenge
+	sol = solve(t, gate, testPeer, testKind)
+	_, err = gate.Redeem("peer-2", sol)
+	require.Equal(t, ErrMismatch, err)
+
+	// Lowering the difficulty invalidates the tag
+	sol.Challenge.Difficulty = 0
+	_, err = gate.Redeem(testPeer, sol)
+	require.Equal(t, ErrInvalidTag, err)
+
+	// Not enough work
+	sol = solve(t, gate, testPeer, testKind)
+	for sol.valid() {
+		sol.Counter++
+	}
+	_, err = gate.Redeem(testPeer, sol)
+	require.Equal(t, ErrInsufficientWork, err)
+
+	// Challenges issued by another gate
+	other, err := NewGate(nil)
+	require.NoError(t, err)
+	require.NoError(t, other.SetDifficulty(testKind, 8))
+	sol = solve(t, other, testPeer, testKind)
+	_, err = gate.Redeem(testPeer, sol)
+	require.Equal(t, ErrInvalidTag, err)
+
+	// Expired challenge
+	sol = solve(t, gate, testPeer, testKind)
+	clock.now = clock.now.Add(DefaultChallengeTTL + time.Second)
+	_, err = gate.Redeem(testPeer, sol)
+	require.Equal(t, ErrExpired, err)
+
+	_, err = gate.Redeem(testPeer, nil)
+	require.Error(t, err)
+}
+
+func TestAdmissionTicketRejections(t *testing.T) {
+	gate, clock := newTestGate(t)
+	ticket, err := gate.Redeem(testPeer, solve(t, gate, testPeer, testKind))
+	require.NoError(t, err)
+
+	require.Equal(t, ErrMismatch, gate.Admit("peer-2", testKind, ticket))
+	require.Equal(t, ErrMismatch, gate.Admit(testPeer, protocol.Dkls18Sign, ticket))
+
+	forged := *ticket
+	forged.Sessions = 100
+	require.Equal(t, ErrInvalidTag, gate.Admit(testPeer, testKind, &forged))
+
+	called := false
+	_, err = gate.Start(testPeer, testKind, nil, func() (protocol.Iterator, error) {
+		called = true
+		return noopIterator{}, nil
+	})
+	require.Error(t, err)
+	require.False(t, called)
+
+	clock.now = clock.now.Add(DefaultTicketTTL + time.Second)
+	require.Equal(t, ErrExpired, gate.Admit(testPeer, testKind, ticket))
+
+	// Expired redemptions are forgotten
+	_, err = gate.Redeem(testPeer, solve(t, gate, testPeer, testKind))
+	require.NoError(t, err)
+	require.Len(t, gate.redeemed, 1)
+}
+
+func TestAdmissionSharedKey(t *testing.T) {
+	key := make([]byte, 32)
+	g1, err := NewGate(key)
+	require.NoError(t, err)
+	g2, err := NewGate(key)
+	require.NoError(t, err)
+	require.NoError(t, g1.SetDifficulty(testKind, 4))
+	ticket, err := g2.Redeem(testPeer, solve(t, g1, testPeer, testKind))
+	require.NoError(t, err)
+	require.NoError(t, g1.Admit(testPeer, testKind, ticket))
+
+	_, err = NewGate(key[:31])
+	require.Error(t, err)
+	require.Error(t, g1.SetDifficulty(testKind, MaxDifficulty+1))
+}
+
+func TestSolveNoSolution(t *testing.T) {
+	gate, _ := newTestGate(t)
+	require.NoError(t, gate.SetDifficulty(testKind, MaxDifficulty))
+	ch, err := gate.NewChallenge(testPeer, testKind)
+	require.NoError(t, err)
+	_, err = Solve(ch, 16)
+	require.Equal(t, ErrNoSolution, err)
+	_, err = Solve(nil, 16)
+	require.Error(t, err)
+}
+
+func TestAdmissionMarshal(t *testing.T) {
+	gate, _ := newTestGate(t)
+	sol := solve(t, gate, testPeer, testKind)
+	data, err := sol.MarshalBinary()
+	require.NoError(t, err)
+	sol2 := new(Solution)
+	require.NoError(t, sol2.UnmarshalBinary(data))
+	require.Equal(t, sol, sol2)
+
+	ticket, err := gate.Redeem(testPeer, sol2)
+	require.NoError(t, err)
+	data, err = ticket.MarshalBinary()
+	require.NoError(t, err)
+	ticket2 := new(Ticket)
+	require.NoError(t, ticket2.UnmarshalBinary(data))
+	require.Equal(t, ticket, ticket2)
+	require.NoError(t, gate.Admit(testPeer, testKind, ticket2))
+}
@@ -0,0 +1,180 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ErrInputClosed is returned when the input channel is closed before the protocol finished
+var ErrInputClosed = fmt.Errorf("input closed before the protocol finished")
+
+// AsyncIterator runs an Iterator in its own goroutine and exchanges messages over channels.
+//
+// Every message received on Input is passed to Next and every message returned by Next is
+// sent on Output. A nil message means the iterator has nothing to send; as the Iterator
+// interface only reports completion through Next, the wrapper then calls Next(nil) once to
+// learn whether the protocol finished, which is how two-party iterators signal their last
+// step. Closing Input also results in a final Next(nil) call.
+//
+// When the execution ends, because the protocol finished, an error occurred or the context
+// was cancelled, Output is closed and the terminal error, nil on success, is sent on Errors.
+type AsyncIterator struct {
+	iterator Iterator
+	in       chan *Message
+	out      chan *Message
+	errs     chan error
+	done     chan struct{}
+	err      error
+	mu       sync.Mutex
+}
+
+// NewAsyncIterator starts running `iterator`. When `start` is true, the iterator is run
+// with a nil input straight away, which is needed for the party that speaks first
+func NewAsyncIterator(ctx context.Context, iterator Iterator, start bool) (*AsyncIterator, error) {
+	if ctx == nil || iterator == nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	a := &AsyncIterator{
+		iterator: iterator,
+		in:       make(chan *Message),
+		out:      make(chan *Message, 1),
+		errs:     make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+	go a.run(ctx, start)
+	return a, nil
+}
+
+// Input returns the channel on which received messages must be sent
+func (a *AsyncIterator) Input() chan<- *Message {
+	return a.in
+}
+
+// Output returns the channel on which the messages to send are published
+func (a *AsyncIterator) Output() <-chan *Message {
+	return a.out
+}
+
+// Errors returns the channel receiving the terminal error, nil when the protocol finished
+func (a *AsyncIterator) Errors() <-chan error {
+	return a.errs
+}
+
+// Done returns a channel that is closed when the execution has ended
+func (a *AsyncIterator) Done() <-chan struct{} {
+	return a.done
+}
+
+// Wait blocks until the execution has ended and returns the terminal error
+func (a *AsyncIterator) Wait() error {
+	<-a.done
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Result returns the result of the iterator once the protocol has finished
+func (a *AsyncIterator) Result(version uint) (*Message, error) {
+	if err := a.Wait(); err != nil {
+		return nil, err
+	}
+	return a.iterator.Result(version)
+}
+
+func (a *AsyncIterator) run(ctx context.Context, start bool) {
+	err := a.loop(ctx, start)
+	a.mu.Lock()
+	a.err = err
+	a.mu.Unlock()
+	close(a.out)
+	a.errs <- err
+	close(a.errs)
+	close(a.done)
+}
+
+// loop returns nil when the protocol finished successfully
+func (a *AsyncIterator) loop(ctx context.Context, start bool) error {
+	if start {
+		if finished, err := a.step(ctx, nil); finished || err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case input, ok := <-a.in:
+			if !ok {
+				finished, err := a.next(ctx, nil)
+				if err == nil && !finished {
+					err = ErrInputClosed
+				}
+				return err
+			}
+			if finished, err := a.step(ctx, input); finished || err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// step runs Next with `input` and, when it produces no message, checks whether the protocol finished
+func (a *AsyncIterator) step(ctx context.Context, input *Message) (bool, error) {
+	output, err := a.iterator.Next(input)
+	if err == ErrProtocolFinished {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if output != nil {
+		return false, a.send(ctx, output)
+	}
+	return a.next(ctx, nil)
+}
+
+// next runs Next once and publishes any output
+func (a *AsyncIterator) next(ctx context.Context, input *Message) (bool, error) {
+	output, err := a.iterator.Next(input)
+	if err == ErrProtocolFinished {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if output != nil {
+		return false, a.send(ctx, output)
+	}
+	return false, nil
+}
+
+func (a *AsyncIterator) send(ctx context.Context, output *Message) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case a.out <- output:
+		return nil
+	}
+}
+
+// Connect forwards the output of `from` to the input of `to` and closes the
+// input of `to` once `from` has ended. It returns immediately
+func Connect(from, to *AsyncIterator) {
+	go func() {
+		defer close(to.in)
+		for msg := range from.out {
+			select {
+			case to.in <- msg:
+			case <-to.done:
+				return
+			}
+		}
+	}()
+}
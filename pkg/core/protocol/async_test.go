@@ -0,0 +1,100 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// counter sends a counter back and forth, incrementing it, until it reaches `limit`.
+// Like the DKLs18 iterators, it only reports completion on the call to Next after its last step
+type counter struct {
+	limit int
+	last  int
+	done  bool
+}
+
+func (c *counter) Next(input *Message) (*Message, error) {
+	if c.done {
+		return nil, ErrProtocolFinished
+	}
+	v := 0
+	if input != nil {
+		if _, err := fmt.Sscanf(string(input.Payloads["n"]), "%d", &v); err != nil {
+			return nil, err
+		}
+	}
+	c.last = v
+	if v >= c.limit {
+		c.done = true
+		return nil, nil
+	}
+	// The party sending the final value is done as well
+	c.last = v + 1
+	c.done = c.last >= c.limit
+	return &Message{Payloads: map[string][]byte{"n": []byte(fmt.Sprint(c.last))}}, nil
+}
+
+func (c *counter) Result(uint) (*Message, error) {
+	if !c.done {
+		return nil, nil
+	}
+	return &Message{Payloads: map[string][]byte{"n": []byte(fmt.Sprint(c.last))}}, nil
+}
+
+func TestAsyncIteratorConnected(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	first, err := NewAsyncIterator(ctx, &counter{limit: 7}, true)
+	require.NoError(t, err)
+	second, err := NewAsyncIterator(ctx, &counter{limit: 7}, false)
+	require.NoError(t, err)
+	Connect(first, second)
+	Connect(second, first)
+
+	require.NoError(t, first.Wait())
+	require.NoError(t, second.Wait())
+	require.NoError(t, <-first.Errors())
+	result, err := second.Result(Version1)
+	require.NoError(t, err)
+	require.Equal(t, "7", string(result.Payloads["n"]))
+}
+
+func TestAsyncIteratorCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a, err := NewAsyncIterator(ctx, &counter{limit: 3}, false)
+	require.NoError(t, err)
+	cancel()
+	require.ErrorIs(t, a.Wait(), context.Canceled)
+	_, ok := <-a.Output()
+	require.False(t, ok)
+	_, err = a.Result(Version1)
+	require.Error(t, err)
+}
+
+func TestAsyncIteratorInputClosed(t *testing.T) {
+	a, err := NewAsyncIterator(context.Background(), &counter{limit: 3}, true)
+	require.NoError(t, err)
+	msg := <-a.Output()
+	require.Equal(t, "1", string(msg.Payloads["n"]))
+	close(a.Input())
+	require.ErrorIs(t, a.Wait(), ErrInputClosed)
+}
+
+func TestAsyncIteratorError(t *testing.T) {
+	a, err := NewAsyncIterator(context.Background(), &counter{limit: 3}, false)
+	require.NoError(t, err)
+	a.Input() <- &Message{Payloads: map[string][]byte{"n": []byte("x")}}
+	require.Error(t, <-a.Errors())
+	_, err = NewAsyncIterator(context.Background(), nil, false)
+	require.Error(t, err)
+}
@@ -0,0 +1,246 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ErrMalformedEncoding is returned when a canonical encoding cannot be parsed.
+var ErrMalformedEncoding = fmt.Errorf("malformed canonical encoding")
+
+// SortedParticipantIds returns the keys of a per-peer map in ascending order.
+// Multi-party rounds exchange `map[uint32]...` values keyed by participant id and
+// Go does not define the iteration order of maps, so anything that is hashed or
+// logged must walk the ids through this function instead of ranging over the map.
+func SortedParticipantIds(ids []uint32) []uint32 {
+	sorted := make([]uint32, len(ids))
+	copy(sorted, ids)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// EncodeParticipantMap serializes a per-peer message map in participant order.
+// The format is
+//
+//	count || (id || len || data)*
+//
+// where count, id and len are big-endian uint32 values and entries are sorted by id.
+// Equal maps always produce identical bytes regardless of insertion order.
+func EncodeParticipantMap(m map[uint32][]byte) []byte {
+	ids := make([]uint32, 0, len(m))
+	size := 4
+	for id, data := range m {
+		ids = append(ids, id)
+		size += 8 + len(data)
+	}
+	ids = SortedParticipantIds(ids)
+
+	out := make([]byte, 0, size)
+	out = appendUint32(out, uint32(len(ids)))
+	for _, id := range ids {
+		out = appendUint32(out, id)
+		out = appendBytes(out, m[id])
+	}
+	return out
+}
+
+// DecodeParticipantMap parses the output of EncodeParticipantMap.
+// Duplicate or out of order ids are rejected so that every map has exactly one valid encoding.
+func DecodeParticipantMap(data []byte) (map[uint32][]byte, error) {
+	r := bytes.NewReader(data)
+	count, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	// Each entry needs at least 8 bytes so bound the allocation by the input size
+	if uint64(count)*8 > uint64(r.Len()) {
+		return nil, ErrMalformedEncoding
+	}
+	m := make(map[uint32][]byte, count)
+	var prev uint32
+	for i := uint32(0); i < count; i++ {
+		id, err := readUint32(r)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && id <= prev {
+			return nil, fmt.Errorf("%w: participant ids are not strictly increasing", ErrMalformedEncoding)
+		}
+		prev = id
+		if m[id], err = readBytes(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes", ErrMalformedEncoding)
+	}
+	return m, nil
+}
+
+// SortedPayloadKeys returns the keys of a `Message.Payloads` or `Message.Metadata` map in canonical order.
+// Keys that are decimal participant ids come first in numeric order, followed by all other keys
+// (such as "broadcast") in lexicographic order.
+func SortedPayloadKeys(keys []string) []string {
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool { return payloadKeyLess(sorted[i], sorted[j]) })
+	return sorted
+}
+
+func payloadKeyLess(a, b string) bool {
+	ia, errA := strconv.ParseUint(a, 10, 32)
+	ib, errB := strconv.ParseUint(b, 10, 32)
+	switch {
+	case errA == nil && errB == nil:
+		if ia != ib {
+			return ia < ib
+		}
+		// "01" and "1" parse to the same id, fall back to the raw string
+		return a < b
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	default:
+		return a < b
+	}
+}
+
+// MarshalCanonical serializes the message deterministically so that it can be hashed into a transcript
+// or written to an audit log. The format is
+//
+//	len || Protocol || Version || count || (len || key || len || payload)* || count || (len || key || len || value)*
+//
+// where all lengths and counts are big-endian uint32 values, Version is a big-endian uint64
+// and map entries are ordered by SortedPayloadKeys.
+func (m *Message) MarshalCanonical() ([]byte, error) {
+	if m == nil {
+		return nil, ErrNotInitialized
+	}
+	out := appendBytes(nil, []byte(m.Protocol))
+	var version [8]byte
+	binary.BigEndian.PutUint64(version[:], uint64(m.Version))
+	out = append(out, version[:]...)
+
+	keys := make([]string, 0, len(m.Payloads))
+	for k := range m.Payloads {
+		keys = append(keys, k)
+	}
+	out = appendUint32(out, uint32(len(keys)))
+	for _, k := range SortedPayloadKeys(keys) {
+		out = appendBytes(out, []byte(k))
+		out = appendBytes(out, m.Payloads[k])
+	}
+
+	keys = keys[:0]
+	for k := range m.Metadata {
+		keys = append(keys, k)
+	}
+	out = appendUint32(out, uint32(len(keys)))
+	for _, k := range SortedPayloadKeys(keys) {
+		out = appendBytes(out, []byte(k))
+		out = appendBytes(out, []byte(m.Metadata[k]))
+	}
+	return out, nil
+}
+
+// UnmarshalCanonical parses the output of MarshalCanonical into this message.
+func (m *Message) UnmarshalCanonical(data []byte) error {
+	r := bytes.NewReader(data)
+	name, err := readBytes(r)
+	if err != nil {
+		return err
+	}
+	var version [8]byte
+	if n, _ := r.Read(version[:]); n != len(version) {
+		return ErrMalformedEncoding
+	}
+	payloads, err := readStringMap(r)
+	if err != nil {
+		return err
+	}
+	metadata, err := readStringMap(r)
+	if err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: trailing bytes", ErrMalformedEncoding)
+	}
+
+	m.Protocol = string(name)
+	m.Version = uint(binary.BigEndian.Uint64(version[:]))
+	m.Payloads = payloads
+	m.Metadata = make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		m.Metadata[k] = string(v)
+	}
+	return nil
+}
+
+func readStringMap(r *bytes.Reader) (map[string][]byte, error) {
+	count, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(count)*8 > uint64(r.Len()) {
+		return nil, ErrMalformedEncoding
+	}
+	out := make(map[string][]byte, count)
+	prev := ""
+	for i := uint32(0); i < count; i++ {
+		k, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		key := string(k)
+		if i > 0 && !payloadKeyLess(prev, key) {
+			return nil, fmt.Errorf("%w: keys are not in canonical order", ErrMalformedEncoding)
+		}
+		prev = key
+		if out[key], err = readBytes(r); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func appendUint32(out []byte, v uint32) []byte {
+	var t [4]byte
+	binary.BigEndian.PutUint32(t[:], v)
+	return append(out, t[:]...)
+}
+
+func appendBytes(out, data []byte) []byte {
+	out = appendUint32(out, uint32(len(data)))
+	return append(out, data...)
+}
+
+func readUint32(r *bytes.Reader) (uint32, error) {
+	var t [4]byte
+	if n, _ := r.Read(t[:]); n != len(t) {
+		return 0, ErrMalformedEncoding
+	}
+	return binary.BigEndian.Uint32(t[:]), nil
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	l, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(l) > uint64(r.Len()) {
+		return nil, ErrMalformedEncoding
+	}
+	out := make([]byte, l)
+	_, _ = r.Read(out)
+	return out, nil
+}
@@ -0,0 +1,80 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeParticipantMapIsOrderIndependent(t *testing.T) {
+	a := map[uint32][]byte{}
+	b := map[uint32][]byte{}
+	ids := []uint32{10, 2, 7, 1, 3}
+	for i, id := range ids {
+		a[id] = []byte{byte(id)}
+		b[ids[len(ids)-1-i]] = []byte{byte(ids[len(ids)-1-i])}
+	}
+	encA := EncodeParticipantMap(a)
+	encB := EncodeParticipantMap(b)
+	require.Equal(t, encA, encB)
+
+	decoded, err := DecodeParticipantMap(encA)
+	require.NoError(t, err)
+	require.Equal(t, a, decoded)
+}
+
+func TestDecodeParticipantMapRejectsNonCanonical(t *testing.T) {
+	enc := EncodeParticipantMap(map[uint32][]byte{1: {1}, 2: {2}})
+	// swap the ids of the two entries
+	enc[7], enc[16] = enc[16], enc[7]
+	_, err := DecodeParticipantMap(enc)
+	require.ErrorIs(t, err, ErrMalformedEncoding)
+
+	enc = EncodeParticipantMap(map[uint32][]byte{1: {1}})
+	_, err = DecodeParticipantMap(append(enc, 0))
+	require.ErrorIs(t, err, ErrMalformedEncoding)
+	_, err = DecodeParticipantMap(enc[:len(enc)-1])
+	require.ErrorIs(t, err, ErrMalformedEncoding)
+	_, err = DecodeParticipantMap([]byte{0xff, 0xff, 0xff, 0xff})
+	require.ErrorIs(t, err, ErrMalformedEncoding)
+}
+
+func TestSortedPayloadKeys(t *testing.T) {
+	keys := []string{"broadcast", "10", "2", "direct", "1"}
+	require.Equal(t, []string{"1", "2", "10", "broadcast", "direct"}, SortedPayloadKeys(keys))
+	// the input is left untouched
+	require.Equal(t, "broadcast", keys[0])
+}
+
+func TestMessageCanonicalRoundTrip(t *testing.T) {
+	msg := &Message{
+		Protocol: Dkls18Dkg,
+		Version:  Version1,
+		Payloads: map[string][]byte{"broadcast": {1, 2, 3}, "3": {4}, "12": {}},
+		Metadata: map[string]string{"round": "2"},
+	}
+	enc, err := msg.MarshalCanonical()
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		again, err := msg.MarshalCanonical()
+		require.NoError(t, err)
+		require.Equal(t, enc, again)
+	}
+
+	decoded := new(Message)
+	require.NoError(t, decoded.UnmarshalCanonical(enc))
+	require.Equal(t, msg, decoded)
+
+	require.ErrorIs(t, decoded.UnmarshalCanonical(enc[:len(enc)-1]), ErrMalformedEncoding)
+	require.ErrorIs(t, decoded.UnmarshalCanonical(append(enc, 0)), ErrMalformedEncoding)
+
+	var nilMsg *Message
+	_, err = nilMsg.MarshalCanonical()
+	require.ErrorIs(t, err, ErrNotInitialized)
+}
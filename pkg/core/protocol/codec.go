@@ -0,0 +1,191 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// CodecMetadataKey is the Message.Metadata key recording the codec used for the payloads.
+// Messages without it were encoded with GobCodec
+const CodecMetadataKey = "codec"
+
+// Codec serializes the payloads of a protocol round.
+// A codec must be able to represent every type the protocol sends, for example
+// payloads containing curves.Scalar or curves.Point interfaces need a codec that
+// knows the concrete types
+type Codec interface {
+	// Name identifies the encoding, e.g. "gob" or "json"
+	Name() string
+	// Version allows the encoding to evolve while keeping the same name
+	Version() uint
+	// Encode serializes `v`
+	Encode(v interface{}) ([]byte, error)
+	// Decode deserializes `data` into `v` which must be a pointer
+	Decode(data []byte, v interface{}) error
+}
+
+var (
+	// GobCodec encodes payloads with encoding/gob and is used unless another codec is selected
+	GobCodec Codec = gobCodec{}
+	// JSONCodec encodes payloads with encoding/json
+	JSONCodec Codec = jsonCodec{}
+
+	codecs = struct {
+		sync.RWMutex
+		registered map[string]Codec
+		selected   map[string]Codec
+	}{
+		registered: map[string]Codec{
+			CodecId(GobCodec):  GobCodec,
+			CodecId(JSONCodec): JSONCodec,
+		},
+		selected: map[string]Codec{},
+	}
+)
+
+// CodecId returns the identifier of `c` recorded in the message metadata
+func CodecId(c Codec) string {
+	return fmt.Sprintf("%s/%d", c.Name(), c.Version())
+}
+
+// RegisterCodec makes `c` available for decoding received messages.
+// Codec identifiers are unique so registering the same one twice fails
+func RegisterCodec(c Codec) error {
+	if c == nil || c.Name() == "" {
+		return fmt.Errorf("invalid codec")
+	}
+	id := CodecId(c)
+	codecs.Lock()
+	defer codecs.Unlock()
+	if _, ok := codecs.registered[id]; ok {
+		return fmt.Errorf("codec %s is already registered", id)
+	}
+	codecs.registered[id] = c
+	return nil
+}
+
+// SetCodec selects `c` for encoding the payloads of `protocol`, registering it if its
+// identifier is not already known. Setting a nil codec restores GobCodec
+func SetCodec(protocol string, c Codec) error {
+	if c == nil {
+		codecs.Lock()
+		delete(codecs.selected, protocol)
+		codecs.Unlock()
+		return nil
+	}
+	if _, err := LookupCodec(CodecId(c)); err != nil {
+		if err = RegisterCodec(c); err != nil {
+			return err
+		}
+	}
+	codecs.Lock()
+	codecs.selected[protocol] = c
+	codecs.Unlock()
+	return nil
+}
+
+// CodecFor returns the codec selected for encoding the payloads of `protocol`
+func CodecFor(protocol string) Codec {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	if c, ok := codecs.selected[protocol]; ok {
+		return c
+	}
+	return GobCodec
+}
+
+// LookupCodec returns the registered codec with identifier `id`
+func LookupCodec(id string) (Codec, error) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	c, ok := codecs.registered[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %s", id)
+	}
+	return c, nil
+}
+
+// EncodePayload serializes `v` with the codec selected for m.Protocol and stores it under `key`.
+// All the payloads of a message must use the same codec
+func (m *Message) EncodePayload(key string, v interface{}) error {
+	c := CodecFor(m.Protocol)
+	id := CodecId(c)
+	if current := m.codecId(); len(m.Payloads) > 0 && current != id {
+		return fmt.Errorf("message payloads are encoded with %s", current)
+	}
+	data, err := c.Encode(v)
+	if err != nil {
+		return err
+	}
+	if m.Payloads == nil {
+		m.Payloads = make(map[string][]byte)
+	}
+	m.Payloads[key] = data
+	// Gob is the default so it is not recorded, keeping those messages unchanged
+	if id != CodecId(GobCodec) {
+		if m.Metadata == nil {
+			m.Metadata = make(map[string]string)
+		}
+		m.Metadata[CodecMetadataKey] = id
+	}
+	return nil
+}
+
+// DecodePayload deserializes the payload stored under `key` into `v` using the codec recorded in the message
+func (m *Message) DecodePayload(key string, v interface{}) error {
+	c, err := LookupCodec(m.codecId())
+	if err != nil {
+		return err
+	}
+	data, ok := m.Payloads[key]
+	if !ok {
+		return fmt.Errorf("missing payload %s", key)
+	}
+	return c.Decode(data, v)
+}
+
+func (m *Message) codecId() string {
+	if id, ok := m.Metadata[CodecMetadataKey]; ok {
+		return id
+	}
+	return CodecId(GobCodec)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string  { return "gob" }
+func (gobCodec) Version() uint { return 1 }
+
+func (gobCodec) Encode(v interface{}) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	if err := gob.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Decode(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewBuffer(data)).Decode(v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string  { return "json" }
+func (jsonCodec) Version() uint { return 1 }
+
+func (jsonCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,101 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type codecTestPayload struct {
+	Round int
+	Data  []byte
+}
+
+// reverseCodec is a test codec that stores JSON in reverse order
+type reverseCodec struct{}
+
+func (reverseCodec) Name() string  { return "reverse-json" }
+func (reverseCodec) Version() uint { return 1 }
+
+func (reverseCodec) Encode(v interface{}) ([]byte, error) {
+	data, err := JSONCodec.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return reverse(data), nil
+}
+
+func (reverseCodec) Decode(data []byte, v interface{}) error {
+	return JSONCodec.Decode(reverse(append([]byte{}, data...)), v)
+}
+
+func reverse(data []byte) []byte {
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+	return data
+}
+
+func TestCodecDefaultIsGob(t *testing.T) {
+	expected := codecTestPayload{Round: 1, Data: []byte{1, 2, 3}}
+	m := &Message{Protocol: "codec-test-default", Version: Version1}
+	require.Equal(t, GobCodec, CodecFor(m.Protocol))
+	require.NoError(t, m.EncodePayload("p", &expected))
+	require.Nil(t, m.Metadata)
+
+	var actual codecTestPayload
+	require.NoError(t, m.DecodePayload("p", &actual))
+	require.Equal(t, expected, actual)
+	require.Error(t, m.DecodePayload("missing", &actual))
+}
+
+func TestCodecSelection(t *testing.T) {
+	const protocol = "codec-test-selection"
+	require.NoError(t, SetCodec(protocol, JSONCodec))
+	defer func() { require.NoError(t, SetCodec(protocol, nil)) }()
+
+	expected := codecTestPayload{Round: 2, Data: []byte("data")}
+	m := &Message{Protocol: protocol, Version: Version1}
+	require.NoError(t, m.EncodePayload("p", &expected))
+	require.Equal(t, "json/1", m.Metadata[CodecMetadataKey])
+	require.JSONEq(t, `{"Round":2,"Data":"ZGF0YQ=="}`, string(m.Payloads["p"]))
+
+	// Decoding follows the message, not the local selection
+	require.NoError(t, SetCodec(protocol, nil))
+	var actual codecTestPayload
+	require.NoError(t, m.DecodePayload("p", &actual))
+	require.Equal(t, expected, actual)
+
+	// Payloads of a message cannot mix codecs
+	require.Error(t, m.EncodePayload("q", &expected))
+}
+
+func TestCodecRegistration(t *testing.T) {
+	const protocol = "codec-test-registration"
+	m := &Message{
+		Protocol: protocol,
+		Payloads: map[string][]byte{"p": []byte("{}")},
+		Metadata: map[string]string{CodecMetadataKey: CodecId(reverseCodec{})},
+	}
+	var actual codecTestPayload
+	require.Error(t, m.DecodePayload("p", &actual))
+
+	require.NoError(t, SetCodec(protocol, reverseCodec{}))
+	defer func() { require.NoError(t, SetCodec(protocol, nil)) }()
+	require.Error(t, RegisterCodec(reverseCodec{}))
+	require.Error(t, RegisterCodec(GobCodec))
+	require.Error(t, RegisterCodec(nil))
+
+	expected := codecTestPayload{Round: 3}
+	m = &Message{Protocol: protocol}
+	require.NoError(t, m.EncodePayload("p", &expected))
+	require.Equal(t, byte('}'), m.Payloads["p"][0])
+	require.NoError(t, m.DecodePayload("p", &actual))
+	require.Equal(t, expected, actual)
+}
@@ -25,6 +25,18 @@ const (
 	// Dkls18Refresh specifies the DKG protocol of the DKLs18 potocol.
 	Dkls18Refresh = "DKLs18-Refresh"
 
+	// FrostDkg specifies the DKG protocol of FROST.
+	FrostDkg = "FROST-DKG"
+
+	// FrostSign specifies the signing protocol of FROST.
+	FrostSign = "FROST-Sign"
+
+	// Ted25519Keygen specifies the key generation of threshold Ed25519.
+	Ted25519Keygen = "Ted25519-Keygen"
+
+	// Ted25519Sign specifies the signing protocol of threshold Ed25519.
+	Ted25519Sign = "Ted25519-Sign"
+
 	// versions will increment in 100 intervals, to leave room for adding other versions in between them if it is
 	// ever needed in the future.
 
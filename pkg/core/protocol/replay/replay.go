@@ -0,0 +1,342 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package replay records the messages exchanged by the parties of a protocol.Iterator based
+// protocol and re-executes a recorded session step by step, reporting the first value that
+// differs from the recording. It is meant for diagnosing failed sessions, e.g. interop
+// failures between two deployments running different builds.
+//
+// A session can only be re-executed if every party drew its randomness from a seed, which is
+// what a Recorder created with a seed does: it creates its party with a deterministic reader
+// derived from the seed, so the protocol must take its randomness from an io.Reader, e.g. the
+// WithReader constructors of DKLs. TEST MODE ONLY: never record production sessions with a
+// seed, anyone who knows it learns every secret of the party.
+package replay
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/protocol"
+)
+
+// seedDomain separates the randomness of replayed parties from any other use of the seed
+const seedDomain = "kryptology replay seed v1"
+
+// NewParty creates the party with id `id`, drawing all its randomness from `random`
+type NewParty func(id uint32, random io.Reader) (protocol.Iterator, error)
+
+// Step is one recorded call to Next
+type Step struct {
+	Input  *protocol.Message `json:"input,omitempty"`
+	Output *protocol.Message `json:"output,omitempty"`
+	// Err is the error returned by Next, empty on success
+	Err string `json:"err,omitempty"`
+}
+
+// Record holds every call to Next made on one party, in order
+type Record struct {
+	Party uint32 `json:"party"`
+	Steps []Step `json:"steps"`
+}
+
+// Recorder is a protocol.Iterator that records every call to Next of the wrapped party
+type Recorder struct {
+	party  protocol.Iterator
+	record Record
+}
+
+// Divergence describes the first value of a replay that differs from the recording
+type Divergence struct {
+	Party uint32
+	Step  int
+	// Key is the payload that differs, empty if the error or the protocol metadata differs
+	Key string
+	// Offset is the first byte of the payload that differs, -1 if not applicable
+	Offset   int
+	Expected string
+	Actual   string
+}
+
+// NewRecorder creates the party with id `id` and wraps it. If `seed` is not nil the party runs
+// in test mode and draws its randomness from the seed, which makes the recording replayable,
+// otherwise it draws from crypto/rand.Reader
+func NewRecorder(id uint32, newParty NewParty, seed []byte) (*Recorder, error) {
+	random := io.Reader(rand.Reader)
+	if seed != nil {
+		random = seededReader(seed)
+	}
+	party, err := newParty(id, random)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{party: party, record: Record{Party: id}}, nil
+}
+
+// Next runs the next round of the wrapped party and records its input and output
+func (r *Recorder) Next(input *protocol.Message) (*protocol.Message, error) {
+	output, err := r.party.Next(input)
+	step := Step{Input: input, Output: output}
+	if err != nil {
+		step.Err = err.Error()
+	}
+	r.record.Steps = append(r.record.Steps, step)
+	return output, err
+}
+
+// Result returns the result of the wrapped party
+func (r *Recorder) Result(version uint) (*protocol.Message, error) {
+	return r.party.Result(version)
+}
+
+// Record returns the steps recorded so far
+func (r *Recorder) Record() *Record {
+	steps := make([]Step, len(r.record.Steps))
+	copy(steps, r.record.Steps)
+	return &Record{Party: r.record.Party, Steps: steps}
+}
+
+func (d *Divergence) Error() string {
+	where := fmt.Sprintf("party %d step %d", d.Party, d.Step)
+	if d.Key != "" {
+		where += fmt.Sprintf(" payload %q", d.Key)
+	}
+	if d.Offset >= 0 {
+		where += fmt.Sprintf(" byte %d", d.Offset)
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", where, d.Expected, d.Actual)
+}
+
+// stepRef identifies a recorded step of a party
+type stepRef struct {
+	party uint32
+	step  int
+}
+
+// Replay re-executes a recorded session. `newParty` must create parties with the same
+// configuration as the recorded ones and `seeds` must hold the seed each was recorded with.
+// It first checks that every payload a party received was sent by one of its peers, then
+// runs the recorded steps with their recorded inputs, each step after the peer steps that
+// sent its input and otherwise in ascending party id order, so that the reported divergence
+// is the first one in causal order. It returns nil if the replay matches the recording
+func Replay(newParty NewParty, seeds map[uint32][]byte, records map[uint32]*Record) (*Divergence, error) {
+	ids := make([]uint32, 0, len(records))
+	parties := make(map[uint32]protocol.Iterator, len(records))
+	for id, record := range records {
+		if record == nil {
+			return nil, fmt.Errorf("party %d has no record", id)
+		}
+		if seeds[id] == nil {
+			return nil, fmt.Errorf("party %d has no seed, only seeded sessions can be replayed", id)
+		}
+		party, err := newParty(id, seededReader(seeds[id]))
+		if err != nil {
+			return nil, err
+		}
+		parties[id] = party
+		ids = append(ids, id)
+	}
+	ids = protocol.SortedParticipantIds(ids)
+
+	dependencies, d := checkDelivery(ids, records)
+	if d != nil {
+		return d, nil
+	}
+
+	// next[id] is the number of steps of party id replayed so far
+	next := make(map[uint32]int, len(ids))
+	for {
+		id, ok := nextRunnable(ids, records, dependencies, next)
+		if !ok {
+			break
+		}
+		i := next[id]
+		step := records[id].Steps[i]
+		output, err := parties[id].Next(step.Input)
+		if d := compareStep(id, i, step, output, err); d != nil {
+			return d, nil
+		}
+		next[id]++
+	}
+	for _, id := range ids {
+		if next[id] < len(records[id].Steps) {
+			return nil, fmt.Errorf("party %d step %d depends on steps that cannot run first", id, next[id])
+		}
+	}
+	return nil, nil
+}
+
+// nextRunnable returns the lowest party id whose next step only depends on replayed steps
+func nextRunnable(ids []uint32, records map[uint32]*Record, dependencies map[stepRef][]stepRef, next map[uint32]int) (uint32, bool) {
+	for _, id := range ids {
+		i := next[id]
+		if i >= len(records[id].Steps) {
+			continue
+		}
+		ready := true
+		for _, dep := range dependencies[stepRef{id, i}] {
+			if next[dep.party] <= dep.step {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// checkDelivery finds, for each step, the peer steps that sent the payloads it received.
+// It returns a divergence for a received payload that no peer recorded sending
+func checkDelivery(ids []uint32, records map[uint32]*Record) (map[stepRef][]stepRef, *Divergence) {
+	dependencies := make(map[stepRef][]stepRef)
+	for _, id := range ids {
+		// The first step of a peer that sent each payload
+		sent := make(map[string]stepRef)
+		for _, peer := range ids {
+			if peer == id {
+				continue
+			}
+			for i, step := range records[peer].Steps {
+				if step.Output == nil {
+					continue
+				}
+				for _, payload := range step.Output.Payloads {
+					if _, ok := sent[string(payload)]; !ok {
+						sent[string(payload)] = stepRef{peer, i}
+					}
+				}
+			}
+		}
+		for i, step := range records[id].Steps {
+			if step.Input == nil {
+				continue
+			}
+			for _, key := range sortedKeys(step.Input.Payloads) {
+				from, ok := sent[string(step.Input.Payloads[key])]
+				if !ok {
+					return nil, &Divergence{
+						Party: id, Step: i, Key: key, Offset: -1,
+						Expected: "a payload sent by a peer",
+						Actual:   "a payload no peer recorded sending",
+					}
+				}
+				dependencies[stepRef{id, i}] = append(dependencies[stepRef{id, i}], from)
+			}
+		}
+	}
+	return dependencies, nil
+}
+
+// compareStep compares the replayed result of a step with the recorded one
+func compareStep(id uint32, i int, step Step, output *protocol.Message, err error) *Divergence {
+	actualErr := ""
+	if err != nil {
+		actualErr = err.Error()
+	}
+	if actualErr != step.Err {
+		return &Divergence{Party: id, Step: i, Offset: -1, Expected: describeErr(step.Err), Actual: describeErr(actualErr)}
+	}
+	expected := step.Output
+	if expected == nil || output == nil {
+		if expected != output {
+			return &Divergence{Party: id, Step: i, Offset: -1, Expected: describeMessage(expected), Actual: describeMessage(output)}
+		}
+		return nil
+	}
+	if expected.Protocol != output.Protocol || expected.Version != output.Version {
+		return &Divergence{
+			Party: id, Step: i, Offset: -1,
+			Expected: fmt.Sprintf("%s version %d", expected.Protocol, expected.Version),
+			Actual:   fmt.Sprintf("%s version %d", output.Protocol, output.Version),
+		}
+	}
+	keys := sortedKeys(expected.Payloads)
+	for _, key := range sortedKeys(output.Payloads) {
+		if _, ok := expected.Payloads[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		want, wantOk := expected.Payloads[key]
+		got, gotOk := output.Payloads[key]
+		switch {
+		case !gotOk:
+			return &Divergence{Party: id, Step: i, Key: key, Offset: -1, Expected: fmt.Sprintf("%d bytes", len(want)), Actual: "no payload"}
+		case !wantOk:
+			return &Divergence{Party: id, Step: i, Key: key, Offset: -1, Expected: "no payload", Actual: fmt.Sprintf("%d bytes", len(got))}
+		case !bytes.Equal(want, got):
+			offset := firstDifference(want, got)
+			return &Divergence{
+				Party: id, Step: i, Key: key, Offset: offset,
+				Expected: describeBytes(want, offset), Actual: describeBytes(got, offset),
+			}
+		}
+	}
+	return nil
+}
+
+// firstDifference returns the index of the first byte where a and b differ
+func firstDifference(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) < len(b) {
+		return len(a)
+	}
+	return len(b)
+}
+
+// describeBytes shows up to 16 bytes of `data` from `offset`
+func describeBytes(data []byte, offset int) string {
+	if offset >= len(data) {
+		return fmt.Sprintf("end of the %d byte payload", len(data))
+	}
+	end := offset + 16
+	if end > len(data) {
+		end = len(data)
+	}
+	return fmt.Sprintf("%x", data[offset:end])
+}
+
+func describeErr(err string) string {
+	if err == "" {
+		return "no error"
+	}
+	return fmt.Sprintf("error %q", err)
+}
+
+func describeMessage(m *protocol.Message) string {
+	if m == nil {
+		return "no message"
+	}
+	return fmt.Sprintf("a message with %d payloads", len(m.Payloads))
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// seededReader returns the deterministic randomness of a party
+func seededReader(seed []byte) io.Reader {
+	shake := sha3.NewShake256()
+	_, _ = shake.Write([]byte(seedDomain))
+	_, _ = shake.Write(seed)
+	return shake
+}
@@ -0,0 +1,130 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+	v1 "github.com/etclab/kryptology/pkg/tecdsa/dkls/v1"
+)
+
+var seeds = map[uint32][]byte{1: []byte("alice seed"), 2: []byte("bob seed")}
+
+// newDkgParty creates Alice as party 1 and Bob as party 2 of a DKLs DKG
+func newDkgParty(id uint32, random io.Reader) (protocol.Iterator, error) {
+	switch id {
+	case 1:
+		return v1.NewAliceDkgWithReader(curves.K256(), protocol.Version1, random), nil
+	case 2:
+		return v1.NewBobDkgWithReader(curves.K256(), protocol.Version1, random), nil
+	}
+	return nil, fmt.Errorf("unknown party %d", id)
+}
+
+// recordDkg runs a seeded DKLs DKG and returns the records of both parties
+func recordDkg(t *testing.T) map[uint32]*Record {
+	recorders := make(map[uint32]*Recorder)
+	parties := make(map[uint32]protocol.Iterator)
+	for id := range seeds {
+		var err error
+		recorders[id], err = NewRecorder(id, newDkgParty, seeds[id])
+		require.NoError(t, err)
+		parties[id] = recorders[id]
+	}
+	r, err := runner.NewRunner(parties, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.SetStarters(2))
+	require.NoError(t, r.Run())
+
+	records := make(map[uint32]*Record)
+	for id, recorder := range recorders {
+		records[id] = recorder.Record()
+	}
+	return records
+}
+
+func TestReplayMatchesRecording(t *testing.T) {
+	records := recordDkg(t)
+
+	// Records survive a round trip through JSON, e.g. when collected from two deployments
+	data, err := json.Marshal(records)
+	require.NoError(t, err)
+	var decoded map[uint32]*Record
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	d, err := Replay(newDkgParty, seeds, decoded)
+	require.NoError(t, err)
+	require.Nil(t, d)
+}
+
+func TestReplayReportsFirstDivergence(t *testing.T) {
+	records := recordDkg(t)
+
+	// A deployment whose party computes a different value in its third step
+	step := records[2].Steps[2]
+	var key string
+	for key = range step.Output.Payloads {
+		break
+	}
+	original := step.Output.Payloads[key]
+	payload := append([]byte{}, original...)
+	payload[5] ^= 1
+	tampered := *step.Output
+	tampered.Payloads = map[string][]byte{key: payload}
+	records[2].Steps[2].Output = &tampered
+
+	d, err := Replay(newDkgParty, seeds, records)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	// The input of the peer no longer matches what was sent
+	require.Equal(t, uint32(1), d.Party)
+	require.Equal(t, key, d.Key)
+
+	// Once delivery is consistent, the replay pins the byte that was computed differently
+	for _, s := range records[1].Steps {
+		if s.Input != nil {
+			if bytes.Equal(s.Input.Payloads[key], original) {
+				s.Input.Payloads[key] = payload
+			}
+		}
+	}
+	d, err = Replay(newDkgParty, seeds, records)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	require.Equal(t, uint32(2), d.Party)
+	require.Equal(t, 2, d.Step)
+	require.Equal(t, key, d.Key)
+	require.Equal(t, 5, d.Offset)
+	require.Contains(t, d.Error(), "party 2 step 2")
+}
+
+func TestReplayWrongSeed(t *testing.T) {
+	records := recordDkg(t)
+	wrong := map[uint32][]byte{1: seeds[1], 2: []byte("another seed")}
+	d, err := Replay(newDkgParty, wrong, records)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	require.Equal(t, uint32(2), d.Party)
+	require.Equal(t, 0, d.Step)
+
+	_, err = Replay(newDkgParty, map[uint32][]byte{1: seeds[1]}, records)
+	require.Error(t, err)
+	_, err = Replay(newDkgParty, seeds, map[uint32]*Record{1: records[1], 3: records[2]})
+	require.Error(t, err)
+	records[1] = nil
+	_, err = Replay(newDkgParty, seeds, records)
+	require.Error(t, err)
+}
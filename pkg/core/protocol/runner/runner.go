@@ -0,0 +1,393 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package runner drives a set of protocol.Iterator instances to completion by
+// routing the payloads each party produces to its peers, round after round.
+//
+// Parties address payloads using the keys of protocol.Message.Payloads:
+// BroadcastKey is delivered to every peer of the sender and PeerKey(id) to a
+// single peer. Before delivery the keys are rewritten so the recipient learns the
+// sender: a broadcast from party i is found under BroadcastFromKey(i) and a
+// point-to-point payload from party i under PeerKey(i). Any other key is only
+// allowed when the sender has a single peer and is delivered unchanged, which is
+// what two-party protocols such as DKLs18 expect.
+//
+// Messages produced in one round are only delivered in the next one, parties are
+// always processed in ascending id order and the inputs are assembled in sender
+// order so the execution does not depend on scheduling. Remote parties can be
+// included by implementing protocol.Iterator with a proxy that forwards Next and
+// Result to the remote endpoint.
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/etclab/kryptology/pkg/core/protocol"
+)
+
+const (
+	// BroadcastKey is the payload key used to send a payload to all peers
+	BroadcastKey = "broadcast"
+
+	// DefaultMaxRounds is the number of rounds after which Run gives up
+	DefaultMaxRounds = 100
+)
+
+var (
+	// ErrStalled is returned when no party produced output or finished during a round
+	ErrStalled = fmt.Errorf("protocol stalled")
+	// ErrMaxRounds is returned when the protocol did not finish within the allowed rounds
+	ErrMaxRounds = fmt.Errorf("maximum number of rounds exceeded")
+	// ErrUnroutable is returned when a payload key does not identify a peer of the sender
+	ErrUnroutable = fmt.Errorf("payload cannot be routed")
+)
+
+// PeerKey returns the payload key for a point-to-point payload to or from party `id`
+func PeerKey(id uint32) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// BroadcastFromKey returns the key under which a recipient finds the broadcast payload sent by party `id`
+func BroadcastFromKey(id uint32) string {
+	return BroadcastKey + "/" + PeerKey(id)
+}
+
+// PartyError records which party failed and in which round
+type PartyError struct {
+	Id    uint32
+	Round int
+	Err   error
+}
+
+func (e *PartyError) Error() string {
+	return fmt.Sprintf("party %d failed in round %d: %v", e.Id, e.Round, e.Err)
+}
+
+func (e *PartyError) Unwrap() error {
+	return e.Err
+}
+
+// Runner routes messages between parties until every party has finished
+type Runner struct {
+	// MaxRounds bounds the number of rounds run by Run, DefaultMaxRounds is used when zero
+	MaxRounds int
+	// Concurrent runs the parties of a round in parallel. Routing is unaffected and still
+	// happens in id order once every party of the round has returned
+	Concurrent bool
+
+	parties  map[uint32]protocol.Iterator
+	ids      []uint32
+	routes   map[uint32]map[uint32]bool
+	starters map[uint32]bool
+	finished map[uint32]bool
+	inbox    map[uint32]map[uint32]*protocol.Message
+	round    int
+}
+
+// NewRunner creates a runner for `parties`, indexed by party id.
+// `routes` lists for each sender the parties it may send payloads to.
+// When `routes` is nil every party can reach every other party
+func NewRunner(parties map[uint32]protocol.Iterator, routes map[uint32][]uint32) (*Runner, error) {
+	if len(parties) < 2 {
+		return nil, fmt.Errorf("at least two parties are required")
+	}
+	r := &Runner{
+		parties:  make(map[uint32]protocol.Iterator, len(parties)),
+		ids:      make([]uint32, 0, len(parties)),
+		routes:   make(map[uint32]map[uint32]bool, len(parties)),
+		finished: make(map[uint32]bool, len(parties)),
+		inbox:    make(map[uint32]map[uint32]*protocol.Message, len(parties)),
+	}
+	for id, p := range parties {
+		if p == nil {
+			return nil, fmt.Errorf("party %d is nil", id)
+		}
+		r.parties[id] = p
+		r.ids = append(r.ids, id)
+	}
+	r.ids = protocol.SortedParticipantIds(r.ids)
+
+	for _, from := range r.ids {
+		r.routes[from] = make(map[uint32]bool)
+		if routes == nil {
+			for _, to := range r.ids {
+				if to != from {
+					r.routes[from][to] = true
+				}
+			}
+		}
+	}
+	for from, peers := range routes {
+		if _, ok := r.parties[from]; !ok {
+			return nil, fmt.Errorf("routing table references unknown party %d", from)
+		}
+		for _, to := range peers {
+			if _, ok := r.parties[to]; !ok {
+				return nil, fmt.Errorf("routing table references unknown party %d", to)
+			}
+			if to == from {
+				return nil, fmt.Errorf("party %d cannot route to itself", from)
+			}
+			r.routes[from][to] = true
+		}
+	}
+	return r, nil
+}
+
+// SetStarters restricts the first round to the parties in `ids`.
+// By default every party runs the first round with a nil input
+func (r *Runner) SetStarters(ids ...uint32) error {
+	if r.round > 0 {
+		return fmt.Errorf("starters cannot change after the first round")
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("at least one starter is required")
+	}
+	starters := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := r.parties[id]; !ok {
+			return fmt.Errorf("unknown party %d", id)
+		}
+		starters[id] = true
+	}
+	r.starters = starters
+	return nil
+}
+
+// Round returns the number of rounds that have been run
+func (r *Runner) Round() int {
+	return r.round
+}
+
+// Done returns true once every party has finished
+func (r *Runner) Done() bool {
+	return len(r.finished) == len(r.parties)
+}
+
+// Run executes rounds until every party has finished
+func (r *Runner) Run() error {
+	maxRounds := r.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = DefaultMaxRounds
+	}
+	for !r.Done() {
+		if r.round >= maxRounds {
+			return ErrMaxRounds
+		}
+		if err := r.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step executes a single round. The parties that received payloads in the previous
+// round are run with those payloads as input. When no payloads are pending, every
+// party that has not finished is run with a nil input so it can finish or start
+func (r *Runner) Step() error {
+	if r.Done() {
+		return protocol.ErrProtocolFinished
+	}
+
+	// Select the parties of this round and assemble their inputs
+	var active []uint32
+	inputs := make(map[uint32]*protocol.Message)
+	switch {
+	case r.round == 0:
+		for _, id := range r.ids {
+			if r.starters == nil || r.starters[id] {
+				active = append(active, id)
+			}
+		}
+	case len(r.inbox) > 0:
+		for _, id := range r.ids {
+			if received, ok := r.inbox[id]; ok {
+				input, err := assemble(received)
+				if err != nil {
+					return &PartyError{Id: id, Round: r.round, Err: err}
+				}
+				active = append(active, id)
+				inputs[id] = input
+			}
+		}
+	default:
+		for _, id := range r.ids {
+			if !r.finished[id] {
+				active = append(active, id)
+			}
+		}
+	}
+	r.inbox = make(map[uint32]map[uint32]*protocol.Message)
+
+	outputs := make([]*protocol.Message, len(active))
+	errs := make([]error, len(active))
+	if r.Concurrent {
+		var wg sync.WaitGroup
+		for i, id := range active {
+			wg.Add(1)
+			go func(i int, id uint32) {
+				defer wg.Done()
+				outputs[i], errs[i] = r.parties[id].Next(inputs[id])
+			}(i, id)
+		}
+		wg.Wait()
+	} else {
+		for i, id := range active {
+			outputs[i], errs[i] = r.parties[id].Next(inputs[id])
+		}
+	}
+
+	// Consuming payloads counts as progress, otherwise a party must produce payloads or finish
+	progress := len(inputs) > 0
+	for i, id := range active {
+		switch {
+		case errs[i] == protocol.ErrProtocolFinished:
+			if inputs[id] != nil {
+				return &PartyError{Id: id, Round: r.round, Err: fmt.Errorf("received payloads after finishing")}
+			}
+			if !r.finished[id] {
+				r.finished[id] = true
+				progress = true
+			}
+		case errs[i] != nil:
+			return &PartyError{Id: id, Round: r.round, Err: errs[i]}
+		case r.finished[id]:
+			return &PartyError{Id: id, Round: r.round, Err: fmt.Errorf("party resumed after finishing")}
+		default:
+			if outputs[i] != nil && len(outputs[i].Payloads) > 0 {
+				progress = true
+			}
+			if err := r.route(id, outputs[i]); err != nil {
+				return &PartyError{Id: id, Round: r.round, Err: err}
+			}
+		}
+	}
+	r.round++
+	if !progress {
+		return ErrStalled
+	}
+	return nil
+}
+
+// Results collects the result of every party once the protocol has finished
+func (r *Runner) Results(version uint) (map[uint32]*protocol.Message, error) {
+	if !r.Done() {
+		return nil, fmt.Errorf("protocol has not finished")
+	}
+	results := make(map[uint32]*protocol.Message, len(r.parties))
+	for _, id := range r.ids {
+		result, err := r.parties[id].Result(version)
+		if err != nil {
+			return nil, &PartyError{Id: id, Round: r.round, Err: err}
+		}
+		results[id] = result
+	}
+	return results, nil
+}
+
+// route splits the output of party `from` into the messages received by each of its peers
+func (r *Runner) route(from uint32, output *protocol.Message) error {
+	if output == nil || len(output.Payloads) == 0 {
+		return nil
+	}
+	peers := r.routes[from]
+	for key, payload := range output.Payloads {
+		switch {
+		case key == BroadcastKey:
+			for to := range peers {
+				r.deliver(from, to, output, BroadcastFromKey(from), payload)
+			}
+		case isPeerKey(key):
+			to, err := strconv.ParseUint(key, 10, 32)
+			if err != nil || !peers[uint32(to)] {
+				return fmt.Errorf("%w: no route to %s", ErrUnroutable, key)
+			}
+			r.deliver(from, uint32(to), output, PeerKey(from), payload)
+		case len(peers) == 1 && !strings.HasPrefix(key, BroadcastKey):
+			for to := range peers {
+				r.deliver(from, to, output, key, payload)
+			}
+		default:
+			return fmt.Errorf("%w: unknown key %q", ErrUnroutable, key)
+		}
+	}
+	return nil
+}
+
+// deliver adds `payload` under `key` to the message from `from` pending for `to`
+func (r *Runner) deliver(from, to uint32, output *protocol.Message, key string, payload []byte) {
+	received, ok := r.inbox[to]
+	if !ok {
+		received = make(map[uint32]*protocol.Message)
+		r.inbox[to] = received
+	}
+	msg, ok := received[from]
+	if !ok {
+		msg = &protocol.Message{
+			Protocol: output.Protocol,
+			Version:  output.Version,
+			Payloads: make(map[string][]byte),
+			Metadata: output.Metadata,
+		}
+		received[from] = msg
+	}
+	msg.Payloads[key] = payload
+}
+
+// assemble merges the messages received from each sender into a single input
+func assemble(received map[uint32]*protocol.Message) (*protocol.Message, error) {
+	senders := make([]uint32, 0, len(received))
+	for id := range received {
+		senders = append(senders, id)
+	}
+	senders = protocol.SortedParticipantIds(senders)
+
+	first := received[senders[0]]
+	input := &protocol.Message{
+		Protocol: first.Protocol,
+		Version:  first.Version,
+		Payloads: make(map[string][]byte),
+	}
+	for _, id := range senders {
+		msg := received[id]
+		if msg.Protocol != input.Protocol || msg.Version != input.Version {
+			return nil, fmt.Errorf("party %d sent %s version %d, expected %s version %d",
+				id, msg.Protocol, msg.Version, input.Protocol, input.Version)
+		}
+		for k, v := range msg.Payloads {
+			if _, ok := input.Payloads[k]; ok {
+				return nil, fmt.Errorf("duplicate payload %q", k)
+			}
+			input.Payloads[k] = v
+		}
+		for k, v := range msg.Metadata {
+			if input.Metadata == nil {
+				input.Metadata = make(map[string]string)
+			}
+			if existing, ok := input.Metadata[k]; ok && existing != v {
+				return nil, fmt.Errorf("party %d sent conflicting metadata %q", id, k)
+			}
+			input.Metadata[k] = v
+		}
+	}
+	return input, nil
+}
+
+// isPeerKey returns true if `key` is a decimal party id
+func isPeerKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
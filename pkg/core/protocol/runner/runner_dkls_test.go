@@ -0,0 +1,59 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// The DKLs test is in an external test package because DKLs signing imports
+// package adaptor, which imports package frost and so this package
+package runner_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+	v1 "github.com/etclab/kryptology/pkg/tecdsa/dkls/v1"
+)
+
+func TestRunnerTwoPartyDkls(t *testing.T) {
+	curve := curves.K256()
+	alice := v1.NewAliceDkg(curve, protocol.Version1)
+	bob := v1.NewBobDkg(curve, protocol.Version1)
+	r, err := runner.NewRunner(map[uint32]protocol.Iterator{1: alice, 2: bob}, nil)
+	require.NoError(t, err)
+	require.Error(t, r.SetStarters(3))
+	require.NoError(t, r.SetStarters(2))
+	require.NoError(t, r.Run())
+	require.Error(t, r.SetStarters(1))
+
+	results, err := r.Results(protocol.Version1)
+	require.NoError(t, err)
+	aliceDkg, err := v1.DecodeAliceDkgResult(results[1])
+	require.NoError(t, err)
+	bobDkg, err := v1.DecodeBobDkgResult(results[2])
+	require.NoError(t, err)
+	require.True(t, aliceDkg.PublicKey.Equal(bobDkg.PublicKey))
+
+	// Signing is started by Alice
+	message := []byte("runner")
+	aliceSign, err := v1.NewAliceSign(curve, sha256.New(), message, results[1], protocol.Version1)
+	require.NoError(t, err)
+	bobSign, err := v1.NewBobSign(curve, sha256.New(), message, results[2], protocol.Version1)
+	require.NoError(t, err)
+	r, err = runner.NewRunner(map[uint32]protocol.Iterator{1: aliceSign, 2: bobSign}, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.SetStarters(1))
+	require.NoError(t, r.Run())
+	// Only Bob produces a signature so Results cannot be used
+	_, err = r.Results(protocol.Version1)
+	require.Error(t, err)
+	result, err := bobSign.Result(protocol.Version1)
+	require.NoError(t, err)
+	_, err = v1.DecodeSignature(result)
+	require.NoError(t, err)
+}
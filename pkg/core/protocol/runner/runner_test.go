@@ -0,0 +1,169 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package runner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/protocol"
+)
+
+const sumProtocol = "test-sum"
+
+// sumParty broadcasts its value and sends value*peer to each peer, then checks
+// the point-to-point values and computes the sum of all values
+type sumParty struct {
+	id    uint32
+	value uint32
+	peers []uint32
+	sum   uint32
+	step  int
+	// corrupt sends an invalid point-to-point payload to this peer
+	corrupt uint32
+}
+
+func u32(v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return b[:]
+}
+
+func (p *sumParty) Next(input *protocol.Message) (*protocol.Message, error) {
+	defer func() { p.step++ }()
+	switch p.step {
+	case 0:
+		if input != nil {
+			return nil, fmt.Errorf("unexpected input")
+		}
+		out := &protocol.Message{
+			Protocol: sumProtocol,
+			Version:  protocol.Version1,
+			Payloads: map[string][]byte{BroadcastKey: u32(p.value)},
+		}
+		for _, peer := range p.peers {
+			out.Payloads[PeerKey(peer)] = u32(p.value * peer)
+			if peer == p.corrupt {
+				out.Payloads[PeerKey(peer)] = u32(p.value*peer + 1)
+			}
+		}
+		return out, nil
+	case 1:
+		if input == nil || len(input.Payloads) != 2*len(p.peers) {
+			return nil, fmt.Errorf("unexpected input")
+		}
+		p.sum = p.value
+		for _, peer := range p.peers {
+			v := binary.BigEndian.Uint32(input.Payloads[BroadcastFromKey(peer)])
+			direct := binary.BigEndian.Uint32(input.Payloads[PeerKey(peer)])
+			if direct != v*p.id {
+				return nil, fmt.Errorf("invalid point-to-point payload from %d", peer)
+			}
+			p.sum += v
+		}
+		return nil, nil
+	default:
+		return nil, protocol.ErrProtocolFinished
+	}
+}
+
+func (p *sumParty) Result(version uint) (*protocol.Message, error) {
+	if p.step < 2 {
+		return nil, nil
+	}
+	return &protocol.Message{
+		Protocol: sumProtocol,
+		Version:  version,
+		Payloads: map[string][]byte{"sum": u32(p.sum)},
+	}, nil
+}
+
+func newSumParties(ids ...uint32) map[uint32]protocol.Iterator {
+	parties := make(map[uint32]protocol.Iterator, len(ids))
+	for _, id := range ids {
+		var peers []uint32
+		for _, peer := range ids {
+			if peer != id {
+				peers = append(peers, peer)
+			}
+		}
+		parties[id] = &sumParty{id: id, value: id * 10, peers: peers}
+	}
+	return parties
+}
+
+func TestRunnerBroadcastAndPointToPoint(t *testing.T) {
+	for _, concurrent := range []bool{false, true} {
+		r, err := NewRunner(newSumParties(1, 3, 7, 20), nil)
+		require.NoError(t, err)
+		r.Concurrent = concurrent
+		require.NoError(t, r.Run())
+		require.True(t, r.Done())
+		require.Equal(t, 3, r.Round())
+		require.ErrorIs(t, r.Step(), protocol.ErrProtocolFinished)
+
+		results, err := r.Results(protocol.Version1)
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		for _, result := range results {
+			require.Equal(t, uint32(310), binary.BigEndian.Uint32(result.Payloads["sum"]))
+		}
+	}
+}
+
+func TestRunnerRoutingTable(t *testing.T) {
+	// Party 2 cannot reach party 3
+	routes := map[uint32][]uint32{
+		1: {2, 3},
+		2: {1},
+		3: {1, 2},
+	}
+	r, err := NewRunner(newSumParties(1, 2, 3), routes)
+	require.NoError(t, err)
+	err = r.Run()
+	require.ErrorIs(t, err, ErrUnroutable)
+	var pe *PartyError
+	require.ErrorAs(t, err, &pe)
+	require.Equal(t, uint32(2), pe.Id)
+	require.Equal(t, 0, pe.Round)
+
+	_, err = NewRunner(newSumParties(1, 2), map[uint32][]uint32{1: {3}})
+	require.Error(t, err)
+	_, err = NewRunner(newSumParties(1, 2), map[uint32][]uint32{1: {1}})
+	require.Error(t, err)
+	_, err = NewRunner(newSumParties(1), nil)
+	require.Error(t, err)
+}
+
+func TestRunnerPartyFailure(t *testing.T) {
+	parties := newSumParties(1, 2, 3)
+	parties[2].(*sumParty).corrupt = 3
+	r, err := NewRunner(parties, nil)
+	require.NoError(t, err)
+	err = r.Run()
+	var pe *PartyError
+	require.ErrorAs(t, err, &pe)
+	require.Equal(t, uint32(3), pe.Id)
+	require.Equal(t, 1, pe.Round)
+	_, err = r.Results(protocol.Version1)
+	require.Error(t, err)
+}
+
+type idleParty struct{}
+
+func (idleParty) Next(*protocol.Message) (*protocol.Message, error) { return nil, nil }
+func (idleParty) Result(uint) (*protocol.Message, error)            { return nil, nil }
+
+func TestRunnerStalled(t *testing.T) {
+	r, err := NewRunner(map[uint32]protocol.Iterator{1: idleParty{}, 2: idleParty{}}, nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, r.Run(), ErrStalled)
+	require.Equal(t, 1, r.Round())
+}
@@ -0,0 +1,78 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// DealerKeygen lets a trusted dealer split `secret`, e.g. an existing signing key, into `limit` shares
+// for participants 1 to limit, any `threshold` of which can sign. A random key is generated if `secret` is nil.
+// The dealer broadcasts the verifier and sends each share privately to its participant, then erases the secret.
+func DealerKeygen(secret curves.Scalar, threshold, limit uint32, curve *curves.Curve, reader io.Reader) (*sharing.FeldmanVerifier, []*sharing.ShamirShare, error) {
+	if curve == nil || reader == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	feldman, err := sharing.NewFeldman(threshold, limit, curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	if secret == nil {
+		secret = curve.Scalar.Random(reader)
+	}
+	if secret.IsZero() {
+		return nil, nil, internal.ErrZeroValue
+	}
+	return feldman.Split(secret, reader)
+}
+
+// NewDealerParticipant returns the participant holding `share` from DealerKeygen, after checking it against
+// the verifier broadcast by the dealer, which must have `threshold` commitments. The participant is in the
+// same state as after Round2 of the DKG, so it can be used with Output and the FROST signer.
+func NewDealerParticipant(share *sharing.ShamirShare, verifier *sharing.FeldmanVerifier, threshold uint32, curve *curves.Curve) (*DkgParticipant, error) {
+	if share == nil || verifier == nil || curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if uint32(len(verifier.Commitments)) != threshold {
+		return nil, fmt.Errorf("verifier has %d commitments, expected %d", len(verifier.Commitments), threshold)
+	}
+	for _, com := range verifier.Commitments {
+		if com == nil || com.CurveName() != curve.Name || !com.IsOnCurve() || com.IsIdentity() {
+			return nil, fmt.Errorf("invalid commitment in verifier")
+		}
+	}
+	if err := share.Validate(curve); err != nil {
+		return nil, err
+	}
+	if err := verifier.Verify(share); err != nil {
+		return nil, fmt.Errorf("share %d does not match the verifier", share.Id)
+	}
+	return newSharedParticipant(share, verifier, curve)
+}
+
+// newSharedParticipant returns the participant holding a share checked against `verifier`, in the state after Round2
+func newSharedParticipant(share *sharing.ShamirShare, verifier *sharing.FeldmanVerifier, curve *curves.Curve) (*DkgParticipant, error) {
+	sk, err := curve.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &DkgParticipant{
+		round:           3,
+		Curve:           curve,
+		Id:              share.Id,
+		SkShare:         sk,
+		VerificationKey: verifier.Commitments[0],
+		VkShare:         curve.ScalarBaseMult(sk),
+		verifiers:       verifier,
+		groupVerifier:   verifier,
+	}, nil
+}
@@ -0,0 +1,80 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+func TestDealerKeygen(t *testing.T) {
+	for _, curve := range []*curves.Curve{testCurve, curves.K256()} {
+		secret := curve.Scalar.Random(crand.Reader)
+		verifier, shares, err := DealerKeygen(secret, 2, 3, curve, crand.Reader)
+		require.NoError(t, err)
+		require.Len(t, shares, 3)
+
+		var participants []*DkgParticipant
+		for _, share := range shares {
+			p, err := NewDealerParticipant(share, verifier, 2, curve)
+			require.NoError(t, err)
+			require.True(t, p.VerificationKey.Equal(curve.ScalarBaseMult(secret)))
+			out, err := p.Output()
+			require.NoError(t, err)
+			require.Equal(t, share.Id, out.Id)
+			participants = append(participants, p)
+		}
+
+		s, err := sharing.NewShamir(2, 3, curve)
+		require.NoError(t, err)
+		actual, err := s.Combine(
+			&sharing.ShamirShare{Id: participants[0].Id, Value: participants[0].SkShare.Bytes()},
+			&sharing.ShamirShare{Id: participants[2].Id, Value: participants[2].SkShare.Bytes()},
+		)
+		require.NoError(t, err)
+		require.Equal(t, 0, secret.Cmp(actual))
+	}
+}
+
+func TestDealerKeygenRandomSecret(t *testing.T) {
+	verifier, shares, err := DealerKeygen(nil, 2, 2, testCurve, crand.Reader)
+	require.NoError(t, err)
+	p1, err := NewDealerParticipant(shares[0], verifier, 2, testCurve)
+	require.NoError(t, err)
+	p2, err := NewDealerParticipant(shares[1], verifier, 2, testCurve)
+	require.NoError(t, err)
+	require.True(t, p1.VerificationKey.Equal(p2.VerificationKey))
+
+	_, _, err = DealerKeygen(testCurve.Scalar.Zero(), 2, 2, testCurve, crand.Reader)
+	require.Equal(t, internal.ErrZeroValue, err)
+	_, _, err = DealerKeygen(nil, 3, 2, testCurve, crand.Reader)
+	require.Error(t, err)
+}
+
+func TestNewDealerParticipantInvalidShare(t *testing.T) {
+	verifier, shares, err := DealerKeygen(nil, 2, 3, testCurve, crand.Reader)
+	require.NoError(t, err)
+
+	// a share that does not match the commitments
+	wrong := &sharing.ShamirShare{Id: shares[0].Id, Value: testCurve.Scalar.One().Bytes()}
+	_, err = NewDealerParticipant(wrong, verifier, 2, testCurve)
+	require.Error(t, err)
+	// a dealer cannot lower the threshold
+	_, err = NewDealerParticipant(shares[0], verifier, 3, testCurve)
+	require.Error(t, err)
+	// the verifier belongs to another curve
+	_, err = NewDealerParticipant(shares[0], verifier, 2, curves.K256())
+	require.Error(t, err)
+	_, err = NewDealerParticipant(nil, verifier, 2, testCurve)
+	require.Equal(t, internal.ErrNilArguments, err)
+}
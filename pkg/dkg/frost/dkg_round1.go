@@ -141,6 +141,7 @@ func (dp *DkgParticipant) Round1(secret []byte) (*Round1Bcast, Round1P2PSend, er
 	}
 
 	// Update internal state
+	dp.round1Bcast = round1Bcast
 	dp.round = 2
 
 	// return
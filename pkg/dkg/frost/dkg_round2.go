@@ -22,6 +22,8 @@ type Round2Bcast struct {
 }
 
 // Round2 implements dkg round 2 of FROST
+// In the robust mode, Round2 must follow Qualify and uses the values of the qualified dealers
+// that were checked during the complaint phase, instead of bcast and p2psend.
 func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint32]*sharing.ShamirShare) (*Round2Bcast, error) {
 	// Make sure dkg participant is not empty
 	if dp == nil || dp.Curve == nil {
@@ -32,6 +34,14 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 	if dp.round != 2 {
 		return nil, internal.ErrInvalidRound
 	}
+	if dp.robust != nil {
+		if dp.robust.stage != stageQualified {
+			return nil, internal.ErrInvalidRound
+		}
+		bcast, p2psend = dp.round2Input()
+	} else {
+		dp.logRound1(bcast)
+	}
 
 	// Check the input is valid
 	if bcast == nil || p2psend == nil || len(p2psend) == 0 {
@@ -56,6 +66,9 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 	}
 	// Validate each received commitment is on curve
 	for id := range bcast {
+		if len(bcast[id].Verifiers.Commitments) != int(dp.feldman.Threshold) {
+			return nil, fmt.Errorf("invalid number of commitments from participant %d\n", id)
+		}
 		for _, com := range bcast[id].Verifiers.Commitments {
 			if !com.IsOnCurve() || com.IsIdentity() {
 				return nil, fmt.Errorf("some commitment is not on curve from participant %d\n", id)
@@ -74,36 +87,8 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 		}
 
 		// Step 4 - Check equation c_j = H(j, CTX, A_{j,0}, g^{w_j}*A_{j,0}^{-c_j}
-		// Get Aj0
-		Aj0 := bcast[id].Verifiers.Commitments[0]
-		// Compute g^{w_j}
-		prod1 := dp.Curve.ScalarBaseMult(bcast[id].Wi)
-		// Compute A_{j,0}^{-c_j}
-		prod2 := Aj0.Mul(bcast[id].Ci.Neg())
-
-		// We need to check Aj0 and prod2 are points on the same curve.
-		if !Aj0.IsOnCurve() || Aj0.IsIdentity() || !prod2.IsOnCurve() || prod2.IsIdentity() || Aj0.CurveName() != prod2.CurveName() {
-			return nil, fmt.Errorf("invalid Aj0 or prod2 which is not on the same curve")
-		}
-		if prod2 == nil {
-			return nil, fmt.Errorf("invalid should not be nil")
-		}
-
-		prod := prod1.Add(prod2)
-		var msg []byte
-		// Append participant id
-		msg = append(msg, byte(id))
-		// Append CTX
-		msg = append(msg, dp.ctx)
-		// Append Aj0
-		msg = append(msg, Aj0.ToAffineCompressed()...)
-		// Append prod
-		msg = append(msg, prod.ToAffineCompressed()...)
-		// Hash the message and get cj
-		cj := dp.Curve.Scalar.Hash(msg)
-		// Check equation
-		if cj.Cmp(bcast[id].Ci) != 0 {
-			return nil, fmt.Errorf("Hash check fails for participant with id %d\n", id)
+		if err = dp.verifyProof(id, bcast[id]); err != nil {
+			return nil, err
 		}
 
 		// Step 5 - FeldmanVerify
@@ -138,6 +123,19 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 		vk = vk.Add(bcast[id].Verifiers.Commitments[0])
 	}
 
+	// The commitments to the sum of the dealt polynomials, which determine the verification key shares
+	commitments := make([]curves.Point, len(dp.verifiers.Commitments))
+	copy(commitments, dp.verifiers.Commitments)
+	for id := range bcast {
+		if id == dp.Id {
+			continue
+		}
+		for k, com := range bcast[id].Verifiers.Commitments {
+			commitments[k] = commitments[k].Add(com)
+		}
+	}
+	dp.groupVerifier = &sharing.FeldmanVerifier{Commitments: commitments}
+
 	// Store signing key share
 	dp.SkShare = sk
 
@@ -156,3 +154,40 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 		dp.VkShare,
 	}, nil
 }
+
+// verifyProof checks the proof of knowledge of the secret of participant `id`,
+// i.e. the equation c_j = H(j, CTX, A_{j,0}, g^{w_j}*A_{j,0}^{-c_j})
+func (dp *DkgParticipant) verifyProof(id uint32, b *Round1Bcast) error {
+	// Get Aj0
+	Aj0 := b.Verifiers.Commitments[0]
+	// Compute g^{w_j}
+	prod1 := dp.Curve.ScalarBaseMult(b.Wi)
+	// Compute A_{j,0}^{-c_j}
+	prod2 := Aj0.Mul(b.Ci.Neg())
+
+	// We need to check Aj0 and prod2 are points on the same curve.
+	if !Aj0.IsOnCurve() || Aj0.IsIdentity() || !prod2.IsOnCurve() || prod2.IsIdentity() || Aj0.CurveName() != prod2.CurveName() {
+		return fmt.Errorf("invalid Aj0 or prod2 which is not on the same curve")
+	}
+	if prod2 == nil {
+		return fmt.Errorf("invalid should not be nil")
+	}
+
+	prod := prod1.Add(prod2)
+	var msg []byte
+	// Append participant id
+	msg = append(msg, byte(id))
+	// Append CTX
+	msg = append(msg, dp.ctx)
+	// Append Aj0
+	msg = append(msg, Aj0.ToAffineCompressed()...)
+	// Append prod
+	msg = append(msg, prod.ToAffineCompressed()...)
+	// Hash the message and get cj
+	cj := dp.Curve.Scalar.Hash(msg)
+	// Check equation
+	if cj.Cmp(b.Ci) != 0 {
+		return fmt.Errorf("Hash check fails for participant with id %d\n", id)
+	}
+	return nil
+}
@@ -166,3 +166,29 @@ func TestFullDkgRoundsWorks(t *testing.T) {
 	vk := testCurve.ScalarBaseMult(sk)
 	require.True(t, vk.Equal(p1.VerificationKey))
 }
+
+func TestDkgOutputMarshal(t *testing.T) {
+	p1, p2, bcast1, bcast2, _, p2psend2 := PrepareRound2Input(t)
+	_, err := p1.Output()
+	require.Error(t, err)
+	bcast := map[uint32]*Round1Bcast{1: bcast1, 2: bcast2}
+	_, err = p1.Round2(bcast, map[uint32]*sharing.ShamirShare{2: p2psend2[1]})
+	require.NoError(t, err)
+
+	output, err := p1.Output()
+	require.NoError(t, err)
+	data, err := output.MarshalBinary()
+	require.NoError(t, err)
+	var decoded DkgOutput
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, p1.Id, decoded.Id)
+	require.Equal(t, 0, decoded.SkShare.Cmp(p1.SkShare))
+	require.True(t, decoded.VerificationKey.Equal(p1.VerificationKey))
+	require.True(t, decoded.VkShare.Equal(p1.VkShare))
+
+	// a verification key share that does not match the signing key share is rejected
+	output.VkShare = p2.Curve.Point.Generator()
+	data, err = output.MarshalBinary()
+	require.NoError(t, err)
+	require.Error(t, decoded.UnmarshalBinary(data))
+}
@@ -0,0 +1,277 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// DkgIterator runs the DKG as a protocol.Iterator, so it can be driven by the same transport as the DKLs18
+// protocols, e.g. by package runner. Broadcasts use runner.BroadcastKey and private shares runner.PeerKey, and
+// received payloads are expected under runner.BroadcastFromKey and runner.PeerKey of their sender. After Round2
+// the participants broadcast their verification keys and check that they agree. The robust mode is not supported
+type DkgIterator struct {
+	*DkgParticipant
+	version uint
+	steps   []func(*protocol.Message) (*protocol.Message, error)
+	step    int
+}
+
+var _ protocol.Iterator = &DkgIterator{}
+
+// dkgRound1Payload is the encoded Round1Bcast
+type dkgRound1Payload struct {
+	Verifiers, Wi, Ci []byte
+}
+
+// dkgRound2Payload is the encoded Round2Bcast
+type dkgRound2Payload struct {
+	VerificationKey, VkShare []byte
+}
+
+// dkgResult is the encoded result of the DKG
+type dkgResult struct {
+	Output, Verifier []byte
+}
+
+const dkgResultKey = "result"
+
+// NewDkgIterator creates the DKG participant `id` with a random secret, like NewDkgParticipant
+func NewDkgIterator(id, threshold uint32, ctx string, curve *curves.Curve, version uint, otherParticipants ...uint32) (*DkgIterator, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	dp, err := NewDkgParticipant(id, threshold, ctx, curve, otherParticipants...)
+	if err != nil {
+		return nil, err
+	}
+	d := &DkgIterator{DkgParticipant: dp, version: version}
+	d.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			bcast, p2p, err := d.Round1(nil)
+			if err != nil {
+				return nil, err
+			}
+			return d.encodeRound1Output(bcast, p2p)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			bcast, p2p, err := d.decodeRound2Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			out, err := d.Round2(bcast, p2p)
+			if err != nil {
+				return nil, err
+			}
+			return d.encodeRound2Output(out)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			return nil, d.checkVerificationKeys(input)
+		},
+	}
+	return d, nil
+}
+
+// Next runs the next round of the DKG
+func (d *DkgIterator) Next(input *protocol.Message) (*protocol.Message, error) {
+	if d.step >= len(d.steps) {
+		return nil, protocol.ErrProtocolFinished
+	}
+	output, err := d.steps[d.step](input)
+	if err != nil {
+		return nil, err
+	}
+	d.step++
+	return output, nil
+}
+
+// Result returns the output of the participant and the commitments of the DKG once it has completed,
+// which DecodeDkgResult turns back into a participant for signing
+func (d *DkgIterator) Result(version uint) (*protocol.Message, error) {
+	if d.step < len(d.steps) {
+		return nil, nil
+	}
+	if d.DkgParticipant == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	output, err := d.Output()
+	if err != nil {
+		return nil, err
+	}
+	var result dkgResult
+	if result.Output, err = output.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if result.Verifier, err = sharing.MarshalFeldmanVerifier(d.groupVerifier); err != nil {
+		return nil, err
+	}
+	m := newDkgMessage("result", version)
+	if err = m.EncodePayload(dkgResultKey, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+// DecodeDkgResult returns the participant of a DkgIterator result, in the same state as after Round2
+func DecodeDkgResult(m *protocol.Message) (*DkgParticipant, error) {
+	if m == nil {
+		return nil, errors.New("invalid result")
+	}
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	var result dkgResult
+	if err := m.DecodePayload(dkgResultKey, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var output DkgOutput
+	if err := output.UnmarshalBinary(result.Output); err != nil {
+		return nil, err
+	}
+	verifier, err := sharing.UnmarshalFeldmanVerifier(result.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	if !verifier.Commitments[0].Equal(output.VerificationKey) {
+		return nil, errors.New("commitments do not match the verification key")
+	}
+	share := &sharing.ShamirShare{Id: output.Id, Value: output.SkShare.Bytes()}
+	if err = verifier.Verify(share); err != nil {
+		return nil, errors.New("signing key share does not match the commitments")
+	}
+	return newSharedParticipant(share, verifier, curves.GetCurveByName(output.VerificationKey.CurveName()))
+}
+
+func newDkgMessage(round string, version uint) *protocol.Message {
+	return &protocol.Message{
+		Protocol: protocol.FrostDkg,
+		Version:  version,
+		Payloads: make(map[string][]byte),
+		Metadata: map[string]string{"round": round},
+	}
+}
+
+func (d *DkgIterator) encodeRound1Output(bcast *Round1Bcast, p2p Round1P2PSend) (*protocol.Message, error) {
+	var payload dkgRound1Payload
+	var err error
+	if payload.Verifiers, err = sharing.MarshalFeldmanVerifier(bcast.Verifiers); err != nil {
+		return nil, err
+	}
+	payload.Wi, payload.Ci = bcast.Wi.Bytes(), bcast.Ci.Bytes()
+	m := newDkgMessage("1", d.version)
+	if err = m.EncodePayload(runner.BroadcastKey, &payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for id, share := range p2p {
+		data, err := sharing.MarshalShare(d.Curve, share)
+		if err != nil {
+			return nil, err
+		}
+		if err = m.EncodePayload(runner.PeerKey(id), data); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return m, nil
+}
+
+func (d *DkgIterator) decodeRound2Input(m *protocol.Message) (map[uint32]*Round1Bcast, map[uint32]*sharing.ShamirShare, error) {
+	if m == nil || m.Version != d.version {
+		return nil, nil, errors.New("only version 1 is supported")
+	}
+	bcast := map[uint32]*Round1Bcast{d.Id: d.round1Bcast}
+	p2p := make(map[uint32]*sharing.ShamirShare, len(d.otherParticipantShares))
+	for id := range d.otherParticipantShares {
+		var payload dkgRound1Payload
+		if err := m.DecodePayload(runner.BroadcastFromKey(id), &payload); err != nil {
+			return nil, nil, err
+		}
+		verifiers, err := sharing.UnmarshalFeldmanVerifier(payload.Verifiers)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid commitments from participant %d: %v", id, err)
+		}
+		wi, err := d.Curve.Scalar.SetBytes(payload.Wi)
+		if err != nil {
+			return nil, nil, err
+		}
+		ci, err := d.Curve.Scalar.SetBytes(payload.Ci)
+		if err != nil {
+			return nil, nil, err
+		}
+		bcast[id] = &Round1Bcast{Verifiers: verifiers, Wi: wi, Ci: ci}
+
+		var data []byte
+		if err = m.DecodePayload(runner.PeerKey(id), &data); err != nil {
+			return nil, nil, err
+		}
+		curve, share, err := sharing.UnmarshalShare(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if curve.Name != d.Curve.Name || share.Id != d.Id {
+			return nil, nil, fmt.Errorf("invalid share from participant %d", id)
+		}
+		p2p[id] = share
+	}
+	return bcast, p2p, nil
+}
+
+func (d *DkgIterator) encodeRound2Output(out *Round2Bcast) (*protocol.Message, error) {
+	payload := dkgRound2Payload{
+		VerificationKey: out.VerificationKey.ToAffineCompressed(),
+		VkShare:         out.VkShare.ToAffineCompressed(),
+	}
+	m := newDkgMessage("2", d.version)
+	if err := m.EncodePayload(runner.BroadcastKey, &payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+// checkVerificationKeys checks that every participant computed the same verification key, and the
+// verification key share which follows from the commitments
+func (d *DkgIterator) checkVerificationKeys(m *protocol.Message) error {
+	if m == nil || m.Version != d.version {
+		return errors.New("only version 1 is supported")
+	}
+	bcast := make(map[uint32]*Round2Bcast, len(d.otherParticipantShares))
+	for id := range d.otherParticipantShares {
+		var payload dkgRound2Payload
+		if err := m.DecodePayload(runner.BroadcastFromKey(id), &payload); err != nil {
+			return err
+		}
+		vk, err := d.Curve.Point.FromAffineCompressed(payload.VerificationKey)
+		if err != nil || !vk.Equal(d.VerificationKey) {
+			return fmt.Errorf("participant %d computed another verification key", id)
+		}
+		vkShare, err := d.Curve.Point.FromAffineCompressed(payload.VkShare)
+		if err != nil {
+			return fmt.Errorf("invalid verification key share from participant %d", id)
+		}
+		expected, err := d.VerificationShare(id)
+		if err != nil {
+			return err
+		}
+		if !vkShare.Equal(expected) {
+			return fmt.Errorf("participant %d computed an invalid verification key share", id)
+		}
+		bcast[id] = &Round2Bcast{vk, vkShare}
+	}
+	if d.transcript != nil {
+		return d.LogRound2(bcast)
+	}
+	return nil
+}
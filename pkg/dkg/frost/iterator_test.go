@@ -0,0 +1,97 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+func newDkgIterators(t *testing.T, curve *curves.Curve, threshold uint32, ids ...uint32) map[uint32]protocol.Iterator {
+	parties := make(map[uint32]protocol.Iterator, len(ids))
+	for _, id := range ids {
+		var others []uint32
+		for _, other := range ids {
+			if other != id {
+				others = append(others, other)
+			}
+		}
+		d, err := NewDkgIterator(id, threshold, Ctx, curve, protocol.Version1, others...)
+		require.NoError(t, err)
+		parties[id] = d
+	}
+	return parties
+}
+
+func TestDkgIterator(t *testing.T) {
+	for _, curve := range []*curves.Curve{testCurve, curves.K256()} {
+		r, err := runner.NewRunner(newDkgIterators(t, curve, 2, 1, 2, 3), nil)
+		require.NoError(t, err)
+		require.NoError(t, r.Run())
+		results, err := r.Results(protocol.Version1)
+		require.NoError(t, err)
+
+		participants := make(map[uint32]*DkgParticipant)
+		for id, result := range results {
+			participants[id], err = DecodeDkgResult(result)
+			require.NoError(t, err)
+			require.Equal(t, id, participants[id].Id)
+		}
+		for _, p := range participants {
+			require.True(t, p.VerificationKey.Equal(participants[1].VerificationKey))
+		}
+		s, _ := sharing.NewShamir(2, 3, curve)
+		sk, err := s.Combine(&sharing.ShamirShare{Id: 1, Value: participants[1].SkShare.Bytes()},
+			&sharing.ShamirShare{Id: 3, Value: participants[3].SkShare.Bytes()})
+		require.NoError(t, err)
+		require.True(t, curve.ScalarBaseMult(sk).Equal(participants[2].VerificationKey))
+	}
+}
+
+func TestDkgIteratorBadInput(t *testing.T) {
+	_, err := NewDkgIterator(1, 2, Ctx, testCurve, protocol.Version0, 2)
+	require.Error(t, err)
+
+	d, err := NewDkgIterator(1, 2, Ctx, testCurve, protocol.Version1, 2)
+	require.NoError(t, err)
+	result, err := d.Result(protocol.Version1)
+	require.NoError(t, err)
+	require.Nil(t, result)
+	_, err = d.Next(nil)
+	require.NoError(t, err)
+	// round 2 needs the payloads of participant 2
+	_, err = d.Next(&protocol.Message{Protocol: protocol.FrostDkg, Version: protocol.Version1})
+	require.Error(t, err)
+
+	parties := newDkgIterators(t, testCurve, 2, 1, 2)
+	r, err := runner.NewRunner(parties, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Run())
+	_, err = parties[1].Next(nil)
+	require.Equal(t, protocol.ErrProtocolFinished, err)
+	results, err := r.Results(protocol.Version1)
+	require.NoError(t, err)
+
+	// a result whose commitments are not those of the DKG is rejected
+	var encoded dkgResult
+	require.NoError(t, results[1].DecodePayload(dkgResultKey, &encoded))
+	verifier, _, err := DealerKeygen(nil, 2, 2, testCurve, crand.Reader)
+	require.NoError(t, err)
+	encoded.Verifier, err = sharing.MarshalFeldmanVerifier(verifier)
+	require.NoError(t, err)
+	tampered := newDkgMessage("result", protocol.Version1)
+	require.NoError(t, tampered.EncodePayload(dkgResultKey, &encoded))
+	_, err = DecodeDkgResult(tampered)
+	require.Error(t, err)
+}
@@ -0,0 +1,81 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// DkgOutput is what a participant keeps after the DKG
+type DkgOutput struct {
+	Id              uint32
+	SkShare         curves.Scalar
+	VerificationKey curves.Point
+	VkShare         curves.Point
+}
+
+// Output returns the result of the DKG once Round2 has completed
+func (dp *DkgParticipant) Output() (*DkgOutput, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.round != 3 {
+		return nil, internal.ErrInvalidRound
+	}
+	return &DkgOutput{
+		Id:              dp.Id,
+		SkShare:         dp.SkShare,
+		VerificationKey: dp.VerificationKey,
+		VkShare:         dp.VkShare,
+	}, nil
+}
+
+// MarshalBinary returns the canonical encoding of the output, which is versioned like those of package sharing
+func (o DkgOutput) MarshalBinary() ([]byte, error) {
+	if o.VerificationKey == nil {
+		return nil, fmt.Errorf("invalid output")
+	}
+	e, err := sharing.NewEncoder(sharing.EncodingDkgOutput, curves.GetCurveByName(o.VerificationKey.CurveName()))
+	if err != nil {
+		return nil, err
+	}
+	e.Uint32(o.Id)
+	if err = e.Scalar(o.SkShare); err != nil {
+		return nil, err
+	}
+	if err = e.Point(o.VerificationKey); err != nil {
+		return nil, err
+	}
+	if err = e.Point(o.VkShare); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// UnmarshalBinary reads an output written by MarshalBinary
+func (o *DkgOutput) UnmarshalBinary(data []byte) error {
+	d, err := sharing.NewDecoder(data, sharing.EncodingDkgOutput)
+	if err != nil {
+		return err
+	}
+	id := d.Uint32()
+	skShare := d.Scalar()
+	vk := d.Point()
+	vkShare := d.Point()
+	if err = d.Finish(); err != nil {
+		return err
+	}
+	if !d.Curve().ScalarBaseMult(skShare).Equal(vkShare) {
+		return fmt.Errorf("verification key share does not match the signing key share")
+	}
+	o.Id, o.SkShare, o.VerificationKey, o.VkShare = id, skShare, vk, vkShare
+	return nil
+}
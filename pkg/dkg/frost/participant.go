@@ -8,10 +8,12 @@
 package frost
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/etclab/kryptology/internal"
 	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/dkg/transcript"
 	"github.com/etclab/kryptology/pkg/sharing"
 )
 
@@ -25,8 +27,13 @@ type DkgParticipant struct {
 	VkShare                curves.Point
 	feldman                *sharing.Feldman
 	verifiers              *sharing.FeldmanVerifier
+	groupVerifier          *sharing.FeldmanVerifier // commitments to the shared signing key after Round2
 	secretShares           []*sharing.ShamirShare
 	ctx                    byte
+	robust                 *robustState
+	round1Bcast            *Round1Bcast
+	transcript             *transcript.Log
+	round2Logged           bool // whether LogRound2 added the Round2 broadcasts to the transcript
 }
 
 type dkgParticipantData struct {
@@ -63,3 +70,25 @@ func NewDkgParticipant(id, threshold uint32, ctx string, curve *curves.Curve, ot
 		ctx:                    byte(ctxV),
 	}, nil
 }
+
+// VerificationShare returns the verification key share of participant `id`, computed from the
+// commitments of the DKG, which lets signers check the signature shares of the other participants
+func (dp *DkgParticipant) VerificationShare(id uint32) (curves.Point, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.round != 3 || dp.groupVerifier == nil {
+		return nil, internal.ErrInvalidRound
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("invalid participant id")
+	}
+	// sum_k A_k * id^k
+	x := dp.Curve.ScalarFromIndex(id)
+	powers := make([]curves.Scalar, len(dp.groupVerifier.Commitments))
+	powers[0] = dp.Curve.Scalar.One()
+	for k := 1; k < len(powers); k++ {
+		powers[k] = powers[k-1].Mul(x)
+	}
+	return dp.Curve.Point.SumOfProducts(dp.groupVerifier.Commitments, powers), nil
+}
@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// GroupVerifier returns the Feldman commitments to the shared signing key after Round2. The new participants
+// of a resharing check the dealt shares against it, so it must reach them from a source they trust
+func (dp *DkgParticipant) GroupVerifier() (*sharing.FeldmanVerifier, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.round != 3 || dp.groupVerifier == nil {
+		return nil, internal.ErrInvalidRound
+	}
+	return dp.groupVerifier, nil
+}
+
+// Reshare deals the signing key share of the participant, which must be in config.Quorum, to the new participants
+// 1 to config.Limit, any config.Threshold of which can sign afterwards. The output is broadcast and shares[j] is
+// sent privately to the new participant with id shares[j].Id. The old share must be erased once the new
+// participants have their shares
+func (dp *DkgParticipant) Reshare(config *sharing.ReshareConfig, reader io.Reader) (*sharing.ReshareOutput, []*sharing.ShamirShare, error) {
+	if dp == nil || dp.Curve == nil || config == nil || reader == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if dp.round != 3 || dp.groupVerifier == nil {
+		return nil, nil, internal.ErrInvalidRound
+	}
+	if config.Curve == nil || config.Curve.Name != dp.Curve.Name {
+		return nil, nil, fmt.Errorf("resharing is over another curve")
+	}
+	if int(config.OldThreshold) != len(dp.groupVerifier.Commitments) {
+		return nil, nil, fmt.Errorf("old threshold should be %d", len(dp.groupVerifier.Commitments))
+	}
+	dealer, err := sharing.NewReshareDealer(config, &sharing.ShamirShare{Id: dp.Id, Value: dp.SkShare.Bytes()})
+	if err != nil {
+		return nil, nil, err
+	}
+	return dealer.Deal(reader)
+}
+
+// NewReshareParticipant returns the new participant `id` of a resharing from the broadcasts of every member of
+// config.Quorum and the shares they sent to it, after checking them against `oldVerifier`, the GroupVerifier of
+// the old participants. The participant is in the same state as after Round2 and has the same verification key
+// as the old participants, so it signs for the existing group key
+func NewReshareParticipant(id uint32, config *sharing.ReshareConfig, oldVerifier *sharing.FeldmanVerifier,
+	outputs []*sharing.ReshareOutput, shares []*sharing.ShamirShare) (*DkgParticipant, error) {
+	if config == nil || oldVerifier == nil || len(oldVerifier.Commitments) == 0 {
+		return nil, internal.ErrNilArguments
+	}
+	if id == 0 || id > config.Limit {
+		return nil, fmt.Errorf("invalid participant id %d", id)
+	}
+	share, verifier, err := sharing.CombineReshare(config, id, oldVerifier, outputs, shares)
+	if err != nil {
+		return nil, err
+	}
+	if !verifier.Commitments[0].Equal(oldVerifier.Commitments[0]) {
+		return nil, fmt.Errorf("resharing changed the verification key")
+	}
+	if err = verifier.Verify(share); err != nil {
+		return nil, err
+	}
+	return newSharedParticipant(share, verifier, config.Curve)
+}
@@ -0,0 +1,136 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// reshare runs a resharing of `old` by the quorum and returns the new participants, indexed by id
+func reshare(t *testing.T, old map[uint32]*DkgParticipant, config *sharing.ReshareConfig) map[uint32]*DkgParticipant {
+	oldVerifier, err := old[config.Quorum[0]].GroupVerifier()
+	require.NoError(t, err)
+	var outputs []*sharing.ReshareOutput
+	received := make(map[uint32][]*sharing.ShamirShare)
+	for _, id := range config.Quorum {
+		output, shares, err := old[id].Reshare(config, crand.Reader)
+		require.NoError(t, err)
+		outputs = append(outputs, output)
+		for _, share := range shares {
+			received[share.Id] = append(received[share.Id], share)
+		}
+	}
+	participants := make(map[uint32]*DkgParticipant)
+	for id := uint32(1); id <= config.Limit; id++ {
+		participants[id], err = NewReshareParticipant(id, config, oldVerifier, outputs, received[id])
+		require.NoError(t, err)
+	}
+	return participants
+}
+
+func TestReshareThresholdChange(t *testing.T) {
+	for _, curve := range []*curves.Curve{testCurve, curves.K256()} {
+		secret := curve.Scalar.Random(crand.Reader)
+		verifier, shares, err := DealerKeygen(secret, 2, 3, curve, crand.Reader)
+		require.NoError(t, err)
+		old := make(map[uint32]*DkgParticipant)
+		for _, share := range shares {
+			old[share.Id], err = NewDealerParticipant(share, verifier, 2, curve)
+			require.NoError(t, err)
+		}
+
+		// 2-of-3 to 3-of-5 with the old participants 1 and 3
+		config := &sharing.ReshareConfig{Curve: curve, OldThreshold: 2, OldLimit: 3, Threshold: 3, Limit: 5, Quorum: []uint32{1, 3}}
+		participants := reshare(t, old, config)
+		for id, p := range participants {
+			require.True(t, p.VerificationKey.Equal(old[1].VerificationKey))
+			vkShare, err := participants[1].VerificationShare(id)
+			require.NoError(t, err)
+			require.True(t, vkShare.Equal(p.VkShare))
+		}
+
+		s, err := sharing.NewShamir(3, 5, curve)
+		require.NoError(t, err)
+		actual, err := s.Combine(
+			&sharing.ShamirShare{Id: 2, Value: participants[2].SkShare.Bytes()},
+			&sharing.ShamirShare{Id: 4, Value: participants[4].SkShare.Bytes()},
+			&sharing.ShamirShare{Id: 5, Value: participants[5].SkShare.Bytes()},
+		)
+		require.NoError(t, err)
+		require.Equal(t, 0, secret.Cmp(actual))
+
+		// and back to 2-of-2 with a different quorum
+		config = &sharing.ReshareConfig{Curve: curve, OldThreshold: 3, OldLimit: 5, Threshold: 2, Limit: 2, Quorum: []uint32{2, 3, 5}}
+		participants = reshare(t, participants, config)
+		s, err = sharing.NewShamir(2, 2, curve)
+		require.NoError(t, err)
+		actual, err = s.Combine(
+			&sharing.ShamirShare{Id: 1, Value: participants[1].SkShare.Bytes()},
+			&sharing.ShamirShare{Id: 2, Value: participants[2].SkShare.Bytes()},
+		)
+		require.NoError(t, err)
+		require.Equal(t, 0, secret.Cmp(actual))
+	}
+}
+
+func TestReshareBadInput(t *testing.T) {
+	verifier, shares, err := DealerKeygen(nil, 2, 3, testCurve, crand.Reader)
+	require.NoError(t, err)
+	p1, err := NewDealerParticipant(shares[0], verifier, 2, testCurve)
+	require.NoError(t, err)
+	p2, err := NewDealerParticipant(shares[1], verifier, 2, testCurve)
+	require.NoError(t, err)
+	config := &sharing.ReshareConfig{Curve: testCurve, OldThreshold: 2, OldLimit: 3, Threshold: 2, Limit: 3, Quorum: []uint32{1, 2}}
+
+	// the threshold and curve must match the old sharing, and the participant must be in the quorum
+	_, _, err = p1.Reshare(&sharing.ReshareConfig{Curve: testCurve, OldThreshold: 3, OldLimit: 3, Threshold: 2, Limit: 3, Quorum: []uint32{1, 2, 3}}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = p1.Reshare(&sharing.ReshareConfig{Curve: curves.K256(), OldThreshold: 2, OldLimit: 3, Threshold: 2, Limit: 3, Quorum: []uint32{1, 2}}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = p1.Reshare(&sharing.ReshareConfig{Curve: testCurve, OldThreshold: 2, OldLimit: 3, Threshold: 2, Limit: 3, Quorum: []uint32{2, 3}}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = p1.Reshare(config, nil)
+	require.Equal(t, internal.ErrNilArguments, err)
+	p, err := NewDkgParticipant(1, 2, Ctx, testCurve, 2)
+	require.NoError(t, err)
+	_, _, err = p.Reshare(config, crand.Reader)
+	require.Equal(t, internal.ErrInvalidRound, err)
+	_, err = p.GroupVerifier()
+	require.Equal(t, internal.ErrInvalidRound, err)
+
+	output1, shares1, err := p1.Reshare(config, crand.Reader)
+	require.NoError(t, err)
+	// a dealer that shares another value than its signing key share is detected
+	cheater, err := sharing.NewFeldman(2, 3, testCurve)
+	require.NoError(t, err)
+	cheatVerifier, cheatShares, err := cheater.Split(testCurve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+	output2 := &sharing.ReshareOutput{Id: 2, Verifier: cheatVerifier}
+	_, err = NewReshareParticipant(1, config, verifier, []*sharing.ReshareOutput{output1, output2},
+		[]*sharing.ShamirShare{shares1[0], cheatShares[0]})
+	require.Error(t, err)
+
+	output2, shares2, err := p2.Reshare(config, crand.Reader)
+	require.NoError(t, err)
+	_, err = NewReshareParticipant(1, config, verifier, []*sharing.ReshareOutput{output1, output2},
+		[]*sharing.ShamirShare{shares1[0], shares2[1]})
+	require.Error(t, err)
+	_, err = NewReshareParticipant(4, config, verifier, []*sharing.ReshareOutput{output1, output2},
+		[]*sharing.ShamirShare{shares1[0], shares2[0]})
+	require.Error(t, err)
+	p, err = NewReshareParticipant(1, config, verifier, []*sharing.ReshareOutput{output1, output2},
+		[]*sharing.ShamirShare{shares1[0], shares2[0]})
+	require.NoError(t, err)
+	require.True(t, p.VerificationKey.Equal(p1.VerificationKey))
+}
@@ -0,0 +1,241 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/dkg/transcript"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// The robust mode lets the DKG complete when participants go offline or misbehave, as long as at least `threshold`
+// participants remain. It runs between Round1 and Round2, and each step is called with the messages that arrived
+// before a timeout chosen by the caller:
+//
+//  1. Complain checks the Round1 values received and broadcasts the dealers whose shares are invalid or missing.
+//     Dealers whose Round1 broadcast is missing or invalid are excluded without complaints.
+//  2. Justify answers the complaints about this participant by broadcasting the shares it sent to the complainers.
+//  3. Qualify computes the qualified set: the dealers with a valid Round1 broadcast that answered every complaint
+//     with a valid share, and that received fewer than `threshold` complaints, which would reveal their secret.
+//     A dealer that times out before answering its complaints is excluded.
+//
+// Round2 then combines the contributions of the qualified dealers only. As the qualified set only depends on
+// broadcast values, all honest participants compute the same one when the broadcast channel is reliable.
+
+// ComplaintBcast lists the dealers whose Round1 shares are invalid or missing for this participant
+type ComplaintBcast = []uint32
+
+// JustificationBcast maps the participants who complained about this dealer to the shares it sent them
+type JustificationBcast = map[uint32]*sharing.ShamirShare
+
+// robustState holds the values of the robust mode
+type robustState struct {
+	stage      int
+	bcast      map[uint32]*Round1Bcast
+	shares     map[uint32]*sharing.ShamirShare
+	complaints map[uint32]ComplaintBcast
+	qualified  []uint32
+}
+
+const (
+	stageComplained = iota + 1
+	stageJustified
+	stageQualified
+)
+
+// Complain checks the Round1 values received before the timeout and returns the dealers to complain about,
+// which are broadcast to all other participants. It starts the robust mode.
+// bcast contains the Round1 broadcasts received from the other participants
+// p2psend contains the Round1 shares received from the other participants
+func (dp *DkgParticipant) Complain(bcast map[uint32]*Round1Bcast, p2psend map[uint32]*sharing.ShamirShare) (ComplaintBcast, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.round != 2 || dp.robust != nil {
+		return nil, internal.ErrInvalidRound
+	}
+
+	state := &robustState{
+		stage:  stageComplained,
+		bcast:  make(map[uint32]*Round1Bcast),
+		shares: make(map[uint32]*sharing.ShamirShare),
+	}
+	dp.logRound1(bcast)
+	complaints := ComplaintBcast{}
+	for _, id := range dp.otherIds() {
+		// Everyone sees the same broadcasts, so a missing or invalid one excludes the dealer without a complaint
+		if bcast[id] == nil || dp.verifyBcast(id, bcast[id]) != nil {
+			continue
+		}
+		state.bcast[id] = bcast[id]
+		if dp.verifyShare(bcast[id], p2psend[id], dp.Id) != nil {
+			complaints = append(complaints, id)
+			continue
+		}
+		state.shares[id] = p2psend[id]
+	}
+
+	dp.robust = state
+	return complaints, nil
+}
+
+// Justify answers the complaints about this participant with the shares it sent to the complainers,
+// which are broadcast to all other participants.
+// complaints contains the complaints received before the timeout
+func (dp *DkgParticipant) Justify(complaints map[uint32]ComplaintBcast) (JustificationBcast, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.robust == nil || dp.robust.stage != stageComplained {
+		return nil, internal.ErrInvalidRound
+	}
+
+	// Ignore complaints from unknown participants
+	known := make(map[uint32]ComplaintBcast, len(complaints))
+	justification := make(JustificationBcast)
+	for complainer, accused := range complaints {
+		if _, ok := dp.otherParticipantShares[complainer]; !ok && complainer != dp.Id {
+			continue
+		}
+		known[complainer] = accused
+		if complainer != dp.Id && containsId(accused, dp.Id) {
+			justification[complainer] = dp.secretShares[complainer-1]
+		}
+	}
+
+	if dp.transcript != nil {
+		dp.transcript.AppendComplaints(2, transcript.Participants(dp.Id, dp.otherIds()), known)
+	}
+	dp.robust.complaints = known
+	dp.robust.stage = stageJustified
+	return justification, nil
+}
+
+// Qualify checks the answers to the complaints and returns the qualified dealers in increasing order.
+// It fails if this participant is not qualified, or if fewer than `threshold` dealers remain.
+// justifications contains the justifications received before the timeout
+func (dp *DkgParticipant) Qualify(justifications map[uint32]JustificationBcast) ([]uint32, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.robust == nil || dp.robust.stage != stageJustified {
+		return nil, internal.ErrInvalidRound
+	}
+	state := dp.robust
+	dp.logJustifications(justifications)
+
+	ownBcast := &Round1Bcast{Verifiers: dp.verifiers}
+	qualified := []uint32{}
+	for _, dealer := range append(dp.otherIds(), dp.Id) {
+		dealerBcast := state.bcast[dealer]
+		if dealer == dp.Id {
+			dealerBcast = ownBcast
+		}
+		if dealerBcast == nil {
+			continue
+		}
+		if dp.qualifies(dealer, dealerBcast, justifications[dealer]) {
+			qualified = append(qualified, dealer)
+		}
+	}
+	sort.Slice(qualified, func(i, j int) bool { return qualified[i] < qualified[j] })
+
+	if !containsId(qualified, dp.Id) {
+		return nil, fmt.Errorf("participant %d is not qualified", dp.Id)
+	}
+	if uint32(len(qualified)) < dp.feldman.Threshold {
+		return nil, fmt.Errorf("only %d participants are qualified, %d are required", len(qualified), dp.feldman.Threshold)
+	}
+	state.qualified = qualified
+	state.stage = stageQualified
+	return qualified, nil
+}
+
+// qualifies checks that the dealer answered every complaint about it with a valid share,
+// and that there are fewer than `threshold` complaints
+func (dp *DkgParticipant) qualifies(dealer uint32, dealerBcast *Round1Bcast, justification JustificationBcast) bool {
+	var complainers []uint32
+	for complainer, accused := range dp.robust.complaints {
+		if complainer != dealer && containsId(accused, dealer) {
+			complainers = append(complainers, complainer)
+		}
+	}
+	if uint32(len(complainers)) >= dp.feldman.Threshold {
+		return false
+	}
+	for _, complainer := range complainers {
+		share := justification[complainer]
+		if dp.verifyShare(dealerBcast, share, complainer) != nil {
+			return false
+		}
+		// The revealed share replaces the one this participant complained about
+		if complainer == dp.Id {
+			dp.robust.shares[dealer] = share
+		}
+	}
+	return true
+}
+
+// round2Input returns the broadcasts and shares of the qualified dealers
+func (dp *DkgParticipant) round2Input() (map[uint32]*Round1Bcast, map[uint32]*sharing.ShamirShare) {
+	bcast := make(map[uint32]*Round1Bcast, len(dp.robust.qualified))
+	p2psend := make(map[uint32]*sharing.ShamirShare, len(dp.robust.qualified))
+	for _, id := range dp.robust.qualified {
+		if id == dp.Id {
+			continue
+		}
+		bcast[id] = dp.robust.bcast[id]
+		p2psend[id] = dp.robust.shares[id]
+	}
+	return bcast, p2psend
+}
+
+// verifyBcast checks the Round1 broadcast of participant `id`
+func (dp *DkgParticipant) verifyBcast(id uint32, b *Round1Bcast) error {
+	if b.Verifiers == nil || len(b.Verifiers.Commitments) != int(dp.feldman.Threshold) || b.Wi == nil || b.Ci == nil {
+		return fmt.Errorf("invalid broadcast from participant %d", id)
+	}
+	if b.Ci.IsZero() {
+		return fmt.Errorf("ci should not be zero from participant %d", id)
+	}
+	for _, com := range b.Verifiers.Commitments {
+		if com == nil || !com.IsOnCurve() || com.IsIdentity() {
+			return fmt.Errorf("some commitment is not on curve from participant %d", id)
+		}
+	}
+	return dp.verifyProof(id, b)
+}
+
+// verifyShare checks the share of participant `id` against the dealer's commitments
+func (dp *DkgParticipant) verifyShare(b *Round1Bcast, share *sharing.ShamirShare, id uint32) error {
+	if share == nil || share.Id != id {
+		return fmt.Errorf("missing share for participant %d", id)
+	}
+	return b.Verifiers.Verify(share)
+}
+
+// otherIds returns the ids of the other participants in increasing order
+func (dp *DkgParticipant) otherIds() []uint32 {
+	ids := make([]uint32, 0, len(dp.otherParticipantShares))
+	for id := range dp.otherParticipantShares {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func containsId(ids []uint32, id uint32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// robustTest holds the participants and the Round1 messages delivered before the timeout
+type robustTest struct {
+	participants map[uint32]*DkgParticipant
+	bcast        map[uint32]*Round1Bcast
+	// p2p maps a receiver to the shares sent to it by each dealer
+	p2p map[uint32]map[uint32]*sharing.ShamirShare
+	// round2 holds the Round2 broadcasts once run has completed
+	round2 map[uint32]*Round2Bcast
+}
+
+func newRobustTest(t *testing.T, n, threshold uint32) *robustTest {
+	return newRobustTestWithTranscript(t, n, threshold, nil)
+}
+
+// newRobustTestWithTranscript enables the transcript of the participants if `label` is not nil
+func newRobustTestWithTranscript(t *testing.T, n, threshold uint32, label []byte) *robustTest {
+	rt := &robustTest{
+		participants: make(map[uint32]*DkgParticipant),
+		bcast:        make(map[uint32]*Round1Bcast),
+		p2p:          make(map[uint32]map[uint32]*sharing.ShamirShare),
+	}
+	for id := uint32(1); id <= n; id++ {
+		var others []uint32
+		for j := uint32(1); j <= n; j++ {
+			if j != id {
+				others = append(others, j)
+			}
+		}
+		p, err := NewDkgParticipant(id, threshold, Ctx, testCurve, others...)
+		require.NoError(t, err)
+		if label != nil {
+			require.NoError(t, p.EnableTranscript(label))
+		}
+		rt.participants[id] = p
+		rt.p2p[id] = make(map[uint32]*sharing.ShamirShare)
+	}
+	for id, p := range rt.participants {
+		bcast, p2psend, err := p.Round1(nil)
+		require.NoError(t, err)
+		rt.bcast[id] = bcast
+		for receiver, share := range p2psend {
+			rt.p2p[receiver][id] = share
+		}
+	}
+	return rt
+}
+
+// drop removes a participant that went offline after sending its broadcast
+func (rt *robustTest) drop(id uint32) {
+	delete(rt.participants, id)
+	for _, shares := range rt.p2p {
+		delete(shares, id)
+	}
+}
+
+// run executes the robust mode and Round2 for the online participants, and checks they agree on the key
+func (rt *robustTest) run(t *testing.T, qualified []uint32) {
+	complaints := make(map[uint32]ComplaintBcast)
+	for id, p := range rt.participants {
+		c, err := p.Complain(rt.bcast, rt.p2p[id])
+		require.NoError(t, err)
+		complaints[id] = c
+	}
+	justifications := make(map[uint32]JustificationBcast)
+	for id, p := range rt.participants {
+		j, err := p.Justify(complaints)
+		require.NoError(t, err)
+		justifications[id] = j
+	}
+	for _, p := range rt.participants {
+		actual, err := p.Qualify(justifications)
+		require.NoError(t, err)
+		require.Equal(t, qualified, actual)
+	}
+
+	var shares []*sharing.ShamirShare
+	var vk *Round2Bcast
+	rt.round2 = make(map[uint32]*Round2Bcast)
+	for id, p := range rt.participants {
+		out, err := p.Round2(nil, nil)
+		require.NoError(t, err)
+		if vk != nil {
+			require.True(t, out.VerificationKey.Equal(vk.VerificationKey))
+		}
+		vk = out
+		rt.round2[id] = out
+		shares = append(shares, &sharing.ShamirShare{Id: id, Value: p.SkShare.Bytes()})
+	}
+
+	p := rt.participants[qualified[0]]
+	s, err := sharing.NewShamir(p.feldman.Threshold, p.feldman.Limit, testCurve)
+	require.NoError(t, err)
+	sk, err := s.Combine(shares...)
+	require.NoError(t, err)
+	require.True(t, testCurve.ScalarBaseMult(sk).Equal(p.VerificationKey))
+}
+
+func TestRobustDkgHonest(t *testing.T) {
+	rt := newRobustTest(t, 4, 3)
+	rt.run(t, []uint32{1, 2, 3, 4})
+}
+
+func TestRobustDkgDropouts(t *testing.T) {
+	rt := newRobustTest(t, 5, 3)
+	// participant 4 never sent its broadcast, and participant 5 went offline before sending its shares
+	delete(rt.bcast, 4)
+	rt.drop(4)
+	rt.drop(5)
+	rt.run(t, []uint32{1, 2, 3})
+}
+
+func TestRobustDkgJustifiedShare(t *testing.T) {
+	rt := newRobustTest(t, 4, 3)
+	// participant 3 sends a wrong share to participant 1, and reveals the correct one when 1 complains
+	rt.p2p[1][3] = &sharing.ShamirShare{Id: 1, Value: testCurve.Scalar.One().Bytes()}
+	rt.run(t, []uint32{1, 2, 3, 4})
+}
+
+func TestRobustDkgInvalidBroadcast(t *testing.T) {
+	rt := newRobustTest(t, 4, 3)
+	// the proof of knowledge of participant 2 does not verify
+	rt.bcast[2] = &Round1Bcast{
+		Verifiers: rt.bcast[2].Verifiers,
+		Wi:        rt.bcast[2].Wi.Double(),
+		Ci:        rt.bcast[2].Ci,
+	}
+	delete(rt.participants, 2)
+	rt.run(t, []uint32{1, 3, 4})
+}
+
+func TestRobustDkgTooManyDropouts(t *testing.T) {
+	rt := newRobustTest(t, 4, 3)
+	rt.drop(3)
+	rt.drop(4)
+	p := rt.participants[1]
+	c, err := p.Complain(rt.bcast, rt.p2p[1])
+	require.NoError(t, err)
+	require.Equal(t, ComplaintBcast{3, 4}, c)
+	_, err = p.Justify(map[uint32]ComplaintBcast{1: c, 2: {3, 4}})
+	require.NoError(t, err)
+	_, err = p.Qualify(map[uint32]JustificationBcast{})
+	require.Error(t, err)
+}
+
+func TestRobustDkgInvalidRound(t *testing.T) {
+	rt := newRobustTest(t, 3, 2)
+	p := rt.participants[1]
+	_, err := p.Justify(map[uint32]ComplaintBcast{})
+	require.Equal(t, internal.ErrInvalidRound, err)
+	_, err = p.Qualify(map[uint32]JustificationBcast{})
+	require.Equal(t, internal.ErrInvalidRound, err)
+
+	_, err = p.Complain(rt.bcast, rt.p2p[1])
+	require.NoError(t, err)
+	_, err = p.Complain(rt.bcast, rt.p2p[1])
+	require.Equal(t, internal.ErrInvalidRound, err)
+	// Round2 must wait for the qualified set
+	_, err = p.Round2(rt.bcast, rt.p2p[1])
+	require.Equal(t, internal.ErrInvalidRound, err)
+}
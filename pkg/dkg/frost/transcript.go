@@ -0,0 +1,116 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/dkg/transcript"
+)
+
+// EnableTranscript makes the participant accumulate the broadcast values of the DKG in a hash chain,
+// whose digest Transcript returns. It must be called before Round1, with the same `label` for all participants.
+func (dp *DkgParticipant) EnableTranscript(label []byte) error {
+	if dp == nil || dp.Curve == nil {
+		return internal.ErrNilArguments
+	}
+	if dp.round != 1 {
+		return internal.ErrInvalidRound
+	}
+	dp.transcript = transcript.New(append([]byte("FROST DKG "), label...))
+	return nil
+}
+
+// Transcript returns the digest of the broadcast values of the DKG once LogRound2 has added the Round2 broadcasts.
+// All participants that saw the same broadcasts, including the complaint phase, return the same digest.
+func (dp *DkgParticipant) Transcript() ([]byte, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.round != 3 || dp.transcript == nil || !dp.round2Logged {
+		return nil, internal.ErrInvalidRound
+	}
+	return dp.transcript.Digest(), nil
+}
+
+// LogRound2 appends the Round2 broadcasts of all participants in increasing order, i.e. the verification key and
+// verification key share that each computed. It is called once after Round2, with the broadcasts received from the
+// other participants, and before Transcript.
+func (dp *DkgParticipant) LogRound2(bcast map[uint32]*Round2Bcast) error {
+	if dp == nil || dp.Curve == nil {
+		return internal.ErrNilArguments
+	}
+	if dp.round != 3 || dp.transcript == nil || dp.round2Logged {
+		return internal.ErrInvalidRound
+	}
+	for _, id := range transcript.Participants(dp.Id, dp.otherIds()) {
+		b := bcast[id]
+		if id == dp.Id {
+			b = &Round2Bcast{dp.VerificationKey, dp.VkShare}
+		}
+		if b == nil {
+			continue
+		}
+		var value []byte
+		for _, p := range []curves.Point{b.VerificationKey, b.VkShare} {
+			if p != nil {
+				value = append(value, p.ToAffineCompressed()...)
+			}
+		}
+		dp.transcript.Append(4, id, "round2", value)
+	}
+	dp.round2Logged = true
+	return nil
+}
+
+// logRound1 appends the Round1 broadcasts of all participants in increasing order
+func (dp *DkgParticipant) logRound1(bcast map[uint32]*Round1Bcast) {
+	if dp.transcript == nil {
+		return
+	}
+	for _, id := range transcript.Participants(dp.Id, dp.otherIds()) {
+		b := bcast[id]
+		if id == dp.Id {
+			b = dp.round1Bcast
+		}
+		if b == nil {
+			continue
+		}
+		var value []byte
+		if b.Verifiers != nil {
+			for _, c := range b.Verifiers.Commitments {
+				if c != nil {
+					value = append(value, c.ToAffineCompressed()...)
+				}
+			}
+		}
+		if b.Wi != nil {
+			value = append(value, b.Wi.Bytes()...)
+		}
+		if b.Ci != nil {
+			value = append(value, b.Ci.Bytes()...)
+		}
+		dp.transcript.Append(1, id, "round1", value)
+	}
+}
+
+// logJustifications appends the justifications of all participants in increasing order
+func (dp *DkgParticipant) logJustifications(justifications map[uint32]JustificationBcast) {
+	if dp.transcript == nil {
+		return
+	}
+	shares := make(map[uint32]map[uint32][]byte, len(justifications))
+	for id, justification := range justifications {
+		shares[id] = make(map[uint32][]byte, len(justification))
+		for complainer, share := range justification {
+			if share != nil {
+				shares[id][complainer] = share.Bytes()
+			}
+		}
+	}
+	dp.transcript.AppendJustifications(3, transcript.Participants(dp.Id, dp.otherIds()), shares)
+}
@@ -0,0 +1,135 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+func TestDkgTranscript(t *testing.T) {
+	participants := make(map[uint32]*DkgParticipant)
+	for id := uint32(1); id <= 3; id++ {
+		var others []uint32
+		for j := uint32(1); j <= 3; j++ {
+			if j != id {
+				others = append(others, j)
+			}
+		}
+		p, err := NewDkgParticipant(id, 2, Ctx, testCurve, others...)
+		require.NoError(t, err)
+		require.NoError(t, p.EnableTranscript([]byte("session 1")))
+		participants[id] = p
+	}
+	bcast := make(map[uint32]*Round1Bcast)
+	p2p := map[uint32]map[uint32]*sharing.ShamirShare{1: {}, 2: {}, 3: {}}
+	for id, p := range participants {
+		b, shares, err := p.Round1(nil)
+		require.NoError(t, err)
+		bcast[id] = b
+		for receiver, share := range shares {
+			p2p[receiver][id] = share
+		}
+	}
+	// a participant cannot enable the transcript after Round1
+	require.Equal(t, internal.ErrInvalidRound, participants[1].EnableTranscript(nil))
+	_, err := participants[1].Transcript()
+	require.Equal(t, internal.ErrInvalidRound, err)
+
+	// participant 3 sees a different broadcast from participant 2, which sent it a matching share
+	equivocator, err := NewDkgParticipant(2, 2, Ctx, testCurve, 1, 3)
+	require.NoError(t, err)
+	otherBcast, otherShares, err := equivocator.Round1(nil)
+	require.NoError(t, err)
+
+	round2 := make(map[uint32]*Round2Bcast)
+	for id, p := range participants {
+		received := bcast
+		if id == 3 {
+			received = map[uint32]*Round1Bcast{1: bcast[1], 2: otherBcast, 3: bcast[3]}
+			p2p[3][2] = otherShares[3]
+		}
+		round2[id], err = p.Round2(received, p2p[id])
+		require.NoError(t, err)
+	}
+	// the transcript is complete once the Round2 broadcasts are logged
+	_, err = participants[1].Transcript()
+	require.Equal(t, internal.ErrInvalidRound, err)
+
+	digests := make(map[uint32][]byte)
+	for id, p := range participants {
+		require.NoError(t, p.LogRound2(round2))
+		digests[id], err = p.Transcript()
+		require.NoError(t, err)
+	}
+	require.Equal(t, digests[1], digests[2])
+	require.NotEqual(t, digests[1], digests[3])
+	require.Equal(t, internal.ErrInvalidRound, participants[1].LogRound2(round2))
+}
+
+func TestRobustDkgTranscript(t *testing.T) {
+	rt := newRobustTestWithTranscript(t, 3, 2, []byte("robust"))
+	rt.p2p[1][3] = &sharing.ShamirShare{Id: 1, Value: testCurve.Scalar.One().Bytes()}
+	rt.run(t, []uint32{1, 2, 3})
+
+	var digest []byte
+	for _, p := range rt.participants {
+		require.NoError(t, p.LogRound2(rt.round2))
+		actual, err := p.Transcript()
+		require.NoError(t, err)
+		if digest != nil {
+			require.Equal(t, digest, actual)
+		}
+		digest = actual
+	}
+}
+
+func TestDkgTranscriptRound2(t *testing.T) {
+	rt := newRobustTestWithTranscript(t, 3, 2, []byte("round 2"))
+	rt.run(t, []uint32{1, 2, 3})
+
+	// participant 3 receives another verification key share from participant 2
+	forged := map[uint32]*Round2Bcast{1: rt.round2[1], 2: {rt.round2[2].VerificationKey, rt.round2[1].VkShare}, 3: rt.round2[3]}
+	digests := make(map[uint32][]byte)
+	for id, p := range rt.participants {
+		received := rt.round2
+		if id == 3 {
+			received = forged
+		}
+		require.NoError(t, p.LogRound2(received))
+		var err error
+		digests[id], err = p.Transcript()
+		require.NoError(t, err)
+	}
+	require.Equal(t, digests[1], digests[2])
+	require.NotEqual(t, digests[1], digests[3])
+}
+
+func TestDkgIteratorTranscript(t *testing.T) {
+	parties := newDkgIterators(t, testCurve, 2, 1, 2, 3)
+	for _, party := range parties {
+		require.NoError(t, party.(*DkgIterator).EnableTranscript([]byte("iterator")))
+	}
+	r, err := runner.NewRunner(parties, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Run())
+
+	var digest []byte
+	for _, party := range parties {
+		actual, err := party.(*DkgIterator).Transcript()
+		require.NoError(t, err)
+		if digest != nil {
+			require.Equal(t, digest, actual)
+		}
+		digest = actual
+	}
+}
@@ -0,0 +1,234 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gennaro
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/dkg/transcript"
+	"github.com/etclab/kryptology/pkg/sharing/v1"
+)
+
+// The complaint phase of https://link.springer.com/content/pdf/10.1007/3-540-48910-X_21.pdf §4 fig 2 step 1
+// runs between Round1 and Round2, so that a dealer who sends bad shares is excluded instead of aborting the DKG:
+//
+//  1. Complain verifies the shares received in Round1 and broadcasts the dealers whose shares are invalid or missing.
+//  2. Justify answers the complaints against this participant by broadcasting the shares it sent to the complainers.
+//  3. Qualify disqualifies every dealer with at least `threshold` complaints, which would reveal its secret,
+//     or that did not answer a complaint with a valid share, and returns them.
+//
+// All honest participants compute the same qualified set, since it only depends on broadcast values. Round2, Round3
+// and Round4 then ignore the disqualified dealers, whose contributions are not part of the key.
+
+// ComplaintBcast lists the dealers whose Round1 shares failed verification for this participant
+type ComplaintBcast = []uint32
+
+// JustificationBcast maps the participants who complained about this dealer to the shares it sent them
+type JustificationBcast = map[uint32]*Round1P2PSendPacket
+
+// DealerError identifies the dealer responsible for an abort
+type DealerError struct {
+	Id  uint32
+	Err error
+}
+
+func (e *DealerError) Error() string {
+	return fmt.Sprintf("participant %d cheated: %v", e.Id, e.Err)
+}
+
+func (e *DealerError) Unwrap() error {
+	return e.Err
+}
+
+// complaintState holds the values of the complaint phase
+type complaintState struct {
+	stage        int
+	bcast        map[uint32]Round1Bcast
+	complaints   map[uint32]ComplaintBcast
+	justified    map[uint32]*Round1P2PSendPacket
+	disqualified map[uint32]bool
+}
+
+const (
+	stageComplained = iota + 1
+	stageJustified
+	stageQualified
+)
+
+// Complain verifies the shares received in Round1 and returns the dealers to complain about,
+// which are broadcast to all other participants.
+// bcast contains all Round1 broadcast from other participants to this participant
+// p2p contains all Round1 P2P send message from other participants to this participant
+func (dp *Participant) Complain(bcast map[uint32]Round1Bcast, p2p map[uint32]*Round1P2PSendPacket) (ComplaintBcast, error) {
+	if dp == nil || dp.curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.round != 2 || dp.complaints != nil {
+		return nil, internal.ErrInvalidRound
+	}
+	if bcast == nil || p2p == nil {
+		return nil, internal.ErrNilArguments
+	}
+
+	dp.logVerifiers(1, "round1", bcast, dp.pedersenResult.BlindedVerifiers)
+	complaints := ComplaintBcast{}
+	for _, id := range dp.otherIds() {
+		if !dp.validPacket(p2p[id], dp.id, bcast[id]) {
+			complaints = append(complaints, id)
+		}
+	}
+
+	dp.complaints = &complaintState{
+		stage: stageComplained,
+		bcast: bcast,
+	}
+	return complaints, nil
+}
+
+// Justify answers the complaints about this participant with the shares it sent to the complainers,
+// which are broadcast to all other participants.
+// complaints contains the complaints broadcast by all participants
+func (dp *Participant) Justify(complaints map[uint32]ComplaintBcast) (JustificationBcast, error) {
+	if dp == nil || dp.curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.complaints == nil || dp.complaints.stage != stageComplained {
+		return nil, internal.ErrInvalidRound
+	}
+	if complaints == nil {
+		return nil, internal.ErrNilArguments
+	}
+
+	justification := make(JustificationBcast)
+	for complainer, accused := range complaints {
+		if _, ok := dp.otherParticipantShares[complainer]; !ok && complainer != dp.id {
+			return nil, fmt.Errorf("complaint from unknown participant %d", complainer)
+		}
+		if complainer == dp.id || !containsId(accused, dp.id) {
+			continue
+		}
+		justification[complainer] = &Round1P2PSendPacket{
+			SecretShare:   dp.pedersenResult.SecretShares[complainer-1],
+			BlindingShare: dp.pedersenResult.BlindingShares[complainer-1],
+		}
+	}
+
+	if dp.transcript != nil {
+		dp.transcript.AppendComplaints(2, transcript.Participants(dp.id, dp.otherIds()), complaints)
+	}
+	dp.complaints.complaints = complaints
+	dp.complaints.stage = stageJustified
+	return justification, nil
+}
+
+// Qualify checks the answers to the complaints and returns the disqualified dealers in increasing order.
+// It fails if this participant is disqualified, or if fewer than `threshold` dealers remain.
+// justifications contains the justifications broadcast by all participants
+func (dp *Participant) Qualify(justifications map[uint32]JustificationBcast) ([]uint32, error) {
+	if dp == nil || dp.curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.complaints == nil || dp.complaints.stage != stageJustified {
+		return nil, internal.ErrInvalidRound
+	}
+	state := dp.complaints
+	dp.logJustifications(justifications)
+
+	state.justified = make(map[uint32]*Round1P2PSendPacket)
+	state.disqualified = make(map[uint32]bool)
+	for _, dealer := range append(dp.otherIds(), dp.id) {
+		// Collect the complaints about the dealer
+		var complainers []uint32
+		for complainer, accused := range state.complaints {
+			if complainer != dealer && containsId(accused, dealer) {
+				complainers = append(complainers, complainer)
+			}
+		}
+		if len(complainers) == 0 {
+			continue
+		}
+		// A dealer that reveals `threshold` shares reveals its secret
+		if len(complainers) >= int(dp.threshold) {
+			state.disqualified[dealer] = true
+			continue
+		}
+		// Every complaint must be answered with a share that matches the dealer's commitments
+		commitments := state.bcast[dealer]
+		if dealer == dp.id {
+			commitments = dp.pedersenResult.BlindedVerifiers
+		}
+		for _, complainer := range complainers {
+			packet := justifications[dealer][complainer]
+			if !dp.validPacket(packet, complainer, commitments) {
+				state.disqualified[dealer] = true
+				break
+			}
+			if complainer == dp.id {
+				state.justified[dealer] = packet
+			}
+		}
+	}
+
+	if state.disqualified[dp.id] {
+		return nil, fmt.Errorf("participant %d was disqualified", dp.id)
+	}
+	if len(dp.otherParticipantShares)+1-len(state.disqualified) < int(dp.threshold) {
+		return nil, fmt.Errorf("fewer than %d participants are qualified", dp.threshold)
+	}
+	disqualified := make([]uint32, 0, len(state.disqualified))
+	for id := range state.disqualified {
+		disqualified = append(disqualified, id)
+	}
+	sort.Slice(disqualified, func(i, j int) bool { return disqualified[i] < disqualified[j] })
+	state.stage = stageQualified
+	return disqualified, nil
+}
+
+// validPacket checks the shares for the participant `id` against the dealer's commitments
+func (dp *Participant) validPacket(packet *Round1P2PSendPacket, id uint32, commitments Round1Bcast) bool {
+	if len(commitments) != int(dp.threshold) || packet == nil {
+		return false
+	}
+	for _, c := range commitments {
+		if c == nil {
+			return false
+		}
+	}
+	for _, share := range []*v1.ShamirShare{packet.SecretShare, packet.BlindingShare} {
+		if share == nil || share.Identifier != id || share.Value == nil || share.Value.Value == nil {
+			return false
+		}
+	}
+	ok, _ := dp.pedersen.Verify(packet.SecretShare, packet.BlindingShare, commitments)
+	return ok
+}
+
+// disqualified returns true if the complaint phase excluded the dealer `id`
+func (dp *Participant) disqualified(id uint32) bool {
+	return dp.complaints != nil && dp.complaints.disqualified[id]
+}
+
+// otherIds returns the ids of the other participants in increasing order
+func (dp *Participant) otherIds() []uint32 {
+	ids := make([]uint32, 0, len(dp.otherParticipantShares))
+	for id := range dp.otherParticipantShares {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func containsId(ids []uint32, id uint32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
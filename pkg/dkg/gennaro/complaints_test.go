@@ -0,0 +1,238 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gennaro
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	v1 "github.com/etclab/kryptology/pkg/sharing/v1"
+)
+
+// complaintTest holds the participants and the Round1 output of a DKG
+type complaintTest struct {
+	participants map[uint32]*Participant
+	bcast        map[uint32]Round1Bcast
+	// p2p maps a receiver to the packets sent to it by each dealer
+	p2p map[uint32]map[uint32]*Round1P2PSendPacket
+}
+
+func newComplaintTest(t *testing.T, n, threshold uint32) *complaintTest {
+	return newComplaintTestWithTranscript(t, n, threshold, nil)
+}
+
+// newComplaintTestWithTranscript enables the transcript of the participants if `label` is not nil
+func newComplaintTestWithTranscript(t *testing.T, n, threshold uint32, label []byte) *complaintTest {
+	ct := &complaintTest{
+		participants: make(map[uint32]*Participant),
+		bcast:        make(map[uint32]Round1Bcast),
+		p2p:          make(map[uint32]map[uint32]*Round1P2PSendPacket),
+	}
+	for id := uint32(1); id <= n; id++ {
+		var others []uint32
+		for j := uint32(1); j <= n; j++ {
+			if j != id {
+				others = append(others, j)
+			}
+		}
+		p, err := NewParticipant(id, threshold, testGenerator, curves.NewK256Scalar(), others...)
+		require.NoError(t, err)
+		if label != nil {
+			require.NoError(t, p.EnableTranscript(label))
+		}
+		ct.participants[id] = p
+		ct.p2p[id] = make(map[uint32]*Round1P2PSendPacket)
+	}
+	for id, p := range ct.participants {
+		bcast, p2p, err := p.Round1(nil)
+		require.NoError(t, err)
+		ct.bcast[id] = bcast
+		for receiver, packet := range p2p {
+			ct.p2p[receiver][id] = packet
+		}
+	}
+	return ct
+}
+
+// tamper replaces the share sent by dealer to receiver with a wrong one
+func (ct *complaintTest) tamper(dealer, receiver uint32) {
+	share := ct.p2p[receiver][dealer].SecretShare
+	ct.p2p[receiver][dealer] = &Round1P2PSendPacket{
+		SecretShare:   &v1.ShamirShare{Identifier: share.Identifier, Value: share.Value.Add(share.Value)},
+		BlindingShare: ct.p2p[receiver][dealer].BlindingShare,
+	}
+}
+
+// complain runs Complain and Justify for all participants, and returns the justifications
+func (ct *complaintTest) complain(t *testing.T) map[uint32]JustificationBcast {
+	complaints := make(map[uint32]ComplaintBcast)
+	for id, p := range ct.participants {
+		c, err := p.Complain(ct.bcast, ct.p2p[id])
+		require.NoError(t, err)
+		complaints[id] = c
+	}
+	justifications := make(map[uint32]JustificationBcast)
+	for id, p := range ct.participants {
+		j, err := p.Justify(complaints)
+		require.NoError(t, err)
+		justifications[id] = j
+	}
+	return justifications
+}
+
+// finish runs Round2 to Round4 for the qualified participants and checks the key they share
+func (ct *complaintTest) finish(t *testing.T, qualified ...uint32) {
+	round2 := make(map[uint32]Round2Bcast)
+	for _, id := range qualified {
+		out, err := ct.participants[id].Round2(ct.bcast, ct.p2p[id])
+		require.NoError(t, err)
+		round2[id] = out
+	}
+	shares := make([]*v1.ShamirShare, 0, len(qualified))
+	var publicShares map[uint32]*curves.EcPoint
+	for _, id := range qualified {
+		p := ct.participants[id]
+		_, share, err := p.Round3(round2)
+		require.NoError(t, err)
+		shares = append(shares, share)
+		w, err := p.Round4()
+		require.NoError(t, err)
+		if publicShares != nil {
+			require.Equal(t, publicShares, w)
+		}
+		publicShares = w
+	}
+
+	first := ct.participants[qualified[0]]
+	s, err := v1.NewShamir(int(first.threshold), len(qualified), curves.NewField(btcec.S256().N))
+	require.NoError(t, err)
+	sk, err := s.Combine(shares...)
+	require.NoError(t, err)
+	x, y := btcec.S256().ScalarBaseMult(sk)
+	pk := &curves.EcPoint{Curve: btcec.S256(), X: x, Y: y}
+	for _, id := range qualified {
+		require.True(t, pk.Equals(ct.participants[id].verificationKey))
+	}
+}
+
+func TestComplaintsHonestDealers(t *testing.T) {
+	ct := newComplaintTest(t, 3, 2)
+	justifications := ct.complain(t)
+	for id, p := range ct.participants {
+		require.Empty(t, justifications[id])
+		disqualified, err := p.Qualify(justifications)
+		require.NoError(t, err)
+		require.Empty(t, disqualified)
+	}
+	ct.finish(t, 1, 2, 3)
+}
+
+func TestComplaintsJustifiedDealer(t *testing.T) {
+	ct := newComplaintTest(t, 3, 2)
+	ct.tamper(3, 1)
+	justifications := ct.complain(t)
+	require.Len(t, justifications[3], 1)
+	require.NotNil(t, justifications[3][1])
+
+	// the correct share revealed by dealer 3 replaces the wrong one
+	for _, p := range ct.participants {
+		disqualified, err := p.Qualify(justifications)
+		require.NoError(t, err)
+		require.Empty(t, disqualified)
+	}
+	ct.finish(t, 1, 2, 3)
+}
+
+func TestComplaintsUnjustifiedDealer(t *testing.T) {
+	ct := newComplaintTest(t, 3, 2)
+	ct.tamper(3, 1)
+	justifications := ct.complain(t)
+	delete(justifications, 3)
+
+	for _, id := range []uint32{1, 2} {
+		disqualified, err := ct.participants[id].Qualify(justifications)
+		require.NoError(t, err)
+		require.Equal(t, []uint32{3}, disqualified)
+	}
+	_, err := ct.participants[3].Qualify(justifications)
+	require.Error(t, err)
+
+	// the key of participants 1 and 2 does not depend on dealer 3
+	ct.finish(t, 1, 2)
+}
+
+func TestComplaintsInvalidJustification(t *testing.T) {
+	ct := newComplaintTest(t, 3, 2)
+	ct.tamper(3, 1)
+	justifications := ct.complain(t)
+	// dealer 3 answers with the wrong share again
+	justifications[3][1] = ct.p2p[1][3]
+
+	disqualified, err := ct.participants[2].Qualify(justifications)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{3}, disqualified)
+}
+
+func TestComplaintsThresholdDisqualifies(t *testing.T) {
+	ct := newComplaintTest(t, 4, 2)
+	ct.tamper(4, 1)
+	ct.tamper(4, 2)
+	justifications := ct.complain(t)
+
+	// answering `threshold` complaints would reveal the secret of dealer 4
+	for _, id := range []uint32{1, 2, 3} {
+		disqualified, err := ct.participants[id].Qualify(justifications)
+		require.NoError(t, err)
+		require.Equal(t, []uint32{4}, disqualified)
+	}
+	ct.finish(t, 1, 2, 3)
+}
+
+func TestComplaintsTooFewQualified(t *testing.T) {
+	ct := newComplaintTest(t, 3, 3)
+	ct.tamper(3, 1)
+	justifications := ct.complain(t)
+	delete(justifications, 3)
+
+	_, err := ct.participants[1].Qualify(justifications)
+	require.Error(t, err)
+}
+
+func TestComplaintsInvalidRound(t *testing.T) {
+	ct := newComplaintTest(t, 3, 2)
+	p := ct.participants[1]
+	_, err := p.Justify(map[uint32]ComplaintBcast{})
+	require.Equal(t, internal.ErrInvalidRound, err)
+	_, err = p.Qualify(map[uint32]JustificationBcast{})
+	require.Equal(t, internal.ErrInvalidRound, err)
+
+	_, err = p.Complain(ct.bcast, ct.p2p[1])
+	require.NoError(t, err)
+	_, err = p.Complain(ct.bcast, ct.p2p[1])
+	require.Equal(t, internal.ErrInvalidRound, err)
+	// Round2 must wait for the complaint phase to finish
+	_, err = p.Round2(ct.bcast, ct.p2p[1])
+	require.Equal(t, internal.ErrInvalidRound, err)
+
+	// a complaint from an unknown participant is rejected
+	_, err = p.Justify(map[uint32]ComplaintBcast{5: {1}})
+	require.Error(t, err)
+}
+
+func TestRound2IdentifiesDealer(t *testing.T) {
+	ct := newComplaintTest(t, 3, 2)
+	ct.tamper(2, 1)
+	_, err := ct.participants[1].Round2(ct.bcast, ct.p2p[1])
+	var dealerErr *DealerError
+	require.True(t, errors.As(err, &dealerErr))
+	require.Equal(t, uint32(2), dealerErr.Id)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/etclab/kryptology/internal"
 	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/dkg/transcript"
 	"github.com/etclab/kryptology/pkg/sharing/v1"
 )
 
@@ -24,11 +25,14 @@ type Participant struct {
 	scalar                 curves.EcScalar
 	otherParticipantShares map[uint32]*dkgParticipantData
 	id                     uint32
+	threshold              uint32
 	skShare                *curves.Element
 	verificationKey        *v1.ShareVerifier
 	feldman                *v1.Feldman
 	pedersen               *v1.Pedersen
 	pedersenResult         *v1.PedersenResult
+	complaints             *complaintState
+	transcript             *transcript.Log
 }
 
 // NewParticipant creates a participant ready to perform a DKG
@@ -65,6 +69,7 @@ func NewParticipant(id, threshold uint32, generator *curves.EcPoint, scalar curv
 
 	return &Participant{
 		id:                     id,
+		threshold:              threshold,
 		round:                  1,
 		curve:                  generator.Curve,
 		scalar:                 scalar,
@@ -19,6 +19,7 @@ type Round2Bcast = []*v1.ShareVerifier
 // Algorithm 3 - Gennaro DKG Round 2
 // bcast contains all Round1 broadcast from other participants to this participant
 // p2p contains all Round1 P2P send message from other participants to this participant
+// If the complaint phase ran, Round2 must follow Qualify, and the dealers it disqualified are ignored.
 func (dp *Participant) Round2(bcast map[uint32]Round1Bcast, p2p map[uint32]*Round1P2PSendPacket) (Round2Bcast, error) {
 
 	// Check participant is not empty
@@ -30,12 +31,20 @@ func (dp *Participant) Round2(bcast map[uint32]Round1Bcast, p2p map[uint32]*Roun
 	if dp.round != 2 {
 		return nil, internal.ErrInvalidRound
 	}
+	if dp.complaints != nil && dp.complaints.stage != stageQualified {
+		return nil, internal.ErrInvalidRound
+	}
 
 	// Check the input is valid
 	if bcast == nil || p2p == nil || len(bcast) == 0 || len(p2p) == 0 {
 		return nil, internal.ErrNilArguments
 	}
 
+	// The broadcasts were added to the transcript by Complain in the complaint phase
+	if dp.complaints == nil {
+		dp.logVerifiers(1, "round1", bcast, dp.pedersenResult.BlindedVerifiers)
+	}
+
 	// 1. set sk = x_{ii}
 	sk := dp.pedersenResult.SecretShares[dp.id-1].Value
 
@@ -43,29 +52,35 @@ func (dp *Participant) Round2(bcast map[uint32]Round1Bcast, p2p map[uint32]*Roun
 	for id := range bcast {
 
 		// 3. if i = j continue
-		if id == dp.id {
+		if id == dp.id || dp.disqualified(id) {
 			continue
 		}
 
+		// A share revealed in the complaint phase replaces the one sent by the dealer
+		packet := p2p[id]
+		if dp.complaints != nil && dp.complaints.justified[id] != nil {
+			packet = dp.complaints.justified[id]
+		}
+
 		// Ensure a valid p2p entry exists
-		if p2p[id] == nil {
-			return nil, fmt.Errorf("missing p2p packet for id=%v", id)
+		if packet == nil {
+			return nil, &DealerError{Id: id, Err: fmt.Errorf("missing p2p packet for id=%v", id)}
 		}
 
 		// 4. If PedersenVerify(E, Q, x_ji, r_ji, {X_ji,...,X_jt}) = false, abort
-		xji := p2p[id].SecretShare
-		rji := p2p[id].BlindingShare
+		xji := packet.SecretShare
+		rji := packet.BlindingShare
 		bvs := bcast[id]
 		if ok, err := dp.pedersen.Verify(xji, rji, bvs); !ok {
 			if err != nil {
-				return nil, err
+				return nil, &DealerError{Id: id, Err: err}
 			} else {
-				return nil, fmt.Errorf("invalid share for participant id=%v", id)
+				return nil, &DealerError{Id: id, Err: fmt.Errorf("invalid share for participant id=%v", id)}
 			}
 		}
 
 		// Store other participants' shares xji for usage in round 3
-		dp.otherParticipantShares[id].Share = p2p[id].SecretShare
+		dp.otherParticipantShares[id].Share = xji
 
 		// 5. sk = (sk+xji) mod q
 		// NOTE: we use the EcScalar class to add instead of
@@ -35,13 +35,15 @@ func (dp *Participant) Round3(bcast map[uint32]Round2Bcast) (*Round3Bcast, *v1.S
 		return nil, nil, internal.ErrNilArguments
 	}
 
+	dp.logVerifiers(4, "round2", bcast, dp.pedersenResult.Verifiers)
+
 	// 1. SetBigInt Pk = R_i1
 	Pk := dp.pedersenResult.Verifiers[0]
 
 	// 2. for j in 1,...,n
 	for id := range bcast {
 		// 3. if i = j continue
-		if id == dp.id {
+		if id == dp.id || dp.disqualified(id) {
 			continue
 		}
 
@@ -50,9 +52,9 @@ func (dp *Participant) Round3(bcast map[uint32]Round2Bcast) (*Round3Bcast, *v1.S
 		vs := bcast[id]
 		if ok, err := dp.feldman.Verify(xji, vs); !ok {
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, &DealerError{Id: id, Err: err}
 			} else {
-				return nil, nil, fmt.Errorf("invalid share for participant #{id}")
+				return nil, nil, &DealerError{Id: id, Err: fmt.Errorf("invalid share for participant id=%v", id)}
 			}
 		}
 
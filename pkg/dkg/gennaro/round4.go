@@ -37,6 +37,10 @@ func (dp *Participant) Round4() (map[uint32]*curves.EcPoint, error) {
 	r := make(map[uint32][]*v1.ShareVerifier, n)
 	r[dp.id] = dp.pedersenResult.Verifiers
 	for j := range dp.otherParticipantShares {
+		// Disqualified participants are excluded from the key
+		if dp.disqualified(j) {
+			continue
+		}
 		r[j] = dp.otherParticipantShares[j].Verifiers
 	}
 
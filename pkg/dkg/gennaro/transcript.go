@@ -0,0 +1,85 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gennaro
+
+import (
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/dkg/transcript"
+	"github.com/etclab/kryptology/pkg/sharing/v1"
+)
+
+// EnableTranscript makes the participant accumulate the broadcast values of the DKG in a hash chain,
+// whose digest Transcript returns. It must be called before Round1, with the same `label` for all participants.
+func (dp *Participant) EnableTranscript(label []byte) error {
+	if dp == nil || dp.curve == nil {
+		return internal.ErrNilArguments
+	}
+	if dp.round != 1 {
+		return internal.ErrInvalidRound
+	}
+	dp.transcript = transcript.New(append([]byte("Gennaro DKG "), label...))
+	return nil
+}
+
+// Transcript returns the digest of the broadcast values of the DKG once Round3 has completed.
+// All participants that saw the same broadcasts, including the complaint phase, return the same digest.
+func (dp *Participant) Transcript() ([]byte, error) {
+	if dp == nil || dp.curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if dp.round != 4 || dp.transcript == nil {
+		return nil, internal.ErrInvalidRound
+	}
+	return dp.transcript.Digest(), nil
+}
+
+// logVerifiers appends the verifiers broadcast by all participants in increasing order, with `own` for this participant
+func (dp *Participant) logVerifiers(round uint32, label string, bcast map[uint32][]*v1.ShareVerifier, own []*v1.ShareVerifier) {
+	if dp.transcript == nil {
+		return
+	}
+	for _, id := range transcript.Participants(dp.id, dp.otherIds()) {
+		verifiers, ok := bcast[id]
+		if id == dp.id {
+			verifiers, ok = own, true
+		}
+		if !ok {
+			continue
+		}
+		var value []byte
+		for _, v := range verifiers {
+			if v != nil && v.X != nil && v.Y != nil {
+				value = append(value, v.Bytes()...)
+			}
+		}
+		dp.transcript.Append(round, id, label, value)
+	}
+}
+
+// logJustifications appends the justifications of all participants in increasing order
+func (dp *Participant) logJustifications(justifications map[uint32]JustificationBcast) {
+	if dp.transcript == nil {
+		return
+	}
+	shares := make(map[uint32]map[uint32][]byte, len(justifications))
+	for id, justification := range justifications {
+		shares[id] = make(map[uint32][]byte, len(justification))
+		for complainer, packet := range justification {
+			if packet == nil {
+				continue
+			}
+			var value []byte
+			for _, share := range []*v1.ShamirShare{packet.SecretShare, packet.BlindingShare} {
+				if share != nil {
+					value = append(value, share.Bytes()...)
+				}
+			}
+			shares[id][complainer] = value
+		}
+	}
+	dp.transcript.AppendJustifications(3, transcript.Participants(dp.id, dp.otherIds()), shares)
+}
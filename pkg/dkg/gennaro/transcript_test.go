@@ -0,0 +1,66 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gennaro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+)
+
+// transcripts returns the digest of every participant after Round3
+func (ct *complaintTest) transcripts(t *testing.T, round1 map[uint32]map[uint32]Round1Bcast) map[uint32][]byte {
+	round2 := make(map[uint32]Round2Bcast)
+	for id, p := range ct.participants {
+		out, err := p.Round2(round1[id], ct.p2p[id])
+		require.NoError(t, err)
+		round2[id] = out
+	}
+	digests := make(map[uint32][]byte)
+	for id, p := range ct.participants {
+		_, _, err := p.Round3(round2)
+		require.NoError(t, err)
+		digests[id], err = p.Transcript()
+		require.NoError(t, err)
+	}
+	return digests
+}
+
+func TestTranscript(t *testing.T) {
+	ct := newComplaintTestWithTranscript(t, 3, 2, []byte("session 1"))
+	p := ct.participants[1]
+	// a participant cannot enable the transcript after Round1
+	require.Equal(t, internal.ErrInvalidRound, p.EnableTranscript(nil))
+	_, err := p.Transcript()
+	require.Equal(t, internal.ErrInvalidRound, err)
+
+	round1 := map[uint32]map[uint32]Round1Bcast{1: ct.bcast, 2: ct.bcast, 3: ct.bcast}
+	digests := ct.transcripts(t, round1)
+	require.Equal(t, digests[1], digests[2])
+	require.Equal(t, digests[1], digests[3])
+}
+
+func TestTranscriptComplaints(t *testing.T) {
+	ct := newComplaintTestWithTranscript(t, 3, 2, []byte("complaints"))
+	ct.tamper(3, 1)
+	justifications := ct.complain(t)
+	for _, p := range ct.participants {
+		_, err := p.Qualify(justifications)
+		require.NoError(t, err)
+	}
+	round1 := map[uint32]map[uint32]Round1Bcast{1: ct.bcast, 2: ct.bcast, 3: ct.bcast}
+	digests := ct.transcripts(t, round1)
+	require.Equal(t, digests[1], digests[2])
+	require.Equal(t, digests[1], digests[3])
+
+	// the transcript of the complaint phase differs from an honest run
+	honest := newComplaintTestWithTranscript(t, 3, 2, []byte("complaints"))
+	round1 = map[uint32]map[uint32]Round1Bcast{1: honest.bcast, 2: honest.bcast, 3: honest.bcast}
+	require.NotEqual(t, digests[1], honest.transcripts(t, round1)[1])
+}
@@ -0,0 +1,104 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package transcript accumulates the broadcast values of a DKG in a hash chain, so that auditors can confirm
+// all participants saw the same values by comparing the digests they output along with their key shares.
+package transcript
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+)
+
+// Log is a hash chain of the broadcast values of a DKG. Every entry updates the digest to
+//
+//	SHA-256(digest || round || sender || len(label) || label || len(value) || value)
+//
+// where integers are 4 bytes big-endian. All participants must append the same entries in the same order,
+// e.g. the broadcasts of each round in increasing order of the senders.
+type Log struct {
+	digest [sha256.Size]byte
+}
+
+// New starts a log for the DKG identified by `label`, e.g. the protocol and session
+func New(label []byte) *Log {
+	l := new(Log)
+	l.digest = sha256.Sum256(append([]byte("kryptology dkg transcript v1"), label...))
+	return l
+}
+
+// Append adds `value` broadcast by `sender` in `round` to the log. `label` names the value within the round
+func (l *Log) Append(round, sender uint32, label string, value []byte) {
+	h := sha256.New()
+	_, _ = h.Write(l.digest[:])
+	writeUint32(h, round)
+	writeUint32(h, sender)
+	writeUint32(h, uint32(len(label)))
+	_, _ = h.Write([]byte(label))
+	writeUint32(h, uint32(len(value)))
+	_, _ = h.Write(value)
+	copy(l.digest[:], h.Sum(nil))
+}
+
+// Digest returns the current digest of the log
+func (l *Log) Digest() []byte {
+	out := make([]byte, len(l.digest))
+	copy(out, l.digest[:])
+	return out
+}
+
+func writeUint32(h interface{ Write([]byte) (int, error) }, v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	_, _ = h.Write(b[:])
+}
+
+// AppendComplaints appends the complaints broadcast in `round` in the order of `ids`, each as the list of the
+// accused participants. Senders without a complaint are skipped
+func (l *Log) AppendComplaints(round uint32, ids []uint32, complaints map[uint32][]uint32) {
+	for _, id := range ids {
+		accused, ok := complaints[id]
+		if !ok {
+			continue
+		}
+		value := make([]byte, 4*len(accused))
+		for i, a := range accused {
+			binary.BigEndian.PutUint32(value[4*i:], a)
+		}
+		l.Append(round, id, "complaints", value)
+	}
+}
+
+// AppendJustifications appends the justifications broadcast in `round` in the order of `ids`, each as the
+// encoded shares it reveals, in increasing order of the complainers. Senders without a justification are skipped
+func (l *Log) AppendJustifications(round uint32, ids []uint32, justifications map[uint32]map[uint32][]byte) {
+	for _, id := range ids {
+		shares, ok := justifications[id]
+		if !ok {
+			continue
+		}
+		complainers := make([]uint32, 0, len(shares))
+		for complainer := range shares {
+			complainers = append(complainers, complainer)
+		}
+		var value []byte
+		for _, complainer := range sortIds(complainers) {
+			value = append(value, shares[complainer]...)
+		}
+		l.Append(round, id, "justifications", value)
+	}
+}
+
+// Participants returns `self` and `others` in increasing order, the order of the entries of a round
+func Participants(self uint32, others []uint32) []uint32 {
+	return sortIds(append(append([]uint32{}, others...), self))
+}
+
+func sortIds(ids []uint32) []uint32 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
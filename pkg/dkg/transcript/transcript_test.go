@@ -0,0 +1,60 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package transcript
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	a := New([]byte("session"))
+	b := New([]byte("session"))
+	require.Equal(t, a.Digest(), b.Digest())
+	require.NotEqual(t, a.Digest(), New([]byte("other session")).Digest())
+
+	a.Append(1, 2, "commitments", []byte{1, 2, 3})
+	require.NotEqual(t, a.Digest(), b.Digest())
+	b.Append(1, 2, "commitments", []byte{1, 2, 3})
+	require.Equal(t, a.Digest(), b.Digest())
+
+	// every field of an entry changes the digest
+	entries := []func(l *Log){
+		func(l *Log) { l.Append(2, 2, "x", []byte{1}) },
+		func(l *Log) { l.Append(1, 3, "x", []byte{1}) },
+		func(l *Log) { l.Append(1, 2, "y", []byte{1}) },
+		func(l *Log) { l.Append(1, 2, "x", []byte{2}) },
+		func(l *Log) { l.Append(1, 2, "x1", nil) },
+	}
+	digests := map[string]bool{}
+	for _, entry := range entries {
+		l := New(nil)
+		entry(l)
+		digests[string(l.Digest())] = true
+	}
+	require.Len(t, digests, len(entries))
+
+	// the digest is a copy
+	d := a.Digest()
+	d[0] ^= 1
+	require.Equal(t, a.Digest(), b.Digest())
+}
+
+func TestAppendComplaintsAndJustifications(t *testing.T) {
+	ids := Participants(2, []uint32{3, 1})
+	require.Equal(t, []uint32{1, 2, 3}, ids)
+
+	a := New(nil)
+	a.AppendComplaints(2, ids, map[uint32][]uint32{3: {1}, 1: {}})
+	a.AppendJustifications(3, ids, map[uint32]map[uint32][]byte{1: {3: {1}, 2: {2}}})
+	b := New(nil)
+	b.Append(2, 1, "complaints", nil)
+	b.Append(2, 3, "complaints", []byte{0, 0, 0, 1})
+	b.Append(3, 1, "justifications", []byte{2, 1})
+	require.Equal(t, b.Digest(), a.Digest())
+}
@@ -0,0 +1,254 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package endemic implements the endemic OT of [MR19](https://eprint.iacr.org/2019/706.pdf), instantiated with
+// Diffie-Hellman key agreement as in figure 1 of the paper. It is an alternative to the simplest OT of the sibling
+// package `simplest`: it is UC secure in the random oracle model without the CRS and the zero-knowledge proof the
+// verified simplest OT relies on, and it takes two messages instead of six.
+//
+// Like the simplest OT, this is a random OT which runs many OTs in parallel. The outputs are the `simplest.SenderOutput`
+// and `simplest.ReceiverOutput` types, so they can seed the OT extensions (KOS, SoftSpokenOT) as they are.
+//
+// The protocol works as follows. The sender picks a secret key b and sends B = b.G. For each OT with choice bit c, the
+// receiver picks a secret key a, a random point r_{1-c}, and sets r_c = a.G - H(c, r_{1-c}). It sends (r_0, r_1), and
+// the sender sees the two public keys
+//
+//	pk_0 = r_0 + H(0, r_1),  pk_1 = r_1 + H(1, r_0).
+//
+// The sender's keys are hashes of b.pk_0 and b.pk_1, and the receiver's key is the hash of a.B = b.pk_c. As H is a
+// random oracle onto the group, the receiver knows the discrete log of at most one of the public keys, and the pair
+// (r_0, r_1) is uniform whatever the choice bit.
+//
+// Limitation: currently we only support batch OTs that are multiples of 8, and prime order curves such as K256 and P256.
+package endemic
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+)
+
+// keyCount is the number of encryption keys created. Since this is a 1-out-of-2 OT, the key count is set to 2.
+const keyCount = 2
+
+// ReceiversEncodedKeys is the pair (r_0, r_1) of a single OT, in compressed format.
+type ReceiversEncodedKeys = [keyCount][]byte
+
+// Sender stores state for the "sender" role in endemic OT.
+type Sender struct {
+	// Output is the output that is produced as a result of running random OT protocol.
+	Output *simplest.SenderOutput
+
+	curve *curves.Curve
+
+	// secretKey is the value `b`, which is (re)used in _all_ executions of the OT.
+	secretKey curves.Scalar
+
+	// publicKey is B = b.G.
+	publicKey curves.Point
+
+	// batchSize is the number of parallel OTs.
+	batchSize int
+
+	transcript *merlin.Transcript
+}
+
+// Receiver stores state for the "receiver" role in endemic OT.
+type Receiver struct {
+	// Output is the output that is produced as a result of running random OT protocol.
+	Output *simplest.ReceiverOutput
+
+	curve *curves.Curve
+
+	// batchSize is the number of parallel OTs.
+	batchSize int
+
+	transcript *merlin.Transcript
+}
+
+// NewSender creates a new "sender" object, ready to participate in a _random_ endemic OT in the role of the sender.
+func NewSender(curve *curves.Curve, batchSize int, uniqueSessionId [simplest.DigestSize]byte) (*Sender, error) {
+	if batchSize&0x07 != 0 { // This is the same as `batchSize % 8 != 0`, but is constant time
+		return nil, errors.New("batch size should be a multiple of 8")
+	}
+	return &Sender{
+		Output:     &simplest.SenderOutput{},
+		curve:      curve,
+		batchSize:  batchSize,
+		transcript: newTranscript(uniqueSessionId),
+	}, nil
+}
+
+// NewReceiver creates a new "receiver" object. As in simplest OT, the choice bits are created randomly.
+func NewReceiver(curve *curves.Curve, batchSize int, uniqueSessionId [simplest.DigestSize]byte) (*Receiver, error) {
+	if batchSize&0x07 != 0 {
+		return nil, errors.New("batch size should be a multiple of 8")
+	}
+	receiver := &Receiver{
+		Output:     &simplest.ReceiverOutput{},
+		curve:      curve,
+		batchSize:  batchSize,
+		transcript: newTranscript(uniqueSessionId),
+	}
+	receiver.Output.PackedRandomChoiceBits = make([]byte, batchSize>>3)
+	if _, err := rand.Read(receiver.Output.PackedRandomChoiceBits); err != nil {
+		return nil, errors.Wrap(err, "choosing random choice bits")
+	}
+	receiver.Output.RandomChoiceBits = make([]int, batchSize)
+	for i := range receiver.Output.RandomChoiceBits {
+		receiver.Output.RandomChoiceBits[i] = int(simplest.ExtractBitFromByteVector(receiver.Output.PackedRandomChoiceBits, i))
+	}
+	return receiver, nil
+}
+
+func newTranscript(uniqueSessionId [simplest.DigestSize]byte) *merlin.Transcript {
+	transcript := merlin.NewTranscript("Coinbase_Endemic_OT")
+	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
+	return transcript
+}
+
+// Round1ComputePublicKey samples the sender's secret key and returns its public key B, in compressed format.
+func (sender *Sender) Round1ComputePublicKey() []byte {
+	sender.secretKey = sender.curve.Scalar.Random(rand.Reader)
+	sender.publicKey = sender.curve.ScalarBaseMult(sender.secretKey)
+	publicKeyBytes := sender.publicKey.ToAffineCompressed()
+	sender.transcript.AppendMessage([]byte("sender public key"), publicKeyBytes)
+	return publicKeyBytes
+}
+
+// Round2EncodeChoices encodes the receiver's public key of each OT into the pair (r_0, r_1), and computes the
+// receiver's output.
+func (receiver *Receiver) Round2EncodeChoices(senderPublicKeyBytes []byte) ([]ReceiversEncodedKeys, error) {
+	senderPublicKey, err := receiver.curve.Point.FromAffineCompressed(senderPublicKeyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "uncompress the sender public key")
+	}
+	if err = curves.ValidateDHPoint(senderPublicKey); err != nil {
+		return nil, errors.Wrap(err, "validating sender public key in endemic OT receiver round 2")
+	}
+	receiver.transcript.AppendMessage([]byte("sender public key"), senderPublicKeyBytes)
+	salt := [simplest.DigestSize]byte{}
+	copy(salt[:], receiver.transcript.ExtractBytes([]byte("random oracle salts"), simplest.DigestSize))
+
+	result := make([]ReceiversEncodedKeys, receiver.batchSize)
+	receiver.Output.OneTimePadDecryptionKey = make([]simplest.OneTimePadDecryptionKey, receiver.batchSize)
+	for i := 0; i < receiver.batchSize; i++ {
+		choice := receiver.Output.RandomChoiceBits[i]
+		a := receiver.curve.Scalar.Random(rand.Reader)
+		publicKey := receiver.curve.ScalarBaseMult(a)
+		other := receiver.curve.Point.Random(rand.Reader)
+		otherBytes := other.ToAffineCompressed()
+		chosenBytes := publicKey.Sub(hashToPoint(receiver.curve, salt, i, choice, otherBytes)).ToAffineCompressed()
+
+		// Place r_c and r_{1-c} by first assuming that c = 0, and swapping them if the choice bit is 1.
+		result[i][0] = chosenBytes
+		result[i][1] = otherBytes
+		swapped := [keyCount][]byte{make([]byte, len(otherBytes)), make([]byte, len(chosenBytes))}
+		copy(swapped[0], otherBytes)
+		copy(swapped[1], chosenBytes)
+		subtle.ConstantTimeCopy(choice, result[i][0], swapped[0])
+		subtle.ConstantTimeCopy(choice, result[i][1], swapped[1])
+
+		key, err := curves.DH(a, senderPublicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "computing key material in endemic OT receiver round 2")
+		}
+		receiver.Output.OneTimePadDecryptionKey[i], err = deriveKey(salt, i, result[i], key)
+		if err != nil {
+			return nil, errors.Wrap(err, "deriving key in endemic OT receiver round 2")
+		}
+	}
+	receiver.Output.Transcript = simplest.BindTranscript(receiver.transcript, senderPublicKeyBytes, flatten(result))
+	return result, nil
+}
+
+// Round3ComputeKeys recovers the two public keys of each OT from the receiver's pairs, and computes the sender's output.
+func (sender *Sender) Round3ComputeKeys(encodedKeys []ReceiversEncodedKeys) error {
+	if len(encodedKeys) != sender.batchSize {
+		return errors.Errorf("expected %d encoded keys, got %d", sender.batchSize, len(encodedKeys))
+	}
+	salt := [simplest.DigestSize]byte{}
+	copy(salt[:], sender.transcript.ExtractBytes([]byte("random oracle salts"), simplest.DigestSize))
+
+	sender.Output.OneTimePadEncryptionKeys = make([]simplest.OneTimePadEncryptionKeys, sender.batchSize)
+	r := [keyCount]curves.Point{}
+	for i := 0; i < sender.batchSize; i++ {
+		var err error
+		for k := 0; k < keyCount; k++ {
+			if r[k], err = sender.curve.Point.FromAffineCompressed(encodedKeys[i][k]); err != nil {
+				return errors.Wrapf(err, "uncompress r_%d of OT %d", k, i)
+			}
+		}
+		for k := 0; k < keyCount; k++ {
+			publicKey := r[k].Add(hashToPoint(sender.curve, salt, i, k, encodedKeys[i][1-k]))
+			// Both keys must be contributory, otherwise the receiver could learn both of them
+			key, err := curves.DH(sender.secretKey, publicKey)
+			if err != nil {
+				return errors.Wrapf(err, "computing key material %d for OT %d", k, i)
+			}
+			sender.Output.OneTimePadEncryptionKeys[i][k], err = deriveKey(salt, i, encodedKeys[i], key)
+			if err != nil {
+				return errors.Wrap(err, "deriving key in endemic OT sender round 3")
+			}
+		}
+	}
+	sender.Output.Transcript = simplest.BindTranscript(sender.transcript, sender.publicKey.ToAffineCompressed(), flatten(encodedKeys))
+	return nil
+}
+
+// flatten lists the points of the receiver's message, in order.
+func flatten(encodedKeys []ReceiversEncodedKeys) [][]byte {
+	result := make([][]byte, 0, keyCount*len(encodedKeys))
+	for _, pair := range encodedKeys {
+		result = append(result, pair[:]...)
+	}
+	return result
+}
+
+// hashToPoint is the random oracle H onto the group, applied to r_{1-k} to mask the public key pk_k of OT i.
+func hashToPoint(curve *curves.Curve, salt [simplest.DigestSize]byte, i, k int, r []byte) curves.Point {
+	input := make([]byte, 0, len(salt)+5+len(r))
+	input = append(input, salt[:]...)
+	input = append(input, uint32Bytes(i)...)
+	input = append(input, byte(k))
+	input = append(input, r...)
+	return curve.Point.Hash(input)
+}
+
+// deriveKey hashes the key material of OT i into a key, binding it to the receiver's pair.
+func deriveKey(salt [simplest.DigestSize]byte, i int, encodedKeys ReceiversEncodedKeys, key curves.Point) ([simplest.DigestSize]byte, error) {
+	result := [simplest.DigestSize]byte{}
+	hash := sha3.New256()
+	if _, err := hash.Write(salt[:]); err != nil {
+		return result, errors.Wrap(err, "writing seed to hash")
+	}
+	if _, err := hash.Write(uint32Bytes(i)); err != nil {
+		return result, errors.Wrap(err, "writing i to hash")
+	}
+	for k := 0; k < keyCount; k++ {
+		if _, err := hash.Write(encodedKeys[k]); err != nil {
+			return result, errors.Wrap(err, "writing encoded key to hash")
+		}
+	}
+	if _, err := hash.Write(key.ToAffineCompressed()); err != nil {
+		return result, errors.Wrap(err, "writing point to hash")
+	}
+	copy(result[:], hash.Sum(nil))
+	return result, nil
+}
+
+func uint32Bytes(i int) []byte {
+	result := make([]byte, 4)
+	binary.BigEndian.PutUint32(result, uint32(i))
+	return result
+}
@@ -0,0 +1,158 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package endemic_test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/endemic"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+	"github.com/etclab/kryptology/pkg/ot/ottest"
+)
+
+func TestOtOnMultipleCurves(t *testing.T) {
+	curveInstances := []*curves.Curve{
+		curves.K256(),
+		curves.P256(),
+	}
+	for _, curve := range curveInstances {
+		batchSize := 256
+		uniqueSessionId := [simplest.DigestSize]byte{}
+		_, err := rand.Read(uniqueSessionId[:])
+		require.NoError(t, err)
+		sender, receiver, err := ottest.RunEndemicOT(curve, batchSize, uniqueSessionId)
+		require.NoError(t, err)
+		require.Equal(t, sender.Transcript, receiver.Transcript)
+
+		for i := 0; i < batchSize; i++ {
+			choice := receiver.RandomChoiceBits[i]
+			require.Equal(t, receiver.OneTimePadDecryptionKey[i], sender.OneTimePadEncryptionKeys[i][choice])
+			require.NotEqual(t, receiver.OneTimePadDecryptionKey[i], sender.OneTimePadEncryptionKeys[i][1-choice])
+		}
+
+		// Transfer messages
+		messages := make([][2][32]byte, batchSize)
+		for i := 0; i < batchSize; i++ {
+			messages[i] = [2][32]byte{
+				sha256.Sum256([]byte(fmt.Sprintf("message[%d][0]", i))),
+				sha256.Sum256([]byte(fmt.Sprintf("message[%d][1]", i))),
+			}
+		}
+		ciphertexts, err := sender.Encrypt(messages)
+		require.NoError(t, err)
+		decrypted, err := receiver.Decrypt(ciphertexts)
+		require.NoError(t, err)
+		for i := 0; i < batchSize; i++ {
+			require.Equal(t, messages[i][receiver.RandomChoiceBits[i]], decrypted[i])
+		}
+	}
+}
+
+func TestOtSeedsKOS(t *testing.T) {
+	curve := curves.K256()
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunEndemicOT(curve, kos.Kappa, uniqueSessionId)
+	require.NoError(t, err)
+
+	sender := kos.NewCOtSender(baseOtReceiverOutput, curve)
+	receiver := kos.NewCOtReceiver(baseOtSenderOutput, curve)
+	choice := [kos.COtBlockSizeBytes]byte{}
+	_, err = rand.Read(choice[:])
+	require.NoError(t, err)
+	input := [kos.L][kos.OtWidth]curves.Scalar{}
+	for i := 0; i < kos.L; i++ {
+		for j := 0; j < kos.OtWidth; j++ {
+			input[i][j] = curve.Scalar.Random(rand.Reader)
+		}
+	}
+	firstMessage, err := receiver.Round1Initialize(uniqueSessionId, choice)
+	require.NoError(t, err)
+	responseTau, err := sender.Round2Transfer(uniqueSessionId, input, firstMessage)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Round3Transfer(responseTau))
+	for j := 0; j < kos.L; j++ {
+		bit := simplest.ExtractBitFromByteVector(choice[:], j) == 1
+		for k := 0; k < kos.OtWidth; k++ {
+			temp := sender.OutputAdditiveShares[j][k].Add(receiver.OutputAdditiveShares[j][k])
+			if bit {
+				require.Equal(t, temp, input[j][k])
+			} else {
+				require.Equal(t, temp, curve.Scalar.Zero())
+			}
+		}
+	}
+}
+
+func TestOtRejectsInvalidMessages(t *testing.T) {
+	curve := curves.P256()
+	batchSize := 8
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+
+	run := func(tamperPublicKey func([]byte) []byte, tamperKeys func([]endemic.ReceiversEncodedKeys) []endemic.ReceiversEncodedKeys) error {
+		sender, err := endemic.NewSender(curve, batchSize, uniqueSessionId)
+		require.NoError(t, err)
+		receiver, err := endemic.NewReceiver(curve, batchSize, uniqueSessionId)
+		require.NoError(t, err)
+		encodedKeys, err := receiver.Round2EncodeChoices(tamperPublicKey(sender.Round1ComputePublicKey()))
+		if err != nil {
+			return err
+		}
+		return sender.Round3ComputeKeys(tamperKeys(encodedKeys))
+	}
+	identity := func(b []byte) []byte { return b }
+	keep := func(keys []endemic.ReceiversEncodedKeys) []endemic.ReceiversEncodedKeys { return keys }
+
+	require.NoError(t, run(identity, keep))
+	// Malformed sender public key
+	err = run(func(b []byte) []byte { return append([]byte{0x04}, b[1:]...) }, keep)
+	require.Error(t, err)
+	// Too few encoded keys
+	err = run(identity, func(keys []endemic.ReceiversEncodedKeys) []endemic.ReceiversEncodedKeys {
+		return keys[:batchSize-1]
+	})
+	require.Error(t, err)
+	// Malformed point
+	err = run(identity, func(keys []endemic.ReceiversEncodedKeys) []endemic.ReceiversEncodedKeys {
+		keys[3][1] = keys[3][1][1:]
+		return keys
+	})
+	require.Error(t, err)
+
+	_, err = endemic.NewSender(curve, 7, uniqueSessionId)
+	require.Error(t, err)
+	_, err = endemic.NewReceiver(curve, 12, uniqueSessionId)
+	require.Error(t, err)
+}
+
+func TestOtSessionBinding(t *testing.T) {
+	curve := curves.K256()
+	batchSize := 8
+	receiver, err := endemic.NewReceiver(curve, batchSize, [simplest.DigestSize]byte{1})
+	require.NoError(t, err)
+	sender, err := endemic.NewSender(curve, batchSize, [simplest.DigestSize]byte{2})
+	require.NoError(t, err)
+	encodedKeys, err := receiver.Round2EncodeChoices(sender.Round1ComputePublicKey())
+	require.NoError(t, err)
+	require.NoError(t, sender.Round3ComputeKeys(encodedKeys))
+	require.NotEqual(t, receiver.Output.Transcript, sender.Output.Transcript)
+	for i := 0; i < batchSize; i++ {
+		for k := 0; k < 2; k++ {
+			require.NotEqual(t, receiver.Output.OneTimePadDecryptionKey[i], sender.Output.OneTimePadEncryptionKeys[i][k])
+		}
+	}
+}
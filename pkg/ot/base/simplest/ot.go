@@ -22,6 +22,7 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	"fmt"
+	"io"
 
 	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
@@ -66,6 +67,10 @@ type SenderOutput struct {
 	// These can be used to encrypt and send two messages to the receiver.
 	// Therefore, for readability they are called OneTimePadEncryptionKeys  in the code.
 	OneTimePadEncryptionKeys []OneTimePadEncryptionKeys
+
+	// Transcript is a digest of the session id and the messages of the OT, equal to the receiver's. The OT extensions
+	// bind it into their challenges, so that they can't be run on the outputs of a different OT instance.
+	Transcript [DigestSize]byte
 }
 
 // ReceiverOutput are the outputs that the receiver will obtain as a result of running the "random" OT protocol.
@@ -80,6 +85,9 @@ type ReceiverOutput struct {
 	// This value will be used to decrypt one of the messages sent by the sender.
 	// Therefore, for readability this is called OneTimePadDecryptionKey in the code.
 	OneTimePadDecryptionKey []OneTimePadDecryptionKey
+
+	// Transcript is a digest of the session id and the messages of the OT, equal to the sender's.
+	Transcript [DigestSize]byte
 }
 
 // Sender stores state for the "sender" role in OT. see Protocol 7 in Appendix A of DKLs18.
@@ -99,6 +107,9 @@ type Sender struct {
 	batchSize int
 
 	transcript *merlin.Transcript
+
+	// reader is the source of the secret key and of the proof nonce.
+	reader io.Reader
 }
 
 // Receiver stores state for the "receiver" role in OT. Protocol 7, Appendix A, of DKLs.
@@ -118,6 +129,9 @@ type Receiver struct {
 	batchSize int
 
 	transcript *merlin.Transcript
+
+	// reader is the source of the choice bits and of the secret keys a.
+	reader io.Reader
 }
 
 // NewSender creates a new "sender" object, ready to participate in a _random_ verified simplest OT in the role of the sender.
@@ -125,6 +139,12 @@ type Receiver struct {
 // ultimately, the `Sender`'s `Output` field will be appropriately populated.
 // you can use it directly, or alternatively bootstrap it into an _actual_ (non-random) OT using `Round7Encrypt` below
 func NewSender(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize]byte) (*Sender, error) {
+	return NewSenderWithReader(curve, batchSize, uniqueSessionId, rand.Reader)
+}
+
+// NewSenderWithReader is NewSender, with all the randomness of the sender read from `reader`. Anything other than a
+// cryptographically secure random source leaks the sender's keys; this is meant for reproducible test vectors.
+func NewSenderWithReader(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize]byte, reader io.Reader) (*Sender, error) {
 	if batchSize&0x07 != 0 { // This is the same as `batchSize % 8 != 0`, but is constant time
 		return nil, errors.New("batch size should be a multiple of 8")
 	}
@@ -135,12 +155,20 @@ func NewSender(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize]b
 		curve:      curve,
 		batchSize:  batchSize,
 		transcript: transcript,
+		reader:     reader,
 	}, nil
 }
 
 // NewReceiver is a Random OT receiver. Therefore, the choice bits are created randomly.
 // The choice bits are stored in a packed format (e.g., each choice is a single bit in a byte array).
 func NewReceiver(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize]byte) (*Receiver, error) {
+	return NewReceiverWithReader(curve, batchSize, uniqueSessionId, rand.Reader)
+}
+
+// NewReceiverWithReader is NewReceiver, with all the randomness of the receiver read from `reader`, the choice bits
+// first. Anything other than a cryptographically secure random source leaks the receiver's choices; this is meant for
+// reproducible test vectors.
+func NewReceiverWithReader(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize]byte, reader io.Reader) (*Receiver, error) {
 	// This is the same as `batchSize % 8 != 0`, but is constant time
 	if batchSize&0x07 != 0 {
 		return nil, errors.New("batch size should be a multiple of 8")
@@ -154,10 +182,11 @@ func NewReceiver(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize
 		curve:      curve,
 		batchSize:  batchSize,
 		transcript: transcript,
+		reader:     reader,
 	}
 	batchSizeBytes := batchSize >> 3 // divide by 8
 	receiver.Output.PackedRandomChoiceBits = make([]byte, batchSizeBytes)
-	if _, err := rand.Read(receiver.Output.PackedRandomChoiceBits[:]); err != nil {
+	if _, err := io.ReadFull(receiver.reader, receiver.Output.PackedRandomChoiceBits[:]); err != nil {
 		return nil, errors.Wrap(err, "choosing random choice bits")
 	}
 	// Unpack into Choice bits
@@ -171,13 +200,13 @@ func NewReceiver(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize
 func (sender *Sender) Round1ComputeAndZkpToPublicKey() (*schnorr.Proof, error) {
 	var err error
 	// Sample the secret key and compute the public key.
-	sender.secretKey = sender.curve.Scalar.Random(rand.Reader)
+	sender.secretKey = sender.curve.Scalar.Random(sender.reader)
 	sender.publicKey = sender.curve.ScalarBaseMult(sender.secretKey)
 
 	// Generate the ZKP proof.
 	uniqueSessionId := [DigestSize]byte{}
 	copy(uniqueSessionId[:], sender.transcript.ExtractBytes([]byte("sender schnorr proof"), DigestSize))
-	prover := schnorr.NewProver(sender.curve, nil, uniqueSessionId[:])
+	prover := schnorr.NewProverWithReader(sender.curve, nil, uniqueSessionId[:], sender.reader)
 	proof, err := prover.Prove(sender.secretKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating zkp proof for secret key in seed OT sender round 1")
@@ -194,12 +223,16 @@ func (receiver *Receiver) Round2VerifySchnorrAndPadTransfer(proof *schnorr.Proof
 	if err := schnorr.Verify(proof, receiver.curve, nil, uniqueSessionId[:]); err != nil {
 		return nil, errors.Wrap(err, "verifying schnorr proof in seed OT receiver round 2")
 	}
+	// The schnorr proof does not rule out a sender public key of small order, which would make rho predictable
+	if err := curves.ValidateDHPoint(receiver.senderPublicKey); err != nil {
+		return nil, errors.Wrap(err, "validating sender public key in seed OT receiver round 2")
+	}
 
 	result := make([]ReceiversMaskedChoices, receiver.batchSize)
 	receiver.Output.OneTimePadDecryptionKey = make([]OneTimePadDecryptionKey, receiver.batchSize)
 	copy(uniqueSessionId[:], receiver.transcript.ExtractBytes([]byte("random oracle salts"), DigestSize))
 	for i := 0; i < receiver.batchSize; i++ {
-		a := receiver.curve.Scalar.Random(rand.Reader)
+		a := receiver.curve.Scalar.Random(receiver.reader)
 		// Computing `A := a . G + w . B` in constant time, by first computing option0 = a.G and option1 = a.G+B and then
 		// constant time choosing one of them by first assuming that the output is option0, and overwrite it if the choice bit is 1.
 
@@ -211,7 +244,10 @@ func (receiver *Receiver) Round2VerifySchnorrAndPadTransfer(proof *schnorr.Proof
 		result[i] = option0Bytes
 		subtle.ConstantTimeCopy(receiver.Output.RandomChoiceBits[i], result[i], option1Bytes)
 		// compute the internal rho
-		rho := receiver.senderPublicKey.Mul(a)
+		rho, err := curves.DH(a, receiver.senderPublicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "computing rho in round 2 pad transfer")
+		}
 		hash := sha3.New256()
 		if _, err := hash.Write(uniqueSessionId[:]); err != nil {
 			return nil, errors.Wrap(err, "writing seed to hash in round 2 pad transfer")
@@ -224,6 +260,7 @@ func (receiver *Receiver) Round2VerifySchnorrAndPadTransfer(proof *schnorr.Proof
 		}
 		copy(receiver.Output.OneTimePadDecryptionKey[i][:], hash.Sum(nil))
 	}
+	receiver.Output.Transcript = BindTranscript(receiver.transcript, receiver.senderPublicKey.ToAffineCompressed(), result)
 	return result, nil
 }
 
@@ -231,6 +268,9 @@ func (receiver *Receiver) Round2VerifySchnorrAndPadTransfer(proof *schnorr.Proof
 // Returns the challenges xi
 func (sender *Sender) Round3PadTransfer(compressedReceiversMaskedChoice []ReceiversMaskedChoices) ([]OtChallenge, error) {
 	var err error
+	if len(compressedReceiversMaskedChoice) != sender.batchSize {
+		return nil, errors.Errorf("expected %d masked choices, got %d", sender.batchSize, len(compressedReceiversMaskedChoice))
+	}
 	challenge := make([]OtChallenge, sender.batchSize)
 	sender.Output.OneTimePadEncryptionKeys = make([]OneTimePadEncryptionKeys, sender.batchSize)
 	negSenderPublicKey := sender.publicKey.Neg()
@@ -241,6 +281,9 @@ func (sender *Sender) Round3PadTransfer(compressedReceiversMaskedChoice []Receiv
 		if receiversMaskedChoice[i], err = sender.curve.Point.FromAffineCompressed(compressedReceiversMaskedChoice[i]); err != nil {
 			return nil, errors.Wrap(err, "uncompress the point")
 		}
+		if err = curves.ValidateDHPoint(receiversMaskedChoice[i]); err != nil {
+			return nil, errors.Wrapf(err, "validating receiver's masked choice %d", i)
+		}
 	}
 
 	baseEncryptionKeyMaterial := make([]curves.Point, keyCount)
@@ -251,10 +294,15 @@ func (sender *Sender) Round3PadTransfer(compressedReceiversMaskedChoice []Receiv
 	for i := 0; i < sender.batchSize; i++ {
 		// Sender creates two options that will eventually be used as her encryption keys.
 		// `baseEncryptionKeyMaterial[0]` and `baseEncryptionKeyMaterial[0]` correspond to rho_0 and rho_1 in the paper, respectively.
-		baseEncryptionKeyMaterial[0] = receiversMaskedChoice[i].Mul(sender.secretKey)
+		// Both keys must be contributory, otherwise the receiver could learn both of them, e.g. when A = B
+		if baseEncryptionKeyMaterial[0], err = curves.DH(sender.secretKey, receiversMaskedChoice[i]); err != nil {
+			return nil, errors.Wrapf(err, "computing rho_0 for OT %d", i)
+		}
 
 		receiverChoiceMinusSenderPublicKey := receiversMaskedChoice[i].Add(negSenderPublicKey)
-		baseEncryptionKeyMaterial[1] = receiverChoiceMinusSenderPublicKey.Mul(sender.secretKey)
+		if baseEncryptionKeyMaterial[1], err = curves.DH(sender.secretKey, receiverChoiceMinusSenderPublicKey); err != nil {
+			return nil, errors.Wrapf(err, "computing rho_1 for OT %d", i)
+		}
 
 		for k := 0; k < keyCount; k++ {
 			hash := sha3.New256()
@@ -279,6 +327,7 @@ func (sender *Sender) Round3PadTransfer(compressedReceiversMaskedChoice []Receiv
 
 		challenge[i] = xorBytes(hashedKey[0], hashedKey[1])
 	}
+	sender.Output.Transcript = BindTranscript(sender.transcript, sender.publicKey.ToAffineCompressed(), compressedReceiversMaskedChoice)
 	return challenge, nil
 }
 
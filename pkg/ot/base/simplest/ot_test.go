@@ -57,6 +57,43 @@ func TestOtOnMultipleCurves(t *testing.T) {
 	}
 }
 
+func TestOtRejectsNonContributoryPoints(t *testing.T) {
+	curve := curves.P256()
+	batchSize := 8
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+
+	run := func(tamper func(senderPublicKey curves.Point, choices []simplest.ReceiversMaskedChoices) []simplest.ReceiversMaskedChoices) error {
+		sender, err := simplest.NewSender(curve, batchSize, uniqueSessionId)
+		require.NoError(t, err)
+		receiver, err := simplest.NewReceiver(curve, batchSize, uniqueSessionId)
+		require.NoError(t, err)
+		proof, err := sender.Round1ComputeAndZkpToPublicKey()
+		require.NoError(t, err)
+		choices, err := receiver.Round2VerifySchnorrAndPadTransfer(proof)
+		require.NoError(t, err)
+		_, err = sender.Round3PadTransfer(tamper(proof.Statement, choices))
+		return err
+	}
+
+	err = run(func(_ curves.Point, choices []simplest.ReceiversMaskedChoices) []simplest.ReceiversMaskedChoices {
+		return choices
+	})
+	require.NoError(t, err)
+	// A = B makes rho_1 the identity, so the receiver would learn both keys
+	err = run(func(senderPublicKey curves.Point, choices []simplest.ReceiversMaskedChoices) []simplest.ReceiversMaskedChoices {
+		choices[0] = senderPublicKey.ToAffineCompressed()
+		return choices
+	})
+	require.ErrorIs(t, err, curves.ErrIdentityPoint)
+	// Too few masked choices
+	err = run(func(_ curves.Point, choices []simplest.ReceiversMaskedChoices) []simplest.ReceiversMaskedChoices {
+		return choices[:batchSize-1]
+	})
+	require.Error(t, err)
+}
+
 func TestOTStreaming(t *testing.T) {
 	batchSize := 256
 	curve := curves.K256()
@@ -83,3 +120,18 @@ func TestOTStreaming(t *testing.T) {
 		require.Equal(t, receiver.Output.OneTimePadDecryptionKey[i], sender.Output.OneTimePadEncryptionKeys[i][receiver.Output.RandomChoiceBits[i]])
 	}
 }
+
+func TestOtTranscript(t *testing.T) {
+	curve := curves.K256()
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	sender, receiver, err := ottest.RunSimplestOT(curve, 8, uniqueSessionId)
+	require.NoError(t, err)
+	require.NotEqual(t, [simplest.DigestSize]byte{}, sender.Transcript)
+	require.Equal(t, sender.Transcript, receiver.Transcript)
+
+	// a second run of the same session has fresh messages, hence a different transcript
+	otherSender, otherReceiver, err := ottest.RunSimplestOT(curve, 8, uniqueSessionId)
+	require.NoError(t, err)
+	require.Equal(t, otherSender.Transcript, otherReceiver.Transcript)
+	require.NotEqual(t, sender.Transcript, otherSender.Transcript)
+}
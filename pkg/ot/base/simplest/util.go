@@ -2,6 +2,8 @@ package simplest
 
 import (
 	"io"
+
+	"github.com/gtank/merlin"
 )
 
 // xorBytes computes c = a xor b.
@@ -21,6 +23,18 @@ func (receiver *Receiver) initChoice() {
 	}
 }
 
+// BindTranscript appends the sender's public key and the receiver's messages of a base OT to its transcript, and
+// extracts the digest that both parties record as the `Transcript` of their outputs.
+func BindTranscript(transcript *merlin.Transcript, senderPublicKey []byte, receiverMessages [][]byte) [DigestSize]byte {
+	transcript.AppendMessage([]byte("sender public key"), senderPublicKey)
+	for _, message := range receiverMessages {
+		transcript.AppendMessage([]byte("receiver message"), message)
+	}
+	result := [DigestSize]byte{}
+	copy(result[:], transcript.ExtractBytes([]byte("session transcript"), DigestSize))
+	return result
+}
+
 // ExtractBitFromByteVector interprets the byte-vector `vector` as if it were a _bit_-vector with len(vector) * 8 bits.
 // it extracts the `index`th such bit, interpreted in the little-endian way (i.e., both across bytes and within bytes).
 func ExtractBitFromByteVector(vector []byte, index int) byte {
@@ -0,0 +1,268 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package kos
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+)
+
+// The cOT of Round1Initialize and Round2Transfer works on batches of exactly L OTs. Workloads such as PSI or garbled
+// circuits need millions of random OTs, which the chunked extension below produces without materializing them all at
+// once: the receiver and the sender expand the base OT seeds into one long pseudorandom stream each, and consume it
+// chunk by chunk. Every chunk is a full KOS extension of its own length, with its own kappaOT columns of padding and
+// its own consistency check, so its memory footprint is Kappa * (count + kappaOT) bits for `count` OTs, and the
+// outputs of a chunk can be used before the next one is computed.
+//
+// The outputs are random OTs, as in Round2Random: two random messages per OT for the sender, and the one selected by
+// the choice bit for the receiver.
+
+// ChunkMessage is the receiver's message for one chunk: the rows of the matrix U, and the two sums of the
+// consistency check.
+type ChunkMessage struct {
+	U      [Kappa][]byte
+	WPrime [simplest.DigestSize]byte
+	VPrime [simplest.DigestSize]byte
+}
+
+// ChunkedReceiver is the receiver of the chunked extension. Create it with NewChunkedReceiver.
+type ChunkedReceiver struct {
+	seedOtTranscript [simplest.DigestSize]byte
+	uniqueSessionId  [simplest.DigestSize]byte
+	// prgs[i][b] expands the base OT seed s_i^b
+	prgs  [Kappa][2]sha3.ShakeHash
+	chunk uint32
+}
+
+// ChunkedSender is the sender of the chunked extension. Create it with NewChunkedSender.
+type ChunkedSender struct {
+	seedOtResults   *simplest.ReceiverOutput
+	uniqueSessionId [simplest.DigestSize]byte
+	// prgs[i] expands the base OT seed s_i^{Nabla_i}
+	prgs  [Kappa]sha3.ShakeHash
+	chunk uint32
+}
+
+// newChunkPrg starts the pseudorandom stream of a base OT seed.
+func newChunkPrg(uniqueSessionId [simplest.DigestSize]byte, seed [simplest.DigestSize]byte) (sha3.ShakeHash, error) {
+	shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_KOS_chunked"))
+	if _, err := shake.Write(seed[:]); err != nil {
+		return nil, errors.Wrap(err, "writing seed OT into shake")
+	}
+	return shake, nil
+}
+
+// NewChunkedReceiver creates a receiver for the chunked extension. As for NewCOtReceiver, it takes the output of the
+// base OT in which it played the _sender_. Each session id must be used for a single chunked extension.
+func NewChunkedReceiver(seedOtResults *simplest.SenderOutput, uniqueSessionId [simplest.DigestSize]byte) (*ChunkedReceiver, error) {
+	if len(seedOtResults.OneTimePadEncryptionKeys) != Kappa {
+		return nil, fmt.Errorf("expected %d base OTs, got %d", Kappa, len(seedOtResults.OneTimePadEncryptionKeys))
+	}
+	receiver := &ChunkedReceiver{seedOtTranscript: seedOtResults.Transcript, uniqueSessionId: uniqueSessionId}
+	for i := range receiver.prgs {
+		for b := range receiver.prgs[i] {
+			var err error
+			if receiver.prgs[i][b], err = newChunkPrg(uniqueSessionId, seedOtResults.OneTimePadEncryptionKeys[i][b]); err != nil {
+				return nil, errors.Wrap(err, "creating chunked receiver")
+			}
+		}
+	}
+	return receiver, nil
+}
+
+// NewChunkedSender creates a sender for the chunked extension. As for NewCOtSender, it takes the output of the base
+// OT in which it played the _receiver_.
+func NewChunkedSender(seedOtResults *simplest.ReceiverOutput, uniqueSessionId [simplest.DigestSize]byte) (*ChunkedSender, error) {
+	if len(seedOtResults.OneTimePadDecryptionKey) != Kappa || len(seedOtResults.RandomChoiceBits) != Kappa {
+		return nil, fmt.Errorf("expected %d base OTs, got %d", Kappa, len(seedOtResults.OneTimePadDecryptionKey))
+	}
+	sender := &ChunkedSender{seedOtResults: seedOtResults, uniqueSessionId: uniqueSessionId}
+	for i := range sender.prgs {
+		var err error
+		if sender.prgs[i], err = newChunkPrg(uniqueSessionId, seedOtResults.OneTimePadDecryptionKey[i]); err != nil {
+			return nil, errors.Wrap(err, "creating chunked sender")
+		}
+	}
+	return sender, nil
+}
+
+// Extend runs the receiver's side of the next chunk, with one OT per bit of the packed choice vector `choice`. It
+// returns the message for the sender, and the receiver's message of each OT of the chunk.
+func (receiver *ChunkedReceiver) Extend(choice []byte) (*ChunkMessage, [][simplest.DigestSize]byte, error) {
+	if len(choice) == 0 {
+		return nil, nil, errors.New("a chunk must have at least one OT")
+	}
+	count := len(choice) << 3
+	extended := make([]byte, len(choice)+kappaOT>>3)
+	copy(extended, choice)
+	// These random values correspond to `gamma^{ext}`.
+	if _, err := rand.Read(extended[len(choice):]); err != nil {
+		return nil, nil, errors.Wrap(err, "sampling random coins for gamma^{ext}")
+	}
+
+	chunk := receiver.chunk
+	receiver.chunk++
+
+	result := &ChunkMessage{}
+	v := [Kappa][]byte{}
+	for i := 0; i < Kappa; i++ {
+		v[i] = make([]byte, len(extended))
+		result.U[i] = make([]byte, len(extended))
+		if _, err := receiver.prgs[i][0].Read(v[i]); err != nil {
+			return nil, nil, errors.Wrap(err, "reading from shake to compute v^0 in chunked receiver")
+		}
+		if _, err := receiver.prgs[i][1].Read(result.U[i]); err != nil {
+			return nil, nil, errors.Wrap(err, "reading from shake to compute v^1 in chunked receiver")
+		}
+		for j := range extended {
+			result.U[i][j] ^= v[i][j] ^ extended[j]
+		}
+	}
+	psi := make([][KappaBytes]byte, len(extended)<<3)
+	transposeRows(&v, psi)
+
+	chi, err := newChunkChallenge(receiver.uniqueSessionId, receiver.seedOtTranscript, chunk, &result.U)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "computing challenge in chunked receiver")
+	}
+	chiJ := make([]byte, KappaBytes)
+	for j := range psi {
+		if _, err = chi.Read(chiJ); err != nil {
+			return nil, nil, errors.Wrap(err, "reading chiJ in chunked receiver")
+		}
+		wJ := convertBitToBitmask(simplest.ExtractBitFromByteVector(extended, j))
+		psiJTimesChiJ := binaryFieldMul(psi[j][:], chiJ)
+		for k := 0; k < KappaBytes; k++ {
+			result.WPrime[k] ^= wJ & chiJ[k]
+			result.VPrime[k] ^= psiJTimesChiJ[k]
+		}
+	}
+
+	messages := make([][simplest.DigestSize]byte, count)
+	for j := range messages {
+		if err = hashChunkedOT(receiver.uniqueSessionId, chunk, j, psi[j][:], &messages[j]); err != nil {
+			return nil, nil, errors.Wrap(err, "computing message in chunked receiver")
+		}
+	}
+	return result, messages, nil
+}
+
+// Extend runs the sender's side of the next chunk: it verifies the receiver's message, and returns the sender's two
+// messages of each OT of the chunk. If the verification fails, the extension must be abandoned.
+func (sender *ChunkedSender) Extend(message *ChunkMessage) ([][2][simplest.DigestSize]byte, error) {
+	length := len(message.U[0])
+	if length <= kappaOT>>3 {
+		return nil, fmt.Errorf("expected more than %d bytes per row of U, got %d", kappaOT>>3, length)
+	}
+	// the streams are consumed whether or not the chunk passes the check, so the chunk counts all the same
+	chunk := sender.chunk
+	sender.chunk++
+
+	z := [Kappa][]byte{}
+	for i := 0; i < Kappa; i++ {
+		if len(message.U[i]) != length {
+			return nil, fmt.Errorf("expected %d bytes in row %d of U, got %d", length, i, len(message.U[i]))
+		}
+		z[i] = make([]byte, length)
+		if _, err := sender.prgs[i].Read(z[i]); err != nil {
+			return nil, errors.Wrap(err, "reading from shake into row `v` in chunked sender")
+		}
+		mask := convertBitToBitmask(byte(sender.seedOtResults.RandomChoiceBits[i]))
+		for j := range z[i] {
+			z[i][j] ^= mask & message.U[i][j]
+		}
+	}
+	zeta := make([][KappaBytes]byte, length<<3)
+	transposeRows(&z, zeta)
+
+	chi, err := newChunkChallenge(sender.uniqueSessionId, sender.seedOtResults.Transcript, chunk, &message.U)
+	if err != nil {
+		return nil, errors.Wrap(err, "computing challenge in chunked sender")
+	}
+	chiJ := make([]byte, KappaBytes)
+	zPrime := [simplest.DigestSize]byte{}
+	for j := range zeta {
+		if _, err = chi.Read(chiJ); err != nil {
+			return nil, errors.Wrap(err, "reading chiJ in chunked sender")
+		}
+		zetaJTimesChiJ := binaryFieldMul(zeta[j][:], chiJ)
+		for k := 0; k < KappaBytes; k++ {
+			zPrime[k] ^= zetaJTimesChiJ[k]
+		}
+	}
+	rhs := [simplest.DigestSize]byte{}
+	nablaTimesWPrime := binaryFieldMul(sender.seedOtResults.PackedRandomChoiceBits, message.WPrime[:])
+	for i := 0; i < KappaBytes; i++ {
+		rhs[i] = message.VPrime[i] ^ nablaTimesWPrime[i]
+	}
+	if subtle.ConstantTimeCompare(zPrime[:], rhs[:]) != 1 {
+		return nil, fmt.Errorf("chunked receiver's consistency check failed; this may be an attempted attack; do NOT re-run the protocol")
+	}
+
+	result := make([][2][simplest.DigestSize]byte, len(zeta)-kappaOT)
+	for j := range result {
+		if err = hashChunkedOT(sender.uniqueSessionId, chunk, j, zeta[j][:], &result[j][0]); err != nil {
+			return nil, errors.Wrap(err, "computing first message in chunked sender")
+		}
+		for i := 0; i < KappaBytes; i++ {
+			zeta[j][i] ^= sender.seedOtResults.PackedRandomChoiceBits[i]
+		}
+		if err = hashChunkedOT(sender.uniqueSessionId, chunk, j, zeta[j][:], &result[j][1]); err != nil {
+			return nil, errors.Wrap(err, "computing second message in chunked sender")
+		}
+	}
+	return result, nil
+}
+
+// newChunkChallenge hashes the matrix U of a chunk, bound to the session, the base OT and the chunk index, into the
+// stream of coefficients chi_j of the consistency check.
+func newChunkChallenge(uniqueSessionId, seedOtTranscript [simplest.DigestSize]byte, chunk uint32, u *[Kappa][]byte) (sha3.ShakeHash, error) {
+	hash, err := newChallengeHash(uniqueSessionId, seedOtTranscript)
+	if err != nil {
+		return nil, err
+	}
+	chunkBytes := [4]byte{}
+	binary.BigEndian.PutUint32(chunkBytes[:], chunk)
+	if _, err = hash.Write(chunkBytes[:]); err != nil {
+		return nil, errors.Wrap(err, "writing chunk index into hash")
+	}
+	for i := range u {
+		if _, err = hash.Write(u[i]); err != nil {
+			return nil, errors.Wrap(err, "writing matrix U into hash")
+		}
+	}
+	shake := sha3.NewCShake256(nil, []byte("Coinbase_KOS_chunked_chi"))
+	if _, err = shake.Write(hash.Sum(nil)); err != nil {
+		return nil, errors.Wrap(err, "writing digest of U into shake")
+	}
+	return shake, nil
+}
+
+// hashChunkedOT breaks the correlation of the row of OT j of a chunk.
+func hashChunkedOT(uniqueSessionId [simplest.DigestSize]byte, chunk uint32, j int, row []byte, out *[simplest.DigestSize]byte) error {
+	shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_KOS_chunked_ROT"))
+	nonce := [8]byte{}
+	binary.BigEndian.PutUint32(nonce[:4], chunk)
+	binary.BigEndian.PutUint32(nonce[4:], uint32(j))
+	if _, err := shake.Write(nonce[:]); err != nil {
+		return errors.Wrap(err, "writing nonce into shake")
+	}
+	if _, err := shake.Write(row); err != nil {
+		return errors.Wrap(err, "writing row into shake")
+	}
+	if _, err := shake.Read(out[:]); err != nil {
+		return errors.Wrap(err, "reading shake into message")
+	}
+	return nil
+}
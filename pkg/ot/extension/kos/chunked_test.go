@@ -0,0 +1,115 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package kos
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/ottest"
+)
+
+func setupChunked(t testing.TB) (*ChunkedSender, *ChunkedReceiver) {
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curves.K256(), Kappa, uniqueSessionId)
+	require.NoError(t, err)
+	sender, err := NewChunkedSender(baseOtReceiverOutput, uniqueSessionId)
+	require.NoError(t, err)
+	receiver, err := NewChunkedReceiver(baseOtSenderOutput, uniqueSessionId)
+	require.NoError(t, err)
+	return sender, receiver
+}
+
+func runChunk(t testing.TB, sender *ChunkedSender, receiver *ChunkedReceiver, choiceBytes int) {
+	choice := make([]byte, choiceBytes)
+	_, err := rand.Read(choice)
+	require.NoError(t, err)
+	message, receiverOutput, err := receiver.Extend(choice)
+	require.NoError(t, err)
+	senderOutput, err := sender.Extend(message)
+	require.NoError(t, err)
+	require.Len(t, receiverOutput, choiceBytes<<3)
+	require.Len(t, senderOutput, choiceBytes<<3)
+	for j := range receiverOutput {
+		bit := simplest.ExtractBitFromByteVector(choice, j)
+		require.Equal(t, senderOutput[j][bit], receiverOutput[j])
+		require.NotEqual(t, senderOutput[j][1-bit], receiverOutput[j])
+	}
+}
+
+func TestChunkedOT(t *testing.T) {
+	sender, receiver := setupChunked(t)
+	// chunks of different sizes consume the same streams
+	for _, choiceBytes := range []int{1, COtBlockSizeBytes, 1000, 3} {
+		runChunk(t, sender, receiver, choiceBytes)
+	}
+}
+
+func TestChunkedOTManyChunks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping a million OTs in short mode")
+	}
+	sender, receiver := setupChunked(t)
+	// 2^20 OTs, of which only one chunk of 2^16 is in memory at a time
+	for i := 0; i < 16; i++ {
+		runChunk(t, sender, receiver, 1<<13)
+	}
+}
+
+func TestChunkedOTConsistencyCheck(t *testing.T) {
+	sender, receiver := setupChunked(t)
+	message, _, err := receiver.Extend(make([]byte, 16))
+	require.NoError(t, err)
+	message.U[7][3] ^= 0x01
+	_, err = sender.Extend(message)
+	require.Error(t, err)
+
+	// a chunk out of order fails the check, as it is bound to its index
+	sender, receiver = setupChunked(t)
+	_, _, err = receiver.Extend(make([]byte, 16))
+	require.NoError(t, err)
+	message, _, err = receiver.Extend(make([]byte, 16))
+	require.NoError(t, err)
+	_, err = sender.Extend(message)
+	require.Error(t, err)
+
+	// rows of the wrong length
+	message.U[3] = message.U[3][1:]
+	_, err = sender.Extend(message)
+	require.Error(t, err)
+	_, err = sender.Extend(&ChunkMessage{})
+	require.Error(t, err)
+	_, _, err = receiver.Extend(nil)
+	require.Error(t, err)
+}
+
+func TestChunkedOTInvalidBaseOT(t *testing.T) {
+	_, err := NewChunkedSender(&simplest.ReceiverOutput{}, [simplest.DigestSize]byte{})
+	require.Error(t, err)
+	_, err = NewChunkedReceiver(&simplest.SenderOutput{}, [simplest.DigestSize]byte{})
+	require.Error(t, err)
+}
+
+func BenchmarkChunkedOT(b *testing.B) {
+	sender, receiver := setupChunked(b)
+	for _, choiceBytes := range []int{COtBlockSizeBytes, 1 << 10, 1 << 13} {
+		choice := make([]byte, choiceBytes)
+		b.Run(fmt.Sprintf("%d OTs", choiceBytes<<3), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				message, _, _ := receiver.Extend(choice)
+				_, _ = sender.Extend(message)
+			}
+		})
+	}
+}
@@ -7,6 +7,9 @@
 // Package kos in an implementation of maliciously secure OT extension protocol defined in "Protocol 9" of
 // [DKLs18](https://eprint.iacr.org/2018/499.pdf). The original protocol was presented in
 // [KOS15](https://eprint.iacr.org/2015/546.pdf).
+//
+// Besides the cOT over scalars, the extension can output correlated OTs or random OTs after the receiver's first
+// message, see Round2Correlated and Round2Random.
 package kos
 
 import (
@@ -14,6 +17,8 @@ import (
 	"crypto/subtle"
 	"encoding/binary"
 	"fmt"
+	"hash"
+	"io"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
@@ -63,6 +68,9 @@ type Receiver struct {
 
 	curve           *curves.Curve
 	uniqueSessionId [simplest.DigestSize]byte // store this between rounds
+
+	// reader is the source of the random coins gamma^{ext}.
+	reader io.Reader
 }
 
 type Sender struct {
@@ -133,9 +141,16 @@ func binaryFieldMul(A []byte, B []byte) []byte {
 // NewCOtReceiver creates a `Receiver` instance, ready for use as the receiver in the KOS cOT protocol
 // you must supply the output gotten by running an instance of seed OT as the _sender_ (note the reversal of roles)
 func NewCOtReceiver(seedOTResults *simplest.SenderOutput, curve *curves.Curve) *Receiver {
+	return NewCOtReceiverWithReader(seedOTResults, curve, rand.Reader)
+}
+
+// NewCOtReceiverWithReader is NewCOtReceiver, with the random coins gamma^{ext} read from `reader`. Anything other than
+// a cryptographically secure random source leaks the receiver's choices; this is meant for reproducible test vectors.
+func NewCOtReceiverWithReader(seedOTResults *simplest.SenderOutput, curve *curves.Curve, reader io.Reader) *Receiver {
 	return &Receiver{
 		seedOtResults: seedOTResults,
 		curve:         curve,
+		reader:        reader,
 	}
 }
 
@@ -173,31 +188,27 @@ func convertBitToBitmask(bit byte) byte {
 // its output is the same boolean matrix, but transposed, so it has dimensions `lPrime` by `kappa`.
 // but likewise we want to compact the output matrix as bytes, again _row-wise_.
 // so the output matrix's dimensions are lPrime by `kappa >> 3 == KappaBytes`, as a _byte_ matrix.
-// the technique is fairly straightforward, but involves some bitwise operations.
+// the work is done by `transpose`, which is written in assembly on amd64 and falls back to `transposeGeneric` elsewhere.
 func transposeBooleanMatrix(input [Kappa][cOtExtendedBlockSizeBytes]byte) [lPrime][KappaBytes]byte {
 	output := [lPrime][KappaBytes]byte{}
-	for rowByte := 0; rowByte < KappaBytes; rowByte++ {
-		for rowBitWithinByte := 0; rowBitWithinByte < 8; rowBitWithinByte++ {
-			for columnByte := 0; columnByte < cOtExtendedBlockSizeBytes; columnByte++ {
-				for columnBitWithinByte := 0; columnBitWithinByte < 8; columnBitWithinByte++ {
-					rowBit := rowByte<<3 + rowBitWithinByte
-					columnBit := columnByte<<3 + columnBitWithinByte
-					// the below code grabs the _bit_ at input[rowBit][columnBit], if input were a viewed as a boolean matrix.
-					// in reality, it's packed into bytes, so instead we have to grab the `columnBitWithinByte`th bit within the appropriate byte.
-					bitAtInputRowBitColumnBit := input[rowBit][columnByte] >> columnBitWithinByte & 0x01
-					// now that we've grabbed the bit we care about, we need to write it into the appropriate place in the output matrix
-					// the output matrix is also packed---but in the "opposite" way (the short dimension is packed, instead of the long one)
-					// what we're going to do is take the _bit_ we got, and shift it by rowBitWithinByte.
-					// this has the effect of preparing for us to write it into the appropriate place into the output matrix.
-					shiftedBit := bitAtInputRowBitColumnBit << rowBitWithinByte
-					output[columnBit][rowByte] |= shiftedBit
-				}
-			}
-		}
-	}
+	transpose(&input, &output)
 	return output
 }
 
+// newChallengeHash starts the hash from which the coefficients chi_j of the consistency check are derived. It binds
+// the session id, which callers derive from the transcript of their own protocol, and the transcript of the base OT,
+// so that the check of one OT extension instance can't be replayed in another session or with other base OT outputs.
+func newChallengeHash(uniqueSessionId, seedOtTranscript [simplest.DigestSize]byte) (hash.Hash, error) {
+	result := sha3.New256()
+	if _, err := result.Write(uniqueSessionId[:]); err != nil {
+		return nil, errors.Wrap(err, "writing session id into hash")
+	}
+	if _, err := result.Write(seedOtTranscript[:]); err != nil {
+		return nil, errors.Wrap(err, "writing seed OT transcript into hash")
+	}
+	return result, nil
+}
+
 // Round1Initialize initializes the OT Extension. see page 17, steps 1), 2), 3) and 4) of Protocol 9 of the paper.
 // The input `choice` vector is "packed" (i.e., the underlying abstract vector of `L` bits is represented as a `cOTBlockSizeBytes` bytes).
 func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]byte, choice [COtBlockSizeBytes]byte) (*Round1Output, error) {
@@ -209,14 +220,18 @@ func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]
 	copy(receiver.extendedPackedChoices[0:COtBlockSizeBytes], choice[:])
 
 	// Fill the rest of the extended choice vector with random values. These random values correspond to `gamma^{ext}`.
-	if _, err := rand.Read(receiver.extendedPackedChoices[COtBlockSizeBytes:]); err != nil {
+	if _, err := io.ReadFull(receiver.reader, receiver.extendedPackedChoices[COtBlockSizeBytes:]); err != nil {
 		return nil, errors.Wrap(err, "sampling random coins for gamma^{ext}")
 	}
 
 	v := [2][Kappa][cOtExtendedBlockSizeBytes]byte{} // kappa * L array of _bits_, in "dense" form. contains _both_ v_0 and v_1.
 	result := &Round1Output{}
 
-	hash := sha3.New256() // basically this will contain a hash of the matrix U.
+	// basically this will contain a hash of the matrix U, bound to this session and to the base OT it extends.
+	hash, err := newChallengeHash(uniqueSessionId, receiver.seedOtResults.Transcript)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding session in cOT receiver round 1")
+	}
 	for i := 0; i < Kappa; i++ {
 		for j := 0; j < 2; j++ {
 			shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
@@ -267,53 +282,9 @@ func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]
 // the output is just the values `Tau` we send back to Bob.
 // as a side effect of this function, our (i.e., the sender's) outputs tA_j from the cOT will be populated.
 func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte, input [L][OtWidth]curves.Scalar, round1Output *Round1Output) (*Round2Output, error) {
-	z := [Kappa][cOtExtendedBlockSizeBytes]byte{}
-	hash := sha3.New256() // basically this will contain a hash of the matrix U.
-
-	for i := 0; i < Kappa; i++ {
-		v := make([]byte, cOtExtendedBlockSizeBytes) // will contain alice's expanded PRG output for the row i, namely v_i^{\Nabla_i}.
-		shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
-		if _, err := shake.Write(sender.seedOtResults.OneTimePadDecryptionKey[i][:]); err != nil {
-			return nil, errors.Wrap(err, "sender writing seed OT decryption key into shake in sender round 2 transfer")
-		}
-		if _, err := shake.Read(v); err != nil {
-			return nil, errors.Wrap(err, "reading from shake into row `v` in sender round 2 transfer")
-		}
-		// use the idExt as the domain separator, and the _secret_ seed rho as the input!
-		mask := convertBitToBitmask(byte(sender.seedOtResults.RandomChoiceBits[i]))
-		for j := 0; j < cOtExtendedBlockSizeBytes; j++ {
-			z[i][j] = v[j] ^ mask&round1Output.U[i][j]
-		}
-		if _, err := hash.Write(round1Output.U[i][:]); err != nil {
-			return nil, errors.Wrap(err, "writing matrix U to hash in cOT sender round 2 transfer")
-		}
-	}
-	zeta := transposeBooleanMatrix(z)
-	digest := hash.Sum(nil) // go ahead and record this, so that we only have to hash the big matrix U once.
-	zPrime := [simplest.DigestSize]byte{}
-	for j := 0; j < lPrime; j++ {
-		hash = sha3.New256()
-		jBytes := [2]byte{}
-		binary.BigEndian.PutUint16(jBytes[:], uint16(j))
-		if _, err := hash.Write(jBytes[:]); err != nil { // write j into hash
-			return nil, errors.Wrap(err, "writing nonce into hash while computing chiJ in cOT sender round 2 transfer")
-		}
-		if _, err := hash.Write(digest); err != nil {
-			return nil, errors.Wrap(err, "writing input digest into hash while computing chiJ in cOT sender round 2 transfer")
-		}
-		chiJ := hash.Sum(nil)
-		zetaJTimesChiJ := binaryFieldMul(zeta[j][:], chiJ)
-		for k := 0; k < KappaBytes; k++ {
-			zPrime[k] ^= zetaJTimesChiJ[k]
-		}
-	}
-	rhs := [simplest.DigestSize]byte{}
-	nablaTimesWPrime := binaryFieldMul(sender.seedOtResults.PackedRandomChoiceBits, round1Output.WPrime[:])
-	for i := 0; i < KappaBytes; i++ {
-		rhs[i] = round1Output.VPrime[i] ^ nablaTimesWPrime[i]
-	}
-	if subtle.ConstantTimeCompare(zPrime[:], rhs[:]) != 1 {
-		return nil, fmt.Errorf("cOT receiver's consistency check failed; this may be an attempted attack; do NOT re-run the protocol")
+	zeta, err := sender.verifyRound1(uniqueSessionId, round1Output)
+	if err != nil {
+		return nil, err
 	}
 	result := &Round2Output{}
 	for j := 0; j < L; j++ {
@@ -364,6 +335,64 @@ func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte,
 	return result, nil
 }
 
+// verifyRound1 computes the sender's rows zeta_j of the extended correlation from the receiver's matrix U, and runs
+// the consistency check of step 5) of Protocol 9. zeta_j equals the receiver's psi_j if w_j == 0, and psi_j ^ Nabla otherwise.
+func (sender *Sender) verifyRound1(uniqueSessionId [simplest.DigestSize]byte, round1Output *Round1Output) (*[lPrime][KappaBytes]byte, error) {
+	z := [Kappa][cOtExtendedBlockSizeBytes]byte{}
+	// basically this will contain a hash of the matrix U, bound to this session and to the base OT it extends.
+	hash, err := newChallengeHash(uniqueSessionId, sender.seedOtResults.Transcript)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding session in cOT sender round 2 transfer")
+	}
+
+	for i := 0; i < Kappa; i++ {
+		v := make([]byte, cOtExtendedBlockSizeBytes) // will contain alice's expanded PRG output for the row i, namely v_i^{\Nabla_i}.
+		shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
+		if _, err := shake.Write(sender.seedOtResults.OneTimePadDecryptionKey[i][:]); err != nil {
+			return nil, errors.Wrap(err, "sender writing seed OT decryption key into shake in sender round 2 transfer")
+		}
+		if _, err := shake.Read(v); err != nil {
+			return nil, errors.Wrap(err, "reading from shake into row `v` in sender round 2 transfer")
+		}
+		// use the idExt as the domain separator, and the _secret_ seed rho as the input!
+		mask := convertBitToBitmask(byte(sender.seedOtResults.RandomChoiceBits[i]))
+		for j := 0; j < cOtExtendedBlockSizeBytes; j++ {
+			z[i][j] = v[j] ^ mask&round1Output.U[i][j]
+		}
+		if _, err := hash.Write(round1Output.U[i][:]); err != nil {
+			return nil, errors.Wrap(err, "writing matrix U to hash in cOT sender round 2 transfer")
+		}
+	}
+	zeta := transposeBooleanMatrix(z)
+	digest := hash.Sum(nil) // go ahead and record this, so that we only have to hash the big matrix U once.
+	zPrime := [simplest.DigestSize]byte{}
+	for j := 0; j < lPrime; j++ {
+		hash = sha3.New256()
+		jBytes := [2]byte{}
+		binary.BigEndian.PutUint16(jBytes[:], uint16(j))
+		if _, err := hash.Write(jBytes[:]); err != nil { // write j into hash
+			return nil, errors.Wrap(err, "writing nonce into hash while computing chiJ in cOT sender round 2 transfer")
+		}
+		if _, err := hash.Write(digest); err != nil {
+			return nil, errors.Wrap(err, "writing input digest into hash while computing chiJ in cOT sender round 2 transfer")
+		}
+		chiJ := hash.Sum(nil)
+		zetaJTimesChiJ := binaryFieldMul(zeta[j][:], chiJ)
+		for k := 0; k < KappaBytes; k++ {
+			zPrime[k] ^= zetaJTimesChiJ[k]
+		}
+	}
+	rhs := [simplest.DigestSize]byte{}
+	nablaTimesWPrime := binaryFieldMul(sender.seedOtResults.PackedRandomChoiceBits, round1Output.WPrime[:])
+	for i := 0; i < KappaBytes; i++ {
+		rhs[i] = round1Output.VPrime[i] ^ nablaTimesWPrime[i]
+	}
+	if subtle.ConstantTimeCompare(zPrime[:], rhs[:]) != 1 {
+		return nil, fmt.Errorf("cOT receiver's consistency check failed; this may be an attempted attack; do NOT re-run the protocol")
+	}
+	return &zeta, nil
+}
+
 // Round3Transfer does the receiver (Bob)'s step 7) of Protocol 9, namely the computation of the outputs tB.
 func (receiver *Receiver) Round3Transfer(round2Output *Round2Output) error {
 	for j := 0; j < L; j++ {
@@ -139,3 +139,83 @@ func TestCOTExtensionStreaming(t *testing.T) {
 		}
 	}
 }
+
+func setupCOT(t *testing.T, curve *curves.Curve) (*Sender, *Receiver, [simplest.DigestSize]byte, [COtBlockSizeBytes]byte, *Round1Output) {
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, Kappa, uniqueSessionId)
+	require.NoError(t, err)
+	sender := NewCOtSender(baseOtReceiverOutput, curve)
+	receiver := NewCOtReceiver(baseOtSenderOutput, curve)
+	choice := [COtBlockSizeBytes]byte{}
+	_, err = rand.Read(choice[:])
+	require.NoError(t, err)
+	firstMessage, err := receiver.Round1Initialize(uniqueSessionId, choice)
+	require.NoError(t, err)
+	return sender, receiver, uniqueSessionId, choice, firstMessage
+}
+
+func TestCorrelatedOT(t *testing.T) {
+	curve := curves.K256()
+	sender, receiver, uniqueSessionId, choice, firstMessage := setupCOT(t, curve)
+	senderOutput, err := sender.Round2Correlated(uniqueSessionId, firstMessage)
+	require.NoError(t, err)
+	receiverOutput := receiver.CorrelatedOutput()
+	require.NotEqual(t, [KappaBytes]byte{}, senderOutput.Delta)
+	for j := 0; j < L; j++ {
+		expected := senderOutput.Rows[j]
+		if simplest.ExtractBitFromByteVector(choice[:], j) == 1 {
+			for i := range expected {
+				expected[i] ^= senderOutput.Delta[i]
+			}
+		}
+		require.Equal(t, expected, receiverOutput.Rows[j])
+	}
+
+	firstMessage.WPrime[0] ^= 0x01
+	_, err = sender.Round2Correlated(uniqueSessionId, firstMessage)
+	require.Error(t, err)
+}
+
+func TestRandomOT(t *testing.T) {
+	curve := curves.K256()
+	sender, receiver, uniqueSessionId, choice, firstMessage := setupCOT(t, curve)
+	senderOutput, err := sender.Round2Random(uniqueSessionId, firstMessage)
+	require.NoError(t, err)
+	receiverOutput, err := receiver.RandomOutput()
+	require.NoError(t, err)
+	require.Len(t, senderOutput, L)
+	require.Len(t, receiverOutput, L)
+	for j := 0; j < L; j++ {
+		bit := simplest.ExtractBitFromByteVector(choice[:], j)
+		require.Equal(t, senderOutput[j][bit], receiverOutput[j])
+		require.NotEqual(t, senderOutput[j][1-bit], receiverOutput[j])
+	}
+
+	firstMessage.VPrime[0] ^= 0x01
+	_, err = sender.Round2Random(uniqueSessionId, firstMessage)
+	require.Error(t, err)
+}
+
+func TestCOTExtensionTranscriptBinding(t *testing.T) {
+	curve := curves.K256()
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, Kappa, uniqueSessionId)
+	require.NoError(t, err)
+
+	// the same base OT keys, claimed to come from another base OT instance, fail the consistency check
+	otherBaseOtReceiverOutput := *baseOtReceiverOutput
+	otherBaseOtReceiverOutput.Transcript[0] ^= 0x01
+	receiver := NewCOtReceiver(baseOtSenderOutput, curve)
+	firstMessage, err := receiver.Round1Initialize(uniqueSessionId, [COtBlockSizeBytes]byte{})
+	require.NoError(t, err)
+	_, err = NewCOtSender(&otherBaseOtReceiverOutput, curve).Round2Transfer(uniqueSessionId, [L][OtWidth]curves.Scalar{}, firstMessage)
+	require.Error(t, err)
+
+	sender := NewCOtSender(baseOtReceiverOutput, curve)
+	_, err = sender.Round2Correlated(uniqueSessionId, firstMessage)
+	require.NoError(t, err)
+}
@@ -0,0 +1,102 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package kos
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+)
+
+// The cOT of Round2Transfer transfers the sender's chosen scalars with a second message. Protocols which only need
+// correlated or random OTs can stop after the receiver's first message instead:
+//
+//   - correlated OT (COT): the sender gets a global `Delta` and a row Q_j per OT, and the receiver gets the rows
+//     T_j = Q_j ^ w_j * Delta, where w_j is its choice bit.
+//   - random OT (ROT): the sender gets two random messages per OT, and the receiver gets the one selected by w_j.
+//
+// In both modes the receiver calls Round1Initialize as usual and its outputs are available right away; the sender
+// verifies the first message with Round2Correlated or Round2Random, and no message is sent back.
+
+// CorrelatedOutput is a party's output of correlated OT. `Rows` holds Q_j for the sender and T_j for the receiver.
+// `Delta` is the sender's global correlation, and is left zero for the receiver.
+type CorrelatedOutput struct {
+	Delta [KappaBytes]byte
+	Rows  [L][KappaBytes]byte
+}
+
+// Round2Correlated verifies the receiver's first message and returns the sender's output of correlated OT.
+func (sender *Sender) Round2Correlated(uniqueSessionId [simplest.DigestSize]byte, round1Output *Round1Output) (*CorrelatedOutput, error) {
+	zeta, err := sender.verifyRound1(uniqueSessionId, round1Output)
+	if err != nil {
+		return nil, err
+	}
+	result := &CorrelatedOutput{}
+	copy(result.Delta[:], sender.seedOtResults.PackedRandomChoiceBits)
+	copy(result.Rows[:], zeta[:L])
+	return result, nil
+}
+
+// CorrelatedOutput returns the receiver's output of correlated OT. It must be called after Round1Initialize.
+func (receiver *Receiver) CorrelatedOutput() *CorrelatedOutput {
+	result := &CorrelatedOutput{}
+	copy(result.Rows[:], receiver.psi[:L])
+	return result
+}
+
+// Round2Random verifies the receiver's first message and returns the sender's two random messages for each OT.
+func (sender *Sender) Round2Random(uniqueSessionId [simplest.DigestSize]byte, round1Output *Round1Output) ([][2][simplest.DigestSize]byte, error) {
+	zeta, err := sender.verifyRound1(uniqueSessionId, round1Output)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][2][simplest.DigestSize]byte, L)
+	for j := 0; j < L; j++ {
+		if err = hashRandomOT(uniqueSessionId, j, zeta[j][:], &result[j][0]); err != nil {
+			return nil, errors.Wrap(err, "computing first message in ROT sender round 2")
+		}
+		for i := 0; i < KappaBytes; i++ {
+			zeta[j][i] ^= sender.seedOtResults.PackedRandomChoiceBits[i]
+		}
+		if err = hashRandomOT(uniqueSessionId, j, zeta[j][:], &result[j][1]); err != nil {
+			return nil, errors.Wrap(err, "computing second message in ROT sender round 2")
+		}
+	}
+	return result, nil
+}
+
+// RandomOutput returns the receiver's message of each random OT, which is the sender's message selected by the
+// corresponding choice bit. It must be called after Round1Initialize.
+func (receiver *Receiver) RandomOutput() ([][simplest.DigestSize]byte, error) {
+	result := make([][simplest.DigestSize]byte, L)
+	for j := 0; j < L; j++ {
+		if err := hashRandomOT(receiver.uniqueSessionId, j, receiver.psi[j][:], &result[j]); err != nil {
+			return nil, errors.Wrap(err, "computing message in ROT receiver")
+		}
+	}
+	return result, nil
+}
+
+// hashRandomOT breaks the correlation of the row of OT j, domain separated from the hash of the cOT.
+func hashRandomOT(uniqueSessionId [simplest.DigestSize]byte, j int, row []byte, out *[simplest.DigestSize]byte) error {
+	shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_KOS_ROT"))
+	jBytes := [2]byte{}
+	binary.BigEndian.PutUint16(jBytes[:], uint16(j))
+	if _, err := shake.Write(jBytes[:]); err != nil {
+		return errors.Wrap(err, "writing nonce into shake")
+	}
+	if _, err := shake.Write(row); err != nil {
+		return errors.Wrap(err, "writing row into shake")
+	}
+	if _, err := shake.Read(out[:]); err != nil {
+		return errors.Wrap(err, "reading shake into message")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package kos
+
+// transposeGeneric is the pure Go transpose, see transposeRows.
+func transposeGeneric(input *[Kappa][cOtExtendedBlockSizeBytes]byte, output *[lPrime][KappaBytes]byte) {
+	rows := [Kappa][]byte{}
+	for i := range rows {
+		rows[i] = input[i][:]
+	}
+	transposeRows(&rows, output[:])
+}
+
+// transposeRows transposes Kappa rows of any common length into the 8 * length rows of output. Rather than moving
+// the matrix one bit at a time, it cuts it into 8x8 bit blocks: the 8 bytes of a block, one from each of 8 consecutive
+// input rows, are packed into a uint64, transposed in place with three rounds of delta swaps, and written out as one
+// byte of 8 consecutive output rows.
+func transposeRows(input *[Kappa][]byte, output [][KappaBytes]byte) {
+	for rowByte := 0; rowByte < KappaBytes; rowByte++ {
+		rows := input[rowByte<<3 : rowByte<<3+8]
+		for columnByte := range rows[0] {
+			// byte i of x is row i of the block, and bit j within that byte is column j.
+			x := uint64(rows[0][columnByte]) |
+				uint64(rows[1][columnByte])<<8 |
+				uint64(rows[2][columnByte])<<16 |
+				uint64(rows[3][columnByte])<<24 |
+				uint64(rows[4][columnByte])<<32 |
+				uint64(rows[5][columnByte])<<40 |
+				uint64(rows[6][columnByte])<<48 |
+				uint64(rows[7][columnByte])<<56
+			x = transpose8x8(x)
+			columns := output[columnByte<<3 : columnByte<<3+8]
+			for j := range columns {
+				columns[j][rowByte] = byte(x >> (j << 3))
+			}
+		}
+	}
+}
+
+// transpose8x8 transposes the 8x8 bit matrix whose bit 8*i+j is the entry (i, j), by swapping the off-diagonal 1x1,
+// 2x2 and 4x4 sub-blocks in turn.
+func transpose8x8(x uint64) uint64 {
+	t := (x ^ (x >> 7)) & 0x00AA00AA00AA00AA
+	x ^= t ^ (t << 7)
+	t = (x ^ (x >> 14)) & 0x0000CCCC0000CCCC
+	x ^= t ^ (t << 14)
+	t = (x ^ (x >> 28)) & 0x00000000F0F0F0F0
+	x ^= t ^ (t << 28)
+	return x
+}
@@ -0,0 +1,10 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+package kos
+
+// transpose sets output to the transpose of input, see transposeBooleanMatrix. It only uses SSE2, which every amd64
+// CPU has.
+//
+//go:noescape
+func transpose(input *[Kappa][cOtExtendedBlockSizeBytes]byte, output *[lPrime][KappaBytes]byte)
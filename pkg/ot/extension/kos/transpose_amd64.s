@@ -0,0 +1,154 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+#include "textflag.h"
+
+// The matrix is transposed in blocks of 16 input rows by 8 input bytes, i.e. 64 output rows by 2 output bytes.
+// The 16 rows of a block are loaded into X0-X15 and transposed as a byte matrix with the unpack instructions, which
+// leaves the 16 bytes of each input column byte in one register. PMOVMSKB then gathers the top bit of these 16
+// bytes, which is one output row of the block, and shifting each byte left by one brings the next bit to the top.
+//
+// SI = first byte of the block in the input, DI = first byte of the block in the output,
+// R8 = input, R9 = output, R10 = output byte of the block, R11 = input row of the block, R12 = input byte of the block
+
+// EMIT writes the 8 output rows of the input byte held in X, whose offset in the block is off. Input bit 7 is the
+// last of the 8 rows, and the rows are KappaBytes = 32 bytes long.
+#define EMIT(X, off) \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+224(DI); \
+	PSLLQ    $1, X; \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+192(DI); \
+	PSLLQ    $1, X; \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+160(DI); \
+	PSLLQ    $1, X; \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+128(DI); \
+	PSLLQ    $1, X; \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+96(DI); \
+	PSLLQ    $1, X; \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+64(DI); \
+	PSLLQ    $1, X; \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+32(DI); \
+	PSLLQ    $1, X; \
+	PMOVMSKB X, AX; \
+	MOVW     AX, off+0(DI)
+
+// func transpose(input *[Kappa][cOtExtendedBlockSizeBytes]byte, output *[lPrime][KappaBytes]byte)
+TEXT ·transpose(SB), NOSPLIT, $0-16
+	MOVQ input+0(FP), R8
+	MOVQ output+8(FP), R9
+	XORQ R10, R10
+
+rowLoop:
+	// the block starts at input row 8 * R10, and the rows are cOtExtendedBlockSizeBytes = 126 bytes long
+	IMUL3Q $1008, R10, R11
+	ADDQ   R8, R11
+	XORQ   R12, R12
+
+columnLoop:
+	LEAQ (R11)(R12*1), SI
+	MOVQ R12, DI
+	SHLQ $8, DI
+	ADDQ R9, DI
+	ADDQ R10, DI
+
+	MOVQ 0(SI), X0
+	MOVQ 126(SI), X1
+	MOVQ 252(SI), X2
+	MOVQ 378(SI), X3
+	MOVQ 504(SI), X4
+	MOVQ 630(SI), X5
+	MOVQ 756(SI), X6
+	MOVQ 882(SI), X7
+	MOVQ 1008(SI), X8
+	MOVQ 1134(SI), X9
+	MOVQ 1260(SI), X10
+	MOVQ 1386(SI), X11
+	MOVQ 1512(SI), X12
+	MOVQ 1638(SI), X13
+	MOVQ 1764(SI), X14
+	MOVQ 1890(SI), X15
+
+	// interleave the bytes of rows 2k and 2k+1 into X(2k)
+	PUNPCKLBW X1, X0
+	PUNPCKLBW X3, X2
+	PUNPCKLBW X5, X4
+	PUNPCKLBW X7, X6
+	PUNPCKLBW X9, X8
+	PUNPCKLBW X11, X10
+	PUNPCKLBW X13, X12
+	PUNPCKLBW X15, X14
+
+	// interleave the words of row pairs into 4 rows at a time: bytes 0-3 in X(4m), bytes 4-7 in X(4m+1)
+	MOVO      X0, X1
+	PUNPCKLWL X2, X0
+	PUNPCKHWL X2, X1
+	MOVO      X4, X5
+	PUNPCKLWL X6, X4
+	PUNPCKHWL X6, X5
+	MOVO      X8, X9
+	PUNPCKLWL X10, X8
+	PUNPCKHWL X10, X9
+	MOVO      X12, X13
+	PUNPCKLWL X14, X12
+	PUNPCKHWL X14, X13
+
+	// interleave the double words into 8 rows at a time, two bytes per register
+	// rows 0-7: bytes 0-1 in X0, 2-3 in X2, 4-5 in X1, 6-7 in X3; rows 8-15 likewise in X8, X10, X9, X11
+	MOVO      X0, X2
+	PUNPCKLLQ X4, X0
+	PUNPCKHLQ X4, X2
+	MOVO      X1, X3
+	PUNPCKLLQ X5, X1
+	PUNPCKHLQ X5, X3
+	MOVO      X8, X10
+	PUNPCKLLQ X12, X8
+	PUNPCKHLQ X12, X10
+	MOVO      X9, X11
+	PUNPCKLLQ X13, X9
+	PUNPCKHLQ X13, X11
+
+	// interleave the quad words into all 16 rows of one byte per register
+	// bytes 0-7 end up in X0, X4, X2, X5, X1, X6, X3, X7
+	MOVO       X0, X4
+	PUNPCKLQDQ X8, X0
+	PUNPCKHQDQ X8, X4
+	MOVO       X2, X5
+	PUNPCKLQDQ X10, X2
+	PUNPCKHQDQ X10, X5
+	MOVO       X1, X6
+	PUNPCKLQDQ X9, X1
+	PUNPCKHQDQ X9, X6
+	MOVO       X3, X7
+	PUNPCKLQDQ X11, X3
+	PUNPCKHQDQ X11, X7
+
+	EMIT(X0, 0)
+	EMIT(X4, 256)
+	EMIT(X2, 512)
+	EMIT(X5, 768)
+	EMIT(X1, 1024)
+	EMIT(X6, 1280)
+	EMIT(X3, 1536)
+	EMIT(X7, 1792)
+
+	// 126 is not a multiple of 8, so the last block starts at byte 118 and redoes bytes 118 and 119, rather than
+	// reading past the end of the rows
+	CMPQ R12, $118
+	JAE  nextRow
+	ADDQ $8, R12
+	CMPQ R12, $118
+	JBE  columnLoop
+	MOVQ $118, R12
+	JMP  columnLoop
+
+nextRow:
+	ADDQ $2, R10
+	CMPQ R10, $32
+	JB   rowLoop
+	RET
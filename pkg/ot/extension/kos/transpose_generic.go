@@ -0,0 +1,9 @@
+//go:build !amd64 || purego
+// +build !amd64 purego
+
+package kos
+
+// transpose sets output to the transpose of input, see transposeBooleanMatrix.
+func transpose(input *[Kappa][cOtExtendedBlockSizeBytes]byte, output *[lPrime][KappaBytes]byte) {
+	transposeGeneric(input, output)
+}
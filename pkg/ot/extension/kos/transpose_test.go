@@ -0,0 +1,93 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package kos
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+)
+
+// transposeBitByBit is the straightforward transpose, which moves one bit at a time. It is the reference for the tests
+// and the baseline for the benchmarks.
+func transposeBitByBit(input *[Kappa][cOtExtendedBlockSizeBytes]byte, output *[lPrime][KappaBytes]byte) {
+	*output = [lPrime][KappaBytes]byte{}
+	for rowBit := 0; rowBit < Kappa; rowBit++ {
+		for columnBit := 0; columnBit < lPrime; columnBit++ {
+			bit := simplest.ExtractBitFromByteVector(input[rowBit][:], columnBit)
+			output[columnBit][rowBit>>3] |= bit << (rowBit & 0x07)
+		}
+	}
+}
+
+func randomMatrix(t testing.TB) *[Kappa][cOtExtendedBlockSizeBytes]byte {
+	input := &[Kappa][cOtExtendedBlockSizeBytes]byte{}
+	for i := range input {
+		_, err := rand.Read(input[i][:])
+		require.NoError(t, err)
+	}
+	return input
+}
+
+func TestTranspose(t *testing.T) {
+	inputs := []*[Kappa][cOtExtendedBlockSizeBytes]byte{randomMatrix(t), randomMatrix(t)}
+	// a single bit set in every row, at a different column each time, catches bits landing in the wrong place
+	diagonal := &[Kappa][cOtExtendedBlockSizeBytes]byte{}
+	for i := range diagonal {
+		j := (i * 5) % lPrime
+		diagonal[i][j>>3] = 1 << (j & 0x07)
+	}
+	inputs = append(inputs, diagonal, &[Kappa][cOtExtendedBlockSizeBytes]byte{})
+
+	for _, input := range inputs {
+		expected := [lPrime][KappaBytes]byte{}
+		transposeBitByBit(input, &expected)
+		for j := 0; j < lPrime; j++ {
+			for i := 0; i < Kappa; i++ {
+				require.Equal(t, simplest.ExtractBitFromByteVector(input[i][:], j), simplest.ExtractBitFromByteVector(expected[j][:], i))
+			}
+		}
+
+		require.Equal(t, expected, transposeBooleanMatrix(*input))
+		generic := [lPrime][KappaBytes]byte{}
+		transposeGeneric(input, &generic)
+		require.Equal(t, expected, generic)
+	}
+}
+
+func TestTranspose8x8(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		row, column := i>>3, i&0x07
+		require.Equal(t, uint64(1)<<(column<<3+row), transpose8x8(uint64(1)<<i))
+	}
+}
+
+func BenchmarkTranspose(b *testing.B) {
+	input := randomMatrix(b)
+	output := &[lPrime][KappaBytes]byte{}
+	b.Run("bit by bit", func(b *testing.B) {
+		b.SetBytes(int64(len(input) * len(input[0])))
+		for i := 0; i < b.N; i++ {
+			transposeBitByBit(input, output)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		b.SetBytes(int64(len(input) * len(input[0])))
+		for i := 0; i < b.N; i++ {
+			transposeGeneric(input, output)
+		}
+	})
+	b.Run("transpose", func(b *testing.B) {
+		b.SetBytes(int64(len(input) * len(input[0])))
+		for i := 0; i < b.N; i++ {
+			transpose(input, output)
+		}
+	})
+}
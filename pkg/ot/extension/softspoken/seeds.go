@@ -0,0 +1,194 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package softspoken
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+)
+
+// SetupMessage is sent once by the receiver to the sender after the base OTs. For each base OT,
+// it holds the XOR of the seeds of the left and right children at the corresponding tree level,
+// masked with the two one-time pads of that base OT.
+type SetupMessage struct {
+	Corrections [Kappa][2][simplest.DigestSize]byte
+}
+
+// ReceiverSeeds holds the receiver's leaves of the Kappa / k GGM trees. They are derived once from
+// the base OTs and can be reused by any number of cOT sessions, each with its own session id.
+type ReceiverSeeds struct {
+	k int
+	// leaves[c][x] is the seed of leaf x of tree c
+	leaves [][][simplest.DigestSize]byte
+	// transcript binds the base OT transcript and the setup message, see setupTranscript
+	transcript [simplest.DigestSize]byte
+}
+
+// SenderSeeds holds the sender's leaves, which are all the receiver's leaves except one per tree.
+type SenderSeeds struct {
+	k int
+	// leaves[c][x] is the seed of leaf x of tree c, and is zero for the punctured leaf
+	leaves [][][simplest.DigestSize]byte
+	// delta packs the indices of the punctured leaves, tree after tree with the most significant bit first.
+	// it plays the role of the base OT choice bits in KOS.
+	delta [KappaBytes]byte
+	// transcript binds the base OT transcript and the setup message, see setupTranscript
+	transcript [simplest.DigestSize]byte
+}
+
+// checkK verifies that the tradeoff parameter k splits the base OTs into whole trees.
+func checkK(k int) error {
+	if k < 1 || k > MaxK || Kappa%k != 0 {
+		return fmt.Errorf("k must divide %d and be between 1 and %d, got %d", Kappa, MaxK, k)
+	}
+	return nil
+}
+
+// setupTranscript digests the transcript of the base OTs together with the setup message. The consistency check of
+// every cOT session binds it, so that the seeds of one setup can't be passed off as those of another.
+func setupTranscript(seedOtTranscript [simplest.DigestSize]byte, setup *SetupMessage) [simplest.DigestSize]byte {
+	hash := sha3.New256()
+	_, _ = hash.Write([]byte("Coinbase_SoftSpoken_setup"))
+	_, _ = hash.Write(seedOtTranscript[:])
+	for i := range setup.Corrections {
+		for b := range setup.Corrections[i] {
+			_, _ = hash.Write(setup.Corrections[i][b][:])
+		}
+	}
+	result := [simplest.DigestSize]byte{}
+	copy(result[:], hash.Sum(nil))
+	return result
+}
+
+// expandNode computes the two children of a GGM tree node.
+func expandNode(node [simplest.DigestSize]byte) ([2][simplest.DigestSize]byte, error) {
+	children := [2][simplest.DigestSize]byte{}
+	shake := sha3.NewCShake256(nil, []byte("Coinbase_SoftSpoken_GGM"))
+	if _, err := shake.Write(node[:]); err != nil {
+		return children, errors.Wrap(err, "writing node into shake while expanding GGM tree")
+	}
+	if _, err := shake.Read(children[0][:]); err != nil {
+		return children, errors.Wrap(err, "reading left child from shake while expanding GGM tree")
+	}
+	if _, err := shake.Read(children[1][:]); err != nil {
+		return children, errors.Wrap(err, "reading right child from shake while expanding GGM tree")
+	}
+	return children, nil
+}
+
+// NewReceiverSeeds samples the receiver's GGM trees from the output of the base OTs in which it played the
+// _sender_ (note the reversal of roles, as in KOS). The returned `SetupMessage` must be delivered to the sender,
+// which passes it to `NewSenderSeeds`.
+func NewReceiverSeeds(seedOtResults *simplest.SenderOutput, k int) (*ReceiverSeeds, *SetupMessage, error) {
+	if err := checkK(k); err != nil {
+		return nil, nil, err
+	}
+	if len(seedOtResults.OneTimePadEncryptionKeys) != Kappa {
+		return nil, nil, fmt.Errorf("expected %d base OTs, got %d", Kappa, len(seedOtResults.OneTimePadEncryptionKeys))
+	}
+	seeds := &ReceiverSeeds{k: k, leaves: make([][][simplest.DigestSize]byte, Kappa/k)}
+	setup := &SetupMessage{}
+	for c := range seeds.leaves {
+		level := make([][simplest.DigestSize]byte, 1)
+		if _, err := rand.Read(level[0][:]); err != nil {
+			return nil, nil, errors.Wrap(err, "sampling GGM tree root")
+		}
+		for l := 0; l < k; l++ {
+			next := make([][simplest.DigestSize]byte, 2*len(level))
+			for n, node := range level {
+				children, err := expandNode(node)
+				if err != nil {
+					return nil, nil, err
+				}
+				next[2*n] = children[0]
+				next[2*n+1] = children[1]
+			}
+			i := c*k + l
+			for b := 0; b < 2; b++ {
+				setup.Corrections[i][b] = seedOtResults.OneTimePadEncryptionKeys[i][b]
+				for n := b; n < len(next); n += 2 {
+					xorInto(setup.Corrections[i][b][:], next[n][:])
+				}
+			}
+			level = next
+		}
+		seeds.leaves[c] = level
+	}
+	seeds.transcript = setupTranscript(seedOtResults.Transcript, setup)
+	return seeds, setup, nil
+}
+
+// NewSenderSeeds reconstructs all the receiver's leaves but one per tree from the output of the base OTs in which
+// the sender played the _receiver_, and the receiver's `SetupMessage`. In tree c, the punctured leaf is the
+// complement of the base OT choice bits c*k, ..., c*k + k - 1.
+func NewSenderSeeds(seedOtResults *simplest.ReceiverOutput, k int, setup *SetupMessage) (*SenderSeeds, error) {
+	if err := checkK(k); err != nil {
+		return nil, err
+	}
+	if len(seedOtResults.RandomChoiceBits) != Kappa || len(seedOtResults.OneTimePadDecryptionKey) != Kappa {
+		return nil, fmt.Errorf("expected %d base OTs, got %d", Kappa, len(seedOtResults.RandomChoiceBits))
+	}
+	seeds := &SenderSeeds{k: k, leaves: make([][][simplest.DigestSize]byte, Kappa/k)}
+	for c := range seeds.leaves {
+		// the root is unknown, so start with an empty tree in which the punctured path is at index 0
+		level := make([][simplest.DigestSize]byte, 1)
+		path := 0
+		for l := 0; l < k; l++ {
+			i := c*k + l
+			choice := seedOtResults.RandomChoiceBits[i]
+			next := make([][simplest.DigestSize]byte, 2*len(level))
+			for n, node := range level {
+				if n == path {
+					continue
+				}
+				children, err := expandNode(node)
+				if err != nil {
+					return nil, err
+				}
+				next[2*n] = children[0]
+				next[2*n+1] = children[1]
+			}
+			// the pad of the base OT opens the sum on the `choice` side, which is the sibling of the punctured path.
+			sibling := 2*path + choice
+			next[sibling] = setup.Corrections[i][choice]
+			xorInto(next[sibling][:], seedOtResults.OneTimePadDecryptionKey[i][:])
+			for n := choice; n < len(next); n += 2 {
+				if n != sibling {
+					xorInto(next[sibling][:], next[n][:])
+				}
+			}
+			path = 2*path + 1 - choice
+			if choice == 0 {
+				seeds.delta[i>>3] |= 1 << (i & 0x07)
+			}
+			level = next
+		}
+		seeds.leaves[c] = level
+	}
+	seeds.transcript = setupTranscript(seedOtResults.Transcript, setup)
+	return seeds, nil
+}
+
+// punctured returns the index of the leaf of tree c which the sender does not know.
+func (seeds *SenderSeeds) punctured(c int) int {
+	x := 0
+	for l := 0; l < seeds.k; l++ {
+		x = x<<1 | int(simplest.ExtractBitFromByteVector(seeds.delta[:], c*seeds.k+l))
+	}
+	return x
+}
+
+func xorInto(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
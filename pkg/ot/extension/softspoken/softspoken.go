@@ -0,0 +1,397 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package softspoken is an implementation of the SoftSpokenOT extension protocol of
+// [Roy22](https://eprint.iacr.org/2022/192.pdf), as an alternative to KOS with less communication.
+//
+// KOS turns each of its Kappa base OTs into one row of the matrix the receiver sends. SoftSpokenOT groups the
+// base OTs into Kappa / k GGM trees with 2^k leaves, of which the sender learns all but one (a (2^k - 1)-out-of-2^k
+// OT), and derives k rows from each tree with a small-field VOLE. The receiver then sends one row per tree, so the
+// first message is k times smaller, at the cost of expanding 2^k seeds per tree instead of 2. k = 1 is KOS; k = 4
+// or 5 are typical choices.
+//
+// The consistency check, the transfer of the sender's inputs and the outputs are the ones of KOS (Protocol 9 of
+// [DKLs18](https://eprint.iacr.org/2018/499.pdf)), and the parameters `Kappa`, `L` and `OtWidth` are shared with
+// package kos, so the outputs can be used wherever the KOS outputs are, e.g. in DKLs multiplication.
+//
+// The GGM trees are derived once from the base OTs with `NewReceiverSeeds` and `NewSenderSeeds`, which costs one
+// extra message from the receiver, and they are then reused by every cOT session like the base OT seeds are in KOS.
+package softspoken
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+)
+
+const (
+	// Kappa is the computational security parameter, and the number of base OTs.
+	Kappa = kos.Kappa
+
+	// KappaBytes is same as Kappa // 8, but avoids cpu division.
+	KappaBytes = kos.KappaBytes
+
+	// L is the batch size used in the cOT functionality.
+	L = kos.L
+
+	// COtBlockSizeBytes is same as L // 8, but avoids cpu division.
+	COtBlockSizeBytes = kos.COtBlockSizeBytes
+
+	// OtWidth is the number of scalars processed per "slot" of the cOT, see kos.OtWidth.
+	OtWidth = kos.OtWidth
+
+	// MaxK is the largest supported value of the tradeoff parameter k.
+	// each tree has 2^k leaves, so computation grows exponentially with k.
+	MaxK = 8
+
+	s                         = 80 // statistical security parameter.
+	kappaOT                   = Kappa + s
+	lPrime                    = L + kappaOT // length of pseudorandom seed expansion, used within cOT protocol
+	cOtExtendedBlockSizeBytes = lPrime >> 3
+)
+
+type Receiver struct {
+	// OutputAdditiveShares are the ultimate output received. basically just the "pads".
+	OutputAdditiveShares [L][OtWidth]curves.Scalar
+
+	seeds *ReceiverSeeds
+
+	// extendedPackedChoices is storage for "choice vector || gamma^{ext}" in a packed format.
+	extendedPackedChoices [cOtExtendedBlockSizeBytes]byte
+	psi                   [lPrime][KappaBytes]byte // transpose of v. gets retained between messages
+
+	curve           *curves.Curve
+	uniqueSessionId [simplest.DigestSize]byte // store this between rounds
+}
+
+type Sender struct {
+	// OutputAdditiveShares are the ultimate output received. basically just the "pads".
+	OutputAdditiveShares [L][OtWidth]curves.Scalar
+
+	seeds *SenderSeeds
+
+	curve *curves.Curve
+}
+
+// NewCOtReceiver creates a `Receiver` instance, ready for use as the receiver in the SoftSpokenOT cOT protocol.
+func NewCOtReceiver(seeds *ReceiverSeeds, curve *curves.Curve) *Receiver {
+	return &Receiver{
+		seeds: seeds,
+		curve: curve,
+	}
+}
+
+// NewCOtSender creates a `Sender` instance, ready for use as the sender in the SoftSpokenOT cOT protocol.
+func NewCOtSender(seeds *SenderSeeds, curve *curves.Curve) *Sender {
+	return &Sender{
+		seeds: seeds,
+		curve: curve,
+	}
+}
+
+// Round1Output is Bob's first message to Alice during cOT extension. `U` holds one row per GGM tree, i.e. Kappa / k
+// rows, which is where the savings over KOS come from. `WPrime` and `VPrime` are the values of the KOS consistency check.
+type Round1Output struct {
+	U      [][cOtExtendedBlockSizeBytes]byte
+	WPrime [simplest.DigestSize]byte
+	VPrime [simplest.DigestSize]byte
+}
+
+// Round2Output this is Alice's response to Bob in cOT extension.
+type Round2Output struct {
+	Tau [L][OtWidth]curves.Scalar
+}
+
+// expandLeaf computes the pseudorandom row of a leaf for the session.
+func expandLeaf(uniqueSessionId [simplest.DigestSize]byte, leaf [simplest.DigestSize]byte, row []byte) error {
+	shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_SoftSpoken_cOT"))
+	if _, err := shake.Write(leaf[:]); err != nil {
+		return errors.Wrap(err, "writing leaf seed into shake")
+	}
+	if _, err := shake.Read(row); err != nil {
+		return errors.Wrap(err, "reading leaf row from shake")
+	}
+	return nil
+}
+
+// newChallengeHash starts the hash of the matrix U, from which the coefficients of the consistency check are derived,
+// with the session id and the transcript of the seeds, as in KOS.
+func newChallengeHash(uniqueSessionId, seedsTranscript [simplest.DigestSize]byte) (hash.Hash, error) {
+	result := sha3.New256()
+	if _, err := result.Write(uniqueSessionId[:]); err != nil {
+		return nil, errors.Wrap(err, "writing session id into hash")
+	}
+	if _, err := result.Write(seedsTranscript[:]); err != nil {
+		return nil, errors.Wrap(err, "writing seeds transcript into hash")
+	}
+	return result, nil
+}
+
+// chi computes the j^th coefficient of the consistency check from the digest of the matrix U.
+func chi(j int, digest []byte) ([]byte, error) {
+	hash := sha3.New256()
+	jBytes := [2]byte{}
+	binary.BigEndian.PutUint16(jBytes[:], uint16(j))
+	if _, err := hash.Write(jBytes[:]); err != nil {
+		return nil, errors.Wrap(err, "writing nonce into hash while computing chiJ")
+	}
+	if _, err := hash.Write(digest); err != nil {
+		return nil, errors.Wrap(err, "writing input digest into hash while computing chiJ")
+	}
+	return hash.Sum(nil), nil
+}
+
+// Round1Initialize initializes the OT Extension. The receiver expands all the leaves of every tree into the small-field
+// VOLE: for tree c, u is the sum of the leaf rows, and row j of v is the sum of the rows of the leaves whose index has
+// bit j set. It sends u masked with the extended choice vector, and the KOS consistency check values on v.
+// The input `choice` vector is "packed" (i.e., the underlying abstract vector of `L` bits is represented as a `cOTBlockSizeBytes` bytes).
+func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]byte, choice [COtBlockSizeBytes]byte) (*Round1Output, error) {
+	// salt the transcript with the OT-extension session ID
+	receiver.uniqueSessionId = uniqueSessionId
+
+	copy(receiver.extendedPackedChoices[0:COtBlockSizeBytes], choice[:])
+	// Fill the rest of the extended choice vector with random values. These random values correspond to `gamma^{ext}`.
+	if _, err := rand.Read(receiver.extendedPackedChoices[COtBlockSizeBytes:]); err != nil {
+		return nil, errors.Wrap(err, "sampling random coins for gamma^{ext}")
+	}
+
+	k := receiver.seeds.k
+	v := [Kappa][cOtExtendedBlockSizeBytes]byte{}
+	result := &Round1Output{U: make([][cOtExtendedBlockSizeBytes]byte, Kappa/k)}
+	// basically this will contain a hash of the matrix U, bound to this session and to the seeds.
+	hash, err := newChallengeHash(uniqueSessionId, receiver.seeds.transcript)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding session in cOT receiver round 1")
+	}
+	row := [cOtExtendedBlockSizeBytes]byte{}
+	for c, leaves := range receiver.seeds.leaves {
+		u := &result.U[c]
+		for x, leaf := range leaves {
+			if err := expandLeaf(uniqueSessionId, leaf, row[:]); err != nil {
+				return nil, errors.Wrap(err, "expanding leaf in cOT receiver round 1")
+			}
+			xorInto(u[:], row[:])
+			for j := 0; j < k; j++ {
+				// bit j of x, counting from the most significant, as in the tree levels
+				mask := -byte(x >> (k - 1 - j) & 0x01)
+				for b := range row {
+					v[c*k+j][b] ^= mask & row[b]
+				}
+			}
+		}
+		xorInto(u[:], receiver.extendedPackedChoices[:])
+		if _, err := hash.Write(u[:]); err != nil {
+			return nil, err
+		}
+	}
+	receiver.psi = transposeBooleanMatrix(v)
+	digest := hash.Sum(nil) // go ahead and record this, so that we only have to hash the big matrix U once.
+	for j := 0; j < lPrime; j++ {
+		chiJ, err := chi(j, digest)
+		if err != nil {
+			return nil, errors.Wrap(err, "computing chiJ in cOT receiver round 1")
+		}
+		wJ := convertBitToBitmask(simplest.ExtractBitFromByteVector(receiver.extendedPackedChoices[:], j))
+		psiJTimesChiJ := binaryFieldMul(receiver.psi[j][:], chiJ)
+		for b := 0; b < KappaBytes; b++ {
+			result.WPrime[b] ^= wJ & chiJ[b]
+			result.VPrime[b] ^= psiJTimesChiJ[b]
+		}
+	}
+	return result, nil
+}
+
+// Round2Transfer computes the OT sender ("Alice")'s part of cOT. For tree c with punctured leaf p, row j of her VOLE
+// output is the sum of the rows of the leaves x with bit j of x different from bit j of p, which equals v_j + p_j * u.
+// Adding p_j times Bob's masked u gives v_j + p_j * w, the same correlation as in KOS with p as the base OT choices.
+// `input` is the sender's main vector of inputs alpha_j; these are the things tA_j and tB_j will add to if w_j == 1.
+// as a side effect of this function, our (i.e., the sender's) outputs tA_j from the cOT will be populated.
+func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte, input [L][OtWidth]curves.Scalar, round1Output *Round1Output) (*Round2Output, error) {
+	k := sender.seeds.k
+	if len(round1Output.U) != Kappa/k {
+		return nil, fmt.Errorf("expected %d rows in U, got %d", Kappa/k, len(round1Output.U))
+	}
+	z := [Kappa][cOtExtendedBlockSizeBytes]byte{}
+	// basically this will contain a hash of the matrix U, bound to this session and to the seeds.
+	hash, err := newChallengeHash(uniqueSessionId, sender.seeds.transcript)
+	if err != nil {
+		return nil, errors.Wrap(err, "binding session in cOT sender round 2 transfer")
+	}
+	row := [cOtExtendedBlockSizeBytes]byte{}
+	for c, leaves := range sender.seeds.leaves {
+		p := sender.seeds.punctured(c)
+		for x, leaf := range leaves {
+			if x == p {
+				continue
+			}
+			if err := expandLeaf(uniqueSessionId, leaf, row[:]); err != nil {
+				return nil, errors.Wrap(err, "expanding leaf in cOT sender round 2 transfer")
+			}
+			for j := 0; j < k; j++ {
+				mask := -byte((x ^ p) >> (k - 1 - j) & 0x01)
+				for b := range row {
+					z[c*k+j][b] ^= mask & row[b]
+				}
+			}
+		}
+		for j := 0; j < k; j++ {
+			mask := -byte(p >> (k - 1 - j) & 0x01)
+			for b := range row {
+				z[c*k+j][b] ^= mask & round1Output.U[c][b]
+			}
+		}
+		if _, err := hash.Write(round1Output.U[c][:]); err != nil {
+			return nil, errors.Wrap(err, "writing matrix U to hash in cOT sender round 2 transfer")
+		}
+	}
+	zeta := transposeBooleanMatrix(z)
+	digest := hash.Sum(nil) // go ahead and record this, so that we only have to hash the big matrix U once.
+	zPrime := [simplest.DigestSize]byte{}
+	for j := 0; j < lPrime; j++ {
+		chiJ, err := chi(j, digest)
+		if err != nil {
+			return nil, errors.Wrap(err, "computing chiJ in cOT sender round 2 transfer")
+		}
+		zetaJTimesChiJ := binaryFieldMul(zeta[j][:], chiJ)
+		for b := 0; b < KappaBytes; b++ {
+			zPrime[b] ^= zetaJTimesChiJ[b]
+		}
+	}
+	rhs := [simplest.DigestSize]byte{}
+	deltaTimesWPrime := binaryFieldMul(sender.seeds.delta[:], round1Output.WPrime[:])
+	for i := 0; i < KappaBytes; i++ {
+		rhs[i] = round1Output.VPrime[i] ^ deltaTimesWPrime[i]
+	}
+	if subtle.ConstantTimeCompare(zPrime[:], rhs[:]) != 1 {
+		return nil, fmt.Errorf("cOT receiver's consistency check failed; this may be an attempted attack; do NOT re-run the protocol")
+	}
+	result := &Round2Output{}
+	for j := 0; j < L; j++ {
+		var err error
+		if err = hashToScalars(sender.curve, uniqueSessionId, j, zeta[j][:], &sender.OutputAdditiveShares[j]); err != nil {
+			return nil, errors.Wrap(err, "computing OutputAdditiveShares in cOT sender round 2 transfer")
+		}
+		for i := 0; i < KappaBytes; i++ {
+			zeta[j][i] ^= sender.seeds.delta[i] // note: overwrites zeta_j. just using it as a place to store
+		}
+		if err = hashToScalars(sender.curve, uniqueSessionId, j, zeta[j][:], &result.Tau[j]); err != nil {
+			return nil, errors.Wrap(err, "computing tau in cOT sender round 2 transfer")
+		}
+		for k := 0; k < OtWidth; k++ {
+			result.Tau[j][k] = result.Tau[j][k].Sub(sender.OutputAdditiveShares[j][k])
+			result.Tau[j][k] = result.Tau[j][k].Add(input[j][k])
+		}
+	}
+	return result, nil
+}
+
+// Round3Transfer does the receiver (Bob)'s computation of the outputs tB.
+func (receiver *Receiver) Round3Transfer(round2Output *Round2Output) error {
+	for j := 0; j < L; j++ {
+		if err := hashToScalars(receiver.curve, receiver.uniqueSessionId, j, receiver.psi[j][:], &receiver.OutputAdditiveShares[j]); err != nil {
+			return errors.Wrap(err, "computing tB in cOT receiver round 3 transfer")
+		}
+		bit := int(simplest.ExtractBitFromByteVector(receiver.extendedPackedChoices[:], j))
+		var err error
+		for k := 0; k < OtWidth; k++ {
+			receiver.OutputAdditiveShares[j][k] = receiver.OutputAdditiveShares[j][k].Neg()
+			wj0 := receiver.OutputAdditiveShares[j][k].Bytes()
+			wj1 := receiver.OutputAdditiveShares[j][k].Add(round2Output.Tau[j][k]).Bytes()
+			subtle.ConstantTimeCopy(bit, wj0, wj1)
+			if receiver.OutputAdditiveShares[j][k], err = receiver.curve.Scalar.SetBytes(wj0); err != nil {
+				return errors.Wrap(err, "scalar output additive shares from bytes")
+			}
+		}
+	}
+	return nil
+}
+
+// hashToScalars derives the `OtWidth` scalars of column j from the row `zeta`, exactly as KOS does.
+func hashToScalars(curve *curves.Curve, uniqueSessionId [simplest.DigestSize]byte, j int, zeta []byte, out *[OtWidth]curves.Scalar) error {
+	column := make([]byte, OtWidth*simplest.DigestSize)
+	shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
+	jBytes := [2]byte{}
+	binary.BigEndian.PutUint16(jBytes[:], uint16(j))
+	if _, err := shake.Write(jBytes[:]); err != nil { // write j into hash
+		return errors.Wrap(err, "writing nonce into shake")
+	}
+	if _, err := shake.Write(zeta); err != nil {
+		return errors.Wrap(err, "writing row into shake")
+	}
+	if _, err := shake.Read(column); err != nil {
+		return errors.Wrap(err, "reading shake into column")
+	}
+	var err error
+	for k := 0; k < OtWidth; k++ {
+		if out[k], err = curve.Scalar.SetBytes(column[k*simplest.DigestSize : (k+1)*simplest.DigestSize]); err != nil {
+			return errors.Wrap(err, "scalar from bytes")
+		}
+	}
+	return nil
+}
+
+// convertBitToBitmask converts a "bit"---i.e., a `byte` which is _assumed to be_ either 0 or 1---into a bitmask,
+// namely, it outputs 0x00 if `bit == 0` and 0xFF if `bit == 1`.
+func convertBitToBitmask(bit byte) byte {
+	return ^(bit - 0x01)
+}
+
+// transposeBooleanMatrix transposes the `Kappa` by `lPrime` bit matrix whose rows are packed as bytes into the
+// `lPrime` by `Kappa` bit matrix, again with packed rows. bits are little-endian within bytes, as in KOS.
+func transposeBooleanMatrix(input [Kappa][cOtExtendedBlockSizeBytes]byte) [lPrime][KappaBytes]byte {
+	output := [lPrime][KappaBytes]byte{}
+	for row := 0; row < Kappa; row++ {
+		shift := row & 0x07
+		for column := 0; column < lPrime; column++ {
+			output[column][row>>3] |= (input[row][column>>3] >> (column & 0x07) & 0x01) << shift
+		}
+	}
+	return output
+}
+
+// binaryFieldMul multiplies `a` and `b` in the finite field of order 2^256, defined by the irreducible polynomial
+// f(X) = X^256 + X^10 + X^5 + X^2 + 1, in the same representation as KOS: 32 little-endian bytes holding the
+// coefficients of a polynomial of degree at most 255. the multiplication is constant-time in both inputs.
+func binaryFieldMul(a, b []byte) []byte {
+	var x, y [4]uint64
+	for i := 0; i < 4; i++ {
+		x[i] = binary.LittleEndian.Uint64(a[8*i:])
+		y[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	// schoolbook carry-less multiplication into 8 words, one bit of x at a time
+	var c [8]uint64
+	for i := 0; i < 256; i++ {
+		mask := -(x[i>>6] >> (i & 63) & 0x01)
+		word, shift := i>>6, uint(i&63)
+		for j := 0; j < 4; j++ {
+			lo := y[j] << shift
+			c[word+j] ^= mask & lo
+			if shift != 0 {
+				c[word+j+1] ^= mask & (y[j] >> (64 - shift))
+			}
+		}
+	}
+	// reduce, using X^256 = X^10 + X^5 + X^2 + 1
+	for i := 7; i >= 4; i-- {
+		t := c[i]
+		c[i-4] ^= t ^ t<<2 ^ t<<5 ^ t<<10
+		c[i-3] ^= t>>62 ^ t>>59 ^ t>>54
+	}
+	out := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(out[8*i:], c[i])
+	}
+	return out
+}
@@ -0,0 +1,231 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package softspoken
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+	"github.com/etclab/kryptology/pkg/ot/ottest"
+)
+
+func TestBinaryMult(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		temp := make([]byte, 32)
+		_, err := rand.Read(temp)
+		require.NoError(t, err)
+		// raising any element to the |F|th power yields the element itself
+		expected := make([]byte, 32)
+		copy(expected, temp)
+		for j := 0; j < 256; j++ {
+			expected = binaryFieldMul(expected, expected)
+		}
+		require.Equal(t, temp, expected)
+	}
+	// X^255 * X = X^256 = X^10 + X^5 + X^2 + 1
+	a := make([]byte, 32)
+	a[31] = 0x80
+	b := make([]byte, 32)
+	b[0] = 0x02
+	expected := make([]byte, 32)
+	expected[0] = 0x25
+	expected[1] = 0x04
+	require.Equal(t, expected, binaryFieldMul(a, b))
+}
+
+func TestTranspose(t *testing.T) {
+	input := [Kappa][cOtExtendedBlockSizeBytes]byte{}
+	for i := range input {
+		_, err := rand.Read(input[i][:])
+		require.NoError(t, err)
+	}
+	output := transposeBooleanMatrix(input)
+	for i := 0; i < Kappa; i++ {
+		for j := 0; j < lPrime; j++ {
+			require.Equal(t, simplest.ExtractBitFromByteVector(input[i][:], j), simplest.ExtractBitFromByteVector(output[j][:], i))
+		}
+	}
+}
+
+// setup runs the base OTs and derives the seeds for each k from them
+func setup(t *testing.T, curve *curves.Curve, ks ...int) ([]*ReceiverSeeds, []*SenderSeeds) {
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, Kappa, uniqueSessionId)
+	require.NoError(t, err)
+	receiverSeeds := make([]*ReceiverSeeds, len(ks))
+	senderSeeds := make([]*SenderSeeds, len(ks))
+	for i, k := range ks {
+		var setupMessage *SetupMessage
+		receiverSeeds[i], setupMessage, err = NewReceiverSeeds(baseOtSenderOutput, k)
+		require.NoError(t, err)
+		senderSeeds[i], err = NewSenderSeeds(baseOtReceiverOutput, k, setupMessage)
+		require.NoError(t, err)
+	}
+	return receiverSeeds, senderSeeds
+}
+
+func TestSeeds(t *testing.T) {
+	ks := []int{1, 2, 4, 8}
+	allReceiverSeeds, allSenderSeeds := setup(t, curves.K256(), ks...)
+	for i, k := range ks {
+		receiverSeeds, senderSeeds := allReceiverSeeds[i], allSenderSeeds[i]
+		require.Len(t, receiverSeeds.leaves, Kappa/k)
+		for c := range receiverSeeds.leaves {
+			require.Len(t, receiverSeeds.leaves[c], 1<<k)
+			p := senderSeeds.punctured(c)
+			for x := range receiverSeeds.leaves[c] {
+				if x == p {
+					require.NotEqual(t, receiverSeeds.leaves[c][x], senderSeeds.leaves[c][x])
+				} else {
+					require.Equal(t, receiverSeeds.leaves[c][x], senderSeeds.leaves[c][x])
+				}
+			}
+		}
+	}
+}
+
+func TestSeedsInvalidK(t *testing.T) {
+	for _, k := range []int{0, 3, 9, 16} {
+		_, _, err := NewReceiverSeeds(&simplest.SenderOutput{}, k)
+		require.Error(t, err)
+		_, err = NewSenderSeeds(&simplest.ReceiverOutput{}, k, &SetupMessage{})
+		require.Error(t, err)
+	}
+	_, _, err := NewReceiverSeeds(&simplest.SenderOutput{}, 4)
+	require.Error(t, err)
+}
+
+func runCOT(t *testing.T, curve *curves.Curve, receiverSeeds *ReceiverSeeds, senderSeeds *SenderSeeds) *Round1Output {
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+	sender := NewCOtSender(senderSeeds, curve)
+	receiver := NewCOtReceiver(receiverSeeds, curve)
+	choice := [COtBlockSizeBytes]byte{} // receiver's input, namely choice vector. just random
+	_, err = rand.Read(choice[:])
+	require.NoError(t, err)
+	input := [L][OtWidth]curves.Scalar{} // sender's input, namely integer "sums" in case w_j == 1.
+	for i := 0; i < L; i++ {
+		for j := 0; j < OtWidth; j++ {
+			input[i][j] = curve.Scalar.Random(rand.Reader)
+		}
+	}
+	firstMessage, err := receiver.Round1Initialize(uniqueSessionId, choice)
+	require.NoError(t, err)
+	responseTau, err := sender.Round2Transfer(uniqueSessionId, input, firstMessage)
+	require.NoError(t, err)
+	err = receiver.Round3Transfer(responseTau)
+	require.NoError(t, err)
+	for j := 0; j < L; j++ {
+		bit := simplest.ExtractBitFromByteVector(choice[:], j) == 1
+		for k := 0; k < OtWidth; k++ {
+			temp := sender.OutputAdditiveShares[j][k].Add(receiver.OutputAdditiveShares[j][k])
+			if bit {
+				require.Equal(t, temp, input[j][k])
+			} else {
+				require.Equal(t, temp, curve.Scalar.Zero())
+			}
+		}
+	}
+	return firstMessage
+}
+
+func TestCOTExtension(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		receiverSeeds, senderSeeds := setup(t, curve, 1, 2, 4, 8)
+		for i := range receiverSeeds {
+			// the seeds are reused across sessions
+			runCOT(t, curve, receiverSeeds[i], senderSeeds[i])
+			runCOT(t, curve, receiverSeeds[i], senderSeeds[i])
+		}
+	}
+}
+
+func TestCOTExtensionConsistencyCheck(t *testing.T) {
+	curve := curves.K256()
+	receiverSeeds, senderSeeds := setup(t, curve, 4)
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	receiver := NewCOtReceiver(receiverSeeds[0], curve)
+	firstMessage, err := receiver.Round1Initialize(uniqueSessionId, [COtBlockSizeBytes]byte{})
+	require.NoError(t, err)
+
+	// flipping a bit of U changes the choices the sender sees, but not the ones the check was computed with
+	for c := range firstMessage.U {
+		firstMessage.U[c][5] ^= 0x10
+	}
+	sender := NewCOtSender(senderSeeds[0], curve)
+	_, err = sender.Round2Transfer(uniqueSessionId, [L][OtWidth]curves.Scalar{}, firstMessage)
+	require.Error(t, err)
+
+	firstMessage.U = firstMessage.U[1:]
+	_, err = sender.Round2Transfer(uniqueSessionId, [L][OtWidth]curves.Scalar{}, firstMessage)
+	require.Error(t, err)
+}
+
+func TestCOTExtensionTranscriptBinding(t *testing.T) {
+	curve := curves.K256()
+	receiverSeeds, senderSeeds := setup(t, curve, 4)
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	receiver := NewCOtReceiver(receiverSeeds[0], curve)
+	firstMessage, err := receiver.Round1Initialize(uniqueSessionId, [COtBlockSizeBytes]byte{})
+	require.NoError(t, err)
+
+	otherSenderSeeds := *senderSeeds[0]
+	otherSenderSeeds.transcript[0] ^= 0x01
+	_, err = NewCOtSender(&otherSenderSeeds, curve).Round2Transfer(uniqueSessionId, [L][OtWidth]curves.Scalar{}, firstMessage)
+	require.Error(t, err)
+	_, err = NewCOtSender(senderSeeds[0], curve).Round2Transfer(uniqueSessionId, [L][OtWidth]curves.Scalar{}, firstMessage)
+	require.NoError(t, err)
+}
+
+func TestCommunication(t *testing.T) {
+	curve := curves.K256()
+	kosMessage := &kos.Round1Output{}
+	kosSize := len(kosMessage.U) * len(kosMessage.U[0])
+	ks := []int{1, 4, 8}
+	receiverSeeds, senderSeeds := setup(t, curve, ks...)
+	for i, k := range ks {
+		firstMessage := runCOT(t, curve, receiverSeeds[i], senderSeeds[i])
+		require.Equal(t, kosSize/k, len(firstMessage.U)*len(firstMessage.U[0]))
+	}
+}
+
+func BenchmarkCOTExtension(b *testing.B) {
+	curve := curves.K256()
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, Kappa, uniqueSessionId)
+	require.NoError(b, err)
+	input := [L][OtWidth]curves.Scalar{}
+	for i := 0; i < L; i++ {
+		for j := 0; j < OtWidth; j++ {
+			input[i][j] = curve.Scalar.Random(rand.Reader)
+		}
+	}
+	for _, k := range []int{1, 2, 4, 8} {
+		receiverSeeds, setupMessage, err := NewReceiverSeeds(baseOtSenderOutput, k)
+		require.NoError(b, err)
+		senderSeeds, err := NewSenderSeeds(baseOtReceiverOutput, k, setupMessage)
+		require.NoError(b, err)
+		b.Run(fmt.Sprintf("k=%d", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				receiver := NewCOtReceiver(receiverSeeds, curve)
+				sender := NewCOtSender(senderSeeds, curve)
+				firstMessage, _ := receiver.Round1Initialize(uniqueSessionId, [COtBlockSizeBytes]byte{})
+				responseTau, _ := sender.Round2Transfer(uniqueSessionId, input, firstMessage)
+				_ = receiver.Round3Transfer(responseTau)
+			}
+		})
+	}
+}
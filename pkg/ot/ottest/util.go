@@ -6,6 +6,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/endemic"
 	"github.com/etclab/kryptology/pkg/ot/base/simplest"
 )
 
@@ -48,3 +49,25 @@ func RunSimplestOT(curve *curves.Curve, batchSize int, uniqueSessionId [simplest
 	}
 	return sender.Output, receiver.Output, nil
 }
+
+// RunEndemicOT is the counterpart of RunSimplestOT for the endemic OT; its outputs can be used in place of the outputs of
+// RunSimplestOT.
+func RunEndemicOT(curve *curves.Curve, batchSize int, uniqueSessionId [simplest.DigestSize]byte) (*simplest.SenderOutput, *simplest.ReceiverOutput, error) {
+	receiver, err := endemic.NewReceiver(curve, batchSize, uniqueSessionId)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "constructing OT receiver in run endemic OT")
+	}
+	sender, err := endemic.NewSender(curve, batchSize, uniqueSessionId)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "constructing OT sender in run endemic OT")
+	}
+	senderPublicKey := sender.Round1ComputePublicKey()
+	encodedKeys, err := receiver.Round2EncodeChoices(senderPublicKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "receiver round 2 in run endemic OT")
+	}
+	if err = sender.Round3ComputeKeys(encodedKeys); err != nil {
+		return nil, nil, errors.Wrap(err, "sender round 3 in run endemic OT")
+	}
+	return sender.Output, receiver.Output, nil
+}
@@ -0,0 +1,249 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package vole implements random vector oblivious linear evaluation (VOLE) over the scalar field of a curve, on top of
+// the KOS OT extension. It supports the curves KOS supports, i.e. those whose group order is close to 2^256 such as
+// K256 and P256.
+//
+// At the end of the protocol, the sender holds random vectors U and V, and the receiver holds a random scalar Delta and
+// the vector W with
+//
+//	W[i] = U[i] * Delta + V[i]
+//
+// for every i. Delta is the receiver's choice vector of the cOT, in binary, and the sender's cOT inputs for the bit j
+// are 2^j * U[i]; adding up the additive shares of the cOT over the bits gives U[i] * Delta. Each cOT batch carries
+// as many copies of the bits of Delta as fit in its `kos.L` choice bits, and `kos.OtWidth` entries per copy, so a VOLE
+// of any length runs as many batches as needed in parallel.
+//
+// Each cOT is as secure as KOS, but the VOLE itself is only secure against semi-honest parties: it performs no
+// consistency check. A malicious sender can use cOT inputs other than 2^j * U[i], in which case W[i] is not of the form
+// U[i] * Delta + V[i] for any U[i], and the receiver's later behavior can leak bits of Delta. A malicious receiver can
+// use different bits of Delta in different batches or lanes. Callers facing malicious parties must check the
+// correlation themselves, e.g. with the consistency checks of QuickSilver.
+package vole
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+)
+
+// Sender holds U and V at the end of the protocol.
+type Sender struct {
+	// U and V are the sender's outputs, vectors of `size` random scalars.
+	U, V []curves.Scalar
+
+	seedOtResults *simplest.ReceiverOutput
+	curve         *curves.Curve
+	size          int
+}
+
+// Receiver holds Delta and W at the end of the protocol.
+type Receiver struct {
+	// Delta is the receiver's random scalar, sampled in Round1Initialize.
+	Delta curves.Scalar
+	// W is the receiver's output, W[i] = U[i] * Delta + V[i].
+	W []curves.Scalar
+
+	seedOtResults *simplest.SenderOutput
+	cOtReceivers  []*kos.Receiver
+	curve         *curves.Curve
+	size          int
+}
+
+// Round1Output is the receiver's first message: the first message of each cOT batch.
+type Round1Output struct {
+	COtRound1Outputs []*kos.Round1Output
+}
+
+// Round2Output is the sender's response: the response of each cOT batch.
+type Round2Output struct {
+	COtRound2Outputs []*kos.Round2Output
+}
+
+// bitLength is the number of bits of Delta, enough to write any scalar.
+func bitLength(curve *curves.Curve) int {
+	return curve.Scalar.New(-1).BigInt().BitLen()
+}
+
+// batchCount returns the number of cOT batches for a VOLE of length `size`, and the number of copies of Delta per batch.
+func batchCount(curve *curves.Curve, size int) (int, int) {
+	lanes := kos.L / bitLength(curve)
+	perBatch := lanes * kos.OtWidth
+	return (size + perBatch - 1) / perBatch, lanes
+}
+
+// batchSessionId derives the session id of a cOT batch, so that no two batches share one.
+func batchSessionId(uniqueSessionId [simplest.DigestSize]byte, batch int) [simplest.DigestSize]byte {
+	hash := sha3.New256()
+	_, _ = hash.Write([]byte("Coinbase_VOLE_batch"))
+	_, _ = hash.Write(uniqueSessionId[:])
+	batchBytes := [4]byte{}
+	binary.BigEndian.PutUint32(batchBytes[:], uint32(batch))
+	_, _ = hash.Write(batchBytes[:])
+	result := [simplest.DigestSize]byte{}
+	copy(result[:], hash.Sum(nil))
+	return result
+}
+
+// NewSender creates a VOLE sender of length `size`. you must supply the output gotten by running an instance of seed OT
+// as the _receiver_, as for the KOS sender.
+func NewSender(seedOtResults *simplest.ReceiverOutput, curve *curves.Curve, size int) (*Sender, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("VOLE size must be positive, got %d", size)
+	}
+	return &Sender{
+		seedOtResults: seedOtResults,
+		curve:         curve,
+		size:          size,
+	}, nil
+}
+
+// NewReceiver creates a VOLE receiver of length `size`. you must supply the output gotten by running an instance of
+// seed OT as the _sender_, as for the KOS receiver.
+func NewReceiver(seedOtResults *simplest.SenderOutput, curve *curves.Curve, size int) (*Receiver, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("VOLE size must be positive, got %d", size)
+	}
+	return &Receiver{
+		seedOtResults: seedOtResults,
+		curve:         curve,
+		size:          size,
+	}, nil
+}
+
+// Round1Initialize samples Delta and runs the first round of the cOT batches with its bits as the choice vector.
+func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]byte) (*Round1Output, error) {
+	receiver.Delta = receiver.curve.Scalar.Random(rand.Reader)
+	delta := receiver.Delta.BigInt()
+	bits := bitLength(receiver.curve)
+	batches, lanes := batchCount(receiver.curve, receiver.size)
+
+	choice := [kos.COtBlockSizeBytes]byte{}
+	for lane := 0; lane < lanes; lane++ {
+		for j := 0; j < bits; j++ {
+			position := lane*bits + j
+			choice[position>>3] |= byte(delta.Bit(j)) << (position & 0x07)
+		}
+	}
+
+	receiver.cOtReceivers = make([]*kos.Receiver, batches)
+	result := &Round1Output{COtRound1Outputs: make([]*kos.Round1Output, batches)}
+	for batch := range receiver.cOtReceivers {
+		receiver.cOtReceivers[batch] = kos.NewCOtReceiver(receiver.seedOtResults, receiver.curve)
+		var err error
+		result.COtRound1Outputs[batch], err = receiver.cOtReceivers[batch].Round1Initialize(batchSessionId(uniqueSessionId, batch), choice)
+		if err != nil {
+			return nil, errors.Wrap(err, "cOT round 1 in VOLE receiver round 1")
+		}
+	}
+	return result, nil
+}
+
+// Round2Transfer samples U and runs the second round of the cOT batches with the inputs 2^j * U[i], then sets V from
+// its additive shares.
+func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte, round1Output *Round1Output) (*Round2Output, error) {
+	bits := bitLength(sender.curve)
+	batches, lanes := batchCount(sender.curve, sender.size)
+	if len(round1Output.COtRound1Outputs) != batches {
+		return nil, fmt.Errorf("expected %d cOT batches, got %d", batches, len(round1Output.COtRound1Outputs))
+	}
+	powers, err := powersOfTwo(sender.curve, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	sender.U = make([]curves.Scalar, sender.size)
+	sender.V = make([]curves.Scalar, sender.size)
+	for i := range sender.U {
+		sender.U[i] = sender.curve.Scalar.Random(rand.Reader)
+		sender.V[i] = sender.curve.Scalar.Zero()
+	}
+	result := &Round2Output{COtRound2Outputs: make([]*kos.Round2Output, batches)}
+	for batch := 0; batch < batches; batch++ {
+		input := [kos.L][kos.OtWidth]curves.Scalar{}
+		for j := range input {
+			for k := range input[j] {
+				input[j][k] = sender.curve.Scalar.Zero()
+			}
+		}
+		forEachEntry(sender.size, batch, lanes, func(i, lane, k int) {
+			for j := 0; j < bits; j++ {
+				input[lane*bits+j][k] = powers[j].Mul(sender.U[i])
+			}
+		})
+		cOtSender := kos.NewCOtSender(sender.seedOtResults, sender.curve)
+		result.COtRound2Outputs[batch], err = cOtSender.Round2Transfer(batchSessionId(uniqueSessionId, batch), input, round1Output.COtRound1Outputs[batch])
+		if err != nil {
+			return nil, errors.Wrap(err, "cOT round 2 in VOLE sender round 2")
+		}
+		forEachEntry(sender.size, batch, lanes, func(i, lane, k int) {
+			for j := 0; j < bits; j++ {
+				sender.V[i] = sender.V[i].Sub(cOtSender.OutputAdditiveShares[lane*bits+j][k])
+			}
+		})
+	}
+	return result, nil
+}
+
+// Round3Transfer finishes the cOT batches and sets W from the receiver's additive shares.
+func (receiver *Receiver) Round3Transfer(round2Output *Round2Output) error {
+	if len(round2Output.COtRound2Outputs) != len(receiver.cOtReceivers) {
+		return fmt.Errorf("expected %d cOT batches, got %d", len(receiver.cOtReceivers), len(round2Output.COtRound2Outputs))
+	}
+	bits := bitLength(receiver.curve)
+	_, lanes := batchCount(receiver.curve, receiver.size)
+	receiver.W = make([]curves.Scalar, receiver.size)
+	for i := range receiver.W {
+		receiver.W[i] = receiver.curve.Scalar.Zero()
+	}
+	for batch, cOtReceiver := range receiver.cOtReceivers {
+		if err := cOtReceiver.Round3Transfer(round2Output.COtRound2Outputs[batch]); err != nil {
+			return errors.Wrap(err, "cOT round 3 in VOLE receiver round 3")
+		}
+		forEachEntry(receiver.size, batch, lanes, func(i, lane, k int) {
+			for j := 0; j < bits; j++ {
+				receiver.W[i] = receiver.W[i].Add(cOtReceiver.OutputAdditiveShares[lane*bits+j][k])
+			}
+		})
+	}
+	return nil
+}
+
+// forEachEntry calls f with the index of every VOLE entry carried by the batch, together with its lane and its slot k
+// of the cOT width.
+func forEachEntry(size, batch, lanes int, f func(i, lane, k int)) {
+	perBatch := lanes * kos.OtWidth
+	for lane := 0; lane < lanes; lane++ {
+		for k := 0; k < kos.OtWidth; k++ {
+			i := batch*perBatch + lane*kos.OtWidth + k
+			if i < size {
+				f(i, lane, k)
+			}
+		}
+	}
+}
+
+// powersOfTwo returns 2^j for j < bits, as scalars.
+func powersOfTwo(curve *curves.Curve, bits int) ([]curves.Scalar, error) {
+	powers := make([]curves.Scalar, bits)
+	for j := range powers {
+		var err error
+		powers[j], err = curve.Scalar.SetBigInt(new(big.Int).Lsh(big.NewInt(1), uint(j)))
+		if err != nil {
+			return nil, errors.Wrap(err, "creating power of two scalar from big int")
+		}
+	}
+	return powers, nil
+}
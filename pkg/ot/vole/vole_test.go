@@ -0,0 +1,83 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package vole
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+	"github.com/etclab/kryptology/pkg/ot/ottest"
+)
+
+func runVOLE(t *testing.T, curve *curves.Curve, size int) (*Sender, *Receiver) {
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(uniqueSessionId[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, uniqueSessionId)
+	require.NoError(t, err)
+
+	sender, err := NewSender(baseOtReceiverOutput, curve, size)
+	require.NoError(t, err)
+	receiver, err := NewReceiver(baseOtSenderOutput, curve, size)
+	require.NoError(t, err)
+	round1Output, err := receiver.Round1Initialize(uniqueSessionId)
+	require.NoError(t, err)
+	round2Output, err := sender.Round2Transfer(uniqueSessionId, round1Output)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Round3Transfer(round2Output))
+	return sender, receiver
+}
+
+func TestVOLE(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		for _, size := range []int{1, 4, 9} {
+			sender, receiver := runVOLE(t, curve, size)
+			require.Len(t, sender.U, size)
+			require.Len(t, sender.V, size)
+			require.Len(t, receiver.W, size)
+			for i := 0; i < size; i++ {
+				expected := sender.U[i].MulAdd(receiver.Delta, sender.V[i])
+				require.Equal(t, 0, expected.Cmp(receiver.W[i]), "%s, size %d, entry %d", curve.Name, size, i)
+			}
+		}
+	}
+}
+
+func TestVOLEBatchCount(t *testing.T) {
+	// 256-bit scalars fit twice in a batch of 672 choice bits, with 2 entries each
+	batches, lanes := batchCount(curves.K256(), 9)
+	require.Equal(t, 3, batches)
+	require.Equal(t, 2, lanes)
+	batches, _ = batchCount(curves.K256(), 4)
+	require.Equal(t, 1, batches)
+}
+
+func TestVOLEInvalid(t *testing.T) {
+	curve := curves.K256()
+	_, err := NewSender(&simplest.ReceiverOutput{}, curve, 0)
+	require.Error(t, err)
+	_, err = NewReceiver(&simplest.SenderOutput{}, curve, -1)
+	require.Error(t, err)
+
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, uniqueSessionId)
+	require.NoError(t, err)
+	sender, err := NewSender(baseOtReceiverOutput, curve, 5)
+	require.NoError(t, err)
+	receiver, err := NewReceiver(baseOtSenderOutput, curve, 4)
+	require.NoError(t, err)
+	round1Output, err := receiver.Round1Initialize(uniqueSessionId)
+	require.NoError(t, err)
+	// the sender expects two batches
+	_, err = sender.Round2Transfer(uniqueSessionId, round1Output)
+	require.Error(t, err)
+}
@@ -0,0 +1,126 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains homomorphic operations over slices of ciphertexts, e.g. for encrypted aggregation.
+// The functions taking a number of workers run it in that many goroutines, or DefaultWorkers if it is not positive.
+
+package paillier
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core"
+)
+
+// BatchAdd adds two slices of ciphertexts element-wise: the result encrypts c[i] + d[i].
+func (pk *PublicKey) BatchAdd(c, d []Ciphertext, workers int) ([]Ciphertext, error) {
+	if len(c) != len(d) {
+		return nil, fmt.Errorf("ciphertext slices have different lengths: %d != %d", len(c), len(d))
+	}
+	result := make([]Ciphertext, len(c))
+	err := parallelFor(len(c), workers, func(i int) error {
+		var err error
+		result[i], err = pk.Add(c[i], d[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BatchMul multiplies a slice of ciphertexts by a slice of plain values element-wise: the result encrypts a[i]·c[i].
+func (pk *PublicKey) BatchMul(a []*big.Int, c []Ciphertext, workers int) ([]Ciphertext, error) {
+	if len(a) != len(c) {
+		return nil, fmt.Errorf("scalar and ciphertext slices have different lengths: %d != %d", len(a), len(c))
+	}
+	result := make([]Ciphertext, len(c))
+	err := parallelFor(len(c), workers, func(i int) error {
+		var err error
+		result[i], err = pk.Mul(a[i], c[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Sum adds all the ciphertexts: the result encrypts the sum of their plaintexts.
+func (pk *PublicKey) Sum(c []Ciphertext) (Ciphertext, error) {
+	if len(c) == 0 {
+		return nil, fmt.Errorf("no ciphertexts to add")
+	}
+	sum := c[0]
+	if sum == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if err := core.In(sum, pk.N2); err != nil {
+		return nil, err
+	}
+	for _, ci := range c[1:] {
+		var err error
+		if sum, err = pk.Add(sum, ci); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
+
+// InnerProduct computes the inner product of a slice of plain values and a slice of ciphertexts:
+// the result encrypts Σ a[i]·c[i]. The exponentiations run in `workers` goroutines.
+func (pk *PublicKey) InnerProduct(a []*big.Int, c []Ciphertext, workers int) (Ciphertext, error) {
+	if len(c) == 0 {
+		return nil, fmt.Errorf("no ciphertexts to multiply")
+	}
+	products, err := pk.BatchMul(a, c, workers)
+	if err != nil {
+		return nil, err
+	}
+	return pk.Sum(products)
+}
+
+// parallelFor calls f for 0 ≤ i < n in `workers` goroutines, or DefaultWorkers if it is not positive,
+// and returns the first error.
+func parallelFor(n, workers int, f func(i int) error) error {
+	workers = workerCount(workers)
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			if err := f(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	indices := make(chan int, n)
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := f(i); err != nil {
+					once.Do(func() { firstErr = err })
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
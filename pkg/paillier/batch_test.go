@@ -0,0 +1,100 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encryptAll(t *testing.T, pk *PublicKey, msgs []int64) []Ciphertext {
+	c := make([]Ciphertext, len(msgs))
+	for i, m := range msgs {
+		var err error
+		c[i], _, err = pk.Encrypt(big.NewInt(m))
+		require.NoError(t, err)
+	}
+	return c
+}
+
+func decryptAll(t *testing.T, sk *SecretKey, c []Ciphertext) []int64 {
+	result := make([]int64, len(c))
+	for i, ci := range c {
+		m, err := sk.Decrypt(ci)
+		require.NoError(t, err)
+		result[i] = m.Int64()
+	}
+	return result
+}
+
+func TestBatchOperations(t *testing.T) {
+	sk, err := NewSecretKey(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	pk := &sk.PublicKey
+	c := encryptAll(t, pk, []int64{1, 2, 3, 4, 5})
+	d := encryptAll(t, pk, []int64{10, 20, 30, 40, 50})
+	a := []*big.Int{big.NewInt(2), big.NewInt(0), big.NewInt(1), big.NewInt(7), big.NewInt(3)}
+
+	for _, workers := range []int{0, 1, 3, 16} {
+		sums, err := pk.BatchAdd(c, d, workers)
+		require.NoError(t, err)
+		require.Equal(t, []int64{11, 22, 33, 44, 55}, decryptAll(t, sk, sums))
+
+		products, err := pk.BatchMul(a, c, workers)
+		require.NoError(t, err)
+		require.Equal(t, []int64{2, 0, 3, 28, 15}, decryptAll(t, sk, products))
+
+		inner, err := pk.InnerProduct(a, d, workers)
+		require.NoError(t, err)
+		require.Equal(t, []int64{480}, decryptAll(t, sk, []Ciphertext{inner}))
+	}
+
+	sum, err := pk.Sum(c)
+	require.NoError(t, err)
+	require.Equal(t, []int64{15}, decryptAll(t, sk, []Ciphertext{sum}))
+
+	empty, err := pk.BatchAdd(nil, nil, 0)
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
+
+func TestBatchOperationsErrors(t *testing.T) {
+	sk, err := NewSecretKey(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	pk := &sk.PublicKey
+	c := encryptAll(t, pk, []int64{1, 2, 3})
+	a := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+
+	// the lengths must match
+	_, err = pk.BatchAdd(c, c[:2], 0)
+	require.Error(t, err)
+	_, err = pk.BatchMul(a[:2], c, 0)
+	require.Error(t, err)
+	_, err = pk.InnerProduct(a, c[:1], 0)
+	require.Error(t, err)
+	_, err = pk.InnerProduct(nil, nil, 0)
+	require.Error(t, err)
+	_, err = pk.Sum(nil)
+	require.Error(t, err)
+
+	// every element is checked, whichever worker handles it
+	invalid := []Ciphertext{c[0], pk.N2, c[2]}
+	_, err = pk.BatchAdd(c, invalid, 2)
+	require.Error(t, err)
+	_, err = pk.BatchMul([]*big.Int{big.NewInt(1), pk.N, big.NewInt(1)}, c, 2)
+	require.Error(t, err)
+	_, err = pk.BatchMul([]*big.Int{big.NewInt(1), nil, big.NewInt(1)}, c, 2)
+	require.Error(t, err)
+	_, err = pk.Sum(invalid)
+	require.Error(t, err)
+	_, err = pk.Sum([]Ciphertext{pk.N2})
+	require.Error(t, err)
+	_, err = pk.Sum([]Ciphertext{nil})
+	require.Error(t, err)
+}
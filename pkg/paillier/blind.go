@@ -0,0 +1,92 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains re-randomization and blinding of ciphertexts, which make them unlinkable to the originals.
+
+package paillier
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core"
+)
+
+// Rerandomize produces a fresh ciphertext of the same plaintext, c·r^N mod N² for a random nonce r ∈ Z_N*,
+// which cannot be linked to c without the secret key. It also returns r.
+func (pk *PublicKey) Rerandomize(c Ciphertext) (Ciphertext, *big.Int, error) {
+	r, err := randUnit(pk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := pk.RerandomizeWithNonce(c, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, r, nil
+}
+
+// RerandomizeWithNonce computes c·r^N mod N², a ciphertext of the same plaintext with the nonce multiplied by r.
+func (pk *PublicKey) RerandomizeWithNonce(c Ciphertext, r *big.Int) (Ciphertext, error) {
+	if c == nil || r == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if err := pk.checkCiphertext(c); err != nil {
+		return nil, err
+	}
+	// Ensure r ∈ Z_N*
+	if err := core.In(r, pk.N); err != nil {
+		return nil, err
+	}
+	if new(big.Int).GCD(nil, nil, r, pk.N).Cmp(core.One) != 0 {
+		return nil, fmt.Errorf("r must be invertible mod N")
+	}
+	return core.Mul(c, new(big.Int).Exp(r, pk.N, pk.N2), pk.N2)
+}
+
+// Blind adds a random mask ρ ∈ Z_N to the plaintext of c and re-randomizes the result, so that whoever decrypts it
+// learns m + ρ mod N instead of m. It returns the blinded ciphertext and ρ, which Unblind removes.
+func (pk *PublicKey) Blind(c Ciphertext) (Ciphertext, *big.Int, error) {
+	ρ, err := core.Rand(pk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := pk.BlindWithMask(c, ρ)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, ρ, nil
+}
+
+// BlindWithMask adds the mask ρ ∈ Z_N to the plaintext of c and re-randomizes the result.
+func (pk *PublicKey) BlindWithMask(c Ciphertext, ρ *big.Int) (Ciphertext, error) {
+	if c == nil || ρ == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if err := pk.checkCiphertext(c); err != nil {
+		return nil, err
+	}
+	mask, _, err := pk.Encrypt(ρ)
+	if err != nil {
+		return nil, err
+	}
+	return pk.Add(c, mask)
+}
+
+// Unblind removes the mask ρ from a blinded plaintext: it returns msg - ρ mod N.
+func (pk *PublicKey) Unblind(msg, ρ *big.Int) (*big.Int, error) {
+	if msg == nil || ρ == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if err := core.In(msg, pk.N); err != nil {
+		return nil, err
+	}
+	if err := core.In(ρ, pk.N); err != nil {
+		return nil, err
+	}
+	result := new(big.Int).Sub(msg, ρ)
+	return result.Mod(result, pk.N), nil
+}
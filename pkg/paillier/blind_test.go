@@ -0,0 +1,78 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRerandomize(t *testing.T) {
+	sk, err := NewSecretKey(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	pk := &sk.PublicKey
+	msg := big.NewInt(31337)
+	c, r, err := pk.Encrypt(msg)
+	require.NoError(t, err)
+
+	rerandomized, s, err := pk.Rerandomize(c)
+	require.NoError(t, err)
+	require.NotEqual(t, c, rerandomized)
+	actual, err := sk.Decrypt(rerandomized)
+	require.NoError(t, err)
+	require.Equal(t, msg, actual)
+
+	// the new nonce is the product of the nonces
+	rs := new(big.Int).Mul(r, s)
+	rs.Mod(rs, pk.N)
+	expected, err := pk.encrypt(msg, rs)
+	require.NoError(t, err)
+	require.Equal(t, expected, rerandomized)
+
+	_, err = pk.RerandomizeWithNonce(c, big.NewInt(0))
+	require.Error(t, err)
+	_, err = pk.RerandomizeWithNonce(c, testPrimes[1])
+	require.Error(t, err)
+	_, err = pk.RerandomizeWithNonce(c, pk.N)
+	require.Error(t, err)
+	_, err = pk.RerandomizeWithNonce(pk.N, r)
+	require.Error(t, err)
+	_, _, err = pk.Rerandomize(nil)
+	require.Error(t, err)
+}
+
+func TestBlind(t *testing.T) {
+	sk, err := NewSecretKey(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	pk := &sk.PublicKey
+	for _, msg := range []*big.Int{big.NewInt(0), big.NewInt(42), new(big.Int).Sub(pk.N, big.NewInt(1))} {
+		c, _, err := pk.Encrypt(msg)
+		require.NoError(t, err)
+		blinded, ρ, err := pk.Blind(c)
+		require.NoError(t, err)
+		masked, err := sk.Decrypt(blinded)
+		require.NoError(t, err)
+		expected := new(big.Int).Add(msg, ρ)
+		require.Equal(t, 0, expected.Mod(expected, pk.N).Cmp(masked))
+		actual, err := pk.Unblind(masked, ρ)
+		require.NoError(t, err)
+		require.Equal(t, 0, msg.Cmp(actual))
+	}
+
+	c, _, err := pk.Encrypt(big.NewInt(5))
+	require.NoError(t, err)
+	_, err = pk.BlindWithMask(c, pk.N)
+	require.Error(t, err)
+	_, err = pk.BlindWithMask(nil, big.NewInt(1))
+	require.Error(t, err)
+	_, err = pk.Unblind(pk.N, big.NewInt(1))
+	require.Error(t, err)
+	_, err = pk.Unblind(big.NewInt(1), nil)
+	require.Error(t, err)
+}
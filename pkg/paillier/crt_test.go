@@ -0,0 +1,114 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	crypto "github.com/etclab/kryptology/pkg/core"
+)
+
+// withoutCrt is a copy of the secret key which computes modulo N², as its factors are unknown without the totient
+func withoutCrt(sk *SecretKey) *SecretKey {
+	return &SecretKey{PublicKey: sk.PublicKey, Lambda: sk.Lambda, U: sk.U}
+}
+
+func TestCrtMatchesGeneric(t *testing.T) {
+	for i := 0; i+1 < 6; i += 2 {
+		sk, err := NewSecretKey(testPrimes[i], testPrimes[i+1])
+		require.NoError(t, err)
+		require.NotNil(t, sk.crtKey())
+		generic := withoutCrt(sk)
+		for _, msg := range []*big.Int{crypto.Zero, crypto.One, new(big.Int).Sub(sk.N, crypto.One), big.NewInt(123456789)} {
+			r, err := crypto.Rand(sk.N)
+			require.NoError(t, err)
+			expected, err := sk.PublicKey.encrypt(msg, r)
+			require.NoError(t, err)
+			actual, err := sk.encrypt(msg, r)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+
+			for _, key := range []*SecretKey{sk, generic} {
+				decrypted, err := key.Decrypt(actual)
+				require.NoError(t, err)
+				require.Zero(t, msg.Cmp(decrypted))
+			}
+			c, _, err := sk.Encrypt(msg)
+			require.NoError(t, err)
+			decrypted, err := generic.Decrypt(c)
+			require.NoError(t, err)
+			require.Zero(t, msg.Cmp(decrypted))
+		}
+	}
+}
+
+func TestCrtDecryptErrorConditions(t *testing.T) {
+	sk, err := NewSecretKey(testPrimes[0], testPrimes[1])
+	require.NoError(t, err)
+	for _, c := range []*big.Int{nil, big.NewInt(-1), sk.N2, sk.crtKey().p, new(big.Int).Mul(sk.crtKey().qq, big.NewInt(3))} {
+		_, err := sk.Decrypt(c)
+		require.Error(t, err)
+		_, err = withoutCrt(sk).Decrypt(c)
+		require.Error(t, err)
+	}
+	_, err = sk.encrypt(sk.N, crypto.One)
+	require.Error(t, err)
+	_, err = sk.encrypt(crypto.One, crypto.Zero)
+	require.Error(t, err)
+	_, err = sk.encrypt(nil, crypto.One)
+	require.Error(t, err)
+}
+
+func TestCrtKeyFromTotient(t *testing.T) {
+	sk, err := NewSecretKey(testPrimes[2], testPrimes[3])
+	require.NoError(t, err)
+	require.Equal(t, sk.crtKey(), crtKeyFromTotient(sk.N, sk.Totient))
+	// the larger prime comes first whatever the order of the arguments
+	sk2, err := NewSecretKey(testPrimes[3], testPrimes[2])
+	require.NoError(t, err)
+	require.Equal(t, sk.crtKey(), sk2.crtKey())
+
+	require.Nil(t, crtKeyFromTotient(sk.N, new(big.Int).Add(sk.Totient, big.NewInt(2))))
+	require.Nil(t, crtKeyFromTotient(big.NewInt(2), crypto.One))
+	require.Nil(t, crtKeyFromTotient(nil, crypto.One))
+	// factors which are not primes
+	require.Nil(t, newCrtKey(big.NewInt(9), big.NewInt(7)))
+	require.Nil(t, newCrtKey(big.NewInt(7), big.NewInt(7)))
+}
+
+func BenchmarkDecrypt(b *testing.B) {
+	sk, err := NewSecretKey(testPrimes[0], testPrimes[1])
+	require.NoError(b, err)
+	c, _, err := sk.Encrypt(big.NewInt(123456789))
+	require.NoError(b, err)
+	for name, key := range map[string]*SecretKey{"crt": sk, "generic": withoutCrt(sk)} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = key.Decrypt(c)
+			}
+		})
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	sk, err := NewSecretKey(testPrimes[0], testPrimes[1])
+	require.NoError(b, err)
+	msg := big.NewInt(123456789)
+	b.Run("crt", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = sk.Encrypt(msg)
+		}
+	})
+	b.Run("public key", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = sk.PublicKey.Encrypt(msg)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the ASN.1 DER and PEM encodings of Paillier keys:
+//
+//	PaillierPublicKey ::= SEQUENCE {
+//	    version  INTEGER,  -- 0
+//	    modulus  INTEGER   -- N
+//	}
+//
+//	PaillierPrivateKey ::= SEQUENCE {
+//	    version  INTEGER,  -- 0
+//	    modulus  INTEGER,  -- N = PQ
+//	    prime1   INTEGER,  -- P, the larger prime
+//	    prime2   INTEGER   -- Q
+//	}
+//
+// The PEM blocks have the types "PAILLIER PUBLIC KEY" and "PAILLIER PRIVATE KEY".
+
+package paillier
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core"
+)
+
+const (
+	// PublicKeyPemType is the type of PEM blocks holding a Paillier public key.
+	PublicKeyPemType = "PAILLIER PUBLIC KEY"
+	// SecretKeyPemType is the type of PEM blocks holding a Paillier secret key.
+	SecretKeyPemType = "PAILLIER PRIVATE KEY"
+
+	// derVersion is the only version of the ASN.1 structures.
+	derVersion = 0
+)
+
+// publicKeyAsn1 is the ASN.1 structure PaillierPublicKey.
+type publicKeyAsn1 struct {
+	Version int
+	N       *big.Int
+}
+
+// secretKeyAsn1 is the ASN.1 structure PaillierPrivateKey.
+type secretKeyAsn1 struct {
+	Version int
+	N       *big.Int
+	P, Q    *big.Int
+}
+
+// MarshalDER encodes the public key as an ASN.1 DER PaillierPublicKey.
+func (pk *PublicKey) MarshalDER() ([]byte, error) {
+	if pk == nil || pk.N == nil {
+		return nil, internal.ErrNilArguments
+	}
+	return asn1.Marshal(publicKeyAsn1{derVersion, pk.N})
+}
+
+// MarshalPEM encodes the public key as a PEM block of type PublicKeyPemType.
+func (pk *PublicKey) MarshalPEM() ([]byte, error) {
+	der, err := pk.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: PublicKeyPemType, Bytes: der}), nil
+}
+
+// ParsePublicKeyDER decodes an ASN.1 DER PaillierPublicKey.
+func ParsePublicKeyDER(der []byte) (*PublicKey, error) {
+	var data publicKeyAsn1
+	rest, err := asn1.Unmarshal(der, &data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("trailing data after the public key")
+	}
+	// encoding/asn1 ignores extra fields and some non-canonical encodings, so require the exact encoding
+	if err = checkCanonical(der, data); err != nil {
+		return nil, err
+	}
+	if data.Version != derVersion {
+		return nil, fmt.Errorf("unsupported public key version %d", data.Version)
+	}
+	// N is the product of two odd primes
+	if data.N.Sign() != 1 || data.N.Bit(0) != 1 {
+		return nil, fmt.Errorf("invalid modulus")
+	}
+	return NewPubkey(data.N)
+}
+
+// ParsePublicKeyPEM decodes a PEM block of type PublicKeyPemType.
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	der, err := decodePem(data, PublicKeyPemType)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKeyDER(der)
+}
+
+// MarshalDER encodes the secret key as an ASN.1 DER PaillierPrivateKey. The encoding holds the
+// factors of N, from which all the other values of the key are recomputed.
+func (sk *SecretKey) MarshalDER() ([]byte, error) {
+	if sk == nil || sk.N == nil {
+		return nil, internal.ErrNilArguments
+	}
+	crt := sk.crtKey()
+	if crt == nil {
+		return nil, fmt.Errorf("the factors of N are unknown")
+	}
+	return asn1.Marshal(secretKeyAsn1{derVersion, sk.N, crt.p, crt.q})
+}
+
+// MarshalPEM encodes the secret key as a PEM block of type SecretKeyPemType.
+func (sk *SecretKey) MarshalPEM() ([]byte, error) {
+	der, err := sk.MarshalDER()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: SecretKeyPemType, Bytes: der}), nil
+}
+
+// ParseSecretKeyDER decodes an ASN.1 DER PaillierPrivateKey, checking that its primes are distinct factors of N.
+func ParseSecretKeyDER(der []byte) (*SecretKey, error) {
+	var data secretKeyAsn1
+	rest, err := asn1.Unmarshal(der, &data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("trailing data after the secret key")
+	}
+	// encoding/asn1 ignores extra fields and some non-canonical encodings, so require the exact encoding
+	if err = checkCanonical(der, data); err != nil {
+		return nil, err
+	}
+	if data.Version != derVersion {
+		return nil, fmt.Errorf("unsupported secret key version %d", data.Version)
+	}
+	if data.P.Cmp(core.One) != 1 || data.Q.Cmp(core.One) != 1 || data.P.Cmp(data.Q) == 0 ||
+		new(big.Int).Mul(data.P, data.Q).Cmp(data.N) != 0 {
+		return nil, fmt.Errorf("the primes are not distinct factors of the modulus")
+	}
+	if !data.P.ProbablyPrime(20) || !data.Q.ProbablyPrime(20) {
+		return nil, fmt.Errorf("the factors of the modulus are not prime")
+	}
+	return NewSecretKey(data.P, data.Q)
+}
+
+// ParseSecretKeyPEM decodes a PEM block of type SecretKeyPemType.
+func ParseSecretKeyPEM(data []byte) (*SecretKey, error) {
+	der, err := decodePem(data, SecretKeyPemType)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSecretKeyDER(der)
+}
+
+// checkCanonical ensures that der is the DER encoding of value.
+func checkCanonical(der []byte, value interface{}) error {
+	encoded, err := asn1.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(encoded, der) {
+		return fmt.Errorf("invalid DER encoding")
+	}
+	return nil
+}
+
+// decodePem returns the content of the single PEM block of type blockType in data.
+func decodePem(data []byte, blockType string) ([]byte, error) {
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if block.Type != blockType {
+		return nil, fmt.Errorf("unexpected PEM block type %q, want %q", block.Type, blockType)
+	}
+	if len(block.Headers) != 0 {
+		return nil, fmt.Errorf("unexpected PEM headers")
+	}
+	if len(bytes.TrimSpace(rest)) != 0 {
+		return nil, fmt.Errorf("trailing data after the PEM block")
+	}
+	return block.Bytes, nil
+}
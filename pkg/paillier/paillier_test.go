@@ -7,6 +7,7 @@
 package paillier
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -595,6 +596,58 @@ func TestNewKeysDistinct(t *testing.T) {
 	require.NotEqual(t, sec1.U, sec2.U)
 }
 
+func TestNewKeysWithContext(t *testing.T) {
+	pub, sec, err := NewKeysWithContext(context.Background(), 128, 2)
+	require.NoError(t, err)
+	require.Equal(t, 256, pub.N.BitLen())
+	msg := big.NewInt(42)
+	c, _, err := pub.Encrypt(msg)
+	require.NoError(t, err)
+	actual, err := sec.Decrypt(c)
+	require.NoError(t, err)
+	require.Equal(t, msg, actual)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = NewKeysWithContext(ctx, PaillierPrimeBits, 0)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewKeysFromPrimes(t *testing.T) {
+	pub, sec, err := NewKeysFromPrimes(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	expected, err := NewSecretKey(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	require.Equal(t, expected, sec)
+	require.Equal(t, &expected.PublicKey, pub)
+
+	_, _, err = NewKeysFromPrimes(testPrimes[1], testPrimes[1])
+	require.Error(t, err)
+	_, _, err = NewKeysFromPrimes(testPrimes[1], nil)
+	require.Error(t, err)
+	// testPrimes[0] has 1025 bits
+	_, _, err = NewKeysFromPrimes(testPrimes[0], testPrimes[1])
+	require.Error(t, err)
+	_, _, err = NewKeysFromPrimes(testPrimes[1], new(big.Int).Add(testPrimes[2], two))
+	require.Error(t, err)
+	// 29 is prime but 14 is not
+	_, _, err = NewKeysFromPrimes(big.NewInt(23), big.NewInt(29))
+	require.Error(t, err)
+}
+
+func TestNewKeysFromSeed(t *testing.T) {
+	pub1, sec1, err := NewKeysFromSeed([]byte("seed"), 128)
+	require.NoError(t, err)
+	pub2, sec2, err := NewKeysFromSeed([]byte("seed"), 128)
+	require.NoError(t, err)
+	pub3, _, err := NewKeysFromSeed([]byte("other seed"), 128)
+	require.NoError(t, err)
+	require.Equal(t, pub1, pub2)
+	require.Equal(t, sec1, sec2)
+	require.NotEqual(t, pub1.N, pub3.N)
+	require.Equal(t, 256, pub1.N.BitLen())
+}
+
 // Tests the restrictions on input values for paillier.Add
 func TestAddErrorConditions(t *testing.T) {
 	pk, err := NewPubkey(N)
@@ -0,0 +1,98 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the sizes of Paillier moduli for a security level, and checks of imported keys against them.
+
+package paillier
+
+import (
+	"context"
+	"fmt"
+)
+
+// SecurityLevel is a number of bits of security, the size of moduli for which follows NIST SP 800-57 Part 1 table 2.
+type SecurityLevel uint
+
+const (
+	// SecurityLevel112 requires a 2048-bit modulus, which NewKeys generates.
+	SecurityLevel112 SecurityLevel = 112
+	// SecurityLevel128 requires a 3072-bit modulus.
+	SecurityLevel128 SecurityLevel = 128
+	// SecurityLevel192 requires a 7680-bit modulus.
+	SecurityLevel192 SecurityLevel = 192
+	// SecurityLevel256 requires a 15360-bit modulus.
+	SecurityLevel256 SecurityLevel = 256
+)
+
+// securityLevels lists the levels in decreasing order with the size of their moduli.
+var securityLevels = []struct {
+	level       SecurityLevel
+	modulusBits uint
+}{
+	{SecurityLevel256, 15360},
+	{SecurityLevel192, 7680},
+	{SecurityLevel128, 3072},
+	{SecurityLevel112, 2048},
+}
+
+// ModulusBits returns the minimum size of a modulus for the security level.
+func (l SecurityLevel) ModulusBits() (uint, error) {
+	for _, s := range securityLevels {
+		if s.level == l {
+			return s.modulusBits, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported security level %d", l)
+}
+
+// NewKeysWithSecurityLevel generates Paillier keys with the smallest modulus for the security level,
+// e.g. 3072 bits for SecurityLevel128.
+func NewKeysWithSecurityLevel(level SecurityLevel) (*PublicKey, *SecretKey, error) {
+	bits, err := level.ModulusBits()
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewKeysWithModulusBits(bits)
+}
+
+// NewKeysWithModulusBits generates Paillier keys with a `bits` sized modulus, e.g. 3072 or 4096.
+// It must be even and at least the size for SecurityLevel112.
+func NewKeysWithModulusBits(bits uint) (*PublicKey, *SecretKey, error) {
+	minimum, _ := SecurityLevel112.ModulusBits()
+	if bits < minimum || bits%2 != 0 {
+		return nil, nil, fmt.Errorf("modulus size must be even and at least %d bits", minimum)
+	}
+	return NewKeysWithContext(context.Background(), bits/2, 0)
+}
+
+// SecurityLevel returns the highest security level that the size of the modulus meets,
+// or 0 if it is smaller than the size for SecurityLevel112.
+func (pk *PublicKey) SecurityLevel() SecurityLevel {
+	if pk == nil || pk.N == nil {
+		return 0
+	}
+	for _, s := range securityLevels {
+		if uint(pk.N.BitLen()) >= s.modulusBits {
+			return s.level
+		}
+	}
+	return 0
+}
+
+// CheckSecurityLevel returns an error if the modulus is too small for the security level,
+// e.g. to reject undersized keys when they are imported.
+func (pk *PublicKey) CheckSecurityLevel(level SecurityLevel) error {
+	bits, err := level.ModulusBits()
+	if err != nil {
+		return err
+	}
+	if pk == nil || pk.N == nil {
+		return fmt.Errorf("public key has no modulus")
+	}
+	if uint(pk.N.BitLen()) < bits {
+		return fmt.Errorf("%d-bit modulus is too small for %d-bit security, which requires %d bits", pk.N.BitLen(), level, bits)
+	}
+	return nil
+}
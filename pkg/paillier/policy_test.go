@@ -0,0 +1,75 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityLevelModulusBits(t *testing.T) {
+	for level, bits := range map[SecurityLevel]uint{
+		SecurityLevel112: 2048,
+		SecurityLevel128: 3072,
+		SecurityLevel192: 7680,
+		SecurityLevel256: 15360,
+	} {
+		actual, err := level.ModulusBits()
+		require.NoError(t, err)
+		require.Equal(t, bits, actual)
+	}
+	_, err := SecurityLevel(80).ModulusBits()
+	require.Error(t, err)
+}
+
+func TestCheckSecurityLevel(t *testing.T) {
+	pk, err := NewPubkey(new(big.Int).Lsh(big.NewInt(1), 2047))
+	require.NoError(t, err)
+	require.Equal(t, SecurityLevel112, pk.SecurityLevel())
+	require.NoError(t, pk.CheckSecurityLevel(SecurityLevel112))
+	require.Error(t, pk.CheckSecurityLevel(SecurityLevel128))
+	require.Error(t, pk.CheckSecurityLevel(SecurityLevel(100)))
+
+	// an imported key with a small modulus is rejected
+	small, err := NewPubkey(big.NewInt(23 * 47))
+	require.NoError(t, err)
+	require.Equal(t, SecurityLevel(0), small.SecurityLevel())
+	require.Error(t, small.CheckSecurityLevel(SecurityLevel112))
+
+	large, err := NewPubkey(new(big.Int).Lsh(big.NewInt(1), 4095))
+	require.NoError(t, err)
+	require.Equal(t, SecurityLevel128, large.SecurityLevel())
+	require.NoError(t, large.CheckSecurityLevel(SecurityLevel128))
+	require.Error(t, large.CheckSecurityLevel(SecurityLevel192))
+
+	require.Equal(t, SecurityLevel(0), (*PublicKey)(nil).SecurityLevel())
+	require.Error(t, (*PublicKey)(nil).CheckSecurityLevel(SecurityLevel112))
+}
+
+func TestNewKeysWithModulusBits(t *testing.T) {
+	_, _, err := NewKeysWithModulusBits(1024)
+	require.Error(t, err)
+	_, _, err = NewKeysWithModulusBits(3073)
+	require.Error(t, err)
+	_, _, err = NewKeysWithSecurityLevel(SecurityLevel(80))
+	require.Error(t, err)
+
+	if testing.Short() {
+		t.Skip("Skipping 3072-bit key generation")
+	}
+	pk, sk, err := NewKeysWithSecurityLevel(SecurityLevel128)
+	require.NoError(t, err)
+	require.Equal(t, 3072, pk.N.BitLen())
+	require.Equal(t, SecurityLevel128, pk.SecurityLevel())
+	c, _, err := pk.Encrypt(big.NewInt(7))
+	require.NoError(t, err)
+	m, err := sk.Decrypt(c)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), m.Int64())
+}
@@ -0,0 +1,400 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains threshold Paillier decryption with a trusted dealer, as in
+// Damgård and Jurik, A Generalisation, a Simplification and Some Applications of Paillier's
+// Probabilistic Public-Key System, §4 with s = 1 [DJ01], which follows Shoup's threshold RSA.
+// https://www.brics.dk/RS/00/45/BRICS-RS-00-45.pdf
+//
+// The dealer shares a decryption exponent d = 0 mod m and d = 1 mod N, where N = PQ for safe
+// primes P = 2p+1, Q = 2q+1 and m = pq. Any `threshold` of the `limit` parties can decrypt a
+// ciphertext by publishing decryption shares, each with a proof that it was computed with the
+// party's share of d. No party ever learns the factorization of N.
+
+package paillier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core"
+)
+
+// decryptionShareProofStatisticalBits is the statistical security of the zero-knowledge proofs of
+// decryption shares, which also bounds the size of their challenges.
+const decryptionShareProofStatisticalBits = 256
+
+type (
+	// ThresholdPublicKey is the public key of a threshold Paillier key: the Paillier public key that
+	// ciphertexts are encrypted under, and the verification keys of the decryption shares.
+	ThresholdPublicKey struct {
+		PublicKey
+		Threshold, Limit uint32
+		// V generates the squares of Z_N²*.
+		V *big.Int
+		// VerificationKeys are v_i = V^{Δ s_i} mod N², where s_i is the key share of the party
+		// with identifier i, at index i-1.
+		VerificationKeys []*big.Int
+	}
+
+	// ThresholdPublicKeyJson encapsulates the data that is serialized to JSON.
+	// It is used internally and not for external use. Public so other pieces
+	// can use for serialization.
+	ThresholdPublicKeyJson struct {
+		N                *big.Int
+		Threshold, Limit uint32
+		V                *big.Int
+		VerificationKeys []*big.Int
+	}
+
+	// ThresholdSecretKeyShare is the share s_i of the decryption exponent of party Id.
+	ThresholdSecretKeyShare struct {
+		Id    uint32
+		Share *big.Int
+	}
+
+	// DecryptionShare is a party's share c_i = c^{2Δ s_i} mod N² of the decryption of ciphertext c.
+	DecryptionShare struct {
+		Id    uint32
+		Value *big.Int
+		Proof *DecryptionShareProof
+	}
+
+	// DecryptionShareProof proves that log_{c⁴}(c_i²) = log_V(v_i), i.e. that a decryption share
+	// was computed with the key share behind the party's verification key.
+	DecryptionShareProof struct {
+		E, Z *big.Int
+	}
+)
+
+// NewThresholdKeys generates a threshold Paillier key with `PaillierPrimeBits` sized safe primes,
+// split among `limit` parties so that any `threshold` of them can decrypt.
+func NewThresholdKeys(threshold, limit uint32) (*ThresholdPublicKey, []*ThresholdSecretKeyShare, error) {
+	return NewThresholdKeysWithPrimeBits(threshold, limit, PaillierPrimeBits)
+}
+
+// NewThresholdKeysWithPrimeBits generates a threshold Paillier key with `bits` sized safe primes.
+func NewThresholdKeysWithPrimeBits(threshold, limit uint32, bits uint) (*ThresholdPublicKey, []*ThresholdSecretKeyShare, error) {
+	if err := checkThresholdParams(threshold, limit); err != nil {
+		return nil, nil, err
+	}
+	p, q, err := safePrimes(safePrimeGenerator(context.Background(), 0), bits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewThresholdSecretKeys(p, q, threshold, limit)
+}
+
+// NewThresholdSecretKeys is the trusted dealer: it shares the decryption exponent of the Paillier key
+// with safe primes p, q among `limit` parties, so that any `threshold` of them can decrypt.
+// The dealer must erase p, q and the returned shares once they are distributed.
+func NewThresholdSecretKeys(p, q *big.Int, threshold, limit uint32) (*ThresholdPublicKey, []*ThresholdSecretKeyShare, error) {
+	if p == nil || q == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if err := checkThresholdParams(threshold, limit); err != nil {
+		return nil, nil, err
+	}
+	if p.Cmp(q) == 0 {
+		return nil, nil, fmt.Errorf("p and q must be distinct")
+	}
+	// m = p'q' for P = 2p'+1, Q = 2q'+1
+	pPrime := new(big.Int).Rsh(p, 1)
+	qPrime := new(big.Int).Rsh(q, 1)
+	for _, prime := range []*big.Int{p, q, pPrime, qPrime} {
+		if !prime.ProbablyPrime(20) {
+			return nil, nil, fmt.Errorf("p and q must be safe primes")
+		}
+	}
+	if big.NewInt(int64(limit)).Cmp(pPrime) >= 0 || big.NewInt(int64(limit)).Cmp(qPrime) >= 0 {
+		return nil, nil, fmt.Errorf("too many parties for the primes")
+	}
+	m := new(big.Int).Mul(pPrime, qPrime)
+	pk, err := NewPubkey(new(big.Int).Mul(p, q))
+	if err != nil {
+		return nil, nil, err
+	}
+	// P = 2q'+1 would make Q a factor of m
+	if new(big.Int).GCD(nil, nil, pk.N, m).Cmp(core.One) != 0 {
+		return nil, nil, fmt.Errorf("N and m must be coprime")
+	}
+	nm := new(big.Int).Mul(pk.N, m)
+
+	// d = 0 mod m and d = 1 mod N
+	d, err := core.Inv(m, pk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	d.Mul(d, m)
+
+	// f(X) = d + a_1 X + ... + a_{t-1} X^{t-1} mod Nm
+	coefficients := make([]*big.Int, threshold)
+	coefficients[0] = d
+	for i := 1; i < len(coefficients); i++ {
+		if coefficients[i], err = core.Rand(nm); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// V = r² mod N² generates the squares with overwhelming probability
+	r, err := core.Rand(pk.N2)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(big.Int).Exp(r, two, pk.N2)
+
+	delta := factorial(limit)
+	tpk := &ThresholdPublicKey{
+		PublicKey:        *pk,
+		Threshold:        threshold,
+		Limit:            limit,
+		V:                v,
+		VerificationKeys: make([]*big.Int, limit),
+	}
+	shares := make([]*ThresholdSecretKeyShare, limit)
+	for i := range shares {
+		id := uint32(i + 1)
+		share := evaluatePolynomial(coefficients, id, nm)
+		shares[i] = &ThresholdSecretKeyShare{Id: id, Share: share}
+		tpk.VerificationKeys[i] = new(big.Int).Exp(v, new(big.Int).Mul(delta, share), pk.N2)
+	}
+	return tpk, shares, nil
+}
+
+func checkThresholdParams(threshold, limit uint32) error {
+	if limit < threshold {
+		return fmt.Errorf("limit cannot be less than threshold")
+	}
+	if threshold < 2 {
+		return fmt.Errorf("threshold cannot be less than 2")
+	}
+	return nil
+}
+
+// evaluatePolynomial computes f(x) mod m with Horner's rule.
+func evaluatePolynomial(coefficients []*big.Int, x uint32, m *big.Int) *big.Int {
+	bx := new(big.Int).SetUint64(uint64(x))
+	result := new(big.Int)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result.Mul(result, bx)
+		result.Add(result, coefficients[i])
+		result.Mod(result, m)
+	}
+	return result
+}
+
+// factorial computes Δ = n!.
+func factorial(n uint32) *big.Int {
+	return new(big.Int).MulRange(1, int64(n))
+}
+
+// DecryptShare computes the party's decryption share of ciphertext `c`, and proves its correctness.
+func (sk *ThresholdSecretKeyShare) DecryptShare(pk *ThresholdPublicKey, c Ciphertext) (*DecryptionShare, error) {
+	if sk == nil || sk.Share == nil || pk == nil || c == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if sk.Id == 0 || sk.Id > pk.Limit {
+		return nil, fmt.Errorf("invalid identifier %d", sk.Id)
+	}
+	if err := pk.checkCiphertext(c); err != nil {
+		return nil, err
+	}
+	// the exponent Δ s_i in c_i = c^{2Δ s_i} and v_i = V^{Δ s_i}
+	x := new(big.Int).Mul(factorial(pk.Limit), sk.Share)
+	value := new(big.Int).Exp(c, new(big.Int).Lsh(x, 1), pk.N2)
+
+	c4 := new(big.Int).Exp(c, big.NewInt(4), pk.N2)
+	ci2 := new(big.Int).Exp(value, two, pk.N2)
+
+	// r is large enough to statistically hide e.Δ.s_i in z
+	bound := new(big.Int).Lsh(core.One, uint(pk.N2.BitLen()+2*decryptionShareProofStatisticalBits))
+	r, err := core.Rand(bound)
+	if err != nil {
+		return nil, err
+	}
+	a := new(big.Int).Exp(c4, r, pk.N2)
+	b := new(big.Int).Exp(pk.V, r, pk.N2)
+	e, err := decryptionShareChallenge(pk, sk.Id, c4, ci2, a, b)
+	if err != nil {
+		return nil, err
+	}
+	// z = r + e.Δ.s_i over the integers
+	z := new(big.Int).Mul(e, x)
+	z.Add(z, r)
+	return &DecryptionShare{
+		Id:    sk.Id,
+		Value: value,
+		Proof: &DecryptionShareProof{E: e, Z: z},
+	}, nil
+}
+
+// VerifyDecryptionShare checks the proof of a decryption share of ciphertext `c`.
+func (pk *ThresholdPublicKey) VerifyDecryptionShare(c Ciphertext, share *DecryptionShare) error {
+	if c == nil || share == nil || share.Value == nil || share.Proof == nil ||
+		share.Proof.E == nil || share.Proof.Z == nil {
+		return internal.ErrNilArguments
+	}
+	if share.Id == 0 || share.Id > pk.Limit || int(pk.Limit) != len(pk.VerificationKeys) {
+		return fmt.Errorf("invalid identifier %d", share.Id)
+	}
+	if err := pk.checkCiphertext(c); err != nil {
+		return err
+	}
+	if err := pk.checkCiphertext(share.Value); err != nil {
+		return err
+	}
+	if share.Proof.E.Sign() < 0 || share.Proof.Z.Sign() < 0 {
+		return fmt.Errorf("invalid decryption share proof")
+	}
+	vi := pk.VerificationKeys[share.Id-1]
+	c4 := new(big.Int).Exp(c, big.NewInt(4), pk.N2)
+	ci2 := new(big.Int).Exp(share.Value, two, pk.N2)
+
+	// a = c⁴^z / c_i^{2e} and b = V^z / v_i^e
+	a, err := expDiv(c4, share.Proof.Z, ci2, share.Proof.E, pk.N2)
+	if err != nil {
+		return err
+	}
+	b, err := expDiv(pk.V, share.Proof.Z, vi, share.Proof.E, pk.N2)
+	if err != nil {
+		return err
+	}
+	e, err := decryptionShareChallenge(pk, share.Id, c4, ci2, a, b)
+	if err != nil {
+		return err
+	}
+	if e.Cmp(share.Proof.E) != 0 {
+		return fmt.Errorf("invalid decryption share proof")
+	}
+	return nil
+}
+
+// CombineDecryptionShares decrypts ciphertext `c` from the decryption shares of at least `Threshold`
+// parties. The shares whose proof does not verify are discarded, so decryption succeeds as long as
+// `Threshold` of the shares are correct.
+func (pk *ThresholdPublicKey) CombineDecryptionShares(c Ciphertext, shares []*DecryptionShare) (*big.Int, error) {
+	if c == nil {
+		return nil, internal.ErrNilArguments
+	}
+	valid := make([]*DecryptionShare, 0, pk.Threshold)
+	seen := make(map[uint32]bool, len(shares))
+	for _, share := range shares {
+		if uint32(len(valid)) == pk.Threshold {
+			break
+		}
+		if share == nil || seen[share.Id] || pk.VerifyDecryptionShare(c, share) != nil {
+			continue
+		}
+		seen[share.Id] = true
+		valid = append(valid, share)
+	}
+	if uint32(len(valid)) < pk.Threshold {
+		return nil, fmt.Errorf("need %d valid decryption shares, got %d", pk.Threshold, len(valid))
+	}
+
+	// c' = Π c_i^{2λ_i} = c^{4Δ²d} = (1+N)^{4Δ²M} mod N², with λ_i = Δ Π_{j≠i} j/(j-i) the
+	// Lagrange coefficients at 0 scaled by Δ to be integers
+	delta := factorial(pk.Limit)
+	combined := big.NewInt(1)
+	for _, share := range valid {
+		numerator := new(big.Int).Set(delta)
+		denominator := big.NewInt(1)
+		for _, other := range valid {
+			if other.Id == share.Id {
+				continue
+			}
+			numerator.Mul(numerator, big.NewInt(int64(other.Id)))
+			denominator.Mul(denominator, big.NewInt(int64(other.Id)-int64(share.Id)))
+		}
+		lambda := numerator.Quo(numerator, denominator)
+		term, err := expSigned(share.Value, lambda.Lsh(lambda, 1), pk.N2)
+		if err != nil {
+			return nil, err
+		}
+		combined.Mul(combined, term)
+		combined.Mod(combined, pk.N2)
+	}
+
+	// M = L(c') (4Δ²)^{-1} mod N
+	ell, err := pk.l(combined)
+	if err != nil {
+		return nil, err
+	}
+	scale := new(big.Int).Mul(delta, delta)
+	scale.Lsh(scale, 2)
+	scale, err = core.Inv(scale.Mod(scale, pk.N), pk.N)
+	if err != nil {
+		return nil, err
+	}
+	return core.Mul(ell, scale, pk.N)
+}
+
+// decryptionShareChallenge is the Fiat-Shamir challenge e of a decryption share proof.
+func decryptionShareChallenge(pk *ThresholdPublicKey, id uint32, c4, ci2, a, b *big.Int) (*big.Int, error) {
+	if int(id) > len(pk.VerificationKeys) || pk.V == nil {
+		return nil, internal.ErrNilArguments
+	}
+	digest, err := core.FiatShamir(pk.N, pk.V, pk.VerificationKeys[id-1], big.NewInt(int64(id)), c4, ci2, a, b)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(digest), nil
+}
+
+// expDiv computes x^y / u^w mod m.
+func expDiv(x, y, u, w, m *big.Int) (*big.Int, error) {
+	denominator, err := core.Inv(new(big.Int).Exp(u, w, m), m)
+	if err != nil {
+		return nil, err
+	}
+	return core.Mul(new(big.Int).Exp(x, y, m), denominator, m)
+}
+
+// expSigned computes x^y mod m for a possibly negative y.
+func expSigned(x, y, m *big.Int) (*big.Int, error) {
+	if y.Sign() >= 0 {
+		return new(big.Int).Exp(x, y, m), nil
+	}
+	inverse, err := core.Inv(x, m)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Exp(inverse, new(big.Int).Neg(y), m), nil
+}
+
+// MarshalJSON converts the threshold public key into json format.
+func (pk ThresholdPublicKey) MarshalJSON() ([]byte, error) {
+	data := ThresholdPublicKeyJson{pk.N, pk.Threshold, pk.Limit, pk.V, pk.VerificationKeys}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON converts the json data into this threshold public key.
+func (pk *ThresholdPublicKey) UnmarshalJSON(bytes []byte) error {
+	data := new(ThresholdPublicKeyJson)
+	if err := json.Unmarshal(bytes, data); err != nil {
+		return err
+	}
+	if data.N == nil || data.V == nil || len(data.VerificationKeys) != int(data.Limit) {
+		return internal.ErrInvalidJson
+	}
+	if err := checkThresholdParams(data.Threshold, data.Limit); err != nil {
+		return err
+	}
+	for _, vi := range data.VerificationKeys {
+		if vi == nil {
+			return internal.ErrInvalidJson
+		}
+	}
+	pk.N = data.N
+	pk.N2 = new(big.Int).Mul(data.N, data.N)
+	pk.Threshold = data.Threshold
+	pk.Limit = data.Limit
+	pk.V = data.V
+	pk.VerificationKeys = data.VerificationKeys
+	return nil
+}
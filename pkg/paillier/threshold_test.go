@@ -0,0 +1,178 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+)
+
+func newTestThresholdKeys(t *testing.T, threshold, limit uint32) (*ThresholdPublicKey, []*ThresholdSecretKeyShare) {
+	pk, shares, err := NewThresholdSecretKeys(testPrimes[1], testPrimes[2], threshold, limit)
+	require.NoError(t, err)
+	require.Len(t, shares, int(limit))
+	return pk, shares
+}
+
+func decryptionShares(t *testing.T, pk *ThresholdPublicKey, shares []*ThresholdSecretKeyShare, c Ciphertext) []*DecryptionShare {
+	result := make([]*DecryptionShare, len(shares))
+	for i, share := range shares {
+		var err error
+		result[i], err = share.DecryptShare(pk, c)
+		require.NoError(t, err)
+		require.NoError(t, pk.VerifyDecryptionShare(c, result[i]))
+	}
+	return result
+}
+
+func TestThresholdDecrypt(t *testing.T) {
+	pk, shares := newTestThresholdKeys(t, 3, 5)
+	sk, err := NewSecretKey(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	require.Equal(t, sk.N, pk.N)
+
+	for _, msg := range []*big.Int{big.NewInt(0), big.NewInt(1), internal.B10("123456789012345678901234567890"), new(big.Int).Sub(pk.N, big.NewInt(1))} {
+		c, _, err := pk.Encrypt(msg)
+		require.NoError(t, err)
+		decryptionShares := decryptionShares(t, pk, shares, c)
+
+		// any subset of threshold parties decrypts
+		for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+			selected := make([]*DecryptionShare, len(subset))
+			for i, j := range subset {
+				selected[i] = decryptionShares[j]
+			}
+			actual, err := pk.CombineDecryptionShares(c, selected)
+			require.NoError(t, err)
+			require.Equal(t, 0, msg.Cmp(actual))
+		}
+		actual, err := pk.CombineDecryptionShares(c, decryptionShares)
+		require.NoError(t, err)
+		require.Equal(t, 0, msg.Cmp(actual))
+		expected, err := sk.Decrypt(c)
+		require.NoError(t, err)
+		require.Equal(t, 0, expected.Cmp(actual))
+
+		_, err = pk.CombineDecryptionShares(c, decryptionShares[:2])
+		require.Error(t, err)
+	}
+}
+
+func TestThresholdDecryptHomomorphic(t *testing.T) {
+	pk, shares := newTestThresholdKeys(t, 2, 3)
+	c1, _, err := pk.Encrypt(big.NewInt(123))
+	require.NoError(t, err)
+	c2, _, err := pk.Encrypt(big.NewInt(456))
+	require.NoError(t, err)
+	sum, err := pk.Add(c1, c2)
+	require.NoError(t, err)
+	product, err := pk.Mul(big.NewInt(10), sum)
+	require.NoError(t, err)
+	actual, err := pk.CombineDecryptionShares(product, decryptionShares(t, pk, shares[1:], product))
+	require.NoError(t, err)
+	require.Equal(t, int64(5790), actual.Int64())
+}
+
+func TestThresholdDecryptInvalidShares(t *testing.T) {
+	pk, shares := newTestThresholdKeys(t, 3, 5)
+	c, _, err := pk.Encrypt(big.NewInt(42))
+	require.NoError(t, err)
+	decryptionShares := decryptionShares(t, pk, shares, c)
+
+	// a wrong value, a share for another ciphertext, or another party's identifier are all rejected
+	forged := *decryptionShares[0]
+	forged.Value = new(big.Int).Mul(forged.Value, big.NewInt(4))
+	forged.Value.Mod(forged.Value, pk.N2)
+	require.Error(t, pk.VerifyDecryptionShare(c, &forged))
+	other, _, err := pk.Encrypt(big.NewInt(43))
+	require.NoError(t, err)
+	require.Error(t, pk.VerifyDecryptionShare(other, decryptionShares[1]))
+	impersonated := *decryptionShares[1]
+	impersonated.Id = 3
+	require.Error(t, pk.VerifyDecryptionShare(c, &impersonated))
+	impersonated.Id = 6
+	require.Error(t, pk.VerifyDecryptionShare(c, &impersonated))
+	require.Error(t, pk.VerifyDecryptionShare(c, &DecryptionShare{Id: 1}))
+
+	// the invalid shares and the duplicates are discarded as long as there are enough valid ones
+	actual, err := pk.CombineDecryptionShares(c, []*DecryptionShare{&forged, decryptionShares[1], decryptionShares[1], nil, decryptionShares[2], decryptionShares[3]})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), actual.Int64())
+	_, err = pk.CombineDecryptionShares(c, []*DecryptionShare{&forged, decryptionShares[1], decryptionShares[1], decryptionShares[2]})
+	require.Error(t, err)
+
+	// the party's identifier must be in range and the ciphertext valid
+	_, err = (&ThresholdSecretKeyShare{Id: 6, Share: shares[0].Share}).DecryptShare(pk, c)
+	require.Error(t, err)
+	_, err = shares[0].DecryptShare(pk, pk.N2)
+	require.Error(t, err)
+	_, err = shares[0].DecryptShare(pk, pk.N)
+	require.Error(t, err)
+}
+
+func TestNewThresholdSecretKeysInvalidParams(t *testing.T) {
+	_, _, err := NewThresholdSecretKeys(testPrimes[1], testPrimes[2], 1, 3)
+	require.Error(t, err)
+	_, _, err = NewThresholdSecretKeys(testPrimes[1], testPrimes[2], 4, 3)
+	require.Error(t, err)
+	_, _, err = NewThresholdSecretKeys(testPrimes[1], testPrimes[1], 2, 3)
+	require.Error(t, err)
+	_, _, err = NewThresholdSecretKeys(testPrimes[1], nil, 2, 3)
+	require.Error(t, err)
+	// 7 and 11 are safe primes, but 13 is not
+	_, _, err = NewThresholdSecretKeys(big.NewInt(7), big.NewInt(13), 2, 3)
+	require.Error(t, err)
+	// there must be fewer parties than the factors of m
+	_, _, err = NewThresholdSecretKeys(big.NewInt(7), big.NewInt(11), 2, 3)
+	require.Error(t, err)
+	// 47 = 2*23 + 1 so 23 divides both N and m
+	_, _, err = NewThresholdSecretKeys(big.NewInt(23), big.NewInt(47), 2, 3)
+	require.Error(t, err)
+	_, _, err = NewThresholdSecretKeys(big.NewInt(23), big.NewInt(59), 2, 3)
+	require.NoError(t, err)
+}
+
+func TestNewThresholdKeysWithPrimeBits(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping TestNewThresholdKeysWithPrimeBits")
+	}
+	pk, shares, err := NewThresholdKeysWithPrimeBits(2, 3, 256)
+	require.NoError(t, err)
+	c, _, err := pk.Encrypt(big.NewInt(7))
+	require.NoError(t, err)
+	actual, err := pk.CombineDecryptionShares(c, decryptionShares(t, pk, shares[:2], c))
+	require.NoError(t, err)
+	require.Equal(t, int64(7), actual.Int64())
+}
+
+func TestThresholdPublicKeyJson(t *testing.T) {
+	pk, shares := newTestThresholdKeys(t, 2, 3)
+	data, err := json.Marshal(pk)
+	require.NoError(t, err)
+	decoded := new(ThresholdPublicKey)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.Equal(t, pk, decoded)
+
+	// decryption shares survive serialization too
+	c, _, err := decoded.Encrypt(big.NewInt(99))
+	require.NoError(t, err)
+	data, err = json.Marshal(decryptionShares(t, pk, shares, c))
+	require.NoError(t, err)
+	var decodedShares []*DecryptionShare
+	require.NoError(t, json.Unmarshal(data, &decodedShares))
+	actual, err := decoded.CombineDecryptionShares(c, decodedShares)
+	require.NoError(t, err)
+	require.Equal(t, int64(99), actual.Int64())
+
+	require.Error(t, json.Unmarshal([]byte(`{"N":15,"Threshold":2,"Limit":3,"V":4,"VerificationKeys":[1]}`), decoded))
+	require.Error(t, json.Unmarshal([]byte(`{"N":15,"Threshold":1,"Limit":1,"V":4,"VerificationKeys":[1]}`), decoded))
+}
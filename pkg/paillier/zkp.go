@@ -0,0 +1,447 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains non-interactive zero-knowledge proofs about Paillier ciphertexts:
+// knowledge of the plaintext and the nonce of a ciphertext, and that the plaintext lies in a range.
+// The range proof is the one of [GG20] appendix A.1, [MtA] Range1Proof, generalized to any bound.
+
+package paillier
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core"
+)
+
+const (
+	// ChallengeBits is the size of the Fiat-Shamir challenges of the proofs in this file.
+	ChallengeBits = 256
+	// StatisticalBits is the statistical security parameter for hiding the witnesses.
+	StatisticalBits = 128
+)
+
+type (
+	// PedersenParams are the parameters of the Pedersen-like commitments used by the range proof:
+	// an RSA modulus Ñ with unknown factorization and two elements H1, H2 of Z_Ñ* such that neither
+	// discrete logarithm log_H1(H2) nor log_H2(H1) is known to the prover.
+	// They must be generated by the verifier, or by a trusted party, and must not come from the prover.
+	PedersenParams struct {
+		N, H1, H2 *big.Int
+	}
+
+	// RangeProofParams contains the inputs to prove that a ciphertext encrypts a value in [0, Bound).
+	RangeProofParams struct {
+		PublicKey  *PublicKey
+		Pedersen   *PedersenParams
+		Bound      *big.Int
+		Context    []byte // Binds the proof to a session, e.g. a session id and the identity of the prover.
+		Ciphertext Ciphertext
+		Plaintext  *big.Int
+		Nonce      *big.Int
+	}
+
+	// RangeVerifyParams contains the inputs to verify a RangeProof.
+	RangeVerifyParams struct {
+		PublicKey  *PublicKey
+		Pedersen   *PedersenParams
+		Bound      *big.Int
+		Context    []byte
+		Ciphertext Ciphertext
+	}
+
+	// RangeProof proves that a ciphertext C = (N+1)^m r^N mod N² was formed correctly
+	// and that its plaintext m is small. Because of the slack of the proof,
+	// a verified proof for the bound B only guarantees that -B·2^(ChallengeBits+StatisticalBits+1) < m < B·2^(ChallengeBits+StatisticalBits+1),
+	// although an honest prover can only prove 0 ≤ m < B.
+	RangeProof struct {
+		Z, E, S, S1, S2 *big.Int
+	}
+
+	// EncryptionProofParams contains the inputs to prove the knowledge of the plaintext and the nonce of a ciphertext.
+	EncryptionProofParams struct {
+		PublicKey  *PublicKey
+		Context    []byte
+		Ciphertext Ciphertext
+		Plaintext  *big.Int
+		Nonce      *big.Int
+	}
+
+	// EncryptionVerifyParams contains the inputs to verify an EncryptionProof.
+	EncryptionVerifyParams struct {
+		PublicKey  *PublicKey
+		Context    []byte
+		Ciphertext Ciphertext
+	}
+
+	// EncryptionProof proves that the prover knows m ∈ Z_N and r ∈ Z_N* such that C = (N+1)^m r^N mod N².
+	// Unlike a RangeProof, it says nothing about the size of m.
+	EncryptionProof struct {
+		U, Z1, Z2 *big.Int
+	}
+)
+
+// NewPedersenParams generates Pedersen parameters with a modulus that is the product of two
+// PaillierPrimeBits sized safe primes.
+func NewPedersenParams() (*PedersenParams, error) {
+	p, q, err := safePrimes(safePrimeGenerator(context.Background(), 0), PaillierPrimeBits)
+	if err != nil {
+		return nil, err
+	}
+	return NewPedersenParamsWithPrimes(p, q)
+}
+
+// NewPedersenParamsWithPrimes generates Pedersen parameters for the modulus Ñ = pq with the safe primes p and q,
+// which must then be discarded: H1 = f² for a random f ∈ Z_Ñ* and H2 = H1^ɑ for a random ɑ ∈ Z_p'q',
+// so both generate the same subgroup of quadratic residues.
+func NewPedersenParamsWithPrimes(p, q *big.Int) (*PedersenParams, error) {
+	if p == nil || q == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if p.Cmp(q) == 0 {
+		return nil, fmt.Errorf("p and q must be distinct")
+	}
+	p1 := new(big.Int).Rsh(p, 1)
+	q1 := new(big.Int).Rsh(q, 1)
+	if !p.ProbablyPrime(20) || !q.ProbablyPrime(20) || !p1.ProbablyPrime(20) || !q1.ProbablyPrime(20) {
+		return nil, fmt.Errorf("p and q must be safe primes")
+	}
+	n := new(big.Int).Mul(p, q)
+	order := new(big.Int).Mul(p1, q1)
+
+	pp := &PedersenParams{N: n}
+	// Resample in the negligible case that the parameters are degenerate
+	for pp.check() != nil {
+		f, err := randUnit(n)
+		if err != nil {
+			return nil, err
+		}
+		ɑ, err := core.Rand(order)
+		if err != nil {
+			return nil, err
+		}
+		pp.H1 = new(big.Int).Exp(f, two, n)
+		pp.H2 = new(big.Int).Exp(pp.H1, ɑ, n)
+	}
+	return pp, nil
+}
+
+// check ensures that the parameters are usable: H1 and H2 are distinct elements of Z_Ñ* other than 1.
+// It cannot check that the discrete logarithms are unknown to the prover.
+func (pp *PedersenParams) check() error {
+	if pp == nil || core.AnyNil(pp.N, pp.H1, pp.H2) {
+		return internal.ErrNilArguments
+	}
+	if pp.N.Sign() != 1 {
+		return fmt.Errorf("invalid Pedersen modulus")
+	}
+	for _, h := range []*big.Int{pp.H1, pp.H2} {
+		if err := core.In(h, pp.N); err != nil {
+			return err
+		}
+		if h.Cmp(core.One) != 1 || new(big.Int).GCD(nil, nil, h, pp.N).Cmp(core.One) != 0 {
+			return fmt.Errorf("invalid Pedersen generator")
+		}
+	}
+	if pp.H1.Cmp(pp.H2) == 0 {
+		return fmt.Errorf("generators H1 and H2 must be distinct")
+	}
+	return nil
+}
+
+// rangeBounds returns the bound on ɑ, B·2^(ChallengeBits+StatisticalBits), and the bound on s1, twice that.
+// It fails if N is too small for the proof to show anything about the plaintext.
+func rangeBounds(pk *PublicKey, bound *big.Int) (*big.Int, *big.Int, error) {
+	if bound.Sign() != 1 {
+		return nil, nil, fmt.Errorf("range bound must be positive")
+	}
+	ɑBound := new(big.Int).Lsh(bound, ChallengeBits+StatisticalBits)
+	s1Bound := new(big.Int).Lsh(ɑBound, 1)
+	// The plaintext recovered from two accepting proofs is within ±s1Bound, which must not wrap around N
+	if new(big.Int).Lsh(s1Bound, 1).Cmp(pk.N) != -1 {
+		return nil, nil, fmt.Errorf("range bound is too large for the Paillier modulus")
+	}
+	return ɑBound, s1Bound, nil
+}
+
+// Prove that a ciphertext encrypts a plaintext in [0, Bound) under the Paillier public key.
+// [GG20] fig 10 with q³ replaced by B·2^(ChallengeBits+StatisticalBits)
+func (p *RangeProofParams) Prove() (*RangeProof, error) {
+	if p == nil ||
+		p.PublicKey == nil ||
+		core.AnyNil(p.PublicKey.N, p.PublicKey.N2, p.Bound, p.Ciphertext, p.Plaintext, p.Nonce) {
+		return nil, internal.ErrNilArguments
+	}
+	if err := p.Pedersen.check(); err != nil {
+		return nil, err
+	}
+	pk, pp := p.PublicKey, p.Pedersen
+	ɑBound, _, err := rangeBounds(pk, p.Bound)
+	if err != nil {
+		return nil, err
+	}
+	if err = core.In(p.Plaintext, p.Bound); err != nil {
+		return nil, fmt.Errorf("plaintext is out of range")
+	}
+	if err = pk.checkCiphertext(p.Ciphertext); err != nil {
+		return nil, err
+	}
+	if c, err := pk.encrypt(p.Plaintext, p.Nonce); err != nil || !core.ConstantTimeEq(c, p.Ciphertext) {
+		return nil, fmt.Errorf("plaintext and nonce do not match the ciphertext")
+	}
+
+	// ɑ ∈ Z_B·2^(ChallengeBits+StatisticalBits)
+	ɑ, err := core.Rand(ɑBound)
+	if err != nil {
+		return nil, err
+	}
+	// β ∈ Z_N*
+	β, err := randUnit(pk.N)
+	if err != nil {
+		return nil, err
+	}
+	// γ ∈ Z_B·2^(ChallengeBits+StatisticalBits)·Ñ
+	γ, err := core.Rand(new(big.Int).Mul(ɑBound, pp.N))
+	if err != nil {
+		return nil, err
+	}
+	// ρ ∈ Z_B·Ñ
+	ρ, err := core.Rand(new(big.Int).Mul(p.Bound, pp.N))
+	if err != nil {
+		return nil, err
+	}
+
+	// z = h1^m h2^ρ mod Ñ
+	z, err := pedersen(pp, p.Plaintext, ρ)
+	if err != nil {
+		return nil, err
+	}
+	// u = (N+1)^ɑ β^N mod N²
+	u, err := pk.encryptWithMask(new(big.Int).Mod(ɑ, pk.N), new(big.Int).Exp(β, pk.N, pk.N2))
+	if err != nil {
+		return nil, err
+	}
+	// w = h1^ɑ h2^γ mod Ñ
+	w, err := pedersen(pp, ɑ, γ)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := rangeChallenge(pk, pp, p.Bound, p.Context, p.Ciphertext, z, u, w)
+	if err != nil {
+		return nil, err
+	}
+
+	// s = r^e β mod N
+	s, err := core.Mul(new(big.Int).Exp(p.Nonce, e, pk.N), β, pk.N)
+	if err != nil {
+		return nil, err
+	}
+	// s1 = e·m + ɑ
+	s1 := new(big.Int).Mul(e, p.Plaintext)
+	s1.Add(s1, ɑ)
+	// s2 = e·ρ + γ
+	s2 := new(big.Int).Mul(e, ρ)
+	s2.Add(s2, γ)
+
+	return &RangeProof{Z: z, E: e, S: s, S1: s1, S2: s2}, nil
+}
+
+// Verify that the ciphertext encrypts a small plaintext, see RangeProof for the exact guarantee.
+// [GG20] fig 10
+func (proof RangeProof) Verify(p *RangeVerifyParams) error {
+	if p == nil ||
+		p.PublicKey == nil ||
+		core.AnyNil(p.PublicKey.N, p.PublicKey.N2, p.Bound, p.Ciphertext, proof.Z, proof.E, proof.S, proof.S1, proof.S2) {
+		return internal.ErrNilArguments
+	}
+	if err := p.Pedersen.check(); err != nil {
+		return err
+	}
+	pk, pp := p.PublicKey, p.Pedersen
+	_, s1Bound, err := rangeBounds(pk, p.Bound)
+	if err != nil {
+		return err
+	}
+	if err = pk.checkCiphertext(p.Ciphertext); err != nil {
+		return err
+	}
+
+	// s1 ∈ [0, 2·B·2^(ChallengeBits+StatisticalBits)), and s2 ≥ 0
+	if err = core.In(proof.S1, s1Bound); err != nil {
+		return fmt.Errorf("s1 is out of range")
+	}
+	if proof.S2.Sign() < 0 {
+		return fmt.Errorf("s2 is out of range")
+	}
+	if err = core.In(proof.E, new(big.Int).Lsh(core.One, ChallengeBits)); err != nil {
+		return fmt.Errorf("e is out of range")
+	}
+	// s ∈ Z_N* and z ∈ Z_Ñ*
+	if err = core.In(proof.S, pk.N); err != nil || new(big.Int).GCD(nil, nil, proof.S, pk.N).Cmp(core.One) != 0 {
+		return fmt.Errorf("s is not in Z_N*")
+	}
+	if err = core.In(proof.Z, pp.N); err != nil || new(big.Int).GCD(nil, nil, proof.Z, pp.N).Cmp(core.One) != 0 {
+		return fmt.Errorf("z is not in Z_Ñ*")
+	}
+
+	// u = (N+1)^s1 s^N c^-e mod N²
+	mask, err := expDiv(proof.S, pk.N, p.Ciphertext, proof.E, pk.N2)
+	if err != nil {
+		return err
+	}
+	u, err := pk.encryptWithMask(new(big.Int).Mod(proof.S1, pk.N), mask)
+	if err != nil {
+		return err
+	}
+	// w = h1^s1 h2^s2 z^-e mod Ñ
+	w, err := pedersen(pp, proof.S1, proof.S2)
+	if err != nil {
+		return err
+	}
+	zInvE, err := core.Inv(new(big.Int).Exp(proof.Z, proof.E, pp.N), pp.N)
+	if err != nil {
+		return err
+	}
+	if w, err = core.Mul(w, zInvE, pp.N); err != nil {
+		return err
+	}
+
+	e, err := rangeChallenge(pk, pp, p.Bound, p.Context, p.Ciphertext, proof.Z, u, w)
+	if err != nil {
+		return err
+	}
+	if !core.ConstantTimeEq(e, proof.E) {
+		return fmt.Errorf("invalid range proof")
+	}
+	return nil
+}
+
+// Prove the knowledge of the plaintext and the nonce of a ciphertext.
+func (p *EncryptionProofParams) Prove() (*EncryptionProof, error) {
+	if p == nil ||
+		p.PublicKey == nil ||
+		core.AnyNil(p.PublicKey.N, p.PublicKey.N2, p.Ciphertext, p.Plaintext, p.Nonce) {
+		return nil, internal.ErrNilArguments
+	}
+	pk := p.PublicKey
+	if err := pk.checkCiphertext(p.Ciphertext); err != nil {
+		return nil, err
+	}
+	if c, err := pk.encrypt(p.Plaintext, p.Nonce); err != nil || !core.ConstantTimeEq(c, p.Ciphertext) {
+		return nil, fmt.Errorf("plaintext and nonce do not match the ciphertext")
+	}
+
+	// u = (N+1)^ɑ β^N mod N² for ɑ ∈ Z_N and β ∈ Z_N*
+	ɑ, err := core.Rand(pk.N)
+	if err != nil {
+		return nil, err
+	}
+	β, err := randUnit(pk.N)
+	if err != nil {
+		return nil, err
+	}
+	u, err := pk.encrypt(ɑ, β)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := encryptionChallenge(pk, p.Context, p.Ciphertext, u)
+	if err != nil {
+		return nil, err
+	}
+
+	// z1 = ɑ + e·m mod N
+	z1 := new(big.Int).Mul(e, p.Plaintext)
+	z1.Add(z1, ɑ)
+	z1.Mod(z1, pk.N)
+	// z2 = β r^e mod N
+	z2, err := core.Mul(new(big.Int).Exp(p.Nonce, e, pk.N), β, pk.N)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptionProof{U: u, Z1: z1, Z2: z2}, nil
+}
+
+// Verify that the prover knows the plaintext and the nonce of the ciphertext:
+// (N+1)^z1 z2^N = u c^e mod N².
+func (proof EncryptionProof) Verify(p *EncryptionVerifyParams) error {
+	if p == nil ||
+		p.PublicKey == nil ||
+		core.AnyNil(p.PublicKey.N, p.PublicKey.N2, p.Ciphertext, proof.U, proof.Z1, proof.Z2) {
+		return internal.ErrNilArguments
+	}
+	pk := p.PublicKey
+	if err := pk.checkCiphertext(p.Ciphertext); err != nil {
+		return err
+	}
+	if err := pk.checkCiphertext(proof.U); err != nil {
+		return err
+	}
+	if err := core.In(proof.Z1, pk.N); err != nil {
+		return fmt.Errorf("z1 is out of range")
+	}
+	if err := core.In(proof.Z2, pk.N); err != nil || new(big.Int).GCD(nil, nil, proof.Z2, pk.N).Cmp(core.One) != 0 {
+		return fmt.Errorf("z2 is not in Z_N*")
+	}
+
+	e, err := encryptionChallenge(pk, p.Context, p.Ciphertext, proof.U)
+	if err != nil {
+		return err
+	}
+	lhs, err := pk.encrypt(proof.Z1, proof.Z2)
+	if err != nil {
+		return err
+	}
+	rhs, err := core.Mul(proof.U, new(big.Int).Exp(p.Ciphertext, e, pk.N2), pk.N2)
+	if err != nil {
+		return err
+	}
+	if !core.ConstantTimeEq(lhs, rhs) {
+		return fmt.Errorf("invalid encryption proof")
+	}
+	return nil
+}
+
+// pedersen computes h1^x h2^y mod Ñ.
+func pedersen(pp *PedersenParams, x, y *big.Int) (*big.Int, error) {
+	return core.Mul(new(big.Int).Exp(pp.H1, x, pp.N), new(big.Int).Exp(pp.H2, y, pp.N), pp.N)
+}
+
+// randUnit samples a random element of Z_n*.
+func randUnit(n *big.Int) (*big.Int, error) {
+	for {
+		r, err := core.Rand(n)
+		if err != nil {
+			return nil, err
+		}
+		if new(big.Int).GCD(nil, nil, r, n).Cmp(core.One) == 0 {
+			return r, nil
+		}
+	}
+}
+
+// rangeChallenge is the Fiat-Shamir challenge e of a range proof.
+func rangeChallenge(pk *PublicKey, pp *PedersenParams, bound *big.Int, context []byte, c, z, u, w *big.Int) (*big.Int, error) {
+	digest, err := core.FiatShamir(
+		big.NewInt(int64(len(context))), new(big.Int).SetBytes(context),
+		pk.N, pp.N, pp.H1, pp.H2, bound, c, z, u, w,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(digest), nil
+}
+
+// encryptionChallenge is the Fiat-Shamir challenge e of an encryption proof.
+func encryptionChallenge(pk *PublicKey, context []byte, c, u *big.Int) (*big.Int, error) {
+	digest, err := core.FiatShamir(big.NewInt(int64(len(context))), new(big.Int).SetBytes(context), pk.N, c, u)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(digest), nil
+}
@@ -0,0 +1,189 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core"
+)
+
+func newTestProofKeys(t *testing.T) (*SecretKey, *PedersenParams) {
+	sk, err := NewSecretKey(testPrimes[1], testPrimes[2])
+	require.NoError(t, err)
+	pp, err := NewPedersenParamsWithPrimes(testPrimes[3], testPrimes[4])
+	require.NoError(t, err)
+	return sk, pp
+}
+
+func TestRangeProof(t *testing.T) {
+	sk, pp := newTestProofKeys(t)
+	pk := &sk.PublicKey
+	bound := new(big.Int).Lsh(core.One, 256)
+
+	for _, m := range []*big.Int{big.NewInt(0), big.NewInt(1), new(big.Int).Sub(bound, core.One)} {
+		c, r, err := pk.Encrypt(m)
+		require.NoError(t, err)
+		proof, err := (&RangeProofParams{pk, pp, bound, []byte("session"), c, m, r}).Prove()
+		require.NoError(t, err)
+		require.NoError(t, proof.Verify(&RangeVerifyParams{pk, pp, bound, []byte("session"), c}))
+	}
+}
+
+func TestRangeProofInvalid(t *testing.T) {
+	sk, pp := newTestProofKeys(t)
+	pk := &sk.PublicKey
+	bound := new(big.Int).Lsh(core.One, 256)
+	m := big.NewInt(1234)
+	c, r, err := pk.Encrypt(m)
+	require.NoError(t, err)
+	proof, err := (&RangeProofParams{pk, pp, bound, []byte("session"), c, m, r}).Prove()
+	require.NoError(t, err)
+
+	// the proof is bound to the context, the ciphertext, the bound and the commitment parameters
+	require.Error(t, proof.Verify(&RangeVerifyParams{pk, pp, bound, []byte("other session"), c}))
+	other, _, err := pk.Encrypt(m)
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(&RangeVerifyParams{pk, pp, bound, []byte("session"), other}))
+	require.Error(t, proof.Verify(&RangeVerifyParams{pk, pp, new(big.Int).Lsh(core.One, 128), []byte("session"), c}))
+	require.Error(t, proof.Verify(&RangeVerifyParams{pk, &PedersenParams{pp.N, pp.H2, pp.H1}, bound, []byte("session"), c}))
+	require.Error(t, proof.Verify(&RangeVerifyParams{pk, &PedersenParams{pp.N, pp.H1, pp.H1}, bound, []byte("session"), c}))
+	require.Error(t, proof.Verify(nil))
+
+	// every value of the proof matters
+	for _, tamper := range []func(p *RangeProof){
+		func(p *RangeProof) { p.Z = new(big.Int).Add(p.Z, core.One) },
+		func(p *RangeProof) { p.E = new(big.Int).Add(p.E, core.One) },
+		func(p *RangeProof) { p.S = new(big.Int).Add(p.S, core.One) },
+		func(p *RangeProof) { p.S1 = new(big.Int).Add(p.S1, core.One) },
+		func(p *RangeProof) { p.S2 = new(big.Int).Add(p.S2, core.One) },
+		func(p *RangeProof) { p.S1 = new(big.Int).Lsh(bound, ChallengeBits+StatisticalBits+1) },
+		func(p *RangeProof) { p.S2 = new(big.Int).Neg(p.S2) },
+		func(p *RangeProof) { p.S = pk.N },
+		func(p *RangeProof) { p.Z = nil },
+	} {
+		forged := *proof
+		tamper(&forged)
+		require.Error(t, forged.Verify(&RangeVerifyParams{pk, pp, bound, []byte("session"), c}))
+	}
+}
+
+func TestRangeProofInvalidParams(t *testing.T) {
+	sk, pp := newTestProofKeys(t)
+	pk := &sk.PublicKey
+	bound := new(big.Int).Lsh(core.One, 256)
+	m := new(big.Int).Add(bound, core.One)
+	c, r, err := pk.Encrypt(m)
+	require.NoError(t, err)
+
+	// an honest prover cannot prove an out of range plaintext
+	_, err = (&RangeProofParams{pk, pp, bound, nil, c, m, r}).Prove()
+	require.Error(t, err)
+	_, err = (&RangeProofParams{pk, pp, bound, nil, c, big.NewInt(5), r}).Prove()
+	require.Error(t, err)
+	_, err = (&RangeProofParams{pk, pp, bound, nil, c, m, new(big.Int).Add(r, core.One)}).Prove()
+	require.Error(t, err)
+	// the bound must leave room for the slack of the proof
+	_, err = (&RangeProofParams{pk, pp, new(big.Int).Rsh(pk.N, ChallengeBits+StatisticalBits), nil, c, m, r}).Prove()
+	require.Error(t, err)
+	_, err = (&RangeProofParams{pk, pp, big.NewInt(0), nil, c, big.NewInt(0), r}).Prove()
+	require.Error(t, err)
+	_, err = (&RangeProofParams{pk, nil, bound, nil, c, m, r}).Prove()
+	require.Error(t, err)
+	_, err = (&RangeProofParams{pk, &PedersenParams{pp.N, core.One, pp.H2}, bound, nil, c, m, r}).Prove()
+	require.Error(t, err)
+	_, err = (&RangeProofParams{pk, pp, bound, nil, pk.N, m, r}).Prove()
+	require.Error(t, err)
+}
+
+func TestEncryptionProof(t *testing.T) {
+	sk, _ := newTestProofKeys(t)
+	pk := &sk.PublicKey
+
+	for _, m := range []*big.Int{big.NewInt(0), big.NewInt(42), new(big.Int).Sub(pk.N, core.One)} {
+		c, r, err := pk.Encrypt(m)
+		require.NoError(t, err)
+		proof, err := (&EncryptionProofParams{pk, []byte("session"), c, m, r}).Prove()
+		require.NoError(t, err)
+		require.NoError(t, proof.Verify(&EncryptionVerifyParams{pk, []byte("session"), c}))
+
+		require.Error(t, proof.Verify(&EncryptionVerifyParams{pk, []byte("other session"), c}))
+		require.Error(t, proof.Verify(&EncryptionVerifyParams{pk, nil, c}))
+		other, err := pk.Add(c, c)
+		require.NoError(t, err)
+		require.Error(t, proof.Verify(&EncryptionVerifyParams{pk, []byte("session"), other}))
+
+		for _, tamper := range []func(p *EncryptionProof){
+			func(p *EncryptionProof) { p.U = new(big.Int).Add(p.U, core.One) },
+			func(p *EncryptionProof) { p.Z1 = new(big.Int).Add(p.Z1, core.One) },
+			func(p *EncryptionProof) { p.Z2 = new(big.Int).Add(p.Z2, core.One) },
+			func(p *EncryptionProof) { p.Z1 = new(big.Int).Add(p.Z1, pk.N) },
+			func(p *EncryptionProof) { p.U = pk.N },
+		} {
+			forged := *proof
+			tamper(&forged)
+			require.Error(t, forged.Verify(&EncryptionVerifyParams{pk, []byte("session"), c}))
+		}
+	}
+
+	// the prover must know the plaintext and the nonce
+	c, r, err := pk.Encrypt(big.NewInt(7))
+	require.NoError(t, err)
+	_, err = (&EncryptionProofParams{pk, nil, c, big.NewInt(8), r}).Prove()
+	require.Error(t, err)
+	_, err = (&EncryptionProofParams{pk, nil, c, big.NewInt(7), nil}).Prove()
+	require.Error(t, err)
+}
+
+func TestProofJson(t *testing.T) {
+	sk, pp := newTestProofKeys(t)
+	pk := &sk.PublicKey
+	bound := new(big.Int).Lsh(core.One, 256)
+	m := big.NewInt(99)
+	c, r, err := pk.Encrypt(m)
+	require.NoError(t, err)
+
+	rangeProof, err := (&RangeProofParams{pk, pp, bound, nil, c, m, r}).Prove()
+	require.NoError(t, err)
+	data, err := json.Marshal(rangeProof)
+	require.NoError(t, err)
+	decodedRangeProof := new(RangeProof)
+	require.NoError(t, json.Unmarshal(data, decodedRangeProof))
+	require.NoError(t, decodedRangeProof.Verify(&RangeVerifyParams{pk, pp, bound, nil, c}))
+
+	encryptionProof, err := (&EncryptionProofParams{pk, nil, c, m, r}).Prove()
+	require.NoError(t, err)
+	data, err = json.Marshal(encryptionProof)
+	require.NoError(t, err)
+	decodedEncryptionProof := new(EncryptionProof)
+	require.NoError(t, json.Unmarshal(data, decodedEncryptionProof))
+	require.NoError(t, decodedEncryptionProof.Verify(&EncryptionVerifyParams{pk, nil, c}))
+
+	data, err = json.Marshal(pp)
+	require.NoError(t, err)
+	decodedParams := new(PedersenParams)
+	require.NoError(t, json.Unmarshal(data, decodedParams))
+	require.Equal(t, pp, decodedParams)
+}
+
+func TestNewPedersenParamsWithPrimes(t *testing.T) {
+	pp, err := NewPedersenParamsWithPrimes(big.NewInt(23), big.NewInt(47))
+	require.NoError(t, err)
+	require.Equal(t, int64(23*47), pp.N.Int64())
+	require.NoError(t, pp.check())
+
+	_, err = NewPedersenParamsWithPrimes(big.NewInt(23), big.NewInt(23))
+	require.Error(t, err)
+	_, err = NewPedersenParamsWithPrimes(big.NewInt(23), big.NewInt(13))
+	require.Error(t, err)
+	_, err = NewPedersenParamsWithPrimes(nil, big.NewInt(23))
+	require.Error(t, err)
+}
@@ -0,0 +1,381 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// maxAccessChildren is the maximum number of children of an access structure node,
+// as for the shares of Shamir
+const maxAccessChildren = 255
+
+// AccessNode is a node of a monotone access structure. A leaf names a party, an inner
+// node is satisfied when at least Threshold of its children are satisfied. AND and OR
+// nodes are the thresholds len(Children) and 1. For example
+//
+//	Or(And(Party("CFO"), Threshold(2, Party("eng1"), Party("eng2"), Party("eng3"))),
+//		Threshold(3, Party("dir1"), Party("dir2"), Party("dir3"), Party("dir4")))
+//
+// lets the CFO with any two engineers, or any three directors, recover the secret.
+// A party may appear in several leaves, in which case it holds a share for each of them
+type AccessNode struct {
+	Party     string        `json:"party,omitempty"`
+	Threshold uint32        `json:"threshold,omitempty"`
+	Children  []*AccessNode `json:"children,omitempty"`
+}
+
+// Party returns a leaf for the named party
+func Party(name string) *AccessNode {
+	return &AccessNode{Party: name}
+}
+
+// And returns a node that requires all of its children
+func And(children ...*AccessNode) *AccessNode {
+	return &AccessNode{Threshold: uint32(len(children)), Children: children}
+}
+
+// Or returns a node that requires any one of its children
+func Or(children ...*AccessNode) *AccessNode {
+	return &AccessNode{Threshold: 1, Children: children}
+}
+
+// Threshold returns a node that requires any `threshold` of its children
+func Threshold(threshold uint32, children ...*AccessNode) *AccessNode {
+	return &AccessNode{Threshold: threshold, Children: children}
+}
+
+// IsLeaf reports whether the node names a party
+func (n AccessNode) IsLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// Validate checks that the tree is well formed
+func (n AccessNode) Validate() error {
+	if n.IsLeaf() {
+		if n.Party == "" {
+			return fmt.Errorf("leaf without a party")
+		}
+		if n.Threshold != 0 {
+			return fmt.Errorf("leaf %s cannot have a threshold", n.Party)
+		}
+		return nil
+	}
+	if n.Party != "" {
+		return fmt.Errorf("inner node cannot name party %s", n.Party)
+	}
+	if len(n.Children) > maxAccessChildren {
+		return fmt.Errorf("cannot exceed %d children", maxAccessChildren)
+	}
+	if n.Threshold < 1 || n.Threshold > uint32(len(n.Children)) {
+		return fmt.Errorf("threshold %d is invalid for %d children", n.Threshold, len(n.Children))
+	}
+	for _, child := range n.Children {
+		if child == nil {
+			return fmt.Errorf("nil child")
+		}
+		if err := child.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Satisfied reports whether the parties are authorized by the tree
+func (n AccessNode) Satisfied(parties ...string) bool {
+	set := make(map[string]bool, len(parties))
+	for _, p := range parties {
+		set[p] = true
+	}
+	return n.satisfied(set)
+}
+
+func (n AccessNode) satisfied(parties map[string]bool) bool {
+	if n.IsLeaf() {
+		return parties[n.Party]
+	}
+	count := uint32(0)
+	for _, child := range n.Children {
+		if child.satisfied(parties) {
+			count++
+		}
+	}
+	return count >= n.Threshold
+}
+
+// Parties returns the distinct parties of the tree in the order they first appear
+func (n AccessNode) Parties() []string {
+	var out []string
+	seen := make(map[string]bool)
+	n.walk(nil, func(path []uint32, leaf *AccessNode) {
+		if !seen[leaf.Party] {
+			seen[leaf.Party] = true
+			out = append(out, leaf.Party)
+		}
+	})
+	return out
+}
+
+// walk calls fn for every leaf below n with the 1-based child indices leading to it
+func (n *AccessNode) walk(path []uint32, fn func(path []uint32, leaf *AccessNode)) {
+	if n.IsLeaf() {
+		fn(path, n)
+		return
+	}
+	for i, child := range n.Children {
+		childPath := append(append(make([]uint32, 0, len(path)+1), path...), uint32(i+1))
+		child.walk(childPath, fn)
+	}
+}
+
+// String formats the tree as an expression such as "(CFO AND 2 of (eng1, eng2, eng3)) OR 3 of (dir1, dir2, dir3, dir4)"
+func (n AccessNode) String() string {
+	return n.format(true)
+}
+
+func (n AccessNode) format(top bool) string {
+	if n.IsLeaf() {
+		return n.Party
+	}
+	children := make([]string, len(n.Children))
+	for i, child := range n.Children {
+		children[i] = child.format(false)
+	}
+	var out string
+	switch {
+	case len(n.Children) > 1 && n.Threshold == uint32(len(n.Children)):
+		out = strings.Join(children, " AND ")
+	case len(n.Children) > 1 && n.Threshold == 1:
+		out = strings.Join(children, " OR ")
+	default:
+		return fmt.Sprintf("%d of (%s)", n.Threshold, strings.Join(children, ", "))
+	}
+	if top {
+		return out
+	}
+	return "(" + out + ")"
+}
+
+// AccessShare is the share of the leaf at Path, the 1-based indices of the children from
+// the root to the leaf, which is held by Party
+type AccessShare struct {
+	Party string   `json:"party"`
+	Path  []uint32 `json:"path"`
+	Value []byte   `json:"value"`
+}
+
+// AccessVerifier holds the Feldman commitments to the polynomial of every inner node of
+// an access structure, in the shape of the tree. Leaves have no commitments
+type AccessVerifier struct {
+	Commitments []curves.Point    `json:"commitments,omitempty"`
+	Children    []*AccessVerifier `json:"children,omitempty"`
+}
+
+// PublicKey returns the commitment to the shared secret
+func (v AccessVerifier) PublicKey() curves.Point {
+	return v.Commitments[0]
+}
+
+// AccessStructure shares secrets among the parties of a monotone access structure by
+// sharing the secret of every inner node with Shamir among its children, as in
+// Benaloh-Leichter. A set of parties can recover the secret iff it satisfies the tree
+type AccessStructure struct {
+	root  *AccessNode
+	curve *curves.Curve
+}
+
+// NewAccessStructure creates an access structure for `root` over `curve`
+func NewAccessStructure(root *AccessNode, curve *curves.Curve) (*AccessStructure, error) {
+	if root == nil {
+		return nil, fmt.Errorf("invalid access structure")
+	}
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	if err := root.Validate(); err != nil {
+		return nil, err
+	}
+	if root.IsLeaf() {
+		return nil, fmt.Errorf("access structure needs more than one party")
+	}
+	return &AccessStructure{root, curve}, nil
+}
+
+// Root returns the tree of the access structure
+func (a AccessStructure) Root() *AccessNode {
+	return a.root
+}
+
+// Split deals a share of `secret` for every leaf of the tree
+func (a AccessStructure) Split(secret curves.Scalar, reader io.Reader) ([]*AccessShare, error) {
+	_, shares, err := a.SplitVerifiable(secret, reader)
+	return shares, err
+}
+
+// SplitVerifiable deals a share of `secret` for every leaf of the tree along with a
+// verifier with which each party can check its shares
+func (a AccessStructure) SplitVerifiable(secret curves.Scalar, reader io.Reader) (*AccessVerifier, []*AccessShare, error) {
+	if secret == nil || secret.IsZero() {
+		return nil, nil, fmt.Errorf("invalid secret")
+	}
+	var shares []*AccessShare
+	verifier := a.deal(a.root, secret, nil, reader, &shares)
+	return verifier, shares, nil
+}
+
+func (a AccessStructure) deal(n *AccessNode, secret curves.Scalar, path []uint32, reader io.Reader, shares *[]*AccessShare) *AccessVerifier {
+	if n.IsLeaf() {
+		*shares = append(*shares, &AccessShare{
+			Party: n.Party,
+			Path:  path,
+			Value: secret.Bytes(),
+		})
+		return &AccessVerifier{}
+	}
+	poly := new(Polynomial).Init(secret, n.Threshold, reader)
+	verifier := &AccessVerifier{
+		Commitments: make([]curves.Point, len(poly.Coefficients)),
+		Children:    make([]*AccessVerifier, len(n.Children)),
+	}
+	for i, c := range poly.Coefficients {
+		verifier.Commitments[i] = a.curve.ScalarBaseMult(c)
+	}
+	for i, child := range n.Children {
+		childPath := append(append(make([]uint32, 0, len(path)+1), path...), uint32(i+1))
+		value := poly.Evaluate(a.curve.ScalarFromIndex(uint32(i + 1)))
+		verifier.Children[i] = a.deal(child, value, childPath, reader, shares)
+	}
+	return verifier
+}
+
+// leaf returns the leaf at `path`
+func (a AccessStructure) leaf(path []uint32) (*AccessNode, error) {
+	n := a.root
+	for _, i := range path {
+		if i < 1 || int(i) > len(n.Children) {
+			return nil, fmt.Errorf("invalid share path")
+		}
+		n = n.Children[i-1]
+	}
+	if !n.IsLeaf() {
+		return nil, fmt.Errorf("share path does not end at a leaf")
+	}
+	return n, nil
+}
+
+// Verify checks the share against the commitments of the nodes on its path
+func (a AccessStructure) Verify(verifier *AccessVerifier, share *AccessShare) error {
+	if verifier == nil || share == nil {
+		return fmt.Errorf("invalid arguments")
+	}
+	leaf, err := a.leaf(share.Path)
+	if err != nil {
+		return err
+	}
+	if leaf.Party != share.Party {
+		return fmt.Errorf("share of %s is at the leaf of %s", share.Party, leaf.Party)
+	}
+	value, err := a.curve.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return err
+	}
+
+	n, v := a.root, verifier
+	var expected curves.Point
+	for _, i := range share.Path {
+		if len(v.Commitments) != int(n.Threshold) || len(v.Children) != len(n.Children) {
+			return fmt.Errorf("verifier does not match the access structure")
+		}
+		if expected != nil && !expected.Equal(v.Commitments[0]) {
+			return fmt.Errorf("inconsistent commitments")
+		}
+		// The commitment to the child's value is the commitments evaluated at its index
+		x := a.curve.ScalarFromIndex(i)
+		xi := a.curve.Scalar.One()
+		expected = v.Commitments[0]
+		for j := 1; j < len(v.Commitments); j++ {
+			xi = xi.Mul(x)
+			expected = expected.Add(v.Commitments[j].Mul(xi))
+		}
+		n, v = n.Children[i-1], v.Children[i-1]
+		if v == nil {
+			return fmt.Errorf("verifier does not match the access structure")
+		}
+	}
+	if !a.curve.ScalarBaseMult(value).Equal(expected) {
+		return fmt.Errorf("not equal")
+	}
+	return nil
+}
+
+// Combine recovers the secret from shares whose parties satisfy the tree
+func (a AccessStructure) Combine(shares ...*AccessShare) (curves.Scalar, error) {
+	values := make(map[string]curves.Scalar, len(shares))
+	for _, share := range shares {
+		if share == nil {
+			return nil, fmt.Errorf("nil share")
+		}
+		leaf, err := a.leaf(share.Path)
+		if err != nil {
+			return nil, err
+		}
+		if leaf.Party != share.Party {
+			return nil, fmt.Errorf("share of %s is at the leaf of %s", share.Party, leaf.Party)
+		}
+		key := pathKey(share.Path)
+		if _, in := values[key]; in {
+			return nil, fmt.Errorf("duplicate share")
+		}
+		if values[key], err = a.curve.Scalar.SetBytes(share.Value); err != nil {
+			return nil, err
+		}
+	}
+	secret := a.combine(a.root, nil, values)
+	if secret == nil {
+		return nil, fmt.Errorf("shares do not satisfy the access structure")
+	}
+	return secret, nil
+}
+
+// combine returns the value of node n at `path`, or nil if the shares do not satisfy it
+func (a AccessStructure) combine(n *AccessNode, path []uint32, values map[string]curves.Scalar) curves.Scalar {
+	if n.IsLeaf() {
+		return values[pathKey(path)]
+	}
+	xs := make([]curves.Scalar, 0, n.Threshold)
+	ys := make([]curves.Scalar, 0, n.Threshold)
+	for i, child := range n.Children {
+		childPath := append(append(make([]uint32, 0, len(path)+1), path...), uint32(i+1))
+		y := a.combine(child, childPath, values)
+		if y == nil {
+			continue
+		}
+		xs = append(xs, a.curve.ScalarFromIndex(uint32(i+1)))
+		ys = append(ys, y)
+		if len(xs) == int(n.Threshold) {
+			break
+		}
+	}
+	if len(xs) < int(n.Threshold) {
+		return nil
+	}
+	// The indices are distinct, so interpolation cannot fail
+	secret, _ := Shamir{curve: a.curve}.interpolate(xs, ys)
+	return secret
+}
+
+func pathKey(path []uint32) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
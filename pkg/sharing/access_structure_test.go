@@ -0,0 +1,139 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func corporatePolicy() *AccessNode {
+	return Or(
+		And(Party("CFO"), Threshold(2, Party("eng1"), Party("eng2"), Party("eng3"))),
+		Threshold(3, Party("dir1"), Party("dir2"), Party("dir3"), Party("dir4")),
+	)
+}
+
+// sharesOf returns the shares held by the parties
+func sharesOf(shares []*AccessShare, parties ...string) []*AccessShare {
+	var out []*AccessShare
+	for _, share := range shares {
+		for _, p := range parties {
+			if share.Party == p {
+				out = append(out, share)
+			}
+		}
+	}
+	return out
+}
+
+func TestAccessNode(t *testing.T) {
+	policy := corporatePolicy()
+	require.NoError(t, policy.Validate())
+	require.Equal(t, "(CFO AND 2 of (eng1, eng2, eng3)) OR 3 of (dir1, dir2, dir3, dir4)", policy.String())
+	require.Equal(t, []string{"CFO", "eng1", "eng2", "eng3", "dir1", "dir2", "dir3", "dir4"}, policy.Parties())
+	require.True(t, policy.Satisfied("CFO", "eng1", "eng3"))
+	require.True(t, policy.Satisfied("dir1", "dir2", "dir4"))
+	require.False(t, policy.Satisfied("CFO", "eng1", "dir1", "dir2"))
+
+	require.Error(t, Threshold(3, Party("a"), Party("b")).Validate())
+	require.Error(t, Threshold(0, Party("a")).Validate())
+	require.Error(t, And(Party("a"), Party("")).Validate())
+	require.Error(t, And(Party("a"), nil).Validate())
+	_, err := NewAccessStructure(Party("a"), curves.ED25519())
+	require.Error(t, err)
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	var decoded AccessNode
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, policy.String(), decoded.String())
+}
+
+func TestAccessStructureCombine(t *testing.T) {
+	curve := curves.K256()
+	scheme, err := NewAccessStructure(corporatePolicy(), curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	verifier, shares, err := scheme.SplitVerifiable(secret, crand.Reader)
+	require.NoError(t, err)
+	require.Len(t, shares, 8)
+	require.True(t, verifier.PublicKey().Equal(curve.ScalarBaseMult(secret)))
+	for _, share := range shares {
+		require.NoError(t, scheme.Verify(verifier, share))
+	}
+
+	for _, parties := range [][]string{
+		{"CFO", "eng1", "eng2"},
+		{"CFO", "eng2", "eng3", "dir1"},
+		{"dir1", "dir3", "dir4"},
+		{"CFO", "eng1", "eng2", "eng3", "dir1", "dir2", "dir3", "dir4"},
+	} {
+		recovered, err := scheme.Combine(sharesOf(shares, parties...)...)
+		require.NoError(t, err)
+		require.Equal(t, 0, recovered.Cmp(secret), parties)
+	}
+	for _, parties := range [][]string{
+		{"eng1", "eng2", "eng3"},
+		{"CFO", "eng1", "dir1", "dir2"},
+		{},
+	} {
+		_, err = scheme.Combine(sharesOf(shares, parties...)...)
+		require.Error(t, err, parties)
+	}
+
+	cfo := sharesOf(shares, "CFO")[0]
+	_, err = scheme.Combine(cfo, cfo)
+	require.Error(t, err)
+	_, err = scheme.Combine(&AccessShare{Party: "eng1", Path: cfo.Path, Value: cfo.Value})
+	require.Error(t, err)
+}
+
+func TestAccessStructureRepeatedParty(t *testing.T) {
+	curve := curves.ED25519()
+	// alice alone, or bob with carol
+	scheme, err := NewAccessStructure(Or(Party("alice"), And(Party("bob"), Party("carol")), Party("alice")), curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	require.Len(t, sharesOf(shares, "alice"), 2)
+	recovered, err := scheme.Combine(sharesOf(shares, "alice")...)
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+	recovered, err = scheme.Combine(sharesOf(shares, "bob", "carol")...)
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+}
+
+func TestAccessStructureVerifyTampered(t *testing.T) {
+	curve := curves.P256()
+	scheme, err := NewAccessStructure(corporatePolicy(), curve)
+	require.NoError(t, err)
+	verifier, shares, err := scheme.SplitVerifiable(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+
+	bad := *shares[2]
+	value, _ := curve.Scalar.SetBytes(bad.Value)
+	bad.Value = value.Add(curve.Scalar.One()).Bytes()
+	require.Error(t, scheme.Verify(verifier, &bad))
+	bad = *shares[2]
+	bad.Path = []uint32{1, 3}
+	require.Error(t, scheme.Verify(verifier, &bad))
+	bad.Path = []uint32{3}
+	require.Error(t, scheme.Verify(verifier, &bad))
+
+	// A verifier whose inner commitments do not match the parent's is rejected
+	verifier.Children[0].Commitments[0] = curve.Point.Generator()
+	require.Error(t, scheme.Verify(verifier, shares[0]))
+	require.NoError(t, scheme.Verify(verifier, shares[len(shares)-1]))
+}
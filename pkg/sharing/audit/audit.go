@@ -0,0 +1,257 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package audit commits to the key shares a custodian holds at a point in time, so that it can
+// prove to an auditor exactly which shares existed in an epoch without revealing any of them.
+//
+// A Snapshot is an RFC 6962 Merkle tree over the public description of every share: the id of the
+// shared key, the share id and the public share s_i * G, which verifiers of a Feldman or Pedersen
+// sharing can already compute. The EpochRoot binds the tree root to the epoch and the number of
+// shares and is signed with BLS by each custodian. Signatures on the same root aggregate into a
+// single multi-signature, and an InclusionProof shows that one share is part of the signed root.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/bls/bls_sig"
+)
+
+const (
+	// rootDomain prefixes the signed encoding of an EpochRoot
+	rootDomain = "kryptology keyring audit root v1"
+	// Hash prefixes that separate leaves from interior nodes, see RFC 6962 section 2.1
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Entry is the public description of a key share
+type Entry struct {
+	// KeyId identifies the shared key, e.g. its public key or an account id
+	KeyId   []byte
+	ShareId uint32
+	// PublicShare is s_i * G for the share value s_i
+	PublicShare curves.Point
+}
+
+// Snapshot is a Merkle tree over the entries of a keyring in one epoch
+type Snapshot struct {
+	epoch   uint64
+	entries []*Entry
+	leaves  [][sha256.Size]byte
+}
+
+// EpochRoot is the commitment to a Snapshot that custodians sign
+type EpochRoot struct {
+	Epoch uint64
+	Size  uint64
+	Hash  [sha256.Size]byte
+}
+
+// InclusionProof shows that the entry at Index is part of a tree with Size leaves
+type InclusionProof struct {
+	Index uint64
+	Path  [][sha256.Size]byte
+}
+
+// NewSnapshot commits to `entries` in `epoch`. The entries are sorted by key id and share id,
+// so the root does not depend on their order. A key share may only appear once
+func NewSnapshot(epoch uint64, entries []*Entry) (*Snapshot, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("a snapshot needs at least one entry")
+	}
+	sorted := make([]*Entry, len(entries))
+	for i, e := range entries {
+		if e == nil || len(e.KeyId) == 0 || e.ShareId == 0 || e.PublicShare == nil {
+			return nil, fmt.Errorf("invalid entry %d", i)
+		}
+		if len(e.KeyId) > 0xffff {
+			return nil, fmt.Errorf("key id of entry %d is too long", i)
+		}
+		sorted[i] = e
+	}
+	sort.Slice(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	leaves := make([][sha256.Size]byte, len(sorted))
+	for i, e := range sorted {
+		if i > 0 && !less(sorted[i-1], e) {
+			return nil, fmt.Errorf("duplicate share %d of key %x", e.ShareId, e.KeyId)
+		}
+		leaves[i] = leafHash(e)
+	}
+	return &Snapshot{epoch: epoch, entries: sorted, leaves: leaves}, nil
+}
+
+// Root returns the commitment to the snapshot
+func (s *Snapshot) Root() *EpochRoot {
+	return &EpochRoot{Epoch: s.epoch, Size: uint64(len(s.leaves)), Hash: treeHash(s.leaves)}
+}
+
+// Entries returns the entries of the snapshot in tree order
+func (s *Snapshot) Entries() []*Entry {
+	entries := make([]*Entry, len(s.entries))
+	copy(entries, s.entries)
+	return entries
+}
+
+// Prove returns the inclusion proof for share `shareId` of key `keyId`
+func (s *Snapshot) Prove(keyId []byte, shareId uint32) (*InclusionProof, error) {
+	target := &Entry{KeyId: keyId, ShareId: shareId}
+	index := sort.Search(len(s.entries), func(i int) bool { return !less(s.entries[i], target) })
+	if index == len(s.entries) || less(target, s.entries[index]) {
+		return nil, fmt.Errorf("share %d of key %x is not in the snapshot", shareId, keyId)
+	}
+	return &InclusionProof{Index: uint64(index), Path: auditPath(index, s.leaves)}, nil
+}
+
+// Bytes returns the message signed by custodians
+func (r *EpochRoot) Bytes() []byte {
+	out := make([]byte, 0, len(rootDomain)+16+sha256.Size)
+	out = append(out, rootDomain...)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], r.Epoch)
+	out = append(out, buf[:]...)
+	binary.BigEndian.PutUint64(buf[:], r.Size)
+	out = append(out, buf[:]...)
+	return append(out, r.Hash[:]...)
+}
+
+// VerifyInclusion checks that `entry` is part of the snapshot committed to by the root
+func (r *EpochRoot) VerifyInclusion(entry *Entry, proof *InclusionProof) error {
+	if entry == nil || entry.PublicShare == nil || proof == nil {
+		return fmt.Errorf("invalid inclusion proof")
+	}
+	if proof.Index >= r.Size {
+		return fmt.Errorf("index %d is outside a tree of size %d", proof.Index, r.Size)
+	}
+	// RFC 9162 section 2.1.3.2
+	fn, sn := proof.Index, r.Size-1
+	hash := leafHash(entry)
+	for _, sibling := range proof.Path {
+		if sn == 0 {
+			return fmt.Errorf("inclusion proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			hash = nodeHash(sibling, hash)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			hash = nodeHash(hash, sibling)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || hash != r.Hash {
+		return fmt.Errorf("share %d of key %x is not included in the root", entry.ShareId, entry.KeyId)
+	}
+	return nil
+}
+
+// SignRoot signs the root with a custodian's BLS key
+func SignRoot(scheme *bls_sig.SigPop, sk *bls_sig.SecretKey, root *EpochRoot) (*bls_sig.Signature, error) {
+	if scheme == nil || sk == nil || root == nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	return scheme.Sign(sk, root.Bytes())
+}
+
+// AggregateRootSignatures combines the signatures of several custodians on the same root
+func AggregateRootSignatures(scheme *bls_sig.SigPop, signatures ...*bls_sig.Signature) (*bls_sig.MultiSignature, error) {
+	if scheme == nil {
+		return nil, fmt.Errorf("invalid scheme")
+	}
+	return scheme.AggregateSignatures(signatures...)
+}
+
+// VerifyRoot checks that every custodian in `custodians` signed the root.
+// The proof of possession of each custodian key must have been checked with
+// SigPop.PopVerify before it is used here, to rule out rogue key attacks
+func VerifyRoot(scheme *bls_sig.SigPop, custodians []*bls_sig.PublicKey, root *EpochRoot, signature *bls_sig.MultiSignature) error {
+	if scheme == nil || root == nil || signature == nil || len(custodians) == 0 {
+		return fmt.Errorf("invalid arguments")
+	}
+	pk, err := scheme.AggregatePublicKeys(custodians...)
+	if err != nil {
+		return err
+	}
+	ok, err := scheme.VerifyMultiSignature(pk, root.Bytes(), signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("invalid root signature for epoch %d", root.Epoch)
+	}
+	return nil
+}
+
+// less orders entries by key id then share id
+func less(a, b *Entry) bool {
+	if c := bytes.Compare(a.KeyId, b.KeyId); c != 0 {
+		return c < 0
+	}
+	return a.ShareId < b.ShareId
+}
+
+// leafHash hashes the canonical encoding of an entry
+func leafHash(e *Entry) [sha256.Size]byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{leafPrefix, byte(len(e.KeyId) >> 8), byte(len(e.KeyId))})
+	_, _ = h.Write(e.KeyId)
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], e.ShareId)
+	_, _ = h.Write(buf[:])
+	name := e.PublicShare.CurveName()
+	_, _ = h.Write([]byte{byte(len(name))})
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write(e.PublicShare.ToAffineCompressed())
+	var out [sha256.Size]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+func nodeHash(left, right [sha256.Size]byte) [sha256.Size]byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{nodePrefix})
+	_, _ = h.Write(left[:])
+	_, _ = h.Write(right[:])
+	var out [sha256.Size]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// split returns the largest power of two smaller than n, for n > 1
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// treeHash computes the Merkle tree hash of RFC 6962 section 2.1
+func treeHash(leaves [][sha256.Size]byte) [sha256.Size]byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(treeHash(leaves[:k]), treeHash(leaves[k:]))
+}
+
+// auditPath computes the Merkle audit path of RFC 6962 section 2.1.1, leaf to root
+func auditPath(m int, leaves [][sha256.Size]byte) [][sha256.Size]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if m < k {
+		return append(auditPath(m, leaves[:k]), treeHash(leaves[k:]))
+	}
+	return append(auditPath(m-k, leaves[k:]), treeHash(leaves[:k]))
+}
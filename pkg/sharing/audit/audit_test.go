@@ -0,0 +1,138 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package audit
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+	"github.com/etclab/kryptology/pkg/signatures/bls/bls_sig"
+)
+
+func newEntries(t *testing.T, keys int) []*Entry {
+	curve := curves.K256()
+	feldman, err := sharing.NewFeldman(2, 3, curve)
+	require.NoError(t, err)
+	var entries []*Entry
+	for k := 0; k < keys; k++ {
+		verifier, shares, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+		require.NoError(t, err)
+		keyId := verifier.Commitments[0].ToAffineCompressed()
+		for _, share := range shares {
+			value, err := curve.Scalar.SetBytes(share.Value)
+			require.NoError(t, err)
+			entries = append(entries, &Entry{KeyId: keyId, ShareId: share.Id, PublicShare: curve.ScalarBaseMult(value)})
+		}
+	}
+	return entries
+}
+
+func TestInclusionProofs(t *testing.T) {
+	all := newEntries(t, 6)
+	for n := 1; n <= len(all); n++ {
+		snapshot, err := NewSnapshot(7, all[:n])
+		require.NoError(t, err)
+		root := snapshot.Root()
+		require.Equal(t, uint64(n), root.Size)
+		for _, e := range snapshot.Entries() {
+			proof, err := snapshot.Prove(e.KeyId, e.ShareId)
+			require.NoError(t, err)
+			require.NoError(t, root.VerifyInclusion(e, proof), fmt.Sprintf("size %d index %d", n, proof.Index))
+		}
+	}
+}
+
+func TestInclusionProofRejects(t *testing.T) {
+	entries := newEntries(t, 3)
+	snapshot, err := NewSnapshot(1, entries)
+	require.NoError(t, err)
+	root := snapshot.Root()
+	e := entries[4]
+	proof, err := snapshot.Prove(e.KeyId, e.ShareId)
+	require.NoError(t, err)
+
+	// A different public share
+	forged := &Entry{KeyId: e.KeyId, ShareId: e.ShareId, PublicShare: e.PublicShare.Double()}
+	require.Error(t, root.VerifyInclusion(forged, proof))
+	// A different position
+	moved := *proof
+	moved.Index ^= 1
+	require.Error(t, root.VerifyInclusion(e, &moved))
+	// A truncated path
+	short := *proof
+	short.Path = proof.Path[:len(proof.Path)-1]
+	require.Error(t, root.VerifyInclusion(e, &short))
+	// The root of another snapshot
+	other, err := NewSnapshot(1, entries[1:])
+	require.NoError(t, err)
+	require.Error(t, other.Root().VerifyInclusion(e, proof))
+
+	_, err = snapshot.Prove(e.KeyId, 9)
+	require.Error(t, err)
+	_, err = NewSnapshot(1, append(entries, entries[0]))
+	require.Error(t, err)
+	_, err = NewSnapshot(1, nil)
+	require.Error(t, err)
+}
+
+func TestSnapshotIsOrderIndependent(t *testing.T) {
+	entries := newEntries(t, 2)
+	a, err := NewSnapshot(3, entries)
+	require.NoError(t, err)
+	reversed := make([]*Entry, len(entries))
+	for i, e := range entries {
+		reversed[len(entries)-1-i] = e
+	}
+	b, err := NewSnapshot(3, reversed)
+	require.NoError(t, err)
+	require.Equal(t, a.Root(), b.Root())
+
+	c, err := NewSnapshot(4, entries)
+	require.NoError(t, err)
+	require.Equal(t, a.Root().Hash, c.Root().Hash)
+	require.NotEqual(t, a.Root().Bytes(), c.Root().Bytes())
+}
+
+func TestSignedRoots(t *testing.T) {
+	snapshot, err := NewSnapshot(9, newEntries(t, 2))
+	require.NoError(t, err)
+	root := snapshot.Root()
+
+	scheme := bls_sig.NewSigPop()
+	var pks []*bls_sig.PublicKey
+	var sigs []*bls_sig.Signature
+	for i := 0; i < 3; i++ {
+		pk, sk, err := scheme.Keygen()
+		require.NoError(t, err)
+		pop, err := scheme.PopProve(sk)
+		require.NoError(t, err)
+		ok, err := scheme.PopVerify(pk, pop)
+		require.NoError(t, err)
+		require.True(t, ok)
+		sig, err := SignRoot(scheme, sk, root)
+		require.NoError(t, err)
+		pks = append(pks, pk)
+		sigs = append(sigs, sig)
+	}
+	aggregate, err := AggregateRootSignatures(scheme, sigs...)
+	require.NoError(t, err)
+	require.NoError(t, VerifyRoot(scheme, pks, root, aggregate))
+
+	// Every custodian must have signed
+	partial, err := AggregateRootSignatures(scheme, sigs[:2]...)
+	require.NoError(t, err)
+	require.Error(t, VerifyRoot(scheme, pks, root, partial))
+	// The signature is bound to the epoch
+	next := *root
+	next.Epoch++
+	require.Error(t, VerifyRoot(scheme, pks, &next, aggregate))
+}
@@ -0,0 +1,166 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// BatchVerifyFeldman checks that every share is valid for the verifier at the same index, e.g. the shares
+// a DKG participant received from all dealers. Instead of checking s_k*G = sum_j x_k^j*C_{k,j} for each share,
+// it checks a random linear combination of the equations with one multi-scalar multiplication.
+// If the combination fails, the shares are verified one by one to find the invalid one.
+func BatchVerifyFeldman(verifiers []*FeldmanVerifier, shares []*ShamirShare, reader io.Reader) error {
+	if len(verifiers) != len(shares) {
+		return fmt.Errorf("number of verifiers and shares should be equal")
+	}
+	if len(shares) == 0 {
+		return fmt.Errorf("no shares to verify")
+	}
+	if reader == nil {
+		return fmt.Errorf("invalid reader")
+	}
+	for i, v := range verifiers {
+		if v == nil || len(v.Commitments) == 0 {
+			return fmt.Errorf("invalid verifier at index %d", i)
+		}
+	}
+	curve, err := batchCurve(verifiers[0].Commitments[0], shares)
+	if err != nil {
+		return err
+	}
+
+	// sum_k rho_k*s_k*G - sum_k sum_j rho_k*x_k^j*C_{k,j} = 0
+	sum := curve.Scalar.Zero()
+	var points []curves.Point
+	var scalars []curves.Scalar
+	for k, share := range shares {
+		rho := curve.Scalar.Random(reader)
+		s, _ := curve.Scalar.SetBytes(share.Value)
+		sum = sum.Add(rho.Mul(s))
+		p, c, err := batchCommitments(curve, verifiers[k].Commitments, share.Id, rho)
+		if err != nil {
+			return fmt.Errorf("invalid verifier at index %d: %v", k, err)
+		}
+		points = append(points, p...)
+		scalars = append(scalars, c...)
+	}
+	points = append(points, curve.NewGeneratorPoint())
+	scalars = append(scalars, sum)
+	if curves.VarTimeSumOfProducts(points, scalars).IsIdentity() {
+		return nil
+	}
+
+	for k, share := range shares {
+		if err := verifiers[k].Verify(share); err != nil {
+			return fmt.Errorf("invalid share %d at index %d", share.Id, k)
+		}
+	}
+	return fmt.Errorf("batch verification failed")
+}
+
+// BatchVerifyPedersen checks that every share and blinding share are valid for the verifier at the same index.
+// Like BatchVerifyFeldman, it checks a random linear combination of the equations
+// s_k*G + b_k*H = sum_j x_k^j*C_{k,j} with one multi-scalar multiplication,
+// and verifies the shares one by one to find the invalid one if it fails.
+func BatchVerifyPedersen(verifiers []*PedersenVerifier, shares, blindingShares []*ShamirShare, reader io.Reader) error {
+	if len(verifiers) != len(shares) || len(shares) != len(blindingShares) {
+		return fmt.Errorf("number of verifiers, shares and blinding shares should be equal")
+	}
+	if len(shares) == 0 {
+		return fmt.Errorf("no shares to verify")
+	}
+	if reader == nil {
+		return fmt.Errorf("invalid reader")
+	}
+	for i, v := range verifiers {
+		if v == nil || v.Generator == nil || len(v.Commitments) == 0 {
+			return fmt.Errorf("invalid verifier at index %d", i)
+		}
+	}
+	curve, err := batchCurve(verifiers[0].Generator, shares)
+	if err != nil {
+		return err
+	}
+	if _, err = batchCurve(verifiers[0].Generator, blindingShares); err != nil {
+		return err
+	}
+	for k, share := range shares {
+		if blindingShares[k].Id != share.Id {
+			return fmt.Errorf("share and blinding share at index %d have different identifiers", k)
+		}
+	}
+
+	// sum_k rho_k*s_k*G + sum_k rho_k*b_k*H_k - sum_k sum_j rho_k*x_k^j*C_{k,j} = 0
+	sum := curve.Scalar.Zero()
+	var points []curves.Point
+	var scalars []curves.Scalar
+	for k, share := range shares {
+		if verifiers[k].Generator.CurveName() != curve.Name {
+			return fmt.Errorf("invalid verifier at index %d", k)
+		}
+		rho := curve.Scalar.Random(reader)
+		s, _ := curve.Scalar.SetBytes(share.Value)
+		b, _ := curve.Scalar.SetBytes(blindingShares[k].Value)
+		sum = sum.Add(rho.Mul(s))
+		p, c, err := batchCommitments(curve, verifiers[k].Commitments, share.Id, rho)
+		if err != nil {
+			return fmt.Errorf("invalid verifier at index %d: %v", k, err)
+		}
+		points = append(points, p...)
+		scalars = append(scalars, c...)
+		points = append(points, verifiers[k].Generator)
+		scalars = append(scalars, rho.Mul(b))
+	}
+	points = append(points, curve.NewGeneratorPoint())
+	scalars = append(scalars, sum)
+	if curves.VarTimeSumOfProducts(points, scalars).IsIdentity() {
+		return nil
+	}
+
+	for k, share := range shares {
+		if err := verifiers[k].Verify(share, blindingShares[k]); err != nil {
+			return fmt.Errorf("invalid share %d at index %d", share.Id, k)
+		}
+	}
+	return fmt.Errorf("batch verification failed")
+}
+
+// batchCurve returns the curve of `point` after checking the shares are valid for it
+func batchCurve(point curves.Point, shares []*ShamirShare) (*curves.Curve, error) {
+	curve := curves.GetCurveByName(point.CurveName())
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	for i, share := range shares {
+		if share == nil {
+			return nil, fmt.Errorf("invalid share at index %d", i)
+		}
+		if err := share.Validate(curve); err != nil {
+			return nil, err
+		}
+	}
+	return curve, nil
+}
+
+// batchCommitments returns the commitments with the scalars -rho*x^j, where x is the share identifier
+func batchCommitments(curve *curves.Curve, commitments []curves.Point, id uint32, rho curves.Scalar) ([]curves.Point, []curves.Scalar, error) {
+	x := curve.ScalarFromIndex(id)
+	scalars := make([]curves.Scalar, len(commitments))
+	c := rho.Neg()
+	for j, commitment := range commitments {
+		if commitment == nil || commitment.CurveName() != curve.Name {
+			return nil, nil, fmt.Errorf("invalid commitment")
+		}
+		scalars[j] = c
+		c = c.Mul(x)
+	}
+	return commitments, scalars, nil
+}
@@ -0,0 +1,98 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// dealFeldman returns the verifiers of `n` dealers and the shares they sent to participant `id`
+func dealFeldman(t testing.TB, curve *curves.Curve, n int, id uint32) ([]*FeldmanVerifier, []*ShamirShare) {
+	feldman, err := NewFeldman(3, 5, curve)
+	require.NoError(t, err)
+	verifiers := make([]*FeldmanVerifier, n)
+	shares := make([]*ShamirShare, n)
+	for k := range verifiers {
+		verifier, dealt, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+		require.NoError(t, err)
+		verifiers[k] = verifier
+		shares[k] = dealt[id-1]
+	}
+	return verifiers, shares
+}
+
+func TestBatchVerifyFeldman(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.ED25519(), curves.P256()} {
+		verifiers, shares := dealFeldman(t, curve, 5, 2)
+		require.NoError(t, BatchVerifyFeldman(verifiers, shares, crand.Reader))
+
+		// a single invalid share is found
+		shares[3] = &ShamirShare{Id: 2, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+		err := BatchVerifyFeldman(verifiers, shares, crand.Reader)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "index 3")
+	}
+
+	curve := curves.K256()
+	verifiers, shares := dealFeldman(t, curve, 2, 1)
+	require.Error(t, BatchVerifyFeldman(verifiers, shares[:1], crand.Reader))
+	require.Error(t, BatchVerifyFeldman(nil, nil, crand.Reader))
+	require.Error(t, BatchVerifyFeldman(verifiers, shares, nil))
+	require.Error(t, BatchVerifyFeldman(verifiers, []*ShamirShare{shares[0], nil}, crand.Reader))
+	// the verifiers must all be on the curve of the shares
+	other, _ := dealFeldman(t, curves.P256(), 1, 1)
+	require.Error(t, BatchVerifyFeldman([]*FeldmanVerifier{verifiers[0], other[0]}, shares, crand.Reader))
+}
+
+func TestBatchVerifyPedersen(t *testing.T) {
+	curve := curves.K256()
+	generator, err := PedersenGenerator(curve, []byte("batch test"))
+	require.NoError(t, err)
+	pedersen, err := NewPedersen(3, 5, generator)
+	require.NoError(t, err)
+
+	var verifiers []*PedersenVerifier
+	var shares, blindingShares []*ShamirShare
+	for k := 0; k < 4; k++ {
+		result, err := pedersen.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+		require.NoError(t, err)
+		verifiers = append(verifiers, result.PedersenVerifier)
+		shares = append(shares, result.SecretShares[4])
+		blindingShares = append(blindingShares, result.BlindingShares[4])
+	}
+	require.NoError(t, BatchVerifyPedersen(verifiers, shares, blindingShares, crand.Reader))
+
+	blindingShares[1] = &ShamirShare{Id: 5, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	err = BatchVerifyPedersen(verifiers, shares, blindingShares, crand.Reader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "index 1")
+
+	require.Error(t, BatchVerifyPedersen(verifiers, shares, blindingShares[:2], crand.Reader))
+	blindingShares[1] = &ShamirShare{Id: 4, Value: shares[1].Value}
+	require.Error(t, BatchVerifyPedersen(verifiers, shares, blindingShares, crand.Reader))
+}
+
+func BenchmarkBatchVerifyFeldman(b *testing.B) {
+	verifiers, shares := dealFeldman(b, curves.K256(), 20, 1)
+	b.Run("individual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for k, share := range shares {
+				_ = verifiers[k].Verify(share)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = BatchVerifyFeldman(verifiers, shares, crand.Reader)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// blobInfo separates the keys derived for SplitBytes from other uses of the shared scalar
+var blobInfo = []byte("kryptology sharing blob v1")
+
+// BlobShare is a share of an arbitrary-length secret. Every holder keeps the secret encrypted under a random key
+// along with a share of the key, so any `threshold` holders can decrypt it.
+type BlobShare struct {
+	Nonce      []byte       `json:"nonce"`
+	Ciphertext []byte       `json:"ciphertext"`
+	Share      *ShamirShare `json:"share"`
+}
+
+// SplitBytes shares an arbitrary-length secret, e.g. a seed phrase or a document. The secret is encrypted with
+// AES-256-GCM under a key derived with HKDF-SHA256 from a random scalar, which is shared with Shamir.
+func (s Shamir) SplitBytes(secret []byte, reader io.Reader) ([]*BlobShare, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("invalid secret")
+	}
+	key := s.curve.Scalar.Random(reader)
+	if key.IsZero() {
+		return nil, fmt.Errorf("invalid key")
+	}
+	aead, err := s.blobAead(key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(reader, nonce); err != nil {
+		return nil, err
+	}
+	ciphertext := aead.Seal(nil, nonce, secret, s.blobAad())
+
+	shares, err := s.Split(key, reader)
+	if err != nil {
+		return nil, err
+	}
+	blobShares := make([]*BlobShare, len(shares))
+	for i, share := range shares {
+		blobShares[i] = &BlobShare{
+			Nonce:      nonce,
+			Ciphertext: ciphertext,
+			Share:      share,
+		}
+	}
+	return blobShares, nil
+}
+
+// CombineBytes recovers a secret shared with SplitBytes from at least `threshold` shares
+func (s Shamir) CombineBytes(shares ...*BlobShare) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("invalid number of shares")
+	}
+	keyShares := make([]*ShamirShare, len(shares))
+	for i, share := range shares {
+		if share == nil || share.Share == nil {
+			return nil, fmt.Errorf("invalid share")
+		}
+		if !bytes.Equal(share.Nonce, shares[0].Nonce) || !bytes.Equal(share.Ciphertext, shares[0].Ciphertext) {
+			return nil, fmt.Errorf("shares belong to different secrets")
+		}
+		keyShares[i] = share.Share
+	}
+	key, err := s.Combine(keyShares...)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := s.blobAead(key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if len(shares[0].Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	secret, err := aead.Open(nil, shares[0].Nonce, shares[0].Ciphertext, s.blobAad())
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed, the shares may be invalid")
+	}
+	return secret, nil
+}
+
+// blobAead returns the cipher for the key shared with Shamir
+func (s Shamir) blobAead(key []byte) (cipher.AEAD, error) {
+	kdf := hkdf.New(sha256.New, key, nil, blobInfo)
+	encKey := make([]byte, 32)
+	if _, err := io.ReadFull(kdf, encKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// blobAad binds the ciphertext to the parameters of the sharing
+func (s Shamir) blobAad() []byte {
+	aad := make([]byte, 8, 8+len(s.curve.Name))
+	binary.BigEndian.PutUint32(aad[:4], s.threshold)
+	binary.BigEndian.PutUint32(aad[4:], s.limit)
+	return append(aad, s.curve.Name...)
+}
@@ -0,0 +1,64 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestSplitBytes(t *testing.T) {
+	curve := curves.ED25519()
+	scheme, err := NewShamir(3, 5, curve)
+	require.NoError(t, err)
+	secret := []byte("abandon ability able about above absent absorb abstract absurd abuse access accident")
+	shares, err := scheme.SplitBytes(secret, crand.Reader)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	recovered, err := scheme.CombineBytes(shares[4], shares[1], shares[2])
+	require.NoError(t, err)
+	require.Equal(t, secret, recovered)
+	recovered, err = scheme.CombineBytes(shares...)
+	require.NoError(t, err)
+	require.Equal(t, secret, recovered)
+
+	_, err = scheme.CombineBytes(shares[0], shares[1])
+	require.Error(t, err)
+	_, err = scheme.SplitBytes(nil, crand.Reader)
+	require.Error(t, err)
+
+	// shares of another secret cannot be mixed in
+	other, err := scheme.SplitBytes([]byte("another secret"), crand.Reader)
+	require.NoError(t, err)
+	_, err = scheme.CombineBytes(shares[0], shares[1], other[2])
+	require.Error(t, err)
+
+	// a tampered ciphertext or share is detected
+	tampered := *shares[0]
+	tampered.Share = &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, err = scheme.CombineBytes(&tampered, shares[1], shares[2])
+	require.Error(t, err)
+	ciphertext := append([]byte{}, shares[0].Ciphertext...)
+	ciphertext[0] ^= 1
+	var bundle []*BlobShare
+	for _, share := range shares[:3] {
+		bundle = append(bundle, &BlobShare{Nonce: share.Nonce, Ciphertext: ciphertext, Share: share.Share})
+	}
+	_, err = scheme.CombineBytes(bundle...)
+	require.Error(t, err)
+
+	// the ciphertext is bound to the parameters of the sharing
+	larger, err := NewShamir(3, 6, curve)
+	require.NoError(t, err)
+	_, err = larger.CombineBytes(shares[0], shares[1], shares[2])
+	require.Error(t, err)
+}
@@ -0,0 +1,319 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package crosscurve is an EXPERIMENTAL protocol that re-commits a secret, Shamir shared on a source curve,
+// into Feldman shares on a target curve of similar order, without reconstructing it.
+//
+// Every holder i of a quorum of source shares acts as a dealer: it computes its Lagrange weighted share
+// a_i = lambda_i * s_i as an integer in [0, q_source), shares a_i on the target curve with Feldman VSS
+// and proves with a crossgroup proof (a bit decomposition bridge) that the constant term of its target
+// commitments a_i * G_target has the same discrete log as a_i * G_source, which anyone can compute from the
+// source Feldman commitments. A second crossgroup proof, of q_source - 1 - a_i, bounds a_i below q_source,
+// so no dealer can shift its contribution by a multiple of q_source. The new holders verify every dealer and
+// add up the shares they received.
+//
+// CAVEAT: the target secret is the integer sum y = a_1 + ... + a_t, reduced modulo q_target. It is congruent
+// to the source secret x modulo q_source, but y = x + k * q_source for a wrap count 0 <= k < t that nobody
+// learns, since computing it without revealing x needs a secure comparison that this package does not
+// implement. The target key therefore equals x * G_target only when k is zero.
+package crosscurve
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+	"github.com/etclab/kryptology/pkg/zkp/crossgroup"
+)
+
+const (
+	// proofLabel and rangeProofLabel separate the session ids of the two proofs of a dealer
+	proofLabel      = "share"
+	rangeProofLabel = "range"
+)
+
+// Config describes a resharing from the source curve to the target curve and must be the same for all parties
+type Config struct {
+	Source, Target *curves.Curve
+	// SourceThreshold and SourceLimit describe the existing sharing
+	SourceThreshold, SourceLimit uint32
+	// Threshold and Limit describe the new sharing on the target curve
+	Threshold, Limit uint32
+	// Quorum lists the ids of the source shares taking part, at least SourceThreshold of them
+	Quorum []uint32
+	// SessionId must be unique for each resharing
+	SessionId []byte
+}
+
+// Dealer is a holder of a source share in the quorum
+type Dealer struct {
+	config   *Config
+	id       uint32
+	weighted curves.Scalar
+}
+
+// DealerOutput is broadcast by a dealer to all new holders
+type DealerOutput struct {
+	Id uint32
+	// Verifier holds the target Feldman commitments, the first one is a_i * G_target
+	Verifier *sharing.FeldmanVerifier
+	Proof    *crossgroup.Proof
+	// RangeProof proves that q_source - 1 - a_i is in range, so a_i < q_source
+	RangeProof *crossgroup.Proof
+}
+
+// Validate checks that the configuration is usable
+func (c *Config) Validate() error {
+	if c == nil || c.Source == nil || c.Target == nil {
+		return fmt.Errorf("source and target curves are required")
+	}
+	if uint32(len(c.Quorum)) < c.SourceThreshold || len(c.Quorum) > int(c.SourceLimit) {
+		return fmt.Errorf("quorum must have between %d and %d members", c.SourceThreshold, c.SourceLimit)
+	}
+	seen := make(map[uint32]bool, len(c.Quorum))
+	for _, id := range c.Quorum {
+		if id == 0 || id > c.SourceLimit || seen[id] {
+			return fmt.Errorf("invalid quorum member %d", id)
+		}
+		seen[id] = true
+	}
+	if _, err := sharing.NewShamir(c.SourceThreshold, c.SourceLimit, c.Source); err != nil {
+		return errors.Wrap(err, "invalid source sharing")
+	}
+	if _, err := sharing.NewFeldman(c.Threshold, c.Limit, c.Target); err != nil {
+		return errors.Wrap(err, "invalid target sharing")
+	}
+	// The range proof bounds a_i only if a_i + (q_source - 1 - a_i) = q_source - 1 cannot hold modulo both
+	// orders for another pair of values in range, which needs distinct orders
+	if order(c.Source).Cmp(order(c.Target)) == 0 {
+		return fmt.Errorf("source and target curves must have different orders")
+	}
+	return nil
+}
+
+// NewDealer creates the dealer for the source `share`, whose id must be in the quorum
+func NewDealer(config *Config, share *sharing.ShamirShare) (*Dealer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if share == nil {
+		return nil, fmt.Errorf("invalid share")
+	}
+	if err := share.Validate(config.Source); err != nil {
+		return nil, errors.Wrap(err, "invalid share")
+	}
+	lambda, err := config.lagrange(share.Id)
+	if err != nil {
+		return nil, err
+	}
+	s, err := config.Source.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid share")
+	}
+	return &Dealer{config: config, id: share.Id, weighted: s.Mul(lambda)}, nil
+}
+
+// Deal shares the dealer's weighted share on the target curve. The output is broadcast
+// and shares[j] is sent privately to the new holder with id shares[j].Id
+func (d *Dealer) Deal(reader io.Reader) (*DealerOutput, []*sharing.ShamirShare, error) {
+	a := d.weighted.BigInt()
+	proof, err := crossgroup.Prove(a, d.config.sourceBits(), d.config.Source, d.config.Target, d.config.proofSessionId(d.id, proofLabel))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "proving weighted share")
+	}
+	complement := new(big.Int).Sub(maxWeighted(d.config.Source), a)
+	rangeProof, err := crossgroup.Prove(complement, d.config.sourceBits(), d.config.Source, d.config.Target, d.config.proofSessionId(d.id, rangeProofLabel))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "proving weighted share range")
+	}
+	feldman, err := sharing.NewFeldman(d.config.Threshold, d.config.Limit, d.config.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+	secret, err := d.config.Target.Scalar.SetBigInt(new(big.Int).Mod(a, order(d.config.Target)))
+	if err != nil {
+		return nil, nil, err
+	}
+	// Splitting zero is refused, it only happens with negligible probability for an honest share
+	verifier, shares, err := feldman.Split(secret, reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "sharing weighted share")
+	}
+	return &DealerOutput{Id: d.id, Verifier: verifier, Proof: proof, RangeProof: rangeProof}, shares, nil
+}
+
+// VerifyDealer checks a dealer's broadcast against the public commitments of the source sharing
+func VerifyDealer(config *Config, sourceVerifier *sharing.FeldmanVerifier, output *DealerOutput) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if output == nil || output.Verifier == nil || output.Proof == nil || output.RangeProof == nil {
+		return fmt.Errorf("invalid dealer output")
+	}
+	if len(output.Verifier.Commitments) != int(config.Threshold) {
+		return fmt.Errorf("dealer %d: expected %d commitments", output.Id, config.Threshold)
+	}
+	lambda, err := config.lagrange(output.Id)
+	if err != nil {
+		return err
+	}
+	publicShare, err := evaluate(sourceVerifier, config.Source, output.Id)
+	if err != nil {
+		return err
+	}
+	if !output.Proof.StatementA.Equal(publicShare.Mul(lambda)) {
+		return fmt.Errorf("dealer %d: proof is not about its weighted share", output.Id)
+	}
+	if !output.Proof.StatementB.Equal(output.Verifier.Commitments[0]) {
+		return fmt.Errorf("dealer %d: proof is not about its target commitments", output.Id)
+	}
+	if err = crossgroup.Verify(output.Proof, config.sourceBits(), config.Source, config.Target, config.proofSessionId(output.Id, proofLabel)); err != nil {
+		return errors.Wrapf(err, "dealer %d", output.Id)
+	}
+
+	// The range proof is about (q_source - 1) * G - a_i * G on both curves
+	maxSource, err := config.Source.Scalar.SetBigInt(maxWeighted(config.Source))
+	if err != nil {
+		return err
+	}
+	maxTarget, err := config.Target.Scalar.SetBigInt(new(big.Int).Mod(maxWeighted(config.Source), order(config.Target)))
+	if err != nil {
+		return err
+	}
+	if output.RangeProof.StatementA == nil || output.RangeProof.StatementB == nil ||
+		!output.RangeProof.StatementA.Equal(config.Source.ScalarBaseMult(maxSource).Sub(output.Proof.StatementA)) ||
+		!output.RangeProof.StatementB.Equal(config.Target.ScalarBaseMult(maxTarget).Sub(output.Proof.StatementB)) {
+		return fmt.Errorf("dealer %d: range proof is not about its weighted share", output.Id)
+	}
+	if err = crossgroup.Verify(output.RangeProof, config.sourceBits(), config.Source, config.Target, config.proofSessionId(output.Id, rangeProofLabel)); err != nil {
+		return errors.Wrapf(err, "dealer %d: range", output.Id)
+	}
+	return nil
+}
+
+// Combine computes the target share of the new holder `id` from the outputs of every dealer
+// in the quorum and the shares they sent to it. It also returns the combined target verifier,
+// whose first commitment is the target public key
+func Combine(config *Config, id uint32, sourceVerifier *sharing.FeldmanVerifier, outputs []*DealerOutput, shares []*sharing.ShamirShare) (*sharing.ShamirShare, *sharing.FeldmanVerifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if len(outputs) != len(config.Quorum) || len(shares) != len(config.Quorum) {
+		return nil, nil, fmt.Errorf("expected an output and a share from each of the %d dealers", len(config.Quorum))
+	}
+	byDealer := make(map[uint32]int, len(outputs))
+	for i, output := range outputs {
+		if output == nil {
+			return nil, nil, fmt.Errorf("invalid dealer output")
+		}
+		if _, ok := byDealer[output.Id]; ok {
+			return nil, nil, fmt.Errorf("duplicate output from dealer %d", output.Id)
+		}
+		byDealer[output.Id] = i
+	}
+
+	value := config.Target.Scalar.Zero()
+	commitments := make([]curves.Point, config.Threshold)
+	for i := range commitments {
+		commitments[i] = config.Target.NewIdentityPoint()
+	}
+	for _, dealer := range config.Quorum {
+		i, ok := byDealer[dealer]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing output from dealer %d", dealer)
+		}
+		output := outputs[i]
+		if err := VerifyDealer(config, sourceVerifier, output); err != nil {
+			return nil, nil, err
+		}
+		share := shares[i]
+		if share == nil || share.Id != id {
+			return nil, nil, fmt.Errorf("dealer %d: share is not for holder %d", dealer, id)
+		}
+		if err := output.Verifier.Verify(share); err != nil {
+			return nil, nil, errors.Wrapf(err, "dealer %d: invalid share", dealer)
+		}
+		s, err := config.Target.Scalar.SetBytes(share.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		value = value.Add(s)
+		for j, c := range output.Verifier.Commitments {
+			commitments[j] = commitments[j].Add(c)
+		}
+	}
+	return &sharing.ShamirShare{Id: id, Value: value.Bytes()}, &sharing.FeldmanVerifier{Commitments: commitments}, nil
+}
+
+// lagrange returns the Lagrange coefficient of `id` in the quorum
+func (c *Config) lagrange(id uint32) (curves.Scalar, error) {
+	shamir, err := sharing.NewShamir(c.SourceThreshold, c.SourceLimit, c.Source)
+	if err != nil {
+		return nil, err
+	}
+	coeffs, err := shamir.LagrangeCoeffs(c.Quorum)
+	if err != nil {
+		return nil, err
+	}
+	lambda, ok := coeffs[id]
+	if !ok {
+		return nil, fmt.Errorf("%d is not in the quorum", id)
+	}
+	return lambda, nil
+}
+
+// sourceBits is the bit length of the weighted shares
+func (c *Config) sourceBits() int {
+	return order(c.Source).BitLen()
+}
+
+// proofSessionId binds a dealer's proof to the resharing, the dealer and the kind of proof
+func (c *Config) proofSessionId(id uint32, label string) []byte {
+	quorum := append([]uint32{}, c.Quorum...)
+	sort.Slice(quorum, func(i, j int) bool { return quorum[i] < quorum[j] })
+	out := append([]byte("kryptology crosscurve v1"), c.SessionId...)
+	out = append(out, []byte(c.Source.Name)...)
+	out = append(out, []byte(c.Target.Name)...)
+	var buf [4]byte
+	for _, v := range append(quorum, id) {
+		binary.BigEndian.PutUint32(buf[:], v)
+		out = append(out, buf[:]...)
+	}
+	return append(out, label...)
+}
+
+// evaluate computes the public share s_id * G of the source sharing from its Feldman commitments
+func evaluate(verifier *sharing.FeldmanVerifier, curve *curves.Curve, id uint32) (curves.Point, error) {
+	if verifier == nil || len(verifier.Commitments) == 0 {
+		return nil, fmt.Errorf("invalid source verifier")
+	}
+	x := curve.ScalarFromIndex(id)
+	power := curve.Scalar.One()
+	result := curve.NewIdentityPoint()
+	for _, c := range verifier.Commitments {
+		if c == nil || c.CurveName() != curve.Name {
+			return nil, fmt.Errorf("invalid source verifier")
+		}
+		result = result.Add(c.Mul(power))
+		power = power.Mul(x)
+	}
+	return result, nil
+}
+
+// maxWeighted is the largest weighted share, q_source - 1
+func maxWeighted(source *curves.Curve) *big.Int {
+	return new(big.Int).Sub(order(source), big.NewInt(1))
+}
+
+func order(curve *curves.Curve) *big.Int {
+	return new(big.Int).Add(curve.Scalar.New(-1).BigInt(), big.NewInt(1))
+}
@@ -0,0 +1,195 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package crosscurve
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+	"github.com/etclab/kryptology/pkg/zkp/crossgroup"
+)
+
+type reshared struct {
+	config   *Config
+	source   *sharing.FeldmanVerifier
+	outputs  []*DealerOutput
+	received map[uint32][]*sharing.ShamirShare
+}
+
+func reshare(t *testing.T, secret curves.Scalar) *reshared {
+	source, target := curves.K256(), curves.P256()
+	feldman, err := sharing.NewFeldman(2, 3, source)
+	require.NoError(t, err)
+	sourceVerifier, sourceShares, err := feldman.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	config := &Config{
+		Source:          source,
+		Target:          target,
+		SourceThreshold: 2,
+		SourceLimit:     3,
+		Threshold:       2,
+		Limit:           3,
+		Quorum:          []uint32{1, 3},
+		SessionId:       []byte("crosscurve test"),
+	}
+	r := &reshared{config: config, source: sourceVerifier, received: map[uint32][]*sharing.ShamirShare{}}
+	for _, id := range config.Quorum {
+		dealer, err := NewDealer(config, sourceShares[id-1])
+		require.NoError(t, err)
+		output, shares, err := dealer.Deal(crand.Reader)
+		require.NoError(t, err)
+		require.NoError(t, VerifyDealer(config, sourceVerifier, output))
+		r.outputs = append(r.outputs, output)
+		for _, share := range shares {
+			r.received[share.Id] = append(r.received[share.Id], share)
+		}
+	}
+	return r
+}
+
+func TestCrossCurveReshare(t *testing.T) {
+	secret := curves.K256().Scalar.Random(crand.Reader)
+	r := reshare(t, secret)
+
+	newShares := make([]*sharing.ShamirShare, 0, 3)
+	var targetVerifier *sharing.FeldmanVerifier
+	for id := uint32(1); id <= 3; id++ {
+		share, verifier, err := Combine(r.config, id, r.source, r.outputs, r.received[id])
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(share))
+		if targetVerifier != nil {
+			require.True(t, targetVerifier.Commitments[0].Equal(verifier.Commitments[0]))
+		}
+		targetVerifier = verifier
+		newShares = append(newShares, share)
+	}
+
+	// Any two new shares reconstruct the same target secret y = x + k * q_source
+	feldman, err := sharing.NewFeldman(2, 3, r.config.Target)
+	require.NoError(t, err)
+	y, err := feldman.Combine(newShares[0], newShares[2])
+	require.NoError(t, err)
+	y2, err := feldman.Combine(newShares[1], newShares[2])
+	require.NoError(t, err)
+	require.Equal(t, y.Bytes(), y2.Bytes())
+	require.True(t, r.config.Target.ScalarBaseMult(y).Equal(targetVerifier.Commitments[0]))
+
+	// y = x + k * q_source modulo q_target, with a wrap count 0 <= k < 2
+	qs, qt := order(r.config.Source), order(r.config.Target)
+	wrapped := new(big.Int).Add(secret.BigInt(), qs)
+	require.Contains(t, []string{
+		new(big.Int).Mod(secret.BigInt(), qt).String(),
+		new(big.Int).Mod(wrapped, qt).String(),
+	}, y.BigInt().String())
+}
+
+func TestCrossCurveRejectsCheatingDealer(t *testing.T) {
+	r := reshare(t, curves.K256().Scalar.Random(crand.Reader))
+
+	// A dealer that shares a different value on the target curve
+	other, err := NewDealer(r.config, &sharing.ShamirShare{Id: 1, Value: curves.K256().Scalar.Random(crand.Reader).Bytes()})
+	require.NoError(t, err)
+	forged, shares, err := other.Deal(crand.Reader)
+	require.NoError(t, err)
+	require.Error(t, VerifyDealer(r.config, r.source, forged))
+
+	outputs := []*DealerOutput{forged, r.outputs[1]}
+	_, _, err = Combine(r.config, shares[0].Id, r.source, outputs, []*sharing.ShamirShare{shares[0], r.received[shares[0].Id][1]})
+	require.Error(t, err)
+
+	// A dealer's proof replayed under another dealer's id
+	replayed := *r.outputs[0]
+	replayed.Id = 3
+	require.Error(t, VerifyDealer(r.config, r.source, &replayed))
+
+	// A dealer output without its range proof
+	noRange := *r.outputs[0]
+	noRange.RangeProof = nil
+	require.Error(t, VerifyDealer(r.config, r.source, &noRange))
+
+	// The range proof of another dealer
+	swapped := *r.outputs[0]
+	swapped.RangeProof = r.outputs[1].RangeProof
+	require.Error(t, VerifyDealer(r.config, r.source, &swapped))
+
+	// A share that does not match the dealer's commitments
+	bad := *r.received[2][0]
+	bad.Value = r.config.Target.Scalar.Random(crand.Reader).Bytes()
+	_, _, err = Combine(r.config, 2, r.source, r.outputs, []*sharing.ShamirShare{&bad, r.received[2][1]})
+	require.Error(t, err)
+}
+
+func TestCrossCurveConfig(t *testing.T) {
+	config := &Config{
+		Source:          curves.K256(),
+		Target:          curves.P256(),
+		SourceThreshold: 2,
+		SourceLimit:     3,
+		Threshold:       2,
+		Limit:           3,
+		Quorum:          []uint32{1},
+	}
+	require.Error(t, config.Validate())
+	config.Quorum = []uint32{1, 1}
+	require.Error(t, config.Validate())
+	config.Quorum = []uint32{1, 4}
+	require.Error(t, config.Validate())
+	config.Quorum = []uint32{1, 2}
+	require.NoError(t, config.Validate())
+	config.Target = curves.K256()
+	require.Error(t, config.Validate())
+	config.Target = nil
+	require.Error(t, config.Validate())
+}
+
+func TestCrossCurveRejectsShiftedShare(t *testing.T) {
+	source, target := curves.K256(), curves.P256()
+	config := &Config{
+		Source:          source,
+		Target:          target,
+		SourceThreshold: 2,
+		SourceLimit:     3,
+		Threshold:       2,
+		Limit:           3,
+		Quorum:          []uint32{1, 3},
+		SessionId:       []byte("crosscurve test"),
+	}
+	// A source sharing where dealer 1 has the weighted share a = 5, so a + q_source still has sourceBits bits
+	lambda, err := config.lagrange(1)
+	require.NoError(t, err)
+	lambdaInv, err := lambda.Invert()
+	require.NoError(t, err)
+	s1 := source.Scalar.New(5).Mul(lambdaInv)
+	c1 := source.Scalar.Random(crand.Reader)
+	c0 := s1.Sub(c1)
+	sourceVerifier := &sharing.FeldmanVerifier{Commitments: []curves.Point{source.ScalarBaseMult(c0), source.ScalarBaseMult(c1)}}
+	dealer, err := NewDealer(config, &sharing.ShamirShare{Id: 1, Value: s1.Bytes()})
+	require.NoError(t, err)
+	honest, _, err := dealer.Deal(crand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, VerifyDealer(config, sourceVerifier, honest))
+
+	// The dealer shares a + q_source instead, which has the same discrete log on the source curve
+	shifted := new(big.Int).Add(big.NewInt(5), order(source))
+	proof, err := crossgroup.Prove(shifted, config.sourceBits(), source, target, config.proofSessionId(1, proofLabel))
+	require.NoError(t, err)
+	feldman, err := sharing.NewFeldman(2, 3, target)
+	require.NoError(t, err)
+	secret, err := target.Scalar.SetBigInt(new(big.Int).Mod(shifted, order(target)))
+	require.NoError(t, err)
+	verifier, _, err := feldman.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	forged := &DealerOutput{Id: 1, Verifier: verifier, Proof: proof, RangeProof: honest.RangeProof}
+	require.NoError(t, crossgroup.Verify(forged.Proof, config.sourceBits(), source, target, config.proofSessionId(1, proofLabel)))
+	require.Error(t, VerifyDealer(config, sourceVerifier, forged))
+}
@@ -130,3 +130,26 @@ func TestEd25519FeldmanAllCombinations(t *testing.T) {
 		}
 	}
 }
+
+func TestFeldmanIdentifiers(t *testing.T) {
+	curve := curves.ED25519()
+	scheme, err := NewFeldmanWithIdentifiers(2, []uint32{17, 4, 300}, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	verifier, shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	for _, share := range shares {
+		require.NoError(t, verifier.Verify(share))
+	}
+	actual, err := scheme.Combine(shares[2], shares[0])
+	require.NoError(t, err)
+	require.Equal(t, 0, secret.Cmp(actual))
+
+	// a share moved to another identifier does not verify
+	require.Error(t, verifier.Verify(&ShamirShare{Id: 18, Value: shares[0].Value}))
+
+	require.Equal(t, []uint32{17, 4, 300}, scheme.Identifiers())
+
+	_, err = NewFeldmanWithIdentifiers(2, []uint32{17, 17, 300}, curve)
+	require.Error(t, err)
+}
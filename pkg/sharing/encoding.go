@@ -0,0 +1,307 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// EncodingVersion is the version of the canonical encodings written by Encoder.
+// Decoders keep reading every earlier version.
+const EncodingVersion byte = 1
+
+// The kinds of values with a canonical encoding
+const (
+	EncodingShare            byte = 1
+	EncodingFeldmanVerifier  byte = 2
+	EncodingPedersenVerifier byte = 3
+	EncodingDkgOutput        byte = 4
+	EncodingNonceStore       byte = 5
+)
+
+// Encoder writes the canonical encoding of a value over a curve, which is
+//
+//	version (1 byte) || kind (1 byte) || len(curve name) (1 byte) || curve name || fields
+//
+// where integers are big-endian, scalars are their canonical bytes and points are compressed,
+// all with the fixed size of the curve
+type Encoder struct {
+	curve *curves.Curve
+	buf   []byte
+}
+
+// NewEncoder starts the encoding of a value of `kind` over `curve`
+func NewEncoder(kind byte, curve *curves.Curve) (*Encoder, error) {
+	if curve == nil || curves.GetCurveByName(curve.Name) == nil || len(curve.Name) > 255 {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	buf := []byte{EncodingVersion, kind, byte(len(curve.Name))}
+	return &Encoder{curve, append(buf, curve.Name...)}, nil
+}
+
+// Uint32 appends `v`
+func (e *Encoder) Uint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+// Scalar appends `s`, which must belong to the curve
+func (e *Encoder) Scalar(s curves.Scalar) error {
+	if s == nil {
+		return fmt.Errorf("invalid scalar")
+	}
+	b := s.Bytes()
+	if len(b) != len(e.curve.Scalar.One().Bytes()) {
+		return fmt.Errorf("scalar does not belong to curve %s", e.curve.Name)
+	}
+	e.buf = append(e.buf, b...)
+	return nil
+}
+
+// Point appends `p`, which must belong to the curve
+func (e *Encoder) Point(p curves.Point) error {
+	if p == nil || p.CurveName() != e.curve.Name {
+		return fmt.Errorf("point does not belong to curve %s", e.curve.Name)
+	}
+	e.buf = append(e.buf, p.ToAffineCompressed()...)
+	return nil
+}
+
+// Points appends the number of points, which cannot exceed 255, followed by the points
+func (e *Encoder) Points(points []curves.Point) error {
+	if len(points) > 255 {
+		return fmt.Errorf("cannot encode more than 255 points")
+	}
+	e.buf = append(e.buf, byte(len(points)))
+	for _, p := range points {
+		if err := e.Point(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bytes returns the encoding
+func (e *Encoder) Bytes() []byte {
+	return e.buf
+}
+
+// Decoder reads a canonical encoding written by Encoder. The first error is kept and returned by Finish
+type Decoder struct {
+	curve *curves.Curve
+	data  []byte
+	err   error
+}
+
+// NewDecoder reads the header of `data`, which must encode a value of `kind` over a known curve
+func NewDecoder(data []byte, kind byte) (*Decoder, error) {
+	if len(data) < 3 {
+		return nil, fmt.Errorf("invalid encoding length")
+	}
+	if data[0] == 0 || data[0] > EncodingVersion {
+		return nil, fmt.Errorf("unsupported encoding version %d", data[0])
+	}
+	if data[1] != kind {
+		return nil, fmt.Errorf("encoding is of kind %d, not %d", data[1], kind)
+	}
+	n := int(data[2])
+	if len(data) < 3+n {
+		return nil, fmt.Errorf("invalid encoding length")
+	}
+	curve := curves.GetCurveByName(string(data[3 : 3+n]))
+	if curve == nil {
+		return nil, fmt.Errorf("unknown curve %q", data[3:3+n])
+	}
+	return &Decoder{curve: curve, data: data[3+n:]}, nil
+}
+
+// Curve returns the curve of the encoded value
+func (d *Decoder) Curve() *curves.Curve {
+	return d.curve
+}
+
+// next returns the next `n` bytes
+func (d *Decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = fmt.Errorf("invalid encoding length")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+// Uint32 reads an integer
+func (d *Decoder) Uint32() uint32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+// Scalar reads a canonical scalar
+func (d *Decoder) Scalar() curves.Scalar {
+	b := d.next(len(d.curve.Scalar.One().Bytes()))
+	if b == nil {
+		return nil
+	}
+	s, err := d.curve.Scalar.SetBytes(b)
+	if err != nil || !bytes.Equal(s.Bytes(), b) {
+		d.err = fmt.Errorf("non-canonical scalar")
+		return nil
+	}
+	return s
+}
+
+// Point reads a canonical compressed point
+func (d *Decoder) Point() curves.Point {
+	b := d.next(len(d.curve.Point.Generator().ToAffineCompressed()))
+	if b == nil {
+		return nil
+	}
+	p, err := d.curve.Point.FromAffineCompressed(b)
+	if err != nil || !bytes.Equal(p.ToAffineCompressed(), b) {
+		d.err = fmt.Errorf("non-canonical point")
+		return nil
+	}
+	return p
+}
+
+// Points reads a number of points followed by the points
+func (d *Decoder) Points() []curves.Point {
+	b := d.next(1)
+	if b == nil {
+		return nil
+	}
+	points := make([]curves.Point, b[0])
+	for i := range points {
+		if points[i] = d.Point(); points[i] == nil {
+			return nil
+		}
+	}
+	return points
+}
+
+// Finish returns the first error, or an error if bytes are left
+func (d *Decoder) Finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) != 0 {
+		return fmt.Errorf("%d trailing bytes", len(d.data))
+	}
+	return nil
+}
+
+// MarshalShare returns the canonical encoding of the share of a secret on `curve`
+func MarshalShare(curve *curves.Curve, share *ShamirShare) ([]byte, error) {
+	if share == nil {
+		return nil, fmt.Errorf("invalid share")
+	}
+	e, err := NewEncoder(EncodingShare, curve)
+	if err != nil {
+		return nil, err
+	}
+	value, err := curve.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return nil, err
+	}
+	e.Uint32(share.Id)
+	if err = e.Scalar(value); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// UnmarshalShare reads a share written by MarshalShare, and returns it with its curve
+func UnmarshalShare(data []byte) (*curves.Curve, *ShamirShare, error) {
+	d, err := NewDecoder(data, EncodingShare)
+	if err != nil {
+		return nil, nil, err
+	}
+	id := d.Uint32()
+	value := d.Scalar()
+	if err = d.Finish(); err != nil {
+		return nil, nil, err
+	}
+	return d.Curve(), &ShamirShare{Id: id, Value: value.Bytes()}, nil
+}
+
+// MarshalFeldmanVerifier returns the canonical encoding of the verifier
+func MarshalFeldmanVerifier(v *FeldmanVerifier) ([]byte, error) {
+	if v == nil || len(v.Commitments) == 0 || v.Commitments[0] == nil {
+		return nil, fmt.Errorf("invalid verifier")
+	}
+	e, err := NewEncoder(EncodingFeldmanVerifier, curves.GetCurveByName(v.Commitments[0].CurveName()))
+	if err != nil {
+		return nil, err
+	}
+	if err = e.Points(v.Commitments); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// UnmarshalFeldmanVerifier reads a verifier written by MarshalFeldmanVerifier
+func UnmarshalFeldmanVerifier(data []byte) (*FeldmanVerifier, error) {
+	d, err := NewDecoder(data, EncodingFeldmanVerifier)
+	if err != nil {
+		return nil, err
+	}
+	commitments := d.Points()
+	if err = d.Finish(); err != nil {
+		return nil, err
+	}
+	if len(commitments) == 0 {
+		return nil, fmt.Errorf("verifier has no commitments")
+	}
+	return &FeldmanVerifier{Commitments: commitments}, nil
+}
+
+// MarshalPedersenVerifier returns the canonical encoding of the verifier
+func MarshalPedersenVerifier(v *PedersenVerifier) ([]byte, error) {
+	if v == nil || v.Generator == nil || len(v.Commitments) == 0 {
+		return nil, fmt.Errorf("invalid verifier")
+	}
+	e, err := NewEncoder(EncodingPedersenVerifier, curves.GetCurveByName(v.Generator.CurveName()))
+	if err != nil {
+		return nil, err
+	}
+	if err = e.Point(v.Generator); err != nil {
+		return nil, err
+	}
+	if err = e.Points(v.Commitments); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// UnmarshalPedersenVerifier reads a verifier written by MarshalPedersenVerifier
+func UnmarshalPedersenVerifier(data []byte) (*PedersenVerifier, error) {
+	d, err := NewDecoder(data, EncodingPedersenVerifier)
+	if err != nil {
+		return nil, err
+	}
+	generator := d.Point()
+	commitments := d.Points()
+	if err = d.Finish(); err != nil {
+		return nil, err
+	}
+	if len(commitments) == 0 {
+		return nil, fmt.Errorf("verifier has no commitments")
+	}
+	return &PedersenVerifier{Generator: generator, Commitments: commitments}, nil
+}
@@ -0,0 +1,86 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestMarshalShare(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.ED25519(), curves.P256(), curves.BLS12381G1()} {
+		share := &ShamirShare{Id: 7, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+		data, err := MarshalShare(curve, share)
+		require.NoError(t, err)
+		require.Equal(t, EncodingVersion, data[0])
+		require.Equal(t, EncodingShare, data[1])
+		decodedCurve, decoded, err := UnmarshalShare(data)
+		require.NoError(t, err)
+		require.Equal(t, curve.Name, decodedCurve.Name)
+		require.Equal(t, share, decoded)
+
+		// truncated, extended and mislabeled encodings are rejected
+		_, _, err = UnmarshalShare(data[:len(data)-1])
+		require.Error(t, err)
+		_, _, err = UnmarshalShare(append(data, 0))
+		require.Error(t, err)
+		_, err = UnmarshalFeldmanVerifier(data)
+		require.Error(t, err)
+	}
+
+	// the encoding is stable across versions of the library
+	data, err := MarshalShare(curves.K256(), &ShamirShare{Id: 2, Value: curves.K256().Scalar.New(5).Bytes()})
+	require.NoError(t, err)
+	require.Equal(t, "010109736563703235366b3100000002"+
+		"0000000000000000000000000000000000000000000000000000000000000005", hex.EncodeToString(data))
+
+	future := append([]byte{}, data...)
+	future[0] = EncodingVersion + 1
+	_, _, err = UnmarshalShare(future)
+	require.Error(t, err)
+	unknown := append([]byte{1, EncodingShare, 3}, "foo"...)
+	_, _, err = UnmarshalShare(append(unknown, data[12:]...))
+	require.Error(t, err)
+	_, err = MarshalShare(nil, &ShamirShare{Id: 2})
+	require.Error(t, err)
+}
+
+func TestMarshalVerifiers(t *testing.T) {
+	curve := curves.P256()
+	generator, err := PedersenGenerator(curve, []byte("encoding test"))
+	require.NoError(t, err)
+	pedersen, err := NewPedersen(3, 5, generator)
+	require.NoError(t, err)
+	result, err := pedersen.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+
+	data, err := MarshalFeldmanVerifier(result.FeldmanVerifier)
+	require.NoError(t, err)
+	feldman, err := UnmarshalFeldmanVerifier(data)
+	require.NoError(t, err)
+	require.Len(t, feldman.Commitments, 3)
+	require.NoError(t, feldman.Verify(result.SecretShares[2]))
+
+	data, err = MarshalPedersenVerifier(result.PedersenVerifier)
+	require.NoError(t, err)
+	verifier, err := UnmarshalPedersenVerifier(data)
+	require.NoError(t, err)
+	require.True(t, verifier.Generator.Equal(generator))
+	require.NoError(t, verifier.Verify(result.SecretShares[4], result.BlindingShares[4]))
+
+	// a corrupted point is rejected
+	data[len(data)-33] = 0xff
+	_, err = UnmarshalPedersenVerifier(data)
+	require.Error(t, err)
+	_, err = MarshalFeldmanVerifier(&FeldmanVerifier{})
+	require.Error(t, err)
+}
@@ -23,7 +23,7 @@ func (v FeldmanVerifier) Verify(share *ShamirShare) error {
 	if err != nil {
 		return err
 	}
-	x := curve.Scalar.New(int(share.Id))
+	x := curve.ScalarFromIndex(share.Id)
 	i := curve.Scalar.One()
 	rhs := v.Commitments[0]
 
@@ -46,6 +46,12 @@ type Feldman struct {
 	Curve            *curves.Curve
 }
 
+// IdentifiedFeldman is a Feldman VSS whose shares have chosen identifiers as x-coordinates
+type IdentifiedFeldman struct {
+	Feldman
+	identifiers []uint32
+}
+
 func NewFeldman(threshold, limit uint32, curve *curves.Curve) (*Feldman, error) {
 	if limit < threshold {
 		return nil, fmt.Errorf("limit cannot be less than threshold")
@@ -62,15 +68,24 @@ func NewFeldman(threshold, limit uint32, curve *curves.Curve) (*Feldman, error)
 	return &Feldman{threshold, limit, curve}, nil
 }
 
+// NewFeldmanWithIdentifiers creates a Feldman VSS whose shares have the given identifiers
+// as x-coordinates instead of 1,...,limit
+func NewFeldmanWithIdentifiers(threshold uint32, identifiers []uint32, curve *curves.Curve) (*IdentifiedFeldman, error) {
+	shamir, err := NewShamirWithIdentifiers(threshold, identifiers, curve)
+	if err != nil {
+		return nil, err
+	}
+	return &IdentifiedFeldman{Feldman{threshold, shamir.limit, curve}, shamir.identifiers}, nil
+}
+
 func (f Feldman) Split(secret curves.Scalar, reader io.Reader) (*FeldmanVerifier, []*ShamirShare, error) {
+	return f.split(f.shamir(), secret, reader)
+}
+
+func (f Feldman) split(shamir *Shamir, secret curves.Scalar, reader io.Reader) (*FeldmanVerifier, []*ShamirShare, error) {
 	if secret.IsZero() {
 		return nil, nil, fmt.Errorf("invalid secret")
 	}
-	shamir := &Shamir{
-		threshold: f.Threshold,
-		limit:     f.Limit,
-		curve:     f.Curve,
-	}
 	shares, poly := shamir.getPolyAndShares(secret, reader)
 	verifier := new(FeldmanVerifier)
 	verifier.Commitments = make([]curves.Point, f.Threshold)
@@ -81,32 +96,60 @@ func (f Feldman) Split(secret curves.Scalar, reader io.Reader) (*FeldmanVerifier
 }
 
 func (f Feldman) LagrangeCoeffs(shares map[uint32]*ShamirShare) (map[uint32]curves.Scalar, error) {
-	shamir := &Shamir{
-		threshold: f.Threshold,
-		limit:     f.Limit,
-		curve:     f.Curve,
-	}
-	identities := make([]uint32, 0)
-	for _, xi := range shares {
-		identities = append(identities, xi.Id)
-	}
-	return shamir.LagrangeCoeffs(identities)
+	return lagrangeCoeffsOf(f.shamir(), shares)
 }
 
 func (f Feldman) Combine(shares ...*ShamirShare) (curves.Scalar, error) {
-	shamir := &Shamir{
-		threshold: f.Threshold,
-		limit:     f.Limit,
-		curve:     f.Curve,
-	}
-	return shamir.Combine(shares...)
+	return f.shamir().Combine(shares...)
 }
 
 func (f Feldman) CombinePoints(shares ...*ShamirShare) (curves.Point, error) {
-	shamir := &Shamir{
+	return f.shamir().CombinePoints(shares...)
+}
+
+func (f Feldman) shamir() *Shamir {
+	return &Shamir{
 		threshold: f.Threshold,
 		limit:     f.Limit,
 		curve:     f.Curve,
 	}
-	return shamir.CombinePoints(shares...)
+}
+
+// Identifiers returns the identifiers of the shares
+func (f IdentifiedFeldman) Identifiers() []uint32 {
+	return copyIdentifiers(f.identifiers)
+}
+
+func (f IdentifiedFeldman) Split(secret curves.Scalar, reader io.Reader) (*FeldmanVerifier, []*ShamirShare, error) {
+	return f.split(f.shamir(), secret, reader)
+}
+
+func (f IdentifiedFeldman) LagrangeCoeffs(shares map[uint32]*ShamirShare) (map[uint32]curves.Scalar, error) {
+	return lagrangeCoeffsOf(f.shamir(), shares)
+}
+
+func (f IdentifiedFeldman) Combine(shares ...*ShamirShare) (curves.Scalar, error) {
+	return f.shamir().Combine(shares...)
+}
+
+func (f IdentifiedFeldman) CombinePoints(shares ...*ShamirShare) (curves.Point, error) {
+	return f.shamir().CombinePoints(shares...)
+}
+
+func (f IdentifiedFeldman) shamir() *Shamir {
+	return &Shamir{
+		threshold:   f.Threshold,
+		limit:       f.Limit,
+		curve:       f.Curve,
+		identifiers: f.identifiers,
+	}
+}
+
+// lagrangeCoeffsOf returns the Lagrange coefficients of the identifiers of `shares`
+func lagrangeCoeffsOf(shamir *Shamir, shares map[uint32]*ShamirShare) (map[uint32]curves.Scalar, error) {
+	identities := make([]uint32, 0)
+	for _, xi := range shares {
+		identities = append(identities, xi.Id)
+	}
+	return shamir.LagrangeCoeffs(identities)
 }
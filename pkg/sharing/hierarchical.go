@@ -0,0 +1,104 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+)
+
+// WeightedParty is a party of a weighted threshold policy, which holds Weight shares
+type WeightedParty struct {
+	Name   string
+	Weight uint32
+}
+
+// Weighted returns a node that requires parties whose weights add up to at least `threshold`.
+// Each party appears in as many leaves as its weight, so it holds one share per unit of weight,
+// and the total weight cannot exceed 255. For example
+//
+//	Weighted(3, WeightedParty{"CEO", 2}, WeightedParty{"CFO", 2}, WeightedParty{"eng1", 1}, WeightedParty{"eng2", 1})
+//
+// lets the CEO or the CFO with anybody else, or both engineers with the CEO or the CFO, recover the secret.
+func Weighted(threshold uint32, parties ...WeightedParty) *AccessNode {
+	var children []*AccessNode
+	for _, p := range parties {
+		for i := uint32(0); i < p.Weight; i++ {
+			children = append(children, Party(p.Name))
+		}
+	}
+	return Threshold(threshold, children...)
+}
+
+// HierarchicalRule requires Upper parties of the upper level and Lower parties of the lower level
+type HierarchicalRule struct {
+	Upper, Lower uint32
+}
+
+// HierarchicalPolicy is a two-level policy which authorizes a set of parties if it satisfies any of the Rules.
+// For example "2 executives OR 1 executive + 3 engineers" is
+//
+//	HierarchicalPolicy{
+//		Upper: []string{"exec1", "exec2", "exec3"},
+//		Lower: []string{"eng1", "eng2", "eng3", "eng4"},
+//		Rules: []HierarchicalRule{{Upper: 2}, {Upper: 1, Lower: 3}},
+//	}
+type HierarchicalPolicy struct {
+	Upper, Lower []string
+	Rules        []HierarchicalRule
+}
+
+// AccessNode returns the access structure of the policy, which can be used with NewAccessStructure
+func (p HierarchicalPolicy) AccessNode() (*AccessNode, error) {
+	if len(p.Rules) == 0 {
+		return nil, fmt.Errorf("policy has no rules")
+	}
+	seen := make(map[string]bool, len(p.Upper)+len(p.Lower))
+	for _, name := range append(append([]string{}, p.Upper...), p.Lower...) {
+		if seen[name] {
+			return nil, fmt.Errorf("party %s appears more than once", name)
+		}
+		seen[name] = true
+	}
+
+	rules := make([]*AccessNode, len(p.Rules))
+	for i, rule := range p.Rules {
+		if rule.Upper == 0 && rule.Lower == 0 {
+			return nil, fmt.Errorf("rule %d requires no parties", i)
+		}
+		if rule.Upper > uint32(len(p.Upper)) || rule.Lower > uint32(len(p.Lower)) {
+			return nil, fmt.Errorf("rule %d requires more parties than the levels have", i)
+		}
+		var levels []*AccessNode
+		if rule.Upper > 0 {
+			levels = append(levels, Threshold(rule.Upper, parties(p.Upper)...))
+		}
+		if rule.Lower > 0 {
+			levels = append(levels, Threshold(rule.Lower, parties(p.Lower)...))
+		}
+		rules[i] = levels[0]
+		if len(levels) > 1 {
+			rules[i] = And(levels...)
+		}
+	}
+	root := rules[0]
+	if len(rules) > 1 {
+		root = Or(rules...)
+	}
+	if err := root.Validate(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// parties returns a leaf for every name
+func parties(names []string) []*AccessNode {
+	leaves := make([]*AccessNode, len(names))
+	for i, name := range names {
+		leaves[i] = Party(name)
+	}
+	return leaves
+}
@@ -0,0 +1,91 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestWeighted(t *testing.T) {
+	curve := curves.K256()
+	policy := Weighted(3, WeightedParty{"CEO", 2}, WeightedParty{"CFO", 2}, WeightedParty{"eng1", 1}, WeightedParty{"eng2", 1})
+	require.NoError(t, policy.Validate())
+	require.True(t, policy.Satisfied("CEO", "CFO"))
+	require.True(t, policy.Satisfied("CFO", "eng2"))
+	require.False(t, policy.Satisfied("CEO"))
+	require.False(t, policy.Satisfied("eng1", "eng2"))
+
+	scheme, err := NewAccessStructure(policy, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	verifier, shares, err := scheme.SplitVerifiable(secret, crand.Reader)
+	require.NoError(t, err)
+	require.Len(t, shares, 6)
+	require.Len(t, sharesOf(shares, "CEO"), 2)
+	for _, share := range shares {
+		require.NoError(t, scheme.Verify(verifier, share))
+	}
+
+	recovered, err := scheme.Combine(sharesOf(shares, "CEO", "eng1")...)
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+	_, err = scheme.Combine(sharesOf(shares, "eng1", "eng2")...)
+	require.Error(t, err)
+
+	// the total weight is limited like the number of Shamir shares
+	require.Error(t, Weighted(2, WeightedParty{"a", 200}, WeightedParty{"b", 100}).Validate())
+	require.Error(t, Weighted(5, WeightedParty{"a", 2}, WeightedParty{"b", 2}).Validate())
+}
+
+func TestHierarchicalPolicy(t *testing.T) {
+	curve := curves.ED25519()
+	policy := HierarchicalPolicy{
+		Upper: []string{"exec1", "exec2", "exec3"},
+		Lower: []string{"eng1", "eng2", "eng3", "eng4"},
+		Rules: []HierarchicalRule{{Upper: 2}, {Upper: 1, Lower: 3}},
+	}
+	root, err := policy.AccessNode()
+	require.NoError(t, err)
+	require.Equal(t, "2 of (exec1, exec2, exec3) OR ((exec1 OR exec2 OR exec3) AND 3 of (eng1, eng2, eng3, eng4))", root.String())
+
+	scheme, err := NewAccessStructure(root, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	for _, parties := range [][]string{
+		{"exec1", "exec3"},
+		{"exec2", "eng1", "eng2", "eng4"},
+	} {
+		recovered, err := scheme.Combine(sharesOf(shares, parties...)...)
+		require.NoError(t, err)
+		require.Equal(t, 0, recovered.Cmp(secret), parties)
+	}
+	for _, parties := range [][]string{
+		{"exec1", "eng1", "eng2"},
+		{"eng1", "eng2", "eng3", "eng4"},
+	} {
+		require.False(t, root.Satisfied(parties...))
+		_, err = scheme.Combine(sharesOf(shares, parties...)...)
+		require.Error(t, err, parties)
+	}
+
+	for _, invalid := range []HierarchicalPolicy{
+		{Upper: policy.Upper, Lower: policy.Lower},
+		{Upper: policy.Upper, Lower: policy.Lower, Rules: []HierarchicalRule{{}}},
+		{Upper: policy.Upper, Lower: policy.Lower, Rules: []HierarchicalRule{{Upper: 4}}},
+		{Upper: policy.Upper, Lower: []string{"exec1"}, Rules: policy.Rules},
+	} {
+		_, err = invalid.AccessNode()
+		require.Error(t, err)
+	}
+}
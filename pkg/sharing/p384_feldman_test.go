@@ -0,0 +1,31 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestFeldmanP384(t *testing.T) {
+	curve := curves.P384()
+	scheme, err := NewFeldman(2, 3, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	verifiers, shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	for _, s := range shares {
+		require.NoError(t, verifiers.Verify(s))
+	}
+	rSecret, err := scheme.Combine(shares[0], shares[2])
+	require.NoError(t, err)
+	require.Equal(t, 0, secret.Cmp(rSecret))
+}
@@ -18,6 +18,8 @@ type Pedersen struct {
 	threshold, limit uint32
 	curve            *curves.Curve
 	generator        curves.Point
+	// identifiers are the x-coordinates of the shares, or 1,...,limit if nil
+	identifiers []uint32
 }
 
 type PedersenVerifier struct {
@@ -34,7 +36,7 @@ func (pv PedersenVerifier) Verify(share, blindShare *ShamirShare) error {
 		return err
 	}
 
-	x := curve.Scalar.New(int(share.Id))
+	x := curve.ScalarFromIndex(share.Id)
 	i := curve.Scalar.One()
 	rhs := pv.Commitments[0]
 
@@ -85,15 +87,48 @@ func NewPedersen(threshold, limit uint32, generator curves.Point) (*Pedersen, er
 	if !generator.IsOnCurve() || generator.IsIdentity() {
 		return nil, fmt.Errorf("invalid generator")
 	}
-	return &Pedersen{threshold, limit, curve, generator}, nil
+	return &Pedersen{threshold, limit, curve, generator, nil}, nil
+}
+
+// NewPedersenWithIdentifiers creates a pedersen VSS whose shares have the given identifiers
+// as x-coordinates instead of 1,...,limit
+func NewPedersenWithIdentifiers(threshold uint32, identifiers []uint32, generator curves.Point) (*Pedersen, error) {
+	if err := checkIdentifiers(identifiers); err != nil {
+		return nil, err
+	}
+	pd, err := NewPedersen(threshold, uint32(len(identifiers)), generator)
+	if err != nil {
+		return nil, err
+	}
+	pd.identifiers = copyIdentifiers(identifiers)
+	return pd, nil
+}
+
+func (pd Pedersen) shamir() *Shamir {
+	return &Shamir{pd.threshold, pd.limit, pd.curve, pd.identifiers}
+}
+
+// PedersenGenerator derives the blinding generator for NewPedersen from `label`
+// with curves.DeriveGenerators, so verifiers can check nobody knows its discrete log
+// with curves.VerifyGenerators
+func PedersenGenerator(curve *curves.Curve, label []byte) (curves.Point, error) {
+	generators, err := curves.DeriveGenerators(curve, label, 1)
+	if err != nil {
+		return nil, err
+	}
+	return generators[0], nil
 }
 
 // Split creates the verifiers, blinding and shares
 func (pd Pedersen) Split(secret curves.Scalar, reader io.Reader) (*PedersenResult, error) {
 	// generate a random blinding factor
 	blinding := pd.curve.Scalar.Random(reader)
+	return pd.splitWithBlinding(secret, blinding, reader)
+}
 
-	shamir := Shamir{pd.threshold, pd.limit, pd.curve}
+// splitWithBlinding is Split with the given blinding factor
+func (pd Pedersen) splitWithBlinding(secret, blinding curves.Scalar, reader io.Reader) (*PedersenResult, error) {
+	shamir := pd.shamir()
 	// split the secret into shares
 	shares, poly := shamir.getPolyAndShares(secret, reader)
 
@@ -121,11 +156,7 @@ func (pd Pedersen) Split(secret curves.Scalar, reader io.Reader) (*PedersenResul
 }
 
 func (pd Pedersen) LagrangeCoeffs(shares map[uint32]*ShamirShare) (map[uint32]curves.Scalar, error) {
-	shamir := &Shamir{
-		threshold: pd.threshold,
-		limit:     pd.limit,
-		curve:     pd.curve,
-	}
+	shamir := pd.shamir()
 	identities := make([]uint32, 0)
 	for _, xi := range shares {
 		identities = append(identities, xi.Id)
@@ -134,19 +165,11 @@ func (pd Pedersen) LagrangeCoeffs(shares map[uint32]*ShamirShare) (map[uint32]cu
 }
 
 func (pd Pedersen) Combine(shares ...*ShamirShare) (curves.Scalar, error) {
-	shamir := &Shamir{
-		threshold: pd.threshold,
-		limit:     pd.limit,
-		curve:     pd.curve,
-	}
+	shamir := pd.shamir()
 	return shamir.Combine(shares...)
 }
 
 func (pd Pedersen) CombinePoints(shares ...*ShamirShare) (curves.Point, error) {
-	shamir := &Shamir{
-		threshold: pd.threshold,
-		limit:     pd.limit,
-		curve:     pd.curve,
-	}
+	shamir := pd.shamir()
 	return shamir.CombinePoints(shares...)
 }
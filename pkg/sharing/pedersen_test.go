@@ -0,0 +1,58 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestPedersenPasta(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.PALLAS(), curves.VESTA()} {
+		generator, err := PedersenGenerator(curve, []byte("pedersen test"))
+		require.NoError(t, err, curve.Name)
+		scheme, err := NewPedersen(2, 3, generator)
+		require.NoError(t, err, curve.Name)
+
+		secret := curve.Scalar.Random(crand.Reader)
+		result, err := scheme.Split(secret, crand.Reader)
+		require.NoError(t, err, curve.Name)
+		for i, s := range result.SecretShares {
+			require.NoError(t, result.PedersenVerifier.Verify(s, result.BlindingShares[i]), curve.Name)
+			require.NoError(t, result.FeldmanVerifier.Verify(s), curve.Name)
+		}
+		rSecret, err := scheme.Combine(result.SecretShares[0], result.SecretShares[2])
+		require.NoError(t, err, curve.Name)
+		require.Equal(t, 0, secret.Cmp(rSecret), curve.Name)
+	}
+}
+
+func TestPedersenIdentifiers(t *testing.T) {
+	curve := curves.K256()
+	generator, err := PedersenGenerator(curve, []byte("identifiers test"))
+	require.NoError(t, err)
+	ids := []uint32{DeriveIdentifier([]byte("alice")), DeriveIdentifier([]byte("bob")), DeriveIdentifier([]byte("carol"))}
+	scheme, err := NewPedersenWithIdentifiers(2, ids, generator)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	result, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	for i, share := range result.SecretShares {
+		require.Equal(t, ids[i], share.Id)
+		require.NoError(t, result.PedersenVerifier.Verify(share, result.BlindingShares[i]))
+	}
+	actual, err := scheme.Combine(result.SecretShares[1], result.SecretShares[2])
+	require.NoError(t, err)
+	require.Equal(t, 0, secret.Cmp(actual))
+
+	_, err = NewPedersenWithIdentifiers(2, []uint32{ids[0], ids[0]}, generator)
+	require.Error(t, err)
+}
@@ -0,0 +1,439 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// ReshareConfig describes the redistribution of a (OldThreshold, OldLimit) sharing to a new
+// (Threshold, Limit) sharing of the same secret, and must be the same for all parties.
+//
+// Every holder i of an old share in the quorum acts as a dealer: it shares s_i with verifiable secret
+// sharing among the new holders, and commits to s_i in the constant term, which everybody checks
+// against the commitments of the old sharing. New holder j then computes s'_j = Σ λ_i s_{i,j} for the
+// Lagrange coefficients λ_i of the quorum, so the secret is never reconstructed. The old shares must
+// be erased once the new ones are verified.
+type ReshareConfig struct {
+	Curve *curves.Curve
+	// OldThreshold and OldLimit describe the existing sharing
+	OldThreshold, OldLimit uint32
+	// Threshold and Limit describe the new sharing
+	Threshold, Limit uint32
+	// Quorum lists the ids of the old shares taking part, at least OldThreshold of them
+	Quorum []uint32
+	// OldIdentifiers and Identifiers are the identifiers of the old and new shares, as given to
+	// NewShamirWithIdentifiers, or 1,...,OldLimit and 1,...,Limit if nil
+	OldIdentifiers, Identifiers []uint32
+}
+
+// ReshareDealer is a holder of an old share in the quorum
+type ReshareDealer struct {
+	config   *ReshareConfig
+	share    curves.Scalar
+	blinding curves.Scalar
+	id       uint32
+}
+
+// ReshareOutput is broadcast by a dealer to all new holders when resharing a Feldman sharing
+type ReshareOutput struct {
+	Id uint32
+	// Verifier holds the commitments to the dealer's polynomial, the first one is s_i * G
+	Verifier *FeldmanVerifier
+}
+
+// PedersenReshareOutput is broadcast by a dealer to all new holders when resharing a Pedersen sharing
+type PedersenReshareOutput struct {
+	Id uint32
+	// Verifier holds the commitments to the dealer's polynomials, the first one is s_i * G + b_i * H
+	Verifier *PedersenVerifier
+}
+
+// Validate checks that the configuration is usable
+func (c *ReshareConfig) Validate() error {
+	if c == nil || c.Curve == nil {
+		return fmt.Errorf("invalid curve")
+	}
+	old, err := c.oldShamir()
+	if err != nil {
+		return fmt.Errorf("invalid old sharing: %v", err)
+	}
+	if _, err = newReshareShamir(c.Threshold, c.Limit, c.Identifiers, c.Curve); err != nil {
+		return fmt.Errorf("invalid new sharing: %v", err)
+	}
+	if uint32(len(c.Quorum)) < c.OldThreshold || len(c.Quorum) > int(c.OldLimit) {
+		return fmt.Errorf("quorum must have between %d and %d members", c.OldThreshold, c.OldLimit)
+	}
+	seen := make(map[uint32]bool, len(c.Quorum))
+	for _, id := range c.Quorum {
+		if id == 0 || !old.validIdentifier(id) || seen[id] {
+			return fmt.Errorf("invalid quorum member %d", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// oldShamir returns the old sharing
+func (c *ReshareConfig) oldShamir() (*Shamir, error) {
+	return newReshareShamir(c.OldThreshold, c.OldLimit, c.OldIdentifiers, c.Curve)
+}
+
+// newReshareShamir creates the sharing with the given identifiers, or 1,...,limit if nil
+func newReshareShamir(threshold, limit uint32, identifiers []uint32, curve *curves.Curve) (*Shamir, error) {
+	if identifiers == nil {
+		return NewShamir(threshold, limit, curve)
+	}
+	if len(identifiers) != int(limit) {
+		return nil, fmt.Errorf("expected %d identifiers, got %d", limit, len(identifiers))
+	}
+	return NewShamirWithIdentifiers(threshold, identifiers, curve)
+}
+
+// NewReshareDealer creates the dealer for the old Feldman or Shamir `share`, whose id must be in the quorum
+func NewReshareDealer(config *ReshareConfig, share *ShamirShare) (*ReshareDealer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	s, err := config.dealerShare(share)
+	if err != nil {
+		return nil, err
+	}
+	return &ReshareDealer{config: config, share: s, id: share.Id}, nil
+}
+
+// NewPedersenReshareDealer creates the dealer for the old Pedersen `share` and `blindingShare`,
+// whose id must be in the quorum
+func NewPedersenReshareDealer(config *ReshareConfig, share, blindingShare *ShamirShare) (*ReshareDealer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	s, err := config.dealerShare(share)
+	if err != nil {
+		return nil, err
+	}
+	b, err := config.dealerShare(blindingShare)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blinding share: %v", err)
+	}
+	if blindingShare.Id != share.Id {
+		return nil, fmt.Errorf("share and blinding share have different ids")
+	}
+	return &ReshareDealer{config: config, share: s, blinding: b, id: share.Id}, nil
+}
+
+// Deal shares the dealer's old share with Feldman VSS. The output is broadcast
+// and shares[j] is sent privately to the new holder with id shares[j].Id
+func (d *ReshareDealer) Deal(reader io.Reader) (*ReshareOutput, []*ShamirShare, error) {
+	shamir, err := newReshareShamir(d.config.Threshold, d.config.Limit, d.config.Identifiers, d.config.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	feldman := Feldman{d.config.Threshold, d.config.Limit, d.config.Curve}
+	verifier, shares, err := feldman.split(shamir, d.share, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ReshareOutput{Id: d.id, Verifier: verifier}, shares, nil
+}
+
+// DealPedersen shares the dealer's old share and blinding share with Pedersen VSS for the blinding generator
+// of the old sharing. The output is broadcast and shares[j] and blindingShares[j] are sent privately to the new
+// holder with id shares[j].Id
+func (d *ReshareDealer) DealPedersen(generator curves.Point, reader io.Reader) (*PedersenReshareOutput, []*ShamirShare, []*ShamirShare, error) {
+	if d.blinding == nil {
+		return nil, nil, nil, fmt.Errorf("dealer has no blinding share")
+	}
+	if generator == nil {
+		return nil, nil, nil, fmt.Errorf("invalid generator")
+	}
+	var pedersen *Pedersen
+	var err error
+	if d.config.Identifiers == nil {
+		pedersen, err = NewPedersen(d.config.Threshold, d.config.Limit, generator)
+	} else {
+		pedersen, err = NewPedersenWithIdentifiers(d.config.Threshold, d.config.Identifiers, generator)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	result, err := pedersen.splitWithBlinding(d.share, d.blinding, reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &PedersenReshareOutput{Id: d.id, Verifier: result.PedersenVerifier}, result.SecretShares, result.BlindingShares, nil
+}
+
+// VerifyReshareOutput checks a dealer's broadcast against the Feldman commitments of the old sharing
+func VerifyReshareOutput(config *ReshareConfig, oldVerifier *FeldmanVerifier, output *ReshareOutput) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if oldVerifier == nil || output == nil || output.Verifier == nil {
+		return fmt.Errorf("invalid dealer output")
+	}
+	if err := config.checkDealer(output.Id, output.Verifier.Commitments); err != nil {
+		return err
+	}
+	publicShare, err := config.evaluate(oldVerifier.Commitments, config.OldThreshold, output.Id)
+	if err != nil {
+		return err
+	}
+	if !output.Verifier.Commitments[0].Equal(publicShare) {
+		return fmt.Errorf("dealer %d: commitments are not to its old share", output.Id)
+	}
+	return nil
+}
+
+// VerifyPedersenReshareOutput checks a dealer's broadcast against the Pedersen commitments of the old sharing
+func VerifyPedersenReshareOutput(config *ReshareConfig, oldVerifier *PedersenVerifier, output *PedersenReshareOutput) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if oldVerifier == nil || oldVerifier.Generator == nil || output == nil || output.Verifier == nil || output.Verifier.Generator == nil {
+		return fmt.Errorf("invalid dealer output")
+	}
+	if !output.Verifier.Generator.Equal(oldVerifier.Generator) {
+		return fmt.Errorf("dealer %d: wrong blinding generator", output.Id)
+	}
+	if err := config.checkDealer(output.Id, output.Verifier.Commitments); err != nil {
+		return err
+	}
+	commitment, err := config.evaluate(oldVerifier.Commitments, config.OldThreshold, output.Id)
+	if err != nil {
+		return err
+	}
+	if !output.Verifier.Commitments[0].Equal(commitment) {
+		return fmt.Errorf("dealer %d: commitments are not to its old share", output.Id)
+	}
+	return nil
+}
+
+// CombineReshare computes the new share of the holder `id` from the outputs of every dealer in the quorum
+// and the shares they sent to it. It also returns the Feldman verifier of the new sharing,
+// whose first commitment is the same public key as the old one
+func CombineReshare(config *ReshareConfig, id uint32, oldVerifier *FeldmanVerifier, outputs []*ReshareOutput, shares []*ShamirShare) (*ShamirShare, *FeldmanVerifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if len(outputs) != len(config.Quorum) || len(shares) != len(config.Quorum) {
+		return nil, nil, fmt.Errorf("expected an output and a share from each of the %d dealers", len(config.Quorum))
+	}
+	if err := config.checkHolder(id); err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint32, len(outputs))
+	for i, output := range outputs {
+		if output == nil {
+			return nil, nil, fmt.Errorf("invalid dealer output")
+		}
+		ids[i] = output.Id
+	}
+	order, lambdas, err := config.quorumOrder(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	value := config.Curve.Scalar.Zero()
+	commitments := identities(config.Curve, config.Threshold)
+	for k, dealer := range config.Quorum {
+		i := order[k]
+		output := outputs[i]
+		if err = VerifyReshareOutput(config, oldVerifier, output); err != nil {
+			return nil, nil, err
+		}
+		share := shares[i]
+		if share == nil || share.Id != id {
+			return nil, nil, fmt.Errorf("dealer %d: share is not for holder %d", dealer, id)
+		}
+		if err = output.Verifier.Verify(share); err != nil {
+			return nil, nil, fmt.Errorf("dealer %d: invalid share: %v", dealer, err)
+		}
+		s, err := config.Curve.Scalar.SetBytes(share.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		lambda := lambdas[dealer]
+		value = value.Add(s.Mul(lambda))
+		for j, c := range output.Verifier.Commitments {
+			commitments[j] = commitments[j].Add(c.Mul(lambda))
+		}
+	}
+	return &ShamirShare{Id: id, Value: value.Bytes()}, &FeldmanVerifier{Commitments: commitments}, nil
+}
+
+// CombinePedersenReshare computes the new share and blinding share of the holder `id` from the outputs of every
+// dealer in the quorum and the shares they sent to it. It also returns the Pedersen verifier of the new sharing,
+// whose first commitment is the same as the old one
+func CombinePedersenReshare(config *ReshareConfig, id uint32, oldVerifier *PedersenVerifier, outputs []*PedersenReshareOutput, shares, blindingShares []*ShamirShare) (*ShamirShare, *ShamirShare, *PedersenVerifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(outputs) != len(config.Quorum) || len(shares) != len(config.Quorum) || len(blindingShares) != len(config.Quorum) {
+		return nil, nil, nil, fmt.Errorf("expected an output and shares from each of the %d dealers", len(config.Quorum))
+	}
+	if err := config.checkHolder(id); err != nil {
+		return nil, nil, nil, err
+	}
+	ids := make([]uint32, len(outputs))
+	for i, output := range outputs {
+		if output == nil {
+			return nil, nil, nil, fmt.Errorf("invalid dealer output")
+		}
+		ids[i] = output.Id
+	}
+	order, lambdas, err := config.quorumOrder(ids)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	value := config.Curve.Scalar.Zero()
+	blinding := config.Curve.Scalar.Zero()
+	commitments := identities(config.Curve, config.Threshold)
+	for k, dealer := range config.Quorum {
+		i := order[k]
+		output := outputs[i]
+		if err = VerifyPedersenReshareOutput(config, oldVerifier, output); err != nil {
+			return nil, nil, nil, err
+		}
+		share, blindingShare := shares[i], blindingShares[i]
+		if share == nil || blindingShare == nil || share.Id != id || blindingShare.Id != id {
+			return nil, nil, nil, fmt.Errorf("dealer %d: shares are not for holder %d", dealer, id)
+		}
+		if err = output.Verifier.Verify(share, blindingShare); err != nil {
+			return nil, nil, nil, fmt.Errorf("dealer %d: invalid share: %v", dealer, err)
+		}
+		s, err := config.Curve.Scalar.SetBytes(share.Value)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		b, err := config.Curve.Scalar.SetBytes(blindingShare.Value)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		lambda := lambdas[dealer]
+		value = value.Add(s.Mul(lambda))
+		blinding = blinding.Add(b.Mul(lambda))
+		for j, c := range output.Verifier.Commitments {
+			commitments[j] = commitments[j].Add(c.Mul(lambda))
+		}
+	}
+	return &ShamirShare{Id: id, Value: value.Bytes()},
+		&ShamirShare{Id: id, Value: blinding.Bytes()},
+		&PedersenVerifier{Generator: oldVerifier.Generator, Commitments: commitments},
+		nil
+}
+
+// dealerShare checks that the share of a dealer is valid and in the quorum
+func (c *ReshareConfig) dealerShare(share *ShamirShare) (curves.Scalar, error) {
+	if share == nil {
+		return nil, fmt.Errorf("invalid share")
+	}
+	if err := share.Validate(c.Curve); err != nil {
+		return nil, fmt.Errorf("invalid share: %v", err)
+	}
+	if !c.inQuorum(share.Id) {
+		return nil, fmt.Errorf("%d is not in the quorum", share.Id)
+	}
+	return c.Curve.Scalar.SetBytes(share.Value)
+}
+
+// checkDealer checks that a dealer is in the quorum and committed to a polynomial of the right degree
+func (c *ReshareConfig) checkDealer(id uint32, commitments []curves.Point) error {
+	if !c.inQuorum(id) {
+		return fmt.Errorf("%d is not in the quorum", id)
+	}
+	if len(commitments) != int(c.Threshold) {
+		return fmt.Errorf("dealer %d: expected %d commitments", id, c.Threshold)
+	}
+	for _, commitment := range commitments {
+		if commitment == nil || commitment.CurveName() != c.Curve.Name {
+			return fmt.Errorf("dealer %d: invalid commitment", id)
+		}
+	}
+	return nil
+}
+
+// quorumOrder maps the position of every quorum member in the configuration to the position of its output,
+// and returns the Lagrange coefficients of the quorum
+func (c *ReshareConfig) quorumOrder(ids []uint32) ([]int, map[uint32]curves.Scalar, error) {
+	positions := make(map[uint32]int, len(ids))
+	for i, id := range ids {
+		if _, ok := positions[id]; ok {
+			return nil, nil, fmt.Errorf("duplicate output from dealer %d", id)
+		}
+		positions[id] = i
+	}
+	order := make([]int, len(c.Quorum))
+	for k, dealer := range c.Quorum {
+		i, ok := positions[dealer]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing output from dealer %d", dealer)
+		}
+		order[k] = i
+	}
+	shamir, err := c.oldShamir()
+	if err != nil {
+		return nil, nil, err
+	}
+	lambdas, err := shamir.LagrangeCoeffs(c.Quorum)
+	if err != nil {
+		return nil, nil, err
+	}
+	return order, lambdas, nil
+}
+
+// checkHolder checks that `id` is the identifier of a new share
+func (c *ReshareConfig) checkHolder(id uint32) error {
+	shamir, err := newReshareShamir(c.Threshold, c.Limit, c.Identifiers, c.Curve)
+	if err != nil {
+		return err
+	}
+	if id == 0 || !shamir.validIdentifier(id) {
+		return fmt.Errorf("%d is not a new holder", id)
+	}
+	return nil
+}
+
+func (c *ReshareConfig) inQuorum(id uint32) bool {
+	for _, member := range c.Quorum {
+		if member == id {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate computes Σ C_k id^k from the commitments of the old sharing, which has `threshold` of them
+func (c *ReshareConfig) evaluate(commitments []curves.Point, threshold, id uint32) (curves.Point, error) {
+	if len(commitments) != int(threshold) {
+		return nil, fmt.Errorf("invalid old verifier")
+	}
+	x := c.Curve.ScalarFromIndex(id)
+	power := c.Curve.Scalar.One()
+	result := c.Curve.NewIdentityPoint()
+	for _, commitment := range commitments {
+		if commitment == nil || commitment.CurveName() != c.Curve.Name {
+			return nil, fmt.Errorf("invalid old verifier")
+		}
+		result = result.Add(commitment.Mul(power))
+		power = power.Mul(x)
+	}
+	return result, nil
+}
+
+// identities returns n identity points
+func identities(curve *curves.Curve, n uint32) []curves.Point {
+	points := make([]curves.Point, n)
+	for i := range points {
+		points[i] = curve.NewIdentityPoint()
+	}
+	return points
+}
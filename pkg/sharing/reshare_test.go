@@ -0,0 +1,257 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func newReshareConfig(curve *curves.Curve) *ReshareConfig {
+	return &ReshareConfig{
+		Curve:        curve,
+		OldThreshold: 2,
+		OldLimit:     3,
+		Threshold:    3,
+		Limit:        5,
+		Quorum:       []uint32{3, 1},
+	}
+}
+
+func TestReshareFeldman(t *testing.T) {
+	curve := curves.K256()
+	config := newReshareConfig(curve)
+	feldman, err := NewFeldman(config.OldThreshold, config.OldLimit, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	oldVerifier, oldShares, err := feldman.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	var outputs []*ReshareOutput
+	received := map[uint32][]*ShamirShare{}
+	for _, id := range config.Quorum {
+		dealer, err := NewReshareDealer(config, oldShares[id-1])
+		require.NoError(t, err)
+		output, shares, err := dealer.Deal(crand.Reader)
+		require.NoError(t, err)
+		require.NoError(t, VerifyReshareOutput(config, oldVerifier, output))
+		outputs = append(outputs, output)
+		for _, share := range shares {
+			received[share.Id] = append(received[share.Id], share)
+		}
+	}
+
+	// the outputs can arrive in any order
+	outputs[0], outputs[1] = outputs[1], outputs[0]
+	for id := range received {
+		received[id][0], received[id][1] = received[id][1], received[id][0]
+	}
+
+	newShares := make([]*ShamirShare, config.Limit)
+	var newVerifier *FeldmanVerifier
+	for id := uint32(1); id <= config.Limit; id++ {
+		share, verifier, err := CombineReshare(config, id, oldVerifier, outputs, received[id])
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(share))
+		require.True(t, verifier.Commitments[0].Equal(oldVerifier.Commitments[0]))
+		if newVerifier != nil {
+			for i, c := range verifier.Commitments {
+				require.True(t, c.Equal(newVerifier.Commitments[i]))
+			}
+		}
+		newVerifier = verifier
+		newShares[id-1] = share
+	}
+
+	newFeldman, err := NewFeldman(config.Threshold, config.Limit, curve)
+	require.NoError(t, err)
+	actual, err := newFeldman.Combine(newShares[4], newShares[0], newShares[2])
+	require.NoError(t, err)
+	require.Equal(t, 0, secret.Cmp(actual))
+	// two new shares are no longer enough
+	_, err = newFeldman.Combine(newShares[0], newShares[1])
+	require.Error(t, err)
+}
+
+func TestResharePedersen(t *testing.T) {
+	curve := curves.P256()
+	config := newReshareConfig(curve)
+	generator, err := PedersenGenerator(curve, []byte("reshare test"))
+	require.NoError(t, err)
+	pedersen, err := NewPedersen(config.OldThreshold, config.OldLimit, generator)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	old, err := pedersen.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	var outputs []*PedersenReshareOutput
+	received := map[uint32][]*ShamirShare{}
+	receivedBlinding := map[uint32][]*ShamirShare{}
+	for _, id := range config.Quorum {
+		dealer, err := NewPedersenReshareDealer(config, old.SecretShares[id-1], old.BlindingShares[id-1])
+		require.NoError(t, err)
+		output, shares, blindingShares, err := dealer.DealPedersen(generator, crand.Reader)
+		require.NoError(t, err)
+		require.NoError(t, VerifyPedersenReshareOutput(config, old.PedersenVerifier, output))
+		outputs = append(outputs, output)
+		for i, share := range shares {
+			received[share.Id] = append(received[share.Id], share)
+			receivedBlinding[share.Id] = append(receivedBlinding[share.Id], blindingShares[i])
+		}
+
+		// a dealer for a Feldman share cannot deal Pedersen shares
+		feldmanDealer, err := NewReshareDealer(config, old.SecretShares[id-1])
+		require.NoError(t, err)
+		_, _, _, err = feldmanDealer.DealPedersen(generator, crand.Reader)
+		require.Error(t, err)
+	}
+
+	newShares := make([]*ShamirShare, config.Limit)
+	for id := uint32(1); id <= config.Limit; id++ {
+		share, blindingShare, verifier, err := CombinePedersenReshare(config, id, old.PedersenVerifier, outputs, received[id], receivedBlinding[id])
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(share, blindingShare))
+		require.True(t, verifier.Commitments[0].Equal(old.PedersenVerifier.Commitments[0]))
+		newShares[id-1] = share
+	}
+	newPedersen, err := NewPedersen(config.Threshold, config.Limit, generator)
+	require.NoError(t, err)
+	actual, err := newPedersen.Combine(newShares[1], newShares[3], newShares[4])
+	require.NoError(t, err)
+	require.Equal(t, 0, secret.Cmp(actual))
+}
+
+func TestReshareRejectsCheatingDealer(t *testing.T) {
+	curve := curves.K256()
+	config := newReshareConfig(curve)
+	feldman, err := NewFeldman(config.OldThreshold, config.OldLimit, curve)
+	require.NoError(t, err)
+	oldVerifier, oldShares, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+
+	honest, err := NewReshareDealer(config, oldShares[0])
+	require.NoError(t, err)
+	honestOutput, honestShares, err := honest.Deal(crand.Reader)
+	require.NoError(t, err)
+
+	// dealer 3 reshares a different value
+	forged := &ShamirShare{Id: 3, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	cheater, err := NewReshareDealer(config, forged)
+	require.NoError(t, err)
+	cheaterOutput, cheaterShares, err := cheater.Deal(crand.Reader)
+	require.NoError(t, err)
+	require.Error(t, VerifyReshareOutput(config, oldVerifier, cheaterOutput))
+	_, _, err = CombineReshare(config, 1, oldVerifier, []*ReshareOutput{honestOutput, cheaterOutput}, []*ShamirShare{honestShares[0], cheaterShares[0]})
+	require.Error(t, err)
+
+	// dealer 3 is honest in public but sends a wrong share
+	dealer, err := NewReshareDealer(config, oldShares[2])
+	require.NoError(t, err)
+	output, shares, err := dealer.Deal(crand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, VerifyReshareOutput(config, oldVerifier, output))
+	_, _, err = CombineReshare(config, 1, oldVerifier, []*ReshareOutput{honestOutput, output}, []*ShamirShare{honestShares[0], shares[1]})
+	require.Error(t, err)
+	wrong := &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, _, err = CombineReshare(config, 1, oldVerifier, []*ReshareOutput{honestOutput, output}, []*ShamirShare{honestShares[0], wrong})
+	require.Error(t, err)
+
+	// every dealer in the quorum must take part exactly once
+	_, _, err = CombineReshare(config, 1, oldVerifier, []*ReshareOutput{honestOutput, honestOutput}, []*ShamirShare{honestShares[0], honestShares[0]})
+	require.Error(t, err)
+	_, _, err = CombineReshare(config, 1, oldVerifier, []*ReshareOutput{honestOutput}, []*ShamirShare{honestShares[0]})
+	require.Error(t, err)
+	_, _, err = CombineReshare(config, 1, oldVerifier, []*ReshareOutput{honestOutput, output}, []*ShamirShare{honestShares[0], shares[0]})
+	require.NoError(t, err)
+
+	// a share outside the quorum cannot deal
+	_, err = NewReshareDealer(config, oldShares[1])
+	require.Error(t, err)
+}
+
+func TestReshareConfigValidate(t *testing.T) {
+	curve := curves.K256()
+	require.NoError(t, newReshareConfig(curve).Validate())
+	for _, tamper := range []func(c *ReshareConfig){
+		func(c *ReshareConfig) { c.Curve = nil },
+		func(c *ReshareConfig) { c.Quorum = []uint32{1} },
+		func(c *ReshareConfig) { c.Quorum = []uint32{1, 1} },
+		func(c *ReshareConfig) { c.Quorum = []uint32{1, 4} },
+		func(c *ReshareConfig) { c.Quorum = []uint32{0, 1} },
+		func(c *ReshareConfig) { c.Quorum = []uint32{1, 2, 3, 4} },
+		func(c *ReshareConfig) { c.Threshold = 6 },
+		func(c *ReshareConfig) { c.Threshold = 1 },
+		func(c *ReshareConfig) { c.OldThreshold = 4 },
+		func(c *ReshareConfig) { c.OldIdentifiers = []uint32{1, 2} },
+		func(c *ReshareConfig) { c.OldIdentifiers = []uint32{1, 2, 2} },
+		func(c *ReshareConfig) { c.OldIdentifiers = []uint32{1, 2, 7} },
+		func(c *ReshareConfig) { c.Identifiers = []uint32{10, 20, 30, 40} },
+		func(c *ReshareConfig) { c.Identifiers = []uint32{10, 20, 30, 40, 0} },
+	} {
+		config := newReshareConfig(curve)
+		tamper(config)
+		require.Error(t, config.Validate())
+	}
+	var config *ReshareConfig
+	require.Error(t, config.Validate())
+}
+
+func TestReshareIdentifiers(t *testing.T) {
+	curve := curves.K256()
+	config := &ReshareConfig{
+		Curve:          curve,
+		OldThreshold:   2,
+		OldLimit:       3,
+		Threshold:      2,
+		Limit:          3,
+		Quorum:         []uint32{900, 17},
+		OldIdentifiers: []uint32{17, 400, 900},
+		Identifiers:    []uint32{1000, 5, 77},
+	}
+	require.NoError(t, config.Validate())
+	feldman, err := NewFeldmanWithIdentifiers(config.OldThreshold, config.OldIdentifiers, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	oldVerifier, oldShares, err := feldman.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	var outputs []*ReshareOutput
+	received := map[uint32][]*ShamirShare{}
+	for _, share := range []*ShamirShare{oldShares[2], oldShares[0]} {
+		dealer, err := NewReshareDealer(config, share)
+		require.NoError(t, err)
+		output, shares, err := dealer.Deal(crand.Reader)
+		require.NoError(t, err)
+		require.NoError(t, VerifyReshareOutput(config, oldVerifier, output))
+		outputs = append(outputs, output)
+		for _, share := range shares {
+			received[share.Id] = append(received[share.Id], share)
+		}
+	}
+	_, err = NewReshareDealer(config, oldShares[1])
+	require.Error(t, err)
+
+	var newShares []*ShamirShare
+	for _, id := range config.Identifiers {
+		share, verifier, err := CombineReshare(config, id, oldVerifier, outputs, received[id])
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(share))
+		newShares = append(newShares, share)
+	}
+	_, _, err = CombineReshare(config, 2, oldVerifier, outputs, received[5])
+	require.Error(t, err)
+
+	newFeldman, err := NewFeldmanWithIdentifiers(config.Threshold, config.Identifiers, curve)
+	require.NoError(t, err)
+	actual, err := newFeldman.Combine(newShares[2], newShares[0])
+	require.NoError(t, err)
+	require.Equal(t, 0, secret.Cmp(actual))
+}
@@ -12,6 +12,8 @@
 package sharing
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -47,6 +49,8 @@ func (ss ShamirShare) Bytes() []byte {
 type Shamir struct {
 	threshold, limit uint32
 	curve            *curves.Curve
+	// identifiers are the x-coordinates of the shares, or 1,...,limit if nil
+	identifiers []uint32
 }
 
 func NewShamir(threshold, limit uint32, curve *curves.Curve) (*Shamir, error) {
@@ -62,7 +66,100 @@ func NewShamir(threshold, limit uint32, curve *curves.Curve) (*Shamir, error) {
 	if curve == nil {
 		return nil, fmt.Errorf("invalid curve")
 	}
-	return &Shamir{threshold, limit, curve}, nil
+	return &Shamir{threshold, limit, curve, nil}, nil
+}
+
+// NewShamirWithIdentifiers creates a sharing whose shares have the given identifiers as x-coordinates
+// instead of 1,...,limit, e.g. identifiers derived from device IDs with DeriveIdentifier,
+// so shares keep their identifiers when participants join or leave
+func NewShamirWithIdentifiers(threshold uint32, identifiers []uint32, curve *curves.Curve) (*Shamir, error) {
+	if err := checkIdentifiers(identifiers); err != nil {
+		return nil, err
+	}
+	s, err := NewShamir(threshold, uint32(len(identifiers)), curve)
+	if err != nil {
+		return nil, err
+	}
+	s.identifiers = copyIdentifiers(identifiers)
+	return s, nil
+}
+
+// DeriveIdentifier maps `data`, e.g. a device ID, to a nonzero share identifier.
+// Identifiers have 32 bits, so anyone can find data that maps to the identifier of another device.
+// Use DeriveIdentifiers, which fails on such collisions, to derive the identifiers of a sharing.
+func DeriveIdentifier(data []byte) uint32 {
+	h := sha256.Sum256(data)
+	for {
+		if id := binary.BigEndian.Uint32(h[:4]); id != 0 {
+			return id
+		}
+		h = sha256.Sum256(h[:])
+	}
+}
+
+// DeriveIdentifiers maps every element of `data` to a share identifier with DeriveIdentifier.
+// It fails if two different elements map to the same identifier, or if an element is repeated
+func DeriveIdentifiers(data ...[]byte) ([]uint32, error) {
+	identifiers := make([]uint32, len(data))
+	seen := make(map[uint32]int, len(data))
+	for i, d := range data {
+		identifiers[i] = DeriveIdentifier(d)
+		if j, ok := seen[identifiers[i]]; ok {
+			if bytes.Equal(data[j], d) {
+				return nil, fmt.Errorf("duplicate data at %d and %d", j, i)
+			}
+			return nil, fmt.Errorf("identifiers of data %d and %d collide", j, i)
+		}
+		seen[identifiers[i]] = i
+	}
+	return identifiers, nil
+}
+
+// checkIdentifiers checks the identifiers are nonzero and distinct
+func checkIdentifiers(identifiers []uint32) error {
+	seen := make(map[uint32]bool, len(identifiers))
+	for _, id := range identifiers {
+		if id == 0 {
+			return fmt.Errorf("invalid identifier")
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate identifier %d", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func copyIdentifiers(identifiers []uint32) []uint32 {
+	if identifiers == nil {
+		return nil
+	}
+	return append([]uint32{}, identifiers...)
+}
+
+// ids returns the identifiers of the shares
+func (s Shamir) ids() []uint32 {
+	if s.identifiers != nil {
+		return s.identifiers
+	}
+	ids := make([]uint32, s.limit)
+	for i := range ids {
+		ids[i] = uint32(i + 1)
+	}
+	return ids
+}
+
+// validIdentifier checks `id` is the identifier of a share
+func (s Shamir) validIdentifier(id uint32) bool {
+	if s.identifiers == nil {
+		return id <= s.limit
+	}
+	for _, v := range s.identifiers {
+		if v == id {
+			return true
+		}
+	}
+	return false
 }
 
 func (s Shamir) Split(secret curves.Scalar, reader io.Reader) ([]*ShamirShare, error) {
@@ -75,11 +172,12 @@ func (s Shamir) Split(secret curves.Scalar, reader io.Reader) ([]*ShamirShare, e
 
 func (s Shamir) getPolyAndShares(secret curves.Scalar, reader io.Reader) ([]*ShamirShare, *Polynomial) {
 	poly := new(Polynomial).Init(secret, s.threshold, reader)
-	shares := make([]*ShamirShare, s.limit)
-	for i := range shares {
-		x := s.curve.Scalar.New(i + 1)
+	ids := s.ids()
+	shares := make([]*ShamirShare, len(ids))
+	for i, id := range ids {
+		x := s.curve.ScalarFromIndex(id)
 		shares[i] = &ShamirShare{
-			Id:    uint32(i + 1),
+			Id:    id,
 			Value: poly.Evaluate(x).Bytes(),
 		}
 	}
@@ -89,7 +187,7 @@ func (s Shamir) getPolyAndShares(secret curves.Scalar, reader io.Reader) ([]*Sha
 func (s Shamir) LagrangeCoeffs(identities []uint32) (map[uint32]curves.Scalar, error) {
 	xs := make(map[uint32]curves.Scalar, len(identities))
 	for _, xi := range identities {
-		xs[xi] = s.curve.Scalar.New(int(xi))
+		xs[xi] = s.curve.ScalarFromIndex(xi)
 	}
 
 	result := make(map[uint32]curves.Scalar, len(identities))
@@ -125,7 +223,7 @@ func (s Shamir) Combine(shares ...*ShamirShare) (curves.Scalar, error) {
 		if err != nil {
 			return nil, err
 		}
-		if share.Id > s.limit {
+		if !s.validIdentifier(share.Id) {
 			return nil, fmt.Errorf("invalid share identifier")
 		}
 		if _, in := dups[share.Id]; in {
@@ -133,7 +231,7 @@ func (s Shamir) Combine(shares ...*ShamirShare) (curves.Scalar, error) {
 		}
 		dups[share.Id] = true
 		ys[i], _ = s.curve.Scalar.SetBytes(share.Value)
-		xs[i] = s.curve.Scalar.New(int(share.Id))
+		xs[i] = s.curve.ScalarFromIndex(share.Id)
 	}
 	return s.interpolate(xs, ys)
 }
@@ -151,7 +249,7 @@ func (s Shamir) CombinePoints(shares ...*ShamirShare) (curves.Point, error) {
 		if err != nil {
 			return nil, err
 		}
-		if share.Id > s.limit {
+		if !s.validIdentifier(share.Id) {
 			return nil, fmt.Errorf("invalid share identifier")
 		}
 		if _, in := dups[share.Id]; in {
@@ -160,7 +258,7 @@ func (s Shamir) CombinePoints(shares ...*ShamirShare) (curves.Point, error) {
 		dups[share.Id] = true
 		sc, _ := s.curve.Scalar.SetBytes(share.Value)
 		ys[i] = s.curve.ScalarBaseMult(sc)
-		xs[i] = s.curve.Scalar.New(int(share.Id))
+		xs[i] = s.curve.ScalarFromIndex(share.Id)
 	}
 	return s.interpolatePoint(xs, ys)
 }
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -184,3 +185,69 @@ func TestMarshalJsonRoundTrip(t *testing.T) {
 		require.Equal(t, bytes.Compare(in.Value, out.Value), 0)
 	}
 }
+
+func TestShamirIdentifiers(t *testing.T) {
+	curve := curves.ED25519()
+	ids := []uint32{
+		DeriveIdentifier([]byte("device-a")),
+		DeriveIdentifier([]byte("device-b")),
+		DeriveIdentifier([]byte("device-c")),
+		1000,
+	}
+	scheme, err := NewShamirWithIdentifiers(3, ids, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	require.Len(t, shares, len(ids))
+	for i, share := range shares {
+		require.Equal(t, ids[i], share.Id)
+	}
+
+	actual, err := scheme.Combine(shares[3], shares[0], shares[2])
+	require.NoError(t, err)
+	require.Equal(t, 0, secret.Cmp(actual))
+	point, err := scheme.CombinePoints(shares[1], shares[2], shares[3])
+	require.NoError(t, err)
+	require.True(t, curve.ScalarBaseMult(secret).Equal(point))
+
+	// shares with an identifier outside the sharing are rejected
+	_, err = scheme.Combine(shares[0], shares[1], &ShamirShare{Id: 2, Value: shares[2].Value})
+	require.Error(t, err)
+
+	// identifiers must be nonzero and distinct
+	_, err = NewShamirWithIdentifiers(2, []uint32{5, 0, 7}, curve)
+	require.Error(t, err)
+	_, err = NewShamirWithIdentifiers(2, []uint32{5, 7, 5}, curve)
+	require.Error(t, err)
+	_, err = NewShamirWithIdentifiers(3, []uint32{5, 7}, curve)
+	require.Error(t, err)
+}
+
+func TestDeriveIdentifier(t *testing.T) {
+	require.Equal(t, DeriveIdentifier([]byte("device-a")), DeriveIdentifier([]byte("device-a")))
+	require.NotEqual(t, DeriveIdentifier([]byte("device-a")), DeriveIdentifier([]byte("device-b")))
+	require.NotZero(t, DeriveIdentifier(nil))
+}
+
+func TestDeriveIdentifiersCollision(t *testing.T) {
+	ids, err := DeriveIdentifiers([]byte("device-a"), []byte("device-b"))
+	require.NoError(t, err)
+	require.Equal(t, []uint32{DeriveIdentifier([]byte("device-a")), DeriveIdentifier([]byte("device-b"))}, ids)
+	_, err = DeriveIdentifiers([]byte("device-a"), []byte("device-a"))
+	require.Error(t, err)
+
+	// 32 bit identifiers collide after about 2^16 devices
+	seen := make(map[uint32][]byte)
+	for i := 0; ; i++ {
+		data := []byte(fmt.Sprintf("device-%d", i))
+		id := DeriveIdentifier(data)
+		if other, ok := seen[id]; ok {
+			_, err = DeriveIdentifiers(other, []byte("device-b"), data)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "collide")
+			break
+		}
+		seen[id] = data
+	}
+}
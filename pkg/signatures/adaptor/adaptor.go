@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package adaptor implements adaptor signatures, also known as one-time verifiably encrypted signatures.
+// A pre-signature of a message is bound to an adaptor point T = t*G. Anyone can verify that it completes to a
+// valid signature under the signer's key with the adaptor secret t, and once the signature is published,
+// anyone holding the pre-signature extracts t from it. This makes the publication of a signature reveal a
+// secret, as needed by atomic swaps and payment channels.
+//
+// Schnorr adaptor signatures follow BIP-340 over secp256k1, and threshold pre-signatures are computed by the
+// FROST signers of package frost with Signer.SetAdaptorPoint. ECDSA adaptor signatures are the one-time VES
+// of "One-Time Verifiably Encrypted Signatures A.K.A. Adaptor Signatures", Fournier 2019,
+// https://github.com/LLFourn/one-time-VES/blob/master/main.pdf, and threshold pre-signatures are computed by
+// the DKLs signers of package dkls/v1/sign with SetAdaptorPoint. These prove the nonce with a chain of DLEQ
+// proofs, one for the nonce share of each signer.
+package adaptor
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// DleqProof proves that A = x*G and B = x*H for the same secret x, a Chaum-Pedersen proof made
+// non-interactive with the Fiat-Shamir transform
+type DleqProof struct {
+	C, Z curves.Scalar
+}
+
+// ProveDleq proves that a = x*g and b = x*h, with randomness from `reader`, or crypto/rand if it is nil
+func ProveDleq(curve *curves.Curve, x curves.Scalar, g, h, a, b curves.Point, reader io.Reader) (*DleqProof, error) {
+	if curve == nil || x == nil || g == nil || h == nil || a == nil || b == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	w := curve.Scalar.Random(reader)
+	if w.IsZero() {
+		return nil, fmt.Errorf("invalid nonce sampled")
+	}
+	c := dleqChallenge(curve, g, h, a, b, g.Mul(w), h.Mul(w))
+	return &DleqProof{C: c, Z: w.Add(c.Mul(x))}, nil
+}
+
+// VerifyDleq verifies that `proof` proves a = x*g and b = x*h
+func VerifyDleq(curve *curves.Curve, proof *DleqProof, g, h, a, b curves.Point) error {
+	if curve == nil || g == nil || h == nil || a == nil || b == nil {
+		return internal.ErrNilArguments
+	}
+	if proof == nil || proof.C == nil || proof.Z == nil {
+		return fmt.Errorf("invalid dleq proof")
+	}
+	// The commitments are z*g - c*a and z*h - c*b
+	cNeg := proof.C.Neg()
+	commitA := g.Mul(proof.Z).Add(a.Mul(cNeg))
+	commitB := h.Mul(proof.Z).Add(b.Mul(cNeg))
+	if dleqChallenge(curve, g, h, a, b, commitA, commitB).Cmp(proof.C) != 0 {
+		return fmt.Errorf("invalid dleq proof")
+	}
+	return nil
+}
+
+func dleqChallenge(curve *curves.Curve, points ...curves.Point) curves.Scalar {
+	transcript := []byte("kryptology adaptor dleq")
+	for _, p := range points {
+		transcript = append(transcript, p.ToAffineCompressed()...)
+	}
+	return curve.Scalar.Hash(transcript)
+}
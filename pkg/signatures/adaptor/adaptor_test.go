@@ -0,0 +1,214 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package adaptor
+
+import (
+	"crypto/ecdsa"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	dkg "github.com/etclab/kryptology/pkg/dkg/frost"
+	"github.com/etclab/kryptology/pkg/sharing"
+	"github.com/etclab/kryptology/pkg/ted25519/frost"
+)
+
+func TestSchnorrAdaptor(t *testing.T) {
+	curve := curves.K256()
+	msg := []byte("message")
+	// Cover keys and nonces with odd and even Y coordinates
+	for i := 0; i < 16; i++ {
+		sk := curve.Scalar.Random(crand.Reader)
+		pk, err := frost.Bip340PublicKey(curve.ScalarBaseMult(sk))
+		require.NoError(t, err)
+		secret := curve.Scalar.Random(crand.Reader)
+		adaptor := curve.ScalarBaseMult(secret)
+
+		preSig, err := SchnorrPreSign(sk, msg, adaptor, nil)
+		require.NoError(t, err)
+		require.NoError(t, SchnorrPreVerify(pk, msg, adaptor, preSig))
+		ok, _ := frost.VerifyBip340(pk, msg, preSig)
+		require.False(t, ok)
+
+		sig, err := SchnorrAdapt(pk, msg, preSig, secret)
+		require.NoError(t, err)
+		ok, err = frost.VerifyBip340(pk, msg, sig)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		extracted, err := SchnorrExtract(pk, msg, preSig, sig, adaptor)
+		require.NoError(t, err)
+		require.Equal(t, 0, extracted.Cmp(secret))
+	}
+}
+
+func TestSchnorrAdaptorBadInput(t *testing.T) {
+	curve := curves.K256()
+	msg := []byte("message")
+	sk := curve.Scalar.Random(crand.Reader)
+	pk, err := frost.Bip340PublicKey(curve.ScalarBaseMult(sk))
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	adaptor := curve.ScalarBaseMult(secret)
+	preSig, err := SchnorrPreSign(sk, msg, adaptor, nil)
+	require.NoError(t, err)
+
+	other := curve.ScalarBaseMult(curve.Scalar.Random(crand.Reader))
+	require.Error(t, SchnorrPreVerify(pk, msg, other, preSig))
+	require.Error(t, SchnorrPreVerify(pk, []byte("other message"), adaptor, preSig))
+	require.Error(t, SchnorrPreVerify(pk[1:], msg, adaptor, preSig))
+	require.Error(t, SchnorrPreVerify(pk, msg, curves.ED25519().Point.Generator(), preSig))
+	tampered := append([]byte{}, preSig...)
+	tampered[63] ^= 1
+	require.Error(t, SchnorrPreVerify(pk, msg, adaptor, tampered))
+
+	_, err = SchnorrAdapt(pk, msg, preSig, curve.Scalar.Random(crand.Reader))
+	require.Error(t, err)
+	_, err = SchnorrPreSign(sk, msg, curve.NewIdentityPoint(), nil)
+	require.Error(t, err)
+
+	// A signature which was not completed from the pre-signature reveals nothing
+	otherPreSig, err := SchnorrPreSign(sk, msg, adaptor, nil)
+	require.NoError(t, err)
+	sig, err := SchnorrAdapt(pk, msg, otherPreSig, secret)
+	require.NoError(t, err)
+	_, err = SchnorrExtract(pk, msg, preSig, sig, adaptor)
+	require.Error(t, err)
+}
+
+func TestThresholdSchnorrAdaptor(t *testing.T) {
+	curve := curves.K256()
+	msg := []byte("message")
+	for i := 0; i < 8; i++ {
+		secret := curve.Scalar.Random(crand.Reader)
+		adaptor := curve.ScalarBaseMult(secret)
+
+		// Signers 1 and 3 of a 2-of-3 key pre-sign for the adaptor point
+		verifier, shares, err := dkg.DealerKeygen(nil, 2, 3, curve, crand.Reader)
+		require.NoError(t, err)
+		signerIds := []uint32{1, 3}
+		scheme, _ := sharing.NewShamir(2, 3, curve)
+		lCoeffs, err := scheme.LagrangeCoeffs(signerIds)
+		require.NoError(t, err)
+		signers := make(map[uint32]*frost.Signer)
+		round2Input := make(map[uint32]*frost.Round1Bcast)
+		for _, id := range signerIds {
+			p, err := dkg.NewDealerParticipant(shares[id-1], verifier, 2, curve)
+			require.NoError(t, err)
+			signers[id], err = frost.NewSigner(p, id, 2, lCoeffs, signerIds, frost.Bip340ChallengeDeriver{})
+			require.NoError(t, err)
+			require.NoError(t, signers[id].SetAdaptorPoint(adaptor))
+			round2Input[id], err = signers[id].SignRound1()
+			require.NoError(t, err)
+		}
+		round3Input := make(map[uint32]*frost.Round2Bcast)
+		for id, signer := range signers {
+			round3Input[id], err = signer.SignRound2(msg, round2Input)
+			require.NoError(t, err)
+		}
+		out, err := signers[1].SignRound3(round3Input)
+		require.NoError(t, err)
+		preSig, err := frost.Bip340Signature(out)
+		require.NoError(t, err)
+
+		pk, err := frost.Bip340PublicKey(verifier.Commitments[0])
+		require.NoError(t, err)
+		require.NoError(t, SchnorrPreVerify(pk, msg, adaptor, preSig))
+		sig, err := SchnorrAdapt(pk, msg, preSig, secret)
+		require.NoError(t, err)
+		ok, err := frost.VerifyBip340(pk, msg, sig)
+		require.NoError(t, err)
+		require.True(t, ok)
+		extracted, err := SchnorrExtract(pk, msg, preSig, sig, adaptor)
+		require.NoError(t, err)
+		require.Equal(t, 0, extracted.Cmp(secret))
+	}
+}
+
+func TestEcdsaAdaptor(t *testing.T) {
+	digest := sha256.Sum256([]byte("message"))
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		ec, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		for i := 0; i < 8; i++ {
+			sk := curve.Scalar.Random(crand.Reader)
+			pk := curve.ScalarBaseMult(sk)
+			secret := curve.Scalar.Random(crand.Reader)
+			adaptor := curve.ScalarBaseMult(secret)
+
+			pre, err := EcdsaPreSign(curve, sk, digest[:], adaptor, nil)
+			require.NoError(t, err)
+			require.NoError(t, EcdsaPreVerify(pk, digest[:], adaptor, pre))
+
+			sig, err := EcdsaAdapt(pre, secret)
+			require.NoError(t, err)
+			uncompressed := pk.ToAffineUncompressed()
+			pub := &ecdsa.PublicKey{
+				Curve: ec,
+				X:     new(big.Int).SetBytes(uncompressed[1:33]),
+				Y:     new(big.Int).SetBytes(uncompressed[33:]),
+			}
+			require.True(t, ecdsa.Verify(pub, digest[:], sig.R, sig.S), curve.Name)
+			require.True(t, sig.S.Cmp(new(big.Int).Rsh(ec.Params().N, 1)) <= 0)
+
+			extracted, err := EcdsaExtract(pre, sig, adaptor)
+			require.NoError(t, err)
+			require.Equal(t, 0, extracted.Cmp(secret))
+
+			// Extraction works for both S values
+			sig.S.Sub(ec.Params().N, sig.S)
+			require.True(t, ecdsa.Verify(pub, digest[:], sig.R, sig.S))
+			extracted, err = EcdsaExtract(pre, sig, adaptor)
+			require.NoError(t, err)
+			require.Equal(t, 0, extracted.Cmp(secret))
+		}
+	}
+}
+
+func TestEcdsaAdaptorBadInput(t *testing.T) {
+	curve := curves.K256()
+	digest := sha256.Sum256([]byte("message"))
+	sk := curve.Scalar.Random(crand.Reader)
+	pk := curve.ScalarBaseMult(sk)
+	secret := curve.Scalar.Random(crand.Reader)
+	adaptor := curve.ScalarBaseMult(secret)
+	pre, err := EcdsaPreSign(curve, sk, digest[:], adaptor, nil)
+	require.NoError(t, err)
+
+	other := curve.Scalar.Random(crand.Reader)
+	otherDigest := sha256.Sum256([]byte("other message"))
+	require.Error(t, EcdsaPreVerify(pk, otherDigest[:], adaptor, pre))
+	require.Error(t, EcdsaPreVerify(curve.ScalarBaseMult(other), digest[:], adaptor, pre))
+	require.Error(t, EcdsaPreVerify(pk, digest[:], curve.ScalarBaseMult(other), pre))
+	require.Error(t, EcdsaPreVerify(pk, digest[:], curves.P256().Point.Generator(), pre))
+
+	// R must be k*Y for the k of RHat, or the completed signature would not be bound to Y
+	tampered := *pre
+	tampered.R = curve.ScalarBaseMult(other)
+	require.Error(t, EcdsaPreVerify(pk, digest[:], adaptor, &tampered))
+	tampered = *pre
+	tampered.SHat = pre.SHat.Add(curve.Scalar.One())
+	require.Error(t, EcdsaPreVerify(pk, digest[:], adaptor, &tampered))
+
+	_, err = EcdsaAdapt(pre, other)
+	require.Error(t, err)
+	_, err = EcdsaPreSign(curve, sk, nil, adaptor, nil)
+	require.Error(t, err)
+	_, err = EcdsaPreSign(curve, sk, digest[:], curves.P256().Point.Generator(), nil)
+	require.Error(t, err)
+
+	otherPre, err := EcdsaPreSign(curve, sk, digest[:], adaptor, nil)
+	require.NoError(t, err)
+	sig, err := EcdsaAdapt(otherPre, secret)
+	require.NoError(t, err)
+	_, err = EcdsaExtract(pre, sig, adaptor)
+	require.Error(t, err)
+}
@@ -0,0 +1,273 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package adaptor
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// EcdsaPreSignature is an ECDSA pre-signature for the adaptor point Y = y*G. The nonce of the signature is
+// R = k*Y, and s = ŝ / y
+type EcdsaPreSignature struct {
+	R    curves.Point  // k*Y, whose X coordinate is the r of the signature
+	RHat curves.Point  // k*G
+	SHat curves.Scalar // (h + r*x) / k
+	// Proof proves that R and RHat have the same discrete log with respect to Y and G
+	Proof *DleqProof
+	// Links replace Proof for a pre-signature computed by several signers: each signer multiplies the points of
+	// the previous link, starting from G and Y, by its nonce share, and the points of the last link are RHat and R
+	Links []*EcdsaNonceLink
+}
+
+// EcdsaNonceLink is the contribution of one signer to the nonce of a threshold ECDSA pre-signature
+type EcdsaNonceLink struct {
+	RHat, R curves.Point
+	// Proof proves that RHat and R have the same discrete log with respect to the points of the previous link
+	Proof *DleqProof
+}
+
+// EcdsaPreSign computes a pre-signature of `digest` under the secret key for the adaptor point, with
+// randomness from `reader`, or crypto/rand if it is nil. The curve must be one of package ecdsa, e.g. secp256k1
+// or P-256
+func EcdsaPreSign(curve *curves.Curve, secretKey curves.Scalar, digest []byte, adaptor curves.Point, reader io.Reader) (*EcdsaPreSignature, error) {
+	if curve == nil || secretKey == nil || adaptor == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if secretKey.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	if err := checkEcdsaAdaptor(curve, adaptor); err != nil {
+		return nil, err
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	h, err := ecdsaDigest(curve, digest)
+	if err != nil {
+		return nil, err
+	}
+	k := curve.Scalar.Random(reader)
+	if k.IsZero() {
+		return nil, fmt.Errorf("invalid nonce sampled")
+	}
+	capR := adaptor.Mul(k)
+	rHat := curve.ScalarBaseMult(k)
+	r, err := ecdsaR(curve, capR)
+	if err != nil {
+		return nil, err
+	}
+	kInv, err := k.Invert()
+	if err != nil {
+		return nil, err
+	}
+	sHat := h.Add(r.Mul(secretKey)).Mul(kInv)
+	if sHat.IsZero() {
+		return nil, fmt.Errorf("invalid nonce sampled")
+	}
+	proof, err := ProveDleq(curve, k, curve.NewGeneratorPoint(), adaptor, rHat, capR, reader)
+	if err != nil {
+		return nil, err
+	}
+	return &EcdsaPreSignature{R: capR, RHat: rHat, SHat: sHat, Proof: proof}, nil
+}
+
+// EcdsaPreVerify checks that a pre-signature of `digest` under `pubKey` completes to a valid signature with
+// the discrete log of the adaptor point
+func EcdsaPreVerify(pubKey curves.Point, digest []byte, adaptor curves.Point, pre *EcdsaPreSignature) error {
+	if pubKey == nil || adaptor == nil || pre == nil || pre.R == nil || pre.RHat == nil || pre.SHat == nil {
+		return internal.ErrNilArguments
+	}
+	curve := curves.GetCurveByName(pubKey.CurveName())
+	if curve == nil {
+		return fmt.Errorf("unknown curve %s", pubKey.CurveName())
+	}
+	if err := checkEcdsaAdaptor(curve, adaptor); err != nil {
+		return err
+	}
+	for _, p := range []curves.Point{pubKey, pre.R, pre.RHat} {
+		if p.CurveName() != curve.Name || p.IsIdentity() || !p.IsOnCurve() {
+			return fmt.Errorf("invalid pre-signature")
+		}
+	}
+	if pre.SHat.IsZero() {
+		return fmt.Errorf("invalid pre-signature")
+	}
+	if err := verifyEcdsaNonce(curve, adaptor, pre); err != nil {
+		return err
+	}
+	h, err := ecdsaDigest(curve, digest)
+	if err != nil {
+		return err
+	}
+	r, err := ecdsaR(curve, pre.R)
+	if err != nil {
+		return err
+	}
+	// ŝ*RHat = h*G + r*X
+	if !pre.RHat.Mul(pre.SHat).Equal(curve.ScalarBaseMult(h).Add(pubKey.Mul(r))) {
+		return fmt.Errorf("invalid pre-signature")
+	}
+	return nil
+}
+
+// EcdsaAdapt completes a pre-signature with the adaptor secret to a signature with the low S value.
+// V is the parity of the Y coordinate of the nonce of the signature
+func EcdsaAdapt(pre *EcdsaPreSignature, secret curves.Scalar) (*curves.EcdsaSignature, error) {
+	if pre == nil || pre.R == nil || pre.RHat == nil || pre.SHat == nil || secret == nil {
+		return nil, internal.ErrNilArguments
+	}
+	curve := curves.GetCurveByName(pre.R.CurveName())
+	if curve == nil {
+		return nil, fmt.Errorf("unknown curve %s", pre.R.CurveName())
+	}
+	// R = k*Y = y*RHat for the adaptor secret y
+	if !pre.RHat.Mul(secret).Equal(pre.R) {
+		return nil, fmt.Errorf("secret does not match the adaptor point")
+	}
+	r, err := ecdsaR(curve, pre.R)
+	if err != nil {
+		return nil, err
+	}
+	yInv, err := secret.Invert()
+	if err != nil {
+		return nil, err
+	}
+	s := pre.SHat.Mul(yInv)
+	// The nonce of (r, s) is R and that of (r, -s) is -R
+	v := int(pre.R.ToAffineCompressed()[0] & 1)
+	if isHighS(curve, s) {
+		s = s.Neg()
+		v ^= 1
+	}
+	return &curves.EcdsaSignature{V: v, R: r.BigInt(), S: s.BigInt()}, nil
+}
+
+// EcdsaExtract returns the adaptor secret of the adaptor point from a pre-signature and the signature it was
+// completed to
+func EcdsaExtract(pre *EcdsaPreSignature, sig *curves.EcdsaSignature, adaptor curves.Point) (curves.Scalar, error) {
+	if pre == nil || pre.R == nil || pre.SHat == nil || sig == nil || sig.R == nil || sig.S == nil || adaptor == nil {
+		return nil, internal.ErrNilArguments
+	}
+	curve := curves.GetCurveByName(pre.R.CurveName())
+	if curve == nil {
+		return nil, fmt.Errorf("unknown curve %s", pre.R.CurveName())
+	}
+	if err := checkEcdsaAdaptor(curve, adaptor); err != nil {
+		return nil, err
+	}
+	r, err := ecdsaR(curve, pre.R)
+	if err != nil {
+		return nil, err
+	}
+	if r.BigInt().Cmp(sig.R) != 0 {
+		return nil, fmt.Errorf("signature was not completed from the pre-signature")
+	}
+	s, err := curve.Scalar.SetBigInt(sig.S)
+	if err != nil || s.IsZero() {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	sInv, err := s.Invert()
+	if err != nil {
+		return nil, err
+	}
+	// The signature may have been normalized to -s, which gives -y
+	y := pre.SHat.Mul(sInv)
+	yG := curve.ScalarBaseMult(y)
+	switch {
+	case yG.Equal(adaptor):
+		return y, nil
+	case yG.Neg().Equal(adaptor):
+		return y.Neg(), nil
+	default:
+		return nil, fmt.Errorf("extracted secret does not match the adaptor point")
+	}
+}
+
+// verifyEcdsaNonce checks the proof, or the chain of proofs, that R and RHat have the same discrete log with
+// respect to Y and G
+func verifyEcdsaNonce(curve *curves.Curve, adaptor curves.Point, pre *EcdsaPreSignature) error {
+	if len(pre.Links) == 0 {
+		return VerifyDleq(curve, pre.Proof, curve.NewGeneratorPoint(), adaptor, pre.RHat, pre.R)
+	}
+	g, h := curve.NewGeneratorPoint(), adaptor
+	for _, link := range pre.Links {
+		if link == nil || link.RHat == nil || link.R == nil {
+			return fmt.Errorf("invalid nonce link")
+		}
+		if err := VerifyDleq(curve, link.Proof, g, h, link.RHat, link.R); err != nil {
+			return err
+		}
+		g, h = link.RHat, link.R
+	}
+	if !g.Equal(pre.RHat) || !h.Equal(pre.R) {
+		return fmt.Errorf("nonce links do not end at the nonce of the pre-signature")
+	}
+	return nil
+}
+
+func checkEcdsaAdaptor(curve *curves.Curve, adaptor curves.Point) error {
+	if adaptor.CurveName() != curve.Name || adaptor.IsIdentity() || !adaptor.IsOnCurve() {
+		return fmt.Errorf("invalid adaptor point")
+	}
+	return nil
+}
+
+// ecdsaDigest converts a digest to a scalar as ECDSA does, keeping its leftmost bits up to the bit length
+// of the group order
+func ecdsaDigest(curve *curves.Curve, digest []byte) (curves.Scalar, error) {
+	if len(digest) == 0 {
+		return nil, fmt.Errorf("empty digest")
+	}
+	ec, err := curve.ToEllipticCurve()
+	if err != nil {
+		return nil, err
+	}
+	n := ec.Params().N
+	orderBits := n.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+	v := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - orderBits; excess > 0 {
+		v.Rsh(v, uint(excess))
+	}
+	return curve.Scalar.SetBigInt(v.Mod(v, n))
+}
+
+// ecdsaR returns the X coordinate of a nonce modulo the group order
+func ecdsaR(curve *curves.Curve, capR curves.Point) (curves.Scalar, error) {
+	ec, err := curve.ToEllipticCurve()
+	if err != nil {
+		return nil, err
+	}
+	uncompressed := capR.ToAffineUncompressed()
+	x := new(big.Int).SetBytes(uncompressed[1 : 1+(len(uncompressed)-1)/2])
+	r, err := curve.Scalar.SetBigInt(x.Mod(x, ec.Params().N))
+	if err != nil {
+		return nil, err
+	}
+	if r.IsZero() {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	return r, nil
+}
+
+func isHighS(curve *curves.Curve, s curves.Scalar) bool {
+	ec, err := curve.ToEllipticCurve()
+	if err != nil {
+		return false
+	}
+	halfOrder := new(big.Int).Rsh(ec.Params().N, 1)
+	return s.BigInt().Cmp(halfOrder) > 0
+}
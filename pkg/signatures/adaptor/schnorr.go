@@ -0,0 +1,185 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package adaptor
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ted25519/frost"
+)
+
+// SchnorrPreSign computes a pre-signature of `msg` under the secret key for the adaptor point, with a nonce
+// sampled from `reader`, or crypto/rand if it is nil. A pre-signature is 64 bytes, the x-only nonce R of the
+// signature followed by s'. The signer's nonce is shifted by the adaptor point T, R = k*G + T, and s' = k + e*d
+// misses the adaptor secret t, where R, k and T are negated together when R has an odd Y coordinate.
+// Public keys are the 32 byte x-only keys of BIP-340
+func SchnorrPreSign(secretKey curves.Scalar, msg []byte, adaptor curves.Point, reader io.Reader) ([]byte, error) {
+	if secretKey == nil || adaptor == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if err := checkSchnorrAdaptor(adaptor); err != nil {
+		return nil, err
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	curve := curves.K256()
+	if secretKey.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	// BIP-340 keys have an even Y coordinate
+	pubKey := curve.ScalarBaseMult(secretKey)
+	if pubKey.IsNegative() {
+		secretKey, pubKey = secretKey.Neg(), pubKey.Neg()
+	}
+
+	k := curve.Scalar.Random(reader)
+	if k.IsZero() {
+		return nil, fmt.Errorf("invalid nonce sampled")
+	}
+	capR := curve.ScalarBaseMult(k).Add(adaptor)
+	if capR.IsIdentity() {
+		return nil, fmt.Errorf("invalid nonce sampled")
+	}
+	if capR.IsNegative() {
+		k = k.Neg()
+	}
+	e, err := frost.Bip340ChallengeDeriver{}.DeriveChallenge(msg, pubKey, capR)
+	if err != nil {
+		return nil, err
+	}
+	preSig := append(capR.ToAffineCompressed()[1:], k.Add(e.Mul(secretKey)).Bytes()...)
+
+	if _, _, err = schnorrPreVerify(pubKey.ToAffineCompressed()[1:], msg, adaptor, preSig); err != nil {
+		return nil, err
+	}
+	return preSig, nil
+}
+
+// SchnorrPreVerify checks that a pre-signature of `msg` under `pubKey` completes to a valid signature with
+// the discrete log of the adaptor point
+func SchnorrPreVerify(pubKey, msg []byte, adaptor curves.Point, preSig []byte) error {
+	_, _, err := schnorrPreVerify(pubKey, msg, adaptor, preSig)
+	return err
+}
+
+// SchnorrAdapt completes a pre-signature of `msg` under `pubKey` with the adaptor secret to a BIP-340
+// signature
+func SchnorrAdapt(pubKey, msg, preSig []byte, secret curves.Scalar) ([]byte, error) {
+	if secret == nil {
+		return nil, internal.ErrNilArguments
+	}
+	adaptor := curves.K256().ScalarBaseMult(secret)
+	sHat, negate, err := schnorrPreVerify(pubKey, msg, adaptor, preSig)
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		secret = secret.Neg()
+	}
+	sig := append(append([]byte{}, preSig[:32]...), sHat.Add(secret).Bytes()...)
+	if ok, err := frost.VerifyBip340(pubKey, msg, sig); !ok || err != nil {
+		return nil, fmt.Errorf("adapted signature does not verify")
+	}
+	return sig, nil
+}
+
+// SchnorrExtract returns the adaptor secret of the adaptor point from a pre-signature of `msg` under `pubKey`
+// and the signature it was completed to
+func SchnorrExtract(pubKey, msg, preSig, sig []byte, adaptor curves.Point) (curves.Scalar, error) {
+	sHat, negate, err := schnorrPreVerify(pubKey, msg, adaptor, preSig)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := frost.VerifyBip340(pubKey, msg, sig); !ok || err != nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if !bytes.Equal(sig[:32], preSig[:32]) {
+		return nil, fmt.Errorf("signature was not completed from the pre-signature")
+	}
+	s, err := parseK256Scalar(sig[32:])
+	if err != nil {
+		return nil, err
+	}
+	secret := s.Sub(sHat)
+	if negate {
+		secret = secret.Neg()
+	}
+	if !curves.K256().ScalarBaseMult(secret).Equal(adaptor) {
+		return nil, fmt.Errorf("extracted secret does not match the adaptor point")
+	}
+	return secret, nil
+}
+
+// schnorrPreVerify verifies a pre-signature and returns its s' and whether the adaptor secret is negated
+// to complete it, i.e. whether k*G + T has an odd Y coordinate
+func schnorrPreVerify(pubKey, msg []byte, adaptor curves.Point, preSig []byte) (curves.Scalar, bool, error) {
+	if adaptor == nil {
+		return nil, false, internal.ErrNilArguments
+	}
+	if err := checkSchnorrAdaptor(adaptor); err != nil {
+		return nil, false, err
+	}
+	if len(pubKey) != 32 {
+		return nil, false, fmt.Errorf("public key must be 32 bytes")
+	}
+	if len(preSig) != 64 {
+		return nil, false, fmt.Errorf("pre-signature must be 64 bytes")
+	}
+	curve := curves.K256()
+	// lift_x(x) is the point with an even Y coordinate
+	p, err := curve.Point.FromAffineCompressed(append([]byte{2}, pubKey...))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid public key")
+	}
+	capR, err := curve.Point.FromAffineCompressed(append([]byte{2}, preSig[:32]...))
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid pre-signature nonce")
+	}
+	sHat, err := parseK256Scalar(preSig[32:])
+	if err != nil {
+		return nil, false, err
+	}
+	e, err := frost.Bip340ChallengeDeriver{}.DeriveChallenge(msg, p, capR)
+	if err != nil {
+		return nil, false, err
+	}
+	// s'*G - e*P is the nonce of the signer, R - T, or R + T when the nonce and T were negated
+	rHat := curve.ScalarBaseMult(sHat).Sub(p.Mul(e))
+	switch {
+	case rHat.Add(adaptor).Equal(capR):
+		return sHat, false, nil
+	case rHat.Sub(adaptor).Equal(capR):
+		return sHat, true, nil
+	default:
+		return nil, false, fmt.Errorf("invalid pre-signature")
+	}
+}
+
+func checkSchnorrAdaptor(adaptor curves.Point) error {
+	if _, ok := adaptor.(*curves.PointK256); !ok || adaptor.IsIdentity() || !adaptor.IsOnCurve() {
+		return fmt.Errorf("invalid adaptor point")
+	}
+	return nil
+}
+
+// parseK256Scalar reads a 32 byte big-endian integer lower than the order of secp256k1
+func parseK256Scalar(b []byte) (curves.Scalar, error) {
+	v := new(big.Int).SetBytes(b)
+	if len(b) != 32 || v.Cmp(btcec.S256().N) >= 0 {
+		return nil, fmt.Errorf("invalid scalar")
+	}
+	return curves.K256().Scalar.SetBigInt(v)
+}
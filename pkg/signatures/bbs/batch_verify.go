@@ -0,0 +1,90 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bbs
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/gtank/merlin"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+// BatchItem is a proof of knowledge of a signature with the values
+// it is verified against, as passed to PokSignatureProof.Verify
+type BatchItem struct {
+	Proof      *PokSignatureProof
+	Revealed   map[int]curves.Scalar
+	Nonce      common.Nonce
+	Challenge  common.Challenge
+	Transcript *merlin.Transcript
+}
+
+// BatchVerify checks many proofs of knowledge of signatures issued under `pk` with `generators`.
+// The selective disclosure proofs are checked one by one, which is cheap, while the pairing equations
+// e(A', W) * e(Abar, -P2) = 1 of all proofs are combined with random scalars read from `reader`,
+// or crypto/rand if it is nil, into a single check of two pairings.
+// When the combined check fails the proofs must be verified individually to find the invalid ones
+func BatchVerify(pk *PublicKey, generators *MessageGenerators, batch []*BatchItem, reader io.Reader) error {
+	if pk == nil || pk.value == nil || pk.value.IsIdentity() {
+		return fmt.Errorf("invalid public key")
+	}
+	if generators == nil {
+		return fmt.Errorf("invalid generators")
+	}
+	if len(batch) == 0 {
+		return fmt.Errorf("batch cannot be empty")
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	aPrimes := make([]curves.Point, len(batch))
+	aBars := make([]curves.Point, len(batch))
+	randoms := make([]curves.Scalar, len(batch))
+	for i, item := range batch {
+		if item == nil || item.Proof == nil || item.Nonce == nil || item.Challenge == nil || item.Transcript == nil {
+			return fmt.Errorf("batch item %d is incomplete", i)
+		}
+		pok := item.Proof
+		if len(pok.proof1) != 2 || len(pok.proof2) != 2+generators.length-len(item.Revealed) {
+			return fmt.Errorf("proof %d does not match the generators", i)
+		}
+		if pok.aPrime.IsIdentity() || pok.aBar.IsIdentity() {
+			return fmt.Errorf("invalid proof %d", i)
+		}
+		pok.GetChallengeContribution(generators, item.Revealed, item.Challenge, item.Transcript)
+		item.Transcript.AppendMessage([]byte("nonce"), item.Nonce.Bytes())
+		okm := item.Transcript.ExtractBytes([]byte("signature proof of knowledge"), 64)
+		vChallenge, err := item.Challenge.SetBytesWide(okm)
+		if err != nil {
+			return err
+		}
+		if item.Challenge.Cmp(vChallenge) != 0 {
+			return fmt.Errorf("invalid proof %d", i)
+		}
+		aPrimes[i] = pok.aPrime
+		aBars[i] = pok.aBar
+		randoms[i] = item.Challenge.Random(reader)
+	}
+
+	// e(sum r_i * A'_i, W) * e(sum r_i * Abar_i, -P2) == 1
+	aPrime, ok := aPrimes[0].SumOfProducts(aPrimes, randoms).(curves.PairingPoint)
+	if !ok {
+		return fmt.Errorf("not a valid point")
+	}
+	aBar, ok := aBars[0].SumOfProducts(aBars, randoms).(curves.PairingPoint)
+	if !ok {
+		return fmt.Errorf("not a valid point")
+	}
+	if !aPrime.MultiPairing(aPrime, pk.value, aBar, pk.value.Generator().Neg().(curves.PairingPoint)).IsOne() {
+		return fmt.Errorf("invalid signature proof in batch")
+	}
+	return nil
+}
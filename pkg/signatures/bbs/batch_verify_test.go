@@ -0,0 +1,111 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bbs
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+const batchTranscriptLabel = "TestBatchVerify"
+
+// newBatchItem creates a proof of the signature which reveals the messages
+// at even indexes and a fresh transcript to verify it with
+func newBatchItem(t *testing.T, curve *curves.PairingCurve, sig *Signature, generators *MessageGenerators, msgs []curves.Scalar) *BatchItem {
+	proofMsgs := make([]common.ProofMessage, len(msgs))
+	revealed := make(map[int]curves.Scalar)
+	for i, m := range msgs {
+		if i%2 == 0 {
+			proofMsgs[i] = common.RevealedMessage{Message: m}
+			revealed[i] = m
+		} else {
+			proofMsgs[i] = common.ProofSpecificMessage{Message: m}
+		}
+	}
+	pok, err := NewPokSignature(sig, generators, proofMsgs, crand.Reader)
+	require.NoError(t, err)
+	nonce := curve.Scalar.Random(crand.Reader)
+	transcript := merlin.NewTranscript(batchTranscriptLabel)
+	pok.GetChallengeContribution(transcript)
+	transcript.AppendMessage([]byte("nonce"), nonce.Bytes())
+	challenge, err := curve.Scalar.SetBytesWide(transcript.ExtractBytes([]byte("signature proof of knowledge"), 64))
+	require.NoError(t, err)
+	proof, err := pok.GenerateProof(challenge)
+	require.NoError(t, err)
+	return &BatchItem{
+		Proof:      proof,
+		Revealed:   revealed,
+		Nonce:      nonce,
+		Challenge:  challenge,
+		Transcript: merlin.NewTranscript(batchTranscriptLabel),
+	}
+}
+
+// resetTranscripts allows verifying the batch again
+func resetTranscripts(batch []*BatchItem) {
+	for _, item := range batch {
+		item.Transcript = merlin.NewTranscript(batchTranscriptLabel)
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	pk, sk, err := NewKeys(curve)
+	require.NoError(t, err)
+	generators, err := new(MessageGenerators).Init(pk, 4)
+	require.NoError(t, err)
+
+	batch := make([]*BatchItem, 8)
+	for i := range batch {
+		msgs := []curves.Scalar{
+			curve.Scalar.New(i),
+			curve.Scalar.Random(crand.Reader),
+			curve.Scalar.New(i + 1),
+			curve.Scalar.Random(crand.Reader),
+		}
+		sig, err := sk.Sign(generators, msgs)
+		require.NoError(t, err)
+		batch[i] = newBatchItem(t, curve, sig, generators, msgs)
+	}
+	require.NoError(t, BatchVerify(pk, generators, batch, crand.Reader))
+	resetTranscripts(batch)
+	for _, item := range batch {
+		require.True(t, item.Proof.Verify(item.Revealed, pk, generators, item.Nonce, item.Challenge, item.Transcript))
+	}
+
+	// A proof of a forged signature has a valid selective disclosure proof but fails the pairing check
+	msgs := []curves.Scalar{curve.Scalar.New(1), curve.Scalar.New(2), curve.Scalar.New(3), curve.Scalar.New(4)}
+	forged := &Signature{
+		a: curve.NewG1GeneratorPoint().Mul(curve.Scalar.Random(crand.Reader)).(curves.PairingPoint),
+		e: curve.Scalar.Random(crand.Reader),
+		s: curve.Scalar.Random(crand.Reader),
+	}
+	resetTranscripts(batch)
+	bad := append(batch[:4:4], newBatchItem(t, curve, forged, generators, msgs))
+	bad = append(bad, batch[4:]...)
+	require.Error(t, BatchVerify(pk, generators, bad, crand.Reader))
+
+	// Another issuer key
+	otherPk, _, err := NewKeys(curve)
+	require.NoError(t, err)
+	resetTranscripts(batch)
+	require.Error(t, BatchVerify(otherPk, generators, batch, crand.Reader))
+
+	// A changed disclosed message
+	resetTranscripts(batch)
+	batch[3].Revealed[2] = curve.Scalar.New(100)
+	require.Error(t, BatchVerify(pk, generators, batch, crand.Reader))
+
+	require.Error(t, BatchVerify(pk, generators, nil, crand.Reader))
+	require.Error(t, BatchVerify(pk, generators, []*BatchItem{{}}, crand.Reader))
+}
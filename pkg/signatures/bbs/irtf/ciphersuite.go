@@ -0,0 +1,209 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package irtf implements the BBS signatures of
+// https://datatracker.ietf.org/doc/draft-irtf-cfrg-bbs-signatures/ with the BLS12-381-SHA-256 and
+// BLS12-381-SHAKE-256 ciphersuites, so signatures and proofs interoperate with other implementations of the draft.
+// Unlike the BBS+ signatures of the bbs package, messages are octet strings which are mapped to scalars
+// by the ciphersuite, the generators are fixed by the ciphersuite instead of derived from the public key,
+// and signatures are (A, e) without the blinding scalar s.
+package irtf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+const (
+	// Length of a scalar
+	ScalarSize = 32
+	// Length of a compressed G1 point
+	G1Size = 48
+	// Length of a compressed G2 point, i.e. of a public key
+	PublicKeySize = 96
+	// Length of a signature
+	SignatureSize = G1Size + ScalarSize
+	// expand_len of hash_to_scalar
+	expandLen = 48
+)
+
+// Ciphersuite is a ciphersuite of the draft, which fixes the hash functions and the generators
+type Ciphersuite struct {
+	// ciphersuite_id
+	id string
+	// api_id of the BBS interface which hashes messages to scalars
+	apiId string
+	shake bool
+	// The fixed point P1
+	p1 curves.Point
+}
+
+// BLS12381Sha256 returns the BLS12-381-SHA-256 ciphersuite
+func BLS12381Sha256() *Ciphersuite {
+	return newCiphersuite("BBS_BLS12381G1_XMD:SHA-256_SSWU_RO_", false)
+}
+
+// BLS12381Shake256 returns the BLS12-381-SHAKE-256 ciphersuite
+func BLS12381Shake256() *Ciphersuite {
+	return newCiphersuite("BBS_BLS12381G1_XOF:SHAKE-256_SSWU_RO_", true)
+}
+
+func newCiphersuite(id string, shake bool) *Ciphersuite {
+	c := &Ciphersuite{id: id, apiId: id + "H2G_HM2S_", shake: shake}
+	// P1 is the first generator for the seed BP_MESSAGE_GENERATOR_SEED
+	c.p1 = c.generators(1, c.apiId+"BP_MESSAGE_GENERATOR_SEED")[0]
+	return c
+}
+
+// ID returns the ciphersuite_id
+func (c *Ciphersuite) ID() string {
+	return c.id
+}
+
+// hasher returns the hash function of expand_message and hash_to_curve_g1
+func (c *Ciphersuite) hasher() *native.EllipticPointHasher {
+	if c.shake {
+		return native.EllipticPointHasherShake256Rfc9380()
+	}
+	return native.EllipticPointHasherSha256()
+}
+
+// expandMessage is expand_message_xmd with SHA-256 or expand_message_xof with SHAKE-256
+func (c *Ciphersuite) expandMessage(msg, dst []byte, n int) []byte {
+	if c.shake {
+		return native.ExpandMsgXof(c.hasher(), msg, dst, n)
+	}
+	return native.ExpandMsgXmd(c.hasher(), msg, dst, n)
+}
+
+// hashToScalar is hash_to_scalar
+func (c *Ciphersuite) hashToScalar(msg, dst []byte) curves.Scalar {
+	return scalarFromWide(c.expandMessage(msg, dst, expandLen))
+}
+
+// generators is create_generators, which returns `count` generators for the seed
+func (c *Ciphersuite) generators(count int, seed string) []curves.Point {
+	seedDst := []byte(c.apiId + "SIG_GENERATOR_SEED_")
+	generatorDst := []byte(c.apiId + "SIG_GENERATOR_DST_")
+	v := c.expandMessage([]byte(seed), seedDst, expandLen)
+	out := make([]curves.Point, count)
+	for i := range out {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(i+1))
+		v = c.expandMessage(append(v, n[:]...), seedDst, expandLen)
+		// hash_to_curve_g1 does not fail with a non-empty tag
+		out[i], _ = curves.HashToCurveWithHasher(curves.BLS12381G1(), c.hasher(), v, generatorDst)
+	}
+	return out
+}
+
+// messageGenerators returns Q_1 followed by the generators H_1, ..., H_L of L messages
+func (c *Ciphersuite) messageGenerators(l int) []curves.Point {
+	return c.generators(l+1, c.apiId+"MESSAGE_GENERATOR_SEED")
+}
+
+// messagesToScalars is messages_to_scalars
+func (c *Ciphersuite) messagesToScalars(messages [][]byte) []curves.Scalar {
+	dst := []byte(c.apiId + "MAP_MSG_TO_SCALAR_AS_HASH_")
+	out := make([]curves.Scalar, len(messages))
+	for i, m := range messages {
+		out[i] = c.hashToScalar(m, dst)
+	}
+	return out
+}
+
+// domain is calculate_domain
+func (c *Ciphersuite) domain(pk *PublicKey, generators []curves.Point, header []byte) curves.Scalar {
+	var s serializer
+	s.bytes(pk.value.ToAffineCompressed())
+	s.uint64(uint64(len(generators) - 1))
+	for _, g := range generators {
+		s.point(g)
+	}
+	s.bytes([]byte(c.apiId))
+	s.uint64(uint64(len(header)))
+	s.bytes(header)
+	return c.hashToScalar(s.buf, []byte(c.apiId+"H2S_"))
+}
+
+// computeB returns P1 + Q_1 * domain + sum H_i * msg_i over the given message indexes
+func (c *Ciphersuite) computeB(generators []curves.Point, domain curves.Scalar, indexes []int, messages []curves.Scalar) curves.Point {
+	points := []curves.Point{c.p1, generators[0]}
+	scalars := []curves.Scalar{curves.BLS12381G1().Scalar.One(), domain}
+	for k, i := range indexes {
+		points = append(points, generators[i+1])
+		scalars = append(scalars, messages[k])
+	}
+	return c.p1.SumOfProducts(points, scalars)
+}
+
+// serializer is serialize of the draft. Points are compressed, scalars are 32 big-endian bytes and
+// integers are 8 big-endian bytes
+type serializer struct {
+	buf []byte
+}
+
+func (s *serializer) bytes(b []byte) {
+	s.buf = append(s.buf, b...)
+}
+
+func (s *serializer) point(p curves.Point) {
+	s.bytes(p.ToAffineCompressed())
+}
+
+func (s *serializer) scalar(v curves.Scalar) {
+	s.bytes(scalarToBytes(v))
+}
+
+func (s *serializer) uint64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	s.bytes(b[:])
+}
+
+// scalarFromWide reduces big-endian bytes modulo r
+func scalarFromWide(b []byte) curves.Scalar {
+	sc := curves.BLS12381G1().Scalar
+	v := new(big.Int).SetBytes(b)
+	v.Mod(v, sc.(*curves.ScalarBls12381).Order())
+	s, _ := sc.SetBigInt(v)
+	return s
+}
+
+// scalarToBytes returns the 32 big-endian bytes of the scalar
+func scalarToBytes(s curves.Scalar) []byte {
+	var b [ScalarSize]byte
+	return s.BigInt().FillBytes(b[:])
+}
+
+// scalarFromBytes reads 32 big-endian bytes, which must encode a non-zero scalar
+func scalarFromBytes(b []byte) (curves.Scalar, error) {
+	if len(b) != ScalarSize {
+		return nil, fmt.Errorf("invalid scalar length")
+	}
+	sc := curves.BLS12381G1().Scalar
+	v := new(big.Int).SetBytes(b)
+	if v.Sign() == 0 || v.Cmp(sc.(*curves.ScalarBls12381).Order()) >= 0 {
+		return nil, fmt.Errorf("invalid scalar")
+	}
+	return sc.SetBigInt(v)
+}
+
+// pointFromBytes reads a compressed G1 point, which cannot be the identity
+func pointFromBytes(b []byte) (curves.Point, error) {
+	p, err := curves.BLS12381G1().Point.FromAffineCompressed(b)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsIdentity() {
+		return nil, fmt.Errorf("invalid point")
+	}
+	return p, nil
+}
@@ -0,0 +1,418 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package irtf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The test vectors of the draft, see the appendix "Test Vectors"
+const (
+	vectorKeyMaterial = "746869732d49532d6a7573742d616e2d546573742d494b4d2d746f2d67656e65726174652d246528724074232d6b6579"
+	vectorKeyInfo     = "746869732d49532d736f6d652d6b65792d6d657461646174612d746f2d62652d757365642d696e2d746573742d6b65792d67656e"
+	vectorHeader      = "11223344556677889900aabbccddeeff"
+	vectorPh          = "bed231d880675ed101ead304512e043ade9958dd0241ea70b4b3957fba941501"
+	// The seed of mocked_calculate_random_scalars
+	vectorMockSeed = "332e313431353932363533353839373933323338343632363433333833323739"
+)
+
+var vectorMessages = []string{
+	"9872ad089e452c7b6e283dfac2a80d58e8d0ff71cc4d5e310a1debdda4a45f02",
+	"c344136d9ab02da4dd5908bbba913ae6f58c2cc844b802a6f811f5fb075f9b80",
+	"7372e9daa5ed31e6cd5c825eac1b855e84476a1d94932aa348e07b73",
+	"77fe97eb97a1ebe2e81e4e3597a3ee740a66e9ef2412472c",
+	"496694774c5604ab1b2544eababcf0f53278ff50",
+	"515ae153e22aae04ad16f759e07237b4",
+	"d183ddc6e2665aa4e2f088af",
+	"ac55fb33a75909ed",
+	"96012096",
+	"",
+}
+
+type ciphersuiteVectors struct {
+	suite      *Ciphersuite
+	sk, pk     string
+	p1, q1, h1 string
+	// The scalar of the first message
+	m1 string
+	// The signatures of the first message and of all the messages
+	sig1, sig10 string
+	// The first of the 10 mocked random scalars of the draft
+	random1 string
+	// The proofs with the mocked random scalars of the first message disclosed, of all the messages disclosed
+	// and of the messages 0, 2, 4 and 6 disclosed out of all the messages
+	proof1, proof10, proof10Partial string
+}
+
+var testVectors = []ciphersuiteVectors{
+	{
+		suite: BLS12381Sha256(),
+		sk:    "60e55110f76883a13d030b2f6bd11883422d5abde717569fc0731f51237169fc",
+		pk: "a820f230f6ae38503b86c70dc50b61c58a77e45c39ab25c0652bbaa8fa136f2851bd4781c9dcde39fc9d1d52c9e60268" +
+			"061e7d7632171d91aa8d460acee0e96f1e7c4cfb12d3ff9ab5d5dc91c277db75c845d649ef3c4f63aebc364cd55ded0c",
+		p1: "a8ce256102840821a3e94ea9025e4662b205762f9776b3a766c872b948f1fd225e7c59698588e70d11406d161b4e28c9",
+		q1: "a9ec65b70a7fbe40c874c9eb041c2cb0a7af36ccec1bea48fa2ba4c2eb67ef7f9ecb17ed27d38d27cdeddff44c8137be",
+		h1: "98cd5313283aaf5db1b3ba8611fe6070d19e605de4078c38df36019fbaad0bd28dd090fd24ed27f7f4d22d5ff5dea7d4",
+		m1: "1cb5bb86114b34dc438a911617655a1db595abafac92f47c5001799cf624b430",
+		sig1: "84773160b824e194073a57493dac1a20b667af70cd2352d8af241c77658da5253aa8458317cca0eae615690d55b1f271" +
+			"64657dcafee1d5c1973947aa70e2cfbb4c892340be5969920d0916067b4565a0",
+		sig10: "8339b285a4acd89dec7777c09543a43e3cc60684b0a6f8ab335da4825c96e1463e28f8c5f4fd0641d19cec5920d3a8ff" +
+			"4bedb6c9691454597bbd298288abed3632078557b2ace7d44caed846e1a0a1e8",
+		random1: "04f8e2518993c4383957ad14eb13a023c4ad0c67d01ec86eeb902e732ed6df3f",
+		proof1: "94916292a7a6bade28456c601d3af33fcf39278d6594b467e128a3f83686a104ef2b2fcf72df0215eeaf69262ffe8194" +
+			"a19fab31a82ddbe06908985abc4c9825788b8a1610942d12b7f5debbea8985296361206dbace7af0cc834c80f33e0aad" +
+			"aeea5597befbb651827b5eed5a66f1a959bb46cfd5ca1a817a14475960f69b32c54db7587b5ee3ab665fbd37b506830a" +
+			"49f21d592f5e634f47cee05a025a2f8f94e73a6c15f02301d1178a92873b6e8634bafe4983c3e15a663d64080678dbf2" +
+			"9417519b78af042be2b3e1c4d08b8d520ffab008cbaaca5671a15b22c239b38e940cfeaa5e72104576a9ec4a6fad78c5" +
+			"32381aeaa6fb56409cef56ee5c140d455feeb04426193c57086c9b6d397d9418",
+		proof10: "b1f468aec2001c4f54cb56f707c6222a43e5803a25b2253e67b2210ab2ef9eab52db2d4b379935c4823281eaf767fd37" +
+			"b08ce80dc65de8f9769d27099ae649ad4c9b4bd2cc23edcba52073a298087d2495e6d57aaae051ef741adf1cbce65c64" +
+			"a73c8c97264177a76c4a03341956d2ae45ed3438ce598d5cda4f1bf9507fecef47855480b7b30b5e4052c92a4360110c" +
+			"67327365763f5aa9fb85ddcbc2975449b8c03db1216ca66b310f07d0ccf12ab460cdc6003b677fed36d0a23d0818a9d4" +
+			"d098d44f749e91008cf50e8567ef936704c8277b7710f41ab7e6e16408ab520edc290f9801349aee7b7b4e318e6a76e0" +
+			"28e1dea911e2e7baec6a6a174da1a22362717fbae1cd961d7bf4adce1d31c2ab",
+		proof10Partial: "a2ed608e8e12ed21abc2bf154e462d744a367c7f1f969bdbf784a2a134c7db2d340394223a5397a3011b1c340ebc4151" +
+			"99462ba6f31106d8a6da8b513b37a47afe93c9b3474d0d7a354b2edc1b88818b063332df774c141f7a07c48fe50d452f" +
+			"897739228c88afc797916dca01e8f03bd9c5375c7a7c59996e514bb952a436afd24457658acbaba5ddac2e693ac48135" +
+			"6918cd38025d86b28650e909defe9604a7259f44386b861608be742af7775a2e71a6070e5836f5f54dc43c60096834a5" +
+			"b6da295bf8f081f72b7cdf7f3b4347fb3ff19edaa9e74055c8ba46dbcb7594fb2b06633bb5324192eb9be91be0d33e45" +
+			"3b4d3127459de59a5e2193c900816f049a02cb9127dac894418105fa1641d5a206ec9c42177af9316f43341744147827" +
+			"6ca0303da8f941bf2e0222a43251cf5c2bf6eac1961890aa740534e519c1767e1223392a3a286b0f4d91f7f25217a786" +
+			"2b8fcc1810cdcfddde2a01c80fcc90b632585fec12dc4ae8fea1918e9ddeb9414623a457e88f53f545841f9d5dcb1f8e" +
+			"160d1560770aa79d65e2eca8edeaecb73fb7e995608b820c4a64de6313a370ba05dc25ed7c1d185192084963652f2870" +
+			"341bdaa4b1a37f8c06348f38a4f80c5a2650a21d59f09e8305dcd3fc3ac30e2a",
+	},
+	{
+		suite: BLS12381Shake256(),
+		sk:    "2eee0f60a8a3a8bec0ee942bfd46cbdae9a0738ee68f5a64e7238311cf09a079",
+		pk: "92d37d1d6cd38fea3a873953333eab23a4c0377e3e049974eb62bd45949cdeb18fb0490edcd4429adff56e65cbce42cf" +
+			"188b31bddbd619e419b99c2c41b38179eb001963bc3decaae0d9f702c7a8c004f207f46c734a5eae2e8e82833f3e7ea5",
+		p1: "8929dfbc7e6642c4ed9cba0856e493f8b9d7d5fcb0c31ef8fdcd34d50648a56c795e106e9eada6e0bda386b414150755",
+		q1: "a9d40131066399fd41af51d883f4473b0dcd7d028d3d34ef17f3241d204e28507d7ecae032afa1d5490849b7678ec1f8",
+		h1: "903c7ca0b7e78a2017d0baf74103bd00ca8ff9bf429f834f071c75ffe6bfdec6d6dca15417e4ac08ca4ae1e78b7adc0e",
+		m1: "1e0dea6c9ea8543731d331a0ab5f64954c188542b33c5bbc8ae5b3a830f2d99f",
+		sig1: "b9a622a4b404e6ca4c85c15739d2124a1deb16df750be202e2430e169bc27fb71c44d98e6d40792033e1c452145ada95" +
+			"030832c5dc778334f2f1b528eced21b0b97a12025a283d78b7136bb9825d04ef",
+		sig10: "956a3427b1b8e3642e60e6a7990b67626811adeec7a0a6cb4f770cdd7c20cf08faabb913ac94d18e1e92832e924cb6e2" +
+			"02912b624261fc6c59b0fea801547f67fb7d3253e1e2acbcf90ef59a6911931e",
+		random1: "1004262112c3eaa95941b2b0d1311c09c845db0099a50e67eda628ad26b43083",
+		proof1: "89e4ab0c160880e0c2f12a754b9c051ed7f5fccfee3d5cbbb62e1239709196c737fff4303054660f8fcd08267a5de668" +
+			"a2e395ebe8866bdcb0dff9786d7014fa5e3c8cf7b41f8d7510e27d307f18032f6b788e200b9d6509f40ce1d2f962ceed" +
+			"b023d58ee44d660434e6ba60ed0da1a5d2cde031b483684cd7c5b13295a82f57e209b584e8fe894bcc964117bf3521b4" +
+			"3d8e2eb59ce31f34d68b39f05bb2c625e4de5e61e95ff38bfd62ab07105d016414b45b01625c69965ad3c8a933e7b25d" +
+			"93daeb777302b966079827a99178240e6c3f13b7db2fb1f14790940e239d775ab32f539bdf9f9b582b250b0588299683" +
+			"2652f7f5d3b6e04744c73ada1702d6791940ccbd75e719537f7ace6ee817298d",
+		proof10: "91b0f598268c57b67bc9e55327c3c2b9b1654be89a0cf963ab392fa9e1637c565241d71fd6d7bbd7dfe243de85a9bac8" +
+			"b7461575c1e13b5055fed0b51fd0ec1433096607755b2f2f9ba6dc614dfa456916ca0d7fc6482b39c679cfb747a50ea1" +
+			"b3dd7ed57aaadc348361e2501a17317352e555a333e014e8e7d71eef808ae4f8fbdf45cd19fde45038bb310d5135f520" +
+			"5fc550b077e381fb3a3543dca31a0d8bba97bc0b660a5aa239eb74921e184aa3035fa01eaba32f52029319ec3df4fa4a" +
+			"4f716edb31a6ce19a19dbb971380099345070bd0fdeecf7c4774a33e0a116e069d5e215992fb637984802066dee69191" +
+			"46ae50b70ea52332dfe57f6e05c66e99f1764d8b890d121d65bfcc2984886ee0",
+		proof10Partial: "b1f8bf99a11c39f04e2a032183c1ead12956ad322dd06799c50f20fb8cf6b0ac279210ef5a2920a7be3ec2aa0911ace7" +
+			"b96811a98f3c1cceba4a2147ae763b3ba036f47bc21c39179f2b395e0ab1ac49017ea5b27848547bedd27be481c1dfc0" +
+			"b73372346feb94ab16189d4c525652b8d3361bab43463700720ecfb0ee75e595ea1b13330615011050a0dfcffdb21af3" +
+			"56dd39bf8bcbfd41bf95d913f4c9b2979e1ed2ca10ac7e881bb6a271722549681e398d29e9ba4eac8848b168eddd5e4a" +
+			"cec7df4103e2ed165e6e32edc80f0a3b28c36fb39ca19b4b8acee570deadba2da9ec20d1f236b571e0d4c2ea3b826fe9" +
+			"24175ed4dfffbf18a9cfa98546c241efb9164c444d970e8c89849bc8601e96cf228fdefe38ab3b7e289cac859e68d9cb" +
+			"b0e648faf692b27df5ff6539c30da17e5444a65143de02ca64cee7b0823be65865cdc310be038ec6b594b99280072ae0" +
+			"67bad1117b0ff3201a5506a8533b925c7ffae9cdb64558857db0ac5f5e0f18e750ae77ec9cf35263474fef3f78138c7a" +
+			"1ef5cfbc878975458239824fad3ce05326ba3969b1f5451bd82bd1f8075f3d32ece2d61d89a064ab4804c3c892d651d1" +
+			"1bc325464a71cd7aacc2d956a811aaff13ea4c35cef7842b656e8ba4758e7558",
+	},
+}
+
+func fromHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func vectorMessageBytes(t *testing.T) [][]byte {
+	msgs := make([][]byte, len(vectorMessages))
+	for i, m := range vectorMessages {
+		msgs[i] = fromHex(t, m)
+	}
+	return msgs
+}
+
+// mockReader returns the output of mocked_calculate_random_scalars for `count` scalars
+func mockReader(t *testing.T, c *Ciphersuite, count int) *bytes.Reader {
+	dst := []byte(c.apiId + "MOCK_RANDOM_SCALARS_DST_")
+	return bytes.NewReader(c.expandMessage(fromHex(t, vectorMockSeed), dst, expandLen*count))
+}
+
+func TestKeyGenVectors(t *testing.T) {
+	for _, v := range testVectors {
+		sk, err := v.suite.KeyGen(fromHex(t, vectorKeyMaterial), fromHex(t, vectorKeyInfo), nil)
+		require.NoError(t, err)
+		b, err := sk.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, v.sk, hex.EncodeToString(b), v.suite.ID())
+		b, err = sk.PublicKey().MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, v.pk, hex.EncodeToString(b), v.suite.ID())
+
+		_, err = v.suite.KeyGen(make([]byte, 31), nil, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestGeneratorVectors(t *testing.T) {
+	for _, v := range testVectors {
+		require.Equal(t, v.p1, hex.EncodeToString(v.suite.p1.ToAffineCompressed()), v.suite.ID())
+		generators := v.suite.messageGenerators(10)
+		require.Equal(t, v.q1, hex.EncodeToString(generators[0].ToAffineCompressed()), v.suite.ID())
+		require.Equal(t, v.h1, hex.EncodeToString(generators[1].ToAffineCompressed()), v.suite.ID())
+		// The first generators do not depend on the number of messages
+		require.True(t, v.suite.messageGenerators(1)[1].Equal(generators[1]))
+
+		m := v.suite.messagesToScalars(vectorMessageBytes(t)[:1])
+		require.Equal(t, v.m1, hex.EncodeToString(scalarToBytes(m[0])), v.suite.ID())
+
+		random, err := randomScalars(mockReader(t, v.suite, 10), 10)
+		require.NoError(t, err)
+		require.Equal(t, v.random1, hex.EncodeToString(scalarToBytes(random[0])), v.suite.ID())
+	}
+}
+
+func TestSignVectors(t *testing.T) {
+	msgs := vectorMessageBytes(t)
+	header := fromHex(t, vectorHeader)
+	for _, v := range testVectors {
+		sk := new(SecretKey)
+		require.NoError(t, sk.UnmarshalBinary(fromHex(t, v.sk)))
+		pk := new(PublicKey)
+		require.NoError(t, pk.UnmarshalBinary(fromHex(t, v.pk)))
+
+		for _, tc := range []struct {
+			msgs [][]byte
+			sig  string
+		}{{msgs[:1], v.sig1}, {msgs, v.sig10}} {
+			sig, err := v.suite.Sign(sk, pk, header, tc.msgs)
+			require.NoError(t, err)
+			b, err := sig.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, tc.sig, hex.EncodeToString(b), v.suite.ID())
+
+			expected := new(Signature)
+			require.NoError(t, expected.UnmarshalBinary(fromHex(t, tc.sig)))
+			require.NoError(t, v.suite.Verify(pk, expected, header, tc.msgs))
+			// Modified message, header or public key
+			modified := append([][]byte{[]byte("modified")}, tc.msgs[1:]...)
+			require.Error(t, v.suite.Verify(pk, expected, header, modified))
+			require.Error(t, v.suite.Verify(pk, expected, nil, tc.msgs))
+			other, _, err := v.suite.NewKeys(nil)
+			require.NoError(t, err)
+			require.Error(t, v.suite.Verify(other, expected, header, tc.msgs))
+		}
+	}
+	// The ciphersuites are not interchangeable
+	sig := new(Signature)
+	require.NoError(t, sig.UnmarshalBinary(fromHex(t, testVectors[0].sig1)))
+	pk := new(PublicKey)
+	require.NoError(t, pk.UnmarshalBinary(fromHex(t, testVectors[0].pk)))
+	require.Error(t, BLS12381Shake256().Verify(pk, sig, header, msgs[:1]))
+}
+
+func TestSignatureEncoding(t *testing.T) {
+	sig := new(Signature)
+	b := fromHex(t, testVectors[0].sig1)
+	require.Error(t, sig.UnmarshalBinary(b[:SignatureSize-1]))
+	// e must be non-zero and less than r
+	zero := append(append([]byte{}, b[:G1Size]...), make([]byte, ScalarSize)...)
+	require.Error(t, sig.UnmarshalBinary(zero))
+	large := append(append([]byte{}, b[:G1Size]...), bytes.Repeat([]byte{0xff}, ScalarSize)...)
+	require.Error(t, sig.UnmarshalBinary(large))
+	// A cannot be the identity
+	identity := append([]byte{0xc0}, make([]byte, G1Size-1)...)
+	require.Error(t, sig.UnmarshalBinary(append(identity, b[G1Size:]...)))
+
+	pk := new(PublicKey)
+	require.Error(t, pk.UnmarshalBinary(append([]byte{0xc0}, make([]byte, PublicKeySize-1)...)))
+	sk := new(SecretKey)
+	require.Error(t, sk.UnmarshalBinary(make([]byte, ScalarSize)))
+}
+
+func TestProof(t *testing.T) {
+	msgs := vectorMessageBytes(t)
+	header := fromHex(t, vectorHeader)
+	ph := fromHex(t, vectorPh)
+	for _, v := range testVectors {
+		c := v.suite
+		sk := new(SecretKey)
+		require.NoError(t, sk.UnmarshalBinary(fromHex(t, v.sk)))
+		pk := sk.PublicKey()
+		sig, err := c.Sign(sk, pk, header, msgs)
+		require.NoError(t, err)
+
+		for _, disclosed := range [][]int{nil, {0}, {0, 2, 4, 6}, {9}, {0, 1, 2, 3, 4, 5, 6, 7, 8, 9}} {
+			proof, err := c.ProofGen(pk, sig, header, ph, msgs, disclosed, nil)
+			require.NoError(t, err)
+			disclosedMsgs := make([][]byte, len(disclosed))
+			for k, i := range disclosed {
+				disclosedMsgs[k] = msgs[i]
+			}
+			require.NoError(t, c.ProofVerify(pk, proof, header, ph, disclosedMsgs, disclosed), "%v", disclosed)
+
+			b, err := proof.MarshalBinary()
+			require.NoError(t, err)
+			require.Len(t, b, 3*G1Size+(4+len(msgs)-len(disclosed))*ScalarSize)
+			decoded := new(Proof)
+			require.NoError(t, decoded.UnmarshalBinary(b))
+			require.NoError(t, c.ProofVerify(pk, decoded, header, ph, disclosedMsgs, disclosed))
+
+			require.Error(t, c.ProofVerify(pk, proof, header, []byte("other"), disclosedMsgs, disclosed))
+			require.Error(t, c.ProofVerify(pk, proof, nil, ph, disclosedMsgs, disclosed))
+			if len(disclosed) > 0 {
+				modified := append([][]byte{[]byte("modified")}, disclosedMsgs[1:]...)
+				require.Error(t, c.ProofVerify(pk, proof, header, ph, modified, disclosed))
+			}
+		}
+
+		// Proofs are randomized, except with the mocked random scalars
+		p1, err := c.ProofGen(pk, sig, header, ph, msgs, []int{0}, nil)
+		require.NoError(t, err)
+		p2, err := c.ProofGen(pk, sig, header, ph, msgs, []int{0}, nil)
+		require.NoError(t, err)
+		b1, _ := p1.MarshalBinary()
+		b2, _ := p2.MarshalBinary()
+		require.NotEqual(t, b1, b2)
+		p1, err = c.ProofGen(pk, sig, header, ph, msgs, []int{0}, mockReader(t, c, 14))
+		require.NoError(t, err)
+		p2, err = c.ProofGen(pk, sig, header, ph, msgs, []int{0}, mockReader(t, c, 14))
+		require.NoError(t, err)
+		b1, _ = p1.MarshalBinary()
+		b2, _ = p2.MarshalBinary()
+		require.Equal(t, b1, b2)
+
+		_, err = c.ProofGen(pk, sig, header, ph, msgs, []int{10}, nil)
+		require.Error(t, err)
+		_, err = c.ProofGen(pk, sig, header, ph, msgs, []int{1, 1}, nil)
+		require.Error(t, err)
+		require.Error(t, c.ProofVerify(pk, p1, header, ph, [][]byte{msgs[0]}, nil))
+	}
+}
+
+func TestProofVectors(t *testing.T) {
+	msgs := vectorMessageBytes(t)
+	header := fromHex(t, vectorHeader)
+	ph := fromHex(t, vectorPh)
+	for _, v := range testVectors {
+		c := v.suite
+		sk := new(SecretKey)
+		require.NoError(t, sk.UnmarshalBinary(fromHex(t, v.sk)))
+		pk := sk.PublicKey()
+		for _, tc := range []struct {
+			msgs      [][]byte
+			disclosed []int
+			proof     string
+		}{
+			{msgs[:1], []int{0}, v.proof1},
+			{msgs, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, v.proof10},
+			{msgs, []int{0, 2, 4, 6}, v.proof10Partial},
+		} {
+			sig, err := c.Sign(sk, pk, header, tc.msgs)
+			require.NoError(t, err)
+			// ProofGen draws 5 random scalars and one per undisclosed message
+			reader := mockReader(t, c, 5+len(tc.msgs)-len(tc.disclosed))
+			proof, err := c.ProofGen(pk, sig, header, ph, tc.msgs, tc.disclosed, reader)
+			require.NoError(t, err)
+			b, err := proof.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, tc.proof, hex.EncodeToString(b), "%s %v", c.ID(), tc.disclosed)
+
+			expected := new(Proof)
+			require.NoError(t, expected.UnmarshalBinary(fromHex(t, tc.proof)))
+			disclosedMsgs := make([][]byte, len(tc.disclosed))
+			for k, i := range tc.disclosed {
+				disclosedMsgs[k] = tc.msgs[i]
+			}
+			require.NoError(t, c.ProofVerify(pk, expected, header, ph, disclosedMsgs, tc.disclosed))
+		}
+	}
+}
+
+func TestProofWrongSignature(t *testing.T) {
+	c := BLS12381Sha256()
+	pk, sk, err := c.NewKeys(nil)
+	require.NoError(t, err)
+	msgs := [][]byte{[]byte("name"), []byte("birthdate")}
+	sig, err := c.Sign(sk, pk, nil, msgs)
+	require.NoError(t, err)
+	// A proof of a signature of other messages does not verify
+	proof, err := c.ProofGen(pk, sig, nil, nil, [][]byte{[]byte("name"), []byte("other")}, []int{0}, nil)
+	require.NoError(t, err)
+	require.Error(t, c.ProofVerify(pk, proof, nil, nil, [][]byte{[]byte("name")}, []int{0}))
+	// Nor for another public key
+	proof, err = c.ProofGen(pk, sig, nil, nil, msgs, []int{0}, nil)
+	require.NoError(t, err)
+	other, _, err := c.NewKeys(nil)
+	require.NoError(t, err)
+	require.Error(t, c.ProofVerify(other, proof, nil, nil, [][]byte{[]byte("name")}, []int{0}))
+	require.NoError(t, c.ProofVerify(pk, proof, nil, nil, [][]byte{[]byte("name")}, []int{0}))
+
+	require.Error(t, new(Proof).UnmarshalBinary(make([]byte, 3*G1Size+3*ScalarSize)))
+}
+
+func TestOctetsVectors(t *testing.T) {
+	msgs := vectorMessageBytes(t)
+	header := fromHex(t, vectorHeader)
+	ph := fromHex(t, vectorPh)
+	for _, v := range testVectors {
+		c := v.suite
+		pk := new(PublicKey)
+		require.NoError(t, pk.FromOctets(fromHex(t, v.pk)))
+		b, err := pk.ToOctets()
+		require.NoError(t, err)
+		require.Equal(t, v.pk, hex.EncodeToString(b), c.ID())
+
+		for _, tc := range []struct {
+			msgs [][]byte
+			sig  string
+		}{{msgs[:1], v.sig1}, {msgs, v.sig10}} {
+			sig := new(Signature)
+			require.NoError(t, sig.FromOctets(fromHex(t, tc.sig)))
+			b, err := sig.ToOctets()
+			require.NoError(t, err)
+			require.Equal(t, tc.sig, hex.EncodeToString(b), c.ID())
+			require.NoError(t, c.Verify(pk, sig, header, tc.msgs))
+		}
+
+		for _, tc := range []struct {
+			msgs      [][]byte
+			disclosed []int
+			proof     string
+		}{
+			{msgs[:1], []int{0}, v.proof1},
+			{msgs, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, v.proof10},
+			{msgs, []int{0, 2, 4, 6}, v.proof10Partial},
+		} {
+			proof := new(Proof)
+			require.NoError(t, proof.FromOctets(fromHex(t, tc.proof)))
+			b, err := proof.ToOctets()
+			require.NoError(t, err)
+			require.Equal(t, tc.proof, hex.EncodeToString(b), "%s %v", c.ID(), tc.disclosed)
+			disclosedMsgs := make([][]byte, len(tc.disclosed))
+			for k, i := range tc.disclosed {
+				disclosedMsgs[k] = tc.msgs[i]
+			}
+			require.NoError(t, c.ProofVerify(pk, proof, header, ph, disclosedMsgs, tc.disclosed))
+
+			// Truncated proofs, zero scalars and the identity are rejected
+			in := fromHex(t, tc.proof)
+			require.Error(t, new(Proof).FromOctets(in[:len(in)-1]))
+			require.Error(t, new(Proof).FromOctets(append(append([]byte{}, in[:len(in)-ScalarSize]...),
+				make([]byte, ScalarSize)...)))
+			identity := append([]byte{0xc0}, make([]byte, G1Size-1)...)
+			require.Error(t, new(Proof).FromOctets(append(identity, in[G1Size:]...)))
+		}
+	}
+}
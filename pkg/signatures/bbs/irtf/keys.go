@@ -0,0 +1,119 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package irtf
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// SecretKey is a BBS secret key
+type SecretKey struct {
+	value curves.Scalar
+}
+
+// PublicKey is a BBS public key in G2
+type PublicKey struct {
+	value curves.Point
+}
+
+// KeyGen derives a secret key from at least 32 bytes of key material, and optional key info and tag.
+// The tag defaults to api_id || "KEYGEN_DST_"
+func (c *Ciphersuite) KeyGen(keyMaterial, keyInfo, keyDst []byte) (*SecretKey, error) {
+	if len(keyMaterial) < 32 {
+		return nil, fmt.Errorf("key material must be at least 32 bytes")
+	}
+	if len(keyInfo) > 65535 {
+		return nil, fmt.Errorf("key info must be at most 65535 bytes")
+	}
+	if len(keyDst) == 0 {
+		keyDst = []byte(c.apiId + "KEYGEN_DST_")
+	}
+	input := make([]byte, 0, len(keyMaterial)+2+len(keyInfo))
+	input = append(input, keyMaterial...)
+	input = append(input, byte(len(keyInfo)>>8), byte(len(keyInfo)))
+	input = append(input, keyInfo...)
+	value := c.hashToScalar(input, keyDst)
+	if value.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	return &SecretKey{value}, nil
+}
+
+// NewKeys creates a key pair from 32 bytes of key material read from `reader`, or crypto/rand if it is nil
+func (c *Ciphersuite) NewKeys(reader io.Reader) (*PublicKey, *SecretKey, error) {
+	if reader == nil {
+		reader = crand.Reader
+	}
+	keyMaterial := make([]byte, 32)
+	if _, err := io.ReadFull(reader, keyMaterial); err != nil {
+		return nil, nil, err
+	}
+	sk, err := c.KeyGen(keyMaterial, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sk.PublicKey(), sk, nil
+}
+
+// PublicKey returns the public key SK * BP2
+func (sk *SecretKey) PublicKey() *PublicKey {
+	return &PublicKey{curves.BLS12381G2().ScalarBaseMult(sk.value)}
+}
+
+// MarshalBinary returns the 32 big-endian bytes of the secret key
+func (sk SecretKey) MarshalBinary() ([]byte, error) {
+	if sk.value == nil {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	return scalarToBytes(sk.value), nil
+}
+
+// UnmarshalBinary reads 32 big-endian bytes, which cannot encode zero
+func (sk *SecretKey) UnmarshalBinary(in []byte) error {
+	value, err := scalarFromBytes(in)
+	if err != nil {
+		return fmt.Errorf("invalid secret key: %v", err)
+	}
+	sk.value = value
+	return nil
+}
+
+// MarshalBinary returns the compressed public key
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	if pk.value == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	return pk.value.ToAffineCompressed(), nil
+}
+
+// UnmarshalBinary reads a compressed public key, which cannot be the identity
+func (pk *PublicKey) UnmarshalBinary(in []byte) error {
+	if len(in) != PublicKeySize {
+		return fmt.Errorf("public key must be %d bytes", PublicKeySize)
+	}
+	value, err := curves.BLS12381G2().Point.FromAffineCompressed(in)
+	if err != nil {
+		return err
+	}
+	if value.IsIdentity() {
+		return fmt.Errorf("invalid public key")
+	}
+	pk.value = value
+	return nil
+}
+
+// valid returns an error if the public key is not initialized or is the identity
+func (pk *PublicKey) valid() error {
+	if pk == nil || pk.value == nil || pk.value.IsIdentity() {
+		return fmt.Errorf("invalid public key")
+	}
+	return nil
+}
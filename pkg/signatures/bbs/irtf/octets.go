@@ -0,0 +1,41 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package irtf
+
+// The octet encodings are the ones of the draft: points are compressed in the ZCash format,
+// scalars are 32 big-endian bytes and decoding rejects the identity, points outside the subgroup,
+// zero and scalars which are not less than r. They interoperate with other implementations of the draft.
+
+// ToOctets is signature_to_octets and returns A || e
+func (sig Signature) ToOctets() ([]byte, error) {
+	return sig.MarshalBinary()
+}
+
+// FromOctets is octets_to_signature
+func (sig *Signature) FromOctets(in []byte) error {
+	return sig.UnmarshalBinary(in)
+}
+
+// ToOctets is point_to_octets_E2 of the public key W
+func (pk PublicKey) ToOctets() ([]byte, error) {
+	return pk.MarshalBinary()
+}
+
+// FromOctets is octets_to_pubkey
+func (pk *PublicKey) FromOctets(in []byte) error {
+	return pk.UnmarshalBinary(in)
+}
+
+// ToOctets is proof_to_octets and returns Abar || Bbar || D || e^ || r1^ || r3^ || m^_1 || ... || m^_U || challenge
+func (p Proof) ToOctets() ([]byte, error) {
+	return p.MarshalBinary()
+}
+
+// FromOctets is octets_to_proof
+func (p *Proof) FromOctets(in []byte) error {
+	return p.UnmarshalBinary(in)
+}
@@ -0,0 +1,263 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package irtf
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// Proof is a zero-knowledge proof of knowledge of a signature which discloses some of the messages
+type Proof struct {
+	aBar, bBar, d curves.Point
+	eHat, r1Hat   curves.Scalar
+	r3Hat         curves.Scalar
+	// The responses of the undisclosed messages
+	mHat      []curves.Scalar
+	challenge curves.Scalar
+}
+
+// MarshalBinary returns Abar || Bbar || D || e^ || r1^ || r3^ || m^_1 || ... || m^_U || challenge
+func (p Proof) MarshalBinary() ([]byte, error) {
+	if p.aBar == nil || p.challenge == nil {
+		return nil, fmt.Errorf("invalid proof")
+	}
+	var s serializer
+	s.point(p.aBar)
+	s.point(p.bBar)
+	s.point(p.d)
+	s.scalar(p.eHat)
+	s.scalar(p.r1Hat)
+	s.scalar(p.r3Hat)
+	for _, m := range p.mHat {
+		s.scalar(m)
+	}
+	s.scalar(p.challenge)
+	return s.buf, nil
+}
+
+// UnmarshalBinary reads a proof written by MarshalBinary
+func (p *Proof) UnmarshalBinary(in []byte) error {
+	if len(in) < 3*G1Size+4*ScalarSize || (len(in)-3*G1Size)%ScalarSize != 0 {
+		return fmt.Errorf("invalid proof length")
+	}
+	points := make([]curves.Point, 3)
+	for i := range points {
+		var err error
+		points[i], err = pointFromBytes(in[i*G1Size : (i+1)*G1Size])
+		if err != nil {
+			return fmt.Errorf("invalid proof: %v", err)
+		}
+	}
+	in = in[3*G1Size:]
+	scalars := make([]curves.Scalar, len(in)/ScalarSize)
+	for i := range scalars {
+		var err error
+		scalars[i], err = scalarFromBytes(in[i*ScalarSize : (i+1)*ScalarSize])
+		if err != nil {
+			return fmt.Errorf("invalid proof: %v", err)
+		}
+	}
+	n := len(scalars)
+	*p = Proof{
+		aBar:      points[0],
+		bBar:      points[1],
+		d:         points[2],
+		eHat:      scalars[0],
+		r1Hat:     scalars[1],
+		r3Hat:     scalars[2],
+		mHat:      scalars[3 : n-1],
+		challenge: scalars[n-1],
+	}
+	return nil
+}
+
+// ProofGen proves knowledge of a signature of the messages and the header, and discloses the messages at
+// the given indexes, which start at 0. The presentation header `ph` binds the proof to a context,
+// e.g. a nonce of the verifier. Random scalars are read from `reader`, or crypto/rand if it is nil
+func (c *Ciphersuite) ProofGen(pk *PublicKey, sig *Signature, header, ph []byte, messages [][]byte,
+	disclosedIndexes []int, reader io.Reader) (*Proof, error) {
+	if err := pk.valid(); err != nil {
+		return nil, err
+	}
+	if sig == nil || sig.a == nil || sig.e == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	disclosed, err := sortedIndexes(disclosedIndexes, len(messages))
+	if err != nil {
+		return nil, err
+	}
+	undisclosed := complementIndexes(disclosed, len(messages))
+
+	msgs := c.messagesToScalars(messages)
+	generators := c.messageGenerators(len(msgs))
+	random, err := randomScalars(reader, 5+len(undisclosed))
+	if err != nil {
+		return nil, err
+	}
+	r1, r2, eTilde, r1Tilde, r3Tilde := random[0], random[1], random[2], random[3], random[4]
+	mTilde := random[5:]
+
+	// ProofInit
+	domain := c.domain(pk, generators, header)
+	b := c.computeB(generators, domain, allIndexes(len(msgs)), msgs)
+	d := b.Mul(r2)
+	aBar := sig.a.Mul(r1.Mul(r2))
+	bBar := d.Mul(r1).Sub(aBar.Mul(sig.e))
+	t1 := aBar.Mul(eTilde).Add(d.Mul(r1Tilde))
+	points := []curves.Point{d}
+	scalars := []curves.Scalar{r3Tilde}
+	for k, j := range undisclosed {
+		points = append(points, generators[j+1])
+		scalars = append(scalars, mTilde[k])
+	}
+	t2 := d.SumOfProducts(points, scalars)
+
+	disclosedMsgs := make([]curves.Scalar, len(disclosed))
+	for k, i := range disclosed {
+		disclosedMsgs[k] = msgs[i]
+	}
+	challenge := c.challenge(aBar, bBar, d, t1, t2, domain, disclosed, disclosedMsgs, ph)
+
+	// ProofFinalize
+	r3, err := r2.Invert()
+	if err != nil {
+		return nil, err
+	}
+	mHat := make([]curves.Scalar, len(undisclosed))
+	for k, j := range undisclosed {
+		mHat[k] = mTilde[k].Add(msgs[j].Mul(challenge))
+	}
+	return &Proof{
+		aBar:      aBar,
+		bBar:      bBar,
+		d:         d,
+		eHat:      eTilde.Add(sig.e.Mul(challenge)),
+		r1Hat:     r1Tilde.Sub(r1.Mul(challenge)),
+		r3Hat:     r3Tilde.Sub(r3.Mul(challenge)),
+		mHat:      mHat,
+		challenge: challenge,
+	}, nil
+}
+
+// ProofVerify checks a proof for the header and presentation header, where disclosedMessages are the messages
+// at disclosedIndexes in ascending order
+func (c *Ciphersuite) ProofVerify(pk *PublicKey, proof *Proof, header, ph []byte, disclosedMessages [][]byte,
+	disclosedIndexes []int) error {
+	if err := pk.valid(); err != nil {
+		return err
+	}
+	if proof == nil || proof.aBar == nil || proof.challenge == nil {
+		return fmt.Errorf("invalid proof")
+	}
+	if len(disclosedMessages) != len(disclosedIndexes) {
+		return fmt.Errorf("number of disclosed messages and indexes should be equal")
+	}
+	l := len(disclosedIndexes) + len(proof.mHat)
+	for i := 1; i < len(disclosedIndexes); i++ {
+		if disclosedIndexes[i] <= disclosedIndexes[i-1] {
+			return fmt.Errorf("disclosed indexes must be in ascending order")
+		}
+	}
+	disclosed, err := sortedIndexes(disclosedIndexes, l)
+	if err != nil {
+		return err
+	}
+	undisclosed := complementIndexes(disclosed, l)
+
+	msgs := c.messagesToScalars(disclosedMessages)
+	generators := c.messageGenerators(l)
+
+	// ProofVerifyInit
+	domain := c.domain(pk, generators, header)
+	t1 := proof.bBar.Mul(proof.challenge).Add(proof.aBar.Mul(proof.eHat)).Add(proof.d.Mul(proof.r1Hat))
+	bv := c.computeB(generators, domain, disclosed, msgs)
+	points := []curves.Point{bv, proof.d}
+	scalars := []curves.Scalar{proof.challenge, proof.r3Hat}
+	for k, j := range undisclosed {
+		points = append(points, generators[j+1])
+		scalars = append(scalars, proof.mHat[k])
+	}
+	t2 := bv.SumOfProducts(points, scalars)
+
+	challenge := c.challenge(proof.aBar, proof.bBar, proof.d, t1, t2, domain, disclosed, msgs, ph)
+	if challenge.Cmp(proof.challenge) != 0 {
+		return fmt.Errorf("invalid proof")
+	}
+	// e(Abar, W) * e(Bbar, -BP2) == 1
+	if !pairingIsOne(proof.aBar, pk.value, proof.bBar, curves.BLS12381G2().NewGeneratorPoint().Neg()) {
+		return fmt.Errorf("invalid proof")
+	}
+	return nil
+}
+
+// challenge is ProofChallengeCalculate
+func (c *Ciphersuite) challenge(aBar, bBar, d, t1, t2 curves.Point, domain curves.Scalar, disclosed []int,
+	disclosedMsgs []curves.Scalar, ph []byte) curves.Scalar {
+	var s serializer
+	s.uint64(uint64(len(disclosed)))
+	for k, i := range disclosed {
+		s.uint64(uint64(i))
+		s.scalar(disclosedMsgs[k])
+	}
+	for _, p := range []curves.Point{aBar, bBar, d, t1, t2} {
+		s.point(p)
+	}
+	s.scalar(domain)
+	s.uint64(uint64(len(ph)))
+	s.bytes(ph)
+	return c.hashToScalar(s.buf, []byte(c.apiId+"H2S_"))
+}
+
+// randomScalars is calculate_random_scalars
+func randomScalars(reader io.Reader, count int) ([]curves.Scalar, error) {
+	out := make([]curves.Scalar, count)
+	buf := make([]byte, expandLen)
+	for i := range out {
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		out[i] = scalarFromWide(buf)
+	}
+	return out, nil
+}
+
+// sortedIndexes returns the indexes in ascending order after checking they are distinct and less than `l`
+func sortedIndexes(indexes []int, l int) ([]int, error) {
+	sorted := append([]int{}, indexes...)
+	sort.Ints(sorted)
+	for k, i := range sorted {
+		if i < 0 || i >= l {
+			return nil, fmt.Errorf("invalid disclosed index %d", i)
+		}
+		if k > 0 && sorted[k-1] == i {
+			return nil, fmt.Errorf("duplicate disclosed index %d", i)
+		}
+	}
+	return sorted, nil
+}
+
+// complementIndexes returns the indexes less than `l` which are not in `sorted`
+func complementIndexes(sorted []int, l int) []int {
+	out := make([]int, 0, l-len(sorted))
+	k := 0
+	for i := 0; i < l; i++ {
+		if k < len(sorted) && sorted[k] == i {
+			k++
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package irtf
+
+import (
+	"fmt"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// Signature is a BBS signature (A, e)
+type Signature struct {
+	a curves.Point
+	e curves.Scalar
+}
+
+// MarshalBinary returns the compressed A followed by the 32 big-endian bytes of e
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	if sig.a == nil || sig.e == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	return append(sig.a.ToAffineCompressed(), scalarToBytes(sig.e)...), nil
+}
+
+// UnmarshalBinary reads a signature written by MarshalBinary
+func (sig *Signature) UnmarshalBinary(in []byte) error {
+	if len(in) != SignatureSize {
+		return fmt.Errorf("signature must be %d bytes", SignatureSize)
+	}
+	a, err := pointFromBytes(in[:G1Size])
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	e, err := scalarFromBytes(in[G1Size:])
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	sig.a, sig.e = a, e
+	return nil
+}
+
+// Sign signs the messages and the header, which every proof discloses, with the secret key
+// and its public key. The signature is deterministic
+func (c *Ciphersuite) Sign(sk *SecretKey, pk *PublicKey, header []byte, messages [][]byte) (*Signature, error) {
+	if sk == nil || sk.value == nil || sk.value.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	if err := pk.valid(); err != nil {
+		return nil, err
+	}
+	msgs := c.messagesToScalars(messages)
+	generators := c.messageGenerators(len(msgs))
+	domain := c.domain(pk, generators, header)
+
+	var s serializer
+	s.scalar(sk.value)
+	for _, m := range msgs {
+		s.scalar(m)
+	}
+	s.scalar(domain)
+	e := c.hashToScalar(s.buf, []byte(c.apiId+"H2S_"))
+
+	b := c.computeB(generators, domain, allIndexes(len(msgs)), msgs)
+	exp, err := sk.value.Add(e).Invert()
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	return &Signature{a: b.Mul(exp), e: e}, nil
+}
+
+// Verify checks the signature of the messages and the header
+func (c *Ciphersuite) Verify(pk *PublicKey, sig *Signature, header []byte, messages [][]byte) error {
+	if err := pk.valid(); err != nil {
+		return err
+	}
+	if sig == nil || sig.a == nil || sig.e == nil || sig.a.IsIdentity() {
+		return fmt.Errorf("invalid signature")
+	}
+	msgs := c.messagesToScalars(messages)
+	generators := c.messageGenerators(len(msgs))
+	domain := c.domain(pk, generators, header)
+	b := c.computeB(generators, domain, allIndexes(len(msgs)), msgs)
+
+	// e(A, W + BP2 * e) * e(B, -BP2) == 1
+	bp2 := curves.BLS12381G2().NewGeneratorPoint()
+	w := pk.value.Add(bp2.Mul(sig.e))
+	if !pairingIsOne(sig.a, w, b, bp2.Neg()) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// pairingIsOne returns true if the product of the pairings of the G1 and G2 points is one
+func pairingIsOne(points ...curves.Point) bool {
+	pairs := make([]curves.PairingPoint, len(points))
+	for i, p := range points {
+		pairs[i] = p.(curves.PairingPoint)
+	}
+	return pairs[0].MultiPairing(pairs...).IsOne()
+}
+
+// allIndexes returns 0, ..., n-1
+func allIndexes(n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
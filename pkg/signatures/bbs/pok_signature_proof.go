@@ -211,3 +211,20 @@ func (pok PokSignatureProof) Verify(
 	}
 	return pok.VerifySigPok(pk) && challenge.Cmp(vChallenge) == 0
 }
+
+// hiddenResponse returns the Schnorr response for the hidden message at `index`.
+// Messages proven with a common.SharedBlindingMessage have equal responses
+// across proofs when the underlying messages are equal
+func (pok PokSignatureProof) hiddenResponse(index int, revealedMsgs map[int]curves.Scalar) curves.Scalar {
+	// The first two responses are for r3 and s'
+	j := 2
+	for i := 0; i < index; i++ {
+		if _, contains := revealedMsgs[i]; !contains {
+			j++
+		}
+	}
+	if j >= len(pok.proof2) {
+		return nil
+	}
+	return pok.proof2[j]
+}
@@ -0,0 +1,203 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bbs
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+func issueTestCredential(t *testing.T, curve *curves.PairingCurve, msgs []curves.Scalar) (*PublicKey, *Credential) {
+	pk, sk, err := NewKeys(curve)
+	require.NoError(t, err)
+	generators, err := new(MessageGenerators).Init(pk, len(msgs))
+	require.NoError(t, err)
+	sig, err := sk.Sign(generators, msgs)
+	require.NoError(t, err)
+	return pk, &Credential{
+		Signature:  sig,
+		Generators: generators,
+		Messages:   msgs,
+	}
+}
+
+func TestPresentationWorks(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	// The holder's id is the first attribute of both credentials
+	id := curve.Scalar.Hash([]byte("holder"))
+	pk1, cred1 := issueTestCredential(t, curve, []curves.Scalar{id, curve.Scalar.New(2), curve.Scalar.New(3)})
+	pk2, cred2 := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(4), id})
+
+	request := common.NewPresentationRequest([]byte("verifier nonce"))
+	c1 := request.AddCredential("passport", 3, 1)
+	c2 := request.AddCredential("membership", 2, 0)
+	request.AddEquality(
+		common.AttributeRef{Credential: c1, Attribute: 0},
+		common.AttributeRef{Credential: c2, Attribute: 1},
+	)
+	require.NoError(t, request.Validate())
+
+	presentation, err := NewPresentation(request, []*Credential{cred1, cred2}, crand.Reader)
+	require.NoError(t, err)
+	require.Equal(t, 0, presentation.Revealed[0][1].Cmp(curve.Scalar.New(2)))
+	require.Equal(t, 0, presentation.Revealed[1][0].Cmp(curve.Scalar.New(4)))
+
+	keys := []*PublicKey{pk1, pk2}
+	generators := []*MessageGenerators{cred1.Generators, cred2.Generators}
+	require.NoError(t, presentation.Verify(request, keys, generators))
+
+	// Serialization round trip
+	data, err := presentation.MarshalBinary()
+	require.NoError(t, err)
+	decoded := new(Presentation).Init(curve)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.NoError(t, decoded.Verify(request, keys, generators))
+	require.Error(t, new(Presentation).Init(curve).UnmarshalBinary(data[:len(data)-1]))
+
+	// A different nonce must not verify
+	other := *request
+	other.Nonce = []byte("another nonce")
+	require.Error(t, presentation.Verify(&other, keys, generators))
+
+	// Swapped keys must not verify
+	require.Error(t, presentation.Verify(request, []*PublicKey{pk2, pk1}, generators))
+
+	// Tampering with a disclosed message must not verify
+	presentation.Revealed[0][1] = curve.Scalar.New(5)
+	require.Error(t, presentation.Verify(request, keys, generators))
+}
+
+func TestPresentationEqualityFails(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	pk1, cred1 := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(1), curve.Scalar.New(2)})
+	pk2, cred2 := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(3), curve.Scalar.New(4)})
+
+	request := common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2)
+	request.AddCredential("b", 2)
+	request.AddEquality(
+		common.AttributeRef{Credential: 0, Attribute: 0},
+		common.AttributeRef{Credential: 1, Attribute: 0},
+	)
+	_, err := NewPresentation(request, []*Credential{cred1, cred2}, crand.Reader)
+	require.Error(t, err)
+
+	// A presentation made without the equality must not satisfy a request that has it
+	loose := common.NewPresentationRequest([]byte("nonce"))
+	loose.AddCredential("a", 2)
+	loose.AddCredential("b", 2)
+	presentation, err := NewPresentation(loose, []*Credential{cred1, cred2}, crand.Reader)
+	require.NoError(t, err)
+	keys := []*PublicKey{pk1, pk2}
+	generators := []*MessageGenerators{cred1.Generators, cred2.Generators}
+	require.NoError(t, presentation.Verify(loose, keys, generators))
+	require.Error(t, presentation.Verify(request, keys, generators))
+}
+
+func TestPresentationRange(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	// The birthdate is hidden, the id is shared with a second credential
+	id := curve.Scalar.Hash([]byte("holder"))
+	birthdate := curve.Scalar.New(19900515)
+	pk1, cred1 := issueTestCredential(t, curve, []curves.Scalar{id, birthdate, curve.Scalar.New(3)})
+	pk2, cred2 := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(4), id})
+	keys := []*PublicKey{pk1, pk2}
+	generators := []*MessageGenerators{cred1.Generators, cred2.Generators}
+
+	birth := common.AttributeRef{Credential: 0, Attribute: 1}
+	request := common.NewPresentationRequest([]byte("verifier nonce"))
+	request.AddCredential("passport", 3, 2)
+	request.AddCredential("membership", 2)
+	request.AddEquality(common.AttributeRef{Credential: 0, Attribute: 0}, common.AttributeRef{Credential: 1, Attribute: 1})
+	request.AddRange(birth, 19000101, 20051017)
+	request.AddRange(common.AttributeRef{Credential: 1, Attribute: 0}, 4, 4)
+	require.NoError(t, request.Validate())
+
+	presentation, err := NewPresentation(request, []*Credential{cred1, cred2}, crand.Reader)
+	require.NoError(t, err)
+	require.Len(t, presentation.Ranges, 2)
+	require.NoError(t, presentation.Verify(request, keys, generators))
+
+	data, err := presentation.MarshalBinary()
+	require.NoError(t, err)
+	decoded := new(Presentation).Init(curve)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.NoError(t, decoded.Verify(request, keys, generators))
+
+	// The proofs are bound to the bounds of the request
+	other := *request
+	other.Ranges = []common.RangePredicate{{Attribute: birth, Lower: 19000101, Upper: 20051016}, request.Ranges[1]}
+	require.Error(t, presentation.Verify(&other, keys, generators))
+
+	// A commitment to another value does not match the signature proof
+	tampered := *presentation
+	tampered.Ranges = []*RangeProof{presentation.Ranges[0], presentation.Ranges[0]}
+	require.Error(t, tampered.Verify(request, keys, generators))
+
+	// A presentation made without the ranges does not satisfy a request that has them
+	loose := *request
+	loose.Ranges = nil
+	presentation, err = NewPresentation(&loose, []*Credential{cred1, cred2}, crand.Reader)
+	require.NoError(t, err)
+	require.Error(t, presentation.Verify(request, keys, generators))
+
+	// The holder cannot prove an attribute out of range
+	for _, r := range []common.RangePredicate{
+		{Attribute: birth, Lower: 19900516, Upper: 20051017},
+		{Attribute: birth, Lower: 0, Upper: 19900514},
+	} {
+		strict := loose
+		strict.Ranges = []common.RangePredicate{r}
+		_, err = NewPresentation(&strict, []*Credential{cred1, cred2}, crand.Reader)
+		require.Error(t, err)
+	}
+}
+
+func TestPresentationRequestValidate(t *testing.T) {
+	require.Error(t, common.NewPresentationRequest(nil).Validate())
+
+	request := common.NewPresentationRequest([]byte("nonce"))
+	require.Error(t, request.Validate())
+
+	request.AddCredential("a", 2, 2)
+	require.Error(t, request.Validate())
+
+	request = common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2, 0, 0)
+	require.Error(t, request.Validate())
+
+	request = common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2, 0)
+	request.AddCredential("b", 2)
+	request.AddEquality(common.AttributeRef{Credential: 0, Attribute: 0}, common.AttributeRef{Credential: 1, Attribute: 0})
+	require.Error(t, request.Validate())
+
+	request = common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2)
+	request.AddEquality(common.AttributeRef{Credential: 0, Attribute: 0})
+	require.Error(t, request.Validate())
+
+	request = common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2, 0)
+	request.AddRange(common.AttributeRef{Credential: 0, Attribute: 0}, 1, 2)
+	require.Error(t, request.Validate())
+
+	request = common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2)
+	request.AddRange(common.AttributeRef{Credential: 0, Attribute: 1}, 2, 1)
+	require.Error(t, request.Validate())
+
+	request = common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2)
+	request.AddRange(common.AttributeRef{Credential: 0, Attribute: 2}, 1, 2)
+	require.Error(t, request.Validate())
+}
@@ -0,0 +1,258 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bbs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/gtank/merlin"
+
+	"github.com/etclab/kryptology/pkg/bulletproof"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+const (
+	rangeTranscriptLabel = "bbs+ range predicate"
+	// Both m - lower and upper - m are proven to be 64-bit values
+	rangeBits = 64
+)
+
+var (
+	rangeGeneratorsDomain = []byte("bbs+ range predicate generators")
+	rangeVectorDomain     = []byte("bbs+ range predicate vectors")
+	rangeIppDomain        = []byte("bbs+ range predicate ipp")
+)
+
+// RangeProof proves a hidden attribute satisfies a common.RangePredicate without revealing it.
+// The attribute m is committed as g * m + h * gamma in G1 of BLS12-381. A Schnorr proof of the opening
+// reuses the response for m of the signature proof of knowledge, which links the commitment to the signed
+// attribute, and two bulletproofs show that m - lower and upper - m are 64-bit values
+type RangeProof struct {
+	Commitment curves.Point
+	// The Schnorr response for gamma
+	blinding     curves.Scalar
+	lower, upper *bulletproof.RangeProof
+}
+
+// rangeWitness holds the secrets of a range proof until the challenge is known
+type rangeWitness struct {
+	gamma, gammaTilde curves.Scalar
+	// The Schnorr commitment g * m~ + h * gamma~
+	capT  curves.Point
+	proof *RangeProof
+}
+
+// rangeCurve is the curve of the commitments, whose scalars are the signed messages
+func rangeCurve() *curves.Curve {
+	return curves.BLS12381G1()
+}
+
+func rangeGenerators() (*bulletproof.RangeProofGenerators, error) {
+	return bulletproof.NewRangeProofGenerators(rangeGeneratorsDomain, *rangeCurve())
+}
+
+// rangeTranscript returns the bulletproof transcript of the i-th range predicate of the request
+func rangeTranscript(request *common.PresentationRequest, i int) *merlin.Transcript {
+	transcript := merlin.NewTranscript(rangeTranscriptLabel)
+	request.AddToTranscript(transcript)
+	var t [4]byte
+	binary.BigEndian.PutUint32(t[:], uint32(i))
+	transcript.AppendMessage([]byte("predicate"), t[:])
+	return transcript
+}
+
+// rangeBounds returns the bounds of the predicate as scalars
+func rangeBounds(r common.RangePredicate) (curves.Scalar, curves.Scalar, error) {
+	sc := rangeCurve().Scalar
+	lower, err := sc.SetBigInt(new(big.Int).SetUint64(r.Lower))
+	if err != nil {
+		return nil, nil, err
+	}
+	upper, err := sc.SetBigInt(new(big.Int).SetUint64(r.Upper))
+	if err != nil {
+		return nil, nil, err
+	}
+	return lower, upper, nil
+}
+
+// newRangeWitnesses commits to the attributes of the range predicates and proves they are in range.
+// The blinding of each attribute must be the one used in its signature proof of knowledge
+func newRangeWitnesses(request *common.PresentationRequest, credentials []*Credential,
+	blindings map[common.AttributeRef]curves.Scalar, reader io.Reader) ([]*rangeWitness, error) {
+	if len(request.Ranges) == 0 {
+		return nil, nil
+	}
+	generators, err := rangeGenerators()
+	if err != nil {
+		return nil, err
+	}
+	prover, err := bulletproof.NewRangeProver(rangeBits, rangeVectorDomain, rangeIppDomain, *rangeCurve())
+	if err != nil {
+		return nil, err
+	}
+	witnesses := make([]*rangeWitness, len(request.Ranges))
+	for i, r := range request.Ranges {
+		m := credentials[r.Attribute.Credential].Messages[r.Attribute.Attribute]
+		v := m.BigInt()
+		if v.Cmp(new(big.Int).SetUint64(r.Lower)) < 0 || v.Cmp(new(big.Int).SetUint64(r.Upper)) > 0 {
+			return nil, fmt.Errorf("attribute %v does not satisfy range %d", r.Attribute, i)
+		}
+		lower, upper, err := rangeBounds(r)
+		if err != nil {
+			return nil, err
+		}
+		gamma := rangeCurve().Scalar.Random(reader)
+		transcript := rangeTranscript(request, i)
+		lowerProof, err := prover.Prove(m.Sub(lower), gamma, rangeBits, *generators, transcript)
+		if err != nil {
+			return nil, err
+		}
+		upperProof, err := prover.Prove(upper.Sub(m), gamma.Neg(), rangeBits, *generators, transcript)
+		if err != nil {
+			return nil, err
+		}
+		gammaTilde := rangeCurve().Scalar.Random(reader)
+		witnesses[i] = &rangeWitness{
+			gamma:      gamma,
+			gammaTilde: gammaTilde,
+			capT:       generators.Commit(blindings[r.Attribute], gammaTilde),
+			proof: &RangeProof{
+				Commitment: generators.Commit(m, gamma),
+				lower:      lowerProof,
+				upper:      upperProof,
+			},
+		}
+	}
+	return witnesses, nil
+}
+
+// getChallengeContribution adds the commitment and the Schnorr commitment to the presentation transcript
+func (w *rangeWitness) getChallengeContribution(transcript *merlin.Transcript) {
+	addRangeToTranscript(w.proof.Commitment, w.capT, transcript)
+}
+
+// generateProof computes the Schnorr response for gamma
+func (w *rangeWitness) generateProof(challenge curves.Scalar) *RangeProof {
+	w.proof.blinding = w.gamma.MulAdd(challenge, w.gammaTilde)
+	return w.proof
+}
+
+func addRangeToTranscript(commitment, capT curves.Point, transcript *merlin.Transcript) {
+	transcript.AppendMessage([]byte("range commitment"), commitment.ToAffineCompressed())
+	transcript.AppendMessage([]byte("range proof"), capT.ToAffineCompressed())
+}
+
+// verifyRanges checks the bulletproofs of the range predicates and adds the commitments to the
+// presentation transcript, where the Schnorr commitments are recomputed from the signature proof responses
+func (p Presentation) verifyRanges(request *common.PresentationRequest, transcript *merlin.Transcript) error {
+	if len(p.Ranges) != len(request.Ranges) {
+		return fmt.Errorf("range proofs do not match the request")
+	}
+	if len(p.Ranges) == 0 {
+		return nil
+	}
+	generators, err := rangeGenerators()
+	if err != nil {
+		return err
+	}
+	verifier, err := bulletproof.NewRangeVerifier(rangeBits, rangeVectorDomain, rangeIppDomain, *rangeCurve())
+	if err != nil {
+		return err
+	}
+	zero := rangeCurve().Scalar.Zero()
+	for i, r := range request.Ranges {
+		rp := p.Ranges[i]
+		if rp == nil || rp.Commitment == nil || rp.blinding == nil || rp.lower == nil || rp.upper == nil {
+			return fmt.Errorf("range proof %d is incomplete", i)
+		}
+		lower, upper, err := rangeBounds(r)
+		if err != nil {
+			return err
+		}
+		// g * (m - lower) + h * gamma and g * (upper - m) - h * gamma
+		bpTranscript := rangeTranscript(request, i)
+		capV := rp.Commitment.Sub(generators.Commit(lower, zero))
+		if ok, err := verifier.Verify(rp.lower, capV, *generators, rangeBits, bpTranscript); !ok || err != nil {
+			return fmt.Errorf("invalid lower bound proof for range %d", i)
+		}
+		capV = generators.Commit(upper, zero).Sub(rp.Commitment)
+		if ok, err := verifier.Verify(rp.upper, capV, *generators, rangeBits, bpTranscript); !ok || err != nil {
+			return fmt.Errorf("invalid upper bound proof for range %d", i)
+		}
+
+		// g * m^ + h * gamma^ - C * c
+		ref := r.Attribute
+		mHat := p.Proofs[ref.Credential].hiddenResponse(ref.Attribute, p.Revealed[ref.Credential])
+		if mHat == nil {
+			return fmt.Errorf("range %d references a missing response", i)
+		}
+		capT := generators.Commit(mHat, rp.blinding).Sub(rp.Commitment.Mul(p.Challenge))
+		addRangeToTranscript(rp.Commitment, capT, transcript)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the range proof as
+// commitment || blinding response || len || lower proof || len || upper proof
+// where the lengths are big-endian uint32 values
+func (rp RangeProof) MarshalBinary() ([]byte, error) {
+	if rp.Commitment == nil || rp.blinding == nil || rp.lower == nil || rp.upper == nil {
+		return nil, fmt.Errorf("invalid range proof")
+	}
+	out := append(rp.Commitment.ToAffineCompressed(), rp.blinding.Bytes()...)
+	for _, proof := range []*bulletproof.RangeProof{rp.lower, rp.upper} {
+		data := proof.MarshalBinary()
+		out = appendUint32(out, len(data))
+		out = append(out, data...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes the output of MarshalBinary
+func (rp *RangeProof) UnmarshalBinary(in []byte) error {
+	curve := rangeCurve()
+	ptSize := len(curve.Point.ToAffineCompressed())
+	scSize := len(curve.Scalar.Bytes())
+	if len(in) < ptSize+scSize {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	commitment, err := curve.Point.FromAffineCompressed(in[:ptSize])
+	if err != nil {
+		return err
+	}
+	blinding, err := curve.Scalar.SetBytes(in[ptSize : ptSize+scSize])
+	if err != nil {
+		return err
+	}
+	in = in[ptSize+scSize:]
+	proofs := make([]*bulletproof.RangeProof, 2)
+	for i := range proofs {
+		var l int
+		if l, in, err = readUint32(in); err != nil {
+			return err
+		}
+		if l > len(in) {
+			return fmt.Errorf("invalid byte sequence")
+		}
+		proofs[i] = bulletproof.NewRangeProof(curve)
+		if err = proofs[i].UnmarshalBinary(in[:l]); err != nil {
+			return err
+		}
+		in = in[l:]
+	}
+	if len(in) != 0 {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	rp.Commitment = commitment
+	rp.blinding = blinding
+	rp.lower, rp.upper = proofs[0], proofs[1]
+	return nil
+}
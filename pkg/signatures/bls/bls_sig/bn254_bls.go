@@ -0,0 +1,259 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bls_sig
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// Domain separation tag for basic signatures on BN254, which hash to G1 with the SVDW map
+const blsSignatureBn254Dst = "BLS_SIG_BN254G1_XMD:SHA-256_SVDW_RO_NUL_"
+
+const (
+	// Size of a compressed public key in G2 on BN254
+	PublicKeyBn254Size = 64
+	// Size of a compressed signature in G1 on BN254
+	SignatureBn254Size = 32
+	// Size of the input of the EVM pairing precompile for one signature
+	PrecompileInputSize = 384
+)
+
+// SecretKeyBn254 is a value mod r, the order of the groups of BN254
+type SecretKeyBn254 struct {
+	value curves.Scalar
+}
+
+// PublicKeyBn254 is a public key in G2 on BN254
+type PublicKeyBn254 struct {
+	value curves.Point
+}
+
+// SignatureBn254 is a signature in G1 on BN254
+type SignatureBn254 struct {
+	value curves.Point
+}
+
+// Creates a new secret key on BN254 from input key material (ikm) of at least 32 bytes,
+// with the KeyGen procedure of section 2.3 in
+// https://tools.ietf.org/html/draft-irtf-cfrg-bls-signature-03
+func (sk SecretKeyBn254) Generate(ikm []byte) (*SecretKeyBn254, error) {
+	if len(ikm) < 32 {
+		return nil, fmt.Errorf("ikm is too short. Must be at least 32")
+	}
+	salt := sha256.Sum256([]byte(hkdfKeyGenSalt))
+	ikm = append(append([]byte{}, ikm...), 0)
+	okm := make([]byte, 48)
+	kdf := hkdf.New(sha256.New, ikm, salt[:], []byte{0, 48})
+	if _, err := kdf.Read(okm); err != nil {
+		return nil, err
+	}
+	value, err := curves.BN254G2().Scalar.SetBytesWide(okm)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsZero() {
+		return nil, fmt.Errorf("secret key cannot be zero")
+	}
+	return &SecretKeyBn254{value}, nil
+}
+
+// GetPublicKey returns the public key of the secret key
+func (sk SecretKeyBn254) GetPublicKey() (*PublicKeyBn254, error) {
+	if sk.value == nil || sk.value.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	return &PublicKeyBn254{curves.BN254G2().ScalarBaseMult(sk.value)}, nil
+}
+
+// Serialize a secret key to 32 big-endian bytes
+func (sk SecretKeyBn254) MarshalBinary() ([]byte, error) {
+	if sk.value == nil {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	return sk.value.Bytes(), nil
+}
+
+// Deserialize a secret key from 32 big-endian bytes, which cannot be zero
+func (sk *SecretKeyBn254) UnmarshalBinary(data []byte) error {
+	if len(data) != SecretKeySize {
+		return fmt.Errorf("secret key must be %d bytes", SecretKeySize)
+	}
+	zeros := make([]byte, len(data))
+	if subtle.ConstantTimeCompare(data, zeros) == 1 {
+		return fmt.Errorf("secret key cannot be zero")
+	}
+	value, err := curves.BN254G2().Scalar.SetBytes(data)
+	if err != nil {
+		return err
+	}
+	sk.value = value
+	return nil
+}
+
+// Serialize a public key to its compressed form
+func (pk PublicKeyBn254) MarshalBinary() ([]byte, error) {
+	if pk.value == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	return pk.value.ToAffineCompressed(), nil
+}
+
+// Deserialize a public key from its compressed form. It cannot be the identity
+func (pk *PublicKeyBn254) UnmarshalBinary(data []byte) error {
+	if len(data) != PublicKeyBn254Size {
+		return fmt.Errorf("public key must be %d bytes", PublicKeyBn254Size)
+	}
+	p, err := curves.BN254G2().Point.FromAffineCompressed(data)
+	if err != nil {
+		return err
+	}
+	if p.IsIdentity() || !p.IsOnCurve() || !p.IsInPrimeSubgroup() {
+		return fmt.Errorf("invalid public key")
+	}
+	pk.value = p
+	return nil
+}
+
+// Serialize a signature to its compressed form
+func (sig SignatureBn254) MarshalBinary() ([]byte, error) {
+	if sig.value == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	return sig.value.ToAffineCompressed(), nil
+}
+
+// Deserialize a signature from its compressed form. It cannot be the identity
+func (sig *SignatureBn254) UnmarshalBinary(data []byte) error {
+	if len(data) != SignatureBn254Size {
+		return fmt.Errorf("signature must be %d bytes", SignatureBn254Size)
+	}
+	p, err := curves.BN254G1().Point.FromAffineCompressed(data)
+	if err != nil {
+		return err
+	}
+	if p.IsIdentity() || !p.IsOnCurve() || !p.IsInPrimeSubgroup() {
+		return fmt.Errorf("invalid signature")
+	}
+	sig.value = p
+	return nil
+}
+
+// SigBn254 is the basic BLS scheme on BN254 with signatures in G1 and public keys in G2, so signatures can be
+// verified by contracts with the pairing precompile of the EVM (EIP-197). See PrecompileInput.
+// Keys and signatures on BN254 are not interchangeable with those of the BLS12-381 schemes,
+// and BN254 provides about 100 bits of security instead of 128.
+type SigBn254 struct {
+	dst string
+}
+
+// Creates a new BLS basic signature scheme on BN254 with the default domain separation tag
+func NewSigBn254() *SigBn254 {
+	return &SigBn254{dst: blsSignatureBn254Dst}
+}
+
+// Creates a new BLS basic signature scheme on BN254 with a custom domain separation tag
+func NewSigBn254WithDst(signDst string) *SigBn254 {
+	return &SigBn254{dst: signDst}
+}
+
+// Dst returns the domain separation tag of the signatures
+func (b SigBn254) Dst() string {
+	return b.dst
+}
+
+// Creates a new BLS key pair
+func (b SigBn254) Keygen() (*PublicKeyBn254, *SecretKeyBn254, error) {
+	ikm, err := generateRandBytes(32)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.KeygenWithSeed(ikm)
+}
+
+// Creates a new BLS key pair from a seed, which must be at least 32 bytes
+func (b SigBn254) KeygenWithSeed(ikm []byte) (*PublicKeyBn254, *SecretKeyBn254, error) {
+	sk, err := new(SecretKeyBn254).Generate(ikm)
+	if err != nil {
+		return nil, nil, err
+	}
+	pk, err := sk.GetPublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return pk, sk, nil
+}
+
+// HashToPoint returns the hash of the message in G1, which a contract verifying a signature
+// must compute itself or obtain from a trusted source
+func (b SigBn254) HashToPoint(msg []byte) (curves.Point, error) {
+	return curves.HashToCurve(curves.BN254G1(), msg, []byte(b.dst))
+}
+
+// Computes a signature in G1 from a secret key and message
+func (b SigBn254) Sign(sk *SecretKeyBn254, msg []byte) (*SignatureBn254, error) {
+	if sk == nil || sk.value == nil || sk.value.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	h, err := b.HashToPoint(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureBn254{h.Mul(sk.value)}, nil
+}
+
+// Checks that a signature is valid for the message under the public key pk,
+// i.e. e(sig, -g2) * e(H(msg), pk) == 1
+func (b SigBn254) Verify(pk *PublicKeyBn254, msg []byte, sig *SignatureBn254) bool {
+	pairs, err := b.pairs(pk, msg, sig)
+	if err != nil {
+		return false
+	}
+	gt, ok := pairs[0].(curves.PairingPoint).MultiPairing(pairs...).(*curves.ScalarBn254Gt)
+	return ok && gt.IsOne()
+}
+
+// PrecompileInput returns the input of the EVM pairing precompile at address 0x08 which verifies the signature,
+// i.e. the uncompressed points sig || -g2 || H(msg) || pk in the encoding of EIP-197.
+// The precompile returns 1 if the signature is valid.
+func (b SigBn254) PrecompileInput(pk *PublicKeyBn254, msg []byte, sig *SignatureBn254) ([]byte, error) {
+	pairs, err := b.pairs(pk, msg, sig)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, PrecompileInputSize)
+	for _, p := range pairs {
+		out = append(out, p.ToAffineUncompressed()...)
+	}
+	return out, nil
+}
+
+// pairs returns the points of the verification equation e(sig, -g2) * e(H(msg), pk) == 1
+func (b SigBn254) pairs(pk *PublicKeyBn254, msg []byte, sig *SignatureBn254) ([]curves.PairingPoint, error) {
+	if pk == nil || pk.value == nil || pk.value.IsIdentity() {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	if sig == nil || sig.value == nil || sig.value.IsIdentity() {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	h, err := b.HashToPoint(msg)
+	if err != nil {
+		return nil, err
+	}
+	g2 := curves.BN254G2().NewGeneratorPoint().Neg()
+	return []curves.PairingPoint{
+		sig.value.(curves.PairingPoint),
+		g2.(curves.PairingPoint),
+		h.(curves.PairingPoint),
+		pk.value.(curves.PairingPoint),
+	}, nil
+}
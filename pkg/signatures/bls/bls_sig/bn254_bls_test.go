@@ -0,0 +1,177 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bls_sig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestBn254SignVerify(t *testing.T) {
+	bls := NewSigBn254()
+	pk, sk, err := bls.Keygen()
+	if err != nil {
+		t.Fatalf("Keygen failed: %v", err)
+	}
+	msg := []byte("pay 1 ether to 0xabcd")
+	sig, err := bls.Sign(sk, msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !bls.Verify(pk, msg, sig) {
+		t.Errorf("Verify failed")
+	}
+	if bls.Verify(pk, []byte("pay 2 ether to 0xabcd"), sig) {
+		t.Errorf("Verify succeeded for another message")
+	}
+	otherPk, _, err := bls.Keygen()
+	if err != nil {
+		t.Fatalf("Keygen failed: %v", err)
+	}
+	if bls.Verify(otherPk, msg, sig) {
+		t.Errorf("Verify succeeded for another public key")
+	}
+	if NewSigBn254WithDst("BLS_SIG_OTHER_APP_").Verify(pk, msg, sig) {
+		t.Errorf("Verify succeeded for another domain separation tag")
+	}
+	if bls.Verify(pk, msg, &SignatureBn254{curves.BN254G1().Point.Identity()}) {
+		t.Errorf("Verify succeeded for the identity")
+	}
+}
+
+func TestBn254KeygenWithSeed(t *testing.T) {
+	bls := NewSigBn254()
+	ikm := make([]byte, 32)
+	readRand(ikm, t)
+	pk1, sk1, err := bls.KeygenWithSeed(ikm)
+	if err != nil {
+		t.Fatalf("KeygenWithSeed failed: %v", err)
+	}
+	pk2, sk2, err := bls.KeygenWithSeed(ikm)
+	if err != nil {
+		t.Fatalf("KeygenWithSeed failed: %v", err)
+	}
+	if !pk1.value.Equal(pk2.value) || sk1.value.Cmp(sk2.value) != 0 {
+		t.Errorf("KeygenWithSeed is not deterministic")
+	}
+	if _, _, err = bls.KeygenWithSeed(ikm[:31]); err == nil {
+		t.Errorf("KeygenWithSeed accepted a short seed")
+	}
+}
+
+func TestBn254Marshal(t *testing.T) {
+	bls := NewSigBn254()
+	pk, sk, err := bls.Keygen()
+	if err != nil {
+		t.Fatalf("Keygen failed: %v", err)
+	}
+	msg := []byte("marshal")
+	sig, err := bls.Sign(sk, msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	skBytes, _ := sk.MarshalBinary()
+	pkBytes, _ := pk.MarshalBinary()
+	sigBytes, _ := sig.MarshalBinary()
+	if len(skBytes) != SecretKeySize || len(pkBytes) != PublicKeyBn254Size || len(sigBytes) != SignatureBn254Size {
+		t.Fatalf("unexpected sizes %d %d %d", len(skBytes), len(pkBytes), len(sigBytes))
+	}
+	sk2 := new(SecretKeyBn254)
+	pk2 := new(PublicKeyBn254)
+	sig2 := new(SignatureBn254)
+	if err = sk2.UnmarshalBinary(skBytes); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if err = pk2.UnmarshalBinary(pkBytes); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if err = sig2.UnmarshalBinary(sigBytes); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if sk2.value.Cmp(sk.value) != 0 {
+		t.Errorf("secret key does not round trip")
+	}
+	if !bls.Verify(pk2, msg, sig2) {
+		t.Errorf("Verify failed after round trip")
+	}
+
+	if err = sk2.UnmarshalBinary(make([]byte, SecretKeySize)); err == nil {
+		t.Errorf("UnmarshalBinary accepted a zero secret key")
+	}
+	if err = sig2.UnmarshalBinary(curves.BN254G1().Point.Identity().ToAffineCompressed()); err == nil {
+		t.Errorf("UnmarshalBinary accepted the identity")
+	}
+	if err = pk2.UnmarshalBinary(pkBytes[1:]); err == nil {
+		t.Errorf("UnmarshalBinary accepted a short public key")
+	}
+}
+
+func TestBn254PrecompileInput(t *testing.T) {
+	bls := NewSigBn254()
+	pk, sk, err := bls.Keygen()
+	if err != nil {
+		t.Fatalf("Keygen failed: %v", err)
+	}
+	msg := []byte("on-chain")
+	sig, err := bls.Sign(sk, msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	input, err := bls.PrecompileInput(pk, msg, sig)
+	if err != nil {
+		t.Fatalf("PrecompileInput failed: %v", err)
+	}
+	if len(input) != PrecompileInputSize {
+		t.Fatalf("input is %d bytes", len(input))
+	}
+
+	// Decode the input as the precompile does, i.e. 64 bytes per G1 point and 128 bytes per G2 point
+	g1 := curves.BN254G1().Point
+	g2 := curves.BN254G2().Point
+	var points []curves.PairingPoint
+	for i, size := range []int{64, 128, 64, 128} {
+		var p curves.Point
+		if i%2 == 0 {
+			p, err = g1.FromAffineUncompressed(input[:size])
+		} else {
+			p, err = g2.FromAffineUncompressed(input[:size])
+		}
+		if err != nil {
+			t.Fatalf("point %d does not decode: %v", i, err)
+		}
+		points = append(points, p.(curves.PairingPoint))
+		input = input[size:]
+	}
+	if !bytes.Equal(points[0].ToAffineUncompressed(), sig.value.ToAffineUncompressed()) {
+		t.Errorf("first point is not the signature")
+	}
+	if !points[1].Equal(curves.BN254G2().NewGeneratorPoint().Neg()) {
+		t.Errorf("second point is not the negated generator")
+	}
+	if !points[3].Equal(pk.value) {
+		t.Errorf("fourth point is not the public key")
+	}
+	gt := points[0].MultiPairing(points...).(*curves.ScalarBn254Gt)
+	if !gt.IsOne() {
+		t.Errorf("pairing check of the input failed")
+	}
+
+	// The first word of a G2 point is the imaginary part of x, as the precompile expects
+	gen := curves.BN254G2().NewGeneratorPoint().ToAffineUncompressed()
+	xIm := "198e9393920d483a7260bfb731fb5d25f1aa493335a9e71297e485b7aef312c2"
+	if x := hex.EncodeToString(gen[:32]); x != xIm {
+		t.Errorf("unexpected G2 encoding %s", x)
+	}
+
+	if _, err = bls.PrecompileInput(nil, msg, sig); err == nil {
+		t.Errorf("PrecompileInput accepted a nil public key")
+	}
+}
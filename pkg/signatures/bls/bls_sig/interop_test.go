@@ -0,0 +1,248 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bls_sig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Compatibility tests with blst and py_ecc. The signatures are the BLS tests of the Ethereum consensus specs,
+// which are generated with py_ecc and checked by clients built on blst, for the proof of possession
+// ciphersuite BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_.
+
+const (
+	// Compressed generators, in the encoding of the zcash BLS12-381 crate which blst and py_ecc follow
+	interopG1Generator = "97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"
+	interopG2Generator = "93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e" +
+		"024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"
+	// The modulus of the base field
+	interopFieldModulus = "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab"
+)
+
+var interopKeys = []struct {
+	sk, pk string
+}{
+	{
+		"263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+		"a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+	},
+	{
+		"47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+		"b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+	},
+	{
+		"328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+		"b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+	},
+}
+
+var interopMessages = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"5656565656565656565656565656565656565656565656565656565656565656",
+	"abababababababababababababababababababababababababababababababab",
+}
+
+// interopSignatures[i][j] is the signature of interopMessages[j] with interopKeys[i]
+var interopSignatures = [][]string{
+	{
+		"b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb515809" +
+			"0352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55",
+		"882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c2" +
+			"0767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb",
+		"91347bccf740d859038fcdcaf233eeceb2a436bcaaee9b2aa3bfb70efe29dfb2677562ccbea1c8e061fb9971b0753c24" +
+			"0622fab78489ce96768259fc01360346da5b9f579e5da0d941e4c6ba18a0e64906082375394f337fa1af2b7127b0d121",
+	},
+	{
+		"b23c46be3a001c63ca711f87a005c200cc550b9429d5f4eb38d74322144f1b63926da3388979e5321012fb1a0526bcd1" +
+			"00b5ef5fe72628ce4cd5e904aeaa3279527843fae5ca9ca675f4f51ed8f83bbf7155da9ecc9663100a885d5dc6df96d9",
+		"af1390c3c47acdb37131a51216da683c509fce0e954328a59f93aebda7e4ff974ba208d9a4a2a2389f892a9d418d6184" +
+			"18dd7f7a6bc7aa0da999a9d3a5b815bc085e14fd001f6a1948768a3f4afefc8b8240dda329f984cb345c6363272ba4fe",
+		"9674e2228034527f4c083206032b020310face156d4a4685e2fcaec2f6f3665aa635d90347b6ce124eb879266b1e801d" +
+			"185de36a0a289b85e9039662634f2eea1e02e670bc7ab849d006a70b2f93b84597558a05b879c8d445f387a5d5b653df",
+	},
+	{
+		"948a7cb99f76d616c2c564ce9bf4a519f1bea6b0a624a02276443c245854219fabb8d4ce061d255af5330b078d538068" +
+			"1751aa7053da2c98bae898edc218c75f07e24d8802a17cd1f6833b71e58f5eb5b94208b4d0bb3848cecb075ea21be115",
+		"a4efa926610b8bd1c8330c918b7a5e9bf374e53435ef8b7ec186abf62e1b1f65aeaaeb365677ac1d1172a1f5b44b4e6d" +
+			"022c252c58486c0a759fbdc7de15a756acc4d343064035667a594b4c2a6f0b0b421975977f297dba63ee2f63ffe47bb6",
+		"ae82747ddeefe4fd64cf9cedb9b04ae3e8a43420cd255e3c7cd06a8d88b7c7f8638543719981c5d16fa3527c468c25f0" +
+			"026704a6951bde891360c7e8d12ddee0559004ccdbe6046b55bae1b257ee97f7cdb955773d7cf29adf3ccbb9975e4eb9",
+	},
+}
+
+// The aggregate of the signatures of the last message
+const interopAggregate = "9712c3edd73a209c742b8250759db12549b3eaf43b5ca61376d9f30e2747dbcf842d8b2ac0901d2a093713e20284a767" +
+	"0fcf6954e9ab93de991bb9b313e664785a075fc285806fa5224c82bde146561b446ccfc706a64b8579513cfc4ff1d930"
+
+func interopHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %s: %v", s, err)
+	}
+	return b
+}
+
+func TestInteropSignatures(t *testing.T) {
+	bls := NewSigPop()
+	for i, key := range interopKeys {
+		sk := new(SecretKey)
+		if err := sk.UnmarshalBinary(interopHex(t, key.sk)); err != nil {
+			t.Fatalf("secret key %d: %v", i, err)
+		}
+		skBytes, _ := sk.MarshalBinary()
+		if hex.EncodeToString(skBytes) != key.sk {
+			t.Errorf("secret key %d does not round trip", i)
+		}
+		pk, err := sk.GetPublicKey()
+		if err != nil {
+			t.Fatalf("public key %d: %v", i, err)
+		}
+		pkBytes, _ := pk.MarshalBinary()
+		if hex.EncodeToString(pkBytes) != key.pk {
+			t.Errorf("public key %d is %x, expected %s", i, pkBytes, key.pk)
+		}
+
+		for j, m := range interopMessages {
+			msg := interopHex(t, m)
+			sig, err := bls.Sign(sk, msg)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			sigBytes, _ := sig.MarshalBinary()
+			if hex.EncodeToString(sigBytes) != interopSignatures[i][j] {
+				t.Errorf("signature %d of message %d is %x, expected %s", i, j, sigBytes, interopSignatures[i][j])
+			}
+
+			expected := new(Signature)
+			if err = expected.UnmarshalBinary(interopHex(t, interopSignatures[i][j])); err != nil {
+				t.Fatalf("signature %d of message %d: %v", i, j, err)
+			}
+			if ok, err := bls.Verify(pk, msg, expected); !ok || err != nil {
+				t.Errorf("signature %d of message %d does not verify", i, j)
+			}
+		}
+	}
+}
+
+func TestInteropAggregate(t *testing.T) {
+	bls := NewSigPop()
+	pks := make([]*PublicKey, len(interopKeys))
+	sigs := make([]*Signature, len(interopKeys))
+	for i, key := range interopKeys {
+		pks[i] = new(PublicKey)
+		if err := pks[i].UnmarshalBinary(interopHex(t, key.pk)); err != nil {
+			t.Fatalf("public key %d: %v", i, err)
+		}
+		sigs[i] = new(Signature)
+		if err := sigs[i].UnmarshalBinary(interopHex(t, interopSignatures[i][2])); err != nil {
+			t.Fatalf("signature %d: %v", i, err)
+		}
+	}
+	asig, err := bls.AggregateSignatures(sigs...)
+	if err != nil {
+		t.Fatalf("AggregateSignatures failed: %v", err)
+	}
+	b, _ := asig.MarshalBinary()
+	if hex.EncodeToString(b) != interopAggregate {
+		t.Errorf("aggregate is %x, expected %s", b, interopAggregate)
+	}
+	sig := new(Signature)
+	if err = sig.UnmarshalBinary(b); err != nil {
+		t.Fatalf("aggregate does not deserialize: %v", err)
+	}
+	if ok, err := bls.FastAggregateVerify(pks, interopHex(t, interopMessages[2]), sig); !ok || err != nil {
+		t.Errorf("FastAggregateVerify failed")
+	}
+	if ok, _ := bls.FastAggregateVerify(pks, interopHex(t, interopMessages[1]), sig); ok {
+		t.Errorf("FastAggregateVerify succeeded for another message")
+	}
+}
+
+func TestInteropEncodings(t *testing.T) {
+	// The flags are c (compressed), b (infinity) and a (sign of y) in the three most significant bits
+	g1 := interopHex(t, interopG1Generator)
+	g2 := interopHex(t, interopG2Generator)
+	pk := new(PublicKey)
+	if err := pk.UnmarshalBinary(g1); err != nil {
+		t.Fatalf("G1 generator: %v", err)
+	}
+	b, _ := pk.MarshalBinary()
+	if !bytes.Equal(b, g1) {
+		t.Errorf("G1 generator is %x", b)
+	}
+	sig := new(Signature)
+	if err := sig.UnmarshalBinary(g2); err != nil {
+		t.Fatalf("G2 generator: %v", err)
+	}
+	b, _ = sig.MarshalBinary()
+	if !bytes.Equal(b, g2) {
+		t.Errorf("G2 generator is %x", b)
+	}
+	// The tiny variant swaps the groups but encodes them the same way
+	pkVt := new(PublicKeyVt)
+	if err := pkVt.UnmarshalBinary(g2); err != nil {
+		t.Fatalf("G2 generator: %v", err)
+	}
+	b, _ = pkVt.MarshalBinary()
+	if !bytes.Equal(b, g2) {
+		t.Errorf("G2 generator is %x", b)
+	}
+
+	// Negating the generators flips the a flag
+	negG2 := new(Signature)
+	negG2.Value.Neg(&sig.Value)
+	b, _ = negG2.MarshalBinary()
+	if b[0] != g2[0]|0x20 || !bytes.Equal(b[1:48], g2[1:48]) || !bytes.Equal(b[48:], g2[48:]) {
+		t.Errorf("negated G2 generator is %x", b)
+	}
+	sig2 := new(Signature)
+	if err := sig2.UnmarshalBinary(b); err != nil || sig2.Value.Equal(&negG2.Value) != 1 {
+		t.Errorf("negated G2 generator does not round trip")
+	}
+
+	g1Modulus := interopHex(t, interopFieldModulus)
+	g1Modulus[0] |= 0x80
+	g2C1Modulus := append(append([]byte{}, g1Modulus...), g2[48:]...)
+	g2C0Modulus := append(append([]byte{}, g2[:48]...), interopHex(t, interopFieldModulus)...)
+	// Encodings that blst and py_ecc reject
+	invalidG1 := map[string][]byte{
+		"infinity":                interopHex(t, "c0"+strings.Repeat("00", 47)),
+		"infinity with a flag":    interopHex(t, "e0"+strings.Repeat("00", 47)),
+		"infinity with nonzero x": interopHex(t, "c0"+strings.Repeat("00", 46)+"01"),
+		"uncompressed flag":       append([]byte{g1[0] &^ 0x80}, g1[1:]...),
+		"x equal to the modulus":  g1Modulus,
+		"not in the subgroup":     interopHex(t, "80"+strings.Repeat("00", 47)),
+		"too short":               g1[:47],
+	}
+	for name, data := range invalidG1 {
+		if err := new(PublicKey).UnmarshalBinary(data); err == nil {
+			t.Errorf("public key accepted: %s", name)
+		}
+		if err := new(SignatureVt).UnmarshalBinary(data); err == nil {
+			t.Errorf("tiny signature accepted: %s", name)
+		}
+	}
+	invalidG2 := map[string][]byte{
+		"infinity":                  interopHex(t, "c0"+strings.Repeat("00", 95)),
+		"infinity with a flag":      interopHex(t, "e0"+strings.Repeat("00", 95)),
+		"uncompressed flag":         append([]byte{g2[0] &^ 0x80}, g2[1:]...),
+		"imaginary x above modulus": g2C1Modulus,
+		"real x above modulus":      g2C0Modulus,
+		"too long":                  append(append([]byte{}, g2...), 0),
+	}
+	for name, data := range invalidG2 {
+		if err := new(Signature).UnmarshalBinary(data); err == nil {
+			t.Errorf("signature accepted: %s", name)
+		}
+		if err := new(PublicKeyVt).UnmarshalBinary(data); err == nil {
+			t.Errorf("tiny public key accepted: %s", name)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bls_sig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The key derivation functions of a keystore
+const (
+	KeystoreScrypt = "scrypt"
+	KeystorePbkdf2 = "pbkdf2"
+)
+
+// The parameters of the key derivation functions recommended by EIP-2335
+const (
+	keystoreScryptN  = 262144
+	keystoreScryptR  = 8
+	keystoreScryptP  = 1
+	keystorePbkdf2C  = 262144
+	keystoreDkLen    = 32
+	keystoreVersion  = 4
+	keystoreChecksum = "sha256"
+	keystoreCipher   = "aes-128-ctr"
+	keystorePrf      = "hmac-sha256"
+)
+
+// Keystore is a secret key encrypted with a password in the JSON format of EIP-2335
+// https://eips.ethereum.org/EIPS/eip-2335, which is used by Ethereum validator clients
+type Keystore struct {
+	Crypto      KeystoreCrypto `json:"crypto"`
+	Description string         `json:"description"`
+	Pubkey      string         `json:"pubkey"`
+	Path        string         `json:"path"`
+	UUID        string         `json:"uuid"`
+	Version     int            `json:"version"`
+}
+
+// KeystoreCrypto holds the modules that encrypt the secret key
+type KeystoreCrypto struct {
+	Kdf      KeystoreModule `json:"kdf"`
+	Checksum KeystoreModule `json:"checksum"`
+	Cipher   KeystoreModule `json:"cipher"`
+}
+
+// KeystoreModule is a function with its parameters and message
+type KeystoreModule struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+// NewKeystore encrypts `sk` with `password` using the key derivation function `kdf`, which is KeystoreScrypt
+// or KeystorePbkdf2, and the parameters recommended by EIP-2335. `path` is the EIP-2334 derivation path
+// of the key, or empty. The password is normalized to NFKD and its control codes are removed as in EIP-2335.
+func NewKeystore(sk *SecretKey, password, path, kdf string) (*Keystore, error) {
+	if sk == nil || sk.value == nil {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	secret, err := sk.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pk, err := sk.GetPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	pkBytes, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	random := make([]byte, 32+aes.BlockSize+16)
+	if _, err = io.ReadFull(rand.Reader, random); err != nil {
+		return nil, err
+	}
+	salt, iv, id := random[:32], random[32:32+aes.BlockSize], random[32+aes.BlockSize:]
+
+	var kdfModule KeystoreModule
+	switch kdf {
+	case KeystoreScrypt:
+		kdfModule = KeystoreModule{Function: KeystoreScrypt, Params: map[string]interface{}{
+			"dklen": keystoreDkLen,
+			"n":     keystoreScryptN,
+			"r":     keystoreScryptR,
+			"p":     keystoreScryptP,
+			"salt":  hex.EncodeToString(salt),
+		}}
+	case KeystorePbkdf2:
+		kdfModule = KeystoreModule{Function: KeystorePbkdf2, Params: map[string]interface{}{
+			"dklen": keystoreDkLen,
+			"c":     keystorePbkdf2C,
+			"prf":   keystorePrf,
+			"salt":  hex.EncodeToString(salt),
+		}}
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function %s", kdf)
+	}
+	dk, err := kdfModule.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := keystoreXor(dk, iv, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Version 4 UUID
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return &Keystore{
+		Crypto: KeystoreCrypto{
+			Kdf: kdfModule,
+			Checksum: KeystoreModule{
+				Function: keystoreChecksum,
+				Params:   map[string]interface{}{},
+				Message:  hex.EncodeToString(keystoreMac(dk, ciphertext)),
+			},
+			Cipher: KeystoreModule{
+				Function: keystoreCipher,
+				Params:   map[string]interface{}{"iv": hex.EncodeToString(iv)},
+				Message:  hex.EncodeToString(ciphertext),
+			},
+		},
+		Pubkey:  hex.EncodeToString(pkBytes),
+		Path:    path,
+		UUID:    fmt.Sprintf("%x-%x-%x-%x-%x", id[:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: keystoreVersion,
+	}, nil
+}
+
+// UnmarshalKeystore parses a keystore in the JSON format of EIP-2335
+func UnmarshalKeystore(data []byte) (*Keystore, error) {
+	ks := new(Keystore)
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, err
+	}
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+	return ks, nil
+}
+
+// Decrypt returns the secret key after checking `password` with the checksum, and that the key
+// matches the public key of the keystore if there is one. Like in NewKeystore, the password is
+// normalized to NFKD.
+func (ks Keystore) Decrypt(password string) (*SecretKey, error) {
+	if ks.Crypto.Checksum.Function != keystoreChecksum {
+		return nil, fmt.Errorf("unsupported checksum function %s", ks.Crypto.Checksum.Function)
+	}
+	if ks.Crypto.Cipher.Function != keystoreCipher {
+		return nil, fmt.Errorf("unsupported cipher function %s", ks.Crypto.Cipher.Function)
+	}
+	checksum, err := hex.DecodeString(ks.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum: %v", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher message: %v", err)
+	}
+	iv, err := ks.Crypto.Cipher.hexParam("iv")
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid iv length")
+	}
+
+	dk, err := ks.Crypto.Kdf.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(keystoreMac(dk, ciphertext), checksum) != 1 {
+		return nil, fmt.Errorf("invalid password")
+	}
+	secret, err := keystoreXor(dk, iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	sk := new(SecretKey)
+	if err = sk.UnmarshalBinary(secret); err != nil {
+		return nil, err
+	}
+
+	if ks.Pubkey != "" {
+		pk, err := sk.GetPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		pkBytes, err := pk.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if hex.EncodeToString(pkBytes) != ks.Pubkey {
+			return nil, fmt.Errorf("secret key does not match the public key")
+		}
+	}
+	return sk, nil
+}
+
+// deriveKey runs the key derivation function on the password with the control codes removed
+func (m KeystoreModule) deriveKey(password string) ([]byte, error) {
+	pwd := keystorePassword(password)
+	salt, err := m.hexParam("salt")
+	if err != nil {
+		return nil, err
+	}
+	dkLen, err := m.intParam("dklen")
+	if err != nil {
+		return nil, err
+	}
+	if dkLen < 32 {
+		return nil, fmt.Errorf("invalid dklen %d", dkLen)
+	}
+	switch m.Function {
+	case KeystoreScrypt:
+		n, err := m.intParam("n")
+		if err != nil {
+			return nil, err
+		}
+		r, err := m.intParam("r")
+		if err != nil {
+			return nil, err
+		}
+		p, err := m.intParam("p")
+		if err != nil {
+			return nil, err
+		}
+		return scrypt.Key(pwd, salt, n, r, p, dkLen)
+	case KeystorePbkdf2:
+		if prf, ok := m.Params["prf"].(string); !ok || prf != keystorePrf {
+			return nil, fmt.Errorf("unsupported prf")
+		}
+		c, err := m.intParam("c")
+		if err != nil {
+			return nil, err
+		}
+		if c < 1 {
+			return nil, fmt.Errorf("invalid iteration count %d", c)
+		}
+		return pbkdf2.Key(pwd, salt, c, dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function %s", m.Function)
+	}
+}
+
+// hexParam returns a parameter encoded in hex
+func (m KeystoreModule) hexParam(name string) ([]byte, error) {
+	s, ok := m.Params[name].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing %s parameter", name)
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %v", name, err)
+	}
+	return b, nil
+}
+
+// intParam returns a positive integer parameter, which is a float64 when parsed from JSON
+func (m KeystoreModule) intParam(name string) (int, error) {
+	switch v := m.Params[name].(type) {
+	case int:
+		if v > 0 {
+			return v, nil
+		}
+	case float64:
+		if v > 0 && v <= 1<<31 && v == float64(int(v)) {
+			return int(v), nil
+		}
+	default:
+		return 0, fmt.Errorf("missing %s parameter", name)
+	}
+	return 0, fmt.Errorf("invalid %s parameter", name)
+}
+
+// keystorePassword normalizes the password to NFKD and removes the C0, C1 and Delete control codes
+func keystorePassword(password string) []byte {
+	var pwd []byte
+	for _, r := range norm.NFKD.String(password) {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			continue
+		}
+		pwd = append(pwd, string(r)...)
+	}
+	return pwd
+}
+
+// keystoreMac is SHA256(dk[16:32] || ciphertext)
+func keystoreMac(dk, ciphertext []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write(dk[16:32])
+	_, _ = h.Write(ciphertext)
+	return h.Sum(nil)
+}
+
+// keystoreXor encrypts or decrypts with AES-128-CTR keyed by dk[:16]
+func keystoreXor(dk, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(dk[:16])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
@@ -0,0 +1,156 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bls_sig
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The test vectors of EIP-2335, whose password "𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑" is "testpassword🔑" after NFKD
+const (
+	keystoreTestPassword = "testpassword🔑"
+	keystoreTestSecret   = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	keystoreTestScrypt   = `{
+    "crypto": {
+        "kdf": {
+            "function": "scrypt",
+            "params": {
+                "dklen": 32,
+                "n": 262144,
+                "p": 1,
+                "r": 8,
+                "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+            },
+            "message": ""
+        },
+        "checksum": {
+            "function": "sha256",
+            "params": {},
+            "message": "d2217fe5f3e9a1e34581ef8a78f7c9928e436d36dacc5e846690a5581e8ea484"
+        },
+        "cipher": {
+            "function": "aes-128-ctr",
+            "params": {
+                "iv": "264daa3f303d7259501c93d997d84fe6"
+            },
+            "message": "06ae90d55fe0a6e9c5c3bc5b170827b2e5cce3929ed3f116c2811e6366dfe20f"
+        }
+    },
+    "description": "This is a test keystore that uses scrypt to secure the secret.",
+    "pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+    "path": "m/12381/60/3141592653/589793238",
+    "uuid": "1d85ae20-35c5-4611-98e8-aa14a633906f",
+    "version": 4
+}`
+	keystoreTestPbkdf2 = `{
+    "crypto": {
+        "kdf": {
+            "function": "pbkdf2",
+            "params": {
+                "dklen": 32,
+                "c": 262144,
+                "prf": "hmac-sha256",
+                "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+            },
+            "message": ""
+        },
+        "checksum": {
+            "function": "sha256",
+            "params": {},
+            "message": "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1"
+        },
+        "cipher": {
+            "function": "aes-128-ctr",
+            "params": {
+                "iv": "264daa3f303d7259501c93d997d84fe6"
+            },
+            "message": "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad"
+        }
+    },
+    "description": "This is a test keystore that uses PBKDF2 to secure the secret.",
+    "pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+    "path": "m/12381/60/0/0",
+    "uuid": "64625def-3331-4eea-ab6f-782f3ed16a83",
+    "version": 4
+}`
+)
+
+func TestKeystoreVectors(t *testing.T) {
+	vectors := []string{keystoreTestPbkdf2}
+	if !testing.Short() {
+		vectors = append(vectors, keystoreTestScrypt)
+	}
+	for _, v := range vectors {
+		ks, err := UnmarshalKeystore([]byte(v))
+		require.NoError(t, err)
+		sk, err := ks.Decrypt(keystoreTestPassword)
+		require.NoError(t, err)
+		secret, err := sk.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, keystoreTestSecret, hex.EncodeToString(secret))
+
+		// control codes are removed from the password
+		_, err = ks.Decrypt("test\x7fpassword\u0085🔑\n")
+		require.NoError(t, err)
+		// the password is normalized to NFKD
+		_, err = ks.Decrypt("𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑")
+		require.NoError(t, err)
+		_, err = ks.Decrypt("testpassword")
+		require.Error(t, err)
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	kdfs := []string{KeystorePbkdf2}
+	if !testing.Short() {
+		kdfs = append(kdfs, KeystoreScrypt)
+	}
+	_, sk, err := NewSigPop().Keygen()
+	require.NoError(t, err)
+	for _, kdf := range kdfs {
+		ks, err := NewKeystore(sk, "password", "m/12381/3600/0/0/0", kdf)
+		require.NoError(t, err)
+		require.Equal(t, byte('4'), ks.UUID[14])
+
+		data, err := json.Marshal(ks)
+		require.NoError(t, err)
+		ks, err = UnmarshalKeystore(data)
+		require.NoError(t, err)
+		actual, err := ks.Decrypt("password")
+		require.NoError(t, err)
+		require.Equal(t, sk.value.Bytes(), actual.value.Bytes())
+	}
+}
+
+func TestKeystoreInvalid(t *testing.T) {
+	_, sk, err := NewSigPop().Keygen()
+	require.NoError(t, err)
+	_, err = NewKeystore(sk, "password", "", "argon2")
+	require.Error(t, err)
+	_, err = NewKeystore(nil, "password", "", KeystorePbkdf2)
+	require.Error(t, err)
+
+	ks, err := NewKeystore(sk, "password", "", KeystorePbkdf2)
+	require.NoError(t, err)
+	// the keystore holds another public key
+	other := *ks
+	other.Pubkey = "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07"
+	_, err = other.Decrypt("password")
+	require.Error(t, err)
+	// the ciphertext was modified
+	other = *ks
+	other.Crypto.Cipher.Message = "00" + ks.Crypto.Cipher.Message[2:]
+	_, err = other.Decrypt("password")
+	require.Error(t, err)
+
+	_, err = UnmarshalKeystore([]byte(`{"version": 3}`))
+	require.Error(t, err)
+}
@@ -12,6 +12,7 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"fmt"
+	"strings"
 
 	"golang.org/x/crypto/hkdf"
 
@@ -206,3 +207,41 @@ func thresholdizeSecretKey(secretKey *SecretKey, threshold, total uint) ([]*Secr
 
 	return secrets, nil
 }
+
+// scalar returns the value of the share, which is stored in big-endian
+func (sks SecretKeyShare) scalar() (*native.Field, error) {
+	var blob [SecretKeySize]byte
+	copy(blob[:], internal.ReverseScalarBytes(sks.value))
+	return bls12381.Bls12381FqNew().SetBytes(&blob)
+}
+
+// checkDst checks that `dst` can be used to hash to the curve. If it has the format of the ciphersuite IDs
+// of the standard, i.e. `prefix` ("BLS_SIG_" or "BLS_POP_") || hash to curve suite || scheme tag || "_",
+// it must hash to `group` and name `scheme`, so that signatures are not verified with the tag of another suite.
+// Tags in other formats, like those of some blockchains, are allowed.
+// See section 4.1 from
+// https://tools.ietf.org/html/draft-irtf-cfrg-bls-signature-03
+func checkDst(dst, prefix, group, scheme string) error {
+	if len(dst) == 0 || len(dst) > 255 {
+		return fmt.Errorf("domain separation tag must be between 1 and 255 bytes")
+	}
+	if !strings.HasPrefix(dst, "BLS_SIG_") && !strings.HasPrefix(dst, "BLS_POP_") {
+		return nil
+	}
+	if !strings.HasPrefix(dst, prefix) {
+		return fmt.Errorf("domain separation tag %q does not start with %s", dst, prefix)
+	}
+	suite := dst[len(prefix):]
+	if !strings.HasPrefix(suite, "BLS12381"+group+"_") {
+		return fmt.Errorf("domain separation tag %q does not hash to BLS12381%s", dst, group)
+	}
+	for _, encoding := range []string{"_RO_", "_NU_"} {
+		if i := strings.Index(suite, encoding); i >= 0 {
+			if !strings.HasPrefix(suite[i+len(encoding):], scheme+"_") {
+				return fmt.Errorf("domain separation tag %q is not for the %s scheme", dst, scheme)
+			}
+			return nil
+		}
+	}
+	return nil
+}
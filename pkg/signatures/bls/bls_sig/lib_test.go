@@ -283,3 +283,47 @@ func TestSecretKeyShareUnmarshalBinary(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckDst(t *testing.T) {
+	valid := []interface{ CheckDst() error }{
+		NewSigBasic(), NewSigAug(), NewSigPop(), NewSigBasicVt(), NewSigAugVt(), NewSigPopVt(),
+		NewSigBasicWithDst("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_TEST"),
+		NewSigAugVtWithDst("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_AUG_"),
+		// a tag outside the format of the standard
+		NewSigBasicWithDst("MY_CHAIN_SIGNATURE_V1"),
+	}
+	for i, b := range valid {
+		if err := b.CheckDst(); err != nil {
+			t.Errorf("valid tag %d rejected: %v", i, err)
+		}
+	}
+
+	invalid := []interface{ CheckDst() error }{
+		// hashes to the group of the public keys
+		NewSigBasicWithDst("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"),
+		NewSigBasicVtWithDst(blsSignatureBasicDst),
+		// names another scheme
+		NewSigAugWithDst(blsSignatureBasicDst),
+		NewSigBasicWithDst("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_NU_POP_"),
+		// the proof of possession tag is used for signatures
+		&SigPop{sigDst: blsPopProofDst, popDst: blsSignaturePopDst},
+		NewSigBasicWithDst(""),
+		NewSigBasicWithDst(string(make([]byte, 256))),
+	}
+	for i, b := range invalid {
+		if err := b.CheckDst(); err == nil {
+			t.Errorf("invalid tag %d accepted", i)
+		}
+	}
+
+	if _, err := NewSigPopWithDst(blsSignaturePopVtDst, blsPopProofVtDst); err == nil {
+		t.Errorf("NewSigPopWithDst accepted the tags of SigPopVt")
+	}
+	b, err := NewSigPopVtWithDst(blsSignaturePopVtDst, blsPopProofVtDst)
+	if err != nil {
+		t.Fatalf("NewSigPopVtWithDst failed: %v", err)
+	}
+	if b.Dst() != blsSignaturePopVtDst || b.PopDst() != blsPopProofVtDst {
+		t.Errorf("unexpected tags %s and %s", b.Dst(), b.PopDst())
+	}
+}
@@ -0,0 +1,205 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bls_sig
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
+)
+
+// PopRegistry is a set of public keys whose proofs of possession have been verified, e.g. a validator set,
+// which can verify multisignatures of any subset of its keys without the risk of rogue key attacks.
+// It caches the aggregate of all its keys and updates it when keys are added or removed, so the aggregate
+// of a subset costs one point addition per key outside of the subset when most keys sign.
+// A registry is safe for concurrent use.
+type PopRegistry struct {
+	scheme    *SigPop
+	mu        sync.RWMutex
+	keys      map[string]*PublicKey
+	aggregate bls12381.G1
+}
+
+// NewPopRegistry creates an empty registry which verifies proofs and signatures with the scheme
+func NewPopRegistry(scheme *SigPop) (*PopRegistry, error) {
+	if scheme == nil {
+		return nil, fmt.Errorf("invalid scheme")
+	}
+	r := &PopRegistry{
+		scheme: scheme,
+		keys:   make(map[string]*PublicKey),
+	}
+	r.aggregate.Identity()
+	return r, nil
+}
+
+// Register adds a public key after verifying its proof of possession
+func (r *PopRegistry) Register(pk *PublicKey, pop *ProofOfPossession) error {
+	return r.RegisterBatch([]*PublicKey{pk}, []*ProofOfPossession{pop})
+}
+
+// RegisterBatch adds public keys after verifying the proof of possession at the same index of each,
+// with BatchPopVerify. No key is added if any proof is invalid or any key is already registered
+func (r *PopRegistry) RegisterBatch(pks []*PublicKey, pops []*ProofOfPossession) error {
+	ids, err := registryIds(pks)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if seen[id] {
+			return fmt.Errorf("public key at %d is duplicated", i)
+		}
+		seen[id] = true
+	}
+	r.mu.RLock()
+	for i, id := range ids {
+		if _, ok := r.keys[id]; ok {
+			r.mu.RUnlock()
+			return fmt.Errorf("public key at %d is already registered", i)
+		}
+	}
+	r.mu.RUnlock()
+
+	ok, err := r.scheme.BatchPopVerify(pks, pops)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("invalid proof of possession")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Check again in case the keys were registered during the verification
+	for i, id := range ids {
+		if _, ok := r.keys[id]; ok {
+			return fmt.Errorf("public key at %d is already registered", i)
+		}
+	}
+	for i, id := range ids {
+		r.keys[id] = pks[i]
+		r.aggregate.Add(&r.aggregate, &pks[i].value)
+	}
+	return nil
+}
+
+// Remove removes a registered public key
+func (r *PopRegistry) Remove(pk *PublicKey) error {
+	ids, err := registryIds([]*PublicKey{pk})
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k, ok := r.keys[ids[0]]
+	if !ok {
+		return fmt.Errorf("public key is not registered")
+	}
+	delete(r.keys, ids[0])
+	r.aggregate.Sub(&r.aggregate, &k.value)
+	return nil
+}
+
+// Contains returns true if the public key is registered
+func (r *PopRegistry) Contains(pk *PublicKey) bool {
+	ids, err := registryIds([]*PublicKey{pk})
+	if err != nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.keys[ids[0]]
+	return ok
+}
+
+// Len returns the number of registered public keys
+func (r *PopRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.keys)
+}
+
+// AggregatePublicKey returns the aggregate of all the registered public keys
+func (r *PopRegistry) AggregatePublicKey() (*MultiPublicKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) == 0 {
+		return nil, fmt.Errorf("registry is empty")
+	}
+	return &MultiPublicKey{value: r.aggregate}, nil
+}
+
+// AggregatePublicKeys returns the aggregate of the signers, which must be distinct registered public keys.
+// When more than half of the keys sign, it subtracts the other keys from the cached aggregate
+func (r *PopRegistry) AggregatePublicKeys(signers ...*PublicKey) (*MultiPublicKey, error) {
+	ids, err := registryIds(signers)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("at least one public key is required")
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	signed := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if _, ok := r.keys[id]; !ok {
+			return nil, fmt.Errorf("public key at %d is not registered", i)
+		}
+		if signed[id] {
+			return nil, fmt.Errorf("public key at %d is duplicated", i)
+		}
+		signed[id] = true
+	}
+
+	result := new(bls12381.G1)
+	if 2*len(ids) > len(r.keys) {
+		result.Set(&r.aggregate)
+		for id, k := range r.keys {
+			if !signed[id] {
+				result.Sub(result, &k.value)
+			}
+		}
+	} else {
+		result.Identity()
+		for _, k := range signers {
+			result.Add(result, &k.value)
+		}
+	}
+	return &MultiPublicKey{value: *result}, nil
+}
+
+// VerifyMultiSignature checks that a multisignature of the message is valid for the signers,
+// which must be distinct registered public keys
+func (r *PopRegistry) VerifyMultiSignature(signers []*PublicKey, msg []byte, sig *MultiSignature) (bool, error) {
+	if sig == nil {
+		return false, fmt.Errorf("signature cannot be nil")
+	}
+	apk, err := r.AggregatePublicKeys(signers...)
+	if err != nil {
+		return false, err
+	}
+	return r.scheme.VerifyMultiSignature(apk, msg, sig)
+}
+
+// registryIds returns the compressed public keys, which identify them in a registry
+func registryIds(pks []*PublicKey) ([]string, error) {
+	ids := make([]string, len(pks))
+	for i, pk := range pks {
+		if pk == nil || pk.value.IsIdentity() == 1 {
+			return nil, fmt.Errorf("public key at %d is invalid", i)
+		}
+		b, err := pk.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = string(b)
+	}
+	return ids, nil
+}
@@ -0,0 +1,182 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bls_sig
+
+import (
+	"testing"
+
+	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
+)
+
+func newRegistryTest(t *testing.T, n int) (*PopRegistry, []*PublicKey, []*SecretKey) {
+	bls := NewSigPop()
+	r, err := NewPopRegistry(bls)
+	if err != nil {
+		t.Fatalf("NewPopRegistry failed: %v", err)
+	}
+	pks := make([]*PublicKey, n)
+	sks := make([]*SecretKey, n)
+	pops := make([]*ProofOfPossession, n)
+	for i := range pks {
+		pks[i], sks[i], err = bls.Keygen()
+		if err != nil {
+			t.Fatalf("Keygen failed: %v", err)
+		}
+		pops[i], err = bls.PopProve(sks[i])
+		if err != nil {
+			t.Fatalf("PopProve failed: %v", err)
+		}
+	}
+	if err = r.RegisterBatch(pks, pops); err != nil {
+		t.Fatalf("RegisterBatch failed: %v", err)
+	}
+	return r, pks, sks
+}
+
+func multiSign(t *testing.T, sks []*SecretKey, msg []byte) *MultiSignature {
+	bls := NewSigPop()
+	sigs := make([]*Signature, len(sks))
+	for i, sk := range sks {
+		var err error
+		sigs[i], err = bls.Sign(sk, msg)
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+	}
+	msig, err := bls.AggregateSignatures(sigs...)
+	if err != nil {
+		t.Fatalf("AggregateSignatures failed: %v", err)
+	}
+	return msig
+}
+
+func TestPopRegistryVerifyMultiSignature(t *testing.T) {
+	r, pks, sks := newRegistryTest(t, 6)
+	if r.Len() != 6 {
+		t.Fatalf("registry has %d keys", r.Len())
+	}
+	msg := []byte("block 1234")
+
+	// A majority uses the cached aggregate, a minority adds the keys
+	for _, signers := range [][]int{{0, 1, 2, 3, 4, 5}, {0, 2, 3, 5}, {1, 4}, {3}} {
+		var spks []*PublicKey
+		var ssks []*SecretKey
+		for _, i := range signers {
+			spks = append(spks, pks[i])
+			ssks = append(ssks, sks[i])
+		}
+		msig := multiSign(t, ssks, msg)
+		if ok, err := r.VerifyMultiSignature(spks, msg, msig); !ok || err != nil {
+			t.Errorf("VerifyMultiSignature failed for signers %v: %v", signers, err)
+		}
+		if ok, _ := r.VerifyMultiSignature(spks, []byte("block 1235"), msig); ok {
+			t.Errorf("VerifyMultiSignature succeeded for another message")
+		}
+		expected, err := NewSigPop().AggregatePublicKeys(spks...)
+		if err != nil {
+			t.Fatalf("AggregatePublicKeys failed: %v", err)
+		}
+		apk, err := r.AggregatePublicKeys(spks...)
+		if err != nil {
+			t.Fatalf("AggregatePublicKeys failed: %v", err)
+		}
+		if apk.value.Equal(&expected.value) != 1 {
+			t.Errorf("aggregate of signers %v is wrong", signers)
+		}
+	}
+
+	// The signers must match the signature
+	msig := multiSign(t, sks[:3], msg)
+	if ok, _ := r.VerifyMultiSignature(pks[:4], msg, msig); ok {
+		t.Errorf("VerifyMultiSignature succeeded with an extra signer")
+	}
+	if _, err := r.VerifyMultiSignature([]*PublicKey{pks[0], pks[1], pks[1]}, msg, msig); err == nil {
+		t.Errorf("VerifyMultiSignature accepted a duplicated signer")
+	}
+	if _, err := r.VerifyMultiSignature(nil, msg, msig); err == nil {
+		t.Errorf("VerifyMultiSignature accepted no signers")
+	}
+}
+
+func TestPopRegistryUpdates(t *testing.T) {
+	r, pks, sks := newRegistryTest(t, 4)
+	bls := NewSigPop()
+
+	// Duplicates and invalid proofs are rejected without changing the registry
+	pop, _ := bls.PopProve(sks[0])
+	if err := r.Register(pks[0], pop); err == nil {
+		t.Errorf("Register accepted a registered key")
+	}
+	pk, sk, _ := bls.Keygen()
+	if err := r.Register(pk, pop); err == nil {
+		t.Errorf("Register accepted an invalid proof")
+	}
+	other, otherSk, _ := bls.Keygen()
+	otherPop, _ := bls.PopProve(otherSk)
+	if err := r.RegisterBatch([]*PublicKey{other, other}, []*ProofOfPossession{otherPop, otherPop}); err == nil {
+		t.Errorf("RegisterBatch accepted a duplicated key")
+	}
+	if r.Len() != 4 || r.Contains(pk) || r.Contains(other) {
+		t.Fatalf("registry changed after rejected registrations")
+	}
+
+	pop, _ = bls.PopProve(sk)
+	if err := r.Register(pk, pop); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Remove(pks[1]); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := r.Remove(pks[1]); err == nil {
+		t.Errorf("Remove accepted an unregistered key")
+	}
+	if !r.Contains(pk) || r.Contains(pks[1]) || r.Len() != 4 {
+		t.Fatalf("registry is wrong after the updates")
+	}
+
+	// The cached aggregate follows the updates
+	current := []*PublicKey{pks[0], pks[2], pks[3], pk}
+	expected, _ := bls.AggregatePublicKeys(current...)
+	apk, err := r.AggregatePublicKey()
+	if err != nil {
+		t.Fatalf("AggregatePublicKey failed: %v", err)
+	}
+	if apk.value.Equal(&expected.value) != 1 {
+		t.Errorf("cached aggregate is wrong after the updates")
+	}
+	msg := []byte("epoch 7")
+	msig := multiSign(t, []*SecretKey{sks[0], sks[2], sk}, msg)
+	if ok, err := r.VerifyMultiSignature([]*PublicKey{pks[0], pks[2], pk}, msg, msig); !ok || err != nil {
+		t.Errorf("VerifyMultiSignature failed after the updates: %v", err)
+	}
+	if _, err = r.VerifyMultiSignature([]*PublicKey{pks[0], pks[1], pks[2]}, msg, msig); err == nil {
+		t.Errorf("VerifyMultiSignature accepted a removed key")
+	}
+}
+
+func TestPopRegistryRogueKey(t *testing.T) {
+	r, pks, _ := newRegistryTest(t, 2)
+	bls := NewSigPop()
+	// The rogue key pk_r = x*G - pk_0 makes x a valid multisignature key of {pk_0, pk_r}, but it has no proof
+	pkX, skX, _ := bls.Keygen()
+	rogue := &PublicKey{}
+	rogue.value.Sub(&pkX.value, &pks[0].value)
+	popX, _ := bls.PopProve(skX)
+	if err := r.Register(rogue, &ProofOfPossession{value: popX.value}); err == nil {
+		t.Errorf("Register accepted a rogue key")
+	}
+	empty, _ := NewPopRegistry(bls)
+	if _, err := empty.AggregatePublicKey(); err == nil {
+		t.Errorf("AggregatePublicKey succeeded for an empty registry")
+	}
+	if _, err := NewPopRegistry(nil); err == nil {
+		t.Errorf("NewPopRegistry accepted a nil scheme")
+	}
+	if err := r.Register(&PublicKey{value: *new(bls12381.G1).Identity()}, popX); err == nil {
+		t.Errorf("Register accepted the identity")
+	}
+}
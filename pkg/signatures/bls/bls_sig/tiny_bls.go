@@ -103,6 +103,17 @@ func NewSigBasicVtWithDst(signDst string) *SigBasicVt {
 	return &SigBasicVt{dst: signDst}
 }
 
+// Dst returns the domain separation tag of the signatures
+func (b SigBasicVt) Dst() string {
+	return b.dst
+}
+
+// CheckDst checks the domain separation tag, e.g. after NewSigBasicVtWithDst. A tag in the format of the standard
+// ciphersuite IDs must hash to G1 and name the NUL scheme, and any tag must be between 1 and 255 bytes.
+func (b SigBasicVt) CheckDst() error {
+	return checkDst(b.dst, "BLS_SIG_", "G1", "NUL")
+}
+
 // Creates a new BLS key pair
 func (b SigBasicVt) Keygen() (*PublicKeyVt, *SecretKey, error) {
 	return generateKeysVt()
@@ -142,6 +153,21 @@ func (b SigBasicVt) CombineSignatures(sigs ...*PartialSignatureVt) (*SignatureVt
 	return combineSigsVt(sigs)
 }
 
+// VerifyPartialSignature checks a partial signature of msg under the public key share of its signer
+func (b SigBasicVt) VerifyPartialSignature(pks *PublicKeyShareVt, msg []byte, sig *PartialSignatureVt) (bool, error) {
+	if pks == nil {
+		return false, fmt.Errorf("public key share cannot be nil")
+	}
+	return pks.verifyPartialSignatureVt(msg, sig, b.dst)
+}
+
+// RobustCombineSignatures combines the partial signatures of msg that are valid under the public key shares,
+// and returns the identifiers of the signers whose partial signatures are invalid. It fails if the valid
+// partial signatures do not yield a signature valid under pk.
+func (b SigBasicVt) RobustCombineSignatures(pk *PublicKeyVt, pkShares []*PublicKeyShareVt, msg []byte, sigs ...*PartialSignatureVt) (*SignatureVt, []byte, error) {
+	return robustCombineSigsVt(pk, pkShares, msg, sigs, b.dst)
+}
+
 // Checks that a signature is valid for the message under the public key pk
 func (b SigBasicVt) Verify(pk *PublicKeyVt, msg []byte, sig *SignatureVt) (bool, error) {
 	return pk.verifySignatureVt(msg, sig, b.dst)
@@ -179,6 +205,17 @@ func NewSigAugVtWithDst(signDst string) *SigAugVt {
 	return &SigAugVt{dst: signDst}
 }
 
+// Dst returns the domain separation tag of the signatures
+func (b SigAugVt) Dst() string {
+	return b.dst
+}
+
+// CheckDst checks the domain separation tag, e.g. after NewSigAugVtWithDst. A tag in the format of the standard
+// ciphersuite IDs must hash to G1 and name the AUG scheme, and any tag must be between 1 and 255 bytes.
+func (b SigAugVt) CheckDst() error {
+	return checkDst(b.dst, "BLS_SIG_", "G1", "AUG")
+}
+
 // Creates a new BLS key pair
 func (b SigAugVt) Keygen() (*PublicKeyVt, *SecretKey, error) {
 	return generateKeysVt()
@@ -237,6 +274,34 @@ func (b SigAugVt) CombineSignatures(sigs ...*PartialSignatureVt) (*SignatureVt,
 	return combineSigsVt(sigs)
 }
 
+// VerifyPartialSignature checks a partial signature of msg, augmented with pk, under the public key share of its signer
+func (b SigAugVt) VerifyPartialSignature(pks *PublicKeyShareVt, pk *PublicKeyVt, msg []byte, sig *PartialSignatureVt) (bool, error) {
+	if pks == nil || pk == nil {
+		return false, fmt.Errorf("public key share and public key cannot be nil")
+	}
+	bytes, err := pk.MarshalBinary()
+	if err != nil {
+		return false, fmt.Errorf("MarshalBinary failed")
+	}
+	bytes = append(bytes, msg...)
+	return pks.verifyPartialSignatureVt(bytes, sig, b.dst)
+}
+
+// RobustCombineSignatures combines the partial signatures of msg that are valid under the public key shares,
+// and returns the identifiers of the signers whose partial signatures are invalid. It fails if the valid
+// partial signatures do not yield a signature valid under pk.
+func (b SigAugVt) RobustCombineSignatures(pk *PublicKeyVt, pkShares []*PublicKeyShareVt, msg []byte, sigs ...*PartialSignatureVt) (*SignatureVt, []byte, error) {
+	if pk == nil {
+		return nil, nil, fmt.Errorf("public key cannot be nil")
+	}
+	bytes, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("MarshalBinary failed")
+	}
+	bytes = append(bytes, msg...)
+	return robustCombineSigsVt(pk, pkShares, bytes, sigs, b.dst)
+}
+
 // Checks that a signature is valid for the message under the public key pk
 // See section 3.2.2 from
 // https://tools.ietf.org/html/draft-irtf-cfrg-bls-signature-03
@@ -296,10 +361,33 @@ func NewSigPopVt() *SigPopVt {
 
 // Creates a new BLS message proof of possession signature scheme with a custom domain separation tag used for signatures.
 func NewSigPopVtWithDst(signDst, popDst string) (*SigPopVt, error) {
-	if signDst == popDst {
-		return nil, fmt.Errorf("domain separation tags cannot be equal")
+	b := &SigPopVt{sigDst: signDst, popDst: popDst}
+	if err := b.CheckDst(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Dst returns the domain separation tag of the signatures
+func (b SigPopVt) Dst() string {
+	return b.sigDst
+}
+
+// PopDst returns the domain separation tag of the proofs of possession
+func (b SigPopVt) PopDst() string {
+	return b.popDst
+}
+
+// CheckDst checks the domain separation tags, which must differ. Tags in the format of the standard
+// ciphersuite IDs must hash to G1 and name the POP scheme, and any tag must be between 1 and 255 bytes.
+func (b SigPopVt) CheckDst() error {
+	if b.sigDst == b.popDst {
+		return fmt.Errorf("domain separation tags cannot be equal")
+	}
+	if err := checkDst(b.sigDst, "BLS_SIG_", "G1", "POP"); err != nil {
+		return err
 	}
-	return &SigPopVt{sigDst: signDst, popDst: popDst}, nil
+	return checkDst(b.popDst, "BLS_POP_", "G1", "POP")
 }
 
 // Creates a new BLS key pair
@@ -343,6 +431,21 @@ func (b SigPopVt) CombineSignatures(sigs ...*PartialSignatureVt) (*SignatureVt,
 	return combineSigsVt(sigs)
 }
 
+// VerifyPartialSignature checks a partial signature of msg under the public key share of its signer
+func (b SigPopVt) VerifyPartialSignature(pks *PublicKeyShareVt, msg []byte, sig *PartialSignatureVt) (bool, error) {
+	if pks == nil {
+		return false, fmt.Errorf("public key share cannot be nil")
+	}
+	return pks.verifyPartialSignatureVt(msg, sig, b.sigDst)
+}
+
+// RobustCombineSignatures combines the partial signatures of msg that are valid under the public key shares,
+// and returns the identifiers of the signers whose partial signatures are invalid. It fails if the valid
+// partial signatures do not yield a signature valid under pk.
+func (b SigPopVt) RobustCombineSignatures(pk *PublicKeyVt, pkShares []*PublicKeyShareVt, msg []byte, sigs ...*PartialSignatureVt) (*SignatureVt, []byte, error) {
+	return robustCombineSigsVt(pk, pkShares, msg, sigs, b.sigDst)
+}
+
 // Checks that a signature is valid for the message under the public key pk
 // See section 2.7 from
 // https://tools.ietf.org/html/draft-irtf-cfrg-bls-signature-03
@@ -488,3 +488,93 @@ func splitXYVt(partials []*PartialSignatureVt) ([]*native.Field, []*bls12381.G1,
 	}
 	return x, y, nil
 }
+
+// PublicKeyShareVt is the public key of a secret key share in G2, which verifies
+// the partial signatures of the share
+type PublicKeyShareVt struct {
+	identifier byte
+	publicKey  PublicKeyVt
+}
+
+// GetPublicKeyShareVt returns the public key of the share, which the holder or the dealer
+// publishes so partial signatures can be checked
+func (sks *SecretKeyShare) GetPublicKeyShareVt() (*PublicKeyShareVt, error) {
+	s, err := sks.scalar()
+	if err != nil {
+		return nil, err
+	}
+	result := new(bls12381.G2).Mul(new(bls12381.G2).Generator(), s)
+	if result.InCorrectSubgroup() == 0 || result.IsIdentity() == 1 {
+		return nil, fmt.Errorf("point is not in correct subgroup")
+	}
+	return &PublicKeyShareVt{identifier: sks.identifier, publicKey: PublicKeyVt{value: *result}}, nil
+}
+
+// Serialize a public key share to the compressed public key followed by the identifier
+func (pks *PublicKeyShareVt) MarshalBinary() ([]byte, error) {
+	out := pks.publicKey.value.ToCompressed()
+	return append(out[:], pks.identifier), nil
+}
+
+// Deserialize a public key share written by MarshalBinary
+func (pks *PublicKeyShareVt) UnmarshalBinary(data []byte) error {
+	if len(data) != PublicKeyVtSize+1 {
+		return fmt.Errorf("public key share must be %d bytes", PublicKeyVtSize+1)
+	}
+	if err := pks.publicKey.UnmarshalBinary(data[:PublicKeyVtSize]); err != nil {
+		return err
+	}
+	pks.identifier = data[PublicKeyVtSize]
+	return nil
+}
+
+// verifyPartialSignatureVt checks a partial signature of the message under this public key share
+func (pks *PublicKeyShareVt) verifyPartialSignatureVt(message []byte, sig *PartialSignatureVt, signDstVt string) (bool, error) {
+	if sig == nil {
+		return false, fmt.Errorf("partial signature cannot be nil")
+	}
+	if sig.identifier != pks.identifier {
+		return false, fmt.Errorf("partial signature identifier %d does not match the public key share %d", sig.identifier, pks.identifier)
+	}
+	return pks.publicKey.verifySignatureVt(message, &SignatureVt{value: sig.signature}, signDstVt)
+}
+
+// robustCombineSigsVt combines the partial signatures that are valid under their public key share,
+// and returns the identifiers of the invalid ones. It fails if the valid partial signatures do not
+// yield a signature valid under pk, e.g. when there are fewer than the threshold.
+func robustCombineSigsVt(pk *PublicKeyVt, pkShares []*PublicKeyShareVt, message []byte, partials []*PartialSignatureVt, signDstVt string) (*SignatureVt, []byte, error) {
+	if pk == nil {
+		return nil, nil, fmt.Errorf("public key cannot be nil")
+	}
+	shares := make(map[byte]*PublicKeyShareVt, len(pkShares))
+	for _, s := range pkShares {
+		if s == nil {
+			return nil, nil, fmt.Errorf("public key share cannot be nil")
+		}
+		shares[s.identifier] = s
+	}
+	var valid []*PartialSignatureVt
+	var invalid []byte
+	for _, p := range partials {
+		if p == nil {
+			return nil, nil, fmt.Errorf("partial signature cannot be nil")
+		}
+		share, ok := shares[p.identifier]
+		if !ok {
+			return nil, nil, fmt.Errorf("no public key share for partial signature %d", p.identifier)
+		}
+		if ok, _ := share.verifyPartialSignatureVt(message, p, signDstVt); !ok {
+			invalid = append(invalid, p.identifier)
+			continue
+		}
+		valid = append(valid, p)
+	}
+	sig, err := combineSigsVt(valid)
+	if err != nil {
+		return nil, invalid, err
+	}
+	if ok, _ := pk.verifySignatureVt(message, sig, signDstVt); !ok {
+		return nil, invalid, fmt.Errorf("not enough valid partial signatures")
+	}
+	return sig, invalid, nil
+}
@@ -843,3 +843,51 @@ func TestPopVtPartialMixupShares(t *testing.T) {
 		t.Errorf("CombineSignatures expected to fail but succeeded.")
 	}
 }
+
+func TestPopVtRobustCombineSignatures(t *testing.T) {
+	bls := NewSigPopVt()
+	pk, sks, err := bls.ThresholdKeygen(2, 4)
+	if err != nil {
+		t.Fatalf("ThresholdKeygen failed: %v", err)
+	}
+	msg := []byte("threshold message")
+	pkShares := make([]*PublicKeyShareVt, len(sks))
+	sigs := make([]*PartialSignatureVt, len(sks))
+	for i, sks := range sks {
+		if pkShares[i], err = sks.GetPublicKeyShareVt(); err != nil {
+			t.Fatalf("GetPublicKeyShareVt failed: %v", err)
+		}
+		if sigs[i], err = bls.PartialSign(sks, msg); err != nil {
+			t.Fatalf("PartialSign failed: %v", err)
+		}
+		if res, err := bls.VerifyPartialSignature(pkShares[i], msg, sigs[i]); err != nil || !res {
+			t.Errorf("VerifyPartialSignature failed: %v", err)
+		}
+	}
+
+	// signers 1 and 4 send partial signatures of another message
+	sigs[0], _ = bls.PartialSign(sks[0], []byte("other message"))
+	sigs[3], _ = bls.PartialSign(sks[3], []byte("other message"))
+	sig, invalid, err := bls.RobustCombineSignatures(pk, pkShares, msg, sigs...)
+	if err != nil {
+		t.Fatalf("RobustCombineSignatures failed: %v", err)
+	}
+	if !bytes.Equal(invalid, []byte{sigs[0].identifier, sigs[3].identifier}) {
+		t.Errorf("expected invalid signers 1 and 4, found %v", invalid)
+	}
+	if res, _ := bls.Verify(pk, msg, sig); !res {
+		t.Errorf("Combined signature does not verify")
+	}
+
+	data, err := pkShares[1].MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	actual := new(PublicKeyShareVt)
+	if err = actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if res, _ := bls.VerifyPartialSignature(actual, msg, sigs[1]); !res {
+		t.Errorf("deserialized public key share does not verify")
+	}
+}
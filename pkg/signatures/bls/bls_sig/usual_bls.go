@@ -103,6 +103,17 @@ func NewSigBasicWithDst(signDst string) *SigBasic {
 	return &SigBasic{dst: signDst}
 }
 
+// Dst returns the domain separation tag of the signatures
+func (b SigBasic) Dst() string {
+	return b.dst
+}
+
+// CheckDst checks the domain separation tag, e.g. after NewSigBasicWithDst. A tag in the format of the standard
+// ciphersuite IDs must hash to G2 and name the NUL scheme, and any tag must be between 1 and 255 bytes.
+func (b SigBasic) CheckDst() error {
+	return checkDst(b.dst, "BLS_SIG_", "G2", "NUL")
+}
+
 // Creates a new BLS key pair
 func (b SigBasic) Keygen() (*PublicKey, *SecretKey, error) {
 	return generateKeys()
@@ -142,6 +153,21 @@ func (b SigBasic) CombineSignatures(sigs ...*PartialSignature) (*Signature, erro
 	return combineSigs(sigs)
 }
 
+// VerifyPartialSignature checks a partial signature of msg under the public key share of its signer
+func (b SigBasic) VerifyPartialSignature(pks *PublicKeyShare, msg []byte, sig *PartialSignature) (bool, error) {
+	if pks == nil {
+		return false, fmt.Errorf("public key share cannot be nil")
+	}
+	return pks.verifyPartialSignature(msg, sig, b.dst)
+}
+
+// RobustCombineSignatures combines the partial signatures of msg that are valid under the public key shares,
+// and returns the identifiers of the signers whose partial signatures are invalid. It fails if the valid
+// partial signatures do not yield a signature valid under pk.
+func (b SigBasic) RobustCombineSignatures(pk *PublicKey, pkShares []*PublicKeyShare, msg []byte, sigs ...*PartialSignature) (*Signature, []byte, error) {
+	return robustCombineSigs(pk, pkShares, msg, sigs, b.dst)
+}
+
 // Checks that a signature is valid for the message under the public key pk
 func (b SigBasic) Verify(pk *PublicKey, msg []byte, sig *Signature) (bool, error) {
 	return pk.verifySignature(msg, sig, b.dst)
@@ -179,6 +205,17 @@ func NewSigAugWithDst(signDst string) *SigAug {
 	return &SigAug{dst: signDst}
 }
 
+// Dst returns the domain separation tag of the signatures
+func (b SigAug) Dst() string {
+	return b.dst
+}
+
+// CheckDst checks the domain separation tag, e.g. after NewSigAugWithDst. A tag in the format of the standard
+// ciphersuite IDs must hash to G2 and name the AUG scheme, and any tag must be between 1 and 255 bytes.
+func (b SigAug) CheckDst() error {
+	return checkDst(b.dst, "BLS_SIG_", "G2", "AUG")
+}
+
 // Creates a new BLS key pair
 func (b SigAug) Keygen() (*PublicKey, *SecretKey, error) {
 	return generateKeys()
@@ -242,6 +279,34 @@ func (b SigAug) CombineSignatures(sigs ...*PartialSignature) (*Signature, error)
 	return combineSigs(sigs)
 }
 
+// VerifyPartialSignature checks a partial signature of msg, augmented with pk, under the public key share of its signer
+func (b SigAug) VerifyPartialSignature(pks *PublicKeyShare, pk *PublicKey, msg []byte, sig *PartialSignature) (bool, error) {
+	if pks == nil || pk == nil {
+		return false, fmt.Errorf("public key share and public key cannot be nil")
+	}
+	bytes, err := pk.MarshalBinary()
+	if err != nil {
+		return false, fmt.Errorf("MarshalBinary failed")
+	}
+	bytes = append(bytes, msg...)
+	return pks.verifyPartialSignature(bytes, sig, b.dst)
+}
+
+// RobustCombineSignatures combines the partial signatures of msg that are valid under the public key shares,
+// and returns the identifiers of the signers whose partial signatures are invalid. It fails if the valid
+// partial signatures do not yield a signature valid under pk.
+func (b SigAug) RobustCombineSignatures(pk *PublicKey, pkShares []*PublicKeyShare, msg []byte, sigs ...*PartialSignature) (*Signature, []byte, error) {
+	if pk == nil {
+		return nil, nil, fmt.Errorf("public key cannot be nil")
+	}
+	bytes, err := pk.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("MarshalBinary failed")
+	}
+	bytes = append(bytes, msg...)
+	return robustCombineSigs(pk, pkShares, bytes, sigs, b.dst)
+}
+
 // Checks that a signature is valid for the message under the public key pk
 // See section 3.2.2 from
 // https://tools.ietf.org/html/draft-irtf-cfrg-bls-signature-03
@@ -301,10 +366,33 @@ func NewSigPop() *SigPop {
 
 // Creates a new BLS message proof of possession signature scheme with a custom domain separation tag used for signatures.
 func NewSigPopWithDst(signDst, popDst string) (*SigPop, error) {
-	if signDst == popDst {
-		return nil, fmt.Errorf("domain separation tags cannot be equal")
+	b := &SigPop{sigDst: signDst, popDst: popDst}
+	if err := b.CheckDst(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Dst returns the domain separation tag of the signatures
+func (b SigPop) Dst() string {
+	return b.sigDst
+}
+
+// PopDst returns the domain separation tag of the proofs of possession
+func (b SigPop) PopDst() string {
+	return b.popDst
+}
+
+// CheckDst checks the domain separation tags, which must differ. Tags in the format of the standard
+// ciphersuite IDs must hash to G2 and name the POP scheme, and any tag must be between 1 and 255 bytes.
+func (b SigPop) CheckDst() error {
+	if b.sigDst == b.popDst {
+		return fmt.Errorf("domain separation tags cannot be equal")
+	}
+	if err := checkDst(b.sigDst, "BLS_SIG_", "G2", "POP"); err != nil {
+		return err
 	}
-	return &SigPop{sigDst: signDst, popDst: popDst}, nil
+	return checkDst(b.popDst, "BLS_POP_", "G2", "POP")
 }
 
 // Creates a new BLS key pair
@@ -348,6 +436,21 @@ func (b SigPop) CombineSignatures(sigs ...*PartialSignature) (*Signature, error)
 	return combineSigs(sigs)
 }
 
+// VerifyPartialSignature checks a partial signature of msg under the public key share of its signer
+func (b SigPop) VerifyPartialSignature(pks *PublicKeyShare, msg []byte, sig *PartialSignature) (bool, error) {
+	if pks == nil {
+		return false, fmt.Errorf("public key share cannot be nil")
+	}
+	return pks.verifyPartialSignature(msg, sig, b.sigDst)
+}
+
+// RobustCombineSignatures combines the partial signatures of msg that are valid under the public key shares,
+// and returns the identifiers of the signers whose partial signatures are invalid. It fails if the valid
+// partial signatures do not yield a signature valid under pk.
+func (b SigPop) RobustCombineSignatures(pk *PublicKey, pkShares []*PublicKeyShare, msg []byte, sigs ...*PartialSignature) (*Signature, []byte, error) {
+	return robustCombineSigs(pk, pkShares, msg, sigs, b.sigDst)
+}
+
 // Checks that a signature is valid for the message under the public key pk
 // See section 2.7 from
 // https://tools.ietf.org/html/draft-irtf-cfrg-bls-signature-03
@@ -427,6 +530,15 @@ func (b SigPop) FastAggregateVerifyConstituent(pks []*PublicKey, msg []byte, sig
 	return b.FastAggregateVerify(pks, msg, asig)
 }
 
+// VerifySameMessage checks the signatures of many signers on the same message, e.g. the votes of a committee,
+// with two pairings. FastAggregateVerifyConstituent only checks the sum of the signatures, which can be valid
+// when the signatures are not. Here each signature is weighted with a random scalar,
+// so the check fails if any signature is invalid.
+// Like FastAggregateVerify, it requires a verified proof of possession for each public key, see BatchPopVerify.
+func (b SigPop) VerifySameMessage(pks []*PublicKey, msg []byte, sigs []*Signature) (bool, error) {
+	return verifySameMessage(pks, msg, sigs, b.sigDst)
+}
+
 // Create a proof of possession for the corresponding public key.
 // A proof of possession must be created for each public key to be used
 // in FastAggregateVerify or a Multipublickey to avoid rogue key attacks.
@@ -444,3 +556,10 @@ func (b SigPop) PopProve(sk *SecretKey) (*ProofOfPossession, error) {
 func (b SigPop) PopVerify(pk *PublicKey, pop2 *ProofOfPossession) (bool, error) {
 	return pop2.verify(pk, b.popDst)
 }
+
+// BatchPopVerify verifies the proof of possession at the same index of each public key, e.g. when a committee
+// is formed, with a single final exponentiation instead of one per proof.
+// It returns false if any proof is invalid, which PopVerify can then find.
+func (b SigPop) BatchPopVerify(pks []*PublicKey, pops []*ProofOfPossession) (bool, error) {
+	return batchVerifyPops(pks, pops, b.popDst)
+}
@@ -7,6 +7,7 @@
 package bls_sig
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/etclab/kryptology/internal"
@@ -490,3 +491,191 @@ func splitXY(partials []*PartialSignature) ([]*native.Field, []*bls12381.G2, err
 	}
 	return x, y, nil
 }
+
+// batchWeights returns `n` random non-zero 64-bit scalars for checking a random linear combination of equations,
+// which fails with probability at most 2^-63 if any equation fails
+func batchWeights(n int) ([]*native.Field, error) {
+	buf, err := generateRandBytes(8 * n)
+	if err != nil {
+		return nil, err
+	}
+	weights := make([]*native.Field, n)
+	for i := range weights {
+		weights[i] = bls12381.Bls12381FqNew().SetUint64(binary.LittleEndian.Uint64(buf[8*i:]) | 1)
+	}
+	return weights, nil
+}
+
+// verifySameMessage checks signatures of the same message under different public keys with two pairings.
+// Every signature is weighted with a random scalar r_i, so that
+// e(sum r_i*pk_i, H(m)) == e(g1, sum r_i*s_i) only holds, except with negligible probability, if each signature does.
+func verifySameMessage(pks []*PublicKey, message []byte, sigs []*Signature, signDst string) (bool, error) {
+	if len(pks) < 1 {
+		return false, fmt.Errorf("at least one key is required")
+	}
+	if len(pks) != len(sigs) {
+		return false, fmt.Errorf("the number of public keys does not match the number of signatures: %v != %v", len(pks), len(sigs))
+	}
+	if message == nil {
+		return false, fmt.Errorf("message cannot be nil")
+	}
+	g1s := make([]*bls12381.G1, len(pks))
+	g2s := make([]*bls12381.G2, len(sigs))
+	for i, pk := range pks {
+		if pk == nil || pk.value.IsIdentity() == 1 || pk.value.InCorrectSubgroup() == 0 {
+			return false, fmt.Errorf("public key at %d is not in the correct subgroup", i)
+		}
+		if sigs[i] == nil || sigs[i].Value.IsIdentity() == 1 || sigs[i].Value.InCorrectSubgroup() == 0 {
+			return false, fmt.Errorf("signature at %d is not in the correct subgroup", i)
+		}
+		g1s[i] = &pk.value
+		g2s[i] = &sigs[i].Value
+	}
+	weights, err := batchWeights(len(pks))
+	if err != nil {
+		return false, err
+	}
+	apk, err := new(bls12381.G1).SumOfProducts(g1s, weights)
+	if err != nil {
+		return false, err
+	}
+	asig, err := new(bls12381.G2).SumOfProducts(g2s, weights)
+	if err != nil {
+		return false, err
+	}
+
+	engine := new(bls12381.Engine)
+	p2 := new(bls12381.G2).Hash(native.EllipticPointHasherSha256(), message, []byte(signDst))
+	engine.AddPair(apk, p2)
+	engine.AddPairInvG1(new(bls12381.G1).Generator(), asig)
+	return engine.Check(), nil
+}
+
+// batchVerifyPops checks proofs of possession with one final exponentiation, by checking
+// e(r_1*pk_1, H(pk_1))*...*e(r_N*pk_N, H(pk_N)) == e(g1, sum r_i*pop_i) for random scalars r_i
+func batchVerifyPops(pks []*PublicKey, pops []*ProofOfPossession, popDst string) (bool, error) {
+	if len(pks) < 1 {
+		return false, fmt.Errorf("at least one key is required")
+	}
+	if len(pks) != len(pops) {
+		return false, fmt.Errorf("the number of public keys does not match the number of proofs: %v != %v", len(pks), len(pops))
+	}
+	weights, err := batchWeights(len(pks))
+	if err != nil {
+		return false, err
+	}
+	dst := []byte(popDst)
+	engine := new(bls12381.Engine)
+	g2s := make([]*bls12381.G2, len(pops))
+	for i, pk := range pks {
+		if pk == nil || pk.value.IsIdentity() == 1 || pk.value.InCorrectSubgroup() == 0 {
+			return false, fmt.Errorf("public key at %d is not in the correct subgroup", i)
+		}
+		if pops[i] == nil || pops[i].value.IsIdentity() == 1 || pops[i].value.InCorrectSubgroup() == 0 {
+			return false, fmt.Errorf("proof of possession at %d is not in the correct subgroup", i)
+		}
+		msg, err := pk.MarshalBinary()
+		if err != nil {
+			return false, err
+		}
+		p2 := new(bls12381.G2).Hash(native.EllipticPointHasherSha256(), msg, dst)
+		engine.AddPair(new(bls12381.G1).Mul(&pk.value, weights[i]), p2)
+		g2s[i] = &pops[i].value
+	}
+	apop, err := new(bls12381.G2).SumOfProducts(g2s, weights)
+	if err != nil {
+		return false, err
+	}
+	engine.AddPairInvG1(new(bls12381.G1).Generator(), apop)
+	return engine.Check(), nil
+}
+
+// PublicKeyShare is the public key of a secret key share in G1, which verifies
+// the partial signatures of the share
+type PublicKeyShare struct {
+	Identifier byte
+	PublicKey  PublicKey
+}
+
+// GetPublicKeyShare returns the public key of the share, which the holder or the dealer
+// publishes so partial signatures can be checked
+func (sks SecretKeyShare) GetPublicKeyShare() (*PublicKeyShare, error) {
+	s, err := sks.scalar()
+	if err != nil {
+		return nil, err
+	}
+	result := new(bls12381.G1).Mul(new(bls12381.G1).Generator(), s)
+	if result.InCorrectSubgroup() == 0 || result.IsIdentity() == 1 {
+		return nil, fmt.Errorf("point is not in correct subgroup")
+	}
+	return &PublicKeyShare{Identifier: sks.identifier, PublicKey: PublicKey{value: *result}}, nil
+}
+
+// Serialize a public key share to the compressed public key followed by the identifier
+func (pks PublicKeyShare) MarshalBinary() ([]byte, error) {
+	out := pks.PublicKey.value.ToCompressed()
+	return append(out[:], pks.Identifier), nil
+}
+
+// Deserialize a public key share written by MarshalBinary
+func (pks *PublicKeyShare) UnmarshalBinary(data []byte) error {
+	if len(data) != PublicKeySize+1 {
+		return fmt.Errorf("public key share must be %d bytes", PublicKeySize+1)
+	}
+	if err := pks.PublicKey.UnmarshalBinary(data[:PublicKeySize]); err != nil {
+		return err
+	}
+	pks.Identifier = data[PublicKeySize]
+	return nil
+}
+
+// verifyPartialSignature checks a partial signature of the message under this public key share
+func (pks PublicKeyShare) verifyPartialSignature(message []byte, sig *PartialSignature, signDst string) (bool, error) {
+	if sig == nil {
+		return false, fmt.Errorf("partial signature cannot be nil")
+	}
+	if sig.Identifier != pks.Identifier {
+		return false, fmt.Errorf("partial signature identifier %d does not match the public key share %d", sig.Identifier, pks.Identifier)
+	}
+	return pks.PublicKey.verifySignature(message, &Signature{Value: sig.Signature}, signDst)
+}
+
+// robustCombineSigs combines the partial signatures that are valid under their public key share,
+// and returns the identifiers of the invalid ones. It fails if the valid partial signatures do not
+// yield a signature valid under pk, e.g. when there are fewer than the threshold.
+func robustCombineSigs(pk *PublicKey, pkShares []*PublicKeyShare, message []byte, partials []*PartialSignature, signDst string) (*Signature, []byte, error) {
+	if pk == nil {
+		return nil, nil, fmt.Errorf("public key cannot be nil")
+	}
+	shares := make(map[byte]*PublicKeyShare, len(pkShares))
+	for _, s := range pkShares {
+		if s == nil {
+			return nil, nil, fmt.Errorf("public key share cannot be nil")
+		}
+		shares[s.Identifier] = s
+	}
+	var valid []*PartialSignature
+	var invalid []byte
+	for _, p := range partials {
+		if p == nil {
+			return nil, nil, fmt.Errorf("partial signature cannot be nil")
+		}
+		share, ok := shares[p.Identifier]
+		if !ok {
+			return nil, nil, fmt.Errorf("no public key share for partial signature %d", p.Identifier)
+		}
+		if ok, _ := share.verifyPartialSignature(message, p, signDst); !ok {
+			invalid = append(invalid, p.Identifier)
+			continue
+		}
+		valid = append(valid, p)
+	}
+	sig, err := combineSigs(valid)
+	if err != nil {
+		return nil, invalid, err
+	}
+	if ok, _ := pk.verifySignature(message, sig, signDst); !ok {
+		return nil, invalid, fmt.Errorf("not enough valid partial signatures")
+	}
+	return sig, invalid, nil
+}
@@ -408,3 +408,38 @@ func TestAugPartialSignNilMessage(t *testing.T) {
 		t.Errorf("Expected partial sign of nil message to fail")
 	}
 }
+
+func TestAugRobustCombineSignatures(t *testing.T) {
+	bls := NewSigAug()
+	pk, sks, err := bls.ThresholdKeygen(2, 3)
+	if err != nil {
+		t.Fatalf("ThresholdKeygen failed: %v", err)
+	}
+	msg := []byte("threshold message")
+	pkShares := make([]*PublicKeyShare, len(sks))
+	sigs := make([]*PartialSignature, len(sks))
+	for i, sks := range sks {
+		if pkShares[i], err = sks.GetPublicKeyShare(); err != nil {
+			t.Fatalf("GetPublicKeyShare failed: %v", err)
+		}
+		if sigs[i], err = bls.PartialSign(sks, pk, msg); err != nil {
+			t.Fatalf("PartialSign failed: %v", err)
+		}
+		if res, err := bls.VerifyPartialSignature(pkShares[i], pk, msg, sigs[i]); err != nil || !res {
+			t.Errorf("VerifyPartialSignature failed: %v", err)
+		}
+	}
+
+	// the partial signature of signer 1 is not augmented with the public key
+	sigs[0], _ = NewSigPop().PartialSign(sks[0], msg)
+	sig, invalid, err := bls.RobustCombineSignatures(pk, pkShares, msg, sigs...)
+	if err != nil {
+		t.Fatalf("RobustCombineSignatures failed: %v", err)
+	}
+	if len(invalid) != 1 || invalid[0] != sigs[0].Identifier {
+		t.Errorf("expected invalid signer %d, found %v", sigs[0].Identifier, invalid)
+	}
+	if res, _ := bls.Verify(pk, msg, sig); !res {
+		t.Errorf("Combined signature does not verify")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/etclab/kryptology/pkg/core/curves/native"
 	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
 )
 
@@ -864,3 +865,138 @@ func TestSigEth2SignRoundTrip(t *testing.T) {
 		t.Errorf("Verify failed: %v", err)
 	}
 }
+
+func TestVerifySameMessageG2Works(t *testing.T) {
+	bls := NewSigPop()
+	msg := []byte("committee vote")
+	pks := make([]*PublicKey, 10)
+	sigs := make([]*Signature, 10)
+	pops := make([]*ProofOfPossession, 10)
+	for i := range pks {
+		pk, sk, err := bls.Keygen()
+		if err != nil {
+			t.Fatalf("Keygen failed: %v", err)
+		}
+		pks[i] = pk
+		if sigs[i], err = bls.Sign(sk, msg); err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		if pops[i], err = bls.PopProve(sk); err != nil {
+			t.Fatalf("PopProve failed: %v", err)
+		}
+	}
+	if res, err := bls.BatchPopVerify(pks, pops); err != nil || !res {
+		t.Errorf("BatchPopVerify failed: %v", err)
+	}
+	if res, err := bls.VerifySameMessage(pks, msg, sigs); err != nil || !res {
+		t.Errorf("VerifySameMessage failed: %v", err)
+	}
+	if res, _ := bls.VerifySameMessage(pks, []byte("other vote"), sigs); res {
+		t.Errorf("VerifySameMessage verified another message")
+	}
+
+	// the proofs do not belong to the same keys
+	pops[0], pops[1] = pops[1], pops[0]
+	if res, _ := bls.BatchPopVerify(pks, pops); res {
+		t.Errorf("BatchPopVerify verified swapped proofs")
+	}
+	if _, err := bls.VerifySameMessage(pks, msg, sigs[1:]); err == nil {
+		t.Errorf("VerifySameMessage accepted mismatched lengths")
+	}
+}
+
+func TestVerifySameMessageG2DetectsSplitSignatures(t *testing.T) {
+	bls := NewSigPop()
+	msg := []byte("committee vote")
+	pks := make([]*PublicKey, 2)
+	sigs := make([]*Signature, 2)
+	for i := range pks {
+		_, sk, _ := bls.Keygen()
+		pks[i], _ = sk.GetPublicKey()
+		sigs[i], _ = bls.Sign(sk, msg)
+	}
+
+	// moving a point from one signature to the other keeps their sum valid
+	x := new(bls12381.G2).Hash(native.EllipticPointHasherSha256(), []byte("x"), []byte("x"))
+	sigs[0] = &Signature{Value: *new(bls12381.G2).Add(&sigs[0].Value, x)}
+	sigs[1] = &Signature{Value: *new(bls12381.G2).Sub(&sigs[1].Value, x)}
+	if res, _ := bls.FastAggregateVerifyConstituent(pks, msg, sigs); !res {
+		t.Errorf("FastAggregateVerifyConstituent should only check the sum")
+	}
+	if res, _ := bls.VerifySameMessage(pks, msg, sigs); res {
+		t.Errorf("VerifySameMessage verified invalid signatures")
+	}
+}
+
+func TestPopRobustCombineSignatures(t *testing.T) {
+	bls := NewSigPop()
+	pk, sks, err := bls.ThresholdKeygen(3, 5)
+	if err != nil {
+		t.Fatalf("ThresholdKeygen failed: %v", err)
+	}
+	msg := []byte("threshold message")
+	pkShares := make([]*PublicKeyShare, len(sks))
+	sigs := make([]*PartialSignature, len(sks))
+	for i, sks := range sks {
+		if pkShares[i], err = sks.GetPublicKeyShare(); err != nil {
+			t.Fatalf("GetPublicKeyShare failed: %v", err)
+		}
+		if sigs[i], err = bls.PartialSign(sks, msg); err != nil {
+			t.Fatalf("PartialSign failed: %v", err)
+		}
+		if res, err := bls.VerifyPartialSignature(pkShares[i], msg, sigs[i]); err != nil || !res {
+			t.Errorf("VerifyPartialSignature failed: %v", err)
+		}
+	}
+	if res, _ := bls.VerifyPartialSignature(pkShares[0], msg, sigs[1]); res {
+		t.Errorf("VerifyPartialSignature verified the partial signature of another share")
+	}
+
+	// signer 2 sends the partial signature of another message
+	bad, _ := bls.PartialSign(sks[1], []byte("other message"))
+	sigs[1] = bad
+	sig, invalid, err := bls.RobustCombineSignatures(pk, pkShares, msg, sigs...)
+	if err != nil {
+		t.Fatalf("RobustCombineSignatures failed: %v", err)
+	}
+	if !bytes.Equal(invalid, []byte{sigs[1].Identifier}) {
+		t.Errorf("expected invalid signer %d, found %v", sigs[1].Identifier, invalid)
+	}
+	if res, _ := bls.Verify(pk, msg, sig); !res {
+		t.Errorf("Combined signature does not verify")
+	}
+
+	// only two valid partial signatures remain
+	_, invalid, err = bls.RobustCombineSignatures(pk, pkShares, msg, sigs[:3]...)
+	if err == nil {
+		t.Errorf("RobustCombineSignatures succeeded below the threshold")
+	}
+	if len(invalid) != 1 {
+		t.Errorf("expected one invalid signer, found %v", invalid)
+	}
+}
+
+func TestPublicKeyShareBytes(t *testing.T) {
+	_, sks, err := NewSigPop().ThresholdKeygen(2, 3)
+	if err != nil {
+		t.Fatalf("ThresholdKeygen failed: %v", err)
+	}
+	pks, err := sks[2].GetPublicKeyShare()
+	if err != nil {
+		t.Fatalf("GetPublicKeyShare failed: %v", err)
+	}
+	data, err := pks.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	actual := new(PublicKeyShare)
+	if err = actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if actual.Identifier != pks.Identifier || actual.PublicKey.value.Equal(&pks.PublicKey.value) != 1 {
+		t.Errorf("public key share changed in serialization")
+	}
+	if err = actual.UnmarshalBinary(data[:PublicKeySize]); err == nil {
+		t.Errorf("UnmarshalBinary accepted a short input")
+	}
+}
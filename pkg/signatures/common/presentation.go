@@ -0,0 +1,209 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/gtank/merlin"
+)
+
+// AttributeRef identifies a signed message by the index of the
+// credential in a PresentationRequest and the index of the message
+// within that credential
+type AttributeRef struct {
+	Credential int
+	Attribute  int
+}
+
+// CredentialRequest describes what a verifier wants to learn
+// about a single credential
+type CredentialRequest struct {
+	// Label is an application specific identifier such as a schema or issuer id
+	Label string
+	// Attributes is the number of messages signed in the credential
+	Attributes int
+	// Revealed lists the indices of the messages the holder must disclose
+	Revealed []int
+}
+
+// PresentationRequest is sent by a verifier to a holder and lists
+// the credentials, disclosed attributes and predicates over hidden
+// attributes that must be proven in a presentation
+type PresentationRequest struct {
+	// Nonce is chosen by the verifier to prove freshness and prevent replays
+	Nonce []byte
+	// Credentials are the credentials that must be presented, in order
+	Credentials []CredentialRequest
+	// Equalities are sets of hidden attributes, possibly in different credentials,
+	// that must be proven equal without revealing them
+	Equalities [][]AttributeRef
+	// Ranges are hidden attributes that must be proven to lie in a range without revealing them
+	Ranges []RangePredicate
+}
+
+// RangePredicate requires the holder to prove the hidden attribute
+// is an integer in [Lower, Upper]
+type RangePredicate struct {
+	Attribute    AttributeRef
+	Lower, Upper uint64
+}
+
+// NewPresentationRequest creates an empty request bound to `nonce`
+func NewPresentationRequest(nonce []byte) *PresentationRequest {
+	n := make([]byte, len(nonce))
+	copy(n, nonce)
+	return &PresentationRequest{Nonce: n}
+}
+
+// AddCredential appends a credential with `attributes` signed messages
+// to the request and returns its index for use in AttributeRef
+func (pr *PresentationRequest) AddCredential(label string, attributes int, revealed ...int) int {
+	r := make([]int, len(revealed))
+	copy(r, revealed)
+	pr.Credentials = append(pr.Credentials, CredentialRequest{
+		Label:      label,
+		Attributes: attributes,
+		Revealed:   r,
+	})
+	return len(pr.Credentials) - 1
+}
+
+// AddEquality requires the holder to prove all `refs` are the same hidden value
+func (pr *PresentationRequest) AddEquality(refs ...AttributeRef) *PresentationRequest {
+	r := make([]AttributeRef, len(refs))
+	copy(r, refs)
+	pr.Equalities = append(pr.Equalities, r)
+	return pr
+}
+
+// AddRange requires the holder to prove the hidden attribute `ref` lies in [lower, upper]
+func (pr *PresentationRequest) AddRange(ref AttributeRef, lower, upper uint64) *PresentationRequest {
+	pr.Ranges = append(pr.Ranges, RangePredicate{Attribute: ref, Lower: lower, Upper: upper})
+	return pr
+}
+
+// IsRevealed returns true if the referenced attribute must be disclosed
+func (pr PresentationRequest) IsRevealed(ref AttributeRef) bool {
+	if ref.Credential < 0 || ref.Credential >= len(pr.Credentials) {
+		return false
+	}
+	for _, i := range pr.Credentials[ref.Credential].Revealed {
+		if i == ref.Attribute {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the request is well-formed: attribute indices are in range,
+// revealed attributes are not repeated and every equality covers at least
+// two distinct hidden attributes with no attribute in more than one equality,
+// and every range is non-empty and references a hidden attribute
+func (pr PresentationRequest) Validate() error {
+	if len(pr.Nonce) == 0 {
+		return fmt.Errorf("nonce cannot be empty")
+	}
+	if len(pr.Credentials) == 0 {
+		return fmt.Errorf("at least one credential is required")
+	}
+	for i, c := range pr.Credentials {
+		if c.Attributes < 1 {
+			return fmt.Errorf("credential %d must have at least one attribute", i)
+		}
+		seen := make(map[int]bool, len(c.Revealed))
+		for _, a := range c.Revealed {
+			if a < 0 || a >= c.Attributes {
+				return fmt.Errorf("credential %d revealed attribute %d out of range", i, a)
+			}
+			if seen[a] {
+				return fmt.Errorf("credential %d revealed attribute %d is duplicated", i, a)
+			}
+			seen[a] = true
+		}
+	}
+	used := make(map[AttributeRef]bool)
+	for i, eq := range pr.Equalities {
+		if len(eq) < 2 {
+			return fmt.Errorf("equality %d must reference at least two attributes", i)
+		}
+		for _, ref := range eq {
+			if ref.Credential < 0 || ref.Credential >= len(pr.Credentials) {
+				return fmt.Errorf("equality %d references unknown credential %d", i, ref.Credential)
+			}
+			if ref.Attribute < 0 || ref.Attribute >= pr.Credentials[ref.Credential].Attributes {
+				return fmt.Errorf("equality %d attribute %d out of range", i, ref.Attribute)
+			}
+			if pr.IsRevealed(ref) {
+				return fmt.Errorf("equality %d references a revealed attribute", i)
+			}
+			if used[ref] {
+				return fmt.Errorf("attribute %v is used in more than one equality", ref)
+			}
+			used[ref] = true
+		}
+	}
+	for i, r := range pr.Ranges {
+		ref := r.Attribute
+		if ref.Credential < 0 || ref.Credential >= len(pr.Credentials) {
+			return fmt.Errorf("range %d references unknown credential %d", i, ref.Credential)
+		}
+		if ref.Attribute < 0 || ref.Attribute >= pr.Credentials[ref.Credential].Attributes {
+			return fmt.Errorf("range %d attribute %d out of range", i, ref.Attribute)
+		}
+		if pr.IsRevealed(ref) {
+			return fmt.Errorf("range %d references a revealed attribute", i)
+		}
+		if r.Lower > r.Upper {
+			return fmt.Errorf("range %d is empty", i)
+		}
+	}
+	return nil
+}
+
+// AddToTranscript binds the full request to a Fiat-Shamir transcript
+// so a presentation cannot be replayed against a different request
+func (pr PresentationRequest) AddToTranscript(transcript *merlin.Transcript) {
+	var t [4]byte
+	u32 := func(v int) []byte {
+		binary.BigEndian.PutUint32(t[:], uint32(v))
+		return t[:]
+	}
+	transcript.AppendMessage([]byte("nonce"), pr.Nonce)
+	transcript.AppendMessage([]byte("credentials"), u32(len(pr.Credentials)))
+	for _, c := range pr.Credentials {
+		revealed := make([]int, len(c.Revealed))
+		copy(revealed, c.Revealed)
+		sort.Ints(revealed)
+		transcript.AppendMessage([]byte("label"), []byte(c.Label))
+		transcript.AppendMessage([]byte("attributes"), u32(c.Attributes))
+		transcript.AppendMessage([]byte("revealed"), u32(len(revealed)))
+		for _, r := range revealed {
+			transcript.AppendMessage([]byte("index"), u32(r))
+		}
+	}
+	transcript.AppendMessage([]byte("equalities"), u32(len(pr.Equalities)))
+	for _, eq := range pr.Equalities {
+		transcript.AppendMessage([]byte("equality"), u32(len(eq)))
+		for _, ref := range eq {
+			transcript.AppendMessage([]byte("credential"), u32(ref.Credential))
+			transcript.AppendMessage([]byte("attribute"), u32(ref.Attribute))
+		}
+	}
+	var b [8]byte
+	transcript.AppendMessage([]byte("ranges"), u32(len(pr.Ranges)))
+	for _, r := range pr.Ranges {
+		transcript.AppendMessage([]byte("credential"), u32(r.Attribute.Credential))
+		transcript.AppendMessage([]byte("attribute"), u32(r.Attribute.Attribute))
+		binary.BigEndian.PutUint64(b[:], r.Lower)
+		transcript.AppendMessage([]byte("lower"), b[:])
+		binary.BigEndian.PutUint64(b[:], r.Upper)
+		transcript.AppendMessage([]byte("upper"), b[:])
+	}
+}
@@ -0,0 +1,242 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package ed448 implements the Ed448 signature algorithm of RFC 8032 section 5.2
+// on the ED448 curve of the curves package. Ed448 targets the 224-bit security level.
+//
+// As in ted25519, the private key includes the public key as a suffix and the
+// RFC 8032 private key is called the seed. Ed448ph is not supported
+package ed448
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of public keys as used in this package.
+	PublicKeySize = 57
+	// PrivateKeySize is the size, in bytes, of private keys as used in this package.
+	PrivateKeySize = 114
+	// SignatureSize is the size, in bytes, of signatures generated and verified by this package.
+	SignatureSize = 114
+	// SeedSize is the size, in bytes, of private key seeds. These are the private key representations used by RFC 8032.
+	SeedSize = 57
+	// MaxContextSize is the maximum length of a context string
+	MaxContextSize = 255
+)
+
+// PublicKey is the type of Ed448 public keys.
+type PublicKey []byte
+
+// PrivateKey is the type of Ed448 private keys. It implements crypto.Signer.
+type PrivateKey []byte
+
+// Options can be used with PrivateKey.Sign to sign with a context string
+type Options struct {
+	// Context is the context string of RFC 8032, at most 255 bytes
+	Context string
+}
+
+// HashFunc returns crypto.Hash(0), since Ed448 signs messages that have not been hashed
+func (o *Options) HashFunc() crypto.Hash {
+	return crypto.Hash(0)
+}
+
+// Bytes returns the publicKey in byte array
+func (p PublicKey) Bytes() []byte {
+	return p
+}
+
+// Public returns the PublicKey corresponding to priv.
+func (priv PrivateKey) Public() crypto.PublicKey {
+	publicKey := make([]byte, PublicKeySize)
+	copy(publicKey, priv[SeedSize:])
+	return PublicKey(publicKey)
+}
+
+// Seed returns the private key seed corresponding to priv. It is provided for
+// interoperability with RFC 8032. RFC 8032's private keys correspond to seeds
+// in this package.
+func (priv PrivateKey) Seed() []byte {
+	seed := make([]byte, SeedSize)
+	copy(seed, priv[:SeedSize])
+	return seed
+}
+
+// Sign signs the given message with priv. opts.HashFunc() must return zero, and
+// opts may be an *Options to set a context string
+func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, fmt.Errorf("ed448: cannot sign hashed message")
+	}
+	var context []byte
+	if o, ok := opts.(*Options); ok {
+		context = []byte(o.Context)
+	}
+	return SignWithContext(priv, message, context)
+}
+
+// GenerateKey generates a public/private key pair using entropy from rand.
+// If rand is nil, crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+
+	privateKey, err := NewKeyFromSeed(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey.Public().(PublicKey), privateKey, nil
+}
+
+// NewKeyFromSeed calculates a private key from a seed. This function is provided
+// for interoperability with RFC 8032. RFC 8032's private keys correspond to seeds
+// in this package.
+func NewKeyFromSeed(seed []byte) (PrivateKey, error) {
+	if l := len(seed); l != SeedSize {
+		return nil, fmt.Errorf("ed448: bad seed length: %d", l)
+	}
+	s, _, err := expandSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	A := curves.ED448().ScalarBaseMult(s)
+
+	privateKey := make([]byte, PrivateKeySize)
+	copy(privateKey, seed)
+	copy(privateKey[SeedSize:], A.ToAffineCompressed())
+	return privateKey, nil
+}
+
+// expandSeed returns the secret scalar and the nonce prefix derived from the seed
+func expandSeed(seed []byte) (curves.Scalar, []byte, error) {
+	digest := make([]byte, 2*SeedSize)
+	sha3.ShakeSum256(digest, seed)
+	digest[0] &= 252
+	digest[55] |= 128
+	digest[56] = 0
+
+	// The clamped integer exceeds the group order and is reduced
+	wide := make([]byte, 2*SeedSize)
+	copy(wide, digest[:SeedSize])
+	s, err := curves.ED448().Scalar.SetBytesWide(wide)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, digest[SeedSize:], nil
+}
+
+// dom4 returns the prefix of the hashes of Ed448 with the context string
+func dom4(context []byte) []byte {
+	out := make([]byte, 0, 10+len(context))
+	out = append(out, "SigEd448"...)
+	out = append(out, 0, byte(len(context)))
+	return append(out, context...)
+}
+
+// hashToScalar reduces SHAKE256(dom4(context) || inputs...) modulo the group order
+func hashToScalar(context []byte, inputs ...[]byte) (curves.Scalar, error) {
+	h := sha3.NewShake256()
+	_, _ = h.Write(dom4(context))
+	for _, input := range inputs {
+		_, _ = h.Write(input)
+	}
+	digest := make([]byte, 2*SeedSize)
+	_, _ = h.Read(digest)
+	return curves.ED448().Scalar.SetBytesWide(digest)
+}
+
+// Sign signs the message with privateKey and an empty context string and returns a signature.
+func Sign(privateKey PrivateKey, message []byte) ([]byte, error) {
+	return SignWithContext(privateKey, message, nil)
+}
+
+// SignWithContext signs the message with privateKey and the context string of at most
+// 255 bytes, which must also be given to VerifyWithContext
+func SignWithContext(privateKey PrivateKey, message, context []byte) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		return nil, fmt.Errorf("ed448: bad private key length: %d", l)
+	}
+	if l := len(context); l > MaxContextSize {
+		return nil, fmt.Errorf("ed448: bad context length: %d", l)
+	}
+	s, prefix, err := expandSeed(privateKey[:SeedSize])
+	if err != nil {
+		return nil, err
+	}
+	r, err := hashToScalar(context, prefix, message)
+	if err != nil {
+		return nil, err
+	}
+
+	// R = r * G
+	encodedR := curves.ED448().ScalarBaseMult(r).ToAffineCompressed()
+	k, err := hashToScalar(context, encodedR, privateKey[SeedSize:], message)
+	if err != nil {
+		return nil, err
+	}
+
+	// S = k*s + r
+	S := k.MulAdd(s, r)
+	signature := make([]byte, SignatureSize)
+	copy(signature, encodedR)
+	copy(signature[PublicKeySize:], S.Bytes())
+	return signature, nil
+}
+
+// Verify reports whether sig is a valid signature of message by publicKey with an empty context string.
+func Verify(publicKey PublicKey, message, sig []byte) (bool, error) {
+	return VerifyWithContext(publicKey, message, sig, nil)
+}
+
+// VerifyWithContext reports whether sig is a valid signature of message by publicKey
+// with the context string. It checks the cofactored equation [4][S]B = [4]R + [4][k]A
+func VerifyWithContext(publicKey PublicKey, message, sig, context []byte) (bool, error) {
+	if l := len(publicKey); l != PublicKeySize {
+		return false, fmt.Errorf("ed448: bad public key length: %d", l)
+	}
+	if l := len(sig); l != SignatureSize {
+		return false, fmt.Errorf("ed448: bad signature size: %d", l)
+	}
+	if l := len(context); l > MaxContextSize {
+		return false, fmt.Errorf("ed448: bad context length: %d", l)
+	}
+	ed448 := curves.ED448()
+	A, err := ed448.Point.FromAffineCompressed(publicKey)
+	if err != nil {
+		return false, err
+	}
+	R, err := ed448.Point.FromAffineCompressed(sig[:PublicKeySize])
+	if err != nil {
+		return false, err
+	}
+	// SetBytes rejects S >= l
+	S, err := ed448.Scalar.SetBytes(sig[PublicKeySize:])
+	if err != nil {
+		return false, err
+	}
+	k, err := hashToScalar(context, sig[:PublicKeySize], publicKey, message)
+	if err != nil {
+		return false, err
+	}
+
+	lhs := ed448.ScalarBaseMult(S).ClearCofactor()
+	rhs := R.Add(A.Mul(k)).ClearCofactor()
+	return lhs.Equal(rhs), nil
+}
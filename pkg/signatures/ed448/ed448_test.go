@@ -0,0 +1,102 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ed448
+
+import (
+	"crypto"
+	crand "crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRfc8032PublicKeys(t *testing.T) {
+	for seed, public := range map[string]string{
+		// -----blank and 1 octet of RFC 8032 section 7.4
+		"6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b": "5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180",
+		"c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e": "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+	} {
+		s, _ := hex.DecodeString(seed)
+		priv, err := NewKeyFromSeed(s)
+		require.NoError(t, err)
+		require.Equal(t, public, hex.EncodeToString(priv.Public().(PublicKey)))
+		require.Equal(t, s, priv.Seed())
+	}
+}
+
+// Signatures generated with OpenSSL
+var signatureVectors = []struct {
+	seed, public, message, signature string
+}{
+	{"176f546e18384fbd332609a0ced542c6aacdda0bf61520008db32f865394957f7a8d71ce023e8b68b3b83525ca53d826cc7533fe8dae5d10a8", "c8ed897cd08939f7278598cc3ef6ab7aad1d266c17bb928fa449a003093be058da43aebd6be2c29c770492f8930d76768edccce5ddbd8d5300", "6f5f8d8b8d2359392b702cb676e3885f093c22e94c1244dc1bd79f1c779a3493447860f618", "51849b60b168bd3a58aaa767053c1506c29e29060f0c0446d2dec9dc0bd9d5b9c4833bf2195a89ef2b2386e4c8a07629629b883ee0ca520880e677ab31b499c61c252ea67136e7e0b039081a512c1778109d29ccbd087963c9b5239be312fbc9c4d3a6734f7451a97d6b6686d5b6e3112e00"},
+	{"e13c2a7277d3e1acb5691183795182f06b177ceb62ee0cdf442d506e2de462725df547f64b80d99871790622c22973550de3e1c7a9f3833d6c", "f4866b23207f5320b9e607a85efcc5aae8d0d55ac859fcd8b347dcec4a8c6e1c36fa2866cde7d24f8fdd61932ba9c1a9eb30d79d76b9677d80", "c32284fce76b3dac56e93b354cbe98b424a0c4776f4cd9f85c5682f6ebf745db929a77a9dfbd8c3ece875ef62f86a0671453c60d9fcd3e04c6b19fbfe76fdc1cb97ae4b3882e3ede360c", "b5a202490ce14ad8fea78e43e19baa4b33b47fe4ef73adf2991f6984860329cf82e1c6ec00b8556809340df4b37ab30513554fd1e311c94d00ed9a4e651693f42becbc39c58fcb0274b92b31576e714d69b9bb1ab349dfc2daf1402e30d9a3feee930934dd202d156605f370736a23443200"},
+	{"4d6bde15f88c814426664f635daf1e0181db24cf889fa93a90a2939c6834a367b228631a298423412e4bf68f457005edcbba1e157cb8fa6f86", "134f0c92ef9f8b3141b2b94966ce93abf691527a095022fad1ce789dfa4eaca17eded95edbe77573b963dcb22635acb436cd3d598483e04980", "15d4ad44a53ae14f2c7b1409094a6329845cde5e497099ee9b1bff86f01808b8486c0c35d8982cc7aa777ee049cb65ae3bd1d11e82f097701dff0041be0ad093eb064fac78d3b3b1764c66b45ab73c01e121f583cff21b1c1d86c9e012ae2c90ae6e4272e6627d5e3b461e3289a891", "0e11e9a0e41950082025e654774546a3d0e8e8f7b17e9c866b017a47bdc5c41e424177ffee2eef5c8f14b77fb7420c096de6c44c33485df80033a20d7d14f3a3df1c72d13ed0dba988edb596cd61d0d88ab99cde2d9a54170236f16d5d4f4775b8eab9743ab46bb2b11de3f7b467f0123e00"},
+}
+
+func TestSignatureVectors(t *testing.T) {
+	for _, v := range signatureVectors {
+		seed, _ := hex.DecodeString(v.seed)
+		public, _ := hex.DecodeString(v.public)
+		message, _ := hex.DecodeString(v.message)
+		expected, _ := hex.DecodeString(v.signature)
+
+		priv, err := NewKeyFromSeed(seed)
+		require.NoError(t, err)
+		require.Equal(t, PublicKey(public), priv.Public())
+		sig, err := Sign(priv, message)
+		require.NoError(t, err)
+		require.Equal(t, expected, sig)
+
+		ok, err := Verify(public, message, sig)
+		require.NoError(t, err)
+		require.True(t, ok)
+		ok, err = Verify(public, message[1:], sig)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	public, private, err := GenerateKey(crand.Reader)
+	require.NoError(t, err)
+	message := []byte("test message")
+
+	sig, err := private.Sign(nil, message, crypto.Hash(0))
+	require.NoError(t, err)
+	ok, err := Verify(public, message, sig)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The context string is bound to the signature
+	sig, err = private.Sign(nil, message, &Options{Context: "context"})
+	require.NoError(t, err)
+	ok, err = VerifyWithContext(public, message, sig, []byte("context"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = Verify(public, message, sig)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, err = SignWithContext(private, message, make([]byte, MaxContextSize+1))
+	require.Error(t, err)
+
+	_, err = private.Sign(nil, message, crypto.SHA256)
+	require.Error(t, err)
+
+	// S must be reduced
+	bad := make([]byte, SignatureSize)
+	copy(bad, sig)
+	bad[SignatureSize-1] = 1
+	_, err = VerifyWithContext(public, message, bad, []byte("context"))
+	require.Error(t, err)
+	_, err = Verify(public[1:], message, sig)
+	require.Error(t, err)
+	_, err = Verify(public, message, sig[1:])
+	require.Error(t, err)
+	_, err = NewKeyFromSeed(make([]byte, SeedSize-1))
+	require.Error(t, err)
+}
@@ -0,0 +1,112 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gtank/merlin"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+// PokSignature a.k.a. Proof of Knowledge of a Signature
+// is used by the prover to convince a verifier
+// that they possess a valid signature and
+// can selectively disclose a set of signed messages
+type PokSignature struct {
+	// The signature randomized with r and t,
+	// sigma1' = sigma1 * r and sigma2' = (sigma2 + sigma1 * t) * r
+	sigma1, sigma2 curves.PairingPoint
+	// k = g~ * t + sum Y~_j * m_j for all undisclosed messages m_j
+	k curves.PairingPoint
+	// proof of knowledge of the opening of k
+	proof   *common.ProofCommittedBuilder
+	secrets []curves.Scalar
+}
+
+// NewPokSignature creates the initial proof data before a Fiat-Shamir calculation
+func NewPokSignature(sig *Signature, pk *PublicKey, msgs []common.ProofMessage, reader io.Reader) (*PokSignature, error) {
+	if err := pk.valid(); err != nil {
+		return nil, err
+	}
+	if sig == nil || sig.sigma1 == nil || sig.sigma2 == nil || sig.sigma1.IsIdentity() {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if len(msgs) != len(pk.y) {
+		return nil, fmt.Errorf("mismatch messages and public key")
+	}
+
+	sc := sig.sigma1.Scalar()
+	r := getNonZeroScalar(sc, reader)
+	t := getNonZeroScalar(sc, reader)
+	sigma1, ok := sig.sigma1.Mul(r).(curves.PairingPoint)
+	if !ok {
+		return nil, fmt.Errorf("invalid point")
+	}
+	sigma2, ok := sig.sigma2.Mul(r).Add(sigma1.Mul(t)).(curves.PairingPoint)
+	if !ok {
+		return nil, fmt.Errorf("invalid point")
+	}
+
+	curve := curves.Curve{
+		Scalar: sc.Zero(),
+		Point:  pk.x.Identity(),
+	}
+	proof := common.NewProofCommittedBuilder(&curve)
+	g := pk.x.Generator()
+	if err := proof.CommitRandom(g, reader); err != nil {
+		return nil, err
+	}
+	points := []curves.Point{g}
+	secrets := []curves.Scalar{t}
+	for i, m := range msgs {
+		if m.IsHidden() {
+			if err := proof.Commit(pk.y[i], m.GetBlinding(reader)); err != nil {
+				return nil, err
+			}
+			points = append(points, pk.y[i])
+			secrets = append(secrets, m.GetMessage())
+		}
+	}
+	k, ok := g.SumOfProducts(points, secrets).(curves.PairingPoint)
+	if !ok {
+		return nil, fmt.Errorf("invalid point")
+	}
+	return &PokSignature{
+		sigma1:  sigma1,
+		sigma2:  sigma2,
+		k:       k,
+		proof:   proof,
+		secrets: secrets,
+	}, nil
+}
+
+// GetChallengeContribution returns the bytes that should be added to
+// a sigma protocol transcript for generating the challenge
+func (pok *PokSignature) GetChallengeContribution(transcript *merlin.Transcript) {
+	transcript.AppendMessage([]byte("sigma1"), pok.sigma1.ToAffineCompressed())
+	transcript.AppendMessage([]byte("sigma2"), pok.sigma2.ToAffineCompressed())
+	transcript.AppendMessage([]byte("K"), pok.k.ToAffineCompressed())
+	transcript.AppendMessage([]byte("Proof"), pok.proof.GetChallengeContribution())
+}
+
+// GenerateProof converts the blinding factors and secrets into Schnorr proofs
+func (pok *PokSignature) GenerateProof(challenge curves.Scalar) (*PokSignatureProof, error) {
+	proof, err := pok.proof.GenerateProof(challenge, pok.secrets)
+	if err != nil {
+		return nil, err
+	}
+	return &PokSignatureProof{
+		sigma1: pok.sigma1,
+		sigma2: pok.sigma2,
+		k:      pok.k,
+		proof:  proof,
+	}, nil
+}
@@ -0,0 +1,179 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	"fmt"
+
+	"github.com/gtank/merlin"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+// PokSignatureProof is the actual proof sent from a prover
+// to a verifier that contains a proof of knowledge of a signature
+// and the selective disclosure proof
+type PokSignatureProof struct {
+	sigma1, sigma2, k curves.PairingPoint
+	// The responses for t followed by the undisclosed messages
+	proof []curves.Scalar
+}
+
+// Init creates an empty proof for a specific curve
+// which should be followed by UnmarshalBinary
+func (pok *PokSignatureProof) Init(curve *curves.PairingCurve) *PokSignatureProof {
+	pok.sigma1 = curve.NewG1IdentityPoint()
+	pok.sigma2 = pok.sigma1
+	pok.k = curve.NewG2IdentityPoint()
+	pok.proof = []curves.Scalar{curve.NewScalar()}
+	return pok
+}
+
+// MarshalBinary returns sigma1 || sigma2 || K || t^ || m^_1 || ... || m^_U
+func (pok PokSignatureProof) MarshalBinary() ([]byte, error) {
+	if pok.sigma1 == nil || pok.sigma2 == nil || pok.k == nil || len(pok.proof) == 0 {
+		return nil, fmt.Errorf("invalid proof")
+	}
+	data := append(pok.sigma1.ToAffineCompressed(), pok.sigma2.ToAffineCompressed()...)
+	data = append(data, pok.k.ToAffineCompressed()...)
+	for _, p := range pok.proof {
+		data = append(data, p.Bytes()...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary reads a proof written by MarshalBinary,
+// the proof must have been created with Init
+func (pok *PokSignatureProof) UnmarshalBinary(in []byte) error {
+	if pok.sigma1 == nil || pok.k == nil || len(pok.proof) == 0 {
+		return fmt.Errorf("proof is not initialized")
+	}
+	g1Size := len(pok.sigma1.ToAffineCompressed())
+	g2Size := len(pok.k.ToAffineCompressed())
+	scSize := len(pok.proof[0].Bytes())
+	if len(in) < 2*g1Size+g2Size+scSize || (len(in)-2*g1Size-g2Size)%scSize != 0 {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	points := make([]curves.PairingPoint, 3)
+	offsets := []int{0, g1Size, 2 * g1Size, 2*g1Size + g2Size}
+	templates := []curves.PairingPoint{pok.sigma1, pok.sigma1, pok.k}
+	for i := range points {
+		p, err := templates[i].FromAffineCompressed(in[offsets[i]:offsets[i+1]])
+		if err != nil {
+			return err
+		}
+		var ok bool
+		points[i], ok = p.(curves.PairingPoint)
+		if !ok {
+			return fmt.Errorf("incorrect type conversion")
+		}
+	}
+	in = in[offsets[3]:]
+	proof := make([]curves.Scalar, len(in)/scSize)
+	for i := range proof {
+		var err error
+		proof[i], err = pok.proof[0].SetBytes(in[i*scSize : (i+1)*scSize])
+		if err != nil {
+			return err
+		}
+	}
+	pok.sigma1, pok.sigma2, pok.k = points[0], points[1], points[2]
+	pok.proof = proof
+	return nil
+}
+
+// GetChallengeContribution converts the committed values to bytes
+// for the Fiat-Shamir challenge
+func (pok PokSignatureProof) GetChallengeContribution(
+	pk *PublicKey,
+	revealedMessages map[int]curves.Scalar,
+	challenge common.Challenge,
+	transcript *merlin.Transcript,
+) {
+	transcript.AppendMessage([]byte("sigma1"), pok.sigma1.ToAffineCompressed())
+	transcript.AppendMessage([]byte("sigma2"), pok.sigma2.ToAffineCompressed())
+	transcript.AppendMessage([]byte("K"), pok.k.ToAffineCompressed())
+
+	// g~ * t^ + sum Y~_j * m^_j - K * c
+	points := []curves.Point{pok.k, pok.k.Generator()}
+	scalars := []curves.Scalar{challenge.Neg(), pok.proof[0]}
+	j := 1
+	for i, y := range pk.y {
+		if _, revealed := revealedMessages[i]; revealed {
+			continue
+		}
+		if j < len(pok.proof) {
+			points = append(points, y)
+			scalars = append(scalars, pok.proof[j])
+		}
+		j++
+	}
+	commitment := pok.k.SumOfProducts(points, scalars)
+	transcript.AppendMessage([]byte("Proof"), commitment.ToAffineCompressed())
+}
+
+// VerifySigPok only validates the signature proof,
+// the selective disclosure proof is checked by
+// verifying
+// pok.challenge == computedChallenge
+func (pok PokSignatureProof) VerifySigPok(pk *PublicKey, revealedMessages map[int]curves.Scalar) bool {
+	if pk.valid() != nil || pok.sigma1.IsIdentity() {
+		return false
+	}
+	if len(pok.proof) != 1+len(pk.y)-len(revealedMessages) {
+		return false
+	}
+	// e(sigma1', X~ + sum Y~_i * m_i + K) == e(sigma2', g~) over the disclosed messages m_i
+	points := []curves.Point{pk.x, pok.k}
+	scalars := []curves.Scalar{pok.proof[0].One(), pok.proof[0].One()}
+	for i, m := range revealedMessages {
+		if i < 0 || i >= len(pk.y) {
+			return false
+		}
+		points = append(points, pk.y[i])
+		scalars = append(scalars, m)
+	}
+	return pairingCheck(pok.sigma1, pk.x.SumOfProducts(points, scalars), pok.sigma2)
+}
+
+// Verify checks a signature proof of knowledge and selective disclosure proof
+func (pok PokSignatureProof) Verify(
+	revealedMsgs map[int]curves.Scalar,
+	pk *PublicKey,
+	nonce common.Nonce,
+	challenge common.Challenge,
+	transcript *merlin.Transcript,
+) bool {
+	if !pok.VerifySigPok(pk, revealedMsgs) {
+		return false
+	}
+	pok.GetChallengeContribution(pk, revealedMsgs, challenge, transcript)
+	transcript.AppendMessage([]byte("nonce"), nonce.Bytes())
+	okm := transcript.ExtractBytes([]byte("signature proof of knowledge"), 64)
+	vChallenge, err := pok.proof[0].SetBytesWide(okm)
+	if err != nil {
+		return false
+	}
+	return challenge.Cmp(vChallenge) == 0
+}
+
+// hiddenResponse returns the Schnorr response of the undisclosed message at `index`,
+// or nil if there is none
+func (pok PokSignatureProof) hiddenResponse(index int, revealedMsgs map[int]curves.Scalar) curves.Scalar {
+	// The first response is for t
+	j := 1
+	for i := 0; i < index; i++ {
+		if _, contains := revealedMsgs[i]; !contains {
+			j++
+		}
+	}
+	if j >= len(pok.proof) {
+		return nil
+	}
+	return pok.proof[j]
+}
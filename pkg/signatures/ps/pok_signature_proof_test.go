@@ -0,0 +1,111 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+// proveSignature creates a proof which reveals the messages at `revealed`
+func proveSignature(t *testing.T, curve *curves.PairingCurve, sig *Signature, pk *PublicKey, msgs []curves.Scalar,
+	nonce common.Nonce, revealed ...int) (*PokSignatureProof, common.Challenge, map[int]curves.Scalar) {
+	proofMsgs := make([]common.ProofMessage, len(msgs))
+	revealedMsgs := make(map[int]curves.Scalar)
+	for i, m := range msgs {
+		proofMsgs[i] = &common.ProofSpecificMessage{Message: m}
+	}
+	for _, i := range revealed {
+		proofMsgs[i] = &common.RevealedMessage{Message: msgs[i]}
+		revealedMsgs[i] = msgs[i]
+	}
+	pok, err := NewPokSignature(sig, pk, proofMsgs, crand.Reader)
+	require.NoError(t, err)
+	transcript := merlin.NewTranscript("TestPokSignatureProof")
+	pok.GetChallengeContribution(transcript)
+	transcript.AppendMessage([]byte("nonce"), nonce.Bytes())
+	okm := transcript.ExtractBytes([]byte("signature proof of knowledge"), 64)
+	challenge, err := curve.Scalar.SetBytesWide(okm)
+	require.NoError(t, err)
+	proof, err := pok.GenerateProof(challenge)
+	require.NoError(t, err)
+	return proof, challenge, revealedMsgs
+}
+
+func TestPokSignatureProofWorks(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	msgs := testMessages(curve)
+	pk, sk, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+	nonce := curve.Scalar.Random(crand.Reader)
+
+	for _, revealed := range [][]int{{2, 3}, {}, {0, 1, 2, 3}} {
+		proof, challenge, revealedMsgs := proveSignature(t, curve, sig, pk, msgs, nonce, revealed...)
+		require.True(t, proof.VerifySigPok(pk, revealedMsgs))
+		transcript := merlin.NewTranscript("TestPokSignatureProof")
+		require.True(t, proof.Verify(revealedMsgs, pk, nonce, challenge, transcript))
+
+		// Another nonce or disclosed message must not verify
+		transcript = merlin.NewTranscript("TestPokSignatureProof")
+		require.False(t, proof.Verify(revealedMsgs, pk, curve.Scalar.Random(crand.Reader), challenge, transcript))
+		if len(revealed) > 0 {
+			revealedMsgs[revealed[0]] = curve.Scalar.New(100)
+			transcript = merlin.NewTranscript("TestPokSignatureProof")
+			require.False(t, proof.Verify(revealedMsgs, pk, nonce, challenge, transcript))
+		}
+	}
+}
+
+func TestPokSignatureProofUnlinkable(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	msgs := testMessages(curve)
+	pk, sk, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+	nonce := curve.Scalar.Random(crand.Reader)
+
+	proof1, _, _ := proveSignature(t, curve, sig, pk, msgs, nonce, 0)
+	proof2, _, _ := proveSignature(t, curve, sig, pk, msgs, nonce, 0)
+	require.False(t, proof1.sigma1.Equal(proof2.sigma1))
+	require.False(t, proof1.sigma1.Equal(sig.sigma1))
+
+	// A proof for a forged signature must not verify
+	forged := &Signature{
+		sigma1: sig.sigma1,
+		sigma2: curve.NewG1GeneratorPoint(),
+	}
+	proof, challenge, revealedMsgs := proveSignature(t, curve, forged, pk, msgs, nonce, 1)
+	require.False(t, proof.Verify(revealedMsgs, pk, nonce, challenge, merlin.NewTranscript("TestPokSignatureProof")))
+}
+
+func TestPokSignatureProofMarshalBinary(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	msgs := testMessages(curve)
+	pk, sk, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+	nonce := curve.Scalar.Random(crand.Reader)
+	proof, challenge, revealedMsgs := proveSignature(t, curve, sig, pk, msgs, nonce, 1, 3)
+
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, 48*2+96+32*3, len(data))
+	proof2 := new(PokSignatureProof).Init(curve)
+	require.NoError(t, proof2.UnmarshalBinary(data))
+	require.True(t, proof2.Verify(revealedMsgs, pk, nonce, challenge, merlin.NewTranscript("TestPokSignatureProof")))
+	require.Error(t, new(PokSignatureProof).Init(curve).UnmarshalBinary(data[:len(data)-1]))
+}
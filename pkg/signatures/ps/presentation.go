@@ -0,0 +1,319 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gtank/merlin"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+const presentationTranscriptLabel = "ps presentation"
+
+// Credential is a signature held by a prover
+// along with the issuer's public key and the messages that were signed
+type Credential struct {
+	Signature *Signature
+	PublicKey *PublicKey
+	Messages  []curves.Scalar
+}
+
+// Presentation is the response from a holder to a common.PresentationRequest.
+// It contains one proof of knowledge per requested credential, the disclosed
+// messages and the Fiat-Shamir challenge shared by all proofs.
+// Range predicates are not supported and requests with ranges are rejected
+type Presentation struct {
+	Challenge common.Challenge
+	Proofs    []*PokSignatureProof
+	Revealed  []map[int]curves.Scalar
+	curve     *curves.PairingCurve
+}
+
+// NewPresentation builds a presentation that satisfies `request` using `credentials`
+// which must be in the same order as request.Credentials
+func NewPresentation(request *common.PresentationRequest, credentials []*Credential, reader io.Reader) (*Presentation, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if len(request.Ranges) != 0 {
+		return nil, fmt.Errorf("range predicates are not supported")
+	}
+	if len(credentials) != len(request.Credentials) {
+		return nil, fmt.Errorf("expected %d credentials, got %d", len(request.Credentials), len(credentials))
+	}
+	for i, c := range credentials {
+		if c == nil || c.Signature == nil || c.PublicKey == nil {
+			return nil, fmt.Errorf("credential %d is incomplete", i)
+		}
+		if len(c.Messages) != request.Credentials[i].Attributes {
+			return nil, fmt.Errorf("credential %d has %d messages, expected %d", i, len(c.Messages), request.Credentials[i].Attributes)
+		}
+	}
+
+	// Attributes proven equal share a blinding factor so their
+	// Schnorr responses are identical when the messages are equal
+	blindings := make(map[common.AttributeRef]curves.Scalar)
+	for _, eq := range request.Equalities {
+		first := credentials[eq[0].Credential].Messages[eq[0].Attribute]
+		blinding := first.Random(reader)
+		for _, ref := range eq {
+			if credentials[ref.Credential].Messages[ref.Attribute].Cmp(first) != 0 {
+				return nil, fmt.Errorf("attribute %v does not satisfy the equality", ref)
+			}
+			blindings[ref] = blinding
+		}
+	}
+
+	transcript := merlin.NewTranscript(presentationTranscriptLabel)
+	request.AddToTranscript(transcript)
+
+	poks := make([]*PokSignature, len(credentials))
+	revealed := make([]map[int]curves.Scalar, len(credentials))
+	for i, c := range credentials {
+		revealed[i] = make(map[int]curves.Scalar, len(request.Credentials[i].Revealed))
+		msgs := make([]common.ProofMessage, len(c.Messages))
+		for j, m := range c.Messages {
+			ref := common.AttributeRef{Credential: i, Attribute: j}
+			switch b, shared := blindings[ref]; {
+			case request.IsRevealed(ref):
+				msgs[j] = common.RevealedMessage{Message: m}
+				revealed[i][j] = m
+			case shared:
+				msgs[j] = common.SharedBlindingMessage{Message: m, Blinding: b}
+			default:
+				msgs[j] = common.ProofSpecificMessage{Message: m}
+			}
+		}
+		pok, err := NewPokSignature(c.Signature, c.PublicKey, msgs, reader)
+		if err != nil {
+			return nil, err
+		}
+		pok.GetChallengeContribution(transcript)
+		poks[i] = pok
+	}
+
+	okm := transcript.ExtractBytes([]byte("presentation challenge"), 64)
+	challenge, err := credentials[0].Signature.sigma1.Scalar().SetBytesWide(okm)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*PokSignatureProof, len(poks))
+	for i, pok := range poks {
+		if proofs[i], err = pok.GenerateProof(challenge); err != nil {
+			return nil, err
+		}
+	}
+	return &Presentation{
+		Challenge: challenge,
+		Proofs:    proofs,
+		Revealed:  revealed,
+	}, nil
+}
+
+// Verify checks the presentation answers `request` for credentials issued under `keys`
+// in the same order as request.Credentials
+func (p Presentation) Verify(request *common.PresentationRequest, keys []*PublicKey) error {
+	if request == nil || p.Challenge == nil {
+		return fmt.Errorf("invalid arguments")
+	}
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	if len(request.Ranges) != 0 {
+		return fmt.Errorf("range predicates are not supported")
+	}
+	n := len(request.Credentials)
+	if len(p.Proofs) != n || len(p.Revealed) != n || len(keys) != n {
+		return fmt.Errorf("presentation does not match the request")
+	}
+
+	transcript := merlin.NewTranscript(presentationTranscriptLabel)
+	request.AddToTranscript(transcript)
+	for i, cr := range request.Credentials {
+		if keys[i] == nil || p.Proofs[i] == nil {
+			return fmt.Errorf("credential %d is incomplete", i)
+		}
+		if keys[i].Length() != cr.Attributes {
+			return fmt.Errorf("credential %d public key does not match the request", i)
+		}
+		if len(p.Revealed[i]) != len(cr.Revealed) {
+			return fmt.Errorf("credential %d revealed messages do not match the request", i)
+		}
+		for _, idx := range cr.Revealed {
+			if _, ok := p.Revealed[i][idx]; !ok {
+				return fmt.Errorf("credential %d is missing revealed message %d", i, idx)
+			}
+		}
+		if len(p.Proofs[i].proof) != 1+cr.Attributes-len(cr.Revealed) {
+			return fmt.Errorf("credential %d proof has the wrong number of responses", i)
+		}
+		p.Proofs[i].GetChallengeContribution(keys[i], p.Revealed[i], p.Challenge, transcript)
+	}
+
+	okm := transcript.ExtractBytes([]byte("presentation challenge"), 64)
+	vChallenge, err := p.Challenge.SetBytesWide(okm)
+	if err != nil {
+		return err
+	}
+	if p.Challenge.Cmp(vChallenge) != 0 {
+		return fmt.Errorf("invalid presentation")
+	}
+	for i, proof := range p.Proofs {
+		if !proof.VerifySigPok(keys[i], p.Revealed[i]) {
+			return fmt.Errorf("invalid signature proof for credential %d", i)
+		}
+	}
+	for i, eq := range request.Equalities {
+		first := p.Proofs[eq[0].Credential].hiddenResponse(eq[0].Attribute, p.Revealed[eq[0].Credential])
+		for _, ref := range eq[1:] {
+			r := p.Proofs[ref.Credential].hiddenResponse(ref.Attribute, p.Revealed[ref.Credential])
+			if first.Cmp(r) != 0 {
+				return fmt.Errorf("equality %d is not satisfied", i)
+			}
+		}
+	}
+	return nil
+}
+
+// Init creates an empty presentation for a specific curve
+// which should be followed by UnmarshalBinary
+func (p *Presentation) Init(curve *curves.PairingCurve) *Presentation {
+	p.Challenge = curve.Scalar.Zero()
+	p.Proofs = nil
+	p.Revealed = nil
+	p.curve = curve
+	return p
+}
+
+// MarshalBinary encodes the presentation as
+// challenge || count || (len || proof || revealed count || (index || message)*)*
+// where all counts, lengths and indices are big-endian uint32 values
+// and revealed messages are ordered by index
+func (p Presentation) MarshalBinary() ([]byte, error) {
+	if p.Challenge == nil || len(p.Proofs) != len(p.Revealed) {
+		return nil, fmt.Errorf("invalid presentation")
+	}
+	out := append([]byte{}, p.Challenge.Bytes()...)
+	out = appendUint32(out, len(p.Proofs))
+	for i, proof := range p.Proofs {
+		if proof == nil {
+			return nil, fmt.Errorf("invalid presentation")
+		}
+		data, err := proof.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		out = appendUint32(out, len(data))
+		out = append(out, data...)
+		indices := make([]int, 0, len(p.Revealed[i]))
+		for idx := range p.Revealed[i] {
+			if idx < 0 {
+				return nil, fmt.Errorf("invalid revealed message index")
+			}
+			indices = append(indices, idx)
+		}
+		sort.Ints(indices)
+		out = appendUint32(out, len(indices))
+		for _, idx := range indices {
+			out = appendUint32(out, idx)
+			out = append(out, p.Revealed[i][idx].Bytes()...)
+		}
+	}
+	return out, nil
+}
+
+// UnmarshalBinary decodes the output of MarshalBinary,
+// the presentation must have been created with Init
+func (p *Presentation) UnmarshalBinary(in []byte) error {
+	if p.curve == nil {
+		return fmt.Errorf("presentation is not initialized")
+	}
+	scSize := len(p.curve.Scalar.Bytes())
+	if len(in) < scSize+4 {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	challenge, err := p.curve.Scalar.SetBytes(in[:scSize])
+	if err != nil {
+		return err
+	}
+	in = in[scSize:]
+	count, in, err := readUint32(in)
+	if err != nil {
+		return err
+	}
+	if count > len(in) {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	proofs := make([]*PokSignatureProof, count)
+	revealed := make([]map[int]curves.Scalar, count)
+	for i := 0; i < count; i++ {
+		var l int
+		if l, in, err = readUint32(in); err != nil {
+			return err
+		}
+		if l > len(in) {
+			return fmt.Errorf("invalid byte sequence")
+		}
+		proofs[i] = new(PokSignatureProof).Init(p.curve)
+		if err = proofs[i].UnmarshalBinary(in[:l]); err != nil {
+			return err
+		}
+		in = in[l:]
+
+		var r int
+		if r, in, err = readUint32(in); err != nil {
+			return err
+		}
+		if r*(4+scSize) > len(in) {
+			return fmt.Errorf("invalid byte sequence")
+		}
+		revealed[i] = make(map[int]curves.Scalar, r)
+		for j := 0; j < r; j++ {
+			var idx int
+			if idx, in, err = readUint32(in); err != nil {
+				return err
+			}
+			if _, ok := revealed[i][idx]; ok {
+				return fmt.Errorf("duplicate revealed message")
+			}
+			if revealed[i][idx], err = p.curve.Scalar.SetBytes(in[:scSize]); err != nil {
+				return err
+			}
+			in = in[scSize:]
+		}
+	}
+	if len(in) != 0 {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	p.Challenge = challenge
+	p.Proofs = proofs
+	p.Revealed = revealed
+	return nil
+}
+
+func appendUint32(out []byte, v int) []byte {
+	var t [4]byte
+	binary.BigEndian.PutUint32(t[:], uint32(v))
+	return append(out, t[:]...)
+}
+
+func readUint32(in []byte) (int, []byte, error) {
+	if len(in) < 4 {
+		return 0, nil, fmt.Errorf("invalid byte sequence")
+	}
+	return int(binary.BigEndian.Uint32(in[:4])), in[4:], nil
+}
@@ -0,0 +1,112 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/common"
+)
+
+func issueTestCredential(t *testing.T, curve *curves.PairingCurve, msgs []curves.Scalar) *Credential {
+	pk, sk, err := NewKeys(curve, len(msgs))
+	require.NoError(t, err)
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+	return &Credential{
+		Signature: sig,
+		PublicKey: pk,
+		Messages:  msgs,
+	}
+}
+
+func TestPresentationWorks(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	// The holder's id is the first attribute of both credentials
+	id := curve.Scalar.Hash([]byte("holder"))
+	cred1 := issueTestCredential(t, curve, []curves.Scalar{id, curve.Scalar.New(2), curve.Scalar.New(3)})
+	cred2 := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(4), id})
+
+	request := common.NewPresentationRequest([]byte("verifier nonce"))
+	c1 := request.AddCredential("passport", 3, 1)
+	c2 := request.AddCredential("membership", 2, 0)
+	request.AddEquality(
+		common.AttributeRef{Credential: c1, Attribute: 0},
+		common.AttributeRef{Credential: c2, Attribute: 1},
+	)
+	require.NoError(t, request.Validate())
+
+	presentation, err := NewPresentation(request, []*Credential{cred1, cred2}, crand.Reader)
+	require.NoError(t, err)
+	require.Equal(t, 0, presentation.Revealed[0][1].Cmp(curve.Scalar.New(2)))
+	require.Equal(t, 0, presentation.Revealed[1][0].Cmp(curve.Scalar.New(4)))
+
+	keys := []*PublicKey{cred1.PublicKey, cred2.PublicKey}
+	require.NoError(t, presentation.Verify(request, keys))
+
+	// Serialization round trip
+	data, err := presentation.MarshalBinary()
+	require.NoError(t, err)
+	decoded := new(Presentation).Init(curve)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.NoError(t, decoded.Verify(request, keys))
+	require.Error(t, new(Presentation).Init(curve).UnmarshalBinary(data[:len(data)-1]))
+
+	// A different nonce must not verify
+	other := *request
+	other.Nonce = []byte("another nonce")
+	require.Error(t, presentation.Verify(&other, keys))
+
+	// Swapped keys must not verify
+	require.Error(t, presentation.Verify(request, []*PublicKey{cred2.PublicKey, cred1.PublicKey}))
+
+	// Tampering with a disclosed message must not verify
+	presentation.Revealed[0][1] = curve.Scalar.New(5)
+	require.Error(t, presentation.Verify(request, keys))
+}
+
+func TestPresentationEqualityFails(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	cred1 := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(1), curve.Scalar.New(2)})
+	cred2 := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(3), curve.Scalar.New(4)})
+
+	request := common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2)
+	request.AddCredential("b", 2)
+	request.AddEquality(
+		common.AttributeRef{Credential: 0, Attribute: 0},
+		common.AttributeRef{Credential: 1, Attribute: 0},
+	)
+	_, err := NewPresentation(request, []*Credential{cred1, cred2}, crand.Reader)
+	require.Error(t, err)
+
+	// A presentation made without the equality must not satisfy a request that has it
+	loose := common.NewPresentationRequest([]byte("nonce"))
+	loose.AddCredential("a", 2)
+	loose.AddCredential("b", 2)
+	presentation, err := NewPresentation(loose, []*Credential{cred1, cred2}, crand.Reader)
+	require.NoError(t, err)
+	keys := []*PublicKey{cred1.PublicKey, cred2.PublicKey}
+	require.NoError(t, presentation.Verify(loose, keys))
+	require.Error(t, presentation.Verify(request, keys))
+}
+
+func TestPresentationRangeUnsupported(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	cred := issueTestCredential(t, curve, []curves.Scalar{curve.Scalar.New(30), curve.Scalar.New(2)})
+
+	request := common.NewPresentationRequest([]byte("nonce"))
+	request.AddCredential("a", 2)
+	request.AddRange(common.AttributeRef{Credential: 0, Attribute: 0}, 18, 65)
+	require.NoError(t, request.Validate())
+	_, err := NewPresentation(request, []*Credential{cred}, crand.Reader)
+	require.Error(t, err)
+}
@@ -0,0 +1,119 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	"fmt"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// PublicKey is a PS verification key (X~, Y~_1, ..., Y~_n) in G2
+type PublicKey struct {
+	x curves.PairingPoint
+	y []curves.PairingPoint
+}
+
+// Init creates an empty public key for a specific curve
+// which should be followed by UnmarshalBinary
+func (pk *PublicKey) Init(curve *curves.PairingCurve) *PublicKey {
+	pk.x = curve.NewG2IdentityPoint()
+	pk.y = nil
+	return pk
+}
+
+// Length returns the number of messages signed under the key
+func (pk PublicKey) Length() int {
+	return len(pk.y)
+}
+
+// MarshalBinary returns X~ || Y~_1 || ... || Y~_n with compressed points
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	if pk.x == nil || len(pk.y) == 0 {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	out := pk.x.ToAffineCompressed()
+	for _, y := range pk.y {
+		out = append(out, y.ToAffineCompressed()...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary reads a public key written by MarshalBinary,
+// the public key must have been created with Init
+func (pk *PublicKey) UnmarshalBinary(in []byte) error {
+	if pk.x == nil {
+		return fmt.Errorf("public key is not initialized")
+	}
+	ptSize := len(pk.x.ToAffineCompressed())
+	if len(in) < 2*ptSize || len(in)%ptSize != 0 {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	points := make([]curves.PairingPoint, len(in)/ptSize)
+	for i := range points {
+		p, err := pk.x.FromAffineCompressed(in[i*ptSize : (i+1)*ptSize])
+		if err != nil {
+			return err
+		}
+		var ok bool
+		points[i], ok = p.(curves.PairingPoint)
+		if !ok || p.IsIdentity() {
+			return fmt.Errorf("invalid public key")
+		}
+	}
+	pk.x = points[0]
+	pk.y = points[1:]
+	return nil
+}
+
+// Verify checks a signature where all messages are known to the verifier
+func (pk PublicKey) Verify(sig *Signature, msgs []curves.Scalar) error {
+	if err := pk.valid(); err != nil {
+		return err
+	}
+	if sig == nil || sig.sigma1 == nil || sig.sigma2 == nil || sig.sigma1.IsIdentity() {
+		return fmt.Errorf("invalid signature")
+	}
+	if len(msgs) != len(pk.y) {
+		return fmt.Errorf("expected %d messages, got %d", len(pk.y), len(msgs))
+	}
+	points := make([]curves.Point, len(msgs)+1)
+	scalars := make([]curves.Scalar, len(msgs)+1)
+	points[0] = pk.x
+	scalars[0] = msgs[0].One()
+	for i, m := range msgs {
+		points[i+1] = pk.y[i]
+		scalars[i+1] = m
+	}
+	if !pairingCheck(sig.sigma1, pk.x.SumOfProducts(points, scalars), sig.sigma2) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// valid returns an error if the key has an identity point or no messages
+func (pk PublicKey) valid() error {
+	if pk.x == nil || len(pk.y) == 0 || pk.x.IsIdentity() {
+		return fmt.Errorf("invalid public key")
+	}
+	for _, y := range pk.y {
+		if y == nil || y.IsIdentity() {
+			return fmt.Errorf("invalid public key")
+		}
+	}
+	return nil
+}
+
+// pairingCheck returns true if e(a1, b1) == e(a2, g~) where g~ is the generator of G2
+func pairingCheck(a1 curves.PairingPoint, b1 curves.Point, a2 curves.PairingPoint) bool {
+	p, ok := b1.(curves.PairingPoint)
+	if !ok {
+		return false
+	}
+	g := p.Generator().Neg().(curves.PairingPoint)
+	return a1.MultiPairing(a1, p, a2, g).IsOne()
+}
@@ -0,0 +1,127 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	crand "crypto/rand"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// SecretKey is a PS signing key (x, y_1, ..., y_n) for vectors of n messages
+type SecretKey struct {
+	x curves.Scalar
+	y []curves.Scalar
+}
+
+// NewKeys creates a key pair for signing vectors of `length` messages
+func NewKeys(curve *curves.PairingCurve, length int) (*PublicKey, *SecretKey, error) {
+	if curve == nil {
+		return nil, nil, fmt.Errorf("invalid curve")
+	}
+	if length < 1 {
+		return nil, nil, fmt.Errorf("length must be at least 1")
+	}
+	sk := &SecretKey{
+		x: getNonZeroScalar(curve.Scalar, crand.Reader),
+		y: make([]curves.Scalar, length),
+	}
+	for i := range sk.y {
+		sk.y[i] = getNonZeroScalar(curve.Scalar, crand.Reader)
+	}
+	return sk.PublicKey(curve), sk, nil
+}
+
+// Init creates an empty secret key for a specific curve
+// which should be followed by UnmarshalBinary
+func (sk *SecretKey) Init(curve *curves.PairingCurve) *SecretKey {
+	sk.x = curve.NewScalar()
+	sk.y = nil
+	return sk
+}
+
+// MarshalBinary returns x || y_1 || ... || y_n
+func (sk SecretKey) MarshalBinary() ([]byte, error) {
+	if sk.x == nil || len(sk.y) == 0 {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	out := append([]byte{}, sk.x.Bytes()...)
+	for _, y := range sk.y {
+		out = append(out, y.Bytes()...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary reads a secret key written by MarshalBinary,
+// the secret key must have been created with Init
+func (sk *SecretKey) UnmarshalBinary(in []byte) error {
+	if sk.x == nil {
+		return fmt.Errorf("secret key is not initialized")
+	}
+	scSize := len(sk.x.Bytes())
+	if len(in) < 2*scSize || len(in)%scSize != 0 {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	scalars := make([]curves.Scalar, len(in)/scSize)
+	for i := range scalars {
+		var err error
+		scalars[i], err = sk.x.SetBytes(in[i*scSize : (i+1)*scSize])
+		if err != nil {
+			return err
+		}
+		if scalars[i].IsZero() {
+			return fmt.Errorf("invalid secret key")
+		}
+	}
+	sk.x = scalars[0]
+	sk.y = scalars[1:]
+	return nil
+}
+
+// PublicKey returns the public key (X~, Y~_1, ..., Y~_n) in G2
+func (sk *SecretKey) PublicKey(curve *curves.PairingCurve) *PublicKey {
+	pk := &PublicKey{
+		x: curve.ScalarG2BaseMult(sk.x),
+		y: make([]curves.PairingPoint, len(sk.y)),
+	}
+	for i, y := range sk.y {
+		pk.y[i] = curve.ScalarG2BaseMult(y)
+	}
+	return pk
+}
+
+// Sign signs the messages, whose number must be the length of the key.
+// The signature is (h, h * (x + sum y_i * m_i)) where h is derived from the key and the messages,
+// so signing the same messages twice returns the same signature
+func (sk *SecretKey) Sign(curve *curves.PairingCurve, msgs []curves.Scalar) (*Signature, error) {
+	if sk.x == nil || sk.x.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	if len(msgs) != len(sk.y) {
+		return nil, fmt.Errorf("expected %d messages, got %d", len(sk.y), len(msgs))
+	}
+	drbg := sha3.NewShake256()
+	_, _ = drbg.Write(sk.x.Bytes())
+	for _, y := range sk.y {
+		_, _ = drbg.Write(y.Bytes())
+	}
+	for _, m := range msgs {
+		_, _ = drbg.Write(m.Bytes())
+	}
+	h := curve.ScalarG1BaseMult(getNonZeroScalar(curve.Scalar, drbg))
+	exp := sk.x
+	for i, m := range msgs {
+		exp = exp.Add(sk.y[i].Mul(m))
+	}
+	return &Signature{
+		sigma1: h,
+		sigma2: h.Mul(exp).(curves.PairingPoint),
+	}, nil
+}
@@ -0,0 +1,93 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package ps is an implementation of the Pointcheval-Sanders signatures of section 4.2 in
+// <https://eprint.iacr.org/2015/525.pdf> with the proof of knowledge of a signature of section 6.2.
+// Signatures are in G1 and public keys in G2. Unlike BBS+ signatures, a PS signature can be
+// randomized into an unlinkable signature of the same messages without any proof
+package ps
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// Signature is a PS signature (sigma1, sigma2)
+type Signature struct {
+	sigma1, sigma2 curves.PairingPoint
+}
+
+// Init creates an empty signature for a specific curve
+// which should be followed by UnmarshalBinary
+func (sig *Signature) Init(curve *curves.PairingCurve) *Signature {
+	sig.sigma1 = curve.NewG1IdentityPoint()
+	sig.sigma2 = curve.NewG1IdentityPoint()
+	return sig
+}
+
+// Randomize returns the signature (sigma1 * t, sigma2 * t) of the same messages for a random t
+// read from `reader`, or crypto/rand if it is nil. It cannot be linked to the original signature
+func (sig Signature) Randomize(reader io.Reader) (*Signature, error) {
+	if sig.sigma1 == nil || sig.sigma2 == nil || sig.sigma1.IsIdentity() {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	t := getNonZeroScalar(sig.sigma1.Scalar(), reader)
+	return &Signature{
+		sigma1: sig.sigma1.Mul(t).(curves.PairingPoint),
+		sigma2: sig.sigma2.Mul(t).(curves.PairingPoint),
+	}, nil
+}
+
+// MarshalBinary returns sigma1 || sigma2 with compressed points
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	if sig.sigma1 == nil || sig.sigma2 == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	return append(sig.sigma1.ToAffineCompressed(), sig.sigma2.ToAffineCompressed()...), nil
+}
+
+// UnmarshalBinary reads a signature written by MarshalBinary,
+// the signature must have been created with Init
+func (sig *Signature) UnmarshalBinary(in []byte) error {
+	if sig.sigma1 == nil {
+		return fmt.Errorf("signature is not initialized")
+	}
+	ptSize := len(sig.sigma1.ToAffineCompressed())
+	if len(in) != 2*ptSize {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	points := make([]curves.PairingPoint, 2)
+	for i := range points {
+		p, err := sig.sigma1.FromAffineCompressed(in[i*ptSize : (i+1)*ptSize])
+		if err != nil {
+			return err
+		}
+		var ok bool
+		points[i], ok = p.(curves.PairingPoint)
+		if !ok {
+			return fmt.Errorf("incorrect type conversion")
+		}
+	}
+	if points[0].IsIdentity() {
+		return fmt.Errorf("invalid signature")
+	}
+	sig.sigma1, sig.sigma2 = points[0], points[1]
+	return nil
+}
+
+func getNonZeroScalar(sc curves.Scalar, reader io.Reader) curves.Scalar {
+	s := sc.Random(reader)
+	for s.IsZero() {
+		s = sc.Random(reader)
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ps
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func testMessages(curve *curves.PairingCurve) []curves.Scalar {
+	return []curves.Scalar{
+		curve.Scalar.New(3),
+		curve.Scalar.New(4),
+		curve.Scalar.New(5),
+		curve.Scalar.New(6),
+	}
+}
+
+func TestSignatureWorks(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	msgs := testMessages(curve)
+	pk, sk, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+	require.Equal(t, 4, pk.Length())
+
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+	require.NoError(t, pk.Verify(sig, msgs))
+
+	// Signing is deterministic
+	sig2, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+	require.True(t, sig.sigma1.Equal(sig2.sigma1))
+
+	_, err = sk.Sign(curve, msgs[:3])
+	require.Error(t, err)
+	require.Error(t, pk.Verify(sig, msgs[:3]))
+}
+
+func TestSignatureIncorrectMessages(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	msgs := testMessages(curve)
+	pk, sk, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+	msgs[0] = curve.Scalar.New(7)
+	require.Error(t, pk.Verify(sig, msgs))
+
+	otherPk, _, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+	require.Error(t, otherPk.Verify(sig, testMessages(curve)))
+}
+
+func TestSignatureRandomize(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	msgs := testMessages(curve)
+	pk, sk, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+
+	randomized, err := sig.Randomize(crand.Reader)
+	require.NoError(t, err)
+	require.False(t, randomized.sigma1.Equal(sig.sigma1))
+	require.False(t, randomized.sigma2.Equal(sig.sigma2))
+	require.NoError(t, pk.Verify(randomized, msgs))
+
+	// The identity is not a valid signature for any messages
+	identity := new(Signature).Init(curve)
+	require.Error(t, pk.Verify(identity, msgs))
+	_, err = identity.Randomize(crand.Reader)
+	require.Error(t, err)
+}
+
+func TestSignatureMarshalBinary(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G2{})
+	msgs := testMessages(curve)
+	pk, sk, err := NewKeys(curve, 4)
+	require.NoError(t, err)
+	sig, err := sk.Sign(curve, msgs)
+	require.NoError(t, err)
+
+	data, err := sig.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, 96, len(data))
+	sig2 := new(Signature).Init(curve)
+	require.NoError(t, sig2.UnmarshalBinary(data))
+	require.True(t, sig.sigma1.Equal(sig2.sigma1))
+	require.True(t, sig.sigma2.Equal(sig2.sigma2))
+
+	data, err = pk.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, 96*5, len(data))
+	pk2 := new(PublicKey).Init(curve)
+	require.NoError(t, pk2.UnmarshalBinary(data))
+	require.NoError(t, pk2.Verify(sig, msgs))
+	require.Error(t, new(PublicKey).Init(curve).UnmarshalBinary(data[:len(data)-1]))
+
+	data, err = sk.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, 32*5, len(data))
+	sk2 := new(SecretKey).Init(curve)
+	require.NoError(t, sk2.UnmarshalBinary(data))
+	sig3, err := sk2.Sign(curve, msgs)
+	require.NoError(t, err)
+	require.True(t, sig.sigma2.Equal(sig3.sigma2))
+}
@@ -0,0 +1,176 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package musig2 implements MuSig2 multi-signatures over secp256k1 as specified by BIP-327,
+// https://github.com/bitcoin/bips/blob/master/bip-0327.mediawiki.
+// n signers aggregate their public keys into a single key and jointly produce a BIP-340 Schnorr
+// signature under it in two rounds, exchanging nonces and then partial signatures. The nonce round
+// does not depend on the message and can be run ahead of time.
+//
+// Public keys are 33 byte compressed points, secret keys and partial signatures 32 byte big-endian integers,
+// and the aggregate public key and the signature the 32 and 64 byte encodings of BIP-340.
+package musig2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// KeyAggContext is the aggregate of the public keys of the signers, with the tweaks applied to it
+type KeyAggContext struct {
+	pubKeys [][]byte
+	// The hash of the public keys and the second distinct one, which determine the key aggregation coefficients
+	hash   []byte
+	second []byte
+	q      curves.Point
+	// The accumulated sign and tweak of the tweaks applied to the aggregate key
+	gacc, tacc curves.Scalar
+}
+
+// KeySort sorts the public keys in lexicographical order, which makes the aggregate key independent of
+// the order in which the signers are listed
+func KeySort(pubKeys [][]byte) [][]byte {
+	sorted := make([][]byte, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return sorted
+}
+
+// KeyAgg aggregates the public keys of the signers, in the given order
+func KeyAgg(pubKeys [][]byte) (*KeyAggContext, error) {
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("no public keys")
+	}
+	curve := curves.K256()
+	ctx := &KeyAggContext{
+		pubKeys: make([][]byte, len(pubKeys)),
+		second:  make([]byte, 33),
+		q:       curve.NewIdentityPoint(),
+		gacc:    curve.Scalar.One(),
+		tacc:    curve.Scalar.Zero(),
+	}
+	var all []byte
+	for i, pk := range pubKeys {
+		ctx.pubKeys[i] = append([]byte{}, pk...)
+		all = append(all, pk...)
+		if !bytes.Equal(pk, pubKeys[0]) && bytes.Equal(ctx.second, make([]byte, 33)) {
+			ctx.second = ctx.pubKeys[i]
+		}
+	}
+	ctx.hash = taggedHash("KeyAgg list", all)
+	for i, pk := range pubKeys {
+		p, err := parsePubKey(pk)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d", i)
+		}
+		ctx.q = ctx.q.Add(p.Mul(ctx.coefficient(pk)))
+	}
+	if ctx.q.IsIdentity() {
+		return nil, fmt.Errorf("aggregate public key is the identity")
+	}
+	return ctx, nil
+}
+
+// ApplyTweak returns the context with `tweak`, a 32 byte big-endian integer, added to the aggregate key.
+// An x-only tweak, e.g. a BIP-341 taproot tweak, is added to the aggregate key with an even Y coordinate,
+// a plain tweak, e.g. of BIP-32 derivation, to the aggregate key itself
+func (ctx *KeyAggContext) ApplyTweak(tweak []byte, xOnly bool) (*KeyAggContext, error) {
+	if ctx == nil || len(tweak) != 32 {
+		return nil, fmt.Errorf("invalid tweak")
+	}
+	t, err := parseScalar(tweak)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tweak")
+	}
+	curve := curves.K256()
+	g := curve.Scalar.One()
+	if xOnly && ctx.q.IsNegative() {
+		g = g.Neg()
+	}
+	q := ctx.q.Mul(g).Add(curve.ScalarBaseMult(t))
+	if q.IsIdentity() {
+		return nil, fmt.Errorf("tweaked key is the identity")
+	}
+	out := *ctx
+	out.q, out.gacc, out.tacc = q, g.Mul(ctx.gacc), t.Add(g.Mul(ctx.tacc))
+	return &out, nil
+}
+
+// PublicKey returns the 32 byte x-only aggregate public key, under which signatures verify
+func (ctx *KeyAggContext) PublicKey() []byte {
+	return ctx.q.ToAffineCompressed()[1:]
+}
+
+// PlainPublicKey returns the 33 byte compressed aggregate public key, e.g. to derive BIP-32 tweaks
+func (ctx *KeyAggContext) PlainPublicKey() []byte {
+	return ctx.q.ToAffineCompressed()
+}
+
+// coefficient returns the key aggregation coefficient of the public key `pk`, which is 1 for the second
+// distinct key so aggregation saves a multiplication
+func (ctx *KeyAggContext) coefficient(pk []byte) curves.Scalar {
+	if bytes.Equal(pk, ctx.second) {
+		return curves.K256().Scalar.One()
+	}
+	return hashScalar(taggedHash("KeyAgg coefficient", ctx.hash, pk))
+}
+
+// contains returns true if `pk` is one of the aggregated public keys
+func (ctx *KeyAggContext) contains(pk []byte) bool {
+	for _, other := range ctx.pubKeys {
+		if bytes.Equal(pk, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// taggedHash is the tagged hash of BIP-340, SHA256(SHA256(tag) || SHA256(tag) || x_1 || ... || x_n)
+func taggedHash(tag string, inputs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	_, _ = h.Write(tagHash[:])
+	_, _ = h.Write(tagHash[:])
+	for _, in := range inputs {
+		_, _ = h.Write(in)
+	}
+	return h.Sum(nil)
+}
+
+// hashScalar interprets a hash as a big-endian integer modulo the order of secp256k1
+func hashScalar(h []byte) curves.Scalar {
+	v := new(big.Int).SetBytes(h)
+	v.Mod(v, btcec.S256().N)
+	s, _ := curves.K256().Scalar.SetBigInt(v)
+	return s
+}
+
+// parseScalar reads a 32 byte big-endian integer, which must be less than the order of secp256k1
+func parseScalar(b []byte) (curves.Scalar, error) {
+	if len(b) != 32 {
+		return nil, fmt.Errorf("invalid scalar length")
+	}
+	v := new(big.Int).SetBytes(b)
+	if v.Cmp(btcec.S256().N) >= 0 {
+		return nil, fmt.Errorf("scalar out of range")
+	}
+	return curves.K256().Scalar.SetBigInt(v)
+}
+
+// parsePubKey reads a 33 byte compressed point
+func parsePubKey(b []byte) (curves.Point, error) {
+	if len(b) != 33 || (b[0] != 2 && b[0] != 3) {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	return curves.K256().Point.FromAffineCompressed(b)
+}
@@ -0,0 +1,390 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package musig2
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ted25519/frost"
+)
+
+func fromHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// From the key_agg_vectors of BIP-327
+func TestKeyAggVectors(t *testing.T) {
+	pubKeys := [][]byte{
+		fromHex(t, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		fromHex(t, "03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659"),
+		fromHex(t, "023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66"),
+	}
+	tests := []struct {
+		indices  []int
+		expected string
+	}{
+		{[]int{0, 1, 2}, "90539EEDE565F5D054F32CC0C220126889ED1E5D193BAF15AEF344FE59D4610C"},
+		{[]int{2, 1, 0}, "6204DE8B083426DC6EAF9502D27024D53FC826BF7D2012148A0575435DF54B2B"},
+		{[]int{0, 0, 0}, "B436E3BAD62B8CD409969A224731C193D051162D8C5AE8B109306127DA3AA935"},
+		{[]int{0, 0, 1, 1}, "69BC22BFA5D106306E48A20679DE1D7389386124D07571D0D872686028C26A3E"},
+	}
+	for _, test := range tests {
+		var keys [][]byte
+		for _, i := range test.indices {
+			keys = append(keys, pubKeys[i])
+		}
+		ctx, err := KeyAgg(keys)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, strings.ToUpper(hex.EncodeToString(ctx.PublicKey())))
+	}
+
+	// Invalid public keys are rejected
+	_, err := KeyAgg([][]byte{pubKeys[0], fromHex(t, "0000000000000000000000000000000000000000000000000000000000000005")})
+	require.Error(t, err)
+	_, err = KeyAgg(nil)
+	require.Error(t, err)
+}
+
+// From the nonce_agg_vectors of BIP-327
+func TestNonceAggVector(t *testing.T) {
+	aggNonce, err := NonceAgg([][]byte{
+		fromHex(t, "020151C80F435648DF67A22B749CD798CE54E0321D034B92B709B567D60A42E66603BA47FBC1834437B3212E89A84D8425E7BF12E0245D98262268EBDCB385D50641"),
+		fromHex(t, "03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B833"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "035FE1873B4F2967F52FEA4A06AD5A8ECCBE9D0FD73068012C894E2E87CCB5804B024725377345BDE0E9C33AF3C43C0A29A9249F2F2956FA8CFEB55C8573D0262DC8",
+		strings.ToUpper(hex.EncodeToString(aggNonce)))
+}
+
+// From the nonce_gen_vectors of BIP-327, whose secret nonces are k1 || k2 || pk
+func TestNonceGenVectors(t *testing.T) {
+	const (
+		sk     = "0202020202020202020202020202020202020202020202020202020202020202"
+		pk     = "024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766"
+		aggPk  = "0707070707070707070707070707070707070707070707070707070707070707"
+		extra  = "0808080808080808080808080808080808080808080808080808080808080808"
+		absent = "absent"
+	)
+	optional := func(s string) []byte {
+		if s == absent {
+			return nil
+		}
+		return fromHex(t, s)
+	}
+	rand := fromHex(t, "0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F0F")
+	tests := []struct {
+		sk, pk, aggPk, msg, extra string
+		secNonce, pubNonce        string
+	}{
+		{
+			sk, pk, aggPk, "0101010101010101010101010101010101010101010101010101010101010101", extra,
+			"B114E502BEAA4E301DD08A50264172C84E41650E6CB726B410C0694D59EFFB6495B5CAF28D045B973D63E3C99A44B807BDE375FD6CB39E46DC4A511708D0E9D2024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+			"02F7BE7089E8376EB355272368766B17E88E7DB72047D05E56AA881EA52B3B35DF02C29C8046FDD0DED4C7E55869137200FBDBFE2EB654267B6D7013602CAED3115A",
+		},
+		{
+			sk, pk, aggPk, "", extra,
+			"E862B068500320088138468D47E0E6F147E01B6024244AE45EAC40ACE5929B9F0789E051170B9E705D0B9EB49049A323BBBBB206D8E05C19F46C6228742AA7A9024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+			"023034FA5E2679F01EE66E12225882A7A48CC66719B1B9D3B6C4DBD743EFEDA2C503F3FD6F01EB3A8E9CB315D73F1F3D287CAFBB44AB321153C6287F407600205109",
+		},
+		{
+			sk, pk, aggPk, "2626262626262626262626262626262626262626262626262626262626262626262626262626", extra,
+			"3221975ACBDEA6820EABF02A02B7F27D3A8EF68EE42787B88CBEFD9AA06AF3632EE85B1A61D8EF31126D4663A00DD96E9D1D4959E72D70FE5EBB6E7696EBA66F024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766",
+			"02E5BBC21C69270F59BD634FCBFA281BE9D76601295345112C58954625BF23793A021307511C79F95D38ACACFF1B4DA98228B77E65AA216AD075E9673286EFB4EAF3",
+		},
+		{
+			absent, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9", absent, absent, absent,
+			"89BDD787D0284E5E4D5FC572E49E316BAB7E21E3B1830DE37DFE80156FA41A6D0B17AE8D024C53679699A6FD7944D9C4A366B514BAF43088E0708B1023DD289702F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+			"02C96E7CB1E8AA5DAC64D872947914198F607D90ECDE5200DE52978AD5DED63C000299EC5117C2D29EDEE8A2092587C3909BE694D5CFF0667D6C02EA4059F7CD9786",
+		},
+	}
+	for _, test := range tests {
+		secNonce, pubNonce, err := NonceGen(optional(test.sk), fromHex(t, test.pk), optional(test.aggPk),
+			optional(test.msg), optional(test.extra), bytes.NewReader(rand))
+		require.NoError(t, err)
+		encoded := append(append(secNonce.k1.Bytes(), secNonce.k2.Bytes()...), secNonce.pubKey...)
+		require.Equal(t, test.secNonce, strings.ToUpper(hex.EncodeToString(encoded)))
+		require.Equal(t, test.pubNonce, strings.ToUpper(hex.EncodeToString(pubNonce)))
+	}
+}
+
+// testSecNonce decodes a secret nonce k1 || k2 || pk of the BIP-327 vectors
+func testSecNonce(t *testing.T, s string) *SecretNonce {
+	b := fromHex(t, s)
+	return &SecretNonce{k1: mustScalar(t, b[:32]), k2: mustScalar(t, b[32:64]), pubKey: b[64:]}
+}
+
+// From the sign_verify_vectors of BIP-327. The signatures of the verify_fail cases are derived from the first
+// valid case as the vectors describe them: its negation, the same signature for another signer, and the group order
+func TestSignVerifyVectors(t *testing.T) {
+	sk := fromHex(t, "7FB9E0E687ADA1EEBF7ECFE2F21E73EBDB51A7D450948DFE8D76D7F2D1007671")
+	pubKeys := []string{
+		"03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA661",
+		"020000000000000000000000000000000000000000000000000000000000000007",
+	}
+	secNonces := []string{
+		"508B81A611F100A6B2B6B29656590898AF488BCF2E1F55CF22E5CFB84421FE61FA27FD49B1D50085B481285E1CA205D55C82CC1B31FF5CD54A489829355901F703935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+		"0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000003935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+	}
+	pubNonces := []string{
+		"0337C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+		"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F817980279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"032DE2662628C90B03F5E720284EB52FF7D71F4284F627B68A853D78C78E1FFE9303E4C5524E83FFE1493B9077CF1CA6BEB2090C93D930321071AD40B2F44E599046",
+		"0237C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0387BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+		"0200000000000000000000000000000000000000000000000000000000000000090287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+	}
+	aggNonces := []string{
+		"028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9",
+		"000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"048465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9",
+		"028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61020000000000000000000000000000000000000000000000000000000000000009",
+		"028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD6102FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+	}
+	msgs := []string{
+		"F95466D086770E689964664219266FE5ED215C92AE20BAB5C9D79ADDDDF3C0CF",
+		"",
+		"2626262626262626262626262626262626262626262626262626262626262626262626262626",
+	}
+	keys := func(indices []int) [][]byte {
+		var out [][]byte
+		for _, i := range indices {
+			out = append(out, fromHex(t, pubKeys[i]))
+		}
+		return out
+	}
+	session := func(keyIndices []int, aggNonce, msg int) (*Session, error) {
+		keyAgg, err := KeyAgg(keys(keyIndices))
+		if err != nil {
+			return nil, err
+		}
+		return NewSession(fromHex(t, aggNonces[aggNonce]), keyAgg, fromHex(t, msgs[msg]))
+	}
+
+	// the first aggregate nonce is that of the first three public nonces, the second that of nonces 0 and 3
+	for i, indices := range [][]int{{0, 1, 2}, {0, 3}} {
+		var nonces [][]byte
+		for _, j := range indices {
+			nonces = append(nonces, fromHex(t, pubNonces[j]))
+		}
+		aggNonce, err := NonceAgg(nonces)
+		require.NoError(t, err)
+		require.Equal(t, aggNonces[i], strings.ToUpper(hex.EncodeToString(aggNonce)))
+	}
+
+	valid := []struct {
+		keys, nonces  []int
+		aggNonce, msg int
+		signer        int
+		expected      string
+	}{
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, 0, 0, "012ABBCB52B3016AC03AD82395A1A415C48B93DEF78718E62A7A90052FE224FB"},
+		{[]int{1, 0, 2}, []int{1, 0, 2}, 0, 0, 1, "9FF2F7AAA856150CC8819254218D3ADEEB0535269051897724F9DB3789513A52"},
+		{[]int{1, 2, 0}, []int{1, 2, 0}, 0, 0, 2, "FA23C359F6FAC4E7796BB93BC9F0532A95468C539BA20FF86D7C76ED92227900"},
+		// both halves of the aggregate nonce are the point at infinity
+		{[]int{0, 1}, []int{0, 3}, 1, 0, 0, "AE386064B26105404798F75DE2EB9AF5EDA5387B064B83D049CB7C5E08879531"},
+		// empty message
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, 1, 0, "D7D63FFD644CCDA4E62BC2BC0B1D02DD32A1DC3030E155195810231D1037D82D"},
+		// 38 byte message
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, 2, 0, "E184351828DA5094A97C79CABDAAA0BFB87608C32E8829A4DF5340A6F243B78C"},
+	}
+	for _, test := range valid {
+		s, err := session(test.keys, test.aggNonce, test.msg)
+		require.NoError(t, err)
+		psig, err := s.Sign(testSecNonce(t, secNonces[0]), sk)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, strings.ToUpper(hex.EncodeToString(psig)))
+		require.NoError(t, s.PartialSigVerify(psig, fromHex(t, pubNonces[test.nonces[test.signer]]), fromHex(t, pubKeys[test.keys[test.signer]])))
+	}
+
+	signErrors := []struct {
+		keys               []int
+		aggNonce, secNonce int
+	}{
+		// the signer's public key is not aggregated
+		{[]int{1, 2}, 0, 0},
+		// signer 2 provided an invalid public key
+		{[]int{1, 0, 3}, 0, 0},
+		// the first half of the aggregate nonce has the wrong tag 0x04
+		{[]int{1, 2, 0}, 2, 0},
+		// the second half of the aggregate nonce is not an X coordinate
+		{[]int{1, 2, 0}, 3, 0},
+		// the second half of the aggregate nonce exceeds the field size
+		{[]int{1, 2, 0}, 4, 0},
+		// the secret nonce is invalid, which may indicate nonce reuse
+		{[]int{0, 1, 2}, 0, 1},
+	}
+	for i, test := range signErrors {
+		s, err := session(test.keys, test.aggNonce, 0)
+		if err == nil {
+			_, err = s.Sign(testSecNonce(t, secNonces[test.secNonce]), sk)
+		}
+		require.Error(t, err, "sign error case %d", i)
+	}
+
+	s, err := session([]int{0, 1, 2}, 0, 0)
+	require.NoError(t, err)
+	psig := mustScalar(t, fromHex(t, valid[0].expected))
+	verifyFail := []struct {
+		psig   []byte
+		signer int
+	}{
+		// the negation of the valid signature
+		{psig.Neg().Bytes(), 0},
+		// wrong signer
+		{psig.Bytes(), 1},
+		// the signature exceeds the group size
+		{fromHex(t, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141"), 0},
+	}
+	for i, test := range verifyFail {
+		err = s.PartialSigVerify(test.psig, fromHex(t, pubNonces[test.signer]), fromHex(t, pubKeys[test.signer]))
+		require.Error(t, err, "verify fail case %d", i)
+	}
+
+	// verify errors: an invalid public nonce, and an invalid public key
+	err = s.PartialSigVerify(psig.Bytes(), fromHex(t, pubNonces[4]), fromHex(t, pubKeys[0]))
+	require.Error(t, err)
+	_, err = KeyAgg(keys([]int{3, 1, 2}))
+	require.Error(t, err)
+}
+
+type testSigner struct {
+	sk, pk   []byte
+	secNonce *SecretNonce
+	pubNonce []byte
+}
+
+func newTestSigners(t *testing.T, n int) ([]*testSigner, [][]byte) {
+	curve := curves.K256()
+	signers := make([]*testSigner, n)
+	pubKeys := make([][]byte, n)
+	for i := range signers {
+		sk := curve.Scalar.Random(crand.Reader)
+		signers[i] = &testSigner{sk: sk.Bytes(), pk: curve.ScalarBaseMult(sk).ToAffineCompressed()}
+		pubKeys[i] = signers[i].pk
+	}
+	return signers, KeySort(pubKeys)
+}
+
+// sign runs both rounds and checks the signature with the BIP-340 verifier of package frost
+func sign(t *testing.T, signers []*testSigner, keyAgg *KeyAggContext, msg []byte) []byte {
+	var pubNonces [][]byte
+	var err error
+	for _, s := range signers {
+		s.secNonce, s.pubNonce, err = NonceGen(s.sk, s.pk, keyAgg.PublicKey(), msg, nil, nil)
+		require.NoError(t, err)
+		pubNonces = append(pubNonces, s.pubNonce)
+	}
+	aggNonce, err := NonceAgg(pubNonces)
+	require.NoError(t, err)
+	session, err := NewSession(aggNonce, keyAgg, msg)
+	require.NoError(t, err)
+	var psigs [][]byte
+	for _, s := range signers {
+		psig, err := session.Sign(s.secNonce, s.sk)
+		require.NoError(t, err)
+		require.NoError(t, session.PartialSigVerify(psig, s.pubNonce, s.pk))
+		psigs = append(psigs, psig)
+	}
+	sig, err := session.PartialSigAgg(psigs)
+	require.NoError(t, err)
+	ok, err := frost.VerifyBip340(keyAgg.PublicKey(), msg, sig)
+	require.NoError(t, err)
+	require.True(t, ok)
+	return sig
+}
+
+func TestSigning(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		signers, pubKeys := newTestSigners(t, n)
+		keyAgg, err := KeyAgg(pubKeys)
+		require.NoError(t, err)
+		sign(t, signers, keyAgg, []byte("message"))
+		sign(t, signers, keyAgg, []byte{})
+	}
+}
+
+func TestSigningWithTweaks(t *testing.T) {
+	signers, pubKeys := newTestSigners(t, 3)
+	keyAgg, err := KeyAgg(pubKeys)
+	require.NoError(t, err)
+	// A plain tweak followed by a taproot tweak, with both parities of the keys covered over the iterations
+	for i := 0; i < 8; i++ {
+		tweak := curves.K256().Scalar.Random(crand.Reader).Bytes()
+		keyAgg, err = keyAgg.ApplyTweak(tweak, i%2 == 0)
+		require.NoError(t, err)
+		sign(t, signers, keyAgg, []byte("message"))
+	}
+
+	// The taproot output key of BIP-341 matches the one computed by package frost
+	internal, err := KeyAgg(pubKeys)
+	require.NoError(t, err)
+	p, err := curves.K256().Point.FromAffineCompressed(internal.PlainPublicKey())
+	require.NoError(t, err)
+	tweak, err := frost.TaprootTweak(p, nil)
+	require.NoError(t, err)
+	output, err := internal.ApplyTweak(tweak.Bytes(), true)
+	require.NoError(t, err)
+	expected, err := frost.TaprootOutputKey(p, nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, output.PublicKey())
+	sign(t, signers, output, []byte("message"))
+}
+
+func TestInvalidPartialSignature(t *testing.T) {
+	signers, pubKeys := newTestSigners(t, 2)
+	keyAgg, err := KeyAgg(pubKeys)
+	require.NoError(t, err)
+	msg := []byte("message")
+	var pubNonces [][]byte
+	for _, s := range signers {
+		s.secNonce, s.pubNonce, err = NonceGen(nil, s.pk, nil, nil, nil, nil)
+		require.NoError(t, err)
+		pubNonces = append(pubNonces, s.pubNonce)
+	}
+	aggNonce, err := NonceAgg(pubNonces)
+	require.NoError(t, err)
+	session, err := NewSession(aggNonce, keyAgg, msg)
+	require.NoError(t, err)
+
+	psig, err := session.Sign(signers[0].secNonce, signers[0].sk)
+	require.NoError(t, err)
+	// the nonce is erased after signing
+	_, err = session.Sign(signers[0].secNonce, signers[0].sk)
+	require.Error(t, err)
+	// a nonce cannot sign with another key
+	_, err = session.Sign(signers[1].secNonce, signers[0].sk)
+	require.Error(t, err)
+
+	// the partial signature is attributed to its signer only
+	require.NoError(t, session.PartialSigVerify(psig, signers[0].pubNonce, signers[0].pk))
+	require.Error(t, session.PartialSigVerify(psig, signers[1].pubNonce, signers[1].pk))
+	require.Error(t, session.PartialSigVerify(psig, signers[0].pubNonce, signers[1].pk))
+	wrong := curves.K256().Scalar.One().Add(mustScalar(t, psig)).Bytes()
+	require.Error(t, session.PartialSigVerify(wrong, signers[0].pubNonce, signers[0].pk))
+
+	// an aggregate with an invalid partial signature does not verify
+	sig, err := session.PartialSigAgg([][]byte{psig, wrong})
+	require.NoError(t, err)
+	ok, _ := frost.VerifyBip340(keyAgg.PublicKey(), msg, sig)
+	require.False(t, ok)
+}
+
+func mustScalar(t *testing.T, b []byte) curves.Scalar {
+	s, err := parseScalar(b)
+	require.NoError(t, err)
+	return s
+}
@@ -0,0 +1,252 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package musig2
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// SecretNonce is the secret nonce of a signer for one signing session. It is erased by Sign,
+// and must never be copied or persisted, since signing twice with it reveals the secret key
+type SecretNonce struct {
+	k1, k2 curves.Scalar
+	pubKey []byte
+}
+
+// Session holds the values every signer derives from the aggregate nonce, the keys and the message
+type Session struct {
+	keyAgg *KeyAggContext
+	msg    []byte
+	b, e   curves.Scalar
+	r      curves.Point
+}
+
+// NonceGen generates the nonces of the signer with public key `pubKey` from randomness read from `reader`,
+// or crypto/rand if it is nil, and returns the secret nonce and the 66 byte public nonce to send to the other
+// signers. The secret key, aggregate public key, message and extra input are optional and can be nil, they only
+// add defense in depth against bad randomness. A nil `msg` means the message is unknown, unlike an empty one
+func NonceGen(secretKey, pubKey, aggPubKey, msg, extra []byte, reader io.Reader) (*SecretNonce, []byte, error) {
+	if _, err := parsePubKey(pubKey); err != nil {
+		return nil, nil, err
+	}
+	if secretKey != nil && len(secretKey) != 32 {
+		return nil, nil, fmt.Errorf("invalid secret key")
+	}
+	if aggPubKey != nil && len(aggPubKey) != 32 {
+		return nil, nil, fmt.Errorf("invalid aggregate public key")
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	random := make([]byte, 32)
+	if _, err := io.ReadFull(reader, random); err != nil {
+		return nil, nil, err
+	}
+	return nonceGen(random, secretKey, pubKey, aggPubKey, msg, extra)
+}
+
+// nonceGen is NonceGen with the given random bytes
+func nonceGen(random, secretKey, pubKey, aggPubKey, msg, extra []byte) (*SecretNonce, []byte, error) {
+	if secretKey != nil {
+		aux := taggedHash("MuSig/aux", random)
+		random = make([]byte, 32)
+		for i := range random {
+			random[i] = secretKey[i] ^ aux[i]
+		}
+	}
+	msgPrefixed := []byte{0}
+	if msg != nil {
+		msgPrefixed = make([]byte, 9, 9+len(msg))
+		msgPrefixed[0] = 1
+		binary.BigEndian.PutUint64(msgPrefixed[1:], uint64(len(msg)))
+		msgPrefixed = append(msgPrefixed, msg...)
+	}
+	extraLen := make([]byte, 4)
+	binary.BigEndian.PutUint32(extraLen, uint32(len(extra)))
+	var k [2]curves.Scalar
+	for i := range k {
+		k[i] = hashScalar(taggedHash("MuSig/nonce", random, []byte{byte(len(pubKey))}, pubKey,
+			[]byte{byte(len(aggPubKey))}, aggPubKey, msgPrefixed, extraLen, extra, []byte{byte(i)}))
+		if k[i].IsZero() {
+			return nil, nil, fmt.Errorf("invalid nonce")
+		}
+	}
+	curve := curves.K256()
+	pubNonce := append(curve.ScalarBaseMult(k[0]).ToAffineCompressed(), curve.ScalarBaseMult(k[1]).ToAffineCompressed()...)
+	return &SecretNonce{k1: k[0], k2: k[1], pubKey: append([]byte{}, pubKey...)}, pubNonce, nil
+}
+
+// NonceAgg sums the public nonces of all signers into the 66 byte aggregate nonce. It can be computed by any
+// party, e.g. a coordinator, since a wrong aggregate nonce only makes signing fail
+func NonceAgg(pubNonces [][]byte) ([]byte, error) {
+	if len(pubNonces) == 0 {
+		return nil, fmt.Errorf("no public nonces")
+	}
+	curve := curves.K256()
+	r := [2]curves.Point{curve.NewIdentityPoint(), curve.NewIdentityPoint()}
+	for i, pubNonce := range pubNonces {
+		if len(pubNonce) != 66 {
+			return nil, fmt.Errorf("invalid public nonce %d", i)
+		}
+		for j := range r {
+			p, err := parsePubKey(pubNonce[33*j : 33*(j+1)])
+			if err != nil {
+				return nil, fmt.Errorf("invalid public nonce %d", i)
+			}
+			r[j] = r[j].Add(p)
+		}
+	}
+	return append(encodeNonce(r[0]), encodeNonce(r[1])...), nil
+}
+
+// NewSession derives the values of the signing session of `msg` with the aggregate nonce and key
+func NewSession(aggNonce []byte, keyAgg *KeyAggContext, msg []byte) (*Session, error) {
+	if keyAgg == nil || len(aggNonce) != 66 {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	r1, err := decodeNonce(aggNonce[:33])
+	if err != nil {
+		return nil, err
+	}
+	r2, err := decodeNonce(aggNonce[33:])
+	if err != nil {
+		return nil, err
+	}
+	q := keyAgg.PublicKey()
+	b := hashScalar(taggedHash("MuSig/noncecoef", aggNonce, q, msg))
+	r := r1.Add(r2.Mul(b))
+	// The aggregate nonce cannot be the identity without the signers knowing a discrete logarithm relation,
+	// it is replaced by the generator so signing does not abort
+	if r.IsIdentity() {
+		r = curves.K256().Point.Generator()
+	}
+	e := hashScalar(taggedHash("BIP0340/challenge", r.ToAffineCompressed()[1:], q, msg))
+	return &Session{keyAgg: keyAgg, msg: append([]byte{}, msg...), b: b, e: e, r: r}, nil
+}
+
+// Sign returns the 32 byte partial signature of the signer with `secretKey`, whose nonce is erased so it
+// cannot be used again. The partial signature is verified before it is returned
+func (s *Session) Sign(secNonce *SecretNonce, secretKey []byte) ([]byte, error) {
+	if s == nil || secNonce == nil || secNonce.k1 == nil || secNonce.k2 == nil {
+		return nil, fmt.Errorf("invalid or used secret nonce")
+	}
+	k1, k2 := secNonce.k1, secNonce.k2
+	secNonce.k1, secNonce.k2 = nil, nil
+	// BIP-327 erases secret nonces with zeros, so a zero nonce may indicate reuse
+	if k1.IsZero() || k2.IsZero() {
+		return nil, fmt.Errorf("invalid or used secret nonce")
+	}
+	sk, err := parseScalar(secretKey)
+	if err != nil || sk.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	curve := curves.K256()
+	pubKey := curve.ScalarBaseMult(sk).ToAffineCompressed()
+	if !bytes.Equal(pubKey, secNonce.pubKey) {
+		return nil, fmt.Errorf("secret nonce was generated for another key")
+	}
+	if !s.keyAgg.contains(pubKey) {
+		return nil, fmt.Errorf("signer is not in the aggregate key")
+	}
+	pubNonce := append(curve.ScalarBaseMult(k1).ToAffineCompressed(), curve.ScalarBaseMult(k2).ToAffineCompressed()...)
+	if s.r.IsNegative() {
+		k1, k2 = k1.Neg(), k2.Neg()
+	}
+	// d = g * gacc * sk, s = k1 + b * k2 + e * a * d
+	d := s.keySign().Mul(sk)
+	psig := k1.Add(s.b.Mul(k2)).Add(s.e.Mul(s.keyAgg.coefficient(pubKey)).Mul(d)).Bytes()
+	if err = s.PartialSigVerify(psig, pubNonce, pubKey); err != nil {
+		return nil, err
+	}
+	return psig, nil
+}
+
+// PartialSigVerify checks the partial signature of the signer with the 33 byte `pubKey` and the 66 byte
+// `pubNonce`, so a signer that sent an invalid one can be identified
+func (s *Session) PartialSigVerify(psig, pubNonce, pubKey []byte) error {
+	if s == nil || len(pubNonce) != 66 {
+		return fmt.Errorf("invalid arguments")
+	}
+	z, err := parseScalar(psig)
+	if err != nil {
+		return fmt.Errorf("invalid partial signature")
+	}
+	p, err := parsePubKey(pubKey)
+	if err != nil || !s.keyAgg.contains(pubKey) {
+		return fmt.Errorf("invalid public key")
+	}
+	r1, err := parsePubKey(pubNonce[:33])
+	if err != nil {
+		return fmt.Errorf("invalid public nonce")
+	}
+	r2, err := parsePubKey(pubNonce[33:])
+	if err != nil {
+		return fmt.Errorf("invalid public nonce")
+	}
+	re := r1.Add(r2.Mul(s.b))
+	if s.r.IsNegative() {
+		re = re.Neg()
+	}
+	// z * G == Re + e * a * g * gacc * P
+	expected := re.Add(p.Mul(s.e.Mul(s.keyAgg.coefficient(pubKey)).Mul(s.keySign())))
+	if !curves.K256().ScalarBaseMult(z).Equal(expected) {
+		return fmt.Errorf("invalid partial signature")
+	}
+	return nil
+}
+
+// PartialSigAgg sums the partial signatures of all signers into the 64 byte BIP-340 signature
+func (s *Session) PartialSigAgg(psigs [][]byte) ([]byte, error) {
+	if s == nil || len(psigs) == 0 {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	z := s.e.Mul(s.keyAgg.tacc)
+	if s.keyAgg.q.IsNegative() {
+		z = z.Neg()
+	}
+	for i, psig := range psigs {
+		zi, err := parseScalar(psig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partial signature %d", i)
+		}
+		z = z.Add(zi)
+	}
+	return append(s.r.ToAffineCompressed()[1:], z.Bytes()...), nil
+}
+
+// keySign returns g * gacc, where g negates the secret keys if the aggregate key has an odd Y coordinate
+func (s *Session) keySign() curves.Scalar {
+	if s.keyAgg.q.IsNegative() {
+		return s.keyAgg.gacc.Neg()
+	}
+	return s.keyAgg.gacc
+}
+
+// encodeNonce encodes a point of the aggregate nonce, where the identity is 33 zero bytes
+func encodeNonce(p curves.Point) []byte {
+	if p.IsIdentity() {
+		return make([]byte, 33)
+	}
+	return p.ToAffineCompressed()
+}
+
+func decodeNonce(b []byte) (curves.Point, error) {
+	if bytes.Equal(b, make([]byte, 33)) {
+		return curves.K256().NewIdentityPoint(), nil
+	}
+	p, err := parsePubKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid aggregate nonce")
+	}
+	return p, nil
+}
@@ -0,0 +1,132 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package watchdog re-verifies the signatures a signing service produced against the keys and
+// policies it was allowed to use, independently of the service itself.
+//
+// The signing service appends a Record to its Journal for every policy decision it takes, with
+// the signature it produced when the request was approved. The journal is a hash chain: each
+// record commits to the digest of the previous one, so a Head, which has the same sequence and
+// digest as the AuditHead of a DKLs wallet bundle, commits to the whole history.
+//
+// A Watchdog holds the registered public keys and the policies each key may sign under. It
+// ingests the journal, checking that the chain is unbroken, that every signature verifies and
+// that nothing was signed against a policy decision, and reconciles it with the signatures
+// observed elsewhere, e.g. on a ledger, to find signatures that were never journaled.
+// Every problem is reported as a Discrepancy.
+package watchdog
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// journalDomain prefixes the hashed encoding of a record
+const journalDomain = "kryptology watchdog journal v1"
+
+// Decision is the outcome of the policy check of a signing request
+type Decision struct {
+	// Policy names the policy that was evaluated
+	Policy   string
+	Approved bool
+}
+
+// Record is one entry of the journal. Signature is empty when the request was denied
+// or signing failed
+type Record struct {
+	Sequence  uint64
+	Prev      [sha256.Size]byte
+	KeyId     string
+	Message   []byte
+	Signature []byte
+	Decision  Decision
+}
+
+// Head identifies the latest record of a journal, Sequence is zero when the journal is empty
+type Head struct {
+	Sequence uint64
+	Digest   [sha256.Size]byte
+}
+
+// Journal is the append-only log of a signing service
+type Journal struct {
+	head    Head
+	records []*Record
+}
+
+// NewJournal returns an empty journal
+func NewJournal() *Journal {
+	return new(Journal)
+}
+
+// Append records a policy decision on signing `message` with key `keyId`,
+// and the signature produced if the request was approved
+func (j *Journal) Append(keyId string, message, signature []byte, decision Decision) (*Record, error) {
+	if keyId == "" || decision.Policy == "" {
+		return nil, fmt.Errorf("invalid record")
+	}
+	if !decision.Approved && len(signature) != 0 {
+		return nil, fmt.Errorf("a denied request cannot have a signature")
+	}
+	r := &Record{
+		Sequence:  j.head.Sequence + 1,
+		Prev:      j.head.Digest,
+		KeyId:     keyId,
+		Message:   append([]byte{}, message...),
+		Signature: append([]byte{}, signature...),
+		Decision:  decision,
+	}
+	j.records = append(j.records, r)
+	j.head = Head{Sequence: r.Sequence, Digest: r.Digest()}
+	return r, nil
+}
+
+// Head returns the head of the journal
+func (j *Journal) Head() Head {
+	return j.head
+}
+
+// Records returns the records with a sequence greater than `after`, in order
+func (j *Journal) Records(after uint64) []*Record {
+	if after >= uint64(len(j.records)) {
+		return nil
+	}
+	records := make([]*Record, uint64(len(j.records))-after)
+	copy(records, j.records[after:])
+	return records
+}
+
+// Digest hashes the record together with the digest of the previous record
+func (r *Record) Digest() [sha256.Size]byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte(journalDomain))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], r.Sequence)
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write(r.Prev[:])
+	writeField(h, []byte(r.KeyId))
+	writeField(h, []byte(r.Decision.Policy))
+	if r.Decision.Approved {
+		_, _ = h.Write([]byte{1})
+	} else {
+		_, _ = h.Write([]byte{0})
+	}
+	writeField(h, r.Message)
+	writeField(h, r.Signature)
+	var out [sha256.Size]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// writeField writes a length prefixed field to `w`
+func writeField(w io.Writer, data []byte) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(len(data)))
+	_, _ = w.Write(buf[:])
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,82 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package watchdog
+
+import (
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/bls/bls_sig"
+	"github.com/etclab/kryptology/pkg/ted25519/ted25519"
+)
+
+// Verifier checks a serialized signature on a message with a registered public key
+// and returns an error if it is invalid
+type Verifier func(message, signature []byte) error
+
+// BlsScheme is implemented by the BLS signature schemes of bls_sig with signatures in G2
+type BlsScheme interface {
+	Verify(pk *bls_sig.PublicKey, msg []byte, sig *bls_sig.Signature) (bool, error)
+}
+
+// BlsVerifier returns a Verifier of BLS signatures in `scheme` by `pk`.
+// Signatures are in the format of Signature.MarshalBinary
+func BlsVerifier(scheme BlsScheme, pk *bls_sig.PublicKey) Verifier {
+	return func(message, signature []byte) error {
+		sig := new(bls_sig.Signature)
+		if err := sig.UnmarshalBinary(signature); err != nil {
+			return err
+		}
+		ok, err := scheme.Verify(pk, message, sig)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("invalid bls signature")
+		}
+		return nil
+	}
+}
+
+// Ed25519Verifier returns a Verifier of Ed25519 signatures by `pk`, which includes
+// the threshold signatures of the ted25519 and frost packages
+func Ed25519Verifier(pk ted25519.PublicKey) Verifier {
+	return func(message, signature []byte) error {
+		ok, err := ted25519.Verify(pk, message, signature)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("invalid ed25519 signature")
+		}
+		return nil
+	}
+}
+
+// EcdsaVerifier returns a Verifier of ECDSA signatures by `pk` on messages hashed with
+// `newHash`, such as those of DKLs18 and GG20. Signatures are r || s, each big-endian
+// and padded to the byte length of the curve order
+func EcdsaVerifier(pk *curves.EcPoint, newHash func() hash.Hash) Verifier {
+	return func(message, signature []byte) error {
+		size := (pk.Curve.Params().N.BitLen() + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("ecdsa signature must be %d bytes", 2*size)
+		}
+		h := newHash()
+		_, _ = h.Write(message)
+		sig := &curves.EcdsaSignature{
+			R: new(big.Int).SetBytes(signature[:size]),
+			S: new(big.Int).SetBytes(signature[size:]),
+		}
+		if !curves.VerifyEcdsa(pk, h.Sum(nil), sig) {
+			return fmt.Errorf("invalid ecdsa signature")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,221 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package watchdog
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Kind classifies a discrepancy
+type Kind int
+
+const (
+	// BrokenChain means a record does not follow the previous one
+	BrokenChain Kind = iota + 1
+	// UnknownKey means a signature is attributed to a key that is not registered
+	UnknownKey
+	// UnknownPolicy means the key is not allowed to sign under the policy of the decision
+	UnknownPolicy
+	// InvalidSignature means a signature does not verify with the registered key
+	InvalidSignature
+	// SignedWhenDenied means a record has a signature although its request was denied
+	SignedWhenDenied
+	// Unjournaled means a produced signature has no approved record in the journal
+	Unjournaled
+)
+
+func (k Kind) String() string {
+	switch k {
+	case BrokenChain:
+		return "broken chain"
+	case UnknownKey:
+		return "unknown key"
+	case UnknownPolicy:
+		return "unknown policy"
+	case InvalidSignature:
+		return "invalid signature"
+	case SignedWhenDenied:
+		return "signed when denied"
+	case Unjournaled:
+		return "unjournaled signature"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Discrepancy is a problem found by the watchdog. Sequence is the sequence of the
+// record, or zero for a produced signature
+type Discrepancy struct {
+	Kind     Kind
+	Sequence uint64
+	KeyId    string
+	Reason   string
+}
+
+func (d Discrepancy) String() string {
+	if d.Sequence == 0 {
+		return fmt.Sprintf("%s for key %s: %s", d.Kind, d.KeyId, d.Reason)
+	}
+	return fmt.Sprintf("%s in record %d for key %s: %s", d.Kind, d.Sequence, d.KeyId, d.Reason)
+}
+
+// Report lists the discrepancies found in one call, Checked is the number of records
+// or signatures that were checked
+type Report struct {
+	Head          Head
+	Checked       int
+	Discrepancies []Discrepancy
+}
+
+// Ok returns true if no discrepancy was found
+func (r *Report) Ok() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Produced is a signature observed outside the journal
+type Produced struct {
+	KeyId     string
+	Message   []byte
+	Signature []byte
+}
+
+// registration is a registered public key and the policies it may sign under
+type registration struct {
+	verify   Verifier
+	policies map[string]bool
+}
+
+// Watchdog cross-checks a journal and produced signatures against the registered keys
+type Watchdog struct {
+	keys     map[string]*registration
+	head     Head
+	approved map[[sha256.Size]byte]bool
+}
+
+// New returns a watchdog without registered keys that expects a journal from its first record
+func New() *Watchdog {
+	return &Watchdog{
+		keys:     make(map[string]*registration),
+		approved: make(map[[sha256.Size]byte]bool),
+	}
+}
+
+// Register adds the key `keyId`, whose signatures are checked with `verify`,
+// and the policies under which it may sign
+func (w *Watchdog) Register(keyId string, verify Verifier, policies ...string) error {
+	if keyId == "" || verify == nil || len(policies) == 0 {
+		return fmt.Errorf("invalid registration")
+	}
+	if _, ok := w.keys[keyId]; ok {
+		return fmt.Errorf("key %s is already registered", keyId)
+	}
+	reg := &registration{verify: verify, policies: make(map[string]bool, len(policies))}
+	for _, p := range policies {
+		if p == "" {
+			return fmt.Errorf("empty policy name")
+		}
+		reg.policies[p] = true
+	}
+	w.keys[keyId] = reg
+	return nil
+}
+
+// Head returns the head of the journal ingested so far
+func (w *Watchdog) Head() Head {
+	return w.head
+}
+
+// Ingest checks the next records of the journal. When the chain is broken the record is
+// reported and the watchdog continues from it, so one gap is only reported once
+func (w *Watchdog) Ingest(records ...*Record) *Report {
+	report := new(Report)
+	for _, r := range records {
+		if r == nil {
+			continue
+		}
+		report.Checked++
+		if r.Sequence != w.head.Sequence+1 {
+			report.add(BrokenChain, r, fmt.Sprintf("expected record %d", w.head.Sequence+1))
+		} else if r.Prev != w.head.Digest {
+			report.add(BrokenChain, r, "previous digest does not match")
+		}
+		w.head = Head{Sequence: r.Sequence, Digest: r.Digest()}
+		w.checkRecord(r, report)
+	}
+	report.Head = w.head
+	return report
+}
+
+// Reconcile checks signatures observed outside the journal. Each must verify with
+// its registered key and have an approved record among the ingested ones
+func (w *Watchdog) Reconcile(produced ...*Produced) *Report {
+	report := &Report{Head: w.head}
+	for _, p := range produced {
+		if p == nil {
+			continue
+		}
+		report.Checked++
+		d := Discrepancy{KeyId: p.KeyId}
+		reg, ok := w.keys[p.KeyId]
+		if !ok {
+			d.Kind, d.Reason = UnknownKey, "key is not registered"
+		} else if err := reg.verify(p.Message, p.Signature); err != nil {
+			d.Kind, d.Reason = InvalidSignature, err.Error()
+		} else if !w.approved[signatureDigest(p.KeyId, p.Message, p.Signature)] {
+			d.Kind, d.Reason = Unjournaled, fmt.Sprintf("no approved record for message %x", p.Message)
+		} else {
+			continue
+		}
+		report.Discrepancies = append(report.Discrepancies, d)
+	}
+	return report
+}
+
+// checkRecord verifies the signature of a record against the registered key and policies
+func (w *Watchdog) checkRecord(r *Record, report *Report) {
+	reg, ok := w.keys[r.KeyId]
+	if !ok {
+		report.add(UnknownKey, r, "key is not registered")
+		return
+	}
+	if !reg.policies[r.Decision.Policy] {
+		report.add(UnknownPolicy, r, fmt.Sprintf("policy %s is not registered for the key", r.Decision.Policy))
+	}
+	if len(r.Signature) == 0 {
+		return
+	}
+	if err := reg.verify(r.Message, r.Signature); err != nil {
+		report.add(InvalidSignature, r, err.Error())
+		return
+	}
+	if !r.Decision.Approved {
+		report.add(SignedWhenDenied, r, fmt.Sprintf("policy %s denied the request", r.Decision.Policy))
+		return
+	}
+	w.approved[signatureDigest(r.KeyId, r.Message, r.Signature)] = true
+}
+
+func (r *Report) add(kind Kind, record *Record, reason string) {
+	r.Discrepancies = append(r.Discrepancies, Discrepancy{
+		Kind:     kind,
+		Sequence: record.Sequence,
+		KeyId:    record.KeyId,
+		Reason:   reason,
+	})
+}
+
+// signatureDigest identifies a signature on a message by a key
+func signatureDigest(keyId string, message, signature []byte) [sha256.Size]byte {
+	h := sha256.New()
+	writeField(h, []byte(keyId))
+	writeField(h, message)
+	writeField(h, signature)
+	var out [sha256.Size]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
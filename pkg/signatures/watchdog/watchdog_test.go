@@ -0,0 +1,205 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package watchdog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/signatures/bls/bls_sig"
+	"github.com/etclab/kryptology/pkg/ted25519/ted25519"
+)
+
+type signer struct {
+	keyId string
+	sign  func(message []byte) []byte
+}
+
+func newBlsSigner(t *testing.T, w *Watchdog, keyId string, policies ...string) *signer {
+	scheme := bls_sig.NewSigPop()
+	pk, sk, err := scheme.Keygen()
+	require.NoError(t, err)
+	require.NoError(t, w.Register(keyId, BlsVerifier(scheme, pk), policies...))
+	return &signer{keyId, func(message []byte) []byte {
+		sig, err := scheme.Sign(sk, message)
+		require.NoError(t, err)
+		out, err := sig.MarshalBinary()
+		require.NoError(t, err)
+		return out
+	}}
+}
+
+func newEd25519Signer(t *testing.T, w *Watchdog, keyId string, policies ...string) *signer {
+	pk, sk, err := ted25519.GenerateKey(crand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, w.Register(keyId, Ed25519Verifier(pk), policies...))
+	return &signer{keyId, func(message []byte) []byte {
+		sig, err := ted25519.Sign(sk, message)
+		require.NoError(t, err)
+		return sig
+	}}
+}
+
+func newEcdsaSigner(t *testing.T, w *Watchdog, keyId string, policies ...string) *signer {
+	sk, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	require.NoError(t, err)
+	pk := &curves.EcPoint{Curve: sk.Curve, X: sk.X, Y: sk.Y}
+	require.NoError(t, w.Register(keyId, EcdsaVerifier(pk, sha256.New), policies...))
+	return &signer{keyId, func(message []byte) []byte {
+		digest := sha256.Sum256(message)
+		r, s, err := ecdsa.Sign(crand.Reader, sk, digest[:])
+		require.NoError(t, err)
+		out := make([]byte, 64)
+		r.FillBytes(out[:32])
+		s.FillBytes(out[32:])
+		return out
+	}}
+}
+
+func kinds(report *Report) []Kind {
+	var out []Kind
+	for _, d := range report.Discrepancies {
+		out = append(out, d.Kind)
+	}
+	return out
+}
+
+func TestWatchdogCleanJournal(t *testing.T) {
+	w := New()
+	signers := []*signer{
+		newBlsSigner(t, w, "bls", "withdrawal"),
+		newEd25519Signer(t, w, "ed25519", "withdrawal", "staking"),
+		newEcdsaSigner(t, w, "ecdsa", "withdrawal"),
+	}
+	journal := NewJournal()
+	var produced []*Produced
+	for i, s := range signers {
+		message := []byte{byte(i), 1, 2, 3}
+		sig := s.sign(message)
+		_, err := journal.Append(s.keyId, message, sig, Decision{Policy: "withdrawal", Approved: true})
+		require.NoError(t, err)
+		produced = append(produced, &Produced{KeyId: s.keyId, Message: message, Signature: sig})
+		_, err = journal.Append(s.keyId, []byte("denied"), nil, Decision{Policy: "withdrawal"})
+		require.NoError(t, err)
+	}
+
+	// Ingest in two batches
+	report := w.Ingest(journal.Records(0)[:2]...)
+	require.True(t, report.Ok(), report.Discrepancies)
+	report = w.Ingest(journal.Records(2)...)
+	require.True(t, report.Ok(), report.Discrepancies)
+	require.Equal(t, 4, report.Checked)
+	require.Equal(t, journal.Head(), report.Head)
+	require.Equal(t, journal.Head(), w.Head())
+
+	report = w.Reconcile(produced...)
+	require.True(t, report.Ok(), report.Discrepancies)
+	require.Equal(t, 3, report.Checked)
+}
+
+func TestWatchdogDiscrepancies(t *testing.T) {
+	w := New()
+	bls := newBlsSigner(t, w, "bls", "withdrawal")
+	ed := newEd25519Signer(t, w, "ed25519", "withdrawal")
+	journal := NewJournal()
+
+	good := []byte("good")
+	_, err := journal.Append(bls.keyId, good, bls.sign(good), Decision{Policy: "withdrawal", Approved: true})
+	require.NoError(t, err)
+	// The signature of another message
+	_, err = journal.Append(ed.keyId, []byte("claimed"), ed.sign([]byte("other")), Decision{Policy: "withdrawal", Approved: true})
+	require.NoError(t, err)
+	_, err = journal.Append(ed.keyId, good, ed.sign(good), Decision{Policy: "staking", Approved: true})
+	require.NoError(t, err)
+	_, err = journal.Append("unknown", good, []byte{1}, Decision{Policy: "withdrawal", Approved: true})
+	require.NoError(t, err)
+	records := journal.Records(0)
+
+	report := w.Ingest(records...)
+	require.Equal(t, []Kind{InvalidSignature, UnknownPolicy, UnknownKey}, kinds(report))
+	require.Equal(t, uint64(2), report.Discrepancies[0].Sequence)
+
+	// A record that was signed although denied, e.g. edited after the fact
+	denied := []byte("denied")
+	tampered := &Record{
+		Sequence:  5,
+		Prev:      journal.Head().Digest,
+		KeyId:     bls.keyId,
+		Message:   denied,
+		Signature: bls.sign(denied),
+		Decision:  Decision{Policy: "withdrawal"},
+	}
+	report = w.Ingest(tampered)
+	require.Equal(t, []Kind{SignedWhenDenied}, kinds(report))
+
+	// A gap in the journal is reported once
+	next, err := journal.Append(bls.keyId, []byte("next"), nil, Decision{Policy: "withdrawal"})
+	require.NoError(t, err)
+	next.Sequence = 7
+	report = w.Ingest(next)
+	require.Equal(t, []Kind{BrokenChain}, kinds(report))
+	after := &Record{Sequence: 8, Prev: next.Digest(), KeyId: bls.keyId, Decision: Decision{Policy: "withdrawal"}}
+	require.True(t, w.Ingest(after).Ok())
+
+	// A rewritten record does not chain to the head
+	w2 := New()
+	_ = w2.Register(bls.keyId, w.keys[bls.keyId].verify, "withdrawal")
+	rewritten := *records[0]
+	rewritten.Message = []byte("rewritten")
+	rewritten.Signature = bls.sign(rewritten.Message)
+	report = w2.Ingest(&rewritten, records[1])
+	require.Contains(t, kinds(report), BrokenChain)
+
+	other := []byte("never journaled")
+	report = w.Reconcile(
+		&Produced{KeyId: bls.keyId, Message: good, Signature: bls.sign(good)},
+		&Produced{KeyId: bls.keyId, Message: other, Signature: bls.sign(other)},
+		&Produced{KeyId: bls.keyId, Message: denied, Signature: bls.sign(denied)},
+		&Produced{KeyId: ed.keyId, Message: other, Signature: bls.sign(other)},
+		&Produced{KeyId: "unknown", Message: other, Signature: []byte{1}},
+	)
+	require.Equal(t, []Kind{Unjournaled, Unjournaled, InvalidSignature, UnknownKey}, kinds(report))
+}
+
+func TestJournalAppend(t *testing.T) {
+	journal := NewJournal()
+	require.Equal(t, Head{}, journal.Head())
+	_, err := journal.Append("", nil, nil, Decision{Policy: "p"})
+	require.Error(t, err)
+	_, err = journal.Append("k", nil, nil, Decision{})
+	require.Error(t, err)
+	_, err = journal.Append("k", nil, []byte{1}, Decision{Policy: "p"})
+	require.Error(t, err)
+
+	first, err := journal.Append("k", []byte{1}, []byte{2}, Decision{Policy: "p", Approved: true})
+	require.NoError(t, err)
+	second, err := journal.Append("k", []byte{1}, nil, Decision{Policy: "p"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), journal.Head().Sequence)
+	require.Equal(t, first.Digest(), second.Prev)
+	require.Equal(t, second.Digest(), journal.Head().Digest)
+	require.NotEqual(t, first.Digest(), second.Digest())
+	require.Len(t, journal.Records(1), 1)
+	require.Nil(t, journal.Records(2))
+}
+
+func TestRegister(t *testing.T) {
+	w := New()
+	verify := func(message, signature []byte) error { return nil }
+	require.Error(t, w.Register("", verify, "p"))
+	require.Error(t, w.Register("k", nil, "p"))
+	require.Error(t, w.Register("k", verify))
+	require.Error(t, w.Register("k", verify, ""))
+	require.NoError(t, w.Register("k", verify, "p"))
+	require.Error(t, w.Register("k", verify, "p"))
+}
@@ -86,7 +86,7 @@ func (receiver *seedOTReceiver) pubKey(r io.Reader) error {
 	if err = input.Verify(); err != nil {
 		return err
 	}
-	return nil
+	return curves.ValidateDHEcPoint(receiver.pub)
 }
 
 // Initializes the choice array from the Packed array
@@ -148,6 +148,10 @@ func (sender *seedOTSender) padTransfer(rw io.ReadWriter) error {
 	result := &seedOtVerification{}
 
 	for i := 0; i < kappa; i++ {
+		// the receiver's points are untrusted, and both rho values must depend on b
+		if err := curves.ValidateDHEcPoint(input[i]); err != nil {
+			return err
+		}
 		d, err := input[i].ScalarMult(sender.b)
 		if err != nil {
 			return err
@@ -160,6 +164,9 @@ func (sender *seedOTSender) padTransfer(rw io.ReadWriter) error {
 		if err != nil {
 			return err
 		}
+		if d.IsIdentity() {
+			return curves.ErrIdentityPoint
+		}
 		sender.Rho[i][1] = sha256.Sum256(append(d.Bytes(), byte(i)))
 		temp0 := sha256.Sum256(sender.Rho[i][0][:])
 		temp0 = sha256.Sum256(temp0[:])
@@ -1,7 +1,12 @@
 package v1
 
 import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
 	"hash"
+	"io"
+	"math/big"
 
 	"github.com/pkg/errors"
 
@@ -60,7 +65,13 @@ var (
 
 // NewAliceDkg creates a new protocol that can compute a DKG as Alice
 func NewAliceDkg(curve *curves.Curve, version uint) *AliceDkg {
-	a := &AliceDkg{Alice: dkg.NewAlice(curve)}
+	return NewAliceDkgWithReader(curve, version, rand.Reader)
+}
+
+// NewAliceDkgWithReader is NewAliceDkg, with all the randomness of Alice read from `reader`. This is meant for
+// reproducible sessions in tests, anything other than a cryptographically secure random source leaks the key share
+func NewAliceDkgWithReader(curve *curves.Curve, version uint, reader io.Reader) *AliceDkg {
+	a := &AliceDkg{Alice: dkg.NewAliceWithReader(curve, reader)}
 	a.steps = []func(*protocol.Message) (*protocol.Message, error){
 		func(input *protocol.Message) (*protocol.Message, error) {
 			bobSeed, err := decodeDkgRound2Input(input)
@@ -136,7 +147,13 @@ func (a *AliceDkg) Result(version uint) (*protocol.Message, error) {
 
 // NewBobDkg Creates a new protocol that can compute a DKG as Bob.
 func NewBobDkg(curve *curves.Curve, version uint) *BobDkg {
-	b := &BobDkg{Bob: dkg.NewBob(curve)}
+	return NewBobDkgWithReader(curve, version, rand.Reader)
+}
+
+// NewBobDkgWithReader is NewBobDkg, with all the randomness of Bob read from `reader`. This is meant for
+// reproducible sessions in tests, anything other than a cryptographically secure random source leaks the key share
+func NewBobDkgWithReader(curve *curves.Curve, version uint, reader io.Reader) *BobDkg {
+	b := &BobDkg{Bob: dkg.NewBobWithReader(curve, reader)}
 	b.steps = []func(message *protocol.Message) (*protocol.Message, error){
 		func(*protocol.Message) (*protocol.Message, error) {
 			commitment, err := b.Round1GenerateRandomSeed()
@@ -207,6 +224,108 @@ func (b *BobDkg) Result(version uint) (*protocol.Message, error) {
 	return EncodeBobDkgOutput(result, version)
 }
 
+// keyCheckMessage is the fixed test vector signed by the key check at the end of the DKG
+var keyCheckMessage = []byte("kryptology dkls dkg key check")
+
+// NewAliceDkgWithKeyCheck is NewAliceDkg followed by a key check: once the DKG completes, Alice and Bob sign a fixed
+// test vector with their new shares and Alice verifies the signature against the joint public key. Result only
+// succeeds after the check passes, so inconsistent shares are caught before the first real signature.
+// Both parties must run the key check.
+func NewAliceDkgWithKeyCheck(curve *curves.Curve, version uint) *AliceDkg {
+	a := NewAliceDkg(curve, version)
+	var signer *sign.Alice
+	dkgFinal := a.steps[len(a.steps)-1]
+	a.steps[len(a.steps)-1] = func(input *protocol.Message) (*protocol.Message, error) {
+		if _, err := dkgFinal(input); err != nil {
+			return nil, err
+		}
+		signer = sign.NewAlice(curve, sha256.New(), a.Output())
+		aliceCommitment, err := signer.Round1GenerateRandomSeed()
+		if err != nil {
+			return nil, err
+		}
+		return encodeSignRound1Output(aliceCommitment, version)
+	}
+	a.steps = append(a.steps,
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Output, err := decodeSignRound3Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round3Output, err := signer.Round3Sign(keyCheckMessage, round2Output)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return encodeSignRound3Output(round3Output, version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			signature, err := DecodeSignature(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if err := verifyKeyCheck(curve, a.Output().PublicKey, signature); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		},
+	)
+	return a
+}
+
+// NewBobDkgWithKeyCheck is NewBobDkg followed by the key check described in NewAliceDkgWithKeyCheck. Bob verifies the
+// signature as he computes it and sends it to Alice.
+func NewBobDkgWithKeyCheck(curve *curves.Curve, version uint) *BobDkg {
+	b := NewBobDkg(curve, version)
+	var signer *sign.Bob
+	b.steps = append(b.steps,
+		func(input *protocol.Message) (*protocol.Message, error) {
+			commitment, err := decodeSignRound2Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			signer = sign.NewBob(curve, sha256.New(), b.Output())
+			round2Output, err := signer.Round2Initialize(commitment)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return encodeSignRound2Output(round2Output, version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input, err := decodeSignRound4Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if err = signer.Round4Final(keyCheckMessage, round4Input); err != nil {
+				return nil, errors.Wrap(err, "key check failed")
+			}
+			return encodeSignature(signer.Signature, version)
+		},
+	)
+	return b
+}
+
+// verifyKeyCheck verifies the key check signature against the joint public key
+func verifyKeyCheck(curve *curves.Curve, publicKey curves.Point, signature *curves.EcdsaSignature) error {
+	ellipticCurve, err := curve.ToEllipticCurve()
+	if err != nil {
+		return errors.Wrap(err, "invalid curve")
+	}
+	uncompressed := publicKey.ToAffineUncompressed()
+	if len(uncompressed) != 65 {
+		return errors.New("the uncompressed form must have exactly 65 bytes")
+	}
+	pk := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	digest := sha256.Sum256(keyCheckMessage)
+	if signature.R == nil || signature.S == nil || !ecdsa.Verify(pk, digest[:], signature.R, signature.S) {
+		return errors.New("key check signature failed to verify")
+	}
+	return nil
+}
+
 // NewAliceSign creates a new protocol that can compute a signature as Alice.
 // Requires dkg state that was produced at the end of DKG.Output().
 func NewAliceSign(curve *curves.Curve, hash hash.Hash, message []byte, dkgResultMessage *protocol.Message, version uint) (*AliceSign, error) {
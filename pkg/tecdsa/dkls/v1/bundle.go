@@ -0,0 +1,374 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package v1
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+)
+
+// walletBundleProtocol is the protocol name of the message holding the bundle contents
+const walletBundleProtocol = "DKLs18-WalletBundle"
+
+const (
+	bundleShareKey         = "share"
+	bundleEpochKey         = "epoch"
+	bundleAuditKey         = "audit"
+	bundleDerivationPrefix = "derivation:"
+	bundlePolicyPrefix     = "policy:"
+
+	bundleKeySize   = 32
+	bundleNonceSize = 12
+	chainCodeSize   = 32
+)
+
+// bundleHeader starts every exported bundle and is authenticated with the contents
+var bundleHeader = []byte("kryptology-dkls-wallet-bundle-v1")
+
+// DerivationNode describes one node of the key derivation tree of a share. The root node has an
+// empty Path and the joint public key of the share, every other node must have its parent in the tree.
+type DerivationNode struct {
+	Path      []uint32
+	ChainCode []byte
+	PublicKey curves.Point
+}
+
+// AuditHead identifies the latest entry of the audit log of a share, Sequence is zero when the log is empty.
+type AuditHead struct {
+	Sequence uint64
+	Digest   [32]byte
+}
+
+// WalletBundle groups everything a device needs to resume using a key share after a migration.
+// Share is the DKG or refresh output message of Alice or Bob.
+type WalletBundle struct {
+	Share        *protocol.Message
+	Derivation   []DerivationNode
+	RefreshEpoch uint64
+	Policy       map[string]string
+	AuditHead    AuditHead
+}
+
+// ExportWalletBundle validates the bundle and encrypts it with the 32-byte `key`, which can come from a
+// KMS or a password based KDF. The output is deterministic: exporting the same bundle with the same key
+// returns the same bytes, so repeated exports can be compared without decrypting them.
+func ExportWalletBundle(bundle *WalletBundle, key []byte) ([]byte, error) {
+	if bundle == nil {
+		return nil, errors.New("bundle is nil")
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	plaintext, err := bundle.marshal()
+	if err != nil {
+		return nil, err
+	}
+	aead, macKey, err := newBundleCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	// The nonce is a MAC of the plaintext so it only repeats when the contents are identical
+	mac := hmac.New(sha256.New, macKey)
+	_, _ = mac.Write(bundleHeader)
+	_, _ = mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:bundleNonceSize]
+
+	out := append([]byte{}, bundleHeader...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, bundleHeader), nil
+}
+
+// ImportWalletBundle decrypts a bundle created by ExportWalletBundle and checks that its contents are consistent.
+func ImportWalletBundle(data, key []byte) (*WalletBundle, error) {
+	if len(data) < len(bundleHeader)+bundleNonceSize || !bytes.Equal(data[:len(bundleHeader)], bundleHeader) {
+		return nil, errors.New("not a wallet bundle")
+	}
+	aead, _, err := newBundleCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := data[len(bundleHeader) : len(bundleHeader)+bundleNonceSize]
+	plaintext, err := aead.Open(nil, nonce, data[len(bundleHeader)+bundleNonceSize:], bundleHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decrypt wallet bundle")
+	}
+	bundle := new(WalletBundle)
+	if err = bundle.unmarshal(plaintext); err != nil {
+		return nil, err
+	}
+	if err = bundle.Validate(); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// PublicKey returns the joint public key of the share in the bundle.
+func (b *WalletBundle) PublicKey() (curves.Point, error) {
+	if b.Share == nil {
+		return nil, errors.New("bundle has no share")
+	}
+	registerTypes()
+	var publicKey curves.Point
+	switch b.Share.Metadata["round"] {
+	case "alice-output":
+		output, err := DecodeAliceDkgResult(b.Share)
+		if err != nil {
+			return nil, err
+		}
+		if output.SecretKeyShare == nil || output.SecretKeyShare.IsZero() || output.SeedOtResult == nil {
+			return nil, errors.New("invalid alice share")
+		}
+		publicKey = output.PublicKey
+	case "bob-output":
+		output, err := DecodeBobDkgResult(b.Share)
+		if err != nil {
+			return nil, err
+		}
+		if output.SecretKeyShare == nil || output.SecretKeyShare.IsZero() || output.SeedOtResult == nil {
+			return nil, errors.New("invalid bob share")
+		}
+		publicKey = output.PublicKey
+	default:
+		return nil, errors.New("share is not a dkg or refresh output")
+	}
+	if publicKey == nil || publicKey.IsIdentity() {
+		return nil, errors.New("invalid share public key")
+	}
+	return publicKey, nil
+}
+
+// Validate checks the internal consistency of the bundle: the share must decode, the derivation tree
+// must be rooted at the joint public key with every node reachable from the root, and the audit head
+// must have a digest exactly when the log is not empty.
+func (b *WalletBundle) Validate() error {
+	if b.Share == nil {
+		return errors.New("bundle has no share")
+	}
+	if b.Share.Protocol != protocol.Dkls18Dkg && b.Share.Protocol != protocol.Dkls18Refresh {
+		return errors.Errorf("unexpected share protocol %s", b.Share.Protocol)
+	}
+	if b.Share.Protocol == protocol.Dkls18Dkg && b.RefreshEpoch != 0 {
+		return errors.New("dkg output cannot have a refresh epoch")
+	}
+	if b.Share.Protocol == protocol.Dkls18Refresh && b.RefreshEpoch == 0 {
+		return errors.New("refresh output must have a refresh epoch")
+	}
+	publicKey, err := b.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	nodes := make(map[string]bool, len(b.Derivation))
+	for _, node := range b.Derivation {
+		path := derivationPath(node.Path)
+		if nodes[path] {
+			return errors.Errorf("duplicate derivation node %s", path)
+		}
+		nodes[path] = true
+		if len(node.ChainCode) != chainCodeSize {
+			return errors.Errorf("invalid chain code for derivation node %s", path)
+		}
+		if node.PublicKey == nil || node.PublicKey.IsIdentity() || node.PublicKey.CurveName() != publicKey.CurveName() {
+			return errors.Errorf("invalid public key for derivation node %s", path)
+		}
+		if len(node.Path) == 0 && !node.PublicKey.Equal(publicKey) {
+			return errors.New("derivation root does not match the share public key")
+		}
+	}
+	for _, node := range b.Derivation {
+		if len(node.Path) > 0 && !nodes[derivationPath(node.Path[:len(node.Path)-1])] {
+			return errors.Errorf("derivation node %s has no parent", derivationPath(node.Path))
+		}
+	}
+
+	if (b.AuditHead.Sequence == 0) != (b.AuditHead.Digest == [32]byte{}) {
+		return errors.New("inconsistent audit log head")
+	}
+	for name := range b.Policy {
+		if name == "" {
+			return errors.New("empty policy name")
+		}
+	}
+	return nil
+}
+
+// marshal encodes the bundle as a canonical protocol message so equal bundles give equal bytes
+func (b *WalletBundle) marshal() ([]byte, error) {
+	share, err := b.Share.MarshalCanonical()
+	if err != nil {
+		return nil, err
+	}
+	m := &protocol.Message{
+		Protocol: walletBundleProtocol,
+		Version:  protocol.Version1,
+		Payloads: map[string][]byte{bundleShareKey: share},
+		Metadata: make(map[string]string, len(b.Policy)),
+	}
+	var epoch [8]byte
+	binary.BigEndian.PutUint64(epoch[:], b.RefreshEpoch)
+	m.Payloads[bundleEpochKey] = epoch[:]
+	var sequence [8]byte
+	binary.BigEndian.PutUint64(sequence[:], b.AuditHead.Sequence)
+	m.Payloads[bundleAuditKey] = append(sequence[:], b.AuditHead.Digest[:]...)
+	for _, node := range b.Derivation {
+		value := append([]byte{}, node.ChainCode...)
+		m.Payloads[bundleDerivationPrefix+derivationPath(node.Path)] = append(value, node.PublicKey.ToAffineCompressed()...)
+	}
+	for name, value := range b.Policy {
+		m.Metadata[bundlePolicyPrefix+name] = value
+	}
+	return m.MarshalCanonical()
+}
+
+func (b *WalletBundle) unmarshal(data []byte) error {
+	m := new(protocol.Message)
+	if err := m.UnmarshalCanonical(data); err != nil {
+		return err
+	}
+	if m.Protocol != walletBundleProtocol || m.Version != protocol.Version1 {
+		return errors.New("unsupported wallet bundle version")
+	}
+	b.Share = new(protocol.Message)
+	if err := b.Share.UnmarshalCanonical(m.Payloads[bundleShareKey]); err != nil {
+		return errors.Wrap(err, "invalid share")
+	}
+	publicKey, err := b.PublicKey()
+	if err != nil {
+		return err
+	}
+	curve := curves.GetCurveByName(publicKey.CurveName())
+	if curve == nil {
+		return errors.New("unknown curve")
+	}
+
+	epoch := m.Payloads[bundleEpochKey]
+	audit := m.Payloads[bundleAuditKey]
+	if len(epoch) != 8 || len(audit) != 8+32 {
+		return errors.New("invalid wallet bundle")
+	}
+	b.RefreshEpoch = binary.BigEndian.Uint64(epoch)
+	b.AuditHead.Sequence = binary.BigEndian.Uint64(audit[:8])
+	copy(b.AuditHead.Digest[:], audit[8:])
+
+	for key, value := range m.Payloads {
+		if key == bundleShareKey || key == bundleEpochKey || key == bundleAuditKey {
+			continue
+		}
+		if !strings.HasPrefix(key, bundleDerivationPrefix) {
+			return errors.Errorf("unexpected wallet bundle field %s", key)
+		}
+		path, err := parseDerivationPath(strings.TrimPrefix(key, bundleDerivationPrefix))
+		if err != nil {
+			return err
+		}
+		if len(value) < chainCodeSize {
+			return errors.New("invalid derivation node")
+		}
+		point, err := curve.Point.FromAffineCompressed(value[chainCodeSize:])
+		if err != nil {
+			return errors.Wrap(err, "invalid derivation node")
+		}
+		b.Derivation = append(b.Derivation, DerivationNode{
+			Path:      path,
+			ChainCode: value[:chainCodeSize],
+			PublicKey: point,
+		})
+	}
+	sortDerivationNodes(b.Derivation)
+
+	for key, value := range m.Metadata {
+		if !strings.HasPrefix(key, bundlePolicyPrefix) {
+			return errors.Errorf("unexpected wallet bundle field %s", key)
+		}
+		if b.Policy == nil {
+			b.Policy = make(map[string]string)
+		}
+		b.Policy[strings.TrimPrefix(key, bundlePolicyPrefix)] = value
+	}
+	return nil
+}
+
+func newBundleCipher(key []byte) (cipher.AEAD, []byte, error) {
+	if len(key) != bundleKeySize {
+		return nil, nil, errors.Errorf("bundle key must be %d bytes", bundleKeySize)
+	}
+	kdf := hkdf.New(sha256.New, key, nil, bundleHeader)
+	encKey := make([]byte, bundleKeySize)
+	macKey := make([]byte, bundleKeySize)
+	if _, err := io.ReadFull(kdf, encKey); err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.ReadFull(kdf, macKey); err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, macKey, nil
+}
+
+// derivationPath formats a path as "m/0/1", the root is "m"
+func derivationPath(path []uint32) string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, index := range path {
+		sb.WriteString("/")
+		sb.WriteString(strconv.FormatUint(uint64(index), 10))
+	}
+	return sb.String()
+}
+
+func parseDerivationPath(s string) ([]uint32, error) {
+	parts := strings.Split(s, "/")
+	if parts[0] != "m" {
+		return nil, errors.Errorf("invalid derivation path %s", s)
+	}
+	path := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || strconv.FormatUint(index, 10) != part {
+			return nil, errors.Errorf("invalid derivation path %s", s)
+		}
+		path = append(path, uint32(index))
+	}
+	return path, nil
+}
+
+// sortDerivationNodes orders nodes by depth, then by index, so parents come before their children
+func sortDerivationNodes(nodes []DerivationNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i].Path, nodes[j].Path
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+}
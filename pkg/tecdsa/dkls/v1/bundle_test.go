@@ -0,0 +1,147 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package v1
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+)
+
+func newTestWalletBundle(t *testing.T, curve *curves.Curve) (*WalletBundle, *WalletBundle) {
+	t.Helper()
+	alice := NewAliceDkg(curve, protocol.Version1)
+	bob := NewBobDkg(curve, protocol.Version1)
+	aErr, bErr := runIteratedProtocol(bob, alice)
+	require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+	require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+	aliceShare, err := alice.Result(protocol.Version1)
+	require.NoError(t, err)
+	bobShare, err := bob.Result(protocol.Version1)
+	require.NoError(t, err)
+
+	publicKey := alice.Alice.Output().PublicKey
+	chainCode := sha256.Sum256([]byte("chain code"))
+	child := publicKey.Add(curve.ScalarBaseMult(curve.Scalar.New(7)))
+	derivation := []DerivationNode{
+		{Path: nil, ChainCode: chainCode[:], PublicKey: publicKey},
+		{Path: []uint32{44}, ChainCode: chainCode[:], PublicKey: child},
+		{Path: []uint32{44, 0}, ChainCode: chainCode[:], PublicKey: child.Double()},
+	}
+	audit := AuditHead{Sequence: 12, Digest: sha256.Sum256([]byte("entry 12"))}
+	policy := map[string]string{"max-amount": "1000", "allowed-chains": "eth,btc"}
+	return &WalletBundle{Share: aliceShare, Derivation: derivation, Policy: policy, AuditHead: audit},
+		&WalletBundle{Share: bobShare, Derivation: derivation[:1]}
+}
+
+func TestWalletBundleExportImport(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		aliceBundle, bobBundle := newTestWalletBundle(t, curve)
+		key := make([]byte, 32)
+		_, err := crand.Read(key)
+		require.NoError(t, err)
+
+		for _, bundle := range []*WalletBundle{aliceBundle, bobBundle} {
+			data, err := ExportWalletBundle(bundle, key)
+			require.NoError(t, err)
+			again, err := ExportWalletBundle(bundle, key)
+			require.NoError(t, err)
+			require.Equal(t, data, again)
+
+			imported, err := ImportWalletBundle(data, key)
+			require.NoError(t, err)
+			require.Equal(t, bundle.Share.Payloads, imported.Share.Payloads)
+			require.Equal(t, bundle.Share.Metadata, imported.Share.Metadata)
+			require.Equal(t, bundle.RefreshEpoch, imported.RefreshEpoch)
+			require.Equal(t, bundle.AuditHead, imported.AuditHead)
+			require.Equal(t, len(bundle.Policy), len(imported.Policy))
+			for k, v := range bundle.Policy {
+				require.Equal(t, v, imported.Policy[k])
+			}
+			require.Len(t, imported.Derivation, len(bundle.Derivation))
+			for i, node := range bundle.Derivation {
+				require.Equal(t, len(node.Path), len(imported.Derivation[i].Path))
+				require.Equal(t, node.ChainCode, imported.Derivation[i].ChainCode)
+				require.True(t, node.PublicKey.Equal(imported.Derivation[i].PublicKey))
+			}
+
+			// The share can be used after the import
+			publicKey, err := imported.PublicKey()
+			require.NoError(t, err)
+			require.True(t, publicKey.Equal(bundle.Derivation[0].PublicKey))
+
+			wrongKey := append([]byte{}, key...)
+			wrongKey[0] ^= 1
+			_, err = ImportWalletBundle(data, wrongKey)
+			require.Error(t, err)
+			data[len(data)-1] ^= 1
+			_, err = ImportWalletBundle(data, key)
+			require.Error(t, err)
+		}
+	}
+}
+
+func TestWalletBundleValidate(t *testing.T) {
+	curve := curves.K256()
+	bundle, _ := newTestWalletBundle(t, curve)
+	require.NoError(t, bundle.Validate())
+	key := make([]byte, 32)
+
+	other := curve.ScalarBaseMult(curve.Scalar.New(3))
+	tests := map[string]func(b WalletBundle) WalletBundle{
+		"missing parent": func(b WalletBundle) WalletBundle {
+			b.Derivation = []DerivationNode{b.Derivation[0], b.Derivation[2]}
+			return b
+		},
+		"wrong root": func(b WalletBundle) WalletBundle {
+			b.Derivation = []DerivationNode{{ChainCode: b.Derivation[0].ChainCode, PublicKey: other}}
+			return b
+		},
+		"duplicate node": func(b WalletBundle) WalletBundle {
+			b.Derivation = append([]DerivationNode{}, b.Derivation[0], b.Derivation[0])
+			return b
+		},
+		"short chain code": func(b WalletBundle) WalletBundle {
+			b.Derivation = []DerivationNode{{ChainCode: []byte{1}, PublicKey: b.Derivation[0].PublicKey}}
+			return b
+		},
+		"audit head without digest": func(b WalletBundle) WalletBundle {
+			b.AuditHead = AuditHead{Sequence: 3}
+			return b
+		},
+		"dkg output with refresh epoch": func(b WalletBundle) WalletBundle {
+			b.RefreshEpoch = 2
+			return b
+		},
+		"empty policy name": func(b WalletBundle) WalletBundle {
+			b.Policy = map[string]string{"": "x"}
+			return b
+		},
+		"not a share": func(b WalletBundle) WalletBundle {
+			b.Share = &protocol.Message{Protocol: protocol.Dkls18Sign, Version: protocol.Version1}
+			return b
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := mutate(*bundle)
+			require.Error(t, b.Validate())
+			_, err := ExportWalletBundle(&b, key)
+			require.Error(t, err)
+		})
+	}
+
+	_, err := ExportWalletBundle(bundle, key[:16])
+	require.Error(t, err)
+	_, err = ImportWalletBundle([]byte("kryptology"), key)
+	require.Error(t, err)
+}
@@ -71,13 +71,21 @@ func produceOTResults(curve *curves.Curve) (*simplest.ReceiverOutput, *simplest.
 		oneTimePadDecryptionKey[i] = oneTimePadEncryptionKeys[i][randomChoiceBits[i]]
 	}
 
+	// there are no messages to bind, but both parties must agree on the transcript
+	transcript := [simplest.DigestSize]byte{}
+	if _, err := rand.Read(transcript[:]); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
 	senderOutput := &simplest.SenderOutput{
 		OneTimePadEncryptionKeys: oneTimePadEncryptionKeys,
+		Transcript:               transcript,
 	}
 	receiverOutput := &simplest.ReceiverOutput{
 		PackedRandomChoiceBits:  packedRandomChoiceBits,
 		RandomChoiceBits:        randomChoiceBits,
 		OneTimePadDecryptionKey: oneTimePadDecryptionKey,
+		Transcript:              transcript,
 	}
 	return receiverOutput, senderOutput, nil
 }
@@ -11,6 +11,7 @@ package dkg
 
 import (
 	"crypto/rand"
+	"io"
 
 	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
@@ -76,6 +77,9 @@ type Alice struct {
 	curve *curves.Curve
 
 	transcript *merlin.Transcript
+
+	// reader is the source of all the randomness of Alice.
+	reader io.Reader
 }
 
 // Bob struct encoding Bob's state during one execution of the overall signing algorithm.
@@ -101,6 +105,9 @@ type Bob struct {
 	curve *curves.Curve
 
 	transcript *merlin.Transcript
+
+	// reader is the source of all the randomness of Bob.
+	reader io.Reader
 }
 
 // Round2Output contains the output of the 2nd round of DKG.
@@ -114,18 +121,32 @@ type Round2Output struct {
 
 // NewAlice creates a party that can participate in 2-of-2 DKG and threshold signature.
 func NewAlice(curve *curves.Curve) *Alice {
+	return NewAliceWithReader(curve, rand.Reader)
+}
+
+// NewAliceWithReader is NewAlice, with all the randomness of Alice read from `reader`. Anything other than a
+// cryptographically secure random source leaks Alice's key share; this is meant for reproducible sessions in tests.
+func NewAliceWithReader(curve *curves.Curve, reader io.Reader) *Alice {
 	return &Alice{
 		curve:      curve,
 		transcript: merlin.NewTranscript("Coinbase_DKLs_DKG"),
+		reader:     reader,
 	}
 }
 
 // NewBob creates a party that can participate in 2-of-2 DKG and threshold signature. This party
 // is the receiver of the signature at the end.
 func NewBob(curve *curves.Curve) *Bob {
+	return NewBobWithReader(curve, rand.Reader)
+}
+
+// NewBobWithReader is NewBob, with all the randomness of Bob read from `reader`. Anything other than a
+// cryptographically secure random source leaks Bob's key share; this is meant for reproducible sessions in tests.
+func NewBobWithReader(curve *curves.Curve, reader io.Reader) *Bob {
 	return &Bob{
 		curve:      curve,
 		transcript: merlin.NewTranscript("Coinbase_DKLs_DKG"),
+		reader:     reader,
 	}
 }
 
@@ -136,7 +157,7 @@ func NewBob(curve *curves.Curve) *Bob {
 // we do it by having each party sample 32 bytes, then by appending _both_ as salts. secure if either party is honest
 func (bob *Bob) Round1GenerateRandomSeed() ([simplest.DigestSize]byte, error) {
 	bobSeed := [simplest.DigestSize]byte{}
-	if _, err := rand.Read(bobSeed[:]); err != nil {
+	if _, err := io.ReadFull(bob.reader, bobSeed[:]); err != nil {
 		return [simplest.DigestSize]byte{}, errors.Wrap(err, "generating random bytes in bob DKG round 1 generate")
 	}
 	bob.transcript.AppendMessage([]byte("session_id_bob"), bobSeed[:]) // note: bob appends first here
@@ -146,7 +167,7 @@ func (bob *Bob) Round1GenerateRandomSeed() ([simplest.DigestSize]byte, error) {
 // Round2CommitToProof steps 1) and 2) of protocol 2 on page 7.
 func (alice *Alice) Round2CommitToProof(bobSeed [simplest.DigestSize]byte) (*Round2Output, error) {
 	aliceSeed := [simplest.DigestSize]byte{}
-	if _, err := rand.Read(aliceSeed[:]); err != nil {
+	if _, err := io.ReadFull(alice.reader, aliceSeed[:]); err != nil {
 		return nil, errors.Wrap(err, "generating random bytes in bob DKG round 1 generate")
 	}
 	alice.transcript.AppendMessage([]byte("session_id_bob"), bobSeed[:])
@@ -155,14 +176,14 @@ func (alice *Alice) Round2CommitToProof(bobSeed [simplest.DigestSize]byte) (*Rou
 	var err error
 	uniqueSessionId := [simplest.DigestSize]byte{} // note: will use and re-use this below for sub-session IDs.
 	copy(uniqueSessionId[:], alice.transcript.ExtractBytes([]byte("salt for simplest OT"), simplest.DigestSize))
-	alice.receiver, err = simplest.NewReceiver(alice.curve, kos.Kappa, uniqueSessionId)
+	alice.receiver, err = simplest.NewReceiverWithReader(alice.curve, kos.Kappa, uniqueSessionId, alice.reader)
 	if err != nil {
 		return nil, errors.Wrap(err, "alice constructing new seed OT receiver in Alice DKG round 1")
 	}
 
-	alice.secretKeyShare = alice.curve.Scalar.Random(rand.Reader)
+	alice.secretKeyShare = alice.curve.Scalar.Random(alice.reader)
 	copy(uniqueSessionId[:], alice.transcript.ExtractBytes([]byte("salt for alice schnorr"), simplest.DigestSize))
-	alice.prover = schnorr.NewProver(alice.curve, nil, uniqueSessionId[:])
+	alice.prover = schnorr.NewProverWithReader(alice.curve, nil, uniqueSessionId[:], alice.reader)
 	var commitment schnorr.Commitment
 	alice.proof, commitment, err = alice.prover.ProveCommit(alice.secretKeyShare) // will mutate `pkA`
 	if err != nil {
@@ -184,15 +205,15 @@ func (bob *Bob) Round3SchnorrProve(round2Output *Round2Output) (*schnorr.Proof,
 	var err error
 	uniqueSessionId := [simplest.DigestSize]byte{} // note: will use and re-use this below for sub-session IDs.
 	copy(uniqueSessionId[:], bob.transcript.ExtractBytes([]byte("salt for simplest OT"), simplest.DigestSize))
-	bob.sender, err = simplest.NewSender(bob.curve, kos.Kappa, uniqueSessionId)
+	bob.sender, err = simplest.NewSenderWithReader(bob.curve, kos.Kappa, uniqueSessionId, bob.reader)
 	if err != nil {
 		return nil, errors.Wrap(err, "bob constructing new OT sender in DKG round 2")
 	}
 	// extract alice's salt in the right order; we won't use this until she reveals her proof and we verify it below
 	copy(bob.aliceSalt[:], bob.transcript.ExtractBytes([]byte("salt for alice schnorr"), simplest.DigestSize))
-	bob.secretKeyShare = bob.curve.Scalar.Random(rand.Reader)
+	bob.secretKeyShare = bob.curve.Scalar.Random(bob.reader)
 	copy(uniqueSessionId[:], bob.transcript.ExtractBytes([]byte("salt for bob schnorr"), simplest.DigestSize))
-	bob.prover = schnorr.NewProver(bob.curve, nil, uniqueSessionId[:])
+	bob.prover = schnorr.NewProverWithReader(bob.curve, nil, uniqueSessionId[:], bob.reader)
 	proof, err := bob.prover.Prove(bob.secretKeyShare)
 	if err != nil {
 		return nil, errors.Wrap(err, "bob schnorr proving in DKG round 2")
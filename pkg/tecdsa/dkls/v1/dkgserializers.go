@@ -1,7 +1,6 @@
 package v1
 
 import (
-	"bytes"
 	"encoding/gob"
 	"fmt"
 
@@ -18,11 +17,11 @@ import (
 
 const payloadKey = "direct"
 
-func newDkgProtocolMessage(payload []byte, round string, version uint) *protocol.Message {
+func newDkgProtocolMessage(round string, version uint) *protocol.Message {
 	return &protocol.Message{
 		Protocol: protocol.Dkls18Dkg,
 		Version:  version,
-		Payloads: map[string][]byte{payloadKey: payload},
+		Payloads: make(map[string][]byte),
 		Metadata: map[string]string{"round": round},
 	}
 }
@@ -39,22 +38,19 @@ func encodeDkgRound1Output(commitment [32]byte, version uint) (*protocol.Message
 		return nil, errors.New("only version 1 is supported")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(&commitment); err != nil {
+	m := newDkgProtocolMessage("1", version)
+	if err := m.EncodePayload(payloadKey, &commitment); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "1", version), nil
+	return m, nil
 }
 
 func decodeDkgRound2Input(m *protocol.Message) ([32]byte, error) {
 	if m.Version != protocol.Version1 {
 		return [32]byte{}, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := [32]byte{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return [32]byte{}, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -64,22 +60,19 @@ func encodeDkgRound2Output(output *dkg.Round2Output, version uint) (*protocol.Me
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(output); err != nil {
+	m := newDkgProtocolMessage("2", version)
+	if err := m.EncodePayload(payloadKey, output); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "2", version), nil
+	return m, nil
 }
 
 func decodeDkgRound3Input(m *protocol.Message) (*dkg.Round2Output, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.Round2Output)
-	if err := dec.Decode(decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -89,22 +82,19 @@ func encodeDkgRound3Output(proof *schnorr.Proof, version uint) (*protocol.Messag
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(proof); err != nil {
+	m := newDkgProtocolMessage("3", version)
+	if err := m.EncodePayload(payloadKey, proof); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "3", version), nil
+	return m, nil
 }
 
 func decodeDkgRound4Input(m *protocol.Message) (*schnorr.Proof, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(schnorr.Proof)
-	if err := dec.Decode(decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -114,22 +104,19 @@ func encodeDkgRound4Output(proof *schnorr.Proof, version uint) (*protocol.Messag
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(proof); err != nil {
+	m := newDkgProtocolMessage("4", version)
+	if err := m.EncodePayload(payloadKey, proof); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "4", version), nil
+	return m, nil
 }
 
 func decodeDkgRound5Input(m *protocol.Message) (*schnorr.Proof, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(schnorr.Proof)
-	if err := dec.Decode(decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -139,22 +126,19 @@ func encodeDkgRound5Output(proof *schnorr.Proof, version uint) (*protocol.Messag
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(proof); err != nil {
+	m := newDkgProtocolMessage("5", version)
+	if err := m.EncodePayload(payloadKey, proof); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "5", version), nil
+	return m, nil
 }
 
 func decodeDkgRound6Input(m *protocol.Message) (*schnorr.Proof, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(schnorr.Proof)
-	if err := dec.Decode(decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -164,22 +148,19 @@ func encodeDkgRound6Output(choices []simplest.ReceiversMaskedChoices, version ui
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(choices); err != nil {
+	m := newDkgProtocolMessage("6", version)
+	if err := m.EncodePayload(payloadKey, choices); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "6", version), nil
+	return m, nil
 }
 
 func decodeDkgRound7Input(m *protocol.Message) ([]simplest.ReceiversMaskedChoices, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.ReceiversMaskedChoices{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -189,22 +170,19 @@ func encodeDkgRound7Output(challenge []simplest.OtChallenge, version uint) (*pro
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(challenge); err != nil {
+	m := newDkgProtocolMessage("7", version)
+	if err := m.EncodePayload(payloadKey, challenge); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "7", version), nil
+	return m, nil
 }
 
 func decodeDkgRound8Input(m *protocol.Message) ([]simplest.OtChallenge, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.OtChallenge{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -214,22 +192,19 @@ func encodeDkgRound8Output(responses []simplest.OtChallengeResponse, version uin
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(responses); err != nil {
+	m := newDkgProtocolMessage("8", version)
+	if err := m.EncodePayload(payloadKey, responses); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "8", version), nil
+	return m, nil
 }
 
 func decodeDkgRound9Input(m *protocol.Message) ([]simplest.OtChallengeResponse, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.OtChallengeResponse{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -239,22 +214,19 @@ func encodeDkgRound9Output(opening []simplest.ChallengeOpening, version uint) (*
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(opening); err != nil {
+	m := newDkgProtocolMessage("9", version)
+	if err := m.EncodePayload(payloadKey, opening); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "9", version), nil
+	return m, nil
 }
 
 func decodeDkgRound10Input(m *protocol.Message) ([]simplest.ChallengeOpening, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.ChallengeOpening{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -266,12 +238,11 @@ func EncodeAliceDkgOutput(result *dkg.AliceOutput, version uint) (*protocol.Mess
 		return nil, errors.New("only version 1 is supported")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(result); err != nil {
+	m := newDkgProtocolMessage("alice-output", version)
+	if err := m.EncodePayload(payloadKey, result); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "alice-output", version), nil
+	return m, nil
 }
 
 // DecodeAliceDkgResult deserializes Alice DKG output.
@@ -280,10 +251,8 @@ func DecodeAliceDkgResult(m *protocol.Message) (*dkg.AliceOutput, error) {
 		return nil, errors.New("only version 1 is supported")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.AliceOutput)
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -295,12 +264,11 @@ func EncodeBobDkgOutput(result *dkg.BobOutput, version uint) (*protocol.Message,
 		return nil, errors.New("only version 1 is supported")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(result); err != nil {
+	m := newDkgProtocolMessage("bob-output", version)
+	if err := m.EncodePayload(payloadKey, result); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "bob-output", version), nil
+	return m, nil
 }
 
 // DecodeBobDkgResult deserializes Bob DKG output.
@@ -308,10 +276,8 @@ func DecodeBobDkgResult(m *protocol.Message) (*dkg.BobOutput, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.BobOutput)
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -8,6 +8,7 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"io/ioutil"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/stretchr/testify/require"
@@ -249,6 +251,67 @@ func TestDkgSignProto(t *testing.T) {
 // NOTE: this cold-start test ensures backwards compatibility with durable,
 // encoding DKG state that may exist within production systems like test
 // Breaking changes must consider downstream production impacts.
+// tamperingIterator replaces the message produced by the wrapped iterator at step `at`
+type tamperingIterator struct {
+	protocol.Iterator
+	step   int
+	at     int
+	tamper func(*protocol.Message) *protocol.Message
+}
+
+func (it *tamperingIterator) Next(input *protocol.Message) (*protocol.Message, error) {
+	output, err := it.Iterator.Next(input)
+	if err == nil && it.step == it.at {
+		output = it.tamper(output)
+	}
+	it.step++
+	return output, err
+}
+
+func TestDkgWithKeyCheck(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		aliceDkg := NewAliceDkgWithKeyCheck(curve, protocol.Version1)
+		bobDkg := NewBobDkgWithKeyCheck(curve, protocol.Version1)
+
+		aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+
+		aliceDkgResultMessage, err := aliceDkg.Result(protocol.Version1)
+		require.NoError(t, err)
+		require.NotNil(t, aliceDkgResultMessage)
+		bobDkgResultMessage, err := bobDkg.Result(protocol.Version1)
+		require.NoError(t, err)
+		require.NotNil(t, bobDkgResultMessage)
+
+		// The shares are usable as the output of a plain DKG
+		signV1(t, curve, aliceDkgResultMessage, bobDkgResultMessage)
+	}
+}
+
+func TestDkgWithKeyCheckRejectsBadSignature(t *testing.T) {
+	curve := curves.K256()
+	aliceDkg := NewAliceDkgWithKeyCheck(curve, protocol.Version1)
+	bobDkg := NewBobDkgWithKeyCheck(curve, protocol.Version1)
+
+	// Bob's last message carries the key check signature
+	bob := &tamperingIterator{Iterator: bobDkg, at: 6, tamper: func(m *protocol.Message) *protocol.Message {
+		signature, err := DecodeSignature(m)
+		require.NoError(t, err)
+		signature.S = new(big.Int).Add(signature.S, big.NewInt(1))
+		tampered, err := encodeSignature(signature, protocol.Version1)
+		require.NoError(t, err)
+		return tampered
+	}}
+	aErr, bErr := runIteratedProtocol(bob, aliceDkg)
+	require.Error(t, aErr)
+	require.NoError(t, bErr)
+
+	result, err := aliceDkg.Result(protocol.Version1)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
 func TestSignColdStart(t *testing.T) {
 	// Decode alice/bob state from file
 	aliceDkg, err := ioutil.ReadFile("testdata/alice-dkls-v1-dkg.bin")
@@ -270,6 +333,84 @@ func TestSignColdStart(t *testing.T) {
 	signV1(t, curves.K256(), aliceDkgMessage, bobDkgMessage)
 }
 
+func TestDkgSignProtoAsync(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	curve := curves.K256()
+	connect := func(first, second protocol.Iterator) (*protocol.AsyncIterator, *protocol.AsyncIterator) {
+		a, err := protocol.NewAsyncIterator(ctx, first, true)
+		require.NoError(t, err)
+		b, err := protocol.NewAsyncIterator(ctx, second, false)
+		require.NoError(t, err)
+		protocol.Connect(a, b)
+		protocol.Connect(b, a)
+		return a, b
+	}
+
+	bob, alice := connect(NewBobDkg(curve, protocol.Version1), NewAliceDkg(curve, protocol.Version1))
+	aliceResult, err := alice.Result(protocol.Version1)
+	require.NoError(t, err)
+	bobResult, err := bob.Result(protocol.Version1)
+	require.NoError(t, err)
+
+	msg := []byte("async")
+	aliceSign, err := NewAliceSign(curve, sha3.New256(), msg, aliceResult, protocol.Version1)
+	require.NoError(t, err)
+	bobSign, err := NewBobSign(curve, sha3.New256(), msg, bobResult, protocol.Version1)
+	require.NoError(t, err)
+	aliceAsync, bobAsync := connect(aliceSign, bobSign)
+	require.NoError(t, aliceAsync.Wait())
+	result, err := bobAsync.Result(protocol.Version1)
+	require.NoError(t, err)
+	_, err = DecodeSignature(result)
+	require.NoError(t, err)
+}
+
+// taggedGobCodec is gob with a prefix, standing in for an integrator supplied codec
+type taggedGobCodec struct{}
+
+var gobTag = []byte("tagged")
+
+func (taggedGobCodec) Name() string  { return "tagged-gob" }
+func (taggedGobCodec) Version() uint { return 1 }
+
+func (taggedGobCodec) Encode(v interface{}) ([]byte, error) {
+	data, err := protocol.GobCodec.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, gobTag...), data...), nil
+}
+
+func (taggedGobCodec) Decode(data []byte, v interface{}) error {
+	if !bytes.HasPrefix(data, gobTag) {
+		return fmt.Errorf("missing tag")
+	}
+	return protocol.GobCodec.Decode(data[len(gobTag):], v)
+}
+
+func TestDkgSignProtoWithCodec(t *testing.T) {
+	for _, name := range []string{protocol.Dkls18Dkg, protocol.Dkls18Sign} {
+		require.NoError(t, protocol.SetCodec(name, taggedGobCodec{}))
+		defer func(name string) { require.NoError(t, protocol.SetCodec(name, nil)) }(name)
+	}
+	curve := curves.K256()
+	aliceDkg := NewAliceDkg(curve, protocol.Version1)
+	bobDkg := NewBobDkg(curve, protocol.Version1)
+	aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+	require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+	require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+
+	aliceResult, err := aliceDkg.Result(protocol.Version1)
+	require.NoError(t, err)
+	bobResult, err := bobDkg.Result(protocol.Version1)
+	require.NoError(t, err)
+	require.Equal(t, "tagged-gob/1", aliceResult.Metadata[protocol.CodecMetadataKey])
+	require.True(t, bytes.HasPrefix(aliceResult.Payloads[payloadKey], gobTag))
+
+	signV1(t, curve, aliceResult, bobResult)
+}
+
 func TestEncodeDecode(t *testing.T) {
 	curve := curves.K256()
 
@@ -1,9 +1,6 @@
 package v1
 
 import (
-	"bytes"
-	"encoding/gob"
-
 	"github.com/pkg/errors"
 
 	"github.com/etclab/kryptology/pkg/core/curves"
@@ -13,11 +10,11 @@ import (
 	"github.com/etclab/kryptology/pkg/tecdsa/dkls/v1/refresh"
 )
 
-func newRefreshProtocolMessage(payload []byte, round string, version uint) *protocol.Message {
+func newRefreshProtocolMessage(round string, version uint) *protocol.Message {
 	return &protocol.Message{
 		Protocol: protocol.Dkls18Refresh,
 		Version:  version,
-		Payloads: map[string][]byte{payloadKey: payload},
+		Payloads: make(map[string][]byte),
 		Metadata: map[string]string{"round": round},
 	}
 }
@@ -34,22 +31,19 @@ func encodeRefreshRound1Output(seed curves.Scalar, version uint) (*protocol.Mess
 		return nil, errors.Wrap(err, "version error")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(&seed); err != nil {
+	m := newRefreshProtocolMessage("1", version)
+	if err := m.EncodePayload(payloadKey, &seed); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "1", version), nil
+	return m, nil
 }
 
 func decodeRefreshRound2Input(m *protocol.Message) (curves.Scalar, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(curves.Scalar)
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return *decoded, nil
@@ -59,22 +53,19 @@ func encodeRefreshRound2Output(output *refresh.RefreshRound2Output, version uint
 	if err := versionIsSupported(version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(output); err != nil {
+	m := newRefreshProtocolMessage("2", version)
+	if err := m.EncodePayload(payloadKey, output); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "2", version), nil
+	return m, nil
 }
 
 func decodeRefreshRound3Input(m *protocol.Message) (*refresh.RefreshRound2Output, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(refresh.RefreshRound2Output)
-	if err := dec.Decode(decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -84,22 +75,19 @@ func encodeRefreshRound3Output(choices []simplest.ReceiversMaskedChoices, versio
 	if err := versionIsSupported(version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(choices); err != nil {
+	m := newRefreshProtocolMessage("3", version)
+	if err := m.EncodePayload(payloadKey, choices); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "3", version), nil
+	return m, nil
 }
 
 func decodeRefreshRound4Input(m *protocol.Message) ([]simplest.ReceiversMaskedChoices, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.ReceiversMaskedChoices{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -109,22 +97,19 @@ func encodeRefreshRound4Output(challenge []simplest.OtChallenge, version uint) (
 	if err := versionIsSupported(version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(challenge); err != nil {
+	m := newRefreshProtocolMessage("4", version)
+	if err := m.EncodePayload(payloadKey, challenge); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "4", version), nil
+	return m, nil
 }
 
 func decodeRefreshRound5Input(m *protocol.Message) ([]simplest.OtChallenge, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.OtChallenge{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -134,22 +119,19 @@ func encodeRefreshRound5Output(responses []simplest.OtChallengeResponse, version
 	if err := versionIsSupported(version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(responses); err != nil {
+	m := newRefreshProtocolMessage("5", version)
+	if err := m.EncodePayload(payloadKey, responses); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "5", version), nil
+	return m, nil
 }
 
 func decodeRefreshRound6Input(m *protocol.Message) ([]simplest.OtChallengeResponse, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.OtChallengeResponse{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -159,22 +141,19 @@ func encodeRefreshRound6Output(opening []simplest.ChallengeOpening, version uint
 	if err := versionIsSupported(version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(opening); err != nil {
+	m := newRefreshProtocolMessage("6", version)
+	if err := m.EncodePayload(payloadKey, opening); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "6", version), nil
+	return m, nil
 }
 
 func decodeRefreshRound7Input(m *protocol.Message) ([]simplest.ChallengeOpening, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := []simplest.ChallengeOpening{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -186,12 +165,11 @@ func EncodeAliceRefreshOutput(result *dkg.AliceOutput, version uint) (*protocol.
 		return nil, errors.Wrap(err, "version error")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(result); err != nil {
+	m := newRefreshProtocolMessage("alice-output", version)
+	if err := m.EncodePayload(payloadKey, result); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "alice-output", version), nil
+	return m, nil
 }
 
 // DecodeAliceRefreshResult deserializes Alice refresh output.
@@ -200,10 +178,8 @@ func DecodeAliceRefreshResult(m *protocol.Message) (*dkg.AliceOutput, error) {
 		return nil, errors.Wrap(err, "version error")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.AliceOutput)
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -215,12 +191,11 @@ func EncodeBobRefreshOutput(result *dkg.BobOutput, version uint) (*protocol.Mess
 		return nil, errors.Wrap(err, "version error")
 	}
 	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(result); err != nil {
+	m := newRefreshProtocolMessage("bob-output", version)
+	if err := m.EncodePayload(payloadKey, result); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newRefreshProtocolMessage(buf.Bytes(), "bob-output", version), nil
+	return m, nil
 }
 
 // DecodeBobRefreshResult deserializes Bob refhresh output.
@@ -228,10 +203,8 @@ func DecodeBobRefreshResult(m *protocol.Message) (*dkg.BobOutput, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.BobOutput)
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -0,0 +1,240 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sign
+
+import (
+	"fmt"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+)
+
+// This exposes the two-party subcomputations used by the signing protocol so that protocol variants, such as
+// blind threshold ECDSA, can be built without copying the sign package.
+// Alice holds the multiplicative shares kA and xA of the nonce k = kA * kB and the secret key x = xA * xB,
+// Bob holds kB and xB. Using one multiplication (protocol 5 of the paper) per product, they obtain additive
+// shares of products such as k^-1 and k^-1 * x, which is what the signing protocol needs, or k and k * x.
+// The outputs are only additive shares: combining them, or checking them against public values as the signing
+// protocol does with its Gamma values, is up to the caller.
+
+// Product identifies a product of the multiplicative shares of the nonce k and the secret key x.
+type Product int
+
+const (
+	// NonceInverse is k^-1 = kA^-1 * kB^-1.
+	NonceInverse Product = iota
+	// NonceInverseTimesKey is k^-1 * x = (xA / kA) * (xB / kB).
+	NonceInverseTimesKey
+	// Nonce is k = kA * kB.
+	Nonce
+	// NonceTimesKey is k * x = (kA * xA) * (kB * xB).
+	NonceTimesKey
+)
+
+// String returns the name of the product.
+func (p Product) String() string {
+	switch p {
+	case NonceInverse:
+		return "k^-1"
+	case NonceInverseTimesKey:
+		return "k^-1*x"
+	case Nonce:
+		return "k"
+	case NonceTimesKey:
+		return "k*x"
+	default:
+		return fmt.Sprintf("unknown product %d", int(p))
+	}
+}
+
+// ProductInputs returns a party's inputs to the multiplications computing `products`, given its multiplicative
+// shares `k` of the nonce and `x` of the secret key. `x` may be nil when no product involves the secret key.
+func ProductInputs(products []Product, k, x curves.Scalar) ([]curves.Scalar, error) {
+	if k == nil || k.IsZero() {
+		return nil, fmt.Errorf("nonce share must be non-zero")
+	}
+	kInv, err := k.Invert()
+	if err != nil {
+		return nil, errors.Wrap(err, "inverting nonce share")
+	}
+	inputs := make([]curves.Scalar, len(products))
+	for i, p := range products {
+		if x == nil && (p == NonceInverseTimesKey || p == NonceTimesKey) {
+			return nil, fmt.Errorf("product %s requires a secret key share", p)
+		}
+		switch p {
+		case NonceInverse:
+			inputs[i] = kInv
+		case NonceInverseTimesKey:
+			inputs[i] = x.Mul(kInv)
+		case Nonce:
+			inputs[i] = k.Clone()
+		case NonceTimesKey:
+			inputs[i] = k.Mul(x)
+		default:
+			return nil, fmt.Errorf("unknown product %d", int(p))
+		}
+	}
+	return inputs, nil
+}
+
+// ProductSharesSender is Alice's side of a batch of multiplications. Each multiplication takes one of Alice's
+// scalars and one of Bob's and gives each of them an additive share of the product.
+type ProductSharesSender struct {
+	senders []*MultiplySender
+	shares  []curves.Scalar
+}
+
+// ProductSharesReceiver is Bob's side of a batch of multiplications.
+type ProductSharesReceiver struct {
+	receivers []*MultiplyReceiver
+	shares    []curves.Scalar
+}
+
+// ProductSharesRound1Output is Bob's first message.
+type ProductSharesRound1Output struct {
+	KosRound1Outputs []*kos.Round1Output
+}
+
+// ProductSharesRound2Output is Alice's response, after which Alice has her shares.
+type ProductSharesRound2Output struct {
+	MultiplyRound2Outputs []*MultiplyRound2Output
+}
+
+// multiplySessionIds derives a distinct session id for each multiplication, which is required to safely reuse
+// the seed OTs across multiplications.
+func multiplySessionIds(uniqueSessionId [simplest.DigestSize]byte, count int) [][simplest.DigestSize]byte {
+	transcript := merlin.NewTranscript("Coinbase_DKLs_ProductShares")
+	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
+	ids := make([][simplest.DigestSize]byte, count)
+	for i := range ids {
+		copy(ids[i][:], transcript.ExtractBytes([]byte(fmt.Sprintf("multiply id %d", i)), simplest.DigestSize))
+	}
+	return ids
+}
+
+// NewProductSharesSender creates Alice's side of `count` multiplications. Alice must be the receiver of the seed OT,
+// as for the signing protocol, and both parties must use the same fresh `uniqueSessionId`.
+func NewProductSharesSender(seedOtResults *simplest.ReceiverOutput, curve *curves.Curve, uniqueSessionId [simplest.DigestSize]byte, count int) (*ProductSharesSender, error) {
+	if seedOtResults == nil || curve == nil {
+		return nil, fmt.Errorf("seed OT results and curve are required")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("at least one multiplication is required")
+	}
+	sender := &ProductSharesSender{senders: make([]*MultiplySender, count)}
+	for i, id := range multiplySessionIds(uniqueSessionId, count) {
+		var err error
+		if sender.senders[i], err = NewMultiplySender(seedOtResults, curve, id); err != nil {
+			return nil, errors.Wrapf(err, "creating multiply sender %d", i)
+		}
+	}
+	return sender, nil
+}
+
+// NewProductSharesReceiver creates Bob's side of `count` multiplications. Bob must be the sender of the seed OT.
+func NewProductSharesReceiver(seedOtResults *simplest.SenderOutput, curve *curves.Curve, uniqueSessionId [simplest.DigestSize]byte, count int) (*ProductSharesReceiver, error) {
+	if seedOtResults == nil || curve == nil {
+		return nil, fmt.Errorf("seed OT results and curve are required")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("at least one multiplication is required")
+	}
+	receiver := &ProductSharesReceiver{receivers: make([]*MultiplyReceiver, count)}
+	for i, id := range multiplySessionIds(uniqueSessionId, count) {
+		var err error
+		if receiver.receivers[i], err = NewMultiplyReceiver(seedOtResults, curve, id); err != nil {
+			return nil, errors.Wrapf(err, "creating multiply receiver %d", i)
+		}
+	}
+	return receiver, nil
+}
+
+// Round1Initialize Bob encodes his inputs `betas`, one per multiplication, and starts the multiplications.
+func (receiver *ProductSharesReceiver) Round1Initialize(betas []curves.Scalar) (*ProductSharesRound1Output, error) {
+	if len(betas) != len(receiver.receivers) {
+		return nil, fmt.Errorf("expected %d inputs, got %d", len(receiver.receivers), len(betas))
+	}
+	output := &ProductSharesRound1Output{KosRound1Outputs: make([]*kos.Round1Output, len(betas))}
+	for i, beta := range betas {
+		if beta == nil {
+			return nil, fmt.Errorf("input %d is nil", i)
+		}
+		var err error
+		if output.KosRound1Outputs[i], err = receiver.receivers[i].Round1Initialize(beta); err != nil {
+			return nil, errors.Wrapf(err, "initializing multiplication %d", i)
+		}
+	}
+	return output, nil
+}
+
+// Round2Multiply Alice responds to Bob's message with her inputs `alphas` and obtains her additive shares.
+func (sender *ProductSharesSender) Round2Multiply(alphas []curves.Scalar, round1Output *ProductSharesRound1Output) (*ProductSharesRound2Output, error) {
+	if len(alphas) != len(sender.senders) {
+		return nil, fmt.Errorf("expected %d inputs, got %d", len(sender.senders), len(alphas))
+	}
+	if round1Output == nil || len(round1Output.KosRound1Outputs) != len(sender.senders) {
+		return nil, fmt.Errorf("expected %d multiplications from bob", len(sender.senders))
+	}
+	output := &ProductSharesRound2Output{MultiplyRound2Outputs: make([]*MultiplyRound2Output, len(alphas))}
+	shares := make([]curves.Scalar, len(alphas))
+	for i, alpha := range alphas {
+		if alpha == nil || round1Output.KosRound1Outputs[i] == nil {
+			return nil, fmt.Errorf("input %d is nil", i)
+		}
+		var err error
+		if output.MultiplyRound2Outputs[i], err = sender.senders[i].Round2Multiply(alpha, round1Output.KosRound1Outputs[i]); err != nil {
+			return nil, errors.Wrapf(err, "multiplication %d", i)
+		}
+		shares[i] = sender.senders[i].OutputAdditiveShare()
+	}
+	sender.shares = shares
+	return output, nil
+}
+
+// Round3Multiply Bob checks Alice's response and obtains his additive shares.
+func (receiver *ProductSharesReceiver) Round3Multiply(round2Output *ProductSharesRound2Output) error {
+	if round2Output == nil || len(round2Output.MultiplyRound2Outputs) != len(receiver.receivers) {
+		return fmt.Errorf("expected %d multiplications from alice", len(receiver.receivers))
+	}
+	shares := make([]curves.Scalar, len(receiver.receivers))
+	for i, r := range receiver.receivers {
+		if round2Output.MultiplyRound2Outputs[i] == nil {
+			return fmt.Errorf("multiplication %d is nil", i)
+		}
+		if err := r.Round3Multiply(round2Output.MultiplyRound2Outputs[i]); err != nil {
+			return errors.Wrapf(err, "multiplication %d", i)
+		}
+		shares[i] = r.OutputAdditiveShare()
+	}
+	receiver.shares = shares
+	return nil
+}
+
+// Shares returns Alice's additive shares, in the order of the inputs, or nil before round 2.
+func (sender *ProductSharesSender) Shares() []curves.Scalar {
+	return sender.shares
+}
+
+// Shares returns Bob's additive shares, in the order of the inputs, or nil before round 3.
+func (receiver *ProductSharesReceiver) Shares() []curves.Scalar {
+	return receiver.shares
+}
+
+// OutputAdditiveShare returns the sender's additive share of the product, or nil before round 2.
+func (sender *MultiplySender) OutputAdditiveShare() curves.Scalar {
+	return sender.outputAdditiveShare
+}
+
+// OutputAdditiveShare returns the receiver's additive share of the product, or nil before round 3.
+func (receiver *MultiplyReceiver) OutputAdditiveShare() curves.Scalar {
+	return receiver.outputAdditiveShare
+}
@@ -0,0 +1,107 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sign
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ot/base/simplest"
+	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+	"github.com/etclab/kryptology/pkg/ot/ottest"
+)
+
+func TestProductShares(t *testing.T) {
+	curve := curves.K256()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+
+	kA := curve.Scalar.Random(rand.Reader)
+	kB := curve.Scalar.Random(rand.Reader)
+	xA := curve.Scalar.Random(rand.Reader)
+	xB := curve.Scalar.Random(rand.Reader)
+	k := kA.Mul(kB)
+	x := xA.Mul(xB)
+	kInv, err := k.Invert()
+	require.NoError(t, err)
+
+	products := []Product{NonceInverse, NonceInverseTimesKey, Nonce, NonceTimesKey}
+	expected := []curves.Scalar{kInv, kInv.Mul(x), k, k.Mul(x)}
+	alphas, err := ProductInputs(products, kA, xA)
+	require.NoError(t, err)
+	betas, err := ProductInputs(products, kB, xB)
+	require.NoError(t, err)
+
+	sessionId := [simplest.DigestSize]byte{}
+	_, err = rand.Read(sessionId[:])
+	require.NoError(t, err)
+	alice, err := NewProductSharesSender(baseOtReceiverOutput, curve, sessionId, len(products))
+	require.NoError(t, err)
+	bob, err := NewProductSharesReceiver(baseOtSenderOutput, curve, sessionId, len(products))
+	require.NoError(t, err)
+	require.Nil(t, alice.Shares())
+
+	round1Output, err := bob.Round1Initialize(betas)
+	require.NoError(t, err)
+	round2Output, err := alice.Round2Multiply(alphas, round1Output)
+	require.NoError(t, err)
+	require.NoError(t, bob.Round3Multiply(round2Output))
+
+	for i := range products {
+		sum := alice.Shares()[i].Add(bob.Shares()[i])
+		require.Equal(t, 0, sum.Cmp(expected[i]), "product %s", products[i])
+	}
+
+	// The nonce point can be checked against the shares: R = k * G
+	r := curve.ScalarBaseMult(alice.Shares()[2]).Add(curve.ScalarBaseMult(bob.Shares()[2]))
+	require.True(t, r.Equal(curve.ScalarBaseMult(kA).Mul(kB)))
+}
+
+func TestProductSharesMismatchedSessions(t *testing.T) {
+	curve := curves.K256()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+
+	alice, err := NewProductSharesSender(baseOtReceiverOutput, curve, [simplest.DigestSize]byte{1}, 1)
+	require.NoError(t, err)
+	bob, err := NewProductSharesReceiver(baseOtSenderOutput, curve, [simplest.DigestSize]byte{2}, 1)
+	require.NoError(t, err)
+	round1Output, err := bob.Round1Initialize([]curves.Scalar{curve.Scalar.Random(rand.Reader)})
+	require.NoError(t, err)
+	round2Output, err := alice.Round2Multiply([]curves.Scalar{curve.Scalar.Random(rand.Reader)}, round1Output)
+	require.Error(t, err)
+	require.Nil(t, round2Output)
+}
+
+func TestProductInputs(t *testing.T) {
+	curve := curves.P256()
+	k := curve.Scalar.Random(rand.Reader)
+	_, err := ProductInputs([]Product{NonceTimesKey}, k, nil)
+	require.Error(t, err)
+	_, err = ProductInputs([]Product{NonceInverse}, curve.Scalar.Zero(), nil)
+	require.Error(t, err)
+	_, err = ProductInputs([]Product{Product(10)}, k, nil)
+	require.Error(t, err)
+	inputs, err := ProductInputs([]Product{NonceInverse, Nonce}, k, nil)
+	require.NoError(t, err)
+	require.True(t, inputs[0].Mul(inputs[1]).IsOne())
+
+	_, err = NewProductSharesSender(nil, curve, [simplest.DigestSize]byte{}, 1)
+	require.Error(t, err)
+	_, err = NewProductSharesReceiver(&simplest.SenderOutput{}, curve, [simplest.DigestSize]byte{}, 0)
+	require.Error(t, err)
+	require.Equal(t, "k^-1*x", NonceInverseTimesKey.String())
+}
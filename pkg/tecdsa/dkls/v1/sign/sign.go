@@ -20,9 +20,11 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 
+	"github.com/etclab/kryptology/internal"
 	"github.com/etclab/kryptology/pkg/core/curves"
 	"github.com/etclab/kryptology/pkg/ot/base/simplest"
 	"github.com/etclab/kryptology/pkg/ot/extension/kos"
+	"github.com/etclab/kryptology/pkg/signatures/adaptor"
 	"github.com/etclab/kryptology/pkg/tecdsa/dkls/v1/dkg"
 	"github.com/etclab/kryptology/pkg/zkp/schnorr"
 )
@@ -38,6 +40,8 @@ type Alice struct {
 	publicKey      curves.Point
 	curve          *curves.Curve
 	transcript     *merlin.Transcript
+	digest         []byte       // set once the message digest has been computed
+	adaptor        curves.Point // the adaptor point Y of a pre-signature, nil for a signature
 }
 
 // Bob struct encoding Bob's state during one execution of the overall signing algorithm.
@@ -46,6 +50,9 @@ type Bob struct {
 	// Signature is the resulting digital signature and is the output of this protocol.
 	Signature *curves.EcdsaSignature
 
+	// PreSignature is the output of this protocol instead of Signature when an adaptor point is set.
+	PreSignature *adaptor.EcdsaPreSignature
+
 	hash           hash.Hash // which hash function should we use to compute message
 	seedOtResults  *simplest.SenderOutput
 	secretKeyShare curves.Scalar
@@ -58,6 +65,12 @@ type Bob struct {
 	kB                curves.Scalar
 	dB                curves.Point
 	curve             *curves.Curve
+	digest            []byte // set once the message digest has been computed
+	// adaptor is the adaptor point Y of a pre-signature, nil for a signature. dBAdaptor = k_{B} . Y and
+	// dBProof proves that it has the same discrete log as dB.
+	adaptor   curves.Point
+	dBAdaptor curves.Point
+	dBProof   *adaptor.DleqProof
 }
 
 // NewAlice creates a party that can participate in protocol runs of DKLs sign, in the role of Alice.
@@ -85,6 +98,38 @@ func NewBob(curve *curves.Curve, hash hash.Hash, dkgOutput *dkg.BobOutput) *Bob
 	}
 }
 
+// Write feeds the next part of the message into Alice's digest, so that a message too large to hold in memory
+// can be streamed in, e.g. with io.Copy, before calling Round3Sign with a nil message.
+// Writing after the digest has been computed is an error.
+func (alice *Alice) Write(p []byte) (int, error) {
+	if alice.digest != nil {
+		return 0, errors.New("the message digest has already been computed")
+	}
+	return alice.hash.Write(p)
+}
+
+// Write feeds the next part of the message into Bob's digest, so that a message too large to hold in memory
+// can be streamed in, e.g. with io.Copy, before calling Round4Final with a nil message.
+// Writing after the digest has been computed is an error.
+func (bob *Bob) Write(p []byte) (int, error) {
+	if bob.digest != nil {
+		return 0, errors.New("the message digest has already been computed")
+	}
+	return bob.hash.Write(p)
+}
+
+// finalizeDigest writes the rest of the message to `h` and appends the resulting digest to the transcript,
+// so that the session ids derived from then on, and with them the proofs, fail to verify if the parties
+// did not hash the same message.
+func finalizeDigest(h hash.Hash, transcript *merlin.Transcript, message []byte) ([]byte, error) {
+	if _, err := h.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash")
+	}
+	digest := h.Sum(nil)
+	transcript.AppendMessage([]byte("message digest"), digest)
+	return digest, nil
+}
+
 // SignRound2Output is the output of the 3rd round of the protocol.
 type SignRound2Output struct {
 	// KosRound1Outputs is the output of the first round of OT Extension, stored for future rounds.
@@ -95,6 +140,12 @@ type SignRound2Output struct {
 
 	// Seed is the random value used to derive the joint unique session id.
 	Seed [simplest.DigestSize]byte
+
+	// DBAdaptor is k_{B} . Y for the adaptor point Y of a pre-signature, nil for a signature.
+	DBAdaptor curves.Point
+
+	// DBProof proves that DB and DBAdaptor have the same discrete log with respect to G and Y.
+	DBProof *adaptor.DleqProof
 }
 
 // SignRound3Output is the output of the 3rd round of the protocol.
@@ -113,6 +164,52 @@ type SignRound3Output struct {
 
 	// EtaSig is the Eta_{Sig} from the paper.
 	EtaSig curves.Scalar
+
+	// RAdaptor is k_{A} . k_{B} . Y, the nonce of a pre-signature for the adaptor point Y, nil for a signature.
+	RAdaptor curves.Point
+
+	// RAdaptorProof proves that R and RAdaptor have the same discrete log with respect to D_{B} and DBAdaptor.
+	RAdaptorProof *adaptor.DleqProof
+}
+
+// SetAdaptorPoint makes Alice compute a pre-signature for the adaptor point Y = y*G instead of a signature,
+// see package adaptor. Bob must set the same adaptor point, and Alice must set it before Round3Sign.
+func (alice *Alice) SetAdaptorPoint(adaptorPoint curves.Point) error {
+	if alice == nil || adaptorPoint == nil {
+		return internal.ErrNilArguments
+	}
+	if alice.digest != nil {
+		return internal.ErrInvalidRound
+	}
+	if err := checkAdaptorPoint(alice.curve, adaptorPoint); err != nil {
+		return err
+	}
+	alice.adaptor = adaptorPoint
+	return nil
+}
+
+// SetAdaptorPoint makes Bob compute a pre-signature for the adaptor point Y = y*G instead of a signature, which
+// is stored in PreSignature, see package adaptor. Alice must set the same adaptor point, and Bob must set it
+// before Round2Initialize.
+func (bob *Bob) SetAdaptorPoint(adaptorPoint curves.Point) error {
+	if bob == nil || adaptorPoint == nil {
+		return internal.ErrNilArguments
+	}
+	if bob.dB != nil {
+		return internal.ErrInvalidRound
+	}
+	if err := checkAdaptorPoint(bob.curve, adaptorPoint); err != nil {
+		return err
+	}
+	bob.adaptor = adaptorPoint
+	return nil
+}
+
+func checkAdaptorPoint(curve *curves.Curve, adaptorPoint curves.Point) error {
+	if adaptorPoint.CurveName() != curve.Name || adaptorPoint.IsIdentity() || !adaptorPoint.IsOnCurve() {
+		return errors.New("invalid adaptor point")
+	}
+	return nil
 }
 
 // Round1GenerateRandomSeed first step of the generation of the shared random salt `idExt`
@@ -162,6 +259,15 @@ func (bob *Bob) Round2Initialize(aliceSeed [simplest.DigestSize]byte) (*SignRoun
 	bob.kB = bob.curve.Scalar.Random(rand.Reader)
 	bob.dB = bob.curve.ScalarBaseMult(bob.kB)
 	round2Output.DB = bob.dB
+	if bob.adaptor != nil {
+		bob.dBAdaptor = bob.adaptor.Mul(bob.kB)
+		bob.dBProof, err = adaptor.ProveDleq(bob.curve, bob.kB, bob.curve.NewGeneratorPoint(), bob.adaptor, bob.dB, bob.dBAdaptor, rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "proving the adaptor nonce in bob round 2 initialize")
+		}
+		round2Output.DBAdaptor = bob.dBAdaptor
+		round2Output.DBProof = bob.dBProof
+	}
 	kBInv := bob.curve.Scalar.One().Div(bob.kB)
 
 	round2Output.KosRound1Outputs[0], err = bob.multiplyReceivers[0].Round1Initialize(kBInv)
@@ -181,6 +287,7 @@ func (bob *Bob) Round2Initialize(aliceSeed [simplest.DigestSize]byte) (*SignRoun
 // then to invoke the multiplication on these two input values (stashing the outputs in her running result struct),
 // then to use the _output_ of the multiplication (which she already possesses as of the end of her computation),
 // and use that to compute some final values which will help Bob compute the final signature.
+// The message is appended to whatever was already streamed in with Write; pass nil if it was all streamed.
 func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (*SignRound3Output, error) {
 	alice.transcript.AppendMessage([]byte("session_id_bob"), round2Output.Seed[:])
 
@@ -195,6 +302,12 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 	if multiplySenders[1], err = NewMultiplySender(alice.seedOtResults, alice.curve, uniqueSessionId); err != nil {
 		return nil, errors.Wrap(err, "creating multiply sender 1 in Alice round 4 sign")
 	}
+	if alice.digest != nil {
+		return nil, errors.New("alice has already signed with this session")
+	}
+	if alice.digest, err = finalizeDigest(alice.hash, alice.transcript, message); err != nil {
+		return nil, errors.Wrap(err, "computing message digest in alice round 4 sign")
+	}
 	round3Output := &SignRound3Output{}
 	kPrimeA := alice.curve.Scalar.Random(rand.Reader)
 	round3Output.RPrime = round2Output.DB.Mul(kPrimeA)
@@ -213,6 +326,23 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 	// reassign / stash the below value here just for notational clarity.
 	// this is _the_ key public point R in the ECDSA signature. we'll use its coordinate X in various places.
 	r := round3Output.RSchnorrProof.Statement
+	// the nonce whose X coordinate is in the signature, R itself or k_{A} . k_{B} . Y for a pre-signature
+	nonce := r
+	if alice.adaptor != nil {
+		if round2Output.DBAdaptor == nil {
+			return nil, errors.New("bob did not compute a pre-signature")
+		}
+		g := alice.curve.NewGeneratorPoint()
+		if err = adaptor.VerifyDleq(alice.curve, round2Output.DBProof, g, alice.adaptor, round2Output.DB, round2Output.DBAdaptor); err != nil {
+			return nil, errors.Wrap(err, "verifying bob's adaptor nonce in alice round 4 sign")
+		}
+		round3Output.RAdaptor = round2Output.DBAdaptor.Mul(kA)
+		round3Output.RAdaptorProof, err = adaptor.ProveDleq(alice.curve, kA, round2Output.DB, round2Output.DBAdaptor, r, round3Output.RAdaptor, rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "proving the adaptor nonce in alice round 4 sign")
+		}
+		nonce = round3Output.RAdaptor
+	}
 	phi := alice.curve.Scalar.Random(rand.Reader)
 	kAInv := alice.curve.Scalar.One().Div(kA)
 
@@ -233,15 +363,11 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 		return nil, errors.Wrap(err, "setting hashGamma1 scalar from bytes")
 	}
 	round3Output.EtaPhi = hashGamma1.Add(phi)
-	if _, err = alice.hash.Write(message); err != nil {
-		return nil, errors.Wrap(err, "writing message to hash in alice round 4 sign")
-	}
-	digest := alice.hash.Sum(nil)
-	hOfMAsInteger, err := alice.curve.Scalar.SetBytes(digest)
+	hOfMAsInteger, err := alice.curve.Scalar.SetBytes(alice.digest)
 	if err != nil {
 		return nil, errors.Wrap(err, "setting hOfMAsInteger scalar from bytes")
 	}
-	affineCompressedForm := r.ToAffineCompressed()
+	affineCompressedForm := nonce.ToAffineCompressed()
 	if len(affineCompressedForm) != 33 {
 		return nil, errors.New("the compressed form must be exactly 33 bytes")
 	}
@@ -269,7 +395,16 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 // Bob begins by _finishing_ the OT-based multiplication, using Alice's one and only message to him re: the mult.
 // Bob then move's onto the remainder of Alice's message, which contains extraneous data used to finish the signature.
 // Using this data, Bob completes the signature, which gets stored in `Bob.Sig`. Bob also verifies it.
+// The message is appended to whatever was already streamed in with Write; pass nil if it was all streamed.
 func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) error {
+	if bob.digest != nil {
+		return errors.New("bob has already signed with this session")
+	}
+	digestBytes, err := finalizeDigest(bob.hash, bob.transcript, message)
+	if err != nil {
+		return errors.Wrap(err, "computing message digest in Bob sign round 5 final")
+	}
+	bob.digest = digestBytes
 	if err := bob.multiplyReceivers[0].Round3Multiply(round3Output.MultiplyRound2Outputs[0]); err != nil {
 		return errors.Wrap(err, "error in round 3 multiply 0 within sign round 5")
 	}
@@ -291,8 +426,19 @@ func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) erro
 	if err = schnorr.Verify(round3Output.RSchnorrProof, bob.curve, bob.dB, uniqueSessionId[:]); err != nil {
 		return errors.Wrap(err, "bob's verification of alice's schnorr proof re: r failed")
 	}
+	// the nonce whose X coordinate is in the signature, R itself or k_{A} . k_{B} . Y for a pre-signature
+	nonce := r
+	if bob.adaptor != nil {
+		if round3Output.RAdaptor == nil {
+			return errors.New("alice did not compute a pre-signature")
+		}
+		if err = adaptor.VerifyDleq(bob.curve, round3Output.RAdaptorProof, bob.dB, bob.dBAdaptor, r, round3Output.RAdaptor); err != nil {
+			return errors.Wrap(err, "verifying alice's adaptor nonce in bob sign round 5")
+		}
+		nonce = round3Output.RAdaptor
+	}
 	zero := bob.curve.Scalar.Zero()
-	affineCompressedForm := r.ToAffineCompressed()
+	affineCompressedForm := nonce.ToAffineCompressed()
 	if len(affineCompressedForm) != 33 {
 		return errors.New("the compressed form must be exactly 33 bytes")
 	}
@@ -313,10 +459,6 @@ func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) erro
 	}
 	phi := round3Output.EtaPhi.Sub(gamma1Hashed)
 	theta := bob.multiplyReceivers[0].outputAdditiveShare.Sub(phi.Div(bob.kB))
-	if _, err = bob.hash.Write(message); err != nil {
-		return errors.Wrap(err, "writing message to hash in Bob sign round 5 final")
-	}
-	digestBytes := bob.hash.Sum(nil)
 	digest, err := bob.curve.Scalar.SetBytes(digestBytes)
 	if err != nil {
 		return errors.Wrap(err, "setting digest scalar from bytes")
@@ -335,6 +477,24 @@ func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) erro
 		return errors.Wrap(err, "setting gamma2Hashed scalar from bytes")
 	}
 	scalarS := sigB.Add(round3Output.EtaSig.Sub(gamma2Hashed))
+	if bob.adaptor != nil {
+		// s is the ŝ of the pre-signature, which completes to a signature with 1/y
+		pre := &adaptor.EcdsaPreSignature{
+			R:    nonce,
+			RHat: r,
+			SHat: scalarS,
+			Links: []*adaptor.EcdsaNonceLink{
+				{RHat: bob.dB, R: bob.dBAdaptor, Proof: bob.dBProof},
+				{RHat: r, R: nonce, Proof: round3Output.RAdaptorProof},
+			},
+		}
+		if err = adaptor.EcdsaPreVerify(bob.publicKey, digestBytes, bob.adaptor, pre); err != nil {
+			return errors.Wrap(err, "final pre-signature failed to verify")
+		}
+		bob.Signature = nil
+		bob.PreSignature = pre
+		return nil
+	}
 	bob.Signature.S = scalarS.BigInt()
 	if bob.Signature.S.Bit(255) == 1 {
 		bob.Signature.S = scalarS.Neg().BigInt()
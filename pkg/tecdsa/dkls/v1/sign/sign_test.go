@@ -7,7 +7,11 @@
 package sign
 
 import (
+	"bytes"
+	"crypto/ecdsa"
 	"crypto/rand"
+	"io"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -17,6 +21,7 @@ import (
 	"github.com/etclab/kryptology/pkg/ot/base/simplest"
 	"github.com/etclab/kryptology/pkg/ot/extension/kos"
 	"github.com/etclab/kryptology/pkg/ot/ottest"
+	"github.com/etclab/kryptology/pkg/signatures/adaptor"
 	"github.com/etclab/kryptology/pkg/tecdsa/dkls/v1/dkg"
 )
 
@@ -79,3 +84,149 @@ func BenchmarkSign(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestSignStreamedMessage(t *testing.T) {
+	curve := curves.K256()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+
+	secretKeyShareA := curve.Scalar.Random(rand.Reader)
+	secretKeyShareB := curve.Scalar.Random(rand.Reader)
+	publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+	newParties := func() (*Alice, *Bob) {
+		alice := NewAlice(curve, sha3.New256(), &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey})
+		bob := NewBob(curve, sha3.New256(), &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey})
+		return alice, bob
+	}
+
+	message := make([]byte, 1<<20)
+	_, err = rand.Read(message)
+	require.NoError(t, err)
+
+	t.Run("streamed in chunks", func(t *testing.T) {
+		alice, bob := newParties()
+		buf := make([]byte, 4096)
+		_, err := io.CopyBuffer(alice, bytes.NewReader(message), buf)
+		require.NoError(t, err)
+		// Bob streams all but the last byte and passes that one to Round4Final
+		_, err = io.CopyBuffer(bob, bytes.NewReader(message[:len(message)-1]), buf)
+		require.NoError(t, err)
+
+		seed, err := alice.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		round3Output, err := bob.Round2Initialize(seed)
+		require.NoError(t, err)
+		round4Output, err := alice.Round3Sign(nil, round3Output)
+		require.NoError(t, err)
+		require.NoError(t, bob.Round4Final(message[len(message)-1:], round4Output))
+
+		digest := sha3.Sum256(message)
+		x, y := new(big.Int).SetBytes(publicKey.ToAffineUncompressed()[1:33]), new(big.Int).SetBytes(publicKey.ToAffineUncompressed()[33:])
+		ellipticCurve, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		require.True(t, ecdsa.Verify(&ecdsa.PublicKey{Curve: ellipticCurve, X: x, Y: y}, digest[:], bob.Signature.R, bob.Signature.S))
+
+		_, err = alice.Write([]byte("late"))
+		require.Error(t, err)
+		_, err = bob.Write([]byte("late"))
+		require.Error(t, err)
+	})
+
+	t.Run("different messages", func(t *testing.T) {
+		alice, bob := newParties()
+		_, err := alice.Write(message)
+		require.NoError(t, err)
+		_, err = bob.Write(message[1:])
+		require.NoError(t, err)
+
+		seed, err := alice.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		round3Output, err := bob.Round2Initialize(seed)
+		require.NoError(t, err)
+		round4Output, err := alice.Round3Sign(nil, round3Output)
+		require.NoError(t, err)
+		err = bob.Round4Final(nil, round4Output)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "schnorr proof")
+	})
+}
+
+func TestSignAdaptor(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		hashKeySeed := [simplest.DigestSize]byte{}
+		_, err := rand.Read(hashKeySeed[:])
+		require.NoError(t, err)
+		baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+		require.NoError(t, err)
+
+		secretKeyShareA := curve.Scalar.Random(rand.Reader)
+		secretKeyShareB := curve.Scalar.Random(rand.Reader)
+		publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+		alice := NewAlice(curve, sha3.New256(), &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey})
+		bob := NewBob(curve, sha3.New256(), &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey})
+
+		secret := curve.Scalar.Random(rand.Reader)
+		adaptorPoint := curve.ScalarBaseMult(secret)
+		require.NoError(t, alice.SetAdaptorPoint(adaptorPoint))
+		require.NoError(t, bob.SetAdaptorPoint(adaptorPoint))
+
+		message := []byte("A message.")
+		seed, err := alice.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		round3Output, err := bob.Round2Initialize(seed)
+		require.NoError(t, err)
+		require.Error(t, bob.SetAdaptorPoint(adaptorPoint))
+		round4Output, err := alice.Round3Sign(message, round3Output)
+		require.NoError(t, err)
+		require.NoError(t, bob.Round4Final(message, round4Output), "curve: %s", curve.Name)
+		require.Nil(t, bob.Signature)
+
+		digest := sha3.Sum256(message)
+		require.NoError(t, adaptor.EcdsaPreVerify(publicKey, digest[:], adaptorPoint, bob.PreSignature))
+		require.Error(t, adaptor.EcdsaPreVerify(publicKey, digest[:], curve.ScalarBaseMult(curve.Scalar.Random(rand.Reader)), bob.PreSignature))
+
+		sig, err := adaptor.EcdsaAdapt(bob.PreSignature, secret)
+		require.NoError(t, err)
+		ellipticCurve, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		uncompressed := publicKey.ToAffineUncompressed()
+		pk := &ecdsa.PublicKey{Curve: ellipticCurve, X: new(big.Int).SetBytes(uncompressed[1:33]), Y: new(big.Int).SetBytes(uncompressed[33:])}
+		require.True(t, ecdsa.Verify(pk, digest[:], sig.R, sig.S))
+
+		extracted, err := adaptor.EcdsaExtract(bob.PreSignature, sig, adaptorPoint)
+		require.NoError(t, err)
+		require.Equal(t, 0, extracted.Cmp(secret))
+	}
+}
+
+func TestSignAdaptorMismatch(t *testing.T) {
+	curve := curves.K256()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+	secretKeyShareA := curve.Scalar.Random(rand.Reader)
+	secretKeyShareB := curve.Scalar.Random(rand.Reader)
+	publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+	alice := NewAlice(curve, sha3.New256(), &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey})
+	bob := NewBob(curve, sha3.New256(), &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey})
+
+	// Alice and Bob use different adaptor points
+	require.NoError(t, alice.SetAdaptorPoint(curve.ScalarBaseMult(curve.Scalar.Random(rand.Reader))))
+	require.NoError(t, bob.SetAdaptorPoint(curve.ScalarBaseMult(curve.Scalar.Random(rand.Reader))))
+	require.Error(t, bob.SetAdaptorPoint(curve.NewIdentityPoint()))
+	require.Error(t, bob.SetAdaptorPoint(curves.P256().NewGeneratorPoint()))
+
+	message := []byte("A message.")
+	seed, err := alice.Round1GenerateRandomSeed()
+	require.NoError(t, err)
+	round3Output, err := bob.Round2Initialize(seed)
+	require.NoError(t, err)
+	_, err = alice.Round3Sign(message, round3Output)
+	require.Error(t, err)
+}
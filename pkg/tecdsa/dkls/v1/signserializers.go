@@ -1,9 +1,6 @@
 package v1
 
 import (
-	"bytes"
-	"encoding/gob"
-
 	"github.com/pkg/errors"
 
 	"github.com/etclab/kryptology/pkg/core/curves"
@@ -11,11 +8,11 @@ import (
 	"github.com/etclab/kryptology/pkg/tecdsa/dkls/v1/sign"
 )
 
-func newSignProtocolMessage(payload []byte, round string, version uint) *protocol.Message {
+func newSignProtocolMessage(round string, version uint) *protocol.Message {
 	return &protocol.Message{
 		Protocol: protocol.Dkls18Sign,
 		Version:  version,
-		Payloads: map[string][]byte{payloadKey: payload},
+		Payloads: make(map[string][]byte),
 		Metadata: map[string]string{"round": round},
 	}
 }
@@ -24,22 +21,19 @@ func encodeSignRound1Output(commitment [32]byte, version uint) (*protocol.Messag
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(&commitment); err != nil {
+	m := newSignProtocolMessage("1", version)
+	if err := m.EncodePayload(payloadKey, &commitment); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newSignProtocolMessage(buf.Bytes(), "1", version), nil
+	return m, nil
 }
 
 func decodeSignRound2Input(m *protocol.Message) ([32]byte, error) {
 	if m.Version != protocol.Version1 {
 		return [32]byte{}, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := [32]byte{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return [32]byte{}, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -49,22 +43,19 @@ func encodeSignRound2Output(output *sign.SignRound2Output, version uint) (*proto
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(output); err != nil {
+	m := newSignProtocolMessage("2", version)
+	if err := m.EncodePayload(payloadKey, output); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newSignProtocolMessage(buf.Bytes(), "2", version), nil
+	return m, nil
 }
 
 func decodeSignRound3Input(m *protocol.Message) (*sign.SignRound2Output, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := &sign.SignRound2Output{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -74,22 +65,19 @@ func encodeSignRound3Output(output *sign.SignRound3Output, version uint) (*proto
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(output); err != nil {
+	m := newSignProtocolMessage("3", version)
+	if err := m.EncodePayload(payloadKey, output); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newSignProtocolMessage(buf.Bytes(), "3", version), nil
+	return m, nil
 }
 
 func decodeSignRound4Input(m *protocol.Message) (*sign.SignRound3Output, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := &sign.SignRound3Output{}
-	if err := dec.Decode(&decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, &decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -99,12 +87,11 @@ func encodeSignature(signature *curves.EcdsaSignature, version uint) (*protocol.
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(signature); err != nil {
+	m := newSignProtocolMessage("signature", version)
+	if err := m.EncodePayload(payloadKey, signature); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return newSignProtocolMessage(buf.Bytes(), "signature", version), nil
+	return m, nil
 }
 
 // DecodeSignature serializes the signature.
@@ -112,10 +99,8 @@ func DecodeSignature(m *protocol.Message) (*curves.EcdsaSignature, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := &curves.EcdsaSignature{}
-	if err := dec.Decode(decoded); err != nil {
+	if err := m.DecodePayload(payloadKey, decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -180,7 +180,7 @@ func DerivePublicKey(curve elliptic.Curve, secretKey *big.Int) (*curves.EcPoint,
 	return curves.NewScalarBaseMult(curve, secretKey)
 }
 
-// NewDealerShares generates the Secp256k1 private key shares and public key
+// NewDealerShares generates the private key shares and public key for `curve`
 // if ikm == nil, a new private key will be generated
 func NewDealerShares(curve elliptic.Curve, threshold, total uint32, ikm *big.Int) (*curves.EcPoint, map[uint32]*Share, error) {
 	if total < threshold {
@@ -195,8 +195,8 @@ func NewDealerShares(curve elliptic.Curve, threshold, total uint32, ikm *big.Int
 	if threshold > 255 {
 		return nil, nil, fmt.Errorf("threshold cannot exceed 255")
 	}
-	if curve.Params().BitSize != 256 {
-		return nil, nil, fmt.Errorf("invalid curve size")
+	if err := CheckCurve(curve); err != nil {
+		return nil, nil, err
 	}
 	var err error
 	if ikm == nil {
@@ -0,0 +1,79 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package dealer
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core"
+)
+
+// MinCurveBits is the smallest curve order, in bits, accepted by the GG20 protocol.
+const MinCurveBits = 256
+
+// ModulusExponent is the power of the curve order q that the Paillier modulus N and
+// the proof modulus N~ must cover. The MtA range proofs bound the prover's values by
+// q^3 and q^7 and sample masks from Z_{q^5}, so N ≈ q^8 is required for the
+// homomorphic operations to never wrap around mod N.
+const ModulusExponent = 8
+
+var (
+	// ErrUnsafeCurve is returned when the curve order is too small for GG20.
+	ErrUnsafeCurve = fmt.Errorf("curve order must be at least %d bits", MinCurveBits)
+	// ErrUnsafeModulus is returned when a Paillier or proof modulus is too small for the curve.
+	ErrUnsafeModulus = fmt.Errorf("modulus is too small for the curve order")
+)
+
+// CheckCurve verifies that `curve` can be used with GG20.
+func CheckCurve(curve elliptic.Curve) error {
+	if curve == nil {
+		return internal.ErrNilArguments
+	}
+	if curve.Params().N.BitLen() < MinCurveBits {
+		return ErrUnsafeCurve
+	}
+	return nil
+}
+
+// PaillierPrimeBits returns the size of the safe primes to use for Paillier keys
+// and ProofParams with `curve`. The product of two such primes has the
+// 8·|q| bits needed by the MtA range proofs, which is 1024-bit primes for
+// secp256k1 and P-256 and 1536-bit primes for P-384.
+func PaillierPrimeBits(curve elliptic.Curve) (uint, error) {
+	if err := CheckCurve(curve); err != nil {
+		return 0, err
+	}
+	return uint(ModulusExponent * curve.Params().N.BitLen() / 2), nil
+}
+
+// CheckModulus verifies that `n` is large enough to be used as a Paillier or proof modulus with `curve`.
+// Since the product of two k-bit primes has either 2k-1 or 2k bits, one bit of slack is allowed.
+func CheckModulus(curve elliptic.Curve, n *big.Int) error {
+	if n == nil {
+		return internal.ErrNilArguments
+	}
+	bits, err := PaillierPrimeBits(curve)
+	if err != nil {
+		return err
+	}
+	if n.BitLen() < int(2*bits)-1 {
+		return fmt.Errorf("%w: %d-bit modulus with %s requires at least %d bits", ErrUnsafeModulus, n.BitLen(), curve.Params().Name, 2*bits-1)
+	}
+	return nil
+}
+
+// NewProofParamsForCurve creates new ProofParams sized for `curve`
+func NewProofParamsForCurve(curve elliptic.Curve) (*ProofParams, error) {
+	bits, err := PaillierPrimeBits(curve)
+	if err != nil {
+		return nil, err
+	}
+	return genProofParams(core.GenerateSafePrime, core.Rand, bits)
+}
@@ -0,0 +1,63 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package dealer
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaillierPrimeBits(t *testing.T) {
+	tests := []struct {
+		curve elliptic.Curve
+		bits  uint
+	}{
+		{btcec.S256(), 1024},
+		{elliptic.P256(), 1024},
+		{elliptic.P384(), 1536},
+	}
+	for _, test := range tests {
+		bits, err := PaillierPrimeBits(test.curve)
+		require.NoError(t, err)
+		require.Equal(t, test.bits, bits)
+	}
+
+	_, err := PaillierPrimeBits(elliptic.P224())
+	require.ErrorIs(t, err, ErrUnsafeCurve)
+	_, err = PaillierPrimeBits(nil)
+	require.Error(t, err)
+}
+
+func TestCheckModulus(t *testing.T) {
+	n2047 := new(big.Int).Lsh(big.NewInt(1), 2046)
+	n2048 := new(big.Int).Lsh(big.NewInt(1), 2047)
+	n3072 := new(big.Int).Lsh(big.NewInt(1), 3071)
+
+	require.NoError(t, CheckModulus(btcec.S256(), n2047))
+	require.NoError(t, CheckModulus(elliptic.P256(), n2048))
+	require.NoError(t, CheckModulus(elliptic.P384(), n3072))
+
+	require.ErrorIs(t, CheckModulus(elliptic.P256(), new(big.Int).Rsh(n2047, 1)), ErrUnsafeModulus)
+	require.ErrorIs(t, CheckModulus(elliptic.P384(), n2048), ErrUnsafeModulus)
+	require.ErrorIs(t, CheckModulus(elliptic.P224(), n3072), ErrUnsafeCurve)
+	require.Error(t, CheckModulus(elliptic.P256(), nil))
+}
+
+func TestNewDealerSharesCurves(t *testing.T) {
+	for _, curve := range []elliptic.Curve{btcec.S256(), elliptic.P256(), elliptic.P384()} {
+		pk, shares, err := NewDealerShares(curve, 2, 3, nil)
+		require.NoError(t, err)
+		require.True(t, pk.IsOnCurve())
+		require.Len(t, shares, 3)
+	}
+	_, _, err := NewDealerShares(elliptic.P224(), 2, 3, nil)
+	require.ErrorIs(t, err, ErrUnsafeCurve)
+}
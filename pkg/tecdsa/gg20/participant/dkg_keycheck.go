@@ -0,0 +1,197 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/paillier"
+	"github.com/etclab/kryptology/pkg/sharing/v1"
+	"github.com/etclab/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+// keyCheckMessage is the fixed test vector signed by the key check
+var keyCheckMessage = []byte("kryptology gg20 dkg key check")
+
+// KeyCheckDigest returns the digest signed by the key check
+func KeyCheckDigest() []byte {
+	digest := sha256.Sum256(keyCheckMessage)
+	return digest[:]
+}
+
+// DkgRound4WithKeyCheck is DkgRound4 followed by the first round of the optional key check. All participants
+// sign KeyCheckDigest with their new shares in the key check rounds, which mirror the signing rounds 1 to 6,
+// and KeyCheckOutput only returns the DKG result once the signature verifies against the verification key.
+// Inconsistent shares are caught at the end of the DKG instead of at the first real signature.
+// All participants must run the key check
+func (dp *DkgParticipant) DkgRound4WithKeyCheck(psfProof map[uint32]paillier.PsfProof) (*Round1Bcast, map[uint32]*Round1P2PSend, error) {
+	result, err := dp.DkgRound4(psfProof)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, err := dp.keyCheckSigner(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	bcast, p2p, err := signer.SignRound1()
+	if err != nil {
+		return nil, nil, err
+	}
+	dp.state.result = result
+	dp.state.keyCheck = signer
+	dp.Round = 5
+	return bcast, p2p, nil
+}
+
+// KeyCheckRound2 runs signing round 2 of the key check
+func (dp *DkgParticipant) KeyCheckRound2(params map[uint32]*Round1Bcast, p2p map[uint32]*Round1P2PSend) (map[uint32]*P2PSend, error) {
+	if err := dp.verifyKeyCheckRound(5); err != nil {
+		return nil, err
+	}
+	out, err := dp.state.keyCheck.SignRound2(params, p2p)
+	if err != nil {
+		return nil, err
+	}
+	dp.Round = 6
+	return out, nil
+}
+
+// KeyCheckRound3 runs signing round 3 of the key check
+func (dp *DkgParticipant) KeyCheckRound3(in map[uint32]*P2PSend) (*Round3Bcast, error) {
+	if err := dp.verifyKeyCheckRound(6); err != nil {
+		return nil, err
+	}
+	out, err := dp.state.keyCheck.SignRound3(in)
+	if err != nil {
+		return nil, err
+	}
+	dp.Round = 7
+	return out, nil
+}
+
+// KeyCheckRound4 runs signing round 4 of the key check
+func (dp *DkgParticipant) KeyCheckRound4(in map[uint32]*Round3Bcast) (*Round4Bcast, error) {
+	if err := dp.verifyKeyCheckRound(7); err != nil {
+		return nil, err
+	}
+	out, err := dp.state.keyCheck.SignRound4(in)
+	if err != nil {
+		return nil, err
+	}
+	dp.Round = 8
+	return out, nil
+}
+
+// KeyCheckRound5 runs signing round 5 of the key check
+func (dp *DkgParticipant) KeyCheckRound5(in map[uint32]*Round4Bcast) (*Round5Bcast, map[uint32]*Round5P2PSend, error) {
+	if err := dp.verifyKeyCheckRound(8); err != nil {
+		return nil, nil, err
+	}
+	bcast, p2p, err := dp.state.keyCheck.SignRound5(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	dp.Round = 9
+	return bcast, p2p, nil
+}
+
+// KeyCheckRound6 runs signing round 6 of the key check over KeyCheckDigest
+func (dp *DkgParticipant) KeyCheckRound6(in map[uint32]*Round5Bcast, p2p map[uint32]*Round5P2PSend) (*Round6FullBcast, error) {
+	if err := dp.verifyKeyCheckRound(9); err != nil {
+		return nil, err
+	}
+	out, err := dp.state.keyCheck.SignRound6Full(KeyCheckDigest(), in, p2p)
+	if err != nil {
+		return nil, err
+	}
+	dp.Round = 10
+	return out, nil
+}
+
+// KeyCheckOutput combines the key check signature and returns the DKG result
+// if the signature verifies against the verification key
+func (dp *DkgParticipant) KeyCheckOutput(in map[uint32]*Round6FullBcast) (*DkgResult, error) {
+	if err := dp.verifyKeyCheckRound(10); err != nil {
+		return nil, err
+	}
+	signature, err := dp.state.keyCheck.SignOutput(in)
+	if err != nil {
+		return nil, fmt.Errorf("key check failed: %v", err)
+	}
+	if err = dp.state.result.VerifyKeyCheck(signature); err != nil {
+		return nil, err
+	}
+	dp.Round = 11
+	return dp.state.result, nil
+}
+
+// verifyKeyCheckRound checks the key check has started and is at the expected round
+func (dp *DkgParticipant) verifyKeyCheckRound(round uint) error {
+	if dp == nil || dp.state == nil || dp.state.keyCheck == nil || dp.state.result == nil {
+		return internal.ErrInvalidRound
+	}
+	return dp.verifyDkgRound(round)
+}
+
+// keyCheckSigner returns the signer of the key check, whose cosigners are all the participants
+func (dp *DkgParticipant) keyCheckSigner(result *DkgResult) (*Signer, error) {
+	field := curves.NewField(dp.Curve.Params().N)
+	point, err := curves.NewScalarBaseMult(dp.Curve, result.SigningKeyShare)
+	if err != nil {
+		return nil, err
+	}
+	if int(dp.id) > len(result.PublicShares) || !result.PublicShares[dp.id-1].Equals(point) {
+		return nil, fmt.Errorf("public share does not match the signing key share")
+	}
+	share := &dealer.Share{
+		ShamirShare: v1.NewShamirShare(dp.id, result.SigningKeyShare.Bytes(), field),
+		Point:       point,
+	}
+
+	proofParams := make(map[uint32]*dealer.ProofParams, len(result.PublicShares))
+	publicShares := make(map[uint32]*dealer.PublicShare, len(result.PublicShares))
+	pubKeys := make(map[uint32]*paillier.PublicKey, len(result.PublicShares))
+	for i, publicShare := range result.PublicShares {
+		id := uint32(i + 1)
+		publicShares[id] = &dealer.PublicShare{Point: publicShare}
+		if id == dp.id {
+			proofParams[id] = &dealer.ProofParams{N: dp.state.N, H1: dp.state.H1, H2: dp.state.H2}
+			pubKeys[id] = &result.EncryptionKey.PublicKey
+			continue
+		}
+		data, ok := result.ParticipantData[id]
+		if !ok || data == nil {
+			return nil, fmt.Errorf("missing data for participant %d", id)
+		}
+		proofParams[id] = data.ProofParams
+		pubKeys[id] = data.PublicKey
+	}
+
+	p := Participant{*share, result.EncryptionKey}
+	return p.PrepareToSign(
+		result.VerificationKey,
+		curves.VerifyEcdsa,
+		dp.Curve,
+		&dealer.DistributedKeyGenType{ProofParams: proofParams},
+		publicShares,
+		pubKeys)
+}
+
+// VerifyKeyCheck checks the signature produced by the key check against the
+// verification key of the DKG result
+func (result *DkgResult) VerifyKeyCheck(signature *curves.EcdsaSignature) error {
+	if result == nil || result.VerificationKey == nil || signature == nil {
+		return internal.ErrNilArguments
+	}
+	if !curves.VerifyEcdsa(result.VerificationKey, KeyCheckDigest(), signature) {
+		return fmt.Errorf("key check signature is not valid")
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/etclab/kryptology/pkg/core"
 	"github.com/etclab/kryptology/pkg/paillier"
 	"github.com/etclab/kryptology/pkg/sharing/v1"
+	"github.com/etclab/kryptology/pkg/tecdsa/gg20/dealer"
 	"github.com/etclab/kryptology/pkg/tecdsa/gg20/proof"
 )
 
@@ -71,13 +72,18 @@ func (dp *DkgParticipant) DkgRound1(threshold, total uint32) (*DkgRound1Bcast, e
 		return nil, err
 	}
 
-	// Step 4: ski, pki := PaillierKeyGen(1^k) (generate a 2048-bit Paillier key pair
-	pki, ski, err := paillier.NewKeys()
+	// Step 4: ski, pki := PaillierKeyGen(1^k) (generate a Paillier key pair
+	// with a modulus sized for the curve, 2048 bits for secp256k1 and P-256)
+	primeBits, err := dealer.PaillierPrimeBits(dp.Curve)
+	if err != nil {
+		return nil, err
+	}
+	pki, ski, err := paillier.NewKeysWithPrimeBits(primeBits)
 	if err != nil {
 		return nil, err
 	}
 
-	// Step 5-6: Choose 1024-bit safe primes Pi, Qi, Pi=2pi+1, Qi=2qi+1 where Pi, Qi, pi, qi are primes
+	// Step 5-6: Choose safe primes Pi, Qi, Pi=2pi+1, Qi=2qi+1 where Pi, Qi, pi, qi are primes
 	values := make(chan *big.Int, 2)
 	errors := make(chan error, 2)
 
@@ -85,7 +91,7 @@ func (dp *DkgParticipant) DkgRound1(threshold, total uint32) (*DkgRound1Bcast, e
 	for Pi == Qi {
 		for range []int{1, 2} {
 			go func() {
-				value, err := core.GenerateSafePrime(paillier.PaillierPrimeBits)
+				value, err := core.GenerateSafePrime(primeBits)
 				values <- value
 				errors <- err
 			}()
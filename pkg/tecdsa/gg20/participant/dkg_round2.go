@@ -11,7 +11,6 @@ import (
 
 	"github.com/etclab/kryptology/internal"
 	"github.com/etclab/kryptology/pkg/core"
-	"github.com/etclab/kryptology/pkg/paillier"
 	"github.com/etclab/kryptology/pkg/sharing/v1"
 	"github.com/etclab/kryptology/pkg/tecdsa/gg20/dealer"
 	"github.com/etclab/kryptology/pkg/tecdsa/gg20/proof"
@@ -69,7 +68,11 @@ func (dp *DkgParticipant) DkgRound2(params map[uint32]*DkgRound1Bcast) (*DkgRoun
 	dp.state.otherParticipantData = make(map[uint32]*dkgParticipantData)
 
 	// For j = [1...n]
-	expKeySize := 2 * paillier.PaillierPrimeBits
+	primeBits, err := dealer.PaillierPrimeBits(dp.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	expKeySize := int(2 * primeBits)
 	for id, param := range params {
 		// If i = j, Continue
 		if id == dp.id {
@@ -85,6 +88,9 @@ func (dp *DkgParticipant) DkgRound2(params map[uint32]*DkgRound1Bcast) (*DkgRoun
 			bitlen != expKeySize-1 {
 			return nil, nil, fmt.Errorf("invalid paillier keys")
 		}
+		if err := dealer.CheckModulus(dp.Curve, param.Ni); err != nil {
+			return nil, nil, err
+		}
 
 		// If VerifyCompositeDL(pi_1j^CDL, g, q, h1j, h2j, tildeN_j) = False, Abort
 		cdlParams1.H1 = param.H1i
@@ -139,6 +139,13 @@ func TestDkgRound3Works(t *testing.T) {
 	pk, err := curves.NewScalarBaseMult(curve, new(big.Int).SetBytes(secret12))
 	require.NoError(t, err)
 	require.True(t, participants[1].state.Y.Equals(pk))
+
+	// Check that the public shares commit to the signing key shares
+	for i := 1; i <= playerCnt; i++ {
+		xi, err := curves.NewScalarBaseMult(curve, participants[uint32(i)].state.Xi)
+		require.NoError(t, err)
+		require.True(t, participants[1].state.PublicShares[i-1].Equals(xi))
+	}
 }
 
 func TestDkgRound3RepeatCall(t *testing.T) {
@@ -1042,4 +1049,114 @@ func TestDkgFullRoundsWorks(t *testing.T) {
 	require.Equal(t, dkgR4Out[1].ParticipantData[2].ProofParams, dkgR4Out[3].ParticipantData[2].ProofParams)
 	require.Equal(t, dkgR4Out[1].ParticipantData[3].ProofParams, dkgR4Out[2].ParticipantData[3].ProofParams)
 	require.Equal(t, dkgR4Out[2].ParticipantData[1].ProofParams, dkgR4Out[3].ParticipantData[1].ProofParams)
+
+	// DkgRound4 leaves the participants in round 4, so the key check reuses them
+	t.Run("KeyCheck", func(t *testing.T) {
+		testDkgKeyCheck(t, dkgParticipants, dkgR3Out, dkgR4Out)
+	})
+}
+
+// testDkgKeyCheck runs the key check rounds after round 4 and checks they end in the same results as DkgRound4
+func testDkgKeyCheck(t *testing.T, dkgParticipants map[uint32]*DkgParticipant, dkgR3Out map[uint32]paillier.PsfProof,
+	dkgR4Out map[uint32]*DkgResult) {
+	var err error
+	_, err = dkgParticipants[1].KeyCheckRound2(nil, nil)
+	require.Error(t, err)
+
+	r1Bcast := make(map[uint32]*Round1Bcast, len(dkgParticipants))
+	r1P2P := make(map[uint32]map[uint32]*Round1P2PSend, len(dkgParticipants))
+	for id, dp := range dkgParticipants {
+		r1Bcast[id], r1P2P[id], err = dp.DkgRound4WithKeyCheck(dkgR3Out)
+		require.NoError(t, err)
+	}
+	// The result is only available at the end of the key check
+	_, err = dkgParticipants[1].DkgRound4(dkgR3Out)
+	require.Error(t, err)
+
+	r2P2P := make(map[uint32]map[uint32]*P2PSend, len(dkgParticipants))
+	for id, dp := range dkgParticipants {
+		bcast := make(map[uint32]*Round1Bcast)
+		p2p := make(map[uint32]*Round1P2PSend)
+		for j := range dkgParticipants {
+			if j != id {
+				bcast[j] = r1Bcast[j]
+				p2p[j] = r1P2P[j][id]
+			}
+		}
+		r2P2P[id], err = dp.KeyCheckRound2(bcast, p2p)
+		require.NoError(t, err)
+	}
+	r3Bcast := make(map[uint32]*Round3Bcast, len(dkgParticipants))
+	for id, dp := range dkgParticipants {
+		in := make(map[uint32]*P2PSend)
+		for j := range dkgParticipants {
+			if j != id {
+				in[j] = r2P2P[j][id]
+			}
+		}
+		r3Bcast[id], err = dp.KeyCheckRound3(in)
+		require.NoError(t, err)
+	}
+	r4Bcast := make(map[uint32]*Round4Bcast, len(dkgParticipants))
+	for id, dp := range dkgParticipants {
+		in := make(map[uint32]*Round3Bcast)
+		for j := range dkgParticipants {
+			if j != id {
+				in[j] = r3Bcast[j]
+			}
+		}
+		r4Bcast[id], err = dp.KeyCheckRound4(in)
+		require.NoError(t, err)
+	}
+	r5Bcast := make(map[uint32]*Round5Bcast, len(dkgParticipants))
+	r5P2P := make(map[uint32]map[uint32]*Round5P2PSend, len(dkgParticipants))
+	for id, dp := range dkgParticipants {
+		in := make(map[uint32]*Round4Bcast)
+		for j := range dkgParticipants {
+			if j != id {
+				in[j] = r4Bcast[j]
+			}
+		}
+		r5Bcast[id], r5P2P[id], err = dp.KeyCheckRound5(in)
+		require.NoError(t, err)
+	}
+	r6Bcast := make(map[uint32]*Round6FullBcast, len(dkgParticipants))
+	for id, dp := range dkgParticipants {
+		in := make(map[uint32]*Round5Bcast)
+		p2p := make(map[uint32]*Round5P2PSend)
+		for j := range dkgParticipants {
+			if j != id {
+				in[j] = r5Bcast[j]
+				p2p[j] = r5P2P[j][id]
+			}
+		}
+		r6Bcast[id], err = dp.KeyCheckRound6(in, p2p)
+		require.NoError(t, err)
+	}
+
+	// A wrong signature share fails the key check without a result
+	tampered := make(map[uint32]*Round6FullBcast)
+	for j := range dkgParticipants {
+		if j != 1 {
+			tampered[j] = &Round6FullBcast{new(big.Int).Add(r6Bcast[j].sElement, big.NewInt(1))}
+		}
+	}
+	result, err := dkgParticipants[1].KeyCheckOutput(tampered)
+	require.Error(t, err)
+	require.Nil(t, result)
+
+	for id, dp := range dkgParticipants {
+		in := make(map[uint32]*Round6FullBcast)
+		for j := range dkgParticipants {
+			if j != id {
+				in[j] = r6Bcast[j]
+			}
+		}
+		result, err := dp.KeyCheckOutput(in)
+		require.NoError(t, err)
+		require.Equal(t, dkgR4Out[id], result)
+	}
+
+	bad := &curves.EcdsaSignature{R: big.NewInt(1), S: big.NewInt(1)}
+	require.Error(t, dkgR4Out[1].VerifyKeyCheck(bad))
 }
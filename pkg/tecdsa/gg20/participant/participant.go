@@ -19,6 +19,7 @@ import (
 	"github.com/etclab/kryptology/pkg/paillier"
 	"github.com/etclab/kryptology/pkg/sharing/v1"
 	"github.com/etclab/kryptology/pkg/tecdsa/gg20/dealer"
+	"github.com/etclab/kryptology/pkg/tecdsa/gg20/proof"
 )
 
 // Participant is a tECDSA player that receives information from a trusted dealer
@@ -40,6 +41,30 @@ type Signer struct {
 	Curve     elliptic.Curve
 	Round     uint   // current signing round in our linear state machine
 	state     *state // Accumulated intermediate values associated with signing
+	// MtA range proof parameters, all cosigners must use the same values
+	proofConfig *proof.RangeProofConfig
+}
+
+// SetRangeProofConfig sets the parameters of the MtA range proofs used while signing.
+// All cosigners must use the same configuration and it can only be changed before round 1.
+// A nil `cfg` restores proof.DefaultRangeProofConfig
+func (signer *Signer) SetRangeProofConfig(cfg *proof.RangeProofConfig) error {
+	if signer == nil || signer.Curve == nil {
+		return internal.ErrNilArguments
+	}
+	if signer.Round > 1 {
+		return fmt.Errorf("range proof configuration cannot change after signing has started")
+	}
+	if cfg == nil {
+		signer.proofConfig = nil
+		return nil
+	}
+	if err := cfg.Validate(signer.Curve); err != nil {
+		return err
+	}
+	c := *cfg
+	signer.proofConfig = &c
+	return nil
 }
 
 // NewSigner C=creates a new signer from a dealer-provided output and a specific set of co-signers
@@ -264,9 +289,14 @@ func (p Participant) PrepareToSign(pubKey *curves.EcPoint,
 		if id == p.Identifier {
 			continue
 		}
-		if _, ok := pubKeys[id]; !ok {
+		pk, ok := pubKeys[id]
+		if !ok || pk == nil {
 			return nil, fmt.Errorf("missing public key for signer %v", id)
 		}
+		// The MtA proofs are only sound when every Paillier modulus covers q^8
+		if err := dealer.CheckModulus(curve, pk.N); err != nil {
+			return nil, err
+		}
 		signer.state.cosigners[id] = true
 	}
 	// Store co-signer pubkeys for round 2 and 6
@@ -331,6 +361,9 @@ type dkgstate struct {
 	Xi *big.Int
 	// X1,...,Xn returned from Round 3
 	PublicShares []*curves.EcPoint
+	// The result of Round 4 and the signer of the key check, if it runs
+	result   *DkgResult
+	keyCheck *Signer
 }
 
 // Check DKG round number is valid
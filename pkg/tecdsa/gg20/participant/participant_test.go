@@ -21,6 +21,7 @@ import (
 	"github.com/etclab/kryptology/pkg/core"
 	"github.com/etclab/kryptology/pkg/paillier"
 	"github.com/etclab/kryptology/pkg/tecdsa/gg20/dealer"
+	"github.com/etclab/kryptology/pkg/tecdsa/gg20/proof"
 )
 
 func TestConvertToAdditiveWorks(t *testing.T) {
@@ -187,6 +188,25 @@ func TestVerifyStateMapRoundCheck(t *testing.T) {
 	}
 }
 
+func TestSetRangeProofConfig(t *testing.T) {
+	s := &Signer{Curve: btcec.S256(), Round: 1}
+	cfg := proof.DefaultRangeProofConfig
+	cfg.Repetitions = 2
+	require.NoError(t, s.SetRangeProofConfig(&cfg))
+	require.Equal(t, cfg, *s.proofConfig)
+
+	cfg.Repetitions = 0
+	require.Error(t, s.SetRangeProofConfig(&cfg))
+	require.Equal(t, uint(2), s.proofConfig.Repetitions)
+
+	require.NoError(t, s.SetRangeProofConfig(nil))
+	require.Nil(t, s.proofConfig)
+
+	s.Round = 2
+	require.Error(t, s.SetRangeProofConfig(&proof.DefaultRangeProofConfig))
+	require.Error(t, (&Signer{}).SetRangeProofConfig(nil))
+}
+
 // Test that verifyStateMap checks rounds as expected
 func TestSetCosigners(t *testing.T) {
 	tests := []struct {
@@ -68,17 +68,19 @@ func (signer *Signer) SignRound1() (*Round1Bcast, map[uint32]*Round1P2PSend, err
 	}
 
 	// 5. c_i, r_i = PaillierEncryptAndReturnRandomness(pk_i, k_i)
-	ctxt, r, err := pk.Encrypt(k)
+	// The secret key encrypts under pk_i with the CRT, which is faster.
+	ctxt, r, err := signer.sk.Encrypt(k)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	pp := proof.Proof1Params{
-		Curve: signer.Curve,
-		Pk:    pk,
-		A:     k,
-		C:     ctxt,
-		R:     r,
+		Curve:  signer.Curve,
+		Pk:     pk,
+		A:      k,
+		C:      ctxt,
+		R:      r,
+		Config: signer.proofConfig,
 	}
 	bcast := Round1Bcast{
 		Identifier: signer.id,
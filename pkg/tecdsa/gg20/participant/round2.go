@@ -64,10 +64,12 @@ func (signer *Signer) SignRound2(params map[uint32]*Round1Bcast, p2p map[uint32]
 	pp := &proof.Proof1Params{
 		Curve:        signer.Curve,
 		DealerParams: signer.state.keyGenType.GetProofParams(signer.id),
+		Config:       signer.proofConfig,
 	}
 	rpp := proof.ResponseProofParams{
-		Curve: signer.Curve,
-		B:     signer.publicSharesMap[signer.id].Point,
+		Curve:  signer.Curve,
+		B:      signer.publicSharesMap[signer.id].Point,
+		Config: signer.proofConfig,
 	}
 
 	// 1. For j = [1 ... t+1]
@@ -75,6 +75,7 @@ func (s *Signer) SignRound3(in map[uint32]*P2PSend) (*Round3Bcast, error) {
 		DealerParams: s.state.keyGenType.GetProofParams(s.id),
 		Sk:           s.sk,
 		C1:           s.state.ci,
+		Config:       s.proofConfig,
 	}
 
 	for j, value := range in {
@@ -0,0 +1,111 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core"
+	"github.com/etclab/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+const (
+	// challengeBits is the size of the Fiat-Shamir challenge e returned by core.FiatShamir
+	challengeBits = 256
+	// MinStatisticalSecurity is the smallest statistical security parameter accepted by RangeProofConfig.Validate
+	MinStatisticalSecurity = 80
+	// MaxRepetitions bounds the number of times a range proof can be repeated
+	MaxRepetitions = 16
+)
+
+// RangeProofConfig holds the parameters of the MtA range proofs in [spec] §7.
+// Slack values are exponents of the curve order q: a slack of k means the
+// corresponding value is sampled from, or bounded by, Z_{q^k}.
+type RangeProofConfig struct {
+	// StatisticalSecurity is the minimum number of bits by which the masking
+	// values exceed the challenge-scaled witnesses, which bounds the statistical
+	// distance of the proofs from the simulated ones
+	StatisticalSecurity uint
+	// WitnessSlack sets the range of the mask α and the bound on s1. The paper uses 3
+	WitnessSlack uint
+	// MaskSlack sets the range of the MtA mask β'. The paper uses 5
+	MaskSlack uint
+	// ResponseSlack sets the range of the mask γ and the bound on t1. The paper uses 7
+	ResponseSlack uint
+	// Repetitions is the number of independent proofs with domain separated challenges
+	// that are generated and verified for each statement
+	Repetitions uint
+}
+
+// DefaultRangeProofConfig contains the parameters of [spec] §7
+// with the mitigations from https://eprint.iacr.org/2019/114.pdf
+var DefaultRangeProofConfig = RangeProofConfig{
+	StatisticalSecurity: 128,
+	WitnessSlack:        3,
+	MaskSlack:           5,
+	ResponseSlack:       7,
+	Repetitions:         1,
+}
+
+// rangeProofConfig returns `cfg` or the default configuration when `cfg` is nil
+func rangeProofConfig(cfg *RangeProofConfig) *RangeProofConfig {
+	if cfg == nil {
+		return &DefaultRangeProofConfig
+	}
+	return cfg
+}
+
+// Validate checks the configuration is safe for use with `curve`
+func (cfg RangeProofConfig) Validate(curve elliptic.Curve) error {
+	if err := dealer.CheckCurve(curve); err != nil {
+		return err
+	}
+	if cfg.StatisticalSecurity < MinStatisticalSecurity {
+		return fmt.Errorf("statistical security must be at least %d bits", MinStatisticalSecurity)
+	}
+	if cfg.Repetitions < 1 || cfg.Repetitions > MaxRepetitions {
+		return fmt.Errorf("repetitions must be between 1 and %d", MaxRepetitions)
+	}
+	// α must hide e·a with a < q and β' must be hidden by γ
+	if cfg.WitnessSlack < 2 || cfg.MaskSlack <= cfg.WitnessSlack || cfg.ResponseSlack <= cfg.MaskSlack {
+		return fmt.Errorf("slack values must satisfy 2 <= witness < mask < response")
+	}
+	// t1 = e·β' + γ is used as a Paillier plaintext so it must stay below N
+	if cfg.ResponseSlack >= dealer.ModulusExponent {
+		return fmt.Errorf("response slack must be smaller than %d", dealer.ModulusExponent)
+	}
+	qBits := uint(curve.Params().N.BitLen())
+	// s1 = e·a + α with e < 2^challengeBits and a < q
+	witnessMargin := int((cfg.WitnessSlack-1)*qBits) - challengeBits
+	// t1 = e·β' + γ with β' < q^MaskSlack
+	responseMargin := int((cfg.ResponseSlack-cfg.MaskSlack)*qBits) - challengeBits
+	if witnessMargin < int(cfg.StatisticalSecurity) || responseMargin < int(cfg.StatisticalSecurity) {
+		return fmt.Errorf("slack values do not provide %d bits of statistical security for %s", cfg.StatisticalSecurity, curve.Params().Name)
+	}
+	return nil
+}
+
+// qPow returns q^k
+func qPow(curve elliptic.Curve, k uint) (*big.Int, error) {
+	if curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	return core.Exp(curve.Params().N, big.NewInt(int64(k)), nil)
+}
+
+// withRepetition appends the repetition index to the Fiat-Shamir inputs.
+// The first repetition is hashed exactly as in [spec] so that
+// the default configuration remains compatible
+func withRepetition(rep uint, values ...*big.Int) []*big.Int {
+	if rep == 0 {
+		return values
+	}
+	return append(values, new(big.Int).SetUint64(uint64(rep)))
+}
@@ -0,0 +1,154 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"crypto/elliptic"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	tt "github.com/etclab/kryptology/internal"
+	crypto "github.com/etclab/kryptology/pkg/core"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	paillier "github.com/etclab/kryptology/pkg/paillier"
+	"github.com/etclab/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+func TestRangeProofConfigDefaults(t *testing.T) {
+	for _, curve := range []elliptic.Curve{btcec.S256(), elliptic.P256(), elliptic.P384()} {
+		require.NoError(t, DefaultRangeProofConfig.Validate(curve))
+	}
+	require.Error(t, DefaultRangeProofConfig.Validate(elliptic.P224()))
+	require.Error(t, DefaultRangeProofConfig.Validate(nil))
+	require.Equal(t, &DefaultRangeProofConfig, rangeProofConfig(nil))
+}
+
+func TestRangeProofConfigInvalid(t *testing.T) {
+	curve := btcec.S256()
+	tests := []struct {
+		name string
+		edit func(cfg *RangeProofConfig)
+	}{
+		{"low statistical security", func(cfg *RangeProofConfig) { cfg.StatisticalSecurity = 40 }},
+		{"no repetitions", func(cfg *RangeProofConfig) { cfg.Repetitions = 0 }},
+		{"too many repetitions", func(cfg *RangeProofConfig) { cfg.Repetitions = MaxRepetitions + 1 }},
+		{"witness slack too small", func(cfg *RangeProofConfig) { cfg.WitnessSlack = 1 }},
+		{"mask not above witness", func(cfg *RangeProofConfig) { cfg.MaskSlack = 3 }},
+		{"response not above mask", func(cfg *RangeProofConfig) { cfg.ResponseSlack = 5 }},
+		{"response exceeds modulus", func(cfg *RangeProofConfig) { cfg.ResponseSlack = dealer.ModulusExponent }},
+		{"witness margin too small", func(cfg *RangeProofConfig) { cfg.WitnessSlack = 2; cfg.StatisticalSecurity = 128 }},
+		{"response margin too small", func(cfg *RangeProofConfig) { cfg.ResponseSlack = 6 }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := DefaultRangeProofConfig
+			test.edit(&cfg)
+			require.Error(t, cfg.Validate(curve))
+		})
+	}
+}
+
+func TestMtaWithRangeProofConfig(t *testing.T) {
+	curve := btcec.S256()
+	cfg := &RangeProofConfig{
+		StatisticalSecurity: 128,
+		WitnessSlack:        3,
+		MaskSlack:           4,
+		ResponseSlack:       6,
+		Repetitions:         3,
+	}
+	require.NoError(t, cfg.Validate(curve))
+
+	p := tt.B10("165498465971525536497859961269214938631289964308823560526920537236787050377699904896554622379770774622567664583533323254169290844053351296829514419428489585830394868303448384771151376037064711115810339324861594209655768995895643373763166292366557525131878080032169065959558884224551806641003919879441772258023")
+	q := tt.B10("153220808452726670380485250948911100156879705361932013063379432599289284377538415448437552509228215741069875651231891196863559464003506000735603508391315084830677543632875274002601909274977876224268309554767555583618065737119993835971994691072180460197745186395985316826257903003552375842892383205848110359007")
+	sk, err := paillier.NewSecretKey(p, q)
+	require.NoError(t, err)
+	dealerParams := &dealer.ProofParams{
+		N:  tt.B10("9972886211275433070035994662048032853531452341596222874944455730406324093082012471095835489777525666656101689703091272431312190038636973920385309846023861"),
+		H1: tt.B10("9645317246937167948100761846052703931860463997240776753358695432123565805217588763869923227018453085470821139033044893014220360494788858157967288186449376"),
+		H2: tt.B10("2050268247938630669991905733361921562776155620188477492052136889909625145533819588548316645784225045947395506721263124727029028420360795789170536034891820"),
+	}
+
+	a, err := crypto.Rand(curve.N)
+	require.NoError(t, err)
+	c, r, err := sk.PublicKey.Encrypt(a)
+	require.NoError(t, err)
+
+	// Range proof (1)
+	pp := &Proof1Params{
+		Curve:        curve,
+		Pk:           &sk.PublicKey,
+		DealerParams: dealerParams,
+		A:            a,
+		C:            c,
+		R:            r,
+		Config:       cfg,
+	}
+	pi, err := pp.Prove()
+	require.NoError(t, err)
+	require.Len(t, pi.reps, 2)
+	require.NoError(t, pi.Verify(pp))
+
+	data, err := json.Marshal(pi)
+	require.NoError(t, err)
+	decoded := new(Range1Proof)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.NoError(t, decoded.Verify(pp))
+
+	// A verifier using the default configuration rejects the proof
+	pp.Config = nil
+	require.Error(t, pi.Verify(pp))
+	pp.Config = cfg
+
+	// Tampering with any repetition is detected
+	pi.reps[1].s1 = new(big.Int).Add(pi.reps[1].s1, bi(1))
+	require.Error(t, pi.Verify(pp))
+
+	// MtA response with proofs (2) and (3)
+	b, err := crypto.Rand(curve.N)
+	require.NoError(t, err)
+	B, err := curves.NewScalarBaseMult(curve, b)
+	require.NoError(t, err)
+	rpp := &ResponseProofParams{
+		Curve:        curve,
+		DealerParams: dealerParams,
+		Pk:           &sk.PublicKey,
+		SmallB:       b,
+		C1:           c,
+		B:            B,
+		Config:       cfg,
+	}
+	vp := &ResponseVerifyParams{
+		Curve:        curve,
+		DealerParams: dealerParams,
+		Sk:           sk,
+		C1:           c,
+		B:            B,
+		Config:       cfg,
+	}
+	rp, err := rpp.Prove()
+	require.NoError(t, err)
+	require.Len(t, rp.R2proof.reps, 2)
+	alpha, err := rp.Finalize(vp)
+	require.NoError(t, err)
+	require.NoError(t, crypto.In(alpha, curve.N))
+
+	rpWc, err := rpp.ProveWc()
+	require.NoError(t, err)
+	_, err = rpWc.FinalizeWc(vp)
+	require.NoError(t, err)
+
+	vp.Config = nil
+	_, err = rp.Finalize(vp)
+	require.Error(t, err)
+	_, err = rpWc.FinalizeWc(vp)
+	require.Error(t, err)
+}
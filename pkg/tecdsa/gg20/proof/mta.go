@@ -27,6 +27,8 @@ type ResponseProofParams struct {
 	Pk           *paillier.PublicKey
 	SmallB, C1   *big.Int
 	B            *curves.EcPoint
+	// Config is the range proof configuration, DefaultRangeProofConfig is used when nil
+	Config *RangeProofConfig
 }
 
 // ResponseVerifyParams encapsulates the values over which a range proof (2) is verified.
@@ -37,6 +39,8 @@ type ResponseVerifyParams struct {
 	Sk           *paillier.SecretKey
 	C1           *big.Int
 	B            *curves.EcPoint
+	// Config is the range proof configuration, DefaultRangeProofConfig is used when nil
+	Config *RangeProofConfig
 }
 
 // ResponseFinalizer captures the interface provided by a response proof
@@ -61,6 +65,8 @@ type Proof1Params struct {
 	Pk           *paillier.PublicKey
 	DealerParams *dealer.ProofParams
 	A, C, R      *big.Int
+	// Config is the range proof configuration, DefaultRangeProofConfig is used when nil
+	Config *RangeProofConfig
 }
 
 // randProof1Params encapsulates the random values generated in proof (1)
@@ -73,11 +79,14 @@ type randProof1Params struct {
 // [spec] fig 10
 type Range1Proof struct {
 	z, e, s, s1, s2 *big.Int
+	// reps holds the additional proofs when RangeProofConfig.Repetitions > 1
+	reps []*Range1Proof
 }
 
 // struct for JSON serialization
 type range1ProofJSON struct {
 	Z, E, S, S1, S2 *big.Int
+	Reps            []*Range1Proof `json:",omitempty"`
 }
 
 // proof2Params encapsulates the values over which a range proof (2) is computed.
@@ -88,6 +97,7 @@ type proof2Params struct {
 	pk              *paillier.PublicKey
 	y, r, c1, c2, x *big.Int
 	X               *curves.EcPoint
+	config          *RangeProofConfig
 }
 
 // verifyProof2Params encapsulates the values over which a range proof (2) is computed.
@@ -98,6 +108,7 @@ type verifyProof2Params struct {
 	pk           *paillier.PublicKey
 	c1, c2       *big.Int
 	X            *curves.EcPoint
+	config       *RangeProofConfig
 }
 
 // Range2Proof encapsulates the results returned in proof (2)
@@ -112,22 +123,26 @@ type randProof2Params struct {
 type Range2Proof struct {
 	z, e, s, s1, s2 *big.Int
 	t, t1, t2       *big.Int
+	// reps holds the additional proofs when RangeProofConfig.Repetitions > 1
+	reps []*Range2Proof
 }
 
 // JSON struct for serialization
 type range2ProofJSON struct {
 	Z, E, S, S1, S2 *big.Int
 	T, T1, T2       *big.Int
+	Reps            []*Range2Proof `json:",omitempty"`
 }
 
 // MarshalJSON converts Range1Proof into JSON
 func (r Range1Proof) MarshalJSON() ([]byte, error) {
 	return json.Marshal(range1ProofJSON{
-		Z:  r.z,
-		E:  r.e,
-		S:  r.s,
-		S1: r.s1,
-		S2: r.s2,
+		Z:    r.z,
+		E:    r.e,
+		S:    r.s,
+		S1:   r.s1,
+		S2:   r.s2,
+		Reps: r.reps,
 	})
 }
 
@@ -144,20 +159,22 @@ func (r *Range1Proof) UnmarshalJSON(bytes []byte) error {
 	r.s = proof.S
 	r.s1 = proof.S1
 	r.s2 = proof.S2
+	r.reps = proof.Reps
 	return nil
 }
 
 // MarshalJSON converts Range2Proof into JSON format
 func (rP2 Range2Proof) MarshalJSON() ([]byte, error) {
 	data := range2ProofJSON{
-		Z:  rP2.z,
-		E:  rP2.e,
-		S:  rP2.s,
-		S1: rP2.s1,
-		S2: rP2.s2,
-		T:  rP2.t,
-		T1: rP2.t1,
-		T2: rP2.t2,
+		Z:    rP2.z,
+		E:    rP2.e,
+		S:    rP2.s,
+		S1:   rP2.s1,
+		S2:   rP2.s2,
+		T:    rP2.t,
+		T1:   rP2.t1,
+		T2:   rP2.t2,
+		Reps: rP2.reps,
 	}
 	return json.Marshal(data)
 }
@@ -179,6 +196,7 @@ func (rP2 *Range2Proof) UnmarshalJSON(bytes []byte) error {
 	rP2.t = data.T
 	rP2.t1 = data.T1
 	rP2.t2 = data.T2
+	rP2.reps = data.Reps
 
 	return nil
 }
@@ -211,7 +229,8 @@ func genResponseProof(rp ResponseProofParams, wc bool) (*ResponseProof, error) {
 	// The mitigation is described in section 3 from
 	// https://eprint.iacr.org/2019/114.pdf
 	// 3. \beta' = Z_{\mathbb{q5}}
-	q5, err := core.Exp(rp.Curve.Params().N, big.NewInt(5), nil)
+	cfg := rangeProofConfig(rp.Config)
+	q5, err := qPow(rp.Curve, cfg.MaskSlack)
 	if err != nil {
 		return nil, err
 	}
@@ -245,6 +264,7 @@ func genResponseProof(rp ResponseProofParams, wc bool) (*ResponseProof, error) {
 		c1:           rp.C1,
 		c2:           c2,
 		X:            rp.B,
+		config:       cfg,
 	}
 	var r2p *Range2Proof
 	if wc {
@@ -273,8 +293,9 @@ func (rp ResponseProof) Finalize(vp *ResponseVerifyParams) (*big.Int, error) {
 			N:  vp.Sk.N,
 			N2: vp.Sk.N2,
 		},
-		c1: vp.C1,
-		c2: rp.C2,
+		c1:     vp.C1,
+		c2:     rp.C2,
+		config: vp.Config,
 	}
 	if err := rp.R2proof.Verify(&v2Params); err != nil {
 		return nil, err
@@ -297,9 +318,10 @@ func (rp ResponseProof) FinalizeWc(vp *ResponseVerifyParams) (*big.Int, error) {
 			N:  vp.Sk.N,
 			N2: vp.Sk.N2,
 		},
-		c1: vp.C1,
-		c2: rp.C2,
-		X:  vp.B,
+		c1:     vp.C1,
+		c2:     rp.C2,
+		X:      vp.B,
+		config: vp.Config,
 	}
 	// 1. If MtaVerifyRange2_wc(...) = False, Return Error
 	if err := rp.R2proof.VerifyWc(&v2Params); err != nil {
@@ -323,21 +345,34 @@ func (pp Proof1Params) Prove() (*Range1Proof, error) {
 	if err := core.In(pp.R, pp.Pk.N); err != nil {
 		return nil, err
 	}
-	// Fetch our randomized values
-	rp, err := rand1(pp.Pk.N, pp.DealerParams.N, pp.Curve.Params().N)
-	if err != nil {
-		return nil, err
+	cfg := rangeProofConfig(pp.Config)
+	var pi *Range1Proof
+	for rep := uint(0); rep < cfg.Repetitions; rep++ {
+		// Fetch our randomized values
+		rp, err := rand1(pp.Pk.N, pp.DealerParams.N, pp.Curve.Params().N, cfg)
+		if err != nil {
+			return nil, err
+		}
+		// Compute the proof
+		p, err := genProof1(pp, rp, rep)
+		if err != nil {
+			return nil, err
+		}
+		if pi == nil {
+			pi = p
+		} else {
+			pi.reps = append(pi.reps, p)
+		}
 	}
-	// Compute and return the proof
-	return genProof1(pp, rp)
+	return pi, nil
 }
 
 // Fetches random values for use in range proof
 // [spec] fig 10: MtaProveRange1
-func rand1(N, Ntilde, q *big.Int) (*randProof1Params, error) {
+func rand1(N, Ntilde, q *big.Int, cfg *RangeProofConfig) (*randProof1Params, error) {
 	// Rings in which we'll operate
 	// q^3
-	q3, err := core.Exp(q, big.NewInt(3), nil)
+	q3, err := core.Exp(q, big.NewInt(int64(cfg.WitnessSlack)), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -387,7 +422,7 @@ func rand1(N, Ntilde, q *big.Int) (*randProof1Params, error) {
 
 // genProof1 deterministically computes a range proof
 // [spec] fig 10: MtaProveRange1
-func genProof1(in Proof1Params, rp *randProof1Params) (*Range1Proof, error) {
+func genProof1(in Proof1Params, rp *randProof1Params, rep uint) (*Range1Proof, error) {
 	// 6: z = h_1^a * h_2^\rho mod N~
 	z, err := pedersen(in.DealerParams.H1, in.DealerParams.H2, in.A, rp.rho, in.DealerParams.N)
 	if err != nil {
@@ -407,7 +442,7 @@ func genProof1(in Proof1Params, rp *randProof1Params) (*Range1Proof, error) {
 	}
 
 	// 9: e = H(g, q, Pk, N~, h_1, h_2, c, z, u, w)
-	bytes, err := core.FiatShamir(in.Curve.Params().Gx, in.Curve.Params().Gy, in.Curve.Params().N, in.Pk.N, in.DealerParams.N, in.DealerParams.H1, in.DealerParams.H2, in.C, z, u, w)
+	bytes, err := core.FiatShamir(withRepetition(rep, in.Curve.Params().Gx, in.Curve.Params().Gy, in.Curve.Params().N, in.Pk.N, in.DealerParams.N, in.DealerParams.H1, in.DealerParams.H2, in.C, z, u, w)...)
 	if err != nil {
 		return nil, err
 	}
@@ -446,9 +481,28 @@ func genProof1(in Proof1Params, rp *randProof1Params) (*Range1Proof, error) {
 
 // Verify checks a range (1) proof: [spec] §7.fig 7: MtaVerifyRange1
 func (pi Range1Proof) Verify(pp *Proof1Params) error {
+	cfg := rangeProofConfig(pp.Config)
+	if uint(len(pi.reps))+1 != cfg.Repetitions {
+		return fmt.Errorf("expected %d repetitions", cfg.Repetitions)
+	}
+	if err := pi.verify(pp, cfg, 0); err != nil {
+		return err
+	}
+	for i, r := range pi.reps {
+		if r == nil {
+			return fmt.Errorf("missing repetition")
+		}
+		if err := r.verify(pp, cfg, uint(i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pi Range1Proof) verify(pp *Proof1Params, cfg *RangeProofConfig, rep uint) error {
 	params := pp.Curve.Params()
 	// Rings in which we'll operate
-	q3, err := core.Exp(params.N, big.NewInt(3), nil) // q^3
+	q3, err := qPow(pp.Curve, cfg.WitnessSlack) // q^3
 	if err != nil {
 		return err
 	}
@@ -473,7 +527,7 @@ func (pi Range1Proof) Verify(pp *Proof1Params) error {
 	}
 
 	// 5: Compute e = H(g,q,Pk,N~,h_1,h_2,c,z,uHat,wHat)
-	bytes, err := core.FiatShamir(params.Gx, params.Gy, params.N, pp.Pk.N, pp.DealerParams.N, pp.DealerParams.H1, pp.DealerParams.H2, pp.C, pi.z, uHat, wHat)
+	bytes, err := core.FiatShamir(withRepetition(rep, params.Gx, params.Gy, params.N, pp.Pk.N, pp.DealerParams.N, pp.DealerParams.H1, pp.DealerParams.H2, pp.C, pi.z, uHat, wHat)...)
 	if err != nil {
 		return err
 	}
@@ -535,11 +589,7 @@ func (pi Range1Proof) wHatConstruct(pp *Proof1Params) (*big.Int, error) {
 // Prove computes a range proof over these parameters
 // [spec] fig 12: MtaProveRange2
 func (pp proof2Params) Prove() (*Range2Proof, error) {
-	randParams, err := rand2(pp.pk.N, pp.dealerParams.N, pp.curve.Params().N)
-	if err != nil {
-		return nil, err
-	}
-	return genProof2(pp, randParams, false)
+	return pp.prove(false)
 }
 
 // Prove computes a range proof over these parameters
@@ -548,15 +598,33 @@ func (pp proof2Params) ProveWc() (*Range2Proof, error) {
 	if pp.X == nil {
 		return nil, fmt.Errorf("X must have a value")
 	}
-	randParams, err := rand2(pp.pk.N, pp.dealerParams.N, pp.curve.Params().N)
-	if err != nil {
-		return nil, err
+	return pp.prove(true)
+}
+
+// prove computes the configured number of repetitions of proof (2)
+func (pp proof2Params) prove(wc bool) (*Range2Proof, error) {
+	cfg := rangeProofConfig(pp.config)
+	var pi *Range2Proof
+	for rep := uint(0); rep < cfg.Repetitions; rep++ {
+		randParams, err := rand2(pp.pk.N, pp.dealerParams.N, pp.curve.Params().N, cfg)
+		if err != nil {
+			return nil, err
+		}
+		p, err := genProof2(pp, randParams, wc, rep)
+		if err != nil {
+			return nil, err
+		}
+		if pi == nil {
+			pi = p
+		} else {
+			pi.reps = append(pi.reps, p)
+		}
 	}
-	return genProof2(pp, randParams, true)
+	return pi, nil
 }
 
 // genProof2 creates the proof for MtAProveRange2
-func genProof2(pp proof2Params, rp *randProof2Params, wc bool) (*Range2Proof, error) {
+func genProof2(pp proof2Params, rp *randProof2Params, wc bool, rep uint) (*Range2Proof, error) {
 	curveParams := pp.curve.Params()
 	if err := core.In(pp.x, curveParams.N); err != nil {
 		return nil, fmt.Errorf("x is not in q")
@@ -626,13 +694,13 @@ func genProof2(pp proof2Params, rp *randProof2Params, wc bool) (*Range2Proof, er
 	var challenge []byte
 	if wc {
 		// g || q || Pk || N ̃ || h1 || h2 || X || C1 || C2 || u || z || z' || t || v || w
-		challenge, err = core.FiatShamir(curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.X.X, pp.X.Y, pp.c1, pp.c2, u.X, u.Y, z, zTick, t, v, w)
+		challenge, err = core.FiatShamir(withRepetition(rep, curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.X.X, pp.X.Y, pp.c1, pp.c2, u.X, u.Y, z, zTick, t, v, w)...)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		// g || q || Pk || N ̃ || h1 || h2 || C1 || C2 || z || z' || t || v || w
-		challenge, err = core.FiatShamir(curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.c1, pp.c2, z, zTick, t, v, w)
+		challenge, err = core.FiatShamir(withRepetition(rep, curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.c1, pp.c2, z, zTick, t, v, w)...)
 		if err != nil {
 			return nil, err
 		}
@@ -693,15 +761,34 @@ func (pi Range2Proof) VerifyWc(pp *verifyProof2Params) error {
 }
 
 func verify2Proof(pi Range2Proof, pp *verifyProof2Params, wc bool) error {
+	cfg := rangeProofConfig(pp.config)
+	if uint(len(pi.reps))+1 != cfg.Repetitions {
+		return fmt.Errorf("expected %d repetitions", cfg.Repetitions)
+	}
+	if err := verify2ProofRepetition(pi, pp, wc, cfg, 0); err != nil {
+		return err
+	}
+	for i, r := range pi.reps {
+		if r == nil {
+			return fmt.Errorf("missing repetition")
+		}
+		if err := verify2ProofRepetition(*r, pp, wc, cfg, uint(i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verify2ProofRepetition(pi Range2Proof, pp *verifyProof2Params, wc bool, cfg *RangeProofConfig, rep uint) error {
 	// 1: Set N = pk.N
 
 	// Rings in which we'll operate
-	q3, err := core.Exp(pp.curve.Params().N, big.NewInt(3), nil) // q^3
+	q3, err := qPow(pp.curve, cfg.WitnessSlack) // q^3
 	if err != nil {
 		return err
 	}
 
-	q7, err := core.Exp(pp.curve.Params().N, big.NewInt(7), nil) // q^7
+	q7, err := qPow(pp.curve, cfg.ResponseSlack) // q^7
 	if err != nil {
 		return err
 	}
@@ -751,13 +838,13 @@ func verify2Proof(pi Range2Proof, pp *verifyProof2Params, wc bool) error {
 	var challenge []byte
 	if wc {
 		// g || q || Pk || N ̃ || h1 || h2 || X || c1 || c2 || uHat || z || zHatTick || t || vHat || wHat
-		challenge, err = core.FiatShamir(curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.X.X, pp.X.Y, pp.c1, pp.c2, uHat.X, uHat.Y, pi.z, zHatTick, pi.t, vHat, wHat)
+		challenge, err = core.FiatShamir(withRepetition(rep, curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.X.X, pp.X.Y, pp.c1, pp.c2, uHat.X, uHat.Y, pi.z, zHatTick, pi.t, vHat, wHat)...)
 		if err != nil {
 			return err
 		}
 	} else {
 		// g || q || Pk || N ̃ || h1 || h2 || c1 || c2 || z || zHatTick || t || vHat || wHat
-		challenge, err = core.FiatShamir(curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.c1, pp.c2, pi.z, zHatTick, pi.t, vHat, wHat)
+		challenge, err = core.FiatShamir(withRepetition(rep, curveParams.Gx, curveParams.Gy, curveParams.N, pp.pk.N, pp.dealerParams.N, pp.dealerParams.H1, pp.dealerParams.H2, pp.c1, pp.c2, pi.z, zHatTick, pi.t, vHat, wHat)...)
 		if err != nil {
 			return err
 		}
@@ -870,13 +957,13 @@ func (pi Range2Proof) wHatConstruct(pp *verifyProof2Params) (*big.Int, error) {
 
 // Fetches random values for use in range proof
 // [spec] fig 12: MtaProveRange2
-func rand2(N, Ntilde, q *big.Int) (*randProof2Params, error) {
+func rand2(N, Ntilde, q *big.Int, cfg *RangeProofConfig) (*randProof2Params, error) {
 	// Rings in which we'll operate
-	q3, err := core.Exp(q, big.NewInt(3), nil) // q^3
+	q3, err := core.Exp(q, big.NewInt(int64(cfg.WitnessSlack)), nil) // q^3
 	if err != nil {
 		return nil, err
 	}
-	q7, err := core.Exp(q, big.NewInt(7), nil) // q^7
+	q7, err := core.Exp(q, big.NewInt(int64(cfg.ResponseSlack)), nil) // q^7
 	if err != nil {
 		return nil, err
 	}
@@ -1194,11 +1194,11 @@ func TestMarshalJsonResponseProof(t *testing.T) {
 // Ensures that marshal-unmarshal Range1Proof is the identity function
 func TestMarshalRange1ProofRoundTrip(t *testing.T) {
 	expected := Range1Proof{
-		bi(-1),
-		bi(0),
-		bi(1),
-		bi(2),
-		bi(3),
+		z:  bi(-1),
+		e:  bi(0),
+		s:  bi(1),
+		s1: bi(2),
+		s2: bi(3),
 	}
 
 	// Marshal and test
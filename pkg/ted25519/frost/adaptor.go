@@ -0,0 +1,36 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// SetAdaptorPoint makes the signer compute an adaptor pre-signature for the adaptor point T = t*G instead
+// of a signature. The nonce of the signature is R + T, so the result of SignRound3 is a pre-signature which
+// Bip340Signature encodes as the 64 bytes that package adaptor verifies, completes with t and extracts t
+// from. Every signer of a session must set the same adaptor point before signing round 1, and it requires a
+// Bip340ChallengeDeriver
+func (signer *Signer) SetAdaptorPoint(adaptor curves.Point) error {
+	if signer == nil || adaptor == nil {
+		return internal.ErrNilArguments
+	}
+	if signer.round != 1 {
+		return internal.ErrInvalidRound
+	}
+	if _, ok := signer.challengeDeriver.(Bip340ChallengeDeriver); !ok {
+		return fmt.Errorf("adaptor signatures require a Bip340ChallengeDeriver")
+	}
+	if _, ok := adaptor.(*curves.PointK256); !ok || adaptor.IsIdentity() || !adaptor.IsOnCurve() {
+		return fmt.Errorf("invalid adaptor point")
+	}
+	signer.adaptor = adaptor
+	return nil
+}
@@ -0,0 +1,62 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestAdaptorPreSignature(t *testing.T) {
+	curve := curves.K256()
+	msg := []byte("message")
+	// Cover nonces R + T with odd and even Y coordinates
+	for i := 0; i < 16; i++ {
+		secret := curve.Scalar.Random(crand.Reader)
+		adaptor := curve.ScalarBaseMult(secret)
+		vk, out := signTweaked(t, curve, Bip340ChallengeDeriver{}, msg, func(s *Signer) error {
+			return s.SetAdaptorPoint(adaptor)
+		})
+		pubKey, err := Bip340PublicKey(vk)
+		require.NoError(t, err)
+		preSig, err := Bip340Signature(out)
+		require.NoError(t, err)
+		ok, _ := VerifyBip340(pubKey, msg, preSig)
+		require.False(t, ok)
+
+		// The signature nonce is R + T, negated with the nonces if it is odd
+		if out.R.IsNegative() {
+			secret = secret.Neg()
+		}
+		sig := append(preSig[:32], out.Z.Add(secret).Bytes()...)
+		ok, err = VerifyBip340(pubKey, msg, sig)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}
+
+func TestSetAdaptorPointBadInput(t *testing.T) {
+	signer1, _ := PrepareNewSigners(t)
+	adaptor := curves.K256().Point.Generator()
+	require.Equal(t, internal.ErrNilArguments, signer1.SetAdaptorPoint(nil))
+	// Adaptor signatures are BIP-340 signatures
+	require.Error(t, signer1.SetAdaptorPoint(adaptor))
+	_, err := signer1.SignRound1()
+	require.NoError(t, err)
+	require.Equal(t, internal.ErrInvalidRound, signer1.SetAdaptorPoint(adaptor))
+
+	_, out := signTweaked(t, curves.K256(), Bip340ChallengeDeriver{}, []byte("message"), func(s *Signer) error {
+		require.Error(t, s.SetAdaptorPoint(curves.K256().NewIdentityPoint()))
+		return s.SetAdaptorPoint(adaptor)
+	})
+	require.NotNil(t, out)
+}
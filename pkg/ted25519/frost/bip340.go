@@ -0,0 +1,102 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// Bip340PublicKey returns the 32 byte x-only public key of BIP-340 for a verification key
+func Bip340PublicKey(vk curves.Point) ([]byte, error) {
+	if _, ok := vk.(*curves.PointK256); !ok || vk.IsIdentity() {
+		return nil, fmt.Errorf("invalid secp256k1 verification key")
+	}
+	return xOnly(vk), nil
+}
+
+// Bip340Signature returns the 64 byte BIP-340 signature x(R) || z of the output of
+// signing round 3 with a Bip340ChallengeDeriver
+func Bip340Signature(out *Round3Bcast) ([]byte, error) {
+	if out == nil || out.R == nil || out.Z == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if _, ok := out.R.(*curves.PointK256); !ok {
+		return nil, fmt.Errorf("invalid secp256k1 signature")
+	}
+	return append(xOnly(out.R), out.Z.Bytes()...), nil
+}
+
+// VerifyBip340 verifies a 64 byte signature of `msg` under a 32 byte x-only public key as specified by BIP-340
+func VerifyBip340(pubKey, msg, sig []byte) (bool, error) {
+	if len(pubKey) != 32 {
+		return false, fmt.Errorf("public key must be 32 bytes")
+	}
+	if len(sig) != 64 {
+		return false, fmt.Errorf("signature must be 64 bytes")
+	}
+	curve := curves.K256()
+	// lift_x(x) is the point with an even Y coordinate
+	p, err := curve.Point.FromAffineCompressed(append([]byte{2}, pubKey...))
+	if err != nil {
+		return false, fmt.Errorf("invalid public key")
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	if r.Cmp(btcec.S256().P) >= 0 {
+		return false, fmt.Errorf("invalid signature")
+	}
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(btcec.S256().N) >= 0 {
+		return false, fmt.Errorf("invalid signature")
+	}
+	sc, err := curve.Scalar.SetBigInt(s)
+	if err != nil {
+		return false, err
+	}
+	e := bip340Scalar(bip340TaggedHash("BIP0340/challenge", sig[:32], pubKey, msg))
+	// R = s * G - e * P must have an even Y coordinate and x(R) = r
+	capR := curve.ScalarBaseMult(sc).Sub(p.Mul(e))
+	if capR.IsIdentity() || capR.IsNegative() {
+		return false, fmt.Errorf("invalid signature")
+	}
+	for i, b := range xOnly(capR) {
+		if b != sig[i] {
+			return false, fmt.Errorf("invalid signature")
+		}
+	}
+	return true, nil
+}
+
+// bip340TaggedHash is SHA256(SHA256(tag) || SHA256(tag) || x_1 || ... || x_n)
+func bip340TaggedHash(tag string, inputs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	_, _ = h.Write(tagHash[:])
+	_, _ = h.Write(tagHash[:])
+	for _, in := range inputs {
+		_, _ = h.Write(in)
+	}
+	return h.Sum(nil)
+}
+
+// bip340Scalar interprets a hash as a big-endian integer modulo the order of secp256k1
+func bip340Scalar(h []byte) curves.Scalar {
+	v := new(big.Int).SetBytes(h)
+	v.Mod(v, btcec.S256().N)
+	s, _ := curves.K256().Scalar.SetBigInt(v)
+	return s
+}
+
+// xOnly returns the 32 byte X coordinate of a secp256k1 point
+func xOnly(p curves.Point) []byte {
+	return p.ToAffineCompressed()[1:]
+}
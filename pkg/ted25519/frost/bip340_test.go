@@ -0,0 +1,111 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestFullRoundsBip340(t *testing.T) {
+	// Sign until both keys with odd and even Y coordinates are covered
+	var odd, even bool
+	for i := 0; i < 64 && !(odd && even); i++ {
+		vk, signer, out := fullRounds(t, curves.K256(), Bip340ChallengeDeriver{})
+		// Signers with an odd key sign for its negation
+		require.False(t, signer.verificationKey.IsNegative())
+		if vk.IsNegative() {
+			odd = true
+		} else {
+			even = true
+		}
+
+		pubKey, err := Bip340PublicKey(vk)
+		require.NoError(t, err)
+		sig, err := Bip340Signature(out)
+		require.NoError(t, err)
+		require.Len(t, sig, 64)
+		ok, err := VerifyBip340(pubKey, []byte("message"), sig)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = VerifyBip340(pubKey, []byte("other message"), sig)
+		require.Error(t, err)
+		require.False(t, ok)
+	}
+	require.True(t, odd && even)
+}
+
+func TestVerifyBip340Vectors(t *testing.T) {
+	// test-vectors.csv of BIP-340, without the secret keys and auxiliary randomness
+	key := "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659"
+	msg := "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89"
+	key2 := "778CAA53B4393AC467774D09497A87224BF9FAB6F6E68B23086497324D6FD117"
+	tests := []struct {
+		pubKey, msg, sig string
+		valid            bool
+	}{
+		// 0
+		{"F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9", "0000000000000000000000000000000000000000000000000000000000000000", "E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0", true},
+		// 1
+		{key, msg, "6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A", true},
+		// 2
+		{"DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8", "7E2D58D8B3BCDF1ABADEC7829054F90DDA9805AAB56C77333024B9D0A508B75C", "5831AAEED7B44BB74E5EAB94BA9D4294C49BCF2A60728D8B4C200F50DD313C1BAB745879A5AD954A72C45A91C3A51D3C7ADEA98D82F8481E0E1E03674A6F3FB7", true},
+		// 3: test fails if msg is reduced modulo p or n
+		{"25D1DFF95105F5253C4022F628A996AD3A0D95FBF21D468A1B33F8C160D8F517", "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", "7EB0509757E246F19449885651611CB965ECC1A187DD51B64FDA1EDC9637D5EC97582B9CB13DB3933705B32BA982AF5AF25FD78881EBB32771FC5922EFC66EA3", true},
+		// 4
+		{"D69C3509BB99E412E68B0FE8544E72837DFA30746D8BE2AA65975F29D22DC7B9", "4DF3C3F68FCC83B27E9D42C90431A72499F17875C81A599B566C9889B9696703", "00000000000000000000003B78CE563F89A0ED9414F5AA28AD0D96D6795F9C6376AFB1548AF603B3EB45C9F8207DEE1060CB71C04E80F593060B07D28308D7F4", true},
+		// 5: public key not on the curve
+		{"EEFDEA4CDB677750A420FEE807EACF21EB9898AE79B9768766E4FAA04A2D4A34", msg, "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E17776969E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B", false},
+		// 6: has_even_y(R) is false
+		{key, msg, "FFF97BD5755EEEA420453A14355235D382F6472F8568A18B2F057A14602975563CC27944640AC607CD107AE10923D9EF7A73C643E166BE5EBEAFA34B1AC553E2", false},
+		// 7: negated message
+		{key, msg, "1FA62E331EDBC21C394792D2AB1100A7B432B013DF3F6FF4F99FCB33E0E1515F28890B3EDB6E7189B630448B515CE4F8622A954CFE545735AAEA5134FCCDB2BD", false},
+		// 8: negated s value
+		{key, msg, "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E177769961764B3AA9B2FFCB6EF947B6887A226E8D7C93E00C5ED0C1834FF0D0C2E6DA6", false},
+		// 9: sG - eP is infinite, fails if has_even_y(inf) is true and x(inf) is 0
+		{key, msg, "0000000000000000000000000000000000000000000000000000000000000000123DDA8328AF9C23A94C1FEECFD123BA4FB73476F0D594DCB65C6425BD186051", false},
+		// 10: sG - eP is infinite, fails if has_even_y(inf) is true and x(inf) is 1
+		{key, msg, "00000000000000000000000000000000000000000000000000000000000000017615FBAF5AE28864013C099742DEADB4DBA87F11AC6754F93780D5A1837CF197", false},
+		// 11: sig[0:32] is not an X coordinate on the curve
+		{key, msg, "4A298DACAE57395A15D0795DDBFD1DCB564DA82B0F269BC70A74F8220429BA1D69E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B", false},
+		// 12: sig[0:32] is equal to field size
+		{key, msg, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F69E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B", false},
+		// 13: sig[32:64] is equal to curve order
+		{key, msg, "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E177769FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", false},
+		// 14: public key is not a valid X coordinate because it exceeds the field size
+		{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30", msg, "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E17776969E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B", false},
+		// 15: message of size 0
+		{key2, "", "71535DB165ECD9FBBC046E5FFAEA61186BB6AD436732FCCC25291A55895464CF6069CE26BF03466228F19A3A62DB8A649F2D560FAC652827D1AF0574E427AB63", true},
+		// 16: message of size 1
+		{key2, "11", "08A20A0AFEF64124649232E0693C583AB1B9934AE63B4C3511F3AE1134C6A303EA3173BFEA6683BD101FA5AA5DBC1996FE7CACFC5A577D33EC14564CEC2BACBF", true},
+		// 17: message of size 17
+		{key2, "0102030405060708090A0B0C0D0E0F1011", "5130F39A4059B43BC7CAC09A19ECE52B5D8699D1A71E3C52DA9AFDB6B50AC370C4A482B77BF960F8681540E25B6771ECE1E5A37FD80E5A51897C5566A97EA5A5", true},
+		// 18: message of size 100
+		{key2, strings.Repeat("99", 100), "403B12B0D8555A344175EA7EC746566303321E5DBFA8BE6F091635163ECA79A8585ED3E3170807E7C03B720FC54C7B23897FCBA0E9D0B4A06894CFD249F22367", true},
+		// The signature of vector 0 with a changed last byte
+		{"F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9", "0000000000000000000000000000000000000000000000000000000000000000", "E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C1", false},
+	}
+	for i, test := range tests {
+		pubKey, _ := hex.DecodeString(test.pubKey)
+		msg, _ := hex.DecodeString(test.msg)
+		sig, _ := hex.DecodeString(test.sig)
+		ok, err := VerifyBip340(pubKey, msg, sig)
+		require.Equal(t, test.valid, ok, "vector %d", i)
+		require.Equal(t, test.valid, err == nil, "vector %d", i)
+	}
+
+	_, err := VerifyBip340(make([]byte, 31), nil, make([]byte, 64))
+	require.Error(t, err)
+	_, err = VerifyBip340(make([]byte, 32), nil, make([]byte, 63))
+	require.Error(t, err)
+}
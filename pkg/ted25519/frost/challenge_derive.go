@@ -8,6 +8,7 @@ package frost
 
 import (
 	"crypto/sha512"
+	"fmt"
 
 	"github.com/etclab/kryptology/pkg/core/curves"
 )
@@ -25,3 +26,46 @@ func (ed Ed25519ChallengeDeriver) DeriveChallenge(msg []byte, pubKey curves.Poin
 	_, _ = h.Write(msg)
 	return new(curves.ScalarEd25519).SetBytesWide(h.Sum(nil))
 }
+
+// CurveChallengeDeriver derives the challenge with the Scalar.Hash of Curve, e.g. for
+// signing over Pallas or Vesta where there is no standard challenge encoding. The signers
+// negate their nonces when R is negative, so R is hashed as its non-negative representative
+type CurveChallengeDeriver struct {
+	Curve *curves.Curve
+}
+
+func (cd CurveChallengeDeriver) DeriveChallenge(msg []byte, pubKey curves.Point, r curves.Point) (curves.Scalar, error) {
+	if cd.Curve == nil {
+		return nil, fmt.Errorf("curve is nil")
+	}
+	if r.IsNegative() {
+		r = r.Neg()
+	}
+	var input []byte
+	input = append(input, r.ToAffineCompressed()...)
+	input = append(input, pubKey.ToAffineCompressed()...)
+	input = append(input, msg...)
+	return cd.Curve.Scalar.Hash(input), nil
+}
+
+// Bip340ChallengeDeriver derives the challenge of BIP-340 Schnorr signatures over secp256k1, the tagged
+// hash "BIP0340/challenge" of the x-only R, the x-only verification key and the message. Signers negate
+// their nonces when R has an odd Y coordinate, and sign for the negated key when the verification key has
+// an odd Y coordinate, since BIP-340 keys and nonces are the points with an even Y coordinate
+type Bip340ChallengeDeriver struct{}
+
+func (Bip340ChallengeDeriver) DeriveChallenge(msg []byte, pubKey curves.Point, r curves.Point) (curves.Scalar, error) {
+	if _, ok := pubKey.(*curves.PointK256); !ok {
+		return nil, fmt.Errorf("bip-340 requires secp256k1 keys")
+	}
+	h := bip340TaggedHash("BIP0340/challenge", xOnly(r), xOnly(pubKey), msg)
+	return bip340Scalar(h), nil
+}
+
+func (Bip340ChallengeDeriver) evenKey() {}
+
+// evenKeyDeriver is implemented by the challenge derivers of signatures whose verification keys are
+// the points with an even Y coordinate
+type evenKeyDeriver interface {
+	evenKey()
+}
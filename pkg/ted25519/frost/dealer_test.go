@@ -0,0 +1,119 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	dkg "github.com/etclab/kryptology/pkg/dkg/frost"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// An existing Ed25519 key split by a trusted dealer keeps producing signatures that verify under the same public key
+func TestDealerEd25519Key(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(crand.Reader)
+	require.NoError(t, err)
+	h := sha512.Sum512(priv.Seed())
+	secret, err := new(curves.ScalarEd25519).SetBytesClamping(h[:32])
+	require.NoError(t, err)
+
+	verifier, shares, err := dkg.DealerKeygen(secret, 2, 3, testCurve, crand.Reader)
+	require.NoError(t, err)
+	participants := make(map[uint32]*dkg.DkgParticipant)
+	for _, share := range shares {
+		participants[share.Id], err = dkg.NewDealerParticipant(share, verifier, 2, testCurve)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []byte(pub), participants[1].VerificationKey.ToAffineCompressed())
+
+	signerIds := []uint32{2, 3}
+	scheme, _ := sharing.NewShamir(2, 3, testCurve)
+	lCoeffs, err := scheme.LagrangeCoeffs(signerIds)
+	require.NoError(t, err)
+	signers := make(map[uint32]*Signer)
+	round2Input := make(map[uint32]*Round1Bcast)
+	for _, id := range signerIds {
+		signers[id], err = NewSigner(participants[id], id, 2, lCoeffs, signerIds, &Ed25519ChallengeDeriver{})
+		require.NoError(t, err)
+		round2Input[id], err = signers[id].SignRound1()
+		require.NoError(t, err)
+	}
+	msg := []byte("message")
+	round3Input := make(map[uint32]*Round2Bcast)
+	for id, signer := range signers {
+		round3Input[id], err = signer.SignRound2(msg, round2Input)
+		require.NoError(t, err)
+	}
+	out, err := signers[2].SignRound3(round3Input)
+	require.NoError(t, err)
+
+	sig := append(out.R.ToAffineCompressed(), out.Z.Bytes()...)
+	require.True(t, ed25519.Verify(pub, msg, sig))
+}
+
+// Signers of a resharing with a higher threshold sign under the key of the old signers
+func TestSignAfterReshare(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(crand.Reader)
+	require.NoError(t, err)
+	h := sha512.Sum512(priv.Seed())
+	secret, err := new(curves.ScalarEd25519).SetBytesClamping(h[:32])
+	require.NoError(t, err)
+	verifier, shares, err := dkg.DealerKeygen(secret, 2, 3, testCurve, crand.Reader)
+	require.NoError(t, err)
+	old := make(map[uint32]*dkg.DkgParticipant)
+	for _, share := range shares {
+		old[share.Id], err = dkg.NewDealerParticipant(share, verifier, 2, testCurve)
+		require.NoError(t, err)
+	}
+
+	config := &sharing.ReshareConfig{Curve: testCurve, OldThreshold: 2, OldLimit: 3, Threshold: 3, Limit: 4, Quorum: []uint32{2, 3}}
+	var outputs []*sharing.ReshareOutput
+	received := make(map[uint32][]*sharing.ShamirShare)
+	for _, id := range config.Quorum {
+		output, dealt, err := old[id].Reshare(config, crand.Reader)
+		require.NoError(t, err)
+		outputs = append(outputs, output)
+		for _, share := range dealt {
+			received[share.Id] = append(received[share.Id], share)
+		}
+	}
+	participants := make(map[uint32]*dkg.DkgParticipant)
+	for id := uint32(1); id <= config.Limit; id++ {
+		participants[id], err = dkg.NewReshareParticipant(id, config, verifier, outputs, received[id])
+		require.NoError(t, err)
+	}
+
+	signerIds := []uint32{1, 2, 4}
+	scheme, _ := sharing.NewShamir(3, 4, testCurve)
+	lCoeffs, err := scheme.LagrangeCoeffs(signerIds)
+	require.NoError(t, err)
+	signers := make(map[uint32]*Signer)
+	round2Input := make(map[uint32]*Round1Bcast)
+	for _, id := range signerIds {
+		signers[id], err = NewSigner(participants[id], id, 3, lCoeffs, signerIds, &Ed25519ChallengeDeriver{})
+		require.NoError(t, err)
+		round2Input[id], err = signers[id].SignRound1()
+		require.NoError(t, err)
+	}
+	msg := []byte("message")
+	round3Input := make(map[uint32]*Round2Bcast)
+	for id, signer := range signers {
+		round3Input[id], err = signer.SignRound2(msg, round2Input)
+		require.NoError(t, err)
+	}
+	out, err := signers[4].SignRound3(round3Input)
+	require.NoError(t, err)
+
+	sig := append(out.R.ToAffineCompressed(), out.Z.Bytes()...)
+	require.True(t, ed25519.Verify(pub, msg, sig))
+}
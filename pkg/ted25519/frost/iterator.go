@@ -0,0 +1,245 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+	"github.com/etclab/kryptology/pkg/dkg/frost"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// SignIterator runs the three signing rounds as a protocol.Iterator, so it can be driven by the same transport
+// as the DKLs18 protocols, e.g. by package runner. Every round broadcasts under runner.BroadcastKey and the
+// payloads of the cosigners are expected under runner.BroadcastFromKey of their sender
+type SignIterator struct {
+	*Signer
+	version uint
+	msg     []byte
+	round2  *Round2Bcast
+	result  *Round3Bcast
+	steps   []func(*protocol.Message) (*protocol.Message, error)
+	step    int
+}
+
+var _ protocol.Iterator = &SignIterator{}
+
+// signRound1Payload is the encoded Round1Bcast
+type signRound1Payload struct {
+	Di, Ei []byte
+}
+
+// signRound2Payload is the encoded Round2Bcast
+type signRound2Payload struct {
+	Zi, Vki []byte
+}
+
+// signatureResult is the encoded Round3Bcast
+type signatureResult struct {
+	R, Z, C []byte
+}
+
+const signatureKey = "signature"
+
+// NewSignIterator creates the signer of `msg` with the participant in `dkgResult`, the result of a
+// frost.DkgIterator, and the `cosigners`, which include the signer, any `threshold` of the participants can sign
+func NewSignIterator(dkgResult *protocol.Message, threshold uint32, cosigners []uint32, msg []byte,
+	challengeDeriver ChallengeDerive, version uint) (*SignIterator, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	info, err := frost.DecodeDkgResult(dkgResult)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	scheme, err := sharing.NewShamir(threshold, uint32(len(cosigners)), info.Curve)
+	if err != nil {
+		return nil, err
+	}
+	lCoeffs, err := scheme.LagrangeCoeffs(cosigners)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewSigner(info, info.Id, threshold, lCoeffs, cosigners, challengeDeriver)
+	if err != nil {
+		return nil, err
+	}
+	s := &SignIterator{Signer: signer, version: version, msg: append([]byte{}, msg...)}
+	s.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			out, err := s.SignRound1()
+			if err != nil {
+				return nil, err
+			}
+			return s.encode("1", &signRound1Payload{
+				Di: out.Di.ToAffineCompressed(),
+				Ei: out.Ei.ToAffineCompressed(),
+			})
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			commitments, err := s.decodeRound2Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			out, err := s.SignRound2(s.msg, commitments)
+			if err != nil {
+				return nil, err
+			}
+			s.round2 = out
+			return s.encode("2", &signRound2Payload{
+				Zi:  out.Zi.Bytes(),
+				Vki: out.Vki.ToAffineCompressed(),
+			})
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			shares, err := s.decodeRound3Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			out, err := s.SignRound3(shares)
+			if err != nil {
+				return nil, err
+			}
+			s.result = out
+			return nil, nil
+		},
+	}
+	return s, nil
+}
+
+// Next runs the next round of signing
+func (s *SignIterator) Next(input *protocol.Message) (*protocol.Message, error) {
+	if s.step >= len(s.steps) {
+		return nil, protocol.ErrProtocolFinished
+	}
+	output, err := s.steps[s.step](input)
+	if err != nil {
+		return nil, err
+	}
+	s.step++
+	return output, nil
+}
+
+// Result returns the signature once signing has completed, which DecodeSignature reads
+func (s *SignIterator) Result(version uint) (*protocol.Message, error) {
+	if s.step < len(s.steps) {
+		return nil, nil
+	}
+	if s.Signer == nil || s.result == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	m := newSignMessage("result", version)
+	result := &signatureResult{R: s.result.R.ToAffineCompressed(), Z: s.result.Z.Bytes(), C: s.result.C.Bytes()}
+	if err := m.EncodePayload(signatureKey, result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+// DecodeSignature returns the signature in the result of a SignIterator over `curve`
+func DecodeSignature(m *protocol.Message, curve *curves.Curve) (*Round3Bcast, error) {
+	if m == nil || curve == nil {
+		return nil, errors.New("invalid result")
+	}
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	var result signatureResult
+	if err := m.DecodePayload(signatureKey, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	r, err := curve.Point.FromAffineCompressed(result.R)
+	if err != nil {
+		return nil, err
+	}
+	z, err := curve.Scalar.SetBytes(result.Z)
+	if err != nil {
+		return nil, err
+	}
+	c, err := curve.Scalar.SetBytes(result.C)
+	if err != nil {
+		return nil, err
+	}
+	return &Round3Bcast{R: r, Z: z, C: c}, nil
+}
+
+func newSignMessage(round string, version uint) *protocol.Message {
+	return &protocol.Message{
+		Protocol: protocol.FrostSign,
+		Version:  version,
+		Payloads: make(map[string][]byte),
+		Metadata: map[string]string{"round": round},
+	}
+}
+
+func (s *SignIterator) encode(round string, payload interface{}) (*protocol.Message, error) {
+	m := newSignMessage(round, s.version)
+	if err := m.EncodePayload(runner.BroadcastKey, payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+func (s *SignIterator) decodeRound2Input(m *protocol.Message) (map[uint32]*Round1Bcast, error) {
+	if m == nil || m.Version != s.version {
+		return nil, errors.New("only version 1 is supported")
+	}
+	commitments := map[uint32]*Round1Bcast{s.id: {Di: s.state.capD, Ei: s.state.capE}}
+	for _, id := range s.cosigners {
+		if id == s.id {
+			continue
+		}
+		var payload signRound1Payload
+		if err := m.DecodePayload(runner.BroadcastFromKey(id), &payload); err != nil {
+			return nil, err
+		}
+		di, err := s.curve.Point.FromAffineCompressed(payload.Di)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commitment from signer %d", id)
+		}
+		ei, err := s.curve.Point.FromAffineCompressed(payload.Ei)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commitment from signer %d", id)
+		}
+		commitments[id] = &Round1Bcast{Di: di, Ei: ei}
+	}
+	return commitments, nil
+}
+
+func (s *SignIterator) decodeRound3Input(m *protocol.Message) (map[uint32]*Round2Bcast, error) {
+	if m == nil || m.Version != s.version {
+		return nil, errors.New("only version 1 is supported")
+	}
+	shares := map[uint32]*Round2Bcast{s.id: s.round2}
+	for _, id := range s.cosigners {
+		if id == s.id {
+			continue
+		}
+		var payload signRound2Payload
+		if err := m.DecodePayload(runner.BroadcastFromKey(id), &payload); err != nil {
+			return nil, err
+		}
+		zi, err := s.curve.Scalar.SetBytes(payload.Zi)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature share from signer %d", id)
+		}
+		vki, err := s.curve.Point.FromAffineCompressed(payload.Vki)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification key share from signer %d", id)
+		}
+		shares[id] = &Round2Bcast{Zi: zi, Vki: vki}
+	}
+	return shares, nil
+}
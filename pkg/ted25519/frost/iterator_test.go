@@ -0,0 +1,88 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+	dkg "github.com/etclab/kryptology/pkg/dkg/frost"
+)
+
+// runIterators runs the DKG with 3 participants and threshold 2, then signs `msg` with signers 1 and 3
+func runIterators(t *testing.T, curve *curves.Curve, challengeDeriver ChallengeDerive, msg []byte) (*dkg.DkgParticipant, *Round3Bcast) {
+	parties := make(map[uint32]protocol.Iterator)
+	ids := []uint32{1, 2, 3}
+	for _, id := range ids {
+		var others []uint32
+		for _, other := range ids {
+			if other != id {
+				others = append(others, other)
+			}
+		}
+		d, err := dkg.NewDkgIterator(id, 2, ctx, curve, protocol.Version1, others...)
+		require.NoError(t, err)
+		parties[id] = d
+	}
+	r, err := runner.NewRunner(parties, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Run())
+	dkgResults, err := r.Results(protocol.Version1)
+	require.NoError(t, err)
+
+	cosigners := []uint32{1, 3}
+	parties = make(map[uint32]protocol.Iterator)
+	for _, id := range cosigners {
+		parties[id], err = NewSignIterator(dkgResults[id], 2, cosigners, msg, challengeDeriver, protocol.Version1)
+		require.NoError(t, err)
+	}
+	r, err = runner.NewRunner(parties, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Run())
+	results, err := r.Results(protocol.Version1)
+	require.NoError(t, err)
+
+	sig1, err := DecodeSignature(results[1], curve)
+	require.NoError(t, err)
+	sig3, err := DecodeSignature(results[3], curve)
+	require.NoError(t, err)
+	require.True(t, sig1.R.Equal(sig3.R))
+	require.Equal(t, 0, sig1.Z.Cmp(sig3.Z))
+	info, err := dkg.DecodeDkgResult(dkgResults[2])
+	require.NoError(t, err)
+	return info, sig1
+}
+
+func TestSignIteratorEd25519(t *testing.T) {
+	msg := []byte("message")
+	info, sig := runIterators(t, testCurve, &Ed25519ChallengeDeriver{}, msg)
+	require.True(t, ed25519.Verify(info.VerificationKey.ToAffineCompressed(), msg, append(sig.R.ToAffineCompressed(), sig.Z.Bytes()...)))
+}
+
+func TestSignIteratorBip340(t *testing.T) {
+	msg := []byte("message")
+	info, out := runIterators(t, curves.K256(), Bip340ChallengeDeriver{}, msg)
+	pubKey, err := Bip340PublicKey(info.VerificationKey)
+	require.NoError(t, err)
+	sig, err := Bip340Signature(out)
+	require.NoError(t, err)
+	ok, err := VerifyBip340(pubKey, msg, sig)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestSignIteratorBadInput(t *testing.T) {
+	_, err := NewSignIterator(nil, 2, []uint32{1, 2}, []byte("message"), &Ed25519ChallengeDeriver{}, protocol.Version1)
+	require.Error(t, err)
+	_, err = DecodeSignature(&protocol.Message{Protocol: protocol.FrostSign, Version: protocol.Version1}, testCurve)
+	require.Error(t, err)
+}
@@ -5,6 +5,10 @@
 //
 
 // Package frost is an implementation of t-of-n threshold signature of https://eprint.iacr.org/2020/852.pdf
+//
+// Signing nonces are always sampled at random. Deriving them from the key, message and a session ID does not protect
+// a signer that loses its state: signing the same session again against different cosigner commitments uses the same
+// nonces with another binding factor and challenge, which reveals the secret share.
 package frost
 
 import (
@@ -28,6 +32,8 @@ type Signer struct {
 	cosigners        []uint32
 	state            *state // Accumulated intermediate values associated with signing
 	challengeDeriver ChallengeDerive
+	vkShares         map[uint32]curves.Point // verification key shares of the cosigners, to check their signature shares
+	adaptor          curves.Point            // adaptor point of a pre-signature when set, see SetAdaptorPoint
 }
 
 type state struct {
@@ -67,10 +73,29 @@ func NewSigner(info *frost.DkgParticipant, id, thresh uint32, lcoeffs map[uint32
 		}
 	}
 
+	skShare, vkShare, verificationKey := info.SkShare, info.VkShare, info.VerificationKey
+	// An odd key is signed for with its negation, which has the same x-only public key
+	_, even := challengeDeriver.(evenKeyDeriver)
+	negate := even && verificationKey.IsNegative()
+	if negate {
+		skShare, vkShare, verificationKey = skShare.Neg(), vkShare.Neg(), verificationKey.Neg()
+	}
+	vkShares := make(map[uint32]curves.Point, len(cosigners))
+	for _, j := range cosigners {
+		vkj, err := info.VerificationShare(j)
+		if err != nil {
+			return nil, err
+		}
+		if negate {
+			vkj = vkj.Neg()
+		}
+		vkShares[j] = vkj
+	}
+
 	return &Signer{
-		skShare:          info.SkShare,
-		vkShare:          info.VkShare,
-		verificationKey:  info.VerificationKey,
+		skShare:          skShare,
+		vkShare:          vkShare,
+		verificationKey:  verificationKey,
 		id:               id,
 		threshold:        thresh,
 		curve:            info.Curve,
@@ -79,5 +104,6 @@ func NewSigner(info *frost.DkgParticipant, id, thresh uint32, lcoeffs map[uint32
 		cosigners:        cosigners,
 		state:            &state{},
 		challengeDeriver: challengeDeriver,
+		vkShares:         vkShares,
 	}, nil
 }
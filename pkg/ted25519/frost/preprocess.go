@@ -0,0 +1,256 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// PreprocessedCommitment is a commitment to a pair of nonces of the preprocessing stage of FROST,
+// which signers publish in batches ahead of signing
+type PreprocessedCommitment struct {
+	Id     uint32 // The signer which holds the nonces
+	Index  uint32 // The position of the nonces in the signer's NonceStore
+	Di, Ei curves.Point
+}
+
+// NonceStore keeps the secret nonces of the preprocessed commitments of a signer until they are used.
+// Every nonce pair is removed when it is used and indexes are never issued twice, so a commitment signs
+// at most one message. The store must be persisted with MarshalBinary after every use, before the signature
+// share is released, and an older copy must never be restored, since it holds nonces which were used
+type NonceStore struct {
+	curve  *curves.Curve
+	id     uint32
+	next   uint32
+	nonces map[uint32][2]curves.Scalar
+}
+
+// NewNonceStore creates an empty store for the signer `id`
+func NewNonceStore(curve *curves.Curve, id uint32) (*NonceStore, error) {
+	if curve == nil || id == 0 {
+		return nil, internal.ErrNilArguments
+	}
+	return &NonceStore{curve: curve, id: id, nonces: make(map[uint32][2]curves.Scalar)}, nil
+}
+
+// Preprocess samples `count` nonce pairs with randomness from `reader`, or crypto/rand if it is nil,
+// and returns their commitments to be published
+func (s *NonceStore) Preprocess(count int, reader io.Reader) ([]*PreprocessedCommitment, error) {
+	if s == nil || s.curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if count <= 0 || uint64(s.next)+uint64(count) > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("invalid number of nonces")
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	out := make([]*PreprocessedCommitment, count)
+	for i := range out {
+		d := s.curve.Scalar.Random(reader)
+		e := s.curve.Scalar.Random(reader)
+		if d.IsZero() || e.IsZero() {
+			return nil, fmt.Errorf("invalid nonce sampled")
+		}
+		s.next++
+		s.nonces[s.next] = [2]curves.Scalar{d, e}
+		out[i] = &PreprocessedCommitment{
+			Id:    s.id,
+			Index: s.next,
+			Di:    s.curve.ScalarBaseMult(d),
+			Ei:    s.curve.ScalarBaseMult(e),
+		}
+	}
+	return out, nil
+}
+
+// Remaining returns the number of unused nonce pairs
+func (s *NonceStore) Remaining() int {
+	return len(s.nonces)
+}
+
+// take removes the nonces of the commitment from the store and returns them
+func (s *NonceStore) take(c *PreprocessedCommitment) (curves.Scalar, curves.Scalar, error) {
+	if c == nil || c.Di == nil || c.Ei == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if c.Id != s.id {
+		return nil, nil, fmt.Errorf("commitment belongs to signer %d", c.Id)
+	}
+	nonces, ok := s.nonces[c.Index]
+	if !ok {
+		return nil, nil, fmt.Errorf("nonces %d are used or unknown", c.Index)
+	}
+	// The nonces are discarded even if the commitment does not match, they were offered for signing
+	delete(s.nonces, c.Index)
+	if !s.curve.ScalarBaseMult(nonces[0]).Equal(c.Di) || !s.curve.ScalarBaseMult(nonces[1]).Equal(c.Ei) {
+		return nil, nil, fmt.Errorf("commitment %d does not match the nonces", c.Index)
+	}
+	return nonces[0], nonces[1], nil
+}
+
+// MarshalBinary encodes the store, which holds secrets and must be stored encrypted
+func (s NonceStore) MarshalBinary() ([]byte, error) {
+	e, err := sharing.NewEncoder(sharing.EncodingNonceStore, s.curve)
+	if err != nil {
+		return nil, err
+	}
+	e.Uint32(s.id)
+	e.Uint32(s.next)
+	e.Uint32(uint32(len(s.nonces)))
+	indexes := make([]uint32, 0, len(s.nonces))
+	for i := range s.nonces {
+		indexes = append(indexes, i)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	for _, i := range indexes {
+		e.Uint32(i)
+		for _, nonce := range s.nonces[i] {
+			if err = e.Scalar(nonce); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return e.Bytes(), nil
+}
+
+// UnmarshalBinary reads a store written by MarshalBinary
+func (s *NonceStore) UnmarshalBinary(data []byte) error {
+	d, err := sharing.NewDecoder(data, sharing.EncodingNonceStore)
+	if err != nil {
+		return err
+	}
+	id := d.Uint32()
+	next := d.Uint32()
+	count := d.Uint32()
+	if count > next {
+		return fmt.Errorf("invalid nonce count")
+	}
+	nonces := make(map[uint32][2]curves.Scalar, count)
+	for j := uint32(0); j < count; j++ {
+		i := d.Uint32()
+		nonces[i] = [2]curves.Scalar{d.Scalar(), d.Scalar()}
+		if i == 0 || i > next {
+			return fmt.Errorf("invalid nonce index %d", i)
+		}
+	}
+	if err = d.Finish(); err != nil {
+		return err
+	}
+	if id == 0 || len(nonces) != int(count) {
+		return fmt.Errorf("invalid nonce store")
+	}
+	s.curve, s.id, s.next, s.nonces = d.Curve(), id, next, nonces
+	return nil
+}
+
+// CommitmentPool holds the published commitments of the signers on the side of the signature
+// aggregator, which hands out every commitment for at most one signing session
+type CommitmentPool struct {
+	pending map[uint32][]*PreprocessedCommitment
+	// The highest index added for each signer, indexes must increase
+	last map[uint32]uint32
+}
+
+// NewCommitmentPool creates an empty pool
+func NewCommitmentPool() *CommitmentPool {
+	return &CommitmentPool{
+		pending: make(map[uint32][]*PreprocessedCommitment),
+		last:    make(map[uint32]uint32),
+	}
+}
+
+// Add adds a published batch of commitments. The indexes of a signer must be higher than those added before
+func (p *CommitmentPool) Add(batch []*PreprocessedCommitment) error {
+	last := make(map[uint32]uint32)
+	for _, c := range batch {
+		if c == nil || c.Di == nil || c.Ei == nil {
+			return internal.ErrNilArguments
+		}
+		if c.Di.IsIdentity() || c.Ei.IsIdentity() || !c.Di.IsOnCurve() || !c.Ei.IsOnCurve() {
+			return fmt.Errorf("invalid commitment %d of signer %d", c.Index, c.Id)
+		}
+		l, ok := last[c.Id]
+		if !ok {
+			l = p.last[c.Id]
+		}
+		if c.Index <= l {
+			return fmt.Errorf("commitment %d of signer %d is not new", c.Index, c.Id)
+		}
+		last[c.Id] = c.Index
+	}
+	for _, c := range batch {
+		p.pending[c.Id] = append(p.pending[c.Id], c)
+	}
+	for id, l := range last {
+		p.last[id] = l
+	}
+	return nil
+}
+
+// Remaining returns the number of unused commitments of signer `id`
+func (p *CommitmentPool) Remaining(id uint32) int {
+	return len(p.pending[id])
+}
+
+// Next removes the oldest unused commitment of each signer and returns them, indexed by signer, as the
+// commitments of a signing session. Nothing is removed if a signer has no commitment left
+func (p *CommitmentPool) Next(signers []uint32) (map[uint32]*PreprocessedCommitment, error) {
+	out := make(map[uint32]*PreprocessedCommitment, len(signers))
+	for _, id := range signers {
+		if _, ok := out[id]; ok {
+			return nil, fmt.Errorf("duplicate signer %d", id)
+		}
+		if len(p.pending[id]) == 0 {
+			return nil, fmt.Errorf("no commitments left for signer %d", id)
+		}
+		out[id] = p.pending[id][0]
+	}
+	for _, id := range signers {
+		p.pending[id] = p.pending[id][1:]
+	}
+	return out, nil
+}
+
+// SignRound1Preprocessed replaces signing round 1 with the preprocessed nonces of the signer's commitment
+// in `commitments`, which are removed from the store. It returns the commitments of the session in the form
+// SignRound2 expects, so online signing is a single round
+func (signer *Signer) SignRound1Preprocessed(store *NonceStore, commitments map[uint32]*PreprocessedCommitment) (map[uint32]*Round1Bcast, error) {
+	if signer == nil || signer.curve == nil || store == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if signer.round != 1 {
+		return nil, internal.ErrInvalidRound
+	}
+	if store.id != signer.id || store.curve.Name != signer.curve.Name {
+		return nil, fmt.Errorf("nonce store does not belong to the signer")
+	}
+	out := make(map[uint32]*Round1Bcast, len(commitments))
+	for id, c := range commitments {
+		if c == nil || c.Id != id {
+			return nil, fmt.Errorf("invalid commitment of signer %d", id)
+		}
+		out[id] = &Round1Bcast{Di: c.Di, Ei: c.Ei}
+	}
+	own, ok := commitments[signer.id]
+	if !ok {
+		return nil, fmt.Errorf("missing commitment of signer %d", signer.id)
+	}
+	d, e, err := store.take(own)
+	if err != nil {
+		return nil, err
+	}
+	signer.commitNonces(d, e)
+	return out, nil
+}
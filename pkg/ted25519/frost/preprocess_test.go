@@ -0,0 +1,133 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/dkg/frost"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+func TestPreprocessedSigning(t *testing.T) {
+	p1, p2 := PrepareDkgOutput(t)
+	participants := map[uint32]*frost.DkgParticipant{p1.Id: p1, p2.Id: p2}
+	ids := []uint32{p1.Id, p2.Id}
+	scheme, err := sharing.NewShamir(2, 2, testCurve)
+	require.NoError(t, err)
+	lCoeffs, err := scheme.LagrangeCoeffs(ids)
+	require.NoError(t, err)
+
+	// Preprocessing
+	pool := NewCommitmentPool()
+	stores := make(map[uint32]*NonceStore)
+	for _, id := range ids {
+		stores[id], err = NewNonceStore(testCurve, id)
+		require.NoError(t, err)
+		batch, err := stores[id].Preprocess(3, nil)
+		require.NoError(t, err)
+		require.NoError(t, pool.Add(batch))
+		require.Equal(t, 3, pool.Remaining(id))
+	}
+
+	for _, msg := range []string{"first", "second", "third"} {
+		commitments, err := pool.Next(ids)
+		require.NoError(t, err)
+
+		// A single online round
+		signers := make(map[uint32]*Signer)
+		round3Input := make(map[uint32]*Round2Bcast)
+		for _, id := range ids {
+			signers[id], err = NewSigner(participants[id], id, 2, lCoeffs, ids, &Ed25519ChallengeDeriver{})
+			require.NoError(t, err)
+			round2Input, err := signers[id].SignRound1Preprocessed(stores[id], commitments)
+			require.NoError(t, err)
+
+			// The store is persisted before the signature share is released
+			data, err := stores[id].MarshalBinary()
+			require.NoError(t, err)
+			stores[id] = new(NonceStore)
+			require.NoError(t, stores[id].UnmarshalBinary(data))
+
+			round3Input[id], err = signers[id].SignRound2([]byte(msg), round2Input)
+			require.NoError(t, err)
+		}
+		out, err := signers[p1.Id].SignRound3(round3Input)
+		require.NoError(t, err)
+		ok, err := Verify(testCurve, &Ed25519ChallengeDeriver{}, p1.VerificationKey, []byte(msg), &Signature{out.Z, out.C})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// The commitments cannot be used again
+		signer, err := NewSigner(p1, p1.Id, 2, lCoeffs, ids, &Ed25519ChallengeDeriver{})
+		require.NoError(t, err)
+		_, err = signer.SignRound1Preprocessed(stores[p1.Id], commitments)
+		require.Error(t, err)
+	}
+	require.Equal(t, 0, stores[p1.Id].Remaining())
+	_, err = pool.Next(ids)
+	require.Error(t, err)
+}
+
+func TestPreprocessedBadInput(t *testing.T) {
+	p1, p2 := PrepareDkgOutput(t)
+	ids := []uint32{p1.Id, p2.Id}
+	scheme, _ := sharing.NewShamir(2, 2, testCurve)
+	lCoeffs, err := scheme.LagrangeCoeffs(ids)
+	require.NoError(t, err)
+	store1, err := NewNonceStore(testCurve, p1.Id)
+	require.NoError(t, err)
+	store2, err := NewNonceStore(testCurve, p2.Id)
+	require.NoError(t, err)
+	batch1, err := store1.Preprocess(2, nil)
+	require.NoError(t, err)
+	batch2, err := store2.Preprocess(2, nil)
+	require.NoError(t, err)
+
+	// Commitments are only accepted once and in order
+	pool := NewCommitmentPool()
+	require.NoError(t, pool.Add(batch1))
+	require.Error(t, pool.Add(batch1))
+	require.Error(t, pool.Add([]*PreprocessedCommitment{batch2[1], batch2[0]}))
+	require.Equal(t, 0, pool.Remaining(p2.Id))
+	_, err = pool.Next(ids)
+	require.Error(t, err)
+	require.Equal(t, 2, pool.Remaining(p1.Id))
+
+	// The store of another signer
+	signer, err := NewSigner(p1, p1.Id, 2, lCoeffs, ids, &Ed25519ChallengeDeriver{})
+	require.NoError(t, err)
+	commitments := map[uint32]*PreprocessedCommitment{p1.Id: batch1[0], p2.Id: batch2[0]}
+	_, err = signer.SignRound1Preprocessed(store2, commitments)
+	require.Error(t, err)
+
+	// A commitment which does not match the nonces burns them
+	mauled := *batch1[0]
+	mauled.Di = batch1[1].Di
+	commitments[p1.Id] = &mauled
+	_, err = signer.SignRound1Preprocessed(store1, commitments)
+	require.Error(t, err)
+	commitments[p1.Id] = batch1[0]
+	_, err = signer.SignRound1Preprocessed(store1, commitments)
+	require.Error(t, err)
+	require.Equal(t, 1, store1.Remaining())
+
+	// The signer refuses a session in which its commitment was replaced
+	round2Input, err := signer.SignRound1Preprocessed(store1, map[uint32]*PreprocessedCommitment{p1.Id: batch1[1], p2.Id: batch2[1]})
+	require.NoError(t, err)
+	round2Input[p1.Id] = &Round1Bcast{Di: batch1[0].Di, Ei: batch1[0].Ei}
+	_, err = signer.SignRound2([]byte("message"), round2Input)
+	require.Error(t, err)
+
+	_, err = NewNonceStore(testCurve, 0)
+	require.Error(t, err)
+	_, err = store1.Preprocess(0, nil)
+	require.Error(t, err)
+	require.Error(t, new(NonceStore).UnmarshalBinary([]byte{1, 5}))
+}
@@ -0,0 +1,178 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package rfc9591 implements the two-round FROST threshold Schnorr signatures of
+// https://www.rfc-editor.org/rfc/rfc9591 with the FROST(Ed25519, SHA-512), FROST(ristretto255, SHA-512),
+// FROST(P-256, SHA-256) and FROST(secp256k1, SHA-256) ciphersuites, so commitments, signature shares and
+// signatures interoperate with other implementations of the RFC.
+// Unlike the frost package, binding factors are computed per participant from the group public key,
+// the message and the encoded commitment list, and the nonces of R are not negated.
+// Ed25519 signatures verify with crypto/ed25519.
+package rfc9591
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/curves/native"
+)
+
+// Length of hash_to_field outputs of the NIST and secp256k1 ciphersuites
+const expandLen = 48
+
+// Ciphersuite fixes the prime order group and the hash functions H1 to H5 of FROST
+type Ciphersuite struct {
+	contextString string
+	curve         *curves.Curve
+	// Whether scalars and hashes are little-endian and hashed to scalars with SHA-512,
+	// as opposed to big-endian with hash_to_field and SHA-256
+	edwards bool
+	// H2 of FROST(Ed25519, SHA-512) omits the context string for RFC 8032 compatibility
+	rfc8032 bool
+}
+
+// Ed25519Sha512 returns FROST(Ed25519, SHA-512), whose signatures are Ed25519 signatures
+func Ed25519Sha512() *Ciphersuite {
+	return &Ciphersuite{contextString: "FROST-ED25519-SHA512-v1", curve: curves.ED25519(), edwards: true, rfc8032: true}
+}
+
+// Ristretto255Sha512 returns FROST(ristretto255, SHA-512)
+func Ristretto255Sha512() *Ciphersuite {
+	return &Ciphersuite{contextString: "FROST-RISTRETTO255-SHA512-v1", curve: curves.RISTRETTO255(), edwards: true}
+}
+
+// P256Sha256 returns FROST(P-256, SHA-256)
+func P256Sha256() *Ciphersuite {
+	return &Ciphersuite{contextString: "FROST-P256-SHA256-v1", curve: curves.P256()}
+}
+
+// Secp256k1Sha256 returns FROST(secp256k1, SHA-256)
+func Secp256k1Sha256() *Ciphersuite {
+	return &Ciphersuite{contextString: "FROST-secp256k1-SHA256-v1", curve: curves.K256()}
+}
+
+// ContextString returns the contextString of the ciphersuite
+func (c *Ciphersuite) ContextString() string {
+	return c.contextString
+}
+
+// Curve returns the group of the ciphersuite
+func (c *Ciphersuite) Curve() *curves.Curve {
+	return c.curve
+}
+
+// SerializeElement returns the compressed encoding of a point, which must not be the identity
+func (c *Ciphersuite) SerializeElement(p curves.Point) ([]byte, error) {
+	if p == nil || p.IsIdentity() || p.CurveName() != c.curve.Name {
+		return nil, fmt.Errorf("invalid element")
+	}
+	return p.ToAffineCompressed(), nil
+}
+
+// DeserializeElement decodes the output of SerializeElement and
+// rejects the identity and points outside the prime order subgroup
+func (c *Ciphersuite) DeserializeElement(in []byte) (curves.Point, error) {
+	if len(in) != len(c.curve.Point.ToAffineCompressed()) {
+		return nil, fmt.Errorf("invalid element length")
+	}
+	p, err := c.curve.Point.FromAffineCompressed(in)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsIdentity() || !curves.IsTorsionFree(p) {
+		return nil, fmt.Errorf("invalid element")
+	}
+	return p, nil
+}
+
+// SerializeScalar returns the 32 byte encoding of a scalar, which is
+// little-endian for Ed25519 and ristretto255 and big-endian otherwise
+func (c *Ciphersuite) SerializeScalar(s curves.Scalar) []byte {
+	return s.Bytes()
+}
+
+// DeserializeScalar decodes the output of SerializeScalar and rejects non-canonical encodings
+func (c *Ciphersuite) DeserializeScalar(in []byte) (curves.Scalar, error) {
+	if len(in) != len(c.curve.Scalar.Bytes()) {
+		return nil, fmt.Errorf("invalid scalar length")
+	}
+	return c.curve.Scalar.SetBytes(in)
+}
+
+// h1 derives binding factors
+func (c *Ciphersuite) h1(m []byte) curves.Scalar {
+	return c.hashToScalar("rho", m)
+}
+
+// h2 derives the challenge
+func (c *Ciphersuite) h2(m []byte) curves.Scalar {
+	if c.rfc8032 {
+		h := sha512.Sum512(m)
+		return c.wideScalar(h[:])
+	}
+	return c.hashToScalar("chal", m)
+}
+
+// h3 derives nonces
+func (c *Ciphersuite) h3(m []byte) curves.Scalar {
+	return c.hashToScalar("nonce", m)
+}
+
+// h4 hashes the message
+func (c *Ciphersuite) h4(m []byte) []byte {
+	return c.hash("msg", m)
+}
+
+// h5 hashes the encoded commitment list
+func (c *Ciphersuite) h5(m []byte) []byte {
+	return c.hash("com", m)
+}
+
+func (c *Ciphersuite) newHash() hash.Hash {
+	if c.edwards {
+		return sha512.New()
+	}
+	return sha256.New()
+}
+
+// hash returns H(contextString || tag || m)
+func (c *Ciphersuite) hash(tag string, m []byte) []byte {
+	h := c.newHash()
+	_, _ = h.Write([]byte(c.contextString + tag))
+	_, _ = h.Write(m)
+	return h.Sum(nil)
+}
+
+// hashToScalar reduces H(contextString || tag || m) for the Edwards ciphersuites and
+// is hash_to_field with expand_message_xmd and the DST contextString || tag otherwise
+func (c *Ciphersuite) hashToScalar(tag string, m []byte) curves.Scalar {
+	if c.edwards {
+		return c.wideScalar(c.hash(tag, m))
+	}
+	okm := native.ExpandMsgXmd(native.EllipticPointHasherSha256(), m, []byte(c.contextString+tag), expandLen)
+	v := new(big.Int).SetBytes(okm)
+	// The reduced value is always a valid scalar
+	s, _ := c.curve.Scalar.SetBigInt(v.Mod(v, c.order()))
+	return s
+}
+
+// wideScalar reduces a little-endian 64 byte digest
+func (c *Ciphersuite) wideScalar(h []byte) curves.Scalar {
+	// The input has the length SetBytesWide expects
+	s, _ := c.curve.Scalar.SetBytesWide(h)
+	return s
+}
+
+// order returns the order of the group
+func (c *Ciphersuite) order() *big.Int {
+	// P-256 and secp256k1 have an elliptic.Curve
+	ec, _ := c.curve.ToEllipticCurve()
+	return ec.Params().N
+}
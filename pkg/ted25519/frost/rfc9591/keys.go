@@ -0,0 +1,116 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package rfc9591
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// KeyPackage is the signing key of a participant
+type KeyPackage struct {
+	Identifier uint32
+	// The secret share sk_i
+	SecretShare curves.Scalar
+	// The public share PK_i = G * sk_i
+	PublicShare    curves.Point
+	GroupPublicKey curves.Point
+}
+
+// TrustedDealerKeygen splits `secret` into `maxParticipants` shares of which `minParticipants` can sign,
+// with identifiers 1 to maxParticipants. It returns the key packages and the VSS commitment to the
+// coefficients of the polynomial, which participants check their shares against with VerifyShare.
+// Random coefficients are read from `reader`, or crypto/rand if it is nil
+func (c *Ciphersuite) TrustedDealerKeygen(secret curves.Scalar, maxParticipants, minParticipants uint32,
+	reader io.Reader) ([]*KeyPackage, []curves.Point, error) {
+	if secret == nil || secret.IsZero() {
+		return nil, nil, fmt.Errorf("invalid secret")
+	}
+	if minParticipants < 2 || minParticipants > maxParticipants {
+		return nil, nil, fmt.Errorf("invalid number of participants")
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	coefficients := make([]curves.Scalar, minParticipants-1)
+	for i := range coefficients {
+		coefficients[i] = c.curve.Scalar.Random(reader)
+	}
+	return c.splitSecret(secret, coefficients, maxParticipants)
+}
+
+// splitSecret is secret_share_shard followed by vss_commit for the given coefficients of degree 1 and higher
+func (c *Ciphersuite) splitSecret(secret curves.Scalar, coefficients []curves.Scalar,
+	maxParticipants uint32) ([]*KeyPackage, []curves.Point, error) {
+	poly := sharing.Polynomial{Coefficients: append([]curves.Scalar{secret}, coefficients...)}
+	commitment := make([]curves.Point, len(poly.Coefficients))
+	for i, a := range poly.Coefficients {
+		commitment[i] = c.curve.ScalarBaseMult(a)
+	}
+	out := make([]*KeyPackage, maxParticipants)
+	for i := range out {
+		id := uint32(i + 1)
+		share := poly.Evaluate(c.curve.ScalarFromIndex(id))
+		out[i] = &KeyPackage{
+			Identifier:     id,
+			SecretShare:    share,
+			PublicShare:    c.curve.ScalarBaseMult(share),
+			GroupPublicKey: commitment[0],
+		}
+	}
+	return out, commitment, nil
+}
+
+// VerifyShare checks a key package against the VSS commitment of TrustedDealerKeygen
+func (c *Ciphersuite) VerifyShare(kp *KeyPackage, commitment []curves.Point) error {
+	if kp == nil || kp.SecretShare == nil || kp.PublicShare == nil || kp.GroupPublicKey == nil || len(commitment) == 0 {
+		return fmt.Errorf("invalid key package")
+	}
+	if kp.Identifier == 0 {
+		return fmt.Errorf("invalid identifier")
+	}
+	// sum commitment[j] * id^j
+	x := c.curve.ScalarFromIndex(kp.Identifier)
+	powers := make([]curves.Scalar, len(commitment))
+	powers[0] = c.curve.Scalar.One()
+	for j := 1; j < len(powers); j++ {
+		powers[j] = powers[j-1].Mul(x)
+	}
+	expected := c.curve.Point.SumOfProducts(commitment, powers)
+	if expected == nil || !expected.Equal(c.curve.ScalarBaseMult(kp.SecretShare)) || !expected.Equal(kp.PublicShare) {
+		return fmt.Errorf("share %d does not match the commitment", kp.Identifier)
+	}
+	if !kp.GroupPublicKey.Equal(commitment[0]) {
+		return fmt.Errorf("group public key does not match the commitment")
+	}
+	return nil
+}
+
+// lagrange is derive_interpolating_value, the Lagrange coefficient of `id` for the signers `ids`
+func (c *Ciphersuite) lagrange(id uint32, ids []uint32) (curves.Scalar, error) {
+	x := c.curve.ScalarFromIndex(id)
+	num := c.curve.Scalar.One()
+	den := c.curve.Scalar.One()
+	found := false
+	for _, j := range ids {
+		if j == id {
+			found = true
+			continue
+		}
+		xj := c.curve.ScalarFromIndex(j)
+		num = num.Mul(xj)
+		den = den.Mul(xj.Sub(x))
+	}
+	if !found {
+		return nil, fmt.Errorf("participant %d is not a signer", id)
+	}
+	return num.Div(den), nil
+}
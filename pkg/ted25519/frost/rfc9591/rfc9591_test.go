@@ -0,0 +1,366 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package rfc9591
+
+import (
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// vector holds the values of the test vectors of RFC 9591 appendix E with participants 1 and 3 of 2-of-3
+type vector struct {
+	suite              *Ciphersuite
+	groupSecretKey     string
+	groupPublicKey     string
+	coefficient        string
+	shares             [3]string
+	hidingRandomness   [2]string
+	bindingRandomness  [2]string
+	hidingNonces       [2]string
+	bindingNonces      [2]string
+	hidingCommitments  [2]string
+	bindingCommitments [2]string
+	bindingFactors     [2]string
+	sigShares          [2]string
+	sig                string
+}
+
+var vectors = []vector{
+	{
+		suite:          Ed25519Sha512(),
+		groupSecretKey: "7b1c33d3f5291d85de664833beb1ad469f7fb6025a0ec78b3a790c6e13a98304",
+		groupPublicKey: "15d21ccd7ee42959562fc8aa63224c8851fb3ec85a3faf66040d380fb9738673",
+		coefficient:    "178199860edd8c62f5212ee91eff1295d0d670ab4ed4506866bae57e7030b204",
+		shares: [3]string{
+			"929dcc590407aae7d388761cddb0c0db6f5627aea8e217f4a033f2ec83d93509",
+			"a91e66e012e4364ac9aaa405fcafd370402d9859f7b6685c07eed76bf409e80d",
+			"d3cb090a075eb154e82fdb4b3cb507f110040905468bb9c46da8bdea643a9a02",
+		},
+		hidingRandomness: [2]string{
+			"0fd2e39e111cdc266f6c0f4d0fd45c947761f1f5d3cb583dfcb9bbaf8d4c9fec",
+			"86d64a260059e495d0fb4fcc17ea3da7452391baa494d4b00321098ed2a0062f",
+		},
+		bindingRandomness: [2]string{
+			"69cd85f631d5f7f2721ed5e40519b1366f340a87c2f6856363dbdcda348a7501",
+			"13e6b25afb2eba51716a9a7d44130c0dbae0004a9ef8d7b5550c8a0e07c61775",
+		},
+		hidingNonces: [2]string{
+			"812d6104142944d5a55924de6d49940956206909f2acaeedecda2b726e630407",
+			"c256de65476204095ebdc01bd11dc10e57b36bc96284595b8215222374f99c0e",
+		},
+		bindingNonces: [2]string{
+			"b1110165fc2334149750b28dd813a39244f315cff14d4e89e6142f262ed83301",
+			"243d71944d929063bc51205714ae3c2218bd3451d0214dfb5aeec2a90c35180d",
+		},
+		hidingCommitments: [2]string{
+			"b5aa8ab305882a6fc69cbee9327e5a45e54c08af61ae77cb8207be3d2ce13de3",
+			"cfbdb165bd8aad6eb79deb8d287bcc0ab6658ae57fdcc98ed12c0669e90aec91",
+		},
+		bindingCommitments: [2]string{
+			"67e98ab55aa310c3120418e5050c9cf76cf387cb20ac9e4b6fdb6f82a469f932",
+			"7487bc41a6e712eea2f2af24681b58b1cf1da278ea11fe4e8b78398965f13552",
+		},
+		bindingFactors: [2]string{
+			"f2cb9d7dd9beff688da6fcc83fa89046b3479417f47f55600b106760eb3b5603",
+			"b087686bf35a13f3dc78e780a34b0fe8a77fef1b9938c563f5573d71d8d7890f",
+		},
+		sigShares: [2]string{
+			"001719ab5a53ee1a12095cd088fd149702c0720ce5fd2f29dbecf24b7281b603",
+			"bd86125de990acc5e1f13781d8e32c03a9bbd4c53539bbc106058bfd14326007",
+		},
+		sig: "36282629c383bb820a88b71cae937d41f2f2adfcc3d02e55507e2fb9e2dd3cbe" +
+			"bd9d2b0844e49ae0f3fa935161e1419aab7b47d21a37ebeae1f17d4987b3160b",
+	},
+	{
+		suite:          Ristretto255Sha512(),
+		groupSecretKey: "1b25a55e463cfd15cf14a5d3acc3d15053f08da49c8afcf3ab265f2ebc4f970b",
+		groupPublicKey: "e2a62f39eede11269e3bd5a7d97554f5ca384f9f6d3dd9c3c0d05083c7254f57",
+		coefficient:    "410f8b744b19325891d73736923525a4f596c805d060dfb9c98009d34e3fec02",
+		shares: [3]string{
+			"5c3430d391552f6e60ecdc093ff9f6f4488756aa6cebdbad75a768010b8f830e",
+			"b06fc5eac20b4f6e1b271d9df2343d843e1e1fb03c4cbb673f2872d459ce6f01",
+			"f17e505f0e2581c6acfe54d3846a622834b5e7b50cad9a2109a97ba7a80d5c04",
+		},
+		hidingRandomness: [2]string{
+			"f595a133b4d95c6e1f79887220c8b275ce6277e7f68a6640e1e7140f9be2fb5c",
+			"daa0cf42a32617786d390e0c7edfbf2efbd428037069357b5173ae61d6dd5d5e",
+		},
+		bindingRandomness: [2]string{
+			"34dd1001360e3513cb37bebfabe7be4a32c5bb91ba19fbd4360d039111f0fbdc",
+			"b4387e72b2e4108ce4168931cc2c7fcce5f345a5297368952c18b5fc8473f050",
+		},
+		hidingNonces: [2]string{
+			"214f2cabb86ed71427ea7ad4283b0fae26b6746c801ce824b83ceb2b99278c03",
+			"3f7927872b0f9051dd98dd73eb2b91494173bbe0feb65a3e7e58d3e2318fa40f",
+		},
+		bindingNonces: [2]string{
+			"c9b8f5e16770d15603f744f8694c44e335e8faef00dad182b8d7a34a62552f0c",
+			"ffd79445fb8030f0a3ddd3861aa4b42b618759282bfe24f1f9304c7009728305",
+		},
+		hidingCommitments: [2]string{
+			"965def4d0958398391fc06d8c2d72932608b1e6255226de4fb8d972dac15fd57",
+			"480e06e3de182bf83489c45d7441879932fd7b434a26af41455756264fbd5d6e",
+		},
+		bindingCommitments: [2]string{
+			"ec5170920660820007ae9e1d363936659ef622f99879898db86e5bf1d5bf2a14",
+			"3064746dfd3c1862ef58fc68c706da287dd925066865ceacc816b3a28c7b363b",
+		},
+		bindingFactors: [2]string{
+			"8967fd70fa06a58e5912603317fa94c77626395a695a0e4e4efc4476662eba0c",
+			"f2c1bb7c33a10511158c2f1766a4a5fadf9f86f2a92692ed333128277cc31006",
+		},
+		sigShares: [2]string{
+			"9285f875923ce7e0c491a592e9ea1865ec1b823ead4854b48c8a46287749ee09",
+			"7cb211fe0e3d59d25db6e36b3fb32344794139602a7b24f1ae0dc4e26ad7b908",
+		},
+		sig: "fc45655fbc66bbffad654ea4ce5fdae253a49a64ace25d9adb62010dd9fb2555" +
+			"2164141787162e5b4cab915b4aa45d94655dbb9ed7c378a53b980a0be220a802",
+	},
+	{
+		suite:          P256Sha256(),
+		groupSecretKey: "8ba9bba2e0fd8c4767154d35a0b7562244a4aaf6f36c8fb8735fa48b301bd8de",
+		groupPublicKey: "023a309ad94e9fe8a7ba45dfc58f38bf091959d3c99cfbd02b4dc00585ec45ab70",
+		coefficient:    "80f25e6c0709353e46bfbe882a11bdbb1f8097e46340eb8673b7e14556e6c3a4",
+		shares: [3]string{
+			"0c9c1a0fe806c184add50bbdcac913dda73e482daf95dcb9f35dbb0d8a9f7731",
+			"8d8e787bef0ff6c2f494ca45f4dad198c6bee01212d6c84067159c52e1863ad5",
+			"0e80d6e8f6192c003b5488ce1eec8f5429587d48cf001541e713b2d53c09d928",
+		},
+		hidingRandomness: [2]string{
+			"ec4c891c85fee802a9d757a67d1252e7f4e5efb8a538991ac18fbd0e06fb6fd3",
+			"c0451c5a0a5480d6c1f860e5db7d655233dca2669fd90ff048454b8ce983367b",
+		},
+		bindingRandomness: [2]string{
+			"9334e29d09061223f69a09421715a347e4e6deba77444c8f42b0c833f80f4ef9",
+			"2ba5f7793ae700e40e78937a82f407dd35e847e33d1e607b5c7eb6ed2a8ed799",
+		},
+		hidingNonces: [2]string{
+			"9f0542a5ba879a58f255c09f06da7102ef6a2dec6279700c656d58394d8facd4",
+			"f73444a8972bcda9e506bbca3d2b1c083c10facdf4bb5d47fef7c2dc1d9f2a0d",
+		},
+		bindingNonces: [2]string{
+			"6513dfe7429aa2fc972c69bb495b27118c45bbc6e654bb9dc9be55385b55c0d7",
+			"44c6a29075d6e7e4f8b97796205f9e22062e7835141470afe9417fd317c1c303",
+		},
+		hidingCommitments: [2]string{
+			"0213b3e6298bf8ad46fd5e9389519a8665d63d98f4ec6a1fcca434e809d2d8070e",
+			"033ac9a5fe4a8b57316ba1c34e8a6de453033b750e8984924a984eb67a11e73a3f",
+		},
+		bindingCommitments: [2]string{
+			"02188ff1390bf69374d7b272e454b1878ef10a6b6ea3ff36f114b300b4dbd5233b",
+			"03a7a2480ee16199262e648aea3acab628a53e9b8c1945078f2ddfbdc98b7df369",
+		},
+		bindingFactors: [2]string{
+			"7925f0d4693f204e6e59233e92227c7124664a99739d2c06b81cf64ddf90559e",
+			"e10d24a8a403723bcb6f9bb4c537f316593683b472f7a89f166630dde11822c4",
+		},
+		sigShares: [2]string{
+			"400308eaed7a2ddee02a265abe6a1cfe04d946ee8720768899619cfabe7a3aeb",
+			"561da3c179edbb0502d941bb3e3ace3c37d122aaa46fb54499f15f3a3331de44",
+		},
+		sig: "026d8d434874f87bdb7bc0dfd239b2c00639044f9dcb195e9a04426f70bfa4b70" +
+			"d9620acac6767e8e3e3036815fca4eb3a3caa69992b902bcd3352fc34f1ac192f",
+	},
+	{
+		suite:          Secp256k1Sha256(),
+		groupSecretKey: "0d004150d27c3bf2a42f312683d35fac7394b1e9e318249c1bfe7f0795a83114",
+		groupPublicKey: "02f37c34b66ced1fb51c34a90bdae006901f10625cc06c4f64663b0eae87d87b4f",
+		coefficient:    "fbf85eadae3058ea14f19148bb72b45e4399c0b16028acaf0395c9b03c823579",
+		shares: [3]string{
+			"08f89ffe80ac94dcb920c26f3f46140bfc7f95b493f8310f5fc1ea2b01f4254c",
+			"04f0feac2edcedc6ce1253b7fab8c86b856a797f44d83d82a385554e6e401984",
+			"00e95d59dd0d46b0e303e500b62b7ccb0e555d49f5b849f5e748c071da8c0dbc",
+		},
+		hidingRandomness: [2]string{
+			"7ea5ed09af19f6ff21040c07ec2d2adbd35b759da5a401d4c99dd26b82391cb2",
+			"e6cc56ccbd0502b3f6f831d91e2ebd01c4de0479e0191b66895a4ffd9b68d544",
+		},
+		bindingRandomness: [2]string{
+			"47acab018f116020c10cb9b9abdc7ac10aae1b48ca6e36dc15acb6ec9be5cdc5",
+			"7203d55eb82a5ca0d7d83674541ab55f6e76f1b85391d2c13706a89a064fd5b9",
+		},
+		hidingNonces: [2]string{
+			"841d3a6450d7580b4da83c8e618414d0f024391f2aeb511d7579224420aa81f0",
+			"2b19b13f193f4ce83a399362a90cdc1e0ddcd83e57089a7af0bdca71d47869b2",
+		},
+		bindingNonces: [2]string{
+			"8d2624f532af631377f33cf44b5ac5f849067cae2eacb88680a31e77c79b5a80",
+			"7a443bde83dc63ef52dda354005225ba0e553243402a4705ce28ffaafe0f5b98",
+		},
+		hidingCommitments: [2]string{
+			"03c699af97d26bb4d3f05232ec5e1938c12f1e6ae97643c8f8f11c9820303f1904",
+			"03077507ba327fc074d2793955ef3410ee3f03b82b4cdc2370f71d865beb926ef6",
+		},
+		bindingCommitments: [2]string{
+			"02fa2aaccd51b948c9dc1a325d77226e98a5a3fe65fe9ba213761a60123040a45e",
+			"02ad53031ddfbbacfc5fbda3d3b0c2445c8e3e99cbc4ca2db2aa283fa68525b135",
+		},
+		bindingFactors: [2]string{
+			"3e08fe561e075c653cbfd46908a10e7637c70c74f0a77d5fd45d1a750c739ec6",
+			"93f79041bb3fd266105be251adaeb5fd7f8b104fb554a4ba9a0becea48ddbfd7",
+		},
+		sigShares: [2]string{
+			"c4fce1775a1e141fb579944166eab0d65eefe7b98d480a569bbbfcb14f91c197",
+			"0160fd0d388932f4826d2ebcd6b9eaba734f7c71cf25b4279a4ca2581e47b18d",
+		},
+		sig: "0205b6d04d3774c8929413e3c76024d54149c372d57aae62574ed74319b5ea14d" +
+			"0c65dde8492a7471437e6c2fe3da49b90d23f642b5c6dbe7e36089f096dd97324",
+	},
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	out, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return out
+}
+
+func TestVectors(t *testing.T) {
+	msg := []byte("test")
+	for _, v := range vectors {
+		c := v.suite
+		t.Run(c.ContextString(), func(t *testing.T) {
+			secret, err := c.DeserializeScalar(decodeHex(t, v.groupSecretKey))
+			require.NoError(t, err)
+			coefficient, err := c.DeserializeScalar(decodeHex(t, v.coefficient))
+			require.NoError(t, err)
+			kps, commitment, err := c.splitSecret(secret, []curves.Scalar{coefficient}, 3)
+			require.NoError(t, err)
+			for i, kp := range kps {
+				require.Equal(t, v.shares[i], hex.EncodeToString(c.SerializeScalar(kp.SecretShare)))
+				require.Equal(t, v.groupPublicKey, hex.EncodeToString(kp.GroupPublicKey.ToAffineCompressed()))
+				require.NoError(t, c.VerifyShare(kp, commitment))
+			}
+
+			// Participants 1 and 3
+			signers := []*KeyPackage{kps[0], kps[2]}
+			nonces := make(map[uint32]*Nonces)
+			commitments := make(map[uint32]*Commitment)
+			for i, kp := range signers {
+				nonces[kp.Identifier] = &Nonces{
+					hiding:  c.nonceGenerate(kp.SecretShare, decodeHex(t, v.hidingRandomness[i])),
+					binding: c.nonceGenerate(kp.SecretShare, decodeHex(t, v.bindingRandomness[i])),
+				}
+				commitments[kp.Identifier] = c.commitment(nonces[kp.Identifier])
+				require.Equal(t, v.hidingNonces[i], hex.EncodeToString(c.SerializeScalar(nonces[kp.Identifier].hiding)))
+				require.Equal(t, v.bindingNonces[i], hex.EncodeToString(c.SerializeScalar(nonces[kp.Identifier].binding)))
+				require.Equal(t, v.hidingCommitments[i], hex.EncodeToString(commitments[kp.Identifier].Hiding.ToAffineCompressed()))
+				require.Equal(t, v.bindingCommitments[i], hex.EncodeToString(commitments[kp.Identifier].Binding.ToAffineCompressed()))
+			}
+
+			bindingFactors, _, _, err := c.groupCommitment(kps[0].GroupPublicKey, commitments, msg)
+			require.NoError(t, err)
+			shares := make(map[uint32]curves.Scalar)
+			for i, kp := range signers {
+				require.Equal(t, v.bindingFactors[i], hex.EncodeToString(c.SerializeScalar(bindingFactors[kp.Identifier])))
+				shares[kp.Identifier], err = c.Sign(kp, nonces[kp.Identifier], msg, commitments)
+				require.NoError(t, err)
+				require.Equal(t, v.sigShares[i], hex.EncodeToString(c.SerializeScalar(shares[kp.Identifier])))
+				require.NoError(t, c.VerifySignatureShare(kp.Identifier, kp.PublicShare, shares[kp.Identifier],
+					commitments, kp.GroupPublicKey, msg))
+			}
+			sig, err := c.Aggregate(commitments, msg, kps[0].GroupPublicKey, shares)
+			require.NoError(t, err)
+			require.NoError(t, c.Verify(kps[0].GroupPublicKey, msg, sig))
+			encoded, err := c.SerializeSignature(sig)
+			require.NoError(t, err)
+			require.Equal(t, v.sig, hex.EncodeToString(encoded))
+			decoded, err := c.DeserializeSignature(encoded)
+			require.NoError(t, err)
+			require.NoError(t, c.Verify(kps[0].GroupPublicKey, msg, decoded))
+		})
+	}
+}
+
+func TestSigning(t *testing.T) {
+	for _, c := range []*Ciphersuite{Ed25519Sha512(), Ristretto255Sha512(), P256Sha256(), Secp256k1Sha256()} {
+		t.Run(c.ContextString(), func(t *testing.T) {
+			kps, commitment, err := c.TrustedDealerKeygen(c.curve.Scalar.Random(crand.Reader), 5, 3, nil)
+			require.NoError(t, err)
+			for _, kp := range kps {
+				require.NoError(t, c.VerifyShare(kp, commitment))
+			}
+			signers := []*KeyPackage{kps[4], kps[1], kps[2]}
+			nonces := make(map[uint32]*Nonces)
+			commitments := make(map[uint32]*Commitment)
+			for _, kp := range signers {
+				nonces[kp.Identifier], commitments[kp.Identifier], err = c.Commit(kp.SecretShare, nil)
+				require.NoError(t, err)
+				// Commitments travel encoded
+				data, err := c.SerializeCommitment(commitments[kp.Identifier])
+				require.NoError(t, err)
+				commitments[kp.Identifier], err = c.DeserializeCommitment(data)
+				require.NoError(t, err)
+			}
+			msg := []byte("message")
+			shares := make(map[uint32]curves.Scalar)
+			for _, kp := range signers {
+				shares[kp.Identifier], err = c.Sign(kp, nonces[kp.Identifier], msg, commitments)
+				require.NoError(t, err)
+			}
+			sig, err := c.Aggregate(commitments, msg, kps[0].GroupPublicKey, shares)
+			require.NoError(t, err)
+			require.NoError(t, c.Verify(kps[0].GroupPublicKey, msg, sig))
+			require.Error(t, c.Verify(kps[0].GroupPublicKey, []byte("other message"), sig))
+
+			// Nonces are erased after signing
+			_, err = c.Sign(signers[0], nonces[signers[0].Identifier], msg, commitments)
+			require.Error(t, err)
+
+			// A bad share is identified
+			shares[2] = shares[2].Add(c.curve.Scalar.One())
+			sig, err = c.Aggregate(commitments, msg, kps[0].GroupPublicKey, shares)
+			require.NoError(t, err)
+			require.Error(t, c.Verify(kps[0].GroupPublicKey, msg, sig))
+			require.Error(t, c.VerifySignatureShare(2, kps[1].PublicShare, shares[2], commitments, kps[0].GroupPublicKey, msg))
+			require.NoError(t, c.VerifySignatureShare(3, kps[2].PublicShare, shares[3], commitments, kps[0].GroupPublicKey, msg))
+			publicShares := make(map[uint32]curves.Point)
+			for _, kp := range kps {
+				publicShares[kp.Identifier] = kp.PublicShare
+			}
+			_, err = c.AggregateVerified(commitments, msg, kps[0].GroupPublicKey, shares, publicShares)
+			var blame *InvalidShareError
+			require.True(t, errors.As(err, &blame))
+			require.Equal(t, []uint32{2}, blame.Ids)
+
+			// The identity is not a valid element
+			_, err = c.SerializeElement(c.curve.NewIdentityPoint())
+			require.Error(t, err)
+			_, err = c.DeserializeElement(make([]byte, len(c.curve.Point.ToAffineCompressed())))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEd25519Compatibility(t *testing.T) {
+	c := Ed25519Sha512()
+	kps, _, err := c.TrustedDealerKeygen(c.curve.Scalar.Random(crand.Reader), 3, 2, nil)
+	require.NoError(t, err)
+	nonces := make(map[uint32]*Nonces)
+	commitments := make(map[uint32]*Commitment)
+	for _, kp := range kps[:2] {
+		nonces[kp.Identifier], commitments[kp.Identifier], err = c.Commit(kp.SecretShare, nil)
+		require.NoError(t, err)
+	}
+	msg := []byte("message")
+	shares := make(map[uint32]curves.Scalar)
+	for _, kp := range kps[:2] {
+		shares[kp.Identifier], err = c.Sign(kp, nonces[kp.Identifier], msg, commitments)
+		require.NoError(t, err)
+	}
+	sig, err := c.Aggregate(commitments, msg, kps[0].GroupPublicKey, shares)
+	require.NoError(t, err)
+	encoded, err := c.SerializeSignature(sig)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(kps[0].GroupPublicKey.ToAffineCompressed(), msg, encoded))
+}
@@ -0,0 +1,347 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package rfc9591
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// Nonces are the secret nonces of round one, which must be used to sign at most once
+type Nonces struct {
+	hiding, binding curves.Scalar
+}
+
+// Commitment is the public commitment of round one to the nonces of a participant
+type Commitment struct {
+	Hiding, Binding curves.Point
+}
+
+// Signature is a Schnorr signature (R, z)
+type Signature struct {
+	R curves.Point
+	Z curves.Scalar
+}
+
+// Commit is round one of signing, which generates the nonces of a participant from the secret share and
+// 32 random bytes each, read from `reader` or crypto/rand if it is nil, and commits to them
+func (c *Ciphersuite) Commit(secretShare curves.Scalar, reader io.Reader) (*Nonces, *Commitment, error) {
+	if secretShare == nil {
+		return nil, nil, fmt.Errorf("invalid secret share")
+	}
+	if reader == nil {
+		reader = crand.Reader
+	}
+	var random [2][32]byte
+	for i := range random {
+		if _, err := io.ReadFull(reader, random[i][:]); err != nil {
+			return nil, nil, err
+		}
+	}
+	nonces := &Nonces{
+		hiding:  c.nonceGenerate(secretShare, random[0][:]),
+		binding: c.nonceGenerate(secretShare, random[1][:]),
+	}
+	return nonces, c.commitment(nonces), nil
+}
+
+// nonceGenerate is nonce_generate with the given random bytes
+func (c *Ciphersuite) nonceGenerate(secret curves.Scalar, random []byte) curves.Scalar {
+	return c.h3(append(append([]byte{}, random...), c.SerializeScalar(secret)...))
+}
+
+func (c *Ciphersuite) commitment(nonces *Nonces) *Commitment {
+	return &Commitment{
+		Hiding:  c.curve.ScalarBaseMult(nonces.hiding),
+		Binding: c.curve.ScalarBaseMult(nonces.binding),
+	}
+}
+
+// Sign is round two of signing, which returns the signature share of a participant for the commitments of
+// all signers, indexed by identifier. The nonces are erased so they cannot be used again
+func (c *Ciphersuite) Sign(kp *KeyPackage, nonces *Nonces, msg []byte, commitments map[uint32]*Commitment) (curves.Scalar, error) {
+	if kp == nil || kp.SecretShare == nil || kp.GroupPublicKey == nil {
+		return nil, fmt.Errorf("invalid key package")
+	}
+	if nonces == nil || nonces.hiding == nil || nonces.binding == nil {
+		return nil, fmt.Errorf("invalid nonces")
+	}
+	own, ok := commitments[kp.Identifier]
+	if !ok {
+		return nil, fmt.Errorf("missing commitment of participant %d", kp.Identifier)
+	}
+	expected := c.commitment(nonces)
+	if own == nil || !expected.Hiding.Equal(own.Hiding) || !expected.Binding.Equal(own.Binding) {
+		return nil, fmt.Errorf("commitment of participant %d does not match its nonces", kp.Identifier)
+	}
+	bindingFactors, groupCommitment, ids, err := c.groupCommitment(kp.GroupPublicKey, commitments, msg)
+	if err != nil {
+		return nil, err
+	}
+	lambda, err := c.lagrange(kp.Identifier, ids)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := c.challenge(groupCommitment, kp.GroupPublicKey, msg)
+	if err != nil {
+		return nil, err
+	}
+	// hiding_nonce + binding_nonce * binding_factor + lambda_i * sk_i * challenge
+	share := nonces.hiding.Add(nonces.binding.Mul(bindingFactors[kp.Identifier])).
+		Add(lambda.Mul(kp.SecretShare).Mul(challenge))
+	nonces.hiding, nonces.binding = nil, nil
+	return share, nil
+}
+
+// Aggregate sums the signature shares of all signers into the signature. The signature should be
+// verified, and if it is invalid VerifySignatureShare identifies the misbehaving participants
+func (c *Ciphersuite) Aggregate(commitments map[uint32]*Commitment, msg []byte, groupPublicKey curves.Point,
+	shares map[uint32]curves.Scalar) (*Signature, error) {
+	if len(shares) != len(commitments) {
+		return nil, fmt.Errorf("signature shares do not match the commitments")
+	}
+	_, groupCommitment, _, err := c.groupCommitment(groupPublicKey, commitments, msg)
+	if err != nil {
+		return nil, err
+	}
+	z := c.curve.Scalar.Zero()
+	for id := range commitments {
+		share, ok := shares[id]
+		if !ok || share == nil {
+			return nil, fmt.Errorf("missing signature share of participant %d", id)
+		}
+		z = z.Add(share)
+	}
+	return &Signature{R: groupCommitment, Z: z}, nil
+}
+
+// InvalidShareError is returned by AggregateVerified for the participants whose signature shares are invalid
+type InvalidShareError struct {
+	Ids []uint32
+}
+
+func (e *InvalidShareError) Error() string {
+	return fmt.Sprintf("invalid signature shares from participants %v", e.Ids)
+}
+
+// AggregateVerified aggregates and verifies the signature like Aggregate and Verify. If the signature is
+// invalid, every share is checked against the public share of its participant and an InvalidShareError
+// names the participants to blame
+func (c *Ciphersuite) AggregateVerified(commitments map[uint32]*Commitment, msg []byte, groupPublicKey curves.Point,
+	shares map[uint32]curves.Scalar, publicShares map[uint32]curves.Point) (*Signature, error) {
+	sig, err := c.Aggregate(commitments, msg, groupPublicKey, shares)
+	if err != nil {
+		return nil, err
+	}
+	if c.Verify(groupPublicKey, msg, sig) == nil {
+		return sig, nil
+	}
+	var invalid []uint32
+	for id, share := range shares {
+		if c.VerifySignatureShare(id, publicShares[id], share, commitments, groupPublicKey, msg) != nil {
+			invalid = append(invalid, id)
+		}
+	}
+	if len(invalid) == 0 {
+		// Valid shares always aggregate to a valid signature
+		return nil, fmt.Errorf("invalid signature")
+	}
+	sort.Slice(invalid, func(i, j int) bool { return invalid[i] < invalid[j] })
+	return nil, &InvalidShareError{Ids: invalid}
+}
+
+// VerifySignatureShare checks the signature share of participant `id`, whose public share is `publicShare`
+func (c *Ciphersuite) VerifySignatureShare(id uint32, publicShare curves.Point, share curves.Scalar,
+	commitments map[uint32]*Commitment, groupPublicKey curves.Point, msg []byte) error {
+	if publicShare == nil || share == nil {
+		return fmt.Errorf("invalid signature share")
+	}
+	commitment, ok := commitments[id]
+	if !ok {
+		return fmt.Errorf("missing commitment of participant %d", id)
+	}
+	bindingFactors, groupCommitment, ids, err := c.groupCommitment(groupPublicKey, commitments, msg)
+	if err != nil {
+		return err
+	}
+	lambda, err := c.lagrange(id, ids)
+	if err != nil {
+		return err
+	}
+	challenge, err := c.challenge(groupCommitment, groupPublicKey, msg)
+	if err != nil {
+		return err
+	}
+	// G * z_i == hiding + binding * binding_factor + PK_i * (challenge * lambda_i)
+	commShare := commitment.Hiding.Add(commitment.Binding.Mul(bindingFactors[id]))
+	r := commShare.Add(publicShare.Mul(challenge.Mul(lambda)))
+	if !c.curve.ScalarBaseMult(share).Equal(r) {
+		return fmt.Errorf("invalid signature share of participant %d", id)
+	}
+	return nil
+}
+
+// Verify checks a signature of `msg` under the group public key. FROST(Ed25519, SHA-512)
+// uses the cofactored equation [8][z]B == [8]R + [8][c]A of RFC 8032
+func (c *Ciphersuite) Verify(groupPublicKey curves.Point, msg []byte, sig *Signature) error {
+	if sig == nil || sig.R == nil || sig.Z == nil {
+		return fmt.Errorf("invalid signature")
+	}
+	challenge, err := c.challenge(sig.R, groupPublicKey, msg)
+	if err != nil {
+		return err
+	}
+	// G * z - PK * c - R
+	d := c.curve.ScalarBaseMult(sig.Z).Sub(groupPublicKey.Mul(challenge)).Sub(sig.R)
+	if c.rfc8032 {
+		d = d.Double().Double().Double()
+	}
+	if !d.IsIdentity() {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// SerializeSignature returns SerializeElement(R) || SerializeScalar(z)
+func (c *Ciphersuite) SerializeSignature(sig *Signature) ([]byte, error) {
+	if sig == nil || sig.Z == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	r, err := c.SerializeElement(sig.R)
+	if err != nil {
+		return nil, err
+	}
+	return append(r, c.SerializeScalar(sig.Z)...), nil
+}
+
+// DeserializeSignature decodes the output of SerializeSignature
+func (c *Ciphersuite) DeserializeSignature(in []byte) (*Signature, error) {
+	ptSize := len(c.curve.Point.ToAffineCompressed())
+	if len(in) != ptSize+len(c.curve.Scalar.Bytes()) {
+		return nil, fmt.Errorf("invalid signature length")
+	}
+	r, err := c.DeserializeElement(in[:ptSize])
+	if err != nil {
+		return nil, err
+	}
+	z, err := c.DeserializeScalar(in[ptSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{R: r, Z: z}, nil
+}
+
+// SerializeCommitment returns SerializeElement(hiding) || SerializeElement(binding)
+func (c *Ciphersuite) SerializeCommitment(comm *Commitment) ([]byte, error) {
+	if comm == nil {
+		return nil, fmt.Errorf("invalid commitment")
+	}
+	hiding, err := c.SerializeElement(comm.Hiding)
+	if err != nil {
+		return nil, err
+	}
+	binding, err := c.SerializeElement(comm.Binding)
+	if err != nil {
+		return nil, err
+	}
+	return append(hiding, binding...), nil
+}
+
+// DeserializeCommitment decodes the output of SerializeCommitment
+func (c *Ciphersuite) DeserializeCommitment(in []byte) (*Commitment, error) {
+	ptSize := len(c.curve.Point.ToAffineCompressed())
+	if len(in) != 2*ptSize {
+		return nil, fmt.Errorf("invalid commitment length")
+	}
+	hiding, err := c.DeserializeElement(in[:ptSize])
+	if err != nil {
+		return nil, err
+	}
+	binding, err := c.DeserializeElement(in[ptSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &Commitment{Hiding: hiding, Binding: binding}, nil
+}
+
+// encodeCommitmentList is encode_group_commitment_list of the commitments ordered by identifier
+func (c *Ciphersuite) encodeCommitmentList(ids []uint32, commitments map[uint32]*Commitment) ([]byte, error) {
+	var out []byte
+	for _, id := range ids {
+		comm, err := c.SerializeCommitment(commitments[id])
+		if err != nil {
+			return nil, fmt.Errorf("invalid commitment of participant %d", id)
+		}
+		out = append(out, c.SerializeScalar(c.curve.ScalarFromIndex(id))...)
+		out = append(out, comm...)
+	}
+	return out, nil
+}
+
+// bindingFactors is compute_binding_factors, which returns the binding factor of each signer
+func (c *Ciphersuite) bindingFactors(groupPublicKey curves.Point, ids []uint32, commitments map[uint32]*Commitment,
+	msg []byte) (map[uint32]curves.Scalar, error) {
+	pk, err := c.SerializeElement(groupPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group public key")
+	}
+	encoded, err := c.encodeCommitmentList(ids, commitments)
+	if err != nil {
+		return nil, err
+	}
+	prefix := append(append(pk, c.h4(msg)...), c.h5(encoded)...)
+	out := make(map[uint32]curves.Scalar, len(ids))
+	for _, id := range ids {
+		input := append(append([]byte{}, prefix...), c.SerializeScalar(c.curve.ScalarFromIndex(id))...)
+		out[id] = c.h1(input)
+	}
+	return out, nil
+}
+
+// groupCommitment returns the binding factors, the group commitment R of compute_group_commitment
+// and the signer identifiers in ascending order
+func (c *Ciphersuite) groupCommitment(groupPublicKey curves.Point, commitments map[uint32]*Commitment,
+	msg []byte) (map[uint32]curves.Scalar, curves.Point, []uint32, error) {
+	if len(commitments) < 2 {
+		return nil, nil, nil, fmt.Errorf("at least two signers are required")
+	}
+	ids := make([]uint32, 0, len(commitments))
+	for id := range commitments {
+		if id == 0 {
+			return nil, nil, nil, fmt.Errorf("invalid identifier")
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	bindingFactors, err := c.bindingFactors(groupPublicKey, ids, commitments, msg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	r := c.curve.NewIdentityPoint()
+	for _, id := range ids {
+		comm := commitments[id]
+		r = r.Add(comm.Hiding).Add(comm.Binding.Mul(bindingFactors[id]))
+	}
+	return bindingFactors, r, ids, nil
+}
+
+// challenge is compute_challenge, H2(SerializeElement(R) || SerializeElement(PK) || msg)
+func (c *Ciphersuite) challenge(r, groupPublicKey curves.Point, msg []byte) (curves.Scalar, error) {
+	rEnc, err := c.SerializeElement(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group commitment")
+	}
+	pk, err := c.SerializeElement(groupPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group public key")
+	}
+	return c.h2(append(append(rEnc, pk...), msg...)), nil
+}
@@ -0,0 +1,198 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package roast implements ROAST, robust asynchronous Schnorr threshold signatures of
+// https://eprint.iacr.org/2022/550, on top of the FROST signing of package rfc9591.
+// A coordinator starts a FROST session as soon as a threshold of signers is responsive, i.e. has sent a
+// fresh nonce commitment, and keeps starting sessions with other subsets while earlier sessions stall.
+// Signers which submit an invalid signature share are excluded. As long as at least a threshold of signers
+// is honest and responsive, one of the sessions completes, after at most n - t + 1 sessions are started.
+package roast
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ted25519/frost/rfc9591"
+)
+
+// SessionRequest asks the signers of a session to sign the message with their commitments
+type SessionRequest struct {
+	SessionId uint64
+	Message   []byte
+	// The commitments of the signers of the session, indexed by identifier
+	Commitments map[uint32]*rfc9591.Commitment
+}
+
+// Response is the message of a signer to the coordinator. Its first response only holds a commitment,
+// later ones the signature share of a session and a fresh commitment for the next session
+type Response struct {
+	Id         uint32
+	SessionId  uint64
+	Share      curves.Scalar
+	Commitment *rfc9591.Commitment
+}
+
+type session struct {
+	request *SessionRequest
+	shares  map[uint32]curves.Scalar
+}
+
+// Coordinator drives the signing of one message. It is not safe for concurrent use
+type Coordinator struct {
+	suite          *rfc9591.Ciphersuite
+	groupPublicKey curves.Point
+	publicShares   map[uint32]curves.Point
+	threshold      int
+	msg            []byte
+
+	// Signers with a fresh commitment which are not in a session, in the order they became responsive
+	responsive  []uint32
+	commitments map[uint32]*rfc9591.Commitment
+	malicious   map[uint32]bool
+	// The session each signer was last asked to sign in
+	signerSession map[uint32]*session
+	nextSession   uint64
+	signature     *rfc9591.Signature
+}
+
+// NewCoordinator creates a coordinator which signs `msg` under the group public key with `threshold`
+// of the signers, whose public shares are indexed by identifier
+func NewCoordinator(suite *rfc9591.Ciphersuite, groupPublicKey curves.Point, publicShares map[uint32]curves.Point,
+	threshold uint32, msg []byte) (*Coordinator, error) {
+	if suite == nil || groupPublicKey == nil || len(publicShares) == 0 {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	if threshold < 2 || int(threshold) > len(publicShares) {
+		return nil, fmt.Errorf("invalid threshold")
+	}
+	for id, share := range publicShares {
+		if id == 0 || share == nil || share.IsIdentity() {
+			return nil, fmt.Errorf("invalid public share of signer %d", id)
+		}
+	}
+	return &Coordinator{
+		suite:          suite,
+		groupPublicKey: groupPublicKey,
+		publicShares:   publicShares,
+		threshold:      int(threshold),
+		msg:            append([]byte{}, msg...),
+		commitments:    make(map[uint32]*rfc9591.Commitment),
+		malicious:      make(map[uint32]bool),
+		signerSession:  make(map[uint32]*session),
+		nextSession:    1,
+	}, nil
+}
+
+// Receive processes a response of a signer. It returns the request of a new session, to be sent to the
+// signers of its commitments, once a threshold of signers is responsive, and nil otherwise.
+// Responses of excluded signers and after the signature is complete are ignored. An error is returned
+// for invalid responses and when so many signers are excluded that no session can complete
+func (c *Coordinator) Receive(resp *Response) (*SessionRequest, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("invalid response")
+	}
+	if c.signature != nil || c.malicious[resp.Id] {
+		return nil, nil
+	}
+	publicShare, ok := c.publicShares[resp.Id]
+	if !ok {
+		return nil, fmt.Errorf("unknown signer %d", resp.Id)
+	}
+	if err := c.checkCommitment(resp.Commitment); err != nil {
+		return nil, fmt.Errorf("invalid commitment of signer %d: %v", resp.Id, err)
+	}
+
+	if s, ok := c.signerSession[resp.Id]; ok {
+		// The signer owes a signature share for its session
+		if resp.Share == nil || resp.SessionId != s.request.SessionId {
+			return nil, fmt.Errorf("signer %d must respond to session %d", resp.Id, s.request.SessionId)
+		}
+		err := c.suite.VerifySignatureShare(resp.Id, publicShare, resp.Share, s.request.Commitments,
+			c.groupPublicKey, c.msg)
+		if err != nil {
+			c.malicious[resp.Id] = true
+			delete(c.signerSession, resp.Id)
+			if len(c.malicious) > len(c.publicShares)-c.threshold {
+				return nil, fmt.Errorf("too many signers submitted invalid shares to complete a session")
+			}
+			return nil, nil
+		}
+		s.shares[resp.Id] = resp.Share
+		delete(c.signerSession, resp.Id)
+		if len(s.shares) == len(s.request.Commitments) {
+			sig, err := c.suite.Aggregate(s.request.Commitments, c.msg, c.groupPublicKey, s.shares)
+			if err != nil {
+				return nil, err
+			}
+			if err = c.suite.Verify(c.groupPublicKey, c.msg, sig); err != nil {
+				return nil, err
+			}
+			c.signature = sig
+			return nil, nil
+		}
+	} else {
+		if resp.Share != nil {
+			return nil, fmt.Errorf("signer %d is not in a session", resp.Id)
+		}
+		if _, ok := c.commitments[resp.Id]; ok {
+			return nil, fmt.Errorf("signer %d is already responsive", resp.Id)
+		}
+	}
+
+	c.commitments[resp.Id] = resp.Commitment
+	c.responsive = append(c.responsive, resp.Id)
+	if len(c.responsive) < c.threshold {
+		return nil, nil
+	}
+	return c.startSession(), nil
+}
+
+// startSession starts a session with the responsive signers
+func (c *Coordinator) startSession() *SessionRequest {
+	s := &session{
+		request: &SessionRequest{
+			SessionId:   c.nextSession,
+			Message:     c.msg,
+			Commitments: make(map[uint32]*rfc9591.Commitment, len(c.responsive)),
+		},
+		shares: make(map[uint32]curves.Scalar, len(c.responsive)),
+	}
+	c.nextSession++
+	for _, id := range c.responsive {
+		s.request.Commitments[id] = c.commitments[id]
+		c.signerSession[id] = s
+		delete(c.commitments, id)
+	}
+	c.responsive = nil
+	return s.request
+}
+
+func (c *Coordinator) checkCommitment(comm *rfc9591.Commitment) error {
+	_, err := c.suite.SerializeCommitment(comm)
+	return err
+}
+
+// Signature returns the signature once a session has completed, and nil before
+func (c *Coordinator) Signature() *rfc9591.Signature {
+	return c.signature
+}
+
+// Malicious returns the signers which submitted invalid signature shares in ascending order
+func (c *Coordinator) Malicious() []uint32 {
+	out := make([]uint32, 0, len(c.malicious))
+	for id := range c.malicious {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Sessions returns the number of sessions started
+func (c *Coordinator) Sessions() uint64 {
+	return c.nextSession - 1
+}
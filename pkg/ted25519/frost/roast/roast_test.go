@@ -0,0 +1,136 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package roast
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/ted25519/frost/rfc9591"
+)
+
+// simulate runs a signing in which the first responses arrive in `order`, `malicious` signers submit
+// invalid shares and `stalled` signers never answer a session request. Messages are delivered in order
+func simulate(t *testing.T, suite *rfc9591.Ciphersuite, n, threshold uint32, order []uint32,
+	malicious, stalled map[uint32]bool) (*Coordinator, error) {
+	kps, _, err := suite.TrustedDealerKeygen(suite.Curve().Scalar.Random(crand.Reader), n, threshold, nil)
+	require.NoError(t, err)
+	publicShares := make(map[uint32]curves.Point)
+	for _, kp := range kps {
+		publicShares[kp.Identifier] = kp.PublicShare
+	}
+	msg := []byte("message")
+	coordinator, err := NewCoordinator(suite, kps[0].GroupPublicKey, publicShares, threshold, msg)
+	require.NoError(t, err)
+
+	signers := make(map[uint32]*Signer)
+	var queue []*Response
+	for _, id := range order {
+		var resp *Response
+		signers[id], resp, err = NewSigner(suite, kps[id-1], msg, nil)
+		require.NoError(t, err)
+		queue = append(queue, resp)
+	}
+	for len(queue) > 0 && coordinator.Signature() == nil {
+		resp := queue[0]
+		queue = queue[1:]
+		req, err := coordinator.Receive(resp)
+		if err != nil {
+			return coordinator, err
+		}
+		if req == nil {
+			continue
+		}
+		for id := range req.Commitments {
+			if stalled[id] {
+				continue
+			}
+			resp, err := signers[id].Sign(req)
+			require.NoError(t, err)
+			if malicious[id] {
+				resp.Share = resp.Share.Add(suite.Curve().Scalar.One())
+			}
+			queue = append(queue, resp)
+		}
+	}
+	return coordinator, nil
+}
+
+func TestRoast(t *testing.T) {
+	for _, suite := range []*rfc9591.Ciphersuite{rfc9591.Ed25519Sha512(), rfc9591.Secp256k1Sha256()} {
+		// Signers 1 and 2 submit invalid shares and signer 6 stalls
+		coordinator, err := simulate(t, suite, 6, 3, []uint32{6, 1, 3, 2, 4, 5},
+			map[uint32]bool{1: true, 2: true}, map[uint32]bool{6: true})
+		require.NoError(t, err)
+		sig := coordinator.Signature()
+		require.NotNil(t, sig)
+		require.Equal(t, []uint32{1, 2}, coordinator.Malicious())
+		require.Equal(t, uint64(3), coordinator.Sessions())
+	}
+}
+
+func TestRoastAllHonest(t *testing.T) {
+	suite := rfc9591.Ristretto255Sha512()
+	coordinator, err := simulate(t, suite, 3, 2, []uint32{3, 1, 2}, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, coordinator.Signature())
+	// Signer 2 and the first share of session 1 make a threshold of responsive signers before session 1 completes
+	require.Equal(t, uint64(2), coordinator.Sessions())
+	require.Empty(t, coordinator.Malicious())
+}
+
+func TestRoastTooManyMalicious(t *testing.T) {
+	suite := rfc9591.Ed25519Sha512()
+	_, err := simulate(t, suite, 4, 3, []uint32{1, 2, 3, 4}, map[uint32]bool{1: true, 2: true}, nil)
+	require.Error(t, err)
+}
+
+func TestRoastBadResponses(t *testing.T) {
+	suite := rfc9591.Ed25519Sha512()
+	kps, _, err := suite.TrustedDealerKeygen(suite.Curve().Scalar.Random(crand.Reader), 3, 2, nil)
+	require.NoError(t, err)
+	publicShares := map[uint32]curves.Point{1: kps[0].PublicShare, 2: kps[1].PublicShare, 3: kps[2].PublicShare}
+	msg := []byte("message")
+	coordinator, err := NewCoordinator(suite, kps[0].GroupPublicKey, publicShares, 2, msg)
+	require.NoError(t, err)
+
+	signer1, resp1, err := NewSigner(suite, kps[0], msg, nil)
+	require.NoError(t, err)
+	_, resp2, err := NewSigner(suite, kps[1], msg, nil)
+	require.NoError(t, err)
+	req, err := coordinator.Receive(resp1)
+	require.NoError(t, err)
+	require.Nil(t, req)
+	// Twice the first response
+	_, err = coordinator.Receive(resp1)
+	require.Error(t, err)
+	_, err = coordinator.Receive(&Response{Id: 4, Commitment: resp1.Commitment})
+	require.Error(t, err)
+	_, err = coordinator.Receive(&Response{Id: 3})
+	require.Error(t, err)
+
+	req, err = coordinator.Receive(resp2)
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	signed, err := signer1.Sign(req)
+	require.NoError(t, err)
+	// A commitment signs at most once
+	_, err = signer1.Sign(req)
+	require.Error(t, err)
+	// A share for another session
+	_, err = coordinator.Receive(&Response{Id: 1, SessionId: 2, Share: signed.Share, Commitment: signed.Commitment})
+	require.Error(t, err)
+	// A request for another message
+	_, err = signer1.Sign(&SessionRequest{SessionId: 2, Message: []byte("other"), Commitments: req.Commitments})
+	require.Error(t, err)
+
+	_, err = NewCoordinator(suite, kps[0].GroupPublicKey, publicShares, 4, msg)
+	require.Error(t, err)
+}
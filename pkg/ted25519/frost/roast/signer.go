@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package roast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/etclab/kryptology/pkg/ted25519/frost/rfc9591"
+)
+
+// Signer is a signer of a ROAST signing of one message. Each of its commitments signs in at most one session
+type Signer struct {
+	suite      *rfc9591.Ciphersuite
+	kp         *rfc9591.KeyPackage
+	msg        []byte
+	reader     io.Reader
+	nonces     *rfc9591.Nonces
+	commitment *rfc9591.Commitment
+}
+
+// NewSigner creates a signer of `msg` and returns its first response, which holds its first commitment.
+// Nonces are generated with randomness from `reader`, or crypto/rand if it is nil
+func NewSigner(suite *rfc9591.Ciphersuite, kp *rfc9591.KeyPackage, msg []byte, reader io.Reader) (*Signer, *Response, error) {
+	if suite == nil || kp == nil || kp.SecretShare == nil {
+		return nil, nil, fmt.Errorf("invalid arguments")
+	}
+	s := &Signer{suite: suite, kp: kp, msg: append([]byte{}, msg...), reader: reader}
+	resp, err := s.commit(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Share = nil
+	return s, resp, nil
+}
+
+// Sign returns the signature share for a session together with a fresh commitment
+func (s *Signer) Sign(req *SessionRequest) (*Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("invalid request")
+	}
+	if !bytes.Equal(req.Message, s.msg) {
+		return nil, fmt.Errorf("session %d signs another message", req.SessionId)
+	}
+	own, ok := req.Commitments[s.kp.Identifier]
+	if !ok || s.nonces == nil || own == nil ||
+		!own.Hiding.Equal(s.commitment.Hiding) || !own.Binding.Equal(s.commitment.Binding) {
+		return nil, fmt.Errorf("session %d does not use the current commitment of signer %d", req.SessionId, s.kp.Identifier)
+	}
+	// The nonces are erased once a share is computed
+	share, err := s.suite.Sign(s.kp, s.nonces, s.msg, req.Commitments)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.commit(req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	resp.Share = share
+	return resp, nil
+}
+
+// commit generates the nonces of the next session
+func (s *Signer) commit(sessionId uint64) (*Response, error) {
+	nonces, commitment, err := s.suite.Commit(s.kp.SecretShare, s.reader)
+	if err != nil {
+		return nil, err
+	}
+	s.nonces, s.commitment = nonces, commitment
+	return &Response{Id: s.kp.Identifier, SessionId: sessionId, Commitment: commitment}, nil
+}
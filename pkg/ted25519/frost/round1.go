@@ -58,6 +58,11 @@ func (signer *Signer) SignRound1() (*Round1Bcast, error) {
 
 	ei := signer.curve.Scalar.Random(crand.Reader)
 
+	return signer.commitNonces(di, ei), nil
+}
+
+// commitNonces computes and stores the commitments to the nonces di, ei and advances to round 2
+func (signer *Signer) commitNonces(di, ei curves.Scalar) *Round1Bcast {
 	// Step 2 - Compute Di, Ei
 	Di := signer.curve.ScalarBaseMult(di)
 
@@ -74,5 +79,5 @@ func (signer *Signer) SignRound1() (*Round1Bcast, error) {
 	return &Round1Bcast{
 		Di,
 		Ei,
-	}, nil
+	}
 }
@@ -87,6 +87,10 @@ func (signer *Signer) SignRound2(msg []byte, round2Input map[uint32]*Round1Bcast
 			return nil, fmt.Errorf("commitment Ei is not on the curve with id %d\n", id)
 		}
 	}
+	// The signer's own commitment must be to its nonces, e.g. when an aggregator distributes preprocessed commitments
+	if own, ok := round2Input[signer.id]; !ok || !own.Di.Equal(signer.state.capD) || !own.Ei.Equal(signer.state.capE) {
+		return nil, fmt.Errorf("round2Input does not contain the commitment of this signer")
+	}
 	// Store Dj, Ej for further usage.
 	signer.state.commitments = round2Input
 
@@ -114,6 +118,11 @@ func (signer *Signer) SignRound2(msg []byte, round2Input map[uint32]*Round1Bcast
 		R = R.Add(Rj)
 	}
 
+	// The nonce of an adaptor pre-signature is shifted by the adaptor point
+	if signer.adaptor != nil {
+		R = R.Add(signer.adaptor)
+	}
+
 	// Step 7 - c = H(m, R)
 	c, err := signer.challengeDeriver.DeriveChallenge(msg, signer.verificationKey, R)
 	if err != nil {
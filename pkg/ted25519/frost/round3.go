@@ -8,6 +8,7 @@ package frost
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/etclab/kryptology/internal"
 	"github.com/etclab/kryptology/pkg/core/curves"
@@ -21,6 +22,16 @@ type Round3Bcast struct {
 	msg  []byte
 }
 
+// InvalidShareError is returned by SignRound3 when signature shares do not verify against the
+// verification key shares of their participants, which are the participants to blame
+type InvalidShareError struct {
+	Ids []uint32
+}
+
+func (e *InvalidShareError) Error() string {
+	return fmt.Sprintf("invalid signature shares from participants %v", e.Ids)
+}
+
 // Define frost signature type
 type Signature struct {
 	Z curves.Scalar
@@ -72,9 +83,18 @@ func (signer *Signer) SignRound3(round3Input map[uint32]*Round2Bcast) (*Round3Bc
 	// Step 1: For j in [1...t]
 	z := signer.curve.NewScalar()
 	negate := signer.state.sumR.IsNegative()
+	var invalid []uint32
 	for id, data := range round3Input {
 		zj := data.Zi
-		vkj := data.Vki
+		// The verification key share of the DKG, which the participant cannot choose
+		vkj, ok := signer.vkShares[id]
+		if !ok {
+			return nil, fmt.Errorf("participant %d is not a cosigner", id)
+		}
+		if zj == nil || (data.Vki != nil && !data.Vki.Equal(vkj)) {
+			invalid = append(invalid, id)
+			continue
+		}
 
 		// Step 2: Verify zj*G = Rj + c*Lj*vkj
 		// zj*G
@@ -95,18 +115,31 @@ func (signer *Signer) SignRound3(round3Input map[uint32]*Round2Bcast) (*Round3Bc
 
 		// Check equation
 		if !zjG.Equal(right) {
-			return nil, fmt.Errorf("zjG != right with participant id %d\n", id)
+			invalid = append(invalid, id)
+			continue
 		}
 
 		// Step 3 - z = z+zj
 		z = z.Add(zj)
 	}
+	if len(invalid) > 0 {
+		sort.Slice(invalid, func(i, j int) bool { return invalid[i] < invalid[j] })
+		return nil, &InvalidShareError{Ids: invalid}
+	}
 
 	// Step 4 - 7: Self verify the signature (z, c)
 	// Step 5 - R' = z*G + (-c)*vk
 	zG := signer.curve.ScalarBaseMult(z)
 	cvk := signer.verificationKey.Mul(signer.state.c.Neg())
 	tempR := zG.Add(cvk)
+	// A pre-signature is short of the adaptor secret, which shifts the nonce by the adaptor point
+	if signer.adaptor != nil {
+		if negate {
+			tempR = tempR.Sub(signer.adaptor)
+		} else {
+			tempR = tempR.Add(signer.adaptor)
+		}
+	}
 	// Step 6 - c' = H(m, R')
 	tempC, err := signer.challengeDeriver.DeriveChallenge(signer.state.msg, signer.verificationKey, tempR)
 	if err != nil {
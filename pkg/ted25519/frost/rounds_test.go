@@ -7,6 +7,7 @@
 package frost
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -312,7 +313,44 @@ func TestSignRound3BadInput(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestSignRound3Blame(t *testing.T) {
+	signer1, signer2, round3Input := PrepareRound3Input(t)
+
+	// Signer 2 sends an invalid share with a verification key share which makes it look valid
+	zi := round3Input[signer2.id].Zi.Add(testCurve.Scalar.New(1))
+	cLi := signer1.state.c.Mul(signer1.lCoeffs[signer2.id])
+	inv, err := cLi.Invert()
+	require.NoError(t, err)
+	round3Input[signer2.id] = &Round2Bcast{
+		Zi:  zi,
+		Vki: testCurve.ScalarBaseMult(zi).Sub(signer1.state.capRs[signer2.id]).Mul(inv),
+	}
+	_, err = signer1.SignRound3(round3Input)
+	var blame *InvalidShareError
+	require.True(t, errors.As(err, &blame))
+	require.Equal(t, []uint32{signer2.id}, blame.Ids)
+
+	// Both shares are invalid
+	signer1, signer2, round3Input = PrepareRound3Input(t)
+	for _, id := range []uint32{signer1.id, signer2.id} {
+		round3Input[id].Zi = round3Input[id].Zi.Add(testCurve.Scalar.New(1))
+	}
+	_, err = signer1.SignRound3(round3Input)
+	require.True(t, errors.As(err, &blame))
+	require.Equal(t, []uint32{signer1.id, signer2.id}, blame.Ids)
+}
+
 func TestFullRoundsWorks(t *testing.T) {
+	fullRounds(t, testCurve, &Ed25519ChallengeDeriver{})
+}
+
+func TestFullRoundsPasta(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.PALLAS(), curves.VESTA()} {
+		fullRounds(t, curve, &CurveChallengeDeriver{curve})
+	}
+}
+
+func fullRounds(t *testing.T, curve *curves.Curve, challengeDeriver ChallengeDerive) (curves.Point, *Signer, *Round3Bcast) {
 	// Give a full-round test (FROST DKG + FROST Signing) with threshold = 2 and limit = 3, same as the test of tECDSA
 	threshold := 2
 	limit := 3
@@ -329,7 +367,7 @@ func TestFullRoundsWorks(t *testing.T) {
 			otherIds[idx] = uint32(j)
 			idx++
 		}
-		p, err := dkg.NewDkgParticipant(uint32(i), uint32(threshold), ctx, testCurve, otherIds...)
+		p, err := dkg.NewDkgParticipant(uint32(i), uint32(threshold), ctx, curve, otherIds...)
 		require.NoError(t, err)
 		participants[uint32(i)] = p
 	}
@@ -358,7 +396,7 @@ func TestFullRoundsWorks(t *testing.T) {
 	}
 
 	// Prepare Lagrange coefficients
-	scheme, _ := sharing.NewShamir(uint32(threshold), uint32(limit), testCurve)
+	scheme, _ := sharing.NewShamir(uint32(threshold), uint32(limit), curve)
 
 	// Here we use {1, 3} as 2 of 3 cosigners, we can also set cosigners as {1, 2}, {2, 3}
 	signerIds := []uint32{1, 3}
@@ -366,7 +404,7 @@ func TestFullRoundsWorks(t *testing.T) {
 	require.NoError(t, err)
 	signers := make(map[uint32]*Signer, threshold)
 	for _, id := range signerIds {
-		signers[id], err = NewSigner(participants[id], id, uint32(threshold), lCoeffs, signerIds, &Ed25519ChallengeDeriver{})
+		signers[id], err = NewSigner(participants[id], id, uint32(threshold), lCoeffs, signerIds, challengeDeriver)
 		require.NoError(t, err)
 		require.NotNil(t, signers[id].skShare)
 	}
@@ -401,4 +439,9 @@ func TestFullRoundsWorks(t *testing.T) {
 	// require.Equal(t, z, result[3].Z)
 	require.Equal(t, result[1].C, result[3].C)
 	// require.Equal(t, c, result[3].C)
+
+	ok, err := Verify(curve, challengeDeriver, signers[1].verificationKey, msg, &Signature{result[1].Z, result[1].C})
+	require.NoError(t, err)
+	require.True(t, ok)
+	return participants[1].VerificationKey, signers[1], result[1]
 }
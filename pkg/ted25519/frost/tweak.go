@@ -0,0 +1,101 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// Tweak makes the signer sign for the verification key plus tweak * G. Every signer of a session must apply
+// the same tweaks, in the same order, before signing round 1. The tweak is added to the signing key share and
+// all verification key shares, which shifts the shared polynomial and keeps the shares consistent. With a
+// Bip340ChallengeDeriver the tweak is added to the key with an even Y coordinate, and the tweaked key is
+// negated again if it has an odd one
+func (signer *Signer) Tweak(tweak curves.Scalar) error {
+	if signer == nil || signer.curve == nil || tweak == nil {
+		return internal.ErrNilArguments
+	}
+	if signer.round != 1 {
+		return internal.ErrInvalidRound
+	}
+	tG := signer.curve.ScalarBaseMult(tweak)
+	verificationKey := signer.verificationKey.Add(tG)
+	if verificationKey.IsIdentity() {
+		return fmt.Errorf("tweaked verification key is the identity")
+	}
+	skShare, vkShare := signer.skShare.Add(tweak), signer.vkShare.Add(tG)
+	vkShares := make(map[uint32]curves.Point, len(signer.vkShares))
+	for id, vkj := range signer.vkShares {
+		vkShares[id] = vkj.Add(tG)
+	}
+	if _, even := signer.challengeDeriver.(evenKeyDeriver); even && verificationKey.IsNegative() {
+		skShare, vkShare, verificationKey = skShare.Neg(), vkShare.Neg(), verificationKey.Neg()
+		for id, vkj := range vkShares {
+			vkShares[id] = vkj.Neg()
+		}
+	}
+	signer.skShare, signer.vkShare, signer.verificationKey, signer.vkShares = skShare, vkShare, verificationKey, vkShares
+	return nil
+}
+
+// TaprootTweak makes a signer with a Bip340ChallengeDeriver sign for the BIP-341 taproot output key of
+// its verification key, the internal key, and the script tree with root hash `merkleRoot`. An empty
+// `merkleRoot` commits to no scripts, as recommended by BIP-86 for key path spending only
+func (signer *Signer) TaprootTweak(merkleRoot []byte) error {
+	if signer == nil || signer.verificationKey == nil {
+		return internal.ErrNilArguments
+	}
+	if _, ok := signer.challengeDeriver.(Bip340ChallengeDeriver); !ok {
+		return fmt.Errorf("taproot tweaks require a Bip340ChallengeDeriver")
+	}
+	tweak, err := TaprootTweak(signer.verificationKey, merkleRoot)
+	if err != nil {
+		return err
+	}
+	return signer.Tweak(tweak)
+}
+
+// TaprootTweak returns the BIP-341 tweak int(hashTapTweak(x(P) || merkleRoot)) of the internal key P,
+// a secp256k1 verification key, for a script tree with root hash `merkleRoot`, which is empty or 32 bytes
+func TaprootTweak(internalKey curves.Point, merkleRoot []byte) (curves.Scalar, error) {
+	if _, ok := internalKey.(*curves.PointK256); !ok || internalKey.IsIdentity() {
+		return nil, fmt.Errorf("invalid secp256k1 internal key")
+	}
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return nil, fmt.Errorf("merkle root must be empty or 32 bytes")
+	}
+	t := new(big.Int).SetBytes(bip340TaggedHash("TapTweak", xOnly(internalKey), merkleRoot))
+	if t.Cmp(btcec.S256().N) >= 0 {
+		return nil, fmt.Errorf("invalid taproot tweak")
+	}
+	return curves.K256().Scalar.SetBigInt(t)
+}
+
+// TaprootOutputKey returns the 32 byte x-only BIP-341 taproot output key of the internal key, a secp256k1
+// verification key, and the script tree with root hash `merkleRoot`. Signers which applied TaprootTweak
+// produce signatures that verify under it
+func TaprootOutputKey(internalKey curves.Point, merkleRoot []byte) ([]byte, error) {
+	tweak, err := TaprootTweak(internalKey, merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	p := internalKey
+	if p.IsNegative() {
+		p = p.Neg()
+	}
+	q := p.Add(curves.K256().ScalarBaseMult(tweak))
+	if q.IsIdentity() {
+		return nil, fmt.Errorf("taproot output key is the identity")
+	}
+	return xOnly(q), nil
+}
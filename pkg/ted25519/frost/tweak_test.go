@@ -0,0 +1,141 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	dkg "github.com/etclab/kryptology/pkg/dkg/frost"
+	"github.com/etclab/kryptology/pkg/sharing"
+)
+
+// signTweaked splits a random key 2-of-3 with a dealer, lets signers 1 and 3 apply `tweak` and sign `msg`
+func signTweaked(t *testing.T, curve *curves.Curve, challengeDeriver ChallengeDerive, msg []byte, tweak func(*Signer) error) (curves.Point, *Round3Bcast) {
+	verifier, shares, err := dkg.DealerKeygen(nil, 2, 3, curve, crand.Reader)
+	require.NoError(t, err)
+	signerIds := []uint32{1, 3}
+	scheme, _ := sharing.NewShamir(2, 3, curve)
+	lCoeffs, err := scheme.LagrangeCoeffs(signerIds)
+	require.NoError(t, err)
+	signers := make(map[uint32]*Signer)
+	round2Input := make(map[uint32]*Round1Bcast)
+	for _, id := range signerIds {
+		p, err := dkg.NewDealerParticipant(shares[id-1], verifier, 2, curve)
+		require.NoError(t, err)
+		signers[id], err = NewSigner(p, id, 2, lCoeffs, signerIds, challengeDeriver)
+		require.NoError(t, err)
+		require.NoError(t, tweak(signers[id]))
+		round2Input[id], err = signers[id].SignRound1()
+		require.NoError(t, err)
+	}
+	round3Input := make(map[uint32]*Round2Bcast)
+	for id, signer := range signers {
+		round3Input[id], err = signer.SignRound2(msg, round2Input)
+		require.NoError(t, err)
+	}
+	out, err := signers[1].SignRound3(round3Input)
+	require.NoError(t, err)
+	return verifier.Commitments[0], out
+}
+
+func TestTaprootOutputKeyVectors(t *testing.T) {
+	// From the wallet test vectors of BIP-341
+	tests := []struct {
+		internalKey, merkleRoot, tweak, outputKey string
+	}{
+		{
+			"d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d",
+			"",
+			"b86e7be8f39bab32a6f2c0443abbc210f0edac0e2c53d501b36b64437d9c6c70",
+			"53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343",
+		},
+		{
+			"187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+			"5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+			"cbd8679ba636c1110ea247542cfbd964131a6be84f873f7f3b62a777528ed001",
+			"147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+		},
+	}
+	for _, test := range tests {
+		x, _ := hex.DecodeString(test.internalKey)
+		merkleRoot, _ := hex.DecodeString(test.merkleRoot)
+		// The internal key is x-only, both points with its X coordinate have the same output key
+		for _, prefix := range []byte{2, 3} {
+			internalKey, err := curves.K256().Point.FromAffineCompressed(append([]byte{prefix}, x...))
+			require.NoError(t, err)
+			tweak, err := TaprootTweak(internalKey, merkleRoot)
+			require.NoError(t, err)
+			require.Equal(t, test.tweak, hex.EncodeToString(tweak.Bytes()))
+			outputKey, err := TaprootOutputKey(internalKey, merkleRoot)
+			require.NoError(t, err)
+			require.Equal(t, test.outputKey, hex.EncodeToString(outputKey))
+		}
+	}
+}
+
+func TestTaprootSigning(t *testing.T) {
+	msg := []byte("message")
+	merkleRoot := make([]byte, 32)
+	_, _ = crand.Read(merkleRoot)
+	// Cover internal and output keys with odd and even Y coordinates
+	for i := 0; i < 16; i++ {
+		root := merkleRoot
+		if i%2 == 0 {
+			root = nil
+		}
+		vk, out := signTweaked(t, curves.K256(), Bip340ChallengeDeriver{}, msg, func(s *Signer) error {
+			return s.TaprootTweak(root)
+		})
+		outputKey, err := TaprootOutputKey(vk, root)
+		require.NoError(t, err)
+		sig, err := Bip340Signature(out)
+		require.NoError(t, err)
+		ok, err := VerifyBip340(outputKey, msg, sig)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// The signature is not valid under the internal key
+		internalKey, err := Bip340PublicKey(vk)
+		require.NoError(t, err)
+		ok, _ = VerifyBip340(internalKey, msg, sig)
+		require.False(t, ok)
+	}
+}
+
+func TestAdditiveTweak(t *testing.T) {
+	msg := []byte("message")
+	tweak := testCurve.Scalar.Random(crand.Reader)
+	vk, out := signTweaked(t, testCurve, &Ed25519ChallengeDeriver{}, msg, func(s *Signer) error {
+		return s.Tweak(tweak)
+	})
+	tweaked := vk.Add(testCurve.ScalarBaseMult(tweak))
+	sig := append(out.R.ToAffineCompressed(), out.Z.Bytes()...)
+	require.True(t, ed25519.Verify(tweaked.ToAffineCompressed(), msg, sig))
+	require.False(t, ed25519.Verify(vk.ToAffineCompressed(), msg, sig))
+}
+
+func TestTweakBadInput(t *testing.T) {
+	signer1, _ := PrepareNewSigners(t)
+	require.Error(t, signer1.TaprootTweak(nil))
+	require.Equal(t, internal.ErrNilArguments, signer1.Tweak(nil))
+	require.NoError(t, signer1.Tweak(testCurve.Scalar.One()))
+	_, err := signer1.SignRound1()
+	require.NoError(t, err)
+	require.Equal(t, internal.ErrInvalidRound, signer1.Tweak(testCurve.Scalar.One()))
+
+	_, err = TaprootTweak(curves.K256().Point.Generator(), make([]byte, 31))
+	require.Error(t, err)
+	_, err = TaprootTweak(testCurve.Point.Generator(), nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,271 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	crand "crypto/rand"
+	"crypto/sha512"
+	"fmt"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing/v1"
+)
+
+// DkgProof is a Schnorr proof of knowledge of the secret contributed by a party to the DKG, the discrete
+// logarithm of its first VSS commitment
+type DkgProof struct {
+	C, Z curves.Scalar
+}
+
+// DkgDealing is the broadcast of a party in the first round of the DKG
+type DkgDealing struct {
+	ShareIdentifier byte
+	Commitments     Commitments
+	Proof           *DkgProof
+}
+
+// DkgComplaint is the broadcast of a party in the second round of the DKG, listing the parties whose share it
+// did not receive or which did not match their commitments
+type DkgComplaint struct {
+	ShareIdentifier byte
+	Accused         []byte
+}
+
+// DkgResult is the output of the DKG for a party
+type DkgResult struct {
+	KeyShare    *KeyShare
+	PublicKey   PublicKey
+	Commitments Commitments
+	// Qualified are the parties whose contributions make up the key
+	Qualified []byte
+}
+
+// DkgDeal generates the contribution of party `identifier` to a DKG without a trusted dealer, a sharing of a
+// random secret. It returns the dealing to broadcast, which proves knowledge of the secret under `ctx`, a
+// unique identifier of the DKG session, and the shares to send, the share of party j at index j-1. The party
+// keeps its own share and the shares it sent, to answer complaints
+func DkgDeal(config *ShareConfiguration, identifier byte, ctx []byte) (*DkgDealing, []*KeyShare, error) {
+	if config == nil || identifier == 0 || int(identifier) > config.N {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	secret := new(curves.ScalarEd25519).Random(crand.Reader)
+	shares, commitments, err := splitPrivateKey(config, reverseBytes(secret.Bytes()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Prove knowledge of the secret so no party can choose its contribution as a function of the others
+	k := new(curves.ScalarEd25519).Random(crand.Reader)
+	r := curves.ED25519().ScalarBaseMult(k)
+	c := dkgChallenge(identifier, ctx, commitments, r)
+	proof := &DkgProof{C: c, Z: k.Add(c.Mul(secret))}
+	return &DkgDealing{ShareIdentifier: identifier, Commitments: commitments, Proof: proof}, shares, nil
+}
+
+// DkgComplain checks the shares received by party `identifier`, indexed by the identifier of their sender,
+// against the dealings of all parties. It returns the complaint to broadcast, or nil if every share matches.
+// Dealings with an invalid proof are not complained about, all parties disqualify them in DkgCombine
+func DkgComplain(
+	config *ShareConfiguration,
+	identifier byte,
+	ctx []byte,
+	dealings []*DkgDealing,
+	shares map[byte]*KeyShare,
+) (*DkgComplaint, error) {
+	if config == nil || identifier == 0 || int(identifier) > config.N {
+		return nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	sorted, err := sortDealings(config, dealings)
+	if err != nil {
+		return nil, err
+	}
+	var accused []byte
+	for _, d := range sorted {
+		if d.ShareIdentifier == identifier || checkDkgDealing(config, d, ctx) != nil {
+			continue
+		}
+		if checkDealing(config, uint32(identifier), shares[d.ShareIdentifier], d.Commitments) != nil {
+			accused = append(accused, d.ShareIdentifier)
+		}
+	}
+	if len(accused) == 0 {
+		return nil, nil
+	}
+	return &DkgComplaint{ShareIdentifier: identifier, Accused: accused}, nil
+}
+
+// DkgAnswer returns the shares that party `identifier` dealt to the parties which complained about it, to
+// broadcast in the third round of the DKG. Answering makes these shares public, which reveals nothing more
+// than the complaining parties could learn from the shares they were sent
+func DkgAnswer(identifier byte, dealt []*KeyShare, complaints []*DkgComplaint) []*KeyShare {
+	var answers []*KeyShare
+	for _, c := range complaints {
+		if c == nil {
+			continue
+		}
+		for _, id := range c.Accused {
+			if id == identifier && int(c.ShareIdentifier) <= len(dealt) && c.ShareIdentifier > 0 {
+				answers = append(answers, dealt[c.ShareIdentifier-1])
+				break
+			}
+		}
+	}
+	return answers
+}
+
+// DkgCombine completes the DKG for party `identifier` from the shares it received, including its own. It
+// disqualifies the parties whose dealing has an invalid proof and those which did not answer a complaint about
+// them with a share matching their commitments, from the broadcast complaints and answers, indexed by the
+// identifier of their sender. It adds the shares of the remaining parties, taking answered shares in place of
+// those the party complained about, and returns its key share, the public key and VSS commitments of the key.
+// All parties must combine the same broadcasts, so they agree on the qualified parties
+func DkgCombine(
+	config *ShareConfiguration,
+	identifier byte,
+	ctx []byte,
+	dealings []*DkgDealing,
+	shares map[byte]*KeyShare,
+	complaints []*DkgComplaint,
+	answers map[byte][]*KeyShare,
+) (*DkgResult, error) {
+	if config == nil || identifier == 0 || int(identifier) > config.N {
+		return nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	sorted, err := sortDealings(config, dealings)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range complaints {
+		if c == nil || c.ShareIdentifier == 0 || int(c.ShareIdentifier) > config.N {
+			return nil, fmt.Errorf("ted25519: invalid complaint")
+		}
+	}
+
+	field := curves.NewField(curves.Ed25519Order())
+	value := field.Zero()
+	commitments := make(Commitments, config.T)
+	for k := range commitments {
+		commitments[k] = curves.ED25519().NewIdentityPoint()
+	}
+	var qualified []byte
+	for _, d := range sorted {
+		if checkDkgDealing(config, d, ctx) != nil {
+			continue
+		}
+		share, ok := resolveComplaints(config, identifier, d, shares[d.ShareIdentifier], complaints, answers[d.ShareIdentifier])
+		if !ok {
+			continue
+		}
+		if err := checkDealing(config, uint32(identifier), share, d.Commitments); err != nil {
+			return nil, fmt.Errorf("ted25519: invalid share of party %d without complaint: %v", d.ShareIdentifier, err)
+		}
+		qualified = append(qualified, d.ShareIdentifier)
+		value = value.Add(share.Value)
+		for k, c := range d.Commitments {
+			commitments[k] = commitments[k].Add(c)
+		}
+	}
+	if len(qualified) < config.T {
+		return nil, fmt.Errorf("ted25519: only %d parties are qualified, expected at least %d", len(qualified), config.T)
+	}
+	return &DkgResult{
+		KeyShare:    &KeyShare{&v1.ShamirShare{Identifier: uint32(identifier), Value: value}},
+		PublicKey:   commitments[0].ToAffineCompressed(),
+		Commitments: commitments,
+		Qualified:   qualified,
+	}, nil
+}
+
+// resolveComplaints checks that dealing `d` answered every complaint about it with a share matching its
+// commitments. It returns the share of party `identifier` to use and false if the dealer is disqualified
+func resolveComplaints(
+	config *ShareConfiguration,
+	identifier byte,
+	d *DkgDealing,
+	share *KeyShare,
+	complaints []*DkgComplaint,
+	answers []*KeyShare,
+) (*KeyShare, bool) {
+	answered := make(map[uint32]*KeyShare, len(answers))
+	for _, a := range answers {
+		if a != nil && a.ShamirShare != nil {
+			answered[a.Identifier] = a
+		}
+	}
+	for _, c := range complaints {
+		accused := false
+		for _, id := range c.Accused {
+			accused = accused || id == d.ShareIdentifier
+		}
+		if !accused {
+			continue
+		}
+		answer := answered[uint32(c.ShareIdentifier)]
+		if checkDealing(config, uint32(c.ShareIdentifier), answer, d.Commitments) != nil {
+			return nil, false
+		}
+		if c.ShareIdentifier == identifier {
+			share = answer
+		}
+	}
+	return share, true
+}
+
+// checkDkgDealing checks the commitments and proof of knowledge of a dealing
+func checkDkgDealing(config *ShareConfiguration, d *DkgDealing, ctx []byte) error {
+	if len(d.Commitments) != config.T || d.Proof == nil || d.Proof.C == nil || d.Proof.Z == nil {
+		return fmt.Errorf("invalid dealing")
+	}
+	for _, c := range d.Commitments {
+		if c == nil {
+			return fmt.Errorf("invalid commitments")
+		}
+	}
+	if d.Commitments[0].IsIdentity() {
+		return fmt.Errorf("invalid commitments")
+	}
+	// R = z*G - c*C_0
+	r := curves.ED25519().ScalarBaseMult(d.Proof.Z).Sub(d.Commitments[0].Mul(d.Proof.C))
+	if dkgChallenge(d.ShareIdentifier, ctx, d.Commitments, r).Cmp(d.Proof.C) != 0 {
+		return fmt.Errorf("invalid proof")
+	}
+	return nil
+}
+
+// dkgChallenge hashes the identifier and commitments of a dealing and the nonce of its proof
+func dkgChallenge(identifier byte, ctx []byte, commitments Commitments, r curves.Point) curves.Scalar {
+	h := sha512.New()
+	_, _ = h.Write([]byte("ted25519 dkg"))
+	_, _ = h.Write(ctx)
+	_, _ = h.Write([]byte{identifier})
+	for _, c := range commitments {
+		_, _ = h.Write(c.ToAffineCompressed())
+	}
+	_, _ = h.Write(r.ToAffineCompressed())
+	// SetBytesWide only fails for inputs which are not 64 bytes
+	c, _ := new(curves.ScalarEd25519).SetBytesWide(h.Sum(nil))
+	return c
+}
+
+// sortDealings sorts the dealings by identifier and checks that there is at most one of each party. Parties
+// without a dealing are not qualified
+func sortDealings(config *ShareConfiguration, dealings []*DkgDealing) ([]*DkgDealing, error) {
+	sorted := append([]*DkgDealing{}, dealings...)
+	for _, d := range sorted {
+		if d == nil || d.ShareIdentifier == 0 || int(d.ShareIdentifier) > config.N {
+			return nil, fmt.Errorf("ted25519: invalid dealing")
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ShareIdentifier < sorted[j].ShareIdentifier })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].ShareIdentifier == sorted[i-1].ShareIdentifier {
+			return nil, fmt.Errorf("ted25519: duplicate dealing of party %d", sorted[i].ShareIdentifier)
+		}
+	}
+	return sorted, nil
+}
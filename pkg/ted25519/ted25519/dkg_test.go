@@ -0,0 +1,188 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+var dkgCtx = []byte("test dkg")
+
+// dkgDeal runs the first round of the DKG and returns the dealings and the shares received by each party,
+// indexed by receiver then sender, along with the shares each party dealt
+func dkgDeal(t *testing.T, config *ShareConfiguration) ([]*DkgDealing, map[byte]map[byte]*KeyShare, map[byte][]*KeyShare) {
+	dealings := make([]*DkgDealing, config.N)
+	received := make(map[byte]map[byte]*KeyShare, config.N)
+	dealt := make(map[byte][]*KeyShare, config.N)
+	for i := range dealings {
+		id := byte(i + 1)
+		dealing, shares, err := DkgDeal(config, id, dkgCtx)
+		require.NoError(t, err)
+		dealings[i] = dealing
+		dealt[id] = shares
+		for j, share := range shares {
+			to := byte(j + 1)
+			if received[to] == nil {
+				received[to] = make(map[byte]*KeyShare)
+			}
+			received[to][id] = share
+		}
+	}
+	return dealings, received, dealt
+}
+
+// dkgFinish runs the complaint and answer rounds of the DKG and combines the results of all parties
+func dkgFinish(
+	t *testing.T,
+	config *ShareConfiguration,
+	dealings []*DkgDealing,
+	received map[byte]map[byte]*KeyShare,
+	dealt map[byte][]*KeyShare,
+	answering func(byte) bool,
+) []*DkgResult {
+	var complaints []*DkgComplaint
+	for id := 1; id <= config.N; id++ {
+		complaint, err := DkgComplain(config, byte(id), dkgCtx, dealings, received[byte(id)])
+		require.NoError(t, err)
+		if complaint != nil {
+			complaints = append(complaints, complaint)
+		}
+	}
+	answers := make(map[byte][]*KeyShare)
+	for id, shares := range dealt {
+		if answering(id) {
+			answers[id] = DkgAnswer(id, shares, complaints)
+		}
+	}
+	results := make([]*DkgResult, config.N)
+	for i := range results {
+		var err error
+		results[i], err = DkgCombine(config, byte(i+1), dkgCtx, dealings, received[byte(i+1)], complaints, answers)
+		require.NoError(t, err)
+		require.Equal(t, results[0].PublicKey, results[i].PublicKey)
+		require.Equal(t, results[0].Qualified, results[i].Qualified)
+	}
+	return results
+}
+
+// assertDkgResults checks that the key shares of the results sign and reconstruct the key
+func assertDkgResults(t *testing.T, config *ShareConfiguration, results []*DkgResult) {
+	keyShares := make([]*KeyShare, len(results))
+	for i, r := range results {
+		keyShares[i] = r.KeyShare
+		verificationShare, err := VerificationShare(results[0].Commitments, byte(i+1))
+		require.NoError(t, err)
+		value, err := elementToScalar(r.KeyShare.Value)
+		require.NoError(t, err)
+		require.Equal(t, []byte(verificationShare), curves.ED25519().ScalarBaseMult(value).ToAffineCompressed())
+	}
+	secret, err := Reconstruct(keyShares, config)
+	require.NoError(t, err)
+	scalar, err := elementToScalar(curves.NewField(curves.Ed25519Order()).ElementFromBytes(secret))
+	require.NoError(t, err)
+	require.Equal(t, []byte(results[0].PublicKey), curves.ED25519().ScalarBaseMult(scalar).ToAffineCompressed())
+
+	assertSharesSign(t, config, keyShares, results[0].PublicKey, results[0].Commitments)
+}
+
+func allAnswer(byte) bool { return true }
+
+func TestDkg(t *testing.T) {
+	for _, config := range []*ShareConfiguration{{T: 2, N: 2}, {T: 2, N: 3}, {T: 3, N: 5}} {
+		dealings, received, dealt := dkgDeal(t, config)
+		results := dkgFinish(t, config, dealings, received, dealt, allAnswer)
+		require.Len(t, results[0].Qualified, config.N)
+		assertDkgResults(t, config, results)
+	}
+}
+
+func TestDkgComplaints(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 4}
+
+	// Party 2 sends party 3 a bad share and answers the complaint with the right one
+	dealings, received, dealt := dkgDeal(t, config)
+	good := received[3][2]
+	received[3][2] = received[4][2]
+	complaint, err := DkgComplain(config, 3, dkgCtx, dealings, received[3])
+	require.NoError(t, err)
+	require.Equal(t, &DkgComplaint{ShareIdentifier: 3, Accused: []byte{2}}, complaint)
+	results := dkgFinish(t, config, dealings, received, dealt, allAnswer)
+	require.Equal(t, []byte{1, 2, 3, 4}, results[0].Qualified)
+	require.Equal(t, good.Value.Bytes(), DkgAnswer(2, dealt[2], []*DkgComplaint{complaint})[0].Value.Bytes())
+	assertDkgResults(t, config, results)
+
+	// Party 2 does not answer and is disqualified
+	dealings, received, dealt = dkgDeal(t, config)
+	delete(received[3], 2)
+	results = dkgFinish(t, config, dealings, received, dealt, func(id byte) bool { return id != 2 })
+	require.Equal(t, []byte{1, 3, 4}, results[0].Qualified)
+	assertDkgResults(t, config, results)
+
+	// Party 2 answers with a share which does not match its commitments and is disqualified
+	dealings, received, dealt = dkgDeal(t, config)
+	received[3][2] = received[4][2]
+	dealt[2][2] = received[4][2]
+	results = dkgFinish(t, config, dealings, received, dealt, allAnswer)
+	require.Equal(t, []byte{1, 3, 4}, results[0].Qualified)
+	assertDkgResults(t, config, results)
+}
+
+func TestDkgInvalidProof(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+
+	// A dealing proven under another context is disqualified
+	dealings, received, dealt := dkgDeal(t, config)
+	other, shares, err := DkgDeal(config, 1, []byte("other dkg"))
+	require.NoError(t, err)
+	dealings[0] = other
+	for i, share := range shares {
+		received[byte(i+1)][1] = share
+	}
+	results := dkgFinish(t, config, dealings, received, dealt, allAnswer)
+	require.Equal(t, []byte{2, 3}, results[0].Qualified)
+	assertDkgResults(t, config, results)
+
+	// Copying the commitments of another party fails the proof
+	dealings, received, dealt = dkgDeal(t, config)
+	dealings[0] = &DkgDealing{ShareIdentifier: 1, Commitments: dealings[1].Commitments, Proof: dealings[1].Proof}
+	for id := range received {
+		received[id][1] = received[id][2]
+	}
+	results = dkgFinish(t, config, dealings, received, dealt, allAnswer)
+	require.Equal(t, []byte{2, 3}, results[0].Qualified)
+}
+
+func TestDkgBadInput(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	_, _, err := DkgDeal(config, 0, dkgCtx)
+	require.Error(t, err)
+	_, _, err = DkgDeal(config, 4, dkgCtx)
+	require.Error(t, err)
+	_, _, err = DkgDeal(nil, 1, dkgCtx)
+	require.Error(t, err)
+
+	dealings, received, _ := dkgDeal(t, config)
+	_, err = DkgComplain(config, 1, dkgCtx, append(dealings, dealings[0]), received[1])
+	require.Error(t, err)
+	_, err = DkgCombine(config, 1, dkgCtx, []*DkgDealing{dealings[0], nil}, received[1], nil, nil)
+	require.Error(t, err)
+	_, err = DkgCombine(config, 1, dkgCtx, dealings, received[1], []*DkgComplaint{{ShareIdentifier: 4}}, nil)
+	require.Error(t, err)
+
+	// Too few qualified parties
+	_, err = DkgCombine(config, 1, dkgCtx, dealings[:1], received[1], nil, nil)
+	require.Error(t, err)
+
+	// A bad share which the party did not complain about
+	received[1][2] = received[3][2]
+	_, err = DkgCombine(config, 1, dkgCtx, dealings, received[1], nil, nil)
+	require.Error(t, err)
+}
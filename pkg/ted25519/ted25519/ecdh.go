@@ -0,0 +1,180 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	crand "crypto/rand"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// ECDHProof is a Chaum-Pedersen proof that a partial ECDH result uses the key share of its party
+type ECDHProof struct {
+	C, Z curves.Scalar
+}
+
+// PartialECDH is the contribution of a party to a threshold X25519 key agreement, the product of its key share
+// and the peer public key as a compressed Edwards point
+type PartialECDH struct {
+	ShareIdentifier byte
+	Point           []byte
+	Proof           *ECDHProof
+}
+
+// X25519PublicKey returns the X25519 public key, the RFC 7748 u-coordinate, equivalent to the public key of a
+// shared key. Counterparties use it for key agreement with the parties, e.g. to encrypt to them with ECIES or
+// HPKE
+func X25519PublicKey(pub PublicKey) ([]byte, error) {
+	p, err := new(curves.PointEd25519).FromAffineCompressed(pub)
+	if err != nil {
+		return nil, err
+	}
+	return p.(*curves.PointEd25519).ToMontgomery(), nil
+}
+
+// ECDHShare computes the contribution of the holder of key share `s` to the X25519 key agreement of the shared
+// key with the X25519 public key `peer`, with a proof that it uses the key share
+func ECDHShare(s *KeyShare, peer []byte) (*PartialECDH, error) {
+	if s == nil || s.ShamirShare == nil || s.Value == nil || s.Identifier == 0 || s.Identifier > 255 {
+		return nil, fmt.Errorf("ted25519: invalid key share")
+	}
+	base, err := ecdhBase(peer)
+	if err != nil {
+		return nil, err
+	}
+	x, err := elementToScalar(s.Value)
+	if err != nil {
+		return nil, err
+	}
+	ed := curves.ED25519()
+	verificationShare := ed.ScalarBaseMult(x)
+	point := base.Mul(x)
+
+	w := new(curves.ScalarEd25519).Random(crand.Reader)
+	c := ecdhChallenge(base, verificationShare, point, ed.ScalarBaseMult(w), base.Mul(w))
+	return &PartialECDH{
+		ShareIdentifier: byte(s.Identifier),
+		Point:           point.ToAffineCompressed(),
+		Proof:           &ECDHProof{C: c, Z: w.Add(c.Mul(x))},
+	}, nil
+}
+
+// VerifyPartialECDH checks that a partial result of the key agreement with `peer` uses the key share whose
+// public key is `verificationShare`
+func VerifyPartialECDH(partial *PartialECDH, peer []byte, verificationShare PublicKey) (bool, error) {
+	if partial == nil || partial.Proof == nil || partial.Proof.C == nil || partial.Proof.Z == nil {
+		return false, fmt.Errorf("ted25519: invalid partial ECDH")
+	}
+	base, err := ecdhBase(peer)
+	if err != nil {
+		return false, err
+	}
+	point, err := new(curves.PointEd25519).FromAffineCompressed(partial.Point)
+	if err != nil {
+		return false, fmt.Errorf("ted25519: invalid partial ECDH")
+	}
+	v, err := new(curves.PointEd25519).FromAffineCompressed(verificationShare)
+	if err != nil {
+		return false, fmt.Errorf("ted25519: invalid verification share")
+	}
+	// The commitments are z*G - c*V and z*Q - c*D
+	cNeg := partial.Proof.C.Neg()
+	commitG := curves.ED25519().ScalarBaseMult(partial.Proof.Z).Add(v.Mul(cNeg))
+	commitQ := base.Mul(partial.Proof.Z).Add(point.Mul(cNeg))
+	return ecdhChallenge(base, v, point, commitG, commitQ).Cmp(partial.Proof.C) == 0, nil
+}
+
+// CombineECDH verifies the partial results of the key agreement with `peer` against the verification shares of
+// their parties, from the VSS commitments of the key, and combines the valid ones. It returns the shared secret,
+// the X25519 output the holder of the whole key would compute, and the identifiers of the parties whose partial
+// result is invalid. It fails if fewer than a threshold of partial results are valid
+func CombineECDH(
+	partials []*PartialECDH,
+	config *ShareConfiguration,
+	peer []byte,
+	keyCommitments Commitments,
+) ([]byte, []byte, error) {
+	if config == nil || len(keyCommitments) != config.T {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	valid := make(map[byte]curves.Point, len(partials))
+	seen := make(map[byte]bool, len(partials))
+	var ids, invalid []byte
+	for _, partial := range partials {
+		if partial == nil {
+			return nil, nil, fmt.Errorf("ted25519: invalid partial ECDH")
+		}
+		id := partial.ShareIdentifier
+		if seen[id] || id == 0 || int(id) > config.N {
+			return nil, nil, fmt.Errorf("ted25519: invalid partial ECDH of party %d", id)
+		}
+		seen[id] = true
+		verificationShare, err := VerificationShare(keyCommitments, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok, err := VerifyPartialECDH(partial, peer, verificationShare); err != nil || !ok {
+			invalid = append(invalid, id)
+			continue
+		}
+		// The proof checked that the point decodes
+		valid[id], _ = new(curves.PointEd25519).FromAffineCompressed(partial.Point)
+		ids = append(ids, id)
+	}
+	if len(ids) < config.T {
+		return nil, invalid, fmt.Errorf("ted25519: only %d valid partial ECDH, expected at least %d", len(ids), config.T)
+	}
+	lambdas, err := lagrangeCoefficients(ids)
+	if err != nil {
+		return nil, invalid, err
+	}
+	shared := curves.ED25519().NewIdentityPoint()
+	for _, id := range ids {
+		lambda, err := elementToScalar(lambdas[id])
+		if err != nil {
+			return nil, invalid, err
+		}
+		shared = shared.Add(valid[id].Mul(lambda))
+	}
+	if shared.IsIdentity() {
+		return nil, invalid, curves.ErrLowOrderPoint
+	}
+	return shared.(*curves.PointEd25519).ToMontgomery(), invalid, nil
+}
+
+// ecdhBase returns the component of prime order of the Edwards point equivalent to `peer`. X25519 clears the
+// low order component with the cofactor in the clamped scalar, which the key shares do not have
+func ecdhBase(peer []byte) (curves.Point, error) {
+	p, err := new(curves.PointEd25519).FromMontgomery(peer)
+	if err != nil {
+		return nil, fmt.Errorf("ted25519: invalid peer public key: %v", err)
+	}
+	ed := curves.ED25519()
+	eightInv, err := ed.Scalar.New(8).Invert()
+	if err != nil {
+		return nil, err
+	}
+	base := p.Mul(ed.Scalar.New(8)).Mul(eightInv)
+	if base.IsIdentity() {
+		return nil, curves.ErrLowOrderPoint
+	}
+	return base, nil
+}
+
+// ecdhChallenge hashes the statement and commitments of an ECDHProof
+func ecdhChallenge(points ...curves.Point) curves.Scalar {
+	h := sha512.New()
+	_, _ = h.Write([]byte("ted25519 ecdh"))
+	for _, p := range points {
+		_, _ = h.Write(p.ToAffineCompressed())
+	}
+	// SetBytesWide only fails for inputs which are not 64 bytes
+	c, _ := new(curves.ScalarEd25519).SetBytesWide(h.Sum(nil))
+	return c
+}
@@ -0,0 +1,137 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// partialECDHs computes the partial results of all key shares for `peer`
+func partialECDHs(t *testing.T, keyShares []*KeyShare, peer []byte) []*PartialECDH {
+	partials := make([]*PartialECDH, len(keyShares))
+	for i, s := range keyShares {
+		var err error
+		partials[i], err = ECDHShare(s, peer)
+		require.NoError(t, err)
+	}
+	return partials
+}
+
+func TestThresholdECDH(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	pub, keyShares, keyCommitments, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	u, err := X25519PublicKey(pub)
+	require.NoError(t, err)
+
+	// The counterparty computes the same secret with the X25519 public key of the parties
+	secret, peer, err := curves.NewX25519Key(crand.Reader)
+	require.NoError(t, err)
+	expected, err := curves.X25519(secret, u)
+	require.NoError(t, err)
+
+	partials := partialECDHs(t, keyShares, peer)
+	for _, pair := range [][]int{{0, 1}, {1, 2}, {0, 2}, {0, 1, 2}} {
+		var subset []*PartialECDH
+		for _, i := range pair {
+			subset = append(subset, partials[i])
+		}
+		shared, invalid, err := CombineECDH(subset, config, peer, keyCommitments)
+		require.NoError(t, err)
+		require.Empty(t, invalid)
+		require.Equal(t, expected, shared)
+	}
+}
+
+func TestThresholdECDHMatchesX25519(t *testing.T) {
+	config := &ShareConfiguration{T: 3, N: 4}
+	// Share the clamped X25519 secret reduced by the group order
+	secret, u, err := curves.NewX25519Key(crand.Reader)
+	require.NoError(t, err)
+	s, err := new(curves.ScalarEd25519).SetBytesClamping(secret)
+	require.NoError(t, err)
+	keyShares, keyCommitments, err := splitPrivateKey(config, reverseBytes(s.Bytes()))
+	require.NoError(t, err)
+	x25519Pub, err := X25519PublicKey(keyCommitments[0].ToAffineCompressed())
+	require.NoError(t, err)
+	require.Equal(t, u, x25519Pub)
+
+	// A peer point with a low order component, which X25519 clears with the cofactor
+	lowOrder, _ := hex.DecodeString("e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b800")
+	torsion, err := new(curves.PointEd25519).FromMontgomery(lowOrder)
+	require.NoError(t, err)
+	p := curves.ED25519().Point.Random(crand.Reader).Add(torsion).(*curves.PointEd25519)
+	peer := p.ToMontgomery()
+	expected, err := curves.X25519(secret, peer)
+	require.NoError(t, err)
+
+	shared, invalid, err := CombineECDH(partialECDHs(t, keyShares[1:], peer), config, peer, keyCommitments)
+	require.NoError(t, err)
+	require.Empty(t, invalid)
+	require.Equal(t, expected, shared)
+
+	// Peers of low order are rejected
+	_, err = ECDHShare(keyShares[0], lowOrder)
+	require.Error(t, err)
+}
+
+func TestThresholdECDHInvalidPartial(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	_, keyShares, keyCommitments, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	_, peer, err := curves.NewX25519Key(crand.Reader)
+	require.NoError(t, err)
+	partials := partialECDHs(t, keyShares, peer)
+
+	verificationShare, err := VerificationShare(keyCommitments, 1)
+	require.NoError(t, err)
+	ok, err := VerifyPartialECDH(partials[0], peer, verificationShare)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = VerifyPartialECDH(partials[1], peer, verificationShare)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Party 2 uses another key share, its result is dropped
+	_, otherShares, _, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	bad, err := ECDHShare(otherShares[1], peer)
+	require.NoError(t, err)
+	expected, _, err := CombineECDH([]*PartialECDH{partials[0], partials[2]}, config, peer, keyCommitments)
+	require.NoError(t, err)
+	shared, invalid, err := CombineECDH([]*PartialECDH{partials[0], bad, partials[2]}, config, peer, keyCommitments)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, invalid)
+	require.Equal(t, expected, shared)
+
+	// Too few valid partial results
+	_, invalid, err = CombineECDH([]*PartialECDH{partials[0], bad}, config, peer, keyCommitments)
+	require.Error(t, err)
+	require.Equal(t, []byte{2}, invalid)
+
+	// A partial result computed for another peer
+	_, otherPeer, err := curves.NewX25519Key(crand.Reader)
+	require.NoError(t, err)
+	other, err := ECDHShare(keyShares[1], otherPeer)
+	require.NoError(t, err)
+	_, invalid, err = CombineECDH([]*PartialECDH{partials[0], other, partials[2]}, config, peer, keyCommitments)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, invalid)
+
+	_, _, err = CombineECDH([]*PartialECDH{partials[0], partials[0]}, config, peer, keyCommitments)
+	require.Error(t, err)
+	_, _, err = CombineECDH(partials, config, peer, keyCommitments[1:])
+	require.Error(t, err)
+	_, err = ECDHShare(nil, peer)
+	require.Error(t, err)
+}
@@ -0,0 +1,538 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+)
+
+// KeygenResult is the output of key generation of a party
+type KeygenResult struct {
+	Config      *ShareConfiguration
+	KeyShare    *KeyShare
+	PublicKey   PublicKey
+	Commitments Commitments
+}
+
+// KeygenIterator runs key generation with GenerateSharedKey as a protocol.Iterator, so it can be driven by the
+// same transport as the DKLs18 protocols, e.g. by package runner. The dealer broadcasts the public key and VSS
+// commitments under runner.BroadcastKey and sends the shares under runner.PeerKey. The other parties check their
+// share, then every party broadcasts a digest of what it received, so a dealer which sends different commitments
+// to different parties is caught. Parties other than the dealer wait until they receive its payloads
+type KeygenIterator struct {
+	config  *ShareConfiguration
+	id      byte
+	dealer  byte
+	version uint
+	result  *KeygenResult
+	steps   []func(*protocol.Message) (*protocol.Message, error)
+	step    int
+}
+
+var _ protocol.Iterator = &KeygenIterator{}
+
+// keygenDealPayload is the broadcast of the dealer
+type keygenDealPayload struct {
+	PublicKey   []byte
+	Commitments [][]byte
+}
+
+// keygenEchoPayload is the digest of the key a party received
+type keygenEchoPayload struct {
+	Digest []byte
+}
+
+// keygenResultPayload is the encoded KeygenResult
+type keygenResultPayload struct {
+	T, N        uint32
+	KeyShare    []byte
+	PublicKey   []byte
+	Commitments [][]byte
+}
+
+const keygenResultKey = "result"
+
+// NewKeygenIterator creates party `id` of the parties 1 to config.N of key generation, where party `dealer`
+// generates and splits the key
+func NewKeygenIterator(config *ShareConfiguration, id, dealer byte, version uint) (*KeygenIterator, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	if config == nil || config.T < 2 || config.N < config.T || config.N > 255 {
+		return nil, errors.New("invalid share configuration")
+	}
+	if id == 0 || int(id) > config.N || dealer == 0 || int(dealer) > config.N {
+		return nil, errors.New("invalid party")
+	}
+	k := &KeygenIterator{config: config, id: id, dealer: dealer, version: version}
+	if id == dealer {
+		k.steps = []func(*protocol.Message) (*protocol.Message, error){k.deal, k.checkEchoes}
+	} else {
+		k.steps = []func(*protocol.Message) (*protocol.Message, error){k.receive, k.checkEchoes}
+	}
+	return k, nil
+}
+
+// Next runs the next round of key generation
+func (k *KeygenIterator) Next(input *protocol.Message) (*protocol.Message, error) {
+	if k.step >= len(k.steps) {
+		return nil, protocol.ErrProtocolFinished
+	}
+	// Parties wait for the payloads of the dealer
+	if k.step == 0 && k.id != k.dealer && input == nil {
+		return nil, nil
+	}
+	output, err := k.steps[k.step](input)
+	if err != nil {
+		return nil, err
+	}
+	k.step++
+	return output, nil
+}
+
+// Result returns the key share, public key and commitments of the party once key generation has completed,
+// which DecodeKeygenResult reads
+func (k *KeygenIterator) Result(version uint) (*protocol.Message, error) {
+	if k.step < len(k.steps) {
+		return nil, nil
+	}
+	if k.result == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	payload := &keygenResultPayload{
+		T:           uint32(k.config.T),
+		N:           uint32(k.config.N),
+		KeyShare:    k.result.KeyShare.Bytes(),
+		PublicKey:   k.result.PublicKey,
+		Commitments: k.result.Commitments.CommitmentsToBytes(),
+	}
+	m := newMessage(protocol.Ted25519Keygen, "result", version)
+	if err := m.EncodePayload(keygenResultKey, payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+// DecodeKeygenResult returns the output of a party in the result of a KeygenIterator
+func DecodeKeygenResult(m *protocol.Message) (*KeygenResult, error) {
+	if m == nil {
+		return nil, errors.New("invalid result")
+	}
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	var payload keygenResultPayload
+	if err := m.DecodePayload(keygenResultKey, &payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(payload.KeyShare) < 4 {
+		return nil, errors.New("invalid key share")
+	}
+	result := &KeygenResult{
+		Config:    &ShareConfiguration{T: int(payload.T), N: int(payload.N)},
+		KeyShare:  KeyShareFromBytes(payload.KeyShare),
+		PublicKey: payload.PublicKey,
+	}
+	if err := result.check(payload.Commitments); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// check sets the commitments of the result and checks that they match the key share and public key
+func (r *KeygenResult) check(commitments [][]byte) error {
+	var err error
+	if len(commitments) != r.Config.T {
+		return errors.New("invalid commitments")
+	}
+	if r.Commitments, err = CommitmentsFromBytes(commitments); err != nil {
+		return err
+	}
+	if !bytes.Equal(r.Commitments[0].ToAffineCompressed(), r.PublicKey) {
+		return errors.New("commitments do not match the public key")
+	}
+	ok, err := r.KeyShare.VerifyVSS(r.Commitments, r.Config)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("key share does not match the commitments")
+	}
+	return nil
+}
+
+func (k *KeygenIterator) deal(*protocol.Message) (*protocol.Message, error) {
+	pub, shares, commitments, err := GenerateSharedKey(k.config)
+	if err != nil {
+		return nil, err
+	}
+	k.result = &KeygenResult{Config: k.config, KeyShare: shares[k.id-1], PublicKey: pub, Commitments: commitments}
+	m := newMessage(protocol.Ted25519Keygen, "1", k.version)
+	payload := &keygenDealPayload{PublicKey: pub, Commitments: commitments.CommitmentsToBytes()}
+	if err = m.EncodePayload(runner.BroadcastKey, payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, share := range shares {
+		if byte(share.Identifier) == k.id {
+			continue
+		}
+		if err = m.EncodePayload(runner.PeerKey(share.Identifier), share.Bytes()); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return m, nil
+}
+
+func (k *KeygenIterator) receive(input *protocol.Message) (*protocol.Message, error) {
+	if input.Version != k.version {
+		return nil, errors.New("only version 1 is supported")
+	}
+	var payload keygenDealPayload
+	if err := input.DecodePayload(runner.BroadcastFromKey(uint32(k.dealer)), &payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var share []byte
+	if err := input.DecodePayload(runner.PeerKey(uint32(k.dealer)), &share); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(share) < 4 {
+		return nil, errors.New("invalid key share from the dealer")
+	}
+	result := &KeygenResult{Config: k.config, KeyShare: KeyShareFromBytes(share), PublicKey: payload.PublicKey}
+	if byte(result.KeyShare.Identifier) != k.id || len(result.PublicKey) != PublicKeySize {
+		return nil, errors.New("invalid key share from the dealer")
+	}
+	if err := result.check(payload.Commitments); err != nil {
+		return nil, errors.Wrap(err, "invalid key from the dealer")
+	}
+	k.result = result
+	m := newMessage(protocol.Ted25519Keygen, "2", k.version)
+	if err := m.EncodePayload(runner.BroadcastKey, &keygenEchoPayload{Digest: k.digest()}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+// checkEchoes checks that the parties other than the dealer received the same key
+func (k *KeygenIterator) checkEchoes(input *protocol.Message) (*protocol.Message, error) {
+	digest := k.digest()
+	for id := 1; id <= k.config.N; id++ {
+		if byte(id) == k.id || byte(id) == k.dealer {
+			continue
+		}
+		if input == nil {
+			return nil, fmt.Errorf("missing digest of party %d", id)
+		}
+		if input.Version != k.version {
+			return nil, errors.New("only version 1 is supported")
+		}
+		var payload keygenEchoPayload
+		if err := input.DecodePayload(runner.BroadcastFromKey(uint32(id)), &payload); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !bytes.Equal(payload.Digest, digest) {
+			return nil, fmt.Errorf("party %d received another key from the dealer", id)
+		}
+	}
+	return nil, nil
+}
+
+// digest hashes the public key and commitments received from the dealer
+func (k *KeygenIterator) digest() []byte {
+	h := sha512.New()
+	_, _ = h.Write(k.result.PublicKey)
+	for _, c := range k.result.Commitments {
+		_, _ = h.Write(c.ToAffineCompressed())
+	}
+	return h.Sum(nil)
+}
+
+// SignIterator runs signing with committed nonces as a protocol.Iterator. Every signer contributes nonces and
+// a partial signature: it broadcasts its nonce commitment, then its nonce reveal while sending the nonce shares
+// under runner.PeerKey, then its partial signature. Payloads of the other signers are expected under
+// runner.BroadcastFromKey and runner.PeerKey of their sender. The partial signatures are verified with
+// AggregateVerified and signing fails, naming the signers, if any of them is invalid
+type SignIterator struct {
+	key         *KeygenResult
+	signers     []byte
+	msg         Message
+	version     uint
+	nonce       *CommittedNonce
+	commitments []*NonceCommitment
+	reveals     []*NonceReveal
+	ownShares   *NonceSharePair
+	partial     *PartialSignature
+	signature   Signature
+	steps       []func(*protocol.Message) (*protocol.Message, error)
+	step        int
+}
+
+var _ protocol.Iterator = &SignIterator{}
+
+// signCommitPayload is the encoded NonceCommitment
+type signCommitPayload struct {
+	Digest []byte
+}
+
+// signRevealPayload is the encoded NonceReveal
+type signRevealPayload struct {
+	D, E                       []byte
+	DCommitments, ECommitments [][]byte
+}
+
+// signNonceSharesPayload is the encoded NonceSharePair
+type signNonceSharesPayload struct {
+	D, E []byte
+}
+
+// signPartialPayload is the encoded PartialSignature
+type signPartialPayload struct {
+	Sig []byte
+}
+
+// signResultPayload is the encoded signature
+type signResultPayload struct {
+	Signature []byte
+}
+
+const signatureKey = "signature"
+
+// NewSignIterator creates the signer of `msg` with the party in `keygenResult`, the result of a KeygenIterator,
+// and the `signers`, which include this party and at least a threshold of the parties
+func NewSignIterator(keygenResult *protocol.Message, signers []byte, msg Message, version uint) (*SignIterator, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	key, err := DecodeKeygenResult(keygenResult)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sorted := append([]byte{}, signers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	own := false
+	for i, id := range sorted {
+		if id == 0 || int(id) > key.Config.N || (i > 0 && id == sorted[i-1]) {
+			return nil, fmt.Errorf("invalid signer %d", id)
+		}
+		own = own || id == byte(key.KeyShare.Identifier)
+	}
+	if !own || len(sorted) < key.Config.T {
+		return nil, errors.New("signers must include this party and at least a threshold of parties")
+	}
+	s := &SignIterator{key: key, signers: sorted, msg: append(Message{}, msg...), version: version}
+	s.steps = []func(*protocol.Message) (*protocol.Message, error){s.commit, s.reveal, s.sign, s.aggregate}
+	return s, nil
+}
+
+// Next runs the next round of signing
+func (s *SignIterator) Next(input *protocol.Message) (*protocol.Message, error) {
+	if s.step >= len(s.steps) {
+		return nil, protocol.ErrProtocolFinished
+	}
+	if s.step > 0 && input == nil {
+		return nil, errors.New("missing payloads of the signers")
+	}
+	if input != nil && input.Version != s.version {
+		return nil, errors.New("only version 1 is supported")
+	}
+	output, err := s.steps[s.step](input)
+	if err != nil {
+		return nil, err
+	}
+	s.step++
+	return output, nil
+}
+
+// Result returns the signature once signing has completed, which DecodeSignature reads
+func (s *SignIterator) Result(version uint) (*protocol.Message, error) {
+	if s.step < len(s.steps) {
+		return nil, nil
+	}
+	if s.signature == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	m := newMessage(protocol.Ted25519Sign, "result", version)
+	if err := m.EncodePayload(signatureKey, &signResultPayload{Signature: s.signature}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+// DecodeSignature returns the signature in the result of a SignIterator
+func DecodeSignature(m *protocol.Message) (Signature, error) {
+	if m == nil {
+		return nil, errors.New("invalid result")
+	}
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	var result signResultPayload
+	if err := m.DecodePayload(signatureKey, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(result.Signature) != SignatureSize {
+		return nil, errors.New("invalid signature")
+	}
+	return result.Signature, nil
+}
+
+func (s *SignIterator) id() byte {
+	return byte(s.key.KeyShare.Identifier)
+}
+
+// others returns the signers other than this party
+func (s *SignIterator) others() []byte {
+	others := make([]byte, 0, len(s.signers)-1)
+	for _, id := range s.signers {
+		if id != s.id() {
+			others = append(others, id)
+		}
+	}
+	return others
+}
+
+func (s *SignIterator) commit(*protocol.Message) (*protocol.Message, error) {
+	nonce, commitment, err := CommitSharedNonce(s.key.Config, s.key.KeyShare, s.key.PublicKey, s.msg)
+	if err != nil {
+		return nil, err
+	}
+	s.nonce = nonce
+	s.commitments = []*NonceCommitment{commitment}
+	return s.broadcast("1", &signCommitPayload{Digest: commitment.Digest})
+}
+
+func (s *SignIterator) reveal(input *protocol.Message) (*protocol.Message, error) {
+	for _, id := range s.others() {
+		var payload signCommitPayload
+		if err := input.DecodePayload(runner.BroadcastFromKey(uint32(id)), &payload); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		s.commitments = append(s.commitments, &NonceCommitment{ShareIdentifier: id, Digest: payload.Digest})
+	}
+	reveal, shares, err := s.nonce.Reveal(s.commitments)
+	if err != nil {
+		return nil, err
+	}
+	s.reveals = []*NonceReveal{reveal}
+	s.ownShares = shares[s.id()-1]
+
+	m := newMessage(protocol.Ted25519Sign, "2", s.version)
+	payload := &signRevealPayload{
+		D:            reveal.D,
+		E:            reveal.E,
+		DCommitments: reveal.DCommitments.CommitmentsToBytes(),
+		ECommitments: reveal.ECommitments.CommitmentsToBytes(),
+	}
+	if err = m.EncodePayload(runner.BroadcastKey, payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, id := range s.others() {
+		pair := shares[id-1]
+		if err = m.EncodePayload(runner.PeerKey(uint32(id)), &signNonceSharesPayload{
+			D: pair.D.Bytes(),
+			E: pair.E.Bytes(),
+		}); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return m, nil
+}
+
+func (s *SignIterator) sign(input *protocol.Message) (*protocol.Message, error) {
+	received := map[byte]*NonceSharePair{s.id(): s.ownShares}
+	for _, id := range s.others() {
+		var payload signRevealPayload
+		if err := input.DecodePayload(runner.BroadcastFromKey(uint32(id)), &payload); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		dCommitments, err := CommitmentsFromBytes(payload.DCommitments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nonce commitments from signer %d", id)
+		}
+		eCommitments, err := CommitmentsFromBytes(payload.ECommitments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nonce commitments from signer %d", id)
+		}
+		s.reveals = append(s.reveals, &NonceReveal{
+			ShareIdentifier: id,
+			D:               payload.D,
+			E:               payload.E,
+			DCommitments:    dCommitments,
+			ECommitments:    eCommitments,
+		})
+
+		var shares signNonceSharesPayload
+		if err = input.DecodePayload(runner.PeerKey(uint32(id)), &shares); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(shares.D) < 4 || len(shares.E) < 4 {
+			return nil, fmt.Errorf("invalid nonce shares from signer %d", id)
+		}
+		received[id] = &NonceSharePair{D: NonceShareFromBytes(shares.D), E: NonceShareFromBytes(shares.E)}
+	}
+	nonceShare, noncePub, err := CombineNonces(s.key.Config, s.key.KeyShare, s.key.PublicKey, s.msg,
+		s.commitments, s.reveals, received)
+	if err != nil {
+		return nil, err
+	}
+	s.partial = TSign(s.msg, s.key.KeyShare, s.key.PublicKey, nonceShare, noncePub)
+	return s.broadcast("3", &signPartialPayload{Sig: s.partial.Sig})
+}
+
+func (s *SignIterator) aggregate(input *protocol.Message) (*protocol.Message, error) {
+	partials := []*PartialSignature{s.partial}
+	for _, id := range s.others() {
+		var payload signPartialPayload
+		if err := input.DecodePayload(runner.BroadcastFromKey(uint32(id)), &payload); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(payload.Sig) != signatureLength {
+			return nil, fmt.Errorf("invalid partial signature from signer %d", id)
+		}
+		partials = append(partials, NewPartialSignature(id, payload.Sig))
+	}
+	sig, invalid, err := AggregateVerified(partials, s.key.Config, s.key.PublicKey, s.msg, s.key.Commitments, s.reveals)
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid partial signatures from signers %v", invalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.signature = sig
+	return nil, nil
+}
+
+func (s *SignIterator) broadcast(round string, payload interface{}) (*protocol.Message, error) {
+	m := newMessage(protocol.Ted25519Sign, round, s.version)
+	if err := m.EncodePayload(runner.BroadcastKey, payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m, nil
+}
+
+func newMessage(name, round string, version uint) *protocol.Message {
+	return &protocol.Message{
+		Protocol: name,
+		Version:  version,
+		Payloads: make(map[string][]byte),
+		Metadata: map[string]string{"round": round},
+	}
+}
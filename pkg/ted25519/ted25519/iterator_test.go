@@ -0,0 +1,149 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/protocol"
+	"github.com/etclab/kryptology/pkg/core/protocol/runner"
+)
+
+// runKeygenIterators runs key generation among all parties of `config` with party 1 as the dealer
+func runKeygenIterators(t *testing.T, config *ShareConfiguration) map[uint32]*protocol.Message {
+	parties := make(map[uint32]protocol.Iterator)
+	for id := 1; id <= config.N; id++ {
+		k, err := NewKeygenIterator(config, byte(id), 1, protocol.Version1)
+		require.NoError(t, err)
+		parties[uint32(id)] = k
+	}
+	r, err := runner.NewRunner(parties, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Run())
+	results, err := r.Results(protocol.Version1)
+	require.NoError(t, err)
+	return results
+}
+
+func TestIteratorKeygenAndSign(t *testing.T) {
+	for _, config := range []*ShareConfiguration{{T: 2, N: 2}, {T: 2, N: 3}, {T: 3, N: 5}} {
+		keygenResults := runKeygenIterators(t, config)
+		var pub PublicKey
+		for _, m := range keygenResults {
+			result, err := DecodeKeygenResult(m)
+			require.NoError(t, err)
+			if pub != nil {
+				require.Equal(t, pub, result.PublicKey)
+			}
+			pub = result.PublicKey
+		}
+
+		signers := make([]byte, config.T)
+		for i := range signers {
+			signers[i] = byte(config.N - i)
+		}
+		message := Message("test message")
+		parties := make(map[uint32]protocol.Iterator)
+		for _, id := range signers {
+			s, err := NewSignIterator(keygenResults[uint32(id)], signers, message, protocol.Version1)
+			require.NoError(t, err)
+			parties[uint32(id)] = s
+		}
+		r, err := runner.NewRunner(parties, nil)
+		require.NoError(t, err)
+		require.NoError(t, r.Run())
+		results, err := r.Results(protocol.Version1)
+		require.NoError(t, err)
+		for _, m := range results {
+			sig, err := DecodeSignature(m)
+			require.NoError(t, err)
+			assertSignatureVerifies(t, pub, message, sig)
+		}
+	}
+}
+
+func TestKeygenIteratorRejectsEquivocation(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	dealer, err := NewKeygenIterator(config, 1, 1, protocol.Version1)
+	require.NoError(t, err)
+	out, err := dealer.Next(nil)
+	require.NoError(t, err)
+
+	// Party 3 receives the share and commitments of another key
+	other, err := NewKeygenIterator(config, 1, 1, protocol.Version1)
+	require.NoError(t, err)
+	otherOut, err := other.Next(nil)
+	require.NoError(t, err)
+
+	receive := func(m *protocol.Message, id byte) *protocol.Message {
+		return &protocol.Message{
+			Protocol: m.Protocol,
+			Version:  m.Version,
+			Payloads: map[string][]byte{
+				runner.BroadcastFromKey(1): m.Payloads[runner.BroadcastKey],
+				runner.PeerKey(1):          m.Payloads[runner.PeerKey(uint32(id))],
+			},
+		}
+	}
+	party2, err := NewKeygenIterator(config, 2, 1, protocol.Version1)
+	require.NoError(t, err)
+	_, err = party2.Next(receive(out, 2))
+	require.NoError(t, err)
+	party3, err := NewKeygenIterator(config, 3, 1, protocol.Version1)
+	require.NoError(t, err)
+	echo3, err := party3.Next(receive(otherOut, 3))
+	require.NoError(t, err)
+
+	_, err = party2.Next(&protocol.Message{
+		Protocol: echo3.Protocol,
+		Version:  echo3.Version,
+		Payloads: map[string][]byte{runner.BroadcastFromKey(3): echo3.Payloads[runner.BroadcastKey]},
+	})
+	require.Error(t, err)
+
+	// A share which does not match the commitments is rejected
+	mixed := receive(out, 2)
+	mixed.Payloads[runner.PeerKey(1)] = otherOut.Payloads[runner.PeerKey(2)]
+	party2, err = NewKeygenIterator(config, 2, 1, protocol.Version1)
+	require.NoError(t, err)
+	_, err = party2.Next(mixed)
+	require.Error(t, err)
+}
+
+func TestIteratorBadInput(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	_, err := NewKeygenIterator(config, 1, 1, 2)
+	require.Error(t, err)
+	_, err = NewKeygenIterator(config, 4, 1, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewKeygenIterator(config, 1, 0, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewKeygenIterator(&ShareConfiguration{T: 1, N: 3}, 1, 1, protocol.Version1)
+	require.Error(t, err)
+
+	keygenResults := runKeygenIterators(t, config)
+	message := Message("test message")
+	_, err = NewSignIterator(nil, []byte{1, 2}, message, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewSignIterator(keygenResults[1], []byte{1}, message, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewSignIterator(keygenResults[1], []byte{2, 3}, message, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewSignIterator(keygenResults[1], []byte{1, 1}, message, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewSignIterator(keygenResults[1], []byte{1, 4}, message, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewSignIterator(keygenResults[1], []byte{1, 2}, message, 2)
+	require.Error(t, err)
+
+	_, err = DecodeKeygenResult(&protocol.Message{Protocol: protocol.Ted25519Keygen, Version: protocol.Version1})
+	require.Error(t, err)
+	_, err = DecodeSignature(&protocol.Message{Protocol: protocol.Ted25519Sign, Version: protocol.Version1})
+	require.Error(t, err)
+}
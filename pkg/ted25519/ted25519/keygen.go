@@ -199,11 +199,50 @@ func (share *KeyShare) VerifyVSS(commitments Commitments, config *ShareConfigura
 	if len(commitments) < config.T {
 		return false, fmt.Errorf("not enough verifiers to check")
 	}
+	rhs, err := commitments.evaluate(share.Identifier)
+	if err != nil {
+		return false, err
+	}
+
+	vValue := reverseBytes(share.Value.Bytes())
+	var vInput [32]byte
+	copy(vInput[:], vValue)
+	vScalar, err := new(curves.ScalarEd25519).SetBytes(vInput[:])
+	if err != nil {
+		return false, err
+	}
+	lhs := curves.ED25519().ScalarBaseMult(vScalar)
+
+	// Check if lhs == rhs
+	return lhs.Equal(rhs), nil
+}
+
+// VerificationShare returns the public key share of the party `identifier`, the public value at its identifier
+// of the polynomial committed to by the VSS commitments of the key. Partial signatures of the party verify
+// under it
+func VerificationShare(commitments Commitments, identifier byte) (PublicKey, error) {
+	if len(commitments) == 0 || identifier == 0 {
+		return nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	for _, c := range commitments {
+		if c == nil {
+			return nil, fmt.Errorf("ted25519: invalid commitments")
+		}
+	}
+	share, err := commitments.evaluate(uint32(identifier))
+	if err != nil {
+		return nil, err
+	}
+	return share.ToAffineCompressed(), nil
+}
+
+// evaluate returns the public value of the committed polynomial at x, c_0 * c_1^x * c_2^{x^2} * ...
+func (commitments Commitments) evaluate(identifier uint32) (curves.Point, error) {
 	field := curves.NewField(curves.Ed25519Order())
 	xBytes := make([]byte, 4)
-	binary.BigEndian.PutUint32(xBytes, share.Identifier)
+	binary.BigEndian.PutUint32(xBytes, identifier)
 	x := field.ElementFromBytes(xBytes)
-	i := share.Value.Modulus.One()
+	i := field.One()
 
 	// c_0
 	rhs := commitments[0]
@@ -214,27 +253,15 @@ func (share *KeyShare) VerifyVSS(commitments Commitments, config *ShareConfigura
 		// i *= x
 		i = i.Mul(x)
 
-		var iBytes [32]byte
-		copy(iBytes[:], i.Bytes()[:])
-		iScalar, err := new(curves.ScalarEd25519).SetBytesCanonical(iBytes[:])
+		// The field element is big-endian and the scalar little-endian
+		iScalar, err := elementToScalar(i)
 		if err != nil {
-			return false, fmt.Errorf("Error in SetBytesCanonical iBytes")
+			return nil, fmt.Errorf("Error in SetBytesCanonical iBytes")
 		}
 		c := commitments[j].Mul(iScalar)
 
 		// ...* c_j^{i^j}
 		rhs = rhs.Add(c)
 	}
-
-	vValue := reverseBytes(share.Value.Bytes())
-	var vInput [32]byte
-	copy(vInput[:], vValue)
-	vScalar, err := new(curves.ScalarEd25519).SetBytes(vInput[:])
-	if err != nil {
-		return false, err
-	}
-	lhs := curves.ED25519().ScalarBaseMult(vScalar)
-
-	// Check if lhs == rhs
-	return lhs.Equal(rhs), nil
+	return rhs, nil
 }
@@ -94,6 +94,18 @@ func TestVerifyVSSEd25519(t *testing.T) {
 	}
 }
 
+func TestVerifyVSSLargeIdentifiers(t *testing.T) {
+	// Powers of the identifiers which do not fit in a byte
+	config := ShareConfiguration{T: 3, N: 20}
+	_, shares, commitments, err := GenerateSharedKey(&config)
+	require.NoError(t, err)
+	for _, s := range shares {
+		ok, err := s.VerifyVSS(commitments, &config)
+		require.NoError(t, err)
+		require.True(t, ok, s.Identifier)
+	}
+}
+
 func TestCommitmentsFromBytes(t *testing.T) {
 	config := ShareConfiguration{T: 2, N: 3}
 	_, _, comms, err := GenerateSharedKey(&config)
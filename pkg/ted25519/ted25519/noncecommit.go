@@ -0,0 +1,319 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing/v1"
+)
+
+// Committed nonce generation replaces GenerateSharedNonce with two rounds. Each party shares two nonces d and e
+// and first broadcasts a hash commitment to them, then, once it holds the commitments of all parties, reveals
+// them and sends the shares. No party sees the nonces of the others before its own are fixed, so none can
+// choose its nonces to bias the nonce of the signature. As in FROST, the nonce of the signature is the sum of
+// D_i + rho_i*E_i over the parties, where the binding factor rho_i is a hash of the message, the public key and
+// the nonces of all parties, so nonces revealed for one signing session cannot be replayed in another one.
+
+// NonceCommitment is the commitment to the nonces of a party, broadcast in the first round
+type NonceCommitment struct {
+	ShareIdentifier byte
+	Digest          []byte
+}
+
+// NonceReveal opens a NonceCommitment in the second round
+type NonceReveal struct {
+	ShareIdentifier byte
+	D, E            PublicKey   // the public nonces
+	DCommitments    Commitments // VSS commitments of the sharing of d
+	ECommitments    Commitments // VSS commitments of the sharing of e
+}
+
+// NonceSharePair holds the shares of d and e which a party sends privately to another party in the second round
+type NonceSharePair struct {
+	D, E *NonceShare
+}
+
+// CommittedNonce holds the nonces of a party between the two rounds
+type CommittedNonce struct {
+	commitment *NonceCommitment
+	reveal     *NonceReveal
+	shares     []*NonceSharePair
+}
+
+// CommitSharedNonce generates and splits the nonces of party `s` for signing `m` under `p`. It returns them
+// together with the commitment to broadcast in the first round
+func CommitSharedNonce(config *ShareConfiguration, s *KeyShare, p PublicKey, m Message) (*CommittedNonce, *NonceCommitment, error) {
+	if config == nil || s == nil || s.ShamirShare == nil {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	dPub, dShares, dCommitments, err := generateSharedNonce(config, s, p, m)
+	if err != nil {
+		return nil, nil, err
+	}
+	ePub, eShares, eCommitments, err := generateSharedNonce(config, s, p, m)
+	if err != nil {
+		return nil, nil, err
+	}
+	reveal := &NonceReveal{
+		ShareIdentifier: byte(s.Identifier),
+		D:               dPub,
+		E:               ePub,
+		DCommitments:    dCommitments,
+		ECommitments:    eCommitments,
+	}
+	shares := make([]*NonceSharePair, len(dShares))
+	for i := range dShares {
+		shares[i] = &NonceSharePair{D: dShares[i], E: eShares[i]}
+	}
+	commitment := &NonceCommitment{
+		ShareIdentifier: reveal.ShareIdentifier,
+		Digest:          nonceCommitmentDigest(reveal, p, m),
+	}
+	return &CommittedNonce{commitment: commitment, reveal: reveal, shares: shares}, commitment, nil
+}
+
+// Reveal returns the opening to broadcast and the nonce shares to send in the second round, where the shares
+// of the party with identifier i are at index i-1. It must only be called once the commitments of all parties
+// are received, which must include the commitment of this party. The nonces are revealed only once
+func (c *CommittedNonce) Reveal(commitments []*NonceCommitment) (*NonceReveal, []*NonceSharePair, error) {
+	if c == nil || c.reveal == nil {
+		return nil, nil, fmt.Errorf("ted25519: nonces are already revealed")
+	}
+	own := false
+	for _, commitment := range commitments {
+		if commitment != nil && commitment.ShareIdentifier == c.commitment.ShareIdentifier {
+			own = bytes.Equal(commitment.Digest, c.commitment.Digest)
+		}
+	}
+	if !own {
+		return nil, nil, fmt.Errorf("ted25519: commitments do not include the nonce commitment of party %d",
+			c.commitment.ShareIdentifier)
+	}
+	reveal, shares := c.reveal, c.shares
+	c.reveal, c.shares = nil, nil
+	return reveal, shares, nil
+}
+
+// CombineNonces checks that the openings of the nonces match the commitments of the first round, and that the
+// nonce shares received by party `s`, indexed by the identifier of their sender, match the VSS commitments of the
+// openings. It returns the nonce share and the public nonce to sign `m` under `p` with TSign
+func CombineNonces(
+	config *ShareConfiguration,
+	s *KeyShare, p PublicKey, m Message,
+	commitments []*NonceCommitment,
+	reveals []*NonceReveal,
+	shares map[byte]*NonceSharePair,
+) (*NonceShare, PublicKey, error) {
+	if config == nil || s == nil || s.ShamirShare == nil || len(commitments) == 0 {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	if len(reveals) != len(commitments) || len(shares) != len(commitments) {
+		return nil, nil, fmt.Errorf("ted25519: expected nonces of %d parties", len(commitments))
+	}
+	digests := make(map[byte][]byte, len(commitments))
+	for _, commitment := range commitments {
+		if commitment == nil {
+			return nil, nil, fmt.Errorf("ted25519: invalid nonce commitment")
+		}
+		if _, ok := digests[commitment.ShareIdentifier]; ok {
+			return nil, nil, fmt.Errorf("ted25519: duplicate nonce commitment of party %d", commitment.ShareIdentifier)
+		}
+		digests[commitment.ShareIdentifier] = commitment.Digest
+	}
+	if _, ok := digests[byte(s.Identifier)]; !ok {
+		return nil, nil, fmt.Errorf("ted25519: missing nonce commitment of party %d", s.Identifier)
+	}
+
+	for _, reveal := range reveals {
+		if err := checkNonceReveal(config, reveal, digests, p, m); err != nil {
+			return nil, nil, err
+		}
+	}
+	sorted, err := sortReveals(reveals)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	field := curves.NewField(curves.Ed25519Order())
+	value := field.Zero()
+	noncePub := curves.ED25519().NewIdentityPoint()
+	for _, reveal := range sorted {
+		pair, ok := shares[reveal.ShareIdentifier]
+		if !ok || pair == nil || pair.D == nil || pair.E == nil {
+			return nil, nil, fmt.Errorf("ted25519: missing nonce shares of party %d", reveal.ShareIdentifier)
+		}
+		if pair.D.Identifier != s.Identifier || pair.E.Identifier != s.Identifier {
+			return nil, nil, fmt.Errorf("ted25519: nonce shares of party %d are not for party %d",
+				reveal.ShareIdentifier, s.Identifier)
+		}
+		okD, err := pair.D.VerifyVSS(reveal.DCommitments, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		okE, err := pair.E.VerifyVSS(reveal.ECommitments, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !okD || !okE {
+			return nil, nil, fmt.Errorf("ted25519: invalid nonce shares of party %d", reveal.ShareIdentifier)
+		}
+
+		rho := bindingFactor(field, reveal.ShareIdentifier, sorted, p, m)
+		rhoScalar, err := elementToScalar(rho)
+		if err != nil {
+			return nil, nil, err
+		}
+		// d + rho*e and D + rho*E
+		value = value.Add(pair.D.Value).Add(rho.Mul(pair.E.Value))
+		noncePub = noncePub.Add(reveal.DCommitments[0]).Add(reveal.ECommitments[0].Mul(rhoScalar))
+	}
+	if noncePub.IsIdentity() || value.BigInt().Sign() == 0 {
+		return nil, nil, fmt.Errorf("ted25519: invalid nonce")
+	}
+	share := &NonceShare{&KeyShare{&v1.ShamirShare{Identifier: s.Identifier, Value: value}}}
+	return share, noncePub.ToAffineCompressed(), nil
+}
+
+// NoncePublicShare returns the public nonce share of the party `identifier` for signing `m` under `p`, which
+// is to the public nonce what the nonce share returned by CombineNonces is to the nonce. It is computed from
+// the openings of all parties, which CombineNonces accepted
+func NoncePublicShare(reveals []*NonceReveal, p PublicKey, m Message, identifier byte) (PublicKey, error) {
+	if identifier == 0 {
+		return nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	share, err := evaluateNonces(reveals, p, m, uint32(identifier))
+	if err != nil {
+		return nil, err
+	}
+	return share.ToAffineCompressed(), nil
+}
+
+// evaluateNonces returns the public value at x of the polynomial sharing the nonce, the public nonce at 0
+func evaluateNonces(reveals []*NonceReveal, p PublicKey, m Message, x uint32) (curves.Point, error) {
+	if len(reveals) == 0 {
+		return nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	for _, reveal := range reveals {
+		if reveal == nil || len(reveal.DCommitments) == 0 || len(reveal.DCommitments) != len(reveal.ECommitments) {
+			return nil, fmt.Errorf("ted25519: invalid nonce reveal")
+		}
+		for i := range reveal.DCommitments {
+			if reveal.DCommitments[i] == nil || reveal.ECommitments[i] == nil {
+				return nil, fmt.Errorf("ted25519: invalid nonce commitments of party %d", reveal.ShareIdentifier)
+			}
+		}
+	}
+	sorted, err := sortReveals(reveals)
+	if err != nil {
+		return nil, err
+	}
+	field := curves.NewField(curves.Ed25519Order())
+	share := curves.ED25519().NewIdentityPoint()
+	for _, reveal := range sorted {
+		rhoScalar, err := elementToScalar(bindingFactor(field, reveal.ShareIdentifier, sorted, p, m))
+		if err != nil {
+			return nil, err
+		}
+		d, err := reveal.DCommitments.evaluate(x)
+		if err != nil {
+			return nil, err
+		}
+		e, err := reveal.ECommitments.evaluate(x)
+		if err != nil {
+			return nil, err
+		}
+		share = share.Add(d).Add(e.Mul(rhoScalar))
+	}
+	return share, nil
+}
+
+// sortReveals returns the openings in the order of the identifiers of their parties, in which the binding
+// factors hash them
+func sortReveals(reveals []*NonceReveal) ([]*NonceReveal, error) {
+	sorted := append([]*NonceReveal{}, reveals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ShareIdentifier < sorted[j].ShareIdentifier })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].ShareIdentifier == sorted[i-1].ShareIdentifier {
+			return nil, fmt.Errorf("ted25519: duplicate nonces of party %d", sorted[i].ShareIdentifier)
+		}
+	}
+	return sorted, nil
+}
+
+// checkNonceReveal checks that an opening is well formed and matches the commitment of its party
+func checkNonceReveal(config *ShareConfiguration, reveal *NonceReveal, digests map[byte][]byte, p PublicKey, m Message) error {
+	if reveal == nil {
+		return fmt.Errorf("ted25519: invalid nonce reveal")
+	}
+	digest, ok := digests[reveal.ShareIdentifier]
+	if !ok {
+		return fmt.Errorf("ted25519: missing nonce commitment of party %d", reveal.ShareIdentifier)
+	}
+	if len(reveal.DCommitments) != config.T || len(reveal.ECommitments) != config.T {
+		return fmt.Errorf("ted25519: invalid nonce commitments of party %d", reveal.ShareIdentifier)
+	}
+	for i := range reveal.DCommitments {
+		if reveal.DCommitments[i] == nil || reveal.ECommitments[i] == nil {
+			return fmt.Errorf("ted25519: invalid nonce commitments of party %d", reveal.ShareIdentifier)
+		}
+	}
+	if !bytes.Equal(reveal.DCommitments[0].ToAffineCompressed(), reveal.D) ||
+		!bytes.Equal(reveal.ECommitments[0].ToAffineCompressed(), reveal.E) {
+		return fmt.Errorf("ted25519: nonces of party %d do not match their commitments", reveal.ShareIdentifier)
+	}
+	if subtle.ConstantTimeCompare(nonceCommitmentDigest(reveal, p, m), digest) != 1 {
+		return fmt.Errorf("ted25519: nonces of party %d do not open its commitment", reveal.ShareIdentifier)
+	}
+	return nil
+}
+
+// nonceCommitmentDigest hashes the opening of a nonce commitment together with the key and message it signs
+func nonceCommitmentDigest(reveal *NonceReveal, p PublicKey, m Message) []byte {
+	h := sha512.New()
+	_, _ = h.Write([]byte("ted25519 nonce commitment"))
+	_, _ = h.Write([]byte{reveal.ShareIdentifier})
+	_, _ = h.Write(p)
+	_, _ = h.Write(reveal.D)
+	_, _ = h.Write(reveal.E)
+	for _, c := range reveal.DCommitments {
+		_, _ = h.Write(c.ToAffineCompressed())
+	}
+	for _, c := range reveal.ECommitments {
+		_, _ = h.Write(c.ToAffineCompressed())
+	}
+	_, _ = h.Write(m)
+	return h.Sum(nil)
+}
+
+// bindingFactor returns the binding factor of party `id` given the nonces of all parties, sorted by identifier
+func bindingFactor(field *curves.Field, id byte, reveals []*NonceReveal, p PublicKey, m Message) *curves.Element {
+	h := sha512.New()
+	_, _ = h.Write([]byte("ted25519 binding factor"))
+	_, _ = h.Write([]byte{id})
+	_, _ = h.Write(p)
+	for _, reveal := range reveals {
+		_, _ = h.Write([]byte{reveal.ShareIdentifier})
+		_, _ = h.Write(reveal.D)
+		_, _ = h.Write(reveal.E)
+	}
+	_, _ = h.Write(m)
+	// The 512 bit digest makes the bias of the reduction negligible
+	return field.ReducedElementFromBytes(h.Sum(nil))
+}
+
+// elementToScalar converts a big-endian field element to a scalar
+func elementToScalar(e *curves.Element) (curves.Scalar, error) {
+	var le [32]byte
+	copy(le[:], reverseBytes(e.Bytes()))
+	return new(curves.ScalarEd25519).SetBytesCanonical(le[:])
+}
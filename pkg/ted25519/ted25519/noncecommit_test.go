@@ -0,0 +1,123 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// committedNonces runs the two rounds of committed nonce generation among all parties and returns the
+// commitments, openings and the nonce shares received by each party, indexed by sender
+func committedNonces(t *testing.T, config *ShareConfiguration, keyShares []*KeyShare, pub PublicKey, message Message) (
+	[]*NonceCommitment, []*NonceReveal, map[byte]map[byte]*NonceSharePair,
+) {
+	nonces := make([]*CommittedNonce, len(keyShares))
+	commitments := make([]*NonceCommitment, len(keyShares))
+	for i, s := range keyShares {
+		var err error
+		nonces[i], commitments[i], err = CommitSharedNonce(config, s, pub, message)
+		require.NoError(t, err)
+	}
+	reveals := make([]*NonceReveal, len(keyShares))
+	received := make(map[byte]map[byte]*NonceSharePair, len(keyShares))
+	for i, n := range nonces {
+		reveal, shares, err := n.Reveal(commitments)
+		require.NoError(t, err)
+		reveals[i] = reveal
+		for j, pair := range shares {
+			to := byte(j + 1)
+			if received[to] == nil {
+				received[to] = make(map[byte]*NonceSharePair)
+			}
+			received[to][reveal.ShareIdentifier] = pair
+		}
+	}
+	return commitments, reveals, received
+}
+
+func TestCommittedNonceSigning(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	pub, keyShares, _, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	message := Message("test message")
+
+	commitments, reveals, received := committedNonces(t, config, keyShares, pub, message)
+	sigs := make([]*PartialSignature, len(keyShares))
+	var noncePub PublicKey
+	for i, s := range keyShares {
+		nonceShare, r, err := CombineNonces(config, s, pub, message, commitments, reveals, received[byte(s.Identifier)])
+		require.NoError(t, err)
+		if noncePub != nil {
+			require.Equal(t, noncePub, r)
+		}
+		noncePub = r
+		sigs[i] = TSign(message, s, pub, nonceShare, noncePub)
+	}
+
+	for _, pair := range [][]int{{0, 1}, {1, 2}, {0, 2}} {
+		sig, err := Aggregate([]*PartialSignature{sigs[pair[0]], sigs[pair[1]]}, config)
+		require.NoError(t, err)
+		assertSignatureVerifies(t, pub, message, sig)
+	}
+}
+
+func TestCommittedNonceRejectsTampering(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	pub, keyShares, _, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	message := Message("test message")
+	commitments, reveals, received := committedNonces(t, config, keyShares, pub, message)
+	s := keyShares[0]
+
+	// Nonces committed for one message cannot be used for another one
+	_, _, err = CombineNonces(config, s, pub, Message("other message"), commitments, reveals, received[1])
+	require.Error(t, err)
+
+	// A party cannot change its nonces after seeing those of the others
+	_, otherReveals, otherReceived := committedNonces(t, config, keyShares, pub, message)
+	tampered := append([]*NonceReveal{otherReveals[1]}, reveals[0], reveals[2])
+	shares := map[byte]*NonceSharePair{1: received[1][1], 2: otherReceived[1][2], 3: received[1][3]}
+	_, _, err = CombineNonces(config, s, pub, message, commitments, tampered, shares)
+	require.Error(t, err)
+
+	// Shares must match the VSS commitments of their sender
+	shares = map[byte]*NonceSharePair{1: received[1][1], 2: otherReceived[1][2], 3: received[1][3]}
+	_, _, err = CombineNonces(config, s, pub, message, commitments, reveals, shares)
+	require.Error(t, err)
+
+	// Shares must be those of the receiving party
+	shares = map[byte]*NonceSharePair{1: received[1][1], 2: received[2][2], 3: received[1][3]}
+	_, _, err = CombineNonces(config, s, pub, message, commitments, reveals, shares)
+	require.Error(t, err)
+
+	_, _, err = CombineNonces(config, s, pub, message, commitments, reveals[:2], received[1])
+	require.Error(t, err)
+	_, _, err = CombineNonces(config, s, pub, message, commitments[1:], reveals[1:],
+		map[byte]*NonceSharePair{2: received[1][2], 3: received[1][3]})
+	require.Error(t, err)
+}
+
+func TestCommittedNonceRevealOnce(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	pub, keyShares, _, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	message := Message("test message")
+	nonce, commitment, err := CommitSharedNonce(config, keyShares[0], pub, message)
+	require.NoError(t, err)
+
+	_, _, err = nonce.Reveal(nil)
+	require.Error(t, err)
+	_, _, err = nonce.Reveal([]*NonceCommitment{{ShareIdentifier: commitment.ShareIdentifier, Digest: make([]byte, 64)}})
+	require.Error(t, err)
+	_, shares, err := nonce.Reveal([]*NonceCommitment{commitment})
+	require.NoError(t, err)
+	require.Len(t, shares, config.N)
+	_, _, err = nonce.Reveal([]*NonceCommitment{commitment})
+	require.Error(t, err)
+}
@@ -67,11 +67,23 @@ func generateSharableNonce(s *KeyShare, p PublicKey, m Message) (PublicKey, []by
 
 // GenerateSharedNonce generates a random nonce, splits it, and returns the nonce pubkey, nonce shares, and
 // VSS commitments.
+//
+// Deprecated: the nonce of a signature combined from these nonces can be biased by the last party to publish its
+// nonce pubkey. Use CommitSharedNonce, Reveal and CombineNonces, which commit to the nonces first.
 func GenerateSharedNonce(config *ShareConfiguration, s *KeyShare, p PublicKey, m Message) (
 	PublicKey,
 	[]*NonceShare,
 	Commitments,
 	error,
+) {
+	return generateSharedNonce(config, s, p, m)
+}
+
+func generateSharedNonce(config *ShareConfiguration, s *KeyShare, p PublicKey, m Message) (
+	PublicKey,
+	[]*NonceShare,
+	Commitments,
+	error,
 ) {
 	noncePubkey, nonce, err := generateSharableNonce(s, p, m)
 	if err != nil {
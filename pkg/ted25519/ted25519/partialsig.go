@@ -6,7 +6,13 @@
 
 package ted25519
 
-import "strconv"
+import (
+	"crypto/sha512"
+	"fmt"
+	"strconv"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
 
 type Message []byte
 
@@ -53,3 +59,35 @@ func TSign(message Message, key *KeyShare, pub PublicKey, nonce *NonceShare, non
 	)
 	return NewPartialSignature(byte(key.ShamirShare.Identifier), sig)
 }
+
+// VerifyPartialSignature checks that a partial signature of `message` under `pub` is valid for the verification
+// share and public nonce share of its signer, i.e. that s_i*G = R_i + c*A_i where c is the challenge of the
+// signature. Only valid partial signatures combine to a valid signature
+func VerifyPartialSignature(sig *PartialSignature, pub PublicKey, message Message, verificationShare, nonceShare PublicKey) (bool, error) {
+	if sig == nil || len(sig.Sig) != signatureLength {
+		return false, fmt.Errorf("ted25519: invalid partial signature")
+	}
+	a, err := new(curves.PointEd25519).FromAffineCompressed(verificationShare)
+	if err != nil {
+		return false, fmt.Errorf("ted25519: invalid verification share")
+	}
+	r, err := new(curves.PointEd25519).FromAffineCompressed(nonceShare)
+	if err != nil {
+		return false, fmt.Errorf("ted25519: invalid nonce share")
+	}
+	s, err := new(curves.ScalarEd25519).SetBytesCanonical(sig.S())
+	if err != nil {
+		return false, nil
+	}
+
+	// c = H(R || A || m) mod q
+	h := sha512.New()
+	_, _ = h.Write(sig.R())
+	_, _ = h.Write(pub)
+	_, _ = h.Write(message)
+	c, err := new(curves.ScalarEd25519).SetBytesWide(h.Sum(nil))
+	if err != nil {
+		return false, err
+	}
+	return curves.ED25519().ScalarBaseMult(s).Equal(r.Add(a.Mul(c))), nil
+}
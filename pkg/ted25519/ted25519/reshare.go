@@ -0,0 +1,193 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/sharing/v1"
+)
+
+// GenerateRefresh generates the contribution of a party to a proactive refresh of the key shares, a sharing of
+// zero. It returns a share of zero for each party and the VSS commitments of the sharing, whose first commitment
+// is the identity. Every party sends the shares to the others and broadcasts the commitments
+func GenerateRefresh(config *ShareConfiguration) ([]*KeyShare, Commitments, error) {
+	if config == nil {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	return splitPrivateKey(config, []byte{0})
+}
+
+// RefreshShare adds the shares of zero received by the holder of key share `s`, indexed by the identifier of
+// their sender, to its share, after checking them against the commitments of their sender. It returns the
+// refreshed share and the refreshed VSS commitments of the key, which has the same public key. All parties
+// must apply the contributions of the same senders, and discard their old shares afterwards
+func RefreshShare(
+	config *ShareConfiguration,
+	s *KeyShare,
+	keyCommitments Commitments,
+	shares map[byte]*KeyShare,
+	commitments map[byte]Commitments,
+) (*KeyShare, Commitments, error) {
+	if config == nil || s == nil || s.ShamirShare == nil || len(keyCommitments) != config.T || len(shares) == 0 {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	if len(commitments) != len(shares) {
+		return nil, nil, fmt.Errorf("ted25519: expected refreshes of %d parties", len(shares))
+	}
+	value := s.Value
+	newCommitments := append(Commitments{}, keyCommitments...)
+	for _, id := range sortedIdentifiers(shares) {
+		share, dealerCommitments := shares[id], commitments[id]
+		if err := checkDealing(config, s.Identifier, share, dealerCommitments); err != nil {
+			return nil, nil, fmt.Errorf("ted25519: invalid refresh of party %d: %v", id, err)
+		}
+		if !dealerCommitments[0].IsIdentity() {
+			return nil, nil, fmt.Errorf("ted25519: refresh of party %d does not share zero", id)
+		}
+		value = value.Add(share.Value)
+		for k, c := range dealerCommitments {
+			newCommitments[k] = newCommitments[k].Add(c)
+		}
+	}
+	return &KeyShare{&v1.ShamirShare{Identifier: s.Identifier, Value: value}}, newCommitments, nil
+}
+
+// GenerateReshare splits key share `s` for the parties of `newConfig`, which may have another threshold and
+// number of parties. At least a threshold of the old parties reshare their key shares. The first VSS commitment
+// returned is the verification share of the party, which the new parties check
+func GenerateReshare(newConfig *ShareConfiguration, s *KeyShare) ([]*KeyShare, Commitments, error) {
+	if newConfig == nil || s == nil || s.ShamirShare == nil {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	return splitPrivateKey(newConfig, s.Value.Bytes())
+}
+
+// CombineReshare returns the key share of the new party `identifier` from the shares it received from the old
+// parties, indexed by their identifiers, and the new VSS commitments of the key, whose public key is unchanged.
+// The first commitment of each old party must be its verification share under the old commitments of the key.
+// All new parties must combine the reshares of the same old parties
+func CombineReshare(
+	oldConfig, newConfig *ShareConfiguration,
+	identifier byte,
+	oldCommitments Commitments,
+	shares map[byte]*KeyShare,
+	commitments map[byte]Commitments,
+) (*KeyShare, Commitments, error) {
+	if oldConfig == nil || newConfig == nil || identifier == 0 || int(identifier) > newConfig.N ||
+		len(oldCommitments) != oldConfig.T {
+		return nil, nil, fmt.Errorf("ted25519: invalid arguments")
+	}
+	if len(shares) < oldConfig.T || len(commitments) != len(shares) {
+		return nil, nil, fmt.Errorf("ted25519: expected reshares of at least %d parties", oldConfig.T)
+	}
+	dealers := sortedIdentifiers(shares)
+	lambdas, err := lagrangeCoefficients(dealers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	field := curves.NewField(curves.Ed25519Order())
+	value := field.Zero()
+	newCommitments := make(Commitments, newConfig.T)
+	for k := range newCommitments {
+		newCommitments[k] = curves.ED25519().NewIdentityPoint()
+	}
+	for _, id := range dealers {
+		share, dealerCommitments := shares[id], commitments[id]
+		if err := checkDealing(newConfig, uint32(identifier), share, dealerCommitments); err != nil {
+			return nil, nil, fmt.Errorf("ted25519: invalid reshare of party %d: %v", id, err)
+		}
+		if int(id) > oldConfig.N {
+			return nil, nil, fmt.Errorf("ted25519: invalid reshare of party %d", id)
+		}
+		verificationShare, err := oldCommitments.evaluate(uint32(id))
+		if err != nil {
+			return nil, nil, err
+		}
+		if !dealerCommitments[0].Equal(verificationShare) {
+			return nil, nil, fmt.Errorf("ted25519: reshare of party %d is not of its key share", id)
+		}
+
+		// The new share is sum lambda_i * share_i and the new commitments sum lambda_i * C_i
+		lambda := lambdas[id]
+		lambdaScalar, err := elementToScalar(lambda)
+		if err != nil {
+			return nil, nil, err
+		}
+		value = value.Add(lambda.Mul(share.Value))
+		for k, c := range dealerCommitments {
+			newCommitments[k] = newCommitments[k].Add(c.Mul(lambdaScalar))
+		}
+	}
+	if !newCommitments[0].Equal(oldCommitments[0]) {
+		return nil, nil, fmt.Errorf("ted25519: reshared key differs from the old key")
+	}
+	return &KeyShare{&v1.ShamirShare{Identifier: uint32(identifier), Value: value}}, newCommitments, nil
+}
+
+// checkDealing checks a share received by party `identifier` against the VSS commitments of its dealer
+func checkDealing(config *ShareConfiguration, identifier uint32, share *KeyShare, commitments Commitments) error {
+	if share == nil || share.ShamirShare == nil || share.Value == nil || share.Identifier != identifier {
+		return fmt.Errorf("invalid share")
+	}
+	if len(commitments) != config.T {
+		return fmt.Errorf("invalid commitments")
+	}
+	for _, c := range commitments {
+		if c == nil {
+			return fmt.Errorf("invalid commitments")
+		}
+	}
+	ok, err := share.VerifyVSS(commitments, config)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("share does not match the commitments")
+	}
+	return nil
+}
+
+// lagrangeCoefficients returns the Lagrange coefficients at zero of the identifiers
+func lagrangeCoefficients(ids []byte) (map[byte]*curves.Element, error) {
+	field := curves.NewField(curves.Ed25519Order())
+	xs := make(map[byte]*curves.Element, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			return nil, fmt.Errorf("ted25519: invalid identifier 0")
+		}
+		xBytes := make([]byte, 4)
+		binary.BigEndian.PutUint32(xBytes, uint32(id))
+		xs[id] = field.ElementFromBytes(xBytes)
+	}
+	lambdas := make(map[byte]*curves.Element, len(ids))
+	for _, i := range ids {
+		// x_j / (x_j - x_i) for j != i
+		basis := field.One()
+		for _, j := range ids {
+			if i == j {
+				continue
+			}
+			basis = basis.Mul(xs[j].Div(xs[j].Sub(xs[i])))
+		}
+		lambdas[i] = basis
+	}
+	return lambdas, nil
+}
+
+func sortedIdentifiers(shares map[byte]*KeyShare) []byte {
+	ids := make([]byte, 0, len(shares))
+	for id := range shares {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
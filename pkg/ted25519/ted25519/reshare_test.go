@@ -0,0 +1,151 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package ted25519
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// assertSharesSign checks that the parties of `keyShares` sign under `pub` with committed nonces
+func assertSharesSign(t *testing.T, config *ShareConfiguration, keyShares []*KeyShare, pub PublicKey, keyCommitments Commitments) {
+	message := Message("test message")
+	sigs, reveals := signCommitted(t, config, keyShares, pub, message)
+	sig, invalid, err := AggregateVerified(sigs, config, pub, message, keyCommitments, reveals)
+	require.NoError(t, err)
+	require.Empty(t, invalid)
+	assertSignatureVerifies(t, pub, message, sig)
+}
+
+func TestRefreshShares(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	pub, keyShares, keyCommitments, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+
+	refreshShares := make(map[byte][]*KeyShare)
+	refreshCommitments := make(map[byte]Commitments)
+	for _, s := range keyShares {
+		shares, commitments, err := GenerateRefresh(config)
+		require.NoError(t, err)
+		refreshShares[byte(s.Identifier)] = shares
+		refreshCommitments[byte(s.Identifier)] = commitments
+	}
+	refreshed := make([]*KeyShare, len(keyShares))
+	var newCommitments Commitments
+	for i, s := range keyShares {
+		received := make(map[byte]*KeyShare)
+		for id, shares := range refreshShares {
+			received[id] = shares[i]
+		}
+		refreshed[i], newCommitments, err = RefreshShare(config, s, keyCommitments, received, refreshCommitments)
+		require.NoError(t, err)
+		require.NotEqual(t, s.Value.Bytes(), refreshed[i].Value.Bytes())
+		ok, err := refreshed[i].VerifyVSS(newCommitments, config)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.True(t, newCommitments[0].Equal(keyCommitments[0]))
+
+	secret, err := Reconstruct(keyShares, config)
+	require.NoError(t, err)
+	refreshedSecret, err := Reconstruct(refreshed, config)
+	require.NoError(t, err)
+	require.Equal(t, secret, refreshedSecret)
+	// Old and refreshed shares do not combine
+	mixed, err := Reconstruct([]*KeyShare{keyShares[0], refreshed[1]}, config)
+	require.NoError(t, err)
+	require.NotEqual(t, secret, mixed)
+
+	assertSharesSign(t, config, refreshed, pub, newCommitments)
+
+	// A refresh must share zero
+	_, _, nonZeroCommitments, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	shares, commitments, err := GenerateRefresh(config)
+	require.NoError(t, err)
+	_, _, err = RefreshShare(config, keyShares[0], keyCommitments,
+		map[byte]*KeyShare{1: shares[0]}, map[byte]Commitments{1: nonZeroCommitments})
+	require.Error(t, err)
+	_, _, err = RefreshShare(config, keyShares[0], keyCommitments,
+		map[byte]*KeyShare{1: shares[1]}, map[byte]Commitments{1: commitments})
+	require.Error(t, err)
+}
+
+func TestReshareThresholdChange(t *testing.T) {
+	oldConfig := &ShareConfiguration{T: 2, N: 3}
+	newConfig := &ShareConfiguration{T: 3, N: 5}
+	pub, keyShares, keyCommitments, err := GenerateSharedKey(oldConfig)
+	require.NoError(t, err)
+
+	// Parties 1 and 3 reshare to 5 new parties
+	dealers := []*KeyShare{keyShares[0], keyShares[2]}
+	reshares := make(map[byte][]*KeyShare)
+	reshareCommitments := make(map[byte]Commitments)
+	for _, s := range dealers {
+		shares, commitments, err := GenerateReshare(newConfig, s)
+		require.NoError(t, err)
+		reshares[byte(s.Identifier)] = shares
+		reshareCommitments[byte(s.Identifier)] = commitments
+	}
+	newShares := make([]*KeyShare, newConfig.N)
+	var newCommitments Commitments
+	for i := range newShares {
+		received := make(map[byte]*KeyShare)
+		for id, shares := range reshares {
+			received[id] = shares[i]
+		}
+		newShares[i], newCommitments, err = CombineReshare(oldConfig, newConfig, byte(i+1), keyCommitments, received, reshareCommitments)
+		require.NoError(t, err)
+	}
+	require.True(t, newCommitments[0].Equal(keyCommitments[0]))
+	require.Len(t, newCommitments, newConfig.T)
+
+	secret, err := Reconstruct(keyShares, oldConfig)
+	require.NoError(t, err)
+	newSecret, err := Reconstruct([]*KeyShare{newShares[1], newShares[3], newShares[4]}, newConfig)
+	require.NoError(t, err)
+	require.Equal(t, secret, newSecret)
+
+	assertSharesSign(t, newConfig, newShares, pub, newCommitments)
+}
+
+func TestReshareBadInput(t *testing.T) {
+	oldConfig := &ShareConfiguration{T: 2, N: 3}
+	newConfig := &ShareConfiguration{T: 2, N: 4}
+	_, keyShares, keyCommitments, err := GenerateSharedKey(oldConfig)
+	require.NoError(t, err)
+	shares1, commitments1, err := GenerateReshare(newConfig, keyShares[0])
+	require.NoError(t, err)
+	shares2, commitments2, err := GenerateReshare(newConfig, keyShares[1])
+	require.NoError(t, err)
+
+	// Too few old parties
+	_, _, err = CombineReshare(oldConfig, newConfig, 1, keyCommitments,
+		map[byte]*KeyShare{1: shares1[0]}, map[byte]Commitments{1: commitments1})
+	require.Error(t, err)
+
+	// Party 2 reshares another secret than its key share
+	_, otherShares, _, err := GenerateSharedKey(oldConfig)
+	require.NoError(t, err)
+	badShares, badCommitments, err := GenerateReshare(newConfig, otherShares[1])
+	require.NoError(t, err)
+	_, _, err = CombineReshare(oldConfig, newConfig, 1, keyCommitments,
+		map[byte]*KeyShare{1: shares1[0], 2: badShares[0]}, map[byte]Commitments{1: commitments1, 2: badCommitments})
+	require.Error(t, err)
+
+	// Shares which do not match the commitments, or are for another party
+	_, _, err = CombineReshare(oldConfig, newConfig, 1, keyCommitments,
+		map[byte]*KeyShare{1: shares1[0], 2: shares2[0]}, map[byte]Commitments{1: commitments1, 2: commitments1})
+	require.Error(t, err)
+	_, _, err = CombineReshare(oldConfig, newConfig, 2, keyCommitments,
+		map[byte]*KeyShare{1: shares1[0], 2: shares2[0]}, map[byte]Commitments{1: commitments1, 2: commitments2})
+	require.Error(t, err)
+	_, _, err = CombineReshare(oldConfig, newConfig, 5, keyCommitments,
+		map[byte]*KeyShare{1: shares1[0], 2: shares2[0]}, map[byte]Commitments{1: commitments1, 2: commitments2})
+	require.Error(t, err)
+}
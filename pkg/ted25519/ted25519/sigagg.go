@@ -60,3 +60,62 @@ func Aggregate(sigs []*PartialSignature, config *ShareConfiguration) (Signature,
 
 	return sig, nil
 }
+
+// AggregateVerified verifies the partial signatures of `message` under `pub` before combining them, with the
+// verification shares of the VSS commitments of the key and the public nonce shares of the openings of
+// committed nonce generation. Invalid partial signatures are dropped and the identifiers of their signers are
+// returned, so they can be excluded from later signing. An error is returned if fewer than a threshold of
+// partial signatures are valid
+func AggregateVerified(
+	sigs []*PartialSignature,
+	config *ShareConfiguration,
+	pub PublicKey, message Message,
+	keyCommitments Commitments,
+	reveals []*NonceReveal,
+) (Signature, []byte, error) {
+	if len(sigs) == 0 || config == nil {
+		return nil, nil, fmt.Errorf("ted25519: sigs must be non-empty")
+	}
+	noncePub, err := evaluateNonces(reveals, pub, message, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var valid []*PartialSignature
+	var invalid []byte
+	seen := make(map[byte]bool, len(sigs))
+	for _, sig := range sigs {
+		if sig == nil || len(sig.Sig) != signatureLength {
+			return nil, nil, fmt.Errorf("ted25519: invalid partial signature")
+		}
+		if seen[sig.ShareIdentifier] {
+			return nil, nil, fmt.Errorf("ted25519: duplicate partial signature of party %d", sig.ShareIdentifier)
+		}
+		seen[sig.ShareIdentifier] = true
+		verificationShare, err := VerificationShare(keyCommitments, sig.ShareIdentifier)
+		if err != nil {
+			return nil, nil, err
+		}
+		nonceShare, err := NoncePublicShare(reveals, pub, message, sig.ShareIdentifier)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The challenge of a partial signature must be that of the public nonce of the session
+		ok, err := VerifyPartialSignature(sig, pub, message, verificationShare, nonceShare)
+		if err != nil || !ok || !bytes.Equal(sig.R(), noncePub.ToAffineCompressed()) {
+			invalid = append(invalid, sig.ShareIdentifier)
+			continue
+		}
+		valid = append(valid, sig)
+	}
+	if len(valid) < config.T {
+		return nil, invalid, fmt.Errorf("ted25519: %d valid partial signatures, invalid from parties %v", len(valid), invalid)
+	}
+	sig, err := Aggregate(valid, config)
+	if err != nil {
+		return nil, invalid, err
+	}
+	if ok, _ := Verify(pub, message, sig); !ok {
+		return nil, invalid, fmt.Errorf("ted25519: aggregated signature does not verify")
+	}
+	return sig, invalid, nil
+}
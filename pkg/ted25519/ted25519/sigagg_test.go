@@ -91,3 +91,82 @@ func assertSignatureVerifies(t *testing.T, pub, message, sig []byte) {
 		t.Errorf("signature of different message accepted")
 	}
 }
+
+// signCommitted signs `message` with all parties using committed nonces and returns the partial signatures and
+// the nonce openings
+func signCommitted(t *testing.T, config *ShareConfiguration, keyShares []*KeyShare, pub PublicKey, message Message) (
+	[]*PartialSignature, []*NonceReveal,
+) {
+	commitments, reveals, received := committedNonces(t, config, keyShares, pub, message)
+	sigs := make([]*PartialSignature, len(keyShares))
+	for i, s := range keyShares {
+		nonceShare, noncePub, err := CombineNonces(config, s, pub, message, commitments, reveals, received[byte(s.Identifier)])
+		require.NoError(t, err)
+		sigs[i] = TSign(message, s, pub, nonceShare, noncePub)
+	}
+	return sigs, reveals
+}
+
+func TestVerifyPartialSignature(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	pub, keyShares, keyCommitments, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	message := Message("test message")
+	sigs, reveals := signCommitted(t, config, keyShares, pub, message)
+
+	for i, sig := range sigs {
+		id := sig.ShareIdentifier
+		verificationShare, err := VerificationShare(keyCommitments, id)
+		require.NoError(t, err)
+		nonceShare, err := NoncePublicShare(reveals, pub, message, id)
+		require.NoError(t, err)
+		ok, err := VerifyPartialSignature(sig, pub, message, verificationShare, nonceShare)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, _ = VerifyPartialSignature(sig, pub, Message("other message"), verificationShare, nonceShare)
+		require.False(t, ok)
+		other := sigs[(i+1)%len(sigs)]
+		ok, _ = VerifyPartialSignature(other, pub, message, verificationShare, nonceShare)
+		require.False(t, ok)
+	}
+	_, err = VerificationShare(keyCommitments, 0)
+	require.Error(t, err)
+	_, err = NoncePublicShare(nil, pub, message, 1)
+	require.Error(t, err)
+}
+
+func TestAggregateVerified(t *testing.T) {
+	config := &ShareConfiguration{T: 2, N: 3}
+	pub, keyShares, keyCommitments, err := GenerateSharedKey(config)
+	require.NoError(t, err)
+	message := Message("test message")
+	sigs, reveals := signCommitted(t, config, keyShares, pub, message)
+
+	sig, invalid, err := AggregateVerified(sigs, config, pub, message, keyCommitments, reveals)
+	require.NoError(t, err)
+	require.Empty(t, invalid)
+	assertSignatureVerifies(t, pub, message, sig)
+
+	// The invalid partial signature of party 2 is dropped
+	bad := NewPartialSignature(2, append([]byte{}, sigs[1].Sig...))
+	bad.Sig[40] ^= 1
+	sig, invalid, err = AggregateVerified([]*PartialSignature{sigs[0], bad, sigs[2]}, config, pub, message, keyCommitments, reveals)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, invalid)
+	assertSignatureVerifies(t, pub, message, sig)
+
+	// A partial signature which is valid for another nonce is dropped too
+	otherSigs, _ := signCommitted(t, config, keyShares, pub, message)
+	sig, invalid, err = AggregateVerified([]*PartialSignature{sigs[0], sigs[1], otherSigs[2]}, config, pub, message, keyCommitments, reveals)
+	require.NoError(t, err)
+	require.Equal(t, []byte{3}, invalid)
+	assertSignatureVerifies(t, pub, message, sig)
+
+	// Fewer than a threshold of valid partial signatures
+	_, invalid, err = AggregateVerified([]*PartialSignature{sigs[0], bad}, config, pub, message, keyCommitments, reveals)
+	require.Error(t, err)
+	require.Equal(t, []byte{2}, invalid)
+	_, _, err = AggregateVerified([]*PartialSignature{sigs[0], sigs[0]}, config, pub, message, keyCommitments, reveals)
+	require.Error(t, err)
+}
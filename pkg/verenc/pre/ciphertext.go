@@ -0,0 +1,277 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package pre
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"git.sr.ht/~sircmpwn/go-bare"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+	"github.com/etclab/kryptology/pkg/core/curves/native/bls12381"
+)
+
+const (
+	nonceSize = 12
+	tagSize   = 16
+	kdfInfo   = "kryptology proxy re-encryption v1"
+)
+
+// Ciphertext is a ciphertext encrypted to the delegator that can be
+// decrypted with its secret key or re-encrypted by a proxy.
+// C1 = g1^(a*k) and Aead is the payload encrypted under KDF(Z^k)
+type Ciphertext struct {
+	C1    curves.PairingPoint
+	Nonce []byte
+	Aead  []byte
+}
+
+// ReEncryptedCiphertext is a ciphertext produced by a proxy that can only
+// be decrypted by the delegatee. C1 = Z^(b*k), the payload is unchanged
+type ReEncryptedCiphertext struct {
+	C1    curves.Scalar
+	Nonce []byte
+	Aead  []byte
+}
+
+type cipherTextMarshal struct {
+	C1    []byte `bare:"c1"`
+	Nonce []byte `bare:"nonce"`
+	Aead  []byte `bare:"aead"`
+	Curve string `bare:"curve"`
+}
+
+// Encrypt `msg` to this public key. The optional `aad` is authenticated
+// but not encrypted and must be supplied again to decrypt
+func (pk PublicKey) Encrypt(msg, aad []byte) (*Ciphertext, error) {
+	if msg == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if err := pk.Validate(); err != nil {
+		return nil, err
+	}
+	k := pk.G1.Scalar().Random(crand.Reader)
+	for k.IsZero() {
+		k = pk.G1.Scalar().Random(crand.Reader)
+	}
+	// Z^k = e(g1^k, g2)
+	g1k, _ := pk.G1.Generator().Mul(k).(curves.PairingPoint)
+	g2, _ := pk.G2.Generator().(curves.PairingPoint)
+	z := g1k.Pairing(g2)
+	if z == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	c1, ok := pk.G1.Mul(k).(curves.PairingPoint)
+	if !ok {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := crand.Read(nonce); err != nil {
+		return nil, err
+	}
+	aead, err := newAead(z)
+	if err != nil {
+		return nil, err
+	}
+	return &Ciphertext{
+		C1:    c1,
+		Nonce: nonce,
+		Aead:  aead.Seal(nil, nonce, msg, aad),
+	}, nil
+}
+
+// Decrypt a ciphertext encrypted to this key
+func (sk SecretKey) Decrypt(ct *Ciphertext, aad []byte) ([]byte, error) {
+	if err := ct.validate(); err != nil {
+		return nil, err
+	}
+	inv, err := sk.value.Invert()
+	if err != nil {
+		return nil, err
+	}
+	// Z^k = e(C1^(1/a), g2)
+	c, _ := ct.C1.Mul(inv).(curves.PairingPoint)
+	g2 := sk.curve.NewG2GeneratorPoint()
+	z := c.Pairing(g2)
+	if z == nil {
+		return nil, fmt.Errorf("invalid ciphertext")
+	}
+	return open(z, ct.Nonce, ct.Aead, aad)
+}
+
+// DecryptReEncrypted decrypts a ciphertext re-encrypted to this key
+func (sk SecretKey) DecryptReEncrypted(ct *ReEncryptedCiphertext, aad []byte) ([]byte, error) {
+	if err := ct.validate(); err != nil {
+		return nil, err
+	}
+	inv, err := sk.value.Invert()
+	if err != nil {
+		return nil, err
+	}
+	// Z^k = C1^(1/b)
+	z, err := gtExp(ct.C1, inv)
+	if err != nil {
+		return nil, err
+	}
+	return open(z, ct.Nonce, ct.Aead, aad)
+}
+
+// ReEncrypt transforms a ciphertext for the delegator into one for the delegatee.
+// The proxy learns nothing about the payload
+func (rk ReEncryptionKey) ReEncrypt(ct *Ciphertext) (*ReEncryptedCiphertext, error) {
+	if rk.Value == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if err := ct.validate(); err != nil {
+		return nil, err
+	}
+	// C1' = e(C1, rk)
+	c1 := ct.C1.Pairing(rk.Value)
+	if c1 == nil {
+		return nil, fmt.Errorf("invalid ciphertext")
+	}
+	return &ReEncryptedCiphertext{
+		C1:    c1,
+		Nonce: append([]byte{}, ct.Nonce...),
+		Aead:  append([]byte{}, ct.Aead...),
+	}, nil
+}
+
+func (ct *Ciphertext) validate() error {
+	if ct == nil || ct.C1 == nil || ct.Nonce == nil || ct.Aead == nil {
+		return internal.ErrNilArguments
+	}
+	// Have to check these because aesgcm will panic if not the correct length
+	if len(ct.Nonce) != nonceSize || len(ct.Aead) < tagSize {
+		return internal.ErrZeroValue
+	}
+	if ct.C1.IsIdentity() {
+		return fmt.Errorf("invalid ciphertext")
+	}
+	return nil
+}
+
+func (ct *ReEncryptedCiphertext) validate() error {
+	if ct == nil || ct.C1 == nil || ct.Nonce == nil || ct.Aead == nil {
+		return internal.ErrNilArguments
+	}
+	if len(ct.Nonce) != nonceSize || len(ct.Aead) < tagSize {
+		return internal.ErrZeroValue
+	}
+	if ct.C1.IsOne() {
+		return fmt.Errorf("invalid ciphertext")
+	}
+	return nil
+}
+
+// MarshalBinary serializes a ciphertext to bytes
+func (ct Ciphertext) MarshalBinary() ([]byte, error) {
+	tv := new(cipherTextMarshal)
+	tv.C1 = ct.C1.ToAffineCompressed()
+	tv.Nonce = append([]byte{}, ct.Nonce...)
+	tv.Aead = append([]byte{}, ct.Aead...)
+	tv.Curve = ct.C1.CurveName()
+	return bare.Marshal(tv)
+}
+
+// UnmarshalBinary deserializes a ciphertext from bytes
+func (ct *Ciphertext) UnmarshalBinary(data []byte) error {
+	tv := new(cipherTextMarshal)
+	err := bare.Unmarshal(data, tv)
+	if err != nil {
+		return err
+	}
+	curve := curves.GetPairingCurveByName(tv.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve")
+	}
+	c1, err := curve.PointG1.FromAffineCompressed(tv.C1)
+	if err != nil {
+		return err
+	}
+	ct.C1, _ = c1.(curves.PairingPoint)
+	ct.Nonce = tv.Nonce
+	ct.Aead = tv.Aead
+	return nil
+}
+
+// MarshalBinary serializes a ciphertext to bytes
+func (ct ReEncryptedCiphertext) MarshalBinary() ([]byte, error) {
+	tv := new(cipherTextMarshal)
+	tv.C1 = ct.C1.Bytes()
+	tv.Nonce = append([]byte{}, ct.Nonce...)
+	tv.Aead = append([]byte{}, ct.Aead...)
+	tv.Curve = ct.C1.Point().CurveName()
+	return bare.Marshal(tv)
+}
+
+// UnmarshalBinary deserializes a ciphertext from bytes
+func (ct *ReEncryptedCiphertext) UnmarshalBinary(data []byte) error {
+	tv := new(cipherTextMarshal)
+	err := bare.Unmarshal(data, tv)
+	if err != nil {
+		return err
+	}
+	curve := curves.GetPairingCurveByName(tv.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve")
+	}
+	if len(tv.C1) != len(curve.GT.Bytes()) {
+		return fmt.Errorf("invalid ciphertext")
+	}
+	c1, err := curve.GT.SetBytes(tv.C1)
+	if err != nil {
+		return err
+	}
+	ct.C1 = c1
+	ct.Nonce = tv.Nonce
+	ct.Aead = tv.Aead
+	return nil
+}
+
+// gtExp raises the target group element `gt` to the power `s`.
+// The curves abstraction only exposes the group operation of the target
+// group, so this uses the native implementation of each pairing curve
+func gtExp(gt, s curves.Scalar) (curves.Scalar, error) {
+	switch g := gt.(type) {
+	case *curves.ScalarBls12381Gt:
+		e, ok := s.(*curves.ScalarBls12381)
+		if !ok {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &curves.ScalarBls12381Gt{Value: new(bls12381.Gt).Mul(g.Value, e.Value)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported pairing curve")
+	}
+}
+
+func newAead(z curves.Scalar) (cipher.AEAD, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, z.Bytes(), nil, []byte(kdfInfo)), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func open(z curves.Scalar, nonce, data, aad []byte) ([]byte, error) {
+	aead, err := newAead(z)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, nonce, data, aad)
+}
@@ -0,0 +1,120 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package pre
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, skA, err := NewKeys(curve)
+	require.NoError(t, err)
+	_, skB, err := NewKeys(curve)
+	require.NoError(t, err)
+
+	msg := []byte("backup of a threshold key share")
+	aad := []byte("backup-2026-10-17")
+	ct, err := pkA.Encrypt(msg, aad)
+	require.NoError(t, err)
+
+	pt, err := skA.Decrypt(ct, aad)
+	require.NoError(t, err)
+	require.Equal(t, msg, pt)
+
+	_, err = skA.Decrypt(ct, []byte("other"))
+	require.Error(t, err)
+	_, err = skB.Decrypt(ct, aad)
+	require.Error(t, err)
+
+	_, err = pkA.Encrypt(nil, aad)
+	require.Error(t, err)
+}
+
+func TestReEncrypt(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, skA, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkB, skB, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkC, skC, err := NewKeys(curve)
+	require.NoError(t, err)
+
+	msg := []byte("backup of a threshold key share")
+	ct, err := pkA.Encrypt(msg, nil)
+	require.NoError(t, err)
+
+	rkAB, err := skA.ReEncryptionKey(pkB)
+	require.NoError(t, err)
+	rct, err := rkAB.ReEncrypt(ct)
+	require.NoError(t, err)
+
+	pt, err := skB.DecryptReEncrypted(rct, nil)
+	require.NoError(t, err)
+	require.Equal(t, msg, pt)
+	_, err = skC.DecryptReEncrypted(rct, nil)
+	require.Error(t, err)
+	_, err = skA.DecryptReEncrypted(rct, nil)
+	require.Error(t, err)
+
+	// A key for another delegatee gives a different ciphertext
+	rkAC, err := skA.ReEncryptionKey(pkC)
+	require.NoError(t, err)
+	rct2, err := rkAC.ReEncrypt(ct)
+	require.NoError(t, err)
+	pt, err = skC.DecryptReEncrypted(rct2, nil)
+	require.NoError(t, err)
+	require.Equal(t, msg, pt)
+
+	// Ciphertexts for another delegator cannot be re-encrypted with the key
+	ctB, err := pkB.Encrypt(msg, nil)
+	require.NoError(t, err)
+	rct3, err := rkAC.ReEncrypt(ctB)
+	require.NoError(t, err)
+	_, err = skC.DecryptReEncrypted(rct3, nil)
+	require.Error(t, err)
+
+	_, err = rkAB.ReEncrypt(&Ciphertext{C1: ct.C1, Nonce: ct.Nonce[:4], Aead: ct.Aead})
+	require.Error(t, err)
+	_, err = rkAB.ReEncrypt(nil)
+	require.Error(t, err)
+}
+
+func TestCiphertextMarshaling(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, skA, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkB, skB, err := NewKeys(curve)
+	require.NoError(t, err)
+	rk, err := skA.ReEncryptionKey(pkB)
+	require.NoError(t, err)
+
+	msg := []byte("backup")
+	ct, err := pkA.Encrypt(msg, nil)
+	require.NoError(t, err)
+	data, err := ct.MarshalBinary()
+	require.NoError(t, err)
+	ct2 := new(Ciphertext)
+	require.NoError(t, ct2.UnmarshalBinary(data))
+	pt, err := skA.Decrypt(ct2, nil)
+	require.NoError(t, err)
+	require.Equal(t, msg, pt)
+
+	rct, err := rk.ReEncrypt(ct2)
+	require.NoError(t, err)
+	data, err = rct.MarshalBinary()
+	require.NoError(t, err)
+	rct2 := new(ReEncryptedCiphertext)
+	require.NoError(t, rct2.UnmarshalBinary(data))
+	pt, err = skB.DecryptReEncrypted(rct2, nil)
+	require.NoError(t, err)
+	require.Equal(t, msg, pt)
+}
@@ -0,0 +1,40 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package pre
+// Proxy re-encryption
+//
+// Proxy re-encryption lets a delegator hand a semi-trusted proxy a re-encryption key that transforms
+// ciphertexts encrypted to the delegator into ciphertexts the delegatee can decrypt, without the proxy
+// learning the plaintext or either secret key. This allows, for example, the decryption rights of an
+// encrypted backup to be delegated to a recovery party without re-encrypting the data.
+//
+// # How it works
+//
+// The scheme is the unidirectional, single hop scheme of Ateniese, Fu, Green and Hohenberger instantiated
+// over an asymmetric pairing e: G1 x G2 -> GT with generators g1, g2 and Z = e(g1, g2). It is used as a key
+// encapsulation mechanism and the payload is encrypted with AES-GCM under a key derived from Z^k.
+//
+//  1. Secret keys are random scalars a, public keys are (g1^a, g2^a)
+//  2. Encryption samples k and outputs C = g1^(a*k), the key is KDF(Z^k)
+//  3. The owner decrypts with e(C^(1/a), g2) = Z^k
+//  4. The re-encryption key from a to b is rk = (g2^b)^(1/a)
+//  5. Re-encryption outputs C' = e(C, rk) = Z^(b*k)
+//  6. The delegatee decrypts with C'^(1/b) = Z^k
+//
+// Re-encryption keys can be checked against both public keys with e(g1^a, rk) = e(g1, g2^b).
+//
+// # Proving
+//
+// A proxy can prove that a re-encrypted ciphertext was computed with a valid re-encryption key without
+// revealing it. The map rk -> (e(C, rk), e(g1^a, rk)) is a group homomorphism from G2 so a Schnorr proof
+// of knowledge of a preimage of (C', e(g1, g2^b)) shows that C' = e(C, rk) for a key satisfying the check above.
+// The proof is made non-interactive with the Fiat-Shamir heuristic and consists of the challenge and one point in G2.
+//
+// # References
+//
+// Improved Proxy Re-Encryption Schemes with Applications to Secure Distributed Storage - Ateniese, Fu, Green and Hohenberger 2006. https://eprint.iacr.org/2005/028.pdf
+package pre
@@ -0,0 +1,234 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package pre
+
+import (
+	crand "crypto/rand"
+	"fmt"
+
+	"git.sr.ht/~sircmpwn/go-bare"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+type publicKeyMarshal struct {
+	G1    []byte `bare:"g1"`
+	G2    []byte `bare:"g2"`
+	Curve string `bare:"curve"`
+}
+
+type secretKeyMarshal struct {
+	Value []byte `bare:"value"`
+	Curve string `bare:"curve"`
+}
+
+type reEncryptionKeyMarshal struct {
+	Value []byte `bare:"value"`
+	Curve string `bare:"curve"`
+}
+
+// PublicKey encrypts messages that can be decrypted by the owner
+// or re-encrypted to a delegatee. G1 = g1^a and G2 = g2^a
+type PublicKey struct {
+	G1, G2 curves.PairingPoint
+}
+
+// SecretKey decrypts ciphertexts and creates re-encryption keys
+type SecretKey struct {
+	curve *curves.PairingCurve
+	value curves.Scalar
+}
+
+// ReEncryptionKey transforms ciphertexts encrypted to the delegator
+// into ciphertexts for the delegatee. Value = (g2^b)^(1/a)
+type ReEncryptionKey struct {
+	Value curves.PairingPoint
+}
+
+// NewKeys creates a new key pair for proxy re-encryption
+func NewKeys(curve *curves.PairingCurve) (*PublicKey, *SecretKey, error) {
+	if curve == nil {
+		return nil, nil, fmt.Errorf("invalid curve")
+	}
+	a := curve.Scalar.Random(crand.Reader)
+	for a != nil && a.IsZero() {
+		a = curve.Scalar.Random(crand.Reader)
+	}
+	if a == nil {
+		return nil, nil, fmt.Errorf("cannot generate secret key")
+	}
+	sk := &SecretKey{curve, a}
+	return sk.PublicKey(), sk, nil
+}
+
+// PublicKey returns the corresponding public key for this secret key
+func (sk SecretKey) PublicKey() *PublicKey {
+	return &PublicKey{
+		G1: sk.curve.ScalarG1BaseMult(sk.value),
+		G2: sk.curve.ScalarG2BaseMult(sk.value),
+	}
+}
+
+// ReEncryptionKey creates the key that allows a proxy to re-encrypt
+// the ciphertexts for this key to the owner of `to`
+func (sk SecretKey) ReEncryptionKey(to *PublicKey) (*ReEncryptionKey, error) {
+	if err := to.Validate(); err != nil {
+		return nil, err
+	}
+	inv, err := sk.value.Invert()
+	if err != nil {
+		return nil, err
+	}
+	rk, ok := to.G2.Mul(inv).(curves.PairingPoint)
+	if !ok {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	return &ReEncryptionKey{rk}, nil
+}
+
+// Validate checks that both components of the public key use the same secret
+func (pk *PublicKey) Validate() error {
+	if pk == nil || pk.G1 == nil || pk.G2 == nil {
+		return internal.ErrNilArguments
+	}
+	if pk.G1.IsIdentity() || pk.G2.IsIdentity() {
+		return fmt.Errorf("invalid public key")
+	}
+	// e(g1^a, g2) * e(g1^-1, g2^a) = 1
+	g1, ok := pk.G1.Generator().Neg().(curves.PairingPoint)
+	if !ok {
+		return fmt.Errorf("invalid public key")
+	}
+	g2, ok := pk.G2.Generator().(curves.PairingPoint)
+	if !ok {
+		return fmt.Errorf("invalid public key")
+	}
+	res := pk.G1.MultiPairing(pk.G1, g2, g1, pk.G2)
+	if res == nil || !res.IsOne() {
+		return fmt.Errorf("invalid public key")
+	}
+	return nil
+}
+
+// Verify checks that the re-encryption key transforms ciphertexts for `from` into ciphertexts for `to`.
+// This requires knowing the key, proxies prove it was used correctly with ReEncryptWithProof instead
+func (rk *ReEncryptionKey) Verify(from, to *PublicKey) error {
+	if rk == nil || rk.Value == nil {
+		return internal.ErrNilArguments
+	}
+	if err := from.Validate(); err != nil {
+		return err
+	}
+	if err := to.Validate(); err != nil {
+		return err
+	}
+	g1, ok := from.G1.Generator().Neg().(curves.PairingPoint)
+	if !ok {
+		return fmt.Errorf("invalid public key")
+	}
+	// e(g1^a, g2^(b/a)) * e(g1^-1, g2^b) = 1
+	res := from.G1.MultiPairing(from.G1, rk.Value, g1, to.G2)
+	if res == nil || !res.IsOne() {
+		return fmt.Errorf("invalid re-encryption key")
+	}
+	return nil
+}
+
+// MarshalBinary serializes a key to bytes
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	tv := new(publicKeyMarshal)
+	tv.G1 = pk.G1.ToAffineCompressed()
+	tv.G2 = pk.G2.ToAffineCompressed()
+	tv.Curve = pk.G1.CurveName()
+	return bare.Marshal(tv)
+}
+
+// UnmarshalBinary deserializes a key from bytes
+func (pk *PublicKey) UnmarshalBinary(data []byte) error {
+	tv := new(publicKeyMarshal)
+	err := bare.Unmarshal(data, tv)
+	if err != nil {
+		return err
+	}
+	curve := curves.GetPairingCurveByName(tv.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve")
+	}
+	g1, err := curve.PointG1.FromAffineCompressed(tv.G1)
+	if err != nil {
+		return err
+	}
+	g2, err := curve.PointG2.FromAffineCompressed(tv.G2)
+	if err != nil {
+		return err
+	}
+	pk.G1, _ = g1.(curves.PairingPoint)
+	pk.G2, _ = g2.(curves.PairingPoint)
+	return pk.Validate()
+}
+
+// MarshalBinary serializes a key to bytes
+func (sk SecretKey) MarshalBinary() ([]byte, error) {
+	tv := new(secretKeyMarshal)
+	tv.Value = sk.value.Bytes()
+	tv.Curve = sk.curve.PointG1.CurveName()
+	return bare.Marshal(tv)
+}
+
+// UnmarshalBinary deserializes a key from bytes
+func (sk *SecretKey) UnmarshalBinary(data []byte) error {
+	tv := new(secretKeyMarshal)
+	err := bare.Unmarshal(data, tv)
+	if err != nil {
+		return err
+	}
+	curve := curves.GetPairingCurveByName(tv.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve")
+	}
+	value, err := curve.Scalar.SetBytes(tv.Value)
+	if err != nil {
+		return err
+	}
+	if value.IsZero() {
+		return internal.ErrZeroValue
+	}
+	sk.curve = curve
+	sk.value = value
+	return nil
+}
+
+// MarshalBinary serializes a key to bytes
+func (rk ReEncryptionKey) MarshalBinary() ([]byte, error) {
+	tv := new(reEncryptionKeyMarshal)
+	tv.Value = rk.Value.ToAffineCompressed()
+	tv.Curve = rk.Value.CurveName()
+	return bare.Marshal(tv)
+}
+
+// UnmarshalBinary deserializes a key from bytes
+func (rk *ReEncryptionKey) UnmarshalBinary(data []byte) error {
+	tv := new(reEncryptionKeyMarshal)
+	err := bare.Unmarshal(data, tv)
+	if err != nil {
+		return err
+	}
+	curve := curves.GetPairingCurveByName(tv.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve")
+	}
+	value, err := curve.PointG2.FromAffineCompressed(tv.Value)
+	if err != nil {
+		return err
+	}
+	if value.IsIdentity() {
+		return fmt.Errorf("invalid re-encryption key")
+	}
+	rk.Value, _ = value.(curves.PairingPoint)
+	return nil
+}
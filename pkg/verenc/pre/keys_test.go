@@ -0,0 +1,85 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package pre
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestReEncryptionKeyVerify(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, skA, err := NewKeys(curve)
+	require.NoError(t, err)
+	require.NoError(t, pkA.Validate())
+	pkB, skB, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkC, _, err := NewKeys(curve)
+	require.NoError(t, err)
+
+	rk, err := skA.ReEncryptionKey(pkB)
+	require.NoError(t, err)
+	require.NoError(t, rk.Verify(pkA, pkB))
+	require.Error(t, rk.Verify(pkA, pkC))
+	require.Error(t, rk.Verify(pkB, pkA))
+
+	rk, err = skB.ReEncryptionKey(pkA)
+	require.NoError(t, err)
+	require.NoError(t, rk.Verify(pkB, pkA))
+}
+
+func TestPublicKeyValidate(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, _, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkB, skB, err := NewKeys(curve)
+	require.NoError(t, err)
+
+	// Mixed components are rejected
+	require.Error(t, (&PublicKey{pkA.G1, pkB.G2}).Validate())
+	require.Error(t, (&PublicKey{}).Validate())
+	identity := &PublicKey{curve.NewG1IdentityPoint(), curve.NewG2IdentityPoint()}
+	require.Error(t, identity.Validate())
+	_, err = skB.ReEncryptionKey(&PublicKey{pkA.G1, pkB.G2})
+	require.Error(t, err)
+	_, _, err = NewKeys(nil)
+	require.Error(t, err)
+}
+
+func TestKeysMarshaling(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, skA, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkB, _, err := NewKeys(curve)
+	require.NoError(t, err)
+	rk, err := skA.ReEncryptionKey(pkB)
+	require.NoError(t, err)
+
+	data, err := pkA.MarshalBinary()
+	require.NoError(t, err)
+	pk := new(PublicKey)
+	require.NoError(t, pk.UnmarshalBinary(data))
+	require.True(t, pk.G1.Equal(pkA.G1))
+	require.True(t, pk.G2.Equal(pkA.G2))
+
+	data, err = skA.MarshalBinary()
+	require.NoError(t, err)
+	sk := new(SecretKey)
+	require.NoError(t, sk.UnmarshalBinary(data))
+	require.True(t, sk.PublicKey().G1.Equal(pkA.G1))
+
+	data, err = rk.MarshalBinary()
+	require.NoError(t, err)
+	rk2 := new(ReEncryptionKey)
+	require.NoError(t, rk2.UnmarshalBinary(data))
+	require.NoError(t, rk2.Verify(pkA, pkB))
+
+	require.Error(t, pk.UnmarshalBinary([]byte{1, 2, 3}))
+}
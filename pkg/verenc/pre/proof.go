@@ -0,0 +1,160 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package pre
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"fmt"
+
+	"git.sr.ht/~sircmpwn/go-bare"
+
+	"github.com/etclab/kryptology/internal"
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+const proofDomain = "kryptology proxy re-encryption proof v1"
+
+// ReEncryptionProof proves that a re-encrypted ciphertext was computed from
+// a ciphertext with a valid re-encryption key, without revealing the key
+type ReEncryptionProof struct {
+	challenge curves.Scalar
+	response  curves.PairingPoint
+}
+
+type proofMarshal struct {
+	Challenge []byte `bare:"challenge"`
+	Response  []byte `bare:"response"`
+	Curve     string `bare:"curve"`
+}
+
+// ReEncryptWithProof re-encrypts `ct` from `from` to `to` and proves the transformation is correct.
+// The `nonce` binds the proof to a context, such as a session identifier, and must be given to Verify
+func (rk ReEncryptionKey) ReEncryptWithProof(ct *Ciphertext, from, to *PublicKey, nonce []byte) (*ReEncryptedCiphertext, *ReEncryptionProof, error) {
+	if nonce == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if err := rk.Verify(from, to); err != nil {
+		return nil, nil, err
+	}
+	rct, err := rk.ReEncrypt(ct)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// R = g2^r, T1 = e(C1, R), T2 = e(g1^a, R)
+	r := from.G1.Scalar().Random(crand.Reader)
+	bigR, ok := from.G2.Generator().Mul(r).(curves.PairingPoint)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid public key")
+	}
+	t1 := ct.C1.Pairing(bigR)
+	t2 := from.G1.Pairing(bigR)
+	if t1 == nil || t2 == nil {
+		return nil, nil, fmt.Errorf("invalid ciphertext")
+	}
+	challenge := proofChallenge(ct, rct, from, to, t1, t2, nonce)
+	// S = R + c * rk
+	response, ok := bigR.Add(rk.Value.Mul(challenge)).(curves.PairingPoint)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid re-encryption key")
+	}
+	return rct, &ReEncryptionProof{challenge, response}, nil
+}
+
+// Verify checks that `rct` is the re-encryption of `ct` from `from` to `to`
+func (pf ReEncryptionProof) Verify(ct *Ciphertext, rct *ReEncryptedCiphertext, from, to *PublicKey, nonce []byte) error {
+	if pf.challenge == nil || pf.response == nil || nonce == nil {
+		return internal.ErrNilArguments
+	}
+	if err := ct.validate(); err != nil {
+		return err
+	}
+	if err := rct.validate(); err != nil {
+		return err
+	}
+	if err := from.Validate(); err != nil {
+		return err
+	}
+	if err := to.Validate(); err != nil {
+		return err
+	}
+	if !bytes.Equal(ct.Nonce, rct.Nonce) || !bytes.Equal(ct.Aead, rct.Aead) {
+		return fmt.Errorf("payload mismatch")
+	}
+
+	// T1 = e(C1, S) / C1'^c
+	lhs := ct.C1.Pairing(pf.response)
+	if lhs == nil {
+		return fmt.Errorf("invalid proof")
+	}
+	rhs, err := gtExp(rct.C1, pf.challenge)
+	if err != nil {
+		return err
+	}
+	t1 := lhs.Div(rhs)
+	// T2 = e(g1^a, S) * e(g1^-c, g2^b)
+	g1c, ok := from.G1.Generator().Mul(pf.challenge.Neg()).(curves.PairingPoint)
+	if !ok {
+		return fmt.Errorf("invalid public key")
+	}
+	t2 := from.G1.MultiPairing(from.G1, pf.response, g1c, to.G2)
+	if t1 == nil || t2 == nil {
+		return fmt.Errorf("invalid proof")
+	}
+	if proofChallenge(ct, rct, from, to, t1, t2, nonce).Cmp(pf.challenge) != 0 {
+		return fmt.Errorf("invalid proof")
+	}
+	return nil
+}
+
+func proofChallenge(ct *Ciphertext, rct *ReEncryptedCiphertext, from, to *PublicKey, t1, t2 curves.Scalar, nonce []byte) curves.Scalar {
+	challengeBytes := []byte(proofDomain)
+	challengeBytes = append(challengeBytes, from.G1.ToAffineCompressed()...)
+	challengeBytes = append(challengeBytes, from.G2.ToAffineCompressed()...)
+	challengeBytes = append(challengeBytes, to.G1.ToAffineCompressed()...)
+	challengeBytes = append(challengeBytes, to.G2.ToAffineCompressed()...)
+	challengeBytes = append(challengeBytes, ct.C1.ToAffineCompressed()...)
+	challengeBytes = append(challengeBytes, rct.C1.Bytes()...)
+	challengeBytes = append(challengeBytes, t1.Bytes()...)
+	challengeBytes = append(challengeBytes, t2.Bytes()...)
+	challengeBytes = append(challengeBytes, nonce...)
+	return from.G1.Scalar().Hash(challengeBytes)
+}
+
+// MarshalBinary serializes a proof to bytes
+func (pf ReEncryptionProof) MarshalBinary() ([]byte, error) {
+	tv := new(proofMarshal)
+	tv.Challenge = pf.challenge.Bytes()
+	tv.Response = pf.response.ToAffineCompressed()
+	tv.Curve = pf.response.CurveName()
+	return bare.Marshal(tv)
+}
+
+// UnmarshalBinary deserializes a proof from bytes
+func (pf *ReEncryptionProof) UnmarshalBinary(data []byte) error {
+	tv := new(proofMarshal)
+	err := bare.Unmarshal(data, tv)
+	if err != nil {
+		return err
+	}
+	curve := curves.GetPairingCurveByName(tv.Curve)
+	if curve == nil {
+		return fmt.Errorf("unknown curve")
+	}
+	challenge, err := curve.Scalar.SetBytes(tv.Challenge)
+	if err != nil {
+		return err
+	}
+	response, err := curve.PointG2.FromAffineCompressed(tv.Response)
+	if err != nil {
+		return err
+	}
+	pf.challenge = challenge
+	pf.response, _ = response.(curves.PairingPoint)
+	return nil
+}
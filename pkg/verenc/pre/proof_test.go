@@ -0,0 +1,88 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package pre
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestReEncryptWithProof(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, skA, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkB, skB, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkC, skC, err := NewKeys(curve)
+	require.NoError(t, err)
+	nonce := []byte("TestReEncryptWithProof")
+
+	msg := []byte("backup of a threshold key share")
+	ct, err := pkA.Encrypt(msg, nil)
+	require.NoError(t, err)
+	rk, err := skA.ReEncryptionKey(pkB)
+	require.NoError(t, err)
+
+	rct, proof, err := rk.ReEncryptWithProof(ct, pkA, pkB, nonce)
+	require.NoError(t, err)
+	require.NoError(t, proof.Verify(ct, rct, pkA, pkB, nonce))
+	pt, err := skB.DecryptReEncrypted(rct, nil)
+	require.NoError(t, err)
+	require.Equal(t, msg, pt)
+
+	// The proof is bound to the statement
+	require.Error(t, proof.Verify(ct, rct, pkA, pkB, []byte("other")))
+	require.Error(t, proof.Verify(ct, rct, pkA, pkC, nonce))
+	require.Error(t, proof.Verify(ct, rct, pkC, pkB, nonce))
+	ct2, err := pkA.Encrypt(msg, nil)
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(ct2, rct, pkA, pkB, nonce))
+
+	// A proxy using another key cannot produce a valid transformation
+	rkC, err := skC.ReEncryptionKey(pkB)
+	require.NoError(t, err)
+	bad, err := rkC.ReEncrypt(ct)
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(ct, bad, pkA, pkB, nonce))
+	_, _, err = rkC.ReEncryptWithProof(ct, pkA, pkB, nonce)
+	require.Error(t, err)
+
+	// Tampering with the payload is detected
+	tampered := *rct
+	tampered.Aead = append([]byte{}, rct.Aead...)
+	tampered.Aead[0] ^= 1
+	require.Error(t, proof.Verify(ct, &tampered, pkA, pkB, nonce))
+
+	_, _, err = rk.ReEncryptWithProof(ct, pkA, pkB, nil)
+	require.Error(t, err)
+}
+
+func TestReEncryptionProofMarshaling(t *testing.T) {
+	curve := curves.BLS12381(&curves.PointBls12381G1{})
+	pkA, skA, err := NewKeys(curve)
+	require.NoError(t, err)
+	pkB, _, err := NewKeys(curve)
+	require.NoError(t, err)
+	nonce := []byte("TestReEncryptionProofMarshaling")
+
+	ct, err := pkA.Encrypt([]byte("backup"), nil)
+	require.NoError(t, err)
+	rk, err := skA.ReEncryptionKey(pkB)
+	require.NoError(t, err)
+	rct, proof, err := rk.ReEncryptWithProof(ct, pkA, pkB, nonce)
+	require.NoError(t, err)
+
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+	proof2 := new(ReEncryptionProof)
+	require.NoError(t, proof2.UnmarshalBinary(data))
+	require.NoError(t, proof2.Verify(ct, rct, pkA, pkB, nonce))
+	require.Error(t, new(ReEncryptionProof).Verify(ct, rct, pkA, pkB, nonce))
+}
@@ -0,0 +1,294 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package crossgroup implements a proof that two points on different curves have the same discrete log,
+// as an integer, with respect to the generators of their curves.
+// The prover commits to every bit of the witness with a Pedersen commitment on each curve and proves with a
+// Cramer-Damgard-Schoenmakers OR proof that each pair of commitments opens to the same bit, 0 or 1.
+// The challenges are 128 bit integers, which have the same meaning on both curves.
+// See "Discrete logarithm equality across groups", Noether, MRL-0010, https://www.getmonero.org/resources/research-lab/pubs/MRL-0010.pdf
+package crossgroup
+
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+// ChallengeSize is the size of the proof challenges in bytes
+const ChallengeSize = 16
+
+var (
+	// blindingLabel is the label of the Pedersen blinding generators, see curves.DeriveGenerators
+	blindingLabel = []byte("kryptology crossgroup blinding")
+	// blindingTables caches the fixed base tables of the blinding generators by curve name
+	blindingTables sync.Map
+)
+
+// BitProof proves that CommitmentA and CommitmentB commit to the same bit
+type BitProof struct {
+	CommitmentA, CommitmentB curves.Point
+	// C0 is the challenge of the 0 branch, the challenge of the 1 branch is C0 xor the proof challenge
+	C0       [ChallengeSize]byte
+	Z0A, Z1A curves.Scalar
+	Z0B, Z1B curves.Scalar
+}
+
+// Proof shows that StatementA = x * G_A and StatementB = x * G_B for an integer 0 <= x < 2^len(Bits)
+type Proof struct {
+	StatementA, StatementB curves.Point
+	C                      [ChallengeSize]byte
+	Bits                   []*BitProof
+}
+
+// Prove generates a proof that x * G_A and x * G_B have the same discrete log `x`, which must be less than 2^bits.
+// `uniqueSessionId` binds the proof to its context and must be given to Verify
+func Prove(x *big.Int, bits int, curveA, curveB *curves.Curve, uniqueSessionId []byte) (*Proof, error) {
+	return prove(x, bits, curveA, curveB, uniqueSessionId, crand.Reader)
+}
+
+func prove(x *big.Int, bits int, curveA, curveB *curves.Curve, uniqueSessionId []byte, reader io.Reader) (*Proof, error) {
+	if x == nil || curveA == nil || curveB == nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	if bits < 1 || x.Sign() < 0 || x.BitLen() > bits {
+		return nil, fmt.Errorf("witness must be between 0 and 2^%d", bits)
+	}
+	hA, hB, err := blindingGenerators(curveA, curveB)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &Proof{
+		StatementA: curveA.ScalarBaseMult(scalarFromInt(curveA, x)),
+		StatementB: curveB.ScalarBaseMult(scalarFromInt(curveB, x)),
+		Bits:       make([]*BitProof, bits),
+	}
+	// The blinding factors are chosen so that sum(2^i * r_i) = 0, which makes
+	// the weighted sum of the commitments equal to the statement on each curve
+	rA := weightedZeroSum(curveA, bits, reader)
+	rB := weightedZeroSum(curveB, bits, reader)
+
+	kA := make([]curves.Scalar, bits)
+	kB := make([]curves.Scalar, bits)
+	simC := make([][ChallengeSize]byte, bits)
+	nonces := make([][4]curves.Point, bits)
+	for i := range proof.Bits {
+		bit := int(x.Bit(i))
+		// The tables are variable time, secret scalars multiply the generators directly
+		bp := &BitProof{
+			CommitmentA: hA.Base().Mul(rA[i]),
+			CommitmentB: hB.Base().Mul(rB[i]),
+		}
+		if bit == 1 {
+			bp.CommitmentA = bp.CommitmentA.Add(curveA.NewGeneratorPoint())
+			bp.CommitmentB = bp.CommitmentB.Add(curveB.NewGeneratorPoint())
+		}
+
+		// Simulate the branch of the other bit value with a random challenge and responses
+		if _, err := io.ReadFull(reader, simC[i][:]); err != nil {
+			return nil, errors.Wrap(err, "sampling simulated challenge")
+		}
+		simZA := curveA.Scalar.Random(reader)
+		simZB := curveB.Scalar.Random(reader)
+		simTA, simTB := branchNonces(curveA, curveB, hA, hB, bp, 1-bit, simC[i], simZA, simZB)
+
+		kA[i] = curveA.Scalar.Random(reader)
+		kB[i] = curveB.Scalar.Random(reader)
+		if bit == 0 {
+			nonces[i] = [4]curves.Point{hA.Base().Mul(kA[i]), hB.Base().Mul(kB[i]), simTA, simTB}
+			bp.Z1A, bp.Z1B = simZA, simZB
+		} else {
+			nonces[i] = [4]curves.Point{simTA, simTB, hA.Base().Mul(kA[i]), hB.Base().Mul(kB[i])}
+			bp.C0 = simC[i]
+			bp.Z0A, bp.Z0B = simZA, simZB
+		}
+		proof.Bits[i] = bp
+	}
+
+	proof.C, err = challenge(proof, nonces, uniqueSessionId)
+	if err != nil {
+		return nil, err
+	}
+	for i, bp := range proof.Bits {
+		if x.Bit(i) == 0 {
+			// The 1 branch was simulated with simC, so c0 = c xor simC
+			bp.C0 = xorChallenge(proof.C, simC[i])
+			bp.Z0A = kA[i].Add(challengeScalar(curveA, bp.C0).Mul(rA[i]))
+			bp.Z0B = kB[i].Add(challengeScalar(curveB, bp.C0).Mul(rB[i]))
+		} else {
+			c1 := xorChallenge(proof.C, bp.C0)
+			bp.Z1A = kA[i].Add(challengeScalar(curveA, c1).Mul(rA[i]))
+			bp.Z1B = kB[i].Add(challengeScalar(curveB, c1).Mul(rB[i]))
+		}
+	}
+	return proof, nil
+}
+
+// Verify checks that the proof shows StatementA and StatementB have the same discrete log less than 2^bits
+func Verify(proof *Proof, bits int, curveA, curveB *curves.Curve, uniqueSessionId []byte) error {
+	if proof == nil || curveA == nil || curveB == nil || proof.StatementA == nil || proof.StatementB == nil {
+		return fmt.Errorf("invalid arguments")
+	}
+	if len(proof.Bits) != bits {
+		return fmt.Errorf("expected %d bit proofs, got %d", bits, len(proof.Bits))
+	}
+	hA, hB, err := blindingGenerators(curveA, curveB)
+	if err != nil {
+		return err
+	}
+
+	commitmentsA := make([]curves.Point, bits)
+	commitmentsB := make([]curves.Point, bits)
+	powersA := make([]curves.Scalar, bits)
+	powersB := make([]curves.Scalar, bits)
+	powerA := curveA.Scalar.One()
+	powerB := curveB.Scalar.One()
+	nonces := make([][4]curves.Point, bits)
+	for i, bp := range proof.Bits {
+		if bp == nil || bp.CommitmentA == nil || bp.CommitmentB == nil ||
+			bp.Z0A == nil || bp.Z1A == nil || bp.Z0B == nil || bp.Z1B == nil {
+			return fmt.Errorf("bit proof %d is incomplete", i)
+		}
+		if !bp.CommitmentA.IsOnCurve() || !bp.CommitmentB.IsOnCurve() {
+			return fmt.Errorf("bit proof %d has invalid commitments", i)
+		}
+		commitmentsA[i], commitmentsB[i] = bp.CommitmentA, bp.CommitmentB
+		powersA[i], powersB[i] = powerA, powerB
+		powerA = powerA.Double()
+		powerB = powerB.Double()
+
+		t0A, t0B := branchNonces(curveA, curveB, hA, hB, bp, 0, bp.C0, bp.Z0A, bp.Z0B)
+		t1A, t1B := branchNonces(curveA, curveB, hA, hB, bp, 1, xorChallenge(proof.C, bp.C0), bp.Z1A, bp.Z1B)
+		nonces[i] = [4]curves.Point{t0A, t0B, t1A, t1B}
+	}
+	sumA := proof.StatementA.SumOfProducts(commitmentsA, powersA)
+	sumB := proof.StatementB.SumOfProducts(commitmentsB, powersB)
+	if sumA == nil || sumB == nil || !sumA.Equal(proof.StatementA) || !sumB.Equal(proof.StatementB) {
+		return fmt.Errorf("bit commitments do not add up to the statements")
+	}
+
+	c, err := challenge(proof, nonces, uniqueSessionId)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(c[:], proof.C[:]) != 1 {
+		return fmt.Errorf("crossgroup verification failed")
+	}
+	return nil
+}
+
+// branchNonces computes the nonces z * H - c * (Commitment - bit * G) of the `bit` branch on each curve
+func branchNonces(curveA, curveB *curves.Curve, hA, hB *curves.FixedBaseTable, bp *BitProof, bit int, c [ChallengeSize]byte, zA, zB curves.Scalar) (curves.Point, curves.Point) {
+	pA, pB := bp.CommitmentA, bp.CommitmentB
+	if bit == 1 {
+		pA = pA.Sub(curveA.NewGeneratorPoint())
+		pB = pB.Sub(curveB.NewGeneratorPoint())
+	}
+	tA := hA.Mul(zA).Sub(pA.Mul(challengeScalar(curveA, c)))
+	tB := hB.Mul(zB).Sub(pB.Mul(challengeScalar(curveB, c)))
+	return tA, tB
+}
+
+// challenge hashes the statements, commitments and nonces into the proof challenge
+func challenge(proof *Proof, nonces [][4]curves.Point, uniqueSessionId []byte) ([ChallengeSize]byte, error) {
+	var c [ChallengeSize]byte
+	hash := sha3.New256()
+	if _, err := hash.Write(uniqueSessionId); err != nil {
+		return c, errors.Wrap(err, "writing salt to hash in crossgroup challenge")
+	}
+	var count [4]byte
+	binary.BigEndian.PutUint32(count[:], uint32(len(proof.Bits)))
+	if _, err := hash.Write(count[:]); err != nil {
+		return c, errors.Wrap(err, "writing bit count to hash in crossgroup challenge")
+	}
+	if _, err := hash.Write(proof.StatementA.ToAffineCompressed()); err != nil {
+		return c, errors.Wrap(err, "writing statement to hash in crossgroup challenge")
+	}
+	if _, err := hash.Write(proof.StatementB.ToAffineCompressed()); err != nil {
+		return c, errors.Wrap(err, "writing statement to hash in crossgroup challenge")
+	}
+	for i, bp := range proof.Bits {
+		points := append([]curves.Point{bp.CommitmentA, bp.CommitmentB}, nonces[i][:]...)
+		for _, p := range points {
+			if _, err := hash.Write(p.ToAffineCompressed()); err != nil {
+				return c, errors.Wrap(err, "writing bit proof to hash in crossgroup challenge")
+			}
+		}
+	}
+	copy(c[:], hash.Sum(nil))
+	return c, nil
+}
+
+// blindingGenerators returns the tables of the Pedersen blinding generators of both curves
+func blindingGenerators(curveA, curveB *curves.Curve) (*curves.FixedBaseTable, *curves.FixedBaseTable, error) {
+	hA, err := blindingGenerator(curveA)
+	if err != nil {
+		return nil, nil, err
+	}
+	hB, err := blindingGenerator(curveB)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hA, hB, nil
+}
+
+// blindingGenerator derives the blinding generator of `curve`, the tables are cached by curve name
+func blindingGenerator(curve *curves.Curve) (*curves.FixedBaseTable, error) {
+	if t, ok := blindingTables.Load(curve.Name); ok {
+		return t.(*curves.FixedBaseTable), nil
+	}
+	h, err := curves.DeriveGenerators(curve, blindingLabel, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving blinding generator")
+	}
+	t, _ := blindingTables.LoadOrStore(curve.Name, curves.NewFixedBaseTable(h[0]))
+	return t.(*curves.FixedBaseTable), nil
+}
+
+// weightedZeroSum returns random scalars r_0..r_{n-1} with sum(2^i * r_i) = 0
+func weightedZeroSum(curve *curves.Curve, n int, reader io.Reader) []curves.Scalar {
+	r := make([]curves.Scalar, n)
+	sum := curve.Scalar.Zero()
+	power := curve.Scalar.One()
+	for i := 0; i < n-1; i++ {
+		r[i] = curve.Scalar.Random(reader)
+		sum = sum.Add(r[i].Mul(power))
+		power = power.Double()
+	}
+	// 2^(n-1) is invertible as the group orders are odd
+	inv, _ := power.Invert()
+	r[n-1] = sum.Neg().Mul(inv)
+	return r
+}
+
+// scalarFromInt reduces the integer `x` modulo the order of the curve
+func scalarFromInt(curve *curves.Curve, x *big.Int) curves.Scalar {
+	order := new(big.Int).Add(curve.Scalar.New(-1).BigInt(), big.NewInt(1))
+	s, _ := curve.Scalar.SetBigInt(new(big.Int).Mod(x, order))
+	return s
+}
+
+func challengeScalar(curve *curves.Curve, c [ChallengeSize]byte) curves.Scalar {
+	return scalarFromInt(curve, new(big.Int).SetBytes(c[:]))
+}
+
+func xorChallenge(a, b [ChallengeSize]byte) [ChallengeSize]byte {
+	var out [ChallengeSize]byte
+	for i := range out {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
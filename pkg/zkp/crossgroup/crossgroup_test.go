@@ -0,0 +1,90 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package crossgroup
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/etclab/kryptology/pkg/core/curves"
+)
+
+func TestCrossGroupProof(t *testing.T) {
+	sessionId := []byte("crossgroup test")
+	pairs := [][2]*curves.Curve{
+		{curves.K256(), curves.P256()},
+		{curves.P256(), curves.ED25519()},
+		{curves.BLS12381G1(), curves.PALLAS()},
+	}
+	for _, pair := range pairs {
+		x, err := crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+		require.NoError(t, err)
+		proof, err := Prove(x, 64, pair[0], pair[1], sessionId)
+		require.NoError(t, err)
+		require.NoError(t, Verify(proof, 64, pair[0], pair[1], sessionId), pair[0].Name)
+		require.True(t, proof.StatementA.Equal(pair[0].ScalarBaseMult(scalarFromInt(pair[0], x))))
+		require.True(t, proof.StatementB.Equal(pair[1].ScalarBaseMult(scalarFromInt(pair[1], x))))
+
+		require.Error(t, Verify(proof, 64, pair[0], pair[1], []byte("other session")))
+		require.Error(t, Verify(proof, 63, pair[0], pair[1], sessionId))
+	}
+}
+
+func TestCrossGroupProofFullWidth(t *testing.T) {
+	// A secp256k1 scalar moved to P-256, which has a smaller order
+	curveA, curveB := curves.K256(), curves.P256()
+	x := curveA.Scalar.New(-1).BigInt()
+	proof, err := Prove(x, 256, curveA, curveB, nil)
+	require.NoError(t, err)
+	require.NoError(t, Verify(proof, 256, curveA, curveB, nil))
+	require.True(t, proof.StatementA.Equal(curveA.ScalarBaseMult(curveA.Scalar.New(-1))))
+}
+
+func TestCrossGroupProofRejectsTampering(t *testing.T) {
+	curveA, curveB := curves.K256(), curves.P256()
+	sessionId := []byte("crossgroup test")
+	x := big.NewInt(0xbeef)
+	proof, err := Prove(x, 16, curveA, curveB, sessionId)
+	require.NoError(t, err)
+
+	// A different statement on one curve
+	bad := *proof
+	bad.StatementB = curveB.ScalarBaseMult(curveB.Scalar.New(0xbeee))
+	require.Error(t, Verify(&bad, 16, curveA, curveB, sessionId))
+
+	// Changing the commitments on one curve while keeping their weighted sum,
+	// so only the bit proofs can catch it
+	bad = *proof
+	bad.Bits = append([]*BitProof{}, proof.Bits...)
+	bit0 := *proof.Bits[0]
+	bit0.CommitmentB = bit0.CommitmentB.Add(curveB.NewGeneratorPoint().Double())
+	bit1 := *proof.Bits[1]
+	bit1.CommitmentB = bit1.CommitmentB.Sub(curveB.NewGeneratorPoint())
+	bad.Bits[0], bad.Bits[1] = &bit0, &bit1
+	require.Error(t, Verify(&bad, 16, curveA, curveB, sessionId))
+
+	// Altered responses
+	bad = *proof
+	bad.Bits = append([]*BitProof{}, proof.Bits...)
+	altered := *proof.Bits[3]
+	altered.Z0A = altered.Z0A.Add(curveA.Scalar.One())
+	bad.Bits[3] = &altered
+	require.Error(t, Verify(&bad, 16, curveA, curveB, sessionId))
+}
+
+func TestCrossGroupProofInvalidWitness(t *testing.T) {
+	_, err := Prove(big.NewInt(1<<16), 16, curves.K256(), curves.P256(), nil)
+	require.Error(t, err)
+	_, err = Prove(big.NewInt(-1), 16, curves.K256(), curves.P256(), nil)
+	require.Error(t, err)
+	_, err = Prove(nil, 16, curves.K256(), curves.P256(), nil)
+	require.Error(t, err)
+	require.Error(t, Verify(nil, 16, curves.K256(), curves.P256(), nil))
+}
@@ -12,6 +12,7 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	"fmt"
+	"io"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
@@ -25,6 +26,9 @@ type Prover struct {
 	curve           *curves.Curve
 	basePoint       curves.Point
 	uniqueSessionId []byte
+
+	// reader is the source of the proof nonces.
+	reader io.Reader
 }
 
 // Proof contains the (c, s) schnorr proof. `Statement` is the curve point you're proving knowledge of discrete log of,
@@ -38,6 +42,12 @@ type Proof struct {
 // NewProver generates a `Prover` object, ready to generate Schnorr proofs on any given point.
 // We allow the option `basePoint == nil`, in which case `basePoint` is auto-assigned to be the "default" generator for the group.
 func NewProver(curve *curves.Curve, basepoint curves.Point, uniqueSessionId []byte) *Prover {
+	return NewProverWithReader(curve, basepoint, uniqueSessionId, rand.Reader)
+}
+
+// NewProverWithReader is NewProver, with the proof nonces sampled from `reader`. Anything other than a cryptographically
+// secure random source leaks the witness; this is meant for reproducible test vectors.
+func NewProverWithReader(curve *curves.Curve, basepoint curves.Point, uniqueSessionId []byte, reader io.Reader) *Prover {
 	if basepoint == nil {
 		basepoint = curve.NewGeneratorPoint()
 	}
@@ -45,6 +55,7 @@ func NewProver(curve *curves.Curve, basepoint curves.Point, uniqueSessionId []by
 		curve:           curve,
 		basePoint:       basepoint,
 		uniqueSessionId: uniqueSessionId,
+		reader:          reader,
 	}
 }
 
@@ -55,7 +66,7 @@ func (p *Prover) Prove(x curves.Scalar) (*Proof, error) {
 	var err error
 	result := &Proof{}
 	result.Statement = p.basePoint.Mul(x)
-	k := p.curve.Scalar.Random(rand.Reader)
+	k := p.curve.Scalar.Random(p.reader)
 	random := p.basePoint.Mul(k)
 	hash := sha3.New256()
 	if _, err = hash.Write(p.uniqueSessionId); err != nil {
@@ -70,7 +81,7 @@ func (p *Prover) Prove(x curves.Scalar) (*Proof, error) {
 	if _, err = hash.Write(random.ToAffineCompressed()); err != nil {
 		return nil, errors.Wrap(err, "writing point K to hash in schnorr prove")
 	}
-	result.C, err = p.curve.Scalar.SetBytes(hash.Sum(nil))
+	result.C, err = challenge(p.curve, hash.Sum(nil))
 	if err != nil {
 		return nil, errors.Wrap(err, "writing point K to hash in schnorr prove")
 	}
@@ -84,9 +95,14 @@ func Verify(proof *Proof, curve *curves.Curve, basepoint curves.Point, uniqueSes
 	if basepoint == nil {
 		basepoint = curve.NewGeneratorPoint()
 	}
-	gs := basepoint.Mul(proof.S)
-	xc := proof.Statement.Mul(proof.C.Neg())
-	random := gs.Add(xc)
+	// The proof is public, so the multiplications may run in variable time
+	random := curves.VarTimeSumOfProducts(
+		[]curves.Point{basepoint, proof.Statement},
+		[]curves.Scalar{proof.S, proof.C.Neg()},
+	)
+	if random == nil {
+		return fmt.Errorf("schnorr verification failed")
+	}
 	hash := sha3.New256()
 	if _, err := hash.Write(uniqueSessionId); err != nil {
 		return errors.Wrap(err, "writing salt to hash in schnorr verify")
@@ -100,12 +116,38 @@ func Verify(proof *Proof, curve *curves.Curve, basepoint curves.Point, uniqueSes
 	if _, err := hash.Write(random.ToAffineCompressed()); err != nil {
 		return errors.Wrap(err, "writing point K to hash in schnorr verify")
 	}
-	if subtle.ConstantTimeCompare(proof.C.Bytes(), hash.Sum(nil)) != 1 {
+	c, err := challenge(curve, hash.Sum(nil))
+	if err != nil {
+		return errors.Wrap(err, "computing challenge in schnorr verify")
+	}
+	if subtle.ConstantTimeCompare(proof.C.Bytes(), c.Bytes()) != 1 {
 		return fmt.Errorf("schnorr verification failed")
 	}
 	return nil
 }
 
+// challenge maps the transcript digest to a scalar. Curves with 32-byte scalars take the digest
+// as their canonical encoding when it is one, otherwise the digest is zero-padded to
+// curves.WideScalarBytes and reduced, e.g. for digests above the Pasta group orders.
+// Curves with wider scalars, such as P-384, hash the digest to a scalar of their full width
+func challenge(curve *curves.Curve, digest []byte) (curves.Scalar, error) {
+	if len(curve.Scalar.Bytes()) > len(digest) {
+		c := curve.Scalar.Hash(digest)
+		if c == nil {
+			return nil, fmt.Errorf("hashing challenge to a %s scalar", curve.Name)
+		}
+		return c, nil
+	}
+	if len(curve.Scalar.Bytes()) == len(digest) {
+		if c, err := curve.Scalar.SetBytes(digest); err == nil {
+			return c, nil
+		}
+	}
+	var wide [curves.WideScalarBytes]byte
+	copy(wide[:], digest)
+	return curve.Scalar.SetBytesWide(wide[:])
+}
+
 // ProveCommit generates _and_ commits to a schnorr proof which is later revealed; see Functionality 7.
 // returns the Proof and Commitment.
 func (p *Prover) ProveCommit(x curves.Scalar) (*Proof, Commitment, error) {
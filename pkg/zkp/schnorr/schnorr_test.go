@@ -15,8 +15,10 @@ func TestZKPOverMultipleCurves(t *testing.T) {
 	curveInstances := []*curves.Curve{
 		curves.K256(),
 		curves.P256(),
+		curves.P384(),
+		curves.PALLAS(),
+		curves.VESTA(),
 		// TODO: the code fails on the following curves. Investigate if this is expected.
-		// curves.PALLAS(),
 		// curves.BLS12377G1(),
 		// curves.BLS12377G2(),
 		// curves.BLS12381G1(),
@@ -35,3 +37,16 @@ func TestZKPOverMultipleCurves(t *testing.T) {
 		require.NoError(t, err, fmt.Sprintf("failed in curve %d", i))
 	}
 }
+
+func TestChallengeWidth(t *testing.T) {
+	digest := sha3.Sum256([]byte("transcript"))
+	// P-384 challenges are hashed to the full width of the scalar, not the 256 bits of the digest
+	c, err := challenge(curves.P384(), digest[:])
+	require.NoError(t, err)
+	require.Equal(t, 0, c.Cmp(curves.P384().Scalar.Hash(digest[:])))
+	require.Greater(t, c.BigInt().BitLen(), 256)
+	// 32-byte scalars keep the digest as their encoding
+	c, err = challenge(curves.K256(), digest[:])
+	require.NoError(t, err)
+	require.Equal(t, digest[:], c.Bytes())
+}